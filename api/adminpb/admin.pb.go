@@ -0,0 +1,966 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v5.29.3
+// source: api/admin.proto
+
+package adminpb
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ControlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"` // Logged by the aggregator
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlRequest) Reset() {
+	*x = ControlRequest{}
+	mi := &file_api_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlRequest) ProtoMessage() {}
+
+func (x *ControlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlRequest.ProtoReflect.Descriptor instead.
+func (*ControlRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ControlRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ControlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // running, paused or aborted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlResponse) Reset() {
+	*x = ControlResponse{}
+	mi := &file_api_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlResponse) ProtoMessage() {}
+
+func (x *ControlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlResponse.ProtoReflect.Descriptor instead.
+func (*ControlResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ControlResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_api_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{2}
+}
+
+type StatusResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FederationId    string                 `protobuf:"bytes,1,opt,name=federation_id,json=federationId,proto3" json:"federation_id,omitempty"`
+	Mode            string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Algorithm       string                 `protobuf:"bytes,3,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	State           string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`                           // idle, running, paused or aborted
+	StartTime       int64                  `protobuf:"varint,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Unix seconds
+	CurrentRound    int32                  `protobuf:"varint,6,opt,name=current_round,json=currentRound,proto3" json:"current_round,omitempty"`
+	RoundsPlanned   int32                  `protobuf:"varint,7,opt,name=rounds_planned,json=roundsPlanned,proto3" json:"rounds_planned,omitempty"` // 0 in async mode
+	RoundsCompleted int32                  `protobuf:"varint,8,opt,name=rounds_completed,json=roundsCompleted,proto3" json:"rounds_completed,omitempty"`
+	UpdatesAccepted int64                  `protobuf:"varint,9,opt,name=updates_accepted,json=updatesAccepted,proto3" json:"updates_accepted,omitempty"`
+	UpdatesDropped  int64                  `protobuf:"varint,10,opt,name=updates_dropped,json=updatesDropped,proto3" json:"updates_dropped,omitempty"`
+	LogLevel        string                 `protobuf:"bytes,11,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_api_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatusResponse) GetFederationId() string {
+	if x != nil {
+		return x.FederationId
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetCurrentRound() int32 {
+	if x != nil {
+		return x.CurrentRound
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetRoundsPlanned() int32 {
+	if x != nil {
+		return x.RoundsPlanned
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetRoundsCompleted() int32 {
+	if x != nil {
+		return x.RoundsCompleted
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetUpdatesAccepted() int64 {
+	if x != nil {
+		return x.UpdatesAccepted
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetUpdatesDropped() int64 {
+	if x != nil {
+		return x.UpdatesDropped
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+type ListCollaboratorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollaboratorsRequest) Reset() {
+	*x = ListCollaboratorsRequest{}
+	mi := &file_api_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollaboratorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollaboratorsRequest) ProtoMessage() {}
+
+func (x *ListCollaboratorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollaboratorsRequest.ProtoReflect.Descriptor instead.
+func (*ListCollaboratorsRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{4}
+}
+
+type ListCollaboratorsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collaborators []*CollaboratorStatus  `protobuf:"bytes,1,rep,name=collaborators,proto3" json:"collaborators,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollaboratorsResponse) Reset() {
+	*x = ListCollaboratorsResponse{}
+	mi := &file_api_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollaboratorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollaboratorsResponse) ProtoMessage() {}
+
+func (x *ListCollaboratorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollaboratorsResponse.ProtoReflect.Descriptor instead.
+func (*ListCollaboratorsResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListCollaboratorsResponse) GetCollaborators() []*CollaboratorStatus {
+	if x != nil {
+		return x.Collaborators
+	}
+	return nil
+}
+
+type CollaboratorStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Role               string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"` // trainer or evaluator; empty for collaborators that are not in the plan
+	UpdatesAccepted    int32                  `protobuf:"varint,3,opt,name=updates_accepted,json=updatesAccepted,proto3" json:"updates_accepted,omitempty"`
+	UpdatesDropped     int32                  `protobuf:"varint,4,opt,name=updates_dropped,json=updatesDropped,proto3" json:"updates_dropped,omitempty"`
+	RoundsParticipated int32                  `protobuf:"varint,5,opt,name=rounds_participated,json=roundsParticipated,proto3" json:"rounds_participated,omitempty"`
+	LastUpdate         int64                  `protobuf:"varint,6,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"` // Unix seconds; 0 before the first accepted update
+	Pending            bool                   `protobuf:"varint,7,opt,name=pending,proto3" json:"pending,omitempty"`                         // expected in the current sync round and not submitted yet
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CollaboratorStatus) Reset() {
+	*x = CollaboratorStatus{}
+	mi := &file_api_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollaboratorStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollaboratorStatus) ProtoMessage() {}
+
+func (x *CollaboratorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollaboratorStatus.ProtoReflect.Descriptor instead.
+func (*CollaboratorStatus) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CollaboratorStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CollaboratorStatus) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *CollaboratorStatus) GetUpdatesAccepted() int32 {
+	if x != nil {
+		return x.UpdatesAccepted
+	}
+	return 0
+}
+
+func (x *CollaboratorStatus) GetUpdatesDropped() int32 {
+	if x != nil {
+		return x.UpdatesDropped
+	}
+	return 0
+}
+
+func (x *CollaboratorStatus) GetRoundsParticipated() int32 {
+	if x != nil {
+		return x.RoundsParticipated
+	}
+	return 0
+}
+
+func (x *CollaboratorStatus) GetLastUpdate() int64 {
+	if x != nil {
+		return x.LastUpdate
+	}
+	return 0
+}
+
+func (x *CollaboratorStatus) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+type RoundStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoundStateRequest) Reset() {
+	*x = RoundStateRequest{}
+	mi := &file_api_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoundStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundStateRequest) ProtoMessage() {}
+
+func (x *RoundStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundStateRequest.ProtoReflect.Descriptor instead.
+func (*RoundStateRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{7}
+}
+
+type RoundStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Round         int32                  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`                         // 0 before the first round; in async mode, the round the next aggregation produces
+	ModelHash     string                 `protobuf:"bytes,2,opt,name=model_hash,json=modelHash,proto3" json:"model_hash,omitempty"` // hash of the model the round trains
+	Expected      int32                  `protobuf:"varint,3,opt,name=expected,proto3" json:"expected,omitempty"`                   // updates the round waits for; in async mode, min_updates
+	Received      []string               `protobuf:"bytes,4,rep,name=received,proto3" json:"received,omitempty"`                    // collaborators whose updates the round holds
+	Pending       []string               `protobuf:"bytes,5,rep,name=pending,proto3" json:"pending,omitempty"`                      // sync mode: expected collaborators that have not submitted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoundStateResponse) Reset() {
+	*x = RoundStateResponse{}
+	mi := &file_api_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoundStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundStateResponse) ProtoMessage() {}
+
+func (x *RoundStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundStateResponse.ProtoReflect.Descriptor instead.
+func (*RoundStateResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RoundStateResponse) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *RoundStateResponse) GetModelHash() string {
+	if x != nil {
+		return x.ModelHash
+	}
+	return ""
+}
+
+func (x *RoundStateResponse) GetExpected() int32 {
+	if x != nil {
+		return x.Expected
+	}
+	return 0
+}
+
+func (x *RoundStateResponse) GetReceived() []string {
+	if x != nil {
+		return x.Received
+	}
+	return nil
+}
+
+func (x *RoundStateResponse) GetPending() []string {
+	if x != nil {
+		return x.Pending
+	}
+	return nil
+}
+
+type LogLevelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"` // debug, info or warn
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLevelRequest) Reset() {
+	*x = LogLevelRequest{}
+	mi := &file_api_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLevelRequest) ProtoMessage() {}
+
+func (x *LogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLevelRequest.ProtoReflect.Descriptor instead.
+func (*LogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LogLevelRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+type LogLevelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	Previous      string                 `protobuf:"bytes,2,opt,name=previous,proto3" json:"previous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLevelResponse) Reset() {
+	*x = LogLevelResponse{}
+	mi := &file_api_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLevelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLevelResponse) ProtoMessage() {}
+
+func (x *LogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLevelResponse.ProtoReflect.Descriptor instead.
+func (*LogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LogLevelResponse) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogLevelResponse) GetPrevious() string {
+	if x != nil {
+		return x.Previous
+	}
+	return ""
+}
+
+type AsyncConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AsyncConfigRequest) Reset() {
+	*x = AsyncConfigRequest{}
+	mi := &file_api_admin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AsyncConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncConfigRequest) ProtoMessage() {}
+
+func (x *AsyncConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncConfigRequest.ProtoReflect.Descriptor instead.
+func (*AsyncConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{11}
+}
+
+// AsyncTuning is a partial update of the runtime-tunable async settings
+type AsyncTuning struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MaxStaleness     *int32                 `protobuf:"varint,1,opt,name=max_staleness,json=maxStaleness,proto3,oneof" json:"max_staleness,omitempty"`
+	MinUpdates       *int32                 `protobuf:"varint,2,opt,name=min_updates,json=minUpdates,proto3,oneof" json:"min_updates,omitempty"`
+	AggregationDelay *int32                 `protobuf:"varint,3,opt,name=aggregation_delay,json=aggregationDelay,proto3,oneof" json:"aggregation_delay,omitempty"` // Seconds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AsyncTuning) Reset() {
+	*x = AsyncTuning{}
+	mi := &file_api_admin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AsyncTuning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncTuning) ProtoMessage() {}
+
+func (x *AsyncTuning) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncTuning.ProtoReflect.Descriptor instead.
+func (*AsyncTuning) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AsyncTuning) GetMaxStaleness() int32 {
+	if x != nil && x.MaxStaleness != nil {
+		return *x.MaxStaleness
+	}
+	return 0
+}
+
+func (x *AsyncTuning) GetMinUpdates() int32 {
+	if x != nil && x.MinUpdates != nil {
+		return *x.MinUpdates
+	}
+	return 0
+}
+
+func (x *AsyncTuning) GetAggregationDelay() int32 {
+	if x != nil && x.AggregationDelay != nil {
+		return *x.AggregationDelay
+	}
+	return 0
+}
+
+type AsyncConfig struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MaxStaleness     int32                  `protobuf:"varint,1,opt,name=max_staleness,json=maxStaleness,proto3" json:"max_staleness,omitempty"`
+	MinUpdates       int32                  `protobuf:"varint,2,opt,name=min_updates,json=minUpdates,proto3" json:"min_updates,omitempty"`
+	AggregationDelay int32                  `protobuf:"varint,3,opt,name=aggregation_delay,json=aggregationDelay,proto3" json:"aggregation_delay,omitempty"` // Seconds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AsyncConfig) Reset() {
+	*x = AsyncConfig{}
+	mi := &file_api_admin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AsyncConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncConfig) ProtoMessage() {}
+
+func (x *AsyncConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncConfig.ProtoReflect.Descriptor instead.
+func (*AsyncConfig) Descriptor() ([]byte, []int) {
+	return file_api_admin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AsyncConfig) GetMaxStaleness() int32 {
+	if x != nil {
+		return x.MaxStaleness
+	}
+	return 0
+}
+
+func (x *AsyncConfig) GetMinUpdates() int32 {
+	if x != nil {
+		return x.MinUpdates
+	}
+	return 0
+}
+
+func (x *AsyncConfig) GetAggregationDelay() int32 {
+	if x != nil {
+		return x.AggregationDelay
+	}
+	return 0
+}
+
+var File_api_admin_proto protoreflect.FileDescriptor
+
+const file_api_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x0fapi/admin.proto\x12\x05admin\"(\n" +
+	"\x0eControlRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"'\n" +
+	"\x0fControlResponse\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\"\x0f\n" +
+	"\rStatusRequest\"\x84\x03\n" +
+	"\x0eStatusResponse\x12#\n" +
+	"\rfederation_id\x18\x01 \x01(\tR\ffederationId\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x1c\n" +
+	"\talgorithm\x18\x03 \x01(\tR\talgorithm\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\x03R\tstartTime\x12#\n" +
+	"\rcurrent_round\x18\x06 \x01(\x05R\fcurrentRound\x12%\n" +
+	"\x0erounds_planned\x18\a \x01(\x05R\rroundsPlanned\x12)\n" +
+	"\x10rounds_completed\x18\b \x01(\x05R\x0froundsCompleted\x12)\n" +
+	"\x10updates_accepted\x18\t \x01(\x03R\x0fupdatesAccepted\x12'\n" +
+	"\x0fupdates_dropped\x18\n" +
+	" \x01(\x03R\x0eupdatesDropped\x12\x1b\n" +
+	"\tlog_level\x18\v \x01(\tR\blogLevel\"\x1a\n" +
+	"\x18ListCollaboratorsRequest\"\\\n" +
+	"\x19ListCollaboratorsResponse\x12?\n" +
+	"\rcollaborators\x18\x01 \x03(\v2\x19.admin.CollaboratorStatusR\rcollaborators\"\xf8\x01\n" +
+	"\x12CollaboratorStatus\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12)\n" +
+	"\x10updates_accepted\x18\x03 \x01(\x05R\x0fupdatesAccepted\x12'\n" +
+	"\x0fupdates_dropped\x18\x04 \x01(\x05R\x0eupdatesDropped\x12/\n" +
+	"\x13rounds_participated\x18\x05 \x01(\x05R\x12roundsParticipated\x12\x1f\n" +
+	"\vlast_update\x18\x06 \x01(\x03R\n" +
+	"lastUpdate\x12\x18\n" +
+	"\apending\x18\a \x01(\bR\apending\"\x13\n" +
+	"\x11RoundStateRequest\"\x9b\x01\n" +
+	"\x12RoundStateResponse\x12\x14\n" +
+	"\x05round\x18\x01 \x01(\x05R\x05round\x12\x1d\n" +
+	"\n" +
+	"model_hash\x18\x02 \x01(\tR\tmodelHash\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\x05R\bexpected\x12\x1a\n" +
+	"\breceived\x18\x04 \x03(\tR\breceived\x12\x18\n" +
+	"\apending\x18\x05 \x03(\tR\apending\"'\n" +
+	"\x0fLogLevelRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\"D\n" +
+	"\x10LogLevelResponse\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x1a\n" +
+	"\bprevious\x18\x02 \x01(\tR\bprevious\"\x14\n" +
+	"\x12AsyncConfigRequest\"\xc7\x01\n" +
+	"\vAsyncTuning\x12(\n" +
+	"\rmax_staleness\x18\x01 \x01(\x05H\x00R\fmaxStaleness\x88\x01\x01\x12$\n" +
+	"\vmin_updates\x18\x02 \x01(\x05H\x01R\n" +
+	"minUpdates\x88\x01\x01\x120\n" +
+	"\x11aggregation_delay\x18\x03 \x01(\x05H\x02R\x10aggregationDelay\x88\x01\x01B\x10\n" +
+	"\x0e_max_stalenessB\x0e\n" +
+	"\f_min_updatesB\x14\n" +
+	"\x12_aggregation_delay\"\x80\x01\n" +
+	"\vAsyncConfig\x12#\n" +
+	"\rmax_staleness\x18\x01 \x01(\x05R\fmaxStaleness\x12\x1f\n" +
+	"\vmin_updates\x18\x02 \x01(\x05R\n" +
+	"minUpdates\x12+\n" +
+	"\x11aggregation_delay\x18\x03 \x01(\x05R\x10aggregationDelay2\x90\x05\n" +
+	"\fAdminService\x126\n" +
+	"\x05Pause\x12\x15.admin.ControlRequest\x1a\x16.admin.ControlResponse\x127\n" +
+	"\x06Resume\x12\x15.admin.ControlRequest\x1a\x16.admin.ControlResponse\x126\n" +
+	"\x05Abort\x12\x15.admin.ControlRequest\x1a\x16.admin.ControlResponse\x128\n" +
+	"\tGetStatus\x12\x14.admin.StatusRequest\x1a\x15.admin.StatusResponse\x12V\n" +
+	"\x11ListCollaborators\x12\x1f.admin.ListCollaboratorsRequest\x1a .admin.ListCollaboratorsResponse\x12D\n" +
+	"\rGetRoundState\x12\x18.admin.RoundStateRequest\x1a\x19.admin.RoundStateResponse\x12I\n" +
+	"\x15TriggerAggregationNow\x12\x15.admin.ControlRequest\x1a\x19.admin.RoundStateResponse\x12>\n" +
+	"\vSetLogLevel\x12\x16.admin.LogLevelRequest\x1a\x17.admin.LogLevelResponse\x12?\n" +
+	"\x0eGetAsyncConfig\x12\x19.admin.AsyncConfigRequest\x1a\x12.admin.AsyncConfig\x123\n" +
+	"\tTuneAsync\x12\x12.admin.AsyncTuning\x1a\x12.admin.AsyncConfigB\x0fZ\r./api/adminpbb\x06proto3"
+
+var (
+	file_api_admin_proto_rawDescOnce sync.Once
+	file_api_admin_proto_rawDescData []byte
+)
+
+func file_api_admin_proto_rawDescGZIP() []byte {
+	file_api_admin_proto_rawDescOnce.Do(func() {
+		file_api_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_admin_proto_rawDesc), len(file_api_admin_proto_rawDesc)))
+	})
+	return file_api_admin_proto_rawDescData
+}
+
+var file_api_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_api_admin_proto_goTypes = []any{
+	(*ControlRequest)(nil),            // 0: admin.ControlRequest
+	(*ControlResponse)(nil),           // 1: admin.ControlResponse
+	(*StatusRequest)(nil),             // 2: admin.StatusRequest
+	(*StatusResponse)(nil),            // 3: admin.StatusResponse
+	(*ListCollaboratorsRequest)(nil),  // 4: admin.ListCollaboratorsRequest
+	(*ListCollaboratorsResponse)(nil), // 5: admin.ListCollaboratorsResponse
+	(*CollaboratorStatus)(nil),        // 6: admin.CollaboratorStatus
+	(*RoundStateRequest)(nil),         // 7: admin.RoundStateRequest
+	(*RoundStateResponse)(nil),        // 8: admin.RoundStateResponse
+	(*LogLevelRequest)(nil),           // 9: admin.LogLevelRequest
+	(*LogLevelResponse)(nil),          // 10: admin.LogLevelResponse
+	(*AsyncConfigRequest)(nil),        // 11: admin.AsyncConfigRequest
+	(*AsyncTuning)(nil),               // 12: admin.AsyncTuning
+	(*AsyncConfig)(nil),               // 13: admin.AsyncConfig
+}
+var file_api_admin_proto_depIdxs = []int32{
+	6,  // 0: admin.ListCollaboratorsResponse.collaborators:type_name -> admin.CollaboratorStatus
+	0,  // 1: admin.AdminService.Pause:input_type -> admin.ControlRequest
+	0,  // 2: admin.AdminService.Resume:input_type -> admin.ControlRequest
+	0,  // 3: admin.AdminService.Abort:input_type -> admin.ControlRequest
+	2,  // 4: admin.AdminService.GetStatus:input_type -> admin.StatusRequest
+	4,  // 5: admin.AdminService.ListCollaborators:input_type -> admin.ListCollaboratorsRequest
+	7,  // 6: admin.AdminService.GetRoundState:input_type -> admin.RoundStateRequest
+	0,  // 7: admin.AdminService.TriggerAggregationNow:input_type -> admin.ControlRequest
+	9,  // 8: admin.AdminService.SetLogLevel:input_type -> admin.LogLevelRequest
+	11, // 9: admin.AdminService.GetAsyncConfig:input_type -> admin.AsyncConfigRequest
+	12, // 10: admin.AdminService.TuneAsync:input_type -> admin.AsyncTuning
+	1,  // 11: admin.AdminService.Pause:output_type -> admin.ControlResponse
+	1,  // 12: admin.AdminService.Resume:output_type -> admin.ControlResponse
+	1,  // 13: admin.AdminService.Abort:output_type -> admin.ControlResponse
+	3,  // 14: admin.AdminService.GetStatus:output_type -> admin.StatusResponse
+	5,  // 15: admin.AdminService.ListCollaborators:output_type -> admin.ListCollaboratorsResponse
+	8,  // 16: admin.AdminService.GetRoundState:output_type -> admin.RoundStateResponse
+	8,  // 17: admin.AdminService.TriggerAggregationNow:output_type -> admin.RoundStateResponse
+	10, // 18: admin.AdminService.SetLogLevel:output_type -> admin.LogLevelResponse
+	13, // 19: admin.AdminService.GetAsyncConfig:output_type -> admin.AsyncConfig
+	13, // 20: admin.AdminService.TuneAsync:output_type -> admin.AsyncConfig
+	11, // [11:21] is the sub-list for method output_type
+	1,  // [1:11] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_api_admin_proto_init() }
+func file_api_admin_proto_init() {
+	if File_api_admin_proto != nil {
+		return
+	}
+	file_api_admin_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_admin_proto_rawDesc), len(file_api_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_admin_proto_goTypes,
+		DependencyIndexes: file_api_admin_proto_depIdxs,
+		MessageInfos:      file_api_admin_proto_msgTypes,
+	}.Build()
+	File_api_admin_proto = out.File
+	file_api_admin_proto_goTypes = nil
+	file_api_admin_proto_depIdxs = nil
+}
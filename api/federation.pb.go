@@ -22,11 +22,180 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// AckStatus tells a collaborator how to react to a rejected request
+type AckStatus int32
+
+const (
+	AckStatus_ACK_OK                      AckStatus = 0 // Accepted; aggregators that predate status codes send it with any success
+	AckStatus_ACK_REJECTED_STALE          AckStatus = 1 // Trained for an earlier round; train again on the latest model
+	AckStatus_ACK_REJECTED_ROUND_MISMATCH AckStatus = 2 // Trained for another round or model; train again on the latest model
+	AckStatus_ACK_REJECTED_INVALID        AckStatus = 3 // Malformed or unusable; resending it cannot succeed
+	AckStatus_ACK_RETRY_AFTER             AckStatus = 4 // Not accepted now; resend after retry_after_seconds
+	AckStatus_ACK_REJECTED_DUPLICATE      AckStatus = 5 // Already received for this round
+)
+
+// Enum value maps for AckStatus.
+var (
+	AckStatus_name = map[int32]string{
+		0: "ACK_OK",
+		1: "ACK_REJECTED_STALE",
+		2: "ACK_REJECTED_ROUND_MISMATCH",
+		3: "ACK_REJECTED_INVALID",
+		4: "ACK_RETRY_AFTER",
+		5: "ACK_REJECTED_DUPLICATE",
+	}
+	AckStatus_value = map[string]int32{
+		"ACK_OK":                      0,
+		"ACK_REJECTED_STALE":          1,
+		"ACK_REJECTED_ROUND_MISMATCH": 2,
+		"ACK_REJECTED_INVALID":        3,
+		"ACK_RETRY_AFTER":             4,
+		"ACK_REJECTED_DUPLICATE":      5,
+	}
+)
+
+func (x AckStatus) Enum() *AckStatus {
+	p := new(AckStatus)
+	*p = x
+	return p
+}
+
+func (x AckStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AckStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_federation_proto_enumTypes[0].Descriptor()
+}
+
+func (AckStatus) Type() protoreflect.EnumType {
+	return &file_api_federation_proto_enumTypes[0]
+}
+
+func (x AckStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AckStatus.Descriptor instead.
+func (AckStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{0}
+}
+
+// TaskType is the kind of work the aggregator assigns to a polling collaborator
+type TaskType int32
+
+const (
+	TaskType_TASK_SLEEP    TaskType = 0 // Nothing to do yet; poll again after sleep_seconds
+	TaskType_TASK_TRAIN    TaskType = 1 // Train on model_weights and submit the update
+	TaskType_TASK_EVALUATE TaskType = 2 // Evaluate model_weights and submit the metrics
+	TaskType_TASK_QUIT     TaskType = 3 // The federation is over
+)
+
+// Enum value maps for TaskType.
+var (
+	TaskType_name = map[int32]string{
+		0: "TASK_SLEEP",
+		1: "TASK_TRAIN",
+		2: "TASK_EVALUATE",
+		3: "TASK_QUIT",
+	}
+	TaskType_value = map[string]int32{
+		"TASK_SLEEP":    0,
+		"TASK_TRAIN":    1,
+		"TASK_EVALUATE": 2,
+		"TASK_QUIT":     3,
+	}
+)
+
+func (x TaskType) Enum() *TaskType {
+	p := new(TaskType)
+	*p = x
+	return p
+}
+
+func (x TaskType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_federation_proto_enumTypes[1].Descriptor()
+}
+
+func (TaskType) Type() protoreflect.EnumType {
+	return &file_api_federation_proto_enumTypes[1]
+}
+
+func (x TaskType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskType.Descriptor instead.
+func (TaskType) EnumDescriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{1}
+}
+
+// EventType is the kind of control event an aggregator sends to watching
+// collaborators
+type EventType int32
+
+const (
+	EventType_EVENT_ROUND_STARTED       EventType = 0 // A sync round started from the model with model_hash
+	EventType_EVENT_FEDERATION_STOPPING EventType = 1 // The federation is over or the aggregator is shutting down
+	EventType_EVENT_MODEL_INVALIDATED   EventType = 2 // The global model changed; updates on older models are stale
+	EventType_EVENT_CONFIG_CHANGED      EventType = 3 // Hyperparameters or aggregation settings changed
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_ROUND_STARTED",
+		1: "EVENT_FEDERATION_STOPPING",
+		2: "EVENT_MODEL_INVALIDATED",
+		3: "EVENT_CONFIG_CHANGED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_ROUND_STARTED":       0,
+		"EVENT_FEDERATION_STOPPING": 1,
+		"EVENT_MODEL_INVALIDATED":   2,
+		"EVENT_CONFIG_CHANGED":      3,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_federation_proto_enumTypes[2].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_api_federation_proto_enumTypes[2]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{2}
+}
+
 type JoinRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId  string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	Capabilities    *Capabilities          `protobuf:"bytes,2,opt,name=capabilities,proto3" json:"capabilities,omitempty"`                              // Unset by collaborators of protocol version 1
+	PlanFingerprint string                 `protobuf:"bytes,3,opt,name=plan_fingerprint,json=planFingerprint,proto3" json:"plan_fingerprint,omitempty"` // federation.PlanFingerprint of the collaborator's plan; empty if unknown
+	Attestation     *Attestation           `protobuf:"bytes,4,opt,name=attestation,proto3" json:"attestation,omitempty"`                                // Evidence of the collaborator's trusted execution environment, if any
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *JoinRequest) Reset() {
@@ -66,27 +235,52 @@ func (x *JoinRequest) GetCollaboratorId() string {
 	return ""
 }
 
-type JoinResponse struct {
+func (x *JoinRequest) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *JoinRequest) GetPlanFingerprint() string {
+	if x != nil {
+		return x.PlanFingerprint
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetAttestation() *Attestation {
+	if x != nil {
+		return x.Attestation
+	}
+	return nil
+}
+
+// Attestation is evidence that a collaborator runs in a trusted execution
+// environment. It commits to security.AttestationReportData of the
+// collaborator's ID, plan fingerprint and attestation challenge.
+type Attestation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	InitialModel  []byte                 `protobuf:"bytes,1,opt,name=initial_model,json=initialModel,proto3" json:"initial_model,omitempty"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // sgx, sev-snp, nitro or another type the aggregator verifies
+	Evidence      []byte                 `protobuf:"bytes,2,opt,name=evidence,proto3" json:"evidence,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JoinResponse) Reset() {
-	*x = JoinResponse{}
+func (x *Attestation) Reset() {
+	*x = Attestation{}
 	mi := &file_api_federation_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JoinResponse) String() string {
+func (x *Attestation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JoinResponse) ProtoMessage() {}
+func (*Attestation) ProtoMessage() {}
 
-func (x *JoinResponse) ProtoReflect() protoreflect.Message {
+func (x *Attestation) ProtoReflect() protoreflect.Message {
 	mi := &file_api_federation_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -98,40 +292,46 @@ func (x *JoinResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JoinResponse.ProtoReflect.Descriptor instead.
-func (*JoinResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Attestation.ProtoReflect.Descriptor instead.
+func (*Attestation) Descriptor() ([]byte, []int) {
 	return file_api_federation_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *JoinResponse) GetInitialModel() []byte {
+func (x *Attestation) GetType() string {
 	if x != nil {
-		return x.InitialModel
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Attestation) GetEvidence() []byte {
+	if x != nil {
+		return x.Evidence
 	}
 	return nil
 }
 
-type ModelUpdate struct {
+type AttestationChallengeRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
-	ModelWeights   []byte                 `protobuf:"bytes,2,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ModelUpdate) Reset() {
-	*x = ModelUpdate{}
+func (x *AttestationChallengeRequest) Reset() {
+	*x = AttestationChallengeRequest{}
 	mi := &file_api_federation_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModelUpdate) String() string {
+func (x *AttestationChallengeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModelUpdate) ProtoMessage() {}
+func (*AttestationChallengeRequest) ProtoMessage() {}
 
-func (x *ModelUpdate) ProtoReflect() protoreflect.Message {
+func (x *AttestationChallengeRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_federation_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -143,46 +343,42 @@ func (x *ModelUpdate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModelUpdate.ProtoReflect.Descriptor instead.
-func (*ModelUpdate) Descriptor() ([]byte, []int) {
+// Deprecated: Use AttestationChallengeRequest.ProtoReflect.Descriptor instead.
+func (*AttestationChallengeRequest) Descriptor() ([]byte, []int) {
 	return file_api_federation_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ModelUpdate) GetCollaboratorId() string {
+func (x *AttestationChallengeRequest) GetCollaboratorId() string {
 	if x != nil {
 		return x.CollaboratorId
 	}
 	return ""
 }
 
-func (x *ModelUpdate) GetModelWeights() []byte {
-	if x != nil {
-		return x.ModelWeights
-	}
-	return nil
-}
-
-type Ack struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// AttestationChallenge is a single-use nonce issued to a collaborator before
+// it joins, so that its evidence cannot be replayed
+type AttestationChallenge struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Nonce            []byte                 `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	ExpiresInSeconds int32                  `protobuf:"varint,2,opt,name=expires_in_seconds,json=expiresInSeconds,proto3" json:"expires_in_seconds,omitempty"` // The nonce is only accepted by joins within this time
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *Ack) Reset() {
-	*x = Ack{}
+func (x *AttestationChallenge) Reset() {
+	*x = AttestationChallenge{}
 	mi := &file_api_federation_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Ack) String() string {
+func (x *AttestationChallenge) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ack) ProtoMessage() {}
+func (*AttestationChallenge) ProtoMessage() {}
 
-func (x *Ack) ProtoReflect() protoreflect.Message {
+func (x *AttestationChallenge) ProtoReflect() protoreflect.Message {
 	mi := &file_api_federation_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -194,39 +390,48 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
-func (*Ack) Descriptor() ([]byte, []int) {
+// Deprecated: Use AttestationChallenge.ProtoReflect.Descriptor instead.
+func (*AttestationChallenge) Descriptor() ([]byte, []int) {
 	return file_api_federation_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Ack) GetSuccess() bool {
+func (x *AttestationChallenge) GetNonce() []byte {
 	if x != nil {
-		return x.Success
+		return x.Nonce
 	}
-	return false
+	return nil
 }
 
-type GetModelRequest struct {
+func (x *AttestationChallenge) GetExpiresInSeconds() int32 {
+	if x != nil {
+		return x.ExpiresInSeconds
+	}
+	return 0
+}
+
+type JoinResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	InitialModel   []byte                 `protobuf:"bytes,1,opt,name=initial_model,json=initialModel,proto3" json:"initial_model,omitempty"`
+	Capabilities   *Capabilities          `protobuf:"bytes,2,opt,name=capabilities,proto3" json:"capabilities,omitempty"`                           // Negotiated feature set both sides use
+	ModelSignature []byte                 `protobuf:"bytes,3,opt,name=model_signature,json=modelSignature,proto3" json:"model_signature,omitempty"` // Aggregator's signature of initial_model's hash, with security.model_signing
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *GetModelRequest) Reset() {
-	*x = GetModelRequest{}
+func (x *JoinResponse) Reset() {
+	*x = JoinResponse{}
 	mi := &file_api_federation_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetModelRequest) String() string {
+func (x *JoinResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetModelRequest) ProtoMessage() {}
+func (*JoinResponse) ProtoMessage() {}
 
-func (x *GetModelRequest) ProtoReflect() protoreflect.Message {
+func (x *JoinResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_federation_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -238,40 +443,58 @@ func (x *GetModelRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetModelRequest.ProtoReflect.Descriptor instead.
-func (*GetModelRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use JoinResponse.ProtoReflect.Descriptor instead.
+func (*JoinResponse) Descriptor() ([]byte, []int) {
 	return file_api_federation_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GetModelRequest) GetCollaboratorId() string {
+func (x *JoinResponse) GetInitialModel() []byte {
 	if x != nil {
-		return x.CollaboratorId
+		return x.InitialModel
 	}
-	return ""
+	return nil
 }
 
-type GetModelResponse struct {
+func (x *JoinResponse) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *JoinResponse) GetModelSignature() []byte {
+	if x != nil {
+		return x.ModelSignature
+	}
+	return nil
+}
+
+// Capabilities describe what a federation process supports. On join, the
+// aggregator answers with the subset both sides support.
+type Capabilities struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ModelWeights  []byte                 `protobuf:"bytes,1,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
-	CurrentRound  int32                  `protobuf:"varint,2,opt,name=current_round,json=currentRound,proto3" json:"current_round,omitempty"`
+	ProtoVersion  int32                  `protobuf:"varint,1,opt,name=proto_version,json=protoVersion,proto3" json:"proto_version,omitempty"`
+	Compression   []string               `protobuf:"bytes,2,rep,name=compression,proto3" json:"compression,omitempty"`      // Supported update codecs, most preferred first
+	Features      []string               `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"`            // Optional protocol features such as task_dispatch
+	HasGpu        bool                   `protobuf:"varint,4,opt,name=has_gpu,json=hasGpu,proto3" json:"has_gpu,omitempty"` // The process's host has an NVIDIA GPU; not negotiated
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetModelResponse) Reset() {
-	*x = GetModelResponse{}
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
 	mi := &file_api_federation_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetModelResponse) String() string {
+func (x *Capabilities) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetModelResponse) ProtoMessage() {}
+func (*Capabilities) ProtoMessage() {}
 
-func (x *GetModelResponse) ProtoReflect() protoreflect.Message {
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
 	mi := &file_api_federation_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -283,49 +506,1020 @@ func (x *GetModelResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetModelResponse.ProtoReflect.Descriptor instead.
-func (*GetModelResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
 	return file_api_federation_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetModelResponse) GetModelWeights() []byte {
+func (x *Capabilities) GetProtoVersion() int32 {
+	if x != nil {
+		return x.ProtoVersion
+	}
+	return 0
+}
+
+func (x *Capabilities) GetCompression() []string {
+	if x != nil {
+		return x.Compression
+	}
+	return nil
+}
+
+func (x *Capabilities) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Capabilities) GetHasGpu() bool {
+	if x != nil {
+		return x.HasGpu
+	}
+	return false
+}
+
+type ModelUpdate struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	ModelWeights   []byte                 `protobuf:"bytes,2,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
+	NumSamples     int64                  `protobuf:"varint,3,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`
+	ImageDigest    string                 `protobuf:"bytes,4,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`                                                  // Digest of the container image that trained the update, if any
+	Privacy        *LocalPrivacy          `protobuf:"bytes,5,opt,name=privacy,proto3" json:"privacy,omitempty"`                                                                             // Local differential privacy applied to the update, if any
+	Round          int32                  `protobuf:"varint,6,opt,name=round,proto3" json:"round,omitempty"`                                                                                // Round the update was trained for; 0 if unknown
+	BaseModelHash  string                 `protobuf:"bytes,7,opt,name=base_model_hash,json=baseModelHash,proto3" json:"base_model_hash,omitempty"`                                          // Hex SHA-256 of the model the update was trained on
+	Delta          bool                   `protobuf:"varint,8,opt,name=delta,proto3" json:"delta,omitempty"`                                                                                // model_weights are the diff from the model with base_model_hash (delta feature)
+	Metrics        map[string]float64     `protobuf:"bytes,9,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Local training metrics of the update, such as loss and accuracy
+	LocalSteps     int64                  `protobuf:"varint,10,opt,name=local_steps,json=localSteps,proto3" json:"local_steps,omitempty"`                                                   // Local optimizer steps the update was trained with; 0 if unknown
+	Encrypted      bool                   `protobuf:"varint,11,opt,name=encrypted,proto3" json:"encrypted,omitempty"`                                                                       // model_weights are Paillier ciphertexts of the weights times num_samples (encrypted_updates feature)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ModelUpdate) Reset() {
+	*x = ModelUpdate{}
+	mi := &file_api_federation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelUpdate) ProtoMessage() {}
+
+func (x *ModelUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelUpdate.ProtoReflect.Descriptor instead.
+func (*ModelUpdate) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ModelUpdate) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+func (x *ModelUpdate) GetModelWeights() []byte {
 	if x != nil {
 		return x.ModelWeights
 	}
 	return nil
 }
 
-func (x *GetModelResponse) GetCurrentRound() int32 {
+func (x *ModelUpdate) GetNumSamples() int64 {
 	if x != nil {
-		return x.CurrentRound
+		return x.NumSamples
 	}
 	return 0
 }
 
-var File_api_federation_proto protoreflect.FileDescriptor
+func (x *ModelUpdate) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
 
-const file_api_federation_proto_rawDesc = "" +
-	"\n" +
-	"\x14api/federation.proto\x12\n" +
-	"federation\"6\n" +
-	"\vJoinRequest\x12'\n" +
-	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\"3\n" +
-	"\fJoinResponse\x12#\n" +
-	"\rinitial_model\x18\x01 \x01(\fR\finitialModel\"[\n" +
-	"\vModelUpdate\x12'\n" +
-	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\x12#\n" +
-	"\rmodel_weights\x18\x02 \x01(\fR\fmodelWeights\"\x1f\n" +
+func (x *ModelUpdate) GetPrivacy() *LocalPrivacy {
+	if x != nil {
+		return x.Privacy
+	}
+	return nil
+}
+
+func (x *ModelUpdate) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ModelUpdate) GetBaseModelHash() string {
+	if x != nil {
+		return x.BaseModelHash
+	}
+	return ""
+}
+
+func (x *ModelUpdate) GetDelta() bool {
+	if x != nil {
+		return x.Delta
+	}
+	return false
+}
+
+func (x *ModelUpdate) GetMetrics() map[string]float64 {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *ModelUpdate) GetLocalSteps() int64 {
+	if x != nil {
+		return x.LocalSteps
+	}
+	return 0
+}
+
+func (x *ModelUpdate) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+// LocalPrivacy are the local differential privacy parameters a collaborator
+// applied to its update before submitting it
+type LocalPrivacy struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ClipNorm        float64                `protobuf:"fixed64,1,opt,name=clip_norm,json=clipNorm,proto3" json:"clip_norm,omitempty"`
+	NoiseMultiplier float64                `protobuf:"fixed64,2,opt,name=noise_multiplier,json=noiseMultiplier,proto3" json:"noise_multiplier,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LocalPrivacy) Reset() {
+	*x = LocalPrivacy{}
+	mi := &file_api_federation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocalPrivacy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalPrivacy) ProtoMessage() {}
+
+func (x *LocalPrivacy) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalPrivacy.ProtoReflect.Descriptor instead.
+func (*LocalPrivacy) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LocalPrivacy) GetClipNorm() float64 {
+	if x != nil {
+		return x.ClipNorm
+	}
+	return 0
+}
+
+func (x *LocalPrivacy) GetNoiseMultiplier() float64 {
+	if x != nil {
+		return x.NoiseMultiplier
+	}
+	return 0
+}
+
+// PartialAggregate is the weighted average of the updates an edge aggregator
+// received in a round, forwarded to the root aggregator
+type PartialAggregate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AggregatorId  string                 `protobuf:"bytes,1,opt,name=aggregator_id,json=aggregatorId,proto3" json:"aggregator_id,omitempty"`
+	ModelWeights  []byte                 `protobuf:"bytes,2,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
+	NumSamples    int64                  `protobuf:"varint,3,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`
+	NumUpdates    int32                  `protobuf:"varint,4,opt,name=num_updates,json=numUpdates,proto3" json:"num_updates,omitempty"`
+	Round         int32                  `protobuf:"varint,5,opt,name=round,proto3" json:"round,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartialAggregate) Reset() {
+	*x = PartialAggregate{}
+	mi := &file_api_federation_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartialAggregate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialAggregate) ProtoMessage() {}
+
+func (x *PartialAggregate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialAggregate.ProtoReflect.Descriptor instead.
+func (*PartialAggregate) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PartialAggregate) GetAggregatorId() string {
+	if x != nil {
+		return x.AggregatorId
+	}
+	return ""
+}
+
+func (x *PartialAggregate) GetModelWeights() []byte {
+	if x != nil {
+		return x.ModelWeights
+	}
+	return nil
+}
+
+func (x *PartialAggregate) GetNumSamples() int64 {
+	if x != nil {
+		return x.NumSamples
+	}
+	return 0
+}
+
+func (x *PartialAggregate) GetNumUpdates() int32 {
+	if x != nil {
+		return x.NumUpdates
+	}
+	return 0
+}
+
+func (x *PartialAggregate) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+type Ack struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // Why the request was rejected
+	Status            AckStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=federation.AckStatus" json:"status,omitempty"`
+	RetryAfterSeconds int32                  `protobuf:"varint,4,opt,name=retry_after_seconds,json=retryAfterSeconds,proto3" json:"retry_after_seconds,omitempty"` // Wait before resending, with ACK_RETRY_AFTER
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	mi := &file_api_federation_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Ack) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *Ack) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Ack) GetStatus() AckStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AckStatus_ACK_OK
+}
+
+func (x *Ack) GetRetryAfterSeconds() int32 {
+	if x != nil {
+		return x.RetryAfterSeconds
+	}
+	return 0
+}
+
+type GetModelRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	KnownModelHash string                 `protobuf:"bytes,2,opt,name=known_model_hash,json=knownModelHash,proto3" json:"known_model_hash,omitempty"` // Hex SHA-256 of the model the collaborator has; with the delta feature the answer may be a diff from it
+	IfVersion      string                 `protobuf:"bytes,3,opt,name=if_version,json=ifVersion,proto3" json:"if_version,omitempty"`                  // model_hash of the model the collaborator has cached; the answer is not_modified while it is current
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetModelRequest) Reset() {
+	*x = GetModelRequest{}
+	mi := &file_api_federation_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetModelRequest) ProtoMessage() {}
+
+func (x *GetModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetModelRequest.ProtoReflect.Descriptor instead.
+func (*GetModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetModelRequest) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+func (x *GetModelRequest) GetKnownModelHash() string {
+	if x != nil {
+		return x.KnownModelHash
+	}
+	return ""
+}
+
+func (x *GetModelRequest) GetIfVersion() string {
+	if x != nil {
+		return x.IfVersion
+	}
+	return ""
+}
+
+type GetModelResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ModelWeights   []byte                 `protobuf:"bytes,1,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
+	CurrentRound   int32                  `protobuf:"varint,2,opt,name=current_round,json=currentRound,proto3" json:"current_round,omitempty"`
+	BaseModelHash  string                 `protobuf:"bytes,3,opt,name=base_model_hash,json=baseModelHash,proto3" json:"base_model_hash,omitempty"`  // Set when model_weights are the diff from this model rather than the model itself
+	ModelHash      string                 `protobuf:"bytes,4,opt,name=model_hash,json=modelHash,proto3" json:"model_hash,omitempty"`                // Hex SHA-256 of the model, its version for if_version
+	ModelSignature []byte                 `protobuf:"bytes,5,opt,name=model_signature,json=modelSignature,proto3" json:"model_signature,omitempty"` // Aggregator's signature of the full model's hash, with security.model_signing
+	NotModified    bool                   `protobuf:"varint,6,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`         // The model named by if_version is current; model_weights and model_signature are unset
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetModelResponse) Reset() {
+	*x = GetModelResponse{}
+	mi := &file_api_federation_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetModelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetModelResponse) ProtoMessage() {}
+
+func (x *GetModelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetModelResponse.ProtoReflect.Descriptor instead.
+func (*GetModelResponse) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetModelResponse) GetModelWeights() []byte {
+	if x != nil {
+		return x.ModelWeights
+	}
+	return nil
+}
+
+func (x *GetModelResponse) GetCurrentRound() int32 {
+	if x != nil {
+		return x.CurrentRound
+	}
+	return 0
+}
+
+func (x *GetModelResponse) GetBaseModelHash() string {
+	if x != nil {
+		return x.BaseModelHash
+	}
+	return ""
+}
+
+func (x *GetModelResponse) GetModelHash() string {
+	if x != nil {
+		return x.ModelHash
+	}
+	return ""
+}
+
+func (x *GetModelResponse) GetModelSignature() []byte {
+	if x != nil {
+		return x.ModelSignature
+	}
+	return nil
+}
+
+func (x *GetModelResponse) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+type GetTaskRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_api_federation_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetTaskRequest) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+// Task is assigned by the aggregator in answer to GetTask
+type Task struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            TaskType               `protobuf:"varint,1,opt,name=type,proto3,enum=federation.TaskType" json:"type,omitempty"`
+	Round           int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	ModelWeights    []byte                 `protobuf:"bytes,3,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"`
+	Hyperparameters map[string]string      `protobuf:"bytes,4,rep,name=hyperparameters,proto3" json:"hyperparameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Passed to the task as --kebab-case args, overriding the plan's
+	SleepSeconds    int32                  `protobuf:"varint,5,opt,name=sleep_seconds,json=sleepSeconds,proto3" json:"sleep_seconds,omitempty"`
+	ModelSignature  []byte                 `protobuf:"bytes,6,opt,name=model_signature,json=modelSignature,proto3" json:"model_signature,omitempty"` // Aggregator's signature of model_weights' hash, with security.model_signing
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_api_federation_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Task) GetType() TaskType {
+	if x != nil {
+		return x.Type
+	}
+	return TaskType_TASK_SLEEP
+}
+
+func (x *Task) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Task) GetModelWeights() []byte {
+	if x != nil {
+		return x.ModelWeights
+	}
+	return nil
+}
+
+func (x *Task) GetHyperparameters() map[string]string {
+	if x != nil {
+		return x.Hyperparameters
+	}
+	return nil
+}
+
+func (x *Task) GetSleepSeconds() int32 {
+	if x != nil {
+		return x.SleepSeconds
+	}
+	return 0
+}
+
+func (x *Task) GetModelSignature() []byte {
+	if x != nil {
+		return x.ModelSignature
+	}
+	return nil
+}
+
+// TaskResult is the outcome of a task assigned by GetTask
+type TaskResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	Type           TaskType               `protobuf:"varint,2,opt,name=type,proto3,enum=federation.TaskType" json:"type,omitempty"`
+	Round          int32                  `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+	ModelWeights   []byte                 `protobuf:"bytes,4,opt,name=model_weights,json=modelWeights,proto3" json:"model_weights,omitempty"` // Updated model of a train task
+	NumSamples     int64                  `protobuf:"varint,5,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`
+	Metrics        map[string]float64     `protobuf:"bytes,6,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Metrics of an evaluate task, or the local training metrics of a train task
+	ImageDigest    string                 `protobuf:"bytes,7,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	Privacy        *LocalPrivacy          `protobuf:"bytes,8,opt,name=privacy,proto3" json:"privacy,omitempty"`
+	BaseModelHash  string                 `protobuf:"bytes,9,opt,name=base_model_hash,json=baseModelHash,proto3" json:"base_model_hash,omitempty"` // Hex SHA-256 of the model a train task started from
+	LocalSteps     int64                  `protobuf:"varint,10,opt,name=local_steps,json=localSteps,proto3" json:"local_steps,omitempty"`          // Local optimizer steps of a train task; 0 if unknown
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TaskResult) Reset() {
+	*x = TaskResult{}
+	mi := &file_api_federation_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResult) ProtoMessage() {}
+
+func (x *TaskResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TaskResult) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+func (x *TaskResult) GetType() TaskType {
+	if x != nil {
+		return x.Type
+	}
+	return TaskType_TASK_SLEEP
+}
+
+func (x *TaskResult) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *TaskResult) GetModelWeights() []byte {
+	if x != nil {
+		return x.ModelWeights
+	}
+	return nil
+}
+
+func (x *TaskResult) GetNumSamples() int64 {
+	if x != nil {
+		return x.NumSamples
+	}
+	return 0
+}
+
+func (x *TaskResult) GetMetrics() map[string]float64 {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *TaskResult) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *TaskResult) GetPrivacy() *LocalPrivacy {
+	if x != nil {
+		return x.Privacy
+	}
+	return nil
+}
+
+func (x *TaskResult) GetBaseModelHash() string {
+	if x != nil {
+		return x.BaseModelHash
+	}
+	return ""
+}
+
+func (x *TaskResult) GetLocalSteps() int64 {
+	if x != nil {
+		return x.LocalSteps
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollaboratorId string                 `protobuf:"bytes,1,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_api_federation_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchRequest) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+// FederationEvent is sent by the aggregator over WatchEvents
+type FederationEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=federation.EventType" json:"type,omitempty"`
+	Round           int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	ModelHash       string                 `protobuf:"bytes,3,opt,name=model_hash,json=modelHash,proto3" json:"model_hash,omitempty"`                                                                      // Hex SHA-256 of the current global model
+	Hyperparameters map[string]string      `protobuf:"bytes,4,rep,name=hyperparameters,proto3" json:"hyperparameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Hyperparameters of the round, when set by a schedule
+	Message         string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FederationEvent) Reset() {
+	*x = FederationEvent{}
+	mi := &file_api_federation_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FederationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FederationEvent) ProtoMessage() {}
+
+func (x *FederationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_federation_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FederationEvent.ProtoReflect.Descriptor instead.
+func (*FederationEvent) Descriptor() ([]byte, []int) {
+	return file_api_federation_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FederationEvent) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_ROUND_STARTED
+}
+
+func (x *FederationEvent) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *FederationEvent) GetModelHash() string {
+	if x != nil {
+		return x.ModelHash
+	}
+	return ""
+}
+
+func (x *FederationEvent) GetHyperparameters() map[string]string {
+	if x != nil {
+		return x.Hyperparameters
+	}
+	return nil
+}
+
+func (x *FederationEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_api_federation_proto protoreflect.FileDescriptor
+
+const file_api_federation_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/federation.proto\x12\n" +
+	"federation\"\xda\x01\n" +
+	"\vJoinRequest\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\x12<\n" +
+	"\fcapabilities\x18\x02 \x01(\v2\x18.federation.CapabilitiesR\fcapabilities\x12)\n" +
+	"\x10plan_fingerprint\x18\x03 \x01(\tR\x0fplanFingerprint\x129\n" +
+	"\vattestation\x18\x04 \x01(\v2\x17.federation.AttestationR\vattestation\"=\n" +
+	"\vAttestation\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1a\n" +
+	"\bevidence\x18\x02 \x01(\fR\bevidence\"F\n" +
+	"\x1bAttestationChallengeRequest\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\"Z\n" +
+	"\x14AttestationChallenge\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\fR\x05nonce\x12,\n" +
+	"\x12expires_in_seconds\x18\x02 \x01(\x05R\x10expiresInSeconds\"\x9a\x01\n" +
+	"\fJoinResponse\x12#\n" +
+	"\rinitial_model\x18\x01 \x01(\fR\finitialModel\x12<\n" +
+	"\fcapabilities\x18\x02 \x01(\v2\x18.federation.CapabilitiesR\fcapabilities\x12'\n" +
+	"\x0fmodel_signature\x18\x03 \x01(\fR\x0emodelSignature\"\x8a\x01\n" +
+	"\fCapabilities\x12#\n" +
+	"\rproto_version\x18\x01 \x01(\x05R\fprotoVersion\x12 \n" +
+	"\vcompression\x18\x02 \x03(\tR\vcompression\x12\x1a\n" +
+	"\bfeatures\x18\x03 \x03(\tR\bfeatures\x12\x17\n" +
+	"\ahas_gpu\x18\x04 \x01(\bR\x06hasGpu\"\xe2\x03\n" +
+	"\vModelUpdate\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\x12#\n" +
+	"\rmodel_weights\x18\x02 \x01(\fR\fmodelWeights\x12\x1f\n" +
+	"\vnum_samples\x18\x03 \x01(\x03R\n" +
+	"numSamples\x12!\n" +
+	"\fimage_digest\x18\x04 \x01(\tR\vimageDigest\x122\n" +
+	"\aprivacy\x18\x05 \x01(\v2\x18.federation.LocalPrivacyR\aprivacy\x12\x14\n" +
+	"\x05round\x18\x06 \x01(\x05R\x05round\x12&\n" +
+	"\x0fbase_model_hash\x18\a \x01(\tR\rbaseModelHash\x12\x14\n" +
+	"\x05delta\x18\b \x01(\bR\x05delta\x12>\n" +
+	"\ametrics\x18\t \x03(\v2$.federation.ModelUpdate.MetricsEntryR\ametrics\x12\x1f\n" +
+	"\vlocal_steps\x18\n" +
+	" \x01(\x03R\n" +
+	"localSteps\x12\x1c\n" +
+	"\tencrypted\x18\v \x01(\bR\tencrypted\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"V\n" +
+	"\fLocalPrivacy\x12\x1b\n" +
+	"\tclip_norm\x18\x01 \x01(\x01R\bclipNorm\x12)\n" +
+	"\x10noise_multiplier\x18\x02 \x01(\x01R\x0fnoiseMultiplier\"\xb4\x01\n" +
+	"\x10PartialAggregate\x12#\n" +
+	"\raggregator_id\x18\x01 \x01(\tR\faggregatorId\x12#\n" +
+	"\rmodel_weights\x18\x02 \x01(\fR\fmodelWeights\x12\x1f\n" +
+	"\vnum_samples\x18\x03 \x01(\x03R\n" +
+	"numSamples\x12\x1f\n" +
+	"\vnum_updates\x18\x04 \x01(\x05R\n" +
+	"numUpdates\x12\x14\n" +
+	"\x05round\x18\x05 \x01(\x05R\x05round\"\x98\x01\n" +
 	"\x03Ack\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\":\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12-\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x15.federation.AckStatusR\x06status\x12.\n" +
+	"\x13retry_after_seconds\x18\x04 \x01(\x05R\x11retryAfterSeconds\"\x83\x01\n" +
 	"\x0fGetModelRequest\x12'\n" +
-	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\"\\\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\x12(\n" +
+	"\x10known_model_hash\x18\x02 \x01(\tR\x0eknownModelHash\x12\x1d\n" +
+	"\n" +
+	"if_version\x18\x03 \x01(\tR\tifVersion\"\xef\x01\n" +
 	"\x10GetModelResponse\x12#\n" +
 	"\rmodel_weights\x18\x01 \x01(\fR\fmodelWeights\x12#\n" +
-	"\rcurrent_round\x18\x02 \x01(\x05R\fcurrentRound2\xdf\x01\n" +
+	"\rcurrent_round\x18\x02 \x01(\x05R\fcurrentRound\x12&\n" +
+	"\x0fbase_model_hash\x18\x03 \x01(\tR\rbaseModelHash\x12\x1d\n" +
+	"\n" +
+	"model_hash\x18\x04 \x01(\tR\tmodelHash\x12'\n" +
+	"\x0fmodel_signature\x18\x05 \x01(\fR\x0emodelSignature\x12!\n" +
+	"\fnot_modified\x18\x06 \x01(\bR\vnotModified\"9\n" +
+	"\x0eGetTaskRequest\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\"\xce\x02\n" +
+	"\x04Task\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.federation.TaskTypeR\x04type\x12\x14\n" +
+	"\x05round\x18\x02 \x01(\x05R\x05round\x12#\n" +
+	"\rmodel_weights\x18\x03 \x01(\fR\fmodelWeights\x12O\n" +
+	"\x0fhyperparameters\x18\x04 \x03(\v2%.federation.Task.HyperparametersEntryR\x0fhyperparameters\x12#\n" +
+	"\rsleep_seconds\x18\x05 \x01(\x05R\fsleepSeconds\x12'\n" +
+	"\x0fmodel_signature\x18\x06 \x01(\fR\x0emodelSignature\x1aB\n" +
+	"\x14HyperparametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd6\x03\n" +
+	"\n" +
+	"TaskResult\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\x12(\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x14.federation.TaskTypeR\x04type\x12\x14\n" +
+	"\x05round\x18\x03 \x01(\x05R\x05round\x12#\n" +
+	"\rmodel_weights\x18\x04 \x01(\fR\fmodelWeights\x12\x1f\n" +
+	"\vnum_samples\x18\x05 \x01(\x03R\n" +
+	"numSamples\x12=\n" +
+	"\ametrics\x18\x06 \x03(\v2#.federation.TaskResult.MetricsEntryR\ametrics\x12!\n" +
+	"\fimage_digest\x18\a \x01(\tR\vimageDigest\x122\n" +
+	"\aprivacy\x18\b \x01(\v2\x18.federation.LocalPrivacyR\aprivacy\x12&\n" +
+	"\x0fbase_model_hash\x18\t \x01(\tR\rbaseModelHash\x12\x1f\n" +
+	"\vlocal_steps\x18\n" +
+	" \x01(\x03R\n" +
+	"localSteps\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"7\n" +
+	"\fWatchRequest\x12'\n" +
+	"\x0fcollaborator_id\x18\x01 \x01(\tR\x0ecollaboratorId\"\xab\x02\n" +
+	"\x0fFederationEvent\x12)\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x15.federation.EventTypeR\x04type\x12\x14\n" +
+	"\x05round\x18\x02 \x01(\x05R\x05round\x12\x1d\n" +
+	"\n" +
+	"model_hash\x18\x03 \x01(\tR\tmodelHash\x12Z\n" +
+	"\x0fhyperparameters\x18\x04 \x03(\v20.federation.FederationEvent.HyperparametersEntryR\x0fhyperparameters\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x1aB\n" +
+	"\x14HyperparametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\x9b\x01\n" +
+	"\tAckStatus\x12\n" +
+	"\n" +
+	"\x06ACK_OK\x10\x00\x12\x16\n" +
+	"\x12ACK_REJECTED_STALE\x10\x01\x12\x1f\n" +
+	"\x1bACK_REJECTED_ROUND_MISMATCH\x10\x02\x12\x18\n" +
+	"\x14ACK_REJECTED_INVALID\x10\x03\x12\x13\n" +
+	"\x0fACK_RETRY_AFTER\x10\x04\x12\x1a\n" +
+	"\x16ACK_REJECTED_DUPLICATE\x10\x05*L\n" +
+	"\bTaskType\x12\x0e\n" +
+	"\n" +
+	"TASK_SLEEP\x10\x00\x12\x0e\n" +
+	"\n" +
+	"TASK_TRAIN\x10\x01\x12\x11\n" +
+	"\rTASK_EVALUATE\x10\x02\x12\r\n" +
+	"\tTASK_QUIT\x10\x03*z\n" +
+	"\tEventType\x12\x17\n" +
+	"\x13EVENT_ROUND_STARTED\x10\x00\x12\x1d\n" +
+	"\x19EVENT_FEDERATION_STOPPING\x10\x01\x12\x1b\n" +
+	"\x17EVENT_MODEL_INVALIDATED\x10\x02\x12\x18\n" +
+	"\x14EVENT_CONFIG_CHANGED\x10\x032\xcc\x04\n" +
 	"\x11FederatedLearning\x12C\n" +
 	"\x0eJoinFederation\x12\x17.federation.JoinRequest\x1a\x18.federation.JoinResponse\x128\n" +
 	"\fSubmitUpdate\x12\x17.federation.ModelUpdate\x1a\x0f.federation.Ack\x12K\n" +
-	"\x0eGetLatestModel\x12\x1b.federation.GetModelRequest\x1a\x1c.federation.GetModelResponseB\aZ\x05./apib\x06proto3"
+	"\x0eGetLatestModel\x12\x1b.federation.GetModelRequest\x1a\x1c.federation.GetModelResponse\x12G\n" +
+	"\x16SubmitPartialAggregate\x12\x1c.federation.PartialAggregate\x1a\x0f.federation.Ack\x127\n" +
+	"\aGetTask\x12\x1a.federation.GetTaskRequest\x1a\x10.federation.Task\x12;\n" +
+	"\x10SubmitTaskResult\x12\x16.federation.TaskResult\x1a\x0f.federation.Ack\x12F\n" +
+	"\vWatchEvents\x12\x18.federation.WatchRequest\x1a\x1b.federation.FederationEvent0\x01\x12d\n" +
+	"\x17GetAttestationChallenge\x12'.federation.AttestationChallengeRequest\x1a .federation.AttestationChallengeB\aZ\x05./apib\x06proto3"
 
 var (
 	file_api_federation_proto_rawDescOnce sync.Once
@@ -339,27 +1533,69 @@ func file_api_federation_proto_rawDescGZIP() []byte {
 	return file_api_federation_proto_rawDescData
 }
 
-var file_api_federation_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_federation_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_api_federation_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_api_federation_proto_goTypes = []any{
-	(*JoinRequest)(nil),      // 0: federation.JoinRequest
-	(*JoinResponse)(nil),     // 1: federation.JoinResponse
-	(*ModelUpdate)(nil),      // 2: federation.ModelUpdate
-	(*Ack)(nil),              // 3: federation.Ack
-	(*GetModelRequest)(nil),  // 4: federation.GetModelRequest
-	(*GetModelResponse)(nil), // 5: federation.GetModelResponse
+	(AckStatus)(0),                      // 0: federation.AckStatus
+	(TaskType)(0),                       // 1: federation.TaskType
+	(EventType)(0),                      // 2: federation.EventType
+	(*JoinRequest)(nil),                 // 3: federation.JoinRequest
+	(*Attestation)(nil),                 // 4: federation.Attestation
+	(*AttestationChallengeRequest)(nil), // 5: federation.AttestationChallengeRequest
+	(*AttestationChallenge)(nil),        // 6: federation.AttestationChallenge
+	(*JoinResponse)(nil),                // 7: federation.JoinResponse
+	(*Capabilities)(nil),                // 8: federation.Capabilities
+	(*ModelUpdate)(nil),                 // 9: federation.ModelUpdate
+	(*LocalPrivacy)(nil),                // 10: federation.LocalPrivacy
+	(*PartialAggregate)(nil),            // 11: federation.PartialAggregate
+	(*Ack)(nil),                         // 12: federation.Ack
+	(*GetModelRequest)(nil),             // 13: federation.GetModelRequest
+	(*GetModelResponse)(nil),            // 14: federation.GetModelResponse
+	(*GetTaskRequest)(nil),              // 15: federation.GetTaskRequest
+	(*Task)(nil),                        // 16: federation.Task
+	(*TaskResult)(nil),                  // 17: federation.TaskResult
+	(*WatchRequest)(nil),                // 18: federation.WatchRequest
+	(*FederationEvent)(nil),             // 19: federation.FederationEvent
+	nil,                                 // 20: federation.ModelUpdate.MetricsEntry
+	nil,                                 // 21: federation.Task.HyperparametersEntry
+	nil,                                 // 22: federation.TaskResult.MetricsEntry
+	nil,                                 // 23: federation.FederationEvent.HyperparametersEntry
 }
 var file_api_federation_proto_depIdxs = []int32{
-	0, // 0: federation.FederatedLearning.JoinFederation:input_type -> federation.JoinRequest
-	2, // 1: federation.FederatedLearning.SubmitUpdate:input_type -> federation.ModelUpdate
-	4, // 2: federation.FederatedLearning.GetLatestModel:input_type -> federation.GetModelRequest
-	1, // 3: federation.FederatedLearning.JoinFederation:output_type -> federation.JoinResponse
-	3, // 4: federation.FederatedLearning.SubmitUpdate:output_type -> federation.Ack
-	5, // 5: federation.FederatedLearning.GetLatestModel:output_type -> federation.GetModelResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	8,  // 0: federation.JoinRequest.capabilities:type_name -> federation.Capabilities
+	4,  // 1: federation.JoinRequest.attestation:type_name -> federation.Attestation
+	8,  // 2: federation.JoinResponse.capabilities:type_name -> federation.Capabilities
+	10, // 3: federation.ModelUpdate.privacy:type_name -> federation.LocalPrivacy
+	20, // 4: federation.ModelUpdate.metrics:type_name -> federation.ModelUpdate.MetricsEntry
+	0,  // 5: federation.Ack.status:type_name -> federation.AckStatus
+	1,  // 6: federation.Task.type:type_name -> federation.TaskType
+	21, // 7: federation.Task.hyperparameters:type_name -> federation.Task.HyperparametersEntry
+	1,  // 8: federation.TaskResult.type:type_name -> federation.TaskType
+	22, // 9: federation.TaskResult.metrics:type_name -> federation.TaskResult.MetricsEntry
+	10, // 10: federation.TaskResult.privacy:type_name -> federation.LocalPrivacy
+	2,  // 11: federation.FederationEvent.type:type_name -> federation.EventType
+	23, // 12: federation.FederationEvent.hyperparameters:type_name -> federation.FederationEvent.HyperparametersEntry
+	3,  // 13: federation.FederatedLearning.JoinFederation:input_type -> federation.JoinRequest
+	9,  // 14: federation.FederatedLearning.SubmitUpdate:input_type -> federation.ModelUpdate
+	13, // 15: federation.FederatedLearning.GetLatestModel:input_type -> federation.GetModelRequest
+	11, // 16: federation.FederatedLearning.SubmitPartialAggregate:input_type -> federation.PartialAggregate
+	15, // 17: federation.FederatedLearning.GetTask:input_type -> federation.GetTaskRequest
+	17, // 18: federation.FederatedLearning.SubmitTaskResult:input_type -> federation.TaskResult
+	18, // 19: federation.FederatedLearning.WatchEvents:input_type -> federation.WatchRequest
+	5,  // 20: federation.FederatedLearning.GetAttestationChallenge:input_type -> federation.AttestationChallengeRequest
+	7,  // 21: federation.FederatedLearning.JoinFederation:output_type -> federation.JoinResponse
+	12, // 22: federation.FederatedLearning.SubmitUpdate:output_type -> federation.Ack
+	14, // 23: federation.FederatedLearning.GetLatestModel:output_type -> federation.GetModelResponse
+	12, // 24: federation.FederatedLearning.SubmitPartialAggregate:output_type -> federation.Ack
+	16, // 25: federation.FederatedLearning.GetTask:output_type -> federation.Task
+	12, // 26: federation.FederatedLearning.SubmitTaskResult:output_type -> federation.Ack
+	19, // 27: federation.FederatedLearning.WatchEvents:output_type -> federation.FederationEvent
+	6,  // 28: federation.FederatedLearning.GetAttestationChallenge:output_type -> federation.AttestationChallenge
+	21, // [21:29] is the sub-list for method output_type
+	13, // [13:21] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_api_federation_proto_init() }
@@ -372,13 +1608,14 @@ func file_api_federation_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_federation_proto_rawDesc), len(file_api_federation_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      3,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_federation_proto_goTypes,
 		DependencyIndexes: file_api_federation_proto_depIdxs,
+		EnumInfos:         file_api_federation_proto_enumTypes,
 		MessageInfos:      file_api_federation_proto_msgTypes,
 	}.Build()
 	File_api_federation_proto = out.File
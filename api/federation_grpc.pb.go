@@ -20,9 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	FederatedLearning_JoinFederation_FullMethodName = "/federation.FederatedLearning/JoinFederation"
-	FederatedLearning_SubmitUpdate_FullMethodName   = "/federation.FederatedLearning/SubmitUpdate"
-	FederatedLearning_GetLatestModel_FullMethodName = "/federation.FederatedLearning/GetLatestModel"
+	FederatedLearning_JoinFederation_FullMethodName          = "/federation.FederatedLearning/JoinFederation"
+	FederatedLearning_SubmitUpdate_FullMethodName            = "/federation.FederatedLearning/SubmitUpdate"
+	FederatedLearning_GetLatestModel_FullMethodName          = "/federation.FederatedLearning/GetLatestModel"
+	FederatedLearning_SubmitPartialAggregate_FullMethodName  = "/federation.FederatedLearning/SubmitPartialAggregate"
+	FederatedLearning_GetTask_FullMethodName                 = "/federation.FederatedLearning/GetTask"
+	FederatedLearning_SubmitTaskResult_FullMethodName        = "/federation.FederatedLearning/SubmitTaskResult"
+	FederatedLearning_WatchEvents_FullMethodName             = "/federation.FederatedLearning/WatchEvents"
+	FederatedLearning_GetAttestationChallenge_FullMethodName = "/federation.FederatedLearning/GetAttestationChallenge"
 )
 
 // FederatedLearningClient is the client API for FederatedLearning service.
@@ -32,6 +37,11 @@ type FederatedLearningClient interface {
 	JoinFederation(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*JoinResponse, error)
 	SubmitUpdate(ctx context.Context, in *ModelUpdate, opts ...grpc.CallOption) (*Ack, error)
 	GetLatestModel(ctx context.Context, in *GetModelRequest, opts ...grpc.CallOption) (*GetModelResponse, error)
+	SubmitPartialAggregate(ctx context.Context, in *PartialAggregate, opts ...grpc.CallOption) (*Ack, error)
+	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error)
+	SubmitTaskResult(ctx context.Context, in *TaskResult, opts ...grpc.CallOption) (*Ack, error)
+	WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FederationEvent], error)
+	GetAttestationChallenge(ctx context.Context, in *AttestationChallengeRequest, opts ...grpc.CallOption) (*AttestationChallenge, error)
 }
 
 type federatedLearningClient struct {
@@ -72,6 +82,65 @@ func (c *federatedLearningClient) GetLatestModel(ctx context.Context, in *GetMod
 	return out, nil
 }
 
+func (c *federatedLearningClient) SubmitPartialAggregate(ctx context.Context, in *PartialAggregate, opts ...grpc.CallOption) (*Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, FederatedLearning_SubmitPartialAggregate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *federatedLearningClient) GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, FederatedLearning_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *federatedLearningClient) SubmitTaskResult(ctx context.Context, in *TaskResult, opts ...grpc.CallOption) (*Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, FederatedLearning_SubmitTaskResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *federatedLearningClient) WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FederationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FederatedLearning_ServiceDesc.Streams[0], FederatedLearning_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, FederationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FederatedLearning_WatchEventsClient = grpc.ServerStreamingClient[FederationEvent]
+
+func (c *federatedLearningClient) GetAttestationChallenge(ctx context.Context, in *AttestationChallengeRequest, opts ...grpc.CallOption) (*AttestationChallenge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttestationChallenge)
+	err := c.cc.Invoke(ctx, FederatedLearning_GetAttestationChallenge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FederatedLearningServer is the server API for FederatedLearning service.
 // All implementations must embed UnimplementedFederatedLearningServer
 // for forward compatibility.
@@ -79,6 +148,11 @@ type FederatedLearningServer interface {
 	JoinFederation(context.Context, *JoinRequest) (*JoinResponse, error)
 	SubmitUpdate(context.Context, *ModelUpdate) (*Ack, error)
 	GetLatestModel(context.Context, *GetModelRequest) (*GetModelResponse, error)
+	SubmitPartialAggregate(context.Context, *PartialAggregate) (*Ack, error)
+	GetTask(context.Context, *GetTaskRequest) (*Task, error)
+	SubmitTaskResult(context.Context, *TaskResult) (*Ack, error)
+	WatchEvents(*WatchRequest, grpc.ServerStreamingServer[FederationEvent]) error
+	GetAttestationChallenge(context.Context, *AttestationChallengeRequest) (*AttestationChallenge, error)
 	mustEmbedUnimplementedFederatedLearningServer()
 }
 
@@ -98,6 +172,21 @@ func (UnimplementedFederatedLearningServer) SubmitUpdate(context.Context, *Model
 func (UnimplementedFederatedLearningServer) GetLatestModel(context.Context, *GetModelRequest) (*GetModelResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestModel not implemented")
 }
+func (UnimplementedFederatedLearningServer) SubmitPartialAggregate(context.Context, *PartialAggregate) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitPartialAggregate not implemented")
+}
+func (UnimplementedFederatedLearningServer) GetTask(context.Context, *GetTaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedFederatedLearningServer) SubmitTaskResult(context.Context, *TaskResult) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTaskResult not implemented")
+}
+func (UnimplementedFederatedLearningServer) WatchEvents(*WatchRequest, grpc.ServerStreamingServer[FederationEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedFederatedLearningServer) GetAttestationChallenge(context.Context, *AttestationChallengeRequest) (*AttestationChallenge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttestationChallenge not implemented")
+}
 func (UnimplementedFederatedLearningServer) mustEmbedUnimplementedFederatedLearningServer() {}
 func (UnimplementedFederatedLearningServer) testEmbeddedByValue()                           {}
 
@@ -173,6 +262,89 @@ func _FederatedLearning_GetLatestModel_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FederatedLearning_SubmitPartialAggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PartialAggregate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FederatedLearningServer).SubmitPartialAggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FederatedLearning_SubmitPartialAggregate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FederatedLearningServer).SubmitPartialAggregate(ctx, req.(*PartialAggregate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FederatedLearning_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FederatedLearningServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FederatedLearning_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FederatedLearningServer).GetTask(ctx, req.(*GetTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FederatedLearning_SubmitTaskResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskResult)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FederatedLearningServer).SubmitTaskResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FederatedLearning_SubmitTaskResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FederatedLearningServer).SubmitTaskResult(ctx, req.(*TaskResult))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FederatedLearning_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FederatedLearningServer).WatchEvents(m, &grpc.GenericServerStream[WatchRequest, FederationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FederatedLearning_WatchEventsServer = grpc.ServerStreamingServer[FederationEvent]
+
+func _FederatedLearning_GetAttestationChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttestationChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FederatedLearningServer).GetAttestationChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FederatedLearning_GetAttestationChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FederatedLearningServer).GetAttestationChallenge(ctx, req.(*AttestationChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FederatedLearning_ServiceDesc is the grpc.ServiceDesc for FederatedLearning service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -192,7 +364,29 @@ var FederatedLearning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLatestModel",
 			Handler:    _FederatedLearning_GetLatestModel_Handler,
 		},
+		{
+			MethodName: "SubmitPartialAggregate",
+			Handler:    _FederatedLearning_SubmitPartialAggregate_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _FederatedLearning_GetTask_Handler,
+		},
+		{
+			MethodName: "SubmitTaskResult",
+			Handler:    _FederatedLearning_SubmitTaskResult_Handler,
+		},
+		{
+			MethodName: "GetAttestationChallenge",
+			Handler:    _FederatedLearning_GetAttestationChallenge_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _FederatedLearning_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/federation.proto",
 }
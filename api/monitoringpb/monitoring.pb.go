@@ -0,0 +1,1039 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v5.29.3
+// source: api/monitoring.proto
+
+package monitoringpb
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Round struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FederationId     string                 `protobuf:"bytes,2,opt,name=federation_id,json=federationId,proto3" json:"federation_id,omitempty"`
+	RoundNumber      int32                  `protobuf:"varint,3,opt,name=round_number,json=roundNumber,proto3" json:"round_number,omitempty"`
+	Algorithm        string                 `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	StartTime        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"` // Set when the round finished
+	Duration         *durationpb.Duration   `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	ParticipantCount int32                  `protobuf:"varint,8,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	UpdatesReceived  int32                  `protobuf:"varint,9,opt,name=updates_received,json=updatesReceived,proto3" json:"updates_received,omitempty"`
+	AggregationTime  *durationpb.Duration   `protobuf:"bytes,10,opt,name=aggregation_time,json=aggregationTime,proto3" json:"aggregation_time,omitempty"`
+	ModelAccuracy    *float64               `protobuf:"fixed64,11,opt,name=model_accuracy,json=modelAccuracy,proto3,oneof" json:"model_accuracy,omitempty"`
+	ModelLoss        *float64               `protobuf:"fixed64,12,opt,name=model_loss,json=modelLoss,proto3,oneof" json:"model_loss,omitempty"`
+	ConvergenceRate  *float64               `protobuf:"fixed64,13,opt,name=convergence_rate,json=convergenceRate,proto3,oneof" json:"convergence_rate,omitempty"`
+	Status           string                 `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"`
+	Hyperparameters  *structpb.Struct       `protobuf:"bytes,15,opt,name=hyperparameters,proto3" json:"hyperparameters,omitempty"` // Train task args used in the round
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Round) Reset() {
+	*x = Round{}
+	mi := &file_api_monitoring_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Round) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Round) ProtoMessage() {}
+
+func (x *Round) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Round.ProtoReflect.Descriptor instead.
+func (*Round) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Round) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Round) GetFederationId() string {
+	if x != nil {
+		return x.FederationId
+	}
+	return ""
+}
+
+func (x *Round) GetRoundNumber() int32 {
+	if x != nil {
+		return x.RoundNumber
+	}
+	return 0
+}
+
+func (x *Round) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *Round) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Round) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Round) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Round) GetParticipantCount() int32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
+}
+
+func (x *Round) GetUpdatesReceived() int32 {
+	if x != nil {
+		return x.UpdatesReceived
+	}
+	return 0
+}
+
+func (x *Round) GetAggregationTime() *durationpb.Duration {
+	if x != nil {
+		return x.AggregationTime
+	}
+	return nil
+}
+
+func (x *Round) GetModelAccuracy() float64 {
+	if x != nil && x.ModelAccuracy != nil {
+		return *x.ModelAccuracy
+	}
+	return 0
+}
+
+func (x *Round) GetModelLoss() float64 {
+	if x != nil && x.ModelLoss != nil {
+		return *x.ModelLoss
+	}
+	return 0
+}
+
+func (x *Round) GetConvergenceRate() float64 {
+	if x != nil && x.ConvergenceRate != nil {
+		return *x.ConvergenceRate
+	}
+	return 0
+}
+
+func (x *Round) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Round) GetHyperparameters() *structpb.Struct {
+	if x != nil {
+		return x.Hyperparameters
+	}
+	return nil
+}
+
+type Update struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FederationId      string                 `protobuf:"bytes,2,opt,name=federation_id,json=federationId,proto3" json:"federation_id,omitempty"`
+	CollaboratorId    string                 `protobuf:"bytes,3,opt,name=collaborator_id,json=collaboratorId,proto3" json:"collaborator_id,omitempty"`
+	RoundNumber       int32                  `protobuf:"varint,4,opt,name=round_number,json=roundNumber,proto3" json:"round_number,omitempty"`
+	Timestamp         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	UpdateSizeBytes   int64                  `protobuf:"varint,6,opt,name=update_size_bytes,json=updateSizeBytes,proto3" json:"update_size_bytes,omitempty"`
+	NumSamples        int64                  `protobuf:"varint,7,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`
+	ProcessingTimeMs  float64                `protobuf:"fixed64,8,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"`
+	Staleness         int32                  `protobuf:"varint,9,opt,name=staleness,proto3" json:"staleness,omitempty"`
+	Weight            float64                `protobuf:"fixed64,10,opt,name=weight,proto3" json:"weight,omitempty"`
+	QualityScore      *float64               `protobuf:"fixed64,11,opt,name=quality_score,json=qualityScore,proto3,oneof" json:"quality_score,omitempty"`
+	CompressionRatio  *float64               `protobuf:"fixed64,12,opt,name=compression_ratio,json=compressionRatio,proto3,oneof" json:"compression_ratio,omitempty"`
+	ImageDigest       string                 `protobuf:"bytes,13,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	DpClipNorm        float64                `protobuf:"fixed64,14,opt,name=dp_clip_norm,json=dpClipNorm,proto3" json:"dp_clip_norm,omitempty"`
+	DpNoiseMultiplier float64                `protobuf:"fixed64,15,opt,name=dp_noise_multiplier,json=dpNoiseMultiplier,proto3" json:"dp_noise_multiplier,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Update) Reset() {
+	*x = Update{}
+	mi := &file_api_monitoring_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Update) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Update) ProtoMessage() {}
+
+func (x *Update) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Update.ProtoReflect.Descriptor instead.
+func (*Update) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Update) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Update) GetFederationId() string {
+	if x != nil {
+		return x.FederationId
+	}
+	return ""
+}
+
+func (x *Update) GetCollaboratorId() string {
+	if x != nil {
+		return x.CollaboratorId
+	}
+	return ""
+}
+
+func (x *Update) GetRoundNumber() int32 {
+	if x != nil {
+		return x.RoundNumber
+	}
+	return 0
+}
+
+func (x *Update) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Update) GetUpdateSizeBytes() int64 {
+	if x != nil {
+		return x.UpdateSizeBytes
+	}
+	return 0
+}
+
+func (x *Update) GetNumSamples() int64 {
+	if x != nil {
+		return x.NumSamples
+	}
+	return 0
+}
+
+func (x *Update) GetProcessingTimeMs() float64 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *Update) GetStaleness() int32 {
+	if x != nil {
+		return x.Staleness
+	}
+	return 0
+}
+
+func (x *Update) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Update) GetQualityScore() float64 {
+	if x != nil && x.QualityScore != nil {
+		return *x.QualityScore
+	}
+	return 0
+}
+
+func (x *Update) GetCompressionRatio() float64 {
+	if x != nil && x.CompressionRatio != nil {
+		return *x.CompressionRatio
+	}
+	return 0
+}
+
+func (x *Update) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *Update) GetDpClipNorm() float64 {
+	if x != nil {
+		return x.DpClipNorm
+	}
+	return 0
+}
+
+func (x *Update) GetDpNoiseMultiplier() float64 {
+	if x != nil {
+		return x.DpNoiseMultiplier
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FederationId  string                 `protobuf:"bytes,2,opt,name=federation_id,json=federationId,proto3" json:"federation_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // Metric type, such as round or training
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Source        string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"` // Aggregator or collaborator ID
+	Level         string                 `protobuf:"bytes,6,opt,name=level,proto3" json:"level,omitempty"`   // info, warning or error
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,8,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_api_monitoring_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetFederationId() string {
+	if x != nil {
+		return x.FederationId
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *Event) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Event) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Resources is a sample of the resource usage of a host
+type Resources struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Source                string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // Component that reports the usage
+	Timestamp             *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CpuUsagePercent       float64                `protobuf:"fixed64,3,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"`
+	MemoryUsagePercent    float64                `protobuf:"fixed64,4,opt,name=memory_usage_percent,json=memoryUsagePercent,proto3" json:"memory_usage_percent,omitempty"`
+	MemoryUsedBytes       int64                  `protobuf:"varint,5,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
+	MemoryTotalBytes      int64                  `protobuf:"varint,6,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memory_total_bytes,omitempty"`
+	DiskUsagePercent      float64                `protobuf:"fixed64,7,opt,name=disk_usage_percent,json=diskUsagePercent,proto3" json:"disk_usage_percent,omitempty"`
+	NetworkRxRateMbps     float64                `protobuf:"fixed64,8,opt,name=network_rx_rate_mbps,json=networkRxRateMbps,proto3" json:"network_rx_rate_mbps,omitempty"`
+	NetworkTxRateMbps     float64                `protobuf:"fixed64,9,opt,name=network_tx_rate_mbps,json=networkTxRateMbps,proto3" json:"network_tx_rate_mbps,omitempty"`
+	GpuUsagePercent       *float64               `protobuf:"fixed64,10,opt,name=gpu_usage_percent,json=gpuUsagePercent,proto3,oneof" json:"gpu_usage_percent,omitempty"`
+	GpuMemoryPercent      *float64               `protobuf:"fixed64,11,opt,name=gpu_memory_percent,json=gpuMemoryPercent,proto3,oneof" json:"gpu_memory_percent,omitempty"`
+	GpuTemperatureCelsius *float64               `protobuf:"fixed64,12,opt,name=gpu_temperature_celsius,json=gpuTemperatureCelsius,proto3,oneof" json:"gpu_temperature_celsius,omitempty"`
+	GpuCount              int32                  `protobuf:"varint,13,opt,name=gpu_count,json=gpuCount,proto3" json:"gpu_count,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Resources) Reset() {
+	*x = Resources{}
+	mi := &file_api_monitoring_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resources) ProtoMessage() {}
+
+func (x *Resources) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Resources) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Resources) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Resources) GetCpuUsagePercent() float64 {
+	if x != nil {
+		return x.CpuUsagePercent
+	}
+	return 0
+}
+
+func (x *Resources) GetMemoryUsagePercent() float64 {
+	if x != nil {
+		return x.MemoryUsagePercent
+	}
+	return 0
+}
+
+func (x *Resources) GetMemoryUsedBytes() int64 {
+	if x != nil {
+		return x.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (x *Resources) GetMemoryTotalBytes() int64 {
+	if x != nil {
+		return x.MemoryTotalBytes
+	}
+	return 0
+}
+
+func (x *Resources) GetDiskUsagePercent() float64 {
+	if x != nil {
+		return x.DiskUsagePercent
+	}
+	return 0
+}
+
+func (x *Resources) GetNetworkRxRateMbps() float64 {
+	if x != nil {
+		return x.NetworkRxRateMbps
+	}
+	return 0
+}
+
+func (x *Resources) GetNetworkTxRateMbps() float64 {
+	if x != nil {
+		return x.NetworkTxRateMbps
+	}
+	return 0
+}
+
+func (x *Resources) GetGpuUsagePercent() float64 {
+	if x != nil && x.GpuUsagePercent != nil {
+		return *x.GpuUsagePercent
+	}
+	return 0
+}
+
+func (x *Resources) GetGpuMemoryPercent() float64 {
+	if x != nil && x.GpuMemoryPercent != nil {
+		return *x.GpuMemoryPercent
+	}
+	return 0
+}
+
+func (x *Resources) GetGpuTemperatureCelsius() float64 {
+	if x != nil && x.GpuTemperatureCelsius != nil {
+		return *x.GpuTemperatureCelsius
+	}
+	return 0
+}
+
+func (x *Resources) GetGpuCount() int32 {
+	if x != nil {
+		return x.GpuCount
+	}
+	return 0
+}
+
+type RecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // ID of the recorded round, update or event
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordResponse) Reset() {
+	*x = RecordResponse{}
+	mi := &file_api_monitoring_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordResponse) ProtoMessage() {}
+
+func (x *RecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordResponse.ProtoReflect.Descriptor instead.
+func (*RecordResponse) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RecordResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Metric is one metric of a StreamMetrics stream
+type Metric struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Metric:
+	//
+	//	*Metric_Round
+	//	*Metric_Update
+	//	*Metric_Event
+	//	*Metric_Resources
+	Metric        isMetric_Metric `protobuf_oneof:"metric"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metric) Reset() {
+	*x = Metric{}
+	mi := &file_api_monitoring_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metric) ProtoMessage() {}
+
+func (x *Metric) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metric.ProtoReflect.Descriptor instead.
+func (*Metric) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Metric) GetMetric() isMetric_Metric {
+	if x != nil {
+		return x.Metric
+	}
+	return nil
+}
+
+func (x *Metric) GetRound() *Round {
+	if x != nil {
+		if x, ok := x.Metric.(*Metric_Round); ok {
+			return x.Round
+		}
+	}
+	return nil
+}
+
+func (x *Metric) GetUpdate() *Update {
+	if x != nil {
+		if x, ok := x.Metric.(*Metric_Update); ok {
+			return x.Update
+		}
+	}
+	return nil
+}
+
+func (x *Metric) GetEvent() *Event {
+	if x != nil {
+		if x, ok := x.Metric.(*Metric_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *Metric) GetResources() *Resources {
+	if x != nil {
+		if x, ok := x.Metric.(*Metric_Resources); ok {
+			return x.Resources
+		}
+	}
+	return nil
+}
+
+type isMetric_Metric interface {
+	isMetric_Metric()
+}
+
+type Metric_Round struct {
+	Round *Round `protobuf:"bytes,1,opt,name=round,proto3,oneof"`
+}
+
+type Metric_Update struct {
+	Update *Update `protobuf:"bytes,2,opt,name=update,proto3,oneof"`
+}
+
+type Metric_Event struct {
+	Event *Event `protobuf:"bytes,3,opt,name=event,proto3,oneof"`
+}
+
+type Metric_Resources struct {
+	Resources *Resources `protobuf:"bytes,4,opt,name=resources,proto3,oneof"`
+}
+
+func (*Metric_Round) isMetric_Metric() {}
+
+func (*Metric_Update) isMetric_Metric() {}
+
+func (*Metric_Event) isMetric_Metric() {}
+
+func (*Metric_Resources) isMetric_Metric() {}
+
+type StreamSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accepted      int32                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors        []*StreamError         `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"` // The first failures, in stream order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSummary) Reset() {
+	*x = StreamSummary{}
+	mi := &file_api_monitoring_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSummary) ProtoMessage() {}
+
+func (x *StreamSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSummary.ProtoReflect.Descriptor instead.
+func (*StreamSummary) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StreamSummary) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetErrors() []*StreamError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type StreamError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Position of the metric in the stream
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamError) Reset() {
+	*x = StreamError{}
+	mi := &file_api_monitoring_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamError) ProtoMessage() {}
+
+func (x *StreamError) ProtoReflect() protoreflect.Message {
+	mi := &file_api_monitoring_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamError.ProtoReflect.Descriptor instead.
+func (*StreamError) Descriptor() ([]byte, []int) {
+	return file_api_monitoring_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamError) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *StreamError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_api_monitoring_proto protoreflect.FileDescriptor
+
+const file_api_monitoring_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/monitoring.proto\x12\n" +
+	"monitoring\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd6\x05\n" +
+	"\x05Round\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rfederation_id\x18\x02 \x01(\tR\ffederationId\x12!\n" +
+	"\fround_number\x18\x03 \x01(\x05R\vroundNumber\x12\x1c\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x12+\n" +
+	"\x11participant_count\x18\b \x01(\x05R\x10participantCount\x12)\n" +
+	"\x10updates_received\x18\t \x01(\x05R\x0fupdatesReceived\x12D\n" +
+	"\x10aggregation_time\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\x0faggregationTime\x12*\n" +
+	"\x0emodel_accuracy\x18\v \x01(\x01H\x00R\rmodelAccuracy\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"model_loss\x18\f \x01(\x01H\x01R\tmodelLoss\x88\x01\x01\x12.\n" +
+	"\x10convergence_rate\x18\r \x01(\x01H\x02R\x0fconvergenceRate\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12A\n" +
+	"\x0fhyperparameters\x18\x0f \x01(\v2\x17.google.protobuf.StructR\x0fhyperparametersB\x11\n" +
+	"\x0f_model_accuracyB\r\n" +
+	"\v_model_lossB\x13\n" +
+	"\x11_convergence_rate\"\xed\x04\n" +
+	"\x06Update\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rfederation_id\x18\x02 \x01(\tR\ffederationId\x12'\n" +
+	"\x0fcollaborator_id\x18\x03 \x01(\tR\x0ecollaboratorId\x12!\n" +
+	"\fround_number\x18\x04 \x01(\x05R\vroundNumber\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12*\n" +
+	"\x11update_size_bytes\x18\x06 \x01(\x03R\x0fupdateSizeBytes\x12\x1f\n" +
+	"\vnum_samples\x18\a \x01(\x03R\n" +
+	"numSamples\x12,\n" +
+	"\x12processing_time_ms\x18\b \x01(\x01R\x10processingTimeMs\x12\x1c\n" +
+	"\tstaleness\x18\t \x01(\x05R\tstaleness\x12\x16\n" +
+	"\x06weight\x18\n" +
+	" \x01(\x01R\x06weight\x12(\n" +
+	"\rquality_score\x18\v \x01(\x01H\x00R\fqualityScore\x88\x01\x01\x120\n" +
+	"\x11compression_ratio\x18\f \x01(\x01H\x01R\x10compressionRatio\x88\x01\x01\x12!\n" +
+	"\fimage_digest\x18\r \x01(\tR\vimageDigest\x12 \n" +
+	"\fdp_clip_norm\x18\x0e \x01(\x01R\n" +
+	"dpClipNorm\x12.\n" +
+	"\x13dp_noise_multiplier\x18\x0f \x01(\x01R\x11dpNoiseMultiplierB\x10\n" +
+	"\x0e_quality_scoreB\x14\n" +
+	"\x12_compression_ratio\"\xff\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rfederation_id\x18\x02 \x01(\tR\ffederationId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x14\n" +
+	"\x05level\x18\x06 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x12+\n" +
+	"\x04data\x18\b \x01(\v2\x17.google.protobuf.StructR\x04data\"\xac\x05\n" +
+	"\tResources\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12*\n" +
+	"\x11cpu_usage_percent\x18\x03 \x01(\x01R\x0fcpuUsagePercent\x120\n" +
+	"\x14memory_usage_percent\x18\x04 \x01(\x01R\x12memoryUsagePercent\x12*\n" +
+	"\x11memory_used_bytes\x18\x05 \x01(\x03R\x0fmemoryUsedBytes\x12,\n" +
+	"\x12memory_total_bytes\x18\x06 \x01(\x03R\x10memoryTotalBytes\x12,\n" +
+	"\x12disk_usage_percent\x18\a \x01(\x01R\x10diskUsagePercent\x12/\n" +
+	"\x14network_rx_rate_mbps\x18\b \x01(\x01R\x11networkRxRateMbps\x12/\n" +
+	"\x14network_tx_rate_mbps\x18\t \x01(\x01R\x11networkTxRateMbps\x12/\n" +
+	"\x11gpu_usage_percent\x18\n" +
+	" \x01(\x01H\x00R\x0fgpuUsagePercent\x88\x01\x01\x121\n" +
+	"\x12gpu_memory_percent\x18\v \x01(\x01H\x01R\x10gpuMemoryPercent\x88\x01\x01\x12;\n" +
+	"\x17gpu_temperature_celsius\x18\f \x01(\x01H\x02R\x15gpuTemperatureCelsius\x88\x01\x01\x12\x1b\n" +
+	"\tgpu_count\x18\r \x01(\x05R\bgpuCountB\x14\n" +
+	"\x12_gpu_usage_percentB\x15\n" +
+	"\x13_gpu_memory_percentB\x1a\n" +
+	"\x18_gpu_temperature_celsius\" \n" +
+	"\x0eRecordResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xcd\x01\n" +
+	"\x06Metric\x12)\n" +
+	"\x05round\x18\x01 \x01(\v2\x11.monitoring.RoundH\x00R\x05round\x12,\n" +
+	"\x06update\x18\x02 \x01(\v2\x12.monitoring.UpdateH\x00R\x06update\x12)\n" +
+	"\x05event\x18\x03 \x01(\v2\x11.monitoring.EventH\x00R\x05event\x125\n" +
+	"\tresources\x18\x04 \x01(\v2\x15.monitoring.ResourcesH\x00R\tresourcesB\b\n" +
+	"\x06metric\"t\n" +
+	"\rStreamSummary\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x05R\baccepted\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12/\n" +
+	"\x06errors\x18\x03 \x03(\v2\x17.monitoring.StreamErrorR\x06errors\"9\n" +
+	"\vStreamError\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xd6\x02\n" +
+	"\x10MonitoringIngest\x12<\n" +
+	"\vRecordRound\x12\x11.monitoring.Round\x1a\x1a.monitoring.RecordResponse\x12>\n" +
+	"\fRecordUpdate\x12\x12.monitoring.Update\x1a\x1a.monitoring.RecordResponse\x12<\n" +
+	"\vRecordEvent\x12\x11.monitoring.Event\x1a\x1a.monitoring.RecordResponse\x12D\n" +
+	"\x0fRecordResources\x12\x15.monitoring.Resources\x1a\x1a.monitoring.RecordResponse\x12@\n" +
+	"\rStreamMetrics\x12\x12.monitoring.Metric\x1a\x19.monitoring.StreamSummary(\x01B\x14Z\x12./api/monitoringpbb\x06proto3"
+
+var (
+	file_api_monitoring_proto_rawDescOnce sync.Once
+	file_api_monitoring_proto_rawDescData []byte
+)
+
+func file_api_monitoring_proto_rawDescGZIP() []byte {
+	file_api_monitoring_proto_rawDescOnce.Do(func() {
+		file_api_monitoring_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_monitoring_proto_rawDesc), len(file_api_monitoring_proto_rawDesc)))
+	})
+	return file_api_monitoring_proto_rawDescData
+}
+
+var file_api_monitoring_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_monitoring_proto_goTypes = []any{
+	(*Round)(nil),                 // 0: monitoring.Round
+	(*Update)(nil),                // 1: monitoring.Update
+	(*Event)(nil),                 // 2: monitoring.Event
+	(*Resources)(nil),             // 3: monitoring.Resources
+	(*RecordResponse)(nil),        // 4: monitoring.RecordResponse
+	(*Metric)(nil),                // 5: monitoring.Metric
+	(*StreamSummary)(nil),         // 6: monitoring.StreamSummary
+	(*StreamError)(nil),           // 7: monitoring.StreamError
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 9: google.protobuf.Duration
+	(*structpb.Struct)(nil),       // 10: google.protobuf.Struct
+}
+var file_api_monitoring_proto_depIdxs = []int32{
+	8,  // 0: monitoring.Round.start_time:type_name -> google.protobuf.Timestamp
+	8,  // 1: monitoring.Round.end_time:type_name -> google.protobuf.Timestamp
+	9,  // 2: monitoring.Round.duration:type_name -> google.protobuf.Duration
+	9,  // 3: monitoring.Round.aggregation_time:type_name -> google.protobuf.Duration
+	10, // 4: monitoring.Round.hyperparameters:type_name -> google.protobuf.Struct
+	8,  // 5: monitoring.Update.timestamp:type_name -> google.protobuf.Timestamp
+	8,  // 6: monitoring.Event.timestamp:type_name -> google.protobuf.Timestamp
+	10, // 7: monitoring.Event.data:type_name -> google.protobuf.Struct
+	8,  // 8: monitoring.Resources.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 9: monitoring.Metric.round:type_name -> monitoring.Round
+	1,  // 10: monitoring.Metric.update:type_name -> monitoring.Update
+	2,  // 11: monitoring.Metric.event:type_name -> monitoring.Event
+	3,  // 12: monitoring.Metric.resources:type_name -> monitoring.Resources
+	7,  // 13: monitoring.StreamSummary.errors:type_name -> monitoring.StreamError
+	0,  // 14: monitoring.MonitoringIngest.RecordRound:input_type -> monitoring.Round
+	1,  // 15: monitoring.MonitoringIngest.RecordUpdate:input_type -> monitoring.Update
+	2,  // 16: monitoring.MonitoringIngest.RecordEvent:input_type -> monitoring.Event
+	3,  // 17: monitoring.MonitoringIngest.RecordResources:input_type -> monitoring.Resources
+	5,  // 18: monitoring.MonitoringIngest.StreamMetrics:input_type -> monitoring.Metric
+	4,  // 19: monitoring.MonitoringIngest.RecordRound:output_type -> monitoring.RecordResponse
+	4,  // 20: monitoring.MonitoringIngest.RecordUpdate:output_type -> monitoring.RecordResponse
+	4,  // 21: monitoring.MonitoringIngest.RecordEvent:output_type -> monitoring.RecordResponse
+	4,  // 22: monitoring.MonitoringIngest.RecordResources:output_type -> monitoring.RecordResponse
+	6,  // 23: monitoring.MonitoringIngest.StreamMetrics:output_type -> monitoring.StreamSummary
+	19, // [19:24] is the sub-list for method output_type
+	14, // [14:19] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_api_monitoring_proto_init() }
+func file_api_monitoring_proto_init() {
+	if File_api_monitoring_proto != nil {
+		return
+	}
+	file_api_monitoring_proto_msgTypes[0].OneofWrappers = []any{}
+	file_api_monitoring_proto_msgTypes[1].OneofWrappers = []any{}
+	file_api_monitoring_proto_msgTypes[3].OneofWrappers = []any{}
+	file_api_monitoring_proto_msgTypes[5].OneofWrappers = []any{
+		(*Metric_Round)(nil),
+		(*Metric_Update)(nil),
+		(*Metric_Event)(nil),
+		(*Metric_Resources)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_monitoring_proto_rawDesc), len(file_api_monitoring_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_monitoring_proto_goTypes,
+		DependencyIndexes: file_api_monitoring_proto_depIdxs,
+		MessageInfos:      file_api_monitoring_proto_msgTypes,
+	}.Build()
+	File_api_monitoring_proto = out.File
+	file_api_monitoring_proto_goTypes = nil
+	file_api_monitoring_proto_depIdxs = nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ishaileshpant/fl-go/pkg/aggregator"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
 )
 
 func main() {
@@ -18,8 +19,11 @@ func main() {
 	log.Println("Creating aggregator...")
 	agg := aggregator.NewFedAvgAggregator(plan)
 
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
 	log.Println("Starting aggregator...")
-	if err := agg.Start(context.Background()); err != nil {
+	if err := agg.Start(ctx); err != nil {
 		log.Fatalf("Aggregator failed: %v", err)
 	}
 
@@ -22,7 +22,7 @@ func main() {
 		log.Fatalf("Failed to connect to aggregator: %v", err)
 	}
 
-	update, err := c.RunTrainTask(pl.Tasks.Train)
+	update, err := c.RunTrainTask(federation.CollaboratorTasks(pl, *id).Train)
 	if err != nil {
 		log.Fatalf("Failed to run training task: %v", err)
 	}
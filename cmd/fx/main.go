@@ -30,6 +30,38 @@ func main() {
 		if err := cli.HandleCollaboratorCommand(args); err != nil {
 			log.Fatalf("Collaborator command failed: %v", err)
 		}
+	case "monitor":
+		if err := cli.HandleMonitorCommand(args); err != nil {
+			log.Fatalf("Monitor command failed: %v", err)
+		}
+	case "model":
+		if err := cli.HandleModelCommand(args); err != nil {
+			log.Fatalf("Model command failed: %v", err)
+		}
+	case "federation":
+		if err := cli.HandleFederationCommand(args); err != nil {
+			log.Fatalf("Federation command failed: %v", err)
+		}
+	case "benchmark":
+		if err := cli.HandleBenchmarkCommand(args); err != nil {
+			log.Fatalf("Benchmark command failed: %v", err)
+		}
+	case "loadtest":
+		if err := cli.HandleLoadtestCommand(args); err != nil {
+			log.Fatalf("Loadtest command failed: %v", err)
+		}
+	case "he":
+		if err := cli.HandleHECommand(args); err != nil {
+			log.Fatalf("HE command failed: %v", err)
+		}
+	case "deploy":
+		if err := cli.HandleDeployCommand(args); err != nil {
+			log.Fatalf("Deploy command failed: %v", err)
+		}
+	case "simulate":
+		if err := cli.HandleSimulateCommand(args); err != nil {
+			log.Fatalf("Simulate command failed: %v", err)
+		}
 	case "version":
 		fmt.Println("FL-Go v1.0.0")
 	case "help", "--help", "-h":
@@ -51,6 +83,14 @@ func printUsage() {
 	fmt.Println("  plan         Manage federated learning plans")
 	fmt.Println("  aggregator   Start and manage aggregator")
 	fmt.Println("  collaborator Start and manage collaborator")
+	fmt.Println("  monitor      Work with the monitoring server")
+	fmt.Println("  model        Manage versions in the model registry")
+	fmt.Println("  federation   Verify reruns and re-aggregate archived rounds")
+	fmt.Println("  simulate     Run virtual collaborators in one process")
+	fmt.Println("  deploy       Generate Kubernetes or Compose deployments of a federation")
+	fmt.Println("  benchmark    Measure aggregation performance")
+	fmt.Println("  loadtest     Load an aggregator or the monitoring API with synthetic traffic")
+	fmt.Println("  he           Keys and key holder of homomorphic aggregation (experimental)")
 	fmt.Println("  version      Show version information")
 	fmt.Println("  help         Show this help message")
 	fmt.Println()
@@ -58,6 +98,13 @@ func printUsage() {
 	fmt.Println("  fx plan init                    # Initialize a new FL workspace")
 	fmt.Println("  fx aggregator start             # Start the aggregator")
 	fmt.Println("  fx collaborator start collab1  # Start collaborator with ID 'collab1'")
+	fmt.Println("  fx monitor export -f fed-1     # Export monitoring data as CSV")
+	fmt.Println("  fx model list                  # List registered model versions")
+	fmt.Println("  fx federation verify-run -m baseline.json  # Check a rerun against a manifest")
+	fmt.Println("  fx benchmark aggregate -o bench.json  # Benchmark the aggregation algorithms")
+	fmt.Println("  fx loadtest aggregator -c 2000 -s 10M --streaming  # Load test an aggregator")
+	fmt.Println("  fx simulate --clients 50       # Simulate 50 collaborators with plan.yaml")
+	fmt.Println("  fx deploy k8s -o k8s.yaml      # Generate Kubernetes manifests for plan.yaml")
 	fmt.Println()
 	fmt.Println("For more help on a specific command:")
 	fmt.Println("  fx <command> --help")
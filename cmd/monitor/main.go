@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
 	"gopkg.in/yaml.v2"
 )
 
@@ -55,11 +54,50 @@ func main() {
 	var storage monitoring.MonitoringService
 	switch config.StorageBackend {
 	case "memory":
-		storage = monitoring.NewMemoryStorage(config)
+		memory := monitoring.NewMemoryStorage(config)
+		if err := memory.LoadLedger(); err != nil {
+			log.Fatalf("Failed to load ledger: %v", err)
+		}
+		storage = memory
+	case "postgres", "postgresql", "timescaledb":
+		postgres, err := monitoring.NewPostgreSQLStorage(monitoring.DatabaseConfig{
+			URL:         config.DatabaseURL,
+			TimescaleDB: config.StorageBackend == "timescaledb",
+		})
+		if err != nil {
+			log.Fatalf("Failed to open PostgreSQL storage: %v", err)
+		}
+		defer postgres.Close()
+		postgres.SetLedgerConfig(config.Ledger)
+		storage = postgres
 	default:
 		log.Fatalf("Unsupported storage backend: %s", config.StorageBackend)
 	}
 
+	// Deliver notifications for the events reported to this server; the
+	// events relayed from other replicas are delivered by those replicas
+	if !config.Notifications.Disabled {
+		notifier := monitoring.NewNotifier(storage, config.Notifications)
+		go func() {
+			if err := notifier.Run(context.Background()); err != nil {
+				log.Printf("Failed to start notifications: %v", err)
+			}
+		}()
+	}
+
+	// Share live events with the other monitoring servers
+	if config.EventBus.Address != "" {
+		bus, err := monitoring.NewRedisStorage(config.EventBus)
+		if err != nil {
+			log.Fatalf("Failed to connect to event bus: %v", err)
+		}
+		defer bus.Close()
+		storage, err = monitoring.RelayEvents(context.Background(), storage, bus)
+		if err != nil {
+			log.Fatalf("Failed to relay events: %v", err)
+		}
+	}
+
 	// Create API server
 	apiServer := monitoring.NewAPIServer(storage, config)
 
@@ -73,18 +111,13 @@ func main() {
 		log.Printf("Failed to create sample data: %v", err)
 	}
 
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle shutdown signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// Setup graceful shutdown on signals and Windows service stop requests
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
 
 	go func() {
-		<-sigCh
+		<-ctx.Done()
 		log.Println("Shutting down...")
-		cancel()
 	}()
 
 	// Start API server
@@ -94,10 +127,15 @@ func main() {
 		}
 	}()
 
+	scheme := "http"
+	if config.TLS.Enabled {
+		scheme = "https"
+	}
+
 	log.Println("FL Monitoring Server started successfully")
-	log.Printf("API available at: http://localhost:%d/api/v1", config.APIPort)
-	log.Printf("Health check: http://localhost:%d/api/v1/health", config.APIPort)
-	log.Printf("Web UI will be available at: http://localhost:%d", config.WebUIPort)
+	log.Printf("API available at: %s://localhost:%d/api/v1", scheme, config.APIPort)
+	log.Printf("Health check: %s://localhost:%d/api/v1/health", scheme, config.APIPort)
+	log.Printf("Dashboard: %s://localhost:%d/", scheme, config.APIPort)
 
 	// Wait for shutdown signal
 	<-ctx.Done()
@@ -127,6 +165,7 @@ func loadConfig(configPath string) (*monitoring.MonitoringConfig, error) {
 func startResourceMonitoring(storage monitoring.MonitoringService, config *monitoring.MonitoringConfig) {
 	ticker := time.NewTicker(config.CollectionInterval)
 	defer ticker.Stop()
+	sampler := monitoring.NewResourceSampler(".")
 
 	for {
 		select {
@@ -134,25 +173,10 @@ func startResourceMonitoring(storage monitoring.MonitoringService, config *monit
 			// Collect and record resource metrics for the monitoring server itself
 			ctx := context.Background()
 
-			// Simulate resource metrics collection
-			// In a real implementation, you would use system calls or libraries to get actual metrics
-			cpuUsage := 15.5                             // Percentage
-			memoryUsage := 42.8                          // Percentage
-			diskUsage := 67.2                            // Percentage
-			memoryUsed := int64(1024 * 1024 * 512)       // 512MB
-			memoryTotal := int64(1024 * 1024 * 1024 * 4) // 4GB
-			networkRx := 1.2                             // Mbps
-			networkTx := 0.8                             // Mbps
-
-			metrics := &monitoring.ResourceMetrics{
-				Timestamp:     time.Now(),
-				CPUUsage:      cpuUsage,
-				MemoryUsage:   memoryUsage,
-				MemoryUsed:    memoryUsed,
-				MemoryTotal:   memoryTotal,
-				DiskUsage:     diskUsage,
-				NetworkRxRate: networkRx,
-				NetworkTxRate: networkTx,
+			metrics, err := sampler.Sample()
+			if err != nil {
+				log.Printf("Failed to collect resource metrics: %v", err)
+				continue
 			}
 
 			if err := storage.RecordResourceMetrics(ctx, "monitoring-server", metrics); err != nil {
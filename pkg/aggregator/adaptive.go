@@ -0,0 +1,138 @@
+package aggregator
+
+import (
+	"log"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+const (
+	defaultStalenessPercentile = 0.9
+	defaultMinAggregationDelay = time.Second
+	// arrivalSmoothing is the weight of the latest interval between two updates
+	// in the estimated arrival rate
+	arrivalSmoothing = 0.2
+	// maxDelayStep bounds how much one aggregation changes the adaptive delay
+	maxDelayStep = 2.0
+)
+
+// delayController chooses how long an async aggregator waits before its next
+// aggregation. Without adaptive_delay that is the plan's aggregation_delay.
+// With it, the delay follows the staleness updates had when they were
+// aggregated: it shrinks while the configured percentile exceeds the target
+// and grows while it stays below, between min_delay and aggregation_delay. It
+// is never shorter than the time the observed arrival rate needs to deliver
+// min_updates, so aggregations are not attempted before they can happen.
+//
+// The controller is not safe for concurrent use; aggregators call it under
+// their lock.
+type delayController struct {
+	delay       time.Duration // staleness-driven delay; 0 until the first aggregation
+	chosen      time.Duration // delay returned by the latest Next
+	interval    float64       // smoothed seconds between updates; 0 until two arrived
+	lastArrival time.Time
+}
+
+func newDelayController() *delayController {
+	return &delayController{}
+}
+
+// Arrived records the arrival time of an update
+func (c *delayController) Arrived(now time.Time) {
+	if !c.lastArrival.IsZero() {
+		seconds := max(now.Sub(c.lastArrival).Seconds(), 0)
+		if c.interval == 0 {
+			c.interval = seconds
+		} else {
+			c.interval += arrivalSmoothing * (seconds - c.interval)
+		}
+	}
+	c.lastArrival = now
+}
+
+// Rate returns the estimated number of updates per second, or 0 until two
+// updates arrived
+func (c *delayController) Rate() float64 {
+	if c.interval <= 0 {
+		return 0
+	}
+	return 1 / c.interval
+}
+
+// Observe adapts the delay to the staleness of the updates an aggregation
+// combined
+func (c *delayController) Observe(config federation.AsyncConfig, staleness []time.Duration) {
+	adaptive := config.AdaptiveDelay
+	if !adaptive.Enabled || len(staleness) == 0 {
+		return
+	}
+
+	percentile := adaptive.Percentile
+	if percentile <= 0 {
+		percentile = defaultStalenessPercentile
+	}
+	observed := stalenessPercentile(staleness, percentile)
+	target := time.Duration(adaptive.TargetStaleness) * time.Second
+
+	step := maxDelayStep
+	if observed > 0 {
+		step = min(max(float64(target)/float64(observed), 1/maxDelayStep), maxDelayStep)
+	}
+	delay := c.delay
+	if delay == 0 {
+		delay = c.chosen
+	}
+	c.delay = clampDelay(time.Duration(float64(delay)*step), config)
+
+	if c.delay != delay {
+		log.Printf("Adaptive aggregation delay: %s (p%.0f staleness %s, target %s, %.2f updates/s)",
+			c.delay, percentile*100, observed.Round(time.Millisecond), target, c.Rate())
+	}
+}
+
+// Next returns the delay before the next aggregation
+func (c *delayController) Next(config federation.AsyncConfig) time.Duration {
+	delay := time.Duration(config.AggregationDelay) * time.Second
+	if config.AdaptiveDelay.Enabled {
+		if c.delay > 0 {
+			delay = c.delay
+		}
+		// Waiting less than min_updates take to arrive only ticks in vain
+		if c.interval > 0 && config.MinUpdates > 1 {
+			fill := time.Duration(float64(config.MinUpdates-1) * c.interval * float64(time.Second))
+			delay = max(delay, fill)
+		}
+		delay = clampDelay(delay, config)
+	}
+	c.chosen = delay
+	return delay
+}
+
+// annotate adds the delay that preceded an aggregation and the arrival rate
+// to its metrics
+func (c *delayController) annotate(metrics *monitoring.AggregationMetrics) {
+	metrics.AggregationDelay = c.chosen.Seconds()
+	metrics.ArrivalRate = c.Rate()
+}
+
+// clampDelay bounds an adaptive delay by min_delay and aggregation_delay
+func clampDelay(delay time.Duration, config federation.AsyncConfig) time.Duration {
+	lower := time.Duration(config.AdaptiveDelay.MinDelay) * time.Second
+	if lower <= 0 {
+		lower = defaultMinAggregationDelay
+	}
+	upper := max(time.Duration(config.AggregationDelay)*time.Second, lower)
+	return min(max(delay, lower), upper)
+}
+
+// stalenessPercentile returns the nearest-rank percentile p of staleness
+func stalenessPercentile(staleness []time.Duration, p float64) time.Duration {
+	sorted := slices.Clone(staleness)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
@@ -0,0 +1,79 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestDelayController(t *testing.T) {
+	fixed := federation.AsyncConfig{AggregationDelay: 10, MinUpdates: 3}
+	c := newDelayController()
+	c.Observe(fixed, []time.Duration{time.Minute})
+	if delay := c.Next(fixed); delay != 10*time.Second {
+		t.Errorf("fixed delay = %s, want 10s", delay)
+	}
+
+	config := fixed
+	config.AdaptiveDelay = federation.AdaptiveDelayConfig{Enabled: true, TargetStaleness: 4, MinDelay: 2}
+	c = newDelayController()
+	if delay := c.Next(config); delay != 10*time.Second {
+		t.Fatalf("adaptive delay before any aggregation = %s, want aggregation_delay", delay)
+	}
+
+	// Updates staler than the target shorten the delay, at most by half per aggregation
+	staleness := []time.Duration{time.Second, 5 * time.Second, 6 * time.Second, 20 * time.Second}
+	c.Observe(config, staleness)
+	if delay := c.Next(config); delay != 5*time.Second {
+		t.Errorf("delay after stale updates = %s, want 5s", delay)
+	}
+	c.Observe(config, []time.Duration{8 * time.Second})
+	if delay := c.Next(config); delay != 2500*time.Millisecond {
+		t.Errorf("delay = %s, want 2.5s", delay)
+	}
+	c.Observe(config, []time.Duration{time.Minute})
+	if delay := c.Next(config); delay != 2*time.Second {
+		t.Errorf("delay = %s, want min_delay", delay)
+	}
+
+	// Fresh updates lengthen it up to aggregation_delay
+	for range 5 {
+		c.Observe(config, []time.Duration{0})
+	}
+	if delay := c.Next(config); delay != 10*time.Second {
+		t.Errorf("delay after fresh updates = %s, want aggregation_delay", delay)
+	}
+
+	// The delay covers the time min_updates take to arrive
+	c.Observe(config, []time.Duration{time.Minute})
+	start := time.Now()
+	for k := range 3 {
+		c.Arrived(start.Add(time.Duration(k) * 3 * time.Second))
+	}
+	if rate := c.Rate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("arrival rate = %g, want 1/3", rate)
+	}
+	if delay := c.Next(config); delay != 6*time.Second {
+		t.Errorf("delay = %s, want the 6s two more updates take", delay)
+	}
+
+	var metrics monitoring.AggregationMetrics
+	c.annotate(&metrics)
+	if metrics.AggregationDelay != 6 || metrics.ArrivalRate != c.Rate() {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
+
+func TestStalenessPercentile(t *testing.T) {
+	staleness := []time.Duration{4, 1, 3, 2, 5, 6, 7, 8, 9, 10}
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{{0.9, 9}, {0.5, 5}, {1, 10}, {0.01, 1}} {
+		if got := stalenessPercentile(staleness, tt.p); got != tt.want {
+			t.Errorf("stalenessPercentile(%g) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+}
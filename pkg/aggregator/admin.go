@@ -0,0 +1,213 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Administrable is implemented by aggregators that the AdminService controls
+// and inspects
+type Administrable interface {
+	Controllable
+	Report() *RunReport
+	RoundState() RoundState
+	AggregateNow() (RoundState, error)
+}
+
+// RoundState is the state of the round an aggregator collects updates for.
+// In async mode, that is the round the next aggregation produces.
+type RoundState struct {
+	Round     int
+	ModelHash string   // the model the round trains
+	Expected  int      // updates the round waits for; in async mode, min_updates
+	Received  []string // collaborators whose updates the round holds
+	Pending   []string // sync mode: expected collaborators that have not submitted
+}
+
+// syncRoundState returns the state of a sync round that expects an update of
+// each of expected and holds those of submitted
+func syncRoundState(round int, modelHash string, expected []string, submitted map[string]bool) RoundState {
+	state := RoundState{Round: round, ModelHash: modelHash, Expected: len(expected)}
+	for id := range submitted {
+		state.Received = append(state.Received, id)
+	}
+	slices.Sort(state.Received)
+	if round > 0 {
+		for _, id := range expected {
+			if !submitted[id] {
+				state.Pending = append(state.Pending, id)
+			}
+		}
+	}
+	return state
+}
+
+// collaboratorIDs returns the IDs of the plan's collaborators, without its
+// evaluators unless withEvaluators is set
+func collaboratorIDs(plan *federation.FLPlan, withEvaluators bool) []string {
+	ids := make([]string, 0, len(plan.Collaborators))
+	for _, collab := range plan.Collaborators {
+		if withEvaluators || collab.Role != federation.CollaboratorEvaluator {
+			ids = append(ids, collab.ID)
+		}
+	}
+	return ids
+}
+
+// serveAdmin serves the AdminService on the plan's admin address, if it sets
+// one, until stop is called. The address only accepts clients with a
+// certificate of the plan's CA, so the plan must enable TLS. Collaborators
+// hold certificates of that CA too, so calls must also carry the admin token.
+// The AdminService is never served on the aggregator's own address, which
+// collaborators reach. tlsManager holds the certificates of the aggregator's
+// own address, or is nil when that address does not use them.
+func serveAdmin(plan *federation.FLPlan, tlsManager *security.TLSManager, agg Administrable) (stop func(), err error) {
+	address := plan.Aggregator.AdminAddress
+	if address == "" {
+		if plan.Aggregator.AdminToken != "" {
+			log.Printf("Warning: aggregator.admin_token is set without aggregator.admin_address, so the AdminService is not served")
+		}
+		return func() {}, nil
+	}
+	if !plan.Security.TLS.Enabled {
+		return nil, fmt.Errorf("aggregator.admin_address requires security.tls.enabled, since the admin address only accepts clients with a certificate")
+	}
+	if plan.Aggregator.AdminToken == "" {
+		return nil, fmt.Errorf("aggregator.admin_address requires aggregator.admin_token, since collaborators hold certificates of the same CA")
+	}
+	if tlsManager == nil {
+		if tlsManager, err = security.NewTLSManager(security.TLSConfig(plan.Security.TLS), "certs"); err != nil {
+			return nil, fmt.Errorf("failed to initialize TLS manager: %w", err)
+		}
+	}
+	serverOpts, err := tlsManager.NewServerOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin server options: %w", err)
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on the admin address: %w", err)
+	}
+	srv := grpc.NewServer(serverOpts...)
+	adminpb.RegisterAdminServiceServer(srv, &adminServer{control: agg, plan: plan, token: plan.Aggregator.AdminToken})
+	go func() {
+		log.Printf("Admin service listening on %s (mTLS)", address)
+		if err := srv.Serve(lis); err != nil {
+			log.Printf("Admin server error: %v", err)
+		}
+	}()
+	return srv.Stop, nil
+}
+
+func (s *adminServer) GetStatus(ctx context.Context, _ *adminpb.StatusRequest) (*adminpb.StatusResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	report := s.control.Report()
+	return &adminpb.StatusResponse{
+		FederationId:    report.FederationID,
+		Mode:            string(report.Mode),
+		Algorithm:       report.Algorithm,
+		State:           string(s.control.ControlState()),
+		StartTime:       report.StartTime.Unix(),
+		CurrentRound:    clampInt32(s.control.RoundState().Round),
+		RoundsPlanned:   clampInt32(report.RoundsPlanned),
+		RoundsCompleted: clampInt32(report.RoundsCompleted),
+		UpdatesAccepted: int64(report.FinalMetrics["total_updates"]),
+		UpdatesDropped:  int64(report.FinalMetrics["dropped_updates"]),
+		LogLevel:        string(logging.CurrentLevel()),
+	}, nil
+}
+
+func (s *adminServer) ListCollaborators(ctx context.Context, _ *adminpb.ListCollaboratorsRequest) (*adminpb.ListCollaboratorsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool)
+	for _, id := range s.control.RoundState().Pending {
+		pending[id] = true
+	}
+
+	resp := &adminpb.ListCollaboratorsResponse{}
+	for _, p := range s.control.Report().Participation {
+		role, _ := federation.RoleOf(s.plan, p.CollaboratorID)
+		var lastUpdate int64
+		if !p.LastUpdate.IsZero() {
+			lastUpdate = p.LastUpdate.Unix()
+		}
+		resp.Collaborators = append(resp.Collaborators, &adminpb.CollaboratorStatus{
+			Id:                 p.CollaboratorID,
+			Role:               string(role),
+			UpdatesAccepted:    clampInt32(p.UpdatesAccepted),
+			UpdatesDropped:     clampInt32(p.UpdatesDropped),
+			RoundsParticipated: clampInt32(p.RoundsParticipated),
+			LastUpdate:         lastUpdate,
+			Pending:            pending[p.CollaboratorID],
+		})
+	}
+	return resp, nil
+}
+
+func (s *adminServer) GetRoundState(ctx context.Context, _ *adminpb.RoundStateRequest) (*adminpb.RoundStateResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return roundStateResponse(s.control.RoundState()), nil
+}
+
+// TriggerAggregationNow aggregates the updates of the current round without
+// waiting for the rest. Only a running federation aggregates.
+func (s *adminServer) TriggerAggregationNow(ctx context.Context, req *adminpb.ControlRequest) (*adminpb.RoundStateResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if state := s.control.ControlState(); state != ControlRunning {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot aggregate: the federation is %s", state)
+	}
+
+	if req.GetReason() != "" {
+		log.Printf("Admin request to aggregate now: %s", req.GetReason())
+	} else {
+		log.Printf("Admin request to aggregate now")
+	}
+	round, err := s.control.AggregateNow()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot aggregate round %d: %v", round.Round, err)
+	}
+	return roundStateResponse(round), nil
+}
+
+func (s *adminServer) SetLogLevel(ctx context.Context, req *adminpb.LogLevelRequest) (*adminpb.LogLevelResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	level, err := logging.ParseLevel(req.GetLevel())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	previous := logging.SetLevel(level)
+	log.Printf("Log level changed from %s to %s on admin request", previous, level)
+	return &adminpb.LogLevelResponse{Level: string(level), Previous: string(previous)}, nil
+}
+
+func roundStateResponse(state RoundState) *adminpb.RoundStateResponse {
+	return &adminpb.RoundStateResponse{
+		Round:     clampInt32(state.Round),
+		ModelHash: state.ModelHash,
+		Expected:  clampInt32(state.Expected),
+		Received:  state.Received,
+		Pending:   state.Pending,
+	}
+}
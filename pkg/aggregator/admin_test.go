@@ -0,0 +1,193 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminServerState(t *testing.T) {
+	agg := NewFedAvgAggregator(&federation.FLPlan{
+		Rounds: 3,
+		Collaborators: []federation.Collaborator{
+			{ID: "c1"}, {ID: "c2"}, {ID: "e1", Role: federation.CollaboratorEvaluator},
+		},
+		Aggregator: federation.AggregatorEntry{AdminToken: "secret"},
+	})
+	_, stop := agg.runControl.start(context.Background())
+	defer stop()
+	agg.currentRound, agg.modelHash = 1, "abc"
+	agg.submitted = map[string]bool{"c1": true}
+	agg.quorum = newUpdateQuorum(2)
+	agg.run.RecordUpdate("c1", 1)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	adminpb.RegisterAdminServiceServer(srv, &adminServer{control: agg, plan: agg.plan, token: agg.plan.Aggregator.AdminToken})
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := adminpb.NewAdminServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+
+	if _, err := client.GetStatus(context.Background(), &adminpb.StatusRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetStatus without token = %v, want Unauthenticated", err)
+	}
+	st, err := client.GetStatus(ctx, &adminpb.StatusRequest{})
+	if err != nil || st.State != string(ControlRunning) || st.CurrentRound != 1 || st.RoundsPlanned != 3 || st.UpdatesAccepted != 1 {
+		t.Errorf("GetStatus = %v, %v", st, err)
+	}
+
+	round, err := client.GetRoundState(ctx, &adminpb.RoundStateRequest{})
+	if err != nil || round.ModelHash != "abc" || round.Expected != 2 ||
+		!slices.Equal(round.Received, []string{"c1"}) || !slices.Equal(round.Pending, []string{"c2"}) {
+		t.Errorf("GetRoundState = %v, %v", round, err)
+	}
+
+	collaborators, err := client.ListCollaborators(ctx, &adminpb.ListCollaboratorsRequest{})
+	if err != nil || len(collaborators.Collaborators) != 3 {
+		t.Fatalf("ListCollaborators = %v, %v", collaborators, err)
+	}
+	for _, c := range collaborators.Collaborators {
+		switch c.Id {
+		case "c1":
+			if c.Pending || c.UpdatesAccepted != 1 || c.LastUpdate == 0 {
+				t.Errorf("c1 = %v", c)
+			}
+		case "c2":
+			if !c.Pending || c.Role != string(federation.CollaboratorTrainer) {
+				t.Errorf("c2 = %v", c)
+			}
+		case "e1":
+			if c.Pending || c.Role != string(federation.CollaboratorEvaluator) {
+				t.Errorf("e1 = %v", c)
+			}
+		}
+	}
+
+	// Aggregating now releases the round's wait with the update it holds
+	if _, err := client.TriggerAggregationNow(ctx, &adminpb.ControlRequest{Reason: "c2 is down"}); err != nil {
+		t.Fatalf("TriggerAggregationNow: %v", err)
+	}
+	select {
+	case <-agg.quorum.reached:
+	default:
+		t.Error("the round still waits after TriggerAggregationNow")
+	}
+	agg.Pause()
+	if _, err := client.TriggerAggregationNow(ctx, &adminpb.ControlRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("TriggerAggregationNow while paused = %v, want FailedPrecondition", err)
+	}
+
+	defer logging.SetLevel(logging.LevelInfo)
+	level, err := client.SetLogLevel(ctx, &adminpb.LogLevelRequest{Level: "debug"})
+	if err != nil || level.Previous != string(logging.LevelInfo) || logging.CurrentLevel() != logging.LevelDebug {
+		t.Errorf("SetLogLevel = %v, %v", level, err)
+	}
+	if _, err := client.SetLogLevel(ctx, &adminpb.LogLevelRequest{Level: "trace"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SetLogLevel(trace) = %v, want InvalidArgument", err)
+	}
+}
+
+func TestServeAdminRequiresClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	plan := &federation.FLPlan{Aggregator: federation.AggregatorEntry{AdminAddress: address}}
+	agg := NewFedAvgAggregator(plan)
+	if _, err := serveAdmin(plan, nil, agg); err == nil {
+		t.Fatal("serveAdmin without TLS succeeded")
+	}
+
+	plan.Security.TLS = federation.TLSConfig{Enabled: true, AutoGenerateCert: true, ServerName: "localhost"}
+	// Collaborators hold certificates of the same CA, so a certificate alone
+	// must not be enough
+	if _, err := serveAdmin(plan, nil, agg); err == nil {
+		t.Fatal("serveAdmin without an admin token succeeded")
+	}
+
+	plan.Aggregator.AdminToken = "secret"
+	stopAdmin, err := serveAdmin(plan, nil, agg)
+	if err != nil {
+		t.Fatalf("serveAdmin: %v", err)
+	}
+	defer stopAdmin()
+
+	// A client that trusts the server but has no certificate is turned away
+	caPEM, err := os.ReadFile(filepath.Join("certs", "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+	anonymous := credentials.NewTLS(&tls.Config{RootCAs: pool, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(anonymous))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := adminpb.NewAdminServiceClient(conn).GetStatus(context.Background(), &adminpb.StatusRequest{}); err == nil {
+		t.Error("GetStatus without a client certificate succeeded")
+	}
+
+	tlsManager, err := security.NewTLSManager(security.TLSConfig{Enabled: true, ServerName: "localhost"}, "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialOpts, err := tlsManager.NewClientDialOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err = grpc.NewClient(address, dialOpts...)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := adminpb.NewAdminServiceClient(conn)
+	if _, err := client.GetStatus(context.Background(), &adminpb.StatusRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetStatus with a client certificate and no token = %v, want Unauthenticated", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	st, err := client.GetStatus(ctx, &adminpb.StatusRequest{})
+	if err != nil || st.State != string(ControlIdle) {
+		t.Errorf("GetStatus with a client certificate = %v, %v", st, err)
+	}
+}
@@ -3,17 +3,22 @@ package aggregator
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net"
-	"os"
+	"slices"
 	"sync"
 	"time"
 
 	pb "github.com/ishaileshpant/fl-go/api"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
 	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -24,6 +29,8 @@ type Aggregator interface {
 	JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error)
 	SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error)
 	GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error)
+	Report() *RunReport
+	Manifest() *RunManifest
 }
 
 // UpdateInfo tracks update metadata for async FL
@@ -33,38 +40,87 @@ type UpdateInfo struct {
 	Timestamp      time.Time
 	Round          int
 	Staleness      int
+	Base           []float32 // global model the update was trained on
 }
 
 // FedAvgAggregator implements synchronous multi-round FedAvg (existing implementation)
 type FedAvgAggregator struct {
 	pb.UnimplementedFederatedLearningServer
+	*runControl
 	plan         *federation.FLPlan
+	attestation  *attestationGate // admits collaborators by their attestation evidence
 	mu           sync.Mutex
-	updates      [][]float32
+	updates      []weightedUpdate
+	stream       *runningSum   // sum of the round's updates when they are streamed
+	quorum       *updateQuorum // reached once the round holds every expected update
+	clipped      int           // number of the round's updates that were clipped
 	modelSize    int
 	currentRound int
 	srv          *grpc.Server
+	submitted    map[string]bool
+	drops        *DropTracker
+	reporter     *UpdateReporter
+	archive      *UpdateArchive
+	wal          *UpdateWAL // nil unless the plan logs updates
+	run          *RunRecorder
+	models       *ModelRegistrar
+	globalModel  []byte        // encoded model trained in the current round
+	modelHash    string        // hash of globalModel, which updates must be trained on
+	mapped       *mappedModel  // backs globalModel when the plan maps the model
+	history      *modelHistory // recent models to send diffs from, with delta transfer
+	events       *eventBroker
+	signer       *security.ModelSigner // nil unless the plan signs models
+
+	// Homomorphic aggregation state; nil unless the plan encrypts updates
+	heKey     *security.PaillierPublicKey
+	keyHolder security.KeyHolder
+	encrypted *security.EncryptedSum // sum of the round's encrypted updates
+
+	// Task dispatch state
+	hyperparameters map[string]interface{} // train args of the current round, after the schedule
+	aggregated      int                    // latest aggregated round, whose model is globalModel
+	evaluated       map[string]int         // evaluator ID -> latest round it was asked to evaluate
+	released        map[string]bool        // collaborators that were told to quit
+	finished        bool
 }
 
 // AsyncFedAvgAggregator implements asynchronous FedAvg based on Papaya paper
 type AsyncFedAvgAggregator struct {
 	pb.UnimplementedFederatedLearningServer
+	*runControl
 	plan         *federation.FLPlan
+	attestation  *attestationGate // admits collaborators by their attestation evidence
 	mu           sync.Mutex
 	updates      []UpdateInfo
 	modelSize    int
 	currentRound int
 	srv          *grpc.Server
 	globalModel  []float32
+	bases        *baseModels // global models handed out, to clip and score updates against
 	lastUpdate   time.Time
-	stopChan     chan struct{}
+	lastDelta    float64       // relative model change of the latest aggregation
+	done         chan string   // completion reason, sent by the aggregation loop
+	tuned        chan struct{} // signals a runtime change of the async config
+	triggered    chan struct{} // signals an admin request to aggregate now
+	delays       *delayController
+	drops        *DropTracker
+	inclusion    *inclusionTracker
+	reporter     *UpdateReporter
+	run          *RunRecorder
+	models       *ModelRegistrar
+	signer       *security.ModelSigner // nil unless the plan signs models
+	events       *eventBroker
 }
 
-// NewAggregator creates the appropriate aggregator based on mode and algorithm
+// NewAggregator creates the appropriate aggregator based on role, mode and algorithm
 func NewAggregator(plan *federation.FLPlan) Aggregator {
-	// Check if a specific algorithm is requested
-	if plan.Algorithm.Name != "" && plan.Algorithm.Name != "fedavg" {
-		// Use modular aggregator for advanced algorithms
+	if plan.Role == federation.RoleEdgeAggregator {
+		return NewEdgeAggregator(plan)
+	}
+
+	// Check if a specific algorithm or clustering is requested
+	if (plan.Algorithm.Name != "" && plan.Algorithm.Name != "fedavg") || plan.Clustering.Enabled {
+		// Use modular aggregator for advanced algorithms and clustering
 		modularAgg, err := NewModularAggregator(plan)
 		if err != nil {
 			log.Printf("Failed to create modular aggregator: %v, falling back to FedAvg", err)
@@ -84,21 +140,87 @@ func NewAggregator(plan *federation.FLPlan) Aggregator {
 }
 
 func NewFedAvgAggregator(plan *federation.FLPlan) *FedAvgAggregator {
-	return &FedAvgAggregator{plan: plan}
+	drops := NewDropTracker(plan)
+	run := NewRunRecorder(plan, drops)
+	return &FedAvgAggregator{
+		runControl:  newRunControl(),
+		plan:        plan,
+		attestation: newAttestationGate(plan),
+		submitted:   make(map[string]bool),
+		drops:       drops,
+		reporter:    NewUpdateReporter(plan),
+		archive:     NewUpdateArchive(plan),
+		run:         run,
+		models:      NewModelRegistrar(plan, run),
+		history:     newModelHistory(plan),
+		evaluated:   make(map[string]int),
+		released:    make(map[string]bool),
+		events:      newEventBroker(),
+	}
 }
 
 func NewAsyncFedAvgAggregator(plan *federation.FLPlan) *AsyncFedAvgAggregator {
+	drops := NewDropTracker(plan)
+	run := NewRunRecorder(plan, drops)
 	return &AsyncFedAvgAggregator{
-		plan:     plan,
-		stopChan: make(chan struct{}),
+		runControl:  newRunControl(),
+		plan:        plan,
+		attestation: newAttestationGate(plan),
+		bases:       newBaseModels(),
+		done:        make(chan string, 1),
+		tuned:       make(chan struct{}, 1),
+		triggered:   make(chan struct{}, 1),
+		delays:      newDelayController(),
+		drops:       drops,
+		inclusion:   newInclusionTracker(plan),
+		reporter:    NewUpdateReporter(plan),
+		run:         run,
+		models:      NewModelRegistrar(plan, run),
+		events:      newEventBroker(),
 	}
 }
 
 // Synchronous Aggregator Implementation (existing)
-func (a *FedAvgAggregator) Start(ctx context.Context) error {
+func (a *FedAvgAggregator) Start(ctx context.Context) (err error) {
+	a.run.Start()
+	defer func() { a.run.Finish(err) }()
+	ctx, stop := a.runControl.start(ctx)
+	defer stop()
+
+	if a.signer, err = loadSigner(a.plan); err != nil {
+		return err
+	}
+	a.run.SetSigner(a.signer)
+	if a.heKey, a.keyHolder, err = loadHomomorphic(a.plan); err != nil {
+		return err
+	}
+	if a.wal, err = OpenUpdateWAL(a.plan); err != nil {
+		return fmt.Errorf("failed to open the update WAL: %w", err)
+	}
+	defer a.wal.Close()
+	recovered, err := a.wal.Recover(a.plan.Rounds)
+	if err != nil {
+		return fmt.Errorf("failed to recover from the update WAL: %w", err)
+	}
+
 	log.Printf("Starting SYNC aggregator on %s", a.plan.Aggregator.Address)
 	log.Printf("Expecting %d collaborators for %d rounds", len(a.plan.Collaborators), a.plan.Rounds)
 
+	if a.plan.Aggregator.MmapModel {
+		if a.mapped, err = openMappedModel(a.plan.InitialModel, "save"); err != nil {
+			return fmt.Errorf("failed to map global model: %w", err)
+		}
+		defer func() {
+			// No request may read the model once it is unmapped
+			if a.srv != nil {
+				a.srv.Stop()
+			}
+			if err := a.mapped.Close(); err != nil {
+				log.Printf("Failed to unmap global model: %v", err)
+			}
+		}()
+	}
+
 	lis, err := net.Listen("tcp", a.plan.Aggregator.Address)
 	if err != nil {
 		return err
@@ -120,9 +242,17 @@ func (a *FedAvgAggregator) Start(ctx context.Context) error {
 	if len(serverOpts) == 0 {
 		serverOpts = []grpc.ServerOption{grpc.Creds(insecure.NewCredentials())}
 	}
+	serverOpts = append(serverOpts, transport.ServerOptions(a.plan.GRPC)...)
 
 	a.srv = grpc.NewServer(serverOpts...)
 	pb.RegisterFederatedLearningServer(a.srv, a)
+	// Whichever way the run ends, the server stops with it
+	defer a.srv.Stop()
+	stopAdmin, err := serveAdmin(a.plan, tlsManager, a)
+	if err != nil {
+		return err
+	}
+	defer stopAdmin()
 
 	// Start gRPC server in background
 	go func() {
@@ -133,126 +263,466 @@ func (a *FedAvgAggregator) Start(ctx context.Context) error {
 	}()
 
 	// Read initial model to determine size
-	data, err := os.ReadFile(a.plan.InitialModel)
-	if err != nil {
+	var data []byte
+	if a.mapped != nil {
+		data = a.mapped.initial
+	} else if data, err = storage.ReadFile(a.plan.InitialModel); err != nil {
 		return err
+	} else if err := transport.CheckWeights(data, 0); err != nil {
+		// A mapped model was checked when it was mapped
+		return fmt.Errorf("invalid initial model %s: %v", a.plan.InitialModel, err)
 	}
 	a.modelSize = len(data) / 4
+	initialHash := transport.ModelHash(data)
+	a.mu.Lock()
+	a.globalModel = data
+	a.modelHash = initialHash
+	a.history.Add(initialHash, data)
+	a.mu.Unlock()
+	a.run.RecordModel(0, initialHash)
 	log.Printf("Model size: %d parameters", a.modelSize)
 
-	// Run federated learning for specified rounds
-	for round := 1; round <= a.plan.Rounds; round++ {
-		a.currentRound = round
+	// Resume the round the WAL was interrupted in, from the model it started
+	// from
+	first, basePath := 1, a.plan.InitialModel
+	if recovered != nil {
+		if len(recovered.BaseModel) != len(data) {
+			return fmt.Errorf("the base model of recovered round %d has %d bytes, the initial model %d", recovered.Round, len(recovered.BaseModel), len(data))
+		}
+		base := recovered.BaseModel
+		if a.mapped != nil {
+			base = a.mapped.Next()
+			copy(base, recovered.BaseModel)
+		}
+		a.mu.Lock()
+		a.globalModel = base
+		a.modelHash = recovered.BaseModelHash
+		a.history.Add(recovered.BaseModelHash, base)
+		a.aggregated = recovered.Round - 1
+		a.mu.Unlock()
+		if recovered.Round > 1 {
+			a.run.RecordModel(recovered.Round-1, recovered.BaseModelHash)
+		}
+		first, basePath = recovered.Round, recovered.BaseModelPath
+		log.Printf("Resuming round %d from the update WAL with %d logged updates", recovered.Round, len(recovered.Updates))
+	}
+	startMetricsServer(ctx, a.plan, a.drops)
+
+	// Run federated learning for specified rounds. Each round is scored,
+	// registered and reported while the next one trains.
+	expected := a.expectedUpdates()
+	pipeline := newRoundPipeline()
+	defer pipeline.Close()
+	for round := first; round <= a.plan.Rounds; round++ {
+		if err := a.runControl.wait(ctx, round); err != nil {
+			log.Printf("Stopping before round %d: %v", round, err)
+			a.events.Close("the aggregator is shutting down")
+			return err
+		}
 		log.Printf("Starting round %d/%d", round, a.plan.Rounds)
+		roundStart := time.Now()
+		hyperparameters, err := scheduledArgs(a.plan.Tasks.Train, round, a.plan.Rounds)
+		if err != nil {
+			return err
+		}
+		if len(a.plan.Tasks.Train.Schedule) > 0 {
+			log.Printf("Round %d hyperparameters: %v", round, hyperparameters)
+		}
 
 		// Reset updates for new round
 		a.mu.Lock()
-		a.updates = make([][]float32, 0)
+		a.currentRound = round
+		a.hyperparameters = hyperparameters
+		a.updates = make([]weightedUpdate, 0)
+		if a.plan.Aggregator.Streaming {
+			a.stream = newRunningSum(a.modelSize)
+		}
+		if a.heKey != nil {
+			a.encrypted = security.NewEncryptedSum(a.heKey, a.modelSize)
+		}
+		a.submitted = make(map[string]bool)
+		a.quorum = newUpdateQuorum(expected)
+		a.clipped = 0
+		modelHash := a.modelHash
+		quorum := a.quorum
+		a.archive.StartRound(round, string(FedAvg), nil, clipNorm(a.plan), a.globalModel)
+		// Opened under the lock, so that no update of the round is logged
+		// before the segment is
+		err = a.wal.StartRound(round, basePath, modelHash)
 		a.mu.Unlock()
-
-		// Wait for all collaborators to submit updates
-		log.Printf("Waiting for %d collaborators to submit updates...", len(a.plan.Collaborators))
-		for {
-			a.mu.Lock()
-			updateCount := len(a.updates)
-			a.mu.Unlock()
-
-			if updateCount >= len(a.plan.Collaborators) {
-				log.Printf("Received updates from all %d collaborators", updateCount)
-				break
+		if err != nil {
+			return fmt.Errorf("failed to log round %d: %w", round, err)
+		}
+		if recovered != nil && recovered.Round == round {
+			for _, upd := range recovered.Updates {
+				if ack := a.accept(upd); !ack.Success {
+					log.Printf("Dropped the logged update of %s: %s", upd.CollaboratorId, ack.Message)
+				}
 			}
+		}
+
+		var scheduled map[string]string
+		if len(a.plan.Tasks.Train.Schedule) > 0 {
+			scheduled = taskHyperparameters(hyperparameters)
+		}
+		a.events.RoundStarted(round, modelHash, scheduled)
+		a.run.RecordHyperparameters(round, taskHyperparameters(hyperparameters))
 
-			log.Printf("Received %d/%d updates, waiting...", updateCount, len(a.plan.Collaborators))
-			time.Sleep(2 * time.Second) // Check every 2 seconds
+		// Wait for all collaborators to submit updates
+		if err := quorum.Wait(ctx, round, a.updateCount); err != nil {
+			a.events.Close("the aggregator is shutting down")
+			return err
 		}
 
-		// Aggregate the updates
+		// Aggregate the updates, weighted by sample count so that partial
+		// aggregates from edge aggregators count for all of their updates
 		log.Printf("Aggregating updates for round %d", round)
-		avg := make([]float32, a.modelSize)
+		aggregationStart := time.Now()
 		a.mu.Lock()
-		for _, upd := range a.updates {
-			for i, v := range upd {
-				avg[i] += v
+		updates, stream, clipped, encrypted := a.updates, a.stream, a.clipped, a.encrypted
+		previous := a.globalModel
+		a.mu.Unlock()
+		var buf []byte
+		switch {
+		case encrypted != nil:
+			if buf, err = a.decryptRound(ctx, round, encrypted, updates); err != nil {
+				return err
 			}
+		case a.mapped != nil && stream != nil:
+			a.history.Retain(1) // the next buffer may hold the previous model
+			buf = a.mapped.Next()
+			stream.AverageInto(buf)
+		case a.mapped != nil:
+			a.history.Retain(1)
+			buf = a.mapped.Next()
+			weightedAverageInto(updates, buf)
+		case stream != nil:
+			avg, _ := stream.Average()
+			buf = encodeModel(avg)
+		default:
+			avg, _ := weightedAverage(updates, a.modelSize)
+			buf = encodeModel(avg)
 		}
-		a.mu.Unlock()
-
-		for i := range avg {
-			avg[i] /= float32(len(a.updates))
+		updateCount := len(updates)
+		var score func()
+		if stream == nil && encrypted == nil {
+			// Streamed and encrypted updates are not kept to be scored. The
+			// models are decoded now, since later rounds reuse mapped buffers.
+			score = a.contributionScorer(round, updates, previous, buf)
 		}
 
 		// Save aggregated model
-		buf := make([]byte, 4*a.modelSize)
-		for i, v := range avg {
-			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
-		}
-
 		outputPath := a.plan.OutputModel
 		if round < a.plan.Rounds {
 			// For intermediate rounds, save to save directory
 			outputPath = fmt.Sprintf("save/round_%d_model.pt", round)
 		}
 
-		if err := os.WriteFile(outputPath, buf, 0600); err != nil {
+		if err := storage.WriteFile(outputPath, buf); err != nil {
 			return err
 		}
-		log.Printf("Round %d complete, model saved to %s", round, outputPath)
+		hash := transport.ModelHash(buf)
+		if err := a.wal.Commit(round, outputPath, hash); err != nil {
+			// The round is aggregated again after a restart
+			log.Printf("Warning: failed to commit round %d to the update WAL: %v", round, err)
+		}
+		basePath = outputPath
+		a.mu.Lock()
+		a.globalModel = buf
+		a.modelHash = hash
+		a.history.Add(hash, buf)
+		a.aggregated = round
+		a.mu.Unlock()
+		end := time.Now()
+		pipeline.Go(func() {
+			if score != nil {
+				score()
+			}
+			a.run.RecordRound(round, outputPath)
+			a.run.RecordModel(round, hash)
+			a.archive.FinishRound(round, outputPath, hash)
+			a.run.SetFinalEncodedModel(buf)
+			a.models.RegisterEncoded(round, buf)
+			a.reporter.RecordAggregation(aggregationMetrics(round, "fedavg", aggregationStart, updateCount, clipped, clipNorm(a.plan)))
+			a.reporter.RecordRound(monitoring.RoundMetrics{
+				RoundNumber:      round,
+				Algorithm:        "fedavg",
+				StartTime:        roundStart,
+				EndTime:          &end,
+				Duration:         end.Sub(roundStart),
+				ParticipantCount: expected,
+				UpdatesReceived:  updateCount,
+				Hyperparameters:  hyperparameters,
+				Status:           "completed",
+			})
+			log.Printf("Round %d complete, model saved to %s", round, outputPath)
+		})
 	}
 
+	pipeline.Close()
 	log.Printf("All %d rounds completed successfully", a.plan.Rounds)
-	a.srv.Stop()
+	a.events.Close("all rounds completed")
+	if a.polling() > 0 {
+		a.drainDispatch(ctx)
+	}
+	stopServing(a.srv)
 	return nil
 }
 
+// Report returns the run summary
+func (a *FedAvgAggregator) Report() *RunReport {
+	return a.run.Report()
+}
+
+// Manifest returns the reproducibility manifest of the run
+func (a *FedAvgAggregator) Manifest() *RunManifest {
+	return a.run.Manifest()
+}
+
+// RoundState returns the state of the round being collected
+func (a *FedAvgAggregator) RoundState() RoundState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return syncRoundState(a.currentRound, a.modelHash, collaboratorIDs(a.plan, false), a.submitted)
+}
+
+// AggregateNow ends the current round with the updates it holds
+func (a *FedAvgAggregator) AggregateNow() (RoundState, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := syncRoundState(a.currentRound, a.modelHash, collaboratorIDs(a.plan, false), a.submitted)
+	return state, a.quorum.Release(len(a.submitted))
+}
+
 func (a *FedAvgAggregator) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
 	log.Printf("Collaborator %s joining federation", req.CollaboratorId)
-	data, err := os.ReadFile(a.plan.InitialModel)
+	capabilities, err := negotiate(ctx, a.plan, a.attestation, syncCapabilities(a.plan), req)
+	if err != nil {
+		return nil, err
+	}
+	if a.mapped != nil {
+		return &pb.JoinResponse{
+			InitialModel:   a.mapped.initial,
+			Capabilities:   capabilities,
+			ModelSignature: signEncoded(a.signer, a.mapped.initial),
+		}, nil
+	}
+	data, err := storage.ReadFile(a.plan.InitialModel)
 	if err != nil {
 		log.Printf("Warning: Could not read initial model %s: %v", a.plan.InitialModel, err)
 		// Return empty model if file doesn't exist
-		return &pb.JoinResponse{InitialModel: []byte{}}, nil
+		return &pb.JoinResponse{InitialModel: []byte{}, Capabilities: capabilities}, nil
 	}
-	return &pb.JoinResponse{InitialModel: data}, nil
+	return &pb.JoinResponse{InitialModel: data, Capabilities: capabilities, ModelSignature: signEncoded(a.signer, data)}, nil
 }
 
 func (a *FedAvgAggregator) SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error) {
-	floats := make([]float32, len(upd.ModelWeights)/4)
-	for i := range floats {
-		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(upd.ModelWeights[i*4:]))
+	if err := a.attestation.authorize(upd.CollaboratorId); err != nil {
+		return nil, err
+	}
+	return a.accept(upd), nil
+}
+
+// WatchEvents streams round starts to a collaborator until the federation ends
+func (a *FedAvgAggregator) WatchEvents(req *pb.WatchRequest, stream pb.FederatedLearning_WatchEventsServer) error {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return err
 	}
+	return a.events.Watch(stream.Context(), req.CollaboratorId, stream.Send)
+}
+
+// updateCount returns the number of updates of the current round
+func (a *FedAvgAggregator) updateCount() int {
 	a.mu.Lock()
-	a.updates = append(a.updates, floats)
-	updateCount := len(a.updates)
+	defer a.mu.Unlock()
+	return len(a.updates)
+}
+
+// contributionScorer decodes the encoded models previous and next and returns
+// a function that scores and reports the contributions of a round's updates
+// to the change between them, or nil when the models cannot be decoded
+func (a *FedAvgAggregator) contributionScorer(round int, updates []weightedUpdate, previous, next []byte) func() {
+	before, _, err := decodeUpdate(previous, a.modelSize)
+	if err != nil {
+		log.Printf("Warning: failed to score contributions of round %d: %v", round, err)
+		return nil
+	}
+	after, _, err := decodeUpdate(next, a.modelSize)
+	if err != nil {
+		log.Printf("Warning: failed to score contributions of round %d: %v", round, err)
+		return nil
+	}
+	clientUpdates := make([]ClientUpdate, len(updates))
+	for k, update := range updates {
+		clientUpdates[k] = ClientUpdate{
+			CollaboratorID: update.collaboratorID,
+			Weights:        update.weights,
+			NumSamples:     int(update.numSamples),
+		}
+	}
+	base := func(ClientUpdate) []float32 { return before }
+	return func() {
+		reportContributions(a.reporter, round, scoreContributions(round, clientUpdates, base, before, after))
+	}
+}
+
+// SubmitPartialAggregate accepts the round's aggregate of an edge aggregator,
+// which is listed among the plan's collaborators under its edge ID
+func (a *FedAvgAggregator) SubmitPartialAggregate(ctx context.Context, partial *pb.PartialAggregate) (*pb.Ack, error) {
+	log.Printf("Received partial aggregate from edge %s (%d updates, %d samples)",
+		partial.AggregatorId, partial.NumUpdates, partial.NumSamples)
+	return a.accept(&pb.ModelUpdate{
+		CollaboratorId: partial.AggregatorId,
+		ModelWeights:   partial.ModelWeights,
+		NumSamples:     partial.NumSamples,
+		Round:          partial.Round,
+	}), nil
+}
+
+// accept validates an update and adds it to the current round
+func (a *FedAvgAggregator) accept(upd *pb.ModelUpdate) *pb.Ack {
+	collaboratorID := upd.CollaboratorId
+	a.mu.Lock()
+	round, modelHash, model := a.currentRound, a.modelHash, a.globalModel
 	a.mu.Unlock()
+	if round == 0 {
+		return notStarted()
+	}
+	if role, _ := federation.RoleOf(a.plan, collaboratorID); role == federation.CollaboratorEvaluator {
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonValidationFailed, "evaluators do not contribute updates")
+	}
+	if a.heKey != nil || upd.Encrypted {
+		return a.acceptEncrypted(upd, round, modelHash)
+	}
 
-	log.Printf("Received update %d/%d for round %d", updateCount, len(a.plan.Collaborators), a.currentRound)
-	return &pb.Ack{Success: true}, nil
+	size := len(upd.ModelWeights)
+	if upd.Delta {
+		// A diff can only be expanded against the model it was trained on
+		if reason, detail := checkRound(upd, round, modelHash); reason != "" {
+			return a.drops.Reject(collaboratorID, round, reason, detail)
+		}
+		if err := expandDelta(upd, model, modelHash); err != nil {
+			return a.drops.Reject(collaboratorID, round, monitoring.DropReasonValidationFailed, err.Error())
+		}
+	}
+
+	floats, reason, err := decodeStreamed(upd.ModelWeights, a.modelSize, a.plan.Aggregator.Streaming)
+	if err != nil {
+		return a.drops.Reject(collaboratorID, round, reason, err.Error())
+	}
+	if reason, detail := checkRound(upd, round, modelHash); reason != "" {
+		return a.drops.Reject(collaboratorID, round, reason, detail)
+	}
+	if err := a.wal.Append(round, upd); err != nil {
+		return notLogged(err)
+	}
+	var raw []byte
+	if a.archive != nil {
+		// Clipping scales the weights in place
+		raw = slices.Clone(upd.ModelWeights)
+	}
+	maxNorm := clipNorm(a.plan)
+	norm, clipped := clipEncoded(upd.ModelWeights, model, maxNorm)
+	if clipped {
+		logClipped(collaboratorID, round, norm, maxNorm)
+		if floats != nil {
+			floats, _, _ = decodeUpdate(upd.ModelWeights, a.modelSize)
+		}
+	}
+
+	a.mu.Lock()
+	if a.submitted[collaboratorID] {
+		a.mu.Unlock()
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonDuplicate,
+			fmt.Sprintf("collaborator already submitted an update for round %d", round))
+	}
+	a.submitted[collaboratorID] = true
+	if clipped {
+		a.clipped++
+	}
+	if a.stream != nil {
+		a.stream.Add(upd.ModelWeights, sampleCount(upd.NumSamples))
+	}
+	a.updates = append(a.updates, weightedUpdate{weights: floats, numSamples: sampleCount(upd.NumSamples), collaboratorID: collaboratorID})
+	updateCount := len(a.updates)
+	a.quorum.Received(updateCount)
+	a.mu.Unlock()
+	a.archive.Add(round, collaboratorID, sampleCount(upd.NumSamples), upd.LocalSteps, raw)
+	a.run.RecordUpdate(collaboratorID, round)
+	a.run.RecordTraining(upd.Metrics)
+	a.reporter.Record(ClientUpdate{
+		CollaboratorID:    collaboratorID,
+		Weights:           floats,
+		Size:              size,
+		Timestamp:         time.Now(),
+		Round:             round,
+		NumSamples:        int(sampleCount(upd.NumSamples)),
+		ImageDigest:       upd.ImageDigest,
+		DPClipNorm:        upd.Privacy.GetClipNorm(),
+		DPNoiseMultiplier: upd.Privacy.GetNoiseMultiplier(),
+		TrainingMetrics:   upd.Metrics,
+	})
+
+	logging.Debugf("Accepted the update of %s for round %d (%d samples)", collaboratorID, round, sampleCount(upd.NumSamples))
+	logging.Infof("Received update %d/%d for round %d", updateCount, a.expectedUpdates(), round)
+	return &pb.Ack{Success: true}
 }
 
 func (a *FedAvgAggregator) GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error) {
-	// In sync mode, return the initial model since rounds are synchronized
-	data, err := os.ReadFile(a.plan.InitialModel)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read initial model: %v", err)
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return nil, err
+	}
+	// The model collaborators train on in the current round
+	a.mu.Lock()
+	data, modelHash, round := a.globalModel, a.modelHash, a.currentRound
+	a.mu.Unlock()
+	if resp := notModified(req, modelHash, clampInt32(round)); resp != nil {
+		return resp, nil
+	}
+	// The signature covers the full model, which a diff expands to
+	signature := a.signer.Sign(modelHash)
+	var baseHash string
+	if delta, ok := a.history.Delta(req.KnownModelHash, modelHash); ok {
+		data, baseHash = delta, req.KnownModelHash
+	}
+	if data == nil {
+		var err error
+		if data, err = storage.ReadFile(a.plan.InitialModel); err != nil {
+			return nil, fmt.Errorf("failed to read initial model: %v", err)
+		}
+		signature = signEncoded(a.signer, data)
 	}
 
 	// Safely convert int to int32 to prevent overflow
 	var currentRound int32
-	if a.currentRound > math.MaxInt32 {
-		log.Printf("Warning: current round %d exceeds int32 max, capping at %d", a.currentRound, math.MaxInt32)
+	if round > math.MaxInt32 {
+		log.Printf("Warning: current round %d exceeds int32 max, capping at %d", round, math.MaxInt32)
 		currentRound = math.MaxInt32
 	} else {
-		currentRound = int32(a.currentRound) // #nosec G115 - Safe conversion with bounds check above
+		currentRound = int32(round) // #nosec G115 - Safe conversion with bounds check above
 	}
 
 	return &pb.GetModelResponse{
-		ModelWeights: data,
-		CurrentRound: currentRound,
+		ModelWeights:   data,
+		CurrentRound:   currentRound,
+		BaseModelHash:  baseHash,
+		ModelHash:      modelHash,
+		ModelSignature: signature,
 	}, nil
 }
 
 // Asynchronous Aggregator Implementation (new)
-func (a *AsyncFedAvgAggregator) Start(ctx context.Context) error {
+func (a *AsyncFedAvgAggregator) Start(ctx context.Context) (err error) {
+	a.run.Start()
+	defer func() { a.run.Finish(err) }()
+	ctx, stop := a.runControl.start(ctx)
+	defer stop()
+
+	if a.signer, err = loadSigner(a.plan); err != nil {
+		return err
+	}
+	a.run.SetSigner(a.signer)
+
 	log.Printf("Starting ASYNC aggregator on %s", a.plan.Aggregator.Address)
 	log.Printf("Async config: max_staleness=%d, min_updates=%d, delay=%ds",
 		a.plan.AsyncConfig.MaxStaleness, a.plan.AsyncConfig.MinUpdates, a.plan.AsyncConfig.AggregationDelay)
@@ -278,9 +748,16 @@ func (a *AsyncFedAvgAggregator) Start(ctx context.Context) error {
 	if len(serverOpts) == 0 {
 		serverOpts = []grpc.ServerOption{grpc.Creds(insecure.NewCredentials())}
 	}
+	serverOpts = append(serverOpts, transport.ServerOptions(a.plan.GRPC)...)
 
 	a.srv = grpc.NewServer(serverOpts...)
 	pb.RegisterFederatedLearningServer(a.srv, a)
+	defer a.srv.Stop()
+	stopAdmin, err := serveAdmin(a.plan, tlsManager, a)
+	if err != nil {
+		return err
+	}
+	defer stopAdmin()
 
 	// Start gRPC server in background
 	go func() {
@@ -291,100 +768,213 @@ func (a *AsyncFedAvgAggregator) Start(ctx context.Context) error {
 	}()
 
 	// Read initial model to determine size and set as global model
-	data, err := os.ReadFile(a.plan.InitialModel)
+	data, err := storage.ReadFile(a.plan.InitialModel)
 	if err != nil {
 		return err
 	}
-	a.modelSize = len(data) / 4
-	a.globalModel = make([]float32, a.modelSize)
-	for i := range a.globalModel {
-		a.globalModel[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	if a.globalModel, err = transport.DecodeWeights(data, 0); err != nil {
+		return fmt.Errorf("invalid initial model %s: %v", a.plan.InitialModel, err)
 	}
+	a.modelSize = len(a.globalModel)
+	a.run.RecordModel(0, transport.ModelHash(data))
 	log.Printf("Model size: %d parameters", a.modelSize)
+	startMetricsServer(ctx, a.plan, metricsHandlers{a.drops, a.inclusion})
 
 	// Start async aggregation loop
-	go a.asyncAggregationLoop()
-
-	// Wait for completion signal (could be based on time, rounds, or other criteria)
-	<-ctx.Done()
-
-	log.Printf("Async FL completed")
+	stopLoop := startAsyncLoop(ctx, a.asyncAggregationLoop)
+
+	// Run until a completion criterion is met or the run is stopped
+	deadline, stopDeadline := asyncDeadline(a.plan.AsyncConfig.MaxDuration)
+	defer stopDeadline()
+
+	reason := CompletionStopped
+	select {
+	case reason = <-a.done:
+	case <-deadline:
+		reason = CompletionMaxDuration
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), ErrAborted) {
+			reason = CompletionAborted
+		}
+	}
+	stopLoop()
+	a.events.Close(fmt.Sprintf("the federation completed (%s)", reason))
 	a.srv.Stop()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := saveFinalAsyncModel(a.plan, a.run, a.models, a.currentRound, a.globalModel, reason); err != nil {
+		return err
+	}
+	if reason == CompletionAborted {
+		return ErrAborted
+	}
 	return nil
 }
 
-func (a *AsyncFedAvgAggregator) asyncAggregationLoop() {
-	ticker := time.NewTicker(time.Duration(a.plan.AsyncConfig.AggregationDelay) * time.Second)
+func (a *AsyncFedAvgAggregator) asyncAggregationLoop(ctx context.Context) {
+	a.mu.Lock()
+	ticker := time.NewTicker(a.delays.Next(a.plan.AsyncConfig))
+	a.mu.Unlock()
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
+			// A paused federation keeps collecting updates for when it resumes
 			a.mu.Lock()
-			updateCount := len(a.updates)
+			ready := a.aggregatable() >= a.plan.AsyncConfig.MinUpdates && !a.paused()
 			a.mu.Unlock()
 
-			if updateCount >= a.plan.AsyncConfig.MinUpdates {
-				a.performAsyncAggregation()
+			if ready && a.aggregateUntilDone() {
+				return
+			}
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-a.triggered:
+			if a.aggregateUntilDone() {
+				return
 			}
-		case <-a.stopChan:
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-a.tuned:
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (a *AsyncFedAvgAggregator) performAsyncAggregation() {
+// aggregateUntilDone aggregates the pending updates and reports whether the
+// new model completed the federation
+func (a *AsyncFedAvgAggregator) aggregateUntilDone() bool {
+	if !a.performAsyncAggregation() {
+		return false
+	}
+	a.mu.Lock()
+	reason := asyncCompletion(a.plan.AsyncConfig, a.currentRound, a.lastDelta)
+	a.mu.Unlock()
+
+	if reason == "" {
+		return false
+	}
+	a.done <- reason
+	return true
+}
+
+// performAsyncAggregation aggregates the pending updates and reports whether
+// a new global model was produced
+func (a *AsyncFedAvgAggregator) performAsyncAggregation() bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	if len(a.updates) == 0 {
-		return
+		return false
 	}
 
-	log.Printf("Performing async aggregation with %d updates", len(a.updates))
+	// Updates beyond a collaborator's share wait for the next aggregation
+	selected, deferred := selectFair(len(a.updates), a.collaboratorAt, a.plan.AsyncConfig.MaxPerCollaborator)
+	batch := make([]UpdateInfo, len(selected))
+	for k, i := range selected {
+		batch[k] = a.updates[i]
+	}
+	pending := make([]UpdateInfo, len(deferred))
+	for k, i := range deferred {
+		pending[k] = a.updates[i]
+	}
+	a.updates = pending
+
+	log.Printf("Performing async aggregation with %d updates (%d deferred)", len(batch), len(pending))
 
 	// Calculate staleness for each update
 	currentTime := time.Now()
-	for i := range a.updates {
-		a.updates[i].Staleness = int(currentTime.Sub(a.updates[i].Timestamp).Seconds())
+	for i := range batch {
+		batch[i].Staleness = int(currentTime.Sub(batch[i].Timestamp).Seconds())
 	}
 
 	// Filter out updates that are too stale
 	validUpdates := make([]UpdateInfo, 0)
-	for _, update := range a.updates {
+	for _, update := range batch {
 		if update.Staleness <= a.plan.AsyncConfig.MaxStaleness {
 			validUpdates = append(validUpdates, update)
 		} else {
-			log.Printf("Dropping stale update from %s (staleness: %d)",
-				update.CollaboratorID, update.Staleness)
+			a.drops.Record(update.CollaboratorID, update.Round, monitoring.DropReasonStale,
+				fmt.Sprintf("staleness %ds exceeds max %ds", update.Staleness, a.plan.AsyncConfig.MaxStaleness))
 		}
 	}
 
 	if len(validUpdates) == 0 {
 		log.Printf("No valid updates to aggregate")
-		return
+		return false
+	}
+	staleness := make([]int, len(validUpdates))
+	waits := make([]time.Duration, len(validUpdates))
+	for k, update := range validUpdates {
+		staleness[k] = update.Staleness
+		waits[k] = currentTime.Sub(update.Timestamp)
+	}
+	a.run.RecordStaleness(staleness)
+	a.delays.Observe(a.plan.AsyncConfig, waits)
+	included := make([]string, len(validUpdates))
+	for k, update := range validUpdates {
+		included[k] = update.CollaboratorID
+	}
+	a.inclusion.Included(included)
+	a.run.RecordAggregated(included)
+
+	// Clip the updates against the models they were trained on before
+	// aggregating them
+	maxNorm := clipNorm(a.plan)
+	clipped := 0
+	for _, update := range validUpdates {
+		if norm, ok := clipUpdate(update.Weights, update.Base, maxNorm); ok {
+			logClipped(update.CollaboratorID, update.Round, norm, maxNorm)
+			clipped++
+		}
 	}
 
 	// Perform staleness-aware aggregation
 	newModel := make([]float32, a.modelSize)
 	totalWeight := 0.0
 
-	for _, update := range validUpdates {
+	weights := make([]float32, len(validUpdates))
+	for k, update := range validUpdates {
 		// Apply staleness weight decay
 		weight := math.Pow(a.plan.AsyncConfig.StalenessWeight, float64(update.Staleness))
+		weights[k] = float32(weight)
 		totalWeight += weight
-
-		for i, v := range update.Weights {
-			newModel[i] += float32(weight) * v
-		}
 	}
 
-	// Normalize by total weight
-	for i := range newModel {
-		newModel[i] /= float32(totalWeight)
+	parallelFor(a.modelSize, func(lo, hi int) {
+		for k, update := range validUpdates {
+			for i := lo; i < min(hi, len(update.Weights)); i++ {
+				newModel[i] += weights[k] * update.Weights[i]
+			}
+		}
+		// Normalize by total weight
+		for i := lo; i < hi; i++ {
+			newModel[i] /= float32(totalWeight)
+		}
+	})
+
+	scored := make([]ClientUpdate, len(validUpdates))
+	for k, update := range validUpdates {
+		scored[k] = ClientUpdate{
+			CollaboratorID:  update.CollaboratorID,
+			Weights:         update.Weights,
+			Base:            update.Base,
+			NumSamples:      1,
+			StalenessWeight: float64(weights[k]),
+		}
 	}
+	reportContributions(a.reporter, a.currentRound+1, scoreContributions(a.currentRound+1, scored, updateBase, a.globalModel, newModel))
 
 	// Update global model
+	a.lastDelta = modelDelta(a.globalModel, newModel)
 	a.globalModel = newModel
 	a.currentRound++
 	a.lastUpdate = currentTime
@@ -396,51 +986,166 @@ func (a *AsyncFedAvgAggregator) performAsyncAggregation() {
 	}
 
 	outputPath := fmt.Sprintf("save/async_round_%d_model.pt", a.currentRound)
-	if err := os.WriteFile(outputPath, buf, 0600); err != nil {
+	if err := storage.WriteFile(outputPath, buf); err != nil {
 		log.Printf("Error saving async model: %v", err)
+		a.run.RecordError(fmt.Errorf("failed to save async round %d model: %v", a.currentRound, err))
 	} else {
+		a.run.RecordRound(a.currentRound, outputPath)
 		log.Printf("Async round %d complete, model saved to %s", a.currentRound, outputPath)
 	}
+	a.run.SetFinalModel(a.globalModel)
+	a.run.RecordModel(a.currentRound, transport.ModelHash(buf))
+	a.events.ModelChanged(a.currentRound, transport.ModelHash(buf))
+	metrics := aggregationMetrics(a.currentRound, "fedavg", currentTime, len(validUpdates), clipped, maxNorm)
+	a.delays.annotate(&metrics)
+	a.reporter.RecordAggregation(metrics)
+	return true
+}
+
+// collaboratorAt returns the collaborator of the i-th queued update
+func (a *AsyncFedAvgAggregator) collaboratorAt(i int) string {
+	return a.updates[i].CollaboratorID
+}
+
+// aggregatable returns how many of the queued updates the next aggregation
+// takes
+func (a *AsyncFedAvgAggregator) aggregatable() int {
+	selected, _ := selectFair(len(a.updates), a.collaboratorAt, a.plan.AsyncConfig.MaxPerCollaborator)
+	return len(selected)
+}
+
+// Report returns the run summary
+func (a *AsyncFedAvgAggregator) Report() *RunReport {
+	return a.run.Report()
+}
+
+// Manifest returns the reproducibility manifest of the run
+func (a *AsyncFedAvgAggregator) Manifest() *RunManifest {
+	return a.run.Manifest()
+}
+
+// RoundState returns the updates queued for the next aggregation
+func (a *AsyncFedAvgAggregator) RoundState() RoundState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := RoundState{Round: a.currentRound + 1, Expected: a.plan.AsyncConfig.MinUpdates}
+	if a.globalModel != nil {
+		state.ModelHash = transport.ModelHash(encodeModel(a.globalModel))
+	}
+	for _, update := range a.updates {
+		state.Received = append(state.Received, update.CollaboratorID)
+	}
+	return state
+}
 
-	// Clear processed updates
-	a.updates = make([]UpdateInfo, 0)
+// AggregateNow has the aggregation loop aggregate the queued updates without
+// waiting for min_updates or the aggregation delay
+func (a *AsyncFedAvgAggregator) AggregateNow() (RoundState, error) {
+	state := a.RoundState()
+	if len(state.Received) == 0 {
+		return state, fmt.Errorf("no updates are queued")
+	}
+	select {
+	case a.triggered <- struct{}{}:
+	default:
+	}
+	return state, nil
+}
+
+// AsyncConfig returns the current async settings
+func (a *AsyncFedAvgAggregator) AsyncConfig() federation.AsyncConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.plan.AsyncConfig
+}
+
+// TuneAsync changes the async settings of the running federation
+func (a *AsyncFedAvgAggregator) TuneAsync(tuning AsyncTuning) (federation.AsyncConfig, error) {
+	a.mu.Lock()
+	if err := tuning.apply(&a.plan.AsyncConfig); err != nil {
+		a.mu.Unlock()
+		return federation.AsyncConfig{}, err
+	}
+	config := a.plan.AsyncConfig
+	a.mu.Unlock()
+
+	select {
+	case a.tuned <- struct{}{}:
+	default:
+	}
+	logAsyncTuning(config, a.events)
+	return config, nil
 }
 
 func (a *AsyncFedAvgAggregator) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
 	log.Printf("Collaborator %s joining async federation", req.CollaboratorId)
+	capabilities, err := negotiate(ctx, a.plan, a.attestation, serverCapabilities(transport.FeatureEvents), req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Return current global model
-	buf := make([]byte, 4*a.modelSize)
-	for i, v := range a.globalModel {
-		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
-	}
+	a.mu.Lock()
+	buf := encodeModel(a.globalModel)
+	a.bases.add(transport.ModelHash(buf), a.globalModel)
+	a.mu.Unlock()
 
-	return &pb.JoinResponse{InitialModel: buf}, nil
+	return &pb.JoinResponse{InitialModel: buf, Capabilities: capabilities, ModelSignature: signEncoded(a.signer, buf)}, nil
+}
+
+// WatchEvents streams new global models and config changes to a collaborator
+func (a *AsyncFedAvgAggregator) WatchEvents(req *pb.WatchRequest, stream pb.FederatedLearning_WatchEventsServer) error {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return err
+	}
+	return a.events.Watch(stream.Context(), req.CollaboratorId, stream.Send)
 }
 
 func (a *AsyncFedAvgAggregator) SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error) {
-	floats := make([]float32, len(upd.ModelWeights)/4)
-	for i := range floats {
-		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(upd.ModelWeights[i*4:]))
+	if err := a.attestation.authorize(upd.CollaboratorId); err != nil {
+		return nil, err
+	}
+	floats, reason, err := decodeUpdate(upd.ModelWeights, a.modelSize)
+	if err != nil {
+		return a.drops.Reject(upd.CollaboratorId, a.currentRound, reason, err.Error()), nil
 	}
 
+	a.mu.Lock()
 	updateInfo := UpdateInfo{
 		CollaboratorID: upd.CollaboratorId,
 		Weights:        floats,
 		Timestamp:      time.Now(),
 		Round:          a.currentRound,
 	}
-
-	a.mu.Lock()
+	base, ok := a.bases.base(upd.BaseModelHash, a.globalModel)
+	if !ok {
+		a.mu.Unlock()
+		return a.drops.Reject(upd.CollaboratorId, updateInfo.Round, monitoring.DropReasonStale, staleBase(upd.BaseModelHash)), nil
+	}
+	updateInfo.Base = base
+	maxPending := a.plan.AsyncConfig.MaxPendingPerCollaborator
+	if i := oldestQueued(len(a.updates), a.collaboratorAt, upd.CollaboratorId, maxPending); i >= 0 {
+		replaced := a.updates[i]
+		a.updates = slices.Delete(a.updates, i, i+1)
+		a.drops.Record(replaced.CollaboratorID, replaced.Round, monitoring.DropReasonRateLimited,
+			fmt.Sprintf("replaced by a newer update, %d updates of the collaborator already queued", maxPending))
+	}
 	a.updates = append(a.updates, updateInfo)
 	updateCount := len(a.updates)
+	a.delays.Arrived(updateInfo.Timestamp)
 	a.mu.Unlock()
+	a.inclusion.Received(upd.CollaboratorId)
+	a.run.RecordUpdate(upd.CollaboratorId, updateInfo.Round)
+	a.run.RecordTraining(upd.Metrics)
 
-	log.Printf("Received async update %d from %s (round %d)", updateCount, upd.CollaboratorId, a.currentRound)
+	logging.Infof("Received async update %d from %s (round %d)", updateCount, upd.CollaboratorId, a.currentRound)
 	return &pb.Ack{Success: true}, nil
 }
 
 func (a *AsyncFedAvgAggregator) GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error) {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return nil, err
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -449,6 +1154,11 @@ func (a *AsyncFedAvgAggregator) GetLatestModel(ctx context.Context, req *pb.GetM
 	for i, v := range a.globalModel {
 		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
 	}
+	modelHash := transport.ModelHash(buf)
+	a.bases.add(modelHash, a.globalModel)
+	if resp := notModified(req, modelHash, clampInt32(a.currentRound)); resp != nil {
+		return resp, nil
+	}
 
 	log.Printf("Providing latest model to %s (round %d)", req.CollaboratorId, a.currentRound)
 
@@ -462,7 +1172,9 @@ func (a *AsyncFedAvgAggregator) GetLatestModel(ctx context.Context, req *pb.GetM
 	}
 
 	return &pb.GetModelResponse{
-		ModelWeights: buf,
-		CurrentRound: currentRound,
+		ModelWeights:   buf,
+		CurrentRound:   currentRound,
+		ModelHash:      modelHash,
+		ModelSignature: a.signer.Sign(modelHash),
 	}, nil
 }
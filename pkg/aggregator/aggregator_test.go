@@ -1,10 +1,22 @@
 package aggregator
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	pb "github.com/ishaileshpant/fl-go/api"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewAggregator(t *testing.T) {
@@ -81,3 +93,215 @@ func TestAsyncConfigDefaults(t *testing.T) {
 		t.Errorf("StalenessWeight = %f, want 0.95", agg.plan.AsyncConfig.StalenessWeight)
 	}
 }
+
+func TestJoinPlanFingerprint(t *testing.T) {
+	plan := &federation.FLPlan{
+		Rounds:        3,
+		Collaborators: []federation.Collaborator{{ID: "a"}, {ID: "b"}},
+		Aggregator:    federation.AggregatorEntry{Address: "localhost:50051"},
+	}
+	fingerprint := func(edit func(p *federation.FLPlan)) string {
+		p := *plan
+		edit(&p)
+		fp, err := federation.PlanFingerprint(&p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fp
+	}
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		wantErr     bool
+	}{
+		{"older collaborator", "", false},
+		{"same plan", fingerprint(func(p *federation.FLPlan) {}), false},
+		{"other aggregator address", fingerprint(func(p *federation.FLPlan) { p.Aggregator.Address = "aggregator:50051" }), false},
+		{"explicit sync mode", fingerprint(func(p *federation.FLPlan) { p.Mode = federation.ModeSync }), false},
+		{"other distill task", fingerprint(func(p *federation.FLPlan) { p.Tasks.Distill.Script = "distill.py" }), false},
+		{"other async config", fingerprint(func(p *federation.FLPlan) { p.AsyncConfig.MaxStaleness = 10 }), false},
+		{"other rounds", fingerprint(func(p *federation.FLPlan) { p.Rounds = 5 }), true},
+		{"other algorithm", fingerprint(func(p *federation.FLPlan) { p.Algorithm.Name = "fedprox" }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &pb.JoinRequest{CollaboratorId: "a", Capabilities: transport.LocalCapabilities(), PlanFingerprint: tt.fingerprint}
+			_, err := negotiate(context.Background(), plan, newAttestationGate(plan), syncCapabilities(plan), req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("negotiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// echoVerifier accepts evidence that is the hex report data it commits to
+type echoVerifier struct{}
+
+func (echoVerifier) Verify(ctx context.Context, evidence []byte) (*security.AttestationClaims, error) {
+	return &security.AttestationClaims{Measurement: "abcd", ReportData: string(evidence)}, nil
+}
+
+func TestJoinAttestation(t *testing.T) {
+	security.RegisterAttestationVerifier("echo", echoVerifier{})
+	ctx := context.Background()
+	plan := &federation.FLPlan{
+		Collaborators: []federation.Collaborator{{ID: "a"}, {ID: "edge"}},
+		Security: federation.SecurityConfig{Attestation: federation.AttestationConfig{
+			Required: true,
+			Exempt:   []string{"edge"},
+		}},
+	}
+	evidence := func(id string, nonce []byte) *pb.Attestation {
+		return &pb.Attestation{Type: "echo", Evidence: []byte(hex.EncodeToString(security.AttestationReportData(id, "", nonce)))}
+	}
+	challenge := func(t *testing.T, gate *attestationGate, id string) []byte {
+		t.Helper()
+		c, err := gate.challenge(&pb.AttestationChallengeRequest{CollaboratorId: id})
+		if err != nil || len(c.Nonce) == 0 || c.ExpiresInSeconds <= 0 {
+			t.Fatalf("challenge() = %v, %v", c, err)
+		}
+		return c.Nonce
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		attestation func(t *testing.T, gate *attestationGate) *pb.Attestation
+		wantErr     bool
+	}{
+		{"attested", "a", func(t *testing.T, gate *attestationGate) *pb.Attestation {
+			return evidence("a", challenge(t, gate, "a"))
+		}, false},
+		{"evidence of another collaborator", "a", func(t *testing.T, gate *attestationGate) *pb.Attestation {
+			return evidence("b", challenge(t, gate, "a"))
+		}, true},
+		{"evidence of an earlier challenge", "a", func(t *testing.T, gate *attestationGate) *pb.Attestation {
+			earlier := challenge(t, gate, "a")
+			challenge(t, gate, "a")
+			return evidence("a", earlier)
+		}, true},
+		{"no challenge", "a", func(t *testing.T, gate *attestationGate) *pb.Attestation {
+			return evidence("a", nil)
+		}, true},
+		{"no evidence", "a", func(t *testing.T, gate *attestationGate) *pb.Attestation { return nil }, true},
+		{"exempt", "edge", func(t *testing.T, gate *attestationGate) *pb.Attestation { return nil }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := newAttestationGate(plan)
+			req := &pb.JoinRequest{CollaboratorId: tt.id, Capabilities: transport.LocalCapabilities(), Attestation: tt.attestation(t, gate)}
+			_, err := negotiate(ctx, plan, gate, syncCapabilities(plan), req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("negotiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && status.Code(err) != codes.PermissionDenied {
+				t.Errorf("rejection code = %s, want PermissionDenied", status.Code(err))
+			}
+			if authorized := gate.authorize(tt.id) == nil; authorized == tt.wantErr {
+				t.Errorf("authorize() after the join = %v, want %v", authorized, !tt.wantErr)
+			}
+		})
+	}
+
+	// A challenge answers one join, so the evidence of a join cannot be replayed
+	gate := newAttestationGate(plan)
+	req := &pb.JoinRequest{CollaboratorId: "a", Capabilities: transport.LocalCapabilities(), Attestation: evidence("a", challenge(t, gate, "a"))}
+	if _, err := negotiate(ctx, plan, gate, syncCapabilities(plan), req); err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+	if _, err := negotiate(ctx, plan, gate, syncCapabilities(plan), req); err == nil {
+		t.Error("replayed evidence was admitted")
+	}
+
+	// Without required, evidence that does not verify is only logged
+	plan.Security.Attestation.Required = false
+	req = &pb.JoinRequest{CollaboratorId: "a", Capabilities: transport.LocalCapabilities(), Attestation: evidence("b", nil)}
+	if _, err := negotiate(ctx, plan, newAttestationGate(plan), syncCapabilities(plan), req); err != nil {
+		t.Errorf("optional attestation rejected a collaborator: %v", err)
+	}
+}
+
+// TestAttestationGatesRPCs checks that collaborators that did not join with
+// valid evidence are refused the RPCs after the join
+func TestAttestationGatesRPCs(t *testing.T) {
+	ctx := context.Background()
+	plan := &federation.FLPlan{
+		Rounds:        1,
+		Collaborators: []federation.Collaborator{{ID: "a"}},
+		Security:      federation.SecurityConfig{Attestation: federation.AttestationConfig{Required: true}},
+	}
+	agg := NewFedAvgAggregator(plan)
+
+	calls := map[string]func() error{
+		"SubmitUpdate": func() error {
+			_, err := agg.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: "a"})
+			return err
+		},
+		"GetLatestModel": func() error {
+			_, err := agg.GetLatestModel(ctx, &pb.GetModelRequest{CollaboratorId: "a"})
+			return err
+		},
+		"GetTask": func() error {
+			_, err := agg.GetTask(ctx, &pb.GetTaskRequest{CollaboratorId: "a"})
+			return err
+		},
+		"SubmitTaskResult": func() error {
+			_, err := agg.SubmitTaskResult(ctx, &pb.TaskResult{CollaboratorId: "a"})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s() of a collaborator that did not join error = %v, want PermissionDenied", name, err)
+		}
+	}
+}
+
+func TestStartRejectsMalformedInitialModel(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil { // mapped models are kept under save/
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	models := map[string][]byte{
+		"truncated": encodeFloats(1, 2)[:6],
+		"nan":       encodeFloats(1, float32(math.NaN())),
+	}
+	for name, model := range models {
+		path := filepath.Join(dir, name+".bin")
+		if err := os.WriteFile(path, model, 0600); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range []struct {
+			name string
+			plan federation.FLPlan
+		}{
+			{name: "sync", plan: federation.FLPlan{Mode: federation.ModeSync}},
+			{name: "mapped", plan: federation.FLPlan{Mode: federation.ModeSync, Aggregator: federation.AggregatorEntry{MmapModel: true}}},
+			{name: "async", plan: federation.FLPlan{Mode: federation.ModeAsync}},
+			{name: "modular", plan: federation.FLPlan{Mode: federation.ModeSync, Algorithm: federation.AlgorithmConfig{Name: "fedprox"}}},
+		} {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				plan := tt.plan
+				plan.Rounds = 1
+				plan.Collaborators = []federation.Collaborator{{ID: "c1"}}
+				plan.Aggregator.Address = "127.0.0.1:0"
+				plan.InitialModel = path
+				plan.OutputModel = filepath.Join(dir, "final.bin")
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				err := NewAggregator(&plan).Start(ctx)
+				if err == nil || !strings.Contains(err.Error(), "invalid initial model") {
+					t.Errorf("Start() error = %v, want an invalid initial model", err)
+				}
+			})
+		}
+	}
+}
@@ -28,21 +28,44 @@ type AggregationAlgorithm interface {
 
 // ClientUpdate represents an update from a collaborator
 type ClientUpdate struct {
-	CollaboratorID string
-	Weights        []float32
-	Timestamp      time.Time
-	Round          int
-	Staleness      int
-	NumSamples     int     // Number of training samples (for weighted aggregation)
-	LearningRate   float32 // Client learning rate (for adaptive algorithms)
+	CollaboratorID  string
+	Weights         []float32
+	Timestamp       time.Time
+	Round           int
+	Staleness       int
+	Base            []float32          // Model the update was trained on, kept by async aggregators
+	NumSamples      int                // Number of training samples (for weighted aggregation)
+	LocalSteps      int                // Local optimizer steps the update was trained with; 0 if unknown
+	LearningRate    float32            // Client learning rate (for adaptive algorithms)
+	StalenessWeight float64            // Down-weighting of stale async updates in (0, 1]; 0 means full weight
+	ImageDigest     string             // Container image the collaborator trained with, if reported
+	Size            int                // Encoded size in bytes of updates whose weights were not kept
+	TrainingMetrics map[string]float64 // Local training metrics the collaborator reported, such as loss
+	// Local differential privacy the collaborator applied, if reported
+	DPClipNorm        float64
+	DPNoiseMultiplier float64
+}
+
+// sampleWeight returns the update's sample count scaled by its staleness weight
+func (u ClientUpdate) sampleWeight() float32 {
+	return float32(u.NumSamples) * u.stalenessFactor()
+}
+
+// stalenessFactor returns the staleness weight, treating unset as full weight
+func (u ClientUpdate) stalenessFactor() float32 {
+	if u.StalenessWeight > 0 {
+		return float32(u.StalenessWeight)
+	}
+	return 1
 }
 
 // AlgorithmConfig contains configuration for aggregation algorithms
 type AlgorithmConfig struct {
-	AlgorithmName   string                 `yaml:"algorithm"` // fedavg, fedopt, fedprox
+	AlgorithmName   string                 `yaml:"algorithm"` // fedavg, fedavgm, fednova, fedopt, fedprox, feddf
 	ModelSize       int                    `yaml:"model_size"`
 	Hyperparameters map[string]interface{} `yaml:"hyperparameters"`
 	Mode            federation.FLMode      `yaml:"mode"` // sync or async
+	DistillTask     federation.TaskConfig  `yaml:"-"`    // Server-side distillation task of feddf
 }
 
 // AlgorithmType represents supported aggregation algorithms
@@ -50,8 +73,11 @@ type AlgorithmType string
 
 const (
 	FedAvg  AlgorithmType = "fedavg"
+	FedAvgM AlgorithmType = "fedavgm"
+	FedNova AlgorithmType = "fednova"
 	FedOpt  AlgorithmType = "fedopt"
 	FedProx AlgorithmType = "fedprox"
+	FedDF   AlgorithmType = "feddf"
 )
 
 // CreateAggregationAlgorithm creates an instance of the specified algorithm
@@ -59,10 +85,16 @@ func CreateAggregationAlgorithm(algType AlgorithmType) (AggregationAlgorithm, er
 	switch algType {
 	case FedAvg:
 		return &FedAvgAlgorithm{}, nil
+	case FedAvgM:
+		return &FedAvgMAlgorithm{}, nil
+	case FedNova:
+		return &FedNovaAlgorithm{}, nil
 	case FedOpt:
 		return &FedOptAlgorithm{}, nil
 	case FedProx:
 		return &FedProxAlgorithm{}, nil
+	case FedDF:
+		return &FedDFAlgorithm{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported aggregation algorithm: %s", algType)
 	}
@@ -106,28 +138,241 @@ func (f *FedAvgAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float3
 
 	// Simple averaging
 	aggregated := make([]float32, f.modelSize)
-	totalSamples := 0
+	totalSamples, totalStaleness := float32(0), float32(0)
 
 	// Calculate total samples for weighted averaging
 	for _, update := range updates {
-		totalSamples += update.NumSamples
+		totalSamples += update.sampleWeight()
+		totalStaleness += update.stalenessFactor()
 	}
 
 	// Weighted aggregation based on number of samples
+	weights := make([]float32, len(updates))
+	for k, update := range updates {
+		weights[k] = update.sampleWeight() / totalSamples
+		if totalSamples == 0 {
+			weights[k] = update.stalenessFactor() / totalStaleness // Equal weighting if no sample info
+		}
+	}
+	accumulate(aggregated, updates, weights)
+
+	return aggregated, nil
+}
+
+// =============================================================================
+// FedAvgM Algorithm (Federated Averaging with Server Momentum)
+// Reference: "Measuring the Effects of Non-Identical Data Distribution for
+// Federated Visual Classification" (Hsu et al., 2019)
+// =============================================================================
+
+type FedAvgMAlgorithm struct {
+	name      string
+	modelSize int
+	serverLR  float32
+	beta      float32   // Server momentum coefficient
+	velocity  []float32 // Momentum buffer
+}
+
+func (f *FedAvgMAlgorithm) Initialize(config AlgorithmConfig) error {
+	f.name = "FedAvgM"
+	f.modelSize = config.ModelSize
+
+	// Default hyperparameters
+	f.serverLR = 1.0
+	f.beta = 0.9
+
+	f.velocity = make([]float32, f.modelSize)
+
+	// Override with custom hyperparameters if provided
+	if params := config.Hyperparameters; params != nil {
+		return f.UpdateHyperparameters(params)
+	}
+	return nil
+}
+
+func (f *FedAvgMAlgorithm) GetName() string {
+	return f.name
+}
+
+func (f *FedAvgMAlgorithm) GetHyperparameters() map[string]interface{} {
+	return map[string]interface{}{
+		"algorithm":            "fedavgm",
+		"server_learning_rate": f.serverLR,
+		"momentum":             f.beta,
+		"description":          "Federated Averaging with Server Momentum",
+	}
+}
+
+func (f *FedAvgMAlgorithm) UpdateHyperparameters(params map[string]interface{}) error {
+	if value, exists := params["momentum"]; exists {
+		beta, ok := federation.Number(value)
+		if !ok {
+			return fmt.Errorf("momentum must be a number, got %v", value)
+		}
+		if beta < 0 || beta >= 1 {
+			return fmt.Errorf("momentum must be in [0, 1), got %v", beta)
+		}
+		f.beta = float32(beta)
+	}
+	if value, exists := params["server_learning_rate"]; exists {
+		lr, ok := federation.Number(value)
+		if !ok {
+			return fmt.Errorf("server_learning_rate must be a number, got %v", value)
+		}
+		if lr <= 0 {
+			return fmt.Errorf("server_learning_rate must be positive, got %v", lr)
+		}
+		f.serverLR = float32(lr)
+	}
+	return nil
+}
+
+func (f *FedAvgMAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float32) ([]float32, error) {
+	if len(updates) == 0 {
+		return globalModel, fmt.Errorf("no updates to aggregate")
+	}
+
+	totalSamples, totalStaleness := float32(0), float32(0)
 	for _, update := range updates {
-		weight := float32(update.NumSamples) / float32(totalSamples)
+		totalSamples += update.sampleWeight()
+		totalStaleness += update.stalenessFactor()
+	}
+
+	// Compute weighted average of client updates
+	clientAverage := make([]float32, f.modelSize)
+	weights := make([]float32, len(updates))
+	for k, update := range updates {
+		weights[k] = update.sampleWeight() / totalSamples
 		if totalSamples == 0 {
-			weight = 1.0 / float32(len(updates)) // Equal weighting if no sample info
+			weights[k] = update.stalenessFactor() / totalStaleness
 		}
+	}
+	accumulate(clientAverage, updates, weights)
 
-		for i, v := range update.Weights {
-			if i < len(aggregated) {
-				aggregated[i] += weight * v
-			}
+	// Accumulate the pseudo-gradient in the momentum buffer and step along it:
+	// v = beta*v + (average - global), new = global + lr*v
+	newModel := make([]float32, f.modelSize)
+	copy(newModel, globalModel)
+	parallelFor(min(f.modelSize, len(globalModel)), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			f.velocity[i] = f.beta*f.velocity[i] + (clientAverage[i] - globalModel[i])
+			newModel[i] += f.serverLR * f.velocity[i]
 		}
+	})
+
+	return newModel, nil
+}
+
+// =============================================================================
+// FedNova Algorithm (Normalized Averaging)
+// Reference: "Tackling the Objective Inconsistency Problem in Heterogeneous
+// Federated Optimization" (Wang et al., 2020)
+// =============================================================================
+
+type FedNovaAlgorithm struct {
+	name      string
+	modelSize int
+	serverLR  float32
+}
+
+func (f *FedNovaAlgorithm) Initialize(config AlgorithmConfig) error {
+	f.name = "FedNova"
+	f.modelSize = config.ModelSize
+	f.serverLR = 1.0
+
+	// Override with custom hyperparameters if provided
+	if params := config.Hyperparameters; params != nil {
+		return f.UpdateHyperparameters(params)
 	}
+	return nil
+}
 
-	return aggregated, nil
+func (f *FedNovaAlgorithm) GetName() string {
+	return f.name
+}
+
+func (f *FedNovaAlgorithm) GetHyperparameters() map[string]interface{} {
+	return map[string]interface{}{
+		"algorithm":            "fednova",
+		"server_learning_rate": f.serverLR,
+		"description":          "Normalized Averaging over heterogeneous local steps",
+	}
+}
+
+func (f *FedNovaAlgorithm) UpdateHyperparameters(params map[string]interface{}) error {
+	if value, exists := params["server_learning_rate"]; exists {
+		lr, ok := federation.Number(value)
+		if !ok {
+			return fmt.Errorf("server_learning_rate must be a number, got %v", value)
+		}
+		if lr <= 0 {
+			return fmt.Errorf("server_learning_rate must be positive, got %v", lr)
+		}
+		f.serverLR = float32(lr)
+	}
+	return nil
+}
+
+// Aggregate divides each client's change of the model by its local steps
+// before averaging, so that clients which ran more steps do not pull the
+// model towards their own objective, and then scales the average by the
+// weighted mean of the steps. Updates that report no local steps are
+// assumed to have run the mean of the reported ones; with no steps reported
+// at all FedNova is FedAvg.
+func (f *FedNovaAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float32) ([]float32, error) {
+	if len(updates) == 0 {
+		return globalModel, fmt.Errorf("no updates to aggregate")
+	}
+
+	totalSamples, totalStaleness := float32(0), float32(0)
+	reportedSteps, reported := 0, 0
+	for _, update := range updates {
+		totalSamples += update.sampleWeight()
+		totalStaleness += update.stalenessFactor()
+		if update.LocalSteps > 0 {
+			reportedSteps += update.LocalSteps
+			reported++
+		}
+	}
+	defaultSteps := float32(1)
+	if reported > 0 {
+		defaultSteps = float32(reportedSteps) / float32(reported)
+	}
+
+	// p_i are the sample weights, tau_i the local steps and
+	// tau_eff = sum p_i * tau_i the effective steps of the round
+	weights := make([]float32, len(updates))
+	steps := make([]float32, len(updates))
+	effectiveSteps := float32(0)
+	for k, update := range updates {
+		weights[k] = update.sampleWeight() / totalSamples
+		if totalSamples == 0 {
+			weights[k] = update.stalenessFactor() / totalStaleness
+		}
+		steps[k] = defaultSteps
+		if update.LocalSteps > 0 {
+			steps[k] = float32(update.LocalSteps)
+		}
+		effectiveSteps += weights[k] * steps[k]
+	}
+
+	// new = global + lr * tau_eff * sum p_i * (w_i - global) / tau_i, which
+	// is sum a_i * w_i + (1 - sum a_i) * global with a_i = lr * tau_eff * p_i / tau_i
+	coefficients := make([]float32, len(updates))
+	total := float32(0)
+	for k := range updates {
+		coefficients[k] = f.serverLR * effectiveSteps * weights[k] / steps[k]
+		total += coefficients[k]
+	}
+	newModel := make([]float32, f.modelSize)
+	accumulate(newModel, updates, coefficients)
+	parallelFor(min(f.modelSize, len(globalModel)), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			newModel[i] += (1 - total) * globalModel[i]
+		}
+	})
+
+	return newModel, nil
 }
 
 // =============================================================================
@@ -221,53 +466,56 @@ func (f *FedOptAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float3
 
 	// First, compute the pseudo-gradient (difference from global model)
 	pseudoGradient := make([]float32, f.modelSize)
-	totalSamples := 0
+	totalSamples, totalStaleness := float32(0), float32(0)
 
 	// Calculate total samples for weighted averaging
 	for _, update := range updates {
-		totalSamples += update.NumSamples
+		totalSamples += update.sampleWeight()
+		totalStaleness += update.stalenessFactor()
 	}
 
 	// Compute weighted average of client updates
 	clientAverage := make([]float32, f.modelSize)
-	for _, update := range updates {
-		weight := float32(update.NumSamples) / float32(totalSamples)
+	weights := make([]float32, len(updates))
+	for k, update := range updates {
+		weights[k] = update.sampleWeight() / totalSamples
 		if totalSamples == 0 {
-			weight = 1.0 / float32(len(updates))
-		}
-
-		for i, v := range update.Weights {
-			if i < len(clientAverage) {
-				clientAverage[i] += weight * v
-			}
+			weights[k] = update.stalenessFactor() / totalStaleness
 		}
 	}
+	accumulate(clientAverage, updates, weights)
 
 	// Compute pseudo-gradient: difference between client average and global model
-	for i := 0; i < f.modelSize && i < len(globalModel); i++ {
-		pseudoGradient[i] = clientAverage[i] - globalModel[i]
-	}
+	parallelFor(min(f.modelSize, len(globalModel)), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pseudoGradient[i] = clientAverage[i] - globalModel[i]
+		}
+	})
 
 	// Apply Adam-like server optimization
 	newModel := make([]float32, f.modelSize)
 	copy(newModel, globalModel)
 
-	for i := 0; i < f.modelSize; i++ {
-		// Update momentum (first moment estimate)
-		f.momentum[i] = f.beta1*f.momentum[i] + (1-f.beta1)*pseudoGradient[i]
+	// Bias correction factors are the same for every parameter
+	momentumCorrection := 1 - float32(math.Pow(float64(f.beta1), float64(f.round)))
+	velocityCorrection := 1 - float32(math.Pow(float64(f.beta2), float64(f.round)))
 
-		// Update velocity (second moment estimate)
-		f.velocity[i] = f.beta2*f.velocity[i] + (1-f.beta2)*pseudoGradient[i]*pseudoGradient[i]
+	parallelFor(f.modelSize, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			// Update momentum (first moment estimate)
+			f.momentum[i] = f.beta1*f.momentum[i] + (1-f.beta1)*pseudoGradient[i]
 
-		// Bias correction
-		momentumCorrected := f.momentum[i] / (1 - float32(math.Pow(float64(f.beta1), float64(f.round))))
-		velocityCorrected := f.velocity[i] / (1 - float32(math.Pow(float64(f.beta2), float64(f.round))))
+			// Update velocity (second moment estimate)
+			f.velocity[i] = f.beta2*f.velocity[i] + (1-f.beta2)*pseudoGradient[i]*pseudoGradient[i]
 
-		// Apply Adam update
-		if i < len(newModel) {
+			// Bias correction
+			momentumCorrected := f.momentum[i] / momentumCorrection
+			velocityCorrected := f.velocity[i] / velocityCorrection
+
+			// Apply Adam update
 			newModel[i] += f.serverLR * momentumCorrected / (float32(math.Sqrt(float64(velocityCorrected))) + f.epsilon)
 		}
-	}
+	})
 
 	return newModel, nil
 }
@@ -327,37 +575,38 @@ func (f *FedProxAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float
 	totalWeight := float32(0)
 
 	// Calculate weights based on number of samples and learning rates
-	for _, update := range updates {
+	weights := make([]float32, len(updates))
+	for k, update := range updates {
 		// Weight based on samples and inverse of learning rate (more stable clients get higher weight)
-		weight := float32(update.NumSamples)
+		weight := update.sampleWeight()
 		if update.LearningRate > 0 {
 			// Clients with smaller learning rates (more conservative) get slightly higher weight
 			weight *= (1.0 + f.mu/update.LearningRate)
 		}
+		weights[k] = weight
 		totalWeight += weight
-
-		for i, v := range update.Weights {
-			if i < len(aggregated) {
-				aggregated[i] += weight * v
-			}
-		}
 	}
+	accumulate(aggregated, updates, weights)
 
 	// Normalize by total weight
 	if totalWeight > 0 {
-		for i := range aggregated {
-			aggregated[i] /= totalWeight
-		}
+		parallelFor(len(aggregated), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				aggregated[i] /= totalWeight
+			}
+		})
 	}
 
 	// Apply proximal term: blend with global model to ensure stability
+	// Proximal update: new_model = (1-α) * aggregated + α * global_model
+	// where α is determined by the proximal term mu
 	proximalBlend := make([]float32, f.modelSize)
-	for i := 0; i < f.modelSize && i < len(globalModel); i++ {
-		// Proximal update: new_model = (1-α) * aggregated + α * global_model
-		// where α is determined by the proximal term mu
-		alpha := f.mu / (1.0 + f.mu)
-		proximalBlend[i] = (1-alpha)*aggregated[i] + alpha*globalModel[i]
-	}
+	alpha := f.mu / (1.0 + f.mu)
+	parallelFor(min(f.modelSize, len(globalModel)), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			proximalBlend[i] = (1-alpha)*aggregated[i] + alpha*globalModel[i]
+		}
+	})
 
 	return proximalBlend, nil
 }
@@ -0,0 +1,140 @@
+package aggregator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFedAvgMMomentum(t *testing.T) {
+	algorithm, err := CreateAggregationAlgorithm(FedAvgM)
+	if err != nil {
+		t.Fatalf("CreateAggregationAlgorithm() error = %v", err)
+	}
+	config := AlgorithmConfig{ModelSize: 1, Hyperparameters: map[string]interface{}{"momentum": 0.5}}
+	if err := algorithm.Initialize(config); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	// Clients move the model by +1 every round; momentum accumulates the
+	// steps: v = 1, 1.5, 1.75
+	global := []float32{0}
+	for _, expected := range []float32{1, 2.5, 4.25} {
+		updates := []ClientUpdate{
+			{Weights: []float32{global[0] + 0.5}, NumSamples: 1},
+			{Weights: []float32{global[0] + 1.5}, NumSamples: 1},
+		}
+		global, err = algorithm.Aggregate(updates, global)
+		if err != nil {
+			t.Fatalf("Aggregate() error = %v", err)
+		}
+		if math.Abs(float64(global[0]-expected)) > 1e-6 {
+			t.Errorf("global = %g, want %g", global[0], expected)
+		}
+	}
+}
+
+func TestFedAvgMHyperparameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{"defaults", nil, false},
+		{"momentum", map[string]interface{}{"momentum": 0.99}, false},
+		{"no momentum", map[string]interface{}{"momentum": 0.0}, false},
+		{"momentum of one", map[string]interface{}{"momentum": 1.0}, true},
+		{"negative momentum", map[string]interface{}{"momentum": -0.1}, true},
+		{"zero server learning rate", map[string]interface{}{"server_learning_rate": 0.0}, true},
+		{"integer momentum of one", map[string]interface{}{"momentum": 1}, true},
+		{"non-numeric momentum", map[string]interface{}{"momentum": "high"}, true},
+		{"non-numeric server learning rate", map[string]interface{}{"server_learning_rate": "1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := &FedAvgMAlgorithm{}
+			err := algorithm.Initialize(AlgorithmConfig{ModelSize: 1, Hyperparameters: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Initialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// YAML decodes whole numbers as ints
+	algorithm := &FedAvgMAlgorithm{}
+	err := algorithm.Initialize(AlgorithmConfig{ModelSize: 1, Hyperparameters: map[string]interface{}{"momentum": 0, "server_learning_rate": 2}})
+	if err != nil || algorithm.beta != 0 || algorithm.serverLR != 2 {
+		t.Errorf("Initialize() with ints = momentum %v, server_learning_rate %v, %v, want 0 and 2", algorithm.beta, algorithm.serverLR, err)
+	}
+}
+
+func TestFedNovaNormalizesLocalSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		updates  []ClientUpdate
+		expected float32
+	}{
+		// Both clients moved by 1 per step; the model moves by tau_eff = 3
+		{"normalized steps", []ClientUpdate{
+			{Weights: []float32{1}, NumSamples: 1, LocalSteps: 1},
+			{Weights: []float32{5}, NumSamples: 1, LocalSteps: 5},
+		}, 3},
+		// The client without steps is assumed to have run the mean of 2
+		{"missing steps", []ClientUpdate{
+			{Weights: []float32{2}, NumSamples: 1, LocalSteps: 2},
+			{Weights: []float32{4}, NumSamples: 1},
+		}, 3},
+		{"no steps is FedAvg", []ClientUpdate{
+			{Weights: []float32{1}, NumSamples: 3},
+			{Weights: []float32{5}, NumSamples: 1},
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, err := CreateAggregationAlgorithm(FedNova)
+			if err != nil {
+				t.Fatalf("CreateAggregationAlgorithm() error = %v", err)
+			}
+			if err := algorithm.Initialize(AlgorithmConfig{ModelSize: 1}); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			model, err := algorithm.Aggregate(tt.updates, []float32{0})
+			if err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+			if math.Abs(float64(model[0]-tt.expected)) > 1e-6 {
+				t.Errorf("model = %g, want %g", model[0], tt.expected)
+			}
+		})
+	}
+}
+
+func TestFedNovaHyperparameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    float32
+		wantErr bool
+	}{
+		{"default", nil, 1, false},
+		{"float", map[string]interface{}{"server_learning_rate": 0.5}, 0.5, false},
+		// YAML decodes whole numbers as ints
+		{"integer", map[string]interface{}{"server_learning_rate": 2}, 2, false},
+		{"zero", map[string]interface{}{"server_learning_rate": 0}, 0, true},
+		{"non-numeric", map[string]interface{}{"server_learning_rate": "fast"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := &FedNovaAlgorithm{}
+			err := algorithm.Initialize(AlgorithmConfig{ModelSize: 1, Hyperparameters: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Initialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && algorithm.serverLR != tt.want {
+				t.Errorf("server_learning_rate = %g, want %g", algorithm.serverLR, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// Reasons an async run completes, recorded in the run report
+const (
+	CompletionMaxRounds   = "max_rounds"
+	CompletionMaxDuration = "max_duration"
+	CompletionConverged   = "converged"
+	CompletionStopped     = "stopped" // the run was stopped before any criterion was met
+	CompletionAborted     = "aborted" // the run was aborted through the admin API
+)
+
+// asyncCompletion returns the completion criterion met after an aggregation
+// produced round with the given relative model change, or "" to keep running
+func asyncCompletion(config federation.AsyncConfig, round int, delta float64) string {
+	if config.MaxRounds > 0 && round >= config.MaxRounds {
+		return CompletionMaxRounds
+	}
+	if config.ConvergenceThreshold > 0 && delta < config.ConvergenceThreshold {
+		return CompletionConverged
+	}
+	return ""
+}
+
+// asyncDeadline returns a channel that fires when maxDuration seconds have
+// elapsed, or nil when the duration is unlimited
+func asyncDeadline(maxDuration int) (<-chan time.Time, func()) {
+	if maxDuration <= 0 {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(time.Duration(maxDuration) * time.Second)
+	return timer.C, func() { timer.Stop() }
+}
+
+// startAsyncLoop runs an aggregation loop until ctx is done or the returned
+// stop is called. stop waits for the loop to return, so that an aggregation in
+// progress finishes before the final model is saved.
+func startAsyncLoop(ctx context.Context, loop func(context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loop(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// asyncBaseHistory is the number of global models an async aggregator keeps
+// after handing them out, to clip and score updates against the model they
+// were trained on. Updates trained on an older model are dropped as stale.
+const asyncBaseHistory = 8
+
+// baseModels keeps the latest global models an async aggregator handed out,
+// by hash. The models are shared with the aggregator, which replaces its
+// global model rather than modifying it.
+type baseModels struct {
+	hashes []string // oldest first
+	models map[string][]float32
+}
+
+func newBaseModels() *baseModels {
+	return &baseModels{models: make(map[string][]float32)}
+}
+
+// add records a model that was handed out with hash
+func (b *baseModels) add(hash string, model []float32) {
+	if _, ok := b.models[hash]; ok {
+		return
+	}
+	b.hashes = append(b.hashes, hash)
+	b.models[hash] = model
+	for len(b.hashes) > asyncBaseHistory {
+		delete(b.models, b.hashes[0])
+		b.hashes = b.hashes[1:]
+	}
+}
+
+// base returns the model an update with baseHash was trained on. Updates
+// that do not name their base are taken to be trained on latest. It returns
+// false when the model is no longer kept.
+func (b *baseModels) base(baseHash string, latest []float32) ([]float32, bool) {
+	if baseHash == "" {
+		return latest, true
+	}
+	model, ok := b.models[baseHash]
+	return model, ok
+}
+
+// updateBase returns the model an async update was trained on
+func updateBase(update ClientUpdate) []float32 {
+	return update.Base
+}
+
+// staleBase is the drop detail of an update trained on a model that is no
+// longer kept
+func staleBase(baseHash string) string {
+	return fmt.Sprintf("update was trained on model %.12s, older than the last %d global models", baseHash, asyncBaseHistory)
+}
+
+// modelDelta returns the L2 distance between two models relative to the norm
+// of the previous one
+func modelDelta(previous, current []float32) float64 {
+	var diff, norm float64
+	for i, v := range current {
+		var old float64
+		if i < len(previous) {
+			old = float64(previous[i])
+		}
+		d := float64(v) - old
+		diff += d * d
+		norm += old * old
+	}
+
+	if norm == 0 {
+		return math.Sqrt(diff)
+	}
+	return math.Sqrt(diff / norm)
+}
+
+// writeModel saves model weights as little-endian float32 values
+func writeModel(path string, model []float32) error {
+	return storage.WriteFile(path, encodeModel(model))
+}
+
+// saveFinalAsyncModel writes the final global model of an async run to the
+// plan's output model, records it with the completion reason in the run report
+// and registers it in the model registry
+func saveFinalAsyncModel(plan *federation.FLPlan, run *RunRecorder, models *ModelRegistrar, round int, model []float32, reason string) error {
+	if err := writeModel(plan.OutputModel, model); err != nil {
+		return fmt.Errorf("failed to save final model: %w", err)
+	}
+
+	run.RecordRound(round, plan.OutputModel)
+	run.SetFinalModel(model)
+	run.SetCompletionReason(reason)
+	models.Register(round, model)
+	log.Printf("Async FL completed (%s) after %d rounds, final model saved to %s", reason, round, plan.OutputModel)
+	return nil
+}
@@ -0,0 +1,228 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestAsyncCompletion(t *testing.T) {
+	config := federation.AsyncConfig{MaxRounds: 10, ConvergenceThreshold: 0.01}
+
+	tests := []struct {
+		name     string
+		config   federation.AsyncConfig
+		round    int
+		delta    float64
+		expected string
+	}{
+		{"keeps running", config, 3, 0.5, ""},
+		{"max rounds reached", config, 10, 0.5, CompletionMaxRounds},
+		{"converged", config, 3, 0.001, CompletionConverged},
+		{"no criteria", federation.AsyncConfig{}, 1000, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := asyncCompletion(tt.config, tt.round, tt.delta); reason != tt.expected {
+				t.Errorf("asyncCompletion(round=%d, delta=%g) = %q, want %q", tt.round, tt.delta, reason, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelDelta(t *testing.T) {
+	if delta := modelDelta([]float32{3, 4}, []float32{3, 4}); delta != 0 {
+		t.Errorf("modelDelta of identical models = %g, want 0", delta)
+	}
+	// |(0, 5) - (3, 4)| / |(3, 4)| = sqrt(10) / 5
+	if delta := modelDelta([]float32{3, 4}, []float32{0, 5}); delta < 0.632 || delta > 0.633 {
+		t.Errorf("modelDelta = %g, want ~0.6325", delta)
+	}
+}
+
+func TestBaseModels(t *testing.T) {
+	bases := newBaseModels()
+	latest := []float32{9}
+	for i := range asyncBaseHistory + 1 {
+		bases.add(string(rune('a'+i)), []float32{float32(i)})
+	}
+
+	if _, ok := bases.base("a", latest); ok {
+		t.Error("base(a) is still kept after more models were handed out")
+	}
+	if model, ok := bases.base("b", latest); !ok || model[0] != 1 {
+		t.Errorf("base(b) = %v, %v, want [1]", model, ok)
+	}
+	if model, ok := bases.base("", latest); !ok || model[0] != 9 {
+		t.Errorf("base() of an update without a base hash = %v, %v, want the latest model", model, ok)
+	}
+}
+
+func TestAsyncAggregatorClipsAgainstUpdateBase(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil { // async round models are saved under save/
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	plan := &federation.FLPlan{
+		Mode:        federation.ModeAsync,
+		AsyncConfig: federation.AsyncConfig{MaxStaleness: 60, StalenessWeight: 1},
+		Security:    federation.SecurityConfig{UpdateClipping: federation.UpdateClippingConfig{Enabled: true, MaxNorm: 1}},
+	}
+	agg := NewAsyncFedAvgAggregator(plan)
+	agg.modelSize = 2
+	ctx := context.Background()
+	handOut := func(model []float32) string {
+		t.Helper()
+		agg.globalModel = model
+		resp, err := agg.GetLatestModel(ctx, &pb.GetModelRequest{CollaboratorId: "c"})
+		if err != nil {
+			t.Fatalf("GetLatestModel() error = %v", err)
+		}
+		return resp.ModelHash
+	}
+	submit := func(id, baseHash string, weights []float32) *pb.Ack {
+		t.Helper()
+		ack, err := agg.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: id, ModelWeights: encodeModel(weights), BaseModelHash: baseHash})
+		if err != nil {
+			t.Fatalf("SubmitUpdate(%s) error = %v", id, err)
+		}
+		return ack
+	}
+
+	// Both updates moved their base by (3, 4), which clips to (0.6, 0.8)
+	old := handOut([]float32{0, 0})
+	current := handOut([]float32{10, 10})
+	submit("old", old, []float32{3, 4})
+	submit("current", current, []float32{13, 14})
+	if ack := submit("lost", "0123456789abcdef", []float32{1, 1}); ack.Success {
+		t.Error("SubmitUpdate() accepted an update trained on an unknown model")
+	}
+	if n := agg.drops.Count("lost", monitoring.DropReasonStale); n != 1 {
+		t.Errorf("%d updates on an unknown model were dropped as stale, want 1", n)
+	}
+
+	if !agg.performAsyncAggregation() {
+		t.Fatal("performAsyncAggregation() produced no model")
+	}
+	want := []float32{5.6, 5.8}
+	for i := range want {
+		if math.Abs(float64(agg.globalModel[i]-want[i])) > 1e-5 {
+			t.Fatalf("global model = %v, want %v", agg.globalModel, want)
+		}
+	}
+}
+
+func TestAsyncAggregatorMaxDuration(t *testing.T) {
+	dir := t.TempDir()
+	initial := filepath.Join(dir, "initial.bin")
+	if err := writeModel(initial, []float32{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &federation.FLPlan{
+		Mode:         federation.ModeAsync,
+		Aggregator:   federation.AggregatorEntry{Address: "127.0.0.1:0"},
+		InitialModel: initial,
+		OutputModel:  filepath.Join(dir, "final.bin"),
+		AsyncConfig: federation.AsyncConfig{
+			MinUpdates:       1,
+			AggregationDelay: 1,
+			MaxDuration:      1,
+		},
+	}
+	agg := NewAsyncFedAvgAggregator(plan)
+
+	// The context is only a safety net; max_duration must end the run first
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := agg.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Run was not ended by max_duration")
+	}
+
+	data, err := os.ReadFile(plan.OutputModel)
+	if err != nil {
+		t.Fatalf("Final model was not written: %v", err)
+	}
+	if len(data) != 12 {
+		t.Errorf("Final model size = %d bytes, want 12", len(data))
+	}
+
+	report := agg.Report()
+	if report.Status != RunCompleted || report.CompletionReason != CompletionMaxDuration {
+		t.Errorf("report status = %s (%s), want completed (%s)", report.Status, report.CompletionReason, CompletionMaxDuration)
+	}
+}
+
+func TestStartStopsWithContext(t *testing.T) {
+	dir := t.TempDir()
+	initial := filepath.Join(dir, "initial.bin")
+	if err := writeModel(initial, []float32{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricsAddress := lis.Addr().String()
+	lis.Close()
+
+	for _, mode := range []federation.FLMode{federation.ModeSync, federation.ModeAsync} {
+		t.Run(string(mode), func(t *testing.T) {
+			plan := &federation.FLPlan{
+				Mode:          mode,
+				Rounds:        3,
+				Collaborators: []federation.Collaborator{{ID: "collab1"}},
+				Aggregator:    federation.AggregatorEntry{Address: "127.0.0.1:0"},
+				InitialModel:  initial,
+				OutputModel:   filepath.Join(dir, "final.bin"),
+				AsyncConfig:   federation.AsyncConfig{MinUpdates: 1, AggregationDelay: 1},
+				Monitoring:    federation.MonitoringConfig{MetricsAddress: metricsAddress},
+			}
+
+			// No collaborator ever submits, so only the deadline ends the run
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- NewAggregator(plan).Start(ctx) }()
+			select {
+			case err := <-done:
+				if mode == federation.ModeSync && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Start() error = %v, want the deadline", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Start() did not return after its context expired")
+			}
+
+			// The metrics server stops with the run, freeing its address
+			// for the next one
+			for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+				if _, err := http.Get("http://" + metricsAddress + "/metrics"); err != nil {
+					break
+				}
+				if time.Since(start) > 5*time.Second {
+					t.Fatal("Metrics server still serves after the run")
+				}
+			}
+		})
+	}
+}
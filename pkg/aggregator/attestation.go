@@ -0,0 +1,149 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/rand"
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// attestationChallengeTTL is how long a challenge nonce is accepted by joins
+const attestationChallengeTTL = 5 * time.Minute
+
+// attestationGate admits collaborators by the attestation evidence they
+// present when joining. It issues the single-use challenges the evidence
+// commits to, and remembers the admitted collaborators so that the RPCs
+// after JoinFederation can be refused to any other when the plan requires
+// attestation.
+type attestationGate struct {
+	plan *federation.FLPlan
+
+	mu         sync.Mutex
+	challenges map[string]pendingChallenge // collaborator ID -> latest challenge
+	admitted   map[string]bool
+}
+
+// pendingChallenge is a nonce issued to a collaborator that has not joined
+// with it yet
+type pendingChallenge struct {
+	nonce   []byte
+	expires time.Time
+}
+
+func newAttestationGate(plan *federation.FLPlan) *attestationGate {
+	return &attestationGate{
+		plan:       plan,
+		challenges: make(map[string]pendingChallenge),
+		admitted:   make(map[string]bool),
+	}
+}
+
+// challenge issues a fresh nonce to a collaborator, replacing any it was
+// issued before
+func (g *attestationGate) challenge(req *pb.AttestationChallengeRequest) (*pb.AttestationChallenge, error) {
+	if req.CollaboratorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "collaborator_id is required")
+	}
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate a challenge: %v", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.challenges[req.CollaboratorId] = pendingChallenge{nonce: nonce, expires: time.Now().Add(attestationChallengeTTL)}
+	return &pb.AttestationChallenge{Nonce: nonce, ExpiresInSeconds: int32(attestationChallengeTTL / time.Second)}, nil
+}
+
+// takeChallenge returns the unexpired nonce issued to a collaborator, if
+// any, and forgets it so that it answers only one join
+func (g *attestationGate) takeChallenge(collaboratorID string) []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	pending, ok := g.challenges[collaboratorID]
+	delete(g.challenges, collaboratorID)
+	if !ok || time.Now().After(pending.expires) {
+		return nil
+	}
+	return pending.nonce
+}
+
+// admit checks the attestation evidence of a joining collaborator against
+// the plan's policy and records the collaborator as admitted. Unless the plan
+// requires attestation, evidence that does not verify is logged and the
+// collaborator is admitted.
+func (g *attestationGate) admit(ctx context.Context, req *pb.JoinRequest) error {
+	config := g.plan.Security.Attestation
+	policy := security.AttestationPolicy{
+		Required:        config.Required,
+		Types:           config.Types,
+		Measurements:    config.Measurements,
+		AllowDebug:      config.AllowDebug,
+		VerifierCommand: config.VerifierCommand,
+		Exempt:          config.Exempt,
+	}
+	claims, err := policy.Admit(ctx, req.CollaboratorId, req.PlanFingerprint, g.takeChallenge(req.CollaboratorId),
+		req.Attestation.GetType(), req.Attestation.GetEvidence())
+	if err != nil {
+		if !config.Required {
+			log.Printf("Warning: attestation of collaborator %s did not verify: %v", req.CollaboratorId, err)
+			return nil
+		}
+		return err
+	}
+	if claims != nil {
+		log.Printf("Collaborator %s attested a %s enclave with measurement %.16s", req.CollaboratorId, claims.Type, claims.Measurement)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.admitted[req.CollaboratorId] = true
+	return nil
+}
+
+// authorize returns a PermissionDenied error unless a collaborator was
+// admitted by JoinFederation. Without required attestation every
+// collaborator is authorized, since any of them may join.
+func (g *attestationGate) authorize(collaboratorID string) error {
+	if !g.plan.Security.Attestation.Required {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.admitted[collaboratorID] {
+		return status.Errorf(codes.PermissionDenied, "collaborator %s has not joined with valid attestation evidence", collaboratorID)
+	}
+	return nil
+}
+
+// GetAttestationChallenge issues the nonce the attestation evidence of a
+// collaborator's next join commits to
+func (a *FedAvgAggregator) GetAttestationChallenge(ctx context.Context, req *pb.AttestationChallengeRequest) (*pb.AttestationChallenge, error) {
+	return a.attestation.challenge(req)
+}
+
+// GetAttestationChallenge issues the nonce the attestation evidence of a
+// collaborator's next join commits to
+func (a *AsyncFedAvgAggregator) GetAttestationChallenge(ctx context.Context, req *pb.AttestationChallengeRequest) (*pb.AttestationChallenge, error) {
+	return a.attestation.challenge(req)
+}
+
+// GetAttestationChallenge issues the nonce the attestation evidence of a
+// collaborator's next join commits to
+func (a *ModularAggregator) GetAttestationChallenge(ctx context.Context, req *pb.AttestationChallengeRequest) (*pb.AttestationChallenge, error) {
+	return a.attestation.challenge(req)
+}
+
+// GetAttestationChallenge issues the nonce the attestation evidence of a
+// collaborator's next join commits to
+func (a *EdgeAggregator) GetAttestationChallenge(ctx context.Context, req *pb.AttestationChallengeRequest) (*pb.AttestationChallenge, error) {
+	return a.attestation.challenge(req)
+}
@@ -0,0 +1,210 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// defaultTensorBoardDir holds the TensorBoard event files of each federation
+const defaultTensorBoardDir = "logs"
+
+// roundBoard collects what happens between two aggregations and writes it to
+// TensorBoard and MLflow as the scalars of the round the next aggregation
+// completes:
+//
+//	participation/updates, participation/fraction  accepted updates, also as a fraction of the plan's collaborators
+//	train/<metric>                                 mean of the local training metrics of the updates
+//	staleness/mean, staleness/max, staleness       staleness of aggregated async updates, with its histogram
+//	round/duration_seconds                         time since the previous round completed
+//	eval/<metric>                                  metrics of dispatched evaluate tasks, at the evaluated round
+//
+// Its methods are called with the RunRecorder's lock held.
+type roundBoard struct {
+	tensorboard   *monitoring.TensorBoardWriter // nil unless the plan enables TensorBoard
+	mlflow        *mlflowTracker                // nil unless the plan sets an MLflow tracking URI
+	collaborators int
+	since         time.Time
+	updates       int
+	training      map[string][]float64
+	staleness     []float64
+	scalars       []monitoring.MLflowMetric // written by flush
+}
+
+// newRoundBoard returns nil when the plan enables neither TensorBoard nor
+// MLflow. Exporters that fail to set up are left out and reported in errs.
+func newRoundBoard(plan *federation.FLPlan, federationID string) (board *roundBoard, errs []error) {
+	board = &roundBoard{
+		collaborators: len(plan.Collaborators),
+		since:         time.Now(),
+		training:      make(map[string][]float64),
+	}
+
+	if plan.Monitoring.TensorBoard {
+		dir := plan.Monitoring.TensorBoardDir
+		if dir == "" {
+			dir = defaultTensorBoardDir
+		}
+		writer, err := monitoring.NewTensorBoardWriter(filepath.Join(dir, federationID))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create TensorBoard event file: %w", err))
+		} else {
+			log.Printf("Writing TensorBoard scalars to %s", writer.Path())
+			board.tensorboard = writer
+		}
+	}
+
+	if plan.MLflow.TrackingURI != "" {
+		tracker, err := newMLflowTracker(plan, federationID)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			board.mlflow = tracker
+		}
+	}
+
+	if board.tensorboard == nil && board.mlflow == nil {
+		return nil, errs
+	}
+	return board, errs
+}
+
+// start starts the MLflow run
+func (b *roundBoard) start() error {
+	if b == nil {
+		return nil
+	}
+	b.since = time.Now()
+	if err := b.mlflow.start(); err != nil {
+		b.mlflow = nil
+		return err
+	}
+	return nil
+}
+
+func (b *roundBoard) recordUpdate() {
+	if b != nil {
+		b.updates++
+	}
+}
+
+func (b *roundBoard) recordTraining(metrics map[string]float64) {
+	if b == nil {
+		return
+	}
+	for name, value := range metrics {
+		b.training[name] = append(b.training[name], value)
+	}
+}
+
+func (b *roundBoard) recordStaleness(staleness []int) {
+	if b == nil {
+		return
+	}
+	for _, s := range staleness {
+		b.staleness = append(b.staleness, float64(s))
+	}
+}
+
+// completeRound writes the scalars collected since the previous round
+func (b *roundBoard) completeRound(round int) {
+	if b == nil {
+		return
+	}
+	now := time.Now()
+	b.add("participation/updates", float64(b.updates))
+	if b.collaborators > 0 {
+		b.add("participation/fraction", float64(b.updates)/float64(b.collaborators))
+	}
+	b.add("round/duration_seconds", now.Sub(b.since).Seconds())
+	b.addMeans("train/", b.training)
+	if len(b.staleness) > 0 {
+		sum, maxStaleness := 0.0, b.staleness[0]
+		for _, s := range b.staleness {
+			sum += s
+			maxStaleness = max(maxStaleness, s)
+		}
+		b.add("staleness/mean", sum/float64(len(b.staleness)))
+		b.add("staleness/max", maxStaleness)
+		if b.tensorboard != nil {
+			if err := b.tensorboard.AddHistogram("staleness", round, b.staleness); err != nil {
+				log.Printf("Failed to write TensorBoard histogram: %v", err)
+			}
+		}
+	}
+	b.flush(round)
+
+	b.since = now
+	b.updates = 0
+	b.training = make(map[string][]float64)
+	b.staleness = nil
+}
+
+// recordEvaluation writes the metrics of an evaluate task at the round it
+// evaluated
+func (b *roundBoard) recordEvaluation(round int, metrics map[string]float64) {
+	if b == nil {
+		return
+	}
+	values := make(map[string][]float64, len(metrics))
+	for name, value := range metrics {
+		values[name] = []float64{value}
+	}
+	b.addMeans("eval/", values)
+	b.flush(round)
+}
+
+// close closes the event file and ends the MLflow run with the status of the
+// run, logging the final model as an artifact
+func (b *roundBoard) close(status RunStatus, finalModel string) {
+	if b == nil {
+		return
+	}
+	if b.tensorboard != nil {
+		if err := b.tensorboard.Close(); err != nil {
+			log.Printf("Failed to close TensorBoard event file: %v", err)
+		}
+	}
+	b.mlflow.finish(status, finalModel)
+}
+
+// addMeans adds the mean of each metric's values under prefix, in name order
+func (b *roundBoard) addMeans(prefix string, metrics map[string][]float64) {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sum := 0.0
+		for _, v := range metrics[name] {
+			sum += v
+		}
+		b.add(prefix+name, sum/float64(len(metrics[name])))
+	}
+}
+
+func (b *roundBoard) add(tag string, value float64) {
+	b.scalars = append(b.scalars, monitoring.MLflowMetric{Key: tag, Value: value})
+}
+
+// flush writes the added scalars at step round
+func (b *roundBoard) flush(round int) {
+	timestamp := time.Now().UnixMilli()
+	for i := range b.scalars {
+		b.scalars[i].Step = round
+		b.scalars[i].Timestamp = timestamp
+		if b.tensorboard != nil {
+			if err := b.tensorboard.AddScalar(b.scalars[i].Key, round, b.scalars[i].Value); err != nil {
+				log.Printf("Failed to write TensorBoard scalar %s: %v", b.scalars[i].Key, err)
+			}
+		}
+	}
+	b.mlflow.logMetrics(b.scalars)
+	b.scalars = nil
+}
@@ -0,0 +1,97 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serverCapabilities returns the capabilities of an aggregator that supports
+// only the given optional features
+func serverCapabilities(features ...string) *pb.Capabilities {
+	capabilities := transport.LocalCapabilities()
+	capabilities.Features = features
+	return capabilities
+}
+
+// syncCapabilities are what the sync FedAvg aggregator supports. It only
+// exchanges deltas when the plan enables them.
+func syncCapabilities(plan *federation.FLPlan) *pb.Capabilities {
+	features := []string{transport.FeatureTaskDispatch, transport.FeatureLocalPrivacy, transport.FeatureEvents}
+	if plan.Aggregator.DeltaTransfer {
+		features = append(features, transport.FeatureDelta)
+	}
+	if plan.Security.Homomorphic.Enabled {
+		features = append(features, transport.FeatureEncrypted)
+	}
+	return serverCapabilities(features...)
+}
+
+// edgeCapabilities are what an edge aggregator supports. It neither
+// dispatches tasks nor forwards privacy parameters to the root, but streams
+// round starts to its collaborators.
+func edgeCapabilities() *pb.Capabilities {
+	return serverCapabilities(transport.FeatureEvents)
+}
+
+// negotiate agrees on the capabilities of a joining collaborator. Plans with
+// task dispatch only admit collaborators that support it, since older ones
+// would train on their own schedule instead of polling for tasks, and plans
+// with encrypted updates only admit collaborators that encrypt them.
+// Collaborators running a different revision of the plan, or without the
+// attestation evidence the plan requires, are rejected. Collaborators that
+// are not rejected are admitted by attestation.
+func negotiate(ctx context.Context, plan *federation.FLPlan, attestation *attestationGate, local *pb.Capabilities, req *pb.JoinRequest) (*pb.Capabilities, error) {
+	if err := checkPlanFingerprint(plan, req); err != nil {
+		log.Printf("Rejected collaborator %s: %v", req.CollaboratorId, err)
+		return nil, fmt.Errorf("collaborator %s is incompatible with this aggregator: %v", req.CollaboratorId, err)
+	}
+	var required []string
+	if plan.Dispatch.Enabled {
+		required = append(required, transport.FeatureTaskDispatch)
+	}
+	if plan.Security.Homomorphic.Enabled {
+		required = append(required, transport.FeatureEncrypted)
+	}
+	capabilities, err := transport.Negotiate(local, req.Capabilities, required...)
+	if err != nil {
+		log.Printf("Rejected collaborator %s: %v", req.CollaboratorId, err)
+		return nil, fmt.Errorf("collaborator %s is incompatible with this aggregator: %v", req.CollaboratorId, err)
+	}
+	if err := attestation.admit(ctx, req); err != nil {
+		log.Printf("Rejected collaborator %s: %v", req.CollaboratorId, err)
+		return nil, status.Errorf(codes.PermissionDenied, "collaborator %s failed attestation: %v", req.CollaboratorId, err)
+	}
+	gpu := ""
+	if req.Capabilities.GetHasGpu() {
+		gpu = ", has GPU"
+	}
+	log.Printf("Collaborator %s speaks protocol version %d (features: %s%s)",
+		req.CollaboratorId, capabilities.ProtoVersion, strings.Join(capabilities.Features, ", "), gpu)
+	return capabilities, nil
+}
+
+// checkPlanFingerprint compares the fingerprint of the collaborator's plan
+// with the aggregator's. Collaborators that send none are older releases and
+// are admitted.
+func checkPlanFingerprint(plan *federation.FLPlan, req *pb.JoinRequest) error {
+	if req.PlanFingerprint == "" {
+		return nil
+	}
+	fingerprint, err := federation.PlanFingerprint(plan)
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint plan: %v", err)
+	}
+	if req.PlanFingerprint != fingerprint {
+		return fmt.Errorf("plan fingerprint %.12s differs from the aggregator's %.12s; compare the plans with fx plan diff",
+			req.PlanFingerprint, fingerprint)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package aggregator
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// clipNorm returns the plan's cap on the L2 norm of an update's change, or 0
+// when updates are not clipped
+func clipNorm(plan *federation.FLPlan) float64 {
+	if !plan.Security.UpdateClipping.Enabled {
+		return 0
+	}
+	return plan.Security.UpdateClipping.MaxNorm
+}
+
+// clipUpdate scales the change from base to weights down to an L2 norm of
+// maxNorm, in place, when it is larger. It returns the norm of the change
+// and whether it was clipped. A maxNorm of 0 disables clipping.
+func clipUpdate(weights, base []float32, maxNorm float64) (float64, bool) {
+	if maxNorm <= 0 || len(weights) != len(base) {
+		return 0, false
+	}
+	var norm float64
+	for i := range weights {
+		d := float64(weights[i] - base[i])
+		norm += d * d
+	}
+	norm = math.Sqrt(norm)
+	if norm <= maxNorm {
+		return norm, false
+	}
+	scale := maxNorm / norm
+	parallelFor(len(weights), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			weights[i] = base[i] + float32(float64(weights[i]-base[i])*scale)
+		}
+	})
+	return norm, true
+}
+
+// clipEncoded is clipUpdate for an encoded update of an encoded model
+func clipEncoded(data, base []byte, maxNorm float64) (float64, bool) {
+	if maxNorm <= 0 || len(data) != len(base) {
+		return 0, false
+	}
+	at := func(buf []byte, i int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	n := len(data) / 4
+	var norm float64
+	for i := 0; i < n; i++ {
+		d := float64(at(data, i) - at(base, i))
+		norm += d * d
+	}
+	norm = math.Sqrt(norm)
+	if norm <= maxNorm {
+		return norm, false
+	}
+	scale := maxNorm / norm
+	parallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			b := at(base, i)
+			putFloat(data, i, b+float32(float64(at(data, i)-b)*scale))
+		}
+	})
+	return norm, true
+}
+
+// clipUpdates clips each update against the model it was trained on, as
+// returned by base, and returns the number of updates that were clipped
+func clipUpdates(updates []ClientUpdate, base func(ClientUpdate) []float32, maxNorm float64) int {
+	if maxNorm <= 0 {
+		return 0
+	}
+	clipped := 0
+	for _, update := range updates {
+		if norm, ok := clipUpdate(update.Weights, base(update), maxNorm); ok {
+			logClipped(update.CollaboratorID, update.Round, norm, maxNorm)
+			clipped++
+		}
+	}
+	return clipped
+}
+
+func logClipped(collaboratorID string, round int, norm, maxNorm float64) {
+	log.Printf("Clipped update from %s (round %d): norm %.4g exceeds %.4g", collaboratorID, round, norm, maxNorm)
+}
+
+// aggregationMetrics describes an aggregation of updates that started at
+// start, of which clipped were clipped to clipNorm
+func aggregationMetrics(round int, algorithm string, start time.Time, updates, clipped int, clipNorm float64) monitoring.AggregationMetrics {
+	end := time.Now()
+	metrics := monitoring.AggregationMetrics{
+		RoundNumber:       round,
+		Algorithm:         algorithm,
+		StartTime:         start,
+		EndTime:           end,
+		Duration:          end.Sub(start),
+		UpdatesAggregated: updates,
+		UpdatesClipped:    clipped,
+		ClipNorm:          clipNorm,
+	}
+	if updates > 0 {
+		metrics.ClippedFraction = float64(clipped) / float64(updates)
+	}
+	return metrics
+}
@@ -0,0 +1,76 @@
+package aggregator
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClipUpdate(t *testing.T) {
+	base := []float32{1, 1}
+	tests := []struct {
+		name        string
+		weights     []float32
+		maxNorm     float64
+		wantNorm    float64
+		wantClipped bool
+		want        []float32
+	}{
+		{"within the cap", []float32{4, 5}, 5, 5, false, []float32{4, 5}},
+		{"above the cap", []float32{4, 5}, 2.5, 5, true, []float32{2.5, 3}},
+		{"disabled", []float32{4, 5}, 0, 0, false, []float32{4, 5}},
+		{"size mismatch", []float32{4}, 1, 0, false, []float32{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights := append([]float32(nil), tt.weights...)
+			norm, clipped := clipUpdate(weights, base, tt.maxNorm)
+			if clipped != tt.wantClipped || math.Abs(norm-tt.wantNorm) > 1e-6 {
+				t.Errorf("clipUpdate() = %g, %v, want %g, %v", norm, clipped, tt.wantNorm, tt.wantClipped)
+			}
+			for i := range tt.want {
+				if math.Abs(float64(weights[i]-tt.want[i])) > 1e-6 {
+					t.Errorf("weights = %v, want %v", weights, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestClipEncoded(t *testing.T) {
+	base := encodeModel([]float32{1, 1})
+	data := encodeModel([]float32{4, 5})
+	norm, clipped := clipEncoded(data, base, 2.5)
+	if !clipped || math.Abs(norm-5) > 1e-6 {
+		t.Fatalf("clipEncoded() = %g, %v, want 5, true", norm, clipped)
+	}
+	weights, _, err := decodeUpdate(data, 2)
+	if err != nil {
+		t.Fatalf("decodeUpdate() error = %v", err)
+	}
+	if math.Abs(float64(weights[0]-2.5)) > 1e-6 || math.Abs(float64(weights[1]-3)) > 1e-6 {
+		t.Errorf("weights = %v, want [2.5 3]", weights)
+	}
+}
+
+func TestClipUpdatesCountsClipped(t *testing.T) {
+	models := map[string][]float32{"a": {0}, "b": {10}}
+	updates := []ClientUpdate{
+		{CollaboratorID: "a", Weights: []float32{3}},
+		{CollaboratorID: "b", Weights: []float32{11}},
+	}
+	clipped := clipUpdates(updates, func(update ClientUpdate) []float32 { return models[update.CollaboratorID] }, 2)
+	if clipped != 1 {
+		t.Errorf("clipped = %d, want 1", clipped)
+	}
+	if updates[0].Weights[0] != 2 || updates[1].Weights[0] != 11 {
+		t.Errorf("weights = %v, %v, want [2], [11]", updates[0].Weights, updates[1].Weights)
+	}
+
+	metrics := aggregationMetrics(1, "fedavg", time.Now(), len(updates), clipped, 2)
+	if metrics.UpdatesClipped != 1 || metrics.ClippedFraction != 0.5 || metrics.ClipNorm != 2 {
+		t.Errorf("metrics = %+v, want 1 clipped, fraction 0.5, norm 2", metrics)
+	}
+}
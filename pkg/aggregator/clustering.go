@@ -0,0 +1,306 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// maxClusterIterations bounds the k-means iterations of a re-clustering
+const maxClusterIterations = 50
+
+// clusterSet holds the global models of a clustered federation and the
+// cluster each collaborator trains in. Every cluster has its own instance of
+// the aggregation algorithm, so that stateful algorithms keep their state
+// per model.
+type clusterSet struct {
+	every      int
+	models     [][]float32
+	algorithms []AggregationAlgorithm
+	hashes     []string       // hash of each model at the start of the round
+	members    map[string]int // collaborator -> cluster; unlisted ones are in cluster 0
+}
+
+// newClusterSet starts every cluster from the initial model
+func newClusterSet(config federation.ClusteringConfig, algType AlgorithmType, algConfig AlgorithmConfig, initial []float32) (*clusterSet, error) {
+	k, every := config.Clusters, config.ReclusterEvery
+	if k == 0 {
+		k = federation.DefaultClusters
+	}
+	if every == 0 {
+		every = federation.DefaultReclusterEvery
+	}
+	if k < 1 || every < 1 {
+		return nil, fmt.Errorf("clustering.clusters and clustering.recluster_every must be positive")
+	}
+
+	s := &clusterSet{every: every, members: make(map[string]int)}
+	for c := 0; c < k; c++ {
+		algorithm, err := CreateAggregationAlgorithm(algType)
+		if err != nil {
+			return nil, err
+		}
+		if err := algorithm.Initialize(algConfig); err != nil {
+			return nil, fmt.Errorf("failed to initialize algorithm of cluster %d: %v", c, err)
+		}
+		s.models = append(s.models, slices.Clone(initial))
+		s.algorithms = append(s.algorithms, algorithm)
+	}
+	return s, nil
+}
+
+// clusterOf returns the cluster of a collaborator
+func (s *clusterSet) clusterOf(collaboratorID string) int {
+	return s.members[collaboratorID]
+}
+
+// startRound records the hashes of the models the round trains on
+func (s *clusterSet) startRound() {
+	s.hashes = make([]string, len(s.models))
+	for c, model := range s.models {
+		s.hashes[c] = transport.ModelHash(encodeModel(model))
+	}
+}
+
+// reclusters reports whether the collaborators are re-clustered after round
+func (s *clusterSet) reclusters(round int) bool {
+	return (round-1)%s.every == 0
+}
+
+// aggregate returns the models and membership after round. The updates of a
+// re-clustering round are clustered by the direction they moved their
+// cluster's model in before each cluster aggregates its members' updates.
+// An update that moved to another cluster is aggregated as its change
+// applied to that cluster's model. Clusters without updates keep their
+// model. The models and membership of s are not changed, but the algorithms
+// of the clusters that aggregated keep the round in their state, such as
+// momentum, so the caller must use the results.
+func (s *clusterSet) aggregate(round int, updates []ClientUpdate) ([][]float32, map[string]int, error) {
+	members := make(map[string]int, len(s.members))
+	for id, c := range s.members {
+		members[id] = c
+	}
+	if s.reclusters(round) {
+		deltas := make([][]float32, len(updates))
+		assignment := make([]int, len(updates))
+		for i, update := range updates {
+			assignment[i] = s.clusterOf(update.CollaboratorID)
+			base := s.models[assignment[i]]
+			deltas[i] = make([]float32, len(update.Weights))
+			for j := range update.Weights {
+				if j < len(base) {
+					deltas[i][j] = update.Weights[j] - base[j]
+				}
+			}
+		}
+		assignment = assignClusters(deltas, assignment, len(s.models))
+		for i, update := range updates {
+			members[update.CollaboratorID] = assignment[i]
+		}
+	}
+
+	models := slices.Clone(s.models)
+	for c := range s.models {
+		var clusterUpdates []ClientUpdate
+		for _, update := range updates {
+			if members[update.CollaboratorID] == c {
+				clusterUpdates = append(clusterUpdates, s.rebase(update, c))
+			}
+		}
+		if len(clusterUpdates) == 0 {
+			continue
+		}
+		model, err := s.algorithms[c].Aggregate(clusterUpdates, s.models[c])
+		if err != nil {
+			return nil, nil, fmt.Errorf("cluster %d: %v", c, err)
+		}
+		models[c] = model
+	}
+	return models, members, nil
+}
+
+// rebase returns an update moved onto the model of cluster c. An update
+// trained on another cluster's model carries its change to that model over.
+func (s *clusterSet) rebase(update ClientUpdate, c int) ClientUpdate {
+	from := s.clusterOf(update.CollaboratorID)
+	if from == c {
+		return update
+	}
+	base, target := s.models[from], s.models[c]
+	weights := slices.Clone(update.Weights)
+	for j := range weights {
+		if j < len(base) && j < len(target) {
+			weights[j] += target[j] - base[j]
+		}
+	}
+	update.Weights = weights
+	return update
+}
+
+// largest returns the cluster with the most members, preferring the lowest
+func (s *clusterSet) largest() int {
+	sizes := s.sizes()
+	largest := 0
+	for c, size := range sizes {
+		if size > sizes[largest] {
+			largest = c
+		}
+	}
+	return largest
+}
+
+// sizes returns the number of members of each cluster
+func (s *clusterSet) sizes() []int {
+	sizes := make([]int, len(s.models))
+	for _, c := range s.members {
+		sizes[c]++
+	}
+	return sizes
+}
+
+// formatClusters describes the members of each cluster for the log
+func formatClusters(members map[string]int, k int) string {
+	clusters := make([][]string, k)
+	for id, c := range members {
+		clusters[c] = append(clusters[c], id)
+	}
+	parts := make([]string, k)
+	for c, ids := range clusters {
+		slices.Sort(ids)
+		parts[c] = fmt.Sprintf("%d: [%s]", c, strings.Join(ids, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// clusterModelPath returns where the model of cluster c is saved next to the
+// model at path
+func clusterModelPath(path string, c int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s_cluster_%d%s", strings.TrimSuffix(path, ext), c, ext)
+}
+
+// assignClusters groups vectors into k clusters by cosine similarity with
+// spherical k-means, starting from assignment. Clusters without vectors are
+// seeded with the vector least similar to the other clusters, so the first
+// re-clustering splits off the most different collaborators. It returns the
+// new assignment.
+func assignClusters(vectors [][]float32, assignment []int, k int) []int {
+	units := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		units[i] = unitVector(v)
+	}
+	assignment = slices.Clone(assignment)
+	centroids := clusterCentroids(units, assignment, k, nil)
+
+	// Seed empty clusters
+	seeded := make(map[int]bool)
+	for c := range centroids {
+		if centroids[c] != nil {
+			continue
+		}
+		seed, lowest := -1, math.Inf(1)
+		for i, u := range units {
+			if seeded[i] {
+				continue
+			}
+			closest := math.Inf(-1)
+			for _, centroid := range centroids {
+				if centroid != nil {
+					closest = max(closest, dot(u, centroid))
+				}
+			}
+			if closest < lowest {
+				seed, lowest = i, closest
+			}
+		}
+		if seed < 0 {
+			break // fewer vectors than clusters
+		}
+		seeded[seed] = true
+		centroids[c] = units[seed]
+	}
+
+	for iteration := 0; iteration < maxClusterIterations; iteration++ {
+		changed := false
+		for i, u := range units {
+			best, bestSimilarity := assignment[i], math.Inf(-1)
+			for c, centroid := range centroids {
+				if centroid == nil {
+					continue
+				}
+				if similarity := dot(u, centroid); similarity > bestSimilarity {
+					best, bestSimilarity = c, similarity
+				}
+			}
+			if best != assignment[i] {
+				assignment[i] = best
+				changed = true
+			}
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+		centroids = clusterCentroids(units, assignment, k, centroids)
+	}
+	return assignment
+}
+
+// clusterCentroids returns the normalized mean of each cluster's vectors.
+// Clusters without vectors keep their previous centroid, or nil.
+func clusterCentroids(units [][]float64, assignment []int, k int, previous [][]float64) [][]float64 {
+	centroids := make([][]float64, k)
+	for i, u := range units {
+		c := assignment[i]
+		if centroids[c] == nil {
+			centroids[c] = make([]float64, len(u))
+		}
+		for j := range u {
+			if j < len(centroids[c]) {
+				centroids[c][j] += u[j]
+			}
+		}
+	}
+	for c := range centroids {
+		if centroids[c] == nil {
+			if previous != nil {
+				centroids[c] = previous[c]
+			}
+			continue
+		}
+		normalize(centroids[c])
+	}
+	return centroids
+}
+
+func unitVector(v []float32) []float64 {
+	u := make([]float64, len(v))
+	for i, x := range v {
+		u[i] = float64(x)
+	}
+	normalize(u)
+	return u
+}
+
+// normalize scales v to unit length; zero vectors are left as they are
+func normalize(v []float64) {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
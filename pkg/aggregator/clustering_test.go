@@ -0,0 +1,106 @@
+package aggregator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestAssignClusters(t *testing.T) {
+	tests := []struct {
+		name       string
+		vectors    [][]float32
+		assignment []int
+		k          int
+		expected   []int
+	}{
+		{"splits opposite directions",
+			[][]float32{{1, 0.1}, {-1, 0}, {0.9, 0}, {-2, 0.1}}, []int{0, 0, 0, 0}, 2, []int{1, 0, 1, 0}},
+		{"keeps cluster labels",
+			[][]float32{{1, 0}, {0, 1}, {0.1, 1}}, []int{1, 0, 0}, 2, []int{1, 0, 0}},
+		{"moves a collaborator that changed direction",
+			[][]float32{{1, 0}, {0, 1}, {1, 0.1}}, []int{0, 1, 1}, 2, []int{0, 1, 0}},
+		{"fewer vectors than clusters",
+			[][]float32{{1, 0}}, []int{0}, 3, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assignClusters(tt.vectors, tt.assignment, tt.k); !slices.Equal(got, tt.expected) {
+				t.Errorf("assignClusters() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClusterSetAggregate(t *testing.T) {
+	config := federation.ClusteringConfig{Enabled: true, Clusters: 2, ReclusterEvery: 2}
+	clusters, err := newClusterSet(config, FedAvg, AlgorithmConfig{ModelSize: 2}, []float32{0, 0})
+	if err != nil {
+		t.Fatalf("newClusterSet() error = %v", err)
+	}
+
+	round := func(round int, weights map[string][]float32) {
+		t.Helper()
+		var updates []ClientUpdate
+		for _, id := range []string{"a", "b", "c", "d"} {
+			updates = append(updates, ClientUpdate{CollaboratorID: id, Weights: weights[id], NumSamples: 1})
+		}
+		models, members, err := clusters.aggregate(round, updates)
+		if err != nil {
+			t.Fatalf("aggregate() error = %v", err)
+		}
+		clusters.models, clusters.members = models, members
+	}
+
+	// Round 1 re-clusters: a and c move right, b and d move left
+	round(1, map[string][]float32{"a": {1, 0}, "b": {-1, 0}, "c": {3, 0}, "d": {-3, 0}})
+	right, left := clusters.clusterOf("a"), clusters.clusterOf("b")
+	if right == left || clusters.clusterOf("c") != right || clusters.clusterOf("d") != left {
+		t.Fatalf("members = %v, want a and c apart from b and d", clusters.members)
+	}
+	if !slices.Equal(clusters.models[right], []float32{2, 0}) || !slices.Equal(clusters.models[left], []float32{-2, 0}) {
+		t.Errorf("models = %v, want [2 0] for a and c and [-2 0] for b and d", clusters.models)
+	}
+
+	// Round 2 keeps the clusters even though a now moves left
+	round(2, map[string][]float32{"a": {0, 0}, "b": {-2, 0}, "c": {2, 0}, "d": {-2, 0}})
+	if got := clusters.clusterOf("a"); got != right {
+		t.Errorf("clusterOf(a) = %d after round 2, want %d", got, right)
+	}
+	if !slices.Equal(clusters.models[right], []float32{1, 0}) {
+		t.Errorf("model of a and c = %v, want [1 0]", clusters.models[right])
+	}
+
+	// Round 3 moves a to the left cluster, which aggregates a's change of -2
+	// applied to its model [-2 0] rather than a's weights
+	updates := []ClientUpdate{
+		{CollaboratorID: "a", Weights: []float32{-1, 0}, NumSamples: 1},
+		{CollaboratorID: "b", Weights: []float32{-4, 0}, NumSamples: 1},
+		{CollaboratorID: "c", Weights: []float32{3, 0}, NumSamples: 1},
+		{CollaboratorID: "d", Weights: []float32{-4, 0}, NumSamples: 1},
+	}
+	models, members, err := clusters.aggregate(3, updates)
+	if err != nil {
+		t.Fatalf("aggregate() error = %v", err)
+	}
+	if members["a"] != left {
+		t.Fatalf("members = %v after round 3, want a in cluster %d", members, left)
+	}
+	if !slices.Equal(models[left], []float32{-4, 0}) || !slices.Equal(models[right], []float32{3, 0}) {
+		t.Errorf("models = %v, want [-4 0] for a, b and d and [3 0] for c", models)
+	}
+	if !slices.Equal(updates[0].Weights, []float32{-1, 0}) {
+		t.Errorf("aggregate() changed the weights of a to %v", updates[0].Weights)
+	}
+	if clusters.clusterOf("a") != right || !slices.Equal(clusters.models[left], []float32{-2, 0}) {
+		t.Errorf("aggregate() changed the cluster set: members %v, models %v", clusters.members, clusters.models)
+	}
+}
+
+func TestClusterModelPath(t *testing.T) {
+	if got := clusterModelPath("save/final_model.pt", 1); got != "save/final_model_cluster_1.pt" {
+		t.Errorf("clusterModelPath() = %s", got)
+	}
+}
@@ -0,0 +1,78 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// scoreContributions scores how much each update contributed to the change
+// an aggregation made from previous to next. base returns the model an
+// update was trained on. An update's cosine is the cosine similarity of its
+// change with the aggregate's, and its share is the projection of its change
+// onto the aggregate's, weighted by the update's sample count and staleness
+// like FedAvg weighs it. The shares of a FedAvg round sum to 1; an update
+// with a negative score moved the model away from where it went.
+func scoreContributions(round int, updates []ClientUpdate, base func(ClientUpdate) []float32, previous, next []float32) []monitoring.ContributionScore {
+	step := make([]float64, len(next))
+	var stepNorm float64
+	for i := range next {
+		if i < len(previous) {
+			step[i] = float64(next[i] - previous[i])
+		}
+		stepNorm += step[i] * step[i]
+	}
+
+	var totalWeight float64
+	for _, update := range updates {
+		totalWeight += float64(update.sampleWeight())
+	}
+
+	now := time.Now()
+	scores := make([]monitoring.ContributionScore, len(updates))
+	for k, update := range updates {
+		model := base(update)
+		var dot, norm float64
+		for i := range min(len(update.Weights), len(model), len(step)) {
+			d := float64(update.Weights[i] - model[i])
+			dot += d * step[i]
+			norm += d * d
+		}
+
+		scores[k] = monitoring.ContributionScore{
+			CollaboratorID: update.CollaboratorID,
+			RoundNumber:    round,
+			Timestamp:      now,
+		}
+		if norm > 0 && stepNorm > 0 {
+			scores[k].Cosine = dot / math.Sqrt(norm*stepNorm)
+		}
+		if stepNorm > 0 && totalWeight > 0 {
+			scores[k].Share = float64(update.sampleWeight()) / totalWeight * dot / stepNorm
+		}
+	}
+	return scores
+}
+
+// reportContributions logs the contribution scores of round and reports them
+// to monitoring
+func reportContributions(reporter *UpdateReporter, round int, scores []monitoring.ContributionScore) {
+	if len(scores) == 0 {
+		return
+	}
+	log.Printf("Round %d contributions: %s", round, formatContributions(scores))
+	reporter.RecordContributions(scores)
+}
+
+// formatContributions describes contribution scores for the log
+func formatContributions(scores []monitoring.ContributionScore) string {
+	parts := make([]string, len(scores))
+	for k, score := range scores {
+		parts[k] = fmt.Sprintf("%s %.3f (cosine %.3f)", score.CollaboratorID, score.Share, score.Cosine)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreContributions(t *testing.T) {
+	global := []float32{0, 0}
+	updates := []ClientUpdate{
+		{CollaboratorID: "a", Weights: []float32{3, 0}, NumSamples: 2},
+		{CollaboratorID: "b", Weights: []float32{0, 3}, NumSamples: 1},
+		{CollaboratorID: "c", Weights: []float32{-3, 0}, NumSamples: 1},
+	}
+	algorithm := &FedAvgAlgorithm{}
+	if err := algorithm.Initialize(AlgorithmConfig{ModelSize: 2}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	next, err := algorithm.Aggregate(updates, global)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	base := func(ClientUpdate) []float32 { return global }
+	scores := scoreContributions(1, updates, base, global, next)
+	if len(scores) != len(updates) {
+		t.Fatalf("got %d scores, want %d", len(scores), len(updates))
+	}
+
+	// The aggregate moved to (0.75, 0.75)
+	expected := []struct{ cosine, share float64 }{
+		{math.Sqrt2 / 2, 1},
+		{math.Sqrt2 / 2, 0.5},
+		{-math.Sqrt2 / 2, -0.5},
+	}
+	var total float64
+	for k, score := range scores {
+		if score.RoundNumber != 1 || score.CollaboratorID != updates[k].CollaboratorID {
+			t.Errorf("score %d = %+v, want round 1 of %s", k, score, updates[k].CollaboratorID)
+		}
+		if math.Abs(score.Cosine-expected[k].cosine) > 1e-6 || math.Abs(score.Share-expected[k].share) > 1e-6 {
+			t.Errorf("%s cosine, share = %g, %g, want %g, %g",
+				score.CollaboratorID, score.Cosine, score.Share, expected[k].cosine, expected[k].share)
+		}
+		total += score.Share
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Errorf("shares sum to %g, want 1", total)
+	}
+}
+
+func TestScoreContributionsWithoutChange(t *testing.T) {
+	global := []float32{1}
+	updates := []ClientUpdate{{CollaboratorID: "a", Weights: []float32{1}, NumSamples: 1}}
+	scores := scoreContributions(1, updates, func(ClientUpdate) []float32 { return global }, global, global)
+	if scores[0].Cosine != 0 || scores[0].Share != 0 {
+		t.Errorf("score = %+v, want zero cosine and share", scores[0])
+	}
+}
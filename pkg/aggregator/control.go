@@ -0,0 +1,217 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrAborted is returned by the Start of an aggregator whose federation was
+// aborted through the admin API
+var ErrAborted = errors.New("the federation was aborted")
+
+// ControlState is the state of a federation run as seen by the admin API
+type ControlState string
+
+const (
+	ControlIdle    ControlState = "idle" // not started yet, or over
+	ControlRunning ControlState = "running"
+	ControlPaused  ControlState = "paused"
+	ControlAborted ControlState = "aborted"
+)
+
+// Controllable is implemented by aggregators whose federation can be paused,
+// resumed and aborted while it runs
+type Controllable interface {
+	Pause() error
+	Resume() error
+	Abort() error
+	ControlState() ControlState
+}
+
+// runControl pauses, resumes and aborts a federation run. Sync aggregators
+// pause between rounds; async aggregators stop aggregating but keep
+// accepting updates. Aborting cancels the run's context with ErrAborted.
+type runControl struct {
+	mu      sync.Mutex
+	state   ControlState
+	resumed chan struct{} // closed when a paused run resumes
+	cancel  context.CancelCauseFunc
+}
+
+func newRunControl() *runControl {
+	return &runControl{state: ControlIdle}
+}
+
+// start returns the context of a run, which Abort cancels, and a function to
+// call when the run is over
+func (c *runControl) start(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	c.mu.Lock()
+	c.state = ControlRunning
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	return ctx, func() {
+		c.mu.Lock()
+		if c.state != ControlAborted {
+			c.state = ControlIdle
+		}
+		c.cancel = nil
+		c.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Pause stops the federation from starting new rounds
+func (c *runControl) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case ControlPaused:
+		return nil
+	case ControlRunning:
+		c.state = ControlPaused
+		c.resumed = make(chan struct{})
+		log.Printf("Federation paused")
+		return nil
+	default:
+		return fmt.Errorf("the federation is %s", c.state)
+	}
+}
+
+// Resume continues a paused federation
+func (c *runControl) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case ControlRunning:
+		return nil
+	case ControlPaused:
+		c.state = ControlRunning
+		close(c.resumed)
+		log.Printf("Federation resumed")
+		return nil
+	default:
+		return fmt.Errorf("the federation is %s", c.state)
+	}
+}
+
+// Abort ends the federation. The aggregator stops like on shutdown and
+// returns ErrAborted from Start.
+func (c *runControl) Abort() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case ControlAborted:
+		return nil
+	case ControlRunning, ControlPaused:
+		c.state = ControlAborted
+		log.Printf("Aborting the federation")
+		c.cancel(ErrAborted)
+		return nil
+	default:
+		return fmt.Errorf("the federation is %s", c.state)
+	}
+}
+
+// ControlState returns the state of the run
+func (c *runControl) ControlState() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// paused reports whether the run is paused
+func (c *runControl) paused() bool {
+	return c.ControlState() == ControlPaused
+}
+
+// wait blocks while the run is paused. It returns the cause of ctx if ctx is
+// done first.
+func (c *runControl) wait(ctx context.Context, round int) error {
+	c.mu.Lock()
+	paused, resumed := c.state == ControlPaused, c.resumed
+	c.mu.Unlock()
+
+	if paused {
+		log.Printf("Federation is paused, round %d starts once it resumes", round)
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+		}
+	}
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// adminServer serves the AdminService RPCs of an aggregator
+type adminServer struct {
+	adminpb.UnimplementedAdminServiceServer
+	control Administrable
+	plan    *federation.FLPlan
+	token   string // required as x-api-key metadata
+}
+
+func (s *adminServer) Pause(ctx context.Context, req *adminpb.ControlRequest) (*adminpb.ControlResponse, error) {
+	return s.apply(ctx, "pause", req, s.control.Pause)
+}
+
+func (s *adminServer) Resume(ctx context.Context, req *adminpb.ControlRequest) (*adminpb.ControlResponse, error) {
+	return s.apply(ctx, "resume", req, s.control.Resume)
+}
+
+func (s *adminServer) Abort(ctx context.Context, req *adminpb.ControlRequest) (*adminpb.ControlResponse, error) {
+	return s.apply(ctx, "abort", req, s.control.Abort)
+}
+
+// authorize checks the admin token of a call. The client certificate the
+// admin address requires does not identify an operator on its own, since the
+// plan's CA also signs the collaborators' certificates.
+func (s *adminServer) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return status.Error(codes.Unauthenticated, "no admin token is configured")
+	}
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			key = values[0]
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(s.token)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return nil
+}
+
+// apply authorizes a control call and applies its action
+func (s *adminServer) apply(ctx context.Context, name string, req *adminpb.ControlRequest, action func() error) (*adminpb.ControlResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.GetReason() != "" {
+		log.Printf("Admin request to %s the federation: %s", name, req.GetReason())
+	} else {
+		log.Printf("Admin request to %s the federation", name)
+	}
+	if err := action(); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot %s: %v", name, err)
+	}
+	return &adminpb.ControlResponse{State: string(s.control.ControlState())}, nil
+}
@@ -0,0 +1,126 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunControl(t *testing.T) {
+	control := newRunControl()
+	if err := control.Pause(); err == nil {
+		t.Error("Pause before the run started succeeded")
+	}
+
+	ctx, stop := control.start(context.Background())
+	defer stop()
+	if err := control.wait(ctx, 1); err != nil {
+		t.Fatalf("wait while running: %v", err)
+	}
+
+	if err := control.Pause(); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if err := control.Pause(); err != nil || control.ControlState() != ControlPaused {
+		t.Fatalf("second Pause = %v, state %s", err, control.ControlState())
+	}
+
+	waited := make(chan error, 1)
+	go func() { waited <- control.wait(ctx, 2) }()
+	select {
+	case err := <-waited:
+		t.Fatalf("wait returned %v while paused", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := control.Resume(); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("wait after resume = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait did not return after resume")
+	}
+
+	// Aborting a paused run ends the wait with ErrAborted
+	control.Pause()
+	go func() { waited <- control.wait(ctx, 3) }()
+	if err := control.Abort(); err != nil {
+		t.Fatalf("Abort: %v", err)
+	}
+	select {
+	case err := <-waited:
+		if !errors.Is(err, ErrAborted) {
+			t.Errorf("wait after abort = %v, want ErrAborted", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait did not return after abort")
+	}
+	if err := control.Resume(); err == nil {
+		t.Error("Resume after abort succeeded")
+	}
+
+	stop()
+	if state := control.ControlState(); state != ControlAborted {
+		t.Errorf("state after the run = %s, want %s", state, ControlAborted)
+	}
+}
+
+func TestAdminServer(t *testing.T) {
+	agg := NewFedAvgAggregator(&federation.FLPlan{
+		Aggregator: federation.AggregatorEntry{AdminToken: "secret"},
+	})
+	ctx, stop := agg.runControl.start(context.Background())
+	defer stop()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	adminpb.RegisterAdminServiceServer(srv, &adminServer{control: agg, plan: agg.plan, token: agg.plan.Aggregator.AdminToken})
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := adminpb.NewAdminServiceClient(conn)
+
+	if _, err := client.Pause(context.Background(), &adminpb.ControlRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Pause without token = %v, want Unauthenticated", err)
+	}
+
+	authorized := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	resp, err := client.Pause(authorized, &adminpb.ControlRequest{Reason: "maintenance"})
+	if err != nil || resp.State != string(ControlPaused) {
+		t.Fatalf("Pause = %v, %v", resp, err)
+	}
+	if resp, err = client.Resume(authorized, &adminpb.ControlRequest{}); err != nil || resp.State != string(ControlRunning) {
+		t.Fatalf("Resume = %v, %v", resp, err)
+	}
+	if resp, err = client.Abort(authorized, &adminpb.ControlRequest{}); err != nil || resp.State != string(ControlAborted) {
+		t.Fatalf("Abort = %v, %v", resp, err)
+	}
+	if !errors.Is(context.Cause(ctx), ErrAborted) {
+		t.Errorf("run context cause = %v, want ErrAborted", context.Cause(ctx))
+	}
+	if _, err := client.Pause(authorized, &adminpb.ControlRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Pause after abort = %v, want FailedPrecondition", err)
+	}
+}
@@ -0,0 +1,123 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// deltaHistory is the number of global models an aggregator keeps to send
+// diffs from: the latest one and the one before it, which collaborators that
+// trained in the previous round still have
+const deltaHistory = 2
+
+// modelHistory tracks the latest global models of an aggregator that
+// transfers deltas, so that a collaborator can be sent the diff from the
+// model it has instead of the full model. Diffs to the latest model are
+// computed once per base and shared by all collaborators.
+type modelHistory struct {
+	mu     sync.Mutex
+	hashes []string // oldest first; the last one is the latest model
+	models map[string][]byte
+	deltas map[string][]byte // diff to the latest model by base hash
+}
+
+// newModelHistory returns nil when the plan does not enable delta transfer
+func newModelHistory(plan *federation.FLPlan) *modelHistory {
+	if !plan.Aggregator.DeltaTransfer {
+		return nil
+	}
+	return &modelHistory{models: make(map[string][]byte), deltas: make(map[string][]byte)}
+}
+
+// Add records the encoded model with hash as the latest model
+func (h *modelHistory) Add(hash string, model []byte) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.models[hash]; !ok {
+		h.hashes = append(h.hashes, hash)
+		h.models[hash] = model
+	}
+	h.retain(deltaHistory)
+	clear(h.deltas)
+}
+
+// Retain forgets all but the latest n models, for example before the buffer
+// of an older one is reused
+func (h *modelHistory) Retain(n int) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retain(n)
+}
+
+func (h *modelHistory) retain(n int) {
+	for len(h.hashes) > n {
+		delete(h.models, h.hashes[0])
+		delete(h.deltas, h.hashes[0])
+		h.hashes = h.hashes[1:]
+	}
+}
+
+// Delta returns the diff from the model with hash base to the latest model,
+// which must have hash latest. It returns false when either model is not
+// the expected one or when a diff would not be smaller than the model.
+func (h *modelHistory) Delta(base, latest string) ([]byte, bool) {
+	if h == nil {
+		return nil, false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.hashes) == 0 || h.hashes[len(h.hashes)-1] != latest || !slices.Contains(h.hashes, base) {
+		return nil, false
+	}
+	model := h.models[latest]
+	if delta, ok := h.deltas[base]; ok {
+		return delta, delta != nil
+	}
+
+	delta, err := transport.EncodeDelta(h.models[base], model)
+	if err != nil || len(delta) >= len(model) {
+		if err != nil {
+			log.Printf("Failed to diff the global model against %.12s: %v", base, err)
+		}
+		delta = nil
+	}
+	h.deltas[base] = delta
+	return delta, delta != nil
+}
+
+// expandDelta replaces the diff of a delta update with the model it encodes.
+// Only diffs from base, the current model with hash baseHash, are accepted.
+func expandDelta(upd *pb.ModelUpdate, base []byte, baseHash string) error {
+	if upd.BaseModelHash == "" || upd.BaseModelHash != baseHash {
+		return fmt.Errorf("delta update is not based on the current model")
+	}
+	model, err := transport.ApplyDelta(base, upd.ModelWeights)
+	if err != nil {
+		return err
+	}
+	upd.ModelWeights = model
+	upd.Delta = false
+	return nil
+}
+
+// notModified answers a GetLatestModel request whose if_version names the
+// current model without sending the model again, or returns nil when the
+// collaborator's cached model is outdated
+func notModified(req *pb.GetModelRequest, modelHash string, round int32) *pb.GetModelResponse {
+	if req.IfVersion == "" || req.IfVersion != modelHash {
+		return nil
+	}
+	return &pb.GetModelResponse{CurrentRound: round, ModelHash: modelHash, NotModified: true}
+}
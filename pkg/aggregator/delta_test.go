@@ -0,0 +1,119 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func TestFedAvgAggregatorDeltaTransfer(t *testing.T) {
+	plan := &federation.FLPlan{
+		Aggregator:    federation.AggregatorEntry{DeltaTransfer: true},
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+	}
+	agg := NewFedAvgAggregator(plan)
+	base := encodeFloats(make([]float32, 1000)...)
+	baseHash := transport.ModelHash(base)
+	agg.modelSize = 1000
+	agg.currentRound = 1
+	agg.globalModel, agg.modelHash = base, baseHash
+	agg.history.Add(baseHash, base)
+
+	update := make([]float32, 1000)
+	update[7] = 0.5
+	delta, err := transport.EncodeDelta(base, encodeModel(update))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ack, _ := agg.SubmitUpdate(context.Background(), &pb.ModelUpdate{
+		CollaboratorId: "c1", ModelWeights: delta, Delta: true, Round: 1, BaseModelHash: baseHash,
+	})
+	if !ack.Success {
+		t.Fatalf("delta update rejected: %s", ack.Message)
+	}
+	if got := agg.updates[0].weights; got[7] != 0.5 {
+		t.Errorf("expanded update = %v, want 0.5 at index 7", got[:8])
+	}
+	ack, _ = agg.SubmitUpdate(context.Background(), &pb.ModelUpdate{
+		CollaboratorId: "c2", ModelWeights: delta, Delta: true, Round: 1, BaseModelHash: "other",
+	})
+	if ack.Success {
+		t.Error("delta update based on another model was accepted")
+	}
+
+	// The next global model is sent as a diff to collaborators that have the last one
+	next := encodeModel(update)
+	nextHash := transport.ModelHash(next)
+	agg.globalModel, agg.modelHash = next, nextHash
+	agg.history.Add(nextHash, next)
+
+	resp, err := agg.GetLatestModel(context.Background(), &pb.GetModelRequest{KnownModelHash: baseHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.BaseModelHash != baseHash || resp.ModelHash != nextHash || len(resp.ModelWeights) >= len(next) {
+		t.Fatalf("response = %d bytes based on %.12s, want a diff from %.12s", len(resp.ModelWeights), resp.BaseModelHash, baseHash)
+	}
+	if model, err := transport.ApplyDelta(base, resp.ModelWeights); err != nil || !bytes.Equal(model, next) {
+		t.Errorf("ApplyDelta() = %v, did not restore the global model", err)
+	}
+
+	resp, _ = agg.GetLatestModel(context.Background(), &pb.GetModelRequest{KnownModelHash: "unknown"})
+	if resp.BaseModelHash != "" || !bytes.Equal(resp.ModelWeights, next) {
+		t.Error("collaborator with an unknown model was not sent the full model")
+	}
+}
+
+func TestGetLatestModelNotModified(t *testing.T) {
+	plan := &federation.FLPlan{Mode: federation.ModeAsync}
+	async := NewAsyncFedAvgAggregator(plan)
+	async.modelSize = 2
+	async.globalModel = []float32{1, 2}
+	async.currentRound = 3
+
+	resp, err := async.GetLatestModel(context.Background(), &pb.GetModelRequest{})
+	if err != nil || resp.NotModified || resp.ModelHash != transport.ModelHash(encodeModel([]float32{1, 2})) {
+		t.Fatalf("GetLatestModel() = %+v, %v", resp, err)
+	}
+	version := resp.ModelHash
+	resp, _ = async.GetLatestModel(context.Background(), &pb.GetModelRequest{IfVersion: version})
+	if !resp.NotModified || resp.ModelWeights != nil || resp.CurrentRound != 3 {
+		t.Errorf("cached current model was sent again: %+v", resp)
+	}
+
+	async.globalModel = []float32{3, 4}
+	resp, _ = async.GetLatestModel(context.Background(), &pb.GetModelRequest{IfVersion: version})
+	if resp.NotModified || !bytes.Equal(resp.ModelWeights, encodeModel([]float32{3, 4})) {
+		t.Errorf("outdated cached model was not replaced: %+v", resp)
+	}
+
+	fedavg := NewFedAvgAggregator(&federation.FLPlan{})
+	fedavg.globalModel, fedavg.modelHash = encodeModel([]float32{1}), "hash"
+	if resp, _ := fedavg.GetLatestModel(context.Background(), &pb.GetModelRequest{IfVersion: "hash"}); !resp.NotModified {
+		t.Errorf("sync aggregator sent the cached model again: %+v", resp)
+	}
+}
+
+func TestModelHistoryKeepsRecentModels(t *testing.T) {
+	h := newModelHistory(&federation.FLPlan{Aggregator: federation.AggregatorEntry{DeltaTransfer: true}})
+	for i, hash := range []string{"a", "b", "c"} {
+		h.Add(hash, encodeFloats(make([]float32, 100+i)...)[:400])
+	}
+	if _, ok := h.Delta("a", "c"); ok {
+		t.Error("diff from a model older than the history")
+	}
+	if _, ok := h.Delta("b", "c"); !ok {
+		t.Error("no diff from the previous model")
+	}
+	if _, ok := h.Delta("b", "b"); ok {
+		t.Error("diff to a model that is no longer the latest")
+	}
+	h.Retain(1)
+	if _, ok := h.Delta("b", "c"); ok {
+		t.Error("diff from a model that was forgotten")
+	}
+}
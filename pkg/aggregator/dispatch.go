@@ -0,0 +1,200 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// dispatchDrainTimeout bounds how long a finished aggregator keeps serving so
+// that collaborators can pick up their quit task and evaluate the final model
+const dispatchDrainTimeout = 2 * time.Minute
+
+// evaluatorCount returns the number of plan collaborators with role evaluator
+func evaluatorCount(plan *federation.FLPlan) int {
+	evaluators := 0
+	for _, collab := range plan.Collaborators {
+		if collab.Role == federation.CollaboratorEvaluator {
+			evaluators++
+		}
+	}
+	return evaluators
+}
+
+// expectedUpdates is the number of updates that complete a round. Evaluators
+// do not train and are not waited for.
+func (a *FedAvgAggregator) expectedUpdates() int {
+	return len(a.plan.Collaborators) - evaluatorCount(a.plan)
+}
+
+// polling is the number of collaborators that poll for tasks: every
+// collaborator with task dispatch, otherwise only the evaluators
+func (a *FedAvgAggregator) polling() int {
+	if a.plan.Dispatch.Enabled {
+		return len(a.plan.Collaborators)
+	}
+	return evaluatorCount(a.plan)
+}
+
+// taskRole returns the role of a collaborator that may poll for tasks.
+// Without task dispatch, only evaluators do.
+func (a *FedAvgAggregator) taskRole(collaboratorID string) (federation.CollaboratorRole, error) {
+	role, ok := federation.RoleOf(a.plan, collaboratorID)
+	if !ok {
+		return "", fmt.Errorf("collaborator %s is not in the plan", collaboratorID)
+	}
+	if !a.plan.Dispatch.Enabled && role != federation.CollaboratorEvaluator {
+		return "", fmt.Errorf("task dispatch is not enabled in the plan")
+	}
+	return role, nil
+}
+
+// GetTask assigns the caller's next task: training in the current round,
+// evaluation of the latest aggregated model for evaluators, or quitting once
+// the federation is over
+func (a *FedAvgAggregator) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return nil, err
+	}
+	role, err := a.taskRole(req.CollaboratorId)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case role == federation.CollaboratorEvaluator && a.evaluated[req.CollaboratorId] < a.aggregated:
+		a.evaluated[req.CollaboratorId] = a.aggregated
+		log.Printf("Assigning evaluation of round %d to %s", a.aggregated, req.CollaboratorId)
+		return &pb.Task{
+			Type:           pb.TaskType_TASK_EVALUATE,
+			Round:          clampInt32(a.aggregated),
+			ModelWeights:   a.globalModel,
+			ModelSignature: a.signer.Sign(a.modelHash),
+		}, nil
+	case a.finished:
+		a.released[req.CollaboratorId] = true
+		return &pb.Task{Type: pb.TaskType_TASK_QUIT}, nil
+	case role != federation.CollaboratorEvaluator && a.currentRound > 0 && !a.submitted[req.CollaboratorId]:
+		log.Printf("Assigning training in round %d to %s", a.currentRound, req.CollaboratorId)
+		// The collaborator's own task args win over the shared ones
+		overrides := federation.TaskArgsOf(a.plan, req.CollaboratorId).Train
+		return &pb.Task{
+			Type:            pb.TaskType_TASK_TRAIN,
+			Round:           clampInt32(a.currentRound),
+			ModelWeights:    a.globalModel,
+			Hyperparameters: taskHyperparameters(federation.MergeArgs(a.hyperparameters, overrides)),
+			ModelSignature:  a.signer.Sign(a.modelHash),
+		}, nil
+	}
+
+	pollInterval := a.plan.Dispatch.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = federation.DefaultPollInterval
+	}
+	return &pb.Task{Type: pb.TaskType_TASK_SLEEP, SleepSeconds: clampInt32(pollInterval)}, nil
+}
+
+// SubmitTaskResult accepts the outcome of a dispatched task. Train results of
+// an earlier round are dropped as stale.
+func (a *FedAvgAggregator) SubmitTaskResult(ctx context.Context, result *pb.TaskResult) (*pb.Ack, error) {
+	if err := a.attestation.authorize(result.CollaboratorId); err != nil {
+		return nil, err
+	}
+	if _, err := a.taskRole(result.CollaboratorId); err != nil {
+		return nil, err
+	}
+
+	switch result.Type {
+	case pb.TaskType_TASK_TRAIN:
+		return a.accept(&pb.ModelUpdate{
+			CollaboratorId: result.CollaboratorId,
+			ModelWeights:   result.ModelWeights,
+			NumSamples:     result.NumSamples,
+			ImageDigest:    result.ImageDigest,
+			Privacy:        result.Privacy,
+			Round:          result.Round,
+			BaseModelHash:  result.BaseModelHash,
+			Metrics:        result.Metrics,
+			LocalSteps:     result.LocalSteps,
+		}), nil
+	case pb.TaskType_TASK_EVALUATE:
+		log.Printf("Evaluation of round %d by %s: %s", result.Round, result.CollaboratorId, formatMetrics(result.Metrics))
+		a.run.RecordEvaluation(result.CollaboratorId, int(result.Round), result.Metrics)
+		a.reporter.RecordEvaluation(result.CollaboratorId, int(result.Round), result.Metrics)
+		return &pb.Ack{Success: true}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s task result", result.Type)
+	}
+}
+
+// drainDispatch keeps the aggregator serving after the last round until every
+// polling collaborator was told to quit, so that evaluators report on the
+// final model
+func (a *FedAvgAggregator) drainDispatch(ctx context.Context) {
+	a.mu.Lock()
+	a.finished = true
+	a.mu.Unlock()
+
+	polling := a.polling()
+	deadline := time.After(dispatchDrainTimeout)
+	for {
+		a.mu.Lock()
+		released := len(a.released)
+		a.mu.Unlock()
+		if released >= polling {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			log.Printf("Stopping with %d/%d collaborators still running dispatched tasks", polling-released, polling)
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// taskHyperparameters converts task args to the string map sent with tasks
+func taskHyperparameters(args map[string]interface{}) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+	hyperparameters := make(map[string]string, len(args))
+	for key, value := range args {
+		if f, ok := value.(float64); ok {
+			// Scheduled values would otherwise carry float rounding noise
+			hyperparameters[key] = strconv.FormatFloat(f, 'g', 10, 64)
+			continue
+		}
+		hyperparameters[key] = fmt.Sprint(value)
+	}
+	return hyperparameters
+}
+
+// formatMetrics renders metrics as name=value pairs in name order
+func formatMetrics(metrics map[string]float64) string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	formatted := ""
+	for i, name := range names {
+		if i > 0 {
+			formatted += " "
+		}
+		formatted += fmt.Sprintf("%s=%g", name, metrics[name])
+	}
+	return formatted
+}
@@ -0,0 +1,156 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestDispatchTasks(t *testing.T) {
+	plan := &federation.FLPlan{
+		Rounds: 2,
+		Collaborators: []federation.Collaborator{
+			{ID: "trainer", TaskArgs: federation.TaskArgsConfig{Train: map[string]interface{}{"batch_size": 16}}},
+			{ID: "evaluator", Role: federation.CollaboratorEvaluator},
+		},
+		Dispatch: federation.DispatchConfig{Enabled: true, PollInterval: 3},
+		Tasks: federation.TasksConfig{
+			Train: federation.TaskConfig{Args: map[string]interface{}{"lr": 0.01}},
+		},
+	}
+	a := NewFedAvgAggregator(plan)
+	if got := a.expectedUpdates(); got != 1 {
+		t.Fatalf("expectedUpdates() = %d, want 1", got)
+	}
+
+	getTask := func(id string) *pb.Task {
+		t.Helper()
+		task, err := a.GetTask(context.Background(), &pb.GetTaskRequest{CollaboratorId: id})
+		if err != nil {
+			t.Fatalf("GetTask(%s) error = %v", id, err)
+		}
+		return task
+	}
+
+	// Nothing to do before the first round starts
+	if task := getTask("trainer"); task.Type != pb.TaskType_TASK_SLEEP || task.SleepSeconds != 3 {
+		t.Errorf("task before round 1 = %v, want sleep for 3s", task)
+	}
+	if _, err := a.GetTask(context.Background(), &pb.GetTaskRequest{CollaboratorId: "stranger"}); err == nil {
+		t.Error("GetTask() for a collaborator outside the plan succeeded")
+	}
+
+	model := encodeFloats(1, 2)
+	a.modelSize = 2
+	a.globalModel = model
+	a.currentRound = 1
+	a.hyperparameters = plan.Tasks.Train.Args
+
+	task := getTask("trainer")
+	if task.Type != pb.TaskType_TASK_TRAIN || task.Round != 1 || task.Hyperparameters["lr"] != "0.01" {
+		t.Fatalf("trainer task = %v, want train in round 1 with lr 0.01", task)
+	}
+	if task.Hyperparameters["batch_size"] != "16" {
+		t.Errorf("trainer hyperparameters = %v, want its own batch_size", task.Hyperparameters)
+	}
+	if task := getTask("evaluator"); task.Type != pb.TaskType_TASK_SLEEP {
+		t.Errorf("evaluator task before aggregation = %v, want sleep", task.Type)
+	}
+
+	// A result for another round is stale
+	stale := &pb.TaskResult{CollaboratorId: "trainer", Type: pb.TaskType_TASK_TRAIN, Round: 2, ModelWeights: model}
+	if ack, _ := a.SubmitTaskResult(context.Background(), stale); ack.Success {
+		t.Error("stale train result accepted")
+	}
+	result := &pb.TaskResult{CollaboratorId: "trainer", Type: pb.TaskType_TASK_TRAIN, Round: 1, ModelWeights: model}
+	if ack, _ := a.SubmitTaskResult(context.Background(), result); !ack.Success {
+		t.Fatal("train result rejected")
+	}
+	if task := getTask("trainer"); task.Type != pb.TaskType_TASK_SLEEP {
+		t.Errorf("trainer task after submitting = %v, want sleep", task.Type)
+	}
+
+	// Once the round is aggregated the evaluator evaluates it, once
+	a.aggregated = 1
+	if task := getTask("evaluator"); task.Type != pb.TaskType_TASK_EVALUATE || task.Round != 1 {
+		t.Errorf("evaluator task = %v, want evaluate round 1", task)
+	}
+	if task := getTask("evaluator"); task.Type != pb.TaskType_TASK_SLEEP {
+		t.Errorf("evaluator task after evaluating = %v, want sleep", task.Type)
+	}
+	evaluation := &pb.TaskResult{CollaboratorId: "evaluator", Type: pb.TaskType_TASK_EVALUATE, Round: 1,
+		Metrics: map[string]float64{"accuracy": 0.9}}
+	if ack, _ := a.SubmitTaskResult(context.Background(), evaluation); !ack.Success {
+		t.Error("evaluate result rejected")
+	}
+	if evaluations := a.Report().Evaluations; len(evaluations) != 1 || evaluations[0].Metrics["accuracy"] != 0.9 {
+		t.Errorf("report evaluations = %v", evaluations)
+	}
+
+	a.finished = true
+	for _, id := range []string{"trainer", "evaluator"} {
+		if task := getTask(id); task.Type != pb.TaskType_TASK_QUIT {
+			t.Errorf("%s task after the last round = %v, want quit", id, task.Type)
+		}
+	}
+	if len(a.released) != 2 {
+		t.Errorf("released = %v, want both collaborators", a.released)
+	}
+}
+
+func TestEvaluatorsWithoutDispatch(t *testing.T) {
+	plan := &federation.FLPlan{
+		Rounds: 1,
+		Collaborators: []federation.Collaborator{
+			{ID: "trainer"},
+			{ID: "holdout", Role: federation.CollaboratorEvaluator},
+		},
+	}
+	a := NewFedAvgAggregator(plan)
+	if got, polling := a.expectedUpdates(), a.polling(); got != 1 || polling != 1 {
+		t.Fatalf("expectedUpdates() = %d, polling() = %d; want 1, 1", got, polling)
+	}
+
+	// Trainers keep pulling models; only evaluators get tasks
+	if _, err := a.GetTask(context.Background(), &pb.GetTaskRequest{CollaboratorId: "trainer"}); err == nil {
+		t.Error("GetTask() for a trainer without dispatch succeeded")
+	}
+
+	model := encodeFloats(1, 2)
+	a.modelSize = 2
+	a.globalModel = model
+	a.currentRound = 1
+	if ack := a.accept(&pb.ModelUpdate{CollaboratorId: "holdout", Round: 1, ModelWeights: model}); ack.Success {
+		t.Error("update of an evaluator accepted")
+	}
+
+	a.aggregated = 1
+	task, err := a.GetTask(context.Background(), &pb.GetTaskRequest{CollaboratorId: "holdout"})
+	if err != nil || task.Type != pb.TaskType_TASK_EVALUATE || task.Round != 1 {
+		t.Fatalf("evaluator task = %v, %v; want evaluate round 1", task, err)
+	}
+	evaluation := &pb.TaskResult{CollaboratorId: "holdout", Type: pb.TaskType_TASK_EVALUATE, Round: 1,
+		Metrics: map[string]float64{"auc": 0.8}}
+	if ack, err := a.SubmitTaskResult(context.Background(), evaluation); err != nil || !ack.Success {
+		t.Errorf("evaluate result rejected: %v, %v", ack, err)
+	}
+	train := &pb.TaskResult{CollaboratorId: "trainer", Type: pb.TaskType_TASK_TRAIN, Round: 1, ModelWeights: model}
+	if _, err := a.SubmitTaskResult(context.Background(), train); err == nil {
+		t.Error("train result without dispatch accepted")
+	}
+
+	// The finished aggregator only waits for the evaluator to quit
+	a.finished = true
+	if task, _ := a.GetTask(context.Background(), &pb.GetTaskRequest{CollaboratorId: "holdout"}); task.Type != pb.TaskType_TASK_QUIT {
+		t.Errorf("evaluator task after the last round = %v, want quit", task.Type)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.drainDispatch(ctx)
+	if ctx.Err() != nil {
+		t.Error("drainDispatch() waited for the trainer")
+	}
+}
@@ -0,0 +1,180 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+)
+
+// DefaultDistillDir is where FedDF writes the models the distillation task
+// reads and the model it writes
+const DefaultDistillDir = "models/distill"
+
+// validDistillArgument matches the distillation task's script and args
+var validDistillArgument = regexp.MustCompile(`^[a-zA-Z0-9._/\-=]+$`)
+
+// distillTeacher is one client model in the distillation task's teachers file
+type distillTeacher struct {
+	Path           string `json:"path"`
+	CollaboratorID string `json:"collaborator_id"`
+	NumSamples     int    `json:"num_samples"`
+}
+
+// =============================================================================
+// FedDF Algorithm (Ensemble Distillation)
+// Reference: "Ensemble Distillation for Robust Model Fusion in Federated
+// Learning" (Lin et al., 2020)
+// =============================================================================
+
+type FedDFAlgorithm struct {
+	name      string
+	modelSize int
+	task      federation.TaskConfig
+	dir       string
+	average   FedAvgAlgorithm
+}
+
+func (f *FedDFAlgorithm) Initialize(config AlgorithmConfig) error {
+	f.name = "FedDF"
+	f.modelSize = config.ModelSize
+	f.task = config.DistillTask
+	f.dir = DefaultDistillDir
+	f.average.Initialize(config)
+
+	switch f.task.Runner {
+	case "", federation.RunnerPython, federation.RunnerExec:
+	default:
+		return fmt.Errorf("tasks.distill supports the python and exec runners, got %s", f.task.Runner)
+	}
+	if f.task.Script == "" {
+		return fmt.Errorf("the feddf algorithm requires tasks.distill.script")
+	}
+	if !validDistillArgument.MatchString(f.task.Script) {
+		return fmt.Errorf("invalid tasks.distill.script %q", f.task.Script)
+	}
+	return nil
+}
+
+func (f *FedDFAlgorithm) GetName() string {
+	return f.name
+}
+
+func (f *FedDFAlgorithm) GetHyperparameters() map[string]interface{} {
+	return map[string]interface{}{
+		"algorithm":   "feddf",
+		"script":      f.task.Script,
+		"args":        f.task.Args,
+		"description": "Ensemble Distillation on server-side unlabeled data",
+	}
+}
+
+func (f *FedDFAlgorithm) UpdateHyperparameters(params map[string]interface{}) error {
+	// The distillation task is configured in tasks.distill
+	return nil
+}
+
+// Aggregate averages the client models like FedAvg and hands the average to
+// the distillation task as the student, along with the client models as
+// teachers. The task distills the teachers' ensemble into the student on the
+// server's unlabeled data and writes the new global model.
+func (f *FedDFAlgorithm) Aggregate(updates []ClientUpdate, globalModel []float32) ([]float32, error) {
+	student, err := f.average.Aggregate(updates, globalModel)
+	if err != nil {
+		return globalModel, err
+	}
+
+	teachersDir := filepath.Join(f.dir, "teachers")
+	if err := os.RemoveAll(teachersDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(teachersDir, 0750); err != nil {
+		return nil, err
+	}
+	teachers := make([]distillTeacher, len(updates))
+	for i, update := range updates {
+		teachers[i] = distillTeacher{
+			Path:           filepath.Join(teachersDir, fmt.Sprintf("teacher_%d.pt", i)),
+			CollaboratorID: update.CollaboratorID,
+			NumSamples:     update.NumSamples,
+		}
+		if err := os.WriteFile(teachers[i].Path, encodeModel(update.Weights), 0600); err != nil {
+			return nil, err
+		}
+	}
+	manifest, err := json.MarshalIndent(teachers, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := distillFiles{
+		ModelIn:  filepath.Join(f.dir, "student.pt"),
+		ModelOut: filepath.Join(f.dir, "distilled.pt"),
+		Teachers: filepath.Join(f.dir, "teachers.json"),
+	}
+	if err := os.WriteFile(files.Teachers, manifest, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(files.ModelIn, encodeModel(student), 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(files.ModelOut); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := f.run(files); err != nil {
+		return nil, fmt.Errorf("distillation task failed: %v", err)
+	}
+	data, err := os.ReadFile(files.ModelOut)
+	if err != nil {
+		return nil, fmt.Errorf("distillation task wrote no model: %v", err)
+	}
+	distilled, _, err := decodeUpdate(data, f.modelSize)
+	if err != nil {
+		return nil, fmt.Errorf("distillation task wrote an invalid model: %v", err)
+	}
+	return distilled, nil
+}
+
+// distillFiles are the paths the distillation task reads from and writes to
+type distillFiles struct {
+	ModelIn  string // FedAvg average of the client models
+	ModelOut string // distilled global model
+	Teachers string // JSON list of the client models
+}
+
+// run runs the distillation task with the files and its args as
+// --kebab-case flags
+func (f *FedDFAlgorithm) run(files distillFiles) error {
+	args := []string{"--model-in", files.ModelIn, "--model-out", files.ModelOut, "--teachers", files.Teachers}
+	keys := make([]string, 0, len(f.task.Args))
+	for k := range f.task.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := fmt.Sprint(f.task.Args[k])
+		if !validDistillArgument.MatchString(k) || !validDistillArgument.MatchString(v) {
+			return fmt.Errorf("invalid argument detected: key=%s, value=%v", k, v)
+		}
+		args = append(args, "--"+strings.ReplaceAll(k, "_", "-"), v)
+	}
+
+	command := f.task.Script
+	if f.task.Runner != federation.RunnerExec {
+		command, args = platform.Python(f.task.Python), append([]string{f.task.Script}, args...)
+	}
+	log.Printf("Running distillation task: %s %v", command, args)
+	cmd := exec.Command(command, args...) // #nosec G204 - Arguments validated with whitelist above
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
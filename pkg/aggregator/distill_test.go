@@ -0,0 +1,102 @@
+package aggregator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// writeDistillScript writes an exec task that runs body with the flags of the
+// distillation task in $model_in, $model_out and $teachers
+func writeDistillScript(t *testing.T, body string) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "distill.sh")
+	content := "#!/bin/sh\nwhile [ $# -gt 1 ]; do\n  case $1 in\n    --model-in) model_in=$2 ;;\n    --model-out) model_out=$2 ;;\n    --teachers) teachers=$2 ;;\n  esac\n  shift 2\ndone\n" + body + "\n"
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestFedDFRunsDistillationTask(t *testing.T) {
+	// The task distills into the second teacher, so that its model is
+	// distinguishable from the FedAvg student
+	script := writeDistillScript(t, `cp "$(dirname "$teachers")/teachers/teacher_1.pt" "$model_out"`)
+	algorithm := &FedDFAlgorithm{}
+	task := federation.TaskConfig{Runner: federation.RunnerExec, Script: script}
+	if err := algorithm.Initialize(AlgorithmConfig{ModelSize: 2, DistillTask: task}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	algorithm.dir = t.TempDir()
+
+	updates := []ClientUpdate{
+		{CollaboratorID: "a", Weights: []float32{1, 1}, NumSamples: 1},
+		{CollaboratorID: "b", Weights: []float32{3, 5}, NumSamples: 1},
+	}
+	model, err := algorithm.Aggregate(updates, []float32{0, 0})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if !slices.Equal(model, []float32{3, 5}) {
+		t.Errorf("model = %v, want the distilled [3 5]", model)
+	}
+
+	student, err := os.ReadFile(filepath.Join(algorithm.dir, "student.pt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if average, _, _ := decodeUpdate(student, 2); !slices.Equal(average, []float32{2, 3}) {
+		t.Errorf("student = %v, want the FedAvg average [2 3]", average)
+	}
+}
+
+func TestFedDFFailedTask(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"task fails", "exit 1"},
+		{"no model", "true"},
+		{"wrong size", `printf 'abcd' > "$model_out"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := &FedDFAlgorithm{}
+			task := federation.TaskConfig{Runner: federation.RunnerExec, Script: writeDistillScript(t, tt.body)}
+			if err := algorithm.Initialize(AlgorithmConfig{ModelSize: 2, DistillTask: task}); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			algorithm.dir = t.TempDir()
+			updates := []ClientUpdate{{CollaboratorID: "a", Weights: []float32{1, 1}, NumSamples: 1}}
+			if _, err := algorithm.Aggregate(updates, []float32{0, 0}); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestFedDFRequiresDistillTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    federation.TaskConfig
+		wantErr bool
+	}{
+		{"python script", federation.TaskConfig{Script: "src/distill.py"}, false},
+		{"no script", federation.TaskConfig{}, true},
+		{"docker runner", federation.TaskConfig{Runner: federation.RunnerDocker, Image: "distill:latest"}, true},
+		{"invalid script", federation.TaskConfig{Script: "distill.py; rm -rf /"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&FedDFAlgorithm{}).Initialize(AlgorithmConfig{ModelSize: 1, DistillTask: tt.task})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Initialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// dropKey identifies a dropped-update counter
+type dropKey struct {
+	collaboratorID string
+	reason         monitoring.DropReason
+}
+
+// DropTracker counts model updates rejected by an aggregator, per collaborator
+// and reason, and optionally forwards each drop to the monitoring server.
+type DropTracker struct {
+	mu           sync.Mutex
+	federationID string
+	counts       map[dropKey]int
+	reportURL    string
+	apiKey       string
+	client       *http.Client
+}
+
+// NewDropTracker creates a drop tracker for the federation described by plan
+func NewDropTracker(plan *federation.FLPlan) *DropTracker {
+	tracker := &DropTracker{
+		federationID: federationIDFor(plan),
+		counts:       make(map[dropKey]int),
+		apiKey:       plan.Monitoring.APIKey,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if plan.Monitoring.Enabled && plan.Monitoring.MonitoringServerURL != "" {
+		tracker.reportURL = strings.TrimRight(plan.Monitoring.MonitoringServerURL, "/") + "/api/v1/updates/dropped"
+	}
+
+	return tracker
+}
+
+// federationIDFor returns the federation ID used when reporting to monitoring
+func federationIDFor(plan *federation.FLPlan) string {
+	if plan.Monitoring.FederationID != "" {
+		return plan.Monitoring.FederationID
+	}
+	return "default"
+}
+
+// Reject records a dropped update and returns the Ack that tells the
+// collaborator why it was dropped
+func (d *DropTracker) Reject(collaboratorID string, round int, reason monitoring.DropReason, detail string) *pb.Ack {
+	d.Record(collaboratorID, round, reason, detail)
+	return &pb.Ack{Success: false, Message: detail, Status: ackStatus(reason)}
+}
+
+// ackStatus tells collaborators how to react to an update dropped for reason
+func ackStatus(reason monitoring.DropReason) pb.AckStatus {
+	switch reason {
+	case monitoring.DropReasonStale:
+		return pb.AckStatus_ACK_REJECTED_STALE
+	case monitoring.DropReasonRoundMismatch:
+		return pb.AckStatus_ACK_REJECTED_ROUND_MISMATCH
+	case monitoring.DropReasonDuplicate:
+		return pb.AckStatus_ACK_REJECTED_DUPLICATE
+	default:
+		return pb.AckStatus_ACK_REJECTED_INVALID
+	}
+}
+
+// Record counts a dropped update and reports it to the monitoring server if configured
+func (d *DropTracker) Record(collaboratorID string, round int, reason monitoring.DropReason, detail string) {
+	d.mu.Lock()
+	d.counts[dropKey{collaboratorID: collaboratorID, reason: reason}]++
+	d.mu.Unlock()
+
+	log.Printf("Dropped update from %s in round %d (%s): %s", collaboratorID, round, reason, detail)
+
+	if d.reportURL == "" {
+		return
+	}
+
+	dropped := monitoring.DroppedUpdate{
+		FederationID:   d.federationID,
+		CollaboratorID: collaboratorID,
+		RoundNumber:    round,
+		Reason:         reason,
+		Detail:         detail,
+		Timestamp:      time.Now(),
+	}
+	go d.report(dropped)
+}
+
+func (d *DropTracker) report(dropped monitoring.DroppedUpdate) {
+	body, err := json.Marshal(dropped)
+	if err != nil {
+		return
+	}
+
+	if err := postMonitoringJSON(d.client, d.reportURL, d.apiKey, body); err != nil {
+		log.Printf("Failed to report dropped update to monitoring: %v", err)
+	}
+}
+
+// Count returns how many updates were dropped for a collaborator and reason
+func (d *DropTracker) Count(collaboratorID string, reason monitoring.DropReason) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.counts[dropKey{collaboratorID: collaboratorID, reason: reason}]
+}
+
+// CollaboratorTotal returns how many updates were dropped for a collaborator across all reasons
+func (d *DropTracker) CollaboratorTotal(collaboratorID string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	total := 0
+	for key, count := range d.counts {
+		if key.collaboratorID == collaboratorID {
+			total += count
+		}
+	}
+	return total
+}
+
+// Total returns the total number of dropped updates
+func (d *DropTracker) Total() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	total := 0
+	for _, count := range d.counts {
+		total += count
+	}
+	return total
+}
+
+// ServeHTTP exposes the drop counters in the Prometheus text format
+func (d *DropTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	samples := make([]monitoring.PrometheusSample, 0, len(d.counts))
+	for key, count := range d.counts {
+		samples = append(samples, monitoring.PrometheusSample{
+			Labels: map[string]string{
+				"federation_id":   d.federationID,
+				"collaborator_id": key.collaboratorID,
+				"reason":          string(key.reason),
+			},
+			Value: float64(count),
+		})
+	}
+	d.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i].Labels, samples[j].Labels
+		if a["collaborator_id"] == b["collaborator_id"] {
+			return a["reason"] < b["reason"]
+		}
+		return a["collaborator_id"] < b["collaborator_id"]
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	monitoring.WritePrometheusMetric(w, "flgo_aggregator_dropped_updates_total",
+		"Model updates rejected by this aggregator", "counter", samples)
+}
+
+// startMetricsServer serves the Prometheus endpoint when the plan configures one.
+// The endpoint is plain HTTP, so it serves nothing that needs the admin token;
+// the async settings are tuned through the AdminService.
+func startMetricsServer(ctx context.Context, plan *federation.FLPlan, handler http.Handler) {
+	addr := plan.Monitoring.MetricsAddress
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Aggregator metrics available at http://%s/metrics", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), serverDrainTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdown); err != nil {
+			log.Printf("Metrics server shutdown error: %v", err)
+		}
+	}()
+}
+
+// decodeUpdate validates a serialized update and converts it to float32 weights.
+// A modelSize of zero skips the size check.
+func decodeUpdate(data []byte, modelSize int) ([]float32, monitoring.DropReason, error) {
+	if reason, err := validateUpdate(data, modelSize); err != nil {
+		return nil, reason, err
+	}
+
+	floats := make([]float32, len(data)/4)
+	parallelFor(len(floats), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+	})
+	return floats, "", nil
+}
+
+// validateUpdate checks an encoded update like decodeUpdate does, without
+// decoding it into a new slice
+func validateUpdate(data []byte, modelSize int) (monitoring.DropReason, error) {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return monitoring.DropReasonValidationFailed,
+			fmt.Errorf("update payload of %d bytes is not a float32 array", len(data))
+	}
+	if modelSize > 0 && len(data)/4 != modelSize {
+		return monitoring.DropReasonSizeMismatch,
+			fmt.Errorf("update has %d parameters, expected %d", len(data)/4, modelSize)
+	}
+
+	n := len(data) / 4
+	var mu sync.Mutex
+	bad := n
+	parallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			v := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				mu.Lock()
+				bad = min(bad, i)
+				mu.Unlock()
+				return
+			}
+		}
+	})
+	if bad < n {
+		return monitoring.DropReasonValidationFailed,
+			fmt.Errorf("update contains non-finite value at index %d", bad)
+	}
+	return "", nil
+}
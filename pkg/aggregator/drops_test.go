@@ -0,0 +1,164 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func encodeFloats(values ...float32) []byte {
+	buf := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func TestDecodeUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		modelSize int
+		reason    monitoring.DropReason
+	}{
+		{"valid", encodeFloats(1, 2, 3), 3, ""},
+		{"unknown model size", encodeFloats(1, 2), 0, ""},
+		{"empty", nil, 3, monitoring.DropReasonValidationFailed},
+		{"truncated", []byte{1, 2, 3}, 0, monitoring.DropReasonValidationFailed},
+		{"size mismatch", encodeFloats(1, 2), 3, monitoring.DropReasonSizeMismatch},
+		{"nan", encodeFloats(1, float32(math.NaN())), 2, monitoring.DropReasonValidationFailed},
+		{"inf", encodeFloats(float32(math.Inf(1))), 1, monitoring.DropReasonValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, reason, err := decodeUpdate(tt.data, tt.modelSize)
+			if reason != tt.reason {
+				t.Errorf("decodeUpdate() reason = %q, want %q", reason, tt.reason)
+			}
+			if (err != nil) != (tt.reason != "") {
+				t.Errorf("decodeUpdate() error = %v", err)
+			}
+		})
+	}
+}
+
+func FuzzDecodeUpdate(f *testing.F) {
+	f.Add(encodeFloats(1, 2, 3), 3)
+	f.Add(encodeFloats(1, 2), 0)
+	f.Add([]byte{1, 2, 3}, 0)
+	f.Add(encodeFloats(1, float32(math.NaN())), 2)
+	f.Fuzz(func(t *testing.T, data []byte, modelSize int) {
+		floats, reason, err := decodeUpdate(data, modelSize)
+		if want := transport.CheckWeights(data, modelSize); (want == nil) != (err == nil) {
+			t.Fatalf("decodeUpdate() error = %v, transport.CheckWeights() = %v", err, want)
+		}
+		if err != nil {
+			if reason == "" {
+				t.Fatalf("decodeUpdate() rejected the update without a reason: %v", err)
+			}
+			return
+		}
+		if want, _ := transport.DecodeWeights(data, modelSize); !slices.Equal(floats, want) {
+			t.Fatalf("decodeUpdate() = %v, want %v", floats, want)
+		}
+	})
+}
+
+// FuzzFedAvgAggregatorSubmitUpdate submits arbitrary payloads, in full or as
+// a diff against the global model, and checks that the aggregator accepts
+// exactly the ones that hold a finite model of its size
+func FuzzFedAvgAggregatorSubmitUpdate(f *testing.F) {
+	global := encodeFloats(1, 2)
+	delta, err := transport.EncodeDelta(global, encodeFloats(3, 4))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(encodeFloats(1, 2), false)
+	f.Add(encodeFloats(1), false)
+	f.Add([]byte{1, 2, 3, 4, 5}, false)
+	f.Add(encodeFloats(float32(math.Inf(1)), 2), false)
+	f.Add(delta, true)
+	f.Add(delta[:len(delta)/2], true)
+	f.Fuzz(func(t *testing.T, weights []byte, isDelta bool) {
+		plan := &federation.FLPlan{Collaborators: []federation.Collaborator{{ID: "c1"}}}
+		agg := NewFedAvgAggregator(plan)
+		agg.modelSize = 2
+		agg.currentRound = 1
+		agg.globalModel = global
+		agg.modelHash = transport.ModelHash(global)
+
+		model := weights
+		var err error
+		if isDelta {
+			model, err = transport.ApplyDelta(global, weights)
+		}
+		valid := err == nil && transport.CheckWeights(model, agg.modelSize) == nil
+
+		upd := &pb.ModelUpdate{CollaboratorId: "c1", ModelWeights: slices.Clone(weights), Delta: isDelta}
+		if isDelta {
+			upd.BaseModelHash = agg.modelHash
+		}
+		ack, err := agg.SubmitUpdate(context.Background(), upd)
+		if err != nil {
+			t.Fatalf("SubmitUpdate() error = %v", err)
+		}
+		if ack.Success != valid {
+			t.Fatalf("SubmitUpdate() success = %v (%s), want %v", ack.Success, ack.Message, valid)
+		}
+		if valid {
+			if want, _ := transport.DecodeWeights(model, agg.modelSize); !slices.Equal(agg.updates[0].weights, want) {
+				t.Fatalf("aggregator holds the update %v, want %v", agg.updates[0].weights, want)
+			}
+		}
+	})
+}
+
+func TestFedAvgAggregatorDropsUpdates(t *testing.T) {
+	plan := &federation.FLPlan{Collaborators: []federation.Collaborator{{ID: "c1"}}}
+	agg := NewFedAvgAggregator(plan)
+	agg.modelSize = 2
+	agg.currentRound = 1
+
+	submit := func(weights []byte) bool {
+		ack, err := agg.SubmitUpdate(context.Background(), &pb.ModelUpdate{CollaboratorId: "c1", ModelWeights: weights})
+		if err != nil {
+			t.Fatalf("SubmitUpdate() error = %v", err)
+		}
+		return ack.Success
+	}
+
+	if !submit(encodeFloats(1, 2)) {
+		t.Error("expected first update to be accepted")
+	}
+	if submit(encodeFloats(1, 2)) {
+		t.Error("expected duplicate update to be rejected")
+	}
+	if submit(encodeFloats(1)) {
+		t.Error("expected undersized update to be rejected")
+	}
+
+	if got := agg.drops.Count("c1", monitoring.DropReasonDuplicate); got != 1 {
+		t.Errorf("duplicate drops = %d, want 1", got)
+	}
+	if got := agg.drops.Count("c1", monitoring.DropReasonSizeMismatch); got != 1 {
+		t.Errorf("size mismatch drops = %d, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	agg.drops.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	want := `flgo_aggregator_dropped_updates_total{collaborator_id="c1",federation_id="default",reason="duplicate"} 1`
+	if !strings.Contains(body, want) {
+		t.Errorf("metrics output missing %q:\n%s", want, body)
+	}
+}
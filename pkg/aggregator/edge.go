@@ -0,0 +1,460 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// weightedUpdate is a decoded update together with the number of samples it
+// was trained on
+type weightedUpdate struct {
+	weights        []float32
+	numSamples     int64
+	collaboratorID string // sender, which fixes the summation order
+}
+
+// sampleCount returns the aggregation weight of an update. Updates that do not
+// report a sample count weigh as a single sample.
+func sampleCount(numSamples int64) int64 {
+	if numSamples <= 0 {
+		return 1
+	}
+	return numSamples
+}
+
+// weightedAverage averages updates by their sample counts and returns the
+// average with the total sample count. Averaging partial aggregates this way
+// yields the same model as averaging all of the underlying updates at once.
+// Updates are summed in collaborator order, so that the result does not depend
+// on the order they arrived in.
+func weightedAverage(updates []weightedUpdate, modelSize int) ([]float32, int64) {
+	avg := make([]float32, modelSize)
+	total := weightedAverageChunks(updates, modelSize, func(i int, v float32) { avg[i] = v })
+	return avg, total
+}
+
+// weightedAverageInto writes the weighted average of updates as an encoded
+// model to dst and returns their total sample count
+func weightedAverageInto(updates []weightedUpdate, dst []byte) int64 {
+	total := weightedAverageChunks(updates, len(dst)/4, func(i int, v float32) { putFloat(dst, i, v) })
+	if total == 0 {
+		clear(dst)
+	}
+	return total
+}
+
+// weightedAverageChunks computes the weighted average chunk by chunk and
+// stores each parameter through store, which may be called concurrently for
+// different indices. Parameters are left unset when no update has samples.
+func weightedAverageChunks(updates []weightedUpdate, modelSize int, store func(i int, v float32)) int64 {
+	updates = slices.Clone(updates)
+	slices.SortStableFunc(updates, func(a, b weightedUpdate) int {
+		return strings.Compare(a.collaboratorID, b.collaboratorID)
+	})
+
+	var total int64
+	for _, upd := range updates {
+		total += upd.numSamples
+	}
+	if total == 0 {
+		return 0
+	}
+	// Each chunk sums its parameters in float64 and only stores the averages
+	parallelFor(modelSize, func(lo, hi int) {
+		sums := make([]float64, hi-lo)
+		for _, upd := range updates {
+			weight := float64(upd.numSamples)
+			for i := lo; i < min(hi, len(upd.weights)); i++ {
+				sums[i-lo] += weight * float64(upd.weights[i])
+			}
+		}
+		for i, sum := range sums {
+			store(lo+i, float32(sum/float64(total)))
+		}
+	})
+	return total
+}
+
+// EdgeAggregator aggregates the updates of a regional group of collaborators
+// each round and forwards the result to the root aggregator as a partial
+// aggregate. To its collaborators it serves the root's latest model.
+type EdgeAggregator struct {
+	pb.UnimplementedFederatedLearningServer
+	plan         *federation.FLPlan
+	attestation  *attestationGate // admits collaborators by their attestation evidence
+	mu           sync.Mutex
+	updates      []weightedUpdate
+	stream       *runningSum // sum of the round's updates when they are streamed
+	clipped      int         // number of the round's updates that were clipped
+	submitted    map[string]bool
+	quorum       *updateQuorum // reached once the round holds an update of every collaborator
+	model        []byte        // latest global model received from the root
+	modelHash    string
+	signature    []byte                  // root's signature of model, relayed to the edge's collaborators
+	verifier     *security.ModelVerifier // checks the root's signatures; nil unless the plan signs models
+	modelSize    int
+	currentRound int
+	srv          *grpc.Server
+	root         pb.FederatedLearningClient
+	drops        *DropTracker
+	run          *RunRecorder
+	events       *eventBroker
+}
+
+func NewEdgeAggregator(plan *federation.FLPlan) *EdgeAggregator {
+	drops := NewDropTracker(plan)
+	return &EdgeAggregator{
+		plan:        plan,
+		attestation: newAttestationGate(plan),
+		submitted:   make(map[string]bool),
+		drops:       drops,
+		run:         NewRunRecorder(plan, drops),
+		events:      newEventBroker(),
+	}
+}
+
+// Start joins the root aggregator, then runs the planned rounds with the
+// edge's collaborators
+func (a *EdgeAggregator) Start(ctx context.Context) (err error) {
+	a.run.Start()
+	defer func() { a.run.Finish(err) }()
+	// Servers started for the run stop when it returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	edgeID := a.plan.Hierarchy.EdgeID
+	if edgeID == "" || a.plan.Hierarchy.RootAddress == "" {
+		return fmt.Errorf("edge aggregator requires hierarchy.edge_id and hierarchy.root_address")
+	}
+
+	if a.verifier, err = loadVerifier(a.plan); err != nil {
+		return err
+	}
+
+	log.Printf("Starting EDGE aggregator %s on %s (root: %s)", edgeID, a.plan.Aggregator.Address, a.plan.Hierarchy.RootAddress)
+	log.Printf("Expecting %d collaborators for %d rounds", len(a.plan.Collaborators), a.plan.Rounds)
+
+	// Initialize TLS manager for both the root connection and the local server
+	tlsManager, err := security.NewTLSManager(security.TLSConfig(a.plan.Security.TLS), "certs")
+	if err != nil {
+		return fmt.Errorf("failed to initialize TLS manager: %w", err)
+	}
+
+	conn, err := a.connectRoot(tlsManager)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	joinCtx, cancel := context.WithTimeout(ctx, transport.CallTimeout(a.plan.GRPC))
+	resp, err := a.root.JoinFederation(joinCtx, &pb.JoinRequest{CollaboratorId: edgeID, Capabilities: edgeCapabilities()})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to join root aggregator: %w", err)
+	}
+	if _, err := transport.Negotiate(edgeCapabilities(), resp.Capabilities); err != nil {
+		return fmt.Errorf("root aggregator is incompatible with this edge: %v", err)
+	}
+	if err := transport.CheckWeights(resp.InitialModel, 0); err != nil {
+		return fmt.Errorf("root aggregator returned an invalid model: %v", err)
+	}
+	modelHash := transport.ModelHash(resp.InitialModel)
+	if err := a.verify(modelHash, resp.ModelSignature); err != nil {
+		return fmt.Errorf("root aggregator's initial model failed verification: %v", err)
+	}
+	a.model = resp.InitialModel
+	a.modelHash = modelHash
+	a.signature = resp.ModelSignature
+	a.modelSize = len(resp.InitialModel) / 4
+	if a.plan.Aggregator.Streaming {
+		a.stream = newRunningSum(a.modelSize)
+	}
+	a.run.RecordModel(0, a.modelHash)
+	log.Printf("Joined root aggregator, model size: %d parameters", a.modelSize)
+
+	lis, err := net.Listen("tcp", a.plan.Aggregator.Address)
+	if err != nil {
+		return err
+	}
+
+	serverOpts, err := tlsManager.NewServerOptions()
+	if err != nil {
+		return fmt.Errorf("failed to get server options: %w", err)
+	}
+	if len(serverOpts) == 0 {
+		serverOpts = []grpc.ServerOption{grpc.Creds(insecure.NewCredentials())}
+	}
+	serverOpts = append(serverOpts, transport.ServerOptions(a.plan.GRPC)...)
+
+	a.srv = grpc.NewServer(serverOpts...)
+	pb.RegisterFederatedLearningServer(a.srv, a)
+	defer a.srv.Stop()
+
+	go func() {
+		log.Printf("gRPC server listening on %s", a.plan.Aggregator.Address)
+		if err := a.srv.Serve(lis); err != nil {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}()
+	startMetricsServer(ctx, a.plan, a.drops)
+
+	a.mu.Lock()
+	a.quorum = newUpdateQuorum(len(a.plan.Collaborators))
+	a.mu.Unlock()
+	for round := 1; round <= a.plan.Rounds; round++ {
+		a.mu.Lock()
+		a.currentRound = round
+		modelHash, quorum := a.modelHash, a.quorum
+		a.mu.Unlock()
+		a.events.RoundStarted(round, modelHash, nil)
+		log.Printf("Starting round %d/%d", round, a.plan.Rounds)
+
+		if err := quorum.Wait(ctx, round, a.updateCount); err != nil {
+			return err
+		}
+
+		// Collect the next round's updates while this one is forwarded
+		a.mu.Lock()
+		var partial []float32
+		var numSamples int64
+		if a.stream != nil {
+			partial, numSamples = a.stream.Average()
+			a.stream = newRunningSum(a.modelSize)
+		} else {
+			partial, numSamples = weightedAverage(a.updates, a.modelSize)
+		}
+		numUpdates, clipped := len(a.updates), a.clipped
+		a.updates = nil
+		a.clipped = 0
+		a.submitted = make(map[string]bool)
+		a.quorum = newUpdateQuorum(len(a.plan.Collaborators))
+		a.mu.Unlock()
+		if clipped > 0 {
+			log.Printf("Round %d: clipped %d/%d updates to norm %g", round, clipped, numUpdates, clipNorm(a.plan))
+		}
+
+		if err := a.forward(ctx, round, partial, numSamples, numUpdates); err != nil {
+			return err
+		}
+		a.run.RecordRound(round, "")
+		a.run.RecordModel(round, transport.ModelHash(encodeModel(partial)))
+		a.run.SetFinalModel(partial)
+		log.Printf("Round %d complete, forwarded partial aggregate of %d updates (%d samples) to root",
+			round, numUpdates, numSamples)
+
+		a.refreshModel(ctx)
+	}
+
+	log.Printf("All %d rounds completed successfully", a.plan.Rounds)
+	a.events.Close("all rounds completed")
+	return nil
+}
+
+// connectRoot dials the root aggregator
+func (a *EdgeAggregator) connectRoot(tlsManager *security.TLSManager) (*grpc.ClientConn, error) {
+	dialOpts, err := tlsManager.NewClientDialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client dial options: %w", err)
+	}
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	dialOpts = append(dialOpts, transport.DialOptions(a.plan.GRPC)...)
+
+	conn, err := grpc.NewClient(a.plan.Hierarchy.RootAddress, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to root aggregator: %w", err)
+	}
+	a.root = pb.NewFederatedLearningClient(conn)
+	return conn, nil
+}
+
+// updateCount returns the number of updates of the current round
+func (a *EdgeAggregator) updateCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.updates)
+}
+
+// forward submits the partial aggregate of a round to the root aggregator
+func (a *EdgeAggregator) forward(ctx context.Context, round int, partial []float32, numSamples int64, numUpdates int) error {
+	submitCtx, cancel := context.WithTimeout(ctx, transport.CallTimeout(a.plan.GRPC))
+	defer cancel()
+	ack, err := a.root.SubmitPartialAggregate(submitCtx, &pb.PartialAggregate{
+		AggregatorId: a.plan.Hierarchy.EdgeID,
+		ModelWeights: encodeModel(partial),
+		NumSamples:   numSamples,
+		NumUpdates:   clampInt32(numUpdates),
+		Round:        clampInt32(round),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to forward partial aggregate for round %d: %w", round, err)
+	}
+	if !ack.Success {
+		return fmt.Errorf("root aggregator rejected the partial aggregate for round %d: %s", round, ack.Message)
+	}
+	return nil
+}
+
+// refreshModel fetches the latest global model from the root for the edge's
+// collaborators. On failure the previous model keeps being served.
+func (a *EdgeAggregator) refreshModel(ctx context.Context) {
+	getCtx, cancel := context.WithTimeout(ctx, transport.CallTimeout(a.plan.GRPC))
+	defer cancel()
+	a.mu.Lock()
+	cached := a.modelHash
+	a.mu.Unlock()
+	resp, err := a.root.GetLatestModel(getCtx, &pb.GetModelRequest{CollaboratorId: a.plan.Hierarchy.EdgeID, IfVersion: cached})
+	if err != nil {
+		log.Printf("Warning: failed to get latest model from root: %v", err)
+		return
+	}
+	if resp.NotModified {
+		return
+	}
+	if err := transport.CheckWeights(resp.ModelWeights, a.modelSize); err != nil {
+		log.Printf("Warning: root returned an invalid model, keeping the previous model: %v", err)
+		return
+	}
+
+	modelHash := transport.ModelHash(resp.ModelWeights)
+	if err := a.verify(modelHash, resp.ModelSignature); err != nil {
+		log.Printf("Warning: root's model failed verification, keeping the previous model: %v", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.model = resp.ModelWeights
+	a.modelHash = modelHash
+	a.signature = resp.ModelSignature
+	a.mu.Unlock()
+}
+
+// verify checks the root's signature of a model when the plan signs models
+func (a *EdgeAggregator) verify(modelHash string, signature []byte) error {
+	if a.verifier == nil {
+		return nil
+	}
+	return a.verifier.Verify(modelHash, signature)
+}
+
+// Report returns the run summary
+func (a *EdgeAggregator) Report() *RunReport {
+	return a.run.Report()
+}
+
+// Manifest returns the reproducibility manifest of the run
+func (a *EdgeAggregator) Manifest() *RunManifest {
+	return a.run.Manifest()
+}
+
+func (a *EdgeAggregator) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
+	log.Printf("Collaborator %s joining edge %s", req.CollaboratorId, a.plan.Hierarchy.EdgeID)
+	capabilities, err := negotiate(ctx, a.plan, a.attestation, edgeCapabilities(), req)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return &pb.JoinResponse{InitialModel: a.model, Capabilities: capabilities, ModelSignature: a.signature}, nil
+}
+
+func (a *EdgeAggregator) SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error) {
+	if err := a.attestation.authorize(upd.CollaboratorId); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	round := a.currentRound
+	modelHash, model := a.modelHash, a.model
+	a.mu.Unlock()
+	if round == 0 {
+		return notStarted(), nil
+	}
+
+	floats, reason, err := decodeStreamed(upd.ModelWeights, a.modelSize, a.plan.Aggregator.Streaming)
+	if err != nil {
+		return a.drops.Reject(upd.CollaboratorId, round, reason, err.Error()), nil
+	}
+	if reason, detail := checkRound(upd, round, modelHash); reason != "" {
+		return a.drops.Reject(upd.CollaboratorId, round, reason, detail), nil
+	}
+	maxNorm := clipNorm(a.plan)
+	norm, clipped := clipEncoded(upd.ModelWeights, model, maxNorm)
+	if clipped {
+		logClipped(upd.CollaboratorId, round, norm, maxNorm)
+		if floats != nil {
+			floats, _, _ = decodeUpdate(upd.ModelWeights, a.modelSize)
+		}
+	}
+
+	a.mu.Lock()
+	if a.submitted[upd.CollaboratorId] {
+		a.mu.Unlock()
+		return a.drops.Reject(upd.CollaboratorId, round, monitoring.DropReasonDuplicate,
+			fmt.Sprintf("collaborator already submitted an update for round %d", round)), nil
+	}
+	a.submitted[upd.CollaboratorId] = true
+	if clipped {
+		a.clipped++
+	}
+	if a.stream != nil {
+		a.stream.Add(upd.ModelWeights, sampleCount(upd.NumSamples))
+	}
+	a.updates = append(a.updates, weightedUpdate{weights: floats, numSamples: sampleCount(upd.NumSamples), collaboratorID: upd.CollaboratorId})
+	updateCount := len(a.updates)
+	a.quorum.Received(updateCount)
+	a.mu.Unlock()
+	a.run.RecordUpdate(upd.CollaboratorId, round)
+	a.run.RecordTraining(upd.Metrics)
+
+	logging.Infof("Received update %d/%d for round %d", updateCount, len(a.plan.Collaborators), round)
+	return &pb.Ack{Success: true}, nil
+}
+
+// WatchEvents streams the edge's round starts to a collaborator
+func (a *EdgeAggregator) WatchEvents(req *pb.WatchRequest, stream pb.FederatedLearning_WatchEventsServer) error {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return err
+	}
+	return a.events.Watch(stream.Context(), req.CollaboratorId, stream.Send)
+}
+
+func (a *EdgeAggregator) GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error) {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if resp := notModified(req, a.modelHash, clampInt32(a.currentRound)); resp != nil {
+		return resp, nil
+	}
+	return &pb.GetModelResponse{
+		ModelWeights:   a.model,
+		CurrentRound:   clampInt32(a.currentRound),
+		ModelHash:      a.modelHash,
+		ModelSignature: a.signature,
+	}, nil
+}
+
+// clampInt32 converts a non-negative count to int32, capping it at MaxInt32
+func clampInt32(v int) int32 {
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(v) // #nosec G115 - Safe conversion with bounds check above
+}
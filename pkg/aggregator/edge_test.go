@@ -0,0 +1,173 @@
+package aggregator
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestWeightedAverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates []weightedUpdate
+		want    []float32
+		samples int64
+	}{
+		{"equal samples", []weightedUpdate{{[]float32{1, 2}, 1, "a"}, {[]float32{3, 4}, 1, "b"}}, []float32{2, 3}, 2},
+		{"weighted", []weightedUpdate{{[]float32{1, 1}, 1, "a"}, {[]float32{5, 5}, 3, "b"}}, []float32{4, 4}, 4},
+		{"no updates", nil, []float32{0, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, samples := weightedAverage(tt.updates, 2)
+			if samples != tt.samples {
+				t.Errorf("samples = %d, want %d", samples, tt.samples)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("average = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	// An aggregate of partial aggregates equals the flat aggregate
+	regionA, samplesA := weightedAverage([]weightedUpdate{{[]float32{1}, 1, "a"}, {[]float32{5}, 3, "b"}}, 1)
+	regionB, samplesB := weightedAverage([]weightedUpdate{{[]float32{2}, 4, "c"}}, 1)
+	root, _ := weightedAverage([]weightedUpdate{{regionA, samplesA, "edge-a"}, {regionB, samplesB, "edge-b"}}, 1)
+	flat, _ := weightedAverage([]weightedUpdate{{[]float32{1}, 1, "a"}, {[]float32{5}, 3, "b"}, {[]float32{2}, 4, "c"}}, 1)
+	if root[0] != flat[0] {
+		t.Errorf("hierarchical average = %v, flat average = %v", root[0], flat[0])
+	}
+
+	// The average does not depend on the arrival order
+	updates := []weightedUpdate{{[]float32{0.1}, 7, "a"}, {[]float32{0.7}, 3, "b"}, {[]float32{0.3}, 11, "c"}}
+	forward, _ := weightedAverage(updates, 1)
+	reversed, _ := weightedAverage([]weightedUpdate{updates[2], updates[1], updates[0]}, 1)
+	if forward[0] != reversed[0] {
+		t.Errorf("average depends on arrival order: %v != %v", forward[0], reversed[0])
+	}
+}
+
+// freeAddress returns a loopback address that is free to listen on
+func freeAddress(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+func TestHierarchicalAggregation(t *testing.T) {
+	dir := t.TempDir()
+	initial := filepath.Join(dir, "initial.bin")
+	if err := writeModel(initial, []float32{0, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	rootPlan := &federation.FLPlan{
+		Rounds:        1,
+		Collaborators: []federation.Collaborator{{ID: "edge-a"}, {ID: "edge-b"}},
+		Aggregator:    federation.AggregatorEntry{Address: freeAddress(t)},
+		InitialModel:  initial,
+		OutputModel:   filepath.Join(dir, "final.bin"),
+	}
+	edgePlan := func(id string, collaborators ...string) *federation.FLPlan {
+		plan := &federation.FLPlan{
+			Rounds:     1,
+			Role:       federation.RoleEdgeAggregator,
+			Aggregator: federation.AggregatorEntry{Address: freeAddress(t)},
+			Hierarchy:  federation.HierarchyConfig{EdgeID: id, RootAddress: rootPlan.Aggregator.Address},
+		}
+		for _, collaborator := range collaborators {
+			plan.Collaborators = append(plan.Collaborators, federation.Collaborator{ID: collaborator})
+		}
+		return plan
+	}
+	edgeA := edgePlan("edge-a", "c1", "c2")
+	edgeB := edgePlan("edge-b", "c3")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	root := NewFedAvgAggregator(rootPlan)
+	rootErr := make(chan error, 1)
+	go func() { rootErr <- root.Start(ctx) }()
+
+	edgeErrs := make(chan error, 2)
+	for _, plan := range []*federation.FLPlan{edgeA, edgeB} {
+		agg, ok := NewAggregator(plan).(*EdgeAggregator)
+		if !ok {
+			t.Fatalf("NewAggregator() = %T, want *EdgeAggregator", agg)
+		}
+		go func() { edgeErrs <- agg.Start(ctx) }()
+	}
+
+	submit := func(address, id string, numSamples int64, weights ...float32) {
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		client := pb.NewFederatedLearningClient(conn)
+
+		// Edges start serving once they have joined the root
+		for {
+			if _, err := client.JoinFederation(ctx, &pb.JoinRequest{CollaboratorId: id}); err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				t.Fatalf("%s could not join its edge: %v", id, ctx.Err())
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		ack, err := client.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: id, ModelWeights: encodeFloats(weights...), NumSamples: numSamples})
+		if err != nil || !ack.Success {
+			t.Fatalf("SubmitUpdate(%s) = %v, %v", id, ack, err)
+		}
+	}
+	submit(edgeA.Aggregator.Address, "c1", 1, 1, 1)
+	submit(edgeA.Aggregator.Address, "c2", 3, 5, 9)
+	submit(edgeB.Aggregator.Address, "c3", 4, 2, 2)
+
+	if err := <-rootErr; err != nil {
+		t.Fatalf("root Start() error = %v", err)
+	}
+	for range 2 {
+		if err := <-edgeErrs; err != nil {
+			t.Errorf("edge Start() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(rootPlan.OutputModel)
+	if err != nil {
+		t.Fatalf("Final model was not written: %v", err)
+	}
+	got, _, err := decodeUpdate(data, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flat FedAvg over all three collaborators: (1*1 + 3*5 + 4*2) / 8 and (1*1 + 3*9 + 4*2) / 8
+	if got[0] != 3 || got[1] != 4.5 {
+		t.Errorf("global model = %v, want [3 4.5]", got)
+	}
+
+	report := root.Report()
+	if report.RoundsCompleted != 1 || len(report.Participation) != 2 {
+		t.Errorf("root report = %d rounds, %d participants, want 1 round from both edges",
+			report.RoundsCompleted, len(report.Participation))
+	}
+}
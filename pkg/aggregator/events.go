@@ -0,0 +1,144 @@
+package aggregator
+
+import (
+	"context"
+	"log"
+	"maps"
+	"sync"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+)
+
+// eventBuffer is how many events a watcher may fall behind before it is
+// dropped. Dropped collaborators fall back to polling.
+const eventBuffer = 16
+
+// eventBroker fans federation events out to the collaborators watching them
+type eventBroker struct {
+	mu       sync.Mutex
+	watchers map[chan *pb.FederationEvent]string // watcher -> collaborator ID
+	last     *pb.FederationEvent                 // latest round start, replayed to new watchers
+	closed   bool
+
+	hyperparameters map[string]string // scheduled hyperparameters of the latest round
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{watchers: make(map[chan *pb.FederationEvent]string)}
+}
+
+// Publish sends event to every watcher
+func (b *eventBroker) Publish(event *pb.FederationEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	if event.Type == pb.EventType_EVENT_ROUND_STARTED {
+		b.last = event
+	}
+	for ch, collaboratorID := range b.watchers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Warning: collaborator %s is not keeping up with events, closing its event stream", collaboratorID)
+			delete(b.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// RoundStarted announces a sync round that started from the model with
+// modelHash. Hyperparameters are those set by the plan's schedule, if any;
+// a change from the previous round is announced as a config change first.
+func (b *eventBroker) RoundStarted(round int, modelHash string, hyperparameters map[string]string) {
+	b.mu.Lock()
+	changed := b.hyperparameters != nil && !maps.Equal(b.hyperparameters, hyperparameters)
+	b.hyperparameters = hyperparameters
+	b.mu.Unlock()
+
+	if changed {
+		b.Publish(&pb.FederationEvent{
+			Type:            pb.EventType_EVENT_CONFIG_CHANGED,
+			Round:           clampInt32(round),
+			Hyperparameters: hyperparameters,
+			Message:         "scheduled hyperparameters changed",
+		})
+	}
+	b.Publish(&pb.FederationEvent{
+		Type:            pb.EventType_EVENT_ROUND_STARTED,
+		Round:           clampInt32(round),
+		ModelHash:       modelHash,
+		Hyperparameters: hyperparameters,
+	})
+}
+
+// ModelChanged announces a new global model of an async federation
+func (b *eventBroker) ModelChanged(round int, modelHash string) {
+	b.Publish(&pb.FederationEvent{
+		Type:      pb.EventType_EVENT_MODEL_INVALIDATED,
+		Round:     clampInt32(round),
+		ModelHash: modelHash,
+	})
+}
+
+// ConfigChanged announces a runtime change of the aggregation settings
+func (b *eventBroker) ConfigChanged(message string) {
+	b.Publish(&pb.FederationEvent{Type: pb.EventType_EVENT_CONFIG_CHANGED, Message: message})
+}
+
+// Close tells the watchers that the federation is stopping and ends their
+// streams
+func (b *eventBroker) Close(message string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	stopping := &pb.FederationEvent{Type: pb.EventType_EVENT_FEDERATION_STOPPING, Message: message}
+	for ch := range b.watchers {
+		select {
+		case ch <- stopping:
+		default:
+		}
+		close(ch)
+	}
+	b.watchers = nil
+}
+
+// Watch sends events to a collaborator until ctx is canceled or the broker
+// closes, starting with the current round
+func (b *eventBroker) Watch(ctx context.Context, collaboratorID string, send func(*pb.FederationEvent) error) error {
+	ch := make(chan *pb.FederationEvent, eventBuffer)
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return send(&pb.FederationEvent{Type: pb.EventType_EVENT_FEDERATION_STOPPING, Message: "the federation is over"})
+	}
+	if b.last != nil {
+		ch <- b.last
+	}
+	b.watchers[ch] = collaboratorID
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.watchers, ch)
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+)
+
+func TestEventBroker(t *testing.T) {
+	b := newEventBroker()
+	b.RoundStarted(1, "abc", map[string]string{"lr": "0.1"})
+
+	received := make(chan *pb.FederationEvent, eventBuffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Watch(context.Background(), "c1", func(event *pb.FederationEvent) error {
+			received <- event
+			return nil
+		})
+	}()
+
+	next := func() *pb.FederationEvent {
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for an event")
+			return nil
+		}
+	}
+
+	// A new watcher learns about the current round first
+	if event := next(); event.Type != pb.EventType_EVENT_ROUND_STARTED || event.Round != 1 || event.ModelHash != "abc" {
+		t.Errorf("first event = %v, want round 1 started", event)
+	}
+
+	b.RoundStarted(2, "def", map[string]string{"lr": "0.05"})
+	if event := next(); event.Type != pb.EventType_EVENT_CONFIG_CHANGED || event.Hyperparameters["lr"] != "0.05" {
+		t.Errorf("event = %v, want config changed", event)
+	}
+	if event := next(); event.Type != pb.EventType_EVENT_ROUND_STARTED || event.Round != 2 {
+		t.Errorf("event = %v, want round 2 started", event)
+	}
+
+	b.Close("all rounds completed")
+	if event := next(); event.Type != pb.EventType_EVENT_FEDERATION_STOPPING {
+		t.Errorf("event = %v, want federation stopping", event)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch() did not return after Close")
+	}
+}
+
+func TestEventBrokerDropsSlowWatchers(t *testing.T) {
+	b := newEventBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocked := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Watch(ctx, "slow", func(*pb.FederationEvent) error {
+			<-blocked
+			return nil
+		})
+	}()
+	for {
+		b.mu.Lock()
+		watching := len(b.watchers)
+		b.mu.Unlock()
+		if watching == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for round := 1; round <= eventBuffer+2; round++ {
+		b.ModelChanged(round, "abc")
+	}
+	close(blocked)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch() of a slow watcher did not return")
+	}
+}
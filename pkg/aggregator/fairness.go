@@ -0,0 +1,135 @@
+package aggregator
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// oldestQueued returns the index of the oldest of collaboratorID's queued
+// updates once it has maxPending of them, so that a new update replaces it, or
+// -1 while it may queue more. The queue of n updates is in arrival order and
+// idAt returns the collaborator of an update. A maxPending of zero is unlimited.
+func oldestQueued(n int, idAt func(i int) string, collaboratorID string, maxPending int) int {
+	if maxPending <= 0 {
+		return -1
+	}
+	oldest, pending := -1, 0
+	for i := 0; i < n; i++ {
+		if idAt(i) == collaboratorID {
+			if oldest < 0 {
+				oldest = i
+			}
+			pending++
+		}
+	}
+	if pending < maxPending {
+		return -1
+	}
+	return oldest
+}
+
+// selectFair splits a queue of n updates in arrival order into the updates an
+// aggregation takes and the ones that wait for the next aggregation. Each
+// collaborator contributes at most perCollaborator updates, its oldest first,
+// so a fast collaborator cannot fill an aggregation on its own. A
+// perCollaborator of zero takes every update.
+func selectFair(n int, idAt func(i int) string, perCollaborator int) (selected, deferred []int) {
+	taken := make(map[string]int)
+	for i := 0; i < n; i++ {
+		id := idAt(i)
+		if perCollaborator > 0 && taken[id] >= perCollaborator {
+			deferred = append(deferred, i)
+			continue
+		}
+		taken[id]++
+		selected = append(selected, i)
+	}
+	return selected, deferred
+}
+
+// inclusionTracker counts, per collaborator, the async updates an aggregator
+// received and the ones it aggregated. A collaborator whose updates are rarely
+// aggregated, or that makes up most of the aggregated updates, biases the
+// global model.
+type inclusionTracker struct {
+	mu           sync.Mutex
+	federationID string
+	received     map[string]int
+	included     map[string]int
+}
+
+func newInclusionTracker(plan *federation.FLPlan) *inclusionTracker {
+	return &inclusionTracker{
+		federationID: federationIDFor(plan),
+		received:     make(map[string]int),
+		included:     make(map[string]int),
+	}
+}
+
+// Received counts an update queued for aggregation
+func (t *inclusionTracker) Received(collaboratorID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.received[collaboratorID]++
+}
+
+// Included counts the updates of an aggregation
+func (t *inclusionTracker) Included(collaboratorIDs []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range collaboratorIDs {
+		t.included[id]++
+	}
+}
+
+// Rate returns the share of a collaborator's updates that were aggregated
+func (t *inclusionTracker) Rate(collaboratorID string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.received[collaboratorID] == 0 {
+		return 0
+	}
+	return float64(t.included[collaboratorID]) / float64(t.received[collaboratorID])
+}
+
+// ServeHTTP exposes the inclusion counters and rates in the Prometheus text format
+func (t *inclusionTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.Lock()
+	ids := make([]string, 0, len(t.received))
+	for id := range t.received {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	received := make([]monitoring.PrometheusSample, len(ids))
+	included := make([]monitoring.PrometheusSample, len(ids))
+	rates := make([]monitoring.PrometheusSample, len(ids))
+	for k, id := range ids {
+		labels := map[string]string{"federation_id": t.federationID, "collaborator_id": id}
+		received[k] = monitoring.PrometheusSample{Labels: labels, Value: float64(t.received[id])}
+		included[k] = monitoring.PrometheusSample{Labels: labels, Value: float64(t.included[id])}
+		rates[k] = monitoring.PrometheusSample{Labels: labels, Value: float64(t.included[id]) / float64(t.received[id])}
+	}
+	t.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	monitoring.WritePrometheusMetric(w, "flgo_aggregator_async_updates_received_total",
+		"Async model updates queued for aggregation", "counter", received)
+	monitoring.WritePrometheusMetric(w, "flgo_aggregator_async_updates_included_total",
+		"Async model updates included in an aggregation", "counter", included)
+	monitoring.WritePrometheusMetric(w, "flgo_aggregator_async_inclusion_rate",
+		"Share of a collaborator's async updates that were aggregated", "gauge", rates)
+}
+
+// metricsHandlers serves the metrics of several handlers on one endpoint
+type metricsHandlers []http.Handler
+
+func (m metricsHandlers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, handler := range m {
+		handler.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,97 @@
+package aggregator
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestSelectFair(t *testing.T) {
+	queue := []string{"fast", "fast", "slow", "fast", "other", "slow"}
+	idAt := func(i int) string { return queue[i] }
+
+	selected, deferred := selectFair(len(queue), idAt, 1)
+	if !slices.Equal(selected, []int{0, 2, 4}) || !slices.Equal(deferred, []int{1, 3, 5}) {
+		t.Errorf("selectFair(1) = %v, %v", selected, deferred)
+	}
+	selected, deferred = selectFair(len(queue), idAt, 2)
+	if !slices.Equal(selected, []int{0, 1, 2, 4, 5}) || !slices.Equal(deferred, []int{3}) {
+		t.Errorf("selectFair(2) = %v, %v", selected, deferred)
+	}
+	if selected, deferred = selectFair(len(queue), idAt, 0); len(selected) != len(queue) || len(deferred) != 0 {
+		t.Errorf("selectFair without a cap = %v, %v", selected, deferred)
+	}
+
+	if i := oldestQueued(len(queue), idAt, "fast", 3); i != 0 {
+		t.Errorf("oldestQueued of a full queue = %d, want 0", i)
+	}
+	if i := oldestQueued(len(queue), idAt, "slow", 3); i != -1 {
+		t.Errorf("oldestQueued below the cap = %d, want -1", i)
+	}
+	if i := oldestQueued(len(queue), idAt, "fast", 0); i != -1 {
+		t.Errorf("oldestQueued without a cap = %d, want -1", i)
+	}
+}
+
+func TestAsyncAggregatorCapsQueuedUpdates(t *testing.T) {
+	plan := &federation.FLPlan{
+		Mode:          federation.ModeAsync,
+		Collaborators: []federation.Collaborator{{ID: "fast"}, {ID: "slow"}},
+		AsyncConfig:   federation.AsyncConfig{MaxPendingPerCollaborator: 2, MaxPerCollaborator: 1},
+	}
+	agg := NewAsyncFedAvgAggregator(plan)
+	agg.modelSize = 2
+	submit := func(id string, value float32) {
+		t.Helper()
+		ack, err := agg.SubmitUpdate(context.Background(), &pb.ModelUpdate{CollaboratorId: id, ModelWeights: encodeModel([]float32{value, value})})
+		if err != nil || !ack.Success {
+			t.Fatalf("SubmitUpdate(%s) = %v, %v", id, ack, err)
+		}
+	}
+
+	for value := range 3 {
+		submit("fast", float32(value))
+	}
+	if len(agg.updates) != 2 || agg.updates[0].Weights[0] != 1 {
+		t.Fatalf("queue holds %d updates starting with %v, want the 2 newest", len(agg.updates), agg.updates[0].Weights)
+	}
+	if n := agg.drops.Count("fast", monitoring.DropReasonRateLimited); n != 1 {
+		t.Errorf("%d replaced updates were counted as dropped, want 1", n)
+	}
+	// One collaborator alone does not make up an aggregation of two updates
+	if n := agg.aggregatable(); n != 1 {
+		t.Errorf("next aggregation takes %d updates, want 1", n)
+	}
+	submit("slow", 5)
+	if n := agg.aggregatable(); n != 2 {
+		t.Errorf("next aggregation takes %d updates, want 2", n)
+	}
+
+	agg.inclusion.Included([]string{"fast", "slow"})
+	if rate := agg.inclusion.Rate("fast"); rate < 0.33 || rate > 0.34 {
+		t.Errorf("inclusion rate of fast = %g, want 1/3", rate)
+	}
+	if rate := agg.inclusion.Rate("slow"); rate != 1 {
+		t.Errorf("inclusion rate of slow = %g, want 1", rate)
+	}
+
+	rec := httptest.NewRecorder()
+	metricsHandlers{agg.drops, agg.inclusion}.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`flgo_aggregator_dropped_updates_total{collaborator_id="fast",federation_id="default",reason="rate_limited"} 1`,
+		`flgo_aggregator_async_updates_received_total{collaborator_id="fast",federation_id="default"} 3`,
+		`flgo_aggregator_async_updates_included_total{collaborator_id="slow",federation_id="default"} 1`,
+		`flgo_aggregator_async_inclusion_rate{collaborator_id="slow",federation_id="default"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %s:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// loadHomomorphic returns the public key collaborators encrypt their updates
+// with and the key holder that decrypts the round sums, or nils when the plan
+// does not encrypt updates
+func loadHomomorphic(plan *federation.FLPlan) (*security.PaillierPublicKey, security.KeyHolder, error) {
+	he := plan.Security.Homomorphic
+	if !he.Enabled {
+		return nil, nil, nil
+	}
+	if he.PublicKeyPath == "" {
+		return nil, nil, fmt.Errorf("security.homomorphic requires public_key_path")
+	}
+	key, err := security.LoadPaillierPublicKey(he.PublicKeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load homomorphic public key: %w", err)
+	}
+	switch {
+	case he.KeyHolderURL != "":
+		log.Printf("Aggregating encrypted updates; round sums are decrypted by the key holder at %s", he.KeyHolderURL)
+		return key, security.NewRemoteKeyHolder(he.KeyHolderURL, he.KeyHolderToken), nil
+	case he.PrivateKeyPath != "":
+		private, err := security.LoadPaillierPrivateKey(he.PrivateKeyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load homomorphic private key: %w", err)
+		}
+		if private.N.Cmp(key.N) != 0 {
+			return nil, nil, fmt.Errorf("homomorphic private key does not match public_key_path")
+		}
+		log.Printf("Warning: aggregating encrypted updates with the private key in the aggregator, which could decrypt single updates")
+		return key, security.NewLocalKeyHolder(private), nil
+	default:
+		return nil, nil, fmt.Errorf("security.homomorphic requires key_holder_url or private_key_path")
+	}
+}
+
+// acceptEncrypted adds an encrypted update to the round's encrypted sum. The
+// aggregator cannot look into it, so it is neither validated beyond its size
+// nor clipped, scored or archived.
+func (a *FedAvgAggregator) acceptEncrypted(upd *pb.ModelUpdate, round int, modelHash string) *pb.Ack {
+	collaboratorID := upd.CollaboratorId
+	if !upd.Encrypted {
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonValidationFailed,
+			"the federation only accepts encrypted updates")
+	}
+	if a.heKey == nil {
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonValidationFailed,
+			"the federation does not accept encrypted updates")
+	}
+	if reason, detail := checkRound(upd, round, modelHash); reason != "" {
+		return a.drops.Reject(collaboratorID, round, reason, detail)
+	}
+	if err := a.wal.Append(round, upd); err != nil {
+		return notLogged(err)
+	}
+
+	// The sum is added to under the lock, so that the round does not end
+	// with an update counted but not yet added
+	a.mu.Lock()
+	if a.submitted[collaboratorID] {
+		a.mu.Unlock()
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonDuplicate,
+			fmt.Sprintf("collaborator already submitted an update for round %d", round))
+	}
+	if err := a.encrypted.Add(upd.ModelWeights); err != nil {
+		a.mu.Unlock()
+		return a.drops.Reject(collaboratorID, round, monitoring.DropReasonValidationFailed, err.Error())
+	}
+	a.submitted[collaboratorID] = true
+	a.updates = append(a.updates, weightedUpdate{numSamples: sampleCount(upd.NumSamples), collaboratorID: collaboratorID})
+	updateCount := len(a.updates)
+	a.quorum.Received(updateCount)
+	a.mu.Unlock()
+	a.run.RecordUpdate(collaboratorID, round)
+	a.run.RecordTraining(upd.Metrics)
+	a.reporter.Record(ClientUpdate{
+		CollaboratorID:  collaboratorID,
+		Size:            len(upd.ModelWeights),
+		Timestamp:       time.Now(),
+		Round:           round,
+		NumSamples:      int(sampleCount(upd.NumSamples)),
+		ImageDigest:     upd.ImageDigest,
+		TrainingMetrics: upd.Metrics,
+	})
+
+	logging.Infof("Received encrypted update %d/%d for round %d", updateCount, a.expectedUpdates(), round)
+	return &pb.Ack{Success: true}
+}
+
+// decryptRound has the key holder decrypt the round's encrypted sum and
+// returns the encoded weighted average of the updates
+func (a *FedAvgAggregator) decryptRound(ctx context.Context, round int, sum *security.EncryptedSum, updates []weightedUpdate) ([]byte, error) {
+	var total int64
+	for _, update := range updates {
+		total += update.numSamples
+	}
+	start := time.Now()
+	sums, err := a.keyHolder.DecryptSum(ctx, sum.Bytes(), sum.Count(), a.modelSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the sum of round %d: %w", round, err)
+	}
+	log.Printf("Decrypted the sum of %d updates in %v", sum.Count(), time.Since(start).Round(time.Millisecond))
+	avg := make([]float32, len(sums))
+	for i, s := range sums {
+		avg[i] = float32(s / float64(max(total, 1)))
+	}
+	return encodeModel(avg), nil
+}
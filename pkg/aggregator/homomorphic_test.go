@@ -0,0 +1,91 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/rand"
+	"math"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func TestFedAvgAggregatorHomomorphic(t *testing.T) {
+	key, err := security.GeneratePaillierKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &federation.FLPlan{
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+		Security:      federation.SecurityConfig{Homomorphic: federation.HomomorphicConfig{Enabled: true}},
+	}
+	agg := NewFedAvgAggregator(plan)
+	base := encodeModel(make([]float32, 20))
+	agg.modelSize = 20
+	agg.currentRound = 1
+	agg.globalModel, agg.modelHash = base, transport.ModelHash(base)
+	agg.heKey, agg.keyHolder = key.Public(), security.NewLocalKeyHolder(key)
+	agg.encrypted = security.NewEncryptedSum(key.Public(), 20)
+
+	submit := func(id string, value float32, samples int64, encrypted bool) *pb.Ack {
+		t.Helper()
+		weights := make([]float32, 20)
+		for i := range weights {
+			weights[i] = value
+		}
+		data := encodeModel(weights)
+		if encrypted {
+			if data, err = security.EncryptUpdate(key.Public(), weights, float64(samples)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ack, _ := agg.SubmitUpdate(context.Background(), &pb.ModelUpdate{
+			CollaboratorId: id, ModelWeights: data, NumSamples: samples, Round: 1,
+			BaseModelHash: agg.modelHash, Encrypted: encrypted,
+		})
+		return ack
+	}
+
+	if ack := submit("c1", 1, 10, false); ack.Success {
+		t.Error("plain update was accepted by an aggregator of encrypted updates")
+	}
+	if ack := submit("c1", 1, 10, true); !ack.Success {
+		t.Fatalf("encrypted update rejected: %s", ack.Message)
+	}
+	if ack := submit("c1", 1, 10, true); ack.Success {
+		t.Error("duplicate encrypted update was accepted")
+	}
+	if ack := submit("c2", -2, 30, true); !ack.Success {
+		t.Fatalf("encrypted update rejected: %s", ack.Message)
+	}
+	if agg.encrypted.Count() != 2 || agg.updates[0].weights != nil {
+		t.Fatalf("aggregator holds %d encrypted updates", agg.encrypted.Count())
+	}
+
+	buf, err := agg.decryptRound(context.Background(), 1, agg.encrypted, agg.updates)
+	if err != nil {
+		t.Fatalf("decryptRound: %v", err)
+	}
+	model, _, err := decodeUpdate(buf, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := (1*10 - 2*30) / 40.0
+	for i, w := range model {
+		if math.Abs(float64(w)-want) > 1e-3 {
+			t.Fatalf("weight %d = %g, want %g", i, w, want)
+		}
+	}
+
+	// Aggregators of plain updates refuse encrypted ones
+	plain := NewFedAvgAggregator(&federation.FLPlan{Collaborators: plan.Collaborators})
+	plain.modelSize = 20
+	plain.currentRound = 1
+	plain.globalModel, plain.modelHash = base, agg.modelHash
+	agg = plain
+	if ack := submit("c1", 1, 10, true); ack.Success {
+		t.Error("encrypted update was accepted by an aggregator of plain updates")
+	}
+}
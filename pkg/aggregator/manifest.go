@@ -0,0 +1,257 @@
+package aggregator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// defaultManifestPath is used when the plan does not set manifest_path
+const defaultManifestPath = "save/run_manifest.json"
+
+// RunManifest records what determines the outcome of a run, so that a rerun
+// can be checked against it
+type RunManifest struct {
+	FederationID     string            `json:"federation_id"`
+	Mode             federation.FLMode `json:"mode"`
+	Algorithm        string            `json:"algorithm"`
+	PlanHash         string            `json:"plan_hash"`
+	Seed             uint64            `json:"seed,omitempty"`            // the plan's seed; 0 when the run was unseeded
+	Seeds            map[string]string `json:"seeds,omitempty"`           // collaborator ID -> seed derived for its privacy noise
+	Hyperparameters  map[string]string `json:"hyperparameters,omitempty"` // algorithm hyperparameters
+	InitialModelHash string            `json:"initial_model_hash,omitempty"`
+	InitialModelFrom string            `json:"initial_model_from,omitempty"` // registry model the run started from, name@vN
+	// Signing key ID and initial model signature, with security.model_signing
+	SigningKey            string          `json:"signing_key,omitempty"`
+	InitialModelSignature []byte          `json:"initial_model_signature,omitempty"`
+	Rounds                []ManifestRound `json:"rounds"`
+}
+
+// ManifestRound records one round of a run
+type ManifestRound struct {
+	Round           int               `json:"round"`
+	ModelHash       string            `json:"model_hash,omitempty"`      // hash of the model the round produced
+	ParentHash      string            `json:"parent_hash,omitempty"`     // hash of the model recorded before it
+	Signature       []byte            `json:"signature,omitempty"`       // aggregator's signature of model_hash
+	Participants    []string          `json:"participants"`              // collaborators whose updates were accepted for the round
+	Hyperparameters map[string]string `json:"hyperparameters,omitempty"` // train args after the schedule
+}
+
+// newManifest starts the manifest of the run described by plan
+func newManifest(plan *federation.FLPlan, federationID, algorithm string) RunManifest {
+	manifest := RunManifest{
+		FederationID:     federationID,
+		Mode:             plan.Mode,
+		Algorithm:        algorithm,
+		Seed:             plan.Seed,
+		Hyperparameters:  taskHyperparameters(plan.Algorithm.Hyperparameters),
+		InitialModelFrom: plan.InitialModelFrom,
+	}
+	if hash, err := federation.PlanHash(plan); err == nil {
+		manifest.PlanHash = hash
+	}
+	if plan.Seed != 0 {
+		manifest.Seeds = make(map[string]string, len(plan.Collaborators))
+		for _, collab := range plan.Collaborators {
+			seed := federation.DeriveSeed(plan.Seed, collab.ID)
+			manifest.Seeds[collab.ID] = hex.EncodeToString(seed[:])
+		}
+	}
+	return manifest
+}
+
+// manifestRound returns the manifest entry of round, creating it on first use.
+// The caller holds r.mu.
+func (r *RunRecorder) manifestRound(round int) *ManifestRound {
+	entry, exists := r.rounds[round]
+	if !exists {
+		entry = &ManifestRound{Round: round, Participants: []string{}}
+		r.rounds[round] = entry
+	}
+	return entry
+}
+
+// SetSigner has the recorder sign the models it records from now on
+func (r *RunRecorder) SetSigner(signer *security.ModelSigner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signer = signer
+	r.manifest.SigningKey = signer.KeyID()
+}
+
+// RecordModel records the hash of the model a round produced, which chains
+// it to the model recorded before. Round 0 is the initial model.
+func (r *RunRecorder) RecordModel(round int, modelHash string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	signature := r.signer.Sign(modelHash)
+	if round == 0 {
+		r.manifest.InitialModelHash = modelHash
+		r.manifest.InitialModelSignature = signature
+		r.lastModel = modelHash
+		return
+	}
+	entry := r.manifestRound(round)
+	entry.ModelHash = modelHash
+	entry.ParentHash = r.lastModel
+	entry.Signature = signature
+	r.lastModel = modelHash
+}
+
+// RecordHyperparameters records the train args of a round
+func (r *RunRecorder) RecordHyperparameters(round int, hyperparameters map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifestRound(round).Hyperparameters = hyperparameters
+}
+
+// Manifest returns a snapshot of the run manifest, with rounds and
+// participants in order
+func (r *RunRecorder) Manifest() *RunManifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manifest := r.manifest
+	manifest.Rounds = make([]ManifestRound, 0, len(r.rounds))
+	for _, entry := range r.rounds {
+		round := *entry
+		round.Participants = slices.Sorted(slices.Values(entry.Participants))
+		manifest.Rounds = append(manifest.Rounds, round)
+	}
+	sort.Slice(manifest.Rounds, func(i, j int) bool {
+		return manifest.Rounds[i].Round < manifest.Rounds[j].Round
+	})
+	return &manifest
+}
+
+// ManifestPath returns where the plan's run manifest is written
+func ManifestPath(plan *federation.FLPlan) string {
+	if plan.ManifestPath == "" {
+		return defaultManifestPath
+	}
+	return plan.ManifestPath
+}
+
+// PublishManifest writes the manifest to the plan's manifest path and returns
+// that path
+func PublishManifest(plan *federation.FLPlan, manifest *RunManifest) (string, error) {
+	path := ManifestPath(plan)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode run manifest: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("failed to create manifest directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write run manifest: %v", err)
+	}
+	return path, nil
+}
+
+// LoadManifest reads a run manifest
+func LoadManifest(path string) (*RunManifest, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - manifests are chosen by the operator
+	if err != nil {
+		return nil, err
+	}
+	var manifest RunManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid run manifest %s: %v", path, err)
+	}
+	return &manifest, nil
+}
+
+// VerifyManifest compares a rerun with the run it reproduces and describes
+// each difference. A rerun that reproduced the run has none.
+func VerifyManifest(expected, actual *RunManifest) []string {
+	var diffs []string
+	differ := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+
+	if expected.PlanHash != actual.PlanHash {
+		differ("plan hash is %.12s, expected %.12s", actual.PlanHash, expected.PlanHash)
+	}
+	if expected.Algorithm != actual.Algorithm {
+		differ("algorithm is %s, expected %s", actual.Algorithm, expected.Algorithm)
+	}
+	if expected.Seed != actual.Seed {
+		differ("seed is %d, expected %d", actual.Seed, expected.Seed)
+	}
+	if !maps.Equal(expected.Seeds, actual.Seeds) {
+		differ("collaborator seeds differ")
+	}
+	if !maps.Equal(expected.Hyperparameters, actual.Hyperparameters) {
+		differ("algorithm hyperparameters are %v, expected %v", actual.Hyperparameters, expected.Hyperparameters)
+	}
+	if expected.InitialModelHash != actual.InitialModelHash {
+		differ("initial model is %.12s, expected %.12s", actual.InitialModelHash, expected.InitialModelHash)
+	}
+	if len(expected.Rounds) != len(actual.Rounds) {
+		differ("rerun recorded %d rounds, expected %d", len(actual.Rounds), len(expected.Rounds))
+	}
+
+	for i := 0; i < min(len(expected.Rounds), len(actual.Rounds)); i++ {
+		want, got := expected.Rounds[i], actual.Rounds[i]
+		if want.Round != got.Round {
+			differ("round %d was recorded as round %d", want.Round, got.Round)
+			continue
+		}
+		if !slices.Equal(want.Participants, got.Participants) {
+			differ("round %d participants are %v, expected %v", got.Round, got.Participants, want.Participants)
+		}
+		if !maps.Equal(want.Hyperparameters, got.Hyperparameters) {
+			differ("round %d hyperparameters are %v, expected %v", got.Round, got.Hyperparameters, want.Hyperparameters)
+		}
+		if want.ModelHash != got.ModelHash {
+			differ("round %d model is %.12s, expected %.12s", got.Round, got.ModelHash, want.ModelHash)
+		}
+	}
+	return diffs
+}
+
+// VerifyLineage checks that the models a run recorded form an unbroken chain
+// from the initial model and, with a verifier, that the aggregator signed
+// each of them. It describes each problem; an intact lineage has none.
+func VerifyLineage(manifest *RunManifest, verifier *security.ModelVerifier) []string {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if verifier != nil {
+		if manifest.SigningKey != "" && manifest.SigningKey != verifier.KeyID() {
+			fail("models were signed with key %.12s, not %.12s", manifest.SigningKey, verifier.KeyID())
+		}
+		if err := verifier.Verify(manifest.InitialModelHash, manifest.InitialModelSignature); err != nil {
+			fail("initial model: %v", err)
+		}
+	}
+
+	parent := manifest.InitialModelHash
+	for _, round := range manifest.Rounds {
+		if round.ModelHash == "" {
+			continue // the round was never aggregated
+		}
+		if round.ParentHash != parent {
+			fail("round %d follows model %.12s, but the previous model is %.12s", round.Round, round.ParentHash, parent)
+		}
+		if verifier != nil {
+			if err := verifier.Verify(round.ModelHash, round.Signature); err != nil {
+				fail("round %d: %v", round.Round, err)
+			}
+		}
+		parent = round.ModelHash
+	}
+	return problems
+}
@@ -0,0 +1,121 @@
+package aggregator
+
+import (
+	"crypto/ed25519"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// recordRun records a two-round run of plan and returns its manifest
+func recordRun(plan *federation.FLPlan, finalHash string) *RunManifest {
+	recorder := NewRunRecorder(plan, nil)
+	recorder.RecordModel(0, "initial")
+	recorder.RecordHyperparameters(1, map[string]string{"learning_rate": "0.1"})
+	recorder.RecordUpdate("c2", 1)
+	recorder.RecordUpdate("c1", 1)
+	recorder.RecordModel(1, "round1")
+	recorder.RecordUpdate("c1", 2)
+	recorder.RecordModel(2, finalHash)
+	return recorder.Manifest()
+}
+
+func TestRunRecorderManifest(t *testing.T) {
+	plan := &federation.FLPlan{
+		Rounds:        2,
+		Seed:          42,
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+		ManifestPath:  filepath.Join(t.TempDir(), "manifest.json"),
+	}
+	manifest := recordRun(plan, "round2")
+
+	if manifest.PlanHash == "" || manifest.InitialModelHash != "initial" || len(manifest.Seeds) != 2 {
+		t.Errorf("manifest = %+v, want plan hash, initial model and two collaborator seeds", manifest)
+	}
+	if len(manifest.Rounds) != 2 {
+		t.Fatalf("rounds = %d, want 2", len(manifest.Rounds))
+	}
+	if got := manifest.Rounds[0].Participants; !slices.Equal(got, []string{"c1", "c2"}) {
+		t.Errorf("round 1 participants = %v, want sorted [c1 c2]", got)
+	}
+	if manifest.Rounds[1].ModelHash != "round2" {
+		t.Errorf("round 2 model = %s, want round2", manifest.Rounds[1].ModelHash)
+	}
+
+	path, err := PublishManifest(plan, manifest)
+	if err != nil {
+		t.Fatalf("PublishManifest() error = %v", err)
+	}
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if diffs := VerifyManifest(manifest, loaded); len(diffs) != 0 {
+		t.Errorf("loaded manifest differs: %v", diffs)
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	plan := &federation.FLPlan{Rounds: 2, Seed: 1, ReportPath: "a.json"}
+	original := recordRun(plan, "round2")
+
+	// Where the outputs go does not change the plan hash
+	rerunPlan := *plan
+	rerunPlan.ReportPath = "b.json"
+	if diffs := VerifyManifest(original, recordRun(&rerunPlan, "round2")); len(diffs) != 0 {
+		t.Errorf("identical rerun differs: %v", diffs)
+	}
+
+	if diffs := VerifyManifest(original, recordRun(plan, "other")); len(diffs) != 1 {
+		t.Errorf("rerun with a different final model: diffs = %v, want 1", diffs)
+	}
+
+	reseeded := *plan
+	reseeded.Seed = 2
+	if diffs := VerifyManifest(original, recordRun(&reseeded, "round2")); len(diffs) != 2 {
+		t.Errorf("rerun with another seed: diffs = %v, want plan hash and seed", diffs)
+	}
+}
+
+func TestVerifyLineage(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := security.NewModelVerifier(public)
+
+	recorder := NewRunRecorder(&federation.FLPlan{Rounds: 3}, nil)
+	recorder.SetSigner(security.NewModelSigner(private))
+	recorder.RecordModel(0, "initial")
+	recorder.RecordModel(1, "round1")
+	recorder.RecordUpdate("c1", 2) // round 2 was never aggregated
+	recorder.RecordModel(3, "round3")
+	manifest := recorder.Manifest()
+
+	if manifest.Rounds[2].ParentHash != "round1" || manifest.SigningKey != verifier.KeyID() {
+		t.Errorf("manifest = %+v, want round 3 to follow round 1 and the signing key", manifest)
+	}
+	if problems := VerifyLineage(manifest, verifier); len(problems) != 0 {
+		t.Errorf("intact lineage has problems: %v", problems)
+	}
+	if problems := VerifyLineage(manifest, nil); len(problems) != 0 {
+		t.Errorf("intact lineage without a verifier has problems: %v", problems)
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	if problems := VerifyLineage(manifest, security.NewModelVerifier(otherKey)); len(problems) != 4 {
+		t.Errorf("lineage checked with another key = %v, want the key and 3 signatures", problems)
+	}
+
+	// A replaced model breaks both its signature and the chain
+	manifest.Rounds[0].ModelHash = "forged"
+	if problems := VerifyLineage(manifest, verifier); len(problems) != 2 {
+		t.Errorf("lineage with a replaced model = %v, want 2 problems", problems)
+	}
+	if problems := VerifyLineage(manifest, nil); len(problems) != 1 {
+		t.Errorf("unsigned check of a replaced model = %v, want the broken chain", problems)
+	}
+}
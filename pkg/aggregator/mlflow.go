@@ -0,0 +1,204 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// defaultMLflowExperiment is used when the plan does not name an experiment
+const defaultMLflowExperiment = "fl-go"
+
+// mlflowQueueSize bounds the MLflow calls waiting to be sent
+const mlflowQueueSize = 256
+
+// mlflowTracker mirrors a federation run as an MLflow run. Calls to the
+// tracking server are made in the background so that a slow server does not
+// delay aggregation.
+type mlflowTracker struct {
+	client     *monitoring.MLflowClient
+	experiment string
+	runName    string
+	tags       map[string]string
+	params     map[string]string
+	run        *monitoring.MLflowRun
+	queue      chan func() error
+	done       chan struct{}
+}
+
+func newMLflowTracker(plan *federation.FLPlan, federationID string) (*mlflowTracker, error) {
+	token := plan.MLflow.Token
+	if token == "" {
+		token = os.Getenv("MLFLOW_TRACKING_TOKEN")
+	}
+	client, err := monitoring.NewMLflowClient(plan.MLflow.TrackingURI, token)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment := plan.MLflow.Experiment
+	if experiment == "" {
+		experiment = defaultMLflowExperiment
+	}
+	runName := plan.MLflow.RunName
+	if runName == "" {
+		runName = federationID
+	}
+	algorithm := plan.Algorithm.Name
+	if algorithm == "" {
+		algorithm = "fedavg"
+	}
+
+	return &mlflowTracker{
+		client:     client,
+		experiment: experiment,
+		runName:    runName,
+		tags: map[string]string{
+			"federation_id": federationID,
+			"mode":          string(plan.Mode),
+			"algorithm":     algorithm,
+		},
+		params: planParams(plan),
+	}, nil
+}
+
+// start creates the MLflow run and logs the plan's hyperparameters as its
+// params
+func (t *mlflowTracker) start() error {
+	if t == nil {
+		return nil
+	}
+	run, err := t.client.StartRun(t.experiment, t.runName, t.tags)
+	if err != nil {
+		return err
+	}
+	log.Printf("Tracking federation run as MLflow run %s in experiment %s", run.ID, t.experiment)
+
+	t.run = run
+	t.queue = make(chan func() error, mlflowQueueSize)
+	t.done = make(chan struct{})
+	go t.work()
+	t.enqueue(func() error { return run.LogParams(t.params) })
+	return nil
+}
+
+func (t *mlflowTracker) work() {
+	defer close(t.done)
+	for call := range t.queue {
+		if err := call(); err != nil {
+			log.Printf("MLflow tracking: %v", err)
+		}
+	}
+}
+
+// enqueue schedules a call, dropping it when the tracking server has fallen
+// too far behind
+func (t *mlflowTracker) enqueue(call func() error) {
+	select {
+	case t.queue <- call:
+	default:
+		log.Printf("MLflow tracking is falling behind, dropping an update of run %s", t.run.ID)
+	}
+}
+
+func (t *mlflowTracker) logMetrics(metrics []monitoring.MLflowMetric) {
+	if t == nil || t.run == nil || len(metrics) == 0 {
+		return
+	}
+	run := t.run
+	metrics = append([]monitoring.MLflowMetric(nil), metrics...)
+	t.enqueue(func() error { return run.LogMetrics(metrics) })
+}
+
+// finish logs the final model as an artifact, ends the run with the status of
+// the federation run and waits for the pending calls
+func (t *mlflowTracker) finish(status RunStatus, finalModel string) {
+	if t == nil || t.run == nil {
+		return
+	}
+	run := t.run
+	if finalModel != "" {
+		t.enqueue(func() error {
+			data, err := storage.ReadFile(finalModel)
+			if err != nil {
+				return fmt.Errorf("failed to read final model %s: %v", finalModel, err)
+			}
+			return run.LogArtifact("model/"+filepath.Base(finalModel), data)
+		})
+	}
+	mlflowStatus := monitoring.MLflowRunFinished
+	switch status {
+	case RunAborted:
+		mlflowStatus = monitoring.MLflowRunKilled
+	case RunFailed:
+		mlflowStatus = monitoring.MLflowRunFailed
+	}
+	t.enqueue(func() error { return run.End(mlflowStatus) })
+
+	close(t.queue)
+	<-t.done
+	t.run = nil
+}
+
+// planParams returns the hyperparameters of plan as MLflow params, with
+// nested settings as dotted keys such as algorithm.hyperparameters.mu
+func planParams(plan *federation.FLPlan) map[string]string {
+	params := map[string]string{
+		"rounds":        strconv.Itoa(plan.Rounds),
+		"mode":          string(plan.Mode),
+		"collaborators": strconv.Itoa(len(plan.Collaborators)),
+	}
+	if plan.Seed != 0 {
+		params["seed"] = strconv.FormatUint(plan.Seed, 10)
+	}
+	flattenParams(params, "algorithm", plan.Algorithm)
+	flattenParams(params, "tasks.train.args", plan.Tasks.Train.Args)
+	flattenParams(params, "tasks.train.schedule", plan.Tasks.Train.Schedule)
+	if plan.Mode == federation.ModeAsync {
+		flattenParams(params, "async_config", plan.AsyncConfig)
+	}
+	return params
+}
+
+// flattenParams adds value under prefix, flattening maps and structs through
+// their YAML form. Lists are added as JSON and empty values are left out.
+func flattenParams(params map[string]string, prefix string, value interface{}) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return
+	}
+	var node interface{}
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return
+	}
+	flattenNode(params, prefix, node)
+}
+
+func flattenNode(params map[string]string, key string, node interface{}) {
+	switch v := node.(type) {
+	case nil:
+	case map[string]interface{}:
+		for name, child := range v {
+			flattenNode(params, key+"."+name, child)
+		}
+	case []interface{}:
+		if encoded, err := json.Marshal(v); err == nil {
+			params[key] = string(encoded)
+		}
+	case string:
+		if v != "" {
+			params[key] = v
+		}
+	default:
+		params[key] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,97 @@
+package aggregator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// mappedModel keeps the encoded global model of a sync aggregator in
+// memory-mapped files instead of on the heap. The kernel pages the model in
+// and out as it is served and aggregated, so a model of several gigabytes is
+// not held in resident memory twice, and each round's aggregate is written
+// into the mapping chunk by chunk rather than next to a decoded copy.
+//
+// A local initial model is mapped read-only. Aggregates alternate between
+// two scratch files, so the model being served is never the one being
+// written.
+type mappedModel struct {
+	initial []byte // initial model; read into memory when it is remote
+	mapped  bool   // whether initial is a mapping
+	buffers [2][]byte
+	paths   [2]string
+	next    int // buffer the next aggregate is written to
+}
+
+// openMappedModel maps the initial model and creates the two aggregate
+// buffers of the same size in dir
+func openMappedModel(initialPath, dir string) (*mappedModel, error) {
+	m := &mappedModel{}
+	var err error
+	if storage.IsRemote(initialPath) {
+		m.initial, err = storage.ReadFile(initialPath)
+	} else {
+		m.initial, err = mapReadOnly(initialPath)
+		m.mapped = err == nil
+		if err == nil {
+			err = storage.VerifyChecksum(initialPath, m.initial)
+		}
+	}
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err := transport.CheckWeights(m.initial, 0); err != nil {
+		m.Close()
+		return nil, fmt.Errorf("invalid initial model %s: %v", initialPath, err)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		m.Close()
+		return nil, err
+	}
+	for i := range m.buffers {
+		m.paths[i] = filepath.Join(dir, fmt.Sprintf("global_model.%d.mmap", i))
+		if m.buffers[i], err = mapFile(m.paths[i], len(m.initial)); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Next returns the buffer the next aggregate is written to. The buffer
+// returned by the previous call stays untouched until the one after.
+func (m *mappedModel) Next() []byte {
+	buf := m.buffers[m.next]
+	m.next = 1 - m.next
+	return buf
+}
+
+// Close unmaps the model and removes the aggregate buffers. No request may
+// read a slice of the model afterwards.
+func (m *mappedModel) Close() error {
+	var errs []error
+	if m.mapped {
+		errs = append(errs, unmapFile(m.initial))
+	}
+	for i, buf := range m.buffers {
+		if buf == nil {
+			continue
+		}
+		errs = append(errs, unmapFile(buf), os.Remove(m.paths[i]))
+	}
+	*m = mappedModel{}
+	return errors.Join(errs...)
+}
+
+// putFloat stores v as the i-th little-endian float32 of an encoded model
+func putFloat(buf []byte, i int, v float32) {
+	binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+}
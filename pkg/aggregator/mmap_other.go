@@ -0,0 +1,20 @@
+//go:build !unix
+
+package aggregator
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func mapReadOnly(path string) ([]byte, error) {
+	return nil, fmt.Errorf("memory-mapped models are not supported on %s", runtime.GOOS)
+}
+
+func mapFile(path string, size int) ([]byte, error) {
+	return nil, fmt.Errorf("memory-mapped models are not supported on %s", runtime.GOOS)
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package aggregator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedModelAlternatesBuffers(t *testing.T) {
+	dir := t.TempDir()
+	initialPath := filepath.Join(dir, "init.pt")
+	initial := encodeFloats(1, 2, 3)
+	if err := os.WriteFile(initialPath, initial, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := openMappedModel(initialPath, filepath.Join(dir, "save"))
+	if err != nil {
+		t.Fatalf("openMappedModel() error = %v", err)
+	}
+	if !bytes.Equal(m.initial, initial) {
+		t.Errorf("initial = %v, want %v", m.initial, initial)
+	}
+
+	first := m.Next()
+	copy(first, encodeFloats(4, 5, 6))
+	second := m.Next()
+	if len(second) != len(initial) || &second[0] == &first[0] {
+		t.Fatal("consecutive rounds share an aggregate buffer")
+	}
+	if third := m.Next(); &third[0] != &first[0] {
+		t.Error("buffers do not alternate")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "save", "*.mmap")); len(files) != 0 {
+		t.Errorf("Close() left %v behind", files)
+	}
+}
+
+func TestEncodedAveragesMatchDecoded(t *testing.T) {
+	updates := []weightedUpdate{
+		{[]float32{1, 2, 3}, 10, "b"},
+		{[]float32{4, -5, 6}, 30, "a"},
+	}
+	want, _ := weightedAverage(updates, 3)
+
+	dst := encodeFloats(9, 9, 9) // stale aggregate of an earlier round
+	weightedAverageInto(updates, dst)
+	if !bytes.Equal(dst, encodeModel(want)) {
+		t.Errorf("weightedAverageInto() = %v, want %v", dst, encodeModel(want))
+	}
+
+	sum := newRunningSum(3)
+	for _, upd := range updates {
+		sum.Add(encodeModel(upd.weights), upd.numSamples)
+	}
+	streamed, _ := sum.Average()
+	sum.AverageInto(dst)
+	if !bytes.Equal(dst, encodeModel(streamed)) {
+		t.Errorf("AverageInto() = %v, want %v", dst, encodeModel(streamed))
+	}
+
+	weightedAverageInto(nil, dst)
+	if !bytes.Equal(dst, make([]byte, 12)) {
+		t.Errorf("average of no updates = %v, want zeros", dst)
+	}
+}
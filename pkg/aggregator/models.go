@@ -0,0 +1,99 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// ModelRegistrar registers the aggregated models of a run in the model
+// registry. Each version's parent is the version registered before it, so a
+// run forms one lineage chain. A run that warm-started from an earlier version
+// of the same model continues that version's chain.
+type ModelRegistrar struct {
+	registry  *registry.Registry
+	name      string
+	algorithm string
+	mode      string
+	parent    int
+	metadata  map[string]string // recorded with every version; holds initial_model_from
+	run       *RunRecorder
+}
+
+// NewModelRegistrar returns nil when the plan does not enable the registry.
+// A registry that cannot be opened is logged and recorded in the run report.
+func NewModelRegistrar(plan *federation.FLPlan, run *RunRecorder) *ModelRegistrar {
+	if !plan.Registry.Enabled {
+		return nil
+	}
+
+	reg, err := registry.Open(registry.Config(plan.Registry))
+	if err != nil {
+		log.Printf("Model registry disabled: %v", err)
+		run.RecordError(fmt.Errorf("failed to open model registry: %w", err))
+		return nil
+	}
+
+	name := plan.Registry.Name
+	if name == "" {
+		name = federationIDFor(plan)
+	}
+	algorithm := plan.Algorithm.Name
+	if algorithm == "" {
+		algorithm = "fedavg"
+	}
+
+	registrar := &ModelRegistrar{
+		registry:  reg,
+		name:      name,
+		algorithm: algorithm,
+		mode:      string(plan.Mode),
+		run:       run,
+	}
+	if plan.InitialModelFrom != "" {
+		registrar.metadata = map[string]string{"initial_model_from": plan.InitialModelFrom}
+		if source, version, ok := pinnedModelRef(plan.InitialModelFrom); ok && source == name {
+			registrar.parent = version
+		}
+	}
+	return registrar
+}
+
+// Register stores model as the next version. Failures do not stop the run.
+func (m *ModelRegistrar) Register(round int, model []float32) {
+	if m == nil {
+		return
+	}
+	m.RegisterEncoded(round, encodeModel(model))
+}
+
+// RegisterEncoded stores an encoded model as the next version
+func (m *ModelRegistrar) RegisterEncoded(round int, data []byte) {
+	if m == nil {
+		return
+	}
+
+	version, err := m.registry.Register(m.name, data, registry.RegisterOptions{
+		Parent:    m.parent,
+		Algorithm: m.algorithm,
+		Mode:      m.mode,
+		Round:     round,
+		Metadata:  m.metadata,
+	})
+	if err != nil {
+		log.Printf("Failed to register round %d model: %v", round, err)
+		m.run.RecordError(fmt.Errorf("failed to register round %d model: %w", round, err))
+		return
+	}
+
+	m.parent = version.Version
+	log.Printf("Registered model %s v%d (round %d, sha256 %.12s)", version.Name, version.Version, round, version.Checksum)
+}
+
+// encodeModel serializes weights as little-endian float32 values
+func encodeModel(model []float32) []byte {
+	return transport.EncodeWeights(model)
+}
@@ -0,0 +1,35 @@
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+)
+
+func TestModelRegistrar(t *testing.T) {
+	dir := t.TempDir()
+	plan := &federation.FLPlan{
+		Algorithm:  federation.AlgorithmConfig{Name: "fedprox"},
+		Monitoring: federation.MonitoringConfig{FederationID: "fed-1"},
+		Registry:   federation.RegistryConfig{Enabled: true, Dir: dir},
+	}
+	models := NewModelRegistrar(plan, NewRunRecorder(plan, NewDropTracker(plan)))
+	models.Register(1, []float32{1, 2})
+	models.Register(2, []float32{3, 4})
+
+	versions, err := registry.New(registry.NewLocalBackend(dir)).List("fed-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Registered %d versions, want 2", len(versions))
+	}
+	if latest := versions[1]; latest.Parent != 1 || latest.Round != 2 || latest.Algorithm != "fedprox" || latest.Size != 8 {
+		t.Errorf("Unexpected latest version: %+v", latest)
+	}
+
+	// Without a registry in the plan, registering is a no-op
+	var disabled *ModelRegistrar
+	disabled.Register(1, []float32{1})
+}
@@ -3,23 +3,32 @@ package aggregator
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net"
-	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	pb "github.com/ishaileshpant/fl-go/api"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
 	"google.golang.org/grpc"
 )
 
 // ModularAggregator implements a flexible aggregator that can use different algorithms
 type ModularAggregator struct {
 	pb.UnimplementedFederatedLearningServer
+	*runControl
 	plan         *federation.FLPlan
+	attestation  *attestationGate // admits collaborators by their attestation evidence
 	algorithm    AggregationAlgorithm
 	mu           sync.Mutex
 	updates      []ClientUpdate
@@ -27,9 +36,27 @@ type ModularAggregator struct {
 	currentRound int
 	srv          *grpc.Server
 	globalModel  []float32
+	modelHash    string      // hash of the model the current sync round started from
+	bases        *baseModels // global models handed out in async mode, to clip and score updates against
 	lastUpdate   time.Time
-	stopChan     chan struct{}
+	lastDelta    float64       // relative model change of the latest async aggregation
+	done         chan string   // async completion reason, sent by the aggregation loop
+	tuned        chan struct{} // signals a runtime change of the async config
+	triggered    chan struct{} // signals an admin request to aggregate now
+	delays       *delayController
 	isAsync      bool
+	submitted    map[string]bool
+	quorum       *updateQuorum // reached once a sync round holds every expected update
+	staleness    StalenessFunc // weights async updates by their staleness
+	clusters     *clusterSet   // global models of clustered federations; nil unless clustering
+	drops        *DropTracker
+	inclusion    *inclusionTracker // async updates received and aggregated per collaborator
+	reporter     *UpdateReporter
+	archive      *UpdateArchive
+	signer       *security.ModelSigner // nil unless the plan signs models
+	run          *RunRecorder
+	models       *ModelRegistrar
+	events       *eventBroker
 }
 
 // NewModularAggregator creates a new modular aggregator with the specified algorithm
@@ -49,20 +76,55 @@ func NewModularAggregator(plan *federation.FLPlan) (*ModularAggregator, error) {
 
 	// Determine if this is async mode
 	isAsync := plan.Mode == federation.ModeAsync
+	if isAsync && plan.Clustering.Enabled {
+		return nil, fmt.Errorf("clustering is only supported in sync mode")
+	}
+
+	staleness, err := NewStalenessFunc(plan.AsyncConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staleness weighting: %v", err)
+	}
 
+	drops := NewDropTracker(plan)
+	run := NewRunRecorder(plan, drops)
 	aggregator := &ModularAggregator{
+		runControl:   newRunControl(),
 		plan:         plan,
+		attestation:  newAttestationGate(plan),
 		algorithm:    algorithm,
+		bases:        newBaseModels(),
 		updates:      make([]ClientUpdate, 0),
 		currentRound: 0,
 		isAsync:      isAsync,
-		stopChan:     make(chan struct{}),
+		done:         make(chan string, 1),
+		tuned:        make(chan struct{}, 1),
+		triggered:    make(chan struct{}, 1),
+		delays:       newDelayController(),
+		submitted:    make(map[string]bool),
+		staleness:    staleness,
+		drops:        drops,
+		inclusion:    newInclusionTracker(plan),
+		reporter:     NewUpdateReporter(plan),
+		archive:      NewUpdateArchive(plan),
+		run:          run,
+		models:       NewModelRegistrar(plan, run),
+		events:       newEventBroker(),
 	}
 
 	return aggregator, nil
 }
 
-func (a *ModularAggregator) Start(ctx context.Context) error {
+func (a *ModularAggregator) Start(ctx context.Context) (err error) {
+	a.run.Start()
+	defer func() { a.run.Finish(err) }()
+	ctx, stop := a.runControl.start(ctx)
+	defer stop()
+
+	if a.signer, err = loadSigner(a.plan); err != nil {
+		return err
+	}
+	a.run.SetSigner(a.signer)
+
 	log.Printf("Starting Modular Aggregator with %s algorithm in %s mode",
 		a.algorithm.GetName(), a.plan.Mode)
 
@@ -72,6 +134,7 @@ func (a *ModularAggregator) Start(ctx context.Context) error {
 		ModelSize:       a.modelSize,
 		Hyperparameters: a.plan.Algorithm.Hyperparameters,
 		Mode:            a.plan.Mode,
+		DistillTask:     a.plan.Tasks.Distill,
 	}
 
 	if err := a.algorithm.Initialize(algConfig); err != nil {
@@ -89,18 +152,39 @@ func (a *ModularAggregator) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to reinitialize algorithm with model size: %v", err)
 	}
 
+	if a.plan.Clustering.Enabled {
+		a.clusters, err = newClusterSet(a.plan.Clustering, a.algorithmType(), algConfig, a.globalModel)
+		if err != nil {
+			return fmt.Errorf("failed to set up clustering: %v", err)
+		}
+		log.Printf("Clustering collaborators into %d global models, re-clustering every %d rounds",
+			len(a.clusters.models), a.clusters.every)
+	}
+
 	// Log algorithm hyperparameters
 	hyperparams := a.algorithm.GetHyperparameters()
 	log.Printf("Algorithm hyperparameters: %+v", hyperparams)
 
+	if a.isAsync {
+		startMetricsServer(ctx, a.plan, metricsHandlers{a.drops, a.inclusion})
+	} else {
+		startMetricsServer(ctx, a.plan, a.drops)
+	}
+
 	// Start gRPC server
 	lis, err := net.Listen("tcp", a.plan.Aggregator.Address)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	a.srv = grpc.NewServer()
+	a.srv = grpc.NewServer(transport.ServerOptions(a.plan.GRPC)...)
 	pb.RegisterFederatedLearningServer(a.srv, a)
+	defer a.srv.Stop()
+	stopAdmin, err := serveAdmin(a.plan, nil, a)
+	if err != nil {
+		return err
+	}
+	defer stopAdmin()
 
 	// Start server in background
 	go func() {
@@ -119,7 +203,7 @@ func (a *ModularAggregator) Start(ctx context.Context) error {
 }
 
 func (a *ModularAggregator) loadInitialModel() error {
-	data, err := os.ReadFile(a.plan.InitialModel)
+	data, err := storage.ReadFile(a.plan.InitialModel)
 	if err != nil {
 		log.Printf("Warning: Could not read initial model %s: %v", a.plan.InitialModel, err)
 		// Create a dummy model for testing
@@ -129,13 +213,11 @@ func (a *ModularAggregator) loadInitialModel() error {
 	}
 
 	// Determine model size from file
-	a.modelSize = len(data) / 4 // 4 bytes per float32
-	a.globalModel = make([]float32, a.modelSize)
-
-	// Load initial weights
-	for i := 0; i < a.modelSize; i++ {
-		a.globalModel[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	if a.globalModel, err = transport.DecodeWeights(data, 0); err != nil {
+		return fmt.Errorf("invalid initial model %s: %v", a.plan.InitialModel, err)
 	}
+	a.modelSize = len(a.globalModel)
+	a.run.RecordModel(0, transport.ModelHash(data))
 
 	log.Printf("Loaded initial model with %d parameters", a.modelSize)
 	return nil
@@ -147,42 +229,80 @@ func (a *ModularAggregator) runSyncFederation(ctx context.Context) error {
 
 	// Run federated learning for specified rounds
 	for round := 1; round <= a.plan.Rounds; round++ {
-		a.currentRound = round
+		if err := a.runControl.wait(ctx, round); err != nil {
+			log.Printf("Stopping before round %d: %v", round, err)
+			a.events.Close("the aggregator is shutting down")
+			a.srv.Stop()
+			return err
+		}
 		log.Printf("Starting round %d/%d with %s algorithm", round, a.plan.Rounds, a.algorithm.GetName())
 
 		// Reset updates for new round
 		a.mu.Lock()
+		a.currentRound = round
+		a.modelHash = transport.ModelHash(encodeModel(a.globalModel))
+		modelHash := a.modelHash
+		if a.clusters != nil {
+			// Each cluster trains on its own model
+			a.clusters.startRound()
+			modelHash = ""
+		}
 		a.updates = make([]ClientUpdate, 0)
+		a.submitted = make(map[string]bool)
+		a.quorum = newUpdateQuorum(len(a.plan.Collaborators))
+		quorum := a.quorum
+		if a.clusters == nil {
+			a.archive.StartRound(round, string(a.algorithmType()), a.plan.Algorithm.Hyperparameters, clipNorm(a.plan), encodeModel(a.globalModel))
+		}
 		a.mu.Unlock()
+		a.events.RoundStarted(round, modelHash, nil)
 
 		// Wait for all collaborators to submit updates
-		log.Printf("Waiting for %d collaborators to submit updates...", len(a.plan.Collaborators))
-		for {
-			a.mu.Lock()
-			updateCount := len(a.updates)
-			a.mu.Unlock()
-
-			if updateCount >= len(a.plan.Collaborators) {
-				log.Printf("Received updates from all %d collaborators", updateCount)
-				break
-			}
-
-			log.Printf("Received %d/%d updates, waiting...", updateCount, len(a.plan.Collaborators))
-			time.Sleep(2 * time.Second)
+		if err := quorum.Wait(ctx, round, a.updateCount); err != nil {
+			a.events.Close("the aggregator is shutting down")
+			a.srv.Stop()
+			return err
 		}
 
 		// Perform aggregation using the selected algorithm
 		log.Printf("Aggregating updates for round %d using %s", round, a.algorithm.GetName())
+		// Aggregate in collaborator order, so that reruns produce the same model
+		a.mu.Lock()
+		updates := slices.Clone(a.updates)
+		a.mu.Unlock()
+		slices.SortStableFunc(updates, func(x, y ClientUpdate) int {
+			return strings.Compare(x.CollaboratorID, y.CollaboratorID)
+		})
+		for _, update := range updates {
+			a.archive.Add(round, update.CollaboratorID, int64(update.NumSamples), int64(update.LocalSteps), encodeModel(update.Weights))
+		}
+		aggregationStart := time.Now()
+		maxNorm := clipNorm(a.plan)
 		a.mu.Lock()
-		newModel, err := a.algorithm.Aggregate(a.updates, a.globalModel)
+		clipped := clipUpdates(updates, func(update ClientUpdate) []float32 { return a.modelFor(update.CollaboratorID) }, maxNorm)
 		a.mu.Unlock()
+		var newModel []float32
+		var err error
+		if a.clusters != nil {
+			newModel, err = a.aggregateClusters(round, updates)
+		} else if newModel, err = a.algorithm.Aggregate(updates, a.globalModel); err == nil {
+			base := func(ClientUpdate) []float32 { return a.globalModel }
+			reportContributions(a.reporter, round, scoreContributions(round, updates, base, a.globalModel, newModel))
+		}
+		if err == nil {
+			for _, update := range updates {
+				a.reporter.Record(update)
+			}
+		}
 
 		if err != nil {
 			return fmt.Errorf("aggregation failed in round %d: %v", round, err)
 		}
+		a.reporter.RecordAggregation(aggregationMetrics(round, string(a.algorithmType()), aggregationStart, len(updates), clipped, maxNorm))
 
 		// Update global model
 		a.globalModel = newModel
+		a.run.SetFinalModel(newModel)
 
 		// Save aggregated model
 		if err := a.saveModel(round); err != nil {
@@ -193,7 +313,8 @@ func (a *ModularAggregator) runSyncFederation(ctx context.Context) error {
 	}
 
 	log.Printf("All %d rounds completed successfully with %s", a.plan.Rounds, a.algorithm.GetName())
-	a.srv.Stop()
+	a.events.Close("all rounds completed")
+	stopServing(a.srv)
 	return nil
 }
 
@@ -201,91 +322,202 @@ func (a *ModularAggregator) runAsyncFederation(ctx context.Context) error {
 	log.Printf("Running asynchronous federation with %s algorithm", a.algorithm.GetName())
 
 	// Start async aggregation goroutine
-	go a.asyncAggregationLoop()
+	stopLoop := startAsyncLoop(ctx, a.asyncAggregationLoop)
+
+	// Run until a completion criterion is met or the run is stopped
+	deadline, stopDeadline := asyncDeadline(a.plan.AsyncConfig.MaxDuration)
+	defer stopDeadline()
 
-	// Keep server running
+	reason := CompletionStopped
 	select {
+	case reason = <-a.done:
+	case <-deadline:
+		reason = CompletionMaxDuration
 	case <-ctx.Done():
-		close(a.stopChan)
-		a.srv.Stop()
-		return ctx.Err()
+		if errors.Is(context.Cause(ctx), ErrAborted) {
+			reason = CompletionAborted
+		}
+	}
+	stopLoop()
+	a.events.Close(fmt.Sprintf("the federation completed (%s)", reason))
+	a.srv.Stop()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := saveFinalAsyncModel(a.plan, a.run, a.models, a.currentRound, a.globalModel, reason); err != nil {
+		return err
 	}
+	return context.Cause(ctx)
 }
 
-func (a *ModularAggregator) asyncAggregationLoop() {
-	ticker := time.NewTicker(time.Duration(a.plan.AsyncConfig.AggregationDelay) * time.Second)
+func (a *ModularAggregator) asyncAggregationLoop(ctx context.Context) {
+	a.mu.Lock()
+	ticker := time.NewTicker(a.delays.Next(a.plan.AsyncConfig))
+	a.mu.Unlock()
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
+			// A paused federation keeps collecting updates for when it resumes
 			a.mu.Lock()
-			updateCount := len(a.updates)
+			ready := a.aggregatable() >= a.plan.AsyncConfig.MinUpdates && !a.paused()
 			a.mu.Unlock()
 
-			if updateCount >= a.plan.AsyncConfig.MinUpdates {
-				a.performAsyncAggregation()
+			if ready && a.aggregateUntilDone() {
+				return
+			}
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-a.triggered:
+			if a.aggregateUntilDone() {
+				return
 			}
-		case <-a.stopChan:
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-a.tuned:
+			a.mu.Lock()
+			ticker.Reset(a.delays.Next(a.plan.AsyncConfig))
+			a.mu.Unlock()
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (a *ModularAggregator) performAsyncAggregation() {
+// aggregateUntilDone aggregates the pending updates and reports whether the
+// new model completed the federation
+func (a *ModularAggregator) aggregateUntilDone() bool {
+	if !a.performAsyncAggregation() {
+		return false
+	}
+	a.mu.Lock()
+	reason := asyncCompletion(a.plan.AsyncConfig, a.currentRound, a.lastDelta)
+	a.mu.Unlock()
+
+	if reason == "" {
+		return false
+	}
+	a.done <- reason
+	return true
+}
+
+// performAsyncAggregation aggregates the pending updates and reports whether
+// a new global model was produced
+func (a *ModularAggregator) performAsyncAggregation() bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	if len(a.updates) == 0 {
-		return
+		return false
 	}
 
-	log.Printf("Performing async aggregation with %d updates using %s",
-		len(a.updates), a.algorithm.GetName())
+	// Updates beyond a collaborator's share wait for the next aggregation
+	selected, deferred := selectFair(len(a.updates), a.collaboratorAt, a.plan.AsyncConfig.MaxPerCollaborator)
+	batch := make([]ClientUpdate, len(selected))
+	for k, i := range selected {
+		batch[k] = a.updates[i]
+	}
+	pending := make([]ClientUpdate, len(deferred))
+	for k, i := range deferred {
+		pending[k] = a.updates[i]
+	}
+	a.updates = pending
+
+	log.Printf("Performing async aggregation with %d updates (%d deferred) using %s",
+		len(batch), len(pending), a.algorithm.GetName())
 
 	// Calculate staleness for each update
 	currentTime := time.Now()
 	validUpdates := make([]ClientUpdate, 0)
 
-	for _, update := range a.updates {
+	for _, update := range batch {
 		staleness := int(currentTime.Sub(update.Timestamp).Seconds())
 		update.Staleness = staleness
 
 		if staleness <= a.plan.AsyncConfig.MaxStaleness {
+			// Mildly stale updates are kept but down-weighted
+			update.StalenessWeight = a.staleness(staleness)
 			validUpdates = append(validUpdates, update)
 		} else {
-			log.Printf("Dropping stale update from %s (staleness: %d)",
-				update.CollaboratorID, staleness)
+			a.drops.Record(update.CollaboratorID, update.Round, monitoring.DropReasonStale,
+				fmt.Sprintf("staleness %ds exceeds max %ds", staleness, a.plan.AsyncConfig.MaxStaleness))
 		}
 	}
 
 	if len(validUpdates) == 0 {
 		log.Printf("No valid updates to aggregate")
-		return
+		return false
+	}
+	staleness := make([]int, len(validUpdates))
+	waits := make([]time.Duration, len(validUpdates))
+	for k, update := range validUpdates {
+		staleness[k] = update.Staleness
+		waits[k] = currentTime.Sub(update.Timestamp)
 	}
+	a.run.RecordStaleness(staleness)
+	a.delays.Observe(a.plan.AsyncConfig, waits)
+
+	// Clip the updates against the models they were trained on before
+	// aggregating them
+	maxNorm := clipNorm(a.plan)
+	clipped := clipUpdates(validUpdates, updateBase, maxNorm)
 
 	// Perform aggregation using the selected algorithm
 	newModel, err := a.algorithm.Aggregate(validUpdates, a.globalModel)
 	if err != nil {
 		log.Printf("Async aggregation failed: %v", err)
-		return
+		// The updates are retried by the next aggregation
+		a.updates = append(validUpdates, a.updates...)
+		return false
+	}
+	included := make([]string, len(validUpdates))
+	for k, update := range validUpdates {
+		included[k] = update.CollaboratorID
+	}
+	a.inclusion.Included(included)
+	a.run.RecordAggregated(included)
+	metrics := aggregationMetrics(a.currentRound+1, string(a.algorithmType()), currentTime, len(validUpdates), clipped, maxNorm)
+	a.delays.annotate(&metrics)
+	a.reporter.RecordAggregation(metrics)
+	reportContributions(a.reporter, a.currentRound+1, scoreContributions(a.currentRound+1, validUpdates, updateBase, a.globalModel, newModel))
+
+	for _, update := range validUpdates {
+		a.reporter.Record(update)
 	}
 
 	// Update global model
+	a.lastDelta = modelDelta(a.globalModel, newModel)
 	a.globalModel = newModel
 	a.currentRound++
 	a.lastUpdate = currentTime
+	a.run.SetFinalModel(newModel)
+
+	a.events.ModelChanged(a.currentRound, transport.ModelHash(encodeModel(newModel)))
 
 	// Save updated model
 	if err := a.saveAsyncModel(); err != nil {
 		log.Printf("Failed to save async model: %v", err)
+		a.run.RecordError(fmt.Errorf("failed to save async round %d model: %v", a.currentRound, err))
 	} else {
 		log.Printf("Async round %d complete using %s, model saved",
 			a.currentRound, a.algorithm.GetName())
 	}
+	return true
+}
 
-	// Clear processed updates
-	a.updates = make([]ClientUpdate, 0)
+// collaboratorAt returns the collaborator of the i-th queued update
+func (a *ModularAggregator) collaboratorAt(i int) string {
+	return a.updates[i].CollaboratorID
+}
+
+// aggregatable returns how many of the queued async updates the next
+// aggregation takes
+func (a *ModularAggregator) aggregatable() int {
+	selected, _ := selectFair(len(a.updates), a.collaboratorAt, a.plan.AsyncConfig.MaxPerCollaborator)
+	return len(selected)
 }
 
 func (a *ModularAggregator) saveModel(round int) error {
@@ -299,9 +531,20 @@ func (a *ModularAggregator) saveModel(round int) error {
 		outputPath = fmt.Sprintf("save/round_%d_model.pt", round)
 	}
 
-	if err := os.WriteFile(outputPath, buf, 0600); err != nil {
+	if err := storage.WriteFile(outputPath, buf); err != nil {
 		return err
 	}
+	if a.clusters != nil {
+		for c, model := range a.clusters.models {
+			if err := storage.WriteFile(clusterModelPath(outputPath, c), encodeModel(model)); err != nil {
+				return err
+			}
+		}
+	}
+	a.run.RecordRound(round, outputPath)
+	a.run.RecordModel(round, transport.ModelHash(buf))
+	a.archive.FinishRound(round, outputPath, transport.ModelHash(buf))
+	a.models.Register(round, a.globalModel)
 
 	log.Printf("Model saved to %s", outputPath)
 	return nil
@@ -315,7 +558,90 @@ func (a *ModularAggregator) saveAsyncModel() error {
 
 	outputPath := fmt.Sprintf("save/async_%s_round_%d_model.pt",
 		a.algorithm.GetName(), a.currentRound)
-	return os.WriteFile(outputPath, buf, 0600)
+	if err := storage.WriteFile(outputPath, buf); err != nil {
+		return err
+	}
+	a.run.RecordRound(a.currentRound, outputPath)
+	a.run.RecordModel(a.currentRound, transport.ModelHash(buf))
+	return nil
+}
+
+// Report returns the run summary
+func (a *ModularAggregator) Report() *RunReport {
+	return a.run.Report()
+}
+
+// Manifest returns the reproducibility manifest of the run
+func (a *ModularAggregator) Manifest() *RunManifest {
+	return a.run.Manifest()
+}
+
+// RoundState returns the state of the sync round being collected, or the
+// updates queued for the next async aggregation
+func (a *ModularAggregator) RoundState() RoundState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.isAsync {
+		return syncRoundState(a.currentRound, a.modelHash, collaboratorIDs(a.plan, true), a.submitted)
+	}
+	state := RoundState{Round: a.currentRound + 1, Expected: a.plan.AsyncConfig.MinUpdates}
+	if a.globalModel != nil {
+		state.ModelHash = transport.ModelHash(encodeModel(a.globalModel))
+	}
+	for _, update := range a.updates {
+		state.Received = append(state.Received, update.CollaboratorID)
+	}
+	return state
+}
+
+// AggregateNow ends the current sync round with the updates it holds, or has
+// the async aggregation loop aggregate the queued updates right away
+func (a *ModularAggregator) AggregateNow() (RoundState, error) {
+	if !a.isAsync {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		state := syncRoundState(a.currentRound, a.modelHash, collaboratorIDs(a.plan, true), a.submitted)
+		return state, a.quorum.Release(len(a.submitted))
+	}
+	state := a.RoundState()
+	if len(state.Received) == 0 {
+		return state, fmt.Errorf("no updates are queued")
+	}
+	select {
+	case a.triggered <- struct{}{}:
+	default:
+	}
+	return state, nil
+}
+
+// AsyncConfig returns the current async settings
+func (a *ModularAggregator) AsyncConfig() federation.AsyncConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.plan.AsyncConfig
+}
+
+// TuneAsync changes the async settings of the running federation. Only
+// async federations can be tuned.
+func (a *ModularAggregator) TuneAsync(tuning AsyncTuning) (federation.AsyncConfig, error) {
+	if !a.isAsync {
+		return federation.AsyncConfig{}, fmt.Errorf("aggregator is not running in async mode")
+	}
+
+	a.mu.Lock()
+	if err := tuning.apply(&a.plan.AsyncConfig); err != nil {
+		a.mu.Unlock()
+		return federation.AsyncConfig{}, err
+	}
+	config := a.plan.AsyncConfig
+	a.mu.Unlock()
+
+	select {
+	case a.tuned <- struct{}{}:
+	default:
+	}
+	logAsyncTuning(config, a.events)
+	return config, nil
 }
 
 // gRPC service implementations
@@ -323,55 +649,143 @@ func (a *ModularAggregator) saveAsyncModel() error {
 func (a *ModularAggregator) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
 	log.Printf("Collaborator %s joining %s federation with %s algorithm",
 		req.CollaboratorId, a.plan.Mode, a.algorithm.GetName())
+	capabilities, err := negotiate(ctx, a.plan, a.attestation, serverCapabilities(transport.FeatureLocalPrivacy, transport.FeatureEvents), req)
+	if err != nil {
+		return nil, err
+	}
 
-	// Return current global model
-	buf := make([]byte, 4*a.modelSize)
-	for i, v := range a.globalModel {
-		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	// Return the model the collaborator trains on
+	a.mu.Lock()
+	model := a.modelFor(req.CollaboratorId)
+	buf := encodeModel(model)
+	if a.isAsync {
+		a.bases.add(transport.ModelHash(buf), model)
 	}
+	a.mu.Unlock()
 
-	return &pb.JoinResponse{InitialModel: buf}, nil
+	return &pb.JoinResponse{InitialModel: buf, Capabilities: capabilities, ModelSignature: signEncoded(a.signer, buf)}, nil
 }
 
 func (a *ModularAggregator) SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error) {
-	floats := make([]float32, len(upd.ModelWeights)/4)
-	for i := range floats {
-		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(upd.ModelWeights[i*4:]))
+	if err := a.attestation.authorize(upd.CollaboratorId); err != nil {
+		return nil, err
+	}
+	return a.accept(upd), nil
+}
+
+// WatchEvents streams round starts, or new models in async mode, to a
+// collaborator until the federation ends
+func (a *ModularAggregator) WatchEvents(req *pb.WatchRequest, stream pb.FederatedLearning_WatchEventsServer) error {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return err
+	}
+	return a.events.Watch(stream.Context(), req.CollaboratorId, stream.Send)
+}
+
+// SubmitPartialAggregate accepts the aggregate of an edge aggregator as an
+// update weighted by the edge's total sample count
+func (a *ModularAggregator) SubmitPartialAggregate(ctx context.Context, partial *pb.PartialAggregate) (*pb.Ack, error) {
+	log.Printf("Received partial aggregate from edge %s (%d updates, %d samples)",
+		partial.AggregatorId, partial.NumUpdates, partial.NumSamples)
+	return a.accept(&pb.ModelUpdate{
+		CollaboratorId: partial.AggregatorId,
+		ModelWeights:   partial.ModelWeights,
+		NumSamples:     partial.NumSamples,
+		Round:          partial.Round,
+	}), nil
+}
+
+// accept validates an update and queues it for aggregation
+func (a *ModularAggregator) accept(upd *pb.ModelUpdate) *pb.Ack {
+	collaboratorID := upd.CollaboratorId
+	floats, reason, err := decodeUpdate(upd.ModelWeights, a.modelSize)
+	if err != nil {
+		return a.drops.Reject(collaboratorID, a.currentRound, reason, err.Error())
 	}
 
 	update := ClientUpdate{
-		CollaboratorID: upd.CollaboratorId,
-		Weights:        floats,
-		Timestamp:      time.Now(),
-		Round:          a.currentRound,
-		NumSamples:     100,  // Default value - could be passed from client
-		LearningRate:   0.01, // Default value - could be passed from client
+		CollaboratorID:    collaboratorID,
+		Weights:           floats,
+		Timestamp:         time.Now(),
+		Round:             a.currentRound,
+		NumSamples:        int(sampleCount(upd.NumSamples)),
+		LocalSteps:        int(upd.LocalSteps),
+		LearningRate:      0.01, // Default value - could be passed from client
+		ImageDigest:       upd.ImageDigest,
+		DPClipNorm:        upd.Privacy.GetClipNorm(),
+		DPNoiseMultiplier: upd.Privacy.GetNoiseMultiplier(),
+		TrainingMetrics:   upd.Metrics,
 	}
 
 	a.mu.Lock()
+	if !a.isAsync {
+		if a.currentRound == 0 {
+			a.mu.Unlock()
+			return notStarted()
+		}
+		if reason, detail := checkRound(upd, a.currentRound, a.roundModelHash(collaboratorID)); reason != "" {
+			a.mu.Unlock()
+			return a.drops.Reject(collaboratorID, update.Round, reason, detail)
+		}
+		if a.submitted[collaboratorID] {
+			a.mu.Unlock()
+			return a.drops.Reject(collaboratorID, update.Round, monitoring.DropReasonDuplicate,
+				fmt.Sprintf("collaborator already submitted an update for round %d", update.Round))
+		}
+		a.submitted[collaboratorID] = true
+	} else {
+		base, ok := a.bases.base(upd.BaseModelHash, a.globalModel)
+		if !ok {
+			a.mu.Unlock()
+			return a.drops.Reject(collaboratorID, update.Round, monitoring.DropReasonStale, staleBase(upd.BaseModelHash))
+		}
+		update.Base = base
+		maxPending := a.plan.AsyncConfig.MaxPendingPerCollaborator
+		if i := oldestQueued(len(a.updates), a.collaboratorAt, collaboratorID, maxPending); i >= 0 {
+			replaced := a.updates[i]
+			a.updates = slices.Delete(a.updates, i, i+1)
+			a.drops.Record(collaboratorID, replaced.Round, monitoring.DropReasonRateLimited,
+				fmt.Sprintf("replaced by a newer update, %d updates of the collaborator already queued", maxPending))
+		}
+		a.inclusion.Received(collaboratorID)
+		a.delays.Arrived(update.Timestamp)
+	}
 	a.updates = append(a.updates, update)
 	updateCount := len(a.updates)
+	if !a.isAsync {
+		a.quorum.Received(updateCount)
+	}
 	a.mu.Unlock()
+	a.run.RecordUpdate(collaboratorID, update.Round)
+	a.run.RecordTraining(update.TrainingMetrics)
 
 	mode := "sync"
 	if a.isAsync {
 		mode = "async"
 	}
 
-	log.Printf("Received %s update %d from %s (round %d) for %s algorithm",
-		mode, updateCount, upd.CollaboratorId, a.currentRound, a.algorithm.GetName())
+	logging.Infof("Received %s update %d from %s (round %d) for %s algorithm",
+		mode, updateCount, collaboratorID, a.currentRound, a.algorithm.GetName())
 
-	return &pb.Ack{Success: true}, nil
+	return &pb.Ack{Success: true}
 }
 
 func (a *ModularAggregator) GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error) {
+	if err := a.attestation.authorize(req.CollaboratorId); err != nil {
+		return nil, err
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	// Return current global model
-	buf := make([]byte, 4*a.modelSize)
-	for i, v := range a.globalModel {
-		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	// Return the model the collaborator trains on
+	model := a.modelFor(req.CollaboratorId)
+	buf := encodeModel(model)
+	modelHash := transport.ModelHash(buf)
+	if a.isAsync {
+		a.bases.add(modelHash, model)
+	}
+	if resp := notModified(req, modelHash, clampInt32(a.currentRound)); resp != nil {
+		return resp, nil
 	}
 
 	log.Printf("Providing latest %s model to %s (round %d)",
@@ -387,7 +801,80 @@ func (a *ModularAggregator) GetLatestModel(ctx context.Context, req *pb.GetModel
 	}
 
 	return &pb.GetModelResponse{
-		ModelWeights: buf,
-		CurrentRound: currentRound,
+		ModelWeights:   buf,
+		CurrentRound:   currentRound,
+		ModelHash:      modelHash,
+		ModelSignature: a.signer.Sign(modelHash),
 	}, nil
 }
+
+// algorithmType returns the plan's aggregation algorithm
+func (a *ModularAggregator) algorithmType() AlgorithmType {
+	if a.plan.Algorithm.Name == "" {
+		return FedAvg
+	}
+	return AlgorithmType(a.plan.Algorithm.Name)
+}
+
+// modelFor returns the model a collaborator trains on: its cluster's model
+// when clustering, otherwise the global model. The caller holds a.mu.
+func (a *ModularAggregator) modelFor(collaboratorID string) []float32 {
+	if a.clusters != nil {
+		return a.clusters.models[a.clusters.clusterOf(collaboratorID)]
+	}
+	return a.globalModel
+}
+
+// roundModelHash returns the hash of the model a collaborator's update for
+// the current round must be trained on. The caller holds a.mu.
+func (a *ModularAggregator) roundModelHash(collaboratorID string) string {
+	if a.clusters != nil && a.clusters.hashes != nil {
+		return a.clusters.hashes[a.clusters.clusterOf(collaboratorID)]
+	}
+	return a.modelHash
+}
+
+// aggregateClusters aggregates each cluster's updates into its model,
+// re-clustering the collaborators first when due, and returns the model of
+// the largest cluster, which is saved as the round's model
+func (a *ModularAggregator) aggregateClusters(round int, updates []ClientUpdate) ([]float32, error) {
+	models, members, err := a.clusters.aggregate(round, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Score each cluster's updates against its change; updates were trained
+	// on the model of the cluster their collaborator was in
+	base := func(update ClientUpdate) []float32 {
+		return a.clusters.models[a.clusters.clusterOf(update.CollaboratorID)]
+	}
+	var scores []monitoring.ContributionScore
+	for c := range models {
+		var clusterUpdates []ClientUpdate
+		for _, update := range updates {
+			if members[update.CollaboratorID] == c {
+				clusterUpdates = append(clusterUpdates, update)
+			}
+		}
+		scores = append(scores, scoreContributions(round, clusterUpdates, base, a.clusters.models[c], models[c])...)
+	}
+	reportContributions(a.reporter, round, scores)
+
+	a.mu.Lock()
+	a.clusters.models, a.clusters.members = models, members
+	largest, sizes := a.clusters.largest(), a.clusters.sizes()
+	a.mu.Unlock()
+
+	if a.clusters.reclusters(round) {
+		log.Printf("Round %d clusters: %s", round, formatClusters(members, len(models)))
+		a.reporter.RecordClusters(round, members, sizes)
+	}
+	return models[largest], nil
+}
+
+// updateCount returns the number of updates the aggregator holds
+func (a *ModularAggregator) updateCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.updates)
+}
@@ -0,0 +1,47 @@
+package aggregator
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelThreshold is the number of parameters from which element-wise loops
+// are split across goroutines. Smaller models aggregate faster serially.
+const parallelThreshold = 1 << 16
+
+// parallelFor calls fn on consecutive chunks [lo, hi) that cover [0, n), one
+// goroutine per CPU when n is large. Each index belongs to exactly one chunk,
+// so fn may write its chunk of a slice without locking.
+func parallelFor(n int, fn func(lo, hi int)) {
+	workers := min(runtime.GOMAXPROCS(0), n/(parallelThreshold/4))
+	if n < parallelThreshold || workers < 2 {
+		fn(0, n)
+		return
+	}
+
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += chunk {
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(lo, min(lo+chunk, n))
+	}
+	wg.Wait()
+}
+
+// accumulate adds weights[k] * updates[k].Weights to dst element-wise. Within
+// each element the updates are added in order, so the result does not depend
+// on how the model was split into chunks.
+func accumulate(dst []float32, updates []ClientUpdate, weights []float32) {
+	parallelFor(len(dst), func(lo, hi int) {
+		for k, update := range updates {
+			weight := weights[k]
+			end := min(hi, len(update.Weights))
+			for i := lo; i < end; i++ {
+				dst[i] += weight * update.Weights[i]
+			}
+		}
+	})
+}
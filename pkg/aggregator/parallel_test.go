@@ -0,0 +1,53 @@
+package aggregator
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelForCoversEachIndexOnce(t *testing.T) {
+	for _, n := range []int{0, 10, parallelThreshold, 5*parallelThreshold + 3} {
+		counts := make([]atomic.Int32, n)
+		parallelFor(n, func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				counts[i].Add(1)
+			}
+		})
+		for i := range counts {
+			if c := counts[i].Load(); c != 1 {
+				t.Fatalf("n=%d: index %d visited %d times", n, i, c)
+			}
+		}
+	}
+}
+
+func TestAccumulateMatchesSerialSum(t *testing.T) {
+	n := 3*parallelThreshold + 11
+	rng := rand.New(rand.NewPCG(1, 2))
+	updates := make([]ClientUpdate, 5)
+	weights := make([]float32, len(updates))
+	for k := range updates {
+		updates[k].Weights = make([]float32, n)
+		for i := range updates[k].Weights {
+			updates[k].Weights[i] = rng.Float32()
+		}
+		weights[k] = rng.Float32()
+	}
+	updates[4].Weights = updates[4].Weights[:n/2] // short updates only cover their prefix
+
+	want := make([]float32, n)
+	for k, update := range updates {
+		for i, v := range update.Weights {
+			want[i] += weights[k] * v
+		}
+	}
+	got := make([]float32, n)
+	accumulate(got, updates, weights)
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("element %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package aggregator
+
+import "sync"
+
+// roundPipeline runs the work that follows the aggregation of a sync round,
+// such as scoring contributions, registering the model and reporting the
+// round, in the background. The next round starts, and its model goes out to
+// collaborators, while that work runs.
+//
+// Rounds are finished one at a time and in order. Go blocks while the
+// previous round is still being finished, so at most one round lags behind,
+// and the aggregate it reads is not overwritten by a later round.
+type roundPipeline struct {
+	stages chan func()
+	done   chan struct{}
+	closed sync.Once
+}
+
+func newRoundPipeline() *roundPipeline {
+	p := &roundPipeline{stages: make(chan func()), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		for stage := range p.stages {
+			stage()
+		}
+	}()
+	return p
+}
+
+// Go finishes a round in the background once the previous one is finished
+func (p *roundPipeline) Go(stage func()) {
+	p.stages <- stage
+}
+
+// Close waits for the rounds being finished. It may be called more than
+// once.
+func (p *roundPipeline) Close() {
+	p.closed.Do(func() { close(p.stages) })
+	<-p.done
+}
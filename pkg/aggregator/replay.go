@@ -0,0 +1,323 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// roundIndexFile is the index of an archived round in its directory
+const roundIndexFile = "round.json"
+
+// ArchivedRound indexes the raw client updates of a sync round, kept so that
+// the round can be re-aggregated offline
+type ArchivedRound struct {
+	Round           int                    `json:"round"`
+	Algorithm       string                 `json:"algorithm"`
+	Hyperparameters map[string]interface{} `json:"hyperparameters,omitempty"` // of the algorithm
+	ClipNorm        float64                `json:"clip_norm,omitempty"`       // updates were clipped to this norm before aggregation
+	BaseModelHash   string                 `json:"base_model_hash"`           // model the round's updates were trained on, in base.bin
+	Updates         []ArchivedUpdate       `json:"updates"`
+	ModelPath       string                 `json:"model_path,omitempty"` // set once the round is aggregated
+	ModelHash       string                 `json:"model_hash,omitempty"`
+}
+
+// ArchivedUpdate is a raw client update of an archived round
+type ArchivedUpdate struct {
+	CollaboratorID string `json:"collaborator_id"`
+	File           string `json:"file"` // encoded weights, relative to the round's directory
+	Hash           string `json:"hash"`
+	NumSamples     int64  `json:"num_samples"`
+	LocalSteps     int64  `json:"local_steps,omitempty"`
+}
+
+// UpdateArchive writes the raw updates of each sync round under
+// <dir>/round_<N>/ when the plan sets aggregator.updates_dir. Archiving is
+// best effort: failures are logged and do not stop the federation.
+type UpdateArchive struct {
+	dir    string
+	mu     sync.Mutex
+	rounds map[int]*ArchivedRound
+}
+
+// NewUpdateArchive returns the archive of the plan, or nil when the plan does
+// not keep updates. The methods of a nil archive do nothing.
+func NewUpdateArchive(plan *federation.FLPlan) *UpdateArchive {
+	if plan.Aggregator.UpdatesDir == "" {
+		return nil
+	}
+	return &UpdateArchive{dir: plan.Aggregator.UpdatesDir, rounds: make(map[int]*ArchivedRound)}
+}
+
+// StartRound archives the model a round starts from
+func (a *UpdateArchive) StartRound(round int, algorithm string, hyperparameters map[string]interface{}, clipNorm float64, base []byte) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	index := &ArchivedRound{
+		Round:           round,
+		Algorithm:       algorithm,
+		Hyperparameters: hyperparameters,
+		ClipNorm:        clipNorm,
+		BaseModelHash:   transport.ModelHash(base),
+		Updates:         []ArchivedUpdate{},
+	}
+	a.rounds[round] = index
+	dir := roundDir(a.dir, round)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		log.Printf("Failed to archive round %d: %v", round, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, "base.bin"), base, 0600); err != nil {
+		log.Printf("Failed to archive the base model of round %d: %v", round, err)
+	}
+	a.writeIndex(index)
+}
+
+// Add archives an accepted update of a round. data is the update's encoded
+// weights before clipping.
+func (a *UpdateArchive) Add(round int, collaboratorID string, numSamples, localSteps int64, data []byte) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	index, ok := a.rounds[round]
+	if !ok {
+		return
+	}
+	// Files are numbered rather than named after collaborator IDs, which
+	// are not necessarily valid file names
+	update := ArchivedUpdate{
+		CollaboratorID: collaboratorID,
+		File:           fmt.Sprintf("update_%d.bin", len(index.Updates)),
+		Hash:           transport.ModelHash(data),
+		NumSamples:     numSamples,
+		LocalSteps:     localSteps,
+	}
+	if err := os.WriteFile(filepath.Join(roundDir(a.dir, round), update.File), data, 0600); err != nil {
+		log.Printf("Failed to archive the round %d update of %s: %v", round, collaboratorID, err)
+		return
+	}
+	index.Updates = append(index.Updates, update)
+	a.writeIndex(index)
+}
+
+// FinishRound records the aggregate a round produced
+func (a *UpdateArchive) FinishRound(round int, modelPath, modelHash string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	index, ok := a.rounds[round]
+	if !ok {
+		return
+	}
+	index.ModelPath = modelPath
+	index.ModelHash = modelHash
+	a.writeIndex(index)
+	delete(a.rounds, round)
+}
+
+func (a *UpdateArchive) writeIndex(index *ArchivedRound) {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(roundDir(a.dir, index.Round), roundIndexFile), data, 0600)
+	}
+	if err != nil {
+		log.Printf("Failed to write the archive index of round %d: %v", index.Round, err)
+	}
+}
+
+func roundDir(dir string, round int) string {
+	return filepath.Join(dir, fmt.Sprintf("round_%d", round))
+}
+
+// LoadArchivedRound reads the index of an archived round
+func LoadArchivedRound(dir string, round int) (*ArchivedRound, error) {
+	data, err := os.ReadFile(filepath.Join(roundDir(dir, round), roundIndexFile)) // #nosec G304 - archives are chosen by the operator
+	if err != nil {
+		return nil, err
+	}
+	var index ArchivedRound
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid archive index of round %d: %v", round, err)
+	}
+	return &index, nil
+}
+
+// ReaggregateOptions selects how an archived round is re-aggregated
+type ReaggregateOptions struct {
+	Algorithm       string                 // default: the round's algorithm
+	Hyperparameters map[string]interface{} // override the round's hyperparameters when the algorithm is unchanged
+	Exclude         []string               // collaborators whose updates are left out
+}
+
+// ReaggregateResult describes a re-aggregated round
+type ReaggregateResult struct {
+	Round             int      `json:"round"`
+	Algorithm         string   `json:"algorithm"`
+	Included          []string `json:"included"`
+	Excluded          []string `json:"excluded,omitempty"`
+	ModelHash         string   `json:"model_hash"`
+	OriginalAlgorithm string   `json:"original_algorithm"`
+	OriginalModelHash string   `json:"original_model_hash,omitempty"`
+	// L2 distance of the new aggregate from the original one, and from the
+	// model the round started from
+	DistanceFromOriginal *float64 `json:"distance_from_original,omitempty"`
+	DistanceFromBase     float64  `json:"distance_from_base"`
+}
+
+// Reaggregate recomputes the aggregate of an archived round, with another
+// algorithm or without some collaborators, and returns the encoded model.
+// Algorithms with server state, like the momentum of fedavgm, start the
+// round from fresh state.
+func Reaggregate(dir string, round int, opts ReaggregateOptions) (*ReaggregateResult, []byte, error) {
+	index, err := LoadArchivedRound(dir, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if index.ModelHash == "" {
+		return nil, nil, fmt.Errorf("round %d was not aggregated", round)
+	}
+
+	base, err := readArchived(dir, round, "base.bin", index.BaseModelHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseModel, _, err := decodeUpdate(base, len(base)/4)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid base model: %v", err)
+	}
+
+	result := &ReaggregateResult{
+		Round:             round,
+		Algorithm:         index.Algorithm,
+		OriginalAlgorithm: index.Algorithm,
+		OriginalModelHash: index.ModelHash,
+	}
+	hyperparameters := index.Hyperparameters
+	if opts.Algorithm != "" && opts.Algorithm != index.Algorithm {
+		result.Algorithm = opts.Algorithm
+		hyperparameters = nil
+	}
+	if len(opts.Hyperparameters) > 0 {
+		hyperparameters = mergeHyperparameters(hyperparameters, opts.Hyperparameters)
+	}
+
+	// Aggregate in collaborator order, like the aggregators do
+	updates := make([]ClientUpdate, 0, len(index.Updates))
+	for _, archived := range index.Updates {
+		if slices.Contains(opts.Exclude, archived.CollaboratorID) {
+			result.Excluded = append(result.Excluded, archived.CollaboratorID)
+			continue
+		}
+		data, err := readArchived(dir, round, archived.File, archived.Hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		weights, _, err := decodeUpdate(data, len(baseModel))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid update of %s: %v", archived.CollaboratorID, err)
+		}
+		updates = append(updates, ClientUpdate{
+			CollaboratorID: archived.CollaboratorID,
+			Weights:        weights,
+			Round:          round,
+			NumSamples:     int(archived.NumSamples),
+			LocalSteps:     int(archived.LocalSteps),
+			LearningRate:   0.01,
+		})
+		result.Included = append(result.Included, archived.CollaboratorID)
+	}
+	if len(updates) == 0 {
+		return nil, nil, fmt.Errorf("no updates of round %d are left to aggregate", round)
+	}
+	slices.SortStableFunc(updates, func(x, y ClientUpdate) int {
+		return strings.Compare(x.CollaboratorID, y.CollaboratorID)
+	})
+	slices.Sort(result.Included)
+	clipUpdates(updates, func(ClientUpdate) []float32 { return baseModel }, index.ClipNorm)
+
+	algorithm, err := CreateAggregationAlgorithm(AlgorithmType(result.Algorithm))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := algorithm.Initialize(AlgorithmConfig{
+		AlgorithmName:   result.Algorithm,
+		ModelSize:       len(baseModel),
+		Hyperparameters: hyperparameters,
+		Mode:            federation.ModeSync,
+	}); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize %s: %v", result.Algorithm, err)
+	}
+	model, err := algorithm.Aggregate(updates, baseModel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("aggregation failed: %v", err)
+	}
+
+	buf := encodeModel(model)
+	result.ModelHash = transport.ModelHash(buf)
+	result.DistanceFromBase = l2Distance(model, baseModel)
+	// The original aggregate may have been moved or overwritten since
+	if original, err := storage.ReadFile(index.ModelPath); err == nil && transport.ModelHash(original) == index.ModelHash {
+		if originalModel, _, err := decodeUpdate(original, len(model)); err == nil {
+			distance := l2Distance(model, originalModel)
+			result.DistanceFromOriginal = &distance
+		}
+	}
+	return result, buf, nil
+}
+
+// readArchived reads a file of an archived round and checks its hash
+func readArchived(dir string, round int, file, hash string) ([]byte, error) {
+	if file != filepath.Base(file) {
+		return nil, fmt.Errorf("invalid archived file name %q", file)
+	}
+	data, err := os.ReadFile(filepath.Join(roundDir(dir, round), file)) // #nosec G304 - names are checked above
+	if err != nil {
+		return nil, err
+	}
+	if transport.ModelHash(data) != hash {
+		return nil, fmt.Errorf("archived file %s of round %d is corrupt", file, round)
+	}
+	return data, nil
+}
+
+// mergeHyperparameters returns base with the values of overrides
+func mergeHyperparameters(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// l2Distance returns the L2 norm of x - y
+func l2Distance(x, y []float32) float64 {
+	var sum float64
+	for i := range x {
+		d := float64(x[i] - y[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
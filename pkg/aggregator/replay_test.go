@@ -0,0 +1,84 @@
+package aggregator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func TestReaggregate(t *testing.T) {
+	dir := t.TempDir()
+	archive := NewUpdateArchive(&federation.FLPlan{Aggregator: federation.AggregatorEntry{UpdatesDir: dir}})
+	if NewUpdateArchive(&federation.FLPlan{}) != nil {
+		t.Error("archive of a plan without updates_dir is not nil")
+	}
+
+	base := encodeModel([]float32{0, 0})
+	archive.StartRound(1, "fedavg", nil, 0, base)
+	archive.Add(1, "collab-b", 30, 0, encodeModel([]float32{3, 3}))
+	archive.Add(1, "collab-a", 10, 0, encodeModel([]float32{1, 1}))
+	archive.Add(1, "../outlier", 10, 0, encodeModel([]float32{100, -100}))
+
+	if _, _, err := Reaggregate(dir, 1, ReaggregateOptions{}); err == nil {
+		t.Error("re-aggregating an unfinished round succeeded")
+	}
+
+	original := encodeModel([]float32{22, -18}) // (1*10 + 3*30 + 100*10) / 50
+	modelPath := filepath.Join(dir, "model.pt")
+	if err := os.WriteFile(modelPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+	archive.FinishRound(1, modelPath, transport.ModelHash(original))
+
+	result, model, err := Reaggregate(dir, 1, ReaggregateOptions{})
+	if err != nil {
+		t.Fatalf("Reaggregate: %v", err)
+	}
+	if result.ModelHash != result.OriginalModelHash || result.DistanceFromOriginal == nil || *result.DistanceFromOriginal != 0 {
+		t.Errorf("unchanged re-aggregation = %+v", result)
+	}
+	if transport.ModelHash(model) != result.ModelHash {
+		t.Error("returned model does not match the result's hash")
+	}
+
+	result, model, err = Reaggregate(dir, 1, ReaggregateOptions{Exclude: []string{"../outlier"}})
+	if err != nil {
+		t.Fatalf("Reaggregate without the outlier: %v", err)
+	}
+	if want := encodeModel([]float32{2.5, 2.5}); !slices.Equal(model, want) {
+		t.Errorf("model without the outlier = %v, want %v", model, want)
+	}
+	if !slices.Equal(result.Included, []string{"collab-a", "collab-b"}) || !slices.Equal(result.Excluded, []string{"../outlier"}) {
+		t.Errorf("included %v, excluded %v", result.Included, result.Excluded)
+	}
+	if result.DistanceFromOriginal == nil || *result.DistanceFromOriginal == 0 {
+		t.Errorf("distance from the original = %v", result.DistanceFromOriginal)
+	}
+
+	result, _, err = Reaggregate(dir, 1, ReaggregateOptions{Algorithm: "fedprox", Hyperparameters: map[string]interface{}{"mu": 0.5}})
+	if err != nil || result.Algorithm != "fedprox" || result.OriginalAlgorithm != "fedavg" {
+		t.Errorf("fedprox re-aggregation = %+v, %v", result, err)
+	}
+
+	if _, _, err := Reaggregate(dir, 1, ReaggregateOptions{Exclude: []string{"collab-a", "collab-b", "../outlier"}}); err == nil {
+		t.Error("re-aggregating without any updates succeeded")
+	}
+	if _, _, err := Reaggregate(dir, 1, ReaggregateOptions{Algorithm: "unknown"}); err == nil {
+		t.Error("re-aggregating with an unknown algorithm succeeded")
+	}
+	if _, _, err := Reaggregate(dir, 2, ReaggregateOptions{}); err == nil {
+		t.Error("re-aggregating a round that was not archived succeeded")
+	}
+
+	// Archived files are checked against their hashes
+	if err := os.WriteFile(filepath.Join(roundDir(dir, 1), "update_0.bin"), encodeModel([]float32{9, 9}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Reaggregate(dir, 1, ReaggregateOptions{}); err == nil {
+		t.Error("re-aggregating a corrupt archive succeeded")
+	}
+}
@@ -0,0 +1,437 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// defaultReportPath is used when the plan does not set report_path
+const defaultReportPath = "save/run_report.json"
+
+// RunStatus is the final state of a federation run
+type RunStatus string
+
+const (
+	RunCompleted RunStatus = "completed"
+	RunAborted   RunStatus = "aborted"
+	RunFailed    RunStatus = "failed"
+)
+
+// Participation summarizes one collaborator's contribution to a run
+type Participation struct {
+	CollaboratorID     string    `json:"collaborator_id"`
+	UpdatesAccepted    int       `json:"updates_accepted"`
+	UpdatesDropped     int       `json:"updates_dropped"`
+	UpdatesAggregated  int       `json:"updates_aggregated,omitempty"` // async runs: accepted updates an aggregation included
+	RoundsParticipated int       `json:"rounds_participated"`
+	LastUpdate         time.Time `json:"last_update,omitempty"`
+}
+
+// Evaluation is the metrics an evaluator reported for a round's model
+type Evaluation struct {
+	CollaboratorID string             `json:"collaborator_id"`
+	Round          int                `json:"round"`
+	Metrics        map[string]float64 `json:"metrics"`
+}
+
+// RunReport is the machine-readable summary written when a federation ends
+type RunReport struct {
+	FederationID     string             `json:"federation_id"`
+	Mode             federation.FLMode  `json:"mode"`
+	Algorithm        string             `json:"algorithm"`
+	Status           RunStatus          `json:"status"`
+	CompletionReason string             `json:"completion_reason,omitempty"`  // async runs: which completion criterion ended the run
+	InitialModelFrom string             `json:"initial_model_from,omitempty"` // registry model the run started from, name@vN
+	StartTime        time.Time          `json:"start_time"`
+	EndTime          time.Time          `json:"end_time"`
+	DurationSeconds  float64            `json:"duration_seconds"`
+	RoundsPlanned    int                `json:"rounds_planned"`
+	RoundsCompleted  int                `json:"rounds_completed"`
+	Participation    []Participation    `json:"participation"`
+	FinalMetrics     map[string]float64 `json:"final_metrics"`
+	Evaluations      []Evaluation       `json:"evaluations,omitempty"` // dispatched evaluate task results
+	Artifacts        []string           `json:"artifacts"`
+	Errors           []string           `json:"errors"`
+}
+
+// RunRecorder collects the data for a RunReport while an aggregator runs
+type RunRecorder struct {
+	mu            sync.Mutex
+	report        RunReport
+	participation map[string]*Participation
+	roundsSeen    map[string]map[int]bool
+	drops         *DropTracker
+	manifest      RunManifest
+	rounds        map[int]*ManifestRound // round -> manifest entry
+	lastModel     string                 // hash of the latest recorded model, the parent of the next
+	signer        *security.ModelSigner  // signs recorded models; nil unless the plan signs models
+	board         *roundBoard            // nil unless the plan enables TensorBoard or MLflow
+}
+
+// NewRunRecorder creates a recorder for the federation described by plan
+func NewRunRecorder(plan *federation.FLPlan, drops *DropTracker) *RunRecorder {
+	algorithm := plan.Algorithm.Name
+	if algorithm == "" {
+		algorithm = "fedavg"
+	}
+
+	roundsPlanned := plan.Rounds
+	if plan.Mode == federation.ModeAsync {
+		roundsPlanned = 0
+	}
+
+	federationID := federationIDFor(plan)
+	r := &RunRecorder{
+		report: RunReport{
+			FederationID:     federationID,
+			Mode:             plan.Mode,
+			Algorithm:        algorithm,
+			InitialModelFrom: plan.InitialModelFrom,
+			RoundsPlanned:    roundsPlanned,
+			StartTime:        time.Now(),
+			FinalMetrics:     make(map[string]float64),
+			Artifacts:        []string{},
+			Errors:           []string{},
+		},
+		participation: make(map[string]*Participation),
+		roundsSeen:    make(map[string]map[int]bool),
+		drops:         drops,
+		manifest:      newManifest(plan, federationID, algorithm),
+		rounds:        make(map[int]*ManifestRound),
+	}
+
+	// Collaborators that never submit still appear in the participation table
+	for _, collab := range plan.Collaborators {
+		r.participation[collab.ID] = &Participation{CollaboratorID: collab.ID}
+	}
+
+	board, errs := newRoundBoard(plan, federationID)
+	for _, err := range errs {
+		log.Printf("Metrics export disabled: %v", err)
+		r.report.Errors = append(r.report.Errors, err.Error())
+	}
+	r.board = board
+
+	return r
+}
+
+// Start marks the beginning of the run
+func (r *RunRecorder) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.StartTime = time.Now()
+	if err := r.board.start(); err != nil {
+		log.Printf("MLflow tracking disabled: %v", err)
+		r.report.Errors = append(r.report.Errors, err.Error())
+	}
+}
+
+// RecordUpdate records an accepted update from a collaborator
+func (r *RunRecorder) RecordUpdate(collaboratorID string, round int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, exists := r.participation[collaboratorID]
+	if !exists {
+		p = &Participation{CollaboratorID: collaboratorID}
+		r.participation[collaboratorID] = p
+	}
+	p.UpdatesAccepted++
+	p.LastUpdate = time.Now()
+	r.board.recordUpdate()
+
+	if r.roundsSeen[collaboratorID] == nil {
+		r.roundsSeen[collaboratorID] = make(map[int]bool)
+	}
+	if !r.roundsSeen[collaboratorID][round] {
+		r.roundsSeen[collaboratorID][round] = true
+		p.RoundsParticipated++
+		entry := r.manifestRound(round)
+		entry.Participants = append(entry.Participants, collaboratorID)
+	}
+}
+
+// RecordAggregated records the collaborators of the updates an async
+// aggregation included
+func (r *RunRecorder) RecordAggregated(collaboratorIDs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range collaboratorIDs {
+		if p, exists := r.participation[id]; exists {
+			p.UpdatesAggregated++
+		}
+	}
+}
+
+// RecordRound records a completed round and the model artifact it produced
+func (r *RunRecorder) RecordRound(round int, artifactPath string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if round > r.report.RoundsCompleted {
+		r.report.RoundsCompleted = round
+	}
+	if artifactPath != "" {
+		r.report.Artifacts = append(r.report.Artifacts, artifactPath)
+	}
+	r.board.completeRound(round)
+}
+
+// RecordTraining records the local training metrics sent with an accepted
+// update
+func (r *RunRecorder) RecordTraining(metrics map[string]float64) {
+	if len(metrics) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.board.recordTraining(metrics)
+}
+
+// RecordStaleness records the staleness of the updates an async aggregation
+// combined
+func (r *RunRecorder) RecordStaleness(staleness []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.board.recordStaleness(staleness)
+}
+
+// RecordEvaluation records the metrics of a dispatched evaluate task
+func (r *RunRecorder) RecordEvaluation(collaboratorID string, round int, metrics map[string]float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Evaluations = append(r.report.Evaluations, Evaluation{
+		CollaboratorID: collaboratorID,
+		Round:          round,
+		Metrics:        metrics,
+	})
+	r.board.recordEvaluation(round, metrics)
+}
+
+// RecordError records a non-fatal error that occurred during the run
+func (r *RunRecorder) RecordError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Errors = append(r.report.Errors, err.Error())
+}
+
+// SetFinalModel records summary statistics of the latest global model
+func (r *RunRecorder) SetFinalModel(model []float32) {
+	r.setModelStats(len(model), func(i int) float32 { return model[i] })
+}
+
+// SetFinalEncodedModel records summary statistics of the latest global model
+// from its encoding
+func (r *RunRecorder) SetFinalEncodedModel(data []byte) {
+	r.setModelStats(len(data)/4, func(i int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	})
+}
+
+func (r *RunRecorder) setModelStats(n int, value func(i int) float32) {
+	var sum, sumSquares float64
+	for i := 0; i < n; i++ {
+		v := float64(value(i))
+		sum += v
+		sumSquares += v * v
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.FinalMetrics["model_parameters"] = float64(n)
+	r.report.FinalMetrics["model_l2_norm"] = math.Sqrt(sumSquares)
+	if n > 0 {
+		r.report.FinalMetrics["model_mean"] = sum / float64(n)
+	}
+}
+
+// SetCompletionReason records why an async run completed
+func (r *RunRecorder) SetCompletionReason(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.CompletionReason = reason
+}
+
+// Finish sets the final status from the error returned by the run. Stopping an
+// async run (which has no planned rounds) through its context is a normal
+// completion rather than an abort, unless it was aborted through the admin API.
+func (r *RunRecorder) Finish(err error) {
+	r.mu.Lock()
+	r.report.EndTime = time.Now()
+	r.report.DurationSeconds = r.report.EndTime.Sub(r.report.StartTime).Seconds()
+
+	switch {
+	case err == nil:
+		r.report.Status = RunCompleted
+	case errors.Is(err, ErrAborted):
+		r.report.Status = RunAborted
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		if r.report.RoundsPlanned == 0 {
+			r.report.Status = RunCompleted
+		} else {
+			r.report.Status = RunAborted
+		}
+	default:
+		r.report.Status = RunFailed
+		r.report.Errors = append(r.report.Errors, err.Error())
+	}
+
+	// The latest artifact is the final model
+	board, status, finalModel := r.board, r.report.Status, ""
+	if n := len(r.report.Artifacts); n > 0 {
+		finalModel = r.report.Artifacts[n-1]
+	}
+	r.board = nil
+	r.mu.Unlock()
+
+	// Closing waits for the tracking server, so it is done without the lock
+	board.close(status, finalModel)
+}
+
+// Report returns a snapshot of the run summary
+func (r *RunRecorder) Report() *RunReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.report
+	report.Artifacts = append([]string{}, r.report.Artifacts...)
+	report.Errors = append([]string{}, r.report.Errors...)
+	report.Evaluations = append([]Evaluation(nil), r.report.Evaluations...)
+	report.FinalMetrics = make(map[string]float64, len(r.report.FinalMetrics))
+	for key, value := range r.report.FinalMetrics {
+		report.FinalMetrics[key] = value
+	}
+
+	report.Participation = make([]Participation, 0, len(r.participation))
+	totalUpdates, totalDropped := 0, 0
+	for id, p := range r.participation {
+		entry := *p
+		if r.drops != nil {
+			entry.UpdatesDropped = r.drops.CollaboratorTotal(id)
+		}
+		totalUpdates += entry.UpdatesAccepted
+		totalDropped += entry.UpdatesDropped
+		report.Participation = append(report.Participation, entry)
+	}
+	sort.Slice(report.Participation, func(i, j int) bool {
+		return report.Participation[i].CollaboratorID < report.Participation[j].CollaboratorID
+	})
+
+	report.FinalMetrics["total_updates"] = float64(totalUpdates)
+	report.FinalMetrics["dropped_updates"] = float64(totalDropped)
+
+	return &report
+}
+
+// PublishReport writes the report to the plan's report path and, when
+// monitoring is enabled, posts it to the monitoring server as an event.
+// It returns the path the report was written to.
+func PublishReport(plan *federation.FLPlan, report *RunReport) (string, error) {
+	path := plan.ReportPath
+	if path == "" {
+		path = defaultReportPath
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode run report: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write run report: %v", err)
+	}
+
+	if plan.Monitoring.Enabled && plan.Monitoring.MonitoringServerURL != "" {
+		if err := postReport(plan.Monitoring.MonitoringServerURL, plan.Monitoring.APIKey, report); err != nil {
+			return path, fmt.Errorf("failed to post run report to monitoring: %v", err)
+		}
+	}
+
+	return path, nil
+}
+
+func postReport(serverURL, apiKey string, report *RunReport) error {
+	// Round-trip through JSON so the event data uses the report's field names
+	var reportData map[string]interface{}
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(encoded, &reportData); err != nil {
+		return err
+	}
+
+	level := "info"
+	if report.Status != RunCompleted {
+		level = "error"
+	}
+
+	message := fmt.Sprintf("Federation run %s after %d rounds", report.Status, report.RoundsCompleted)
+	if report.CompletionReason != "" {
+		message += fmt.Sprintf(" (%s)", report.CompletionReason)
+	}
+
+	event := monitoring.MonitoringEvent{
+		FederationID: report.FederationID,
+		Type:         monitoring.MetricTypeRunReport,
+		Timestamp:    report.EndTime,
+		Source:       "aggregator",
+		Level:        level,
+		Message:      message,
+		Data:         reportData,
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	return postMonitoringJSON(client, strings.TrimRight(serverURL, "/")+"/api/v1/events", apiKey, body)
+}
+
+// postMonitoringJSON posts a JSON body to the monitoring server, authenticating
+// with apiKey when one is configured
+func postMonitoringJSON(client *http.Client, url, apiKey string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("monitoring server returned %d", resp.StatusCode)
+	}
+	return nil
+}
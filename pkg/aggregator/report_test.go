@@ -0,0 +1,202 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestRunRecorderStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     federation.FLMode
+		err      error
+		expected RunStatus
+	}{
+		{"sync completed", federation.ModeSync, nil, RunCompleted},
+		{"sync interrupted", federation.ModeSync, context.Canceled, RunAborted},
+		{"async stopped", federation.ModeAsync, context.Canceled, RunCompleted},
+		{"failure", federation.ModeSync, errors.New("disk full"), RunFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := NewRunRecorder(&federation.FLPlan{Mode: tt.mode, Rounds: 3}, nil)
+			recorder.Finish(tt.err)
+
+			if status := recorder.Report().Status; status != tt.expected {
+				t.Errorf("Finish(%v) status = %s, want %s", tt.err, status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunRecorderParticipation(t *testing.T) {
+	plan := &federation.FLPlan{
+		Rounds:        2,
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+		ReportPath:    filepath.Join(t.TempDir(), "report.json"),
+	}
+	drops := NewDropTracker(plan)
+	recorder := NewRunRecorder(plan, drops)
+
+	recorder.RecordUpdate("c1", 1)
+	recorder.RecordUpdate("c1", 2)
+	drops.Record("c2", 1, monitoring.DropReasonStale, "too old")
+	recorder.RecordRound(1, "save/round_1_model.pt")
+	recorder.SetFinalModel([]float32{3, 4})
+	recorder.Finish(nil)
+
+	path, err := PublishReport(plan, recorder.Report())
+	if err != nil {
+		t.Fatalf("PublishReport() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	if len(report.Participation) != 2 {
+		t.Fatalf("Expected 2 participation rows, got %d", len(report.Participation))
+	}
+	c1, c2 := report.Participation[0], report.Participation[1]
+	if c1.RoundsParticipated != 2 || c1.UpdatesAccepted != 2 {
+		t.Errorf("c1 participation = %+v", c1)
+	}
+	if c2.UpdatesAccepted != 0 || c2.UpdatesDropped != 1 {
+		t.Errorf("c2 participation = %+v", c2)
+	}
+	if report.FinalMetrics["model_l2_norm"] != 5 {
+		t.Errorf("model_l2_norm = %v, want 5", report.FinalMetrics["model_l2_norm"])
+	}
+	if report.RoundsCompleted != 1 || len(report.Artifacts) != 1 {
+		t.Errorf("rounds=%d artifacts=%v", report.RoundsCompleted, report.Artifacts)
+	}
+}
+
+func TestRunRecorderTensorBoard(t *testing.T) {
+	dir := t.TempDir()
+	plan := &federation.FLPlan{
+		Rounds:        1,
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+		Monitoring:    federation.MonitoringConfig{TensorBoard: true, TensorBoardDir: dir},
+	}
+	recorder := NewRunRecorder(plan, nil)
+
+	recorder.RecordUpdate("c1", 1)
+	recorder.RecordTraining(map[string]float64{"loss": 0.5})
+	recorder.RecordStaleness([]int{0, 3})
+	recorder.RecordRound(1, "")
+	recorder.RecordEvaluation("c2", 1, map[string]float64{"accuracy": 0.9})
+	recorder.Finish(nil)
+
+	files, err := filepath.Glob(filepath.Join(dir, recorder.Report().FederationID, "events.out.tfevents.*"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one event file, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"participation/updates", "participation/fraction", "train/loss",
+		"staleness/mean", "staleness/max", "round/duration_seconds", "eval/accuracy"} {
+		if !bytes.Contains(data, []byte(tag)) {
+			t.Errorf("event file is missing %s", tag)
+		}
+	}
+
+	// Recording after the run finished must not write to the closed file
+	recorder.RecordRound(2, "")
+}
+
+func TestRunRecorderMLflow(t *testing.T) {
+	var mu sync.Mutex
+	var batches []map[string]interface{}
+	var status interface{}
+	artifacts := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]interface{}
+		json.Unmarshal(body, &request)
+		switch r.URL.Path {
+		case "/api/2.0/mlflow/experiments/get-by-name":
+			w.Write([]byte(`{"experiment": {"experiment_id": "1"}}`))
+		case "/api/2.0/mlflow/runs/create":
+			w.Write([]byte(`{"run": {"info": {"run_id": "r1", "artifact_uri": "mlflow-artifacts:/1/r1/artifacts"}}}`))
+		case "/api/2.0/mlflow/runs/log-batch":
+			batches = append(batches, request)
+			w.Write([]byte("{}"))
+		case "/api/2.0/mlflow/runs/update":
+			status = request["status"]
+			w.Write([]byte("{}"))
+		default:
+			artifacts[r.URL.Path] = string(body)
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	modelPath := filepath.Join(t.TempDir(), "final_model.pt")
+	if err := os.WriteFile(modelPath, []byte("weights"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	plan := &federation.FLPlan{
+		Rounds:        3,
+		Collaborators: []federation.Collaborator{{ID: "c1"}},
+		Algorithm:     federation.AlgorithmConfig{Name: "fedprox", Hyperparameters: map[string]interface{}{"mu": 0.01}},
+		MLflow:        federation.MLflowConfig{TrackingURI: server.URL},
+	}
+	recorder := NewRunRecorder(plan, nil)
+	recorder.Start()
+	recorder.RecordUpdate("c1", 1)
+	recorder.RecordTraining(map[string]float64{"loss": 0.5})
+	recorder.RecordRound(1, modelPath)
+	recorder.Finish(errors.New("aggregation failed"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 {
+		t.Fatalf("sent %d batches, want params and round 1 metrics", len(batches))
+	}
+	params := make(map[string]interface{})
+	for _, p := range batches[0]["params"].([]interface{}) {
+		param := p.(map[string]interface{})
+		params[param["key"].(string)] = param["value"]
+	}
+	if params["algorithm.hyperparameters.mu"] != "0.01" || params["rounds"] != "3" {
+		t.Errorf("params = %v", params)
+	}
+	metrics := make(map[string]interface{})
+	for _, m := range batches[1]["metrics"].([]interface{}) {
+		metric := m.(map[string]interface{})
+		metrics[metric["key"].(string)] = metric["value"]
+	}
+	if metrics["train/loss"] != 0.5 || metrics["participation/updates"] != 1.0 {
+		t.Errorf("metrics = %v", metrics)
+	}
+	if artifacts["/api/2.0/mlflow-artifacts/artifacts/1/r1/artifacts/model/final_model.pt"] != "weights" {
+		t.Errorf("final model was not logged, artifacts = %v", artifacts)
+	}
+	if status != "FAILED" {
+		t.Errorf("run status = %v, want FAILED", status)
+	}
+}
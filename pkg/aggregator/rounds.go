@@ -0,0 +1,134 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"google.golang.org/grpc"
+)
+
+// roundRetryAfter is how long collaborators wait before resending an update
+// that arrived before the first round started
+const roundRetryAfter = 5 * time.Second
+
+// notStarted answers updates that arrive before the first round started
+func notStarted() *pb.Ack {
+	return &pb.Ack{
+		Success:           false,
+		Message:           "the first round has not started yet",
+		Status:            pb.AckStatus_ACK_RETRY_AFTER,
+		RetryAfterSeconds: int32(roundRetryAfter / time.Second),
+	}
+}
+
+// checkRound tells why an update does not belong to the current round of a
+// sync aggregator, whose round started from the model with hash modelHash.
+// It returns an empty reason for updates that do. Collaborators that predate
+// round metadata send neither round nor hash, so their updates are accepted.
+func checkRound(upd *pb.ModelUpdate, round int, modelHash string) (monitoring.DropReason, string) {
+	switch {
+	case upd.Round != 0 && int(upd.Round) < round:
+		return monitoring.DropReasonStale,
+			fmt.Sprintf("update was computed for round %d, the aggregator is in round %d", upd.Round, round)
+	case upd.Round != 0 && int(upd.Round) > round:
+		return monitoring.DropReasonRoundMismatch,
+			fmt.Sprintf("update was computed for round %d, the aggregator is in round %d", upd.Round, round)
+	case upd.BaseModelHash != "" && modelHash != "" && upd.BaseModelHash != modelHash:
+		return monitoring.DropReasonRoundMismatch,
+			fmt.Sprintf("update was trained on model %.12s, round %d started from model %.12s", upd.BaseModelHash, round, modelHash)
+	}
+	return "", ""
+}
+
+// roundProgressInterval is how often a sync round that is still waiting for
+// updates logs its progress
+const roundProgressInterval = 30 * time.Second
+
+// updateQuorum wakes the round loop of a sync aggregator as soon as the round
+// holds the updates it waits for, instead of the loop polling the count
+type updateQuorum struct {
+	need     int
+	once     sync.Once
+	reached  chan struct{}
+	released int // updates the round held when the admin API released it early
+}
+
+func newUpdateQuorum(need int) *updateQuorum {
+	q := &updateQuorum{need: need, reached: make(chan struct{})}
+	q.Received(0)
+	return q
+}
+
+// Received records that the round holds count updates. A nil quorum, of a
+// round that has not started, ignores it.
+func (q *updateQuorum) Received(count int) {
+	if q != nil && count >= q.need {
+		q.once.Do(func() { close(q.reached) })
+	}
+}
+
+// Release ends the wait for the round early, which then aggregates the count
+// updates it holds. A round without updates cannot be released.
+func (q *updateQuorum) Release(count int) error {
+	if q == nil {
+		return fmt.Errorf("no round has started")
+	}
+	if count == 0 {
+		return fmt.Errorf("the round holds no updates yet")
+	}
+	q.once.Do(func() {
+		q.released = count
+		close(q.reached)
+	})
+	return nil
+}
+
+// Wait blocks until the quorum is reached or ctx is done. count reports the
+// updates received so far for the progress log.
+func (q *updateQuorum) Wait(ctx context.Context, round int, count func() int) error {
+	log.Printf("Waiting for %d collaborators to submit updates...", q.need)
+	ticker := time.NewTicker(roundProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.reached:
+			if q.released > 0 {
+				log.Printf("Aggregating round %d early with %d/%d updates on admin request", round, q.released, q.need)
+			} else {
+				log.Printf("Received updates from all %d collaborators", q.need)
+			}
+			return nil
+		case <-ctx.Done():
+			log.Printf("Aborting in round %d: %v", round, context.Cause(ctx))
+			return context.Cause(ctx)
+		case <-ticker.C:
+			logging.Infof("Received %d/%d updates for round %d, waiting...", count(), q.need, round)
+		}
+	}
+}
+
+// serverDrainTimeout bounds how long a sync aggregator that completed its
+// rounds waits for calls in flight, such as the acknowledgement of the last
+// update, before it stops serving
+const serverDrainTimeout = 5 * time.Second
+
+// stopServing stops srv once its calls in flight have returned. Event streams
+// must be closed first.
+func stopServing(srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(serverDrainTimeout):
+		srv.Stop()
+	}
+}
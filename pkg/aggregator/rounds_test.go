@@ -0,0 +1,129 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestCheckRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		upd    *pb.ModelUpdate
+		reason monitoring.DropReason
+	}{
+		{"current round", &pb.ModelUpdate{Round: 3, BaseModelHash: "abc"}, ""},
+		{"no metadata", &pb.ModelUpdate{}, ""},
+		{"earlier round", &pb.ModelUpdate{Round: 2, BaseModelHash: "abc"}, monitoring.DropReasonStale},
+		{"later round", &pb.ModelUpdate{Round: 4}, monitoring.DropReasonRoundMismatch},
+		{"other base model", &pb.ModelUpdate{Round: 3, BaseModelHash: "def"}, monitoring.DropReasonRoundMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, detail := checkRound(tt.upd, 3, "abc")
+			if reason != tt.reason {
+				t.Errorf("checkRound() reason = %q, want %q", reason, tt.reason)
+			}
+			if (detail != "") != (tt.reason != "") {
+				t.Errorf("checkRound() detail = %q", detail)
+			}
+		})
+	}
+}
+
+func TestFedAvgAggregatorRejectsWithMessage(t *testing.T) {
+	plan := &federation.FLPlan{Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}}}
+	agg := NewFedAvgAggregator(plan)
+	agg.modelSize = 2
+	agg.currentRound = 2
+	agg.modelHash = "abc"
+
+	submit := func(upd *pb.ModelUpdate) *pb.Ack {
+		upd.ModelWeights = encodeFloats(1, 2)
+		ack, err := agg.SubmitUpdate(context.Background(), upd)
+		if err != nil {
+			t.Fatalf("SubmitUpdate() error = %v", err)
+		}
+		return ack
+	}
+
+	if ack := submit(&pb.ModelUpdate{CollaboratorId: "c1", Round: 2, BaseModelHash: "abc"}); !ack.Success {
+		t.Fatalf("expected update for the current round to be accepted: %s", ack.Message)
+	}
+	if ack := submit(&pb.ModelUpdate{CollaboratorId: "c1", Round: 2, BaseModelHash: "abc"}); ack.Success || ack.Message == "" ||
+		ack.Status != pb.AckStatus_ACK_REJECTED_DUPLICATE {
+		t.Errorf("duplicate ack = %v, want a rejection with a message", ack)
+	}
+	if ack := submit(&pb.ModelUpdate{CollaboratorId: "c2", Round: 3}); ack.Success || ack.Message == "" ||
+		ack.Status != pb.AckStatus_ACK_REJECTED_ROUND_MISMATCH {
+		t.Errorf("round mismatch ack = %v, want a rejection with a message", ack)
+	}
+	if got := agg.drops.Count("c2", monitoring.DropReasonRoundMismatch); got != 1 {
+		t.Errorf("round mismatch drops = %d, want 1", got)
+	}
+
+	agg.currentRound = 0
+	if ack := submit(&pb.ModelUpdate{CollaboratorId: "c2"}); ack.Status != pb.AckStatus_ACK_RETRY_AFTER || ack.RetryAfterSeconds == 0 {
+		t.Errorf("ack before the first round = %v, want retry after", ack)
+	}
+}
+
+func TestUpdateQuorum(t *testing.T) {
+	count := func() int { return 0 }
+	var unstarted *updateQuorum
+	unstarted.Received(5) // updates before the first round are ignored
+
+	q := newUpdateQuorum(2)
+	q.Received(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.Wait(ctx, 1, count); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait with 1/2 updates = %v, want the context's error", err)
+	}
+
+	go q.Received(2)
+	if err := q.Wait(context.Background(), 1, count); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	q.Received(3) // later updates do not close the quorum again
+
+	if err := newUpdateQuorum(0).Wait(context.Background(), 1, count); err != nil {
+		t.Errorf("Wait of a round without expected updates: %v", err)
+	}
+}
+
+func TestRoundPipeline(t *testing.T) {
+	p := newRoundPipeline()
+	release := make(chan struct{})
+	var finished []int
+	p.Go(func() {
+		<-release
+		finished = append(finished, 1)
+	})
+
+	// The next round waits until the previous one is finished
+	queued := make(chan struct{})
+	go func() {
+		p.Go(func() { finished = append(finished, 2) })
+		close(queued)
+	}()
+	select {
+	case <-queued:
+		t.Fatal("round 2 was queued while round 1 was being finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-queued
+	p.Close()
+	p.Close()
+	if !slices.Equal(finished, []int{1, 2}) {
+		t.Errorf("rounds finished in order %v", finished)
+	}
+}
@@ -0,0 +1,82 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// scheduledArgs returns the args of task for a round of a run with the given
+// number of rounds, with every scheduled arg set to its value in that round
+func scheduledArgs(task federation.TaskConfig, round, rounds int) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(task.Args)+len(task.Schedule))
+	for key, value := range task.Args {
+		args[key] = value
+	}
+
+	for key, schedule := range task.Schedule {
+		value, err := scheduleValue(schedule, task.Args[key], round, rounds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule of %s: %v", key, err)
+		}
+		if value != nil {
+			args[key] = value
+		}
+	}
+	return args, nil
+}
+
+// scheduleValue computes a scheduled value from the arg's base value. It
+// returns nil when a piecewise schedule has no value yet and there is no base.
+func scheduleValue(schedule federation.ScheduleConfig, base interface{}, round, rounds int) (interface{}, error) {
+	if schedule.Type == federation.SchedulePiecewise {
+		if len(schedule.Values) == 0 {
+			return nil, fmt.Errorf("piecewise schedule requires values")
+		}
+		starts := make([]int, 0, len(schedule.Values))
+		for start := range schedule.Values {
+			starts = append(starts, start)
+		}
+		sort.Ints(starts)
+		value := base
+		for _, start := range starts {
+			if start <= round {
+				value = schedule.Values[start]
+			}
+		}
+		return value, nil
+	}
+
+	initial, ok := federation.Number(base)
+	if !ok {
+		return nil, fmt.Errorf("%s schedule requires a numeric arg value, got %v", schedule.Type, base)
+	}
+	elapsed := float64(round - 1)
+
+	switch schedule.Type {
+	case federation.ScheduleStep:
+		if schedule.Gamma <= 0 {
+			return nil, fmt.Errorf("step schedule requires a positive gamma")
+		}
+		stepSize := schedule.StepSize
+		if stepSize <= 0 {
+			stepSize = 1
+		}
+		return initial * math.Pow(schedule.Gamma, float64((round-1)/stepSize)), nil
+	case federation.ScheduleExponential:
+		if schedule.Gamma <= 0 {
+			return nil, fmt.Errorf("exponential schedule requires a positive gamma")
+		}
+		return initial * math.Pow(schedule.Gamma, elapsed), nil
+	case federation.ScheduleCosine:
+		if rounds <= 1 {
+			return initial, nil
+		}
+		progress := elapsed / float64(rounds-1)
+		return schedule.Min + (initial-schedule.Min)*(1+math.Cos(math.Pi*progress))/2, nil
+	default:
+		return nil, fmt.Errorf("unknown schedule type %q", schedule.Type)
+	}
+}
@@ -0,0 +1,72 @@
+package aggregator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestScheduledArgs(t *testing.T) {
+	task := federation.TaskConfig{
+		Args: map[string]interface{}{"learning_rate": 0.1, "epochs": 4, "batch_size": 32},
+		Schedule: map[string]federation.ScheduleConfig{
+			"learning_rate": {Type: federation.ScheduleStep, Gamma: 0.5, StepSize: 2},
+			"epochs":        {Type: federation.SchedulePiecewise, Values: map[int]interface{}{3: 2}},
+		},
+	}
+
+	tests := []struct {
+		round        int
+		learningRate float64
+		epochs       interface{}
+	}{
+		{round: 1, learningRate: 0.1, epochs: 4},
+		{round: 2, learningRate: 0.1, epochs: 4},
+		{round: 3, learningRate: 0.05, epochs: 2},
+		{round: 5, learningRate: 0.025, epochs: 2},
+	}
+	for _, tt := range tests {
+		args, err := scheduledArgs(task, tt.round, 5)
+		if err != nil {
+			t.Fatalf("scheduledArgs(round %d) error = %v", tt.round, err)
+		}
+		if lr := args["learning_rate"].(float64); math.Abs(lr-tt.learningRate) > 1e-12 {
+			t.Errorf("round %d learning_rate = %v, want %v", tt.round, lr, tt.learningRate)
+		}
+		if args["epochs"] != tt.epochs || args["batch_size"] != 32 {
+			t.Errorf("round %d args = %v, want epochs %v and batch_size 32", tt.round, args, tt.epochs)
+		}
+	}
+}
+
+func TestScheduleValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule federation.ScheduleConfig
+		base     interface{}
+		round    int
+		want     float64
+		wantErr  bool
+	}{
+		{name: "exponential", schedule: federation.ScheduleConfig{Type: federation.ScheduleExponential, Gamma: 0.9}, base: 1.0, round: 3, want: 0.81},
+		{name: "cosine start", schedule: federation.ScheduleConfig{Type: federation.ScheduleCosine, Min: 0.01}, base: 0.1, round: 1, want: 0.1},
+		{name: "cosine middle", schedule: federation.ScheduleConfig{Type: federation.ScheduleCosine}, base: 0.1, round: 3, want: 0.05},
+		{name: "cosine end", schedule: federation.ScheduleConfig{Type: federation.ScheduleCosine, Min: 0.01}, base: 0.1, round: 5, want: 0.01},
+		{name: "non-numeric base", schedule: federation.ScheduleConfig{Type: federation.ScheduleStep, Gamma: 0.5}, base: "fast", round: 2, wantErr: true},
+		{name: "missing gamma", schedule: federation.ScheduleConfig{Type: federation.ScheduleExponential}, base: 1.0, round: 2, wantErr: true},
+		{name: "unknown type", schedule: federation.ScheduleConfig{Type: "linear"}, base: 1.0, round: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scheduleValue(tt.schedule, tt.base, tt.round, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scheduleValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got.(float64)-tt.want) > 1e-12 {
+				t.Errorf("scheduleValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// loadSigner returns the signer of the models the aggregator hands out, or
+// nil when the plan does not sign models
+func loadSigner(plan *federation.FLPlan) (*security.ModelSigner, error) {
+	signing := plan.Security.ModelSigning
+	if !signing.Enabled {
+		return nil, nil
+	}
+	if signing.KeyPath == "" {
+		return nil, fmt.Errorf("security.model_signing requires key_path on the aggregator")
+	}
+	signer, err := security.LoadModelSigner(signing.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load model signing key: %w", err)
+	}
+	log.Printf("Signing models with key %.12s", signer.KeyID())
+	return signer, nil
+}
+
+// loadVerifier returns the verifier of the models an edge aggregator receives
+// from its root, or nil when the plan does not sign models
+func loadVerifier(plan *federation.FLPlan) (*security.ModelVerifier, error) {
+	signing := plan.Security.ModelSigning
+	if !signing.Enabled {
+		return nil, nil
+	}
+	if signing.PublicKeyPath == "" {
+		return nil, fmt.Errorf("security.model_signing requires public_key_path to verify models")
+	}
+	verifier, err := security.LoadModelVerifier(signing.PublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load model signing public key: %w", err)
+	}
+	return verifier, nil
+}
+
+// signEncoded returns the signature of an encoded model, or nil without a
+// signer
+func signEncoded(signer *security.ModelSigner, model []byte) []byte {
+	if signer == nil {
+		return nil
+	}
+	return signer.Sign(transport.ModelHash(model))
+}
@@ -0,0 +1,51 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// Staleness weighting functions, following FedAsync (Xie et al., 2019)
+const (
+	StalenessConstant   = "constant"   // every update gets full weight
+	StalenessPolynomial = "polynomial" // (s + 1)^-a
+	StalenessHinge      = "hinge"      // 1 up to b seconds, then 1 / (a(s - b) + 1)
+)
+
+// defaultStalenessAlpha is the decay rate used when staleness_alpha is unset
+const defaultStalenessAlpha = 0.5
+
+// StalenessFunc maps the staleness of an update, in seconds, to a weight in (0, 1]
+type StalenessFunc func(staleness int) float64
+
+// NewStalenessFunc returns the weighting function configured in config
+func NewStalenessFunc(config federation.AsyncConfig) (StalenessFunc, error) {
+	alpha := config.StalenessAlpha
+	if alpha == 0 {
+		alpha = defaultStalenessAlpha
+	}
+	if alpha < 0 {
+		return nil, fmt.Errorf("staleness_alpha must be positive, got %g", alpha)
+	}
+	hinge := config.StalenessHinge
+
+	switch config.StalenessFunction {
+	case "", StalenessConstant:
+		return func(int) float64 { return 1 }, nil
+	case StalenessPolynomial:
+		return func(staleness int) float64 {
+			return math.Pow(float64(max(staleness, 0))+1, -alpha)
+		}, nil
+	case StalenessHinge:
+		return func(staleness int) float64 {
+			if staleness <= hinge {
+				return 1
+			}
+			return 1 / (alpha*float64(staleness-hinge) + 1)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported staleness function: %s", config.StalenessFunction)
+	}
+}
@@ -0,0 +1,131 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestStalenessFunc(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    federation.AsyncConfig
+		staleness int
+		expected  float64
+	}{
+		{"constant by default", federation.AsyncConfig{}, 100, 1},
+		{"polynomial fresh", federation.AsyncConfig{StalenessFunction: StalenessPolynomial}, 0, 1},
+		{"polynomial default alpha", federation.AsyncConfig{StalenessFunction: StalenessPolynomial}, 3, 0.5},
+		{"polynomial alpha 1", federation.AsyncConfig{StalenessFunction: StalenessPolynomial, StalenessAlpha: 1}, 4, 0.2},
+		{"hinge before threshold", federation.AsyncConfig{StalenessFunction: StalenessHinge, StalenessHinge: 10}, 10, 1},
+		{"hinge after threshold", federation.AsyncConfig{StalenessFunction: StalenessHinge, StalenessHinge: 10, StalenessAlpha: 0.25}, 14, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weight, err := NewStalenessFunc(tt.config)
+			if err != nil {
+				t.Fatalf("NewStalenessFunc() error = %v", err)
+			}
+			if got := weight(tt.staleness); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("weight(%d) = %g, want %g", tt.staleness, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := NewStalenessFunc(federation.AsyncConfig{StalenessFunction: "exponential"}); err == nil {
+		t.Error("Expected error for unsupported staleness function")
+	}
+}
+
+func TestFedAvgStalenessWeighting(t *testing.T) {
+	algorithm := &FedAvgAlgorithm{}
+	algorithm.Initialize(AlgorithmConfig{ModelSize: 1})
+
+	// Equal sample counts, but the stale update counts for a third as much
+	updates := []ClientUpdate{
+		{CollaboratorID: "fresh", Weights: []float32{4}, NumSamples: 100, StalenessWeight: 1},
+		{CollaboratorID: "stale", Weights: []float32{0}, NumSamples: 100, StalenessWeight: 1.0 / 3},
+	}
+
+	model, err := algorithm.Aggregate(updates, []float32{0})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if math.Abs(float64(model[0])-3) > 1e-5 {
+		t.Errorf("Aggregate() = %v, want [3]", model)
+	}
+}
+
+func TestUpdateReporter(t *testing.T) {
+	received := make(chan monitoring.ModelUpdateMetrics, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metrics monitoring.ModelUpdateMetrics
+		json.NewDecoder(r.Body).Decode(&metrics)
+		received <- metrics
+	}))
+	defer server.Close()
+
+	reporter := NewUpdateReporter(&federation.FLPlan{
+		Monitoring: federation.MonitoringConfig{Enabled: true, MonitoringServerURL: server.URL, FederationID: "fed-1"},
+	})
+	reporter.Record(ClientUpdate{CollaboratorID: "c1", Weights: make([]float32, 10), Round: 3, Staleness: 12, StalenessWeight: 0.25})
+
+	select {
+	case metrics := <-received:
+		if metrics.FederationID != "fed-1" || metrics.CollaboratorID != "c1" || metrics.Weight != 0.25 ||
+			metrics.Staleness != 12 || metrics.UpdateSize != 40 {
+			t.Errorf("Unexpected reported update: %+v", metrics)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Model update was not reported")
+	}
+}
+
+func TestUpdateReporterForwardsTrainingMetrics(t *testing.T) {
+	updates := make(chan monitoring.ModelUpdateMetrics, 1)
+	events := make(chan monitoring.MonitoringEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/updates":
+			var metrics monitoring.ModelUpdateMetrics
+			json.NewDecoder(r.Body).Decode(&metrics)
+			updates <- metrics
+		case "/api/v1/events":
+			var event monitoring.MonitoringEvent
+			json.NewDecoder(r.Body).Decode(&event)
+			events <- event
+		}
+	}))
+	defer server.Close()
+
+	reporter := NewUpdateReporter(&federation.FLPlan{
+		Monitoring: federation.MonitoringConfig{Enabled: true, MonitoringServerURL: server.URL, FederationID: "fed-1"},
+	})
+	reporter.Record(ClientUpdate{CollaboratorID: "c1", Round: 3,
+		TrainingMetrics: map[string]float64{"loss": 0.25, "accuracy": 0.9}})
+
+	select {
+	case metrics := <-updates:
+		if metrics.QualityScore == nil || *metrics.QualityScore != 0.9 {
+			t.Errorf("quality score = %v, want the accuracy 0.9", metrics.QualityScore)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Model update was not reported")
+	}
+	select {
+	case event := <-events:
+		if event.Type != monitoring.MetricTypeTraining || event.Source != "c1" ||
+			event.Data["loss"] != 0.25 || event.Data["round"] != float64(3) {
+			t.Errorf("Unexpected training event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Training event was not reported")
+	}
+}
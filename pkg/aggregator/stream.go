@@ -0,0 +1,78 @@
+package aggregator
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// runningSum adds updates to a weighted sum as they arrive, straight from
+// their encoding. An aggregator that streams updates this way holds one
+// model-sized buffer however many collaborators submit, instead of a decoded
+// copy of every update.
+type runningSum struct {
+	mu    sync.Mutex
+	sums  []float64
+	total int64
+}
+
+func newRunningSum(modelSize int) *runningSum {
+	return &runningSum{sums: make([]float64, modelSize)}
+}
+
+// Add adds an encoded update that passed validateUpdate, weighted by its
+// sample count
+func (s *runningSum) Add(data []byte, numSamples int64) {
+	weight := float64(numSamples)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parallelFor(len(s.sums), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			s.sums[i] += weight * float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+		}
+	})
+	s.total += numSamples
+}
+
+// Average returns the weighted average of the updates added so far and their
+// total sample count
+func (s *runningSum) Average() ([]float32, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	avg := make([]float32, len(s.sums))
+	if s.total == 0 {
+		return avg, 0
+	}
+	parallelFor(len(s.sums), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			avg[i] = float32(s.sums[i] / float64(s.total))
+		}
+	})
+	return avg, s.total
+}
+
+// AverageInto writes the weighted average as an encoded model to dst, which
+// holds the running sum's model size, and returns the total sample count
+func (s *runningSum) AverageInto(dst []byte) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := max(s.total, 1) // an empty sum averages to zeros
+	parallelFor(len(s.sums), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			putFloat(dst, i, float32(s.sums[i]/float64(total)))
+		}
+	})
+	return s.total
+}
+
+// decodeStreamed validates an update and decodes it, unless the aggregator
+// streams updates into a running sum and only needs it validated
+func decodeStreamed(data []byte, modelSize int, streaming bool) ([]float32, monitoring.DropReason, error) {
+	if streaming {
+		reason, err := validateUpdate(data, modelSize)
+		return nil, reason, err
+	}
+	return decodeUpdate(data, modelSize)
+}
@@ -0,0 +1,75 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestRunningSumMatchesWeightedAverage(t *testing.T) {
+	updates := []weightedUpdate{
+		{[]float32{1, 2, 3}, 10, "a"},
+		{[]float32{4, -5, 6}, 30, "b"},
+	}
+	sum := newRunningSum(3)
+	for _, upd := range updates {
+		sum.Add(encodeModel(upd.weights), upd.numSamples)
+	}
+
+	got, total := sum.Average()
+	want, wantTotal := weightedAverage(updates, 3)
+	if total != wantTotal {
+		t.Errorf("total = %d, want %d", total, wantTotal)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("average = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestValidateUpdateFindsFirstNonFiniteValue(t *testing.T) {
+	n := 4 * parallelThreshold
+	data := make([]byte, 4*n)
+	binary.LittleEndian.PutUint32(data[4*(n-1):], math.Float32bits(float32(math.Inf(1))))
+	binary.LittleEndian.PutUint32(data[4*(n/2):], math.Float32bits(float32(math.NaN())))
+
+	_, err := validateUpdate(data, n)
+	if err == nil || !strings.Contains(err.Error(), "index 131072") {
+		t.Errorf("validateUpdate() error = %v, want the NaN at index %d", err, n/2)
+	}
+}
+
+func TestFedAvgAggregatorStreamsUpdates(t *testing.T) {
+	plan := &federation.FLPlan{
+		Aggregator:    federation.AggregatorEntry{Streaming: true},
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}},
+	}
+	agg := NewFedAvgAggregator(plan)
+	agg.modelSize = 2
+	agg.currentRound = 1
+	agg.stream = newRunningSum(2)
+
+	for _, upd := range []*pb.ModelUpdate{
+		{CollaboratorId: "c1", ModelWeights: encodeFloats(1, 2), NumSamples: 1},
+		{CollaboratorId: "c2", ModelWeights: encodeFloats(3, 4), NumSamples: 3},
+	} {
+		if ack, _ := agg.SubmitUpdate(context.Background(), upd); !ack.Success {
+			t.Fatalf("SubmitUpdate(%s) rejected: %s", upd.CollaboratorId, ack.Message)
+		}
+	}
+
+	for _, upd := range agg.updates {
+		if upd.weights != nil {
+			t.Errorf("streamed update of %s was kept", upd.collaboratorID)
+		}
+	}
+	if avg, _ := agg.stream.Average(); avg[0] != 2.5 || avg[1] != 3.5 {
+		t.Errorf("streamed average = %v, want [2.5 3.5]", avg)
+	}
+}
@@ -0,0 +1,130 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AsyncTuning is a partial update of the async settings that can change while
+// a federation runs. Unset fields keep their current value.
+type AsyncTuning struct {
+	MaxStaleness     *int `json:"max_staleness,omitempty"`
+	MinUpdates       *int `json:"min_updates,omitempty"`
+	AggregationDelay *int `json:"aggregation_delay,omitempty"`
+}
+
+// AsyncTunable is implemented by aggregators whose async settings can be
+// changed at runtime
+type AsyncTunable interface {
+	AsyncConfig() federation.AsyncConfig
+	TuneAsync(tuning AsyncTuning) (federation.AsyncConfig, error)
+}
+
+// TuningFromConfig returns a tuning that sets every runtime-tunable field of config
+func TuningFromConfig(config federation.AsyncConfig) AsyncTuning {
+	return AsyncTuning{
+		MaxStaleness:     &config.MaxStaleness,
+		MinUpdates:       &config.MinUpdates,
+		AggregationDelay: &config.AggregationDelay,
+	}
+}
+
+// apply validates the tuning and applies it to config
+func (t AsyncTuning) apply(config *federation.AsyncConfig) error {
+	if t.MaxStaleness != nil && *t.MaxStaleness < 0 {
+		return fmt.Errorf("max_staleness must not be negative")
+	}
+	if t.MinUpdates != nil && *t.MinUpdates < 0 {
+		return fmt.Errorf("min_updates must not be negative")
+	}
+	if t.AggregationDelay != nil && *t.AggregationDelay < 1 {
+		return fmt.Errorf("aggregation_delay must be at least 1 second")
+	}
+
+	if t.MaxStaleness != nil {
+		config.MaxStaleness = *t.MaxStaleness
+	}
+	if t.MinUpdates != nil {
+		config.MinUpdates = *t.MinUpdates
+	}
+	if t.AggregationDelay != nil {
+		config.AggregationDelay = *t.AggregationDelay
+	}
+	return nil
+}
+
+// asyncTunable returns the aggregator the admin server controls as an
+// AsyncTunable, or a FailedPrecondition error unless it runs an async
+// federation
+func (s *adminServer) asyncTunable() (AsyncTunable, error) {
+	tunable, ok := s.control.(AsyncTunable)
+	if !ok || s.plan.Mode != federation.ModeAsync {
+		return nil, status.Error(codes.FailedPrecondition, "only async federations have tunable settings")
+	}
+	return tunable, nil
+}
+
+// GetAsyncConfig returns the runtime-tunable async settings
+func (s *adminServer) GetAsyncConfig(ctx context.Context, _ *adminpb.AsyncConfigRequest) (*adminpb.AsyncConfig, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	tunable, err := s.asyncTunable()
+	if err != nil {
+		return nil, err
+	}
+	return asyncConfigResponse(tunable.AsyncConfig()), nil
+}
+
+// TuneAsync applies a partial update of the async settings
+func (s *adminServer) TuneAsync(ctx context.Context, req *adminpb.AsyncTuning) (*adminpb.AsyncConfig, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	tunable, err := s.asyncTunable()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := tunable.TuneAsync(AsyncTuning{
+		MaxStaleness:     intPointer(req.MaxStaleness),
+		MinUpdates:       intPointer(req.MinUpdates),
+		AggregationDelay: intPointer(req.AggregationDelay),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return asyncConfigResponse(config), nil
+}
+
+func asyncConfigResponse(config federation.AsyncConfig) *adminpb.AsyncConfig {
+	return &adminpb.AsyncConfig{
+		MaxStaleness:     clampInt32(config.MaxStaleness),
+		MinUpdates:       clampInt32(config.MinUpdates),
+		AggregationDelay: clampInt32(config.AggregationDelay),
+	}
+}
+
+// intPointer converts an optional proto field
+func intPointer(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+// logAsyncTuning logs the async settings after a runtime change and
+// announces them to watching collaborators
+func logAsyncTuning(config federation.AsyncConfig, events *eventBroker) {
+	summary := fmt.Sprintf("max_staleness=%d, min_updates=%d, delay=%ds",
+		config.MaxStaleness, config.MinUpdates, config.AggregationDelay)
+	log.Printf("Async config updated: %s", summary)
+	events.ConfigChanged("async config updated: " + summary)
+}
@@ -0,0 +1,77 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAdminServerTuneAsync(t *testing.T) {
+	agg := NewAsyncFedAvgAggregator(&federation.FLPlan{
+		Mode:        federation.ModeAsync,
+		AsyncConfig: federation.AsyncConfig{MaxStaleness: 60, MinUpdates: 1, AggregationDelay: 5, StalenessWeight: 0.9},
+	})
+	server := &adminServer{control: agg, plan: agg.plan, token: "secret"}
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "secret"))
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		tuning     *adminpb.AsyncTuning // nil reads the settings
+		wantCode   codes.Code
+		wantConfig *adminpb.AsyncConfig
+	}{
+		{"missing token", context.Background(), nil, codes.Unauthenticated, nil},
+		{"read", admin, nil, codes.OK, &adminpb.AsyncConfig{MaxStaleness: 60, MinUpdates: 1, AggregationDelay: 5}},
+		{"partial update", admin, &adminpb.AsyncTuning{MinUpdates: proto.Int32(3)}, codes.OK, &adminpb.AsyncConfig{MaxStaleness: 60, MinUpdates: 3, AggregationDelay: 5}},
+		{"invalid delay", admin, &adminpb.AsyncTuning{AggregationDelay: proto.Int32(0), MaxStaleness: proto.Int32(10)}, codes.InvalidArgument, nil},
+		{"unchanged after rejected update", admin, nil, codes.OK, &adminpb.AsyncConfig{MaxStaleness: 60, MinUpdates: 3, AggregationDelay: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config *adminpb.AsyncConfig
+			var err error
+			if tt.tuning == nil {
+				config, err = server.GetAsyncConfig(tt.ctx, &adminpb.AsyncConfigRequest{})
+			} else {
+				config, err = server.TuneAsync(tt.ctx, tt.tuning)
+			}
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want %s", err, tt.wantCode)
+			}
+			if tt.wantConfig != nil && !proto.Equal(config, tt.wantConfig) {
+				t.Errorf("config = %v, want %v", config, tt.wantConfig)
+			}
+		})
+	}
+
+	// Settings that are not runtime-tunable are left alone
+	if config := agg.AsyncConfig(); config.StalenessWeight != 0.9 {
+		t.Errorf("StalenessWeight = %g, want 0.9", config.StalenessWeight)
+	}
+
+	syncAgg := NewFedAvgAggregator(&federation.FLPlan{Rounds: 1})
+	server = &adminServer{control: syncAgg, plan: syncAgg.plan, token: "secret"}
+	if _, err := server.GetAsyncConfig(admin, &adminpb.AsyncConfigRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("GetAsyncConfig() of a sync federation error = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestModularAggregatorTuneAsyncRequiresAsync(t *testing.T) {
+	agg, err := NewModularAggregator(&federation.FLPlan{Mode: federation.ModeSync, Algorithm: federation.AlgorithmConfig{Name: "fedprox"}})
+	if err != nil {
+		t.Fatalf("NewModularAggregator() error = %v", err)
+	}
+
+	minUpdates := 2
+	if _, err := agg.TuneAsync(AsyncTuning{MinUpdates: &minUpdates}); err == nil {
+		t.Error("Expected an error when tuning a sync federation")
+	}
+}
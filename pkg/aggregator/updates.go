@@ -0,0 +1,202 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// UpdateReporter forwards aggregated model updates, with the staleness weight
+// they were aggregated with, the collaborators' training metrics, the
+// evaluators' metrics, aggregations, contribution scores and completed rounds
+// to the monitoring server
+type UpdateReporter struct {
+	federationID string
+	reportURL    string
+	roundsURL    string
+	eventsURL    string
+	aggregateURL string
+	scoresURL    string
+	labels       map[string]string
+	apiKey       string
+	client       *http.Client
+}
+
+// NewUpdateReporter creates a reporter for the federation described by plan.
+// Reporting is a no-op unless monitoring is enabled.
+func NewUpdateReporter(plan *federation.FLPlan) *UpdateReporter {
+	reporter := &UpdateReporter{
+		federationID: federationIDFor(plan),
+		labels:       plan.Monitoring.Labels,
+		apiKey:       plan.Monitoring.APIKey,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if plan.Monitoring.Enabled && plan.Monitoring.MonitoringServerURL != "" {
+		serverURL := strings.TrimRight(plan.Monitoring.MonitoringServerURL, "/")
+		reporter.reportURL = serverURL + "/api/v1/updates"
+		reporter.roundsURL = serverURL + "/api/v1/rounds"
+		reporter.eventsURL = serverURL + "/api/v1/events"
+		reporter.aggregateURL = serverURL + "/api/v1/aggregations"
+		reporter.scoresURL = serverURL + "/api/v1/contributions"
+	}
+
+	return reporter
+}
+
+// Record reports an update that was included in an aggregation
+func (r *UpdateReporter) Record(update ClientUpdate) {
+	if r.reportURL == "" {
+		return
+	}
+
+	metrics := monitoring.ModelUpdateMetrics{
+		FederationID:      r.federationID,
+		CollaboratorID:    update.CollaboratorID,
+		RoundNumber:       update.Round,
+		Timestamp:         update.Timestamp,
+		UpdateSize:        max(update.Size, 4*len(update.Weights)),
+		NumSamples:        int64(update.NumSamples),
+		Staleness:         update.Staleness,
+		Weight:            float64(update.stalenessFactor()),
+		ImageDigest:       update.ImageDigest,
+		DPClipNorm:        update.DPClipNorm,
+		DPNoiseMultiplier: update.DPNoiseMultiplier,
+	}
+	if accuracy, ok := update.TrainingMetrics["accuracy"]; ok {
+		metrics.QualityScore = &accuracy
+	}
+	go r.report(r.reportURL, "model update", metrics)
+
+	if len(update.TrainingMetrics) > 0 {
+		go r.report(r.eventsURL, "training metrics", trainingEvent(r.federationID, update))
+	}
+}
+
+// trainingEvent describes the local training of an update. Its data holds the
+// round and each of the collaborator's metrics.
+func trainingEvent(federationID string, update ClientUpdate) monitoring.MonitoringEvent {
+	data := make(map[string]interface{}, len(update.TrainingMetrics)+1)
+	for name, value := range update.TrainingMetrics {
+		data[name] = value
+	}
+	data["round"] = update.Round
+	return monitoring.MonitoringEvent{
+		FederationID: federationID,
+		Type:         monitoring.MetricTypeTraining,
+		Timestamp:    update.Timestamp,
+		Source:       update.CollaboratorID,
+		Level:        "info",
+		Message: fmt.Sprintf("Round %d training by %s: %s",
+			update.Round, update.CollaboratorID, formatMetrics(update.TrainingMetrics)),
+		Data: data,
+	}
+}
+
+// RecordEvaluation reports the metrics an evaluator measured on the model
+// aggregated in round
+func (r *UpdateReporter) RecordEvaluation(collaboratorID string, round int, metrics map[string]float64) {
+	if r.eventsURL == "" {
+		return
+	}
+	go r.report(r.eventsURL, "evaluation", evaluationEvent(r.federationID, collaboratorID, round, metrics))
+}
+
+// evaluationEvent describes the evaluation of an aggregated model. Its data
+// holds the round and each of the evaluator's metrics.
+func evaluationEvent(federationID, collaboratorID string, round int, metrics map[string]float64) monitoring.MonitoringEvent {
+	data := make(map[string]interface{}, len(metrics)+1)
+	for name, value := range metrics {
+		data[name] = value
+	}
+	data["round"] = round
+	return monitoring.MonitoringEvent{
+		FederationID: federationID,
+		Type:         monitoring.MetricTypeEvaluation,
+		Timestamp:    time.Now(),
+		Source:       collaboratorID,
+		Level:        "info",
+		Message:      fmt.Sprintf("Round %d evaluation by %s: %s", round, collaboratorID, formatMetrics(metrics)),
+		Data:         data,
+	}
+}
+
+// RecordClusters reports the cluster of each collaborator after a clustered
+// federation re-clustered them in round
+func (r *UpdateReporter) RecordClusters(round int, members map[string]int, sizes []int) {
+	if r.eventsURL == "" {
+		return
+	}
+	go r.report(r.eventsURL, "clusters", clusteringEvent(r.federationID, round, members, sizes))
+}
+
+// clusteringEvent describes the clusters of a clustered federation. Its data
+// holds the round, the cluster of each collaborator and the cluster sizes.
+func clusteringEvent(federationID string, round int, members map[string]int, sizes []int) monitoring.MonitoringEvent {
+	return monitoring.MonitoringEvent{
+		FederationID: federationID,
+		Type:         monitoring.MetricTypeClustering,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Round %d re-clustered %d collaborators into clusters of sizes %v", round, len(members), sizes),
+		Data: map[string]interface{}{
+			"round":    round,
+			"clusters": members,
+			"sizes":    sizes,
+		},
+	}
+}
+
+// RecordRound reports a completed round
+func (r *UpdateReporter) RecordRound(round monitoring.RoundMetrics) {
+	if r.roundsURL == "" {
+		return
+	}
+
+	round.FederationID = r.federationID
+	if round.Labels == nil {
+		round.Labels = r.labels
+	}
+	go r.report(r.roundsURL, "round", round)
+}
+
+// RecordAggregation reports an aggregation and how many of its updates were
+// clipped
+func (r *UpdateReporter) RecordAggregation(aggregation monitoring.AggregationMetrics) {
+	if r.aggregateURL == "" {
+		return
+	}
+
+	aggregation.FederationID = r.federationID
+	go r.report(r.aggregateURL, "aggregation", aggregation)
+}
+
+// RecordContributions reports the contribution scores of an aggregation
+func (r *UpdateReporter) RecordContributions(scores []monitoring.ContributionScore) {
+	if r.scoresURL == "" || len(scores) == 0 {
+		return
+	}
+
+	for i := range scores {
+		scores[i].FederationID = r.federationID
+	}
+	go r.report(r.scoresURL, "contribution scores", scores)
+}
+
+func (r *UpdateReporter) report(url, kind string, metrics interface{}) {
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		return
+	}
+
+	if err := postMonitoringJSON(r.client, url, r.apiKey, body); err != nil {
+		log.Printf("Failed to report %s to monitoring: %v", kind, err)
+	}
+}
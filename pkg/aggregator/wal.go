@@ -0,0 +1,427 @@
+package aggregator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/protobuf/proto"
+)
+
+// Record types of a WAL segment
+const (
+	walHeader byte = 1 // JSON walRoundHeader; the first record of a segment
+	walUpdate byte = 2 // marshaled pb.ModelUpdate, as it was received
+	walCommit byte = 3 // JSON walRoundCommit; the round was aggregated
+)
+
+// walRecordOverhead is the length, checksum and type that precede the
+// payload of a record
+const walRecordOverhead = 9
+
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// walMarshal encodes logged updates. Deterministic encoding gives a replayed
+// update the bytes it was logged with.
+var walMarshal = proto.MarshalOptions{Deterministic: true}
+
+// walRoundHeader starts the segment of a round with the model the round's
+// updates are trained on, which is on disk already: the initial model or the
+// previous round's aggregate
+type walRoundHeader struct {
+	Round           int    `json:"round"`
+	PlanFingerprint string `json:"plan_fingerprint"`
+	BaseModelPath   string `json:"base_model_path"`
+	BaseModelHash   string `json:"base_model_hash"`
+}
+
+// walRoundCommit ends the segment of an aggregated round
+type walRoundCommit struct {
+	ModelPath string `json:"model_path"`
+	ModelHash string `json:"model_hash"`
+}
+
+// RecoveredRound is the round a sync aggregator resumes after a restart, with
+// the updates it had received for it
+type RecoveredRound struct {
+	Round         int
+	BaseModelPath string
+	BaseModel     []byte
+	BaseModelHash string
+	Updates       []*pb.ModelUpdate
+}
+
+// UpdateWAL appends the updates of each sync round to
+// <dir>/round_<N>.wal as they are accepted, so that an aggregator restarted
+// after a crash resumes the round with the updates it had received. Unlike
+// the archive, a failed write rejects the update: an acknowledged update
+// must survive a restart.
+type UpdateWAL struct {
+	dir         string
+	fsync       bool // sync every record to the disk
+	retain      int  // aggregated rounds whose segments are kept
+	fingerprint string
+
+	mu       sync.Mutex
+	file     *os.File
+	round    int
+	replayed map[[32]byte]int // SHA-256 of the logged updates of the open round that are replayed, and not logged again
+}
+
+// OpenUpdateWAL returns the WAL of the plan, or nil when the plan does not
+// log updates. The methods of a nil WAL do nothing.
+func OpenUpdateWAL(plan *federation.FLPlan) (*UpdateWAL, error) {
+	config := plan.Aggregator.WAL
+	if config.Dir == "" {
+		return nil, nil
+	}
+	if config.RetainRounds < 0 {
+		return nil, fmt.Errorf("aggregator.wal.retain_rounds must not be negative")
+	}
+	fingerprint, err := federation.PlanFingerprint(plan)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(config.Dir, 0750); err != nil {
+		return nil, err
+	}
+	return &UpdateWAL{
+		dir:         config.Dir,
+		fsync:       config.Fsync,
+		retain:      config.RetainRounds,
+		fingerprint: fingerprint,
+	}, nil
+}
+
+// Recover returns the round to resume from the WAL of an interrupted run of
+// rounds rounds, or nil to start a new run. Starting a new run removes the
+// segments of a completed one.
+func (w *UpdateWAL) Recover(rounds int) (*RecoveredRound, error) {
+	if w == nil {
+		return nil, nil
+	}
+	segments, err := w.segments()
+	if err != nil || len(segments) == 0 {
+		return nil, err
+	}
+	last := segments[len(segments)-1]
+	header, updates, commit, err := w.readSegment(last)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		// Interrupted before the header was written; the previous segment
+		// tells where the run was
+		if err := os.Remove(w.segmentPath(last)); err != nil {
+			return nil, err
+		}
+		return w.Recover(rounds)
+	}
+	if header.PlanFingerprint != w.fingerprint {
+		return nil, fmt.Errorf("the WAL in %s belongs to another plan; remove it to start a new run", w.dir)
+	}
+
+	recovered := &RecoveredRound{
+		Round:         header.Round,
+		BaseModelPath: header.BaseModelPath,
+		BaseModelHash: header.BaseModelHash,
+		Updates:       updates,
+	}
+	if commit != nil {
+		if header.Round >= rounds {
+			log.Printf("The WAL in %s holds a completed run; starting a new one", w.dir)
+			return nil, w.remove(func(int) bool { return true })
+		}
+		// Aggregated, but the next round had not started
+		recovered = &RecoveredRound{
+			Round:         header.Round + 1,
+			BaseModelPath: commit.ModelPath,
+			BaseModelHash: commit.ModelHash,
+		}
+	}
+
+	base, err := storage.ReadFile(recovered.BaseModelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the base model of round %d: %w", recovered.Round, err)
+	}
+	if transport.ModelHash(base) != recovered.BaseModelHash {
+		return nil, fmt.Errorf("the base model of round %d in %s has changed since it was logged", recovered.Round, recovered.BaseModelPath)
+	}
+	recovered.BaseModel = base
+	return recovered, nil
+}
+
+// StartRound opens the segment of a round, whose updates are trained on the
+// model at basePath. The segment of a recovered round is appended to. Older
+// segments are removed, except for those of the rounds retained.
+func (w *UpdateWAL) StartRound(round int, basePath, baseHash string) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.closeSegment(); err != nil {
+		return err
+	}
+
+	path := w.segmentPath(round)
+	header, updates, commit, err := w.readSegment(round)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		header = nil
+	case err != nil:
+		return err
+	}
+	w.replayed = make(map[[32]byte]int)
+	if header != nil && commit == nil && header.PlanFingerprint == w.fingerprint && header.BaseModelHash == baseHash {
+		for _, upd := range updates {
+			payload, err := walMarshal.Marshal(upd)
+			if err != nil {
+				return err
+			}
+			w.replayed[sha256.Sum256(payload)]++
+		}
+		if w.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600); err != nil { // #nosec G304 - segments are named by round
+			return err
+		}
+	} else {
+		if w.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil { // #nosec G304 - segments are named by round
+			return err
+		}
+		payload, err := json.Marshal(walRoundHeader{
+			Round:           round,
+			PlanFingerprint: w.fingerprint,
+			BaseModelPath:   basePath,
+			BaseModelHash:   baseHash,
+		})
+		if err != nil {
+			return err
+		}
+		if err := w.write(walHeader, payload); err != nil {
+			return err
+		}
+	}
+	w.round = round
+
+	return w.remove(func(r int) bool { return r < round-w.retain })
+}
+
+// Append logs a valid update of a round before it is added to the round.
+// Duplicates are logged too, and rejected again when they are replayed. The
+// replay of a logged update is not logged again; it is recognized by its
+// content, so that a new update a collaborator sends while the round is
+// replayed is logged.
+func (w *UpdateWAL) Append(round int, upd *pb.ModelUpdate) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil || w.round != round {
+		return fmt.Errorf("round %d is not open in the WAL", round)
+	}
+	payload, err := walMarshal.Marshal(upd)
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(payload); w.replayed[sum] > 0 {
+		w.replayed[sum]--
+		return nil
+	}
+	return w.write(walUpdate, payload)
+}
+
+// Commit records that a round was aggregated into the model at modelPath
+func (w *UpdateWAL) Commit(round int, modelPath, modelHash string) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil || w.round != round {
+		return fmt.Errorf("round %d is not open in the WAL", round)
+	}
+	payload, err := json.Marshal(walRoundCommit{ModelPath: modelPath, ModelHash: modelHash})
+	if err != nil {
+		return err
+	}
+	if err := w.write(walCommit, payload); err != nil {
+		return err
+	}
+	return w.closeSegment()
+}
+
+// Close closes the open segment
+func (w *UpdateWAL) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeSegment()
+}
+
+func (w *UpdateWAL) closeSegment() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// write appends a record to the open segment
+func (w *UpdateWAL) write(recordType byte, payload []byte) error {
+	record := make([]byte, walRecordOverhead+len(payload))
+	binary.LittleEndian.PutUint32(record[0:], uint32(len(payload))) // #nosec G115 - updates are far below 4 GiB
+	record[8] = recordType
+	copy(record[walRecordOverhead:], payload)
+	binary.LittleEndian.PutUint32(record[4:], crc32.Checksum(record[8:], walTable))
+	if _, err := w.file.Write(record); err != nil {
+		return err
+	}
+	if w.fsync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+// readSegment reads the records of a round's segment. A torn or corrupt
+// record, left by a crash while it was written, ends the segment and is
+// truncated away so that appends continue after the last whole record.
+func (w *UpdateWAL) readSegment(round int) (header *walRoundHeader, updates []*pb.ModelUpdate, commit *walRoundCommit, err error) {
+	path := w.segmentPath(round)
+	f, err := os.Open(path) // #nosec G304 - segments are named by round
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	var prefix [walRecordOverhead]byte
+	for {
+		if _, err = io.ReadFull(reader, prefix[:]); err != nil {
+			break
+		}
+		// A corrupt length must not allocate more than the file holds
+		size := int64(binary.LittleEndian.Uint32(prefix[0:]))
+		if offset+walRecordOverhead+size > info.Size() {
+			err = io.ErrUnexpectedEOF
+			break
+		}
+		payload := make([]byte, size)
+		if _, err = io.ReadFull(reader, payload); err != nil {
+			break
+		}
+		checksum := crc32.Update(crc32.Checksum(prefix[8:], walTable), walTable, payload)
+		if checksum != binary.LittleEndian.Uint32(prefix[4:]) {
+			err = fmt.Errorf("checksum mismatch")
+			break
+		}
+
+		switch recordType := prefix[8]; {
+		case recordType == walHeader && offset == 0:
+			header = &walRoundHeader{}
+			err = json.Unmarshal(payload, header)
+		case recordType == walUpdate && header != nil && commit == nil:
+			upd := &pb.ModelUpdate{}
+			if err = proto.Unmarshal(payload, upd); err == nil {
+				updates = append(updates, upd)
+			}
+		case recordType == walCommit && header != nil && commit == nil:
+			commit = &walRoundCommit{}
+			err = json.Unmarshal(payload, commit)
+		default:
+			err = fmt.Errorf("unexpected record of type %d", recordType)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid WAL segment %s at offset %d: %v", path, offset, err)
+		}
+		offset += walRecordOverhead + size
+	}
+
+	if err != io.EOF {
+		log.Printf("Warning: truncating WAL segment %s after %d bytes: %v", path, offset, err)
+		if err := os.Truncate(path, offset); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if header != nil && header.Round != round {
+		return nil, nil, nil, fmt.Errorf("WAL segment %s holds round %d", path, header.Round)
+	}
+	return header, updates, commit, nil
+}
+
+// notLogged answers updates the WAL failed to log; they are not acknowledged,
+// so collaborators resend them
+func notLogged(err error) *pb.Ack {
+	return &pb.Ack{
+		Success:           false,
+		Message:           fmt.Sprintf("the aggregator failed to log the update: %v", err),
+		Status:            pb.AckStatus_ACK_RETRY_AFTER,
+		RetryAfterSeconds: int32(roundRetryAfter / time.Second),
+	}
+}
+
+// segments returns the rounds that have a segment, in order
+func (w *UpdateWAL) segments() ([]int, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+	var rounds []int
+	for _, entry := range entries {
+		var round int
+		name := entry.Name()
+		if _, err := fmt.Sscanf(name, "round_%d.wal", &round); err == nil && name == filepath.Base(w.segmentPath(round)) {
+			rounds = append(rounds, round)
+		}
+	}
+	slices.Sort(rounds)
+	return rounds, nil
+}
+
+// remove deletes the segments of the rounds selected
+func (w *UpdateWAL) remove(selected func(round int) bool) error {
+	rounds, err := w.segments()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, round := range rounds {
+		if selected(round) {
+			if err := os.Remove(w.segmentPath(round)); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove WAL segments: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (w *UpdateWAL) segmentPath(round int) string {
+	return filepath.Join(w.dir, fmt.Sprintf("round_%d.wal", round))
+}
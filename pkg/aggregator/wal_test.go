@@ -0,0 +1,227 @@
+package aggregator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func TestUpdateWAL(t *testing.T) {
+	dir := t.TempDir()
+	plan := &federation.FLPlan{
+		Rounds:        2,
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}},
+		InitialModel:  filepath.Join(dir, "init.pt"),
+		Aggregator:    federation.AggregatorEntry{WAL: federation.WALConfig{Dir: filepath.Join(dir, "wal")}},
+	}
+	if wal, err := OpenUpdateWAL(&federation.FLPlan{}); wal != nil || err != nil {
+		t.Errorf("WAL of a plan without aggregator.wal = %v, %v", wal, err)
+	}
+	initial := encodeModel([]float32{0, 0})
+	if err := os.WriteFile(plan.InitialModel, initial, 0600); err != nil {
+		t.Fatal(err)
+	}
+	open := func() *UpdateWAL {
+		t.Helper()
+		wal, err := OpenUpdateWAL(plan)
+		if err != nil {
+			t.Fatalf("OpenUpdateWAL: %v", err)
+		}
+		t.Cleanup(func() { wal.Close() })
+		return wal
+	}
+	update := func(id string) *pb.ModelUpdate {
+		return &pb.ModelUpdate{CollaboratorId: id, ModelWeights: encodeModel([]float32{1, 2}), NumSamples: 10, Round: 1}
+	}
+
+	wal := open()
+	if recovered, err := wal.Recover(plan.Rounds); recovered != nil || err != nil {
+		t.Fatalf("Recover of an empty WAL = %+v, %v", recovered, err)
+	}
+	if err := wal.Append(1, update("c1")); err == nil {
+		t.Error("update was logged before its round started")
+	}
+	if err := wal.StartRound(1, plan.InitialModel, transport.ModelHash(initial)); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	for _, id := range []string{"c1", "c2"} {
+		if err := wal.Append(1, update(id)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := wal.Append(2, update("c3")); err == nil {
+		t.Error("update of another round was logged")
+	}
+	wal.Close()
+
+	// A crash while writing leaves a torn record behind
+	segment := filepath.Join(plan.Aggregator.WAL.Dir, "round_1.wal")
+	whole, _ := os.Stat(segment)
+	f, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{200, 0, 0, 0, 1, 2})
+	f.Close()
+
+	wal = open()
+	recovered, err := wal.Recover(plan.Rounds)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if recovered.Round != 1 || len(recovered.Updates) != 2 || recovered.Updates[1].CollaboratorId != "c2" || recovered.BaseModelHash != transport.ModelHash(initial) {
+		t.Fatalf("recovered %+v", recovered)
+	}
+	if info, _ := os.Stat(segment); info.Size() != whole.Size() {
+		t.Errorf("segment has %d bytes after recovery, want %d", info.Size(), whole.Size())
+	}
+
+	// Resuming the round logs new updates but not the replayed ones, even
+	// when a collaborator whose update is replayed sends another first
+	if err := wal.StartRound(1, plan.InitialModel, transport.ModelHash(initial)); err != nil {
+		t.Fatalf("StartRound of the recovered round: %v", err)
+	}
+	resent := update("c1")
+	resent.ModelWeights = encodeModel([]float32{3, 4})
+	for _, upd := range append([]*pb.ModelUpdate{resent}, append(recovered.Updates, update("c3"))...) {
+		if err := wal.Append(1, upd); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	model := encodeModel([]float32{1, 2})
+	modelPath := filepath.Join(dir, "round_1.pt")
+	if err := os.WriteFile(modelPath, model, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Commit(1, modelPath, transport.ModelHash(model)); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	// An aggregated round resumes the next one from its aggregate
+	wal = open()
+	if recovered, err = wal.Recover(plan.Rounds); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if recovered.Round != 2 || len(recovered.Updates) != 0 || string(recovered.BaseModel) != string(model) {
+		t.Fatalf("recovered %+v after round 1 was aggregated", recovered)
+	}
+	if _, updates, _, err := wal.readSegment(1); err != nil || len(updates) != 4 {
+		t.Errorf("round 1 logged %d updates, want 4 (%v)", len(updates), err)
+	} else if !bytes.Equal(updates[2].ModelWeights, resent.ModelWeights) || updates[3].CollaboratorId != "c3" {
+		t.Errorf("round 1 logged %v after the replayed updates, want the update c1 resent and c3's", updates[2:])
+	}
+	if err := wal.StartRound(2, modelPath, transport.ModelHash(model)); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	if _, err := os.Stat(segment); !os.IsNotExist(err) {
+		t.Errorf("segment of round 1 was kept without retain_rounds: %v", err)
+	}
+
+	// A WAL of another plan is not resumed
+	other := *plan
+	other.Rounds = 5
+	if wal, err := OpenUpdateWAL(&other); err != nil {
+		t.Fatal(err)
+	} else if _, err := wal.Recover(other.Rounds); err == nil {
+		t.Error("WAL of another plan was recovered")
+	}
+
+	// A completed run is cleared for the next one
+	if err := wal.Commit(2, modelPath, transport.ModelHash(model)); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if recovered, err := open().Recover(plan.Rounds); recovered != nil || err != nil {
+		t.Errorf("Recover of a completed run = %+v, %v", recovered, err)
+	}
+	if entries, _ := os.ReadDir(plan.Aggregator.WAL.Dir); len(entries) != 0 {
+		t.Errorf("%d segments of a completed run were kept", len(entries))
+	}
+}
+
+func TestUpdateWALRetention(t *testing.T) {
+	dir := t.TempDir()
+	plan := &federation.FLPlan{
+		Rounds:     5,
+		Aggregator: federation.AggregatorEntry{WAL: federation.WALConfig{Dir: dir, RetainRounds: 2}},
+	}
+	wal, err := OpenUpdateWAL(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	for round := 1; round <= 4; round++ {
+		if err := wal.StartRound(round, "model.pt", "hash"); err != nil {
+			t.Fatalf("StartRound: %v", err)
+		}
+		if err := wal.Commit(round, "model.pt", "hash"); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+	segments, err := wal.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 || segments[0] != 2 {
+		t.Errorf("segments of rounds %v were kept, want 2, 3 and 4", segments)
+	}
+}
+
+func TestFedAvgAggregatorReplaysWAL(t *testing.T) {
+	dir := t.TempDir()
+	plan := &federation.FLPlan{
+		Rounds:        3,
+		Collaborators: []federation.Collaborator{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}},
+		Aggregator:    federation.AggregatorEntry{WAL: federation.WALConfig{Dir: dir}},
+	}
+	base := encodeModel(make([]float32, 4))
+	newAggregator := func() *FedAvgAggregator {
+		t.Helper()
+		agg := NewFedAvgAggregator(plan)
+		agg.modelSize = 4
+		agg.currentRound = 1
+		agg.globalModel, agg.modelHash = base, transport.ModelHash(base)
+		var err error
+		if agg.wal, err = OpenUpdateWAL(plan); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { agg.wal.Close() })
+		if err := agg.wal.StartRound(1, "init.pt", agg.modelHash); err != nil {
+			t.Fatal(err)
+		}
+		return agg
+	}
+
+	agg := newAggregator()
+	for _, id := range []string{"c1", "c2", "c1"} {
+		agg.accept(&pb.ModelUpdate{CollaboratorId: id, ModelWeights: encodeModel([]float32{1, 2, 3, 4}), NumSamples: 5, Round: 1, BaseModelHash: agg.modelHash})
+	}
+	if len(agg.updates) != 2 {
+		t.Fatalf("aggregator holds %d updates", len(agg.updates))
+	}
+	agg.wal.Close()
+
+	// The restarted aggregator replays the accepted updates, and the logged
+	// duplicate is rejected again
+	recovered := newAggregator()
+	_, logged, _, err := recovered.wal.readSegment(1)
+	if err != nil || len(logged) != 3 {
+		t.Fatalf("segment holds %d updates (%v)", len(logged), err)
+	}
+	for _, upd := range logged {
+		recovered.accept(upd)
+	}
+	if len(recovered.updates) != 2 || !recovered.submitted["c1"] || !recovered.submitted["c2"] {
+		t.Errorf("replay restored %d updates, submitted %v", len(recovered.updates), recovered.submitted)
+	}
+	if ack := recovered.accept(&pb.ModelUpdate{CollaboratorId: "c3", ModelWeights: encodeModel([]float32{1, 2, 3, 4}), NumSamples: 5, Round: 1}); !ack.Success {
+		t.Fatalf("update after the replay rejected: %s", ack.Message)
+	}
+	if _, logged, _, _ = recovered.wal.readSegment(1); len(logged) != 4 {
+		t.Errorf("segment holds %d updates after the replay, want 4", len(logged))
+	}
+}
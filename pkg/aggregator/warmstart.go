@@ -0,0 +1,102 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// warmStartDir holds the registry models that runs start from
+const warmStartDir = "save"
+
+// ParseModelRef splits an initial_model_from reference into the model name
+// and the version reference, which defaults to latest
+func ParseModelRef(ref string) (name, version string) {
+	name, version, found := strings.Cut(ref, "@")
+	if !found || version == "" {
+		version = "latest"
+	}
+	return name, version
+}
+
+// WarmStart resolves the plan's initial_model_from in the model registry,
+// downloads the model and points initial_model at the local copy. It pins
+// initial_model_from to the version it resolved to, name@vN, so that the run
+// report, the manifest and the registered models record the exact parent. The
+// registry is opened from the plan's registry settings even when registering
+// is disabled. Without initial_model_from, WarmStart returns nil.
+func WarmStart(plan *federation.FLPlan) (*registry.ModelVersion, error) {
+	if plan.InitialModelFrom == "" {
+		return nil, nil
+	}
+
+	name, ref := ParseModelRef(plan.InitialModelFrom)
+	models, err := registry.Open(registry.Config(plan.Registry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open model registry: %w", err)
+	}
+	version, err := models.Get(name, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve initial_model_from %s: %w", plan.InitialModelFrom, err)
+	}
+	data, err := models.Download(version)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(warmStartDir, fmt.Sprintf("initial_%s_v%d.pt", version.Name, version.Version))
+	if err := storage.WriteFile(path, data); err != nil {
+		return nil, fmt.Errorf("failed to write initial model: %w", err)
+	}
+
+	plan.InitialModel = path
+	plan.InitialModelFrom = fmt.Sprintf("%s@v%d", version.Name, version.Version)
+	log.Printf("Warm start from %s (round %d, sha256 %.12s), saved to %s", plan.InitialModelFrom, version.Round, version.Checksum, path)
+	return version, nil
+}
+
+// pinnedModelRef returns the name and version of a model reference pinned by
+// WarmStart, or false for references that name no version number
+func pinnedModelRef(ref string) (string, int, bool) {
+	name, version := ParseModelRef(ref)
+	number, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return "", 0, false
+	}
+	return name, number, true
+}
+
+// RecordWarmStart reports the registry model a run starts from
+func (r *UpdateReporter) RecordWarmStart(version *registry.ModelVersion) {
+	if r.eventsURL == "" {
+		return
+	}
+	go r.report(r.eventsURL, "warm start", warmStartEvent(r.federationID, version))
+}
+
+// warmStartEvent describes the lineage of a warm-started run. Its data holds
+// the model name, version, round and checksum it starts from.
+func warmStartEvent(federationID string, version *registry.ModelVersion) monitoring.MonitoringEvent {
+	return monitoring.MonitoringEvent{
+		FederationID: federationID,
+		Type:         monitoring.MetricTypeLineage,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Federation starts from %s v%d (round %d)", version.Name, version.Version, version.Round),
+		Data: map[string]interface{}{
+			"model":    version.Name,
+			"version":  version.Version,
+			"round":    version.Round,
+			"checksum": version.Checksum,
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package aggregator
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+)
+
+func TestWarmStart(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	models := registry.New(registry.NewLocalBackend("registry"))
+	for round, model := range [][]float32{{1, 2}, {3, 4}} {
+		if _, err := models.Register("run-1", encodeModel(model), registry.RegisterOptions{Parent: round, Round: round + 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without initial_model_from the plan is left alone
+	plan := &federation.FLPlan{InitialModel: "save/init_model.pt"}
+	if version, err := WarmStart(plan); version != nil || err != nil || plan.InitialModel != "save/init_model.pt" {
+		t.Fatalf("WarmStart() = %v, %v, initial model %s", version, err, plan.InitialModel)
+	}
+
+	plan = &federation.FLPlan{
+		InitialModelFrom: "run-1",
+		Monitoring:       federation.MonitoringConfig{FederationID: "run-2"},
+		Registry:         federation.RegistryConfig{Enabled: true, Name: "run-1"},
+	}
+	version, err := WarmStart(plan)
+	if err != nil {
+		t.Fatalf("WarmStart() error = %v", err)
+	}
+	if version.Version != 2 || plan.InitialModelFrom != "run-1@v2" {
+		t.Errorf("Resolved v%d, initial_model_from %s; want the latest version pinned", version.Version, plan.InitialModelFrom)
+	}
+	data, err := os.ReadFile(plan.InitialModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, encodeModel([]float32{3, 4})) {
+		t.Errorf("Initial model = %v", data)
+	}
+
+	// The lineage is recorded in the report, the manifest and the registry
+	run := NewRunRecorder(plan, NewDropTracker(plan))
+	if run.Report().InitialModelFrom != "run-1@v2" || run.Manifest().InitialModelFrom != "run-1@v2" {
+		t.Errorf("Report and manifest do not record initial_model_from")
+	}
+	NewModelRegistrar(plan, run).Register(1, []float32{5, 6})
+	latest, err := models.Get("run-1", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Version != 3 || latest.Parent != 2 || latest.Metadata["initial_model_from"] != "run-1@v2" {
+		t.Errorf("Registered version does not continue the lineage: %+v", latest)
+	}
+
+	plan.InitialModelFrom = "run-1@production"
+	if _, err := WarmStart(plan); err == nil {
+		t.Error("WarmStart() without a production version succeeded")
+	}
+}
+
+func TestParseModelRef(t *testing.T) {
+	for _, tt := range []struct{ ref, name, version string }{
+		{"run-1", "run-1", "latest"},
+		{"run-1@", "run-1", "latest"},
+		{"run-1@v3", "run-1", "v3"},
+		{"mnist@production", "mnist", "production"},
+	} {
+		if name, version := ParseModelRef(tt.ref); name != tt.name || version != tt.version {
+			t.Errorf("ParseModelRef(%q) = %s, %s; want %s, %s", tt.ref, name, version, tt.name, tt.version)
+		}
+	}
+}
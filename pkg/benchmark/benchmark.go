@@ -0,0 +1,248 @@
+// Package benchmark measures the throughput and memory use of the
+// aggregation algorithms across model sizes and client counts, including
+// homomorphic aggregation of encrypted updates. Results are JSON so that runs
+// can be compared to catch performance regressions.
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// distinctUpdates is how many different update vectors a case allocates.
+// Clients share them round robin, so that large models with many clients fit
+// in memory; the algorithms only read update weights.
+const distinctUpdates = 2
+
+// Config selects the cases of a benchmark run: every algorithm with every
+// model size and client count
+type Config struct {
+	Algorithms   []string // aggregation algorithms, and Homomorphic
+	ModelSizes   []int    // parameters per model
+	ClientCounts []int
+	KeyBits      int // Paillier modulus size of the Homomorphic case; 0 for 2048
+}
+
+// Result is the measurement of one case
+type Result struct {
+	Algorithm       string  `json:"algorithm"`
+	ModelSize       int     `json:"model_size"`
+	Clients         int     `json:"clients"`
+	Iterations      int     `json:"iterations"`
+	NsPerOp         int64   `json:"ns_per_op"`
+	ParamsPerSecond float64 `json:"params_per_second"` // update parameters aggregated per second
+	BytesPerOp      int64   `json:"bytes_per_op"`      // heap allocated by one aggregation
+	AllocsPerOp     int64   `json:"allocs_per_op"`
+	UpdateBytes     int64   `json:"update_bytes"`     // size of the updates one aggregation reads
+	HeapInuseBytes  uint64  `json:"heap_inuse_bytes"` // heap in use after the case, including the updates
+	SharedUpdates   bool    `json:"shared_updates"`   // clients share update vectors
+	// Time a client takes to encrypt its update, in the Homomorphic case
+	EncryptNsPerUpdate int64 `json:"encrypt_ns_per_update,omitempty"`
+}
+
+// Report is the output of a benchmark run
+type Report struct {
+	Timestamp time.Time `json:"timestamp"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	CPUs      int       `json:"cpus"`
+	Results   []Result  `json:"results"`
+}
+
+// Run measures every case of config, calling progress after each one
+func Run(config Config, progress func(Result)) (*Report, error) {
+	if len(config.Algorithms) == 0 || len(config.ModelSizes) == 0 || len(config.ClientCounts) == 0 {
+		return nil, fmt.Errorf("benchmark requires at least one algorithm, model size and client count")
+	}
+	var key *security.PaillierPrivateKey
+	for _, name := range config.Algorithms {
+		if name == Homomorphic {
+			var err error
+			if key, err = homomorphicKey(config.KeyBits); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := aggregator.CreateAggregationAlgorithm(aggregator.AlgorithmType(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{
+		Timestamp: time.Now().UTC(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}
+	for _, size := range config.ModelSizes {
+		if size < 1 {
+			return nil, fmt.Errorf("invalid model size %d", size)
+		}
+		vectors := randomVectors(size)
+		global := make([]float32, size)
+		for _, clients := range config.ClientCounts {
+			if clients < 1 {
+				return nil, fmt.Errorf("invalid client count %d", clients)
+			}
+			updates := clientUpdates(vectors, clients)
+			for _, name := range config.Algorithms {
+				var result Result
+				var err error
+				if name == Homomorphic {
+					result, err = measureHomomorphic(key, vectors, clients)
+				} else {
+					result, err = measure(name, updates, global)
+				}
+				if err != nil {
+					return nil, err
+				}
+				report.Results = append(report.Results, result)
+				if progress != nil {
+					progress(result)
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// measure benchmarks one algorithm on updates
+func measure(name string, updates []aggregator.ClientUpdate, global []float32) (Result, error) {
+	algorithm, err := aggregator.CreateAggregationAlgorithm(aggregator.AlgorithmType(name))
+	if err != nil {
+		return Result{}, err
+	}
+	if err := algorithm.Initialize(aggregator.AlgorithmConfig{
+		AlgorithmName: name,
+		ModelSize:     len(global),
+		Mode:          federation.ModeSync,
+	}); err != nil {
+		return Result{}, fmt.Errorf("failed to initialize %s: %v", name, err)
+	}
+
+	var aggregateErr error
+	br := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := algorithm.Aggregate(updates, global); err != nil {
+				aggregateErr = err
+				b.FailNow()
+			}
+		}
+	})
+	if aggregateErr != nil {
+		return Result{}, fmt.Errorf("%s failed: %v", name, aggregateErr)
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	params := float64(len(global)) * float64(len(updates))
+	result := Result{
+		Algorithm:      name,
+		ModelSize:      len(global),
+		Clients:        len(updates),
+		Iterations:     br.N,
+		NsPerOp:        br.NsPerOp(),
+		BytesPerOp:     br.AllocedBytesPerOp(),
+		AllocsPerOp:    br.AllocsPerOp(),
+		UpdateBytes:    int64(4 * params),
+		HeapInuseBytes: mem.HeapInuse,
+		SharedUpdates:  len(updates) > distinctUpdates,
+	}
+	if result.NsPerOp > 0 {
+		result.ParamsPerSecond = params / (float64(result.NsPerOp) / 1e9)
+	}
+	return result, nil
+}
+
+// randomVectors returns the update vectors of a model size, drawn from a fixed
+// seed so that runs measure the same data
+func randomVectors(size int) [][]float32 {
+	rng := rand.New(rand.NewPCG(1, uint64(size))) // #nosec G115 - sizes are positive
+	vectors := make([][]float32, distinctUpdates)
+	for v := range vectors {
+		vectors[v] = make([]float32, size)
+		for i := range vectors[v] {
+			vectors[v][i] = rng.Float32() - 0.5
+		}
+	}
+	return vectors
+}
+
+// clientUpdates builds the updates of n clients from vectors
+func clientUpdates(vectors [][]float32, n int) []aggregator.ClientUpdate {
+	updates := make([]aggregator.ClientUpdate, n)
+	for i := range updates {
+		updates[i] = aggregator.ClientUpdate{
+			CollaboratorID: fmt.Sprintf("client-%d", i+1),
+			Weights:        vectors[i%len(vectors)],
+			Timestamp:      time.Now(),
+			Round:          1,
+			NumSamples:     100 + i,
+		}
+	}
+	return updates
+}
+
+// Compare returns a description of each case of current whose throughput
+// fell by more than tolerance, a fraction, from the same case in baseline.
+// Cases missing from baseline are not compared.
+func Compare(baseline, current *Report, tolerance float64) []string {
+	type key struct {
+		algorithm string
+		size      int
+		clients   int
+	}
+	previous := make(map[key]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		previous[key{r.Algorithm, r.ModelSize, r.Clients}] = r
+	}
+
+	var regressions []string
+	for _, r := range current.Results {
+		base, ok := previous[key{r.Algorithm, r.ModelSize, r.Clients}]
+		if !ok || base.ParamsPerSecond == 0 {
+			continue
+		}
+		change := r.ParamsPerSecond/base.ParamsPerSecond - 1
+		if change < -tolerance {
+			regressions = append(regressions, fmt.Sprintf("%s with %d parameters and %d clients: %.3g params/s, baseline %.3g (%.1f%%)",
+				r.Algorithm, r.ModelSize, r.Clients, r.ParamsPerSecond, base.ParamsPerSecond, 100*change))
+		}
+	}
+	return regressions
+}
+
+// WriteReport writes a report as indented JSON
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadReport reads a report written by WriteReport
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - reports are chosen by the operator
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid benchmark report %s: %v", path, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,147 @@
+package benchmark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// BenchmarkAggregate measures each algorithm at the smaller model sizes; use
+// fx benchmark aggregate for the large ones
+func BenchmarkAggregate(b *testing.B) {
+	for _, size := range []int{1_000_000, 10_000_000} {
+		vectors := randomVectors(size)
+		global := make([]float32, size)
+		for _, clients := range []int{10, 100} {
+			updates := clientUpdates(vectors, clients)
+			for _, name := range []aggregator.AlgorithmType{aggregator.FedAvg, aggregator.FedOpt, aggregator.FedProx} {
+				b.Run(fmt.Sprintf("%s/params=%d/clients=%d", name, size, clients), func(b *testing.B) {
+					algorithm, err := aggregator.CreateAggregationAlgorithm(name)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := algorithm.Initialize(aggregator.AlgorithmConfig{ModelSize: size, Mode: federation.ModeSync}); err != nil {
+						b.Fatal(err)
+					}
+					b.ReportAllocs()
+					b.SetBytes(int64(4 * size * clients))
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						if _, err := algorithm.Aggregate(updates, global); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{Results: []Result{
+		{Algorithm: "fedavg", ModelSize: 100, Clients: 10, ParamsPerSecond: 1000},
+		{Algorithm: "fedopt", ModelSize: 100, Clients: 10, ParamsPerSecond: 1000},
+	}}
+	current := &Report{Results: []Result{
+		{Algorithm: "fedavg", ModelSize: 100, Clients: 10, ParamsPerSecond: 950},
+		{Algorithm: "fedopt", ModelSize: 100, Clients: 10, ParamsPerSecond: 800},
+		{Algorithm: "fedprox", ModelSize: 100, Clients: 10, ParamsPerSecond: 1},
+	}}
+
+	regressions := Compare(baseline, current, 0.1)
+	if len(regressions) != 1 {
+		t.Fatalf("Compare() = %v, want only the fedopt regression", regressions)
+	}
+}
+
+func TestClientUpdatesShareVectors(t *testing.T) {
+	vectors := randomVectors(8)
+	updates := clientUpdates(vectors, 5)
+	if len(updates) != 5 {
+		t.Fatalf("updates = %d, want 5", len(updates))
+	}
+	if &updates[0].Weights[0] != &updates[distinctUpdates].Weights[0] {
+		t.Errorf("clients do not share update vectors")
+	}
+	if updates[0].CollaboratorID == updates[1].CollaboratorID {
+		t.Errorf("clients share an ID")
+	}
+}
+
+// BenchmarkHomomorphic measures how long a client takes to encrypt its
+// update, and homomorphic aggregation of the encrypted updates next to plain
+// fedavg. It is orders of magnitude slower, so the models are small.
+func BenchmarkHomomorphic(b *testing.B) {
+	key, err := homomorphicKey(defaultKeyBits)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range []int{1_000, 10_000} {
+		vectors := randomVectors(size)
+		encrypted := make([][]byte, len(vectors))
+		b.Run(fmt.Sprintf("%s/encrypt/params=%d", Homomorphic, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if encrypted[i%len(vectors)], err = security.EncryptUpdate(key.Public(), vectors[i%len(vectors)], 100); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		for v := range encrypted {
+			if encrypted[v], err = security.EncryptUpdate(key.Public(), vectors[v], 100); err != nil {
+				b.Fatal(err)
+			}
+		}
+		global := make([]float32, size)
+		for _, clients := range []int{10, 100} {
+			b.Run(fmt.Sprintf("%s/params=%d/clients=%d", Homomorphic, size, clients), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := aggregateEncrypted(key, encrypted, clients, size); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			updates := clientUpdates(vectors, clients)
+			b.Run(fmt.Sprintf("%s/params=%d/clients=%d", aggregator.FedAvg, size, clients), func(b *testing.B) {
+				algorithm, err := aggregator.CreateAggregationAlgorithm(aggregator.FedAvg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := algorithm.Initialize(aggregator.AlgorithmConfig{ModelSize: size, Mode: federation.ModeSync}); err != nil {
+					b.Fatal(err)
+				}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := algorithm.Aggregate(updates, global); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRunHomomorphic(t *testing.T) {
+	report, err := Run(Config{
+		Algorithms:   []string{Homomorphic, string(aggregator.FedAvg)},
+		ModelSizes:   []int{50},
+		ClientCounts: []int{3},
+		KeyBits:      512,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(report.Results))
+	}
+	he, plain := report.Results[0], report.Results[1]
+	if he.Algorithm != Homomorphic || he.EncryptNsPerUpdate <= 0 || he.NsPerOp <= 0 {
+		t.Errorf("homomorphic result = %+v", he)
+	}
+	if he.UpdateBytes <= plain.UpdateBytes || plain.EncryptNsPerUpdate != 0 {
+		t.Errorf("encrypted updates take %d bytes, plain ones %d", he.UpdateBytes, plain.UpdateBytes)
+	}
+}
@@ -0,0 +1,96 @@
+package benchmark
+
+import (
+	"crypto/rand"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// Homomorphic is the benchmark name of homomorphic aggregation, measured
+// against the plain algorithms: an operation is the aggregator adding up the
+// Paillier-encrypted updates of all clients and the key holder decrypting
+// the sum
+const Homomorphic = "paillier"
+
+// defaultKeyBits is the Paillier modulus size of the homomorphic case
+const defaultKeyBits = 2048
+
+// measureHomomorphic benchmarks homomorphic aggregation of the updates of
+// clients, which share vectors like the plain cases do
+func measureHomomorphic(key *security.PaillierPrivateKey, vectors [][]float32, clients int) (Result, error) {
+	size := len(vectors[0])
+	encrypted := make([][]byte, len(vectors))
+	start := time.Now()
+	for v, vector := range vectors {
+		var err error
+		if encrypted[v], err = security.EncryptUpdate(key.Public(), vector, float64(100+v)); err != nil {
+			return Result{}, err
+		}
+	}
+	encryptNs := time.Since(start).Nanoseconds() / int64(len(vectors))
+
+	var aggregateErr error
+	br := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := aggregateEncrypted(key, encrypted, clients, size); err != nil {
+				aggregateErr = err
+				b.FailNow()
+			}
+		}
+	})
+	if aggregateErr != nil {
+		return Result{}, fmt.Errorf("%s failed: %v", Homomorphic, aggregateErr)
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	params := float64(size) * float64(clients)
+	result := Result{
+		Algorithm:          Homomorphic,
+		ModelSize:          size,
+		Clients:            clients,
+		Iterations:         br.N,
+		NsPerOp:            br.NsPerOp(),
+		BytesPerOp:         br.AllocedBytesPerOp(),
+		AllocsPerOp:        br.AllocsPerOp(),
+		UpdateBytes:        int64(len(encrypted[0]) * clients),
+		HeapInuseBytes:     mem.HeapInuse,
+		SharedUpdates:      clients > distinctUpdates,
+		EncryptNsPerUpdate: encryptNs,
+	}
+	if result.NsPerOp > 0 {
+		result.ParamsPerSecond = params / (float64(result.NsPerOp) / 1e9)
+	}
+	return result, nil
+}
+
+// aggregateEncrypted adds up the encrypted updates of clients, which share
+// the encrypted vectors round robin, and decrypts the sum
+func aggregateEncrypted(key *security.PaillierPrivateKey, encrypted [][]byte, clients, size int) error {
+	sum := security.NewEncryptedSum(key.Public(), size)
+	for c := 0; c < clients; c++ {
+		if err := sum.Add(encrypted[c%len(encrypted)]); err != nil {
+			return err
+		}
+	}
+	_, err := security.DecryptSum(key, sum.Bytes(), clients, size)
+	return err
+}
+
+// homomorphicKey generates the key of the homomorphic case
+func homomorphicKey(bits int) (*security.PaillierPrivateKey, error) {
+	if bits == 0 {
+		bits = defaultKeyBits
+	}
+	key, err := security.GeneratePaillierKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a Paillier key: %v", err)
+	}
+	return key, nil
+}
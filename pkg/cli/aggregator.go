@@ -4,9 +4,21 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/ishaileshpant/fl-go/api/adminpb"
 	"github.com/ishaileshpant/fl-go/pkg/aggregator"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // HandleAggregatorCommand handles all aggregator-related commands
@@ -21,6 +33,14 @@ func HandleAggregatorCommand(args []string) error {
 	switch subcommand {
 	case "start":
 		return handleAggregatorStart(subArgs)
+	case "tune":
+		return handleAggregatorTune(subArgs)
+	case "status":
+		return handleAggregatorStatus(subArgs)
+	case "aggregate-now":
+		return handleAggregatorAggregateNow(subArgs)
+	case "log-level":
+		return handleAggregatorLogLevel(subArgs)
 	case "--help", "-h":
 		printAggregatorUsage()
 		return nil
@@ -58,10 +78,116 @@ func handleAggregatorStart(args []string) error {
 		plan.Mode = federation.ModeSync
 	}
 
+	if plan.Mode == federation.ModeDecentralized {
+		return fmt.Errorf("plan uses decentralized mode, which has no central aggregator; start collaborators only")
+	}
+
+	switch plan.Role {
+	case "", federation.RoleAggregator:
+	case federation.RoleEdgeAggregator:
+		if plan.Mode != federation.ModeSync {
+			return fmt.Errorf("edge aggregators only support sync mode")
+		}
+		if plan.Hierarchy.EdgeID == "" || plan.Hierarchy.RootAddress == "" {
+			return fmt.Errorf("role %s requires hierarchy.edge_id and hierarchy.root_address", plan.Role)
+		}
+	default:
+		return fmt.Errorf("unknown role %q (expected %s or %s)", plan.Role, federation.RoleAggregator, federation.RoleEdgeAggregator)
+	}
+
+	if plan.Clustering.Enabled {
+		if err := validateClustering(plan); err != nil {
+			return err
+		}
+	}
+
+	if clipping := plan.Security.UpdateClipping; clipping.Enabled && clipping.MaxNorm <= 0 {
+		return fmt.Errorf("security.update_clipping.max_norm must be positive")
+	}
+
+	if attestation := plan.Security.Attestation; attestation.Required && len(attestation.VerifierCommand) == 0 {
+		fmt.Printf("⚠️  security.attestation.required without verifier_command only admits evidence of verifiers built into the aggregator\n")
+	}
+
+	if plan.Security.Homomorphic.Enabled {
+		if err := validateHomomorphic(plan); err != nil {
+			return err
+		}
+	}
+
+	if plan.Aggregator.WAL.Dir != "" {
+		if err := validateWAL(plan); err != nil {
+			return err
+		}
+	}
+
+	if plan.Aggregator.AdminAddress != "" {
+		if err := validateAdminAddress(plan); err != nil {
+			return err
+		}
+	}
+
+	if async := plan.AsyncConfig; async.MaxPendingPerCollaborator < 0 || async.MaxPerCollaborator < 0 {
+		return fmt.Errorf("async_config.max_pending_per_collaborator and async_config.max_per_collaborator must not be negative")
+	}
+
+	if plan.Mode == federation.ModeAsync && plan.AsyncConfig.AdaptiveDelay.Enabled {
+		if err := validateAdaptiveDelay(plan.AsyncConfig); err != nil {
+			return err
+		}
+	}
+
+	if assignsTasks(plan) {
+		if err := validateDispatch(plan); err != nil {
+			return err
+		}
+	}
+	if err := federation.ValidateTaskArgs(plan); err != nil {
+		return err
+	}
+	if !plan.Dispatch.Enabled && len(plan.Tasks.Train.Schedule) > 0 {
+		return fmt.Errorf("tasks.train.schedule requires dispatch.enabled, which sends the scheduled values to collaborators")
+	}
+
+	if plan.InitialModelFrom != "" {
+		if plan.Role == federation.RoleEdgeAggregator {
+			return fmt.Errorf("initial_model_from is not supported by edge aggregators, which start from the root aggregator's model")
+		}
+		version, err := aggregator.WarmStart(plan)
+		if err != nil {
+			return fmt.Errorf("failed to warm-start: %v", err)
+		}
+		aggregator.NewUpdateReporter(plan).RecordWarmStart(version)
+	}
+
 	fmt.Printf("🚀 Starting aggregator...\n")
 	fmt.Printf("📊 Configuration:\n")
 	fmt.Printf("   Mode: %s\n", plan.Mode)
 	fmt.Printf("   Address: %s\n", plan.Aggregator.Address)
+	if plan.Aggregator.AdminAddress != "" {
+		fmt.Printf("   Admin Service: %s (mTLS)\n", plan.Aggregator.AdminAddress)
+	}
+	if plan.Role == federation.RoleEdgeAggregator {
+		fmt.Printf("   Role: %s (%s -> root %s)\n", plan.Role, plan.Hierarchy.EdgeID, plan.Hierarchy.RootAddress)
+	}
+	if plan.Dispatch.Enabled {
+		fmt.Printf("   Task Dispatch: enabled\n")
+	}
+	if plan.Clustering.Enabled {
+		fmt.Printf("   Clustering: enabled\n")
+	}
+	if clipping := plan.Security.UpdateClipping; clipping.Enabled {
+		fmt.Printf("   Update Clipping: max norm %g\n", clipping.MaxNorm)
+	}
+	if plan.Security.Homomorphic.Enabled {
+		fmt.Printf("   Homomorphic Aggregation: enabled (experimental)\n")
+	}
+	if wal := plan.Aggregator.WAL; wal.Dir != "" {
+		fmt.Printf("   Update WAL: %s (fsync: %t, retained rounds: %d)\n", wal.Dir, wal.Fsync, wal.RetainRounds)
+	}
+	if attestation := plan.Security.Attestation; attestation.Required {
+		fmt.Printf("   Attestation: required (%d allowed measurements)\n", len(attestation.Measurements))
+	}
 
 	// Display algorithm information
 	algorithmName := "fedavg" // default
@@ -84,11 +210,47 @@ func handleAggregatorStart(args []string) error {
 		fmt.Printf("     Max Staleness: %d\n", plan.AsyncConfig.MaxStaleness)
 		fmt.Printf("     Min Updates: %d\n", plan.AsyncConfig.MinUpdates)
 		fmt.Printf("     Aggregation Delay: %ds\n", plan.AsyncConfig.AggregationDelay)
+		if adaptive := plan.AsyncConfig.AdaptiveDelay; adaptive.Enabled {
+			percentile := adaptive.Percentile
+			if percentile == 0 {
+				percentile = 0.9
+			}
+			fmt.Printf("     Adaptive Delay: p%g staleness under %ds\n", percentile*100, adaptive.TargetStaleness)
+		}
 		fmt.Printf("     Staleness Weight: %.3f\n", plan.AsyncConfig.StalenessWeight)
+		if plan.AsyncConfig.MaxPendingPerCollaborator > 0 {
+			fmt.Printf("     Max Pending per Collaborator: %d\n", plan.AsyncConfig.MaxPendingPerCollaborator)
+		}
+		if plan.AsyncConfig.MaxPerCollaborator > 0 {
+			fmt.Printf("     Max per Collaborator per Aggregation: %d\n", plan.AsyncConfig.MaxPerCollaborator)
+		}
+		if plan.AsyncConfig.MaxRounds > 0 {
+			fmt.Printf("     Max Rounds: %d\n", plan.AsyncConfig.MaxRounds)
+		}
+		if plan.AsyncConfig.MaxDuration > 0 {
+			fmt.Printf("     Max Duration: %ds\n", plan.AsyncConfig.MaxDuration)
+		}
+		if plan.AsyncConfig.ConvergenceThreshold > 0 {
+			fmt.Printf("     Convergence Threshold: %g\n", plan.AsyncConfig.ConvergenceThreshold)
+		}
 	}
 
-	fmt.Printf("   Collaborators: %d\n", len(plan.Collaborators))
-	fmt.Printf("   Initial Model: %s\n", plan.InitialModel)
+	evaluators := 0
+	for _, collab := range plan.Collaborators {
+		if collab.Role == federation.CollaboratorEvaluator {
+			evaluators++
+		}
+	}
+	if evaluators > 0 {
+		fmt.Printf("   Collaborators: %d (%d evaluators)\n", len(plan.Collaborators), evaluators)
+	} else {
+		fmt.Printf("   Collaborators: %d\n", len(plan.Collaborators))
+	}
+	if plan.InitialModelFrom != "" {
+		fmt.Printf("   Initial Model: %s (from %s)\n", plan.InitialModel, plan.InitialModelFrom)
+	} else {
+		fmt.Printf("   Initial Model: %s\n", plan.InitialModel)
+	}
 	fmt.Printf("   Output Model: %s\n", plan.OutputModel)
 
 	agg := aggregator.NewAggregator(plan)
@@ -96,13 +258,531 @@ func handleAggregatorStart(args []string) error {
 	fmt.Printf("\n🎯 Aggregator ready! Waiting for collaborators to connect...\n")
 	fmt.Printf("💡 To start collaborators, run: fx collaborator start <name>\n\n")
 
-	if err := agg.Start(context.Background()); err != nil {
-		return fmt.Errorf("aggregator failed: %v", err)
+	// Interrupts abort the run cleanly so that the run report is still written
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
+	if config := plan.Monitoring; config.Enabled && config.CollectResourceMetrics && config.MonitoringServerURL != "" {
+		source := "aggregator"
+		if config.FederationID != "" {
+			source += "-" + config.FederationID
+		}
+		interval := time.Duration(config.ReportInterval) * time.Second
+		go monitoring.ReportResources(ctx, config.MonitoringServerURL, config.APIKey, source, interval, monitoring.NewResourceSampler("."))
+	}
+
+	if tunable, ok := agg.(aggregator.AsyncTunable); ok && plan.Mode == federation.ModeAsync {
+		go reloadAsyncConfigOnHangup(ctx, planPath, tunable)
 	}
 
-	fmt.Printf("✅ Federated learning completed successfully!\n")
-	fmt.Printf("📄 Final model saved to: %s\n", plan.OutputModel)
+	runErr := agg.Start(ctx)
+
+	report := agg.Report()
+	reportPath, err := aggregator.PublishReport(plan, report)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	printRunReport(report, reportPath)
+	if manifestPath, err := aggregator.PublishManifest(plan, agg.Manifest()); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else {
+		fmt.Printf("🔁 Run manifest: %s\n", manifestPath)
+	}
+
+	// Only a completed run exits successfully, so that scripts can tell an
+	// aborted run from a finished one
+	switch {
+	case report.Status == aggregator.RunCompleted:
+		fmt.Printf("✅ Federated learning completed successfully!\n")
+		fmt.Printf("📄 Final model saved to: %s\n", plan.OutputModel)
+		return nil
+	case report.Status == aggregator.RunAborted:
+		fmt.Printf("🛑 Federated learning aborted after %d rounds\n", report.RoundsCompleted)
+		return fmt.Errorf("aggregator run aborted")
+	case runErr != nil:
+		return fmt.Errorf("aggregator failed: %v", runErr)
+	default:
+		return fmt.Errorf("aggregator run %s", report.Status)
+	}
+}
 
+// validateClustering checks that the aggregator can cluster the plan's
+// collaborators
+func validateClustering(plan *federation.FLPlan) error {
+	if plan.Mode != federation.ModeSync {
+		return fmt.Errorf("clustering requires sync mode")
+	}
+	if plan.Role == federation.RoleEdgeAggregator {
+		return fmt.Errorf("clustering is not supported by edge aggregators")
+	}
+	if plan.Clustering.Clusters < 0 || plan.Clustering.ReclusterEvery < 0 {
+		return fmt.Errorf("clustering.clusters and clustering.recluster_every must be positive")
+	}
+	clusters := plan.Clustering.Clusters
+	if clusters == 0 {
+		clusters = federation.DefaultClusters
+	}
+	if clusters > len(plan.Collaborators) {
+		return fmt.Errorf("clustering.clusters (%d) exceeds the number of collaborators (%d)", clusters, len(plan.Collaborators))
+	}
+	return nil
+}
+
+// validateHomomorphic checks that a plan with encrypted updates can be run:
+// only the sync FedAvg aggregator sums ciphertexts, and features that need to
+// read single updates cannot work with them
+func validateHomomorphic(plan *federation.FLPlan) error {
+	he := plan.Security.Homomorphic
+	switch {
+	case plan.Mode != federation.ModeSync:
+		return fmt.Errorf("homomorphic aggregation requires sync mode")
+	case plan.Algorithm.Name != "" && plan.Algorithm.Name != "fedavg":
+		return fmt.Errorf("homomorphic aggregation only supports the fedavg algorithm")
+	case plan.Clustering.Enabled:
+		return fmt.Errorf("homomorphic aggregation does not support clustering")
+	case plan.Role == federation.RoleEdgeAggregator:
+		return fmt.Errorf("homomorphic aggregation is not supported by edge aggregators")
+	case plan.Dispatch.Enabled:
+		return fmt.Errorf("homomorphic aggregation does not support task dispatch")
+	case plan.Security.UpdateClipping.Enabled:
+		return fmt.Errorf("encrypted updates cannot be clipped; disable security.update_clipping")
+	case plan.Aggregator.DeltaTransfer || plan.Aggregator.Streaming || plan.Aggregator.MmapModel:
+		return fmt.Errorf("homomorphic aggregation does not support delta_transfer, streaming or mmap_model")
+	case plan.Aggregator.UpdatesDir != "":
+		return fmt.Errorf("encrypted updates cannot be archived for re-aggregation; unset aggregator.updates_dir")
+	case he.PublicKeyPath == "":
+		return fmt.Errorf("security.homomorphic requires public_key_path")
+	case he.KeyHolderURL == "" && he.PrivateKeyPath == "":
+		return fmt.Errorf("security.homomorphic requires key_holder_url or private_key_path")
+	}
+	return nil
+}
+
+// assignsTasks tells whether the aggregator of a plan assigns tasks: with task
+// dispatch, or to collaborators with a role other than trainer, which are
+// evaluators unless the role is misspelled
+func assignsTasks(plan *federation.FLPlan) bool {
+	if plan.Dispatch.Enabled {
+		return true
+	}
+	for _, collab := range plan.Collaborators {
+		if collab.Role != "" && collab.Role != federation.CollaboratorTrainer {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDispatch checks that a plan with task dispatch or evaluators can be
+// run: only the sync FedAvg aggregator assigns tasks, and it needs at least
+// one trainer
+func validateDispatch(plan *federation.FLPlan) error {
+	trainers := 0
+	for _, collab := range plan.Collaborators {
+		switch collab.Role {
+		case "", federation.CollaboratorTrainer:
+			trainers++
+		case federation.CollaboratorEvaluator:
+		default:
+			return fmt.Errorf("collaborator %s has unknown role %q (expected %s or %s)",
+				collab.ID, collab.Role, federation.CollaboratorTrainer, federation.CollaboratorEvaluator)
+		}
+	}
+
+	feature := "task dispatch"
+	if !plan.Dispatch.Enabled {
+		feature = "role " + string(federation.CollaboratorEvaluator)
+	}
+	if plan.Mode != federation.ModeSync {
+		return fmt.Errorf("%s requires sync mode", feature)
+	}
+	if plan.Algorithm.Name != "" && plan.Algorithm.Name != "fedavg" {
+		return fmt.Errorf("%s only supports the fedavg algorithm", feature)
+	}
+	if plan.Clustering.Enabled {
+		return fmt.Errorf("%s does not support clustering", feature)
+	}
+	if plan.Role == federation.RoleEdgeAggregator {
+		return fmt.Errorf("%s is not supported by edge aggregators", feature)
+	}
+	if trainers == 0 {
+		return fmt.Errorf("%s requires at least one collaborator with role %s", feature, federation.CollaboratorTrainer)
+	}
+	return nil
+}
+
+// validateAdaptiveDelay checks the bounds and target of an adaptive async
+// aggregation delay
+func validateAdaptiveDelay(config federation.AsyncConfig) error {
+	adaptive := config.AdaptiveDelay
+	switch {
+	case adaptive.TargetStaleness <= 0:
+		return fmt.Errorf("async_config.adaptive_delay.target_staleness must be positive")
+	case config.MaxStaleness > 0 && adaptive.TargetStaleness > config.MaxStaleness:
+		return fmt.Errorf("async_config.adaptive_delay.target_staleness must not exceed max_staleness")
+	case adaptive.Percentile < 0 || adaptive.Percentile > 1:
+		return fmt.Errorf("async_config.adaptive_delay.percentile must be in (0, 1]")
+	case adaptive.MinDelay < 0:
+		return fmt.Errorf("async_config.adaptive_delay.min_delay must not be negative")
+	case adaptive.MinDelay > config.AggregationDelay:
+		return fmt.Errorf("async_config.adaptive_delay.min_delay must not exceed aggregation_delay")
+	}
+	return nil
+}
+
+// validateWAL checks that a plan with an update WAL can be run: only the sync
+// FedAvg aggregator logs its rounds and resumes them
+func validateWAL(plan *federation.FLPlan) error {
+	switch {
+	case plan.Mode != federation.ModeSync:
+		return fmt.Errorf("aggregator.wal requires sync mode")
+	case plan.Algorithm.Name != "" && plan.Algorithm.Name != "fedavg":
+		return fmt.Errorf("aggregator.wal only supports the fedavg algorithm")
+	case plan.Clustering.Enabled:
+		return fmt.Errorf("aggregator.wal does not support clustering")
+	case plan.Role == federation.RoleEdgeAggregator:
+		return fmt.Errorf("aggregator.wal is not supported by edge aggregators")
+	case plan.Aggregator.WAL.RetainRounds < 0:
+		return fmt.Errorf("aggregator.wal.retain_rounds must not be negative")
+	}
+	return nil
+}
+
+// validateAdminAddress checks that the plan's admin address can only be
+// reached with a client certificate and the admin token
+func validateAdminAddress(plan *federation.FLPlan) error {
+	switch {
+	case plan.Role == federation.RoleEdgeAggregator:
+		return fmt.Errorf("aggregator.admin_address is not supported by edge aggregators, which follow their root")
+	case !plan.Security.TLS.Enabled:
+		return fmt.Errorf("aggregator.admin_address requires security.tls.enabled, since the admin address only accepts clients with a certificate")
+	case plan.Aggregator.AdminToken == "":
+		return fmt.Errorf("aggregator.admin_address requires aggregator.admin_token, since collaborators hold certificates of the same CA")
+	case plan.Aggregator.AdminAddress == plan.Aggregator.Address:
+		return fmt.Errorf("aggregator.admin_address must differ from aggregator.address")
+	}
+	return nil
+}
+
+// printRunReport prints the exit summary of an aggregator run
+func printRunReport(report *aggregator.RunReport, reportPath string) {
+	if report.CompletionReason != "" {
+		fmt.Printf("\n📊 Run summary (%s: %s)\n", report.Status, report.CompletionReason)
+	} else {
+		fmt.Printf("\n📊 Run summary (%s)\n", report.Status)
+	}
+	fmt.Printf("   Federation: %s\n", report.FederationID)
+	fmt.Printf("   Duration: %.1fs\n", report.DurationSeconds)
+	if report.RoundsPlanned > 0 {
+		fmt.Printf("   Rounds: %d/%d\n", report.RoundsCompleted, report.RoundsPlanned)
+	} else {
+		fmt.Printf("   Rounds: %d\n", report.RoundsCompleted)
+	}
+
+	if len(report.Participation) > 0 {
+		fmt.Printf("   Participation:\n")
+		fmt.Printf("     %-20s %8s %8s %8s\n", "COLLABORATOR", "ROUNDS", "UPDATES", "DROPPED")
+		for _, p := range report.Participation {
+			fmt.Printf("     %-20s %8d %8d %8d\n", p.CollaboratorID, p.RoundsParticipated, p.UpdatesAccepted, p.UpdatesDropped)
+		}
+	}
+
+	if len(report.FinalMetrics) > 0 {
+		keys := make([]string, 0, len(report.FinalMetrics))
+		for key := range report.FinalMetrics {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Printf("   Final metrics:\n")
+		for _, key := range keys {
+			fmt.Printf("     %s: %g\n", key, report.FinalMetrics[key])
+		}
+	}
+
+	if len(report.Artifacts) > 0 {
+		fmt.Printf("   Artifacts: %d (last: %s)\n", len(report.Artifacts), report.Artifacts[len(report.Artifacts)-1])
+	}
+
+	for _, runErr := range report.Errors {
+		fmt.Printf("   ❌ %s\n", runErr)
+	}
+
+	if reportPath != "" {
+		fmt.Printf("   Report: %s\n\n", reportPath)
+	}
+}
+
+// reloadAsyncConfigOnHangup re-reads the plan on SIGHUP and applies its
+// runtime-tunable async settings to the running aggregator
+func reloadAsyncConfigOnHangup(ctx context.Context, planPath string, tunable aggregator.AsyncTunable) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-hangup:
+			plan, err := federation.LoadPlan(planPath)
+			if err != nil {
+				fmt.Printf("⚠️  Failed to reload plan: %v\n", err)
+				continue
+			}
+			if _, err := tunable.TuneAsync(aggregator.TuningFromConfig(plan.AsyncConfig)); err != nil {
+				fmt.Printf("⚠️  Failed to apply async config from %s: %v\n", planPath, err)
+				continue
+			}
+			fmt.Printf("🔄 Reloaded async config from %s\n", planPath)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleAggregatorTune shows or changes the async settings of a running
+// aggregator through its AdminService
+func handleAggregatorTune(args []string) error {
+	client, rest, err := connectAdmin(args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tuning := &adminpb.AsyncTuning{}
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--max-staleness", "--min-updates", "--aggregation-delay":
+			if i+1 >= len(rest) {
+				return fmt.Errorf("missing value for %s", rest[i])
+			}
+			n, err := strconv.ParseInt(rest[i+1], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %v", rest[i], err)
+			}
+			value := int32(n)
+			switch rest[i] {
+			case "--max-staleness":
+				tuning.MaxStaleness = &value
+			case "--min-updates":
+				tuning.MinUpdates = &value
+			default:
+				tuning.AggregationDelay = &value
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown option: %s", rest[i])
+		}
+	}
+	ctx, cancel := client.call()
+	defer cancel()
+
+	var current *adminpb.AsyncConfig
+	if tuning.MaxStaleness != nil || tuning.MinUpdates != nil || tuning.AggregationDelay != nil {
+		current, err = client.TuneAsync(ctx, tuning)
+	} else {
+		current, err = client.GetAsyncConfig(ctx, &adminpb.AsyncConfigRequest{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to tune the aggregator: %v", err)
+	}
+
+	fmt.Printf("⚙️  Async config:\n")
+	fmt.Printf("   Max Staleness: %d\n", current.MaxStaleness)
+	fmt.Printf("   Min Updates: %d\n", current.MinUpdates)
+	fmt.Printf("   Aggregation Delay: %ds\n", current.AggregationDelay)
+	return nil
+}
+
+// adminTimeout bounds a call to the AdminService of an aggregator
+const adminTimeout = 10 * time.Second
+
+// adminClient is a connection to the AdminService of a running aggregator
+type adminClient struct {
+	adminpb.AdminServiceClient
+	conn   *grpc.ClientConn
+	token  string
+	reason string
+}
+
+// connectAdmin connects to the admin address of an aggregator with the client
+// certificate of its plan. It returns the arguments that are not admin
+// options.
+func connectAdmin(args []string) (*adminClient, []string, error) {
+	planPath, address := "plan.yaml", ""
+	client := &adminClient{token: os.Getenv("FLGO_ADMIN_TOKEN")}
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--plan", "-p", "--address", "-a", "--token", "-t", "--reason":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("missing value for %s", args[i])
+			}
+			switch value := args[i+1]; args[i] {
+			case "--plan", "-p":
+				planPath = value
+			case "--address", "-a":
+				address = value
+			case "--token", "-t":
+				client.token = value
+			default:
+				client.reason = value
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	plan, err := federation.LoadPlan(planPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load plan: %v", err)
+	}
+	if address == "" {
+		address = plan.Aggregator.AdminAddress
+	}
+	if address == "" {
+		return nil, nil, fmt.Errorf("the plan sets no aggregator.admin_address; pass the admin address with --address")
+	}
+	if client.token == "" {
+		client.token = plan.Aggregator.AdminToken
+	}
+	if !plan.Security.TLS.Enabled {
+		return nil, nil, fmt.Errorf("the admin address only accepts clients with a certificate; enable security.tls in the plan")
+	}
+
+	// The client presents the certificates the aggregator's workspace holds
+	config := security.TLSConfig(plan.Security.TLS)
+	config.AutoGenerateCert = false
+	tlsManager, err := security.NewTLSManager(config, "certs")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize TLS: %v", err)
+	}
+	dialOpts, err := tlsManager.NewClientDialOptions()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get client dial options: %v", err)
+	}
+	if client.conn, err = grpc.NewClient(address, dialOpts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the admin address: %v", err)
+	}
+	client.AdminServiceClient = adminpb.NewAdminServiceClient(client.conn)
+	return client, rest, nil
+}
+
+// call returns the context of an admin call, which carries the admin token
+func (c *adminClient) call() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", c.token)
+	}
+	return ctx, cancel
+}
+
+func (c *adminClient) Close() error {
+	return c.conn.Close()
+}
+
+func handleAggregatorStatus(args []string) error {
+	client, rest, err := connectAdmin(args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if len(rest) > 0 {
+		return fmt.Errorf("unknown option: %s", rest[0])
+	}
+	ctx, cancel := client.call()
+	defer cancel()
+
+	st, err := client.GetStatus(ctx, &adminpb.StatusRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to get the aggregator status: %v", err)
+	}
+	round, err := client.GetRoundState(ctx, &adminpb.RoundStateRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to get the round state: %v", err)
+	}
+	collaborators, err := client.ListCollaborators(ctx, &adminpb.ListCollaboratorsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list collaborators: %v", err)
+	}
+
+	fmt.Printf("🛰️  Aggregator of federation %s\n", st.FederationId)
+	fmt.Printf("   State: %s\n", st.State)
+	fmt.Printf("   Mode: %s (%s)\n", st.Mode, st.Algorithm)
+	fmt.Printf("   Started: %s\n", time.Unix(st.StartTime, 0).Format(time.RFC3339))
+	if st.RoundsPlanned > 0 {
+		fmt.Printf("   Rounds: %d/%d completed\n", st.RoundsCompleted, st.RoundsPlanned)
+	} else {
+		fmt.Printf("   Rounds: %d completed\n", st.RoundsCompleted)
+	}
+	fmt.Printf("   Updates: %d accepted, %d dropped\n", st.UpdatesAccepted, st.UpdatesDropped)
+	fmt.Printf("   Log Level: %s\n", st.LogLevel)
+
+	if round.Round > 0 {
+		fmt.Printf("\n🔄 Round %d: %d/%d updates\n", round.Round, len(round.Received), round.Expected)
+		if round.ModelHash != "" {
+			fmt.Printf("   Model: %.12s\n", round.ModelHash)
+		}
+		if len(round.Pending) > 0 {
+			fmt.Printf("   Waiting for: %s\n", strings.Join(round.Pending, ", "))
+		}
+	}
+
+	fmt.Printf("\n👥 Collaborators:\n")
+	fmt.Printf("   %-20s %-10s %8s %8s %8s  %s\n", "COLLABORATOR", "ROLE", "ROUNDS", "UPDATES", "DROPPED", "LAST UPDATE")
+	for _, c := range collaborators.Collaborators {
+		lastUpdate := "-"
+		if c.LastUpdate > 0 {
+			lastUpdate = time.Unix(c.LastUpdate, 0).Format(time.RFC3339)
+		}
+		if c.Pending {
+			lastUpdate += " (pending)"
+		}
+		fmt.Printf("   %-20s %-10s %8d %8d %8d  %s\n", c.Id, c.Role, c.RoundsParticipated, c.UpdatesAccepted, c.UpdatesDropped, lastUpdate)
+	}
+	return nil
+}
+
+func handleAggregatorAggregateNow(args []string) error {
+	client, rest, err := connectAdmin(args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if len(rest) > 0 {
+		return fmt.Errorf("unknown option: %s", rest[0])
+	}
+	ctx, cancel := client.call()
+	defer cancel()
+
+	round, err := client.TriggerAggregationNow(ctx, &adminpb.ControlRequest{Reason: client.reason})
+	if err != nil {
+		return fmt.Errorf("failed to trigger aggregation: %v", err)
+	}
+	fmt.Printf("⚡ Aggregating round %d with %d/%d updates\n", round.Round, len(round.Received), round.Expected)
+	if len(round.Pending) > 0 {
+		fmt.Printf("   Not waiting for: %s\n", strings.Join(round.Pending, ", "))
+	}
+	return nil
+}
+
+func handleAggregatorLogLevel(args []string) error {
+	client, rest, err := connectAdmin(args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if len(rest) != 1 {
+		return fmt.Errorf("log-level requires one level (debug, info or warn)")
+	}
+	ctx, cancel := client.call()
+	defer cancel()
+
+	resp, err := client.SetLogLevel(ctx, &adminpb.LogLevelRequest{Level: rest[0]})
+	if err != nil {
+		return fmt.Errorf("failed to set the log level: %v", err)
+	}
+	fmt.Printf("📝 Log level changed from %s to %s\n", resp.Previous, resp.Level)
 	return nil
 }
 
@@ -113,12 +793,30 @@ func printAggregatorUsage() {
 	fmt.Println("  fx aggregator <subcommand> [options]")
 	fmt.Println()
 	fmt.Println("Available Subcommands:")
-	fmt.Println("  start     Start the aggregator")
+	fmt.Println("  start          Start the aggregator")
+	fmt.Println("  tune           Show or change the async settings of a running aggregator")
+	fmt.Println("  status         Show the state, round and collaborators of a running aggregator")
+	fmt.Println("  aggregate-now  Aggregate the updates received so far without waiting for the rest")
+	fmt.Println("  log-level      Change the log level of a running aggregator (debug, info or warn)")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --plan, -p    Path to plan.yaml file (default: plan.yaml)")
 	fmt.Println()
+	fmt.Println("Tune Options:")
+	fmt.Println("  --max-staleness N      Maximum update staleness in seconds")
+	fmt.Println("  --min-updates N        Minimum updates before aggregating")
+	fmt.Println("  --aggregation-delay N  Seconds between aggregation attempts")
+	fmt.Println()
+	fmt.Println("Tune, Status, Aggregate-now and Log-level Options:")
+	fmt.Println("  --plan, -p             Plan of the aggregator, for its admin address and TLS settings (default: plan.yaml)")
+	fmt.Println("  --address, -a          Admin address (default: aggregator.admin_address of the plan)")
+	fmt.Println("  --token, -t            Admin token (default: $FLGO_ADMIN_TOKEN, then aggregator.admin_token of the plan)")
+	fmt.Println("  --reason               Reason logged by the aggregator (aggregate-now)")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  fx aggregator start                    # Start with plan.yaml")
 	fmt.Println("  fx aggregator start --plan my_plan.yaml # Start with custom plan")
+	fmt.Println("  fx aggregator tune --min-updates 3     # Aggregate after 3 updates from now on")
+	fmt.Println("  fx aggregator status                   # Show what the aggregator of plan.yaml is doing")
+	fmt.Println("  fx aggregator log-level debug          # Log the details of each update")
 }
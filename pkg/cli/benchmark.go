@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/benchmark"
+)
+
+// HandleBenchmarkCommand handles the performance benchmark commands
+func HandleBenchmarkCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("benchmark command requires a subcommand (aggregate)")
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printBenchmarkUsage()
+		return nil
+	case "aggregate":
+		return handleBenchmarkAggregate(args[1:])
+	default:
+		return fmt.Errorf("unknown benchmark subcommand: %s", args[0])
+	}
+}
+
+// handleBenchmarkAggregate measures the aggregation algorithms
+func handleBenchmarkAggregate(args []string) error {
+	options := map[string]string{
+		"--algorithms": "fedavg,fedopt,fedprox",
+		"--sizes":      "1M,10M,100M",
+		"--clients":    "10,100",
+		"--tolerance":  "0.1",
+	}
+
+	aliases := map[string]string{"-a": "--algorithms", "-s": "--sizes", "-c": "--clients", "-o": "--output", "-k": "--key-bits"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		switch arg {
+		case "--algorithms", "--sizes", "--clients", "--output", "--baseline", "--tolerance", "--key-bits":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			options[arg] = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown benchmark option: %s", arg)
+		}
+	}
+
+	config := benchmark.Config{Algorithms: splitList(options["--algorithms"])}
+	for _, s := range splitList(options["--sizes"]) {
+		size, err := parseParamCount(s)
+		if err != nil {
+			return err
+		}
+		config.ModelSizes = append(config.ModelSizes, size)
+	}
+	for _, s := range splitList(options["--clients"]) {
+		clients, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid client count: %s", s)
+		}
+		config.ClientCounts = append(config.ClientCounts, clients)
+	}
+	if bits, ok := options["--key-bits"]; ok {
+		var err error
+		if config.KeyBits, err = strconv.Atoi(bits); err != nil {
+			return fmt.Errorf("invalid --key-bits: %s", bits)
+		}
+	}
+	tolerance, err := strconv.ParseFloat(options["--tolerance"], 64)
+	if err != nil || tolerance < 0 {
+		return fmt.Errorf("invalid --tolerance: %s", options["--tolerance"])
+	}
+
+	var baseline *benchmark.Report
+	if path, ok := options["--baseline"]; ok {
+		if baseline, err = benchmark.LoadReport(path); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%-9s %-12s %-8s %-14s %-16s %-14s\n", "ALGORITHM", "PARAMS", "CLIENTS", "MS/OP", "PARAMS/S", "ALLOC/OP")
+	report, err := benchmark.Run(config, func(r benchmark.Result) {
+		fmt.Printf("%-9s %-12d %-8d %-14.2f %-16.3g %-14s\n", r.Algorithm, r.ModelSize, r.Clients,
+			float64(r.NsPerOp)/1e6, r.ParamsPerSecond, formatBytes(r.BytesPerOp))
+		if r.EncryptNsPerUpdate > 0 {
+			fmt.Printf("          clients encrypt an update in %.2f ms, into %s\n",
+				float64(r.EncryptNsPerUpdate)/1e6, formatBytes(r.UpdateBytes/int64(r.Clients)))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if path, ok := options["--output"]; ok {
+		if err := benchmark.WriteReport(path, report); err != nil {
+			return fmt.Errorf("failed to write benchmark report: %v", err)
+		}
+		fmt.Printf("Results written to %s\n", path)
+	}
+
+	if baseline != nil {
+		regressions := benchmark.Compare(baseline, report, tolerance)
+		if len(regressions) > 0 {
+			fmt.Printf("❌ Throughput regressed by more than %.0f%%:\n", 100*tolerance)
+			for _, r := range regressions {
+				fmt.Printf("   - %s\n", r)
+			}
+			return fmt.Errorf("%d benchmark cases regressed", len(regressions))
+		}
+		fmt.Printf("✅ No case regressed by more than %.0f%% from %s\n", 100*tolerance, options["--baseline"])
+	}
+	return nil
+}
+
+// splitList splits a comma-separated option value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseParamCount parses a parameter count such as 500000, 10K, 1M or 1G
+func parseParamCount(s string) (int, error) {
+	multiplier := 1
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		multiplier = 1_000
+	case "M":
+		multiplier = 1_000_000
+	case "G":
+		multiplier = 1_000_000_000
+	}
+	digits := s
+	if multiplier > 1 {
+		digits = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid model size: %s", s)
+	}
+	return n * multiplier, nil
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func printBenchmarkUsage() {
+	fmt.Println("Benchmark Commands:")
+	fmt.Println("  fx benchmark aggregate [options]   # Measure aggregation throughput and memory")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --algorithms, -a   Algorithms to measure (default: fedavg,fedopt,fedprox); paillier measures")
+	fmt.Println("                     homomorphic aggregation of encrypted updates, best with small --sizes")
+	fmt.Println("  --sizes, -s        Model sizes in parameters, with K, M or G suffixes (default: 1M,10M,100M)")
+	fmt.Println("  --clients, -c      Client counts (default: 10,100)")
+	fmt.Println("  --output, -o       Write the results as JSON to this path")
+	fmt.Println("  --baseline         Fail when throughput fell from this earlier JSON report")
+	fmt.Println("  --tolerance        Allowed throughput drop against the baseline (default: 0.1)")
+	fmt.Println("  --key-bits, -k     Paillier modulus size of the paillier case (default: 2048)")
+}
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
 	"github.com/ishaileshpant/fl-go/pkg/collaborator"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
 )
 
 // HandleCollaboratorCommand handles all collaborator-related commands
@@ -37,6 +40,11 @@ func handleCollaboratorStart(args []string) error {
 
 	// Parse flags
 	planPath := "plan.yaml"
+	localConfigPath := ""
+	daemon := false
+	adminAddress := collaborator.DefaultAdminAddress
+	adminToken := os.Getenv("FLGO_COLLABORATOR_ADMIN_TOKEN")
+	chaosSpec := ""
 
 	for i, arg := range args[1:] {
 		switch arg {
@@ -44,6 +52,32 @@ func handleCollaboratorStart(args []string) error {
 			if i+2 < len(args) {
 				planPath = args[i+2]
 			}
+		case "--local-config":
+			if i+2 < len(args) {
+				localConfigPath = args[i+2]
+			}
+		case "--daemon", "-d":
+			daemon = true
+		case "--admin-address":
+			if i+2 < len(args) {
+				adminAddress = args[i+2]
+			}
+		case "--admin-token":
+			if i+2 < len(args) {
+				adminToken = args[i+2]
+			}
+		case "--chaos":
+			if i+2 < len(args) {
+				chaosSpec = args[i+2]
+			}
+		}
+	}
+
+	var chaos collaborator.ChaosConfig
+	if chaosSpec != "" {
+		var err error
+		if chaos, err = collaborator.ParseChaos(chaosSpec); err != nil {
+			return err
 		}
 	}
 
@@ -65,13 +99,30 @@ func handleCollaboratorStart(args []string) error {
 
 	// Find this collaborator in the plan
 	var found bool
+	role := federation.CollaboratorTrainer
 	for _, collab := range plan.Collaborators {
 		if collab.ID == collaboratorName {
 			found = true
+			if collab.Role != "" {
+				role = collab.Role
+			}
 			break
 		}
 	}
 
+	if plan.Dispatch.Enabled || role == federation.CollaboratorEvaluator {
+		if err := validateDispatch(plan); err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("collaborator '%s' is not in the plan; task dispatch only serves plan collaborators", collaboratorName)
+		}
+	}
+
+	if daemon && !plan.Dispatch.Enabled {
+		return fmt.Errorf("daemon mode requires dispatch.enabled in the plan so that the aggregator assigns the tasks")
+	}
+
 	if !found {
 		fmt.Printf("⚠️  Warning: Collaborator '%s' not found in plan. Available collaborators:\n", collaboratorName)
 		for _, collab := range plan.Collaborators {
@@ -84,9 +135,17 @@ func handleCollaboratorStart(args []string) error {
 	fmt.Printf("📊 Configuration:\n")
 	fmt.Printf("   Mode: %s\n", plan.Mode)
 	fmt.Printf("   Aggregator: %s\n", plan.Aggregator.Address)
+	if plan.Dispatch.Enabled {
+		fmt.Printf("   Task Dispatch: enabled (role %s)\n", role)
+	} else if role == federation.CollaboratorEvaluator {
+		fmt.Printf("   Role: %s (evaluates each aggregated model, never trains)\n", role)
+	}
 
 	if plan.Mode == federation.ModeSync {
 		fmt.Printf("   Rounds: %d\n", plan.Rounds)
+	} else if plan.Mode == federation.ModeDecentralized {
+		fmt.Printf("   Rounds: %d\n", plan.Rounds)
+		fmt.Printf("   Strategy: %s\n", plan.Decentralized.Strategy)
 	} else {
 		fmt.Printf("   Async Config:\n")
 		fmt.Printf("     Max Staleness: %d\n", plan.AsyncConfig.MaxStaleness)
@@ -95,23 +154,101 @@ func handleCollaboratorStart(args []string) error {
 		fmt.Printf("     Staleness Weight: %.3f\n", plan.AsyncConfig.StalenessWeight)
 	}
 
-	fmt.Printf("   Training Script: %s\n", plan.Tasks.Train.Script)
-	fmt.Printf("   Epochs: %v\n", plan.Tasks.Train.Args["epochs"])
-	fmt.Printf("   Batch Size: %v\n", plan.Tasks.Train.Args["batch_size"])
+	if err := federation.ValidateTaskArgs(plan); err != nil {
+		return err
+	}
+	tasks := federation.CollaboratorTasks(plan, collaboratorName)
+	task := tasks.Train
+	if task.Runner == "" {
+		task.Runner = federation.RunnerPython
+	}
+	fmt.Printf("   Task Runner: %s\n", task.Runner)
+	switch task.Runner {
+	case federation.RunnerDocker:
+		fmt.Printf("   Image: %s\n", task.Image)
+	case federation.RunnerGo:
+		fmt.Printf("   Training Function: %s\n", task.Function)
+	}
+	if task.Script != "" {
+		fmt.Printf("   Training Script: %s\n", task.Script)
+	}
+	var local federation.LocalConfig
+	if localConfigPath != "" {
+		loaded, err := federation.LoadLocalConfig(localConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to load local config: %v", err)
+		}
+		local = *loaded
+	}
+	if local.Privacy.Enabled {
+		fmt.Printf("   Local DP: clip norm %g, noise multiplier %g\n", local.Privacy.ClipNorm, local.Privacy.NoiseMultiplier)
+	}
+	if local.Attestation.Type != "" {
+		fmt.Printf("   Attestation: %s\n", local.Attestation.Type)
+	}
+	if chaos.Enabled() {
+		fmt.Printf("   ⚠️  Chaos: %s\n", chaos)
+	}
+	if plan.Data.Path != "" {
+		fmt.Printf("   Dataset: %s\n", plan.Data.Path)
+	}
+	fmt.Printf("   Epochs: %v\n", tasks.Train.Args["epochs"])
+	fmt.Printf("   Batch Size: %v\n", tasks.Train.Args["batch_size"])
+
+	if role == federation.CollaboratorEvaluator {
+		// Evaluators never train, so only their evaluate task must be valid
+		if _, err := collaborator.NewTaskRunner(tasks.Evaluate); err != nil {
+			return fmt.Errorf("invalid evaluate task: %v", err)
+		}
+	} else if _, err := collaborator.NewTaskRunner(task); err != nil {
+		return fmt.Errorf("invalid training task: %v", err)
+	}
+
+	// Without an aggregator, decentralized collaborators fetch the warm-start
+	// model themselves
+	if plan.Mode == federation.ModeDecentralized {
+		if _, err := aggregator.WarmStart(plan); err != nil {
+			return fmt.Errorf("failed to warm-start: %v", err)
+		}
+	}
 
 	collab := collaborator.NewCollaborator(plan, collaboratorName)
+	if err := collab.SetLocalConfig(local); err != nil {
+		return fmt.Errorf("invalid local config: %v", err)
+	}
+	if err := collab.SetChaos(chaos); err != nil {
+		return err
+	}
 
-	fmt.Printf("\n🔗 Connecting to aggregator...\n")
-	if err := collab.Connect(); err != nil {
-		return fmt.Errorf("failed to connect to aggregator: %v", err)
+	if daemon {
+		return runCollaboratorDaemon(planPath, localConfigPath, collaboratorName, adminAddress, adminToken, chaos)
 	}
 
-	fmt.Printf("✅ Connected successfully!\n")
+	// Decentralized mode has no central aggregator to connect to
+	if plan.Mode != federation.ModeDecentralized {
+		fmt.Printf("\n🔗 Connecting to aggregator...\n")
+		if err := collab.Connect(); err != nil {
+			return fmt.Errorf("failed to connect to aggregator: %v", err)
+		}
+
+		fmt.Printf("✅ Connected successfully!\n")
+	}
 	fmt.Printf("🎯 Starting federated learning...\n\n")
 
-	// Use the new Run method that handles both sync and async modes
-	if err := collab.Run(plan.Tasks.Train); err != nil {
-		return fmt.Errorf("federated learning failed: %v", err)
+	// Use the new Run method that handles both sync and async modes. A stop
+	// request ends the process instead of waiting for the federation, which is
+	// also how a Windows service is stopped.
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+	done := make(chan error, 1)
+	go func() { done <- collab.Run(tasks.Train) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("federated learning failed: %v", err)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("collaborator '%s' stopped before the federation completed", collaboratorName)
 	}
 
 	fmt.Printf("\n🎉 Federated learning completed!\n")
@@ -120,6 +257,26 @@ func handleCollaboratorStart(args []string) error {
 	return nil
 }
 
+// runCollaboratorDaemon keeps the collaborator participating in federations
+// until it is interrupted
+func runCollaboratorDaemon(planPath, localConfigPath, collaboratorName, adminAddress, adminToken string, chaos collaborator.ChaosConfig) error {
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
+	fmt.Printf("\n🔁 Starting collaborator daemon (admin endpoint: http://%s)\n", adminAddress)
+	fmt.Printf("   Joins each federation the aggregator runs; press Ctrl+C to stop\n\n")
+	d := collaborator.NewDaemon(planPath, localConfigPath, collaboratorName, adminAddress)
+	d.SetAdminToken(adminToken)
+	d.SetChaos(chaos)
+	if err := d.Run(ctx); err != nil {
+		return fmt.Errorf("collaborator daemon failed: %v", err)
+	}
+
+	status := d.Status()
+	fmt.Printf("\n👋 Collaborator daemon stopped after %d federations\n", status.FederationsCompleted)
+	return nil
+}
+
 func printCollaboratorUsage() {
 	fmt.Println("Collaborator command - Start and manage collaborator")
 	fmt.Println()
@@ -130,9 +287,16 @@ func printCollaboratorUsage() {
 	fmt.Println("  start     Start a collaborator")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --plan, -p    Path to plan.yaml file (default: plan.yaml)")
+	fmt.Println("  --plan, -p         Path to plan.yaml file (default: plan.yaml)")
+	fmt.Println("  --local-config     Collaborator's own config file, e.g. for local differential privacy")
+	fmt.Println("  --daemon, -d       Keep running across federations (requires task dispatch)")
+	fmt.Println("  --admin-address    Address of the daemon's admin endpoint (default: localhost:9104)")
+	fmt.Println("  --admin-token      Token /abort requires as X-API-Key (default: $FLGO_COLLABORATOR_ADMIN_TOKEN); required off loopback")
+	fmt.Println("  --chaos            Inject faults for testing, e.g. drop=0.1,delay=2s..10s,corrupt=0.05")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  fx collaborator start collaborator1           # Start collaborator1")
 	fmt.Println("  fx collaborator start collab1 --plan my.yaml  # Start with custom plan")
+	fmt.Println("  fx collaborator start collab1 --daemon        # Join federations until stopped")
+	fmt.Println("  fx collaborator start collab1 --chaos drop=0.2  # Skip a fifth of the rounds")
 }
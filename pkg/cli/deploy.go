@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/deploy"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// HandleDeployCommand handles commands that generate deployment configurations
+func HandleDeployCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("deploy command requires a subcommand (k8s, compose)")
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printDeployUsage()
+		return nil
+	case "k8s", "kubernetes":
+		return handleDeployKubernetes(args[1:])
+	case "compose":
+		return handleDeployCompose(args[1:])
+	default:
+		return fmt.Errorf("unknown deploy subcommand: %s", args[0])
+	}
+}
+
+// parseDeployOptions parses the options of a deploy subcommand into options,
+// which holds their defaults
+func parseDeployOptions(command string, args []string, options map[string]string, valid ...string) error {
+	aliases := map[string]string{"-p": "--plan", "-o": "--output", "-i": "--image", "-n": "--namespace"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		known := false
+		for _, option := range valid {
+			known = known || arg == option
+		}
+		if !known {
+			return fmt.Errorf("unknown %s option: %s", command, arg)
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		options[arg] = args[i+1]
+		i++
+	}
+	return nil
+}
+
+func handleDeployKubernetes(args []string) error {
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			printDeployUsage()
+			return nil
+		}
+	}
+	options := map[string]string{"--plan": "plan.yaml", "--output": "-", "--collaborator-kind": deploy.CollaboratorJob}
+	if err := parseDeployOptions("deploy k8s", args, options,
+		"--plan", "--output", "--image", "--name", "--namespace", "--collaborator-kind", "--storage"); err != nil {
+		return err
+	}
+
+	plan, planData, err := loadDeployPlan(options["--plan"])
+	if err != nil {
+		return err
+	}
+
+	manifests, err := deploy.Kubernetes(plan, planData, deploy.KubernetesOptions{
+		Name:             options["--name"],
+		Namespace:        options["--namespace"],
+		Image:            options["--image"],
+		CollaboratorKind: options["--collaborator-kind"],
+		StorageSize:      options["--storage"],
+		PlanFile:         filepath.Base(options["--plan"]),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate Kubernetes manifests: %v", err)
+	}
+
+	if err := writeDeployOutput(options["--output"], manifests); err != nil {
+		return err
+	}
+	if options["--output"] != "-" {
+		fmt.Printf("✅ Kubernetes manifests for %d collaborators written to %s\n", len(plan.Collaborators), options["--output"])
+		fmt.Printf("💡 Apply them with: kubectl apply -f %s\n", options["--output"])
+	}
+	return nil
+}
+
+func handleDeployCompose(args []string) error {
+	options := map[string]string{"--plan": "plan.yaml", "--output": "docker-compose.yaml"}
+	var flags []string
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			printDeployUsage()
+			return nil
+		case "--no-monitor":
+			options[arg] = "true"
+		default:
+			flags = append(flags, arg)
+		}
+	}
+	if err := parseDeployOptions("deploy compose", flags, options,
+		"--plan", "--output", "--image", "--name", "--build"); err != nil {
+		return err
+	}
+
+	plan, _, err := loadDeployPlan(options["--plan"])
+	if err != nil {
+		return err
+	}
+
+	// Services mount the current directory, the workspace the plan's paths
+	// are relative to, with paths relative to the Compose file
+	output := options["--output"]
+	workspace, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	outputDir, err := filepath.Abs(filepath.Dir(output))
+	if err != nil {
+		return err
+	}
+	relWorkspace, err := filepath.Rel(outputDir, workspace)
+	if err != nil {
+		return fmt.Errorf("failed to locate the workspace from %s: %v", output, err)
+	}
+	planPath, err := filepath.Rel(workspace, absPath(options["--plan"]))
+	if err != nil || strings.HasPrefix(planPath, "..") {
+		return fmt.Errorf("plan %s must be inside the current directory, which services mount as the workspace", options["--plan"])
+	}
+	build := options["--build"]
+	if build != "" {
+		if build, err = filepath.Rel(outputDir, absPath(build)); err != nil {
+			return fmt.Errorf("failed to locate the build context from %s: %v", output, err)
+		}
+		build = filepath.ToSlash(build)
+	}
+
+	composeOptions := deploy.ComposeOptions{
+		Name:      options["--name"],
+		Image:     options["--image"],
+		Build:     build,
+		PlanPath:  filepath.ToSlash(planPath),
+		Workspace: filepath.ToSlash(relWorkspace),
+		NoMonitor: options["--no-monitor"] == "true",
+	}
+	// Run as the current user so that the services can write to the workspace
+	if uid := os.Getuid(); uid >= 0 {
+		composeOptions.User = fmt.Sprintf("%d:%d", uid, os.Getgid())
+	}
+	compose, err := deploy.Compose(plan, composeOptions)
+	if err != nil {
+		return fmt.Errorf("failed to generate Compose file: %v", err)
+	}
+
+	// Created here so that they belong to the current user rather than to
+	// the Docker daemon
+	for _, collab := range plan.Collaborators {
+		if err := os.MkdirAll(filepath.FromSlash(deploy.CollaboratorModelDir(collab.ID)), 0750); err != nil {
+			return fmt.Errorf("failed to create models directory of %s: %v", collab.ID, err)
+		}
+	}
+	if err := writeDeployOutput(output, compose); err != nil {
+		return err
+	}
+
+	if output == "-" {
+		return nil
+	}
+	fmt.Printf("✅ Compose file for the aggregator and %d collaborators written to %s\n", len(plan.Collaborators), output)
+	fmt.Printf("💡 Start the federation with: docker compose -f %s up\n", output)
+	if !composeOptions.NoMonitor {
+		fmt.Printf("📊 Dashboard: http://localhost:8080/\n")
+	}
+	return nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// loadDeployPlan loads the plan a deployment runs, along with the plan as
+// written, merged with the plans it extends, so that it can be shipped as one
+// file
+func loadDeployPlan(path string) (*federation.FLPlan, []byte, error) {
+	plan, err := federation.LoadPlan(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load plan: %v", err)
+	}
+	data, err := federation.RenderPlan(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render plan: %v", err)
+	}
+	return plan, data, nil
+}
+
+// writeDeployOutput writes data to path, or to stdout when path is -
+func writeDeployOutput(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+func printDeployUsage() {
+	fmt.Println("Deploy Commands:")
+	fmt.Println("  fx deploy k8s [options]       # Generate Kubernetes manifests for the plan's federation")
+	fmt.Println("  fx deploy compose [options]   # Generate a Compose file that runs the federation on one machine")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --plan, -p            Plan to deploy (default: plan.yaml)")
+	fmt.Println("  --output, -o          File to write, or - for stdout (default: - for k8s, docker-compose.yaml for compose)")
+	fmt.Println("  --image, -i           Image with fx and the workspace (default: fl-go:latest)")
+	fmt.Println("  --name                Prefix of the object names (default: monitoring.federation_id)")
+	fmt.Println("  --namespace, -n       k8s: namespace of the objects")
+	fmt.Println("  --collaborator-kind   k8s: job or statefulset (default: job)")
+	fmt.Println("  --storage             k8s: size of each persistent volume (default: 1Gi)")
+	fmt.Println("  --build               compose: build the image from this source directory")
+	fmt.Println("  --no-monitor          compose: leave out the monitoring server")
+	fmt.Println()
+	fmt.Println("Settings that differ in the deployment, such as aggregator.address, are")
+	fmt.Println("overridden with FLGO_* environment variables.")
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// HandleFederationCommand handles commands about federation runs
+func HandleFederationCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("federation command requires a subcommand (verify-run, reaggregate)")
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printFederationUsage()
+		return nil
+	case "verify-run":
+		return handleVerifyRun(args[1:])
+	case "reaggregate":
+		return handleReaggregate(args[1:])
+	default:
+		return fmt.Errorf("unknown federation subcommand: %s", args[0])
+	}
+}
+
+// handleVerifyRun checks the manifest of a rerun against the manifest of the
+// run it reproduces
+func handleVerifyRun(args []string) error {
+	var positional []string
+	options := map[string]string{"--plan": "plan.yaml"}
+
+	aliases := map[string]string{"-p": "--plan", "-m": "--manifest"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+			continue
+		}
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		switch arg {
+		case "--plan", "--manifest":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			options[arg] = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown verify-run option: %s", arg)
+		}
+	}
+
+	expectedPath, ok := options["--manifest"]
+	if !ok {
+		return fmt.Errorf("verify-run requires --manifest with the manifest of the original run")
+	}
+
+	// The rerun's manifest defaults to where the plan writes it
+	var rerunPath string
+	switch len(positional) {
+	case 0:
+		plan, err := federation.LoadPlan(options["--plan"])
+		if err != nil {
+			return fmt.Errorf("failed to load plan: %v", err)
+		}
+		rerunPath = aggregator.ManifestPath(plan)
+	case 1:
+		rerunPath = positional[0]
+	default:
+		return fmt.Errorf("verify-run takes at most one rerun manifest")
+	}
+
+	expected, err := aggregator.LoadManifest(expectedPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %v", err)
+	}
+	actual, err := aggregator.LoadManifest(rerunPath)
+	if err != nil {
+		return fmt.Errorf("failed to load rerun manifest: %v", err)
+	}
+
+	diffs := aggregator.VerifyManifest(expected, actual)
+	if len(diffs) > 0 {
+		fmt.Printf("❌ %s does not reproduce %s:\n", rerunPath, expectedPath)
+		for _, diff := range diffs {
+			fmt.Printf("   - %s\n", diff)
+		}
+		return fmt.Errorf("rerun differs from the original run in %d ways", len(diffs))
+	}
+
+	fmt.Printf("✅ %s reproduces %s (%d rounds)\n", rerunPath, expectedPath, len(actual.Rounds))
+	return nil
+}
+
+// handleReaggregate recomputes the aggregate of a round from the updates the
+// aggregator archived, with another algorithm or without some collaborators
+func handleReaggregate(args []string) error {
+	options := map[string]string{"--plan": "plan.yaml", "--format": "text"}
+	var exclude []string
+	hyperparameters := make(map[string]interface{})
+
+	aliases := map[string]string{
+		"-p": "--plan", "-r": "--round", "-a": "--algorithm", "-x": "--exclude", "-d": "--dir", "-o": "--output",
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--plan", "--round", "--algorithm", "--dir", "--output", "--format":
+			options[arg] = value
+		case "--exclude":
+			exclude = append(exclude, strings.Split(value, ",")...)
+		case "--param":
+			key, v, ok := strings.Cut(value, "=")
+			if !ok {
+				return fmt.Errorf("invalid --param %q, expected key=value", value)
+			}
+			hyperparameters[key] = parseParam(v)
+		default:
+			return fmt.Errorf("unknown reaggregate option: %s", arg)
+		}
+	}
+
+	round, err := strconv.Atoi(options["--round"])
+	if err != nil || round < 1 {
+		return fmt.Errorf("reaggregate requires --round with a round number")
+	}
+	if options["--format"] != "text" && options["--format"] != "json" {
+		return fmt.Errorf("unknown format %q (text or json)", options["--format"])
+	}
+
+	// The archive defaults to where the plan keeps updates
+	dir := options["--dir"]
+	if dir == "" {
+		plan, err := federation.LoadPlan(options["--plan"])
+		if err != nil {
+			return fmt.Errorf("failed to load plan: %v", err)
+		}
+		if plan.Aggregator.UpdatesDir == "" {
+			return fmt.Errorf("the plan does not set aggregator.updates_dir; pass --dir with the archive")
+		}
+		dir = plan.Aggregator.UpdatesDir
+	}
+
+	result, model, err := aggregator.Reaggregate(dir, round, aggregator.ReaggregateOptions{
+		Algorithm:       options["--algorithm"],
+		Hyperparameters: hyperparameters,
+		Exclude:         exclude,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to re-aggregate round %d: %v", round, err)
+	}
+	if output := options["--output"]; output != "" {
+		if err := storage.WriteFile(output, model); err != nil {
+			return fmt.Errorf("failed to write model: %v", err)
+		}
+	}
+
+	if options["--format"] == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("🔁 Round %d re-aggregated with %s (originally %s)\n", result.Round, result.Algorithm, result.OriginalAlgorithm)
+	fmt.Printf("   Included: %s\n", strings.Join(result.Included, ", "))
+	if len(result.Excluded) > 0 {
+		fmt.Printf("   Excluded: %s\n", strings.Join(result.Excluded, ", "))
+	}
+	fmt.Printf("   Model hash: %s (original %s)\n", result.ModelHash, result.OriginalModelHash)
+	fmt.Printf("   Distance from the round's base model: %.6f\n", result.DistanceFromBase)
+	if result.DistanceFromOriginal != nil {
+		fmt.Printf("   Distance from the original aggregate: %.6f\n", *result.DistanceFromOriginal)
+	} else {
+		fmt.Println("   The original aggregate is no longer available to compare with")
+	}
+	if output := options["--output"]; output != "" {
+		fmt.Printf("💾 Model written to %s\n", output)
+	}
+	return nil
+}
+
+// parseParam reads a hyperparameter value as a number or boolean when it is
+// one, like plan YAML does
+func parseParam(value string) interface{} {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
+
+func printFederationUsage() {
+	fmt.Println("Federation Commands:")
+	fmt.Println("  fx federation verify-run --manifest <file> [rerun-manifest]   # Check a rerun against a run manifest")
+	fmt.Println("  fx federation reaggregate --round <n> [options]              # Recompute a round from its archived updates")
+	fmt.Println()
+	fmt.Println("verify-run options:")
+	fmt.Println("  --manifest, -m   Manifest of the original run")
+	fmt.Println("  --plan, -p       Plan whose manifest_path locates the rerun's manifest (default: plan.yaml)")
+	fmt.Println()
+	fmt.Println("reaggregate options:")
+	fmt.Println("  --round, -r      Round to re-aggregate")
+	fmt.Println("  --algorithm, -a  Aggregation algorithm (default: the round's algorithm)")
+	fmt.Println("  --exclude, -x    Comma-separated collaborators to leave out (repeatable)")
+	fmt.Println("  --param          Algorithm hyperparameter as key=value (repeatable)")
+	fmt.Println("  --dir, -d        Update archive (default: aggregator.updates_dir of --plan)")
+	fmt.Println("  --plan, -p       Plan (default: plan.yaml)")
+	fmt.Println("  --output, -o     Write the re-aggregated model to this file")
+	fmt.Println("  --format         text (default) or json")
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// HandleHECommand handles the homomorphic aggregation commands
+func HandleHECommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("he command requires a subcommand (keygen, key-holder)")
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printHEUsage()
+		return nil
+	case "keygen":
+		return handleHEKeygen(args[1:])
+	case "key-holder":
+		return handleHEKeyHolder(args[1:])
+	default:
+		return fmt.Errorf("unknown he subcommand: %s", args[0])
+	}
+}
+
+// parseHEOptions parses --option value pairs into defaults
+func parseHEOptions(args []string, options map[string]string, aliases map[string]string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		if _, ok := options[arg]; !ok {
+			return fmt.Errorf("unknown he option: %s", args[i])
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		options[arg] = args[i+1]
+		i++
+	}
+	return nil
+}
+
+// handleHEKeygen writes a Paillier key pair: the public key for the plan and
+// the private key for the key holder
+func handleHEKeygen(args []string) error {
+	options := map[string]string{"--bits": "2048", "--output": "he"}
+	if err := parseHEOptions(args, options, map[string]string{"-b": "--bits", "-o": "--output"}); err != nil {
+		return err
+	}
+	bits, err := strconv.Atoi(options["--bits"])
+	if err != nil {
+		return fmt.Errorf("invalid --bits: %s", options["--bits"])
+	}
+	if bits < 2048 {
+		fmt.Printf("⚠️  %d-bit keys are only fit for testing\n", bits)
+	}
+
+	fmt.Printf("🔑 Generating a %d-bit Paillier key...\n", bits)
+	key, err := security.GeneratePaillierKey(rand.Reader, bits)
+	if err != nil {
+		return err
+	}
+	public, err := security.MarshalPaillierPublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+	private, err := security.MarshalPaillierPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	publicPath, privatePath := options["--output"]+".pub", options["--output"]+".key"
+	if err := os.WriteFile(publicPath, public, 0644); err != nil { // #nosec G306 - public keys are shared
+		return err
+	}
+	if err := os.WriteFile(privatePath, private, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Public key written to %s (security.homomorphic.public_key_path)\n", publicPath)
+	fmt.Printf("✅ Private key written to %s; give it to the key holder only\n", privatePath)
+	return nil
+}
+
+// handleHEKeyHolder serves decryption of round sums to the aggregator
+func handleHEKeyHolder(args []string) error {
+	options := map[string]string{
+		"--key":         "he.key",
+		"--address":     ":8090",
+		"--token":       os.Getenv("FL_KEY_HOLDER_TOKEN"),
+		"--min-updates": "2",
+		"--tls-cert":    "",
+		"--tls-key":     "",
+	}
+	aliases := map[string]string{"-k": "--key", "-a": "--address", "-t": "--token"}
+	if err := parseHEOptions(args, options, aliases); err != nil {
+		return err
+	}
+	minUpdates, err := strconv.Atoi(options["--min-updates"])
+	if err != nil || minUpdates < 1 {
+		return fmt.Errorf("invalid --min-updates: %s", options["--min-updates"])
+	}
+	key, err := security.LoadPaillierPrivateKey(options["--key"])
+	if err != nil {
+		return err
+	}
+	if options["--token"] == "" {
+		fmt.Printf("⚠️  No --token set; anyone who reaches the key holder can have sums decrypted\n")
+	}
+
+	server := &http.Server{
+		Addr:              options["--address"],
+		Handler:           security.NewKeyHolderHandler(key, options["--token"], minUpdates),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	fmt.Printf("🔐 Key holder listening on %s (sums of at least %d updates)\n", options["--address"], minUpdates)
+	if options["--tls-cert"] != "" {
+		err = server.ListenAndServeTLS(options["--tls-cert"], options["--tls-key"])
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func printHEUsage() {
+	fmt.Println("Homomorphic Aggregation Commands (experimental):")
+	fmt.Println("  fx he keygen [options]       # Generate a Paillier key pair")
+	fmt.Println("  fx he key-holder [options]   # Decrypt the aggregator's round sums")
+	fmt.Println()
+	fmt.Println("Keygen Options:")
+	fmt.Println("  --bits, -b       Modulus size (default: 2048)")
+	fmt.Println("  --output, -o     Writes <output>.pub and <output>.key (default: he)")
+	fmt.Println()
+	fmt.Println("Key Holder Options:")
+	fmt.Println("  --key, -k        Private key (default: he.key)")
+	fmt.Println("  --address, -a    Listen address (default: :8090)")
+	fmt.Println("  --token, -t      API key the aggregator sends (default: $FL_KEY_HOLDER_TOKEN)")
+	fmt.Println("  --min-updates    Refuse sums of fewer updates (default: 2)")
+	fmt.Println("  --tls-cert       Serve HTTPS with this certificate")
+	fmt.Println("  --tls-key        Key of --tls-cert")
+}
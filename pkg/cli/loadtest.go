@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/loadtest"
+	"github.com/ishaileshpant/fl-go/pkg/logging"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+)
+
+// HandleLoadtestCommand handles the load test commands
+func HandleLoadtestCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("loadtest command requires a subcommand (aggregator, monitor)")
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printLoadtestUsage()
+		return nil
+	case "aggregator":
+		return handleLoadtestAggregator(args[1:])
+	case "monitor":
+		return handleLoadtestMonitor(args[1:])
+	default:
+		return fmt.Errorf("unknown loadtest subcommand: %s", args[0])
+	}
+}
+
+// handleLoadtestAggregator loads an aggregator with simulated collaborators
+func handleLoadtestAggregator(args []string) error {
+	options := map[string]string{
+		"--clients":     "100",
+		"--model-size":  "1M",
+		"--rounds":      "1",
+		"--concurrency": "64",
+		"--connections": "8",
+		"--algorithm":   "fedavg",
+		"--timeout":     "10m",
+		"--output":      "loadtest-aggregator.json",
+		"--log-level":   string(logging.LevelWarn),
+	}
+	streaming := false
+
+	aliases := map[string]string{"-c": "--clients", "-s": "--model-size", "-r": "--rounds", "-a": "--algorithm", "-o": "--output"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		switch arg {
+		case "--clients", "--model-size", "--rounds", "--concurrency", "--connections", "--algorithm", "--timeout", "--output", "--log-level":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			options[arg] = args[i+1]
+			i++
+		case "--streaming":
+			streaming = true
+		default:
+			return fmt.Errorf("unknown loadtest option: %s", arg)
+		}
+	}
+
+	config := loadtest.AggregatorConfig{Algorithm: options["--algorithm"], Streaming: streaming}
+	var err error
+	if config.ModelSize, err = parseParamCount(options["--model-size"]); err != nil {
+		return err
+	}
+	ints := []struct {
+		option string
+		value  *int
+	}{
+		{"--clients", &config.Clients},
+		{"--rounds", &config.Rounds},
+		{"--concurrency", &config.Concurrency},
+		{"--connections", &config.Connections},
+	}
+	for _, o := range ints {
+		if *o.value, err = strconv.Atoi(options[o.option]); err != nil || *o.value < 1 {
+			return fmt.Errorf("invalid %s: %s", o.option, options[o.option])
+		}
+	}
+	if config.Timeout, err = time.ParseDuration(options["--timeout"]); err != nil || config.Timeout <= 0 {
+		return fmt.Errorf("invalid --timeout: %s", options["--timeout"])
+	}
+	level, err := logging.ParseLevel(options["--log-level"])
+	if err != nil {
+		return err
+	}
+	defer logging.SetLevel(logging.SetLevel(level))
+
+	updateBytes := int64(4 * config.ModelSize)
+	fmt.Printf("🔥 Load testing a %s aggregator: %d clients, %d rounds, %s updates\n",
+		config.Algorithm, config.Clients, config.Rounds, formatBytes(updateBytes))
+	if !streaming {
+		fmt.Printf("   The aggregator holds %s of updates per round; --streaming keeps a running sum instead\n",
+			formatBytes(updateBytes*int64(config.Clients)))
+	}
+
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
+	fmt.Printf("%-6s %-12s %-12s %-10s %-10s %-10s %-15s %-8s\n",
+		"ROUND", "UPDATES/S", "THROUGHPUT", "P50 MS", "P95 MS", "P99 MS", "AGGREGATION MS", "RETRIES")
+	report, err := loadtest.RunAggregator(ctx, config, func(r loadtest.AggregatorRound) {
+		fmt.Printf("%-6d %-12.1f %-12s %-10.1f %-10.1f %-10.1f %-15.1f %-8d\n",
+			r.Round, r.UpdatesPerSecond, formatBytes(int64(r.BytesPerSecond))+"/s",
+			r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Aggregation, r.Retries)
+	})
+	if err != nil {
+		return err
+	}
+	printAggregatorLoadReport(report)
+
+	if err := loadtest.WriteReport(options["--output"], report); err != nil {
+		return fmt.Errorf("failed to write load test report: %v", err)
+	}
+	fmt.Printf("Report written to %s\n", options["--output"])
+	return nil
+}
+
+func printAggregatorLoadReport(report *loadtest.AggregatorReport) {
+	fmt.Println(strings.Repeat("-", 52))
+	fmt.Printf("Updates:     %d accepted, %d refused in %.1f s\n", report.Accepted, report.Rejected, report.Duration/1000)
+	fmt.Printf("Throughput:  %.1f updates/s, %s/s, including aggregation\n",
+		report.UpdatesPerSecond, formatBytes(int64(report.BytesPerSecond)))
+	fmt.Printf("Latency:     p50 %.1f ms, p95 %.1f ms, p99 %.1f ms, max %.1f ms\n",
+		report.Latency.P50, report.Latency.P95, report.Latency.P99, report.Latency.Max)
+	fmt.Printf("Memory:      peak heap %s, peak from the OS %s, %d GCs, %d goroutines at most\n",
+		formatBytes(int64(report.Memory.PeakHeapInuseBytes)), formatBytes(int64(report.Memory.PeakSysBytes)), // #nosec G115 - memory sizes fit in int64
+		report.Memory.NumGC, report.Memory.PeakGoroutines)
+	if len(report.Errors) > 0 {
+		fmt.Printf("⚠️  Failed calls, retried:")
+		for code, n := range report.Errors {
+			fmt.Printf(" %s %d", code, n)
+		}
+		fmt.Println()
+	}
+}
+
+// handleLoadtestMonitor pushes the metrics of simulated federations at a
+// monitoring server
+func handleLoadtestMonitor(args []string) error {
+	options := map[string]string{
+		"--server":        defaultMonitoringServer,
+		"--api-key":       os.Getenv("FLGO_API_KEY"),
+		"--federations":   "1",
+		"--collaborators": "10",
+		"--rate":          "1000",
+		"--batch-size":    "100",
+		"--queue-size":    "",
+		"--duration":      "1m",
+		"--interval":      "10s",
+		"--timeout":       "10s",
+		"--output":        "loadtest-monitor.json",
+	}
+
+	aliases := map[string]string{"-s": "--server", "-f": "--federations", "-c": "--collaborators", "-r": "--rate", "-d": "--duration", "-o": "--output"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		if _, ok := options[arg]; !ok {
+			return fmt.Errorf("unknown loadtest option: %s", arg)
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		options[arg] = args[i+1]
+		i++
+	}
+
+	config := loadtest.MonitorConfig{Server: options["--server"], APIKey: options["--api-key"]}
+	var err error
+	if config.Rate, err = strconv.ParseFloat(options["--rate"], 64); err != nil || config.Rate <= 0 {
+		return fmt.Errorf("invalid --rate: %s", options["--rate"])
+	}
+	ints := []struct {
+		option string
+		value  *int
+	}{
+		{"--federations", &config.Federations},
+		{"--collaborators", &config.Collaborators},
+		{"--batch-size", &config.BatchSize},
+		{"--queue-size", &config.QueueSize},
+	}
+	for _, o := range ints {
+		if options[o.option] == "" {
+			continue
+		}
+		if *o.value, err = strconv.Atoi(options[o.option]); err != nil || *o.value < 1 {
+			return fmt.Errorf("invalid %s: %s", o.option, options[o.option])
+		}
+	}
+	durations := []struct {
+		option string
+		value  *time.Duration
+	}{
+		{"--duration", &config.Duration},
+		{"--interval", &config.Interval},
+		{"--timeout", &config.Timeout},
+	}
+	for _, o := range durations {
+		if *o.value, err = time.ParseDuration(options[o.option]); err != nil || *o.value <= 0 {
+			return fmt.Errorf("invalid %s: %s", o.option, options[o.option])
+		}
+	}
+
+	fmt.Printf("🔥 Load testing the monitoring server at %s: %d federations of %d collaborators, %g metrics/s for %s\n",
+		config.Server, config.Federations, config.Collaborators, config.Rate, config.Duration)
+
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
+	fmt.Printf("%-10s %-10s %-12s %-10s %-10s %-10s %-10s\n",
+		"ELAPSED S", "SENT", "ACCEPTED/S", "DROPPED", "P50 MS", "P95 MS", "P99 MS")
+	report, err := loadtest.RunMonitor(ctx, config, func(i loadtest.MonitorInterval) {
+		fmt.Printf("%-10.1f %-10d %-12.1f %-10d %-10.1f %-10.1f %-10.1f\n",
+			i.Elapsed, i.Sent, i.ItemsPerSecond, i.Dropped, i.Latency.P50, i.Latency.P95, i.Latency.P99)
+	})
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		fmt.Println("⚠️  Interrupted; the report covers the load until then")
+	}
+	printMonitorLoadReport(report)
+
+	if err := loadtest.WriteReport(options["--output"], report); err != nil {
+		return fmt.Errorf("failed to write load test report: %v", err)
+	}
+	fmt.Printf("Report written to %s\n", options["--output"])
+	return nil
+}
+
+func printMonitorLoadReport(report *loadtest.MonitorReport) {
+	fmt.Println(strings.Repeat("-", 52))
+	fmt.Printf("Metrics:     %d sent, %d accepted in %.1f s (%.1f/s)\n",
+		report.Sent, report.Accepted, report.Duration/1000, report.ItemsPerSecond)
+	fmt.Printf("Dropped:     %d (%.2f%%): %d rejected, %d in failed requests, %d shed while the queue was full\n",
+		report.Dropped, 100*report.DropRate, report.Rejected, report.Failed, report.Shed)
+	fmt.Printf("Latency:     p50 %.1f ms, p95 %.1f ms, p99 %.1f ms, max %.1f ms over %d requests\n",
+		report.Latency.P50, report.Latency.P95, report.Latency.P99, report.Latency.Max, report.Latency.Count)
+	if len(report.Errors) > 0 {
+		fmt.Printf("⚠️  Failed requests:")
+		for reason, n := range report.Errors {
+			fmt.Printf(" %s %d", reason, n)
+		}
+		fmt.Println()
+	}
+}
+
+func printLoadtestUsage() {
+	fmt.Println("Load Test Commands:")
+	fmt.Println("  fx loadtest aggregator [options]   # Load an in-process aggregator with simulated collaborators")
+	fmt.Println("  fx loadtest monitor [options]      # Push the metrics of simulated federations at a monitoring server")
+	fmt.Println()
+	fmt.Println("Aggregator Options:")
+	fmt.Println("  --clients, -c      Simulated collaborators, each submitting an update per round (default: 100)")
+	fmt.Println("  --model-size, -s   Parameters per update, with K, M or G suffixes (default: 1M)")
+	fmt.Println("  --rounds, -r       Rounds (default: 1)")
+	fmt.Println("  --concurrency      Submissions in flight at once (default: 64)")
+	fmt.Println("  --connections      gRPC connections the clients share (default: 8)")
+	fmt.Println("  --algorithm, -a    Aggregation algorithm (default: fedavg)")
+	fmt.Println("  --streaming        Add updates to a running sum as they arrive (aggregator.streaming)")
+	fmt.Println("  --timeout          Fail when the test takes longer (default: 10m)")
+	fmt.Println("  --output, -o       JSON report to write (default: loadtest-aggregator.json)")
+	fmt.Println("  --log-level        Log level of the aggregator (default: warn)")
+	fmt.Println()
+	fmt.Println("Monitor Options:")
+	fmt.Println("  --server, -s          Monitoring server URL (default: " + defaultMonitoringServer + ")")
+	fmt.Println("  --api-key             API key with the monitor role (default: $FLGO_API_KEY)")
+	fmt.Println("  --federations, -f     Federations reporting at once (default: 1)")
+	fmt.Println("  --collaborators, -c   Collaborators per federation (default: 10)")
+	fmt.Println("  --rate, -r            Metrics per second over all federations (default: 1000)")
+	fmt.Println("  --batch-size          Metrics per ingest request (default: 100)")
+	fmt.Println("  --queue-size          Metrics a federation holds while the server is slow (default: 10 batches)")
+	fmt.Println("  --duration, -d        How long to push metrics; hours for a soak test (default: 1m)")
+	fmt.Println("  --interval            Between progress lines (default: 10s)")
+	fmt.Println("  --timeout             Of each request (default: 10s)")
+	fmt.Println("  --output, -o          JSON report to write (default: loadtest-monitor.json)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  fx loadtest aggregator --clients 2000 --model-size 10M --streaming")
+	fmt.Println("  fx loadtest aggregator -c 500 -s 1M -r 3 --concurrency 256 -o load.json")
+	fmt.Println("  fx loadtest monitor --federations 20 --collaborators 50 --rate 5000 --duration 5m")
+	fmt.Println("  fx loadtest monitor -s http://monitor:8080 -r 2000 -d 12h --interval 1m -o soak.json")
+}
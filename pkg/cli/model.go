@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// HandleModelCommand handles the model registry commands
+func HandleModelCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("model command requires a subcommand (list, get, promote, verify)")
+	}
+
+	subcommand := args[0]
+	if subcommand == "--help" || subcommand == "-h" {
+		printModelUsage()
+		return nil
+	}
+
+	positional, options, err := parseModelArgs(args[1:])
+	if err != nil {
+		return err
+	}
+	if subcommand == "verify" {
+		// Verifies model files against a run manifest, without a registry
+		return handleModelVerify(positional, options)
+	}
+
+	models, err := openModelRegistry(options)
+	if err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "list":
+		return handleModelList(models, positional)
+	case "get":
+		return handleModelGet(models, positional, options)
+	case "promote":
+		return handleModelPromote(models, positional, options)
+	default:
+		return fmt.Errorf("unknown model subcommand: %s", subcommand)
+	}
+}
+
+// parseModelArgs separates positional arguments from --option value pairs
+func parseModelArgs(args []string) ([]string, map[string]string, error) {
+	var positional []string
+	options := make(map[string]string)
+
+	aliases := map[string]string{
+		"-p": "--plan", "-d": "--dir", "-o": "--output", "-s": "--stage", "-m": "--manifest", "-k": "--public-key",
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+			continue
+		}
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+
+		switch arg {
+		case "--plan", "--dir", "--output", "--stage", "--manifest", "--public-key":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("missing value for %s", arg)
+			}
+			options[arg] = args[i+1]
+			i++
+		default:
+			return nil, nil, fmt.Errorf("unknown model option: %s", arg)
+		}
+	}
+	return positional, options, nil
+}
+
+// openModelRegistry opens a local registry directory given with --dir, or the
+// registry configured in the plan
+func openModelRegistry(options map[string]string) (*registry.Registry, error) {
+	if dir, ok := options["--dir"]; ok {
+		return registry.Open(registry.Config{Dir: dir})
+	}
+
+	planPath := options["--plan"]
+	if planPath == "" {
+		planPath = "plan.yaml"
+	}
+	plan, err := federation.LoadPlan(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan: %v", err)
+	}
+	if !plan.Registry.Enabled {
+		return nil, fmt.Errorf("plan %s does not enable the model registry; use --dir for a local registry", planPath)
+	}
+	return registry.Open(registry.Config(plan.Registry))
+}
+
+func handleModelList(models *registry.Registry, args []string) error {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	versions, err := models.List(name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Println("No models registered")
+		return nil
+	}
+
+	fmt.Printf("%-20s %8s %8s %6s %-10s %-12s %-12s %s\n", "NAME", "VERSION", "PARENT", "ROUND", "ALGORITHM", "STAGE", "CHECKSUM", "CREATED")
+	for _, v := range versions {
+		fmt.Printf("%-20s %8d %8s %6d %-10s %-12s %-12.12s %s\n", v.Name, v.Version, versionLabel(v.Parent), v.Round,
+			v.Algorithm, stageLabel(v.Stage), v.Checksum, v.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func handleModelGet(models *registry.Registry, args []string, options map[string]string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: fx model get <name> [version|latest|stage] [--output file]")
+	}
+	ref := "latest"
+	if len(args) > 1 {
+		ref = args[1]
+	}
+
+	version, err := models.Get(args[0], ref)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 %s v%d\n", version.Name, version.Version)
+	fmt.Printf("   Stage: %s\n", stageLabel(version.Stage))
+	fmt.Printf("   Algorithm: %s\n", version.Algorithm)
+	if version.Mode != "" {
+		fmt.Printf("   Mode: %s\n", version.Mode)
+	}
+	fmt.Printf("   Round: %d\n", version.Round)
+	fmt.Printf("   Size: %d bytes\n", version.Size)
+	fmt.Printf("   Checksum: sha256:%s\n", version.Checksum)
+	fmt.Printf("   Created: %s\n", version.CreatedAt.Format("2006-01-02 15:04:05"))
+	keys := make([]string, 0, len(version.Metadata))
+	for key := range version.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("   %s: %s\n", key, version.Metadata[key])
+	}
+
+	lineage, err := models.Lineage(version)
+	if err != nil {
+		return err
+	}
+	labels := make([]string, len(lineage))
+	for i, v := range lineage {
+		labels[i] = fmt.Sprintf("v%d (round %d)", v.Version, v.Round)
+	}
+	fmt.Printf("   Lineage: %s\n", strings.Join(labels, " <- "))
+
+	if output := options["--output"]; output != "" {
+		data, err := models.Download(version)
+		if err != nil {
+			return err
+		}
+		if err := storage.WriteFile(output, data); err != nil {
+			return fmt.Errorf("failed to write model: %v", err)
+		}
+		fmt.Printf("✅ Model written to %s (checksum verified)\n", output)
+	}
+	return nil
+}
+
+func handleModelPromote(models *registry.Registry, args []string, options map[string]string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: fx model promote <name> <version> [--stage production]")
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(args[1], "v"))
+	if err != nil {
+		return fmt.Errorf("invalid version %q", args[1])
+	}
+
+	stageName := options["--stage"]
+	if stageName == "" {
+		stageName = string(registry.StageProduction)
+	}
+	stage, err := registry.ParseStage(stageName)
+	if err != nil {
+		return err
+	}
+
+	version, err := models.Promote(args[0], number, stage)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s v%d promoted to %s\n", version.Name, version.Version, version.Stage)
+	return nil
+}
+
+// handleModelVerify checks that a model file is one of the models a run
+// produced, that the run's lineage is unbroken, and that the aggregator
+// signed every model in it
+func handleModelVerify(args []string, options map[string]string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: fx model verify <model-file> [--manifest file] [--public-key file]")
+	}
+
+	// The manifest and public key default to the plan's, when there is one
+	manifestPath, publicKeyPath := options["--manifest"], options["--public-key"]
+	if manifestPath == "" || publicKeyPath == "" {
+		planPath := options["--plan"]
+		if planPath == "" {
+			planPath = "plan.yaml"
+		}
+		plan, err := federation.LoadPlan(planPath)
+		switch {
+		case err == nil:
+			if manifestPath == "" {
+				manifestPath = aggregator.ManifestPath(plan)
+			}
+			if publicKeyPath == "" && plan.Security.ModelSigning.Enabled {
+				publicKeyPath = plan.Security.ModelSigning.PublicKeyPath
+			}
+		case manifestPath == "":
+			return fmt.Errorf("failed to load plan: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read model: %v", err)
+	}
+	manifest, err := aggregator.LoadManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %v", err)
+	}
+	var verifier *security.ModelVerifier
+	if publicKeyPath != "" {
+		if verifier, err = security.LoadModelVerifier(publicKeyPath); err != nil {
+			return err
+		}
+	}
+
+	// Walk the lineage up to the model
+	modelHash := transport.ModelHash(data)
+	lineage := []string{fmt.Sprintf("initial %.12s", manifest.InitialModelHash)}
+	found := modelHash == manifest.InitialModelHash
+	for _, round := range manifest.Rounds {
+		if found {
+			break
+		}
+		if round.ModelHash == "" {
+			continue
+		}
+		lineage = append(lineage, fmt.Sprintf("round %d %.12s", round.Round, round.ModelHash))
+		found = round.ModelHash == modelHash
+	}
+	if !found {
+		return fmt.Errorf("%s (sha256:%.12s) is not a model of run %s in %s", args[0], modelHash, manifest.FederationID, manifestPath)
+	}
+
+	fmt.Printf("🔍 %s (sha256:%.12s) is a model of run %s\n", args[0], modelHash, manifest.FederationID)
+	fmt.Printf("   Lineage: %s\n", strings.Join(lineage, " -> "))
+
+	problems := aggregator.VerifyLineage(manifest, verifier)
+	if len(problems) > 0 {
+		fmt.Printf("❌ The lineage in %s does not verify:\n", manifestPath)
+		for _, problem := range problems {
+			fmt.Printf("   - %s\n", problem)
+		}
+		return fmt.Errorf("model lineage has %d problems", len(problems))
+	}
+	if verifier == nil {
+		fmt.Println("⚠️  Signatures not checked: pass --public-key or set security.model_signing.public_key_path")
+		fmt.Printf("✅ Lineage of %d rounds is unbroken\n", len(manifest.Rounds))
+		return nil
+	}
+	fmt.Printf("✅ Lineage is unbroken and every model is signed by key %.12s\n", verifier.KeyID())
+	return nil
+}
+
+func versionLabel(version int) string {
+	if version == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("v%d", version)
+}
+
+func stageLabel(stage registry.Stage) string {
+	if stage == registry.StageNone {
+		return "-"
+	}
+	return string(stage)
+}
+
+func printModelUsage() {
+	fmt.Println("Model Registry Commands:")
+	fmt.Println("  fx model list [name]                         # List registered model versions")
+	fmt.Println("  fx model get <name> [version]                # Show a version and its lineage")
+	fmt.Println("  fx model get <name> production -o model.pt   # Download the production model")
+	fmt.Println("  fx model promote <name> <version>            # Promote a version to production")
+	fmt.Println("  fx model verify <model-file>                 # Check a model against its run's signed lineage")
+	fmt.Println()
+	fmt.Println("Versions are numbers (3 or v3), latest, or a stage (staging, production, archived).")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --plan, -p      Plan whose registry settings are used (default: plan.yaml)")
+	fmt.Println("  --dir, -d       Use a local registry directory instead of the plan")
+	fmt.Println("  --output, -o    get: write the model file to this path")
+	fmt.Println("  --stage, -s     promote: target stage (default: production)")
+	fmt.Println("  --manifest, -m  verify: run manifest (default: the plan's manifest_path)")
+	fmt.Println("  --public-key, -k verify: aggregator's public key (default: security.model_signing.public_key_path)")
+}
@@ -0,0 +1,933 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	monitorclient "github.com/ishaileshpant/fl-go/pkg/monitoring/client"
+)
+
+const defaultMonitoringServer = monitorclient.DefaultServer
+
+// HandleMonitorCommand handles all monitoring-related commands
+func HandleMonitorCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("monitor command requires a subcommand (export, import, backup, restore, contributions, compare, pause, resume, abort, apikey)")
+	}
+
+	subcommand := args[0]
+	subArgs := args[1:]
+
+	switch subcommand {
+	case "export":
+		return handleMonitorExport(subArgs)
+	case "import":
+		return handleMonitorImport(subArgs)
+	case "backup":
+		return handleMonitorBackup(subArgs)
+	case "restore":
+		return handleMonitorRestore(subArgs)
+	case "contributions":
+		return handleMonitorContributions(subArgs)
+	case "compare":
+		return handleMonitorCompare(subArgs)
+	case "pause", "resume", "abort":
+		return handleMonitorControl(monitoring.ControlAction(subcommand), subArgs)
+	case "apikey":
+		return handleMonitorAPIKey(subArgs)
+	case "--help", "-h":
+		printMonitorUsage()
+		return nil
+	default:
+		return fmt.Errorf("unknown monitor subcommand: %s", subcommand)
+	}
+}
+
+func handleMonitorExport(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	federationID := ""
+	format := "csv"
+	outputDir := "."
+	datasets := monitoring.ExportDatasets
+	query := url.Values{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--federation", "-f":
+			federationID = value
+		case "--format":
+			format = value
+		case "--output", "-o":
+			outputDir = value
+		case "--datasets", "-d":
+			datasets = nil
+			for _, name := range strings.Split(value, ",") {
+				dataset, err := monitoring.ParseExportDataset(strings.TrimSpace(name))
+				if err != nil {
+					return err
+				}
+				datasets = append(datasets, dataset)
+			}
+		case "--collaborator":
+			query.Set("collaborator_id", value)
+		case "--round":
+			query.Set("round_number", value)
+		case "--status":
+			query.Set("status", value)
+		case "--start-time":
+			query.Set("start_time", value)
+		case "--end-time":
+			query.Set("end_time", value)
+		default:
+			return fmt.Errorf("unknown export option: %s", arg)
+		}
+	}
+
+	if federationID == "" {
+		return fmt.Errorf("--federation is required")
+	}
+
+	exportFormat, err := monitoring.ParseExportFormat(format)
+	if err != nil {
+		return err
+	}
+	query.Set("format", string(exportFormat))
+
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	fmt.Printf("🔄 Exporting federation %s from %s\n", federationID, server)
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	for _, dataset := range datasets {
+		endpoint := fmt.Sprintf("%s/api/v1/federations/%s/export/%s?%s",
+			strings.TrimRight(server, "/"), url.PathEscape(federationID), dataset, query.Encode())
+		outputPath := filepath.Join(outputDir, fmt.Sprintf("%s_%s.%s", federationID, dataset, exportFormat))
+
+		if err := downloadExport(client, endpoint, apiKey, outputPath); err != nil {
+			return fmt.Errorf("failed to export %s: %v", dataset, err)
+		}
+		fmt.Printf("✅ %s written to %s\n", dataset, outputPath)
+	}
+
+	return nil
+}
+
+// downloadExport fetches an export endpoint and writes the body to outputPath
+func downloadExport(client *http.Client, endpoint, apiKey, outputPath string) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiResp monitoring.APIResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiResp); err == nil && apiResp.Error != "" {
+			return fmt.Errorf("server returned %d: %s", resp.StatusCode, apiResp.Error)
+		}
+		return fmt.Errorf("server returned %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(filepath.Clean(outputPath))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func handleMonitorImport(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	federationID := ""
+	format := ""
+	var files []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			files = append(files, arg)
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--federation", "-f":
+			federationID = value
+		case "--format":
+			format = value
+		default:
+			return fmt.Errorf("unknown import option: %s", arg)
+		}
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("at least one file to import is required")
+	}
+
+	archive := &monitoring.ImportArchive{}
+	for _, path := range files {
+		fileFormat := format
+		if fileFormat == "" {
+			fileFormat = strings.TrimPrefix(filepath.Ext(path), ".")
+		}
+
+		fmt.Printf("📥 Reading %s (%s)\n", path, fileFormat)
+		if err := readImportFile(path, fileFormat, federationID, archive); err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+
+	if federationID != "" {
+		if archive.Federation != nil {
+			archive.Federation.ID = federationID
+		}
+		for _, round := range archive.Rounds {
+			round.FederationID = federationID
+		}
+		for _, update := range archive.Updates {
+			update.FederationID = federationID
+		}
+		for _, event := range archive.Events {
+			event.FederationID = federationID
+		}
+	}
+
+	fmt.Printf("🔄 Importing %d rounds, %d updates and %d events into %s\n",
+		len(archive.Rounds), len(archive.Updates), len(archive.Events), server)
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey, Timeout: 5 * time.Minute})
+	if err != nil {
+		return err
+	}
+	result, err := client.ImportHistory(context.Background(), archive)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Imported federation %s: %d rounds, %d updates, %d events, %d collaborators\n",
+		result.FederationID, result.Rounds, result.Updates, result.Events, result.Collaborators)
+	if result.Skipped > 0 {
+		fmt.Printf("⏭️  Skipped %d records that were already imported\n", result.Skipped)
+	}
+	return nil
+}
+
+// readImportFile adds the records of a JSON archive, exported CSV file or
+// aggregator log to archive
+func readImportFile(path, format, federationID string, archive *monitoring.ImportArchive) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch format {
+	case "json":
+		var fileArchive monitoring.ImportArchive
+		if err := json.NewDecoder(file).Decode(&fileArchive); err != nil {
+			return fmt.Errorf("invalid archive: %v", err)
+		}
+		mergeImportArchive(archive, &fileArchive)
+
+	case "csv":
+		// Exported files are named <federation>_<dataset>.csv
+		base := strings.TrimSuffix(filepath.Base(path), ".csv")
+		for _, dataset := range monitoring.ExportDatasets {
+			if strings.HasSuffix(base, "_"+string(dataset)) {
+				return monitoring.ReadExportCSV(dataset, file, archive)
+			}
+		}
+		return fmt.Errorf("cannot tell dataset from file name; expected <federation>_{rounds,updates,events}.csv")
+
+	case "log", "txt":
+		if federationID == "" {
+			return fmt.Errorf("--federation is required when importing logs")
+		}
+		logArchive, err := monitoring.ParseAggregatorLog(file, federationID)
+		if err != nil {
+			return err
+		}
+		mergeImportArchive(archive, logArchive)
+
+	default:
+		return fmt.Errorf("unsupported import format %q (use json, csv or log)", format)
+	}
+
+	return nil
+}
+
+func mergeImportArchive(dst, src *monitoring.ImportArchive) {
+	if src.Federation != nil {
+		dst.Federation = src.Federation
+	}
+	dst.Collaborators = append(dst.Collaborators, src.Collaborators...)
+	dst.Rounds = append(dst.Rounds, src.Rounds...)
+	dst.Updates = append(dst.Updates, src.Updates...)
+	dst.Events = append(dst.Events, src.Events...)
+}
+
+// handleMonitorBackup writes all monitoring data of a server to a file
+func handleMonitorBackup(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	outputPath := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--out", "-o":
+			outputPath = value
+		default:
+			return fmt.Errorf("unknown backup option: %s", arg)
+		}
+	}
+
+	if outputPath == "" {
+		return fmt.Errorf("--out is required")
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey, Timeout: 5 * time.Minute})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔄 Backing up %s\n", server)
+	backup, err := client.Backup(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file so that a failed backup does not replace a
+	// good one
+	outputPath = filepath.Clean(outputPath)
+	tmpPath := outputPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := monitoring.WriteBackup(file, backup); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write backup: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	rounds, updates, events := 0, 0, 0
+	for _, federation := range backup.Federations {
+		rounds += len(federation.Rounds)
+		updates += len(federation.Updates)
+		events += len(federation.Events)
+	}
+	fmt.Printf("✅ Backed up %d federations (%d rounds, %d updates, %d events) and %d dashboards to %s\n",
+		len(backup.Federations), rounds, updates, events, len(backup.Dashboards), outputPath)
+	return nil
+}
+
+// handleMonitorRestore imports a backup written by handleMonitorBackup
+func handleMonitorRestore(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	inputPath := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if inputPath != "" {
+				return fmt.Errorf("only one backup can be restored at a time")
+			}
+			inputPath = arg
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		default:
+			return fmt.Errorf("unknown restore option: %s", arg)
+		}
+	}
+
+	if inputPath == "" {
+		return fmt.Errorf("usage: fx monitor restore [options] <backup>")
+	}
+
+	file, err := os.Open(filepath.Clean(inputPath))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	backup, err := monitoring.ReadBackup(file)
+	if err != nil {
+		return err
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey, Timeout: 5 * time.Minute})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔄 Restoring %d federations and %d dashboards from %s (taken %s) into %s\n",
+		len(backup.Federations), len(backup.Dashboards), inputPath, backup.CreatedAt.Format(time.RFC3339), server)
+	result, err := client.Restore(context.Background(), backup)
+	if result != nil {
+		for _, federation := range result.Federations {
+			fmt.Printf("✅ Restored federation %s: %d rounds, %d updates, %d events, %d collaborators",
+				federation.FederationID, federation.Rounds, federation.Updates, federation.Events, federation.Collaborators)
+			if federation.Skipped > 0 {
+				fmt.Printf(" (%d already present)", federation.Skipped)
+			}
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Restored %d dashboards\n", result.Dashboards)
+	return nil
+}
+
+// handleMonitorContributions prints the participation ledger of a federation
+func handleMonitorContributions(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	federationID := ""
+	collaboratorID := ""
+	format := "table"
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--federation", "-f":
+			federationID = value
+		case "--collaborator":
+			collaboratorID = value
+		case "--format":
+			format = value
+		default:
+			return fmt.Errorf("unknown contributions option: %s", arg)
+		}
+	}
+
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use table, csv or json)", format)
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey})
+	if err != nil {
+		return err
+	}
+	accounts, err := client.GetLedger(context.Background(), &monitoring.MetricsFilter{
+		FederationID:   federationID,
+		CollaboratorID: collaboratorID,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(accounts)
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"federation_id", "collaborator_id", "rounds_joined", "updates", "samples_contributed", "quality_score", "contribution_share", "credits", "first_round", "last_round"})
+		for _, account := range accounts {
+			writer.Write([]string{
+				account.FederationID,
+				account.CollaboratorID,
+				strconv.Itoa(account.RoundsJoined),
+				strconv.Itoa(account.Updates),
+				strconv.FormatInt(account.SamplesContributed, 10),
+				strconv.FormatFloat(account.QualityScore, 'f', -1, 64),
+				strconv.FormatFloat(account.ContributionShare, 'f', -1, 64),
+				strconv.FormatFloat(account.Credits, 'f', -1, 64),
+				strconv.Itoa(account.FirstRound),
+				strconv.Itoa(account.LastRound),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	if len(accounts) == 0 {
+		fmt.Println("No ledger accounts")
+		return nil
+	}
+	fmt.Printf("%-20s  %-20s  %6s  %10s  %7s  %7s  %9s\n", "FEDERATION", "COLLABORATOR", "ROUNDS", "SAMPLES", "QUALITY", "SHARE", "CREDITS")
+	for _, account := range accounts {
+		quality := "-"
+		if account.QualityReports > 0 {
+			quality = fmt.Sprintf("%.3f", account.QualityScore)
+		}
+		fmt.Printf("%-20s  %-20s  %6d  %10d  %7s  %7.3f  %9.2f\n", account.FederationID, account.CollaboratorID,
+			account.RoundsJoined, account.SamplesContributed, quality, account.ContributionShare, account.Credits)
+	}
+	return nil
+}
+
+func handleMonitorCompare(args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	format := "table"
+	var threshold *float64
+	var federations []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			federations = append(federations, arg)
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--threshold", "-t":
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid threshold: %s", value)
+			}
+			threshold = &parsed
+		case "--format":
+			format = value
+		default:
+			return fmt.Errorf("unknown compare option: %s", arg)
+		}
+	}
+
+	if len(federations) != 2 {
+		return fmt.Errorf("compare requires two federation IDs")
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use table or json)", format)
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey})
+	if err != nil {
+		return err
+	}
+	comparison, err := client.CompareFederations(context.Background(), federations[0], federations[1], threshold)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(comparison)
+	}
+
+	a, b := comparison.A, comparison.B
+	fmt.Printf("%-22s  %20s  %20s  %10s\n", "", a.ID, b.ID, "DELTA")
+	fmt.Printf("%-22s  %20s  %20s\n", "Algorithm", a.Algorithm, b.Algorithm)
+	fmt.Printf("%-22s  %20d  %20d  %10s\n", "Rounds", a.Rounds, b.Rounds, formatIntDelta(intPtr(a.Rounds), intPtr(b.Rounds)))
+	fmt.Printf("%-22s  %20s  %20s  %10s\n", "Final accuracy", formatOptional(a.FinalAccuracy), formatOptional(b.FinalAccuracy), formatFloatDelta(comparison.Summary.FinalAccuracyDelta))
+	fmt.Printf("%-22s  %20s  %20s  %10s\n", "Best accuracy", formatOptional(a.BestAccuracy), formatOptional(b.BestAccuracy), formatFloatDelta(comparison.Summary.BestAccuracyDelta))
+	fmt.Printf("%-22s  %20s  %20s  %10s\n", "Final loss", formatOptional(a.FinalLoss), formatOptional(b.FinalLoss), formatFloatDelta(comparison.Summary.FinalLossDelta))
+	fmt.Printf("%-22s  %20.0f  %20.0f  %+10.0f\n", "Duration (s)", a.DurationSeconds, b.DurationSeconds, comparison.Summary.DurationDelta)
+	if comparison.Threshold != nil {
+		label := fmt.Sprintf("Rounds to %.3g", *comparison.Threshold)
+		fmt.Printf("%-22s  %20s  %20s  %10s\n", label, formatOptionalInt(a.RoundsToThreshold), formatOptionalInt(b.RoundsToThreshold), formatIntDelta(a.RoundsToThreshold, b.RoundsToThreshold))
+		label = fmt.Sprintf("Seconds to %.3g", *comparison.Threshold)
+		fmt.Printf("%-22s  %20s  %20s  %10s\n", label, formatOptionalSeconds(a.TimeToThreshold), formatOptionalSeconds(b.TimeToThreshold), formatSecondsDelta(comparison.Summary.TimeToThresholdDelta))
+	}
+	fmt.Printf("%-22s  %20.1f  %20.1f  %+10.1f\n", "Mean participants", a.MeanParticipants, b.MeanParticipants, comparison.Participation.MeanParticipantsDelta)
+	fmt.Printf("%-22s  %20d  %20d  %+10d\n", "Collaborators", a.Collaborators, b.Collaborators, comparison.Participation.CollaboratorsDelta)
+	fmt.Printf("Shared collaborators: %d, only in %s: %v, only in %s: %v\n",
+		len(comparison.Participation.Shared), a.ID, comparison.Participation.OnlyA, b.ID, comparison.Participation.OnlyB)
+
+	if len(comparison.Rounds) == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("%5s  %10s  %10s  %10s  %10s  %10s  %10s  %7s\n", "ROUND", "ACC A", "ACC B", "ACC DELTA", "LOSS A", "LOSS B", "LOSS DELTA", "PARTS")
+	for _, round := range comparison.Rounds {
+		fmt.Printf("%5d  %10s  %10s  %10s  %10s  %10s  %10s  %7s\n", round.RoundNumber,
+			formatOptional(round.AccuracyA), formatOptional(round.AccuracyB), formatFloatDelta(round.AccuracyDelta),
+			formatOptional(round.LossA), formatOptional(round.LossB), formatFloatDelta(round.LossDelta),
+			formatIntDelta(round.ParticipantsA, round.ParticipantsB))
+	}
+	return nil
+}
+
+func handleMonitorControl(action monitoring.ControlAction, args []string) error {
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	reason := ""
+	var federations []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			federations = append(federations, arg)
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--reason", "-r":
+			reason = value
+		default:
+			return fmt.Errorf("unknown %s option: %s", action, arg)
+		}
+	}
+
+	if len(federations) != 1 {
+		return fmt.Errorf("%s requires a federation ID", action)
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey})
+	if err != nil {
+		return err
+	}
+	federation, err := client.ControlFederation(context.Background(), federations[0], action, reason)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Federation %s is %s\n", federation.ID, federation.Status)
+	return nil
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func formatOptional(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", *value)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return "-"
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalSeconds(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", *value)
+}
+
+func formatFloatDelta(delta *float64) string {
+	if delta == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.4f", *delta)
+}
+
+func formatSecondsDelta(delta *float64) string {
+	if delta == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f", *delta)
+}
+
+func formatIntDelta(a, b *int) string {
+	if a == nil || b == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+d", *b-*a)
+}
+
+func handleMonitorAPIKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("apikey command requires an action (create, list, revoke, rotate)")
+	}
+
+	action := args[0]
+	server := defaultMonitoringServer
+	apiKey := os.Getenv("FLGO_API_KEY")
+	req := monitoring.APIKeyRequest{}
+	keyID := ""
+
+	opts := args[1:]
+	if (action == "revoke" || action == "rotate") && len(opts) > 0 && !strings.HasPrefix(opts[0], "-") {
+		keyID = opts[0]
+		opts = opts[1:]
+	}
+
+	for i := 0; i < len(opts); i++ {
+		arg := opts[i]
+		if i+1 >= len(opts) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := opts[i+1]
+		i++
+
+		switch arg {
+		case "--server", "-s":
+			server = value
+		case "--api-key":
+			apiKey = value
+		case "--role", "-r":
+			req.Role = value
+		case "--name", "-n":
+			req.Name = value
+		case "--expires-in":
+			req.ExpiresIn = value
+		case "--org":
+			req.OrgID = value
+		default:
+			return fmt.Errorf("unknown apikey option: %s", arg)
+		}
+	}
+
+	client, err := monitorclient.New(monitorclient.Config{Server: server, APIKey: apiKey})
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch action {
+	case "create":
+		if req.Role == "" {
+			return fmt.Errorf("--role is required")
+		}
+		issued, err := client.CreateAPIKey(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created %s API key %s\n", issued.Role, issued.ID)
+		if issued.OrgID != "" {
+			fmt.Printf("🏢 Organization: %s\n", issued.OrgID)
+		}
+		printIssuedAPIKey(issued)
+
+	case "list":
+		keys, err := client.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			fmt.Println("No managed API keys")
+			return nil
+		}
+		fmt.Printf("%-36s  %-12s  %-9s  %-8s  %-12s  %s\n", "ID", "PREFIX", "ROLE", "STATE", "ORG", "NAME")
+		for _, key := range keys {
+			state := "active"
+			if key.RevokedAt != nil {
+				state = "revoked"
+			} else if !key.Active(time.Now()) {
+				state = "expired"
+			}
+			org := key.OrgID
+			if org == "" {
+				org = "-"
+			}
+			fmt.Printf("%-36s  %-12s  %-9s  %-8s  %-12s  %s\n", key.ID, key.Prefix, key.Role, state, org, key.Name)
+		}
+
+	case "revoke":
+		if keyID == "" {
+			return fmt.Errorf("usage: fx monitor apikey revoke <id>")
+		}
+		key, err := client.RevokeAPIKey(ctx, keyID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Revoked API key %s\n", key.ID)
+
+	case "rotate":
+		if keyID == "" {
+			return fmt.Errorf("usage: fx monitor apikey rotate <id>")
+		}
+		issued, err := client.RotateAPIKey(ctx, keyID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Rotated API key %s\n", issued.ID)
+		printIssuedAPIKey(issued)
+
+	default:
+		return fmt.Errorf("unknown apikey action: %s", action)
+	}
+
+	return nil
+}
+
+func printIssuedAPIKey(issued *monitoring.IssuedAPIKey) {
+	fmt.Printf("🔑 Key: %s\n", issued.Key)
+	if issued.ExpiresAt != nil {
+		fmt.Printf("⏰ Expires: %s\n", issued.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Println("⚠️  Store this key now; it cannot be retrieved again")
+}
+
+func printMonitorUsage() {
+	fmt.Println("Monitor command - Work with the monitoring server")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  fx monitor <subcommand> [options]")
+	fmt.Println()
+	fmt.Println("Available Subcommands:")
+	fmt.Println("  export         Export rounds, model updates and events as CSV or Parquet")
+	fmt.Println("  import         Backfill historical runs from JSON archives, exported CSV or aggregator logs")
+	fmt.Println("  backup         Save all federations, rounds, updates, events and dashboards to a file")
+	fmt.Println("  restore        Load a backup into a server, skipping records it already has")
+	fmt.Println("  contributions  Show the participation ledger: rounds, samples, quality and credits")
+	fmt.Println("  compare        Compare two federations round by round: accuracy, loss and participation")
+	fmt.Println("  pause          Stop a federation from starting new rounds (requires admin)")
+	fmt.Println("  resume         Resume a paused federation (requires admin)")
+	fmt.Println("  abort          End a federation through its aggregator (requires admin)")
+	fmt.Println("  apikey         Create, list, revoke and rotate API keys (requires admin)")
+	fmt.Println()
+	fmt.Println("Export Options:")
+	fmt.Println("  --federation, -f  Federation ID (required)")
+	fmt.Println("  --server, -s      Monitoring server URL (default: http://localhost:8080)")
+	fmt.Println("  --api-key         API key for authenticated servers (default: $FLGO_API_KEY)")
+	fmt.Println("  --format          csv or parquet (default: csv)")
+	fmt.Println("  --output, -o      Output directory (default: .)")
+	fmt.Println("  --datasets, -d    Comma-separated datasets: rounds,updates,events")
+	fmt.Println("  --collaborator, --round, --status, --start-time, --end-time  Filters")
+	fmt.Println()
+	fmt.Println("Import Options:")
+	fmt.Println("  fx monitor import [options] <file>...")
+	fmt.Println("  --federation, -f  Federation ID (required for logs; overrides IDs in files)")
+	fmt.Println("  --format          json, csv or log (default: from file extension)")
+	fmt.Println("  --server, -s, --api-key  As for export")
+	fmt.Println()
+	fmt.Println("Backup and Restore Options:")
+	fmt.Println("  fx monitor backup --out <file>     Write a zstd-compressed tar of JSON files")
+	fmt.Println("  fx monitor restore <file>          Restoring dashboards requires an admin key")
+	fmt.Println("  --server, -s, --api-key  As for export")
+	fmt.Println()
+	fmt.Println("Contributions Options:")
+	fmt.Println("  --federation, -f  Federation ID (default: all federations)")
+	fmt.Println("  --collaborator    Only this collaborator")
+	fmt.Println("  --format          table, csv or json (default: table)")
+	fmt.Println("  --server, -s, --api-key  As for export")
+	fmt.Println()
+	fmt.Println("Compare Options:")
+	fmt.Println("  fx monitor compare [options] <federation A> <federation B>   Deltas are B minus A")
+	fmt.Println("  --threshold, -t   Accuracy to compare the rounds and time to reach by")
+	fmt.Println("  --format          table or json (default: table)")
+	fmt.Println("  --server, -s, --api-key  As for export")
+	fmt.Println()
+	fmt.Println("Pause, Resume and Abort Options:")
+	fmt.Println("  fx monitor pause|resume|abort [options] <federation>")
+	fmt.Println("  --reason, -r      Recorded in the federation's events")
+	fmt.Println("  --server, -s, --api-key  As for export")
+	fmt.Println()
+	fmt.Println("API Key Commands:")
+	fmt.Println("  apikey create --role <role> [--name <name>] [--expires-in <duration>] [--org <org>]")
+	fmt.Println("  apikey list")
+	fmt.Println("  apikey revoke <id>")
+	fmt.Println("  apikey rotate <id>")
+	fmt.Println("  (all accept --server and --api-key)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  fx monitor export -f fed-1 --format parquet -o exports/")
+	fmt.Println("  fx monitor export -f fed-1 -d updates --collaborator collab1")
+	fmt.Println("  fx monitor import exports/fed-1_rounds.csv exports/fed-1_updates.csv")
+	fmt.Println("  fx monitor import -f fed-legacy aggregator.log")
+	fmt.Println("  fx monitor backup --out snapshot.tar.zst")
+	fmt.Println("  fx monitor restore -s http://new-server:8080 snapshot.tar.zst")
+	fmt.Println("  fx monitor contributions -f fed-1 --format csv > fed-1_ledger.csv")
+	fmt.Println("  fx monitor compare fed-fedavg fed-fedprox --threshold 0.9")
+	fmt.Println("  fx monitor pause fed-1 --reason \"cluster maintenance\"")
+	fmt.Println("  fx monitor apikey create --role monitor --name aggregator-1 --expires-in 720h")
+}
@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/ishaileshpant/fl-go/pkg/federation"
 )
@@ -25,6 +30,12 @@ func HandlePlanCommand(args []string) error {
 		return handlePlanValidate(subArgs)
 	case "show":
 		return handlePlanShow(subArgs)
+	case "render":
+		return handlePlanRender(subArgs)
+	case "migrate":
+		return handlePlanMigrate(subArgs)
+	case "diff":
+		return handlePlanDiff(subArgs)
 	case "--help", "-h":
 		printPlanUsage()
 		return nil
@@ -113,7 +124,8 @@ func handlePlanInit(args []string) error {
 
 func createDefaultPlan(path string, templateType string) error {
 	plan := &federation.FLPlan{
-		Rounds: 3,
+		SchemaVersion: federation.SchemaVersion,
+		Rounds:        3,
 		Collaborators: []federation.Collaborator{
 			{ID: "collaborator1", Address: "localhost:50052"},
 			{ID: "collaborator2", Address: "localhost:50053"},
@@ -274,18 +286,48 @@ func handlePlanValidate(args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load plan: %v", err)
 	}
+	if err := federation.ValidateTaskArgs(plan); err != nil {
+		return fmt.Errorf("invalid plan: %v", err)
+	}
 
 	fmt.Printf("✅ Plan validation successful\n")
 	fmt.Printf("📋 Configuration:\n")
+	fmt.Printf("   Schema Version: %d\n", plan.SchemaVersion)
 	fmt.Printf("   Rounds: %d\n", plan.Rounds)
 	fmt.Printf("   Collaborators: %d\n", len(plan.Collaborators))
 	fmt.Printf("   Aggregator: %s\n", plan.Aggregator.Address)
 	fmt.Printf("   Initial Model: %s\n", plan.InitialModel)
 	fmt.Printf("   Output Model: %s\n", plan.OutputModel)
+	for _, collab := range plan.Collaborators {
+		if args := collab.TaskArgs; len(args.Train) > 0 || len(args.Evaluate) > 0 {
+			fmt.Printf("   Task Args of %s:", collab.ID)
+			if len(args.Train) > 0 {
+				fmt.Printf(" train %s", formatArgs(args.Train))
+			}
+			if len(args.Evaluate) > 0 {
+				fmt.Printf(" evaluate %s", formatArgs(args.Evaluate))
+			}
+			fmt.Println()
+		}
+	}
 
 	return nil
 }
 
+// formatArgs renders task args as key=value pairs in key order
+func formatArgs(args map[string]interface{}) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, args[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
 func handlePlanShow(args []string) error {
 	planPath := "plan.yaml"
 	if len(args) > 0 {
@@ -301,6 +343,164 @@ func handlePlanShow(args []string) error {
 	return nil
 }
 
+// handlePlanRender prints the effective plan: the plan merged with the plans
+// it extends. With --resolve, references and FLGO_ overrides are applied too.
+func handlePlanRender(args []string) error {
+	planPath := "plan.yaml"
+	output := "-"
+	resolve := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--resolve":
+			resolve = true
+		case "--output", "-o":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			output = args[i+1]
+			i++
+		default:
+			if len(arg) > 0 && arg[0] == '-' {
+				return fmt.Errorf("unknown plan render option: %s", arg)
+			}
+			planPath = arg
+		}
+	}
+
+	var content []byte
+	if resolve {
+		plan, err := federation.LoadPlan(planPath)
+		if err != nil {
+			return fmt.Errorf("failed to load plan: %v", err)
+		}
+		if content, err = yaml.Marshal(plan); err != nil {
+			return fmt.Errorf("failed to encode plan: %v", err)
+		}
+	} else {
+		var err error
+		if content, err = federation.RenderPlan(planPath); err != nil {
+			return fmt.Errorf("failed to render plan: %v", err)
+		}
+	}
+
+	if output == "-" {
+		fmt.Print(string(content))
+		return nil
+	}
+	if err := os.WriteFile(output, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	fmt.Printf("✅ Effective plan written to %s\n", output)
+	return nil
+}
+
+// handlePlanMigrate rewrites a plan in the current schema version. It writes
+// to stdout unless --write or --output is given.
+func handlePlanMigrate(args []string) error {
+	planPath := "plan.yaml"
+	output := "-"
+	write := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--write", "-w":
+			write = true
+		case "--output", "-o":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			output = args[i+1]
+			i++
+		default:
+			if len(arg) > 0 && arg[0] == '-' {
+				return fmt.Errorf("unknown plan migrate option: %s", arg)
+			}
+			planPath = arg
+		}
+	}
+	if write {
+		output = planPath
+	}
+
+	content, from, notes, err := federation.MigratePlanFile(planPath)
+	if err != nil {
+		return fmt.Errorf("failed to migrate plan: %v", err)
+	}
+
+	// Notes go to stderr so that stdout holds only the plan
+	if from == federation.SchemaVersion {
+		fmt.Fprintf(os.Stderr, "✅ %s is already at schema version %d\n", planPath, from)
+	} else {
+		fmt.Fprintf(os.Stderr, "🔄 Migrating %s from schema version %d to %d\n", planPath, from, federation.SchemaVersion)
+		for _, note := range notes {
+			fmt.Fprintf(os.Stderr, "   - %s\n", note)
+		}
+		if len(notes) == 0 {
+			fmt.Fprintf(os.Stderr, "   - no settings changed\n")
+		}
+	}
+
+	if output == "-" {
+		fmt.Print(string(content))
+		return nil
+	}
+	if err := os.WriteFile(output, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Migrated plan written to %s\n", output)
+	return nil
+}
+
+// handlePlanDiff prints the settings that differ between two plans and
+// whether a collaborator running one can join an aggregator running the other
+func handlePlanDiff(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: fx plan diff <planA.yaml> <planB.yaml>")
+	}
+	pathA, pathB := args[0], args[1]
+
+	changes, err := federation.DiffPlans(pathA, pathB)
+	if err != nil {
+		return fmt.Errorf("failed to compare plans: %v", err)
+	}
+	if len(changes) == 0 {
+		fmt.Printf("✅ No differences between %s and %s\n", pathA, pathB)
+	} else {
+		fmt.Printf("--- %s\n+++ %s\n", pathA, pathB)
+		for _, change := range changes {
+			switch {
+			case change.New == "":
+				fmt.Printf("- %s: %s\n", change.Path, change.Old)
+			case change.Old == "":
+				fmt.Printf("+ %s: %s\n", change.Path, change.New)
+			default:
+				fmt.Printf("~ %s: %s -> %s\n", change.Path, change.Old, change.New)
+			}
+		}
+	}
+
+	fingerprintA, errA := planFingerprint(pathA)
+	fingerprintB, errB := planFingerprint(pathB)
+	switch {
+	case errA != nil || errB != nil:
+		fmt.Printf("⚠️  Fingerprints unavailable: %v\n", errors.Join(errA, errB))
+	case fingerprintA == fingerprintB:
+		fmt.Printf("🔗 Fingerprint %.12s: collaborators of either plan can join an aggregator running the other\n", fingerprintA)
+	default:
+		fmt.Printf("❌ Fingerprints %.12s and %.12s differ: an aggregator running one rejects collaborators running the other\n", fingerprintA, fingerprintB)
+	}
+	return nil
+}
+
+// planFingerprint returns the fingerprint of the plan at path as collaborators
+// and aggregators load it
+func planFingerprint(path string) (string, error) {
+	plan, err := federation.LoadPlan(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+	return federation.PlanFingerprint(plan)
+}
+
 func printPlanUsage() {
 	fmt.Println("Plan command - Manage federated learning plans")
 	fmt.Println()
@@ -311,9 +511,16 @@ func printPlanUsage() {
 	fmt.Println("  init      Initialize a new FL workspace")
 	fmt.Println("  validate  Validate an existing plan")
 	fmt.Println("  show      Display plan contents")
+	fmt.Println("  render    Display the effective plan, merged with the plans it extends")
+	fmt.Println("  migrate   Rewrite a plan in the current schema version")
+	fmt.Println("  diff      Show the settings that differ between two plans")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  fx plan init --name my_experiment    # Create workspace 'my_experiment'")
 	fmt.Println("  fx plan validate plan.yaml           # Validate plan.yaml")
 	fmt.Println("  fx plan show                          # Show current plan")
+	fmt.Println("  fx plan render site-a.yaml            # Show site-a.yaml merged onto its base plan")
+	fmt.Println("  fx plan render --resolve -o eff.yaml  # Write the plan with references and overrides resolved")
+	fmt.Println("  fx plan migrate --write plan.yaml     # Update plan.yaml to the current schema version")
+	fmt.Println("  fx plan diff plan.yaml site-a.yaml    # Compare two plans and their fingerprints")
 }
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+	"github.com/ishaileshpant/fl-go/pkg/simulation"
+)
+
+// HandleSimulateCommand runs a federation of virtual collaborators in process
+func HandleSimulateCommand(args []string) error {
+	options := map[string]string{
+		"--plan":          "plan.yaml",
+		"--clients":       "10",
+		"--trainer":       "synthetic",
+		"--heterogeneity": "0.5",
+		"--model-size":    "100",
+		"--work-dir":      "simulation",
+	}
+
+	aliases := map[string]string{"-p": "--plan", "-c": "--clients", "-r": "--rounds", "-o": "--output"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if alias, ok := aliases[arg]; ok {
+			arg = alias
+		}
+		switch arg {
+		case "--help", "-h":
+			printSimulateUsage()
+			return nil
+		case "--plan", "--clients", "--rounds", "--fraction", "--seed", "--trainer",
+			"--heterogeneity", "--model-size", "--work-dir", "--output", "--report":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", arg)
+			}
+			options[arg] = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown simulate option: %s", arg)
+		}
+	}
+
+	plan, err := federation.LoadPlan(options["--plan"])
+	if err != nil {
+		return fmt.Errorf("failed to load plan: %v", err)
+	}
+	if _, err := aggregator.WarmStart(plan); err != nil {
+		return fmt.Errorf("failed to warm-start: %v", err)
+	}
+
+	config := simulation.Config{}
+	ints := []struct {
+		option string
+		value  *int
+	}{
+		{"--clients", &config.Clients},
+		{"--rounds", &config.Rounds},
+		{"--model-size", &config.ModelSize},
+	}
+	for _, o := range ints {
+		if v, ok := options[o.option]; ok {
+			if *o.value, err = strconv.Atoi(v); err != nil {
+				return fmt.Errorf("invalid %s: %s", o.option, v)
+			}
+		}
+	}
+	if v, ok := options["--fraction"]; ok {
+		if config.Fraction, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("invalid --fraction: %s", v)
+		}
+	}
+	config.Seed = plan.Seed
+	if v, ok := options["--seed"]; ok {
+		if config.Seed, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return fmt.Errorf("invalid --seed: %s", v)
+		}
+	}
+
+	switch options["--trainer"] {
+	case "synthetic":
+		heterogeneity, err := strconv.ParseFloat(options["--heterogeneity"], 64)
+		if err != nil {
+			return fmt.Errorf("invalid --heterogeneity: %s", options["--heterogeneity"])
+		}
+		config.Trainer = simulation.NewSyntheticTrainer(config.Seed, heterogeneity)
+	case "task":
+		config.Trainer = &simulation.TaskTrainer{Task: plan.Tasks.Train, Dir: options["--work-dir"]}
+	default:
+		return fmt.Errorf("unknown trainer %q (expected synthetic or task)", options["--trainer"])
+	}
+
+	ctx, stop := platform.ShutdownContext(context.Background())
+	defer stop()
+
+	result, err := simulation.Run(ctx, plan, config)
+	if err != nil {
+		return err
+	}
+	printSimulationResult(result)
+
+	if path, ok := options["--output"]; ok {
+		if err := simulation.SaveModel(path, result.Model); err != nil {
+			return fmt.Errorf("failed to save model: %v", err)
+		}
+		fmt.Printf("Final model written to %s\n", path)
+	}
+	if path, ok := options["--report"]; ok {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write report: %v", err)
+		}
+		fmt.Printf("Round results written to %s\n", path)
+	}
+	return nil
+}
+
+func printSimulationResult(result *simulation.Result) {
+	fmt.Printf("\nSimulated %d rounds of %s with %d clients\n", len(result.Rounds), result.Algorithm, result.Clients)
+	fmt.Println(strings.Repeat("-", 52))
+	fmt.Printf("%-6s %-13s %-14s %-14s\n", "ROUND", "PARTICIPANTS", "MODEL DELTA", "LOSS")
+	for _, r := range result.Rounds {
+		fmt.Printf("%-6d %-13d %-14.6f %-14.6f\n", r.Round, r.Participants, r.ModelDelta, r.Loss)
+	}
+}
+
+func printSimulateUsage() {
+	fmt.Println("Simulate Command:")
+	fmt.Println("  fx simulate --clients 50 --plan plan.yaml   # Run 50 virtual collaborators in process")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --plan, -p        Plan whose algorithm, rounds and initial model are used (default: plan.yaml)")
+	fmt.Println("  --clients, -c     Number of virtual collaborators (default: 10)")
+	fmt.Println("  --rounds, -r      Override the plan's rounds")
+	fmt.Println("  --fraction        Share of clients sampled each round (default: 1)")
+	fmt.Println("  --seed            Seed of sample counts, sampling and synthetic data (default: the plan's seed)")
+	fmt.Println("  --trainer         synthetic (quadratic loss per client) or task (the plan's train task)")
+	fmt.Println("  --heterogeneity   synthetic: spread of client optima (default: 0.5)")
+	fmt.Println("  --model-size      Parameters of a zero model when the plan names no initial model (default: 100)")
+	fmt.Println("  --work-dir        task: directory for the clients' model files (default: simulation)")
+	fmt.Println("  --output, -o      Write the final model to this path")
+	fmt.Println("  --report          Write the round results as JSON to this path")
+}
@@ -0,0 +1,64 @@
+package collaborator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+)
+
+// errModelOutdated is returned for updates the aggregator rejected because
+// they were trained on an outdated model. Training again on the latest model
+// may succeed.
+var errModelOutdated = errors.New("update was trained on an outdated model")
+
+// submit sends an update or task result and reacts to the aggregator's Ack.
+// It resends while the aggregator asks to retry later, returns
+// errModelOutdated for stale and mismatched updates, and fails for updates
+// the aggregator cannot use. A duplicate counts as submitted, since the
+// aggregator already has the update.
+func (c *SimpleCollaborator) submit(name string, fn func(ctx context.Context) (*pb.Ack, error)) error {
+	for attempt := 1; ; attempt++ {
+		var ack *pb.Ack
+		err := c.call(name, func(ctx context.Context) error {
+			var err error
+			ack, err = fn(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case ack.Success:
+			c.submitted.Add(1)
+			return nil
+		case ack.Status == pb.AckStatus_ACK_REJECTED_DUPLICATE:
+			log.Printf("Aggregator already has the %s: %s", name, ack.Message)
+			c.submitted.Add(1)
+			return nil
+		case ack.Status == pb.AckStatus_ACK_REJECTED_STALE, ack.Status == pb.AckStatus_ACK_REJECTED_ROUND_MISMATCH:
+			return fmt.Errorf("%w: %s", errModelOutdated, ack.Message)
+		case ack.Status == pb.AckStatus_ACK_RETRY_AFTER && attempt < c.retry.maxAttempts:
+			delay := retryAfter(ack)
+			log.Printf("Aggregator asked to resend the %s in %v (attempt %d/%d): %s",
+				name, delay, attempt, c.retry.maxAttempts, ack.Message)
+			if err := c.retry.sleep(c.ctx, delay); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("aggregator rejected the %s: %s", name, ack.Message)
+		}
+	}
+}
+
+// retryAfter returns how long to wait before resending, at least a second
+func retryAfter(ack *pb.Ack) time.Duration {
+	if ack.RetryAfterSeconds < 1 {
+		return time.Second
+	}
+	return time.Duration(ack.RetryAfterSeconds) * time.Second
+}
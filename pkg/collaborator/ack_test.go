@@ -0,0 +1,68 @@
+package collaborator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestSubmitReactsToAck(t *testing.T) {
+	retry := &pb.Ack{Status: pb.AckStatus_ACK_RETRY_AFTER, RetryAfterSeconds: 3}
+	accepted := &pb.Ack{Success: true}
+
+	tests := []struct {
+		name          string
+		acks          []*pb.Ack // returned by successive submissions
+		wantSubmits   int
+		wantErr       error // errModelOutdated, or any error when wantFailure
+		wantFailure   bool
+		wantSubmitted int64
+	}{
+		{name: "accepted", acks: []*pb.Ack{accepted}, wantSubmits: 1, wantSubmitted: 1},
+		{name: "retry after", acks: []*pb.Ack{retry, retry, accepted}, wantSubmits: 3, wantSubmitted: 1},
+		{name: "retry exhausted", acks: []*pb.Ack{retry, retry, retry}, wantSubmits: 3, wantFailure: true},
+		{name: "duplicate", acks: []*pb.Ack{{Status: pb.AckStatus_ACK_REJECTED_DUPLICATE}}, wantSubmits: 1, wantSubmitted: 1},
+		{name: "stale", acks: []*pb.Ack{{Status: pb.AckStatus_ACK_REJECTED_STALE}}, wantSubmits: 1, wantErr: errModelOutdated},
+		{name: "round mismatch", acks: []*pb.Ack{{Status: pb.AckStatus_ACK_REJECTED_ROUND_MISMATCH}}, wantSubmits: 1, wantErr: errModelOutdated},
+		{name: "invalid", acks: []*pb.Ack{{Status: pb.AckStatus_ACK_REJECTED_INVALID}}, wantSubmits: 1, wantFailure: true},
+		{name: "no status", acks: []*pb.Ack{{}}, wantSubmits: 1, wantFailure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollaborator(&federation.FLPlan{Retry: federation.RetryConfig{MaxAttempts: 3}}, "c1")
+			var slept []time.Duration
+			c.retry.sleep = func(ctx context.Context, d time.Duration) error {
+				slept = append(slept, d)
+				return nil
+			}
+
+			submits := 0
+			err := c.submit("update", func(ctx context.Context) (*pb.Ack, error) {
+				submits++
+				return tt.acks[submits-1], nil
+			})
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("submit() error = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && (err != nil) != tt.wantFailure:
+				t.Errorf("submit() error = %v, wantFailure %v", err, tt.wantFailure)
+			}
+			if submits != tt.wantSubmits {
+				t.Errorf("submits = %d, want %d", submits, tt.wantSubmits)
+			}
+			if got := c.submitted.Load(); got != tt.wantSubmitted {
+				t.Errorf("submitted = %d, want %d", got, tt.wantSubmitted)
+			}
+			for _, d := range slept {
+				if d != 3*time.Second {
+					t.Errorf("waited %v before resending, want 3s", d)
+				}
+			}
+		})
+	}
+}
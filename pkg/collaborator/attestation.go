@@ -0,0 +1,35 @@
+package collaborator
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// attest returns the attestation evidence the collaborator presents when it
+// joins with plan fingerprint fingerprint, or nil when its local config sets
+// no attestation type. The evidence commits to a challenge the aggregator
+// issues for the join.
+func (c *SimpleCollaborator) attest(ctx context.Context, fingerprint string) (*pb.Attestation, error) {
+	config := c.local.Attestation
+	if config.Type == "" {
+		return nil, nil
+	}
+	attester, err := security.NewAttester(config.Type, config.Command)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := c.cli.GetAttestationChallenge(ctx, &pb.AttestationChallengeRequest{CollaboratorId: c.id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an attestation challenge: %v", err)
+	}
+	evidence, err := attester.Attest(ctx, security.AttestationReportData(c.id, fingerprint, challenge.Nonce))
+	if err != nil {
+		return nil, fmt.Errorf("failed to produce %s attestation evidence: %v", config.Type, err)
+	}
+	log.Printf("Presenting %d bytes of %s attestation evidence", len(evidence), config.Type)
+	return &pb.Attestation{Type: config.Type, Evidence: evidence}, nil
+}
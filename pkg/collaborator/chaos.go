@@ -0,0 +1,157 @@
+package collaborator
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// chaosScale is what a corrupted update multiplies the weights by. Flipping
+// their sign keeps them finite, so that they reach the aggregation instead of
+// being dropped by update validation.
+const chaosScale = -10
+
+// ChaosConfig injects faults into a collaborator to test how a federation
+// copes with unreliable collaborators. The zero value injects none.
+type ChaosConfig struct {
+	Drop     float64       // probability of skipping a round without training
+	DelayMin time.Duration // submissions wait a random time between DelayMin and DelayMax
+	DelayMax time.Duration
+	Corrupt  float64 // probability of sending a corrupted update
+}
+
+// ParseChaos parses a fault specification such as
+// "drop=0.1,delay=2s..10s,corrupt=0.05". A delay without a range waits
+// exactly that long.
+func ParseChaos(spec string) (ChaosConfig, error) {
+	var config ChaosConfig
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return ChaosConfig{}, fmt.Errorf("invalid chaos setting %q, want key=value", field)
+		}
+		var err error
+		switch key {
+		case "drop":
+			config.Drop, err = parseProbability(value)
+		case "corrupt":
+			config.Corrupt, err = parseProbability(value)
+		case "delay":
+			config.DelayMin, config.DelayMax, err = parseDelay(value)
+		default:
+			return ChaosConfig{}, fmt.Errorf("unknown chaos setting %q (want drop, delay or corrupt)", key)
+		}
+		if err != nil {
+			return ChaosConfig{}, fmt.Errorf("invalid chaos %s: %v", key, err)
+		}
+	}
+	return config, nil
+}
+
+func parseProbability(s string) (float64, error) {
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil || p < 0 || p > 1 {
+		return 0, fmt.Errorf("%q is not a probability between 0 and 1", s)
+	}
+	return p, nil
+}
+
+func parseDelay(s string) (time.Duration, time.Duration, error) {
+	lo, hi, isRange := strings.Cut(s, "..")
+	minDelay, err := time.ParseDuration(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxDelay := minDelay
+	if isRange {
+		if maxDelay, err = time.ParseDuration(hi); err != nil {
+			return 0, 0, err
+		}
+	}
+	if minDelay < 0 || maxDelay < minDelay {
+		return 0, 0, fmt.Errorf("%q is not a range of non-negative durations", s)
+	}
+	return minDelay, maxDelay, nil
+}
+
+// Enabled reports whether the config injects any fault
+func (c ChaosConfig) Enabled() bool {
+	return c.Drop > 0 || c.Corrupt > 0 || c.DelayMax > 0
+}
+
+func (c ChaosConfig) String() string {
+	return fmt.Sprintf("drop %g, delay %s..%s, corrupt %g", c.Drop, c.DelayMin, c.DelayMax, c.Corrupt)
+}
+
+// SetChaos makes the collaborator inject the faults of config. Seeded plans
+// inject the same faults in every run.
+func (c *SimpleCollaborator) SetChaos(config ChaosConfig) error {
+	var seed [32]byte
+	if c.plan.Seed != 0 {
+		seed = federation.DeriveSeed(c.plan.Seed, "chaos/"+c.id)
+	} else if _, err := crand.Read(seed[:]); err != nil {
+		return fmt.Errorf("failed to seed chaos: %v", err)
+	}
+	c.chaos = config
+	c.chaosRand = rand.New(rand.NewChaCha8(seed))
+	if config.Enabled() {
+		log.Printf("Chaos mode: %s", config)
+	}
+	return nil
+}
+
+// chaosDrop tells whether the collaborator skips round
+func (c *SimpleCollaborator) chaosDrop(round int) bool {
+	if c.chaos.Drop == 0 || c.chaosRand.Float64() >= c.chaos.Drop {
+		return false
+	}
+	log.Printf("Chaos: dropping out of round %d", round)
+	return true
+}
+
+// chaosUpdate delays the submission of an update and may corrupt it. It
+// returns the update to submit, or the context's error when the collaborator
+// stopped while waiting.
+func (c *SimpleCollaborator) chaosUpdate(weights []byte) ([]byte, error) {
+	if !c.chaos.Enabled() {
+		return weights, nil
+	}
+	if c.chaos.DelayMax > 0 {
+		delay := c.chaos.DelayMin
+		if spread := c.chaos.DelayMax - c.chaos.DelayMin; spread > 0 {
+			delay += time.Duration(c.chaosRand.Int64N(int64(spread) + 1))
+		}
+		log.Printf("Chaos: delaying the update by %s", delay.Round(time.Millisecond))
+		if err := sleepContext(c.ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+	if c.chaos.Corrupt > 0 && c.chaosRand.Float64() < c.chaos.Corrupt {
+		log.Printf("Chaos: corrupting the update")
+		return corruptWeights(weights), nil
+	}
+	return weights, nil
+}
+
+// corruptWeights returns the weights with their sign flipped and scaled by
+// chaosScale. Data that is not a float32 vector is inverted bytewise.
+func corruptWeights(data []byte) []byte {
+	if len(data) == 0 || len(data)%4 != 0 {
+		corrupted := make([]byte, len(data))
+		for i, b := range data {
+			corrupted[i] = ^b
+		}
+		return corrupted
+	}
+	weights := decodeWeights(data)
+	for i := range weights {
+		weights[i] *= chaosScale
+	}
+	return encodeWeights(weights)
+}
@@ -0,0 +1,84 @@
+package collaborator
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestParseChaos(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ChaosConfig
+		wantErr bool
+	}{
+		{spec: "drop=0.1,delay=2s..10s", want: ChaosConfig{Drop: 0.1, DelayMin: 2 * time.Second, DelayMax: 10 * time.Second}},
+		{spec: "delay=500ms, corrupt=1", want: ChaosConfig{DelayMin: 500 * time.Millisecond, DelayMax: 500 * time.Millisecond, Corrupt: 1}},
+		{spec: "drop=1.5", wantErr: true},
+		{spec: "delay=10s..2s", wantErr: true},
+		{spec: "delay=soon", wantErr: true},
+		{spec: "crash=0.1", wantErr: true},
+		{spec: "drop", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseChaos(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseChaos(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseChaos(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestChaosFaults(t *testing.T) {
+	plan := &federation.FLPlan{Seed: 7}
+	drops := func() []bool {
+		c := NewCollaborator(plan, "c1")
+		if err := c.SetChaos(ChaosConfig{Drop: 0.5}); err != nil {
+			t.Fatal(err)
+		}
+		var dropped []bool
+		for round := 1; round <= 20; round++ {
+			dropped = append(dropped, c.chaosDrop(round))
+		}
+		return dropped
+	}
+	first := drops()
+	if !slices.Contains(first, true) || !slices.Contains(first, false) {
+		t.Errorf("dropping half of 20 rounds dropped %v", first)
+	}
+	if !slices.Equal(first, drops()) {
+		t.Error("a seeded plan dropped different rounds")
+	}
+
+	// Without chaos the update is submitted unchanged and at once
+	c := NewCollaborator(plan, "c1")
+	update := encodeWeights([]float32{1, -2})
+	if got, err := c.chaosUpdate(update); err != nil || !slices.Equal(got, update) {
+		t.Errorf("chaosUpdate without chaos = %v, %v", got, err)
+	}
+
+	if err := c.SetChaos(ChaosConfig{Corrupt: 1, DelayMin: time.Millisecond, DelayMax: 2 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.chaosUpdate(update)
+	if err != nil || !slices.Equal(decodeWeights(got), []float32{-10, 20}) {
+		t.Errorf("corrupted update = %v, %v", decodeWeights(got), err)
+	}
+
+	// A stopped collaborator stops waiting to submit
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.SetContext(ctx)
+	if err := c.SetChaos(ChaosConfig{DelayMin: time.Hour, DelayMax: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.chaosUpdate(update); err == nil {
+		t.Error("chaosUpdate of a stopped collaborator waited out the delay")
+	}
+}
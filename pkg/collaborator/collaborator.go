@@ -2,114 +2,401 @@ package collaborator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/ishaileshpant/fl-go/api"
 	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
 	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type SimpleCollaborator struct {
-	plan *federation.FLPlan
-	id   string
-	cli  pb.FederatedLearningClient
+	plan  *federation.FLPlan
+	id    string
+	cli   pb.FederatedLearningClient
+	conn  *grpc.ClientConn
+	peers map[string]pb.FederatedLearningClient // peer address -> client (decentralized mode)
+
+	imageDigest  string             // container image of the last training task, sent with the update
+	numSamples   int64              // samples in the validated dataset, sent with each update
+	trainMetrics map[string]float64 // local metrics of the last training task, sent with the update
+	localSteps   int64              // optimizer steps of the last training task, sent with the update
+
+	local federation.LocalConfig // the collaborator's own settings
+	noise *rand.Rand             // source of local privacy noise
+
+	chaos     ChaosConfig // faults to inject; none unless set
+	chaosRand *rand.Rand  // decides which faults are injected
+
+	capabilities *pb.Capabilities           // negotiated with the aggregator on join
+	events       <-chan *pb.FederationEvent // control events of the aggregator; nil while polling
+
+	modelRound int    // aggregator round of the model being trained, sent with the update
+	modelHash  string // hash of the model being trained, sent with the update
+
+	verifier *security.ModelVerifier     // checks the aggregator's model signatures; nil unless the plan signs models
+	heKey    *security.PaillierPublicKey // encrypts updates; nil unless the plan encrypts them
+
+	stopResources context.CancelFunc // stops resource reporting; nil unless reporting
+
+	retry  retryPolicy
+	joined bool // joined the federation at least once
+	rejoin bool // the aggregator was unreachable, so it may have restarted
+
+	modelDir  string          // holds the models and task outputs; defaultModelDir unless set
+	ctx       context.Context // cancels aggregator calls and waits between rounds
+	round     atomic.Int64    // current round
+	submitted atomic.Int64    // updates and task results acknowledged by the aggregator
 }
 
 func NewCollaborator(plan *federation.FLPlan, id string) *SimpleCollaborator {
-	return &SimpleCollaborator{plan: plan, id: id}
+	return &SimpleCollaborator{plan: plan, id: id, retry: newRetryPolicy(plan.Retry), ctx: context.Background()}
+}
+
+// defaultModelDir is where collaborators keep their models unless told
+// otherwise
+const defaultModelDir = "models"
+
+// SetModelDir makes the collaborator keep its models and task outputs in dir,
+// so that several collaborators can share a working directory
+func (c *SimpleCollaborator) SetModelDir(dir string) {
+	c.modelDir = dir
+}
+
+// modelPath returns the path of the file name in the model directory
+func (c *SimpleCollaborator) modelPath(name string) string {
+	dir := c.modelDir
+	if dir == "" {
+		dir = defaultModelDir
+	}
+	return filepath.Join(dir, name)
+}
+
+// SetContext sets the context that stops the collaborator when it is
+// canceled. A running training task is finished first.
+func (c *SimpleCollaborator) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Progress returns the current round and the number of updates and task
+// results the aggregator acknowledged
+func (c *SimpleCollaborator) Progress() (round, submitted int) {
+	return int(c.round.Load()), int(c.submitted.Load())
 }
 
 func (c *SimpleCollaborator) Connect() error {
+	if err := c.ValidateData(); err != nil {
+		return err
+	}
 	log.Printf("Connecting to aggregator at %s", c.plan.Aggregator.Address)
 
-	// Initialize TLS manager for secure communication
-	tlsManager, err := security.NewTLSManager(security.TLSConfig(c.plan.Security.TLS), "certs")
+	verifier, err := modelVerifier(c.plan)
 	if err != nil {
-		return fmt.Errorf("failed to initialize TLS manager: %w", err)
+		return err
 	}
-
-	// Get client dial options with TLS support
-	dialOpts, err := tlsManager.NewClientDialOptions()
-	if err != nil {
-		return fmt.Errorf("failed to get client dial options: %w", err)
+	c.verifier = verifier
+	if c.heKey, err = homomorphicKey(c.plan); err != nil {
+		return err
 	}
 
-	// Fallback to insecure credentials if TLS is not enabled
-	if len(dialOpts) == 0 {
-		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	dialOpts, err := c.dialOptions()
+	if err != nil {
+		return err
 	}
 
 	conn, err := grpc.NewClient(c.plan.Aggregator.Address, dialOpts...)
 	if err != nil {
 		return err
 	}
+	c.conn = conn
 	c.cli = pb.NewFederatedLearningClient(conn)
-	resp, err := c.cli.JoinFederation(context.Background(), &pb.JoinRequest{CollaboratorId: c.id})
+	if err := c.call("join federation", c.join); err != nil {
+		return err
+	}
+	c.watchEvents()
+	c.reportResources()
+	return nil
+}
+
+// Close closes the connection to the aggregator
+func (c *SimpleCollaborator) Close() error {
+	if c.stopResources != nil {
+		c.stopResources()
+	}
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// join registers with the aggregator, agrees on the features both support
+// and stores the model it hands out
+func (c *SimpleCollaborator) join(ctx context.Context) error {
+	fingerprint, err := federation.PlanFingerprint(c.plan)
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint plan: %v", err)
+	}
+	attestation, err := c.attest(ctx, fingerprint)
 	if err != nil {
 		return err
 	}
+	local := transport.LocalCapabilities()
+	local.HasGpu = monitoring.HasGPU()
+	resp, err := c.cli.JoinFederation(ctx, &pb.JoinRequest{
+		CollaboratorId:  c.id,
+		Capabilities:    local,
+		PlanFingerprint: fingerprint,
+		Attestation:     attestation,
+	})
+	if err != nil {
+		return err
+	}
+
+	var required []string
+	if c.pollsTasks() {
+		required = append(required, transport.FeatureTaskDispatch)
+	}
+	if c.heKey != nil {
+		required = append(required, transport.FeatureEncrypted)
+	}
+	capabilities, err := transport.Negotiate(transport.LocalCapabilities(), resp.Capabilities, required...)
+	if err != nil {
+		return fmt.Errorf("aggregator at %s is incompatible: %v", c.plan.Aggregator.Address, err)
+	}
+	if c.local.Privacy.Enabled && !transport.HasFeature(capabilities, transport.FeatureLocalPrivacy) {
+		log.Printf("Warning: aggregator does not record local privacy parameters; updates are still privatized")
+	}
+	c.capabilities = capabilities
+	c.joined = true
 
-	// Create models directory if it doesn't exist
-	if err := os.MkdirAll("models", 0750); err != nil {
+	return c.setModel(resp.InitialModel, resp.ModelSignature, 0)
+}
+
+// setModel stores the model to train next, handed out by the aggregator in
+// round. A model that is not a finite float32 array is refused, and so is,
+// when the plan signs models, one whose signature does not verify.
+func (c *SimpleCollaborator) setModel(model, signature []byte, round int) error {
+	if err := transport.CheckWeights(model, 0); err != nil {
+		return fmt.Errorf("refusing the model of round %d: %v", round, err)
+	}
+	modelHash := transport.ModelHash(model)
+	if err := c.verifyModel(modelHash, signature); err != nil {
+		return fmt.Errorf("refusing the model of round %d: %v", round, err)
+	}
+
+	// Replaced atomically, so a crash never leaves a partial model to train on
+	if err := storage.WriteFile(c.modelPath("model_init.pt"), model); err != nil {
 		return err
 	}
+	c.modelRound = round
+	c.modelHash = modelHash
+	return nil
+}
 
-	return os.WriteFile("models/model_init.pt", resp.InitialModel, 0600)
+// useModel stores the model of a GetLatestModel answer for round. A not
+// modified answer confirms that the cached model is still current.
+func (c *SimpleCollaborator) useModel(resp *pb.GetModelResponse, round int) error {
+	if resp.NotModified {
+		c.modelRound = round
+		return nil
+	}
+	return c.setModel(resp.ModelWeights, resp.ModelSignature, round)
 }
 
-func (c *SimpleCollaborator) RunTrainTask(task federation.TaskConfig) ([]byte, error) {
-	args := []string{task.Script, "--model-in", "models/model_init.pt", "--model-out", "models/update.pt"}
-	for k, v := range task.Args {
-		// Validate key and value to prevent injection
-		if !isValidArgument(k) || !isValidArgument(fmt.Sprint(v)) {
-			return nil, fmt.Errorf("invalid argument detected: key=%s, value=%v", k, v)
-		}
+// verifyModel checks the aggregator's signature of a model when the plan
+// signs models
+func (c *SimpleCollaborator) verifyModel(modelHash string, signature []byte) error {
+	if c.verifier == nil {
+		return nil
+	}
+	return c.verifier.Verify(modelHash, signature)
+}
 
-		// Convert snake_case to kebab-case for Python argparse
-		kebabKey := strings.ReplaceAll(k, "_", "-")
-		args = append(args, fmt.Sprintf("--%s", kebabKey), fmt.Sprint(v))
+// modelVerifier loads the public key the plan's models are signed with, or
+// returns nil when the plan does not sign models
+func modelVerifier(plan *federation.FLPlan) (*security.ModelVerifier, error) {
+	signing := plan.Security.ModelSigning
+	if !signing.Enabled {
+		return nil, nil
+	}
+	if signing.PublicKeyPath == "" {
+		return nil, fmt.Errorf("security.model_signing requires public_key_path to verify models")
 	}
+	verifier, err := security.LoadModelVerifier(signing.PublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load model signing public key: %w", err)
+	}
+	return verifier, nil
+}
 
-	log.Printf("Running training task: python3 %v", args)
-	cmd := exec.Command("python3", args...) // #nosec G204 - Arguments validated with whitelist above
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+// dialOptions builds gRPC dial options honoring the plan's TLS settings
+func (c *SimpleCollaborator) dialOptions() ([]grpc.DialOption, error) {
+	// Initialize TLS manager for secure communication
+	tlsManager, err := security.NewTLSManager(security.TLSConfig(c.plan.Security.TLS), "certs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize TLS manager: %w", err)
+	}
+
+	// Get client dial options with TLS support
+	dialOpts, err := tlsManager.NewClientDialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client dial options: %w", err)
+	}
+
+	// Fallback to insecure credentials if TLS is not enabled
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	dialOpts = append(dialOpts, transport.DialOptions(c.plan.GRPC)...)
+
+	return dialOpts, nil
+}
+
+// RunTrainTask trains on the current model with the task's runner and returns
+// the update. Tasks with metrics report their local training metrics, which
+// are sent with the update.
+func (c *SimpleCollaborator) RunTrainTask(task federation.TaskConfig) ([]byte, error) {
+	runner, err := NewTaskRunner(task)
+	if err != nil {
 		return nil, err
 	}
-	return os.ReadFile("models/update.pt")
+	files := TaskFiles{ModelIn: c.modelPath("model_init.pt"), ModelOut: c.modelPath("update.pt")}
+	if task.Metrics {
+		files.MetricsOut = c.modelPath("train_metrics.json")
+		if err := os.Remove(files.MetricsOut); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	c.trainMetrics = nil
+	start := time.Now()
+	if err := runner.Run(files); err != nil {
+		return nil, err
+	}
+	if task.Metrics {
+		c.trainMetrics = c.readTrainMetrics(files.MetricsOut, time.Since(start))
+	}
+	c.localSteps = localSteps(task, c.trainMetrics, c.numSamples)
+	if image, ok := runner.(interface{ ImageDigest() string }); ok {
+		c.imageDigest = image.ImageDigest()
+	}
+	update, err := os.ReadFile(c.modelPath("update.pt"))
+	if err != nil {
+		return nil, err
+	}
+	return c.privatize(c.modelPath("model_init.pt"), update)
 }
 
+// readTrainMetrics returns the metrics a train task wrote, with the wall time
+// it took. Missing or invalid metrics do not fail the round.
+func (c *SimpleCollaborator) readTrainMetrics(path string, wallTime time.Duration) map[string]float64 {
+	metrics, err := readTaskMetrics(path)
+	if err != nil {
+		log.Printf("Warning: train task %v", err)
+		metrics = make(map[string]float64)
+	}
+	metrics["wall_time_seconds"] = wallTime.Seconds()
+	log.Printf("Training metrics: %v", metrics)
+	return metrics
+}
+
+// SubmitUpdate sends an update, resubmitting it until it is acknowledged or
+// the retries are exhausted. It returns errModelOutdated when the update was
+// trained on an outdated model. Plans with encrypted updates send them
+// encrypted instead of as diffs.
 func (c *SimpleCollaborator) SubmitUpdate(weights []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	_, err := c.cli.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: c.id, ModelWeights: weights})
-	return err
+	var delta, encrypted bool
+	if c.heKey != nil {
+		var err error
+		if weights, err = c.encryptUpdate(weights); err != nil {
+			return err
+		}
+		encrypted = true
+	} else {
+		weights, delta = c.diffUpdate(weights)
+	}
+	update := &pb.ModelUpdate{
+		CollaboratorId: c.id,
+		ModelWeights:   weights,
+		NumSamples:     c.numSamples,
+		ImageDigest:    c.imageDigest,
+		Privacy:        c.privacy(),
+		Round:          int32(c.modelRound), // #nosec G115 - rounds come from the aggregator's int32
+		BaseModelHash:  c.modelHash,
+		Delta:          delta,
+		Metrics:        c.trainMetrics,
+		LocalSteps:     c.localSteps,
+		Encrypted:      encrypted,
+	}
+	return c.submit("update", func(ctx context.Context) (*pb.Ack, error) {
+		return c.cli.SubmitUpdate(ctx, update)
+	})
+}
+
+// GetLatestModel returns the aggregator's current model and round. The
+// aggregator answers not modified, without the model, while the cached model
+// is current. With delta transfer, it may answer with the diff from the
+// model the collaborator has, which is applied here.
+func (c *SimpleCollaborator) GetLatestModel() (*pb.GetModelResponse, error) {
+	var known string
+	if transport.HasFeature(c.capabilities, transport.FeatureDelta) {
+		known = c.modelHash
+	}
+	resp, err := c.fetchModel(known)
+	if err != nil || resp.NotModified || resp.BaseModelHash == "" {
+		return resp, err
+	}
+	if err := c.applyModelDelta(resp); err != nil {
+		log.Printf("Warning: %v; fetching the full model", err)
+		return c.fetchModel("")
+	}
+	return resp, nil
 }
 
-func (c *SimpleCollaborator) GetLatestModel() ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	resp, err := c.cli.GetLatestModel(ctx, &pb.GetModelRequest{CollaboratorId: c.id})
+// fetchModel asks the aggregator for its current model unless the cached one
+// is current, or for the diff from the model with hash known when it is set
+func (c *SimpleCollaborator) fetchModel(known string) (*pb.GetModelResponse, error) {
+	var resp *pb.GetModelResponse
+	err := c.call("get latest model", func(ctx context.Context) error {
+		var err error
+		resp, err = c.cli.GetLatestModel(ctx, &pb.GetModelRequest{CollaboratorId: c.id, KnownModelHash: known, IfVersion: c.modelHash})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	return resp.ModelWeights, nil
+	return resp, nil
 }
 
-// RunSyncMode runs the traditional synchronous FL mode
+// RunSyncMode runs the traditional synchronous FL mode. Each round starts
+// from the model the aggregator hands out for it.
 func (c *SimpleCollaborator) RunSyncMode(task federation.TaskConfig) error {
 	log.Printf("Starting SYNC mode training for %d rounds", c.plan.Rounds)
 
-	for round := 1; round <= c.plan.Rounds; round++ {
+	for last := 0; last < c.plan.Rounds; {
+		round, err := c.waitForRound(last)
+		if errors.Is(err, errFederationStopping) {
+			log.Printf("SYNC mode training stopped after round %d", last)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		c.round.Store(int64(round))
+		if c.chaosDrop(round) {
+			last = round
+			continue
+		}
 		log.Printf("Starting round %d/%d", round, c.plan.Rounds)
 
 		// Train on current model
@@ -117,55 +404,91 @@ func (c *SimpleCollaborator) RunSyncMode(task federation.TaskConfig) error {
 		if err != nil {
 			return fmt.Errorf("training failed in round %d: %v", round, err)
 		}
+		if weights, err = c.chaosUpdate(weights); err != nil {
+			return err
+		}
 
-		// Submit update
-		if err := c.SubmitUpdate(weights); err != nil {
+		// Submit update; an outdated one is trained again on the latest model
+		if err := c.SubmitUpdate(weights); errors.Is(err, errModelOutdated) {
+			log.Printf("Warning: %v; training again on the latest model", err)
+			continue
+		} else if err != nil {
 			return fmt.Errorf("failed to submit update in round %d: %v", round, err)
 		}
 
 		log.Printf("Round %d/%d completed", round, c.plan.Rounds)
-
-		// Wait for next round (in sync mode, we wait for all collaborators)
-		if round < c.plan.Rounds {
-			log.Printf("Waiting for next round...")
-			time.Sleep(5 * time.Second)
-		}
+		last = round
 	}
 
 	log.Printf("SYNC mode training completed")
 	return nil
 }
 
+// waitForRound polls the aggregator until it is past round last and stores
+// the model it hands out for the new round. A round start event ends the
+// wait early.
+func (c *SimpleCollaborator) waitForRound(last int) (int, error) {
+	for {
+		resp, err := c.GetLatestModel()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the model for round %d: %v", last+1, err)
+		}
+		if round := int(resp.CurrentRound); round > last {
+			if err := c.useModel(resp, round); err != nil {
+				return 0, fmt.Errorf("failed to save the model for round %d: %v", round, err)
+			}
+			return round, nil
+		}
+
+		// In sync mode, we wait for all collaborators
+		log.Printf("Waiting for next round...")
+		if _, err := c.waitForEvent(c.pollInterval()); err != nil {
+			return 0, err
+		}
+	}
+}
+
 // RunAsyncMode runs the asynchronous FL mode based on Papaya paper
 func (c *SimpleCollaborator) RunAsyncMode(task federation.TaskConfig) error {
 	log.Printf("Starting ASYNC mode training (continuous)")
 
 	round := 1
 	for {
-		log.Printf("Starting async round %d", round)
+		c.round.Store(int64(round))
+		if !c.chaosDrop(round) {
+			log.Printf("Starting async round %d", round)
+
+			// Train on current model
+			weights, err := c.RunTrainTask(task)
+			if err != nil {
+				return fmt.Errorf("training failed in async round %d: %v", round, err)
+			}
+			if weights, err = c.chaosUpdate(weights); err != nil {
+				return err
+			}
 
-		// Train on current model
-		weights, err := c.RunTrainTask(task)
-		if err != nil {
-			return fmt.Errorf("training failed in async round %d: %v", round, err)
-		}
+			// Submit update immediately; an outdated one is replaced by an
+			// update on the latest model next round
+			if err := c.SubmitUpdate(weights); errors.Is(err, errModelOutdated) {
+				log.Printf("Warning: %v", err)
+			} else if err != nil {
+				return fmt.Errorf("failed to submit update in async round %d: %v", round, err)
+			}
 
-		// Submit update immediately
-		if err := c.SubmitUpdate(weights); err != nil {
-			return fmt.Errorf("failed to submit update in async round %d: %v", round, err)
+			log.Printf("Async round %d completed", round)
 		}
 
-		log.Printf("Async round %d completed", round)
-
 		// In async mode, get the latest model from aggregator after each round
 		log.Printf("Getting latest model from aggregator...")
-		latestModel, err := c.GetLatestModel()
+		latest, err := c.GetLatestModel()
 		if err != nil {
 			log.Printf("Warning: failed to get latest model: %v", err)
 		} else {
 			// Update the local model with the latest from aggregator
-			if err := os.WriteFile("models/model_init.pt", latestModel, 0600); err != nil {
+			if err := c.useModel(latest, int(latest.CurrentRound)); err != nil {
 				log.Printf("Warning: failed to save latest model: %v", err)
+			} else if latest.NotModified {
+				log.Printf("Local model is still the latest")
 			} else {
 				log.Printf("Updated local model with latest from aggregator")
 			}
@@ -173,7 +496,11 @@ func (c *SimpleCollaborator) RunAsyncMode(task federation.TaskConfig) error {
 
 		// In async mode, we can continue immediately without waiting
 		// But we add a small delay to prevent overwhelming the system
-		time.Sleep(2 * time.Second)
+		if _, err := c.waitForEvent(2 * time.Second); errors.Is(err, errFederationStopping) {
+			break
+		} else if err != nil {
+			return err
+		}
 
 		round++
 
@@ -195,14 +522,31 @@ func (c *SimpleCollaborator) Run(task federation.TaskConfig) error {
 		c.plan.Mode = federation.ModeSync
 	}
 
+	if c.pollsTasks() {
+		return c.RunDispatchMode(task)
+	}
+
 	switch c.plan.Mode {
 	case federation.ModeAsync:
 		return c.RunAsyncMode(task)
+	case federation.ModeDecentralized:
+		return c.RunDecentralizedMode(task)
 	default:
 		return c.RunSyncMode(task)
 	}
 }
 
+// pollsTasks tells whether the collaborator gets its work from the
+// aggregator as dispatched tasks: in sync plans with task dispatch, and as an
+// evaluator, which never trains
+func (c *SimpleCollaborator) pollsTasks() bool {
+	if c.plan.Mode != "" && c.plan.Mode != federation.ModeSync {
+		return false
+	}
+	role, _ := federation.RoleOf(c.plan, c.id)
+	return c.plan.Dispatch.Enabled || role == federation.CollaboratorEvaluator
+}
+
 // isValidArgument validates command line arguments to prevent injection attacks
 func isValidArgument(arg string) bool {
 	// Allow alphanumeric characters, dots, slashes, dashes, underscores, and equals
@@ -0,0 +1,28 @@
+package collaborator
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestSetModelRefusesMalformedModels(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCollaborator(&federation.FLPlan{}, "c1")
+	c.SetModelDir(dir)
+
+	for _, model := range [][]byte{nil, {1, 2, 3}, encodeWeights([]float32{1, float32(math.Inf(1))})} {
+		if err := c.setModel(model, nil, 1); err == nil {
+			t.Errorf("setModel() accepted the model %v", model)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model_init.pt")); !os.IsNotExist(err) {
+		t.Errorf("a refused model was stored: %v", err)
+	}
+	if err := c.setModel(encodeWeights([]float32{1, 2}), nil, 1); err != nil {
+		t.Errorf("setModel() error = %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package collaborator
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// DefaultAdminAddress is where a daemon serves its admin endpoint by default
+const DefaultAdminAddress = "localhost:9104"
+
+// daemonRetryInterval is how long a daemon waits before joining again after a
+// federation ended or the aggregator could not be reached
+const daemonRetryInterval = 10 * time.Second
+
+// DaemonState is what a daemon is doing
+type DaemonState string
+
+const (
+	DaemonWaiting    DaemonState = "waiting"    // waiting for the next federation
+	DaemonConnecting DaemonState = "connecting" // joining the aggregator
+	DaemonRunning    DaemonState = "running"    // running tasks assigned by the aggregator
+	DaemonStopped    DaemonState = "stopped"
+)
+
+// DaemonStatus is reported by the daemon's /status endpoint
+type DaemonStatus struct {
+	CollaboratorID       string            `json:"collaborator_id"`
+	State                DaemonState       `json:"state"`
+	Aggregator           string            `json:"aggregator,omitempty"`
+	Mode                 federation.FLMode `json:"mode,omitempty"`
+	Round                int               `json:"round"`     // current round of the running federation
+	Submitted            int               `json:"submitted"` // results acknowledged in the running federation
+	FederationsCompleted int               `json:"federations_completed"`
+	LastError            string            `json:"last_error,omitempty"`
+	StartedAt            time.Time         `json:"started_at"`
+}
+
+// Daemon keeps a collaborator running across federations. Before each
+// federation it reloads the plan, so that the next federation can use a new
+// one, joins the aggregator and runs the tasks it assigns until it is told
+// to quit.
+type Daemon struct {
+	planPath      string
+	localPath     string // collaborator's own config file, if any
+	id            string
+	adminAddress  string
+	adminToken    string // required by /abort as X-API-Key when set
+	retryInterval time.Duration
+	chaos         ChaosConfig // faults each federation's collaborator injects
+
+	mu     sync.Mutex
+	status DaemonStatus
+	collab *SimpleCollaborator // collaborator of the running federation
+	abort  context.CancelFunc  // aborts the running federation
+}
+
+// NewDaemon creates a daemon for the collaborator id of the plan at planPath.
+// localPath is the collaborator's own config file and may be empty.
+func NewDaemon(planPath, localPath, id, adminAddress string) *Daemon {
+	if adminAddress == "" {
+		adminAddress = DefaultAdminAddress
+	}
+	return &Daemon{
+		planPath:      planPath,
+		localPath:     localPath,
+		id:            id,
+		adminAddress:  adminAddress,
+		retryInterval: daemonRetryInterval,
+		status: DaemonStatus{
+			CollaboratorID: id,
+			State:          DaemonWaiting,
+			StartedAt:      time.Now(),
+		},
+	}
+}
+
+// SetChaos makes the collaborator inject the faults of config in every
+// federation
+func (d *Daemon) SetChaos(config ChaosConfig) {
+	d.chaos = config
+}
+
+// SetAdminToken makes /abort require token as X-API-Key. Without a token
+// the admin endpoint may only listen on a loopback address.
+func (d *Daemon) SetAdminToken(token string) {
+	d.adminToken = token
+}
+
+// Run participates in federations until ctx is canceled
+func (d *Daemon) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", d.adminAddress)
+	if err != nil {
+		return fmt.Errorf("failed to start admin endpoint: %v", err)
+	}
+	if addr, ok := lis.Addr().(*net.TCPAddr); ok && !addr.IP.IsLoopback() && d.adminToken == "" {
+		lis.Close()
+		return fmt.Errorf("admin endpoint %s is not a loopback address; set an admin token to serve it there", d.adminAddress)
+	}
+	srv := &http.Server{Handler: d.adminHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		log.Printf("Collaborator admin endpoint available at http://%s", d.adminAddress)
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Printf("Admin endpoint error: %v", err)
+		}
+	}()
+	defer srv.Close()
+
+	for {
+		d.runFederation(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		d.setState(DaemonWaiting)
+		if err := sleepContext(ctx, d.retryInterval); err != nil {
+			break
+		}
+	}
+
+	d.setState(DaemonStopped)
+	log.Printf("Collaborator daemon stopped")
+	return nil
+}
+
+// runFederation joins the aggregator of the current plan and runs its tasks
+// until the federation ends, fails or is aborted
+func (d *Daemon) runFederation(ctx context.Context) {
+	plan, err := federation.LoadPlan(d.planPath)
+	if err != nil {
+		d.recordError(fmt.Errorf("failed to load plan: %v", err))
+		return
+	}
+	if plan.Mode == "" {
+		plan.Mode = federation.ModeSync
+	}
+	if !plan.Dispatch.Enabled || plan.Mode != federation.ModeSync {
+		d.recordError(fmt.Errorf("daemon mode requires a sync plan with dispatch.enabled"))
+		return
+	}
+	if err := federation.ValidateTaskArgs(plan); err != nil {
+		d.recordError(err)
+		return
+	}
+
+	collab := NewCollaborator(plan, d.id)
+	if d.localPath != "" {
+		local, err := federation.LoadLocalConfig(d.localPath)
+		if err == nil {
+			err = collab.SetLocalConfig(*local)
+		}
+		if err != nil {
+			d.recordError(fmt.Errorf("invalid local config %s: %v", d.localPath, err))
+			return
+		}
+	}
+
+	if err := collab.SetChaos(d.chaos); err != nil {
+		d.recordError(err)
+		return
+	}
+
+	fedCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	collab.SetContext(fedCtx)
+	defer collab.Close()
+
+	d.mu.Lock()
+	d.status.State = DaemonConnecting
+	d.status.Aggregator = plan.Aggregator.Address
+	d.status.Mode = plan.Mode
+	d.collab = collab
+	d.abort = abort
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.collab = nil
+		d.abort = nil
+		d.mu.Unlock()
+	}()
+
+	if err := collab.Connect(); err != nil {
+		if fedCtx.Err() == nil {
+			d.recordError(fmt.Errorf("failed to join the federation: %v", err))
+		}
+		return
+	}
+	d.setState(DaemonRunning)
+
+	err = collab.Run(federation.CollaboratorTasks(plan, d.id).Train)
+	_, submitted := collab.Progress()
+	switch {
+	case ctx.Err() != nil:
+	case fedCtx.Err() != nil:
+		log.Printf("Federation aborted")
+		d.recordError(fmt.Errorf("federation aborted by admin request"))
+	case err != nil:
+		d.recordError(err)
+	case submitted > 0:
+		// A federation that only told the daemon to quit was already over
+		d.mu.Lock()
+		d.status.FederationsCompleted++
+		d.status.LastError = ""
+		d.mu.Unlock()
+		log.Printf("Federation completed after %d submitted results", submitted)
+	}
+}
+
+// Status returns the daemon's current status
+func (d *Daemon) Status() DaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.status
+	if d.collab != nil {
+		status.Round, status.Submitted = d.collab.Progress()
+	}
+	return status
+}
+
+// Abort stops the running federation, after its current task, and reports
+// whether one was running. The daemon then waits for the next federation.
+func (d *Daemon) Abort() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.abort == nil {
+		return false
+	}
+	d.abort()
+	return true
+}
+
+func (d *Daemon) setState(state DaemonState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.State = state
+}
+
+func (d *Daemon) recordError(err error) {
+	log.Printf("Warning: %v", err)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.LastError = err.Error()
+}
+
+// adminHandler serves /health, /status and /abort. /abort is refused to
+// requests carrying an Origin header, which only browsers send, so that a web
+// page cannot abort the federation through a daemon on localhost.
+func (d *Daemon) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, d.Status())
+	})
+	mux.HandleFunc("/abort", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Origin") != "" {
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+		if d.adminToken != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(d.adminToken)) != 1 {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		if !d.Abort() {
+			http.Error(w, "no federation is running", http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]bool{"aborted": true})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
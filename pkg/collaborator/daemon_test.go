@@ -0,0 +1,90 @@
+package collaborator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDaemonAdminEndpoint(t *testing.T) {
+	d := NewDaemon("plan.yaml", "", "collaborator1", "")
+	handler := d.adminHandler()
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	if rec := request(http.MethodGet, "/health"); rec.Code != http.StatusOK {
+		t.Errorf("GET /health = %d, want 200", rec.Code)
+	}
+
+	rec := request(http.MethodGet, "/status")
+	var status DaemonStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status.CollaboratorID != "collaborator1" || status.State != DaemonWaiting {
+		t.Errorf("status = %+v, want collaborator1 waiting", status)
+	}
+
+	if rec := request(http.MethodPost, "/abort"); rec.Code != http.StatusConflict {
+		t.Errorf("POST /abort without a federation = %d, want 409", rec.Code)
+	}
+
+	aborted := false
+	d.abort = func() { aborted = true }
+	if rec := request(http.MethodPost, "/abort"); rec.Code != http.StatusAccepted || !aborted {
+		t.Errorf("POST /abort = %d (aborted %v), want 202", rec.Code, aborted)
+	}
+	if rec := request(http.MethodGet, "/abort"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /abort = %d, want 405", rec.Code)
+	}
+}
+
+func TestDaemonAdminAbortAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header map[string]string
+		want   int
+	}{
+		{name: "no token", want: http.StatusAccepted},
+		{name: "cross-origin", header: map[string]string{"Origin": "https://example.com"}, want: http.StatusForbidden},
+		{name: "missing token", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: map[string]string{"X-API-Key": "guess"}, want: http.StatusUnauthorized},
+		{name: "token", token: "secret", header: map[string]string{"X-API-Key": "secret"}, want: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDaemon("plan.yaml", "", "collaborator1", "")
+			d.SetAdminToken(tt.token)
+			aborted := false
+			d.abort = func() { aborted = true }
+
+			req := httptest.NewRequest(http.MethodPost, "/abort", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			d.adminHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.want || aborted != (tt.want == http.StatusAccepted) {
+				t.Errorf("POST /abort = %d (aborted %v), want %d", rec.Code, aborted, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonRequiresTokenOffLoopback(t *testing.T) {
+	d := NewDaemon("plan.yaml", "", "collaborator1", "0.0.0.0:0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := d.Run(ctx); err == nil || !strings.Contains(err.Error(), "loopback") {
+		t.Errorf("Run() on 0.0.0.0 without a token error = %v, want a loopback error", err)
+	}
+}
@@ -0,0 +1,348 @@
+package collaborator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// DatasetInfo is what a DataLoader found in a dataset
+type DatasetInfo struct {
+	Samples int
+	Columns []string // nil for formats without columns
+}
+
+// DataLoader inspects a dataset of one format
+type DataLoader interface {
+	Inspect(path string) (DatasetInfo, error)
+}
+
+var (
+	dataLoadersMu sync.RWMutex
+	dataLoaders   = map[federation.DataFormat]DataLoader{
+		federation.DataCSV:   csvLoader{},
+		federation.DataJSONL: jsonlLoader{},
+		federation.DataNPY:   npyLoader{},
+		federation.DataFiles: filesLoader{},
+	}
+)
+
+// RegisterDataLoader makes loader available to plans with data format format
+func RegisterDataLoader(format federation.DataFormat, loader DataLoader) {
+	dataLoadersMu.Lock()
+	defer dataLoadersMu.Unlock()
+	dataLoaders[format] = loader
+}
+
+// ValidateData checks the plan's dataset against its manifest and records
+// its number of samples, which is sent with each update. Plans without a
+// data section are not checked.
+func (c *SimpleCollaborator) ValidateData() error {
+	config := c.plan.Data
+	if config.Path == "" {
+		return nil
+	}
+	expected := config.SHA256
+	for _, collab := range c.plan.Collaborators {
+		if collab.ID == c.id && collab.DataSHA256 != "" {
+			expected = collab.DataSHA256
+		}
+	}
+
+	info, err := validateDataset(config, expected)
+	if err != nil {
+		return fmt.Errorf("dataset %s: %v", config.Path, err)
+	}
+	c.numSamples = int64(info.Samples)
+	log.Printf("Dataset %s validated: %d samples", config.Path, info.Samples)
+	return nil
+}
+
+// validateDataset inspects the dataset described by config and checks its
+// schema and, when expected is set, its digest
+func validateDataset(config federation.DataConfig, expected string) (DatasetInfo, error) {
+	stat, err := os.Stat(config.Path)
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	format := config.Format
+	if format == "" {
+		format = inferDataFormat(config.Path, stat.IsDir())
+	}
+
+	dataLoadersMu.RLock()
+	loader, ok := dataLoaders[format]
+	dataLoadersMu.RUnlock()
+	if !ok {
+		return DatasetInfo{}, fmt.Errorf("unsupported data format: %q", format)
+	}
+
+	info, err := loader.Inspect(config.Path)
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	if info.Samples == 0 {
+		return DatasetInfo{}, fmt.Errorf("dataset has no samples")
+	}
+	if err := checkSchema(info.Columns, config.Schema, format); err != nil {
+		return DatasetInfo{}, err
+	}
+
+	if expected != "" {
+		digest, err := datasetDigest(config.Path)
+		if err != nil {
+			return DatasetInfo{}, fmt.Errorf("failed to hash dataset: %v", err)
+		}
+		if !strings.EqualFold(digest, strings.TrimPrefix(expected, "sha256:")) {
+			return DatasetInfo{}, fmt.Errorf("sha256 mismatch: got %s, want %s", digest, expected)
+		}
+	}
+	return info, nil
+}
+
+// inferDataFormat picks a format from the dataset path when the plan sets none
+func inferDataFormat(path string, dir bool) federation.DataFormat {
+	if dir {
+		return federation.DataFiles
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return federation.DataCSV
+	case ".jsonl", ".ndjson":
+		return federation.DataJSONL
+	case ".npy":
+		return federation.DataNPY
+	}
+	return ""
+}
+
+// checkSchema reports the columns of schema that columns lacks
+func checkSchema(columns, schema []string, format federation.DataFormat) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if columns == nil {
+		return fmt.Errorf("format %s has no columns to check against the schema", format)
+	}
+	have := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		have[column] = true
+	}
+	var missing []string
+	for _, column := range schema {
+		if !have[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// datasetDigest returns the hex SHA-256 of a dataset file. The digest of a
+// directory covers the relative path and content digest of each file in it.
+func datasetDigest(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !stat.IsDir() {
+		return fileDigest(path)
+	}
+
+	files, err := datasetFiles(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, file := range files {
+		digest, err := fileDigest(filepath.Join(path, file))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(file), digest)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 - dataset path comes from the plan
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// datasetFiles returns the regular files below dir, sorted and relative to
+// it, skipping hidden files and directories
+func datasetFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// csvLoader reads a CSV file with a header row
+type csvLoader struct{}
+
+func (csvLoader) Inspect(path string) (DatasetInfo, error) {
+	f, err := os.Open(path) // #nosec G304 - dataset path comes from the plan
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return DatasetInfo{}, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	info := DatasetInfo{Columns: header}
+	for {
+		if _, err := r.Read(); err == io.EOF {
+			break
+		} else if err != nil {
+			return DatasetInfo{}, err
+		}
+		info.Samples++
+	}
+	return info, nil
+}
+
+// jsonlLoader reads one JSON object per line. Its columns are the keys of
+// the first object.
+type jsonlLoader struct{}
+
+func (jsonlLoader) Inspect(path string) (DatasetInfo, error) {
+	f, err := os.Open(path) // #nosec G304 - dataset path comes from the plan
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	defer f.Close()
+
+	var info DatasetInfo
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(text, &record); err != nil {
+			return DatasetInfo{}, fmt.Errorf("line %d is not a JSON object: %v", line, err)
+		}
+		if info.Columns == nil {
+			info.Columns = make([]string, 0, len(record))
+			for key := range record {
+				info.Columns = append(info.Columns, key)
+			}
+			sort.Strings(info.Columns)
+		}
+		info.Samples++
+	}
+	return info, scanner.Err()
+}
+
+// npyLoader reads the header of a NumPy array file
+type npyLoader struct{}
+
+// npyShape matches the shape entry of an .npy header, e.g. 'shape': (60000, 28, 28)
+var npyShape = regexp.MustCompile(`'shape':\s*\(\s*(\d*)`)
+
+func (npyLoader) Inspect(path string) (DatasetInfo, error) {
+	f, err := os.Open(path) // #nosec G304 - dataset path comes from the plan
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	defer f.Close()
+
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(f, prefix); err != nil || string(prefix[:6]) != "\x93NUMPY" {
+		return DatasetInfo{}, fmt.Errorf("not a NumPy array file")
+	}
+	var headerLen uint32
+	if prefix[6] == 1 {
+		var n uint16
+		if err := binary.Read(f, binary.LittleEndian, &n); err != nil {
+			return DatasetInfo{}, err
+		}
+		headerLen = uint32(n)
+	} else if err := binary.Read(f, binary.LittleEndian, &headerLen); err != nil {
+		return DatasetInfo{}, err
+	}
+	if headerLen > 1<<20 {
+		return DatasetInfo{}, fmt.Errorf("NumPy header too large")
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return DatasetInfo{}, err
+	}
+
+	match := npyShape.FindSubmatch(header)
+	if match == nil || len(match[1]) == 0 {
+		return DatasetInfo{}, fmt.Errorf("NumPy array has no sample dimension")
+	}
+	samples, err := strconv.Atoi(string(match[1]))
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	return DatasetInfo{Samples: samples}, nil
+}
+
+// filesLoader counts the files of a directory, one per sample
+type filesLoader struct{}
+
+func (filesLoader) Inspect(path string) (DatasetInfo, error) {
+	if stat, err := os.Stat(path); err != nil {
+		return DatasetInfo{}, err
+	} else if !stat.IsDir() {
+		return DatasetInfo{}, fmt.Errorf("format files requires a directory")
+	}
+	files, err := datasetFiles(path)
+	if err != nil {
+		return DatasetInfo{}, err
+	}
+	return DatasetInfo{Samples: len(files)}, nil
+}
@@ -0,0 +1,107 @@
+package collaborator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// npyFile returns a version 1 .npy file with the given header dict
+func npyFile(header string) []byte {
+	data := []byte("\x93NUMPY\x01\x00")
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(header)))
+	return append(data, header...)
+}
+
+func TestValidateDataset(t *testing.T) {
+	dir := t.TempDir()
+	csvData := "feature,label\n0.1,1\n0.2,0\n0.3,1\n"
+	files := map[string]string{
+		"train.csv":           csvData,
+		"train.jsonl":         "{\"text\": \"a\", \"label\": 1}\n\n{\"text\": \"b\", \"label\": 0}\n",
+		"train.npy":           string(npyFile("{'descr': '<f4', 'fortran_order': False, 'shape': (60000, 28, 28), }")),
+		"images/0.png":        "x",
+		"images/1.png":        "y",
+		"images/.DS_Store":    "",
+		"broken.jsonl":        "{\"text\": \"a\"}\nnot json\n",
+		"empty.csv":           "feature,label\n",
+		"scalar.npy":          string(npyFile("{'descr': '<f4', 'fortran_order': False, 'shape': (), }")),
+		"images/sub/2.png":    "z",
+		"images/.cache/3.png": "w",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := sha256.Sum256([]byte(csvData))
+	csvDigest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name        string
+		config      federation.DataConfig
+		expected    string
+		wantSamples int
+		wantErr     bool
+	}{
+		{name: "csv", config: federation.DataConfig{Path: "train.csv", Schema: []string{"label", "feature"}}, wantSamples: 3},
+		{name: "csv missing column", config: federation.DataConfig{Path: "train.csv", Schema: []string{"label", "weight"}}, wantErr: true},
+		{name: "csv digest", config: federation.DataConfig{Path: "train.csv"}, expected: "sha256:" + csvDigest, wantSamples: 3},
+		{name: "csv digest mismatch", config: federation.DataConfig{Path: "train.csv"}, expected: "0123", wantErr: true},
+		{name: "empty csv", config: federation.DataConfig{Path: "empty.csv"}, wantErr: true},
+		{name: "jsonl", config: federation.DataConfig{Path: "train.jsonl", Schema: []string{"text", "label"}}, wantSamples: 2},
+		{name: "broken jsonl", config: federation.DataConfig{Path: "broken.jsonl"}, wantErr: true},
+		{name: "npy", config: federation.DataConfig{Path: "train.npy"}, wantSamples: 60000},
+		{name: "scalar npy", config: federation.DataConfig{Path: "scalar.npy"}, wantErr: true},
+		{name: "npy schema", config: federation.DataConfig{Path: "train.npy", Schema: []string{"label"}}, wantErr: true},
+		{name: "files", config: federation.DataConfig{Path: "images"}, wantSamples: 3},
+		{name: "explicit format", config: federation.DataConfig{Path: "train.csv", Format: federation.DataFiles}, wantErr: true},
+		{name: "unknown format", config: federation.DataConfig{Path: "train.csv", Format: "parquet"}, wantErr: true},
+		{name: "missing", config: federation.DataConfig{Path: "missing.csv"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Path = filepath.Join(dir, tt.config.Path)
+			info, err := validateDataset(tt.config, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if info.Samples != tt.wantSamples {
+				t.Errorf("samples = %d, want %d", info.Samples, tt.wantSamples)
+			}
+		})
+	}
+}
+
+func TestDatasetDigestOfDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.png": "a", "b.png": "b"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, err := datasetDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.png"), []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	after, err := datasetDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Errorf("digest did not change when a file changed")
+	}
+}
@@ -0,0 +1,552 @@
+package collaborator
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// defaultPeerRoundTimeout bounds how long a peer waits on others within a round
+const defaultPeerRoundTimeout = 300 * time.Second
+
+// peerRetryAfter is how long peers wait before resending an update to the
+// leader of a round
+const peerRetryAfter = time.Second
+
+// peerServer serves this collaborator's model to other peers in decentralized mode.
+// When the collaborator is the round leader it also collects peer updates.
+type peerServer struct {
+	pb.UnimplementedFederatedLearningServer
+	mu         sync.Mutex
+	model      []byte
+	round      int
+	collecting int // round whose updates are accepted, 0 when none
+	collected  int // last round whose updates were taken
+	updates    map[string][]float32
+	fetched    map[string]int // collaborator ID -> highest round fetched
+}
+
+func newPeerServer(model []byte) *peerServer {
+	return &peerServer{
+		model:   model,
+		updates: make(map[string][]float32),
+		fetched: make(map[string]int),
+	}
+}
+
+func (p *peerServer) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &pb.JoinResponse{InitialModel: p.model}, nil
+}
+
+// SubmitUpdate accepts updates of the round the peer collects as leader.
+// Updates of rounds it already aggregated are rejected as stale, so that a
+// late retry is not averaged into a later round against another model, and
+// updates of later rounds are to be resent once the peer collects them.
+func (p *peerServer) SubmitUpdate(ctx context.Context, upd *pb.ModelUpdate) (*pb.Ack, error) {
+	p.mu.Lock()
+	round := int(upd.Round)
+	switch {
+	case round == 0, round != p.collecting && round <= max(p.collected, p.round):
+		p.mu.Unlock()
+		log.Printf("Peer rejected the round %d update of %s as stale", round, upd.CollaboratorId)
+		return &pb.Ack{
+			Success: false,
+			Message: fmt.Sprintf("update was computed for round %d, the peer already aggregated round %d", round, max(p.collected, p.round)),
+			Status:  pb.AckStatus_ACK_REJECTED_STALE,
+		}, nil
+	case round != p.collecting:
+		p.mu.Unlock()
+		return &pb.Ack{
+			Success:           false,
+			Message:           fmt.Sprintf("peer is not collecting round %d yet", round),
+			Status:            pb.AckStatus_ACK_RETRY_AFTER,
+			RetryAfterSeconds: int32(peerRetryAfter / time.Second),
+		}, nil
+	}
+	weights, err := transport.DecodeWeights(upd.ModelWeights, len(p.model)/4)
+	if err != nil {
+		p.mu.Unlock()
+		log.Printf("Peer rejected the update of %s: %v", upd.CollaboratorId, err)
+		return &pb.Ack{Success: false, Message: err.Error()}, nil
+	}
+	p.updates[upd.CollaboratorId] = weights
+	updateCount := len(p.updates)
+	p.mu.Unlock()
+
+	log.Printf("Peer received update %d from %s", updateCount, upd.CollaboratorId)
+	return &pb.Ack{Success: true}, nil
+}
+
+func (p *peerServer) GetLatestModel(ctx context.Context, req *pb.GetModelRequest) (*pb.GetModelResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.round > p.fetched[req.CollaboratorId] {
+		p.fetched[req.CollaboratorId] = p.round
+	}
+
+	// Safely convert int to int32 to prevent overflow
+	currentRound := int32(math.MaxInt32)
+	if p.round < math.MaxInt32 {
+		currentRound = int32(p.round) // #nosec G115 - Safe conversion with bounds check above
+	}
+
+	return &pb.GetModelResponse{ModelWeights: p.model, CurrentRound: currentRound}, nil
+}
+
+// publish makes a model available to peers for the given round
+func (p *peerServer) publish(model []byte, round int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.model = model
+	p.round = round
+}
+
+// collect starts accepting the updates of a round, dropping any left from
+// earlier rounds
+func (p *peerServer) collect(round int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.collecting = round
+	p.updates = make(map[string][]float32)
+}
+
+// takeUpdates returns the collected updates and stops collecting, so that
+// updates of the round that arrive later are rejected
+func (p *peerServer) takeUpdates() map[string][]float32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	updates := p.updates
+	p.updates = make(map[string][]float32)
+	p.collected = max(p.collected, p.collecting)
+	p.collecting = 0
+	return updates
+}
+
+func (p *peerServer) updateCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.updates)
+}
+
+func (p *peerServer) hasFetched(collaboratorID string, round int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetched[collaboratorID] >= round
+}
+
+// LeaderForRound returns the collaborator acting as aggregator for a round
+// under round-robin rotation. Rounds are 1-based.
+func LeaderForRound(collaborators []federation.Collaborator, round int) federation.Collaborator {
+	if len(collaborators) == 0 {
+		return federation.Collaborator{}
+	}
+	return collaborators[(round-1)%len(collaborators)]
+}
+
+// GossipNeighbors returns the ring neighbors of a collaborator, up to
+// neighbors peers on each side.
+func GossipNeighbors(collaborators []federation.Collaborator, id string, neighbors int) []federation.Collaborator {
+	index := -1
+	for i, collab := range collaborators {
+		if collab.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	if neighbors <= 0 {
+		neighbors = 1
+	}
+
+	n := len(collaborators)
+	seen := map[int]bool{index: true}
+	var result []federation.Collaborator
+	for k := 1; k <= neighbors; k++ {
+		for _, j := range []int{(index + k) % n, (index - k + n) % n} {
+			if !seen[j] {
+				seen[j] = true
+				result = append(result, collaborators[j])
+			}
+		}
+	}
+	return result
+}
+
+// RunDecentralizedMode runs federated learning without a central aggregator.
+// This mode is experimental.
+func (c *SimpleCollaborator) RunDecentralizedMode(task federation.TaskConfig) error {
+	cfg := c.plan.Decentralized
+	if cfg.Strategy == "" {
+		cfg.Strategy = federation.StrategyRoundRobin
+	}
+	timeout := defaultPeerRoundTimeout
+	if cfg.RoundTimeout > 0 {
+		timeout = time.Duration(cfg.RoundTimeout) * time.Second
+	}
+
+	var self *federation.Collaborator
+	for i := range c.plan.Collaborators {
+		if c.plan.Collaborators[i].ID == c.id {
+			self = &c.plan.Collaborators[i]
+			break
+		}
+	}
+	if self == nil {
+		return fmt.Errorf("collaborator %s must be listed in the plan for decentralized mode", c.id)
+	}
+	if err := c.ValidateData(); err != nil {
+		return err
+	}
+
+	log.Printf("Starting DECENTRALIZED mode (%s) for %d rounds as %s", cfg.Strategy, c.plan.Rounds, self.Address)
+
+	initial, err := storage.ReadFile(c.plan.InitialModel)
+	if err != nil {
+		return fmt.Errorf("failed to read initial model: %v", err)
+	}
+	model, err := transport.DecodeWeights(initial, 0)
+	if err != nil {
+		return fmt.Errorf("invalid initial model %s: %v", c.plan.InitialModel, err)
+	}
+	if err := storage.WriteFile(c.modelPath("model_init.pt"), initial); err != nil {
+		return err
+	}
+
+	algorithm, err := c.newPeerAlgorithm(len(model))
+	if err != nil {
+		return err
+	}
+
+	peer := newPeerServer(initial)
+	srv, err := c.startPeerServer(peer, self.Address)
+	if err != nil {
+		return err
+	}
+	defer srv.Stop()
+
+	for round := 1; round <= c.plan.Rounds; round++ {
+		if cfg.Strategy == federation.StrategyRoundRobin && LeaderForRound(c.plan.Collaborators, round).ID == c.id {
+			// Peers that finish training first submit while the leader trains
+			peer.collect(round)
+		}
+
+		weights, err := c.RunTrainTask(task)
+		if err != nil {
+			return fmt.Errorf("training failed in round %d: %v", round, err)
+		}
+
+		switch cfg.Strategy {
+		case federation.StrategyRoundRobin:
+			model, err = c.roundRobinRound(peer, algorithm, model, weights, round, timeout)
+		case federation.StrategyGossip:
+			model, err = c.gossipRound(peer, algorithm, model, weights, round, cfg.Neighbors, timeout)
+		default:
+			return fmt.Errorf("unsupported decentralized strategy: %s", cfg.Strategy)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := storage.WriteFile(c.modelPath("model_init.pt"), encodeWeights(model)); err != nil {
+			return err
+		}
+		log.Printf("Decentralized round %d/%d completed", round, c.plan.Rounds)
+	}
+
+	// Keep serving until the peers that depend on our final model have fetched it
+	var dependents []federation.Collaborator
+	if cfg.Strategy == federation.StrategyGossip {
+		dependents = GossipNeighbors(c.plan.Collaborators, c.id, cfg.Neighbors)
+	} else if LeaderForRound(c.plan.Collaborators, c.plan.Rounds).ID == c.id {
+		dependents = c.plan.Collaborators
+	}
+	c.waitForPeerFetches(peer, dependents, c.plan.Rounds, timeout)
+
+	if c.plan.OutputModel != "" {
+		if err := storage.WriteFile(c.plan.OutputModel, encodeWeights(model)); err != nil {
+			return err
+		}
+		log.Printf("Final model saved to %s", c.plan.OutputModel)
+	}
+
+	log.Printf("DECENTRALIZED mode training completed")
+	return nil
+}
+
+// roundRobinRound runs one round where a rotating leader aggregates everyone's updates
+func (c *SimpleCollaborator) roundRobinRound(peer *peerServer, algorithm aggregator.AggregationAlgorithm, model []float32, weights []byte, round int, timeout time.Duration) ([]float32, error) {
+	leader := LeaderForRound(c.plan.Collaborators, round)
+	log.Printf("Round %d leader: %s", round, leader.ID)
+
+	if leader.ID == c.id {
+		ack, err := peer.SubmitUpdate(context.Background(), &pb.ModelUpdate{CollaboratorId: c.id, ModelWeights: weights, Round: int32(round)}) // #nosec G115 - rounds are bounded by the plan
+		if err != nil {
+			return nil, err
+		}
+		if !ack.Success {
+			return nil, fmt.Errorf("invalid update in round %d: %s", round, ack.Message)
+		}
+
+		deadline := time.Now().Add(timeout)
+		for peer.updateCount() < len(c.plan.Collaborators) && time.Now().Before(deadline) {
+			time.Sleep(500 * time.Millisecond)
+		}
+		if count := peer.updateCount(); count < len(c.plan.Collaborators) {
+			log.Printf("Round %d timed out with %d/%d updates, aggregating what was received",
+				round, count, len(c.plan.Collaborators))
+		}
+
+		newModel, err := aggregatePeerUpdates(algorithm, peer.takeUpdates(), model)
+		if err != nil {
+			return nil, fmt.Errorf("aggregation failed in round %d: %v", round, err)
+		}
+		peer.publish(encodeWeights(newModel), round)
+		return newModel, nil
+	}
+
+	client, err := c.peerClient(leader.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	if err := c.submitToLeader(client, leader, weights, round, deadline); err != nil {
+		return nil, err
+	}
+
+	data, err := c.awaitPeerModel(client, round, deadline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get round %d model from leader %s: %v", round, leader.ID, err)
+	}
+	newModel, err := transport.DecodeWeights(data, len(model))
+	if err != nil {
+		return nil, fmt.Errorf("invalid round %d model from leader %s: %v", round, leader.ID, err)
+	}
+	peer.publish(data, round)
+	return newModel, nil
+}
+
+// submitToLeader sends an update to the leader of a round, resending while
+// the leader cannot be reached or does not collect the round yet. An update
+// the leader rejects as stale is given up, since the leader aggregated the
+// round without it.
+func (c *SimpleCollaborator) submitToLeader(client pb.FederatedLearningClient, leader federation.Collaborator, weights []byte, round int, deadline time.Time) error {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), transport.CallTimeout(c.plan.GRPC))
+		ack, err := client.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: c.id, ModelWeights: weights, Round: int32(round)}) // #nosec G115 - rounds are bounded by the plan
+		cancel()
+		if err == nil {
+			switch {
+			case ack.Success:
+				return nil
+			case ack.Status == pb.AckStatus_ACK_REJECTED_STALE:
+				log.Printf("Leader %s aggregated round %d without our update: %s", leader.ID, round, ack.Message)
+				return nil
+			case ack.Status != pb.AckStatus_ACK_RETRY_AFTER:
+				return fmt.Errorf("leader %s rejected the update of round %d: %s", leader.ID, round, ack.Message)
+			}
+			err = errors.New(ack.Message)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to submit update to leader %s in round %d: %v", leader.ID, round, err)
+		}
+		time.Sleep(peerRetryAfter)
+	}
+}
+
+// gossipRound averages the local update with the updates of ring neighbors
+func (c *SimpleCollaborator) gossipRound(peer *peerServer, algorithm aggregator.AggregationAlgorithm, model []float32, weights []byte, round, neighbors int, timeout time.Duration) ([]float32, error) {
+	peer.publish(weights, round)
+
+	own, err := transport.DecodeWeights(weights, len(model))
+	if err != nil {
+		return nil, fmt.Errorf("invalid update in round %d: %v", round, err)
+	}
+	updates := map[string][]float32{c.id: own}
+	deadline := time.Now().Add(timeout)
+	for _, neighbor := range GossipNeighbors(c.plan.Collaborators, c.id, neighbors) {
+		client, err := c.peerClient(neighbor.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := c.awaitPeerModel(client, round, deadline)
+		var neighborModel []float32
+		if err == nil {
+			neighborModel, err = transport.DecodeWeights(data, len(model))
+		}
+		if err != nil {
+			log.Printf("Skipping neighbor %s in round %d: %v", neighbor.ID, round, err)
+			continue
+		}
+		updates[neighbor.ID] = neighborModel
+	}
+
+	newModel, err := aggregatePeerUpdates(algorithm, updates, model)
+	if err != nil {
+		return nil, fmt.Errorf("gossip aggregation failed in round %d: %v", round, err)
+	}
+	return newModel, nil
+}
+
+// awaitPeerModel polls a peer until it publishes a model for at least the given round
+func (c *SimpleCollaborator) awaitPeerModel(client pb.FederatedLearningClient, round int, deadline time.Time) ([]byte, error) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), transport.CallTimeout(c.plan.GRPC))
+		resp, err := client.GetLatestModel(ctx, &pb.GetModelRequest{CollaboratorId: c.id})
+		cancel()
+		if err == nil && int(resp.CurrentRound) >= round {
+			return resp.ModelWeights, nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("peer is still at round %d", resp.CurrentRound)
+			}
+			return nil, err
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (c *SimpleCollaborator) waitForPeerFetches(peer *peerServer, dependents []federation.Collaborator, round int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for _, dependent := range dependents {
+		if dependent.ID == c.id {
+			continue
+		}
+		for !peer.hasFetched(dependent.ID, round) && time.Now().Before(deadline) {
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+}
+
+func (c *SimpleCollaborator) newPeerAlgorithm(modelSize int) (aggregator.AggregationAlgorithm, error) {
+	name := c.plan.Algorithm.Name
+	if name == "" {
+		name = string(aggregator.FedAvg)
+	}
+
+	algorithm, err := aggregator.CreateAggregationAlgorithm(aggregator.AlgorithmType(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := algorithm.Initialize(aggregator.AlgorithmConfig{
+		AlgorithmName:   name,
+		ModelSize:       modelSize,
+		Hyperparameters: c.plan.Algorithm.Hyperparameters,
+		Mode:            c.plan.Mode,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize algorithm: %v", err)
+	}
+
+	return algorithm, nil
+}
+
+func (c *SimpleCollaborator) startPeerServer(peer *peerServer, address string) (*grpc.Server, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+
+	// Initialize TLS manager for secure communication
+	tlsManager, err := security.NewTLSManager(security.TLSConfig(c.plan.Security.TLS), "certs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize TLS manager: %w", err)
+	}
+
+	serverOpts, err := tlsManager.NewServerOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server options: %w", err)
+	}
+
+	// Fallback to insecure credentials if TLS is not enabled
+	if len(serverOpts) == 0 {
+		serverOpts = []grpc.ServerOption{grpc.Creds(insecure.NewCredentials())}
+	}
+	serverOpts = append(serverOpts, transport.ServerOptions(c.plan.GRPC)...)
+
+	srv := grpc.NewServer(serverOpts...)
+	pb.RegisterFederatedLearningServer(srv, peer)
+
+	go func() {
+		log.Printf("Peer server listening on %s", address)
+		if err := srv.Serve(lis); err != nil {
+			log.Printf("Peer server error: %v", err)
+		}
+	}()
+
+	return srv, nil
+}
+
+func (c *SimpleCollaborator) peerClient(address string) (pb.FederatedLearningClient, error) {
+	if client, ok := c.peers[address]; ok {
+		return client, nil
+	}
+
+	dialOpts, err := c.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(address, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.peers == nil {
+		c.peers = make(map[string]pb.FederatedLearningClient)
+	}
+	client := pb.NewFederatedLearningClient(conn)
+	c.peers[address] = client
+	return client, nil
+}
+
+func aggregatePeerUpdates(algorithm aggregator.AggregationAlgorithm, updates map[string][]float32, model []float32) ([]float32, error) {
+	clientUpdates := make([]aggregator.ClientUpdate, 0, len(updates))
+	for id, weights := range updates {
+		clientUpdates = append(clientUpdates, aggregator.ClientUpdate{
+			CollaboratorID: id,
+			Weights:        weights,
+			Timestamp:      time.Now(),
+		})
+	}
+	return algorithm.Aggregate(clientUpdates, model)
+}
+
+func decodeWeights(data []byte) []float32 {
+	floats := make([]float32, len(data)/4)
+	for i := range floats {
+		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return floats
+}
+
+func encodeWeights(weights []float32) []byte {
+	buf := make([]byte, 4*len(weights))
+	for i, v := range weights {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
@@ -0,0 +1,123 @@
+package collaborator
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+func testPeers() []federation.Collaborator {
+	return []federation.Collaborator{
+		{ID: "a", Address: "localhost:1"},
+		{ID: "b", Address: "localhost:2"},
+		{ID: "c", Address: "localhost:3"},
+		{ID: "d", Address: "localhost:4"},
+	}
+}
+
+func TestLeaderForRound(t *testing.T) {
+	peers := testPeers()
+	expected := []string{"a", "b", "c", "d", "a", "b"}
+
+	for i, want := range expected {
+		if got := LeaderForRound(peers, i+1).ID; got != want {
+			t.Errorf("LeaderForRound(%d) = %s, want %s", i+1, got, want)
+		}
+	}
+}
+
+func TestGossipNeighbors(t *testing.T) {
+	peers := testPeers()
+
+	neighbors := GossipNeighbors(peers, "a", 1)
+	if len(neighbors) != 2 || neighbors[0].ID != "b" || neighbors[1].ID != "d" {
+		t.Errorf("GossipNeighbors(a, 1) = %v, want [b d]", neighbors)
+	}
+
+	// Wide neighborhoods cover every other peer exactly once
+	neighbors = GossipNeighbors(peers, "a", 3)
+	if len(neighbors) != 3 {
+		t.Errorf("GossipNeighbors(a, 3) returned %d peers, want 3", len(neighbors))
+	}
+
+	if GossipNeighbors(peers, "missing", 1) != nil {
+		t.Error("Expected no neighbors for unknown collaborator")
+	}
+}
+
+func TestWeightsRoundTrip(t *testing.T) {
+	weights := []float32{0.5, -1.25, 3}
+	decoded := decodeWeights(encodeWeights(weights))
+
+	for i := range weights {
+		if decoded[i] != weights[i] {
+			t.Errorf("decoded[%d] = %f, want %f", i, decoded[i], weights[i])
+		}
+	}
+}
+
+func FuzzPeerSubmitUpdate(f *testing.F) {
+	f.Add(encodeWeights([]float32{1, 2}))
+	f.Add(encodeWeights([]float32{1}))
+	f.Add([]byte{1, 2, 3})
+	f.Add(encodeWeights([]float32{float32(math.NaN()), 2}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		peer := newPeerServer(encodeWeights([]float32{0, 0}))
+		peer.collect(1)
+		ack, err := peer.SubmitUpdate(context.Background(), &pb.ModelUpdate{CollaboratorId: "b", ModelWeights: data, Round: 1})
+		if err != nil {
+			t.Fatalf("SubmitUpdate() error = %v", err)
+		}
+		valid := transport.CheckWeights(data, 2) == nil
+		if ack.Success != valid {
+			t.Fatalf("SubmitUpdate() success = %v (%s), want %v", ack.Success, ack.Message, valid)
+		}
+		if held := len(peer.takeUpdates()) == 1; held != valid {
+			t.Fatalf("peer holds the update: %v, want %v", held, valid)
+		}
+	})
+}
+
+func TestPeerServerRounds(t *testing.T) {
+	ctx := context.Background()
+	peer := newPeerServer(encodeWeights([]float32{0}))
+	submit := func(collaboratorID string, round int32) *pb.Ack {
+		t.Helper()
+		ack, err := peer.SubmitUpdate(ctx, &pb.ModelUpdate{CollaboratorId: collaboratorID, ModelWeights: encodeWeights([]float32{1}), Round: round})
+		if err != nil {
+			t.Fatalf("SubmitUpdate() error = %v", err)
+		}
+		return ack
+	}
+
+	// An update that arrives before the leader collects its round is resent
+	if ack := submit("b", 1); ack.Success || ack.Status != pb.AckStatus_ACK_RETRY_AFTER {
+		t.Errorf("early update ack = %+v, want retry after", ack)
+	}
+	peer.collect(1)
+	if ack := submit("a", 1); !ack.Success {
+		t.Fatalf("update of the collected round rejected: %s", ack.Message)
+	}
+	if updates := peer.takeUpdates(); len(updates) != 1 {
+		t.Fatalf("took %d updates of round 1, want 1", len(updates))
+	}
+	peer.publish(encodeWeights([]float32{1}), 1)
+
+	// A retry that arrives after the round was aggregated is not held for
+	// the next round the peer leads
+	if ack := submit("b", 1); ack.Success || ack.Status != pb.AckStatus_ACK_REJECTED_STALE {
+		t.Errorf("late update ack = %+v, want rejected as stale", ack)
+	}
+	peer.collect(5)
+	if ack := submit("c", 5); !ack.Success {
+		t.Fatalf("update of round 5 rejected: %s", ack.Message)
+	}
+	updates := peer.takeUpdates()
+	if _, held := updates["b"]; held || len(updates) != 1 {
+		t.Errorf("round 5 updates = %v, want only c's", updates)
+	}
+}
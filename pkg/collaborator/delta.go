@@ -0,0 +1,51 @@
+package collaborator
+
+import (
+	"fmt"
+	"log"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// diffUpdate returns an update as the diff from the model it was trained on,
+// and true, when the aggregator accepts deltas and the diff is smaller than
+// the update. Otherwise it returns the update unchanged.
+func (c *SimpleCollaborator) diffUpdate(weights []byte) ([]byte, bool) {
+	if !transport.HasFeature(c.capabilities, transport.FeatureDelta) || c.modelHash == "" {
+		return weights, false
+	}
+	base, err := storage.ReadFile(c.modelPath("model_init.pt"))
+	if err != nil || transport.ModelHash(base) != c.modelHash {
+		log.Printf("Warning: the model the update was trained on changed; sending the full update")
+		return weights, false
+	}
+	delta, err := transport.EncodeDelta(base, weights)
+	if err != nil || len(delta) >= len(weights) {
+		return weights, false
+	}
+	log.Printf("Sending the update as a %d-byte delta instead of %d bytes", len(delta), len(weights))
+	return delta, true
+}
+
+// applyModelDelta replaces the diff the aggregator sent in resp with the
+// model it encodes, which must have the hash the aggregator announced
+func (c *SimpleCollaborator) applyModelDelta(resp *pb.GetModelResponse) error {
+	base, err := storage.ReadFile(c.modelPath("model_init.pt"))
+	if err != nil {
+		return fmt.Errorf("failed to read the base of the model delta: %v", err)
+	}
+	if transport.ModelHash(base) != resp.BaseModelHash {
+		return fmt.Errorf("model delta is based on model %.12s, which the collaborator no longer has", resp.BaseModelHash)
+	}
+	model, err := transport.ApplyDelta(base, resp.ModelWeights)
+	if err != nil {
+		return err
+	}
+	if transport.ModelHash(model) != resp.ModelHash {
+		return fmt.Errorf("model delta did not restore model %.12s", resp.ModelHash)
+	}
+	resp.ModelWeights, resp.BaseModelHash = model, ""
+	return nil
+}
@@ -0,0 +1,197 @@
+package collaborator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// GetTask asks the aggregator for the collaborator's next task
+func (c *SimpleCollaborator) GetTask() (*pb.Task, error) {
+	var task *pb.Task
+	err := c.call("get task", func(ctx context.Context) error {
+		var err error
+		task, err = c.cli.GetTask(ctx, &pb.GetTaskRequest{CollaboratorId: c.id})
+		return err
+	})
+	return task, err
+}
+
+// SubmitTaskResult sends the outcome of a dispatched task to the aggregator.
+// It returns errModelOutdated for train results of an outdated model.
+func (c *SimpleCollaborator) SubmitTaskResult(result *pb.TaskResult) error {
+	result.CollaboratorId = c.id
+	name := fmt.Sprintf("%s result of round %d", result.Type, result.Round)
+	return c.submit(name, func(ctx context.Context) (*pb.Ack, error) {
+		return c.cli.SubmitTaskResult(ctx, result)
+	})
+}
+
+// RunDispatchMode polls the aggregator for tasks and runs them until it is
+// told to quit. Train tasks run task with the hyperparameters sent by the
+// aggregator, evaluate tasks run the plan's evaluate task.
+func (c *SimpleCollaborator) RunDispatchMode(task federation.TaskConfig) error {
+	log.Printf("Starting DISPATCH mode, polling the aggregator for tasks")
+
+	pollInterval := time.Duration(c.plan.Dispatch.PollInterval) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = federation.DefaultPollInterval * time.Second
+	}
+
+	for {
+		assigned, err := c.GetTask()
+		if err != nil {
+			return fmt.Errorf("failed to get a task from the aggregator: %v", err)
+		}
+
+		if assigned.Round > 0 {
+			c.round.Store(int64(assigned.Round))
+		}
+
+		switch assigned.Type {
+		case pb.TaskType_TASK_TRAIN:
+			if err := c.runDispatchedTrain(task, assigned); err != nil {
+				return fmt.Errorf("train task failed in round %d: %v", assigned.Round, err)
+			}
+		case pb.TaskType_TASK_EVALUATE:
+			if err := c.runDispatchedEvaluate(assigned); err != nil {
+				return fmt.Errorf("evaluate task failed in round %d: %v", assigned.Round, err)
+			}
+		case pb.TaskType_TASK_QUIT:
+			log.Printf("Aggregator finished the federation")
+			return nil
+		default:
+			sleep := time.Duration(assigned.SleepSeconds) * time.Second
+			if sleep <= 0 {
+				sleep = pollInterval
+			}
+			// A round start ends the wait early; after a stop the
+			// aggregator answers with the remaining tasks or quit
+			if _, err := c.waitForEvent(sleep); err != nil && !errors.Is(err, errFederationStopping) {
+				return err
+			}
+		}
+	}
+}
+
+func (c *SimpleCollaborator) runDispatchedTrain(task federation.TaskConfig, assigned *pb.Task) error {
+	log.Printf("Received train task for round %d", assigned.Round)
+	if err := c.setModel(assigned.ModelWeights, assigned.ModelSignature, int(assigned.Round)); err != nil {
+		return err
+	}
+
+	if c.chaosDrop(int(assigned.Round)) {
+		return nil
+	}
+	weights, err := c.RunTrainTask(withHyperparameters(task, assigned.Hyperparameters))
+	if err != nil {
+		return err
+	}
+	if weights, err = c.chaosUpdate(weights); err != nil {
+		return err
+	}
+	err = c.SubmitTaskResult(&pb.TaskResult{
+		Type:          pb.TaskType_TASK_TRAIN,
+		Round:         assigned.Round,
+		ModelWeights:  weights,
+		NumSamples:    c.numSamples,
+		ImageDigest:   c.imageDigest,
+		Privacy:       c.privacy(),
+		BaseModelHash: c.modelHash,
+		Metrics:       c.trainMetrics,
+		LocalSteps:    c.localSteps,
+	})
+	if errors.Is(err, errModelOutdated) {
+		// The next train task hands out the latest model
+		log.Printf("Warning: %v", err)
+		return nil
+	}
+	return err
+}
+
+func (c *SimpleCollaborator) runDispatchedEvaluate(assigned *pb.Task) error {
+	log.Printf("Received evaluate task for round %d", assigned.Round)
+	if err := c.verifyModel(transport.ModelHash(assigned.ModelWeights), assigned.ModelSignature); err != nil {
+		return fmt.Errorf("refusing the model of round %d: %v", assigned.Round, err)
+	}
+	if err := storage.WriteFile(c.modelPath("model_eval.pt"), assigned.ModelWeights); err != nil {
+		return err
+	}
+
+	evaluate := federation.CollaboratorTasks(c.plan, c.id).Evaluate
+	metrics, err := c.RunEvaluateTask(withHyperparameters(evaluate, assigned.Hyperparameters))
+	if err != nil {
+		return err
+	}
+	log.Printf("Round %d evaluation: %v", assigned.Round, metrics)
+	return c.SubmitTaskResult(&pb.TaskResult{
+		Type:    pb.TaskType_TASK_EVALUATE,
+		Round:   assigned.Round,
+		Metrics: metrics,
+	})
+}
+
+// RunEvaluateTask evaluates model_eval.pt of the model directory with the
+// task's runner and returns the metrics the task wrote
+func (c *SimpleCollaborator) RunEvaluateTask(task federation.TaskConfig) (map[string]float64, error) {
+	runner, err := NewTaskRunner(task)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evaluate task: %v", err)
+	}
+
+	files := TaskFiles{ModelIn: c.modelPath("model_eval.pt"), MetricsOut: c.modelPath("metrics.json")}
+	if err := os.Remove(files.MetricsOut); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := runner.Run(files); err != nil {
+		return nil, err
+	}
+
+	metrics, err := readTaskMetrics(files.MetricsOut)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate task %v", err)
+	}
+	return metrics, nil
+}
+
+// readTaskMetrics reads the JSON object of metric name to value a task wrote
+func readTaskMetrics(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("wrote no metrics: %v", err)
+	}
+	var metrics map[string]float64
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, fmt.Errorf("wrote invalid metrics: %v", err)
+	}
+	if metrics == nil {
+		metrics = make(map[string]float64)
+	}
+	return metrics, nil
+}
+
+// withHyperparameters returns a copy of task whose args are overridden by
+// the hyperparameters sent by the aggregator
+func withHyperparameters(task federation.TaskConfig, hyperparameters map[string]string) federation.TaskConfig {
+	if len(hyperparameters) == 0 {
+		return task
+	}
+	args := make(map[string]interface{}, len(task.Args)+len(hyperparameters))
+	for key, value := range task.Args {
+		args[key] = value
+	}
+	for key, value := range hyperparameters {
+		args[key] = value
+	}
+	task.Args = args
+	return task
+}
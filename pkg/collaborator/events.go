@@ -0,0 +1,98 @@
+package collaborator
+
+import (
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+const (
+	// syncPollInterval is how often a sync collaborator asks the aggregator
+	// whether the next round started
+	syncPollInterval = 5 * time.Second
+	// eventPollInterval replaces syncPollInterval while the collaborator
+	// watches events, in case one is lost
+	eventPollInterval = 30 * time.Second
+)
+
+// errFederationStopping is returned once the aggregator announced that the
+// federation is over
+var errFederationStopping = errors.New("the aggregator is stopping the federation")
+
+// watchEvents streams the aggregator's control events when both sides support
+// them. When the stream ends the collaborator falls back to polling.
+func (c *SimpleCollaborator) watchEvents() {
+	if c.events != nil || !transport.HasFeature(c.capabilities, transport.FeatureEvents) {
+		return
+	}
+	stream, err := c.cli.WatchEvents(c.ctx, &pb.WatchRequest{CollaboratorId: c.id})
+	if err != nil {
+		log.Printf("Warning: failed to watch federation events, polling instead: %v", err)
+		return
+	}
+
+	events := make(chan *pb.FederationEvent, 16)
+	c.events = events
+	go func() {
+		defer close(events)
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && c.ctx.Err() == nil {
+					log.Printf("Warning: federation event stream ended, polling instead: %v", err)
+				}
+				return
+			}
+			select {
+			case events <- event:
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// pollInterval is how long a sync collaborator waits between asking for the
+// next round
+func (c *SimpleCollaborator) pollInterval() time.Duration {
+	if c.events != nil {
+		return eventPollInterval
+	}
+	return syncPollInterval
+}
+
+// waitForEvent waits up to d for a control event of the aggregator. It
+// returns nil when d passed or the event stream ended, and
+// errFederationStopping when the aggregator ends the federation. Config
+// changes are logged.
+func (c *SimpleCollaborator) waitForEvent(d time.Duration) (*pb.FederationEvent, error) {
+	if c.events == nil {
+		return nil, sleepContext(c.ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil, nil
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	case event, ok := <-c.events:
+		if !ok {
+			c.events = nil
+			return nil, nil
+		}
+		switch event.Type {
+		case pb.EventType_EVENT_FEDERATION_STOPPING:
+			log.Printf("Aggregator is stopping the federation: %s", event.Message)
+			return event, errFederationStopping
+		case pb.EventType_EVENT_CONFIG_CHANGED:
+			log.Printf("Aggregator changed the federation config: %s %v", event.Message, event.Hyperparameters)
+		}
+		return event, nil
+	}
+}
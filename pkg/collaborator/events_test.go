@@ -0,0 +1,38 @@
+package collaborator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestWaitForEvent(t *testing.T) {
+	events := make(chan *pb.FederationEvent, 2)
+	c := NewCollaborator(&federation.FLPlan{}, "c1")
+	c.events = events
+	if got := c.pollInterval(); got != eventPollInterval {
+		t.Errorf("pollInterval() = %v, want %v while watching events", got, eventPollInterval)
+	}
+
+	events <- &pb.FederationEvent{Type: pb.EventType_EVENT_ROUND_STARTED, Round: 2}
+	if event, err := c.waitForEvent(time.Minute); err != nil || event.GetRound() != 2 {
+		t.Errorf("waitForEvent() = %v, %v, want round 2 started", event, err)
+	}
+
+	events <- &pb.FederationEvent{Type: pb.EventType_EVENT_FEDERATION_STOPPING}
+	if _, err := c.waitForEvent(time.Minute); !errors.Is(err, errFederationStopping) {
+		t.Errorf("waitForEvent() error = %v, want errFederationStopping", err)
+	}
+
+	// Once the stream ends the collaborator polls again
+	close(events)
+	if event, err := c.waitForEvent(time.Minute); event != nil || err != nil {
+		t.Errorf("waitForEvent() = %v, %v after the stream ended, want nil", event, err)
+	}
+	if got := c.pollInterval(); got != syncPollInterval {
+		t.Errorf("pollInterval() = %v, want %v without events", got, syncPollInterval)
+	}
+}
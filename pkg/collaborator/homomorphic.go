@@ -0,0 +1,39 @@
+package collaborator
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// homomorphicKey loads the public key the plan's updates are encrypted with,
+// or returns nil when the plan does not encrypt updates
+func homomorphicKey(plan *federation.FLPlan) (*security.PaillierPublicKey, error) {
+	he := plan.Security.Homomorphic
+	if !he.Enabled {
+		return nil, nil
+	}
+	if he.PublicKeyPath == "" {
+		return nil, fmt.Errorf("security.homomorphic requires public_key_path to encrypt updates")
+	}
+	key, err := security.LoadPaillierPublicKey(he.PublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load homomorphic public key: %w", err)
+	}
+	return key, nil
+}
+
+// encryptUpdate encrypts an update weighted by the collaborator's sample
+// count, which the aggregator divides the decrypted sum by
+func (c *SimpleCollaborator) encryptUpdate(weights []byte) ([]byte, error) {
+	start := time.Now()
+	encrypted, err := security.EncryptUpdate(c.heKey, decodeWeights(weights), float64(max(c.numSamples, 1)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt the update: %v", err)
+	}
+	log.Printf("Encrypted the update into %d bytes in %v", len(encrypted), time.Since(start).Round(time.Millisecond))
+	return encrypted, nil
+}
@@ -0,0 +1,102 @@
+package collaborator
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// SetLocalConfig applies the collaborator's own settings, such as local
+// differential privacy and attestation
+func (c *SimpleCollaborator) SetLocalConfig(config federation.LocalConfig) error {
+	dp := config.Privacy
+	if dp.Enabled {
+		if dp.ClipNorm <= 0 {
+			return fmt.Errorf("local privacy requires a positive clip_norm")
+		}
+		if dp.NoiseMultiplier < 0 {
+			return fmt.Errorf("local privacy noise_multiplier must not be negative")
+		}
+	}
+
+	if attestation := config.Attestation; attestation.Type != "" {
+		if _, err := security.NewAttester(attestation.Type, attestation.Command); err != nil {
+			return err
+		}
+	}
+
+	// Seeded plans make the noise, and so the run, reproducible
+	var seed [32]byte
+	if c.plan.Seed != 0 {
+		seed = federation.DeriveSeed(c.plan.Seed, c.id)
+	} else if _, err := crand.Read(seed[:]); err != nil {
+		return fmt.Errorf("failed to seed privacy noise: %v", err)
+	}
+	c.local = config
+	c.noise = rand.New(rand.NewChaCha8(seed))
+	return nil
+}
+
+// privacy returns the local privacy parameters reported with updates, or
+// nil when local privacy is disabled
+func (c *SimpleCollaborator) privacy() *pb.LocalPrivacy {
+	if !c.local.Privacy.Enabled {
+		return nil
+	}
+	return &pb.LocalPrivacy{
+		ClipNorm:        c.local.Privacy.ClipNorm,
+		NoiseMultiplier: c.local.Privacy.NoiseMultiplier,
+	}
+}
+
+// privatize applies local privacy to an update trained from the model at
+// modelPath
+func (c *SimpleCollaborator) privatize(modelPath string, update []byte) ([]byte, error) {
+	if !c.local.Privacy.Enabled {
+		return update, nil
+	}
+	model, err := storage.ReadFile(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	return applyLocalPrivacy(model, update, c.local.Privacy, c.noise)
+}
+
+// applyLocalPrivacy clips the change from model to update to an L2 norm of
+// at most config.ClipNorm and adds Gaussian noise with a standard deviation
+// of config.NoiseMultiplier * config.ClipNorm to every weight
+func applyLocalPrivacy(model, update []byte, config federation.PrivacyConfig, noise *rand.Rand) ([]byte, error) {
+	if len(model) == 0 || len(model)%4 != 0 || len(update) != len(model) {
+		return nil, fmt.Errorf("local privacy needs an update of the model's size, got %d and %d bytes", len(update), len(model))
+	}
+	base := decodeWeights(model)
+	weights := decodeWeights(update)
+
+	var norm float64
+	for i := range weights {
+		delta := float64(weights[i] - base[i])
+		norm += delta * delta
+	}
+	norm = math.Sqrt(norm)
+
+	scale := 1.0
+	if norm > config.ClipNorm {
+		scale = config.ClipNorm / norm
+	}
+	sigma := config.NoiseMultiplier * config.ClipNorm
+	for i := range weights {
+		delta := float64(weights[i]-base[i])*scale + sigma*noise.NormFloat64()
+		weights[i] = base[i] + float32(delta)
+	}
+
+	log.Printf("Applied local privacy: update norm %.4g clipped to %.4g, noise stddev %.4g",
+		norm, math.Min(norm, config.ClipNorm), sigma)
+	return encodeWeights(weights), nil
+}
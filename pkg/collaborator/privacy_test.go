@@ -0,0 +1,97 @@
+package collaborator
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestApplyLocalPrivacy(t *testing.T) {
+	model := encodeWeights([]float32{1, 1})
+
+	tests := []struct {
+		name    string
+		update  []float32
+		config  federation.PrivacyConfig
+		want    []float32 // nil when noise makes the result random
+		wantErr bool
+	}{
+		{name: "within clip norm", update: []float32{1.3, 1.4}, config: federation.PrivacyConfig{ClipNorm: 1}, want: []float32{1.3, 1.4}},
+		{name: "clipped", update: []float32{4, 5}, config: federation.PrivacyConfig{ClipNorm: 1}, want: []float32{1.6, 1.8}},
+		{name: "noise", update: []float32{1, 1}, config: federation.PrivacyConfig{ClipNorm: 1, NoiseMultiplier: 1}},
+		{name: "size mismatch", update: []float32{1}, config: federation.PrivacyConfig{ClipNorm: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noise := rand.New(rand.NewPCG(1, 2))
+			data, err := applyLocalPrivacy(model, encodeWeights(tt.update), tt.config, noise)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyLocalPrivacy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := decodeWeights(data)
+			if tt.want == nil {
+				// Noise must move an unchanged update away from the model
+				if got[0] == 1 && got[1] == 1 {
+					t.Errorf("got %v, want noise added", got)
+				}
+				return
+			}
+			for i := range tt.want {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-5 {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSetLocalConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		privacy federation.PrivacyConfig
+		wantErr bool
+	}{
+		{name: "disabled"},
+		{name: "enabled", privacy: federation.PrivacyConfig{Enabled: true, ClipNorm: 1, NoiseMultiplier: 0.5}},
+		{name: "no clip norm", privacy: federation.PrivacyConfig{Enabled: true, NoiseMultiplier: 0.5}, wantErr: true},
+		{name: "negative noise", privacy: federation.PrivacyConfig{Enabled: true, ClipNorm: 1, NoiseMultiplier: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollaborator(&federation.FLPlan{}, "c1")
+			err := c.SetLocalConfig(federation.LocalConfig{Privacy: tt.privacy})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetLocalConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := c.privacy(); (got != nil) != (tt.privacy.Enabled && !tt.wantErr) {
+				t.Errorf("privacy() = %v, want reported only when enabled", got)
+			}
+		})
+	}
+}
+
+func TestSeededPrivacyNoise(t *testing.T) {
+	config := federation.LocalConfig{Privacy: federation.PrivacyConfig{Enabled: true, ClipNorm: 1, NoiseMultiplier: 1}}
+	noise := func(seed uint64, id string) float64 {
+		c := NewCollaborator(&federation.FLPlan{Seed: seed}, id)
+		if err := c.SetLocalConfig(config); err != nil {
+			t.Fatalf("SetLocalConfig() error = %v", err)
+		}
+		return c.noise.NormFloat64()
+	}
+
+	if noise(42, "c1") != noise(42, "c1") {
+		t.Errorf("seeded collaborators drew different noise")
+	}
+	if noise(42, "c1") == noise(42, "c2") {
+		t.Errorf("collaborators of a seeded plan drew the same noise")
+	}
+}
@@ -0,0 +1,52 @@
+package collaborator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// reportResources reports the host's CPU, memory, disk, network and GPU usage
+// to the monitoring server every monitoring.report_interval seconds, so that
+// operators can see which sites are resource-constrained. It requires
+// monitoring.enabled and monitoring.collect_resource_metrics.
+func (c *SimpleCollaborator) reportResources() {
+	config := c.plan.Monitoring
+	if !config.Enabled || !config.CollectResourceMetrics || config.MonitoringServerURL == "" || c.stopResources != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.stopResources = cancel
+
+	federationID := config.FederationID
+	if federationID == "" {
+		federationID = "default"
+	}
+	now := time.Now()
+	metrics := &monitoring.CollaboratorMetrics{
+		ID:           c.id,
+		FederationID: federationID,
+		Status:       monitoring.CollabStatusConnected,
+		JoinTime:     now,
+		LastSeen:     now,
+		HasGPU:       monitoring.HasGPU(),
+	}
+	for _, collab := range c.plan.Collaborators {
+		if collab.ID == c.id {
+			metrics.Region = collab.Region
+			metrics.Site = collab.Site
+			metrics.Tags = collab.Tags
+			metrics.Latitude = collab.Latitude
+			metrics.Longitude = collab.Longitude
+		}
+	}
+	err := monitoring.RegisterCollaborator(ctx, config.MonitoringServerURL, config.APIKey, metrics)
+	if err != nil {
+		log.Printf("Warning: failed to register with monitoring: %v", err)
+	}
+
+	interval := time.Duration(config.ReportInterval) * time.Second
+	go monitoring.ReportResources(ctx, config.MonitoringServerURL, config.APIKey, c.id, interval, monitoring.NewResourceSampler("."))
+}
@@ -0,0 +1,122 @@
+package collaborator
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryPolicy is the resolved form of a plan's retry settings
+type retryPolicy struct {
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryPolicy(config federation.RetryConfig) retryPolicy {
+	p := retryPolicy{
+		maxAttempts: config.MaxAttempts,
+		initial:     time.Duration(config.InitialBackoff * float64(time.Second)),
+		max:         time.Duration(config.MaxBackoff * float64(time.Second)),
+		multiplier:  config.Multiplier,
+		jitter:      config.Jitter,
+		sleep:       sleepContext,
+	}
+	if p.maxAttempts <= 0 {
+		p.maxAttempts = 5
+	}
+	if p.initial <= 0 {
+		p.initial = time.Second
+	}
+	if p.max <= 0 {
+		p.max = 30 * time.Second
+	}
+	if p.multiplier < 1 {
+		p.multiplier = 2
+	}
+	if p.jitter <= 0 || p.jitter > 1 {
+		p.jitter = 0.2
+	}
+	return p
+}
+
+// backoff returns the delay after the given failed attempt, starting at 1
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.initial) * math.Pow(p.multiplier, float64(attempt-1))
+	if delay > float64(p.max) {
+		delay = float64(p.max)
+	}
+	// Spread retries so that collaborators do not all reconnect at once
+	delay *= 1 + p.jitter*(2*rand.Float64()-1) // #nosec G404 - jitter needs no secure randomness
+	return time.Duration(delay)
+}
+
+// isRetryable reports whether a failed call may succeed when repeated
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// call runs an aggregator call, retrying transient failures with exponential
+// backoff. After the aggregator was unreachable the collaborator joins again
+// before retrying, since a restarted aggregator has forgotten it.
+func (c *SimpleCollaborator) call(name string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := c.attempt(fn)
+		if err == nil || !isRetryable(err) || attempt >= c.retry.maxAttempts {
+			return err
+		}
+		if status.Code(err) == codes.Unavailable && c.joined {
+			c.rejoin = true
+		}
+
+		delay := c.retry.backoff(attempt)
+		log.Printf("Warning: %s failed (attempt %d/%d), retrying in %v: %v",
+			name, attempt, c.retry.maxAttempts, delay.Round(time.Millisecond), err)
+		if err := c.retry.sleep(c.ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *SimpleCollaborator) attempt(fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(c.ctx, transport.CallTimeout(c.plan.GRPC))
+	defer cancel()
+
+	if c.rejoin {
+		if err := c.join(ctx); err != nil {
+			return err
+		}
+		c.rejoin = false
+		log.Printf("Rejoined the federation at %s", c.plan.Aggregator.Address)
+		c.watchEvents()
+	}
+	return fn(ctx)
+}
+
+// sleepContext waits for d, returning early with the context's error when it
+// is canceled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,102 @@
+package collaborator
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// joinCounter is an aggregator client that counts JoinFederation calls
+type joinCounter struct {
+	pb.FederatedLearningClient
+	joins int
+}
+
+func (j *joinCounter) JoinFederation(ctx context.Context, in *pb.JoinRequest, opts ...grpc.CallOption) (*pb.JoinResponse, error) {
+	j.joins++
+	return &pb.JoinResponse{InitialModel: []byte{0, 0, 0, 0}}, nil
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := newRetryPolicy(federation.RetryConfig{InitialBackoff: 1, MaxBackoff: 5, Multiplier: 2, Jitter: 0.1})
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second}, // capped
+	}
+	for _, tt := range tests {
+		got := policy.backoff(tt.attempt)
+		if got < tt.want*9/10 || got > tt.want*11/10 {
+			t.Errorf("backoff(%d) = %v, want %v ± 10%%", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestCallRetries(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	invalid := status.Error(codes.InvalidArgument, "bad request")
+
+	tests := []struct {
+		name         string
+		errs         []error // returned by successive attempts, then nil
+		wantAttempts int
+		wantErr      bool
+		wantJoins    int
+	}{
+		{name: "success", wantAttempts: 1},
+		{name: "transient", errs: []error{unavailable, unavailable}, wantAttempts: 3, wantJoins: 2},
+		{name: "not retryable", errs: []error{invalid}, wantAttempts: 1, wantErr: true},
+		{name: "exhausted", errs: []error{unavailable, unavailable, unavailable, unavailable}, wantAttempts: 3, wantErr: true, wantJoins: 2},
+	}
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &joinCounter{}
+			c := NewCollaborator(&federation.FLPlan{Retry: federation.RetryConfig{MaxAttempts: 3}}, "c1")
+			c.cli = client
+			c.joined = true
+			c.retry.sleep = func(context.Context, time.Duration) error { return nil }
+
+			attempts := 0
+			err := c.call("test", func(ctx context.Context) error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			// The collaborator joins again before each retry after the aggregator was unreachable
+			if client.joins != tt.wantJoins {
+				t.Errorf("joins = %d, want %d", client.joins, tt.wantJoins)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package collaborator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/platform"
+)
+
+// TaskFiles are the paths a task reads from and writes to
+type TaskFiles struct {
+	ModelIn    string
+	ModelOut   string // updated model, written by train tasks
+	MetricsOut string // JSON object of metric name to value, written by evaluate tasks and train tasks with metrics
+}
+
+// TaskRunner executes a task that reads the model at files.ModelIn and
+// writes its outputs to the other paths of files
+type TaskRunner interface {
+	Run(files TaskFiles) error
+}
+
+// TrainFunc trains in process. It receives the current model and the task's
+// args and returns the updated model in the same encoding.
+type TrainFunc func(model []byte, args map[string]interface{}) ([]byte, error)
+
+var (
+	trainFuncsMu sync.RWMutex
+	trainFuncs   = make(map[string]TrainFunc)
+)
+
+// RegisterTrainFunc makes fn available to tasks with runner go and function name
+func RegisterTrainFunc(name string, fn TrainFunc) {
+	trainFuncsMu.Lock()
+	defer trainFuncsMu.Unlock()
+	trainFuncs[name] = fn
+}
+
+// validImage matches docker image references such as repo/name:tag or name@sha256:digest
+var validImage = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/:@\-]*$`)
+
+// NewTaskRunner returns the runner selected by the task's runner field
+func NewTaskRunner(task federation.TaskConfig) (TaskRunner, error) {
+	switch task.Runner {
+	case "", federation.RunnerPython:
+		if task.Script == "" {
+			return nil, fmt.Errorf("python runner requires a script")
+		}
+		return &commandRunner{task: task, command: platform.Python(task.Python), prefix: []string{task.Script}}, nil
+	case federation.RunnerExec:
+		if task.Script == "" {
+			return nil, fmt.Errorf("exec runner requires a script")
+		}
+		return &commandRunner{task: task, command: task.Script}, nil
+	case federation.RunnerDocker:
+		if !validImage.MatchString(task.Image) {
+			return nil, fmt.Errorf("docker runner requires a valid image, got %q", task.Image)
+		}
+		return &dockerRunner{task: task}, nil
+	case federation.RunnerGo:
+		trainFuncsMu.RLock()
+		fn, ok := trainFuncs[task.Function]
+		trainFuncsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("no Go training function registered as %q", task.Function)
+		}
+		return &goRunner{task: task, fn: fn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported task runner: %s", task.Runner)
+	}
+}
+
+// taskArgs builds the command line arguments of a task. Args are passed as
+// --kebab-case flags in sorted order.
+func taskArgs(task federation.TaskConfig, files TaskFiles) ([]string, error) {
+	args := []string{"--model-in", files.ModelIn}
+	if files.ModelOut != "" {
+		args = append(args, "--model-out", files.ModelOut)
+	}
+	if files.MetricsOut != "" {
+		args = append(args, "--metrics-out", files.MetricsOut)
+	}
+
+	keys := make([]string, 0, len(task.Args))
+	for k := range task.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := task.Args[k]
+		// Validate key and value to prevent injection
+		if !isValidArgument(k) || !isValidArgument(fmt.Sprint(v)) {
+			return nil, fmt.Errorf("invalid argument detected: key=%s, value=%v", k, v)
+		}
+
+		// Convert snake_case to kebab-case for Python argparse
+		kebabKey := strings.ReplaceAll(k, "_", "-")
+		args = append(args, fmt.Sprintf("--%s", kebabKey), fmt.Sprint(v))
+	}
+	return args, nil
+}
+
+// commandRunner runs the task as a local process
+type commandRunner struct {
+	task    federation.TaskConfig
+	command string
+	prefix  []string // arguments before the model paths, such as the python script
+}
+
+func (r *commandRunner) Run(files TaskFiles) error {
+	args, err := taskArgs(r.task, files)
+	if err != nil {
+		return err
+	}
+	args = append(append([]string{}, r.prefix...), args...)
+
+	log.Printf("Running task: %s %v", r.command, args)
+	cmd := exec.Command(r.command, args...) // #nosec G204 - Arguments validated with whitelist above
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// dockerRunner runs the task in a container with the working directory
+// mounted at /workspace, so model paths are the same inside and out
+type dockerRunner struct {
+	task   federation.TaskConfig
+	digest string
+}
+
+var (
+	// validVolume matches host:container[:options] mounts
+	validVolume = regexp.MustCompile(`^[a-zA-Z0-9._/\-]+:/[a-zA-Z0-9._/\-]*(:[a-z,]+)?$`)
+	validCPUs   = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	validMemory = regexp.MustCompile(`^[0-9]+[bkmgBKMG]?$`)
+	validGPUs   = regexp.MustCompile(`^(all|[0-9]+|device=[0-9,]+)$`)
+)
+
+func (r *dockerRunner) Run(files TaskFiles) error {
+	workspace, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	args, err := r.dockerArgs(workspace, files)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Running task: docker %v", args)
+	cmd := exec.Command("docker", args...) // #nosec G204 - Image and arguments validated above
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	// The image is local once it has run, so its digest can be resolved
+	// without a registry round trip
+	digest, err := resolveImageDigest(r.task.Image)
+	if err != nil {
+		log.Printf("Warning: could not resolve digest of %s: %v", r.task.Image, err)
+	}
+	r.digest = digest
+	return nil
+}
+
+// ImageDigest returns the digest of the image of the last run, if known
+func (r *dockerRunner) ImageDigest() string {
+	return r.digest
+}
+
+func (r *dockerRunner) dockerArgs(workspace string, files TaskFiles) ([]string, error) {
+	args := []string{"run", "--rm", "-v", workspace + ":/workspace", "-w", "/workspace"}
+	// Run as the current user so that the updated model is not owned by root
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+
+	config := r.task.Docker
+	for _, volume := range config.Volumes {
+		if !validVolume.MatchString(volume) {
+			return nil, fmt.Errorf("invalid docker volume %q (expected host:container[:ro])", volume)
+		}
+		args = append(args, "-v", volume)
+	}
+	limits := []struct {
+		flag, value string
+		valid       *regexp.Regexp
+	}{
+		{"--cpus", config.CPUs, validCPUs},
+		{"--memory", config.Memory, validMemory},
+		{"--gpus", config.GPUs, validGPUs},
+	}
+	for _, limit := range limits {
+		if limit.value == "" {
+			continue
+		}
+		if !limit.valid.MatchString(limit.value) {
+			return nil, fmt.Errorf("invalid docker %s value %q", strings.TrimPrefix(limit.flag, "--"), limit.value)
+		}
+		args = append(args, limit.flag, limit.value)
+	}
+	args = append(args, r.task.Image)
+
+	if r.task.Script != "" {
+		if !isValidArgument(r.task.Script) {
+			return nil, fmt.Errorf("invalid container command: %s", r.task.Script)
+		}
+		// Python scripts need not be executable inside the image. The image
+		// brings its own interpreter, so the task's python setting is ignored.
+		if strings.HasSuffix(r.task.Script, ".py") {
+			args = append(args, "python3")
+		}
+		args = append(args, r.task.Script)
+	}
+	// The container sees the workspace through a Linux path, also on Windows hosts
+	files = TaskFiles{
+		ModelIn:    filepath.ToSlash(files.ModelIn),
+		ModelOut:   filepath.ToSlash(files.ModelOut),
+		MetricsOut: filepath.ToSlash(files.MetricsOut),
+	}
+	taskArgs, err := taskArgs(r.task, files)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, taskArgs...), nil
+}
+
+// resolveImageDigest returns the registry digest of a local image
+// (repo@sha256:...), or its image ID when it was never pushed or pulled
+func resolveImageDigest(image string) (string, error) {
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{json .RepoDigests}} {{.Id}}", image).Output() // #nosec G204 - Image validated in NewTaskRunner
+	if err != nil {
+		return "", err
+	}
+	return parseImageDigest(string(out))
+}
+
+// parseImageDigest picks the digest out of docker image inspect output
+func parseImageDigest(output string) (string, error) {
+	repoDigests, id, ok := strings.Cut(strings.TrimSpace(output), " ")
+	if !ok {
+		return "", fmt.Errorf("unexpected docker image inspect output: %q", output)
+	}
+	var digests []string
+	if err := json.Unmarshal([]byte(repoDigests), &digests); err != nil {
+		return "", fmt.Errorf("unexpected docker image inspect output: %q", output)
+	}
+	if len(digests) > 0 {
+		return digests[0], nil
+	}
+	return id, nil
+}
+
+// goRunner calls a registered training function in process. It only runs
+// train tasks.
+type goRunner struct {
+	task federation.TaskConfig
+	fn   TrainFunc
+}
+
+func (r *goRunner) Run(files TaskFiles) error {
+	if files.ModelOut == "" {
+		return fmt.Errorf("go runner only supports train tasks")
+	}
+	model, err := os.ReadFile(files.ModelIn)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Running training task: Go function %s", r.task.Function)
+	updated, err := r.fn(model, r.task.Args)
+	if err != nil {
+		return fmt.Errorf("training function %s failed: %w", r.task.Function, err)
+	}
+	return os.WriteFile(files.ModelOut, updated, 0600)
+}
@@ -0,0 +1,260 @@
+package collaborator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestNewTaskRunner(t *testing.T) {
+	RegisterTrainFunc("test-noop", func(model []byte, _ map[string]interface{}) ([]byte, error) {
+		return model, nil
+	})
+
+	tests := []struct {
+		name    string
+		task    federation.TaskConfig
+		wantErr bool
+	}{
+		{name: "python by default", task: federation.TaskConfig{Script: "src/train.py"}},
+		{name: "python without script", task: federation.TaskConfig{Runner: federation.RunnerPython}, wantErr: true},
+		{name: "exec", task: federation.TaskConfig{Runner: federation.RunnerExec, Script: "./train"}},
+		{name: "docker", task: federation.TaskConfig{Runner: federation.RunnerDocker, Image: "ghcr.io/org/train:1.0"}},
+		{name: "docker without image", task: federation.TaskConfig{Runner: federation.RunnerDocker}, wantErr: true},
+		{name: "docker with invalid image", task: federation.TaskConfig{Runner: federation.RunnerDocker, Image: "img; rm -rf /"}, wantErr: true},
+		{name: "go", task: federation.TaskConfig{Runner: federation.RunnerGo, Function: "test-noop"}},
+		{name: "go unregistered", task: federation.TaskConfig{Runner: federation.RunnerGo, Function: "missing"}, wantErr: true},
+		{name: "unknown", task: federation.TaskConfig{Runner: "java"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTaskRunner(tt.task)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTaskRunner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDockerArgs(t *testing.T) {
+	task := federation.TaskConfig{
+		Runner: federation.RunnerDocker,
+		Image:  "pytorch/pytorch:2.3.0",
+		Script: "src/train.py",
+		Args:   map[string]interface{}{"epochs": 3, "batch_size": 32},
+		Docker: federation.DockerConfig{
+			Volumes: []string{"/data/mnist:/data:ro"},
+			CPUs:    "2",
+			Memory:  "4g",
+			GPUs:    "all",
+		},
+	}
+
+	args, err := (&dockerRunner{task: task}).dockerArgs("/work", TaskFiles{ModelIn: "models/model_init.pt", ModelOut: "models/update.pt"})
+	if err != nil {
+		t.Fatalf("dockerArgs() error = %v", err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm -v /work:/workspace -w /workspace",
+		"-v /data/mnist:/data:ro --cpus 2 --memory 4g --gpus all pytorch/pytorch:2.3.0",
+		"pytorch/pytorch:2.3.0 python3 src/train.py --model-in models/model_init.pt --model-out models/update.pt --batch-size 32 --epochs 3",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dockerArgs() = %q, missing %q", got, want)
+		}
+	}
+
+	invalid := []federation.DockerConfig{
+		{Volumes: []string{"--privileged"}},
+		{Volumes: []string{"/data:relative"}},
+		{CPUs: "two"},
+		{Memory: "4g --privileged"},
+		{GPUs: "device=0;1"},
+	}
+	for _, config := range invalid {
+		task.Docker = config
+		if _, err := (&dockerRunner{task: task}).dockerArgs("/work", TaskFiles{ModelIn: "in", ModelOut: "out"}); err == nil {
+			t.Errorf("dockerArgs() with %+v succeeded, want error", config)
+		}
+	}
+}
+
+func TestParseImageDigest(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{output: `["pytorch/pytorch@sha256:abc"] sha256:123` + "\n", want: "pytorch/pytorch@sha256:abc"},
+		{output: `[] sha256:123`, want: "sha256:123"},
+		{output: `garbage`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseImageDigest(tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseImageDigest(%q) = %q, %v; want %q", tt.output, got, err, tt.want)
+		}
+	}
+}
+
+func TestExecAndGoRunners(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec runner test uses a shell script")
+	}
+	dir := t.TempDir()
+	modelIn := filepath.Join(dir, "in.pt")
+	modelOut := filepath.Join(dir, "out.pt")
+	if err := os.WriteFile(modelIn, []byte{1, 2, 3, 4}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The script copies the input model to the output path given by the flags
+	script := filepath.Join(dir, "train.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncp \"$2\" \"$4\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	RegisterTrainFunc("test-double", func(model []byte, args map[string]interface{}) ([]byte, error) {
+		if args["epochs"] != 2 {
+			return nil, fmt.Errorf("unexpected args %v", args)
+		}
+		return append(model, model...), nil
+	})
+
+	tests := []struct {
+		name string
+		task federation.TaskConfig
+		want []byte
+	}{
+		{name: "exec", task: federation.TaskConfig{Runner: federation.RunnerExec, Script: script}, want: []byte{1, 2, 3, 4}},
+		{name: "go", task: federation.TaskConfig{Runner: federation.RunnerGo, Function: "test-double",
+			Args: map[string]interface{}{"epochs": 2}}, want: []byte{1, 2, 3, 4, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(modelOut)
+			runner, err := NewTaskRunner(tt.task)
+			if err != nil {
+				t.Fatalf("NewTaskRunner() error = %v", err)
+			}
+			if err := runner.Run(TaskFiles{ModelIn: modelIn, ModelOut: modelOut}); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			got, err := os.ReadFile(modelOut)
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("model out = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPythonInterpreter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("interpreter test uses a shell script")
+	}
+	dir := t.TempDir()
+	modelIn := filepath.Join(dir, "in.pt")
+	modelOut := filepath.Join(dir, "out.pt")
+	if err := os.WriteFile(modelIn, []byte{1, 2, 3, 4}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interpreter gets the script first, then the flags
+	python := filepath.Join(dir, "python.sh")
+	if err := os.WriteFile(python, []byte("#!/bin/sh\n[ \"$1\" = src/train.py ] && cp \"$3\" \"$5\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("FLGO_PYTHON", filepath.Join(dir, "missing"))
+	runner, err := NewTaskRunner(federation.TaskConfig{Script: "src/train.py"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runner.(*commandRunner).command; got != filepath.Join(dir, "missing") {
+		t.Errorf("Interpreter = %s, want FLGO_PYTHON", got)
+	}
+
+	// The task's interpreter takes precedence over FLGO_PYTHON
+	runner, err = NewTaskRunner(federation.TaskConfig{Script: "src/train.py", Python: python})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Run(TaskFiles{ModelIn: modelIn, ModelOut: modelOut}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, err := os.ReadFile(modelOut); err != nil || !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Errorf("model out = %v, %v", got, err)
+	}
+}
+
+func TestEvaluateTaskArgs(t *testing.T) {
+	task := federation.TaskConfig{Script: "src/evaluate.py", Args: map[string]interface{}{"batch_size": 32}}
+	task = withHyperparameters(task, map[string]string{"batch_size": "64"})
+
+	args, err := taskArgs(task, TaskFiles{ModelIn: "models/model_eval.pt", MetricsOut: "models/metrics.json"})
+	if err != nil {
+		t.Fatalf("taskArgs() error = %v", err)
+	}
+	want := "--model-in models/model_eval.pt --metrics-out models/metrics.json --batch-size 64"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("taskArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestTrainTaskMetrics(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("train task test uses a shell script")
+	}
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.MkdirAll("models", 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("models/model_init.pt", []byte{1, 2, 3, 4}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// --model-in in --model-out out --metrics-out metrics
+	script := filepath.Join(dir, "train.sh")
+	body := "#!/bin/sh\ncp \"$2\" \"$4\"\necho '{\"loss\": 0.25, \"accuracy\": 0.9, \"epochs\": 2}' > \"$6\"\n"
+	if err := os.WriteFile(script, []byte(body), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCollaborator(&federation.FLPlan{}, "c1")
+	if _, err := c.RunTrainTask(federation.TaskConfig{Runner: federation.RunnerExec, Script: script, Metrics: true}); err != nil {
+		t.Fatalf("RunTrainTask() error = %v", err)
+	}
+	if c.trainMetrics["loss"] != 0.25 || c.trainMetrics["accuracy"] != 0.9 || c.trainMetrics["epochs"] != 2 {
+		t.Errorf("training metrics = %v", c.trainMetrics)
+	}
+	if _, ok := c.trainMetrics["wall_time_seconds"]; !ok {
+		t.Error("training metrics lack the wall time")
+	}
+
+	// Tasks without metrics get no --metrics-out and report nothing
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncp \"$2\" \"$4\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RunTrainTask(federation.TaskConfig{Runner: federation.RunnerExec, Script: script}); err != nil {
+		t.Fatalf("RunTrainTask() error = %v", err)
+	}
+	if c.trainMetrics != nil {
+		t.Errorf("training metrics without metrics = %v", c.trainMetrics)
+	}
+}
@@ -0,0 +1,40 @@
+package collaborator
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// localStepsMetric is the training metric a train task reports its local
+// optimizer steps in
+const localStepsMetric = "local_steps"
+
+// localSteps returns the optimizer steps a train task took: the local_steps
+// metric when the task reported it, otherwise epochs * ceil(samples /
+// batch_size) from the task's args. It returns 0 when neither is known.
+func localSteps(task federation.TaskConfig, metrics map[string]float64, numSamples int64) int64 {
+	if steps, ok := metrics[localStepsMetric]; ok && steps > 0 {
+		return int64(math.Round(steps))
+	}
+	epochs, ok := argNumber(task.Args["epochs"])
+	if !ok || epochs <= 0 || numSamples <= 0 {
+		return 0
+	}
+	batchSize, ok := argNumber(task.Args["batch_size"])
+	if !ok || batchSize <= 0 {
+		return 0
+	}
+	return int64(epochs * math.Ceil(float64(numSamples)/batchSize))
+}
+
+// argNumber returns a numeric task arg. Args overridden by the aggregator
+// are strings.
+func argNumber(value interface{}) (float64, bool) {
+	if v, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return federation.Number(value)
+}
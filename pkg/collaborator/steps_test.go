@@ -0,0 +1,33 @@
+package collaborator
+
+import (
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestLocalSteps(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		metrics    map[string]float64
+		numSamples int64
+		expected   int64
+	}{
+		{"reported by the task", map[string]interface{}{"epochs": 3, "batch_size": 32}, map[string]float64{"local_steps": 42}, 1000, 42},
+		{"from epochs and batch size", map[string]interface{}{"epochs": 3, "batch_size": 32}, nil, 1000, 96},
+		{"overridden args", map[string]interface{}{"epochs": "2", "batch_size": 50.0}, nil, 100, 4},
+		{"no batch size", map[string]interface{}{"epochs": 3}, nil, 1000, 0},
+		{"no samples", map[string]interface{}{"epochs": 3, "batch_size": 32}, nil, 0, 0},
+		{"no args", nil, nil, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := federation.TaskConfig{Args: tt.args}
+			if got := localSteps(task, tt.metrics, tt.numSamples); got != tt.expected {
+				t.Errorf("localSteps() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package deploy
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+const (
+	// composeWorkspace is where services mount the workspace
+	composeWorkspace = "/app/workspace"
+	// monitorPort is the API and dashboard port of the monitoring server
+	monitorPort = "8080"
+)
+
+// ComposeOptions configures the generated Compose file
+type ComposeOptions struct {
+	Name      string // Compose project name (default: the federation ID)
+	Image     string // Image with fx and fl-monitor (default: fl-go:latest)
+	Build     string // Build context of the image, relative to the Compose file; empty pulls Image
+	PlanPath  string // Plan path relative to the workspace (default: plan.yaml)
+	Workspace string // Workspace directory relative to the Compose file (default: .)
+	User      string // uid:gid the services run as, to write to the workspace
+	NoMonitor bool   // Leave out the monitoring server
+}
+
+// CollaboratorModelDir is the directory, relative to the workspace, that holds
+// a collaborator's local model files. Collaborators share the workspace, so
+// each gets its own models directory.
+func CollaboratorModelDir(id string) string {
+	return path.Join(".collaborators", objectName(id), "models")
+}
+
+// Compose returns a Compose file that runs the aggregator, the collaborators
+// and the monitoring server of plan on one machine. Services share the
+// workspace, so round models and the final model end up in its save
+// directory.
+func Compose(plan *federation.FLPlan, opts ComposeOptions) ([]byte, error) {
+	if err := checkDeployable(plan); err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		opts.Name = plan.Monitoring.FederationID
+	}
+	if opts.Name == "" {
+		opts.Name = "fl-go"
+	}
+	if opts.Image == "" {
+		opts.Image = defaultImage
+	}
+	if opts.PlanPath == "" {
+		opts.PlanPath = "plan.yaml"
+	}
+	if opts.Workspace == "" {
+		opts.Workspace = "."
+	}
+	if path.IsAbs(opts.PlanPath) {
+		return nil, fmt.Errorf("plan path must be relative to the workspace, got %s", opts.PlanPath)
+	}
+
+	grpcPort := port(plan.Aggregator.Address, defaultGRPCPort)
+	workspace := bindSource(opts.Workspace) + ":" + composeWorkspace
+	env := map[string]string{"PYTHONUNBUFFERED": "1"}
+	if !opts.NoMonitor {
+		env[federation.EnvName("monitoring.enabled")] = "true"
+		env[federation.EnvName("monitoring.monitoring_server_url")] = "http://monitor:" + monitorPort
+	}
+	service := func(args ...string) composeService {
+		s := composeService{
+			Image:       opts.Image,
+			Entrypoint:  []string{"/app/fx"},
+			Command:     args,
+			User:        opts.User,
+			WorkingDir:  composeWorkspace,
+			Volumes:     []string{workspace},
+			Environment: map[string]string{},
+		}
+		if opts.Build != "" {
+			s.Build = &composeBuild{Context: opts.Build, Dockerfile: "deploy/docker/Dockerfile"}
+		}
+		for name, value := range env {
+			s.Environment[name] = value
+		}
+		return s
+	}
+
+	services := make(map[string]composeService)
+
+	aggregator := service("aggregator", "start", "--plan", opts.PlanPath)
+	aggregator.Environment[federation.EnvName("aggregator.address")] = "0.0.0.0:" + grpcPort
+	aggregator.Ports = []portMapping{portMapping(grpcPort + ":" + grpcPort)}
+	if !opts.NoMonitor {
+		aggregator.DependsOn = []string{"monitor"}
+	}
+	services["aggregator"] = aggregator
+
+	for _, collab := range plan.Collaborators {
+		collaborator := service("collaborator", "start", collab.ID, "--plan", opts.PlanPath)
+		collaborator.Environment[federation.EnvName("aggregator.address")] = "aggregator:" + grpcPort
+		collaborator.Volumes = append(collaborator.Volumes,
+			bindSource(path.Join(opts.Workspace, CollaboratorModelDir(collab.ID)))+":"+path.Join(composeWorkspace, "models"))
+		collaborator.DependsOn = []string{"aggregator"}
+		services["collaborator-"+objectName(collab.ID)] = collaborator
+	}
+
+	if !opts.NoMonitor {
+		monitor := composeService{
+			Image:      opts.Image,
+			Entrypoint: []string{"/app/fl-monitor"},
+			Command:    []string{"--port", monitorPort},
+			Ports:      []portMapping{portMapping(monitorPort + ":" + monitorPort)},
+		}
+		if opts.Build != "" {
+			monitor.Build = &composeBuild{Context: opts.Build, Dockerfile: "deploy/docker/Dockerfile"}
+		}
+		services["monitor"] = monitor
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(composeFile{Name: objectName(opts.Name), Services: services}); err != nil {
+		return nil, fmt.Errorf("failed to encode Compose file: %v", err)
+	}
+	encoder.Close()
+	return buf.Bytes(), nil
+}
+
+// bindSource marks a relative path as a bind mount rather than a named volume.
+// Windows paths are written with forward slashes, which Compose accepts there.
+func bindSource(p string) string {
+	p = filepath.ToSlash(p)
+	if path.IsAbs(p) || filepath.IsAbs(p) || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || p == "." || p == ".." {
+		return p
+	}
+	return "./" + p
+}
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string            `yaml:"image"`
+	Build       *composeBuild     `yaml:"build,omitempty"`
+	Entrypoint  []string          `yaml:"entrypoint,omitempty"`
+	Command     []string          `yaml:"command,omitempty"`
+	User        string            `yaml:"user,omitempty"`
+	WorkingDir  string            `yaml:"working_dir,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Ports       []portMapping     `yaml:"ports,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	DependsOn   []string          `yaml:"depends_on,omitempty"`
+}
+
+// portMapping is a host:container port pair. It is always quoted, as YAML 1.1
+// parsers read some unquoted pairs as base 60 numbers.
+type portMapping string
+
+func (p portMapping) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: string(p)}, nil
+}
+
+type composeBuild struct {
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile"`
+}
@@ -0,0 +1,80 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestCompose(t *testing.T) {
+	data, err := Compose(testPlan(), ComposeOptions{Workspace: "workspace", PlanPath: "plans/plan.yaml", User: "1000:1000"})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"50052:50052"`) {
+		t.Errorf("port mapping is not quoted:\n%s", data)
+	}
+
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		t.Fatalf("invalid Compose file: %v", err)
+	}
+	if compose.Name != "fed-1" {
+		t.Errorf("project name = %s, want fed-1", compose.Name)
+	}
+	if len(compose.Services) != 4 {
+		t.Fatalf("generated %d services, want aggregator, 2 collaborators and monitor", len(compose.Services))
+	}
+
+	aggregator := compose.Services["aggregator"]
+	if got := aggregator.Environment["FLGO_AGGREGATOR_ADDRESS"]; got != "0.0.0.0:50052" {
+		t.Errorf("aggregator listens on %s", got)
+	}
+	if got := aggregator.Environment["FLGO_MONITORING_MONITORING_SERVER_URL"]; got != "http://monitor:8080" {
+		t.Errorf("aggregator reports to %s", got)
+	}
+	if got := strings.Join(aggregator.Command, " "); got != "aggregator start --plan plans/plan.yaml" {
+		t.Errorf("aggregator command = %s", got)
+	}
+
+	collaborator := compose.Services["collaborator-site-a"]
+	if got := collaborator.Command[2]; got != "site_A" {
+		t.Errorf("collaborator ID = %s", got)
+	}
+	if got := collaborator.Environment["FLGO_AGGREGATOR_ADDRESS"]; got != "aggregator:50052" {
+		t.Errorf("collaborator dials %s", got)
+	}
+	wantVolumes := []string{"./workspace:/app/workspace", "./workspace/.collaborators/site-a/models:/app/workspace/models"}
+	if strings.Join(collaborator.Volumes, ",") != strings.Join(wantVolumes, ",") {
+		t.Errorf("collaborator volumes = %v, want %v", collaborator.Volumes, wantVolumes)
+	}
+	if collaborator.User != "1000:1000" {
+		t.Errorf("collaborator user = %s", collaborator.User)
+	}
+}
+
+func TestComposeWithoutMonitor(t *testing.T) {
+	plan := testPlan()
+	plan.Mode = federation.ModeAsync
+	data, err := Compose(plan, ComposeOptions{NoMonitor: true, Build: ".."})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		t.Fatalf("invalid Compose file: %v", err)
+	}
+	if _, ok := compose.Services["monitor"]; ok {
+		t.Error("monitor service generated with NoMonitor")
+	}
+	aggregator := compose.Services["aggregator"]
+	if _, ok := aggregator.Environment["FLGO_MONITORING_ENABLED"]; ok {
+		t.Error("monitoring enabled without a monitoring server")
+	}
+	if aggregator.Build == nil || aggregator.Build.Context != ".." {
+		t.Errorf("build = %+v, want context ..", aggregator.Build)
+	}
+}
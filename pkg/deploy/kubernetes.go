@@ -0,0 +1,549 @@
+// Package deploy generates deployment configurations that run a federation
+// described by a plan on container platforms
+package deploy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// Collaborator workload kinds
+const (
+	CollaboratorJob         = "job"         // one Job per collaborator, run to completion
+	CollaboratorStatefulSet = "statefulset" // one StatefulSet per collaborator, with a persistent data volume
+)
+
+const (
+	defaultImage       = "fl-go:latest"
+	defaultStorageSize = "1Gi"
+	defaultGRPCPort    = "50051"
+	// workDir is the working directory of the fl-go image
+	workDir = "/app"
+	// planMountDir holds the plan from the ConfigMap, relative to workDir
+	planMountDir = "config"
+	// imageGroup is the group of the fl-go image's user, which needs write
+	// access to persistent volumes
+	imageGroup = 1001
+)
+
+// KubernetesOptions configures the generated manifests
+type KubernetesOptions struct {
+	Name             string // Prefix of every object name (default: the federation ID)
+	Namespace        string // Namespace of every object; empty leaves it to kubectl
+	Image            string // Image with fx and the workspace (default: fl-go:latest)
+	CollaboratorKind string // job (default) or statefulset
+	StorageSize      string // Size of each persistent volume claim (default: 1Gi)
+	PlanFile         string // File name of the plan in the ConfigMap (default: plan.yaml)
+}
+
+// Kubernetes returns the manifests, as one multi-document YAML file, that run
+// the aggregator and collaborators of plan. planData is the plan file as
+// written, which the pods read from a ConfigMap; the settings that differ
+// inside the cluster, such as addresses, are overridden with FLGO_ environment
+// variables.
+func Kubernetes(plan *federation.FLPlan, planData []byte, opts KubernetesOptions) ([]byte, error) {
+	if err := checkDeployable(plan); err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		opts.Name = plan.Monitoring.FederationID
+	}
+	if opts.Name == "" {
+		opts.Name = "fl-go"
+	}
+	opts.Name = objectName(opts.Name)
+	if opts.Image == "" {
+		opts.Image = defaultImage
+	}
+	if opts.StorageSize == "" {
+		opts.StorageSize = defaultStorageSize
+	}
+	if opts.PlanFile == "" {
+		opts.PlanFile = "plan.yaml"
+	}
+	switch opts.CollaboratorKind {
+	case "":
+		opts.CollaboratorKind = CollaboratorJob
+	case CollaboratorJob, CollaboratorStatefulSet:
+	default:
+		return nil, fmt.Errorf("unknown collaborator kind %q (expected %s or %s)", opts.CollaboratorKind, CollaboratorJob, CollaboratorStatefulSet)
+	}
+
+	g := &k8sGenerator{plan: plan, opts: opts, grpcPort: port(plan.Aggregator.Address, defaultGRPCPort)}
+	if plan.Monitoring.MetricsAddress != "" {
+		g.metricsPort = port(plan.Monitoring.MetricsAddress, "")
+	}
+
+	objects := []k8sObject{g.planConfigMap(planData)}
+	secret, err := g.tlsSecret()
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil {
+		objects = append(objects, *secret)
+	}
+	objects = append(objects, g.aggregatorVolumeClaim(), g.aggregatorWorkload(), g.aggregatorService())
+	if opts.CollaboratorKind == CollaboratorStatefulSet {
+		objects = append(objects, g.collaboratorService())
+	}
+	for _, collab := range plan.Collaborators {
+		if opts.CollaboratorKind == CollaboratorStatefulSet {
+			objects = append(objects, g.collaboratorStatefulSet(collab.ID))
+		} else {
+			objects = append(objects, g.collaboratorJob(collab.ID))
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, object := range objects {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(object); err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s: %v", object.Kind, object.Metadata.Name, err)
+		}
+		encoder.Close()
+	}
+	return buf.Bytes(), nil
+}
+
+// checkDeployable rejects plans whose topology the generators do not cover
+func checkDeployable(plan *federation.FLPlan) error {
+	if plan.Mode == federation.ModeDecentralized {
+		return fmt.Errorf("decentralized plans have no aggregator and are not supported")
+	}
+	if plan.Role == federation.RoleEdgeAggregator {
+		return fmt.Errorf("edge aggregator plans are not supported; generate the root federation's plan")
+	}
+	if len(plan.Collaborators) == 0 {
+		return fmt.Errorf("plan has no collaborators")
+	}
+	return nil
+}
+
+type k8sGenerator struct {
+	plan        *federation.FLPlan
+	opts        KubernetesOptions
+	grpcPort    string
+	metricsPort string // empty unless the plan sets monitoring.metrics_address
+}
+
+func (g *k8sGenerator) meta(name, component string) objectMeta {
+	return objectMeta{Name: name, Namespace: g.opts.Namespace, Labels: g.labels(component)}
+}
+
+func (g *k8sGenerator) labels(component string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      "fl-go",
+		"app.kubernetes.io/instance":  g.opts.Name,
+		"app.kubernetes.io/component": component,
+	}
+}
+
+func (g *k8sGenerator) aggregatorName() string {
+	return g.opts.Name + "-aggregator"
+}
+
+func (g *k8sGenerator) collaboratorName(id string) string {
+	return objectName(g.opts.Name + "-collaborator-" + id)
+}
+
+func (g *k8sGenerator) planConfigMap(planData []byte) k8sObject {
+	return k8sObject{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   g.meta(g.opts.Name+"-plan", "plan"),
+		Data:       map[string]string{g.opts.PlanFile: string(planData)},
+	}
+}
+
+// tlsSecret holds the plan's certificate files, mounted where the plan
+// expects them
+func (g *k8sGenerator) tlsSecret() (*k8sObject, error) {
+	files := tlsFiles(g.plan)
+	if len(files) == 0 {
+		return nil, nil
+	}
+	data := make(map[string]string, len(files))
+	for key, file := range files {
+		content, err := os.ReadFile(file) // #nosec G304 - certificate paths come from the plan
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate for the TLS secret: %v", err)
+		}
+		data[key] = base64.StdEncoding.EncodeToString(content)
+	}
+	return &k8sObject{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   g.meta(g.opts.Name+"-tls", "tls"),
+		Type:       "Opaque",
+		Data:       data,
+	}, nil
+}
+
+// tlsFiles maps secret keys to the certificate files of the plan. Plans that
+// generate their certificates have none.
+func tlsFiles(plan *federation.FLPlan) map[string]string {
+	tls := plan.Security.TLS
+	if !tls.Enabled || tls.AutoGenerateCert {
+		return nil
+	}
+	files := make(map[string]string)
+	for key, file := range map[string]string{"tls.crt": tls.CertPath, "tls.key": tls.KeyPath, "ca.crt": tls.CAPath} {
+		if file != "" {
+			files[key] = file
+		}
+	}
+	return files
+}
+
+func (g *k8sGenerator) aggregatorVolumeClaim() k8sObject {
+	return k8sObject{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Metadata:   g.meta(g.aggregatorName()+"-save", "aggregator"),
+		Spec:       g.volumeClaimSpec(),
+	}
+}
+
+func (g *k8sGenerator) volumeClaimSpec() *volumeClaimSpec {
+	return &volumeClaimSpec{
+		AccessModes: []string{"ReadWriteOnce"},
+		Resources:   resourceRequirements{Requests: map[string]string{"storage": g.opts.StorageSize}},
+	}
+}
+
+// aggregatorWorkload runs the aggregator. Async aggregators run until they
+// are stopped and are Deployments; sync aggregators exit after the last round
+// and are Jobs, so that they are not restarted into a new run.
+func (g *k8sGenerator) aggregatorWorkload() k8sObject {
+	env := []envVar{{Name: federation.EnvName("aggregator.address"), Value: "0.0.0.0:" + g.grpcPort}}
+	ports := []containerPort{{Name: "grpc", ContainerPort: atoi(g.grpcPort)}}
+	if g.metricsPort != "" {
+		env = append(env, envVar{Name: federation.EnvName("monitoring.metrics_address"), Value: "0.0.0.0:" + g.metricsPort})
+		ports = append(ports, containerPort{Name: "metrics", ContainerPort: atoi(g.metricsPort)})
+	}
+
+	c := g.container("aggregator", []string{"aggregator", "start", "--plan", g.planPath()}, env)
+	c.Ports = ports
+	c.VolumeMounts = append(c.VolumeMounts, volumeMount{Name: "save", MountPath: workDir + "/save"})
+
+	sync := g.plan.Mode != federation.ModeAsync
+	restartPolicy := "Always"
+	if sync {
+		restartPolicy = "OnFailure"
+	}
+	pod := g.podSpec(restartPolicy, c)
+	// The volume hides the image's save directory, which holds the initial
+	// model of plans created with fx plan init
+	pod.InitContainers = []container{{
+		Name:         "copy-save",
+		Image:        g.opts.Image,
+		Command:      []string{"sh", "-c", "cp -rn " + workDir + "/save/. /volume/"},
+		VolumeMounts: []volumeMount{{Name: "save", MountPath: "/volume"}},
+	}}
+	pod.Volumes = append(pod.Volumes, volume{
+		Name:                  "save",
+		PersistentVolumeClaim: &claimVolumeSource{ClaimName: g.aggregatorName() + "-save"},
+	})
+
+	selector := g.labels("aggregator")
+	if sync {
+		backoffLimit := 3
+		return k8sObject{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+			Metadata:   g.meta(g.aggregatorName(), "aggregator"),
+			Spec: &workloadSpec{
+				BackoffLimit: &backoffLimit,
+				Template:     podTemplate{Metadata: objectMeta{Labels: selector}, Spec: pod},
+			},
+		}
+	}
+	replicas := 1
+	return k8sObject{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   g.meta(g.aggregatorName(), "aggregator"),
+		Spec: &workloadSpec{
+			Replicas: &replicas,
+			Selector: &labelSelector{MatchLabels: selector},
+			// The volume can only be attached to one pod at a time
+			Strategy: &deploymentStrategy{Type: "Recreate"},
+			Template: podTemplate{Metadata: objectMeta{Labels: selector}, Spec: pod},
+		},
+	}
+}
+
+func (g *k8sGenerator) aggregatorService() k8sObject {
+	ports := []servicePort{{Name: "grpc", Port: atoi(g.grpcPort), TargetPort: "grpc"}}
+	if g.metricsPort != "" {
+		ports = append(ports, servicePort{Name: "metrics", Port: atoi(g.metricsPort), TargetPort: "metrics"})
+	}
+	return k8sObject{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   g.meta(g.aggregatorName(), "aggregator"),
+		Spec:       &serviceSpec{Selector: g.labels("aggregator"), Ports: ports},
+	}
+}
+
+// collaboratorService is the headless service StatefulSets require
+func (g *k8sGenerator) collaboratorService() k8sObject {
+	return k8sObject{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   g.meta(g.opts.Name+"-collaborators", "collaborator"),
+		Spec:       &serviceSpec{ClusterIP: "None", Selector: g.labels("collaborator")},
+	}
+}
+
+func (g *k8sGenerator) collaboratorJob(id string) k8sObject {
+	backoffLimit := 3
+	labels := g.collaboratorLabels(id)
+	meta := g.meta(g.collaboratorName(id), "collaborator")
+	meta.Labels = labels
+	return k8sObject{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata:   meta,
+		Spec: &workloadSpec{
+			BackoffLimit: &backoffLimit,
+			Template: podTemplate{
+				Metadata: objectMeta{Labels: labels},
+				Spec:     g.podSpec("OnFailure", g.collaboratorContainer(id)),
+			},
+		},
+	}
+}
+
+func (g *k8sGenerator) collaboratorStatefulSet(id string) k8sObject {
+	c := g.collaboratorContainer(id)
+	c.VolumeMounts = append(c.VolumeMounts, volumeMount{Name: "data", MountPath: workDir + "/data"})
+
+	labels := g.collaboratorLabels(id)
+	meta := g.meta(g.collaboratorName(id), "collaborator")
+	meta.Labels = labels
+	replicas := 1
+	return k8sObject{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Metadata:   meta,
+		Spec: &workloadSpec{
+			Replicas:    &replicas,
+			ServiceName: g.opts.Name + "-collaborators",
+			Selector:    &labelSelector{MatchLabels: labels},
+			Template:    podTemplate{Metadata: objectMeta{Labels: labels}, Spec: g.podSpec("Always", c)},
+			VolumeClaimTemplates: []k8sObject{{
+				Metadata: objectMeta{Name: "data"},
+				Spec:     g.volumeClaimSpec(),
+			}},
+		},
+	}
+}
+
+func (g *k8sGenerator) collaboratorLabels(id string) map[string]string {
+	labels := g.labels("collaborator")
+	labels["fl-go/collaborator"] = objectName(id)
+	return labels
+}
+
+func (g *k8sGenerator) collaboratorContainer(id string) container {
+	address := g.aggregatorName() + ":" + g.grpcPort
+	env := []envVar{{Name: federation.EnvName("aggregator.address"), Value: address}}
+	return g.container("collaborator", []string{"collaborator", "start", id, "--plan", g.planPath()}, env)
+}
+
+// container runs fx with args, with the plan and certificates mounted
+func (g *k8sGenerator) container(name string, args []string, env []envVar) container {
+	mounts := []volumeMount{{Name: "plan", MountPath: workDir + "/" + planMountDir, ReadOnly: true}}
+	for key, file := range tlsFiles(g.plan) {
+		if !path.IsAbs(file) {
+			file = workDir + "/" + file
+		}
+		mounts = append(mounts, volumeMount{Name: "tls", MountPath: file, SubPath: key, ReadOnly: true})
+	}
+	sortMounts(mounts)
+	return container{Name: name, Image: g.opts.Image, Args: args, Env: env, VolumeMounts: mounts}
+}
+
+// podSpec runs c with the plan and certificate volumes
+func (g *k8sGenerator) podSpec(restartPolicy string, c container) podSpec {
+	volumes := []volume{{Name: "plan", ConfigMap: &configMapVolumeSource{Name: g.opts.Name + "-plan"}}}
+	if len(tlsFiles(g.plan)) > 0 {
+		volumes = append(volumes, volume{Name: "tls", Secret: &secretVolumeSource{SecretName: g.opts.Name + "-tls"}})
+	}
+	return podSpec{
+		RestartPolicy:   restartPolicy,
+		SecurityContext: &podSecurityContext{FSGroup: imageGroup},
+		Containers:      []container{c},
+		Volumes:         volumes,
+	}
+}
+
+func (g *k8sGenerator) planPath() string {
+	return planMountDir + "/" + g.opts.PlanFile
+}
+
+// port returns the port of a host:port address, or fallback when it has none
+func port(address, fallback string) string {
+	if _, p, err := net.SplitHostPort(address); err == nil && p != "" {
+		return p
+	}
+	return fallback
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// objectName turns s into a valid object name: lower case alphanumerics and
+// dashes, at most 63 characters, starting and ending with an alphanumeric
+func objectName(s string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.Trim(name, "-")
+}
+
+func sortMounts(mounts []volumeMount) {
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].MountPath < mounts[j].MountPath })
+}
+
+// The subset of the Kubernetes API the generated manifests use
+
+type k8sObject struct {
+	APIVersion string            `yaml:"apiVersion,omitempty"`
+	Kind       string            `yaml:"kind,omitempty"`
+	Metadata   objectMeta        `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	Spec       interface{}       `yaml:"spec,omitempty"`
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// workloadSpec is the spec of Deployments, StatefulSets and Jobs
+type workloadSpec struct {
+	Replicas             *int                `yaml:"replicas,omitempty"`
+	ServiceName          string              `yaml:"serviceName,omitempty"`
+	BackoffLimit         *int                `yaml:"backoffLimit,omitempty"`
+	Selector             *labelSelector      `yaml:"selector,omitempty"`
+	Strategy             *deploymentStrategy `yaml:"strategy,omitempty"`
+	Template             podTemplate         `yaml:"template"`
+	VolumeClaimTemplates []k8sObject         `yaml:"volumeClaimTemplates,omitempty"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type deploymentStrategy struct {
+	Type string `yaml:"type"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `yaml:"metadata"`
+	Spec     podSpec    `yaml:"spec"`
+}
+
+type podSpec struct {
+	RestartPolicy   string              `yaml:"restartPolicy,omitempty"`
+	SecurityContext *podSecurityContext `yaml:"securityContext,omitempty"`
+	InitContainers  []container         `yaml:"initContainers,omitempty"`
+	Containers      []container         `yaml:"containers"`
+	Volumes         []volume            `yaml:"volumes,omitempty"`
+}
+
+type podSecurityContext struct {
+	FSGroup int `yaml:"fsGroup"`
+}
+
+type container struct {
+	Name         string          `yaml:"name"`
+	Image        string          `yaml:"image"`
+	Command      []string        `yaml:"command,omitempty"`
+	Args         []string        `yaml:"args,omitempty"`
+	Env          []envVar        `yaml:"env,omitempty"`
+	Ports        []containerPort `yaml:"ports,omitempty"`
+	VolumeMounts []volumeMount   `yaml:"volumeMounts,omitempty"`
+}
+
+type envVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type containerPort struct {
+	Name          string `yaml:"name"`
+	ContainerPort int    `yaml:"containerPort"`
+}
+
+type volumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type volume struct {
+	Name                  string                 `yaml:"name"`
+	ConfigMap             *configMapVolumeSource `yaml:"configMap,omitempty"`
+	Secret                *secretVolumeSource    `yaml:"secret,omitempty"`
+	PersistentVolumeClaim *claimVolumeSource     `yaml:"persistentVolumeClaim,omitempty"`
+}
+
+type configMapVolumeSource struct {
+	Name string `yaml:"name"`
+}
+
+type secretVolumeSource struct {
+	SecretName string `yaml:"secretName"`
+}
+
+type claimVolumeSource struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type volumeClaimSpec struct {
+	AccessModes []string             `yaml:"accessModes"`
+	Resources   resourceRequirements `yaml:"resources"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `yaml:"requests"`
+}
+
+type serviceSpec struct {
+	ClusterIP string            `yaml:"clusterIP,omitempty"`
+	Selector  map[string]string `yaml:"selector"`
+	Ports     []servicePort     `yaml:"ports,omitempty"`
+}
+
+type servicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort string `yaml:"targetPort"`
+}
@@ -0,0 +1,172 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+// decodeObjects decodes a multi-document manifest by kind and name
+func decodeObjects(t *testing.T, data []byte) map[string]map[string]interface{} {
+	t.Helper()
+	objects := make(map[string]map[string]interface{})
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			return objects
+		}
+		if err != nil {
+			t.Fatalf("invalid manifest: %v", err)
+		}
+		name := object["metadata"].(map[string]interface{})["name"].(string)
+		objects[object["kind"].(string)+"/"+name] = object
+	}
+}
+
+// lookup follows a path of map keys and list indexes
+func lookup(t *testing.T, node interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			node = node.(map[string]interface{})[k]
+		case int:
+			node = node.([]interface{})[k]
+		}
+	}
+	return node
+}
+
+func testPlan() *federation.FLPlan {
+	return &federation.FLPlan{
+		Rounds:        3,
+		Mode:          federation.ModeSync,
+		Aggregator:    federation.AggregatorEntry{Address: "localhost:50052"},
+		Collaborators: []federation.Collaborator{{ID: "site_A"}, {ID: "site-b"}},
+		Monitoring:    federation.MonitoringConfig{FederationID: "Fed-1", MetricsAddress: ":9090"},
+	}
+}
+
+func TestKubernetesJobs(t *testing.T) {
+	manifests, err := Kubernetes(testPlan(), []byte("rounds: 3\n"), KubernetesOptions{Namespace: "fl", Image: "registry/fl:1"})
+	if err != nil {
+		t.Fatalf("Kubernetes() error = %v", err)
+	}
+	objects := decodeObjects(t, manifests)
+
+	for _, name := range []string{"ConfigMap/fed-1-plan", "PersistentVolumeClaim/fed-1-aggregator-save",
+		"Job/fed-1-aggregator", "Service/fed-1-aggregator", "Job/fed-1-collaborator-site-a", "Job/fed-1-collaborator-site-b"} {
+		if objects[name] == nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if len(objects) != 6 {
+		t.Errorf("generated %d objects, want 6", len(objects))
+	}
+	if got := lookup(t, objects["ConfigMap/fed-1-plan"], "data", "plan.yaml"); got != "rounds: 3\n" {
+		t.Errorf("plan = %q", got)
+	}
+	if got := lookup(t, objects["Service/fed-1-aggregator"], "metadata", "namespace"); got != "fl" {
+		t.Errorf("namespace = %v", got)
+	}
+
+	aggregator := lookup(t, objects["Job/fed-1-aggregator"], "spec", "template", "spec", "containers", 0)
+	if got := lookup(t, aggregator, "env", 0, "value"); got != "0.0.0.0:50052" {
+		t.Errorf("aggregator listens on %v", got)
+	}
+	if got := lookup(t, aggregator, "env", 1, "name"); got != "FLGO_MONITORING_METRICS_ADDRESS" {
+		t.Errorf("second aggregator env = %v", got)
+	}
+
+	collaborator := lookup(t, objects["Job/fed-1-collaborator-site-a"], "spec", "template", "spec", "containers", 0)
+	if got := lookup(t, collaborator, "args", 2); got != "site_A" {
+		t.Errorf("collaborator ID = %v", got)
+	}
+	if got := lookup(t, collaborator, "env", 0, "value"); got != "fed-1-aggregator:50052" {
+		t.Errorf("collaborator dials %v", got)
+	}
+	if got := lookup(t, collaborator, "image"); got != "registry/fl:1" {
+		t.Errorf("image = %v", got)
+	}
+}
+
+func TestKubernetesStatefulSetsAndSecrets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"ca.crt", "server.crt", "server.key"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan := testPlan()
+	plan.Mode = federation.ModeAsync
+	plan.Security.TLS = federation.TLSConfig{
+		Enabled:  true,
+		CAPath:   filepath.Join(dir, "ca.crt"),
+		CertPath: filepath.Join(dir, "server.crt"),
+		KeyPath:  filepath.Join(dir, "server.key"),
+	}
+
+	manifests, err := Kubernetes(plan, nil, KubernetesOptions{Name: "demo", CollaboratorKind: CollaboratorStatefulSet})
+	if err != nil {
+		t.Fatalf("Kubernetes() error = %v", err)
+	}
+	objects := decodeObjects(t, manifests)
+
+	if got := lookup(t, objects["Secret/demo-tls"], "data", "tls.key"); got != "c2VydmVyLmtleQ==" {
+		t.Errorf("tls.key = %v", got)
+	}
+	// Async aggregators run until they are stopped
+	if objects["Deployment/demo-aggregator"] == nil {
+		t.Error("async aggregator is not a Deployment")
+	}
+	if got := lookup(t, objects["Service/demo-collaborators"], "spec", "clusterIP"); got != "None" {
+		t.Errorf("collaborator service clusterIP = %v", got)
+	}
+	statefulSet := objects["StatefulSet/demo-collaborator-site-b"]
+	if got := lookup(t, statefulSet, "spec", "volumeClaimTemplates", 0, "metadata", "name"); got != "data" {
+		t.Errorf("volume claim template = %v", got)
+	}
+	mounts := lookup(t, statefulSet, "spec", "template", "spec", "containers", 0, "volumeMounts").([]interface{})
+	found := false
+	for _, m := range mounts {
+		mount := m.(map[string]interface{})
+		found = found || (mount["mountPath"] == plan.Security.TLS.KeyPath && mount["subPath"] == "tls.key")
+	}
+	if !found {
+		t.Errorf("key is not mounted at %s: %v", plan.Security.TLS.KeyPath, mounts)
+	}
+}
+
+func TestKubernetesRejectsUnsupportedPlans(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*federation.FLPlan)
+		opts   KubernetesOptions
+	}{
+		{"decentralized", func(p *federation.FLPlan) { p.Mode = federation.ModeDecentralized }, KubernetesOptions{}},
+		{"edge aggregator", func(p *federation.FLPlan) { p.Role = federation.RoleEdgeAggregator }, KubernetesOptions{}},
+		{"no collaborators", func(p *federation.FLPlan) { p.Collaborators = nil }, KubernetesOptions{}},
+		{"unknown kind", func(p *federation.FLPlan) {}, KubernetesOptions{CollaboratorKind: "daemonset"}},
+		{"missing certificate", func(p *federation.FLPlan) {
+			p.Security.TLS = federation.TLSConfig{Enabled: true, CAPath: "missing/ca.crt"}
+		}, KubernetesOptions{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := testPlan()
+			tt.modify(plan)
+			if _, err := Kubernetes(plan, nil, tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
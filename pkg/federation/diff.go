@@ -0,0 +1,113 @@
+package federation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PlanChange is a setting that differs between two plans. Old is empty when
+// only the second plan sets it, New when only the first does.
+type PlanChange struct {
+	Path string // Dotted path of the setting; list items with an id are indexed by it
+	Old  string
+	New  string
+}
+
+// DiffPlans compares the settings of two plans, each merged with the plans it
+// extends and migrated to SchemaVersion. References are compared as written.
+func DiffPlans(pathA, pathB string) ([]PlanChange, error) {
+	a, err := planSettings(pathA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := planSettings(pathB)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []PlanChange
+	for path, old := range a {
+		if value, ok := b[path]; !ok || value != old {
+			changes = append(changes, PlanChange{Path: path, Old: old, New: value})
+		}
+	}
+	for path, value := range b {
+		if _, ok := a[path]; !ok {
+			changes = append(changes, PlanChange{Path: path, New: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// planSettings flattens the plan at path into its settings by dotted path
+func planSettings(path string) (map[string]string, error) {
+	root, err := loadPlanNode(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]string)
+	if len(root.Content) > 0 {
+		flattenNode(root.Content[0], "", settings)
+	}
+	return settings, nil
+}
+
+func flattenNode(node *yaml.Node, path string, settings map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 && path != "" {
+			settings[path] = "{}"
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			flattenNode(node.Content[i+1], key, settings)
+		}
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			settings[path] = "[]"
+		}
+		ids := itemIDs(node)
+		for i, item := range node.Content {
+			index := fmt.Sprint(i)
+			if ids != nil {
+				index = ids[i]
+			}
+			flattenNode(item, path+"["+index+"]", settings)
+		}
+	case yaml.AliasNode:
+		flattenNode(node.Alias, path, settings)
+	default:
+		if node.Value == "" {
+			settings[path] = `""`
+		} else {
+			settings[path] = node.Value
+		}
+	}
+}
+
+// itemIDs returns the ids of the items of a list, such as collaborators, so
+// that reordering it is no change. It returns nil unless every item has a
+// distinct id.
+func itemIDs(list *yaml.Node) []string {
+	ids := make([]string, len(list.Content))
+	seen := make(map[string]bool)
+	for i, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil
+		}
+		id := mappingValue(item, "id")
+		if id == nil || id.Kind != yaml.ScalarNode || id.Value == "" || seen[id.Value] || strings.ContainsAny(id.Value, "[]") {
+			return nil
+		}
+		seen[id.Value] = true
+		ids[i] = id.Value
+	}
+	return ids
+}
@@ -0,0 +1,92 @@
+package federation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the names of environment variables that override plan
+// settings
+const EnvPrefix = "FLGO_"
+
+// EnvName returns the environment variable that overrides the setting at the
+// YAML path, e.g. FLGO_AGGREGATOR_ADDRESS for aggregator.address
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// ApplyEnvOverrides sets the plan settings named by environment variables, so
+// that deployments can adjust a shared plan without editing it. Only scalar
+// settings (strings, numbers and booleans) can be overridden; lookup is
+// usually os.LookupEnv.
+func ApplyEnvOverrides(plan *FLPlan, lookup func(string) (string, bool)) error {
+	return applyEnv(reflect.ValueOf(plan).Elem(), "", lookup)
+}
+
+func applyEnv(v reflect.Value, path string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct {
+			if err := applyEnv(value, name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env := EnvName(name)
+		raw, ok := lookup(env)
+		if !ok {
+			continue
+		}
+		if err := setScalar(value, raw); err != nil {
+			return fmt.Errorf("invalid %s: %v", env, err)
+		}
+	}
+	return nil
+}
+
+func setScalar(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%s settings cannot be set from the environment", v.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package federation
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extendsKey names the plan a plan extends
+const extendsKey = "extends"
+
+// RenderPlan returns the plan at path merged with the plans it extends, as
+// YAML. References are left unresolved, so secrets are not revealed.
+func RenderPlan(path string) ([]byte, error) {
+	root, err := loadPlanNode(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	return buf.Bytes(), nil
+}
+
+// loadPlanNode parses the plan at path, migrates it to SchemaVersion and
+// merges it onto the plan it extends. A relative extends path is relative to the extending plan's
+// directory. chain holds the plans that extend this one, to detect cycles.
+func loadPlanNode(path string, chain []string) (*yaml.Node, error) {
+	if err := validateFilePath(path); err != nil {
+		return nil, err
+	}
+	for _, seen := range chain {
+		if filepath.Clean(seen) == filepath.Clean(path) {
+			return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), path)
+		}
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - Path validated with whitelist above
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return &root, nil
+	}
+
+	plan := root.Content[0]
+	from, notes, err := migratePlan(plan)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(notes) > 0 {
+		log.Printf("Warning: plan %s uses schema version %d and was migrated to %d (update it with fx plan migrate): %s",
+			path, from, SchemaVersion, strings.Join(notes, "; "))
+	}
+
+	var base string
+	if value := removeKey(plan, extendsKey); value != nil {
+		base = value.Value
+	}
+	if base == "" {
+		return &root, nil
+	}
+
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(path), base)
+	}
+	baseRoot, err := loadPlanNode(base, append(chain, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s, extended by %s: %w", base, path, err)
+	}
+	if len(baseRoot.Content) == 0 {
+		return &root, nil
+	}
+	baseRoot.Content[0] = mergeNodes(baseRoot.Content[0], plan)
+	return baseRoot, nil
+}
+
+// mergeNodes merges overlay onto base. Mappings are merged key by key; any
+// other overlay value, including a list, replaces the base value.
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,300 @@
+package federation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writePlans writes plans, keyed by path relative to a new directory, and
+// returns the directory
+func writePlans(t *testing.T, plans map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, plan := range plans {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(plan), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRenderPlanExtends(t *testing.T) {
+	tests := []struct {
+		name  string
+		plans map[string]string
+		want  string
+	}{
+		{
+			name: "maps merged key by key",
+			plans: map[string]string{
+				"base.yaml": `
+rounds: 10
+aggregator:
+  address: localhost:50051
+  admin_address: localhost:50053
+algorithm:
+  name: fedavg
+  hyperparameters:
+    learning_rate: 0.1
+    momentum: 0.9
+`,
+				"plan.yaml": `
+extends: base.yaml
+aggregator:
+  address: 0.0.0.0:50051
+algorithm:
+  hyperparameters:
+    learning_rate: 0.01
+`,
+			},
+			want: `
+schema_version: 2
+rounds: 10
+aggregator:
+  address: 0.0.0.0:50051
+  admin_address: localhost:50053
+algorithm:
+  name: fedavg
+  hyperparameters:
+    learning_rate: 0.01
+    momentum: 0.9
+`,
+		},
+		{
+			name: "lists replaced",
+			plans: map[string]string{
+				"base.yaml": `
+collaborators:
+  - id: site-a
+    address: a:50052
+  - id: site-b
+    address: b:50052
+`,
+				"plan.yaml": `
+extends: base.yaml
+collaborators:
+  - id: site-c
+    address: c:50052
+`,
+			},
+			want: `
+schema_version: 2
+collaborators:
+  - id: site-c
+    address: c:50052
+`,
+		},
+		{
+			name: "scalar replaces mapping",
+			plans: map[string]string{
+				"base.yaml": `
+tasks:
+  train:
+    script: train.py
+`,
+				"plan.yaml": `
+extends: base.yaml
+tasks: null
+`,
+			},
+			want: `
+schema_version: 2
+tasks: null
+`,
+		},
+		{
+			name: "nested extends",
+			plans: map[string]string{
+				"common/defaults.yaml": `
+rounds: 10
+algorithm:
+  name: fedavg
+  hyperparameters:
+    learning_rate: 0.1
+`,
+				"common/site.yaml": `
+extends: defaults.yaml
+rounds: 20
+aggregator:
+  address: localhost:50051
+`,
+				"plan.yaml": `
+extends: common/site.yaml
+algorithm:
+  hyperparameters:
+    learning_rate: 0.01
+`,
+			},
+			want: `
+schema_version: 2
+rounds: 20
+algorithm:
+  name: fedavg
+  hyperparameters:
+    learning_rate: 0.01
+aggregator:
+  address: localhost:50051
+`,
+		},
+		{
+			name: "extended plans migrated before merging",
+			plans: map[string]string{
+				"base.yaml": `
+aggregator:
+  host: localhost
+  port: 50051
+algorithm:
+  type: fedavg
+`,
+				"plan.yaml": `
+schema_version: 2
+extends: base.yaml
+algorithm:
+  name: fedprox
+`,
+			},
+			want: `
+schema_version: 2
+aggregator:
+  address: localhost:50051
+algorithm:
+  name: fedprox
+`,
+		},
+		{
+			name: "references left unresolved",
+			plans: map[string]string{
+				"base.yaml": `
+monitoring:
+  api_key: ${secret:monitoring_key}
+`,
+				"plan.yaml": `
+extends: base.yaml
+rounds: ${ROUNDS:-3}
+`,
+			},
+			want: `
+schema_version: 2
+monitoring:
+  api_key: ${secret:monitoring_key}
+rounds: ${ROUNDS:-3}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePlans(t, tt.plans)
+			data, err := RenderPlan(filepath.Join(dir, "plan.yaml"))
+			if err != nil {
+				t.Fatalf("RenderPlan() error = %v", err)
+			}
+			var got, want map[string]interface{}
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatalf("RenderPlan() returned invalid YAML: %v\n%s", err, data)
+			}
+			if err := yaml.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("RenderPlan() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderPlanExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		plans map[string]string
+		want  string
+	}{
+		{
+			name:  "self",
+			plans: map[string]string{"plan.yaml": "extends: plan.yaml\n"},
+			want:  "extends cycle",
+		},
+		{
+			name: "cycle",
+			plans: map[string]string{
+				"plan.yaml": "extends: a.yaml\n",
+				"a.yaml":    "extends: b.yaml\n",
+				"b.yaml":    "extends: ./plan.yaml\n",
+			},
+			want: "extends cycle",
+		},
+		{
+			name:  "missing base",
+			plans: map[string]string{"plan.yaml": "extends: missing.yaml\n"},
+			want:  "failed to load",
+		},
+		{
+			name:  "base not a plan file",
+			plans: map[string]string{"plan.yaml": "extends: base.json\n"},
+			want:  "invalid file extension",
+		},
+		{
+			name: "base newer than supported",
+			plans: map[string]string{
+				"plan.yaml": "extends: base.yaml\n",
+				"base.yaml": "schema_version: 99\n",
+			},
+			want: "newer than the latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePlans(t, tt.plans)
+			if _, err := RenderPlan(filepath.Join(dir, "plan.yaml")); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RenderPlan() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPlanExtends(t *testing.T) {
+	dir := writePlans(t, map[string]string{
+		"base.yaml": `
+rounds: 10
+collaborators:
+  - id: site-a
+    address: a:50052
+algorithm:
+  name: fedavg
+`,
+		"plan.yaml": `
+extends: base.yaml
+rounds: 5
+`,
+	})
+	plan, err := LoadPlan(filepath.Join(dir, "plan.yaml"))
+	if err != nil {
+		t.Fatalf("LoadPlan() error = %v", err)
+	}
+	if plan.Rounds != 5 || len(plan.Collaborators) != 1 || plan.Collaborators[0].ID != "site-a" || plan.Algorithm.Name != "fedavg" {
+		t.Errorf("LoadPlan() = %+v, want the base merged with the overrides", plan)
+	}
+}
+
+func TestMergeNodesKeepsBase(t *testing.T) {
+	base := parseMapping(t, "algorithm:\n  name: fedavg\n")
+	overlay := parseMapping(t, "algorithm:\n  name: fedprox\n")
+	merged := mergeNodes(base, overlay)
+
+	if got := mappingValue(mappingValue(merged, "algorithm"), "name").Value; got != "fedprox" {
+		t.Errorf("merged algorithm.name = %s, want fedprox", got)
+	}
+	if got := mappingValue(mappingValue(base, "algorithm"), "name").Value; got != "fedavg" {
+		t.Errorf("base algorithm.name = %s after merging, want fedavg", got)
+	}
+}
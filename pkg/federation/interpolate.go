@@ -0,0 +1,143 @@
+package federation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSecretsDir is where ${secret:name} references are read from unless
+// FLGO_SECRETS_DIR is set. Docker and Kubernetes mount secrets as files
+// there.
+const DefaultSecretsDir = "/run/secrets"
+
+// decodeYAML decodes data into out after interpolating references in its
+// values:
+//
+//	${VAR}           the environment variable VAR, which must be set
+//	${VAR:-default}  VAR, or default when VAR is unset or empty
+//	${secret:name}   the file name in the secrets directory
+//	${file:path}     the file at path
+//	$${              a literal ${
+//
+// File contents are used without their trailing newline. References are
+// resolved in values only, after parsing, so resolved values need no YAML
+// quoting.
+func decodeYAML(data []byte, out interface{}) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	return decodeNode(&root, out)
+}
+
+// decodeNode decodes a parsed document into out after interpolating the
+// references in its values
+func decodeNode(root *yaml.Node, out interface{}) error {
+	if err := interpolateNode(root); err != nil {
+		return err
+	}
+	if root.Kind == 0 {
+		return nil // empty document
+	}
+	return root.Decode(out)
+}
+
+func interpolateNode(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+		value, err := Interpolate(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", node.Line, err)
+		}
+		node.Value = value
+		// Unquoted values are typed by what they resolve to, so that
+		// "rounds: ${ROUNDS}" is a number
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := interpolateNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interpolate resolves the references in s (see decodeYAML)
+func Interpolate(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", s)
+		}
+		value, err := resolveReference(s[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+func resolveReference(ref string) (string, error) {
+	if name, ok := strings.CutPrefix(ref, "secret:"); ok {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return "", fmt.Errorf("invalid secret name %q", name)
+		}
+		dir := os.Getenv("FLGO_SECRETS_DIR")
+		if dir == "" {
+			dir = DefaultSecretsDir
+		}
+		return readReferencedFile(filepath.Join(dir, name), "secret "+name)
+	}
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		if path == "" {
+			return "", fmt.Errorf("empty file reference")
+		}
+		return readReferencedFile(path, "file "+path)
+	}
+
+	name, fallback, hasDefault := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty reference ${%s}", ref)
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if hasDefault {
+		return fallback, nil
+	}
+	if _, set := os.LookupEnv(name); set {
+		return "", nil
+	}
+	return "", fmt.Errorf("environment variable %s is not set", name)
+}
+
+func readReferencedFile(path, what string) (string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - the plan names the file
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", what, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,141 @@
+package federation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	secrets := t.TempDir()
+	if err := os.WriteFile(filepath.Join(secrets, "api_key"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("tok\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FLGO_SECRETS_DIR", secrets)
+	t.Setenv("FLGO_TEST_HOST", "agg.example.com")
+	t.Setenv("FLGO_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", in: "localhost:50051", want: "localhost:50051"},
+		{name: "variable", in: "${FLGO_TEST_HOST}:50051", want: "agg.example.com:50051"},
+		{name: "several references", in: "${FLGO_TEST_HOST}/${FLGO_TEST_HOST}", want: "agg.example.com/agg.example.com"},
+		{name: "default unused", in: "${FLGO_TEST_HOST:-localhost}", want: "agg.example.com"},
+		{name: "default when unset", in: "${FLGO_TEST_UNSET:-localhost}:50051", want: "localhost:50051"},
+		{name: "default when empty", in: "${FLGO_TEST_EMPTY:-localhost}", want: "localhost"},
+		{name: "empty default", in: "${FLGO_TEST_UNSET:-}", want: ""},
+		{name: "default containing a colon", in: "${FLGO_TEST_UNSET:-localhost:50051}", want: "localhost:50051"},
+		{name: "set but empty", in: "[${FLGO_TEST_EMPTY}]", want: "[]"},
+		{name: "unset", in: "${FLGO_TEST_UNSET}", wantErr: "FLGO_TEST_UNSET is not set"},
+		{name: "escaped", in: "$${FLGO_TEST_HOST}", want: "${FLGO_TEST_HOST}"},
+		{name: "escaped and resolved", in: "$${A} ${FLGO_TEST_HOST}", want: "${A} agg.example.com"},
+		{name: "escaped unterminated", in: "cost: $${", want: "cost: ${"},
+		{name: "lone dollar", in: "$5 and $HOME", want: "$5 and $HOME"},
+		{name: "unterminated", in: "${FLGO_TEST_HOST", wantErr: "unterminated reference"},
+		{name: "empty reference", in: "${}", wantErr: "empty reference"},
+		{name: "empty name with default", in: "${:-x}", wantErr: "empty reference"},
+		{name: "secret", in: "key=${secret:api_key}", want: "key=s3cret"},
+		{name: "missing secret", in: "${secret:missing}", wantErr: "failed to read secret missing"},
+		{name: "file", in: "${file:" + file + "}", want: "tok"},
+		{name: "empty file reference", in: "${file:}", wantErr: "empty file reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Interpolate(%q) = %q, %v, want error %q", tt.in, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Interpolate(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveReferenceSecretNames(t *testing.T) {
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secrets, 0750); err != nil {
+		t.Fatal(err)
+	}
+	// A file beside the secrets directory that no secret name may reach
+	if err := os.WriteFile(filepath.Join(dir, "outside"), []byte("leaked"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FLGO_SECRETS_DIR", secrets)
+
+	for _, name := range []string{"", ".", "..", "../outside", "sub/key", `..\outside`, "/etc/passwd"} {
+		if value, err := resolveReference("secret:" + name); err == nil || !strings.Contains(err.Error(), "invalid secret name") {
+			t.Errorf("resolveReference(secret:%s) = %q, %v, want invalid secret name", name, value, err)
+		}
+	}
+}
+
+func TestResolveReferenceDefaultSecretsDir(t *testing.T) {
+	t.Setenv("FLGO_SECRETS_DIR", "")
+	_, err := resolveReference("secret:fl-go-test-missing")
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(DefaultSecretsDir, "fl-go-test-missing")) {
+		t.Errorf("resolveReference() error = %v, want a read of %s", err, DefaultSecretsDir)
+	}
+}
+
+func TestDecodeYAMLTypes(t *testing.T) {
+	t.Setenv("FLGO_TEST_ROUNDS", "5")
+	t.Setenv("FLGO_TEST_ENABLED", "true")
+
+	var values map[string]interface{}
+	err := decodeYAML([]byte(`
+rounds: ${FLGO_TEST_ROUNDS}
+quoted: "${FLGO_TEST_ROUNDS}"
+single: '${FLGO_TEST_ROUNDS}'
+enabled: ${FLGO_TEST_ENABLED}
+defaulted: ${FLGO_TEST_UNSET_RATE:-0.5}
+literal: |
+  ${FLGO_TEST_ROUNDS}
+escaped: $${FLGO_TEST_ROUNDS}
+nested:
+  - ${FLGO_TEST_ROUNDS}
+`), &values)
+	if err != nil {
+		t.Fatalf("decodeYAML() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"rounds":    5,
+		"quoted":    "5",
+		"single":    "5",
+		"enabled":   true,
+		"defaulted": 0.5,
+		"literal":   "5\n",
+		"escaped":   "${FLGO_TEST_ROUNDS}",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, values[key], value)
+		}
+	}
+	if nested, ok := values["nested"].([]interface{}); !ok || len(nested) != 1 || nested[0] != 5 {
+		t.Errorf("nested = %#v, want [5]", values["nested"])
+	}
+
+	var plan FLPlan
+	if err := decodeYAML([]byte("rounds: ${FLGO_TEST_ROUNDS}\n"), &plan); err != nil || plan.Rounds != 5 {
+		t.Errorf("decodeYAML() rounds = %d, %v, want 5", plan.Rounds, err)
+	}
+	if err := decodeYAML([]byte("rounds: 1\nname: ${FLGO_TEST_UNSET_NAME}\n"), &values); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("decodeYAML() error = %v, want the line of the unset variable", err)
+	}
+}
@@ -1,17 +1,39 @@
 package federation
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// LoadPlan loads a federated learning plan from a YAML file.
+// LoadPlan loads a federated learning plan from a YAML file, merged onto the
+// plan it extends. Environment and secret references in its values are
+// resolved (see decodeYAML), and FLGO_ environment variables override its
+// settings (see ApplyEnvOverrides).
 func LoadPlan(path string) (*FLPlan, error) {
-	// Validate and sanitize the file path to prevent path traversal
+	root, err := loadPlanNode(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var plan FLPlan
+	if err := decodeNode(root, &plan); err != nil {
+		return nil, err
+	}
+	if err := ApplyEnvOverrides(&plan, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// LoadLocalConfig loads a collaborator's own config file
+func LoadLocalConfig(path string) (*LocalConfig, error) {
 	if err := validateFilePath(path); err != nil {
 		return nil, err
 	}
@@ -20,15 +42,21 @@ func LoadPlan(path string) (*FLPlan, error) {
 	if err != nil {
 		return nil, err
 	}
-	var plan FLPlan
-	if err := yaml.Unmarshal(data, &plan); err != nil {
+	var config LocalConfig
+	if err := decodeYAML(data, &config); err != nil {
 		return nil, err
 	}
-	return &plan, nil
+	return &config, nil
 }
 
-// SavePlan saves a federated learning plan to a YAML file.
+// SavePlan saves a federated learning plan to a YAML file. Plans without a
+// schema version are saved as SchemaVersion.
 func SavePlan(plan *FLPlan, path string) error {
+	if plan.SchemaVersion == 0 {
+		saved := *plan
+		saved.SchemaVersion = SchemaVersion
+		plan = &saved
+	}
 	data, err := yaml.Marshal(plan)
 	if err != nil {
 		return err
@@ -36,6 +64,73 @@ func SavePlan(plan *FLPlan, path string) error {
 	return os.WriteFile(path, data, 0600)
 }
 
+// PlanHash returns the hex SHA-256 of the plan's canonical YAML encoding.
+// Where the run report and manifest are written does not change the hash, so
+// that a rerun can keep its outputs apart from the original's.
+func PlanHash(plan *FLPlan) (string, error) {
+	canonical := *plan
+	canonical.ReportPath = ""
+	canonical.ManifestPath = ""
+	data, err := yaml.Marshal(&canonical)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PlanFingerprint returns the hex SHA-256 of the settings every member of a
+// federation must agree on: the mode, rounds, seed, collaborator IDs,
+// algorithm, tasks and data format. Settings that differ between sites, such
+// as addresses, credentials, file paths, interpreters, container mounts and
+// monitoring, are left out, so that overlays and FLGO_ overrides keep the
+// fingerprint. So are the settings only the aggregator reads, async_config
+// and tasks.distill, which collaborators' plans need not carry.
+func PlanFingerprint(plan *FLPlan) (string, error) {
+	ids := make([]string, len(plan.Collaborators))
+	for i, collab := range plan.Collaborators {
+		ids[i] = collab.ID
+	}
+	sort.Strings(ids)
+	mode := plan.Mode
+	if mode == "" {
+		mode = ModeSync
+	}
+	tasks := plan.Tasks
+	tasks.Train.Docker = DockerConfig{}
+	tasks.Evaluate.Docker = DockerConfig{}
+	tasks.Train.Python, tasks.Evaluate.Python = "", ""
+	tasks.Distill = TaskConfig{}
+
+	contract := struct {
+		SchemaVersion int                 `yaml:"schema_version"`
+		Mode          FLMode              `yaml:"mode"`
+		Rounds        int                 `yaml:"rounds"`
+		Seed          uint64              `yaml:"seed"`
+		Collaborators []string            `yaml:"collaborators"`
+		Algorithm     AlgorithmConfig     `yaml:"algorithm"`
+		Tasks         TasksConfig         `yaml:"tasks"`
+		Decentralized DecentralizedConfig `yaml:"decentralized"`
+		Dispatch      bool                `yaml:"dispatch"`
+		DataFormat    DataFormat          `yaml:"data_format"`
+		DataSchema    []string            `yaml:"data_schema"`
+	}{plan.SchemaVersion, mode, plan.Rounds, plan.Seed, ids, plan.Algorithm, tasks,
+		plan.Decentralized, plan.Dispatch.Enabled, plan.Data.Format, plan.Data.Schema}
+	data, err := yaml.Marshal(&contract)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DeriveSeed derives the seed of a named random number generator, such as a
+// collaborator's privacy noise, from the plan's seed
+func DeriveSeed(seed uint64, name string) [32]byte {
+	buf := binary.LittleEndian.AppendUint64(nil, seed)
+	return sha256.Sum256(append(buf, name...))
+}
+
 // validateFilePath validates and sanitizes file paths to prevent directory traversal attacks
 func validateFilePath(path string) error {
 	// Clean the path to resolve any "../" sequences
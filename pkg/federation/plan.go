@@ -2,21 +2,126 @@ package federation
 
 // FLPlan is the federated learning configuration.
 type FLPlan struct {
-	Rounds        int             `yaml:"rounds"`
-	Collaborators []Collaborator  `yaml:"collaborators"`
-	Aggregator    AggregatorEntry `yaml:"aggregator"`
-	InitialModel  string          `yaml:"initial_model"`
-	OutputModel   string          `yaml:"output_model"`
-	Tasks         TasksConfig     `yaml:"tasks"`
+	SchemaVersion    int             `yaml:"schema_version"` // Plan format version; older plans are migrated when loaded
+	Rounds           int             `yaml:"rounds"`
+	Collaborators    []Collaborator  `yaml:"collaborators"`
+	Aggregator       AggregatorEntry `yaml:"aggregator"`
+	InitialModel     string          `yaml:"initial_model"`
+	InitialModelFrom string          `yaml:"initial_model_from"` // Registry model to start from instead of initial_model: <name>[@<version|latest|stage>]
+	OutputModel      string          `yaml:"output_model"`
+	ReportPath       string          `yaml:"report_path"`   // Run summary written at shutdown (default: save/run_report.json)
+	ManifestPath     string          `yaml:"manifest_path"` // Reproducibility manifest written at shutdown (default: save/run_manifest.json)
+	Seed             uint64          `yaml:"seed"`          // Seeds the run's random number generators (default: unseeded)
+	Tasks            TasksConfig     `yaml:"tasks"`
 	// New fields for async FL support
-	Mode        FLMode      `yaml:"mode"`         // sync or async
+	Mode        FLMode      `yaml:"mode"`         // sync, async or decentralized
 	AsyncConfig AsyncConfig `yaml:"async_config"` // async-specific settings
+	// Decentralized (serverless) aggregation settings
+	Decentralized DecentralizedConfig `yaml:"decentralized"`
 	// New field for aggregation algorithm support
 	Algorithm AlgorithmConfig `yaml:"algorithm"` // aggregation algorithm configuration
 	// Monitoring configuration
 	Monitoring MonitoringConfig `yaml:"monitoring"` // monitoring configuration
 	// Security configuration
 	Security SecurityConfig `yaml:"security"` // security configuration
+	// Hierarchical aggregation settings
+	Role      Role            `yaml:"role"`      // aggregator (default) or edge-aggregator
+	Hierarchy HierarchyConfig `yaml:"hierarchy"` // edge aggregator settings
+	// Model registry for aggregated models
+	Registry RegistryConfig `yaml:"registry"`
+	// MLflow experiment tracking of federation runs
+	MLflow MLflowConfig `yaml:"mlflow"`
+	// Aggregator-driven task dispatch (sync fedavg only)
+	Dispatch DispatchConfig `yaml:"dispatch"`
+	// Several global models for clusters of similar collaborators (sync only)
+	Clustering ClusteringConfig `yaml:"clustering"`
+	// Collaborator retries of failed aggregator calls
+	Retry RetryConfig `yaml:"retry"`
+	// Local dataset collaborators validate before joining
+	Data DataConfig `yaml:"data"`
+	// gRPC tuning of aggregator and collaborator connections
+	GRPC GRPCConfig `yaml:"grpc"`
+}
+
+// GRPCConfig tunes the gRPC servers and clients of aggregators and
+// collaborators. Zero values select the defaults.
+type GRPCConfig struct {
+	MaxRecvMsgSize    int `yaml:"max_recv_msg_size"`  // Largest message received, in MB (default: 256)
+	MaxSendMsgSize    int `yaml:"max_send_msg_size"`  // Largest message sent, in MB (default: 256)
+	KeepaliveTime     int `yaml:"keepalive_time"`     // Seconds without activity before a keepalive ping (default: disabled; minimum 10)
+	KeepaliveTimeout  int `yaml:"keepalive_timeout"`  // Seconds to wait for a ping acknowledgement before closing (default: 20)
+	ConnectionTimeout int `yaml:"connection_timeout"` // Seconds to establish a connection (default: gRPC's)
+	CallTimeout       int `yaml:"call_timeout"`       // Deadline of each call to an aggregator or peer in seconds (default: 30)
+}
+
+// DataFormat selects how a collaborator reads its local dataset
+type DataFormat string
+
+const (
+	DataCSV   DataFormat = "csv"   // CSV file with a header row; one sample per row
+	DataJSONL DataFormat = "jsonl" // one JSON object per line and sample
+	DataNPY   DataFormat = "npy"   // NumPy array whose first dimension counts the samples
+	DataFiles DataFormat = "files" // directory with one file per sample, such as images
+)
+
+// DataConfig describes the local dataset of each collaborator. Collaborators
+// check that it exists and matches the manifest before joining, and weight
+// their updates by its number of samples.
+type DataConfig struct {
+	Path   string     `yaml:"path"`   // Dataset file or directory, relative to the workspace
+	Format DataFormat `yaml:"format"` // csv, jsonl, npy or files (default: inferred from path)
+	Schema []string   `yaml:"schema"` // Columns csv and jsonl datasets must have
+	SHA256 string     `yaml:"sha256"` // Expected digest when all collaborators share a dataset
+}
+
+// RetryConfig controls how collaborators retry aggregator calls that fail
+// with a transient error, such as an unreachable or restarting aggregator
+type RetryConfig struct {
+	MaxAttempts    int     `yaml:"max_attempts"`    // Attempts per call, including the first (default: 5; 1 disables retries)
+	InitialBackoff float64 `yaml:"initial_backoff"` // Seconds before the first retry (default: 1)
+	MaxBackoff     float64 `yaml:"max_backoff"`     // Upper bound of the delay between retries in seconds (default: 30)
+	Multiplier     float64 `yaml:"multiplier"`      // Growth of the delay per retry (default: 2)
+	Jitter         float64 `yaml:"jitter"`          // Random spread of each delay as a fraction of it (default: 0.2)
+}
+
+// DispatchConfig lets the aggregator assign tasks to collaborators, which
+// poll it with GetTask instead of running rounds on their own
+type DispatchConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	PollInterval int  `yaml:"poll_interval"` // Seconds a collaborator sleeps when there is no task (default: DefaultPollInterval)
+}
+
+// DefaultPollInterval is the dispatch poll interval in seconds when none is set
+const DefaultPollInterval = 5
+
+// ClusteringConfig lets the aggregator keep several global models and train
+// each on a cluster of collaborators whose updates point the same way, for
+// cohorts whose data differ too much to share one model
+type ClusteringConfig struct {
+	Enabled        bool `yaml:"enabled"`
+	Clusters       int  `yaml:"clusters"`        // Number of global models (default: DefaultClusters)
+	ReclusterEvery int  `yaml:"recluster_every"` // Rounds between re-clustering, starting after round 1 (default: DefaultReclusterEvery)
+}
+
+// Clustering defaults used when the plan sets none
+const (
+	DefaultClusters       = 2
+	DefaultReclusterEvery = 5
+)
+
+// Role selects what an aggregator process does in a hierarchical federation
+type Role string
+
+const (
+	RoleAggregator Role = "aggregator" // aggregates updates into the global model (root or single tier)
+	// RoleEdgeAggregator aggregates a regional group of collaborators and
+	// forwards the partial aggregate to the root aggregator
+	RoleEdgeAggregator Role = "edge-aggregator"
+)
+
+type HierarchyConfig struct {
+	EdgeID      string `yaml:"edge_id"`      // ID the edge aggregator uses with the root; must be listed in the root plan's collaborators
+	RootAddress string `yaml:"root_address"` // Address of the root aggregator
 }
 
 type FLMode string
@@ -24,50 +129,267 @@ type FLMode string
 const (
 	ModeSync  FLMode = "sync"
 	ModeAsync FLMode = "async"
+	// ModeDecentralized is experimental: collaborators aggregate among themselves
+	ModeDecentralized FLMode = "decentralized"
+)
+
+// DecentralizedStrategy selects how peers aggregate without a central server
+type DecentralizedStrategy string
+
+const (
+	StrategyRoundRobin DecentralizedStrategy = "round_robin" // collaborators take turns acting as aggregator
+	StrategyGossip     DecentralizedStrategy = "gossip"      // each peer averages with its ring neighbors
 )
 
+type DecentralizedConfig struct {
+	Strategy     DecentralizedStrategy `yaml:"strategy"`      // round_robin (default) or gossip
+	Neighbors    int                   `yaml:"neighbors"`     // Number of ring neighbors per side for gossip
+	RoundTimeout int                   `yaml:"round_timeout"` // Seconds to wait for peers before giving up on a round
+}
+
 type AsyncConfig struct {
 	MaxStaleness     int     `yaml:"max_staleness"`     // Maximum staleness allowed for updates
 	MinUpdates       int     `yaml:"min_updates"`       // Minimum updates before aggregation
-	AggregationDelay int     `yaml:"aggregation_delay"` // Delay in seconds before aggregating
+	AggregationDelay int     `yaml:"aggregation_delay"` // Delay in seconds before aggregating; the upper bound of an adaptive delay
 	StalenessWeight  float64 `yaml:"staleness_weight"`  // Weight decay factor for stale updates
+	// Adapts the aggregation delay to the arrival rate and staleness of updates
+	AdaptiveDelay AdaptiveDelayConfig `yaml:"adaptive_delay"`
+	// Staleness weighting of the modular aggregator (fedopt, fedprox, ...)
+	StalenessFunction string  `yaml:"staleness_function"` // constant (default), polynomial or hinge
+	StalenessAlpha    float64 `yaml:"staleness_alpha"`    // Decay rate of the polynomial and hinge functions (default: 0.5)
+	StalenessHinge    int     `yaml:"staleness_hinge"`    // Seconds of staleness before the hinge function starts to decay
+	// Fairness across collaborators, so a fast one cannot dominate the global model
+	MaxPendingPerCollaborator int `yaml:"max_pending_per_collaborator"` // Updates of one collaborator that may wait for aggregation; a newer one replaces the oldest (0 = unlimited)
+	MaxPerCollaborator        int `yaml:"max_per_collaborator"`         // Updates of one collaborator an aggregation takes; the rest wait for the next one (0 = unlimited)
+	// Completion criteria; the run ends when any is met, or when it is stopped
+	MaxRounds            int     `yaml:"max_rounds"`            // Stop after this many aggregations (0 = unlimited)
+	MaxDuration          int     `yaml:"max_duration"`          // Stop after this many seconds (0 = unlimited)
+	ConvergenceThreshold float64 `yaml:"convergence_threshold"` // Stop when an aggregation changes the model by less than this relative L2 distance (0 = disabled)
 }
 
 type Collaborator struct {
-	ID      string `yaml:"id"`
-	Address string `yaml:"address"`
+	ID      string           `yaml:"id"`
+	Address string           `yaml:"address"`
+	Role    CollaboratorRole `yaml:"role"` // trainer (default) or evaluator
+	// Expected digest of this collaborator's dataset, overriding data.sha256
+	DataSHA256 string `yaml:"data_sha256"`
+	// Task args of this collaborator, such as its data path or a smaller batch size for weaker hardware
+	TaskArgs TaskArgsConfig `yaml:"task_args"`
+	// Where the collaborator runs, reported to the monitoring server
+	Region    string   `yaml:"region"`
+	Site      string   `yaml:"site"` // Name of the site, such as a hospital or lab
+	Tags      []string `yaml:"tags"`
+	Latitude  *float64 `yaml:"latitude"`
+	Longitude *float64 `yaml:"longitude"`
+}
+
+// TaskArgsConfig overrides the args of the plan's tasks for one collaborator
+type TaskArgsConfig struct {
+	Train    map[string]interface{} `yaml:"train"`    // Merged over tasks.train.args
+	Evaluate map[string]interface{} `yaml:"evaluate"` // Merged over tasks.evaluate.args
+}
+
+// CollaboratorRole selects whether a collaborator trains or only evaluates.
+// Evaluators receive their models as dispatched evaluate tasks, also in plans
+// without task dispatch.
+type CollaboratorRole string
+
+const (
+	CollaboratorTrainer   CollaboratorRole = "trainer"   // trains in every round
+	CollaboratorEvaluator CollaboratorRole = "evaluator" // only evaluates the aggregated models on its hold-out data
+)
+
+// RoleOf returns the role of a plan collaborator, or false if the
+// collaborator is not in the plan
+func RoleOf(plan *FLPlan, collaboratorID string) (CollaboratorRole, bool) {
+	for _, collab := range plan.Collaborators {
+		if collab.ID == collaboratorID {
+			if collab.Role == "" {
+				return CollaboratorTrainer, true
+			}
+			return collab.Role, true
+		}
+	}
+	return "", false
 }
 
 type AggregatorEntry struct {
-	Address string `yaml:"address"`
+	Address       string    `yaml:"address"`
+	AdminToken    string    `yaml:"admin_token"`    // Required by the admin endpoints on monitoring.metrics_address and the AdminService RPCs; sent as X-API-Key
+	AdminAddress  string    `yaml:"admin_address"`  // Serves the AdminService here, to clients with a certificate of the security.tls CA and the admin token
+	Streaming     bool      `yaml:"streaming"`      // Sync FedAvg and edge aggregators add updates to a running sum instead of keeping them for the round
+	MmapModel     bool      `yaml:"mmap_model"`     // Sync FedAvg keeps the global model in memory-mapped files under save/ instead of on the heap
+	DeltaTransfer bool      `yaml:"delta_transfer"` // Sync FedAvg exchanges models and updates with collaborators as diffs from a model both sides have
+	UpdatesDir    string    `yaml:"updates_dir"`    // Sync aggregators keep each round's raw updates here for fx federation reaggregate
+	WAL           WALConfig `yaml:"wal"`            // Sync FedAvg logs updates to disk as they arrive and resumes the round after a restart
+}
+
+// AdaptiveDelayConfig configures an async aggregation delay that follows the
+// observed arrival rate and staleness of updates
+type AdaptiveDelayConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	TargetStaleness int     `yaml:"target_staleness"` // Seconds the percentile of update staleness should stay under
+	Percentile      float64 `yaml:"percentile"`       // Staleness percentile held to the target, in (0, 1] (default: 0.9)
+	MinDelay        int     `yaml:"min_delay"`        // Shortest delay in seconds (default: 1)
+}
+
+// WALConfig configures the write-ahead log of updates
+type WALConfig struct {
+	Dir          string `yaml:"dir"`           // Enables the WAL; one segment per round
+	Fsync        bool   `yaml:"fsync"`         // Sync each update to the disk before acknowledging it, so that it survives a machine crash
+	RetainRounds int    `yaml:"retain_rounds"` // Segments of aggregated rounds to keep (default: 0, removed when the next round starts)
 }
 
 type TasksConfig struct {
-	Train TaskConfig `yaml:"train"`
+	Train    TaskConfig `yaml:"train"`
+	Evaluate TaskConfig `yaml:"evaluate"` // Run for dispatched evaluate tasks
+	Distill  TaskConfig `yaml:"distill"`  // Run by the aggregator to distill client models with the feddf algorithm
 }
 
+// RunnerType selects how a collaborator executes a task
+type RunnerType string
+
+const (
+	RunnerPython RunnerType = "python" // <python> <script>
+	RunnerExec   RunnerType = "exec"   // script is an executable
+	RunnerDocker RunnerType = "docker" // runs script, or the image's entrypoint, in a container with the workspace mounted
+	RunnerGo     RunnerType = "go"     // calls a Go training function registered under function
+)
+
 type TaskConfig struct {
-	Script string                 `yaml:"script"`
-	Args   map[string]interface{} `yaml:"args"`
+	Runner   RunnerType             `yaml:"runner"`           // python (default), exec, docker or go
+	Script   string                 `yaml:"script"`           // Python script, executable or container command
+	Python   string                 `yaml:"python,omitempty"` // Interpreter of the python runner (default: FLGO_PYTHON, then python3, or python on Windows)
+	Image    string                 `yaml:"image"`            // Container image for the docker runner
+	Function string                 `yaml:"function"`         // Registered function name for the go runner
+	Docker   DockerConfig           `yaml:"docker"`           // Container settings for the docker runner
+	Args     map[string]interface{} `yaml:"args"`
+	Metrics  bool                   `yaml:"metrics"` // Train tasks write their local metrics as JSON to --metrics-out
+	// Per-round values of args, sent with dispatched train tasks
+	Schedule map[string]ScheduleConfig `yaml:"schedule"`
+}
+
+// ScheduleType selects how a scheduled arg changes from round to round
+type ScheduleType string
+
+const (
+	ScheduleStep        ScheduleType = "step"        // multiplied by gamma every step_size rounds
+	ScheduleExponential ScheduleType = "exponential" // multiplied by gamma every round
+	ScheduleCosine      ScheduleType = "cosine"      // annealed from the arg's value to min over the run
+	SchedulePiecewise   ScheduleType = "piecewise"   // takes values[r] from round r on
+)
+
+// ScheduleConfig describes the per-round values of one task arg. Step,
+// exponential and cosine schedules start from the arg's value in round 1.
+type ScheduleConfig struct {
+	Type     ScheduleType        `yaml:"type"`
+	Gamma    float64             `yaml:"gamma"`     // Decay factor of step and exponential schedules
+	StepSize int                 `yaml:"step_size"` // Rounds between decays of a step schedule (default: 1)
+	Min      float64             `yaml:"min"`       // Value of a cosine schedule in the last round
+	Values   map[int]interface{} `yaml:"values"`    // Round -> value of a piecewise schedule
+}
+
+// DockerConfig holds the mounts and resource limits of a docker task
+type DockerConfig struct {
+	Volumes []string `yaml:"volumes"` // Extra mounts as host:container[:ro], e.g. /data/mnist:/data:ro
+	CPUs    string   `yaml:"cpus"`    // CPU limit, e.g. "2" or "0.5"
+	Memory  string   `yaml:"memory"`  // Memory limit, e.g. "4g"
+	GPUs    string   `yaml:"gpus"`    // GPUs to expose: "all", a count, or "device=0,1"
 }
 
 type AlgorithmConfig struct {
-	Name            string                 `yaml:"name"`            // fedavg, fedopt, fedprox
+	Name            string                 `yaml:"name"`            // fedavg, fedavgm, fednova, fedopt, fedprox, feddf
 	Hyperparameters map[string]interface{} `yaml:"hyperparameters"` // algorithm-specific parameters
 }
 
 // MonitoringConfig contains monitoring configuration for a federation
 type MonitoringConfig struct {
-	Enabled                bool   `yaml:"enabled"`                  // Enable monitoring for this federation
-	MonitoringServerURL    string `yaml:"monitoring_server_url"`    // URL of the monitoring server
-	CollectResourceMetrics bool   `yaml:"collect_resource_metrics"` // Collect system resource metrics
-	ReportInterval         int    `yaml:"report_interval"`          // Interval in seconds for metric reporting
-	EnableRealTimeEvents   bool   `yaml:"enable_realtime_events"`   // Enable real-time event streaming
+	Enabled                bool              `yaml:"enabled"`                  // Enable monitoring for this federation
+	MonitoringServerURL    string            `yaml:"monitoring_server_url"`    // URL of the monitoring server
+	CollectResourceMetrics bool              `yaml:"collect_resource_metrics"` // Collect system resource metrics
+	ReportInterval         int               `yaml:"report_interval"`          // Interval in seconds for metric reporting
+	EnableRealTimeEvents   bool              `yaml:"enable_realtime_events"`   // Enable real-time event streaming
+	FederationID           string            `yaml:"federation_id"`            // Federation ID reported to the monitoring server
+	MetricsAddress         string            `yaml:"metrics_address"`          // Address for the aggregator Prometheus /metrics endpoint
+	APIKey                 string            `yaml:"api_key"`                  // API key sent when the monitoring server requires authentication
+	TensorBoard            bool              `yaml:"tensorboard"`              // Write per-round scalars of the aggregator as TensorBoard event files
+	TensorBoardDir         string            `yaml:"tensorboard_dir"`          // Directory of the event files, one subdirectory per federation (default: logs)
+	Labels                 map[string]string `yaml:"labels"`                   // key=value labels of the rounds reported to the monitoring server
+}
+
+// MLflowConfig tracks each federation run as an MLflow run
+type MLflowConfig struct {
+	TrackingURI string `yaml:"tracking_uri"` // URL of the MLflow tracking server; empty disables tracking
+	Experiment  string `yaml:"experiment"`   // Experiment the runs belong to (default: fl-go)
+	RunName     string `yaml:"run_name"`     // Default: the federation ID
+	Token       string `yaml:"token"`        // Bearer token (default: MLFLOW_TRACKING_TOKEN)
+}
+
+// RegistryConfig configures the model registry that versions every aggregated model
+type RegistryConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Backend         string `yaml:"backend"`           // local (default) or s3
+	Name            string `yaml:"name"`              // Model name (default: the federation ID)
+	Dir             string `yaml:"dir"`               // Local backend directory (default: registry)
+	Bucket          string `yaml:"bucket"`            // S3 bucket
+	Prefix          string `yaml:"prefix"`            // S3 key prefix
+	Region          string `yaml:"region"`            // S3 region (default: us-east-1)
+	Endpoint        string `yaml:"endpoint"`          // S3-compatible endpoint such as MinIO
+	AccessKeyID     string `yaml:"access_key_id"`     // Default: AWS_ACCESS_KEY_ID
+	SecretAccessKey string `yaml:"secret_access_key"` // Default: AWS_SECRET_ACCESS_KEY
 }
 
 // SecurityConfig contains security configuration for a federation
 type SecurityConfig struct {
-	TLS TLSConfig `yaml:"tls"` // TLS configuration
+	TLS            TLSConfig            `yaml:"tls"`             // TLS configuration
+	UpdateClipping UpdateClippingConfig `yaml:"update_clipping"` // Norm cap on collaborators' updates
+	ModelSigning   ModelSigningConfig   `yaml:"model_signing"`   // Signed global models
+	Homomorphic    HomomorphicConfig    `yaml:"homomorphic"`     // Encrypted updates, summed without decryption (experimental)
+	Attestation    AttestationConfig    `yaml:"attestation"`     // Admission of collaborators by enclave attestation
+}
+
+// AttestationConfig admits collaborators by the attestation evidence of the
+// trusted execution environment, such as SGX, SEV-SNP or Nitro, they present
+// when joining. Without required, evidence is checked and logged but never
+// keeps a collaborator out.
+type AttestationConfig struct {
+	Required        bool     `yaml:"required"`         // Reject collaborators without valid evidence
+	Types           []string `yaml:"types"`            // Accepted evidence types; empty accepts any
+	Measurements    []string `yaml:"measurements"`     // Hex measurements of the allowed enclave code; empty accepts any verified one
+	AllowDebug      bool     `yaml:"allow_debug"`      // Admit enclaves in debug mode, whose memory the host can read
+	VerifierCommand []string `yaml:"verifier_command"` // Verifies evidence of types without a verifier built into the aggregator
+	Exempt          []string `yaml:"exempt"`           // Collaborator IDs admitted without evidence, such as edge aggregators
+}
+
+// HomomorphicConfig has collaborators encrypt their updates with a Paillier
+// public key, so that the aggregator adds up ciphertexts and never sees a
+// single update. A key holder decrypts the sum of each round. Experimental:
+// only the sync FedAvg aggregator supports it, and encrypting is orders of
+// magnitude slower than plain aggregation.
+type HomomorphicConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	PublicKeyPath  string `yaml:"public_key_path"`  // Paillier public key updates are encrypted with, from fx he keygen
+	KeyHolderURL   string `yaml:"key_holder_url"`   // Key holder that decrypts the round sums, such as fx he key-holder
+	KeyHolderToken string `yaml:"key_holder_token"` // Sent to the key holder as X-API-Key
+	PrivateKeyPath string `yaml:"private_key_path"` // Decrypt in the aggregator instead of a key holder; for testing only
+}
+
+// ModelSigningConfig has the aggregator sign every model it hands out with an
+// Ed25519 key. Collaborators refuse to train on models whose signature does
+// not verify with the public key.
+type ModelSigningConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	KeyPath       string `yaml:"key_path"`        // Aggregator's private key, PKCS #8 PEM; only read by the aggregator
+	PublicKeyPath string `yaml:"public_key_path"` // Public key collaborators and edge aggregators verify models with, PKIX PEM
+}
+
+// UpdateClippingConfig caps the L2 norm of the change an update makes to the
+// model it was trained on. The aggregator scales larger changes down to the
+// cap before aggregating, which bounds how far a single poisoned update can
+// move the global model.
+type UpdateClippingConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	MaxNorm float64 `yaml:"max_norm"` // Maximum L2 norm of an update's change
 }
 
 // TLSConfig represents the TLS configuration for mTLS
@@ -80,3 +402,26 @@ type TLSConfig struct {
 	InsecureSkipTLS  bool   `yaml:"insecure_skip_tls"`  // Skip TLS verification (development only)
 	AutoGenerateCert bool   `yaml:"auto_generate_cert"` // Auto-generate self-signed certificates
 }
+
+// LocalConfig holds settings a collaborator chooses for itself. It is read
+// from the collaborator's own config file rather than the shared plan.
+type LocalConfig struct {
+	Privacy     PrivacyConfig  `yaml:"privacy"`
+	Attestation AttesterConfig `yaml:"attestation"`
+}
+
+// AttesterConfig has a collaborator in a trusted execution environment
+// present attestation evidence when it joins the federation
+type AttesterConfig struct {
+	Type    string   `yaml:"type"`    // Evidence type: sgx, sev-snp, nitro or a registered attester's
+	Command []string `yaml:"command"` // Writes the evidence to stdout; unset for registered attesters
+}
+
+// PrivacyConfig enables local differential privacy. The collaborator clips
+// the change its training made to the model and adds Gaussian noise to it
+// before submitting the update.
+type PrivacyConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	ClipNorm        float64 `yaml:"clip_norm"`        // Maximum L2 norm of the change to the model
+	NoiseMultiplier float64 `yaml:"noise_multiplier"` // Noise standard deviation as a multiple of clip_norm
+}
@@ -0,0 +1,266 @@
+package federation
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the plan format this version of fl-go reads and writes.
+// Plans without schema_version are version 1.
+const SchemaVersion = 2
+
+// schemaVersionKey names the plan's schema version
+const schemaVersionKey = "schema_version"
+
+// migrations[i] migrates a plan mapping from schema version i+1 to i+2 and
+// returns a note for each change it made
+var migrations = []func(plan *yaml.Node) []string{
+	migrateV1,
+}
+
+// CheckSchemaVersion reports whether this version of fl-go can load plans of
+// schema version v
+func CheckSchemaVersion(v int) error {
+	if v < 1 {
+		return fmt.Errorf("invalid schema_version %d", v)
+	}
+	if v > SchemaVersion {
+		return fmt.Errorf("plan schema_version %d is newer than the latest this fl-go supports (%d); upgrade fl-go", v, SchemaVersion)
+	}
+	return nil
+}
+
+// MigratePlanFile returns the plan at path migrated to SchemaVersion, along
+// with its original schema version and notes on what changed. Comments are
+// kept; references and extends are left as written.
+func MigratePlanFile(path string) ([]byte, int, []string, error) {
+	if err := validateFilePath(path); err != nil {
+		return nil, 0, nil, err
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - Path validated with whitelist above
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, 0, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, nil, fmt.Errorf("%s: plan is not a mapping", path)
+	}
+	from, notes, err := migratePlan(root.Content[0])
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, 0, nil, err
+	}
+	encoder.Close()
+	return buf.Bytes(), from, notes, nil
+}
+
+// migratePlan migrates a plan mapping in place to SchemaVersion and sets its
+// schema_version. It returns the plan's original version and the notes of
+// the migrations applied.
+func migratePlan(plan *yaml.Node) (int, []string, error) {
+	from := 1
+	if value := mappingValue(plan, schemaVersionKey); value != nil {
+		v, err := strconv.Atoi(value.Value)
+		if err != nil || value.Kind != yaml.ScalarNode {
+			return 0, nil, fmt.Errorf("line %d: schema_version must be an integer", value.Line)
+		}
+		from = v
+	}
+	if err := CheckSchemaVersion(from); err != nil {
+		return 0, nil, err
+	}
+
+	var notes []string
+	for v := from; v < SchemaVersion; v++ {
+		notes = append(notes, migrations[v-1](plan)...)
+	}
+	removeKey(plan, schemaVersionKey)
+	plan.Content = append([]*yaml.Node{scalarNode(schemaVersionKey), scalarNode(strconv.Itoa(SchemaVersion))}, plan.Content...)
+	return from, notes, nil
+}
+
+// migrateV1 moves the settings of the layouts older documentation described
+// to where fl-go reads them: the federation: section with host/port
+// addresses, algorithm type and parameters, and security.mtls.
+func migrateV1(plan *yaml.Node) []string {
+	var notes []string
+
+	if federation := mappingValue(plan, "federation"); federation != nil && federation.Kind == yaml.MappingNode {
+		removeKey(plan, "federation")
+		for i := 0; i+1 < len(federation.Content); i += 2 {
+			if mappingValue(plan, federation.Content[i].Value) == nil {
+				plan.Content = append(plan.Content, federation.Content[i], federation.Content[i+1])
+			}
+		}
+		notes = append(notes, "moved the settings of federation to the top level")
+
+		if name := removeKey(plan, "name"); name != nil {
+			monitoring := ensureMapping(plan, "monitoring")
+			if mappingValue(monitoring, "federation_id") == nil {
+				setKey(monitoring, "federation_id", name)
+			}
+			notes = append(notes, "moved name to monitoring.federation_id")
+		}
+		if removeKey(plan, "description") != nil {
+			notes = append(notes, "removed description")
+		}
+	}
+
+	if aggregator := mappingValue(plan, "aggregator"); aggregator != nil && aggregator.Kind == yaml.MappingNode {
+		if hostPortToAddress(aggregator) {
+			notes = append(notes, "joined aggregator.host and aggregator.port into aggregator.address")
+		}
+	}
+	if collaborators := mappingValue(plan, "collaborators"); collaborators != nil && collaborators.Kind == yaml.SequenceNode {
+		changed := false
+		for _, collab := range collaborators.Content {
+			if collab.Kind != yaml.MappingNode {
+				continue
+			}
+			changed = renameKey(collab, "name", "id") || changed
+			changed = hostPortToAddress(collab) || changed
+		}
+		if changed {
+			notes = append(notes, "renamed collaborator name to id and joined host and port into address")
+		}
+	}
+
+	if algorithm := mappingValue(plan, "algorithm"); algorithm != nil && algorithm.Kind == yaml.MappingNode {
+		if renameKey(algorithm, "type", "name") {
+			notes = append(notes, "renamed algorithm.type to algorithm.name")
+		}
+		if renameKey(algorithm, "parameters", "hyperparameters") {
+			notes = append(notes, "renamed algorithm.parameters to algorithm.hyperparameters")
+		}
+		if rounds := removeKey(algorithm, "rounds"); rounds != nil {
+			if mappingValue(plan, "rounds") == nil {
+				setKey(plan, "rounds", rounds)
+			}
+			notes = append(notes, "moved algorithm.rounds to rounds")
+		}
+		if epochs := removeKey(algorithm, "epochs_per_round"); epochs != nil {
+			args := ensureMapping(ensureMapping(ensureMapping(plan, "tasks"), "train"), "args")
+			if mappingValue(args, "epochs") == nil {
+				setKey(args, "epochs", epochs)
+			}
+			notes = append(notes, "moved algorithm.epochs_per_round to tasks.train.args.epochs")
+		}
+	}
+
+	if removeKey(plan, "model") != nil {
+		notes = append(notes, "removed model; the initial_model file defines the model")
+	}
+	if monitoring := mappingValue(plan, "monitoring"); monitoring != nil && monitoring.Kind == yaml.MappingNode {
+		if removeKey(monitoring, "config") != nil {
+			notes = append(notes, "removed monitoring.config")
+		}
+	}
+
+	if security := mappingValue(plan, "security"); security != nil && security.Kind == yaml.MappingNode {
+		if mtls := mappingValue(security, "mtls"); mtls != nil && mtls.Kind == yaml.MappingNode && mappingValue(security, "tls") == nil {
+			removeKey(security, "mtls")
+			renameKey(mtls, "ca_cert", "ca_path")
+			renameKey(mtls, "cert", "cert_path")
+			renameKey(mtls, "key", "key_path")
+			setKey(security, "tls", mtls)
+			notes = append(notes, "moved security.mtls to security.tls and renamed ca_cert, cert and key to ca_path, cert_path and key_path")
+		}
+	}
+	return notes
+}
+
+// hostPortToAddress replaces the host and port of m with address host:port.
+// It reports whether m changed.
+func hostPortToAddress(m *yaml.Node) bool {
+	host, port := mappingValue(m, "host"), mappingValue(m, "port")
+	if host == nil && port == nil {
+		return false
+	}
+	removeKey(m, "host")
+	removeKey(m, "port")
+	if mappingValue(m, "address") != nil {
+		return true
+	}
+	address := "localhost"
+	if host != nil {
+		address = host.Value
+	}
+	if port != nil {
+		address += ":" + port.Value
+	}
+	setKey(m, "address", scalarNode(address))
+	return true
+}
+
+// mappingValue returns the value of key in mapping m, or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeKey removes key from mapping m and returns its value, or nil
+func removeKey(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value := m.Content[i+1]
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// setKey sets key in mapping m to value
+func setKey(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalarNode(key), value)
+}
+
+// renameKey renames key from to to in mapping m unless to is already set. It
+// reports whether from was set.
+func renameKey(m *yaml.Node, from, to string) bool {
+	value := removeKey(m, from)
+	if value == nil {
+		return false
+	}
+	if mappingValue(m, to) == nil {
+		setKey(m, to, value)
+	}
+	return true
+}
+
+// ensureMapping returns the mapping at key in m, adding an empty one if needed
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	value := mappingValue(m, key)
+	if value == nil || value.Kind != yaml.MappingNode {
+		value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setKey(m, key, value)
+	}
+	return value
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
@@ -0,0 +1,353 @@
+package federation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigratePlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     string
+		want     string
+		wantFrom int
+		notes    []string
+	}{
+		{
+			name: "federation section",
+			plan: `
+federation:
+  name: mnist
+  description: MNIST demo
+  rounds: 5
+rounds: 3
+`,
+			want: `
+schema_version: 2
+rounds: 3
+monitoring:
+  federation_id: mnist
+`,
+			wantFrom: 1,
+			notes:    []string{"moved the settings of federation to the top level", "moved name to monitoring.federation_id", "removed description"},
+		},
+		{
+			name: "aggregator host and port",
+			plan: `
+aggregator:
+  host: 10.0.0.1
+  port: 50051
+`,
+			want: `
+schema_version: 2
+aggregator:
+  address: 10.0.0.1:50051
+`,
+			wantFrom: 1,
+			notes:    []string{"joined aggregator.host and aggregator.port into aggregator.address"},
+		},
+		{
+			name: "aggregator port only",
+			plan: `
+aggregator:
+  port: 50051
+`,
+			want: `
+schema_version: 2
+aggregator:
+  address: localhost:50051
+`,
+			wantFrom: 1,
+			notes:    []string{"joined aggregator.host and aggregator.port into aggregator.address"},
+		},
+		{
+			name: "collaborators",
+			plan: `
+collaborators:
+  - name: site-a
+    host: a.example.com
+    port: 50052
+  - id: site-b
+    address: b.example.com:50052
+`,
+			want: `
+schema_version: 2
+collaborators:
+  - id: site-a
+    address: a.example.com:50052
+  - id: site-b
+    address: b.example.com:50052
+`,
+			wantFrom: 1,
+			notes:    []string{"renamed collaborator name to id and joined host and port into address"},
+		},
+		{
+			name: "algorithm",
+			plan: `
+algorithm:
+  type: fedavg
+  parameters:
+    learning_rate: 0.1
+  rounds: 10
+  epochs_per_round: 2
+`,
+			want: `
+schema_version: 2
+algorithm:
+  name: fedavg
+  hyperparameters:
+    learning_rate: 0.1
+rounds: 10
+tasks:
+  train:
+    args:
+      epochs: 2
+`,
+			wantFrom: 1,
+			notes: []string{
+				"renamed algorithm.type to algorithm.name",
+				"renamed algorithm.parameters to algorithm.hyperparameters",
+				"moved algorithm.rounds to rounds",
+				"moved algorithm.epochs_per_round to tasks.train.args.epochs",
+			},
+		},
+		{
+			name: "moved settings keep the values already set",
+			plan: `
+rounds: 3
+algorithm:
+  type: fedavg
+  name: fedprox
+  rounds: 10
+  epochs_per_round: 2
+tasks:
+  train:
+    args:
+      epochs: 5
+`,
+			want: `
+schema_version: 2
+rounds: 3
+algorithm:
+  name: fedprox
+tasks:
+  train:
+    args:
+      epochs: 5
+`,
+			wantFrom: 1,
+			notes: []string{
+				"renamed algorithm.type to algorithm.name",
+				"moved algorithm.rounds to rounds",
+				"moved algorithm.epochs_per_round to tasks.train.args.epochs",
+			},
+		},
+		{
+			name: "model and monitoring config",
+			plan: `
+model:
+  layers: [784, 10]
+monitoring:
+  enabled: true
+  config: monitoring.yaml
+`,
+			want: `
+schema_version: 2
+monitoring:
+  enabled: true
+`,
+			wantFrom: 1,
+			notes:    []string{"removed model; the initial_model file defines the model", "removed monitoring.config"},
+		},
+		{
+			name: "security mtls",
+			plan: `
+security:
+  mtls:
+    ca_cert: ca.pem
+    cert: agg.pem
+    key: agg.key
+`,
+			want: `
+schema_version: 2
+security:
+  tls:
+    ca_path: ca.pem
+    cert_path: agg.pem
+    key_path: agg.key
+`,
+			wantFrom: 1,
+			notes:    []string{"moved security.mtls to security.tls and renamed ca_cert, cert and key to ca_path, cert_path and key_path"},
+		},
+		{
+			name: "explicit version 1",
+			plan: `
+schema_version: 1
+rounds: 3
+`,
+			want: `
+schema_version: 2
+rounds: 3
+`,
+			wantFrom: 1,
+		},
+		{
+			name: "already current",
+			plan: `
+schema_version: 2
+rounds: 3
+aggregator:
+  address: localhost:50051
+algorithm:
+  name: fedavg
+`,
+			want: `
+schema_version: 2
+rounds: 3
+aggregator:
+  address: localhost:50051
+algorithm:
+  name: fedavg
+`,
+			wantFrom: 2,
+		},
+		{
+			name: "already current plans are not migrated",
+			plan: `
+schema_version: 2
+model:
+  layers: [784, 10]
+`,
+			want: `
+schema_version: 2
+model:
+  layers: [784, 10]
+`,
+			wantFrom: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := parseMapping(t, tt.plan)
+			from, notes, err := migratePlan(plan)
+			if err != nil {
+				t.Fatalf("migratePlan() error = %v", err)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("migratePlan() from = %d, want %d", from, tt.wantFrom)
+			}
+			if !reflect.DeepEqual(notes, tt.notes) {
+				t.Errorf("migratePlan() notes = %q, want %q", notes, tt.notes)
+			}
+			if got, want := plainValue(t, plan), plainValue(t, parseMapping(t, tt.want)); !reflect.DeepEqual(got, want) {
+				t.Errorf("migratePlan() plan = %v, want %v", got, want)
+			}
+			if plan.Content[0].Value != schemaVersionKey {
+				t.Errorf("migratePlan() put %s first, want %s", plan.Content[0].Value, schemaVersionKey)
+			}
+		})
+	}
+}
+
+func TestMigratePlanVersionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want string
+	}{
+		{name: "newer than supported", plan: "schema_version: 3\n", want: "newer than the latest"},
+		{name: "zero", plan: "schema_version: 0\n", want: "invalid schema_version 0"},
+		{name: "not an integer", plan: "schema_version: two\n", want: "must be an integer"},
+		{name: "not a scalar", plan: "schema_version: [2]\n", want: "must be an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := parseMapping(t, tt.plan)
+			if _, _, err := migratePlan(plan); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("migratePlan() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratePlanFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.yaml")
+	plan := `# Legacy plan
+aggregator:
+  host: localhost # the aggregator runs locally
+  port: 50051
+rounds: 3
+`
+	if err := os.WriteFile(path, []byte(plan), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, from, notes, err := MigratePlanFile(path)
+	if err != nil {
+		t.Fatalf("MigratePlanFile() error = %v", err)
+	}
+	if from != 1 || len(notes) != 1 {
+		t.Errorf("MigratePlanFile() from = %d, notes = %q, want version 1 and one note", from, notes)
+	}
+	if !strings.Contains(string(data), "# Legacy plan") {
+		t.Errorf("MigratePlanFile() dropped the comments:\n%s", data)
+	}
+	if !strings.HasPrefix(string(data), "schema_version: 2\n") {
+		t.Errorf("MigratePlanFile() = %q, want schema_version first", data)
+	}
+
+	// The migrated plan is current and migrates to itself
+	current := filepath.Join(dir, "current.yaml")
+	if err := os.WriteFile(current, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	again, from, notes, err := MigratePlanFile(current)
+	if err != nil || from != SchemaVersion || len(notes) != 0 || string(again) != string(data) {
+		t.Errorf("MigratePlanFile(migrated) = %q, %d, %q, %v, want the plan unchanged", again, from, notes, err)
+	}
+
+	notMapping := filepath.Join(dir, "list.yaml")
+	if err := os.WriteFile(notMapping, []byte("- a\n- b\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := MigratePlanFile(notMapping); err == nil || !strings.Contains(err.Error(), "not a mapping") {
+		t.Errorf("MigratePlanFile(list) error = %v, want not a mapping", err)
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	for v, wantErr := range map[int]bool{-1: true, 0: true, 1: false, SchemaVersion: false, SchemaVersion + 1: true} {
+		if err := CheckSchemaVersion(v); (err != nil) != wantErr {
+			t.Errorf("CheckSchemaVersion(%d) error = %v, want error %v", v, err, wantErr)
+		}
+	}
+}
+
+// parseMapping parses YAML text into its top-level mapping node
+func parseMapping(t *testing.T, text string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &root); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	return root.Content[0]
+}
+
+// plainValue decodes a node into plain values, so that plans compare without
+// regard to key order or style
+func plainValue(t *testing.T, node *yaml.Node) map[string]interface{} {
+	t.Helper()
+	var value map[string]interface{}
+	if err := node.Decode(&value); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return value
+}
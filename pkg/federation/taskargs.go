@@ -0,0 +1,89 @@
+package federation
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// CollaboratorTasks returns the plan's tasks with the task args of a
+// collaborator merged over the shared ones. The plan itself is left alone, so
+// that its fingerprint stays the one the aggregator computes.
+func CollaboratorTasks(plan *FLPlan, collaboratorID string) TasksConfig {
+	tasks := plan.Tasks
+	overrides := TaskArgsOf(plan, collaboratorID)
+	tasks.Train.Args = MergeArgs(tasks.Train.Args, overrides.Train)
+	tasks.Evaluate.Args = MergeArgs(tasks.Evaluate.Args, overrides.Evaluate)
+	return tasks
+}
+
+// TaskArgsOf returns the task args a plan sets for one collaborator, which
+// are empty for collaborators outside the plan
+func TaskArgsOf(plan *FLPlan, collaboratorID string) TaskArgsConfig {
+	for _, collab := range plan.Collaborators {
+		if collab.ID == collaboratorID {
+			return collab.TaskArgs
+		}
+	}
+	return TaskArgsConfig{}
+}
+
+// MergeArgs returns a copy of args with overrides merged over them, or args
+// itself when there are no overrides
+func MergeArgs(args, overrides map[string]interface{}) map[string]interface{} {
+	if len(overrides) == 0 {
+		return args
+	}
+	merged := make(map[string]interface{}, len(args)+len(overrides))
+	maps.Copy(merged, args)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// Number returns a numeric plan value, such as a task arg or an algorithm
+// hyperparameter. YAML decodes whole numbers as ints and others as float64s.
+func Number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// ValidateTaskArgs checks the task args of each collaborator. Values must be
+// scalars, since they are passed as command line flags, scheduled train args
+// are set by the aggregator in every round, and evaluators never train.
+func ValidateTaskArgs(plan *FLPlan) error {
+	for _, collab := range plan.Collaborators {
+		overrides := collab.TaskArgs
+		if len(overrides.Train) > 0 && collab.Role == CollaboratorEvaluator {
+			return fmt.Errorf("collaborator %s is an evaluator and never trains; set task_args.evaluate instead of task_args.train", collab.ID)
+		}
+		for _, task := range []struct {
+			name string
+			args map[string]interface{}
+		}{{"train", overrides.Train}, {"evaluate", overrides.Evaluate}} {
+			keys := make([]string, 0, len(task.args))
+			for key := range task.args {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				switch task.args[key].(type) {
+				case string, int, float64, bool:
+				default:
+					return fmt.Errorf("collaborator %s: task_args.%s.%s must be a string, number or boolean", collab.ID, task.name, key)
+				}
+				if _, scheduled := plan.Tasks.Train.Schedule[key]; scheduled && task.name == "train" {
+					return fmt.Errorf("collaborator %s: task_args.train.%s is scheduled by tasks.train.schedule and cannot be overridden", collab.ID, key)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,407 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// distinctUpdates is how many different updates the clients submit.
+	// Clients share them round robin, so that the load generator's memory
+	// does not grow with the client count.
+	distinctUpdates = 2
+	// roundPollInterval is how often the load test asks the aggregator
+	// whether the next round started
+	roundPollInterval = 50 * time.Millisecond
+	// maxRetryDelay caps the backoff of a client whose submission failed
+	maxRetryDelay = 2 * time.Second
+)
+
+// AggregatorConfig describes the load of an aggregator load test
+type AggregatorConfig struct {
+	Clients     int           `json:"clients"`     // simulated collaborators
+	ModelSize   int           `json:"model_size"`  // parameters of the model and of each update
+	Rounds      int           `json:"rounds"`      // default 1
+	Concurrency int           `json:"concurrency"` // submissions in flight at once (default 64)
+	Connections int           `json:"connections"` // gRPC connections the clients share (default 8)
+	Algorithm   string        `json:"algorithm"`   // aggregation algorithm (default fedavg)
+	Streaming   bool          `json:"streaming"`   // aggregator.streaming of the plan
+	Timeout     time.Duration `json:"-"`           // the test fails when it takes longer (default 10 minutes)
+}
+
+func (c AggregatorConfig) withDefaults() AggregatorConfig {
+	if c.Rounds <= 0 {
+		c.Rounds = 1
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 64
+	}
+	if c.Connections <= 0 {
+		c.Connections = 8
+	}
+	c.Connections = min(c.Connections, c.Clients)
+	if c.Algorithm == "" {
+		c.Algorithm = string(aggregator.FedAvg)
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Minute
+	}
+	return c
+}
+
+// AggregatorRound is the measurement of one round
+type AggregatorRound struct {
+	Round            int     `json:"round"`
+	Submit           float64 `json:"submit_ms"`      // from the start of the round to the last acknowledged update
+	Aggregation      float64 `json:"aggregation_ms"` // from the last update to the aggregated model
+	UpdatesPerSecond float64 `json:"updates_per_second"`
+	BytesPerSecond   float64 `json:"bytes_per_second"`
+	Latency          Latency `json:"latency"` // of accepted submissions
+	Retries          int     `json:"retries"` // submissions that failed and were sent again
+}
+
+// AggregatorReport is the outcome of an aggregator load test
+type AggregatorReport struct {
+	Environment
+	Config           AggregatorConfig  `json:"config"`
+	UpdateBytes      int               `json:"update_bytes"`
+	Duration         float64           `json:"duration_ms"`
+	Accepted         int               `json:"accepted"`
+	Rejected         int               `json:"rejected"`         // updates the aggregator refused
+	Errors           map[string]int    `json:"errors,omitempty"` // failed calls by gRPC status code
+	UpdatesPerSecond float64           `json:"updates_per_second"`
+	BytesPerSecond   float64           `json:"bytes_per_second"`
+	Latency          Latency           `json:"latency"`
+	Rounds           []AggregatorRound `json:"rounds"`
+	// Memory of the whole process, which holds the aggregator and the load
+	// generator with its in-flight updates
+	Memory Memory `json:"memory"`
+}
+
+// RunAggregator starts an aggregator in process and loads it with the
+// simulated collaborators of config, which submit an update in every round
+// over gRPC. It calls progress after each round.
+//
+// The aggregator writes its models to a temporary working directory, so
+// RunAggregator changes the working directory while it runs.
+func RunAggregator(ctx context.Context, config AggregatorConfig, progress func(AggregatorRound)) (*AggregatorReport, error) {
+	if config.Clients < 1 || config.ModelSize < 1 {
+		return nil, fmt.Errorf("load test requires at least one client and parameter")
+	}
+	config = config.withDefaults()
+	if _, err := aggregator.CreateAggregationAlgorithm(aggregator.AlgorithmType(config.Algorithm)); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "fl-loadtest-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	defer os.Chdir(wd)
+
+	address, err := transport.FreeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port: %v", err)
+	}
+	plan := loadPlan(config, address)
+	if err := storage.WriteFile(plan.InitialModel, make([]byte, 4*config.ModelSize)); err != nil {
+		return nil, err
+	}
+
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, transport.DialOptions(plan.GRPC)...)
+	conns := make([]*grpc.ClientConn, config.Connections)
+	for i := range conns {
+		if conns[i], err = grpc.NewClient(address, dialOpts...); err != nil {
+			return nil, err
+		}
+		defer conns[i].Close()
+	}
+
+	memory := startMemorySampler()
+	aggCtx, stopAggregator := context.WithCancel(ctx)
+	agg := startAggregator(aggCtx, aggregator.NewAggregator(plan))
+	defer func() {
+		// It must not write to the working directory once it is removed
+		stopAggregator()
+		<-agg.done
+	}()
+
+	lt := &aggregatorLoad{
+		config:     config,
+		clients:    make([]pb.FederatedLearningClient, len(conns)),
+		updates:    randomUpdates(config.ModelSize),
+		aggregator: agg,
+		errors:     make(map[string]int),
+	}
+	for i, conn := range conns {
+		lt.clients[i] = pb.NewFederatedLearningClient(conn)
+	}
+
+	report := &AggregatorReport{
+		Environment: currentEnvironment(),
+		Config:      config,
+		UpdateBytes: 4 * config.ModelSize,
+	}
+	log.Printf("Load testing a %s aggregator with %d clients, %d parameters per update, for %d rounds",
+		config.Algorithm, config.Clients, config.ModelSize, config.Rounds)
+	start := time.Now()
+	var all []time.Duration
+	for round := 1; round <= config.Rounds; round++ {
+		result, durations, err := lt.round(ctx, round)
+		if err != nil {
+			memory.Stop()
+			return nil, err
+		}
+		all = append(all, durations...)
+		report.Rounds = append(report.Rounds, result)
+		if progress != nil {
+			progress(result)
+		}
+	}
+	report.Memory = memory.Stop()
+	elapsed := time.Since(start)
+
+	report.Duration = milliseconds(elapsed)
+	report.Accepted = len(all)
+	report.Rejected = lt.rejected
+	if len(lt.errors) > 0 {
+		report.Errors = lt.errors
+	}
+	report.UpdatesPerSecond = float64(report.Accepted) / elapsed.Seconds()
+	report.BytesPerSecond = report.UpdatesPerSecond * float64(report.UpdateBytes)
+	report.Latency = summarize(all)
+	return report, nil
+}
+
+// runningAggregator is an aggregator started in the background
+type runningAggregator struct {
+	done chan struct{} // closed when Start returned
+	err  error         // what Start returned
+}
+
+func startAggregator(ctx context.Context, agg aggregator.Aggregator) *runningAggregator {
+	r := &runningAggregator{done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		r.err = agg.Start(ctx)
+	}()
+	return r
+}
+
+// loadPlan returns the plan of the aggregator under test
+func loadPlan(config AggregatorConfig, address string) *federation.FLPlan {
+	plan := &federation.FLPlan{
+		Mode:         federation.ModeSync,
+		Rounds:       config.Rounds,
+		InitialModel: filepath.Join("models", "initial.pt"),
+		OutputModel:  filepath.Join("models", "final.pt"),
+		Aggregator:   federation.AggregatorEntry{Address: address, Streaming: config.Streaming},
+		Algorithm:    federation.AlgorithmConfig{Name: config.Algorithm},
+	}
+	for i := 0; i < config.Clients; i++ {
+		plan.Collaborators = append(plan.Collaborators, federation.Collaborator{ID: clientID(i)})
+	}
+	return plan
+}
+
+func clientID(i int) string {
+	return fmt.Sprintf("client-%d", i+1)
+}
+
+// aggregatorLoad is the state of a running aggregator load test
+type aggregatorLoad struct {
+	config     AggregatorConfig
+	clients    []pb.FederatedLearningClient // one per connection
+	updates    [][]byte
+	aggregator *runningAggregator
+
+	mu       sync.Mutex
+	rejected int
+	errors   map[string]int
+	retries  int
+}
+
+// round waits for the aggregator to start round, submits the update of every
+// client and waits for the aggregation
+func (lt *aggregatorLoad) round(ctx context.Context, round int) (AggregatorRound, []time.Duration, error) {
+	if err := lt.waitForRound(ctx, round); err != nil {
+		return AggregatorRound{}, nil, err
+	}
+
+	start := time.Now()
+	var accepted latencies
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(lt.config.Concurrency, lt.config.Clients); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if d, ok := lt.submit(ctx, i, round); ok {
+					accepted.Add(d)
+				}
+			}
+		}()
+	}
+	for i := 0; i < lt.config.Clients; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	submitted := time.Now()
+	if err := ctx.Err(); err != nil {
+		return AggregatorRound{}, nil, fmt.Errorf("round %d did not complete: %v", round, err)
+	}
+
+	durations := accepted.Take()
+	if len(durations) < lt.config.Clients {
+		// The round never reaches its quorum
+		return AggregatorRound{}, nil, fmt.Errorf("the aggregator refused %d of the %d updates of round %d",
+			lt.config.Clients-len(durations), lt.config.Clients, round)
+	}
+	if round < lt.config.Rounds {
+		if err := lt.waitForRound(ctx, round+1); err != nil {
+			return AggregatorRound{}, nil, err
+		}
+	} else if err := lt.waitForEnd(ctx); err != nil {
+		return AggregatorRound{}, nil, err
+	}
+
+	submit := submitted.Sub(start)
+	lt.mu.Lock()
+	retries := lt.retries
+	lt.retries = 0
+	lt.mu.Unlock()
+	updatesPerSecond := float64(len(durations)) / submit.Seconds()
+	return AggregatorRound{
+		Round:            round,
+		Submit:           milliseconds(submit),
+		Aggregation:      milliseconds(time.Since(submitted)),
+		UpdatesPerSecond: updatesPerSecond,
+		BytesPerSecond:   updatesPerSecond * float64(4*lt.config.ModelSize),
+		Latency:          summarize(durations),
+		Retries:          retries,
+	}, durations, nil
+}
+
+// submit sends the update of client i until the aggregator acknowledges it,
+// and returns the latency of the acknowledged call. Failed calls and updates
+// the aggregator asks to retry are sent again; refused updates are not.
+func (lt *aggregatorLoad) submit(ctx context.Context, i, round int) (time.Duration, bool) {
+	client := lt.clients[i%len(lt.clients)]
+	upd := &pb.ModelUpdate{
+		CollaboratorId: clientID(i),
+		ModelWeights:   lt.updates[i%len(lt.updates)],
+		NumSamples:     100,
+		Round:          int32(round), // #nosec G115 - rounds of a load test are small
+	}
+	delay := 100 * time.Millisecond
+	for {
+		callStart := time.Now()
+		ack, err := client.SubmitUpdate(ctx, upd)
+		latency := time.Since(callStart)
+		switch {
+		case err == nil && ack.Success:
+			return latency, true
+		case err == nil && ack.Status != pb.AckStatus_ACK_RETRY_AFTER:
+			lt.mu.Lock()
+			lt.rejected++
+			lt.mu.Unlock()
+			log.Printf("Update of %s in round %d was refused: %s", upd.CollaboratorId, round, ack.Message)
+			return 0, false
+		case err != nil:
+			if ctx.Err() != nil {
+				return 0, false
+			}
+			lt.mu.Lock()
+			lt.errors[status.Code(err).String()]++
+			lt.mu.Unlock()
+		}
+		lt.mu.Lock()
+		lt.retries++
+		lt.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return 0, false
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// waitForRound polls the aggregator until it is in round or a later one
+func (lt *aggregatorLoad) waitForRound(ctx context.Context, round int) error {
+	var version string
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, transport.DefaultCallTimeout)
+		resp, err := lt.clients[0].GetLatestModel(callCtx, &pb.GetModelRequest{CollaboratorId: clientID(0), IfVersion: version})
+		cancel()
+		if err == nil {
+			if int(resp.CurrentRound) >= round {
+				return nil
+			}
+			version = resp.ModelHash
+		}
+		select {
+		case <-lt.aggregator.done:
+			return fmt.Errorf("the aggregator stopped before round %d: %v", round, lt.aggregator.err)
+		case <-ctx.Done():
+			return fmt.Errorf("the aggregator did not start round %d: %v", round, ctx.Err())
+		case <-time.After(roundPollInterval):
+		}
+	}
+}
+
+// waitForEnd waits for the aggregator to finish the last round
+func (lt *aggregatorLoad) waitForEnd(ctx context.Context) error {
+	select {
+	case <-lt.aggregator.done:
+		if err := lt.aggregator.err; err != nil {
+			return fmt.Errorf("the aggregator failed: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the aggregator did not finish the last round: %v", ctx.Err())
+	}
+}
+
+// randomUpdates returns the encoded updates the clients share, drawn from a
+// fixed seed so that runs submit the same data
+func randomUpdates(size int) [][]byte {
+	rng := rand.New(rand.NewPCG(1, uint64(size))) // #nosec G115 - sizes are positive
+	updates := make([][]byte, distinctUpdates)
+	for u := range updates {
+		updates[u] = make([]byte, 4*size)
+		for i := 0; i < size; i++ {
+			binary.LittleEndian.PutUint32(updates[u][i*4:], math.Float32bits(rng.Float32()-0.5))
+		}
+	}
+	return updates
+}
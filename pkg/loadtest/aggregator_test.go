@@ -0,0 +1,47 @@
+package loadtest
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestRunAggregator(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs an aggregator")
+	}
+	for _, config := range []AggregatorConfig{
+		{Clients: 40, ModelSize: 1000, Rounds: 2, Concurrency: 8, Connections: 3},
+		{Clients: 10, ModelSize: 1000, Streaming: true},
+		{Clients: 10, ModelSize: 1000, Algorithm: "fedprox"},
+	} {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rounds []int
+		report, err := RunAggregator(context.Background(), config, func(r AggregatorRound) {
+			rounds = append(rounds, r.Round)
+		})
+		if err != nil {
+			t.Fatalf("RunAggregator(%+v) error = %v", config, err)
+		}
+		if after, _ := os.Getwd(); after != wd {
+			t.Errorf("RunAggregator() left the working directory at %s", after)
+		}
+
+		config = config.withDefaults()
+		if len(rounds) != config.Rounds || len(report.Rounds) != config.Rounds {
+			t.Errorf("reported rounds %v and %d round results, want %d", rounds, len(report.Rounds), config.Rounds)
+		}
+		if report.Accepted != config.Clients*config.Rounds || report.Rejected != 0 {
+			t.Errorf("accepted %d and rejected %d updates, want %d accepted", report.Accepted, report.Rejected, config.Clients*config.Rounds)
+		}
+		if report.Latency.Count != report.Accepted || report.Latency.Max <= 0 || report.UpdatesPerSecond <= 0 {
+			t.Errorf("latency %+v at %g updates/s", report.Latency, report.UpdatesPerSecond)
+		}
+		if report.Memory.PeakHeapInuseBytes == 0 {
+			t.Error("memory was not sampled")
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package loadtest drives FL-Go components with synthetic load to measure
+// their throughput, latency and memory. Reports are JSON so that runs can be
+// kept as artifacts and compared.
+package loadtest
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memorySampleInterval is how often the memory of the process is sampled
+const memorySampleInterval = 100 * time.Millisecond
+
+// Environment describes the machine a load test ran on
+type Environment struct {
+	Timestamp time.Time `json:"timestamp"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	CPUs      int       `json:"cpus"`
+}
+
+func currentEnvironment() Environment {
+	return Environment{
+		Timestamp: time.Now().UTC(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}
+}
+
+// Latency summarizes the latencies of successful calls, in milliseconds
+type Latency struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+	Mean  float64 `json:"mean_ms"`
+}
+
+// summarize returns the latency summary of durations, which it sorts
+func summarize(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	slices.Sort(durations)
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		i := int(p * float64(len(durations)-1))
+		return milliseconds(durations[i])
+	}
+	return Latency{
+		Count: len(durations),
+		P50:   percentile(0.50),
+		P95:   percentile(0.95),
+		P99:   percentile(0.99),
+		Max:   milliseconds(durations[len(durations)-1]),
+		Mean:  milliseconds(total / time.Duration(len(durations))),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// latencies collects call durations from concurrent callers
+type latencies struct {
+	mu        sync.Mutex
+	durations []time.Duration
+}
+
+func (l *latencies) Add(d time.Duration) {
+	l.mu.Lock()
+	l.durations = append(l.durations, d)
+	l.mu.Unlock()
+}
+
+// Take returns the collected durations and starts over
+func (l *latencies) Take() []time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	durations := l.durations
+	l.durations = nil
+	return durations
+}
+
+// Memory is the memory use of the process during a load test
+type Memory struct {
+	PeakHeapInuseBytes uint64  `json:"peak_heap_inuse_bytes"`
+	PeakSysBytes       uint64  `json:"peak_sys_bytes"` // obtained from the OS
+	PeakGoroutines     int     `json:"peak_goroutines"`
+	NumGC              uint32  `json:"num_gc"`
+	GCPauseTotal       float64 `json:"gc_pause_total_ms"`
+}
+
+// memorySampler records the peak memory use of the process until it is
+// stopped
+type memorySampler struct {
+	stop chan struct{}
+	done chan Memory
+}
+
+func startMemorySampler() *memorySampler {
+	s := &memorySampler{stop: make(chan struct{}), done: make(chan Memory, 1)}
+	var start runtime.MemStats
+	runtime.ReadMemStats(&start)
+	go func() {
+		var peak Memory
+		var mem runtime.MemStats
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			runtime.ReadMemStats(&mem)
+			peak.PeakHeapInuseBytes = max(peak.PeakHeapInuseBytes, mem.HeapInuse)
+			peak.PeakSysBytes = max(peak.PeakSysBytes, mem.Sys)
+			peak.PeakGoroutines = max(peak.PeakGoroutines, runtime.NumGoroutine())
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				peak.NumGC = mem.NumGC - start.NumGC
+				peak.GCPauseTotal = milliseconds(time.Duration(mem.PauseTotalNs - start.PauseTotalNs)) // #nosec G115 - pause totals are far below MaxInt64
+				s.done <- peak
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Stop stops sampling and returns the peaks
+func (s *memorySampler) Stop() Memory {
+	close(s.stop)
+	return <-s.done
+}
+
+// WriteReport writes a load test report as indented JSON
+func WriteReport(path string, report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,36 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	if got := summarize(nil); got != (Latency{}) {
+		t.Errorf("summarize(nil) = %+v, want the zero summary", got)
+	}
+
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	got := summarize(durations)
+	want := Latency{Count: 100, P50: 50, P95: 95, P99: 99, Max: 100, Mean: 50.5}
+	if got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemorySampler(t *testing.T) {
+	sampler := startMemorySampler()
+	buf := make([]byte, 8<<20)
+	buf[len(buf)-1] = 1
+	time.Sleep(2 * memorySampleInterval)
+	memory := sampler.Stop()
+	if memory.PeakHeapInuseBytes < uint64(len(buf)) || memory.PeakSysBytes < memory.PeakHeapInuseBytes {
+		t.Errorf("sampled %+v while holding %d bytes", memory, len(buf))
+	}
+	if memory.PeakGoroutines < 2 {
+		t.Errorf("peak goroutines = %d, want the sampler's and the test's", memory.PeakGoroutines)
+	}
+}
@@ -0,0 +1,546 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring/client"
+)
+
+const (
+	// monitorFlushInterval is how long a federation holds metrics before it
+	// sends a batch that is not full
+	monitorFlushInterval = time.Second
+	// monitorRegistrations is how many federations register at once before
+	// the load starts
+	monitorRegistrations = 16
+)
+
+// MonitorConfig describes the load of a monitoring API load test
+type MonitorConfig struct {
+	Server        string        `json:"server"`        // base URL of the monitoring server
+	APIKey        string        `json:"-"`             // of a monitor role
+	Federations   int           `json:"federations"`   // reporting at once (default 1)
+	Collaborators int           `json:"collaborators"` // per federation (default 10)
+	Rate          float64       `json:"rate"`          // metrics per second over all federations (default 1000)
+	BatchSize     int           `json:"batch_size"`    // metrics per ingest request (default 100)
+	QueueSize     int           `json:"queue_size"`    // metrics a federation holds while the server is slow before it drops new ones (default 10 batches)
+	Duration      time.Duration `json:"-"`             // of the load (default 1 minute)
+	Interval      time.Duration `json:"-"`             // between progress reports (default 10s)
+	Timeout       time.Duration `json:"-"`             // of each request (default 10s)
+}
+
+func (c MonitorConfig) withDefaults() MonitorConfig {
+	if c.Federations <= 0 {
+		c.Federations = 1
+	}
+	if c.Collaborators <= 0 {
+		c.Collaborators = 10
+	}
+	if c.Rate <= 0 {
+		c.Rate = 1000
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 10 * c.BatchSize
+	}
+	c.QueueSize = max(c.QueueSize, c.BatchSize)
+	if c.Duration <= 0 {
+		c.Duration = time.Minute
+	}
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	return c
+}
+
+// MonitorInterval is the measurement of one progress interval
+type MonitorInterval struct {
+	Elapsed        float64 `json:"elapsed_s"` // at the end of the interval
+	Sent           int     `json:"sent"`
+	Accepted       int     `json:"accepted"`
+	Dropped        int     `json:"dropped"`
+	ItemsPerSecond float64 `json:"items_per_second"` // accepted
+	Latency        Latency `json:"latency"`
+}
+
+// MonitorReport is the outcome of a monitoring API load test
+type MonitorReport struct {
+	Environment
+	Config         MonitorConfig     `json:"config"`
+	Run            string            `json:"run"` // prefix of the IDs of the generated federations, collaborators and rounds
+	Duration       float64           `json:"duration_ms"`
+	Sent           int               `json:"sent"`
+	Accepted       int               `json:"accepted"`
+	Rejected       int               `json:"rejected"`         // metrics the server failed to record
+	Failed         int               `json:"failed"`           // metrics in requests that failed
+	Shed           int               `json:"shed"`             // metrics dropped unsent because the queue was full
+	Dropped        int               `json:"dropped"`          // rejected, failed and shed metrics
+	DropRate       float64           `json:"drop_rate"`        // dropped share of the generated metrics
+	Errors         map[string]int    `json:"errors,omitempty"` // failed requests by HTTP status, or transport
+	ItemsPerSecond float64           `json:"items_per_second"` // accepted
+	Latency        Latency           `json:"latency"`          // of ingest requests the server processed
+	Intervals      []MonitorInterval `json:"intervals"`
+}
+
+// RunMonitor pushes the metrics of simulated federations at the monitoring
+// server of config for config.Duration and calls progress after each
+// interval of config.Interval. Every federation reports its rounds in order, like the
+// aggregator of a real federation: a round start, a model update and the
+// resources of each collaborator, the aggregation, a contribution score per
+// collaborator, an event and the round end. Failed requests are not retried
+// and a federation drops new metrics while its queue is full, so an
+// overloaded server shows as dropped metrics rather than a slower test.
+//
+// Federations and collaborators are registered before the load starts. When
+// ctx is done, the load stops early and the report covers the time it ran.
+func RunMonitor(ctx context.Context, config MonitorConfig, progress func(MonitorInterval)) (*MonitorReport, error) {
+	config = config.withDefaults()
+	if config.BatchSize > monitoring.MaxIngestBatchSize {
+		return nil, fmt.Errorf("batch size %d exceeds the server's maximum of %d", config.BatchSize, monitoring.MaxIngestBatchSize)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.Federations
+	defer transport.CloseIdleConnections()
+	c, err := client.New(client.Config{
+		Server:     config.Server,
+		APIKey:     config.APIKey,
+		MaxRetries: -1,
+		HTTPClient: &http.Client{Timeout: config.Timeout, Transport: transport},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Health(ctx); err != nil {
+		return nil, fmt.Errorf("monitoring server is not reachable: %w", err)
+	}
+
+	report := &MonitorReport{
+		Environment: currentEnvironment(),
+		Config:      config,
+		Run:         "loadtest-" + time.Now().UTC().Format("20060102-150405"),
+	}
+	rate := config.Rate / float64(config.Federations)
+	stats := &monitorStats{errors: map[string]int{}}
+	reporters := make([]*monitorReporter, config.Federations)
+	for i := range reporters {
+		reporters[i] = newMonitorReporter(c, config, stats, fmt.Sprintf("%s-fed-%d", report.Run, i+1), rate, uint64(i)) // #nosec G115 - indices are not negative
+	}
+	if err := registerReporters(ctx, c, reporters); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var all []time.Duration
+	var last, previous monitorCounts // at the end of the last interval and of the one before
+	lastTime, previousTime := start, start
+	var lastDurations []time.Duration
+	// takeInterval measures the time since the last interval. With merge, it
+	// extends the last interval instead, so that the final requests do not
+	// make up an interval of a few milliseconds.
+	takeInterval := func(merge bool) MonitorInterval {
+		now := time.Now()
+		counts := stats.snapshot()
+		durations := stats.latencies.Take()
+		all = append(all, durations...)
+		if merge {
+			report.Intervals = report.Intervals[:len(report.Intervals)-1]
+			last, lastTime = previous, previousTime
+			durations = append(lastDurations, durations...)
+		}
+		delta := counts.minus(last)
+		interval := MonitorInterval{
+			Elapsed:        now.Sub(start).Seconds(),
+			Sent:           delta.sent,
+			Accepted:       delta.accepted,
+			Dropped:        delta.dropped(),
+			ItemsPerSecond: float64(delta.accepted) / now.Sub(lastTime).Seconds(),
+			Latency:        summarize(durations),
+		}
+		previous, previousTime = last, lastTime
+		last, lastTime, lastDurations = counts, now, durations
+		report.Intervals = append(report.Intervals, interval)
+		return interval
+	}
+
+	stopIntervals := make(chan struct{})
+	intervalsDone := make(chan struct{})
+	go func() {
+		defer close(intervalsDone)
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				interval := takeInterval(false)
+				if progress != nil {
+					progress(interval)
+				}
+			case <-stopIntervals:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, r := range reporters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.run(ctx, start)
+		}()
+	}
+	wg.Wait()
+	close(stopIntervals)
+	<-intervalsDone
+	if counts := stats.snapshot(); counts != last {
+		// The rest of a short final interval is only in the report
+		merge := len(report.Intervals) > 0 && time.Since(lastTime) < config.Interval/2
+		interval := takeInterval(merge)
+		if progress != nil && !merge {
+			progress(interval)
+		}
+	}
+
+	elapsed := time.Since(start)
+	counts := stats.snapshot()
+	report.Duration = milliseconds(elapsed)
+	report.Sent = counts.sent
+	report.Accepted = counts.accepted
+	report.Rejected = counts.rejected
+	report.Failed = counts.failed
+	report.Shed = counts.shed
+	report.Dropped = counts.dropped()
+	if generated := counts.sent + counts.shed; generated > 0 {
+		report.DropRate = float64(report.Dropped) / float64(generated)
+	}
+	report.ItemsPerSecond = float64(counts.accepted) / elapsed.Seconds()
+	report.Latency = summarize(all)
+	if len(stats.errors) > 0 {
+		report.Errors = stats.errors
+	}
+	return report, nil
+}
+
+// registerReporters registers the federations of reporters and their
+// collaborators
+func registerReporters(ctx context.Context, c *client.Client, reporters []*monitorReporter) error {
+	errs := make([]error, len(reporters))
+	sem := make(chan struct{}, monitorRegistrations)
+	var wg sync.WaitGroup
+	for i, r := range reporters {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = r.register(ctx, c)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// monitorCounts are the metrics a load test sent, by outcome
+type monitorCounts struct {
+	sent, accepted, rejected, failed, shed int
+}
+
+func (c monitorCounts) minus(o monitorCounts) monitorCounts {
+	return monitorCounts{
+		sent:     c.sent - o.sent,
+		accepted: c.accepted - o.accepted,
+		rejected: c.rejected - o.rejected,
+		failed:   c.failed - o.failed,
+		shed:     c.shed - o.shed,
+	}
+}
+
+func (c monitorCounts) dropped() int {
+	return c.rejected + c.failed + c.shed
+}
+
+// monitorStats collects the outcomes of the requests of all federations
+type monitorStats struct {
+	mu        sync.Mutex
+	counts    monitorCounts
+	errors    map[string]int // failed requests by HTTP status
+	latencies latencies
+}
+
+func (s *monitorStats) snapshot() monitorCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts
+}
+
+// processed records a request the server processed
+func (s *monitorStats) processed(result *monitoring.IngestResult, sent int, latency time.Duration) {
+	s.mu.Lock()
+	s.counts.sent += sent
+	s.counts.accepted += result.Accepted
+	s.counts.rejected += result.Failed
+	s.mu.Unlock()
+	s.latencies.Add(latency)
+}
+
+// failed records a request that failed as a whole
+func (s *monitorStats) failed(sent int, reason string) {
+	s.mu.Lock()
+	s.counts.sent += sent
+	s.counts.failed += sent
+	s.errors[reason]++
+	s.mu.Unlock()
+}
+
+func (s *monitorStats) shed(n int) {
+	s.mu.Lock()
+	s.counts.shed += n
+	s.mu.Unlock()
+}
+
+// monitorReporter generates and sends the metrics of one simulated
+// federation
+type monitorReporter struct {
+	client        *client.Client
+	config        MonitorConfig
+	stats         *monitorStats
+	federation    string
+	collaborators []string
+	rate          float64 // metrics per second
+	rng           *rand.Rand
+	round         int
+	pending       []monitoring.IngestItem // the rest of the current round
+	queue         []monitoring.IngestItem // generated and not sent yet
+}
+
+func newMonitorReporter(c *client.Client, config MonitorConfig, stats *monitorStats, federation string, rate float64, seed uint64) *monitorReporter {
+	r := &monitorReporter{
+		client:     c,
+		config:     config,
+		stats:      stats,
+		federation: federation,
+		rate:       rate,
+		rng:        rand.New(rand.NewPCG(seed, 0)),
+	}
+	for i := 0; i < config.Collaborators; i++ {
+		r.collaborators = append(r.collaborators, fmt.Sprintf("%s-collaborator-%d", federation, i+1))
+	}
+	return r
+}
+
+func (r *monitorReporter) register(ctx context.Context, c *client.Client) error {
+	now := time.Now().UTC()
+	err := c.RegisterFederation(ctx, &monitoring.FederationMetrics{
+		ID:           r.federation,
+		Name:         r.federation,
+		Status:       monitoring.StatusRunning,
+		Mode:         "sync",
+		Algorithm:    "fedavg",
+		StartTime:    now,
+		TotalCollabs: len(r.collaborators),
+		LastUpdate:   now,
+		Labels:       map[string]string{"loadtest": "monitor"},
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range r.collaborators {
+		err := c.RegisterCollaborator(ctx, &monitoring.CollaboratorMetrics{
+			ID:           id,
+			FederationID: r.federation,
+			Status:       monitoring.CollabStatusConnected,
+			JoinTime:     now,
+			LastSeen:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run generates metrics at the rate of the reporter from start until the
+// duration of the load test passed, and sends them in batches
+func (r *monitorReporter) run(ctx context.Context, start time.Time) {
+	end := start.Add(r.config.Duration)
+	generated := 0
+	lastFlush := time.Now()
+	for ctx.Err() == nil {
+		now := time.Now()
+		if now.After(end) {
+			now = end
+		}
+		due := int(now.Sub(start).Seconds() * r.rate)
+		for ; generated < due; generated++ {
+			item := r.next()
+			if len(r.queue) >= r.config.QueueSize {
+				r.stats.shed(1)
+				continue
+			}
+			r.queue = append(r.queue, item)
+		}
+
+		finished := !now.Before(end)
+		if len(r.queue) >= r.config.BatchSize || (len(r.queue) > 0 && (finished || time.Since(lastFlush) >= monitorFlushInterval)) {
+			n := min(len(r.queue), r.config.BatchSize)
+			r.send(ctx, r.queue[:n])
+			r.queue = slices.Delete(r.queue, 0, n)
+			lastFlush = time.Now()
+			continue
+		}
+		if finished {
+			return
+		}
+
+		next := start.Add(time.Duration(float64(generated+1) / r.rate * float64(time.Second)))
+		wait := min(time.Until(next), time.Until(lastFlush.Add(monitorFlushInterval)), time.Until(end))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (r *monitorReporter) send(ctx context.Context, items []monitoring.IngestItem) {
+	began := time.Now()
+	result, err := r.client.PostMetrics(ctx, &monitoring.IngestBatch{Items: items})
+	latency := time.Since(began)
+
+	var apiErr *client.APIError
+	switch {
+	case result != nil:
+		// Processed, possibly with failed items
+		r.stats.processed(result, len(items), latency)
+	case ctx.Err() != nil:
+		// Interrupted; the server may or may not have recorded the batch
+	case errors.As(err, &apiErr):
+		r.stats.failed(len(items), strconv.Itoa(apiErr.StatusCode))
+	default:
+		r.stats.failed(len(items), "transport")
+	}
+}
+
+// next returns the next metric of the federation, starting a round when the
+// last one was reported
+func (r *monitorReporter) next() monitoring.IngestItem {
+	if len(r.pending) == 0 {
+		r.pending = r.roundItems()
+	}
+	item := r.pending[0]
+	r.pending = r.pending[1:]
+	return item
+}
+
+// roundItems returns the metrics of the next round in the order an
+// aggregator reports them. The round lasts as long as reporting its metrics
+// takes at the rate of the reporter.
+func (r *monitorReporter) roundItems() []monitoring.IngestItem {
+	r.round++
+	collabs := len(r.collaborators)
+	count := 3*collabs + 4
+	duration := time.Duration(float64(count) / r.rate * float64(time.Second))
+	start := time.Now().UTC()
+	end := start.Add(duration)
+	at := func(i int) time.Time {
+		return start.Add(duration * time.Duration(i+1) / time.Duration(collabs+2))
+	}
+
+	round := monitoring.RoundMetrics{
+		ID:               fmt.Sprintf("%s-round-%d", r.federation, r.round),
+		FederationID:     r.federation,
+		RoundNumber:      r.round,
+		Algorithm:        "fedavg",
+		StartTime:        start,
+		ParticipantCount: collabs,
+		Status:           "running",
+	}
+	items := make([]monitoring.IngestItem, 0, count)
+	items = append(items, monitorItem(monitoring.IngestRoundStart, "", round))
+
+	for i, id := range r.collaborators {
+		items = append(items, monitorItem(monitoring.IngestResource, id, monitoring.ResourceMetrics{
+			Timestamp:     at(i),
+			CPUUsage:      20 + 70*r.rng.Float64(),
+			MemoryUsage:   30 + 50*r.rng.Float64(),
+			MemoryUsed:    4<<30 + r.rng.Int64N(8<<30),
+			MemoryTotal:   16 << 30,
+			DiskUsage:     40 + 10*r.rng.Float64(),
+			NetworkRxRate: 100 * r.rng.Float64(),
+			NetworkTxRate: 100 * r.rng.Float64(),
+		}))
+		items = append(items, monitorItem(monitoring.IngestModelUpdate, "", monitoring.ModelUpdateMetrics{
+			FederationID:   r.federation,
+			CollaboratorID: id,
+			RoundNumber:    r.round,
+			Timestamp:      at(i),
+			UpdateSize:     4 << 20,
+			NumSamples:     1000 + r.rng.Int64N(9000),
+			ProcessingTime: 50 + 200*r.rng.Float64(),
+			Weight:         1 / float64(collabs),
+		}))
+	}
+
+	aggregationStart := at(collabs)
+	items = append(items, monitorItem(monitoring.IngestAggregation, "", monitoring.AggregationMetrics{
+		FederationID:      r.federation,
+		RoundNumber:       r.round,
+		Algorithm:         "fedavg",
+		StartTime:         aggregationStart,
+		EndTime:           end,
+		Duration:          end.Sub(aggregationStart),
+		UpdatesAggregated: collabs,
+	}))
+	for _, id := range r.collaborators {
+		items = append(items, monitorItem(monitoring.IngestContribution, "", monitoring.ContributionScore{
+			FederationID:   r.federation,
+			CollaboratorID: id,
+			RoundNumber:    r.round,
+			Timestamp:      end,
+			Cosine:         0.5 + 0.5*r.rng.Float64(),
+			Share:          (0.5 + r.rng.Float64()) / float64(collabs),
+		}))
+	}
+	items = append(items, monitorItem(monitoring.IngestEvent, "", monitoring.MonitoringEvent{
+		FederationID: r.federation,
+		Type:         monitoring.MetricTypeTraining,
+		Timestamp:    end,
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Round %d aggregated %d updates", r.round, collabs),
+	}))
+
+	// The model converges over the rounds
+	accuracy := 1 - 0.5*math.Exp(-0.1*float64(r.round))
+	loss := 2 * math.Exp(-0.1*float64(r.round))
+	round.EndTime = &end
+	round.Duration = duration
+	round.UpdatesReceived = collabs
+	round.AggregationTime = end.Sub(aggregationStart)
+	round.ModelAccuracy = &accuracy
+	round.ModelLoss = &loss
+	round.Status = "completed"
+	items = append(items, monitorItem(monitoring.IngestRoundEnd, "", round))
+	return items
+}
+
+func monitorItem(itemType monitoring.IngestItemType, source string, data interface{}) monitoring.IngestItem {
+	encoded, _ := json.Marshal(data) // metrics always encode
+	return monitoring.IngestItem{Type: itemType, Source: source, Data: encoded}
+}
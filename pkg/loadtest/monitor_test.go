@@ -0,0 +1,125 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func TestRunMonitor(t *testing.T) {
+	config := &monitoring.MonitoringConfig{
+		Auth: monitoring.AuthConfig{
+			Enabled:    true,
+			APIKeyAuth: monitoring.APIKeyConfig{Enabled: true, Keys: map[string]string{"monitor-key": monitoring.RoleMonitor}},
+		},
+	}
+	storage := monitoring.NewMemoryStorage(config)
+	server := httptest.NewServer(monitoring.NewAPIServer(storage, config).Handler())
+	defer server.Close()
+
+	var intervals int
+	report, err := RunMonitor(context.Background(), MonitorConfig{
+		Server:        server.URL,
+		APIKey:        "monitor-key",
+		Federations:   2,
+		Collaborators: 3,
+		Rate:          1000,
+		BatchSize:     50,
+		Duration:      time.Second,
+		Interval:      300 * time.Millisecond,
+	}, func(MonitorInterval) { intervals++ })
+	if err != nil {
+		t.Fatalf("RunMonitor() error = %v", err)
+	}
+
+	if report.Sent < 500 || report.Accepted != report.Sent || report.Dropped != 0 {
+		t.Errorf("RunMonitor() sent %d, accepted %d and dropped %d metrics, want about 1000 sent and all accepted",
+			report.Sent, report.Accepted, report.Dropped)
+	}
+	if report.Latency.Count == 0 || report.Latency.P99 <= 0 {
+		t.Errorf("RunMonitor() latency = %+v, want the latency of every request", report.Latency)
+	}
+	if intervals < 3 || len(report.Intervals) != intervals {
+		t.Errorf("RunMonitor() reported %d intervals and called progress %d times, want at least 3", len(report.Intervals), intervals)
+	}
+
+	ctx := context.Background()
+	federations, _ := storage.GetActiveFederations(ctx)
+	if len(federations) != 2 {
+		t.Fatalf("registered %d federations, want 2", len(federations))
+	}
+	// 3 collaborators give 13 metrics per round, so each federation reports
+	// more than 30 rounds in order
+	rounds, _ := storage.GetFederationRounds(ctx, federations[0].ID)
+	if len(rounds) < 10 || rounds[0].Status != "completed" {
+		t.Errorf("federation %s reported %d rounds, want completed rounds", federations[0].ID, len(rounds))
+	}
+}
+
+func TestRunMonitorDrops(t *testing.T) {
+	tests := []struct {
+		name   string
+		ingest http.HandlerFunc
+		check  func(t *testing.T, report *MonitorReport)
+	}{
+		{
+			name: "unavailable",
+			ingest: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(monitoring.APIResponse{Error: "overloaded"})
+			},
+			check: func(t *testing.T, report *MonitorReport) {
+				if report.Failed == 0 || report.Failed != report.Sent || report.Errors["503"] == 0 {
+					t.Errorf("failed %d of %d metrics with errors %v, want all in 503 responses", report.Failed, report.Sent, report.Errors)
+				}
+			},
+		},
+		{
+			name: "slow",
+			ingest: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				var batch monitoring.IngestBatch
+				json.NewDecoder(r.Body).Decode(&batch)
+				json.NewEncoder(w).Encode(monitoring.APIResponse{Success: true, Data: monitoring.IngestResult{Accepted: len(batch.Items)}})
+			},
+			check: func(t *testing.T, report *MonitorReport) {
+				if report.Shed == 0 || report.Accepted != report.Sent {
+					t.Errorf("shed %d metrics and accepted %d of %d, want metrics shed while the server was slow", report.Shed, report.Accepted, report.Sent)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/ingest" {
+					tt.ingest(w, r)
+					return
+				}
+				json.NewEncoder(w).Encode(monitoring.APIResponse{Success: true})
+			}))
+			defer server.Close()
+
+			report, err := RunMonitor(context.Background(), MonitorConfig{
+				Server:    server.URL,
+				Rate:      1000,
+				BatchSize: 10,
+				QueueSize: 20,
+				Duration:  time.Second,
+			}, nil)
+			if err != nil {
+				t.Fatalf("RunMonitor() error = %v", err)
+			}
+			if report.Dropped != report.Rejected+report.Failed+report.Shed || report.DropRate <= 0 {
+				t.Errorf("dropped %d metrics at rate %g, want rejected, failed and shed metrics summed", report.Dropped, report.DropRate)
+			}
+			tt.check(t, report)
+		})
+	}
+}
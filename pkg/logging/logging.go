@@ -0,0 +1,67 @@
+// Package logging filters the log lines of long-running processes by level.
+// Lines logged with the standard log package directly are always written;
+// Debugf and Infof lines only at or above the current level, which an
+// operator can change while the process runs.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// Level is the verbosity of the log
+type Level string
+
+const (
+	LevelDebug Level = "debug" // adds details, such as the collaborator and samples of each sync update
+	LevelInfo  Level = "info"  // the default
+	LevelWarn  Level = "warn"  // hides the per-update progress lines of busy federations
+)
+
+// rank orders the levels from the most verbose
+var rank = map[Level]int{LevelDebug: 0, LevelInfo: 1, LevelWarn: 2}
+
+var current atomic.Value // Level
+
+func init() {
+	current.Store(LevelInfo)
+}
+
+// ParseLevel returns the level named s
+func ParseLevel(s string) (Level, error) {
+	level := Level(s)
+	if _, ok := rank[level]; !ok {
+		return "", fmt.Errorf("unknown log level %q (want debug, info or warn)", s)
+	}
+	return level, nil
+}
+
+// SetLevel changes the level and returns the previous one
+func SetLevel(level Level) Level {
+	return current.Swap(level).(Level)
+}
+
+// CurrentLevel returns the level
+func CurrentLevel() Level {
+	return current.Load().(Level)
+}
+
+// Enabled reports whether lines of level are written
+func Enabled(level Level) bool {
+	return rank[level] >= rank[CurrentLevel()]
+}
+
+// Debugf logs a line at the debug level
+func Debugf(format string, args ...interface{}) {
+	if Enabled(LevelDebug) {
+		log.Printf(format, args...)
+	}
+}
+
+// Infof logs a line at the info level
+func Infof(format string, args ...interface{}) {
+	if Enabled(LevelInfo) {
+		log.Printf(format, args...)
+	}
+}
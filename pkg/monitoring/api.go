@@ -1,8 +1,9 @@
 package monitoring
 
 import (
-	"context"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
 	"github.com/rs/cors"
 )
 
@@ -21,17 +23,28 @@ type APIServer struct {
 	config   *MonitoringConfig
 	router   *mux.Router
 	upgrader websocket.Upgrader
+	auth     *AuthManager
+	authErr  error
+	models   *registry.Registry
+	modelErr error
+	limiter  *rateLimiter // nil unless rate limiting is enabled
+
+	controller FederationController // pauses, resumes, aborts and inspects federations
 }
 
 // NewAPIServer creates a new API server instance
 func NewAPIServer(service MonitoringService, config *MonitoringConfig) *APIServer {
 	server := &APIServer{
-		service: service,
+		service: newTenantService(newProvisioningService(service, config.Dashboards)),
 		config:  config,
 		router:  mux.NewRouter(),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
+				// Only browsers send an Origin; other clients are authenticated like any request
 				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
 				allowedOrigins := []string{"http://localhost:3000", "http://localhost:8080", "http://127.0.0.1:3000", "http://127.0.0.1:8080"}
 				if config.Production {
 					allowedOrigins = config.AllowedOrigins
@@ -48,12 +61,52 @@ func NewAPIServer(service MonitoringService, config *MonitoringConfig) *APIServe
 		},
 	}
 
+	server.controller = &grpcController{config: config.Control}
+
+	server.auth, server.authErr = NewAuthManager(config.Auth)
+	if server.authErr != nil {
+		log.Printf("Failed to initialize authentication: %v", server.authErr)
+	}
+	if server.auth != nil {
+		server.auth.SetKeyStore(server.service)
+	}
+	if config.Auth.Enabled {
+		log.Printf("API authentication enabled (api_key=%v, jwt=%v)",
+			config.Auth.APIKeyAuth.Enabled, config.Auth.JWTAuth.Enabled)
+	}
+
+	if config.RateLimit.Enabled {
+		server.limiter = newRateLimiter(config.RateLimit)
+		log.Printf("API rate limiting enabled (%g requests/s, daily quota %d)",
+			config.RateLimit.RequestsPerSecond, config.RateLimit.DailyQuota)
+	}
+
+	if config.Registry.Enabled {
+		server.models, server.modelErr = registry.Open(config.Registry)
+		if server.modelErr != nil {
+			log.Printf("Failed to open model registry: %v", server.modelErr)
+		}
+	}
+
 	server.setupRoutes()
 	return server
 }
 
+// Handler returns the routes of the server, without the CORS handling added
+// by Start
+func (s *APIServer) Handler() http.Handler {
+	return s.router
+}
+
 // Start starts the API server
 func (s *APIServer) Start() error {
+	if s.authErr != nil {
+		return fmt.Errorf("authentication is misconfigured: %w", s.authErr)
+	}
+	if s.modelErr != nil {
+		return fmt.Errorf("model registry is misconfigured: %w", s.modelErr)
+	}
+
 	// Setup CORS with secure defaults
 	allowedOrigins := []string{"http://localhost:3000", "http://localhost:8080", "http://127.0.0.1:3000", "http://127.0.0.1:8080"}
 	if s.config.Production {
@@ -64,88 +117,222 @@ func (s *APIServer) Start() error {
 	c := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "X-Requested-With"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "X-Requested-With", "Idempotency-Key"},
+		ExposedHeaders:   []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300, // 5 minutes
 	})
 
 	handler := c.Handler(s.router)
 
+	if s.config.GRPCPort > 0 {
+		if err := s.startGRPC(); err != nil {
+			return err
+		}
+	}
+
 	addr := fmt.Sprintf(":%d", s.config.APIPort)
-	log.Printf("Starting monitoring API server on %s", addr)
+	server := s.newHTTPServer(addr, handler)
+
+	if !s.config.TLS.Enabled {
+		log.Printf("Starting monitoring API server on %s", addr)
+		return server.ListenAndServe()
+	}
+
+	tlsConfig, err := s.config.TLS.LoadTLSConfig()
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	server.TLSConfig = tlsConfig
 
-	return http.ListenAndServe(addr, handler)
+	if port := s.config.TLS.HTTPRedirectPort; port > 0 {
+		redirectAddr := fmt.Sprintf(":%d", port)
+		redirect := s.newHTTPServer(redirectAddr, httpsRedirectHandler(s.config.APIPort))
+		go func() {
+			log.Printf("Redirecting HTTP on %s to HTTPS", redirectAddr)
+			if err := redirect.ListenAndServe(); err != nil {
+				log.Printf("HTTP redirect server error: %v", err)
+			}
+		}()
+	}
+
+	log.Printf("Starting monitoring API server on %s (HTTPS)", addr)
+	return server.ListenAndServeTLS("", "")
 }
 
 // setupRoutes configures all API routes
 func (s *APIServer) setupRoutes() {
+	s.router.Use(compressResponses, etagResponses)
 	api := s.router.PathPrefix("/api/v1").Subrouter()
 
-	// Health check
+	// Health check is always public
 	api.HandleFunc("/health", s.handleHealth).Methods("GET")
-	api.HandleFunc("/stats", s.handleStats).Methods("GET")
+
+	// API description, also public so that clients can be generated from it
+	api.HandleFunc("/openapi.json", s.handleOpenAPI).Methods("GET")
+	api.HandleFunc("/docs", s.handleAPIDocs).Methods("GET")
+
+	api.Handle("/stats", s.withRole(RoleReadOnly, s.handleStats)).Methods("GET")
+	api.Handle("/ingest", s.withRole(RoleMonitor, s.audit(AuditMetricsIngest, s.handleIngest))).Methods("POST")
+	api.Handle("/import", s.withRole(RoleMonitor, s.audit(AuditHistoryImport, s.handleImport))).Methods("POST")
+
+	// Authentication endpoints
+	api.Handle("/auth/token", s.withRole(RoleReadOnly, s.audit(AuditTokenIssue, s.handleIssueToken))).Methods("POST")
+
+	// API key management endpoints
+	apiKeys := api.PathPrefix("/apikeys").Subrouter()
+	apiKeys.Handle("", s.withRole(RoleAdmin, s.handleListAPIKeys)).Methods("GET")
+	apiKeys.Handle("", s.withRole(RoleAdmin, s.audit(AuditAPIKeyCreate, s.handleCreateAPIKey))).Methods("POST")
+	apiKeys.Handle("/{id}", s.withRole(RoleAdmin, s.audit(AuditAPIKeyRevoke, s.handleRevokeAPIKey))).Methods("DELETE")
+	apiKeys.Handle("/{id}/rotate", s.withRole(RoleAdmin, s.audit(AuditAPIKeyRotate, s.handleRotateAPIKey))).Methods("POST")
+
+	// Notification subscriptions; webhook URLs often carry credentials, so
+	// they are only shown to admins
+	notifications := api.PathPrefix("/notifications").Subrouter()
+	notifications.Handle("", s.withRole(RoleAdmin, s.handleListNotifications)).Methods("GET")
+	notifications.Handle("", s.withRole(RoleAdmin, s.audit(AuditNotificationCreate, s.handleCreateNotification))).Methods("POST")
+	notifications.Handle("/{id}", s.withRole(RoleAdmin, s.handleGetNotification)).Methods("GET")
+	notifications.Handle("/{id}", s.withRole(RoleAdmin, s.audit(AuditNotificationUpdate, s.handleUpdateNotification))).Methods("PUT")
+	notifications.Handle("/{id}", s.withRole(RoleAdmin, s.audit(AuditNotificationDelete, s.handleDeleteNotification))).Methods("DELETE")
+
+	// Audit log
+	api.Handle("/audit", s.withRole(RoleAdmin, s.handleListAudit)).Methods("GET")
 
 	// Federation endpoints
 	federations := api.PathPrefix("/federations").Subrouter()
-	federations.HandleFunc("", s.handleListFederations).Methods("GET")
-	federations.HandleFunc("", s.handleCreateFederation).Methods("POST")
-	federations.HandleFunc("/{id}", s.handleGetFederation).Methods("GET")
-	federations.HandleFunc("/{id}", s.handleUpdateFederation).Methods("PUT")
-	federations.HandleFunc("/{id}/overview", s.handleGetSystemOverview).Methods("GET")
-	federations.HandleFunc("/{id}/insights", s.handleGetPerformanceInsights).Methods("GET")
-	federations.HandleFunc("/{id}/convergence", s.handleGetConvergenceAnalysis).Methods("GET")
-	federations.HandleFunc("/{id}/efficiency", s.handleGetEfficiencyMetrics).Methods("GET")
+	federations.Handle("", s.withRole(RoleReadOnly, s.handleListFederations)).Methods("GET")
+	federations.Handle("", s.withRole(RoleMonitor, s.audit(AuditFederationCreate, s.handleCreateFederation))).Methods("POST")
+	federations.Handle("/{id}", s.withRole(RoleReadOnly, s.handleGetFederation)).Methods("GET")
+	federations.Handle("/{id}", s.withRole(RoleMonitor, s.audit(AuditFederationUpdate, s.handleUpdateFederation))).Methods("PUT")
+	federations.Handle("/{id}/overview", s.withRole(RoleReadOnly, s.handleGetSystemOverview)).Methods("GET")
+	federations.Handle("/{id}/insights", s.withRole(RoleReadOnly, s.handleGetPerformanceInsights)).Methods("GET")
+	federations.Handle("/{id}/convergence", s.withRole(RoleReadOnly, s.handleGetConvergenceAnalysis)).Methods("GET")
+	federations.Handle("/{id}/efficiency", s.withRole(RoleReadOnly, s.handleGetEfficiencyMetrics)).Methods("GET")
+	federations.Handle("/{id}/timeseries", s.withRole(RoleReadOnly, s.handleGetTimeSeries)).Methods("GET")
+	federations.Handle("/{id}/topology", s.withRole(RoleReadOnly, s.handleGetTopology)).Methods("GET")
+	federations.Handle("/{id}/compare/{other}", s.withRole(RoleReadOnly, s.handleCompareFederations)).Methods("GET")
+	federations.Handle("/{id}/pause", s.withRole(RoleAdmin, s.audit(AuditFederationPause, s.handleControlFederation(ControlPause)))).Methods("POST")
+	federations.Handle("/{id}/resume", s.withRole(RoleAdmin, s.audit(AuditFederationResume, s.handleControlFederation(ControlResume)))).Methods("POST")
+	federations.Handle("/{id}/abort", s.withRole(RoleAdmin, s.audit(AuditFederationAbort, s.handleControlFederation(ControlAbort)))).Methods("POST")
+	federations.Handle("/{id}/aggregator", s.withRole(RoleAdmin, s.handleGetAggregatorStatus)).Methods("GET")
+	federations.Handle("/{id}/export/{dataset}", s.withRole(RoleReadOnly, s.handleExport)).Methods("GET")
+	federations.Handle("/{id}/import/{dataset}", s.withRole(RoleMonitor, s.audit(AuditHistoryImport, s.handleImportCSV))).Methods("POST")
 
 	// Collaborator endpoints
 	collaborators := api.PathPrefix("/collaborators").Subrouter()
-	collaborators.HandleFunc("", s.handleListCollaborators).Methods("GET")
-	collaborators.HandleFunc("", s.handleCreateCollaborator).Methods("POST")
-	collaborators.HandleFunc("/{id}", s.handleGetCollaborator).Methods("GET")
-	collaborators.HandleFunc("/{id}", s.handleUpdateCollaborator).Methods("PUT")
+	collaborators.Handle("", s.withRole(RoleReadOnly, s.handleListCollaborators)).Methods("GET")
+	collaborators.Handle("", s.withRole(RoleMonitor, s.audit(AuditCollaboratorCreate, s.handleCreateCollaborator))).Methods("POST")
+	collaborators.Handle("/{id}", s.withRole(RoleReadOnly, s.handleGetCollaborator)).Methods("GET")
+	collaborators.Handle("/{id}", s.withRole(RoleMonitor, s.audit(AuditCollaboratorUpdate, s.handleUpdateCollaborator))).Methods("PUT")
 
 	// Round endpoints
 	rounds := api.PathPrefix("/rounds").Subrouter()
-	rounds.HandleFunc("", s.handleListRounds).Methods("GET")
-	rounds.HandleFunc("", s.handleCreateRound).Methods("POST")
-	rounds.HandleFunc("/{id}", s.handleGetRound).Methods("GET")
-	rounds.HandleFunc("/{id}", s.handleUpdateRound).Methods("PUT")
+	rounds.Handle("", s.withRole(RoleReadOnly, s.handleListRounds)).Methods("GET")
+	rounds.Handle("", s.withRole(RoleMonitor, s.audit(AuditRoundCreate, s.handleCreateRound))).Methods("POST")
+	rounds.Handle("/{id}", s.withRole(RoleReadOnly, s.handleGetRound)).Methods("GET")
+	rounds.Handle("/{id}", s.withRole(RoleMonitor, s.audit(AuditRoundUpdate, s.handleUpdateRound))).Methods("PUT")
 
 	// Model update endpoints
 	updates := api.PathPrefix("/updates").Subrouter()
-	updates.HandleFunc("", s.handleListModelUpdates).Methods("GET")
-	updates.HandleFunc("", s.handleCreateModelUpdate).Methods("POST")
-	updates.HandleFunc("/statistics", s.handleGetUpdateStatistics).Methods("GET")
+	updates.Handle("", s.withRole(RoleReadOnly, s.handleListModelUpdates)).Methods("GET")
+	updates.Handle("", s.withRole(RoleMonitor, s.audit(AuditUpdateCreate, s.handleCreateModelUpdate))).Methods("POST")
+	updates.Handle("/statistics", s.withRole(RoleReadOnly, s.handleGetUpdateStatistics)).Methods("GET")
+	updates.Handle("/dropped", s.withRole(RoleReadOnly, s.handleListDroppedUpdates)).Methods("GET")
+	updates.Handle("/dropped", s.withRole(RoleMonitor, s.audit(AuditDroppedCreate, s.handleCreateDroppedUpdate))).Methods("POST")
 
 	// Aggregation endpoints
 	aggregations := api.PathPrefix("/aggregations").Subrouter()
-	aggregations.HandleFunc("", s.handleListAggregations).Methods("GET")
-	aggregations.HandleFunc("", s.handleCreateAggregation).Methods("POST")
-	aggregations.HandleFunc("/statistics", s.handleGetAggregationStatistics).Methods("GET")
+	aggregations.Handle("", s.withRole(RoleReadOnly, s.handleListAggregations)).Methods("GET")
+	aggregations.Handle("", s.withRole(RoleMonitor, s.audit(AuditAggregationCreate, s.handleCreateAggregation))).Methods("POST")
+	aggregations.Handle("/statistics", s.withRole(RoleReadOnly, s.handleGetAggregationStatistics)).Methods("GET")
+	aggregations.Handle("/{id}", s.withRole(RoleReadOnly, s.handleGetAggregation)).Methods("GET")
+	aggregations.Handle("/{id}", s.withRole(RoleMonitor, s.audit(AuditAggregationUpdate, s.handleUpdateAggregation))).Methods("PUT")
+
+	// Contribution score endpoints
+	contributions := api.PathPrefix("/contributions").Subrouter()
+	contributions.Handle("", s.withRole(RoleReadOnly, s.handleListContributions)).Methods("GET")
+	contributions.Handle("", s.withRole(RoleMonitor, s.audit(AuditContributionCreate, s.handleCreateContributions))).Methods("POST")
+	contributions.Handle("/summary", s.withRole(RoleReadOnly, s.handleGetContributionSummary)).Methods("GET")
+
+	// Participation ledger
+	api.Handle("/ledger", s.withRole(RoleReadOnly, s.handleGetLedger)).Methods("GET")
 
 	// Resource metrics endpoints
 	resources := api.PathPrefix("/resources").Subrouter()
-	resources.HandleFunc("/{source}", s.handleGetResourceMetrics).Methods("GET")
-	resources.HandleFunc("/{source}", s.handleCreateResourceMetrics).Methods("POST")
+	resources.Handle("/{source}", s.withRole(RoleReadOnly, s.handleGetResourceMetrics)).Methods("GET")
+	resources.Handle("/{source}", s.withRole(RoleMonitor, s.audit(AuditResourceCreate, s.handleCreateResourceMetrics))).Methods("POST")
 
 	// Event endpoints
 	events := api.PathPrefix("/events").Subrouter()
-	events.HandleFunc("", s.handleListEvents).Methods("GET")
-	events.HandleFunc("", s.handleCreateEvent).Methods("POST")
-	events.HandleFunc("/alerts", s.handleGetActiveAlerts).Methods("GET")
+	events.Handle("", s.withRole(RoleReadOnly, s.handleListEvents)).Methods("GET")
+	events.Handle("", s.withRole(RoleMonitor, s.audit(AuditEventCreate, s.handleCreateEvent))).Methods("POST")
+	events.Handle("/alerts", s.withRole(RoleReadOnly, s.handleGetActiveAlerts)).Methods("GET")
 
 	// Dashboard endpoints
 	dashboards := api.PathPrefix("/dashboards").Subrouter()
-	dashboards.HandleFunc("", s.handleListDashboards).Methods("GET")
-	dashboards.HandleFunc("", s.handleCreateDashboard).Methods("POST")
-	dashboards.HandleFunc("/{id}", s.handleGetDashboard).Methods("GET")
-	dashboards.HandleFunc("/{id}", s.handleUpdateDashboard).Methods("PUT")
-	dashboards.HandleFunc("/{id}", s.handleDeleteDashboard).Methods("DELETE")
+	dashboards.Handle("", s.withRole(RoleReadOnly, s.handleListDashboards)).Methods("GET")
+	dashboards.Handle("", s.withRole(RoleAdmin, s.audit(AuditDashboardCreate, s.handleCreateDashboard))).Methods("POST")
+	dashboards.Handle("/templates", s.withRole(RoleReadOnly, s.handleListDashboardTemplates)).Methods("GET")
+	dashboards.Handle("/templates/{name}", s.withRole(RoleAdmin, s.audit(AuditDashboardCreate, s.handleInstantiateDashboardTemplate))).Methods("POST")
+	dashboards.Handle("/{id}", s.withRole(RoleReadOnly, s.handleGetDashboard)).Methods("GET")
+	dashboards.Handle("/{id}", s.withRole(RoleAdmin, s.audit(AuditDashboardUpdate, s.handleUpdateDashboard))).Methods("PUT")
+	dashboards.Handle("/{id}", s.withRole(RoleAdmin, s.audit(AuditDashboardDelete, s.handleDeleteDashboard))).Methods("DELETE")
+	dashboards.Handle("/{id}/widgets/{wid}/data", s.withRole(RoleReadOnly, s.handleGetWidgetData)).Methods("GET")
 
 	// WebSocket endpoint for real-time events
-	api.HandleFunc("/ws", s.handleWebSocket).Methods("GET")
+	api.Handle("/ws", s.withRole(RoleReadOnly, s.handleWebSocket)).Methods("GET")
+
+	// Model registry routes
+	models := api.PathPrefix("/models").Subrouter()
+	models.Handle("", s.withRole(RoleReadOnly, s.handleListModels)).Methods("GET")
+	models.Handle("/{name}/versions/{version}", s.withRole(RoleReadOnly, s.handleGetModel)).Methods("GET")
+	models.Handle("/{name}/versions/{version}/lineage", s.withRole(RoleReadOnly, s.handleGetModelLineage)).Methods("GET")
+	models.Handle("/{name}/versions/{version}/download", s.withRole(RoleReadOnly, s.handleDownloadModel)).Methods("GET")
+	models.Handle("/{name}/versions/{version}/promote", s.withRole(RoleAdmin, s.audit(AuditModelPromote, s.handlePromoteModel))).Methods("POST")
+
+	// Grafana JSON datasource endpoints. Queries are reads, so POST only needs readonly.
+	grafana := api.PathPrefix("/grafana").Subrouter()
+	grafana.Handle("", s.withRole(RoleReadOnly, s.handleGrafanaTest)).Methods("GET")
+	grafana.Handle("/", s.withRole(RoleReadOnly, s.handleGrafanaTest)).Methods("GET")
+	grafana.Handle("/search", s.withRole(RoleReadOnly, s.handleGrafanaSearch)).Methods("POST")
+	grafana.Handle("/query", s.withRole(RoleReadOnly, s.handleGrafanaQuery)).Methods("POST")
+	grafana.Handle("/annotations", s.withRole(RoleReadOnly, s.handleGrafanaAnnotations)).Methods("POST")
+
+	// Prometheus scrape endpoint
+	s.router.Handle("/metrics", s.withRole(RoleReadOnly, s.handlePrometheusMetrics)).Methods("GET")
+
+	// Unauthenticated public status page, only when explicitly enabled
+	if s.config.PublicStatus.Enabled {
+		api.HandleFunc("/public/status", s.handlePublicStatus).Methods("GET")
+		s.router.HandleFunc("/status", s.handlePublicStatusPage).Methods("GET")
+	}
+
+	// Serve the web UI
+	s.router.PathPrefix("/").Handler(s.webUIHandler())
+}
+
+// withRole wraps a handler with the auth middleware and the caller's rate
+// limit, and limits rejected credentials by client address. The configured
+// required_role raises the minimum role of every protected route.
+func (s *APIServer) withRole(role string, handler http.HandlerFunc) http.Handler {
+	if s.auth == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.sendError(w, http.StatusServiceUnavailable, "Authentication unavailable", s.authErr)
+		})
+	}
+
+	if required := s.config.Auth.RequiredRole; ValidateRole(required) && !s.auth.hasRole(role, required) {
+		role = required
+	}
 
-	// Serve static files for the web UI
-	s.router.PathPrefix("/").Handler(http.FileServer(http.Dir("./web/dist/")))
+	return s.limitFailedAuth(s.auth.AuthMiddleware(role), s.rateLimit(handler))
+}
+
+// idempotencyKey returns the Idempotency-Key header of a create request. It
+// is used as the ID of a record whose body has none, so that a retried request
+// updates the record made by the first attempt instead of adding another.
+func idempotencyKey(r *http.Request) string {
+	return r.Header.Get("Idempotency-Key")
 }
 
 // Health check endpoint
@@ -178,6 +365,105 @@ func (s *APIServer) handleStats(w http.ResponseWriter, r *http.Request) {
 }
 
 // Federation handlers
+// handleIssueToken issues a JWT to an authenticated caller
+func (s *APIServer) handleIssueToken(w http.ResponseWriter, r *http.Request) {
+	caller, ok := GetUserFromContext(r.Context())
+	if !ok {
+		s.sendError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	var req TokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	if !s.config.Auth.Enabled || !s.config.Auth.JWTAuth.Enabled {
+		s.sendError(w, http.StatusBadRequest, "JWT authentication is not enabled", nil)
+		return
+	}
+
+	token, err := s.auth.IssueToken(caller, req)
+	if err != nil {
+		s.sendError(w, http.StatusForbidden, "Failed to issue token", err)
+		return
+	}
+
+	setAuditResource(w, token.UserID)
+	s.sendSuccess(w, token)
+}
+
+// API key handlers
+func (s *APIServer) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.service.ListAPIKeys(r.Context())
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list API keys", err)
+		return
+	}
+
+	s.sendSuccess(w, keys)
+}
+
+func (s *APIServer) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	caller, ok := GetUserFromContext(r.Context())
+	if !ok {
+		s.sendError(w, http.StatusUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if s.config.Auth.Enabled && !s.config.Auth.APIKeyAuth.Enabled {
+		s.sendError(w, http.StatusBadRequest, "API key authentication is not enabled", nil)
+		return
+	}
+
+	key, err := s.auth.IssueAPIKey(r.Context(), caller, req)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Failed to create API key", err)
+		return
+	}
+
+	setAuditResource(w, key.ID)
+	s.sendSuccess(w, key)
+}
+
+func (s *APIServer) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	key, err := s.auth.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Failed to revoke API key", err)
+		return
+	}
+
+	s.sendSuccess(w, key)
+}
+
+func (s *APIServer) handleRotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if _, err := s.service.GetAPIKey(r.Context(), id); err != nil {
+		s.sendError(w, http.StatusNotFound, "API key not found", err)
+		return
+	}
+
+	key, err := s.auth.RotateAPIKey(r.Context(), id)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Failed to rotate API key", err)
+		return
+	}
+
+	s.sendSuccess(w, key)
+}
+
 func (s *APIServer) handleListFederations(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
@@ -188,7 +474,13 @@ func (s *APIServer) handleListFederations(w http.ResponseWriter, r *http.Request
 			s.sendError(w, http.StatusInternalServerError, "Failed to get active federations", err)
 			return
 		}
-		s.sendSuccess(w, federations)
+		labeled := federations[:0]
+		for _, federation := range federations {
+			if hasLabels(federation.Labels, filter.Labels) {
+				labeled = append(labeled, federation)
+			}
+		}
+		s.sendSuccess(w, labeled)
 		return
 	}
 
@@ -209,12 +501,21 @@ func (s *APIServer) handleCreateFederation(w http.ResponseWriter, r *http.Reques
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := ValidateLabels(federation.Labels); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if federation.ID == "" {
+		federation.ID = idempotencyKey(r)
+	}
 
 	if err := s.service.RegisterFederation(ctx, &federation); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to register federation", err)
 		return
 	}
 
+	setAuditResource(w, federation.ID)
 	s.sendSuccess(w, federation)
 }
 
@@ -240,6 +541,10 @@ func (s *APIServer) handleUpdateFederation(w http.ResponseWriter, r *http.Reques
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := ValidateLabels(federation.Labels); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
 	if err := s.service.UpdateFederation(ctx, id, &federation); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to update federation", err)
@@ -301,13 +606,273 @@ func (s *APIServer) handleGetEfficiencyMetrics(w http.ResponseWriter, r *http.Re
 	s.sendSuccess(w, metrics)
 }
 
+func (s *APIServer) handleGetTimeSeries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	metrics, err := ParseTimeSeriesMetrics(r.URL.Query().Get("metrics"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid metrics", err)
+		return
+	}
+
+	// Default to the last hour
+	query := &TimeSeriesQuery{
+		Metrics:   metrics,
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now(),
+		Source:    r.URL.Query().Get("source"),
+	}
+
+	if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid start_time", err)
+			return
+		}
+		query.StartTime = startTime
+	}
+
+	if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
+		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid end_time", err)
+			return
+		}
+		query.EndTime = endTime
+	}
+
+	if !query.EndTime.After(query.StartTime) {
+		s.sendError(w, http.StatusBadRequest, "end_time must be after start_time", nil)
+		return
+	}
+
+	if intervalStr := r.URL.Query().Get("interval"); intervalStr != "" {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil || interval <= 0 {
+			s.sendError(w, http.StatusBadRequest, "Invalid interval", err)
+			return
+		}
+		query.Interval = interval
+	}
+
+	// Unknown federations, and those of other organizations, are not found
+	if _, err := s.service.GetFederation(ctx, id); err != nil {
+		s.sendError(w, http.StatusNotFound, "Federation not found", err)
+		return
+	}
+
+	series, err := s.service.GetTimeSeries(ctx, id, query)
+	if errors.Is(err, errHidden) {
+		s.sendError(w, http.StatusNotFound, "Time series not found", err)
+		return
+	}
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get time series", err)
+		return
+	}
+
+	s.sendSuccess(w, series)
+}
+
+func (s *APIServer) handleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	dataset, err := ParseExportDataset(vars["dataset"])
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+
+	format, err := ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid format", err)
+		return
+	}
+
+	filter := s.parseMetricsFilter(r)
+	filter.FederationID = vars["id"]
+
+	// Buffer the export so that failures can still be reported as JSON
+	var buf bytes.Buffer
+	if err := ExportMetrics(ctx, s.service, dataset, format, filter, &buf); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to export metrics", err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", vars["id"], dataset, format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// handleListAudit returns audit entries, newest first
+func (s *APIServer) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	metricsFilter := s.parseMetricsFilter(r)
+
+	filter := &AuditFilter{
+		UserID:     query.Get("user_id"),
+		OrgID:      query.Get("org_id"),
+		Action:     query.Get("action"),
+		ResourceID: query.Get("resource_id"),
+		StartTime:  metricsFilter.StartTime,
+		EndTime:    metricsFilter.EndTime,
+		Page:       metricsFilter.Page,
+		PerPage:    metricsFilter.PerPage,
+	}
+	if successStr := query.Get("success"); successStr != "" {
+		success, err := strconv.ParseBool(successStr)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid success filter", err)
+			return
+		}
+		filter.Success = &success
+	}
+
+	entries, err := s.service.GetAuditLog(r.Context(), filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get audit log", err)
+		return
+	}
+
+	s.sendSuccess(w, entries)
+}
+
+// handleImport backfills the historical records of a federation
+func (s *APIServer) handleImport(w http.ResponseWriter, r *http.Request) {
+	var archive ImportArchive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportBodyBytes)).Decode(&archive); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	s.importHistory(w, r, &archive)
+}
+
+// handleImportCSV backfills one dataset of a federation from a CSV file in
+// the format produced by the export endpoint
+func (s *APIServer) handleImportCSV(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	dataset, err := ParseExportDataset(vars["dataset"])
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+
+	var archive ImportArchive
+	if err := ReadExportCSV(dataset, http.MaxBytesReader(w, r.Body, maxImportBodyBytes), &archive); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid CSV", err)
+		return
+	}
+
+	// Records are imported into the federation named in the path
+	for _, round := range archive.Rounds {
+		round.FederationID = vars["id"]
+	}
+	for _, update := range archive.Updates {
+		update.FederationID = vars["id"]
+	}
+	for _, event := range archive.Events {
+		event.FederationID = vars["id"]
+	}
+	if len(archive.Rounds)+len(archive.Updates)+len(archive.Events) == 0 {
+		s.sendError(w, http.StatusBadRequest, "CSV contains no records", nil)
+		return
+	}
+
+	s.importHistory(w, r, &archive)
+}
+
+func (s *APIServer) importHistory(w http.ResponseWriter, r *http.Request, archive *ImportArchive) {
+	result, err := ImportHistory(r.Context(), s.service, archive)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Failed to import history", err)
+		return
+	}
+
+	setAuditResource(w, result.FederationID)
+	log.Printf("Imported history for federation %s: %d rounds, %d updates, %d events (%d skipped)",
+		result.FederationID, result.Rounds, result.Updates, result.Events, result.Skipped)
+	s.sendSuccess(w, result)
+}
+
+// handleIngest records a batch of heterogeneous metrics. Items are processed
+// independently; failures are reported per item with 207 Multi-Status.
+func (s *APIServer) handleIngest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var batch IngestBatch
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIngestBodyBytes)).Decode(&batch); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if len(batch.Items) == 0 {
+		s.sendError(w, http.StatusBadRequest, "Batch contains no items", nil)
+		return
+	}
+
+	if len(batch.Items) > MaxIngestBatchSize {
+		s.sendError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Batch exceeds maximum of %d items", MaxIngestBatchSize), nil)
+		return
+	}
+
+	result := IngestMetrics(ctx, s.service, &batch)
+	if result.Failed == 0 {
+		s.sendSuccess(w, result)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultiStatus)
+	json.NewEncoder(w).Encode(APIResponse{
+		Success: false,
+		Data:    result,
+		Error:   fmt.Sprintf("%d of %d items failed", result.Failed, len(batch.Items)),
+	})
+}
+
+// handlePublicStatus serves the public status as JSON
+func (s *APIServer) handlePublicStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := BuildPublicStatus(r.Context(), s.service, s.config.PublicStatus)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get status", nil)
+		return
+	}
+
+	s.sendSuccess(w, status)
+}
+
+// handlePublicStatusPage serves the public status as an HTML page
+func (s *APIServer) handlePublicStatusPage(w http.ResponseWriter, r *http.Request) {
+	status, err := BuildPublicStatus(r.Context(), s.service, s.config.PublicStatus)
+	if err != nil {
+		http.Error(w, "Status unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := publicStatusTemplate.Execute(w, status); err != nil {
+		http.Error(w, "Status unavailable", http.StatusInternalServerError)
+	}
+}
+
 // Collaborator handlers
 func (s *APIServer) handleListCollaborators(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
 
-	// Check if filtering by federation
-	if federationID := r.URL.Query().Get("federation_id"); federationID != "" {
+	// Check if filtering by federation only
+	if federationID := r.URL.Query().Get("federation_id"); federationID != "" && len(filter.Tags) == 0 && filter.Federations == nil {
 		collaborators, err := s.service.GetFederationCollaborators(ctx, federationID)
 		if err != nil {
 			s.sendError(w, http.StatusInternalServerError, "Failed to get federation collaborators", err)
@@ -334,12 +899,17 @@ func (s *APIServer) handleCreateCollaborator(w http.ResponseWriter, r *http.Requ
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := collaborator.ValidateLocation(); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
 	if err := s.service.RegisterCollaborator(ctx, &collaborator); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to register collaborator", err)
 		return
 	}
 
+	setAuditResource(w, collaborator.ID)
 	s.sendSuccess(w, collaborator)
 }
 
@@ -365,6 +935,10 @@ func (s *APIServer) handleUpdateCollaborator(w http.ResponseWriter, r *http.Requ
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := collaborator.ValidateLocation(); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
 	if err := s.service.UpdateCollaborator(ctx, id, &collaborator); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to update collaborator", err)
@@ -379,8 +953,8 @@ func (s *APIServer) handleListRounds(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
 
-	// Check if filtering by federation
-	if federationID := r.URL.Query().Get("federation_id"); federationID != "" {
+	// Check if filtering by federation only
+	if federationID := r.URL.Query().Get("federation_id"); federationID != "" && len(filter.Labels) == 0 {
 		rounds, err := s.service.GetFederationRounds(ctx, federationID)
 		if err != nil {
 			s.sendError(w, http.StatusInternalServerError, "Failed to get federation rounds", err)
@@ -407,12 +981,21 @@ func (s *APIServer) handleCreateRound(w http.ResponseWriter, r *http.Request) {
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := ValidateLabels(round.Labels); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if round.ID == "" {
+		round.ID = idempotencyKey(r)
+	}
 
 	if err := s.service.RecordRoundStart(ctx, &round); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to record round start", err)
 		return
 	}
 
+	setAuditResource(w, round.ID)
 	s.sendSuccess(w, round)
 }
 
@@ -438,6 +1021,10 @@ func (s *APIServer) handleUpdateRound(w http.ResponseWriter, r *http.Request) {
 		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	if err := ValidateLabels(round.Labels); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
 	if err := s.service.RecordRoundEnd(ctx, id, &round); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to record round end", err)
@@ -451,6 +1038,10 @@ func (s *APIServer) handleUpdateRound(w http.ResponseWriter, r *http.Request) {
 func (s *APIServer) handleListModelUpdates(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
 
 	updates, err := s.service.GetModelUpdates(ctx, filter)
 	if err != nil {
@@ -504,10 +1095,57 @@ func (s *APIServer) handleGetUpdateStatistics(w http.ResponseWriter, r *http.Req
 	s.sendSuccess(w, stats)
 }
 
+func (s *APIServer) handleListDroppedUpdates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
+
+	if reason := r.URL.Query().Get("reason"); reason != "" {
+		filter.Status = reason
+	}
+
+	stats, err := s.service.GetDroppedUpdateStats(ctx, filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get dropped updates", err)
+		return
+	}
+
+	s.sendSuccess(w, stats)
+}
+
+func (s *APIServer) handleCreateDroppedUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var dropped DroppedUpdate
+	if err := json.NewDecoder(r.Body).Decode(&dropped); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if dropped.Reason == "" {
+		s.sendError(w, http.StatusBadRequest, "reason is required", nil)
+		return
+	}
+
+	if err := s.service.RecordDroppedUpdate(ctx, &dropped); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to record dropped update", err)
+		return
+	}
+
+	s.sendSuccess(w, dropped)
+}
+
 // Aggregation handlers
 func (s *APIServer) handleListAggregations(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
 
 	aggregations, err := s.service.GetAggregations(ctx, filter)
 	if err != nil {
@@ -535,6 +1173,115 @@ func (s *APIServer) handleCreateAggregation(w http.ResponseWriter, r *http.Reque
 	s.sendSuccess(w, aggregation)
 }
 
+func (s *APIServer) handleGetAggregation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	aggregation, err := s.service.GetAggregation(ctx, id)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Aggregation not found", err)
+		return
+	}
+
+	s.sendSuccess(w, aggregation)
+}
+
+func (s *APIServer) handleUpdateAggregation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	var aggregation AggregationMetrics
+	if err := json.NewDecoder(r.Body).Decode(&aggregation); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := s.service.UpdateAggregation(ctx, id, &aggregation); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to update aggregation", err)
+		return
+	}
+
+	s.sendSuccess(w, aggregation)
+}
+
+// Contribution score handlers
+func (s *APIServer) handleListContributions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
+
+	scores, err := s.service.GetContributions(ctx, filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get contribution scores", err)
+		return
+	}
+
+	s.sendSuccess(w, scores)
+}
+
+// handleCreateContributions records the scores of an aggregation, posted as
+// a list
+func (s *APIServer) handleCreateContributions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var scores []*ContributionScore
+	if err := json.NewDecoder(r.Body).Decode(&scores); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	for _, score := range scores {
+		if score == nil || score.CollaboratorID == "" {
+			s.sendError(w, http.StatusBadRequest, "collaborator_id is required", nil)
+			return
+		}
+	}
+
+	if err := s.service.RecordContributions(ctx, scores); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to record contribution scores", err)
+		return
+	}
+
+	s.sendSuccess(w, scores)
+}
+
+func (s *APIServer) handleGetContributionSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	federationID := r.URL.Query().Get("federation_id")
+	if federationID == "" {
+		s.sendError(w, http.StatusBadRequest, "federation_id is required", nil)
+		return
+	}
+
+	summary, err := s.service.GetContributionSummary(ctx, federationID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get contribution summary", err)
+		return
+	}
+
+	s.sendSuccess(w, summary)
+}
+
+func (s *APIServer) handleGetLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
+
+	accounts, err := s.service.GetLedger(ctx, filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get ledger", err)
+		return
+	}
+
+	s.sendSuccess(w, accounts)
+}
+
 func (s *APIServer) handleGetAggregationStatistics(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -598,6 +1345,10 @@ func (s *APIServer) handleCreateResourceMetrics(w http.ResponseWriter, r *http.R
 func (s *APIServer) handleListEvents(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	filter := s.parseMetricsFilter(r)
+	if err := s.scopeToLabels(ctx, filter); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to select labeled federations", err)
+		return
+	}
 
 	events, err := s.service.GetEvents(ctx, filter)
 	if err != nil {
@@ -617,6 +1368,10 @@ func (s *APIServer) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if event.ID == "" {
+		event.ID = idempotencyKey(r)
+	}
+
 	if err := s.service.RecordEvent(ctx, &event); err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to record event", err)
 		return
@@ -670,6 +1425,49 @@ func (s *APIServer) handleCreateDashboard(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	setAuditResource(w, dashboard.ID)
+	s.sendSuccess(w, dashboard)
+}
+
+func (s *APIServer) handleListDashboardTemplates(w http.ResponseWriter, r *http.Request) {
+	s.sendSuccess(w, DashboardTemplates())
+}
+
+func (s *APIServer) handleInstantiateDashboardTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	template, err := findDashboardTemplate(mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Dashboard template not found", err)
+		return
+	}
+
+	var req DashboardTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.FederationID == "" {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", fmt.Errorf("federation_id is required"))
+		return
+	}
+
+	federation, err := s.service.GetFederation(ctx, req.FederationID)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Federation not found", err)
+		return
+	}
+
+	dashboard := template.Instantiate(federation, "")
+	if req.Name != "" {
+		dashboard.Name = req.Name
+	}
+	if err := s.service.CreateDashboard(ctx, dashboard); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to create dashboard", err)
+		return
+	}
+
+	setAuditResource(w, dashboard.ID)
 	s.sendSuccess(w, dashboard)
 }
 
@@ -716,6 +1514,198 @@ func (s *APIServer) handleDeleteDashboard(w http.ResponseWriter, r *http.Request
 	s.sendSuccess(w, map[string]string{"message": "Dashboard deleted successfully"})
 }
 
+func (s *APIServer) handleGetWidgetData(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	dashboard, err := s.service.GetDashboard(ctx, vars["id"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Dashboard not found", err)
+		return
+	}
+
+	var widget *Widget
+	for i := range dashboard.Widgets {
+		if dashboard.Widgets[i].ID == vars["wid"] {
+			widget = &dashboard.Widgets[i]
+			break
+		}
+	}
+	if widget == nil {
+		s.sendError(w, http.StatusNotFound, "Widget not found", nil)
+		return
+	}
+
+	var override WidgetRange
+	if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
+		if override.StartTime, err = time.Parse(time.RFC3339, startTimeStr); err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid start_time", err)
+			return
+		}
+	}
+	if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
+		if override.EndTime, err = time.Parse(time.RFC3339, endTimeStr); err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid end_time", err)
+			return
+		}
+	}
+	if intervalStr := r.URL.Query().Get("interval"); intervalStr != "" {
+		if override.Interval, err = time.ParseDuration(intervalStr); err != nil || override.Interval <= 0 {
+			s.sendError(w, http.StatusBadRequest, "Invalid interval", err)
+			return
+		}
+	}
+
+	query, err := newWidgetQuery(widget, override)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid widget config", err)
+		return
+	}
+
+	data, err := query.resolve(ctx, s.service)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get widget data", err)
+		return
+	}
+
+	s.sendSuccess(w, data)
+}
+
+// Notification subscription handlers
+func (s *APIServer) handleListNotifications(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := s.service.ListNotificationSubscriptions(r.Context())
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get notification subscriptions", err)
+		return
+	}
+
+	s.sendSuccess(w, subscriptions)
+}
+
+func (s *APIServer) handleCreateNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var subscription NotificationSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := s.validateNotification(&subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid notification subscription", err)
+		return
+	}
+	subscription.CreatedBy = ""
+	if caller, ok := GetUserFromContext(ctx); ok {
+		subscription.CreatedBy = caller.UserID
+	}
+
+	if err := s.service.CreateNotificationSubscription(ctx, &subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Failed to create notification subscription", err)
+		return
+	}
+
+	setAuditResource(w, subscription.ID)
+	s.sendSuccess(w, subscription)
+}
+
+// validateNotification checks a subscription and fills in its defaults.
+// Email subscriptions need the server's mail settings.
+func (s *APIServer) validateNotification(subscription *NotificationSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	if smtp := s.config.Notifications.SMTP; len(subscription.Email) > 0 && (smtp.Address == "" || smtp.From == "") {
+		return fmt.Errorf("email notifications require notifications.smtp.address and notifications.smtp.from in the server configuration")
+	}
+	return nil
+}
+
+func (s *APIServer) handleGetNotification(w http.ResponseWriter, r *http.Request) {
+	subscription, err := s.service.GetNotificationSubscription(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Notification subscription not found", err)
+		return
+	}
+
+	s.sendSuccess(w, subscription)
+}
+
+func (s *APIServer) handleUpdateNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	existing, err := s.service.GetNotificationSubscription(ctx, id)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Notification subscription not found", err)
+		return
+	}
+
+	var subscription NotificationSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := s.validateNotification(&subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid notification subscription", err)
+		return
+	}
+	subscription.CreatedBy = existing.CreatedBy
+
+	if err := s.service.UpdateNotificationSubscription(ctx, id, &subscription); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Failed to update notification subscription", err)
+		return
+	}
+
+	s.sendSuccess(w, subscription)
+}
+
+func (s *APIServer) handleDeleteNotification(w http.ResponseWriter, r *http.Request) {
+	if err := s.service.DeleteNotificationSubscription(r.Context(), mux.Vars(r)["id"]); err != nil {
+		s.sendError(w, http.StatusNotFound, "Notification subscription not found", err)
+		return
+	}
+
+	s.sendSuccess(w, map[string]string{"message": "Notification subscription deleted successfully"})
+}
+
+// Prometheus metrics handler
+func (s *APIServer) handlePrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dropped, err := s.service.GetDroppedUpdateStats(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stats, err := s.service.GetMetricsStats(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	samples := make([]PrometheusSample, 0, len(dropped))
+	for _, d := range dropped {
+		samples = append(samples, PrometheusSample{
+			Labels: map[string]string{
+				"federation_id":   d.FederationID,
+				"collaborator_id": d.CollaboratorID,
+				"reason":          string(d.Reason),
+			},
+			Value: float64(d.Count),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	WritePrometheusMetric(w, "flgo_dropped_updates_total", "Model updates rejected by aggregators", "counter", samples)
+	WritePrometheusMetric(w, "flgo_federations_active", "Currently running federations", "gauge",
+		[]PrometheusSample{{Value: float64(stats.ActiveFederations)}})
+	WritePrometheusMetric(w, "flgo_collaborators_active", "Currently active collaborators", "gauge",
+		[]PrometheusSample{{Value: float64(stats.ActiveCollaborators)}})
+	WritePrometheusMetric(w, "flgo_model_updates_total", "Model updates recorded", "counter",
+		[]PrometheusSample{{Value: float64(stats.TotalUpdates)}})
+}
+
 // WebSocket handler for real-time events
 func (s *APIServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := s.upgrader.Upgrade(w, r, nil)
@@ -737,7 +1727,7 @@ func (s *APIServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Subscribe to events
-	ctx := context.Background()
+	ctx := r.Context()
 	eventChan, err := s.service.SubscribeToEvents(ctx, federationID, eventTypes)
 	if err != nil {
 		log.Printf("Failed to subscribe to events: %v", err)
@@ -803,6 +1793,9 @@ func (s *APIServer) parseMetricsFilter(r *http.Request) *MetricsFilter {
 		}
 	}
 
+	filter.Tags = parseTags(r)
+	filter.Labels = parseLabels(r)
+
 	if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
 		if startTime, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
 			filter.StartTime = &startTime
@@ -818,6 +1811,40 @@ func (s *APIServer) parseMetricsFilter(r *http.Request) *MetricsFilter {
 	return filter
 }
 
+// parseLabels returns the label selector of a request, given as repeated or
+// comma-separated key=value label parameters. A requirement without a value
+// selects the label with an empty value.
+func parseLabels(r *http.Request) map[string]string {
+	var labels map[string]string
+	for _, value := range r.URL.Query()["label"] {
+		for _, requirement := range strings.Split(value, ",") {
+			key, value, _ := strings.Cut(requirement, "=")
+			if key = strings.TrimSpace(key); key == "" {
+				continue
+			}
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[key] = strings.TrimSpace(value)
+		}
+	}
+	return labels
+}
+
+// parseTags returns the tags of a request, given as repeated or
+// comma-separated tag parameters
+func parseTags(r *http.Request) []string {
+	var tags []string
+	for _, value := range r.URL.Query()["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
 func (s *APIServer) sendSuccess(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -831,6 +1858,12 @@ func (s *APIServer) sendSuccess(w http.ResponseWriter, data interface{}) {
 }
 
 func (s *APIServer) sendError(w http.ResponseWriter, statusCode int, message string, err error) {
+	// Writes to another organization's federations are forbidden whichever
+	// handler they reach
+	if errors.Is(err, errOtherOrganization) {
+		statusCode = http.StatusForbidden
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -0,0 +1,214 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix marks keys issued by the monitoring server
+const apiKeyPrefix = "flgo_"
+
+// APIKey is a managed API key. Only a hash of the secret is stored; the
+// plaintext key is returned once, when the key is created or rotated.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	OrgID     string     `json:"org_id,omitempty"`
+	Prefix    string     `json:"prefix"` // leading characters of the key, for identification
+	KeyHash   string     `json:"-"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRequest is the body of an API key creation request
+type APIKeyRequest struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	OrgID     string `json:"org_id,omitempty"`     // defaults to the caller's organization
+	ExpiresIn string `json:"expires_in,omitempty"` // Go duration, e.g. "720h"; empty never expires
+}
+
+// IssuedAPIKey is returned when a key is created or rotated
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyStore persists managed API keys
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	GetAPIKey(ctx context.Context, keyID string) (*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+	UpdateAPIKey(ctx context.Context, keyID string, key *APIKey) error
+}
+
+// Active reports whether the key can be used to authenticate at time now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// hashStoredAPIKey returns the hash under which a managed key is stored
+func hashStoredAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetKeyStore enables authentication with managed keys from store
+func (am *AuthManager) SetKeyStore(store APIKeyStore) {
+	am.keyStore = store
+}
+
+// IssueAPIKey creates and stores a new managed API key. Callers may only
+// issue keys with roles they hold, for their own organization.
+func (am *AuthManager) IssueAPIKey(ctx context.Context, caller *UserContext, req APIKeyRequest) (*IssuedAPIKey, error) {
+	if am.keyStore == nil {
+		return nil, fmt.Errorf("API key store not configured")
+	}
+
+	if !ValidateRole(req.Role) {
+		return nil, fmt.Errorf("invalid role: %q", req.Role)
+	}
+	if !am.hasRole(caller.Role, req.Role) {
+		return nil, fmt.Errorf("cannot issue a %s key with role %s", req.Role, caller.Role)
+	}
+	orgID, err := callerOrg(caller, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := &APIKey{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Role:      req.Role,
+		OrgID:     orgID,
+		CreatedBy: caller.UserID,
+		CreatedAt: now,
+	}
+
+	if req.ExpiresIn != "" {
+		lifetime, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("invalid expires_in: %q", req.ExpiresIn)
+		}
+		expiresAt := now.Add(lifetime)
+		key.ExpiresAt = &expiresAt
+	}
+
+	secret, err := am.newManagedKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := am.keyStore.CreateAPIKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return &IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// RotateAPIKey replaces the secret of an active key. The key keeps its ID and
+// role, and a key with an expiry is given its original lifetime again.
+func (am *AuthManager) RotateAPIKey(ctx context.Context, keyID string) (*IssuedAPIKey, error) {
+	if am.keyStore == nil {
+		return nil, fmt.Errorf("API key store not configured")
+	}
+
+	key, err := am.keyStore.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("API key %s is revoked", keyID)
+	}
+
+	if key.ExpiresAt != nil {
+		issuedAt := key.CreatedAt
+		if key.RotatedAt != nil {
+			issuedAt = *key.RotatedAt
+		}
+		expiresAt := now.Add(key.ExpiresAt.Sub(issuedAt))
+		key.ExpiresAt = &expiresAt
+	}
+	key.RotatedAt = &now
+
+	secret, err := am.newManagedKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := am.keyStore.UpdateAPIKey(ctx, keyID, key); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return &IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// RevokeAPIKey permanently disables a key. Revoked keys remain listed.
+func (am *AuthManager) RevokeAPIKey(ctx context.Context, keyID string) (*APIKey, error) {
+	if am.keyStore == nil {
+		return nil, fmt.Errorf("API key store not configured")
+	}
+
+	key, err := am.keyStore.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := am.keyStore.UpdateAPIKey(ctx, keyID, key); err != nil {
+			return nil, fmt.Errorf("failed to store API key: %w", err)
+		}
+	}
+
+	return key, nil
+}
+
+// newManagedKey generates a secret for key and sets its hash and prefix
+func (am *AuthManager) newManagedKey(key *APIKey) (string, error) {
+	generated, err := am.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	secret := apiKeyPrefix + generated
+	key.KeyHash = hashStoredAPIKey(secret)
+	key.Prefix = secret[:len(apiKeyPrefix)+6]
+	return secret, nil
+}
+
+// authenticateManagedKey looks up a key in the key store
+func (am *AuthManager) authenticateManagedKey(ctx context.Context, apiKey string) (*UserContext, error) {
+	if am.keyStore == nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	key, err := am.keyStore.GetAPIKeyByHash(ctx, hashStoredAPIKey(apiKey))
+	if err != nil || !key.Active(time.Now()) {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	return &UserContext{
+		UserID: fmt.Sprintf("apikey-%s", key.ID),
+		Role:   key.Role,
+		OrgID:  key.OrgID,
+		APIKey: apiKey,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newAPIKeyTestServer() *APIServer {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeyAuth: APIKeyConfig{
+				Enabled: true,
+				Keys:    map[string]string{"admin-key": RoleAdmin, "monitor-key": RoleMonitor},
+			},
+		},
+	}
+	return NewAPIServer(NewMemoryStorage(config), config)
+}
+
+// doAPIKeyRequest sends a request to server and decodes the response data into out
+func doAPIKeyRequest(t *testing.T, server *APIServer, method, path, apiKey, body string, out interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-API-Key", apiKey)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if out != nil && rr.Code == http.StatusOK {
+		resp := APIResponse{Data: out}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return rr.Code
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	server := newAPIKeyTestServer()
+
+	var issued IssuedAPIKey
+	code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys", "admin-key",
+		`{"name": "aggregator", "role": "monitor", "expires_in": "24h"}`, &issued)
+	if code != http.StatusOK {
+		t.Fatalf("Create API key status = %d, want 200", code)
+	}
+	if !strings.HasPrefix(issued.Key, apiKeyPrefix) || issued.Role != RoleMonitor || issued.ExpiresAt == nil {
+		t.Fatalf("Unexpected issued key: %+v", issued)
+	}
+
+	// The new key can write metrics but cannot manage keys
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/events", issued.Key, `{"type": "custom"}`, nil); code != http.StatusOK {
+		t.Errorf("Managed key write status = %d, want 200", code)
+	}
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/apikeys", issued.Key, "", nil); code != http.StatusForbidden {
+		t.Errorf("Managed key list status = %d, want 403", code)
+	}
+
+	// Listing never exposes the secret or its hash
+	req := httptest.NewRequest("GET", "/api/v1/apikeys", nil)
+	req.Header.Set("X-API-Key", "admin-key")
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if body := rr.Body.String(); strings.Contains(body, issued.Key) || strings.Contains(body, hashStoredAPIKey(issued.Key)) {
+		t.Errorf("Key listing leaked the secret: %s", body)
+	}
+
+	var rotated IssuedAPIKey
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys/"+issued.ID+"/rotate", "admin-key", "", &rotated); code != http.StatusOK {
+		t.Fatalf("Rotate API key status = %d, want 200", code)
+	}
+	if rotated.ID != issued.ID || rotated.Key == issued.Key {
+		t.Fatalf("Rotation should keep the ID and change the key: %+v", rotated)
+	}
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/stats", issued.Key, "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Old key after rotation status = %d, want 401", code)
+	}
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/stats", rotated.Key, "", nil); code != http.StatusOK {
+		t.Errorf("Rotated key status = %d, want 200", code)
+	}
+
+	if code := doAPIKeyRequest(t, server, "DELETE", "/api/v1/apikeys/"+issued.ID, "admin-key", "", nil); code != http.StatusOK {
+		t.Fatalf("Revoke API key status = %d, want 200", code)
+	}
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/stats", rotated.Key, "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Revoked key status = %d, want 401", code)
+	}
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys/"+issued.ID+"/rotate", "admin-key", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Rotating a revoked key status = %d, want 400", code)
+	}
+}
+
+func TestAuthManager_IssueAPIKey(t *testing.T) {
+	am, err := NewAuthManager(AuthConfig{Enabled: true, APIKeyAuth: APIKeyConfig{Enabled: true}})
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	am.SetKeyStore(storage)
+
+	monitor := &UserContext{UserID: "ops", Role: RoleMonitor}
+
+	tests := []struct {
+		name    string
+		req     APIKeyRequest
+		wantErr bool
+	}{
+		{"same role", APIKeyRequest{Role: RoleMonitor}, false},
+		{"lower role", APIKeyRequest{Role: RoleReadOnly, ExpiresIn: "1h"}, false},
+		{"escalation", APIKeyRequest{Role: RoleAdmin}, true},
+		{"invalid role", APIKeyRequest{Role: "superuser"}, true},
+		{"missing role", APIKeyRequest{}, true},
+		{"invalid expiry", APIKeyRequest{Role: RoleReadOnly, ExpiresIn: "soon"}, true},
+		{"negative expiry", APIKeyRequest{Role: RoleReadOnly, ExpiresIn: "-1h"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued, err := am.IssueAPIKey(context.Background(), monitor, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IssueAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && issued.CreatedBy != "ops" {
+				t.Errorf("CreatedBy = %q, want ops", issued.CreatedBy)
+			}
+		})
+	}
+
+	// Expired keys are rejected
+	issued, err := am.IssueAPIKey(context.Background(), monitor, APIKeyRequest{Role: RoleReadOnly, ExpiresIn: "1h"})
+	if err != nil {
+		t.Fatalf("IssueAPIKey() error = %v", err)
+	}
+	expired := time.Now().Add(-time.Minute)
+	issued.ExpiresAt = &expired
+	if err := storage.UpdateAPIKey(context.Background(), issued.ID, issued.APIKey); err != nil {
+		t.Fatalf("UpdateAPIKey() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/stats", nil)
+	req.Header.Set("X-API-Key", issued.Key)
+	if _, err := am.AuthenticateRequest(req); err == nil {
+		t.Error("Expected expired key to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+package monitoring
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAuditEntries bounds the audit log kept by the memory backend
+const maxAuditEntries = 10000
+
+// Audited actions, named <resource>.<verb>
+const (
+	AuditFederationCreate   = "federation.create"
+	AuditFederationUpdate   = "federation.update"
+	AuditFederationPause    = "federation.pause"
+	AuditFederationResume   = "federation.resume"
+	AuditFederationAbort    = "federation.abort"
+	AuditCollaboratorCreate = "collaborator.create"
+	AuditCollaboratorUpdate = "collaborator.update"
+	AuditRoundCreate        = "round.create"
+	AuditRoundUpdate        = "round.update"
+	AuditUpdateCreate       = "update.create"
+	AuditDroppedCreate      = "dropped_update.create"
+	AuditAggregationCreate  = "aggregation.create"
+	AuditAggregationUpdate  = "aggregation.update"
+	AuditContributionCreate = "contribution.create"
+	AuditResourceCreate     = "resource.create"
+	AuditEventCreate        = "event.create"
+	AuditDashboardCreate    = "dashboard.create"
+	AuditDashboardUpdate    = "dashboard.update"
+	AuditDashboardDelete    = "dashboard.delete"
+	AuditNotificationCreate = "notification.create"
+	AuditNotificationUpdate = "notification.update"
+	AuditNotificationDelete = "notification.delete"
+	AuditAPIKeyCreate       = "apikey.create"
+	AuditAPIKeyRevoke       = "apikey.revoke"
+	AuditAPIKeyRotate       = "apikey.rotate"
+	AuditTokenIssue         = "token.issue"
+	AuditMetricsIngest      = "metrics.ingest"
+	AuditHistoryImport      = "history.import"
+	AuditModelPromote       = "model.promote"
+)
+
+// AuditEntry records a write or administrative API call
+type AuditEntry struct {
+	ID         string    `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	UserID     string    `json:"user_id"` // user or API key identity
+	Role       string    `json:"role"`
+	OrgID      string    `json:"org_id,omitempty"`
+	Action     string    `json:"action"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	RemoteAddr string    `json:"remote_addr"`
+}
+
+// AuditFilter selects audit entries. Action matches either an exact action or
+// a resource, e.g. "apikey" matches "apikey.revoke".
+type AuditFilter struct {
+	UserID     string     `json:"user_id,omitempty"`
+	OrgID      string     `json:"org_id,omitempty"`
+	Action     string     `json:"action,omitempty"`
+	ResourceID string     `json:"resource_id,omitempty"`
+	Success    *bool      `json:"success,omitempty"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	Page       int        `json:"page,omitempty"`
+	PerPage    int        `json:"per_page,omitempty"`
+}
+
+// Matches reports whether entry is selected by the filter
+func (f *AuditFilter) Matches(entry *AuditEntry) bool {
+	if f == nil {
+		return true
+	}
+	if f.UserID != "" && entry.UserID != f.UserID {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action && !strings.HasPrefix(entry.Action, f.Action+".") {
+		return false
+	}
+	if f.OrgID != "" && entry.OrgID != f.OrgID {
+		return false
+	}
+	if f.ResourceID != "" && entry.ResourceID != f.ResourceID {
+		return false
+	}
+	if f.Success != nil && entry.Success != *f.Success {
+		return false
+	}
+	if f.StartTime != nil && entry.Timestamp.Before(*f.StartTime) {
+		return false
+	}
+	if f.EndTime != nil && entry.Timestamp.After(*f.EndTime) {
+		return false
+	}
+	return true
+}
+
+// statusRecorder captures the status code written by a handler and the ID
+// of resources created by it
+type statusRecorder struct {
+	http.ResponseWriter
+	status     int
+	resourceID string
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// setAuditResource names the resource a handler created, for requests whose
+// path does not contain the resource ID
+func setAuditResource(w http.ResponseWriter, resourceID string) {
+	if recorder, ok := w.(*statusRecorder); ok {
+		recorder.resourceID = resourceID
+	}
+}
+
+// audit wraps a handler so that every call is recorded in the audit log under
+// action. It runs inside the auth middleware, so rejected credentials are not
+// recorded, but calls that fail after authentication are.
+func (s *APIServer) audit(action string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(recorder, r)
+
+		vars := mux.Vars(r)
+		resourceID := recorder.resourceID
+		if resourceID == "" {
+			resourceID = vars["id"]
+		}
+		if resourceID == "" {
+			resourceID = vars["source"]
+		}
+
+		entry := &AuditEntry{
+			Timestamp:  time.Now(),
+			Action:     action,
+			ResourceID: resourceID,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			StatusCode: recorder.status,
+			Success:    recorder.status < http.StatusBadRequest,
+			RemoteAddr: r.RemoteAddr,
+		}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			entry.RemoteAddr = host
+		}
+		if user, ok := GetUserFromContext(r.Context()); ok {
+			entry.UserID = user.UserID
+			entry.Role = user.Role
+			entry.OrgID = user.OrgID
+		}
+
+		if err := s.service.RecordAudit(r.Context(), entry); err != nil {
+			log.Printf("Failed to record audit entry for %s: %v", action, err)
+		}
+	}
+}
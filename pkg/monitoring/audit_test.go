@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	server := newAPIKeyTestServer()
+
+	send := func(method, path, apiKey, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	send("POST", "/api/v1/federations", "monitor-key", `{"id": "fed-1", "name": "Study"}`)
+	send("PUT", "/api/v1/dashboards/missing", "admin-key", `{"name": "x"}`)
+	send("GET", "/api/v1/federations", "monitor-key", "")
+	send("POST", "/api/v1/dashboards", "monitor-key", `{"name": "denied"}`)
+
+	var issued IssuedAPIKey
+	doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys", "admin-key", `{"role": "readonly"}`, &issued)
+	send("DELETE", "/api/v1/apikeys/"+issued.ID, "admin-key", "")
+
+	tests := []struct {
+		name    string
+		query   string
+		actions []string
+	}{
+		{"all entries newest first", "", []string{AuditAPIKeyRevoke, AuditAPIKeyCreate, AuditDashboardUpdate, AuditFederationCreate}},
+		{"by resource", "?action=apikey", []string{AuditAPIKeyRevoke, AuditAPIKeyCreate}},
+		{"by exact action", "?action=federation.create", []string{AuditFederationCreate}},
+		{"failures", "?success=false", []string{AuditDashboardUpdate}},
+		{"by resource id", "?resource_id=" + issued.ID, []string{AuditAPIKeyRevoke, AuditAPIKeyCreate}},
+		{"by user", "?user_id=apikey-moni****-key", []string{AuditFederationCreate}},
+		{"paginated", "?page=2&per_page=3", []string{AuditFederationCreate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []*AuditEntry
+			if code := doAPIKeyRequest(t, server, "GET", "/api/v1/audit"+tt.query, "admin-key", "", &entries); code != http.StatusOK {
+				t.Fatalf("GET /api/v1/audit%s status = %d, want 200", tt.query, code)
+			}
+
+			var actions []string
+			for _, entry := range entries {
+				actions = append(actions, entry.Action)
+			}
+			if strings.Join(actions, ",") != strings.Join(tt.actions, ",") {
+				t.Errorf("actions = %v, want %v", actions, tt.actions)
+			}
+		})
+	}
+
+	entries, _ := server.service.GetAuditLog(context.Background(), &AuditFilter{Action: AuditFederationCreate})
+	if len(entries) != 1 {
+		t.Fatalf("Expected one federation.create entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.ResourceID != "fed-1" || entry.Role != RoleMonitor || !strings.HasPrefix(entry.UserID, "apikey-") ||
+		entry.StatusCode != http.StatusOK || entry.Method != "POST" {
+		encoded, _ := json.Marshal(entry)
+		t.Errorf("Unexpected audit entry: %s", encoded)
+	}
+
+	if code := send("GET", "/api/v1/audit", "monitor-key", ""); code != http.StatusForbidden {
+		t.Errorf("Non-admin audit access status = %d, want 403", code)
+	}
+}
@@ -26,6 +26,7 @@ type AuthConfig struct {
 type APIKeyConfig struct {
 	Enabled    bool              `yaml:"enabled"`
 	Keys       map[string]string `yaml:"keys"`        // key -> role mapping
+	Orgs       map[string]string `yaml:"orgs"`        // key -> organization mapping; keys without one are server-wide
 	HeaderName string            `yaml:"header_name"` // default: X-API-Key
 }
 
@@ -53,17 +54,40 @@ type OAuthConfig struct {
 type AuthManager struct {
 	config    AuthConfig
 	jwtSecret []byte
+	keyStore  APIKeyStore
 }
 
 // UserContext represents an authenticated user
 type UserContext struct {
 	UserID   string
 	Role     string
+	OrgID    string // organization the user is scoped to; empty for server-wide users
 	APIKey   string
 	JWTToken string
 	Claims   jwt.MapClaims
 }
 
+// defaultTokenExpiry is used when JWT is enabled without a token_expiry
+const defaultTokenExpiry = time.Hour
+
+// TokenRequest is the body of a JWT issuance request. All fields are
+// optional and default to the caller's identity, role and organization.
+type TokenRequest struct {
+	UserID string `json:"user_id,omitempty"`
+	Role   string `json:"role,omitempty"`
+	OrgID  string `json:"org_id,omitempty"`
+}
+
+// TokenResponse contains an issued JWT
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	OrgID     string    `json:"org_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Role constants
 const (
 	RoleAdmin    = "admin"
@@ -78,6 +102,10 @@ func NewAuthManager(config AuthConfig) (*AuthManager, error) {
 	}
 
 	if config.JWTAuth.Enabled {
+		if config.JWTAuth.TokenExpiry <= 0 {
+			am.config.JWTAuth.TokenExpiry = defaultTokenExpiry
+		}
+
 		if config.JWTAuth.Secret == "" {
 			// Generate a random secret if none provided
 			secret := make([]byte, 32)
@@ -132,15 +160,16 @@ func (am *AuthManager) authenticateAPIKey(r *http.Request) (*UserContext, error)
 		return nil, fmt.Errorf("API key not provided")
 	}
 
-	// Check if API key exists and get role
+	// Check if API key exists and get role, falling back to managed keys
 	role, exists := am.config.APIKeyAuth.Keys[apiKey]
 	if !exists {
-		return nil, fmt.Errorf("invalid API key")
+		return am.authenticateManagedKey(r.Context(), apiKey)
 	}
 
 	return &UserContext{
 		UserID: fmt.Sprintf("apikey-%s", hashAPIKey(apiKey)),
 		Role:   role,
+		OrgID:  am.config.APIKeyAuth.Orgs[apiKey],
 		APIKey: apiKey,
 	}, nil
 }
@@ -185,6 +214,7 @@ func (am *AuthManager) authenticateJWT(r *http.Request) (*UserContext, error) {
 	// Extract user information from claims
 	userID, _ := claims["sub"].(string)
 	role, _ := claims["role"].(string)
+	orgID, _ := claims["org"].(string)
 
 	if userID == "" {
 		return nil, fmt.Errorf("user ID not found in JWT claims")
@@ -197,6 +227,7 @@ func (am *AuthManager) authenticateJWT(r *http.Request) (*UserContext, error) {
 	return &UserContext{
 		UserID:   userID,
 		Role:     role,
+		OrgID:    orgID,
 		JWTToken: tokenString,
 		Claims:   claims,
 	}, nil
@@ -234,8 +265,9 @@ func (am *AuthManager) hasRole(userRole, requiredRole string) bool {
 	return userLevel >= requiredLevel
 }
 
-// GenerateJWT generates a JWT token for a user
-func (am *AuthManager) GenerateJWT(userID, role string) (string, error) {
+// GenerateJWT generates a JWT token for a user. An empty orgID issues a
+// server-wide token.
+func (am *AuthManager) GenerateJWT(userID, role, orgID string) (string, error) {
 	if !am.config.JWTAuth.Enabled {
 		return "", fmt.Errorf("JWT authentication not enabled")
 	}
@@ -248,11 +280,73 @@ func (am *AuthManager) GenerateJWT(userID, role string) (string, error) {
 		"exp":  now.Add(am.config.JWTAuth.TokenExpiry).Unix(),
 		"iss":  am.config.JWTAuth.Issuer,
 	}
+	if orgID != "" {
+		claims["org"] = orgID
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(am.jwtSecret)
 }
 
+// IssueToken issues a JWT on behalf of an authenticated caller. Callers may
+// only request roles they hold, and only admins may issue tokens for others.
+func (am *AuthManager) IssueToken(caller *UserContext, req TokenRequest) (*TokenResponse, error) {
+	userID := req.UserID
+	if userID == "" {
+		userID = caller.UserID
+	}
+
+	role := req.Role
+	if role == "" {
+		role = caller.Role
+	}
+
+	if !ValidateRole(role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+
+	if !am.hasRole(caller.Role, role) {
+		return nil, fmt.Errorf("cannot issue a %s token with role %s", role, caller.Role)
+	}
+
+	if userID != caller.UserID && !am.hasRole(caller.Role, RoleAdmin) {
+		return nil, fmt.Errorf("only admins can issue tokens for other users")
+	}
+
+	orgID, err := callerOrg(caller, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(am.config.JWTAuth.TokenExpiry)
+	token, err := am.GenerateJWT(userID, role, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		UserID:    userID,
+		Role:      role,
+		OrgID:     orgID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// callerOrg returns the organization a credential issued by caller is scoped
+// to. Scoped callers can only issue credentials for their own organization;
+// server-wide callers may scope credentials to any organization.
+func callerOrg(caller *UserContext, requested string) (string, error) {
+	if requested == "" || requested == caller.OrgID {
+		return caller.OrgID, nil
+	}
+	if caller.OrgID != "" {
+		return "", fmt.Errorf("cannot issue credentials for organization %s", requested)
+	}
+	return requested, nil
+}
+
 // GenerateAPIKey generates a new API key
 func (am *AuthManager) GenerateAPIKey() (string, error) {
 	keyBytes := make([]byte, 32)
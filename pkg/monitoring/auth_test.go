@@ -3,6 +3,7 @@ package monitoring
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -101,7 +102,7 @@ func TestAuthManager_JWTAuthentication(t *testing.T) {
 	}
 
 	// Generate a valid JWT token
-	token, err := authManager.GenerateJWT("test-user", RoleMonitor)
+	token, err := authManager.GenerateJWT("test-user", RoleMonitor, "")
 	if err != nil {
 		t.Fatalf("Failed to generate JWT token: %v", err)
 	}
@@ -417,3 +418,87 @@ func TestGenerateAPIKey(t *testing.T) {
 		t.Error("GenerateAPIKey() should not generate empty keys")
 	}
 }
+
+func TestAPIServerRouteRoles(t *testing.T) {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeyAuth: APIKeyConfig{
+				Enabled: true,
+				Keys: map[string]string{
+					"admin-key":    RoleAdmin,
+					"monitor-key":  RoleMonitor,
+					"readonly-key": RoleReadOnly,
+				},
+			},
+			JWTAuth: JWTConfig{Enabled: true, Secret: "test-secret"},
+		},
+	}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		apiKey         string
+		body           string
+		expectedStatus int
+	}{
+		{"health is public", "GET", "/api/v1/health", "", "", http.StatusOK},
+		{"read requires auth", "GET", "/api/v1/federations", "", "", http.StatusUnauthorized},
+		{"readonly can read", "GET", "/api/v1/federations", "readonly-key", "", http.StatusOK},
+		{"readonly cannot post metrics", "POST", "/api/v1/events", "readonly-key", `{"message":"x"}`, http.StatusForbidden},
+		{"monitor can post metrics", "POST", "/api/v1/events", "monitor-key", `{"message":"x"}`, http.StatusOK},
+		{"monitor cannot create dashboards", "POST", "/api/v1/dashboards", "monitor-key", `{"name":"d"}`, http.StatusForbidden},
+		{"admin can create dashboards", "POST", "/api/v1/dashboards", "admin-key", `{"name":"d"}`, http.StatusOK},
+		{"readonly can get a token", "POST", "/api/v1/auth/token", "readonly-key", "", http.StatusOK},
+		{"readonly cannot escalate", "POST", "/api/v1/auth/token", "readonly-key", `{"role":"admin"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("%s %s status = %d, want %d: %s", tt.method, tt.path, rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthManager_IssueToken(t *testing.T) {
+	authManager, err := NewAuthManager(AuthConfig{
+		Enabled: true,
+		JWTAuth: JWTConfig{Enabled: true, Secret: "test-secret"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+
+	monitor := &UserContext{UserID: "svc", Role: RoleMonitor}
+
+	token, err := authManager.IssueToken(monitor, TokenRequest{Role: RoleReadOnly})
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if token.UserID != "svc" || token.Role != RoleReadOnly || !token.ExpiresAt.After(time.Now()) {
+		t.Errorf("IssueToken() = %+v", token)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/federations", nil)
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	user, err := authManager.AuthenticateRequest(req)
+	if err != nil || user.Role != RoleReadOnly {
+		t.Errorf("Issued token did not authenticate: %v", err)
+	}
+
+	if _, err := authManager.IssueToken(monitor, TokenRequest{UserID: "someone-else"}); err == nil {
+		t.Error("Expected non-admin to be refused a token for another user")
+	}
+}
@@ -0,0 +1,205 @@
+package monitoring
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// BackupVersion is the version of the backup format written by WriteBackup
+const BackupVersion = 1
+
+// maxBackupEntryBytes limits the size of a single file read from a backup
+const maxBackupEntryBytes = 1 << 30
+
+// Backup is a snapshot of the data of a monitoring server. Each federation is
+// kept as an ImportArchive, so a backup restores through the historical
+// import of any storage backend.
+type Backup struct {
+	CreatedAt   time.Time
+	Federations []*ImportArchive
+	Dashboards  []*Dashboard
+}
+
+// backupManifest describes the files of a backup
+type backupManifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	Federations []string  `json:"federations"` // federation IDs, in file order
+	Dashboards  int       `json:"dashboards"`
+}
+
+// WriteBackup writes backup as a zstd-compressed tar file holding
+// manifest.json, dashboards.json and a JSON archive per federation under
+// federations/. Files are named by position rather than ID so that any ID
+// is safe to write.
+func WriteBackup(w io.Writer, backup *Backup) error {
+	encoder, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	archive := tar.NewWriter(encoder)
+
+	manifest := backupManifest{
+		Version:    BackupVersion,
+		CreatedAt:  backup.CreatedAt,
+		Dashboards: len(backup.Dashboards),
+	}
+	for _, federation := range backup.Federations {
+		manifest.Federations = append(manifest.Federations, federation.FederationID())
+	}
+
+	writeFile := func(name string, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: backup.CreatedAt}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = archive.Write(data)
+		return err
+	}
+
+	if err := writeFile("manifest.json", manifest); err != nil {
+		return err
+	}
+	dashboards := backup.Dashboards
+	if dashboards == nil {
+		dashboards = []*Dashboard{}
+	}
+	if err := writeFile("dashboards.json", dashboards); err != nil {
+		return err
+	}
+	for i, federation := range backup.Federations {
+		if err := writeFile(backupFederationFile(i), federation); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// ReadBackup reads a backup written by WriteBackup
+func ReadBackup(r io.Reader) (*Backup, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+
+	files := make(map[string][]byte)
+	archive := tar.NewReader(decoder)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(archive, maxBackupEntryBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup: %w", err)
+		}
+		if len(data) > maxBackupEntryBytes {
+			return nil, fmt.Errorf("backup file %s is too large", header.Name)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+
+	readFile := func(name string, value interface{}) error {
+		data, exists := files[name]
+		if !exists {
+			return fmt.Errorf("invalid backup: %s is missing", name)
+		}
+		if err := json.Unmarshal(data, value); err != nil {
+			return fmt.Errorf("invalid backup: %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var manifest backupManifest
+	if err := readFile("manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version < 1 || manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	backup := &Backup{CreatedAt: manifest.CreatedAt}
+	if err := readFile("dashboards.json", &backup.Dashboards); err != nil {
+		return nil, err
+	}
+	for i, federationID := range manifest.Federations {
+		var federation ImportArchive
+		if err := readFile(backupFederationFile(i), &federation); err != nil {
+			return nil, err
+		}
+		if federation.FederationID() != federationID {
+			return nil, fmt.Errorf("invalid backup: %s holds federation %s, expected %s",
+				backupFederationFile(i), federation.FederationID(), federationID)
+		}
+		backup.Federations = append(backup.Federations, &federation)
+	}
+
+	return backup, nil
+}
+
+// backupFederationFile returns the name of the file holding the i-th
+// federation of a backup
+func backupFederationFile(i int) string {
+	return fmt.Sprintf("federations/%06d.json", i+1)
+}
+
+// Split divides the archive into archives of at most maxRecords rounds,
+// updates and events in total, so that a large federation can be imported
+// in several requests. The first archive carries the federation and its
+// collaborators.
+func (a *ImportArchive) Split(maxRecords int) []*ImportArchive {
+	if maxRecords <= 0 {
+		return []*ImportArchive{a}
+	}
+
+	first := &ImportArchive{Federation: a.Federation, Collaborators: a.Collaborators}
+	parts := []*ImportArchive{first}
+	current := first
+	count := 0
+
+	next := func() *ImportArchive {
+		if count == maxRecords {
+			current = &ImportArchive{}
+			parts = append(parts, current)
+			count = 0
+		}
+		count++
+		return current
+	}
+
+	for _, round := range a.Rounds {
+		part := next()
+		part.Rounds = append(part.Rounds, round)
+	}
+	for _, update := range a.Updates {
+		part := next()
+		part.Updates = append(part.Updates, update)
+	}
+	for _, event := range a.Events {
+		part := next()
+		part.Events = append(part.Events, event)
+	}
+
+	return parts
+}
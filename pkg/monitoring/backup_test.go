@@ -0,0 +1,64 @@
+package monitoring
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	backup := &Backup{
+		CreatedAt: now,
+		Federations: []*ImportArchive{
+			{
+				Federation: &FederationMetrics{ID: "fed/../1", Name: "Federation 1", StartTime: now},
+				Rounds:     []*RoundMetrics{{ID: "r1", FederationID: "fed/../1", RoundNumber: 1, StartTime: now}},
+				Events:     []*MonitoringEvent{{ID: "e1", FederationID: "fed/../1", Timestamp: now, Message: "started"}},
+			},
+			{Federation: &FederationMetrics{ID: "fed-2", StartTime: now}},
+		},
+		Dashboards: []*Dashboard{{ID: "d1", Name: "Overview"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBackup(&buf, backup); err != nil {
+		t.Fatalf("WriteBackup: %v", err)
+	}
+	restored, err := ReadBackup(&buf)
+	if err != nil {
+		t.Fatalf("ReadBackup: %v", err)
+	}
+	if !reflect.DeepEqual(restored, backup) {
+		t.Errorf("ReadBackup() = %+v, want %+v", restored, backup)
+	}
+
+	if _, err := ReadBackup(bytes.NewReader([]byte("not a backup"))); err == nil {
+		t.Error("ReadBackup accepted an invalid file")
+	}
+}
+
+func TestImportArchiveSplit(t *testing.T) {
+	archive := &ImportArchive{
+		Federation:    &FederationMetrics{ID: "fed"},
+		Collaborators: []*CollaboratorMetrics{{ID: "c1"}},
+		Rounds:        []*RoundMetrics{{ID: "r1"}, {ID: "r2"}},
+		Updates:       []*ModelUpdateMetrics{{ID: "u1"}, {ID: "u2"}},
+		Events:        []*MonitoringEvent{{ID: "e1"}},
+	}
+
+	parts := archive.Split(2)
+	if len(parts) != 3 {
+		t.Fatalf("Split(2) returned %d parts, want 3", len(parts))
+	}
+	if parts[0].Federation == nil || len(parts[0].Collaborators) != 1 || len(parts[0].Rounds) != 2 {
+		t.Errorf("first part = %+v, want the federation, collaborators and both rounds", parts[0])
+	}
+	if parts[1].Federation != nil || len(parts[1].Updates) != 2 {
+		t.Errorf("second part = %+v, want both updates", parts[1])
+	}
+	if len(parts[2].Events) != 1 {
+		t.Errorf("third part = %+v, want the event", parts[2])
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// backupPageSize is the number of records requested per page while taking a
+// backup
+const backupPageSize = 1000
+
+// restoreBatchSize is the number of records sent per import request while
+// restoring a backup
+const restoreBatchSize = 5000
+
+// RestoreResult reports what restoring a backup added to the server
+type RestoreResult struct {
+	Federations []*monitoring.ImportResult `json:"federations"`
+	Dashboards  int                        `json:"dashboards"`
+}
+
+// Backup reads all federations with their collaborators, rounds, model
+// updates and events, and all dashboards, from the server
+func (c *Client) Backup(ctx context.Context) (*monitoring.Backup, error) {
+	backup := &monitoring.Backup{CreatedAt: time.Now().UTC()}
+
+	federations, err := listAll(func(filter *monitoring.MetricsFilter) ([]*monitoring.FederationMetrics, error) {
+		return c.ListFederations(ctx, filter)
+	}, &monitoring.MetricsFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, federation := range federations {
+		filter := &monitoring.MetricsFilter{FederationID: federation.ID}
+		archive := &monitoring.ImportArchive{Federation: federation}
+
+		if archive.Collaborators, err = listAll(func(filter *monitoring.MetricsFilter) ([]*monitoring.CollaboratorMetrics, error) {
+			return c.GetCollaborators(ctx, filter)
+		}, filter); err != nil {
+			return nil, err
+		}
+		if archive.Rounds, err = listAll(func(filter *monitoring.MetricsFilter) ([]*monitoring.RoundMetrics, error) {
+			return c.GetRounds(ctx, filter)
+		}, filter); err != nil {
+			return nil, err
+		}
+		if archive.Updates, err = listAll(func(filter *monitoring.MetricsFilter) ([]*monitoring.ModelUpdateMetrics, error) {
+			return c.GetModelUpdates(ctx, filter)
+		}, filter); err != nil {
+			return nil, err
+		}
+		if archive.Events, err = listAll(func(filter *monitoring.MetricsFilter) ([]*monitoring.MonitoringEvent, error) {
+			return c.GetEvents(ctx, filter)
+		}, filter); err != nil {
+			return nil, err
+		}
+
+		backup.Federations = append(backup.Federations, archive)
+	}
+
+	if backup.Dashboards, err = c.ListDashboards(ctx); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// Restore imports the federations and dashboards of a backup. Records that
+// the server already has are skipped, so an interrupted restore can be run
+// again.
+func (c *Client) Restore(ctx context.Context, backup *monitoring.Backup) (*RestoreResult, error) {
+	result := &RestoreResult{}
+
+	for _, archive := range backup.Federations {
+		total := &monitoring.ImportResult{FederationID: archive.FederationID()}
+		for _, part := range archive.Split(restoreBatchSize) {
+			imported, err := c.ImportHistory(ctx, part)
+			if err != nil {
+				return result, err
+			}
+			total.FederationCreated = total.FederationCreated || imported.FederationCreated
+			total.Collaborators += imported.Collaborators
+			total.Rounds += imported.Rounds
+			total.Updates += imported.Updates
+			total.Events += imported.Events
+			total.Skipped += imported.Skipped
+		}
+		result.Federations = append(result.Federations, total)
+	}
+
+	for _, dashboard := range backup.Dashboards {
+		if _, err := c.CreateDashboard(ctx, dashboard); err != nil {
+			return result, err
+		}
+		result.Dashboards++
+	}
+
+	return result, nil
+}
+
+// listAll requests the pages of a list until a page comes back short
+func listAll[T any](list func(*monitoring.MetricsFilter) ([]T, error), filter *monitoring.MetricsFilter) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		pageFilter := *filter
+		pageFilter.Page = page
+		pageFilter.PerPage = backupPageSize
+
+		records, err := list(&pageFilter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, records...)
+		if len(records) < backupPageSize {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,517 @@
+// Package client is a Go client of the monitoring API served by
+// monitoring.APIServer
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+// DefaultServer is the address of a monitoring server started with defaults
+const DefaultServer = "http://localhost:8080"
+
+// Config configures a Client. Zero values use the defaults.
+type Config struct {
+	Server       string        // base URL of the server (default: DefaultServer)
+	APIKey       string        // sent as X-API-Key
+	Token        string        // JWT sent as a bearer token when APIKey is empty
+	Timeout      time.Duration // per request (default: 30s)
+	MaxRetries   int           // retries of failed requests (default: 3, negative disables)
+	RetryBackoff time.Duration // delay before the first retry, doubled for each retry (default: 500ms)
+	HTTPClient   *http.Client  // replaces the default client, ignoring Timeout
+}
+
+// Client calls the monitoring API. It is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	apiKey       string
+	token        string
+	maxRetries   int
+	retryBackoff time.Duration
+	http         *http.Client
+}
+
+// APIError is an error response of the monitoring API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("monitoring server returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("monitoring server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response of the monitoring API
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New creates a client from config
+func New(config Config) (*Client, error) {
+	server := config.Server
+	if server == "" {
+		server = DefaultServer
+	}
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("monitoring server must be an http(s) URL, got %q", server)
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimRight(server, "/"),
+		apiKey:       config.APIKey,
+		token:        config.Token,
+		maxRetries:   config.MaxRetries,
+		retryBackoff: config.RetryBackoff,
+		http:         config.HTTPClient,
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = 3
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.retryBackoff <= 0 {
+		c.retryBackoff = 500 * time.Millisecond
+	}
+	if c.http == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		c.http = &http.Client{Timeout: timeout}
+	}
+	return c, nil
+}
+
+// Health checks that the server is up
+func (c *Client) Health(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
+
+// ListFederations lists the federations matching filter, which may be nil
+func (c *Client) ListFederations(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.FederationMetrics, error) {
+	var federations []*monitoring.FederationMetrics
+	if err := c.call(ctx, http.MethodGet, "/federations", filterQuery(filter), nil, &federations); err != nil {
+		return nil, fmt.Errorf("failed to list federations: %w", err)
+	}
+	return federations, nil
+}
+
+// GetFederation gets a federation
+func (c *Client) GetFederation(ctx context.Context, federationID string) (*monitoring.FederationMetrics, error) {
+	var federation monitoring.FederationMetrics
+	if err := c.call(ctx, http.MethodGet, "/federations/"+url.PathEscape(federationID), nil, nil, &federation); err != nil {
+		return nil, fmt.Errorf("failed to get federation %s: %w", federationID, err)
+	}
+	return &federation, nil
+}
+
+// RegisterFederation registers a federation, or updates the federation with
+// the same ID
+func (c *Client) RegisterFederation(ctx context.Context, federation *monitoring.FederationMetrics) error {
+	if err := c.call(ctx, http.MethodPost, "/federations", nil, federation, nil); err != nil {
+		return fmt.Errorf("failed to register federation %s: %w", federation.ID, err)
+	}
+	return nil
+}
+
+// CompareFederations compares federation b with a round by round. With a
+// threshold, the rounds and time each took to reach that accuracy are
+// compared too.
+func (c *Client) CompareFederations(ctx context.Context, a, b string, threshold *float64) (*monitoring.FederationComparison, error) {
+	query := url.Values{}
+	if threshold != nil {
+		query.Set("threshold", strconv.FormatFloat(*threshold, 'f', -1, 64))
+	}
+	var comparison monitoring.FederationComparison
+	path := "/federations/" + url.PathEscape(a) + "/compare/" + url.PathEscape(b)
+	if err := c.call(ctx, http.MethodGet, path, query, nil, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %w", a, b, err)
+	}
+	return &comparison, nil
+}
+
+// ControlFederation pauses, resumes or aborts a federation through its
+// aggregator and returns the federation with its new status
+func (c *Client) ControlFederation(ctx context.Context, federationID string, action monitoring.ControlAction, reason string) (*monitoring.FederationMetrics, error) {
+	var federation monitoring.FederationMetrics
+	path := "/federations/" + url.PathEscape(federationID) + "/" + string(action)
+	if err := c.call(ctx, http.MethodPost, path, nil, monitoring.ControlRequest{Reason: reason}, &federation); err != nil {
+		return nil, fmt.Errorf("failed to %s federation %s: %w", action, federationID, err)
+	}
+	return &federation, nil
+}
+
+// GetRounds lists the rounds matching filter, which may be nil
+func (c *Client) GetRounds(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.RoundMetrics, error) {
+	var rounds []*monitoring.RoundMetrics
+	if err := c.call(ctx, http.MethodGet, "/rounds", filterQuery(filter), nil, &rounds); err != nil {
+		return nil, fmt.Errorf("failed to get rounds: %w", err)
+	}
+	return rounds, nil
+}
+
+// GetCollaborators lists the collaborators matching filter, which may be nil
+func (c *Client) GetCollaborators(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.CollaboratorMetrics, error) {
+	var collaborators []*monitoring.CollaboratorMetrics
+	if err := c.call(ctx, http.MethodGet, "/collaborators", filterQuery(filter), nil, &collaborators); err != nil {
+		return nil, fmt.Errorf("failed to get collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+// RegisterCollaborator registers a collaborator of a federation
+func (c *Client) RegisterCollaborator(ctx context.Context, collaborator *monitoring.CollaboratorMetrics) error {
+	if err := c.call(ctx, http.MethodPost, "/collaborators", nil, collaborator, nil); err != nil {
+		return fmt.Errorf("failed to register collaborator %s: %w", collaborator.ID, err)
+	}
+	return nil
+}
+
+// GetEvents lists the events matching filter, which may be nil
+func (c *Client) GetEvents(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.MonitoringEvent, error) {
+	var events []*monitoring.MonitoringEvent
+	if err := c.call(ctx, http.MethodGet, "/events", filterQuery(filter), nil, &events); err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	return events, nil
+}
+
+// GetModelUpdates lists the model updates matching filter, which may be nil
+func (c *Client) GetModelUpdates(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.ModelUpdateMetrics, error) {
+	var updates []*monitoring.ModelUpdateMetrics
+	if err := c.call(ctx, http.MethodGet, "/updates", filterQuery(filter), nil, &updates); err != nil {
+		return nil, fmt.Errorf("failed to get model updates: %w", err)
+	}
+	return updates, nil
+}
+
+// ListDashboards lists the saved dashboards
+func (c *Client) ListDashboards(ctx context.Context) ([]*monitoring.Dashboard, error) {
+	var dashboards []*monitoring.Dashboard
+	if err := c.call(ctx, http.MethodGet, "/dashboards", nil, nil, &dashboards); err != nil {
+		return nil, fmt.Errorf("failed to list dashboards: %w", err)
+	}
+	return dashboards, nil
+}
+
+// CreateDashboard saves a dashboard, replacing a dashboard with the same ID
+func (c *Client) CreateDashboard(ctx context.Context, dashboard *monitoring.Dashboard) (*monitoring.Dashboard, error) {
+	var created monitoring.Dashboard
+	if err := c.call(ctx, http.MethodPost, "/dashboards", nil, dashboard, &created); err != nil {
+		return nil, fmt.Errorf("failed to create dashboard: %w", err)
+	}
+	return &created, nil
+}
+
+// GetLedger gets the participation ledger accounts matching filter, which may
+// be nil
+func (c *Client) GetLedger(ctx context.Context, filter *monitoring.MetricsFilter) ([]*monitoring.LedgerAccount, error) {
+	var accounts []*monitoring.LedgerAccount
+	if err := c.call(ctx, http.MethodGet, "/ledger", filterQuery(filter), nil, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to get ledger: %w", err)
+	}
+	return accounts, nil
+}
+
+// PostMetrics records a batch of metrics. Items are processed independently;
+// when some fail, the result lists them and an error is returned as well.
+func (c *Client) PostMetrics(ctx context.Context, batch *monitoring.IngestBatch) (*monitoring.IngestResult, error) {
+	var result monitoring.IngestResult
+	err := c.call(ctx, http.MethodPost, "/ingest", nil, batch, &result)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusMultiStatus {
+		return &result, fmt.Errorf("failed to record metrics: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record metrics: %w", err)
+	}
+	return &result, nil
+}
+
+// ImportHistory backfills the historical records of a federation
+func (c *Client) ImportHistory(ctx context.Context, archive *monitoring.ImportArchive) (*monitoring.ImportResult, error) {
+	var result monitoring.ImportResult
+	if err := c.call(ctx, http.MethodPost, "/import", nil, archive, &result); err != nil {
+		return nil, fmt.Errorf("failed to import history: %w", err)
+	}
+	return &result, nil
+}
+
+// ListAPIKeys lists the managed API keys
+func (c *Client) ListAPIKeys(ctx context.Context) ([]*monitoring.APIKey, error) {
+	var keys []*monitoring.APIKey
+	if err := c.call(ctx, http.MethodGet, "/apikeys", nil, nil, &keys); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateAPIKey issues a managed API key
+func (c *Client) CreateAPIKey(ctx context.Context, req monitoring.APIKeyRequest) (*monitoring.IssuedAPIKey, error) {
+	var issued monitoring.IssuedAPIKey
+	if err := c.call(ctx, http.MethodPost, "/apikeys", nil, req, &issued); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return &issued, nil
+}
+
+// RevokeAPIKey revokes a managed API key
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) (*monitoring.APIKey, error) {
+	var key monitoring.APIKey
+	if err := c.call(ctx, http.MethodDelete, "/apikeys/"+url.PathEscape(id), nil, nil, &key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return &key, nil
+}
+
+// RotateAPIKey replaces the secret of a managed API key
+func (c *Client) RotateAPIKey(ctx context.Context, id string) (*monitoring.IssuedAPIKey, error) {
+	var issued monitoring.IssuedAPIKey
+	if err := c.call(ctx, http.MethodPost, "/apikeys/"+url.PathEscape(id)+"/rotate", nil, nil, &issued); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+	return &issued, nil
+}
+
+// StreamEvents streams the events of a federation, or of all federations
+// when federationID is empty, until ctx is done or the connection drops. The
+// channel is closed when the stream ends.
+func (c *Client) StreamEvents(ctx context.Context, federationID string, eventTypes ...monitoring.MetricType) (<-chan *monitoring.MonitoringEvent, error) {
+	query := url.Values{}
+	if federationID != "" {
+		query.Set("federation_id", federationID)
+	}
+	if len(eventTypes) > 0 {
+		types := make([]string, len(eventTypes))
+		for i, eventType := range eventTypes {
+			types[i] = string(eventType)
+		}
+		query.Set("event_types", strings.Join(types, ","))
+	}
+
+	endpoint := c.baseURL + "/api/v1/ws"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	endpoint = "ws" + strings.TrimPrefix(endpoint, "http")
+
+	var conn *websocket.Conn
+	err := c.retry(ctx, func() (bool, time.Duration, error) {
+		var resp *http.Response
+		var err error
+		conn, resp, err = websocket.DefaultDialer.DialContext(ctx, endpoint, c.authHeader())
+		if err == nil {
+			return false, 0, nil
+		}
+		if resp == nil {
+			return ctx.Err() == nil, 0, err
+		}
+		defer resp.Body.Close()
+		return retryable(resp.StatusCode, http.MethodGet), retryAfter(resp), readAPIError(resp)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream events: %w", err)
+	}
+
+	events := make(chan *monitoring.MonitoringEvent)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		defer close(events)
+		defer conn.Close()
+		for {
+			var event monitoring.MonitoringEvent
+			if err := conn.ReadJSON(&event); err != nil {
+				return
+			}
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// call sends a request to endpoint under /api/v1 and decodes the data of the
+// response envelope into out when it is not nil
+func (c *Client) call(ctx context.Context, method, endpoint string, query url.Values, body, out interface{}) error {
+	target := c.baseURL + "/api/v1" + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	return c.retry(ctx, func() (bool, time.Duration, error) {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			return false, 0, err
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for key, values := range c.authHeader() {
+			req.Header[key] = values
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			// A request that may have reached the server is only repeated
+			// when repeating it is harmless
+			return ctx.Err() == nil && idempotent(method), 0, err
+		}
+		defer resp.Body.Close()
+
+		var envelope struct {
+			Success bool            `json:"success"`
+			Data    json.RawMessage `json:"data"`
+			Error   string          `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil && resp.StatusCode == http.StatusOK {
+			return false, 0, fmt.Errorf("invalid response: %w", err)
+		}
+		if out != nil && len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+			if err := json.Unmarshal(envelope.Data, out); err != nil {
+				return false, 0, fmt.Errorf("invalid response data: %w", err)
+			}
+		}
+		if resp.StatusCode != http.StatusOK || !envelope.Success {
+			return retryable(resp.StatusCode, method), retryAfter(resp), &APIError{StatusCode: resp.StatusCode, Message: envelope.Error}
+		}
+		return false, 0, nil
+	})
+}
+
+// retry runs attempt until it succeeds, fails permanently or runs out of
+// retries, backing off exponentially in between. Attempts return whether to
+// retry and how long the server asked to wait.
+func (c *Client) retry(ctx context.Context, attempt func() (bool, time.Duration, error)) error {
+	backoff := c.retryBackoff
+	for tries := 0; ; tries++ {
+		again, wait, err := attempt()
+		if err == nil || !again || tries >= c.maxRetries {
+			return err
+		}
+		select {
+		case <-time.After(max(backoff, wait)):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a request that got status is worth repeating
+func retryable(status int, method string) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// The request was turned away before it was processed
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+// retryAfter is the delay asked for by the Retry-After header of resp
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func idempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+func (c *Client) authHeader() http.Header {
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("X-API-Key", c.apiKey)
+	} else if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	return header
+}
+
+// readAPIError reads the error of a failed response
+func readAPIError(resp *http.Response) *APIError {
+	var envelope monitoring.APIResponse
+	json.NewDecoder(resp.Body).Decode(&envelope)
+	return &APIError{StatusCode: resp.StatusCode, Message: envelope.Error}
+}
+
+// filterQuery encodes filter as the query parameters read by the server
+func filterQuery(filter *monitoring.MetricsFilter) url.Values {
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+	if filter.FederationID != "" {
+		query.Set("federation_id", filter.FederationID)
+	}
+	if filter.CollaboratorID != "" {
+		query.Set("collaborator_id", filter.CollaboratorID)
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if filter.MetricType != "" {
+		query.Set("metric_type", string(filter.MetricType))
+	}
+	if filter.RoundNumber != nil {
+		query.Set("round_number", strconv.Itoa(*filter.RoundNumber))
+	}
+	if filter.Page > 0 {
+		query.Set("page", strconv.Itoa(filter.Page))
+	}
+	if filter.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(filter.PerPage))
+	}
+	if filter.StartTime != nil {
+		query.Set("start_time", filter.StartTime.Format(time.RFC3339))
+	}
+	if filter.EndTime != nil {
+		query.Set("end_time", filter.EndTime.Format(time.RFC3339))
+	}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	if len(filter.Labels) > 0 {
+		query.Set("label", monitoring.FormatLabelSelector(filter.Labels))
+	}
+	return query
+}
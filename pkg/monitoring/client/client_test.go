@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/monitoring"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, monitoring.MonitoringService) {
+	t.Helper()
+	config := &monitoring.MonitoringConfig{
+		Auth: monitoring.AuthConfig{
+			Enabled:    true,
+			APIKeyAuth: monitoring.APIKeyConfig{Enabled: true, Keys: map[string]string{"monitor-key": monitoring.RoleMonitor}},
+		},
+	}
+	storage := monitoring.NewMemoryStorage(config)
+	server := httptest.NewServer(monitoring.NewAPIServer(storage, config).Handler())
+	t.Cleanup(server.Close)
+	return server, storage
+}
+
+func TestClientQueries(t *testing.T) {
+	server, storage := newTestServer(t)
+	ctx := context.Background()
+	storage.RegisterFederation(ctx, &monitoring.FederationMetrics{ID: "fed-1", Name: "Federation 1", Status: monitoring.StatusRunning})
+	storage.RecordRoundStart(ctx, &monitoring.RoundMetrics{ID: "r1", FederationID: "fed-1", RoundNumber: 1})
+	storage.RecordRoundStart(ctx, &monitoring.RoundMetrics{ID: "r2", FederationID: "fed-1", RoundNumber: 2})
+
+	c, err := New(Config{Server: server.URL, APIKey: "monitor-key"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	federations, err := c.ListFederations(ctx, nil)
+	if err != nil || len(federations) != 1 || federations[0].ID != "fed-1" {
+		t.Fatalf("ListFederations() = %v, %v, want fed-1", federations, err)
+	}
+
+	round := 2
+	rounds, err := c.GetRounds(ctx, &monitoring.MetricsFilter{RoundNumber: &round})
+	if err != nil || len(rounds) != 1 || rounds[0].RoundNumber != 2 {
+		t.Fatalf("GetRounds() = %v, %v, want round 2", rounds, err)
+	}
+
+	if _, err := c.GetFederation(ctx, "missing"); !IsNotFound(err) {
+		t.Errorf("GetFederation(missing) error = %v, want not found", err)
+	}
+
+	unauthenticated, _ := New(Config{Server: server.URL, MaxRetries: -1})
+	var apiErr *APIError
+	if _, err := unauthenticated.ListFederations(ctx, nil); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated ListFederations() error = %v, want 401", err)
+	}
+}
+
+func TestClientRegister(t *testing.T) {
+	server, storage := newTestServer(t)
+	ctx := context.Background()
+	c, _ := New(Config{Server: server.URL, APIKey: "monitor-key"})
+
+	if err := c.RegisterFederation(ctx, &monitoring.FederationMetrics{ID: "fed-1", Name: "Federation 1", Status: monitoring.StatusRunning}); err != nil {
+		t.Fatalf("RegisterFederation() error = %v", err)
+	}
+	if err := c.RegisterCollaborator(ctx, &monitoring.CollaboratorMetrics{ID: "collab-1", FederationID: "fed-1", Status: monitoring.CollabStatusConnected}); err != nil {
+		t.Fatalf("RegisterCollaborator() error = %v", err)
+	}
+
+	if federation, err := storage.GetFederation(ctx, "fed-1"); err != nil || federation.Name != "Federation 1" {
+		t.Errorf("GetFederation(fed-1) = %v, %v, want the registered federation", federation, err)
+	}
+	collaborators, _ := storage.GetFederationCollaborators(ctx, "fed-1")
+	if len(collaborators) != 1 || collaborators[0].ID != "collab-1" {
+		t.Errorf("GetFederationCollaborators(fed-1) = %v, want collab-1", collaborators)
+	}
+}
+
+func TestClientPostMetrics(t *testing.T) {
+	server, storage := newTestServer(t)
+	ctx := context.Background()
+	c, _ := New(Config{Server: server.URL, APIKey: "monitor-key"})
+
+	event, _ := json.Marshal(monitoring.MonitoringEvent{FederationID: "fed-1", Type: monitoring.MetricTypeRound, Message: "started"})
+	result, err := c.PostMetrics(ctx, &monitoring.IngestBatch{Items: []monitoring.IngestItem{
+		{Type: monitoring.IngestEvent, Data: event},
+		{Type: "unknown", Data: json.RawMessage(`{}`)},
+	}})
+	if err == nil {
+		t.Error("PostMetrics() with a failing item returned no error")
+	}
+	if result == nil || result.Accepted != 1 || result.Failed != 1 {
+		t.Fatalf("PostMetrics() result = %+v, want 1 accepted and 1 failed", result)
+	}
+
+	events, _ := storage.GetEvents(ctx, &monitoring.MetricsFilter{FederationID: "fed-1"})
+	if len(events) != 1 {
+		t.Errorf("recorded %d events, want 1", len(events))
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(monitoring.APIResponse{Error: "starting"})
+			return
+		}
+		json.NewEncoder(w).Encode(monitoring.APIResponse{Success: true, Data: []*monitoring.FederationMetrics{{ID: "fed-1"}}})
+	}))
+	defer server.Close()
+
+	c, _ := New(Config{Server: server.URL, RetryBackoff: time.Millisecond})
+	federations, err := c.ListFederations(context.Background(), nil)
+	if err != nil || len(federations) != 1 {
+		t.Fatalf("ListFederations() = %v, %v, want success after retries", federations, err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", attempts.Load())
+	}
+
+	// Client errors are not retried
+	attempts.Store(0)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(monitoring.APIResponse{Error: "bad request"})
+	}))
+	defer failing.Close()
+	c, _ = New(Config{Server: failing.URL, RetryBackoff: time.Millisecond})
+	if _, err := c.ListFederations(context.Background(), nil); err == nil || attempts.Load() != 1 {
+		t.Errorf("ListFederations() = %v after %d attempts, want one failed attempt", err, attempts.Load())
+	}
+}
+
+func TestClientStreamEvents(t *testing.T) {
+	server, storage := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, _ := New(Config{Server: server.URL, APIKey: "monitor-key"})
+	events, err := c.StreamEvents(ctx, "fed-1")
+	if err != nil {
+		t.Fatalf("StreamEvents() error = %v", err)
+	}
+
+	// The server subscribes after the connection is upgraded, so events are
+	// recorded until one arrives
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-events:
+			if event == nil || event.FederationID != "fed-1" {
+				t.Fatalf("event = %+v, want an event of fed-1", event)
+			}
+			cancel()
+			for range events {
+			}
+			return
+		case <-ticker.C:
+			storage.RecordEvent(context.Background(), &monitoring.MonitoringEvent{FederationID: "fed-1", Type: monitoring.MetricTypeRound})
+		case <-ctx.Done():
+			t.Fatal("no event was streamed")
+		}
+	}
+}
+
+func TestClientBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	newServer := func() (*Client, monitoring.MonitoringService) {
+		config := &monitoring.MonitoringConfig{
+			Auth: monitoring.AuthConfig{
+				Enabled:    true,
+				APIKeyAuth: monitoring.APIKeyConfig{Enabled: true, Keys: map[string]string{"admin-key": monitoring.RoleAdmin}},
+			},
+		}
+		storage := monitoring.NewMemoryStorage(config)
+		server := httptest.NewServer(monitoring.NewAPIServer(storage, config).Handler())
+		t.Cleanup(server.Close)
+		c, err := New(Config{Server: server.URL, APIKey: "admin-key"})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return c, storage
+	}
+
+	source, storage := newServer()
+	start := time.Now().Add(-time.Hour)
+	storage.RegisterFederation(ctx, &monitoring.FederationMetrics{ID: "fed-1", Name: "Federation 1", Status: monitoring.StatusRunning, StartTime: start})
+	storage.RegisterCollaborator(ctx, &monitoring.CollaboratorMetrics{ID: "c1", FederationID: "fed-1", JoinTime: start})
+	storage.RecordRoundStart(ctx, &monitoring.RoundMetrics{ID: "r1", FederationID: "fed-1", RoundNumber: 1, StartTime: start})
+	storage.RecordModelUpdate(ctx, &monitoring.ModelUpdateMetrics{ID: "u1", FederationID: "fed-1", CollaboratorID: "c1", RoundNumber: 1, Timestamp: start})
+	storage.CreateDashboard(ctx, &monitoring.Dashboard{ID: "d1", Name: "Overview"})
+
+	backup, err := source.Backup(ctx)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if len(backup.Federations) != 1 || len(backup.Federations[0].Updates) != 1 || len(backup.Dashboards) != 1 {
+		t.Fatalf("Backup() = %+v, want fed-1 with its update and the dashboard", backup)
+	}
+
+	target, restored := newServer()
+	result, err := target.Restore(ctx, backup)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(result.Federations) != 1 || !result.Federations[0].FederationCreated || result.Federations[0].Updates != 1 || result.Dashboards != 1 {
+		t.Errorf("Restore() = %+v", result)
+	}
+	if federation, err := restored.GetFederation(ctx, "fed-1"); err != nil || federation.Name != "Federation 1" {
+		t.Errorf("restored federation = %v, %v", federation, err)
+	}
+	if _, err := restored.GetDashboard(ctx, "d1"); err != nil {
+		t.Errorf("restored dashboard: %v", err)
+	}
+
+	// Restoring again skips everything already restored
+	result, err = target.Restore(ctx, backup)
+	if err != nil || result.Federations[0].Updates != 0 || result.Federations[0].Skipped == 0 {
+		t.Errorf("second Restore() = %+v, %v, want everything skipped", result, err)
+	}
+}
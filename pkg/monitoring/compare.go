@@ -0,0 +1,322 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// FederationComparison lines up two federations round by round, such as a
+// FedAvg and a FedProx run on the same data. Deltas are B minus A.
+type FederationComparison struct {
+	A             ComparedFederation      `json:"a"`
+	B             ComparedFederation      `json:"b"`
+	Threshold     *float64                `json:"threshold,omitempty"` // accuracy the time to threshold is measured to
+	Rounds        []RoundComparison       `json:"rounds"`
+	Summary       ComparisonSummary       `json:"summary"`
+	Participation ParticipationComparison `json:"participation"`
+}
+
+// ComparedFederation sums up one side of a comparison
+type ComparedFederation struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Algorithm         string   `json:"algorithm"`
+	Status            string   `json:"status"`
+	Rounds            int      `json:"rounds"`
+	FinalAccuracy     *float64 `json:"final_accuracy,omitempty"`
+	BestAccuracy      *float64 `json:"best_accuracy,omitempty"`
+	FinalLoss         *float64 `json:"final_loss,omitempty"`
+	DurationSeconds   float64  `json:"duration_seconds"`              // from the start of the first round to the end of the last
+	RoundsToThreshold *int     `json:"rounds_to_threshold,omitempty"` // first round reaching the threshold
+	TimeToThreshold   *float64 `json:"time_to_threshold_seconds,omitempty"`
+	MeanParticipants  float64  `json:"mean_participants"`
+	Collaborators     int      `json:"collaborators"` // distinct collaborators with aggregated updates
+}
+
+// RoundComparison compares the rounds with the same number. Values of a
+// federation without that round are omitted.
+type RoundComparison struct {
+	RoundNumber       int      `json:"round_number"`
+	AccuracyA         *float64 `json:"accuracy_a,omitempty"`
+	AccuracyB         *float64 `json:"accuracy_b,omitempty"`
+	AccuracyDelta     *float64 `json:"accuracy_delta,omitempty"`
+	LossA             *float64 `json:"loss_a,omitempty"`
+	LossB             *float64 `json:"loss_b,omitempty"`
+	LossDelta         *float64 `json:"loss_delta,omitempty"`
+	ParticipantsA     *int     `json:"participants_a,omitempty"`
+	ParticipantsB     *int     `json:"participants_b,omitempty"`
+	ParticipantsDelta *int     `json:"participants_delta,omitempty"`
+}
+
+// ComparisonSummary holds the differences between the two federations
+type ComparisonSummary struct {
+	FinalAccuracyDelta     *float64 `json:"final_accuracy_delta,omitempty"`
+	BestAccuracyDelta      *float64 `json:"best_accuracy_delta,omitempty"`
+	FinalLossDelta         *float64 `json:"final_loss_delta,omitempty"`
+	MeanAccuracyDelta      *float64 `json:"mean_accuracy_delta,omitempty"` // over the rounds both have an accuracy for
+	RoundsToThresholdDelta *int     `json:"rounds_to_threshold_delta,omitempty"`
+	TimeToThresholdDelta   *float64 `json:"time_to_threshold_delta_seconds,omitempty"`
+	DurationDelta          float64  `json:"duration_delta_seconds"`
+}
+
+// ParticipationComparison compares who took part in the two federations
+type ParticipationComparison struct {
+	MeanParticipantsDelta float64  `json:"mean_participants_delta"`
+	CollaboratorsDelta    int      `json:"collaborators_delta"`
+	Shared                []string `json:"shared"`
+	OnlyA                 []string `json:"only_a"`
+	OnlyB                 []string `json:"only_b"`
+}
+
+// CompareFederations compares federations a and b. With a threshold, the
+// rounds and time each took to reach that accuracy are compared too.
+func CompareFederations(ctx context.Context, service MonitoringService, a, b string, threshold *float64) (*FederationComparison, error) {
+	sideA, err := loadComparedFederation(ctx, service, a, threshold)
+	if err != nil {
+		return nil, err
+	}
+	sideB, err := loadComparedFederation(ctx, service, b, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &FederationComparison{
+		A:         sideA.summary,
+		B:         sideB.summary,
+		Threshold: threshold,
+		Rounds:    compareRounds(sideA.rounds, sideB.rounds),
+	}
+
+	summary := &comparison.Summary
+	summary.FinalAccuracyDelta = floatDelta(sideA.summary.FinalAccuracy, sideB.summary.FinalAccuracy)
+	summary.BestAccuracyDelta = floatDelta(sideA.summary.BestAccuracy, sideB.summary.BestAccuracy)
+	summary.FinalLossDelta = floatDelta(sideA.summary.FinalLoss, sideB.summary.FinalLoss)
+	summary.RoundsToThresholdDelta = intDelta(sideA.summary.RoundsToThreshold, sideB.summary.RoundsToThreshold)
+	summary.TimeToThresholdDelta = floatDelta(sideA.summary.TimeToThreshold, sideB.summary.TimeToThreshold)
+	summary.DurationDelta = sideB.summary.DurationSeconds - sideA.summary.DurationSeconds
+
+	var total float64
+	var compared int
+	for _, round := range comparison.Rounds {
+		if round.AccuracyDelta != nil {
+			total += *round.AccuracyDelta
+			compared++
+		}
+	}
+	if compared > 0 {
+		mean := total / float64(compared)
+		summary.MeanAccuracyDelta = &mean
+	}
+
+	participation := &comparison.Participation
+	participation.MeanParticipantsDelta = sideB.summary.MeanParticipants - sideA.summary.MeanParticipants
+	participation.CollaboratorsDelta = sideB.summary.Collaborators - sideA.summary.Collaborators
+	participation.Shared, participation.OnlyA, participation.OnlyB = []string{}, []string{}, []string{}
+	for id := range sideA.collaborators {
+		if sideB.collaborators[id] {
+			participation.Shared = append(participation.Shared, id)
+		} else {
+			participation.OnlyA = append(participation.OnlyA, id)
+		}
+	}
+	for id := range sideB.collaborators {
+		if !sideA.collaborators[id] {
+			participation.OnlyB = append(participation.OnlyB, id)
+		}
+	}
+	sort.Strings(participation.Shared)
+	sort.Strings(participation.OnlyA)
+	sort.Strings(participation.OnlyB)
+
+	return comparison, nil
+}
+
+// comparedSide is a federation with the records it is compared by
+type comparedSide struct {
+	summary       ComparedFederation
+	rounds        map[int]*RoundMetrics
+	collaborators map[string]bool
+}
+
+func loadComparedFederation(ctx context.Context, service MonitoringService, federationID string, threshold *float64) (*comparedSide, error) {
+	federation, err := service.GetFederation(ctx, federationID)
+	if err != nil {
+		return nil, err
+	}
+	rounds, err := service.GetFederationRounds(ctx, federationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rounds of %s: %w", federationID, err)
+	}
+	updates, err := service.GetModelUpdates(ctx, &MetricsFilter{FederationID: federationID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model updates of %s: %w", federationID, err)
+	}
+
+	side := &comparedSide{
+		summary: ComparedFederation{
+			ID:        federation.ID,
+			Name:      federation.Name,
+			Algorithm: federation.Algorithm,
+			Status:    string(federation.Status),
+		},
+		rounds:        make(map[int]*RoundMetrics, len(rounds)),
+		collaborators: make(map[string]bool),
+	}
+
+	// A round recorded more than once, e.g. after a restart, counts with its
+	// latest attempt
+	for _, round := range rounds {
+		if existing, exists := side.rounds[round.RoundNumber]; !exists || round.StartTime.After(existing.StartTime) {
+			side.rounds[round.RoundNumber] = round
+		}
+	}
+	numbers := make([]int, 0, len(side.rounds))
+	for number := range side.rounds {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	summary := &side.summary
+	summary.Rounds = len(numbers)
+	var start, end time.Time
+	var participants int
+	for _, number := range numbers {
+		round := side.rounds[number]
+		if start.IsZero() || (!round.StartTime.IsZero() && round.StartTime.Before(start)) {
+			start = round.StartTime
+		}
+		finished := roundFinished(round)
+		if finished.After(end) {
+			end = finished
+		}
+		participants += round.ParticipantCount
+
+		if round.ModelAccuracy != nil {
+			summary.FinalAccuracy = round.ModelAccuracy
+			if summary.BestAccuracy == nil || *round.ModelAccuracy > *summary.BestAccuracy {
+				summary.BestAccuracy = round.ModelAccuracy
+			}
+			if threshold != nil && summary.RoundsToThreshold == nil && *round.ModelAccuracy >= *threshold {
+				roundNumber := number
+				summary.RoundsToThreshold = &roundNumber
+				if !start.IsZero() && !finished.IsZero() {
+					seconds := finished.Sub(start).Seconds()
+					summary.TimeToThreshold = &seconds
+				}
+			}
+		}
+		if round.ModelLoss != nil {
+			summary.FinalLoss = round.ModelLoss
+		}
+	}
+	if !start.IsZero() && end.After(start) {
+		summary.DurationSeconds = end.Sub(start).Seconds()
+	}
+	if len(numbers) > 0 {
+		summary.MeanParticipants = float64(participants) / float64(len(numbers))
+	}
+
+	for _, update := range updates {
+		side.collaborators[update.CollaboratorID] = true
+	}
+	summary.Collaborators = len(side.collaborators)
+
+	return side, nil
+}
+
+// roundFinished returns when a round ended, or zero if it is still running
+func roundFinished(round *RoundMetrics) time.Time {
+	if round.EndTime != nil {
+		return *round.EndTime
+	}
+	if round.Duration > 0 && !round.StartTime.IsZero() {
+		return round.StartTime.Add(round.Duration)
+	}
+	return time.Time{}
+}
+
+// compareRounds pairs the rounds of two federations by number
+func compareRounds(a, b map[int]*RoundMetrics) []RoundComparison {
+	numbers := make(map[int]bool, len(a)+len(b))
+	for number := range a {
+		numbers[number] = true
+	}
+	for number := range b {
+		numbers[number] = true
+	}
+
+	comparisons := make([]RoundComparison, 0, len(numbers))
+	for number := range numbers {
+		comparison := RoundComparison{RoundNumber: number}
+		if round, exists := a[number]; exists {
+			participants := round.ParticipantCount
+			comparison.AccuracyA, comparison.LossA, comparison.ParticipantsA = round.ModelAccuracy, round.ModelLoss, &participants
+		}
+		if round, exists := b[number]; exists {
+			participants := round.ParticipantCount
+			comparison.AccuracyB, comparison.LossB, comparison.ParticipantsB = round.ModelAccuracy, round.ModelLoss, &participants
+		}
+		comparison.AccuracyDelta = floatDelta(comparison.AccuracyA, comparison.AccuracyB)
+		comparison.LossDelta = floatDelta(comparison.LossA, comparison.LossB)
+		comparison.ParticipantsDelta = intDelta(comparison.ParticipantsA, comparison.ParticipantsB)
+		comparisons = append(comparisons, comparison)
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].RoundNumber < comparisons[j].RoundNumber
+	})
+	return comparisons
+}
+
+// floatDelta returns b - a, or nil unless both are set
+func floatDelta(a, b *float64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	delta := *b - *a
+	return &delta
+}
+
+// intDelta returns b - a, or nil unless both are set
+func intDelta(a, b *int) *int {
+	if a == nil || b == nil {
+		return nil
+	}
+	delta := *b - *a
+	return &delta
+}
+
+func (s *APIServer) handleCompareFederations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var threshold *float64
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid threshold", err)
+			return
+		}
+		threshold = &parsed
+	}
+
+	for _, id := range []string{vars["id"], vars["other"]} {
+		if _, err := s.service.GetFederation(r.Context(), id); err != nil {
+			s.sendError(w, http.StatusNotFound, "Federation not found", err)
+			return
+		}
+	}
+
+	comparison, err := CompareFederations(r.Context(), s.service, vars["id"], vars["other"], threshold)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to compare federations", err)
+		return
+	}
+
+	s.sendSuccess(w, comparison)
+}
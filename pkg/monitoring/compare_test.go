@@ -0,0 +1,94 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCompareFederations(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	start := time.Now().Add(-time.Hour)
+
+	record := func(federationID string, accuracies []float64, collaborators ...string) {
+		storage.RegisterFederation(ctx, &FederationMetrics{ID: federationID, Name: federationID})
+		for i, accuracy := range accuracies {
+			accuracy := accuracy
+			end := start.Add(time.Duration(i+1) * time.Minute)
+			storage.RecordRoundStart(ctx, &RoundMetrics{
+				ID:               fmt.Sprintf("%s-%d", federationID, i+1),
+				FederationID:     federationID,
+				RoundNumber:      i + 1,
+				StartTime:        end.Add(-time.Minute),
+				EndTime:          &end,
+				ParticipantCount: len(collaborators),
+				ModelAccuracy:    &accuracy,
+			})
+		}
+		for _, collaborator := range collaborators {
+			storage.RecordModelUpdate(ctx, &ModelUpdateMetrics{FederationID: federationID, CollaboratorID: collaborator, RoundNumber: 1})
+		}
+	}
+	record("fedavg", []float64{0.5, 0.7, 0.8}, "c1", "c2")
+	record("fedprox", []float64{0.6, 0.9}, "c2", "c3", "c4")
+
+	threshold := 0.75
+	comparison, err := CompareFederations(ctx, storage, "fedavg", "fedprox", &threshold)
+	if err != nil {
+		t.Fatalf("CompareFederations: %v", err)
+	}
+
+	if len(comparison.Rounds) != 3 {
+		t.Fatalf("rounds = %+v", comparison.Rounds)
+	}
+	if delta := comparison.Rounds[0].AccuracyDelta; delta == nil || *delta < 0.099 || *delta > 0.101 {
+		t.Errorf("round 1 accuracy delta = %v, want 0.1", delta)
+	}
+	if last := comparison.Rounds[2]; last.AccuracyB != nil || last.AccuracyDelta != nil || *last.AccuracyA != 0.8 {
+		t.Errorf("round 3 = %+v, want only A", last)
+	}
+
+	if *comparison.A.RoundsToThreshold != 3 || *comparison.B.RoundsToThreshold != 2 || *comparison.Summary.RoundsToThresholdDelta != -1 {
+		t.Errorf("rounds to threshold = %d, %d", *comparison.A.RoundsToThreshold, *comparison.B.RoundsToThreshold)
+	}
+	if delta := comparison.Summary.TimeToThresholdDelta; delta == nil || *delta != -60 {
+		t.Errorf("time to threshold delta = %v, want -60", delta)
+	}
+	if delta := *comparison.Summary.FinalAccuracyDelta; delta < 0.099 || delta > 0.101 {
+		t.Errorf("final accuracy delta = %v, want 0.1", delta)
+	}
+
+	participation := comparison.Participation
+	if participation.CollaboratorsDelta != 1 || participation.MeanParticipantsDelta != 1 {
+		t.Errorf("participation = %+v", participation)
+	}
+	if len(participation.Shared) != 1 || participation.Shared[0] != "c2" || len(participation.OnlyA) != 1 || len(participation.OnlyB) != 2 {
+		t.Errorf("collaborators = %+v", participation)
+	}
+}
+
+func TestCompareFederationsAPI(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-b"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-b-key", `{"id": "fed-x"}`, nil)
+
+	requests := []struct {
+		path   string
+		apiKey string
+		want   int
+	}{
+		{"/api/v1/federations/fed-a/compare/fed-b?threshold=0.9", "lab-a-key", http.StatusOK},
+		{"/api/v1/federations/fed-a/compare/fed-b?threshold=high", "lab-a-key", http.StatusBadRequest},
+		{"/api/v1/federations/fed-a/compare/fed-x", "lab-a-key", http.StatusNotFound},
+		{"/api/v1/federations/fed-a/compare/missing", "lab-a-key", http.StatusNotFound},
+	}
+	for _, tt := range requests {
+		if code := doAPIKeyRequest(t, server, "GET", tt.path, tt.apiKey, "", nil); code != tt.want {
+			t.Errorf("GET %s as %s = %d, want %d", tt.path, tt.apiKey, code, tt.want)
+		}
+	}
+}
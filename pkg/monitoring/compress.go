@@ -0,0 +1,172 @@
+package monitoring
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressResponses compresses responses with gzip or deflate when the
+// client accepts it. WebSocket upgrades are passed through untouched.
+func compressResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding picks gzip or deflate from an Accept-Encoding header
+func acceptedEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter compresses the body written through it. Whether to compress
+// is decided when the headers are written, so responses that are already
+// encoded or have no body are left alone.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	wroteHeader bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") == "" && status != http.StatusNoContent && status != http.StatusNotModified {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			cw.writer = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.writer, _ = flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.writer == nil {
+		return cw.ResponseWriter.Write(data)
+	}
+	return cw.writer.Write(data)
+}
+
+// Close flushes the compressed body
+func (cw *compressWriter) Close() error {
+	if cw.writer == nil {
+		return nil
+	}
+	return cw.writer.Close()
+}
+
+// etagResponses adds an ETag to JSON responses of GET requests and answers
+// 304 Not Modified when it matches If-None-Match, so that polling clients do
+// not download unchanged data again. Other responses, such as exports and
+// model downloads, are streamed through.
+func etagResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		if !ew.buffering {
+			return
+		}
+
+		if ew.status == http.StatusOK {
+			sum := sha256.Sum256(ew.body.Bytes())
+			etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.WriteHeader(ew.status)
+		w.Write(ew.body.Bytes())
+	})
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers JSON responses so that their ETag can be computed, and
+// passes other responses straight through
+type etagWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	buffering   bool
+}
+
+func (ew *etagWriter) WriteHeader(status int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = status
+	ew.buffering = strings.HasPrefix(ew.Header().Get("Content-Type"), "application/json")
+	if !ew.buffering {
+		ew.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (ew *etagWriter) Write(data []byte) (int, error) {
+	if !ew.wroteHeader {
+		if ew.Header().Get("Content-Type") == "" {
+			ew.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.buffering {
+		return ew.body.Write(data)
+	}
+	return ew.ResponseWriter.Write(data)
+}
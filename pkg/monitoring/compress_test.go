@@ -0,0 +1,123 @@
+package monitoring
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newCompressTestServer(t *testing.T) *APIServer {
+	t.Helper()
+	config := &MonitoringConfig{}
+	storage := NewMemoryStorage(config)
+	storage.RegisterFederation(context.Background(), &FederationMetrics{ID: "fed-1", Name: "Federation 1", Status: StatusRunning})
+	return NewAPIServer(storage, config)
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip;q=0.5", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"br", ""},
+	}
+	for _, tt := range tests {
+		if got := acceptedEncoding(tt.header); got != tt.want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressedResponses(t *testing.T) {
+	server := newCompressTestServer(t)
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/federations/fed-1", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Content-Encoding"); got != encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, encoding)
+			}
+			var reader io.Reader
+			if encoding == "gzip" {
+				gz, err := gzip.NewReader(rr.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				reader = gz
+			} else {
+				reader = flate.NewReader(rr.Body)
+			}
+
+			var resp struct {
+				Data FederationMetrics `json:"data"`
+			}
+			if err := json.NewDecoder(reader).Decode(&resp); err != nil {
+				t.Fatalf("decode error = %v", err)
+			}
+			if resp.Data.ID != "fed-1" {
+				t.Errorf("federation = %+v, want fed-1", resp.Data)
+			}
+		})
+	}
+}
+
+func TestETagNotModified(t *testing.T) {
+	server := newCompressTestServer(t)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/federations/fed-1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first response = %d with ETag %q, want 200 with an ETag", first.Code, etag)
+	}
+
+	cached := get(etag)
+	if cached.Code != http.StatusNotModified || cached.Body.Len() != 0 {
+		t.Errorf("conditional response = %d with %d bytes, want an empty 304", cached.Code, cached.Body.Len())
+	}
+
+	// A changed federation has a new ETag
+	server.service.UpdateFederation(context.Background(), "fed-1", &FederationMetrics{Name: "Renamed", Status: StatusRunning})
+	changed := get(etag)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("changed response = %d with ETag %q, want 200 with a new ETag", changed.Code, changed.Header().Get("ETag"))
+	}
+}
+
+func TestETagSkipsNonJSON(t *testing.T) {
+	server := newCompressTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/federations/fed-1/export/rounds?format=csv", nil)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", rr.Code)
+	}
+	if rr.Header().Get("ETag") != "" {
+		t.Errorf("export has ETag %q, want none", rr.Header().Get("ETag"))
+	}
+}
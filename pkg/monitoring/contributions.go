@@ -0,0 +1,159 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContributionScore is how much a collaborator's update contributed to the
+// model a round aggregated. Both scores compare the change the update made
+// to the model it was trained on with the change the aggregation made.
+type ContributionScore struct {
+	ID             string    `json:"id"`
+	FederationID   string    `json:"federation_id"`
+	CollaboratorID string    `json:"collaborator_id"`
+	RoundNumber    int       `json:"round_number"`
+	Timestamp      time.Time `json:"timestamp"`
+	Cosine         float64   `json:"cosine"` // Cosine similarity of the update's change with the aggregate's
+	Share          float64   `json:"share"`  // Weighted projection onto the aggregate's change; a round's shares sum to about 1
+}
+
+// ContributionSummary sums up the contribution scores of a collaborator in a
+// federation
+type ContributionSummary struct {
+	FederationID   string  `json:"federation_id"`
+	CollaboratorID string  `json:"collaborator_id"`
+	Rounds         int     `json:"rounds"`
+	MeanCosine     float64 `json:"mean_cosine"`
+	MeanShare      float64 `json:"mean_share"`
+	TotalShare     float64 `json:"total_share"`
+	OpposedRounds  int     `json:"opposed_rounds"` // Rounds whose update moved away from the aggregate
+	LastRound      int     `json:"last_round"`
+}
+
+// RecordContributions stores the contribution scores of an aggregation
+func (m *MemoryStorage) RecordContributions(ctx context.Context, scores []*ContributionScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, score := range scores {
+		if score.ID == "" {
+			score.ID = uuid.New().String()
+		}
+		if score.Timestamp.IsZero() {
+			score.Timestamp = time.Now()
+		}
+		m.contributions = append(m.contributions, score)
+		m.ledger.recordContribution(score)
+	}
+	m.ledger.persist()
+
+	event := contributionsRecordedEvent(scores)
+	m.events = append(m.events, event)
+	m.notifySubscribers(event)
+
+	return nil
+}
+
+// GetContributions returns the contribution scores matching filter, latest
+// round first
+func (m *MemoryStorage) GetContributions(ctx context.Context, filter *MetricsFilter) ([]*ContributionScore, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]*ContributionScore, 0)
+	for _, score := range m.contributions {
+		if matchesContributionFilter(score, filter) {
+			result := *score
+			results = append(results, &result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].RoundNumber != results[j].RoundNumber {
+			return results[i].RoundNumber > results[j].RoundNumber
+		}
+		return results[i].CollaboratorID < results[j].CollaboratorID
+	})
+
+	if filter == nil || filter.Page <= 0 {
+		return results, nil
+	}
+	perPage := filter.PerPage
+	if perPage <= 0 {
+		perPage = 50
+	}
+	start := (filter.Page - 1) * perPage
+	if start >= len(results) {
+		return []*ContributionScore{}, nil
+	}
+	return results[start:min(start+perPage, len(results))], nil
+}
+
+// GetContributionSummary sums up the contribution scores of each
+// collaborator of a federation, highest total share first
+func (m *MemoryStorage) GetContributionSummary(ctx context.Context, federationID string) ([]*ContributionSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summaries := make(map[string]*ContributionSummary)
+	for _, score := range m.contributions {
+		if score.FederationID != federationID {
+			continue
+		}
+		summary, exists := summaries[score.CollaboratorID]
+		if !exists {
+			summary = &ContributionSummary{FederationID: federationID, CollaboratorID: score.CollaboratorID}
+			summaries[score.CollaboratorID] = summary
+		}
+		summary.Rounds++
+		summary.MeanCosine += score.Cosine
+		summary.TotalShare += score.Share
+		if score.Cosine < 0 {
+			summary.OpposedRounds++
+		}
+		summary.LastRound = max(summary.LastRound, score.RoundNumber)
+	}
+
+	results := make([]*ContributionSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		summary.MeanCosine /= float64(summary.Rounds)
+		summary.MeanShare = summary.TotalShare / float64(summary.Rounds)
+		results = append(results, summary)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].TotalShare != results[j].TotalShare {
+			return results[i].TotalShare > results[j].TotalShare
+		}
+		return results[i].CollaboratorID < results[j].CollaboratorID
+	})
+	return results, nil
+}
+
+func matchesContributionFilter(score *ContributionScore, filter *MetricsFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if !filter.matchesFederation(score.FederationID) {
+		return false
+	}
+	if filter.CollaboratorID != "" && score.CollaboratorID != filter.CollaboratorID {
+		return false
+	}
+	if filter.RoundNumber != nil && score.RoundNumber != *filter.RoundNumber {
+		return false
+	}
+	if filter.StartTime != nil && score.Timestamp.Before(*filter.StartTime) {
+		return false
+	}
+	if filter.EndTime != nil && score.Timestamp.After(*filter.EndTime) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,276 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ishaileshpant/fl-go/api/adminpb"
+	"github.com/ishaileshpant/fl-go/pkg/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// defaultControlTimeout bounds a control call to an aggregator
+const defaultControlTimeout = 10 * time.Second
+
+// ControlAction is a change to a running federation requested through the API
+type ControlAction string
+
+const (
+	ControlPause  ControlAction = "pause"
+	ControlResume ControlAction = "resume"
+	ControlAbort  ControlAction = "abort"
+)
+
+// ControlConfig configures how the server reaches the aggregators of the
+// federations it pauses, resumes or aborts. The addresses come from the
+// server's configuration only: reported federation metrics are writable by
+// every monitor key, and the server sends its admin token to the address it
+// dials.
+type ControlConfig struct {
+	AdminToken  string             `yaml:"admin_token"` // aggregator.admin_token of the federations' plans
+	TLS         security.TLSConfig `yaml:"tls"`         // client certificate for aggregators that require mTLS
+	Timeout     time.Duration      `yaml:"timeout"`     // default: 10s
+	Aggregators map[string]string  `yaml:"aggregators"` // AdminService address of each controllable federation, by federation ID
+}
+
+// adminAddress returns the configured AdminService address of a federation,
+// or "" if the federation cannot be controlled
+func (c ControlConfig) adminAddress(federationID string) string {
+	return c.Aggregators[federationID]
+}
+
+// FederationController applies a control action to the aggregator of a
+// federation and returns the aggregator's state afterwards. It also reads the
+// live state of the aggregator.
+type FederationController interface {
+	Control(ctx context.Context, federation *FederationMetrics, action ControlAction, reason string) (string, error)
+	Status(ctx context.Context, federation *FederationMetrics) (*AggregatorStatus, error)
+}
+
+// AggregatorStatus is the live state of a federation's aggregator and of the
+// round it collects updates for
+type AggregatorStatus struct {
+	State           string   `json:"state"` // idle, running, paused or aborted
+	CurrentRound    int      `json:"current_round"`
+	RoundsCompleted int      `json:"rounds_completed"`
+	UpdatesAccepted int64    `json:"updates_accepted"`
+	UpdatesDropped  int64    `json:"updates_dropped"`
+	LogLevel        string   `json:"log_level"`
+	ModelHash       string   `json:"model_hash,omitempty"` // the model the round trains
+	ExpectedUpdates int      `json:"expected_updates"`
+	Received        []string `json:"received"`          // collaborators whose updates the round holds
+	Pending         []string `json:"pending,omitempty"` // sync rounds: expected collaborators that have not submitted
+}
+
+// grpcController calls the AdminService of aggregators
+type grpcController struct {
+	config ControlConfig
+}
+
+// connect dials the AdminService of a federation's aggregator at its
+// configured address. The returned context carries the admin token and the
+// call timeout.
+func (c *grpcController) connect(ctx context.Context, federation *FederationMetrics) (adminpb.AdminServiceClient, context.Context, func(), error) {
+	address := c.config.adminAddress(federation.ID)
+	if address == "" {
+		return nil, nil, nil, fmt.Errorf("no admin address is configured for federation %s", federation.ID)
+	}
+	tlsManager, err := security.NewTLSManager(c.config.TLS, "certs")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to initialize TLS: %w", err)
+	}
+	dialOpts, err := tlsManager.NewClientDialOptions()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get client dial options: %w", err)
+	}
+
+	conn, err := grpc.NewClient(address, dialOpts...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to the aggregator: %w", err)
+	}
+
+	timeout := c.config.Timeout
+	if timeout <= 0 {
+		timeout = defaultControlTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	if c.config.AdminToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", c.config.AdminToken)
+	}
+	return adminpb.NewAdminServiceClient(conn), ctx, func() {
+		cancel()
+		conn.Close()
+	}, nil
+}
+
+func (c *grpcController) Control(ctx context.Context, federation *FederationMetrics, action ControlAction, reason string) (string, error) {
+	client, ctx, done, err := c.connect(ctx, federation)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	req := &adminpb.ControlRequest{Reason: reason}
+	var resp *adminpb.ControlResponse
+	switch action {
+	case ControlPause:
+		resp, err = client.Pause(ctx, req)
+	case ControlResume:
+		resp, err = client.Resume(ctx, req)
+	case ControlAbort:
+		resp, err = client.Abort(ctx, req)
+	default:
+		return "", fmt.Errorf("unknown control action %q", action)
+	}
+	if err != nil {
+		return "", err
+	}
+	return resp.GetState(), nil
+}
+
+func (c *grpcController) Status(ctx context.Context, federation *FederationMetrics) (*AggregatorStatus, error) {
+	client, ctx, done, err := c.connect(ctx, federation)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	st, err := client.GetStatus(ctx, &adminpb.StatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	round, err := client.GetRoundState(ctx, &adminpb.RoundStateRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &AggregatorStatus{
+		State:           st.GetState(),
+		CurrentRound:    int(st.GetCurrentRound()),
+		RoundsCompleted: int(st.GetRoundsCompleted()),
+		UpdatesAccepted: st.GetUpdatesAccepted(),
+		UpdatesDropped:  st.GetUpdatesDropped(),
+		LogLevel:        st.GetLogLevel(),
+		ModelHash:       round.GetModelHash(),
+		ExpectedUpdates: int(round.GetExpected()),
+		Received:        round.GetReceived(),
+		Pending:         round.GetPending(),
+	}, nil
+}
+
+// controlTransitions are the statuses a federation may be controlled from,
+// and the status each action leads to
+var controlTransitions = map[ControlAction]struct {
+	from []FederationStatus
+	to   FederationStatus
+}{
+	ControlPause:  {[]FederationStatus{StatusRunning}, StatusPaused},
+	ControlResume: {[]FederationStatus{StatusPaused}, StatusRunning},
+	ControlAbort:  {[]FederationStatus{StatusPending, StatusRunning, StatusPaused}, StatusAborted},
+}
+
+// controlledStatus returns the status action moves a federation in status to
+func controlledStatus(status FederationStatus, action ControlAction) (FederationStatus, error) {
+	transition, ok := controlTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("unknown control action %q", action)
+	}
+	for _, from := range transition.from {
+		if status == from {
+			return transition.to, nil
+		}
+	}
+	return "", fmt.Errorf("cannot %s a federation that is %s", action, status)
+}
+
+// ControlRequest is the optional body of a control request
+type ControlRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// handleControlFederation pauses, resumes or aborts a federation through its
+// aggregator, then records the new status and an event
+func (s *APIServer) handleControlFederation(action ControlAction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := mux.Vars(r)["id"]
+
+		var req ControlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+
+		federation, err := s.service.GetFederation(ctx, id)
+		if err != nil {
+			s.sendError(w, http.StatusNotFound, "Federation not found", err)
+			return
+		}
+		previous := federation.Status
+		status, err := controlledStatus(previous, action)
+		if err != nil {
+			s.sendError(w, http.StatusConflict, "Invalid federation status", err)
+			return
+		}
+		if s.config.Control.adminAddress(id) == "" {
+			s.sendError(w, http.StatusConflict, "Federation has no configured admin address", nil)
+			return
+		}
+
+		state, err := s.controller.Control(ctx, federation, action, req.Reason)
+		if err != nil {
+			s.sendError(w, http.StatusBadGateway, "Failed to reach the aggregator", err)
+			return
+		}
+
+		now := time.Now()
+		federation.Status = status
+		federation.LastUpdate = now
+		if status == StatusAborted {
+			federation.EndTime = &now
+		}
+		if err := s.service.UpdateFederation(ctx, id, federation); err != nil {
+			s.sendError(w, http.StatusInternalServerError, "Failed to update federation", err)
+			return
+		}
+
+		user := ""
+		if userCtx, ok := GetUserFromContext(ctx); ok {
+			user = userCtx.UserID
+		}
+		if err := s.service.RecordEvent(ctx, federationControlledEvent(federation, action, previous, state, req.Reason, user)); err != nil {
+			s.sendError(w, http.StatusInternalServerError, "Failed to record event", err)
+			return
+		}
+
+		s.sendSuccess(w, federation)
+	}
+}
+
+// handleGetAggregatorStatus returns the live state of a federation's
+// aggregator, read through its AdminService
+func (s *APIServer) handleGetAggregatorStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	federation, err := s.service.GetFederation(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Federation not found", err)
+		return
+	}
+	if s.config.Control.adminAddress(federation.ID) == "" {
+		s.sendError(w, http.StatusConflict, "Federation has no configured admin address", nil)
+		return
+	}
+
+	status, err := s.controller.Status(ctx, federation)
+	if err != nil {
+		s.sendError(w, http.StatusBadGateway, "Failed to reach the aggregator", err)
+		return
+	}
+	s.sendSuccess(w, status)
+}
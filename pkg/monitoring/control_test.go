@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// fakeController records control actions instead of calling aggregators
+type fakeController struct {
+	actions []ControlAction
+	err     error
+}
+
+func (c *fakeController) Control(ctx context.Context, federation *FederationMetrics, action ControlAction, reason string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.actions = append(c.actions, action)
+	return map[ControlAction]string{ControlPause: "paused", ControlResume: "running", ControlAbort: "aborted"}[action], nil
+}
+
+func (c *fakeController) Status(ctx context.Context, federation *FederationMetrics) (*AggregatorStatus, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &AggregatorStatus{State: "running", CurrentRound: 2, ExpectedUpdates: 2, Received: []string{"c1"}, Pending: []string{"c2"}}, nil
+}
+
+func TestControlFederation(t *testing.T) {
+	server := newTenantTestServer()
+	controller := &fakeController{}
+	server.controller = controller
+	server.config.Control.Aggregators = map[string]string{"fed-a": "localhost:50060", "fed-b": "localhost:50061"}
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "status": "running"}`, nil)
+	// A reported aggregator address does not make a federation controllable
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key",
+		`{"id": "fed-local", "status": "running", "aggregator_address": "attacker.example:50051"}`, nil)
+
+	requests := []struct {
+		path       string
+		apiKey     string
+		body       string
+		want       int
+		wantStatus FederationStatus
+	}{
+		{"/api/v1/federations/fed-a/pause", "lab-b-key", "", http.StatusForbidden, ""},
+		{"/api/v1/federations/fed-a/resume", "lab-a-key", "", http.StatusConflict, ""},
+		{"/api/v1/federations/fed-a/pause", "lab-a-key", `{"reason": "maintenance"}`, http.StatusOK, StatusPaused},
+		{"/api/v1/federations/fed-a/pause", "lab-a-key", "", http.StatusConflict, ""},
+		{"/api/v1/federations/fed-a/resume", "lab-a-key", "", http.StatusOK, StatusRunning},
+		{"/api/v1/federations/fed-a/abort", "admin-key", "", http.StatusOK, StatusAborted},
+		{"/api/v1/federations/fed-a/resume", "lab-a-key", "", http.StatusConflict, ""},
+		{"/api/v1/federations/fed-local/pause", "lab-a-key", "", http.StatusConflict, ""},
+		{"/api/v1/federations/missing/pause", "admin-key", "", http.StatusNotFound, ""},
+		{"/api/v1/federations/fed-a/pause", "lab-a-key", `{"reason": `, http.StatusBadRequest, ""},
+	}
+	for _, tt := range requests {
+		var federation FederationMetrics
+		code := doAPIKeyRequest(t, server, "POST", tt.path, tt.apiKey, tt.body, &federation)
+		if code != tt.want {
+			t.Errorf("POST %s as %s = %d, want %d", tt.path, tt.apiKey, code, tt.want)
+			continue
+		}
+		if tt.wantStatus != "" && federation.Status != tt.wantStatus {
+			t.Errorf("POST %s status = %s, want %s", tt.path, federation.Status, tt.wantStatus)
+		}
+	}
+
+	want := []ControlAction{ControlPause, ControlResume, ControlAbort}
+	if fmt.Sprint(controller.actions) != fmt.Sprint(want) {
+		t.Errorf("aggregator actions = %v, want %v", controller.actions, want)
+	}
+
+	var federation FederationMetrics
+	doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a", "lab-a-key", "", &federation)
+	if federation.Status != StatusAborted || federation.EndTime == nil {
+		t.Errorf("aborted federation = %+v", federation)
+	}
+
+	var events []*MonitoringEvent
+	doAPIKeyRequest(t, server, "GET", "/api/v1/events?federation_id=fed-a", "lab-a-key", "", &events)
+	var messages []string
+	for _, event := range events {
+		if event.Source == "api" {
+			messages = append(messages, event.Message)
+		}
+	}
+	if len(messages) != 3 {
+		t.Errorf("control events = %v, want 3", messages)
+	}
+
+	// Federations keep their status when the aggregator cannot be reached
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-b", "status": "running"}`, nil)
+	controller.err = fmt.Errorf("connection refused")
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/federations/fed-b/pause", "lab-a-key", "", nil); code != http.StatusBadGateway {
+		t.Errorf("pause of an unreachable aggregator = %d, want %d", code, http.StatusBadGateway)
+	}
+	doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-b", "lab-a-key", "", &federation)
+	if federation.Status != StatusRunning {
+		t.Errorf("status after a failed pause = %s", federation.Status)
+	}
+}
+
+func TestAggregatorStatus(t *testing.T) {
+	server := newTenantTestServer()
+	controller := &fakeController{}
+	server.controller = controller
+	server.config.Control.Aggregators = map[string]string{"fed-a": "localhost:50060"}
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "status": "running"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key",
+		`{"id": "fed-local", "status": "running", "aggregator_address": "attacker.example:50051"}`, nil)
+
+	var status AggregatorStatus
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a/aggregator", "lab-a-key", "", &status); code != http.StatusOK {
+		t.Fatalf("GET aggregator status = %d", code)
+	}
+	if status.CurrentRound != 2 || fmt.Sprint(status.Pending) != "[c2]" {
+		t.Errorf("aggregator status = %+v", status)
+	}
+
+	// Reading the status dials the aggregator with the admin token, so it
+	// is limited to admins like pausing
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a/aggregator", "lab-b-key", "", nil); code != http.StatusForbidden {
+		t.Errorf("GET aggregator status as a monitor = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-local/aggregator", "lab-a-key", "", nil); code != http.StatusConflict {
+		t.Errorf("GET aggregator status without address = %d, want %d", code, http.StatusConflict)
+	}
+	controller.err = fmt.Errorf("connection refused")
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a/aggregator", "lab-a-key", "", nil); code != http.StatusBadGateway {
+		t.Errorf("GET status of an unreachable aggregator = %d, want %d", code, http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,216 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// DashboardTemplate is a predefined dashboard of a federation. The config of
+// each widget names the data it shows with a "source", such as "timeseries"
+// or "collaborators", and source-specific options; instantiating the
+// template adds the "federation_id".
+type DashboardTemplate struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Widgets     []Widget `json:"widgets"`
+}
+
+// DashboardTemplateRequest instantiates a dashboard template
+type DashboardTemplateRequest struct {
+	FederationID string `json:"federation_id"`
+	Name         string `json:"name,omitempty"` // dashboard name (default: from the template and federation)
+}
+
+// DashboardConfig configures the dashboards created for new federations
+type DashboardConfig struct {
+	DisableProvisioning bool     `yaml:"disable_provisioning" json:"disable_provisioning"`
+	Templates           []string `yaml:"templates,omitempty" json:"templates,omitempty"` // templates created for each new federation (default: all)
+}
+
+// dashboardTemplates are the predefined dashboards, laid out on a grid 12
+// units wide
+var dashboardTemplates = []DashboardTemplate{
+	{
+		Name:        "federation-overview",
+		Title:       "Federation Overview",
+		Description: "Progress, model quality and alerts of a federation",
+		Widgets: []Widget{
+			{ID: "current-round", Type: "metric", Title: "Current Round", X: 0, Y: 0, Width: 3, Height: 2,
+				Config: map[string]interface{}{"source": "federation", "field": "current_round"}},
+			{ID: "active-collaborators", Type: "metric", Title: "Active Collaborators", X: 3, Y: 0, Width: 3, Height: 2,
+				Config: map[string]interface{}{"source": "federation", "field": "active_collaborators"}},
+			{ID: "participation", Type: "metric", Title: "Participation Rate", X: 6, Y: 0, Width: 3, Height: 2,
+				Config: map[string]interface{}{"source": "convergence", "field": "participation_rate"}},
+			{ID: "alerts", Type: "alert", Title: "Active Alerts", X: 9, Y: 0, Width: 3, Height: 2,
+				Config: map[string]interface{}{"source": "alerts"}},
+			{ID: "model-quality", Type: "chart", Title: "Accuracy and Loss", X: 0, Y: 2, Width: 8, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "accuracy,loss"}},
+			{ID: "round-duration", Type: "chart", Title: "Round Duration", X: 8, Y: 2, Width: 4, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "round_duration"}},
+			{ID: "recent-rounds", Type: "table", Title: "Recent Rounds", X: 0, Y: 6, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "rounds", "limit": 10}},
+			{ID: "recent-events", Type: "table", Title: "Recent Events", X: 6, Y: 6, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "events", "limit": 20}},
+		},
+	},
+	{
+		Name:        "collaborator-health",
+		Title:       "Collaborator Health",
+		Description: "Connectivity, resource usage and rejected updates of the collaborators of a federation",
+		Widgets: []Widget{
+			{ID: "collaborators", Type: "table", Title: "Collaborators", X: 0, Y: 0, Width: 12, Height: 4,
+				Config: map[string]interface{}{"source": "collaborators"}},
+			{ID: "cpu", Type: "chart", Title: "CPU Usage", X: 0, Y: 4, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "cpu"}},
+			{ID: "memory", Type: "chart", Title: "Memory Usage", X: 6, Y: 4, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "memory"}},
+			{ID: "update-latency", Type: "chart", Title: "Update Latency", X: 0, Y: 8, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "update_latency"}},
+			{ID: "dropped-updates", Type: "table", Title: "Dropped Updates", X: 6, Y: 8, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "dropped_updates"}},
+		},
+	},
+	{
+		Name:        "convergence",
+		Title:       "Convergence",
+		Description: "How the global model of a federation converges, and what each collaborator contributes",
+		Widgets: []Widget{
+			{ID: "convergence-rate", Type: "metric", Title: "Convergence Rate", X: 0, Y: 0, Width: 4, Height: 2,
+				Config: map[string]interface{}{"source": "convergence", "field": "convergence_rate"}},
+			{ID: "participation", Type: "metric", Title: "Participation Rate", X: 4, Y: 0, Width: 4, Height: 2,
+				Config: map[string]interface{}{"source": "convergence", "field": "participation_rate"}},
+			{ID: "estimated-completion", Type: "metric", Title: "Estimated Completion", X: 8, Y: 0, Width: 4, Height: 2,
+				Config: map[string]interface{}{"source": "convergence", "field": "estimated_completion"}},
+			{ID: "accuracy", Type: "chart", Title: "Accuracy", X: 0, Y: 2, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "accuracy"}},
+			{ID: "loss", Type: "chart", Title: "Loss", X: 6, Y: 2, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "timeseries", "metrics": "loss"}},
+			{ID: "aggregations", Type: "table", Title: "Aggregations", X: 0, Y: 6, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "aggregations", "limit": 10}},
+			{ID: "contributions", Type: "table", Title: "Contributions", X: 6, Y: 6, Width: 6, Height: 4,
+				Config: map[string]interface{}{"source": "contributions"}},
+		},
+	},
+}
+
+// DashboardTemplates returns the predefined dashboard templates
+func DashboardTemplates() []DashboardTemplate {
+	return dashboardTemplates
+}
+
+// findDashboardTemplate returns the template with the given name
+func findDashboardTemplate(name string) (*DashboardTemplate, error) {
+	for i := range dashboardTemplates {
+		if dashboardTemplates[i].Name == name {
+			return &dashboardTemplates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("dashboard template %s not found", name)
+}
+
+// Instantiate creates a dashboard of the template for a federation. An empty
+// id lets storage assign one.
+func (t *DashboardTemplate) Instantiate(federation *FederationMetrics, id string) *Dashboard {
+	name := federation.Name
+	if name == "" {
+		name = federation.ID
+	}
+
+	dashboard := &Dashboard{
+		ID:          id,
+		Name:        fmt.Sprintf("%s: %s", name, t.Title),
+		Description: t.Description,
+		OrgID:       federation.OrgID,
+		Widgets:     make([]Widget, len(t.Widgets)),
+	}
+	for i, widget := range t.Widgets {
+		config := make(map[string]interface{}, len(widget.Config)+1)
+		for key, value := range widget.Config {
+			config[key] = value
+		}
+		config["federation_id"] = federation.ID
+		widget.Config = config
+		dashboard.Widgets[i] = widget
+	}
+	return dashboard
+}
+
+// provisionedDashboardID returns the ID of the dashboard of a template
+// created for a new federation
+func provisionedDashboardID(federationID, template string) string {
+	return federationID + "-" + template
+}
+
+// provisioningService creates the dashboards of the configured templates for
+// each federation it sees registered for the first time
+type provisioningService struct {
+	MonitoringService
+
+	templates []*DashboardTemplate
+}
+
+// newProvisioningService wraps service to provision dashboards, or returns
+// service unchanged when provisioning is disabled
+func newProvisioningService(service MonitoringService, config DashboardConfig) MonitoringService {
+	if config.DisableProvisioning {
+		return service
+	}
+
+	p := &provisioningService{MonitoringService: service}
+	if len(config.Templates) == 0 {
+		for i := range dashboardTemplates {
+			p.templates = append(p.templates, &dashboardTemplates[i])
+		}
+		return p
+	}
+	for _, name := range config.Templates {
+		template, err := findDashboardTemplate(name)
+		if err != nil {
+			log.Printf("Warning: skipping dashboard provisioning: %v", err)
+			continue
+		}
+		p.templates = append(p.templates, template)
+	}
+	return p
+}
+
+func (p *provisioningService) RegisterFederation(ctx context.Context, metrics *FederationMetrics) error {
+	_, err := p.MonitoringService.GetFederation(ctx, metrics.ID)
+	isNew := err != nil
+
+	if err := p.MonitoringService.RegisterFederation(ctx, metrics); err != nil {
+		return err
+	}
+	if isNew {
+		p.provision(ctx, metrics)
+	}
+	return nil
+}
+
+func (p *provisioningService) ImportFederationHistory(ctx context.Context, archive *ImportArchive) (*ImportResult, error) {
+	result, err := p.MonitoringService.ImportFederationHistory(ctx, archive)
+	if err != nil || !result.FederationCreated {
+		return result, err
+	}
+
+	if federation, err := p.MonitoringService.GetFederation(ctx, result.FederationID); err == nil {
+		p.provision(ctx, federation)
+	}
+	return result, nil
+}
+
+// provision creates the dashboards of a new federation. Dashboards have
+// fixed IDs, so existing ones, e.g. restored from a backup, are kept.
+func (p *provisioningService) provision(ctx context.Context, federation *FederationMetrics) {
+	for _, template := range p.templates {
+		id := provisionedDashboardID(federation.ID, template.Name)
+		if _, err := p.MonitoringService.GetDashboard(ctx, id); err == nil {
+			continue
+		}
+		if err := p.MonitoringService.CreateDashboard(ctx, template.Instantiate(federation, id)); err != nil {
+			log.Printf("Warning: failed to create %s dashboard of federation %s: %v", template.Name, federation.ID, err)
+		}
+	}
+}
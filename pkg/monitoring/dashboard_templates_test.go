@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDashboardProvisioning(t *testing.T) {
+	server := newTenantTestServer()
+
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil); code != http.StatusOK {
+		t.Fatalf("register federation status = %d", code)
+	}
+	// Registering the federation again does not recreate deleted dashboards
+	doAPIKeyRequest(t, server, "DELETE", "/api/v1/dashboards/fed-a-convergence", "lab-a-key", "", nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil)
+
+	var dashboards []*Dashboard
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards", "lab-a-key", "", &dashboards); code != http.StatusOK {
+		t.Fatalf("list dashboards status = %d", code)
+	}
+	if len(dashboards) != 2 {
+		t.Fatalf("got %d dashboards, want federation-overview and collaborator-health", len(dashboards))
+	}
+	for _, dashboard := range dashboards {
+		if dashboard.OrgID != "lab-a" {
+			t.Errorf("dashboard %s org = %q, want lab-a", dashboard.ID, dashboard.OrgID)
+		}
+		for _, widget := range dashboard.Widgets {
+			if widget.Config["federation_id"] != "fed-a" {
+				t.Errorf("widget %s of %s shows %v, want fed-a", widget.ID, dashboard.ID, widget.Config["federation_id"])
+			}
+		}
+	}
+	doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards", "lab-b-key", "", &dashboards)
+	if len(dashboards) != 0 {
+		t.Errorf("lab-b sees %d dashboards of lab-a", len(dashboards))
+	}
+}
+
+func TestInstantiateDashboardTemplate(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil)
+
+	var templates []DashboardTemplate
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards/templates", "lab-b-key", "", &templates); code != http.StatusOK || len(templates) != 3 {
+		t.Fatalf("list templates = %d, %d templates", code, len(templates))
+	}
+
+	var dashboard Dashboard
+	code := doAPIKeyRequest(t, server, "POST", "/api/v1/dashboards/templates/convergence", "lab-a-key", `{"federation_id": "fed-a", "name": "A convergence"}`, &dashboard)
+	if code != http.StatusOK || dashboard.Name != "A convergence" || dashboard.ID == "fed-a-convergence" || len(dashboard.Widgets) == 0 {
+		t.Errorf("instantiate = %d, %+v", code, dashboard)
+	}
+
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/dashboards/templates/missing", "lab-a-key", `{"federation_id": "fed-a"}`, nil); code != http.StatusNotFound {
+		t.Errorf("unknown template status = %d, want 404", code)
+	}
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/dashboards/templates/convergence", "admin-key", `{"federation_id": "fed-x"}`, nil); code != http.StatusNotFound {
+		t.Errorf("unknown federation status = %d, want 404", code)
+	}
+}
+
+func TestDisableDashboardProvisioning(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	service := newProvisioningService(storage, DashboardConfig{DisableProvisioning: true})
+	if service != MonitoringService(storage) {
+		t.Fatal("provisioning was not disabled")
+	}
+
+	ctx := context.Background()
+	service = newProvisioningService(storage, DashboardConfig{Templates: []string{"convergence"}})
+	service.RegisterFederation(ctx, &FederationMetrics{ID: "fed"})
+	dashboards, _ := storage.ListDashboards(ctx)
+	if len(dashboards) != 1 || dashboards[0].ID != "fed-convergence" {
+		t.Errorf("got dashboards %+v, want only fed-convergence", dashboards)
+	}
+}
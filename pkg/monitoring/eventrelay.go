@@ -0,0 +1,60 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// EventBus shares live events between monitoring servers. RedisStorage
+// implements it with Redis pub/sub.
+type EventBus interface {
+	PublishEvent(ctx context.Context, event *MonitoringEvent) error
+	SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error)
+	UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error
+}
+
+// relayService publishes the events of a MonitoringService to an EventBus
+// and serves live event subscriptions from the bus, so that WebSocket
+// clients of any replica see the events reported to every replica
+type relayService struct {
+	MonitoringService
+
+	bus EventBus
+}
+
+// RelayEvents wraps a MonitoringService so that its live events are shared
+// with other monitoring servers through bus. Events are relayed until ctx is
+// done.
+func RelayEvents(ctx context.Context, service MonitoringService, bus EventBus) (MonitoringService, error) {
+	events, err := service.SubscribeToEvents(ctx, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to local events: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := bus.PublishEvent(ctx, event); err != nil {
+					log.Printf("Warning: failed to relay event %s: %v", event.ID, err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &relayService{MonitoringService: service, bus: bus}, nil
+}
+
+func (r *relayService) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
+	return r.bus.SubscribeToEvents(ctx, federationID, eventTypes)
+}
+
+func (r *relayService) UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error {
+	return r.bus.UnsubscribeFromEvents(ctx, subscriptionID)
+}
@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBus is an in-process EventBus standing in for Redis pub/sub
+type testBus struct {
+	mu            sync.Mutex
+	subscriptions map[string]*EventSubscription
+}
+
+func (b *testBus) PublishEvent(ctx context.Context, event *MonitoringEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subscription := range b.subscriptions {
+		if subscription.matches(event) {
+			subscription.Channel <- event
+		}
+	}
+	return nil
+}
+
+func (b *testBus) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscription := &EventSubscription{
+		ID:           fmt.Sprintf("sub-%d", len(b.subscriptions)),
+		FederationID: federationID,
+		EventTypes:   eventTypes,
+		Channel:      make(chan *MonitoringEvent, 10),
+	}
+	b.subscriptions[subscription.ID] = subscription
+	return subscription.Channel, nil
+}
+
+func (b *testBus) UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscriptions, subscriptionID)
+	return nil
+}
+
+func TestRelayEventsAcrossReplicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := &testBus{subscriptions: make(map[string]*EventSubscription)}
+	config := &MonitoringConfig{}
+	replicaA, err := RelayEvents(ctx, NewMemoryStorage(config), bus)
+	if err != nil {
+		t.Fatalf("RelayEvents: %v", err)
+	}
+	replicaB, err := RelayEvents(ctx, NewMemoryStorage(config), bus)
+	if err != nil {
+		t.Fatalf("RelayEvents: %v", err)
+	}
+
+	events, err := replicaB.SubscribeToEvents(ctx, "fed-1", nil)
+	if err != nil {
+		t.Fatalf("SubscribeToEvents: %v", err)
+	}
+
+	for _, federationID := range []string{"fed-2", "fed-1"} {
+		event := &MonitoringEvent{FederationID: federationID, Type: MetricTypeRound, Message: "reported to " + federationID}
+		if err := replicaA.RecordEvent(ctx, event); err != nil {
+			t.Fatalf("RecordEvent: %v", err)
+		}
+	}
+
+	select {
+	case event := <-events:
+		if event.FederationID != "fed-1" {
+			t.Errorf("got event of %s, want fed-1", event.FederationID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event recorded on replica A was not delivered to replica B")
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
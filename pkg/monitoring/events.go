@@ -0,0 +1,228 @@
+package monitoring
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events that storage backends record when metrics are recorded
+
+func federationRegisteredEvent(metrics *FederationMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.ID,
+		Type:         MetricTypeRound,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Federation %s registered", metrics.Name),
+		Data: map[string]interface{}{
+			"mode":      metrics.Mode,
+			"algorithm": metrics.Algorithm,
+			"rounds":    metrics.TotalRounds,
+		},
+	}
+}
+
+func historyImportedEvent(result *ImportResult) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: result.FederationID,
+		Type:         MetricTypeRound,
+		Timestamp:    time.Now(),
+		Source:       "import",
+		Level:        "info",
+		Message:      fmt.Sprintf("Imported historical records for federation %s", result.FederationID),
+		Data: map[string]interface{}{
+			"rounds":  result.Rounds,
+			"updates": result.Updates,
+			"events":  result.Events,
+			"skipped": result.Skipped,
+		},
+	}
+}
+
+func federationControlledEvent(federation *FederationMetrics, action ControlAction, previous FederationStatus, aggregatorState, reason, user string) *MonitoringEvent {
+	level := "info"
+	if action == ControlAbort {
+		level = "warning"
+	}
+	verbs := map[ControlAction]string{ControlPause: "paused", ControlResume: "resumed", ControlAbort: "aborted"}
+	message := fmt.Sprintf("Federation %s %s", federation.ID, verbs[action])
+	if reason != "" {
+		message += ": " + reason
+	}
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: federation.ID,
+		Type:         MetricTypeRound,
+		Timestamp:    time.Now(),
+		Source:       "api",
+		Level:        level,
+		Message:      message,
+		Data: map[string]interface{}{
+			"action":           string(action),
+			"previous_status":  string(previous),
+			"status":           string(federation.Status),
+			"aggregator_state": aggregatorState,
+			"reason":           reason,
+			"user":             user,
+		},
+	}
+}
+
+func collaboratorJoinedEvent(metrics *CollaboratorMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.FederationID,
+		Type:         MetricTypeCollaborator,
+		Timestamp:    time.Now(),
+		Source:       metrics.ID,
+		Level:        "info",
+		Message:      fmt.Sprintf("Collaborator %s joined federation", metrics.ID),
+		Data: map[string]interface{}{
+			"address": metrics.Address,
+			"status":  metrics.Status,
+		},
+	}
+}
+
+func roundStartedEvent(metrics *RoundMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.FederationID,
+		Type:         MetricTypeRound,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Round %d started", metrics.RoundNumber),
+		Data: map[string]interface{}{
+			"round_id":     metrics.ID,
+			"algorithm":    metrics.Algorithm,
+			"participants": metrics.ParticipantCount,
+		},
+	}
+}
+
+func roundCompletedEvent(metrics *RoundMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.FederationID,
+		Type:         MetricTypeRound,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Round %d completed", metrics.RoundNumber),
+		Data: map[string]interface{}{
+			"round_id":     metrics.ID,
+			"duration_ms":  metrics.Duration.Milliseconds(),
+			"participants": metrics.ParticipantCount,
+			"updates":      metrics.UpdatesReceived,
+		},
+	}
+}
+
+func updateReceivedEvent(metrics *ModelUpdateMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.FederationID,
+		Type:         MetricTypeModelUpdate,
+		Timestamp:    time.Now(),
+		Source:       metrics.CollaboratorID,
+		Level:        "info",
+		Message:      fmt.Sprintf("Model update received from %s", metrics.CollaboratorID),
+		Data: map[string]interface{}{
+			"round":         metrics.RoundNumber,
+			"size_bytes":    metrics.UpdateSize,
+			"processing_ms": metrics.ProcessingTime,
+		},
+	}
+}
+
+func updateDroppedEvent(dropped *DroppedUpdate) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: dropped.FederationID,
+		Type:         MetricTypeModelUpdate,
+		Timestamp:    dropped.Timestamp,
+		Source:       dropped.CollaboratorID,
+		Level:        "warning",
+		Message:      fmt.Sprintf("Model update from %s dropped (%s)", dropped.CollaboratorID, dropped.Reason),
+		Data: map[string]interface{}{
+			"round":  dropped.RoundNumber,
+			"reason": dropped.Reason,
+			"detail": dropped.Detail,
+		},
+	}
+}
+
+func aggregationRecordedEvent(metrics *AggregationMetrics) *MonitoringEvent {
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: metrics.FederationID,
+		Type:         MetricTypeAggregation,
+		Timestamp:    time.Now(),
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Aggregation completed for round %d", metrics.RoundNumber),
+		Data: map[string]interface{}{
+			"algorithm":   metrics.Algorithm,
+			"duration_ms": metrics.Duration.Milliseconds(),
+			"updates":     metrics.UpdatesAggregated,
+			"clipped":     metrics.UpdatesClipped,
+		},
+	}
+}
+
+func contributionsRecordedEvent(scores []*ContributionScore) *MonitoringEvent {
+	first := scores[0]
+	return &MonitoringEvent{
+		ID:           uuid.New().String(),
+		FederationID: first.FederationID,
+		Type:         MetricTypeContribution,
+		Timestamp:    first.Timestamp,
+		Source:       "aggregator",
+		Level:        "info",
+		Message:      fmt.Sprintf("Contribution scores recorded for %d collaborators in round %d", len(scores), first.RoundNumber),
+		Data: map[string]interface{}{
+			"round":         first.RoundNumber,
+			"collaborators": len(scores),
+		},
+	}
+}
+
+// eventClusters returns the cluster of each collaborator listed in a
+// clustering event
+func eventClusters(event *MonitoringEvent) map[string]int {
+	switch members := event.Data["clusters"].(type) {
+	case map[string]int:
+		return members
+	case map[string]interface{}: // decoded from JSON
+		clusters := make(map[string]int, len(members))
+		for id, c := range members {
+			if number, ok := c.(float64); ok {
+				clusters[id] = int(number)
+			}
+		}
+		return clusters
+	}
+	return nil
+}
+
+// matches reports whether an event is delivered to the subscription
+func (s *EventSubscription) matches(event *MonitoringEvent) bool {
+	if s.FederationID != "" && s.FederationID != event.FederationID {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range s.EventTypes {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ExportFormat is the file format of a monitoring data export
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatParquet ExportFormat = "parquet"
+)
+
+// ExportDataset identifies which monitoring records to export
+type ExportDataset string
+
+const (
+	ExportRounds       ExportDataset = "rounds"
+	ExportModelUpdates ExportDataset = "updates"
+	ExportEvents       ExportDataset = "events"
+)
+
+// ExportDatasets lists all exportable datasets
+var ExportDatasets = []ExportDataset{ExportRounds, ExportModelUpdates, ExportEvents}
+
+// ParseExportFormat validates an export format name
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(value)); format {
+	case ExportFormatCSV, ExportFormatParquet:
+		return format, nil
+	case "":
+		return ExportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", value)
+	}
+}
+
+// ParseExportDataset validates an export dataset name
+func ParseExportDataset(value string) (ExportDataset, error) {
+	for _, dataset := range ExportDatasets {
+		if string(dataset) == value {
+			return dataset, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export dataset: %s", value)
+}
+
+// ContentType returns the HTTP content type for the format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// roundExportRow is the flat, column-oriented form of RoundMetrics.
+// EndTime holds Unix milliseconds because parquet-go cannot encode a nullable timestamp.
+type roundExportRow struct {
+	ID                string    `parquet:"id"`
+	FederationID      string    `parquet:"federation_id"`
+	RoundNumber       int64     `parquet:"round_number"`
+	Algorithm         string    `parquet:"algorithm"`
+	StartTime         time.Time `parquet:"start_time,timestamp(millisecond)"`
+	EndTime           *int64    `parquet:"end_time_ms,optional"`
+	DurationMs        int64     `parquet:"duration_ms"`
+	ParticipantCount  int64     `parquet:"participant_count"`
+	UpdatesReceived   int64     `parquet:"updates_received"`
+	AggregationTimeMs int64     `parquet:"aggregation_time_ms"`
+	ModelAccuracy     *float64  `parquet:"model_accuracy,optional"`
+	ModelLoss         *float64  `parquet:"model_loss,optional"`
+	ConvergenceRate   *float64  `parquet:"convergence_rate,optional"`
+	Status            string    `parquet:"status"`
+	Hyperparameters   string    `parquet:"hyperparameters"` // JSON object, empty when none were recorded
+}
+
+// updateExportRow is the flat, column-oriented form of ModelUpdateMetrics
+type updateExportRow struct {
+	ID                string    `parquet:"id"`
+	FederationID      string    `parquet:"federation_id"`
+	CollaboratorID    string    `parquet:"collaborator_id"`
+	RoundNumber       int64     `parquet:"round_number"`
+	Timestamp         time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	UpdateSizeBytes   int64     `parquet:"update_size_bytes"`
+	ProcessingTimeMs  float64   `parquet:"processing_time_ms"`
+	Staleness         int64     `parquet:"staleness"`
+	Weight            float64   `parquet:"weight"`
+	QualityScore      *float64  `parquet:"quality_score,optional"`
+	CompressionRatio  *float64  `parquet:"compression_ratio,optional"`
+	ImageDigest       string    `parquet:"image_digest"`
+	DPClipNorm        float64   `parquet:"dp_clip_norm"`
+	DPNoiseMultiplier float64   `parquet:"dp_noise_multiplier"`
+}
+
+// eventExportRow is the flat, column-oriented form of MonitoringEvent.
+// Event data is stored as a JSON document.
+type eventExportRow struct {
+	ID           string    `parquet:"id"`
+	FederationID string    `parquet:"federation_id"`
+	Type         string    `parquet:"type"`
+	Timestamp    time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	Source       string    `parquet:"source"`
+	Level        string    `parquet:"level"`
+	Message      string    `parquet:"message"`
+	Data         string    `parquet:"data"`
+}
+
+// ExportMetrics writes the selected dataset, honoring filter, to w in the given format
+func ExportMetrics(ctx context.Context, service MonitoringService, dataset ExportDataset, format ExportFormat, filter *MetricsFilter, w io.Writer) error {
+	switch dataset {
+	case ExportRounds:
+		rounds, err := service.GetRoundHistory(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to get rounds: %w", err)
+		}
+		rows := make([]roundExportRow, 0, len(rounds))
+		for _, round := range rounds {
+			rows = append(rows, toRoundExportRow(round))
+		}
+		return writeExport(w, format, rows)
+
+	case ExportModelUpdates:
+		updates, err := service.GetModelUpdates(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to get model updates: %w", err)
+		}
+		rows := make([]updateExportRow, 0, len(updates))
+		for _, update := range updates {
+			rows = append(rows, updateExportRow{
+				ID:                update.ID,
+				FederationID:      update.FederationID,
+				CollaboratorID:    update.CollaboratorID,
+				RoundNumber:       int64(update.RoundNumber),
+				Timestamp:         update.Timestamp,
+				UpdateSizeBytes:   int64(update.UpdateSize),
+				ProcessingTimeMs:  update.ProcessingTime,
+				Staleness:         int64(update.Staleness),
+				Weight:            update.Weight,
+				QualityScore:      update.QualityScore,
+				CompressionRatio:  update.CompressionRatio,
+				ImageDigest:       update.ImageDigest,
+				DPClipNorm:        update.DPClipNorm,
+				DPNoiseMultiplier: update.DPNoiseMultiplier,
+			})
+		}
+		return writeExport(w, format, rows)
+
+	case ExportEvents:
+		events, err := service.GetEvents(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+		rows := make([]eventExportRow, 0, len(events))
+		for _, event := range events {
+			data := ""
+			if len(event.Data) > 0 {
+				encoded, err := json.Marshal(event.Data)
+				if err != nil {
+					return fmt.Errorf("failed to encode data of event %s: %w", event.ID, err)
+				}
+				data = string(encoded)
+			}
+			rows = append(rows, eventExportRow{
+				ID:           event.ID,
+				FederationID: event.FederationID,
+				Type:         string(event.Type),
+				Timestamp:    event.Timestamp,
+				Source:       event.Source,
+				Level:        event.Level,
+				Message:      event.Message,
+				Data:         data,
+			})
+		}
+		return writeExport(w, format, rows)
+
+	default:
+		return fmt.Errorf("unsupported export dataset: %s", dataset)
+	}
+}
+
+func toRoundExportRow(round *RoundMetrics) roundExportRow {
+	row := roundExportRow{
+		ID:                round.ID,
+		FederationID:      round.FederationID,
+		RoundNumber:       int64(round.RoundNumber),
+		Algorithm:         round.Algorithm,
+		StartTime:         round.StartTime,
+		DurationMs:        round.Duration.Milliseconds(),
+		ParticipantCount:  int64(round.ParticipantCount),
+		UpdatesReceived:   int64(round.UpdatesReceived),
+		AggregationTimeMs: round.AggregationTime.Milliseconds(),
+		ModelAccuracy:     round.ModelAccuracy,
+		ModelLoss:         round.ModelLoss,
+		ConvergenceRate:   round.ConvergenceRate,
+		Status:            round.Status,
+	}
+	if round.EndTime != nil {
+		endTime := round.EndTime.UnixMilli()
+		row.EndTime = &endTime
+	}
+	if len(round.Hyperparameters) > 0 {
+		if data, err := json.Marshal(round.Hyperparameters); err == nil {
+			row.Hyperparameters = string(data)
+		}
+	}
+	return row
+}
+
+func writeExport[T any](w io.Writer, format ExportFormat, rows []T) error {
+	switch format {
+	case ExportFormatParquet:
+		writer := parquet.NewGenericWriter[T](w)
+		if _, err := writer.Write(rows); err != nil {
+			return fmt.Errorf("failed to write parquet rows: %w", err)
+		}
+		return writer.Close()
+	case ExportFormatCSV:
+		return writeCSV(w, rows)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// writeCSV writes rows as CSV using the parquet column names as the header
+func writeCSV[T any](w io.Writer, rows []T) error {
+	rowType := reflect.TypeOf((*T)(nil)).Elem()
+	header := make([]string, rowType.NumField())
+	for i := range header {
+		header[i] = strings.Split(rowType.Field(i).Tag.Get("parquet"), ",")[0]
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	for _, row := range rows {
+		value := reflect.ValueOf(row)
+		for i := range record {
+			record[i] = formatCSVValue(value.Field(i))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCSVValue(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,104 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func newExportTestStorage(t *testing.T) *MemoryStorage {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	ctx := context.Background()
+	accuracy := 0.9
+
+	for i, federationID := range []string{"fed-a", "fed-a", "fed-b"} {
+		round := &RoundMetrics{
+			FederationID:  federationID,
+			RoundNumber:   i + 1,
+			Algorithm:     "fedavg",
+			StartTime:     time.Now(),
+			ModelAccuracy: &accuracy,
+			Status:        "completed",
+		}
+		if err := storage.RecordRoundStart(ctx, round); err != nil {
+			t.Fatalf("RecordRoundStart() error = %v", err)
+		}
+	}
+
+	return storage
+}
+
+func TestExportMetricsCSV(t *testing.T) {
+	storage := newExportTestStorage(t)
+
+	var buf bytes.Buffer
+	filter := &MetricsFilter{FederationID: "fed-a"}
+	if err := ExportMetrics(context.Background(), storage, ExportRounds, ExportFormatCSV, filter, &buf); err != nil {
+		t.Fatalf("ExportMetrics() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	if records[0][0] != "id" || records[0][1] != "federation_id" {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+	for _, record := range records[1:] {
+		if record[1] != "fed-a" {
+			t.Errorf("Expected only fed-a rows, got %s", record[1])
+		}
+	}
+}
+
+func TestExportMetricsParquet(t *testing.T) {
+	storage := newExportTestStorage(t)
+
+	var buf bytes.Buffer
+	if err := ExportMetrics(context.Background(), storage, ExportRounds, ExportFormatParquet, nil, &buf); err != nil {
+		t.Fatalf("ExportMetrics() error = %v", err)
+	}
+
+	rows, err := parquet.Read[roundExportRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read parquet export: %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].ModelAccuracy == nil || *rows[0].ModelAccuracy != 0.9 {
+		t.Errorf("Expected model_accuracy 0.9, got %v", rows[0].ModelAccuracy)
+	}
+	if rows[0].EndTime != nil {
+		t.Errorf("Expected null end_time, got %v", rows[0].EndTime)
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{"", ExportFormatCSV, false},
+		{"csv", ExportFormatCSV, false},
+		{"Parquet", ExportFormatParquet, false},
+		{"xlsx", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseExportFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseExportFormat(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
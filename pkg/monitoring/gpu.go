@@ -0,0 +1,139 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GPUStats is the usage of one NVIDIA GPU
+type GPUStats struct {
+	Index       int
+	Name        string
+	Utilization float64 // Percent of time a kernel was running
+	MemoryUsed  int64   // Bytes
+	MemoryTotal int64   // Bytes
+	Temperature float64 // Degrees Celsius
+}
+
+// nvidiaSMIQuery are the fields QueryGPUs reads, in parseNvidiaSMI's order
+const nvidiaSMIQuery = "index,name,utilization.gpu,memory.used,memory.total,temperature.gpu"
+
+var (
+	nvidiaSMIOnce sync.Once
+	nvidiaSMIPath string
+
+	hasGPUOnce sync.Once
+	hasGPU     bool
+)
+
+// findNvidiaSMI returns the path of nvidia-smi, or "" when it is not installed
+func findNvidiaSMI() string {
+	nvidiaSMIOnce.Do(func() {
+		nvidiaSMIPath, _ = exec.LookPath("nvidia-smi")
+	})
+	return nvidiaSMIPath
+}
+
+// HasGPU reports whether the host has an NVIDIA GPU that QueryGPUs can read.
+// The GPUs are only looked for once.
+func HasGPU() bool {
+	hasGPUOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		gpus, err := QueryGPUs(ctx)
+		hasGPU = err == nil && len(gpus) > 0
+	})
+	return hasGPU
+}
+
+// QueryGPUs reads the usage of the host's NVIDIA GPUs with nvidia-smi, which
+// reports the driver's NVML counters. Hosts without nvidia-smi have no GPUs.
+func QueryGPUs(ctx context.Context) ([]GPUStats, error) {
+	path := findNvidiaSMI()
+	if path == "" {
+		return nil, nil
+	}
+	// #nosec G204 - fixed arguments
+	out, err := exec.CommandContext(ctx, path, "--query-gpu="+nvidiaSMIQuery, "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi failed: %v", err)
+	}
+	return parseNvidiaSMI(out)
+}
+
+// parseNvidiaSMI parses the CSV nvidia-smi prints for nvidiaSMIQuery. Fields
+// a GPU does not support, such as the temperature of some virtual GPUs, are
+// reported as [N/A] and left zero.
+func parseNvidiaSMI(data []byte) ([]GPUStats, error) {
+	var gpus []GPUStats
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected nvidia-smi line %q", line)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid GPU index %q", fields[0])
+		}
+		gpu := GPUStats{
+			Index:       index,
+			Name:        fields[1],
+			Utilization: parseGPUValue(fields[2]),
+			// nvidia-smi reports memory in MiB
+			MemoryUsed:  int64(parseGPUValue(fields[3]) * 1024 * 1024),
+			MemoryTotal: int64(parseGPUValue(fields[4]) * 1024 * 1024),
+			Temperature: parseGPUValue(fields[5]),
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+func parseGPUValue(field string) float64 {
+	v, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// setGPUMetrics sets the GPU usage of metrics: the mean utilization, the
+// share of all GPU memory in use and the hottest GPU's temperature
+func setGPUMetrics(metrics *ResourceMetrics, gpus []GPUStats) {
+	if len(gpus) == 0 {
+		return
+	}
+	var utilization, temperature float64
+	var used, total int64
+	for _, gpu := range gpus {
+		utilization += gpu.Utilization
+		temperature = max(temperature, gpu.Temperature)
+		used += gpu.MemoryUsed
+		total += gpu.MemoryTotal
+	}
+	utilization /= float64(len(gpus))
+	metrics.GPUUsage = &utilization
+	if total > 0 {
+		memory := 100 * float64(used) / float64(total)
+		metrics.GPUMemory = &memory
+	}
+	if temperature > 0 {
+		metrics.GPUTemperature = &temperature
+	}
+	metrics.GPUCount = len(gpus)
+}
@@ -0,0 +1,272 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Grafana JSON datasource targets are named <federation_id>:<metric>, e.g.
+// "fed-1:accuracy". Annotation queries are a federation ID, optionally
+// followed by an event type: "fed-1" or "fed-1:round".
+const grafanaTargetSeparator = ":"
+
+// grafanaSeriesMetrics are the metrics offered to Grafana for every federation
+var grafanaSeriesMetrics = []TimeSeriesMetric{
+	SeriesAccuracy, SeriesLoss, SeriesRoundDuration, SeriesUpdateLatency, SeriesCPU, SeriesMemory,
+}
+
+// GrafanaRange is the dashboard time range sent with Grafana requests
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaSearchRequest is the body of a Grafana metric search
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// GrafanaTarget is a single query of a Grafana panel
+type GrafanaTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"` // timeserie (default) or table
+}
+
+// GrafanaQueryRequest is the body of a Grafana panel query
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange    `json:"range"`
+	IntervalMs    int64           `json:"intervalMs"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+	Targets       []GrafanaTarget `json:"targets"`
+}
+
+// GrafanaTimeSeries is a time series response; datapoints are [value, unix_ms] pairs
+type GrafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaTableColumn describes a column of a table response
+type GrafanaTableColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaTable is a table response
+type GrafanaTable struct {
+	Type    string               `json:"type"`
+	Columns []GrafanaTableColumn `json:"columns"`
+	Rows    [][]interface{}      `json:"rows"`
+}
+
+// GrafanaAnnotationQuery is the annotation definition sent by Grafana
+type GrafanaAnnotationQuery struct {
+	Name       string `json:"name"`
+	Datasource string `json:"datasource,omitempty"`
+	Enable     bool   `json:"enable"`
+	Query      string `json:"query"`
+}
+
+// GrafanaAnnotationRequest is the body of a Grafana annotation query
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange           `json:"range"`
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+}
+
+// GrafanaAnnotation is a single annotation; time is in unix milliseconds
+type GrafanaAnnotation struct {
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+	Time       int64                  `json:"time"`
+	Title      string                 `json:"title"`
+	Text       string                 `json:"text"`
+	Tags       []string               `json:"tags"`
+}
+
+// parseGrafanaTarget splits a target into its federation ID and metric
+func parseGrafanaTarget(target string) (string, TimeSeriesMetric, error) {
+	index := strings.LastIndex(target, grafanaTargetSeparator)
+	if index <= 0 || index == len(target)-1 {
+		return "", "", fmt.Errorf("target %q must be <federation_id>:<metric>", target)
+	}
+
+	metrics, err := ParseTimeSeriesMetrics(target[index+1:])
+	if err != nil {
+		return "", "", err
+	}
+
+	return target[:index], metrics[0], nil
+}
+
+// Connection test used by the datasource settings page
+func (s *APIServer) handleGrafanaTest(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *APIServer) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaSearchRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	federations, err := s.service.GetFederationHistory(r.Context(), nil)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get federations", err)
+		return
+	}
+
+	targets := []string{}
+	for _, federation := range federations {
+		for _, metric := range grafanaSeriesMetrics {
+			target := federation.ID + grafanaTargetSeparator + string(metric)
+			if strings.Contains(target, req.Target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	sort.Strings(targets)
+
+	s.sendGrafanaJSON(w, targets)
+}
+
+func (s *APIServer) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !req.Range.To.After(req.Range.From) {
+		s.sendError(w, http.StatusBadRequest, "range.to must be after range.from", nil)
+		return
+	}
+
+	// Honour maxDataPoints when Grafana's interval would produce more buckets
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+	if req.MaxDataPoints > 0 {
+		if minimum := req.Range.To.Sub(req.Range.From) / time.Duration(req.MaxDataPoints); interval < minimum {
+			interval = minimum
+		}
+	}
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		if target.Target == "" {
+			continue
+		}
+
+		federationID, metric, err := parseGrafanaTarget(target.Target)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "Invalid target", err)
+			return
+		}
+
+		series, err := s.service.GetTimeSeries(r.Context(), federationID, &TimeSeriesQuery{
+			Metrics:   []TimeSeriesMetric{metric},
+			StartTime: req.Range.From,
+			EndTime:   req.Range.To,
+			Interval:  interval,
+		})
+		if err != nil {
+			s.sendError(w, http.StatusInternalServerError, "Failed to get time series", err)
+			return
+		}
+
+		var buckets []TimeSeriesBucket
+		if len(series) > 0 {
+			buckets = series[0].Buckets
+		}
+
+		if target.Type == "table" {
+			results = append(results, grafanaTable(buckets))
+			continue
+		}
+
+		datapoints := make([][2]float64, 0, len(buckets))
+		for _, bucket := range buckets {
+			datapoints = append(datapoints, [2]float64{bucket.Avg, float64(bucket.Timestamp.UnixMilli())})
+		}
+		results = append(results, GrafanaTimeSeries{Target: target.Target, Datapoints: datapoints})
+	}
+
+	s.sendGrafanaJSON(w, results)
+}
+
+// grafanaTable renders downsampled buckets as a Grafana table
+func grafanaTable(buckets []TimeSeriesBucket) GrafanaTable {
+	table := GrafanaTable{
+		Type: "table",
+		Columns: []GrafanaTableColumn{
+			{Text: "Time", Type: "time"},
+			{Text: "Avg", Type: "number"},
+			{Text: "Min", Type: "number"},
+			{Text: "Max", Type: "number"},
+			{Text: "Count", Type: "number"},
+		},
+		Rows: make([][]interface{}, 0, len(buckets)),
+	}
+
+	for _, bucket := range buckets {
+		table.Rows = append(table.Rows, []interface{}{
+			bucket.Timestamp.UnixMilli(), bucket.Avg, bucket.Min, bucket.Max, bucket.Count,
+		})
+	}
+
+	return table
+}
+
+func (s *APIServer) handleGrafanaAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaAnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	federationID, eventType, _ := strings.Cut(strings.TrimSpace(req.Annotation.Query), grafanaTargetSeparator)
+	filter := &MetricsFilter{
+		FederationID: federationID,
+		MetricType:   MetricType(eventType),
+		StartTime:    &req.Range.From,
+		EndTime:      &req.Range.To,
+	}
+
+	events, err := s.service.GetEvents(r.Context(), filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get events", err)
+		return
+	}
+
+	annotations := make([]GrafanaAnnotation, 0, len(events))
+	for _, event := range events {
+		tags := []string{}
+		for _, tag := range []string{event.FederationID, string(event.Type), event.Level, event.Source} {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
+		annotations = append(annotations, GrafanaAnnotation{
+			Annotation: req.Annotation,
+			Time:       event.Timestamp.UnixMilli(),
+			Title:      fmt.Sprintf("%s (%s)", event.Type, event.Level),
+			Text:       event.Message,
+			Tags:       tags,
+		})
+	}
+
+	s.sendGrafanaJSON(w, annotations)
+}
+
+// sendGrafanaJSON writes a bare JSON response; the Grafana datasource does not
+// understand the APIResponse envelope
+func (s *APIServer) sendGrafanaJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrafanaDatasource(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	server := NewAPIServer(storage, &MonitoringConfig{})
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed-1", Name: "Test"}); err != nil {
+		t.Fatalf("Failed to register federation: %v", err)
+	}
+	for i := 1; i <= 4; i++ {
+		endTime := start.Add(time.Duration(i) * time.Minute)
+		accuracy := float64(i) / 10
+		if err := storage.RecordRoundStart(ctx, &RoundMetrics{
+			FederationID:  "fed-1",
+			RoundNumber:   i,
+			StartTime:     endTime.Add(-30 * time.Second),
+			EndTime:       &endTime,
+			ModelAccuracy: &accuracy,
+		}); err != nil {
+			t.Fatalf("Failed to record round: %v", err)
+		}
+	}
+	if err := storage.RecordEvent(ctx, &MonitoringEvent{
+		FederationID: "fed-1", Type: MetricTypeAggregation, Level: "warning", Message: "slow aggregation",
+		Timestamp: start.Add(2 * time.Minute),
+	}); err != nil {
+		t.Fatalf("Failed to record event: %v", err)
+	}
+
+	post := func(path, body string, out interface{}) int {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/grafana"+path, strings.NewReader(body)))
+		if rr.Code == http.StatusOK && out != nil {
+			if err := json.Unmarshal(rr.Body.Bytes(), out); err != nil {
+				t.Fatalf("Failed to decode %s response %q: %v", path, rr.Body.String(), err)
+			}
+		}
+		return rr.Code
+	}
+	timeRange := fmt.Sprintf(`"range": {"from": %q, "to": %q}`,
+		start.Format(time.RFC3339), start.Add(10*time.Minute).Format(time.RFC3339))
+
+	var targets []string
+	if code := post("/search", `{"target": "acc"}`, &targets); code != http.StatusOK {
+		t.Fatalf("search status = %d", code)
+	}
+	if len(targets) != 1 || targets[0] != "fed-1:accuracy" {
+		t.Errorf("search targets = %v, want [fed-1:accuracy]", targets)
+	}
+
+	// Two 5-minute buckets: rounds 1-4 end at minutes 1-4, so all land in the first
+	var series []GrafanaTimeSeries
+	query := `{` + timeRange + `, "intervalMs": 300000, "targets": [{"target": "fed-1:accuracy", "refId": "A"}]}`
+	if code := post("/query", query, &series); code != http.StatusOK {
+		t.Fatalf("query status = %d", code)
+	}
+	if len(series) != 1 || series[0].Target != "fed-1:accuracy" || len(series[0].Datapoints) != 1 {
+		t.Fatalf("Unexpected series: %+v", series)
+	}
+	if point := series[0].Datapoints[0]; point[0] < 0.249 || point[0] > 0.251 || int64(point[1]) != start.UnixMilli() {
+		t.Errorf("datapoint = %v, want [0.25 %d]", point, start.UnixMilli())
+	}
+
+	var tables []GrafanaTable
+	query = `{` + timeRange + `, "intervalMs": 60000, "targets": [{"target": "fed-1:accuracy", "type": "table"}]}`
+	if code := post("/query", query, &tables); code != http.StatusOK {
+		t.Fatalf("table query status = %d", code)
+	}
+	if len(tables) != 1 || tables[0].Type != "table" || len(tables[0].Rows) != 4 {
+		t.Errorf("Unexpected table: %+v", tables)
+	}
+
+	if code := post("/query", `{`+timeRange+`, "targets": [{"target": "accuracy"}]}`, nil); code != http.StatusBadRequest {
+		t.Errorf("query without federation status = %d, want 400", code)
+	}
+
+	var annotations []GrafanaAnnotation
+	body := `{` + timeRange + `, "annotation": {"name": "FL", "query": "fed-1:aggregation"}}`
+	if code := post("/annotations", body, &annotations); code != http.StatusOK {
+		t.Fatalf("annotations status = %d", code)
+	}
+	if len(annotations) != 1 || annotations[0].Text != "slow aggregation" || annotations[0].Annotation.Name != "FL" {
+		t.Errorf("Unexpected annotations: %+v", annotations)
+	}
+}
@@ -0,0 +1,418 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/api/monitoringpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxStreamErrors bounds the failures listed in a StreamSummary
+const maxStreamErrors = 100
+
+// grpcAuditActions are the audit actions of the MonitoringIngest methods
+var grpcAuditActions = map[string]string{
+	monitoringpb.MonitoringIngest_RecordRound_FullMethodName:     AuditRoundCreate,
+	monitoringpb.MonitoringIngest_RecordUpdate_FullMethodName:    AuditUpdateCreate,
+	monitoringpb.MonitoringIngest_RecordEvent_FullMethodName:     AuditEventCreate,
+	monitoringpb.MonitoringIngest_RecordResources_FullMethodName: AuditResourceCreate,
+	monitoringpb.MonitoringIngest_StreamMetrics_FullMethodName:   AuditMetricsIngest,
+}
+
+// grpcIngestServer serves the MonitoringIngest API from the same service as
+// the REST API
+type grpcIngestServer struct {
+	monitoringpb.UnimplementedMonitoringIngestServer
+	service MonitoringService
+}
+
+// NewGRPCServer creates a gRPC server serving the MonitoringIngest API. Calls
+// are authenticated, authorized for the monitor role and rate limited like
+// REST requests.
+func (s *APIServer) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	server := grpc.NewServer(opts...)
+	monitoringpb.RegisterMonitoringIngestServer(server, &grpcIngestServer{service: s.service})
+	return server
+}
+
+// startGRPC serves the MonitoringIngest API on the configured gRPC port
+func (s *APIServer) startGRPC() error {
+	var opts []grpc.ServerOption
+	if s.config.TLS.Enabled {
+		tlsConfig, err := s.config.TLS.LoadTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to configure gRPC TLS: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	addr := fmt.Sprintf(":%d", s.config.GRPCPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := s.NewGRPCServer(opts...)
+	go func() {
+		log.Printf("Starting monitoring gRPC server on %s", addr)
+		if err := server.Serve(listener); err != nil {
+			log.Printf("Monitoring gRPC server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *APIServer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorizeCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+
+	resourceID := ""
+	if recorded, ok := resp.(*monitoringpb.RecordResponse); ok {
+		resourceID = recorded.GetId()
+	}
+	s.auditCall(ctx, info.FullMethod, resourceID, err)
+	return resp, err
+}
+
+func (s *APIServer) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorizeCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+	s.auditCall(ctx, info.FullMethod, "", err)
+	return err
+}
+
+// authorizedStream carries the authenticated user to stream handlers
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorizeCall authenticates a call from its metadata, which carries the
+// same credentials as REST headers, and returns its context with the user
+func (s *APIServer) authorizeCall(ctx context.Context) (context.Context, error) {
+	if s.auth == nil {
+		return nil, status.Errorf(codes.Unavailable, "authentication unavailable: %v", s.authErr)
+	}
+
+	r := &http.Request{Header: http.Header{}}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	user, err := s.auth.AuthenticateRequest(r)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	role := RoleMonitor
+	if required := s.config.Auth.RequiredRole; ValidateRole(required) && !s.auth.hasRole(role, required) {
+		role = required
+	}
+	if err := s.auth.Authorize(user, role); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "authorization failed: %v", err)
+	}
+
+	if s.limiter != nil {
+		client := user.UserID
+		if client == "" || client == "anonymous" {
+			client = r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				client = host
+			}
+		}
+		if decision := s.limiter.allow(client, user.Role); !decision.allowed {
+			return nil, status.Errorf(codes.ResourceExhausted, "too many requests: %s", decision.reason)
+		}
+	}
+
+	return context.WithValue(ctx, "user", user), nil
+}
+
+// auditCall records an authorized call in the audit log
+func (s *APIServer) auditCall(ctx context.Context, method, resourceID string, callErr error) {
+	action, ok := grpcAuditActions[method]
+	if !ok {
+		return
+	}
+
+	entry := &AuditEntry{
+		Timestamp:  time.Now(),
+		Action:     action,
+		ResourceID: resourceID,
+		Method:     "GRPC",
+		Path:       method,
+		StatusCode: grpcHTTPStatus(status.Code(callErr)),
+		Success:    callErr == nil,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.RemoteAddr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.RemoteAddr); err == nil {
+			entry.RemoteAddr = host
+		}
+	}
+	if user, ok := GetUserFromContext(ctx); ok {
+		entry.UserID = user.UserID
+		entry.Role = user.Role
+		entry.OrgID = user.OrgID
+	}
+
+	if err := s.service.RecordAudit(ctx, entry); err != nil {
+		log.Printf("Failed to record audit entry for %s: %v", action, err)
+	}
+}
+
+// grpcHTTPStatus maps a gRPC code to the HTTP status recorded in audit entries
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (g *grpcIngestServer) RecordRound(ctx context.Context, req *monitoringpb.Round) (*monitoringpb.RecordResponse, error) {
+	id, err := g.recordRound(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &monitoringpb.RecordResponse{Id: id}, nil
+}
+
+func (g *grpcIngestServer) RecordUpdate(ctx context.Context, req *monitoringpb.Update) (*monitoringpb.RecordResponse, error) {
+	id, err := g.recordUpdate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &monitoringpb.RecordResponse{Id: id}, nil
+}
+
+func (g *grpcIngestServer) RecordEvent(ctx context.Context, req *monitoringpb.Event) (*monitoringpb.RecordResponse, error) {
+	id, err := g.recordEvent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &monitoringpb.RecordResponse{Id: id}, nil
+}
+
+func (g *grpcIngestServer) RecordResources(ctx context.Context, req *monitoringpb.Resources) (*monitoringpb.RecordResponse, error) {
+	if err := g.recordResources(ctx, req); err != nil {
+		return nil, err
+	}
+	return &monitoringpb.RecordResponse{}, nil
+}
+
+// StreamMetrics records metrics until the client closes the stream. Metrics
+// that fail are reported in the summary and do not end the stream.
+func (g *grpcIngestServer) StreamMetrics(stream monitoringpb.MonitoringIngest_StreamMetricsServer) error {
+	ctx := stream.Context()
+	summary := &monitoringpb.StreamSummary{}
+
+	for index := int32(0); ; index++ {
+		metric, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := g.recordMetric(ctx, metric); err != nil {
+			summary.Failed++
+			if len(summary.Errors) < maxStreamErrors {
+				summary.Errors = append(summary.Errors, &monitoringpb.StreamError{Index: index, Error: status.Convert(err).Message()})
+			}
+			continue
+		}
+		summary.Accepted++
+	}
+}
+
+func (g *grpcIngestServer) recordMetric(ctx context.Context, metric *monitoringpb.Metric) error {
+	var err error
+	switch m := metric.GetMetric().(type) {
+	case *monitoringpb.Metric_Round:
+		_, err = g.recordRound(ctx, m.Round)
+	case *monitoringpb.Metric_Update:
+		_, err = g.recordUpdate(ctx, m.Update)
+	case *monitoringpb.Metric_Event:
+		_, err = g.recordEvent(ctx, m.Event)
+	case *monitoringpb.Metric_Resources:
+		err = g.recordResources(ctx, m.Resources)
+	default:
+		err = status.Error(codes.InvalidArgument, "metric is empty")
+	}
+	return err
+}
+
+func (g *grpcIngestServer) recordRound(ctx context.Context, req *monitoringpb.Round) (string, error) {
+	round := roundFromProto(req)
+	if round.EndTime == nil {
+		if err := g.service.RecordRoundStart(ctx, round); err != nil {
+			return "", status.Error(codes.Internal, err.Error())
+		}
+		return round.ID, nil
+	}
+
+	if round.ID == "" {
+		return "", status.Error(codes.InvalidArgument, "round id is required")
+	}
+	if err := g.service.RecordRoundEnd(ctx, round.ID, round); err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return round.ID, nil
+}
+
+func (g *grpcIngestServer) recordUpdate(ctx context.Context, req *monitoringpb.Update) (string, error) {
+	update := updateFromProto(req)
+	if err := g.service.RecordModelUpdate(ctx, update); err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return update.ID, nil
+}
+
+func (g *grpcIngestServer) recordEvent(ctx context.Context, req *monitoringpb.Event) (string, error) {
+	event := eventFromProto(req)
+	if err := g.service.RecordEvent(ctx, event); err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	return event.ID, nil
+}
+
+func (g *grpcIngestServer) recordResources(ctx context.Context, req *monitoringpb.Resources) error {
+	if req.GetSource() == "" {
+		return status.Error(codes.InvalidArgument, "source is required for resource metrics")
+	}
+	if err := g.service.RecordResourceMetrics(ctx, req.GetSource(), resourcesFromProto(req)); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func roundFromProto(req *monitoringpb.Round) *RoundMetrics {
+	round := &RoundMetrics{
+		ID:               req.GetId(),
+		FederationID:     req.GetFederationId(),
+		RoundNumber:      int(req.GetRoundNumber()),
+		Algorithm:        req.GetAlgorithm(),
+		Duration:         req.GetDuration().AsDuration(),
+		ParticipantCount: int(req.GetParticipantCount()),
+		UpdatesReceived:  int(req.GetUpdatesReceived()),
+		AggregationTime:  req.GetAggregationTime().AsDuration(),
+		ModelAccuracy:    req.ModelAccuracy,
+		ModelLoss:        req.ModelLoss,
+		ConvergenceRate:  req.ConvergenceRate,
+		Status:           req.GetStatus(),
+	}
+	if req.GetStartTime() != nil {
+		round.StartTime = req.GetStartTime().AsTime()
+	}
+	if req.GetEndTime() != nil {
+		end := req.GetEndTime().AsTime()
+		round.EndTime = &end
+	}
+	if req.GetHyperparameters() != nil {
+		round.Hyperparameters = req.GetHyperparameters().AsMap()
+	}
+	return round
+}
+
+func updateFromProto(req *monitoringpb.Update) *ModelUpdateMetrics {
+	update := &ModelUpdateMetrics{
+		ID:                req.GetId(),
+		FederationID:      req.GetFederationId(),
+		CollaboratorID:    req.GetCollaboratorId(),
+		RoundNumber:       int(req.GetRoundNumber()),
+		UpdateSize:        int(req.GetUpdateSizeBytes()),
+		NumSamples:        req.GetNumSamples(),
+		ProcessingTime:    req.GetProcessingTimeMs(),
+		Staleness:         int(req.GetStaleness()),
+		Weight:            req.GetWeight(),
+		QualityScore:      req.QualityScore,
+		CompressionRatio:  req.CompressionRatio,
+		ImageDigest:       req.GetImageDigest(),
+		DPClipNorm:        req.GetDpClipNorm(),
+		DPNoiseMultiplier: req.GetDpNoiseMultiplier(),
+	}
+	if req.GetTimestamp() != nil {
+		update.Timestamp = req.GetTimestamp().AsTime()
+	}
+	return update
+}
+
+func eventFromProto(req *monitoringpb.Event) *MonitoringEvent {
+	event := &MonitoringEvent{
+		ID:           req.GetId(),
+		FederationID: req.GetFederationId(),
+		Type:         MetricType(req.GetType()),
+		Source:       req.GetSource(),
+		Level:        req.GetLevel(),
+		Message:      req.GetMessage(),
+	}
+	if req.GetTimestamp() != nil {
+		event.Timestamp = req.GetTimestamp().AsTime()
+	}
+	if req.GetData() != nil {
+		event.Data = req.GetData().AsMap()
+	}
+	return event
+}
+
+func resourcesFromProto(req *monitoringpb.Resources) *ResourceMetrics {
+	resources := &ResourceMetrics{
+		CPUUsage:       req.GetCpuUsagePercent(),
+		MemoryUsage:    req.GetMemoryUsagePercent(),
+		MemoryUsed:     req.GetMemoryUsedBytes(),
+		MemoryTotal:    req.GetMemoryTotalBytes(),
+		DiskUsage:      req.GetDiskUsagePercent(),
+		NetworkRxRate:  req.GetNetworkRxRateMbps(),
+		NetworkTxRate:  req.GetNetworkTxRateMbps(),
+		GPUUsage:       req.GpuUsagePercent,
+		GPUMemory:      req.GpuMemoryPercent,
+		GPUTemperature: req.GpuTemperatureCelsius,
+		GPUCount:       int(req.GetGpuCount()),
+	}
+	if req.GetTimestamp() != nil {
+		resources.Timestamp = req.GetTimestamp().AsTime()
+	}
+	return resources
+}
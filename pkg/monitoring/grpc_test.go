@@ -0,0 +1,165 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/api/monitoringpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newGRPCTestClient(t *testing.T, config *MonitoringConfig) (monitoringpb.MonitoringIngestClient, *MemoryStorage) {
+	t.Helper()
+	storage := NewMemoryStorage(config)
+	server := NewAPIServer(storage, config).NewGRPCServer()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return monitoringpb.NewMonitoringIngestClient(conn), storage
+}
+
+func TestGRPCRecordRound(t *testing.T) {
+	client, storage := newGRPCTestClient(t, &MonitoringConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hyperparameters, _ := structpb.NewStruct(map[string]interface{}{"learning_rate": 0.01})
+	started, err := client.RecordRound(ctx, &monitoringpb.Round{
+		FederationId:    "fed-1",
+		RoundNumber:     1,
+		StartTime:       timestamppb.New(start),
+		Status:          "running",
+		Hyperparameters: hyperparameters,
+	})
+	if err != nil || started.GetId() == "" {
+		t.Fatalf("RecordRound(start) = %v, %v, want a round ID", started, err)
+	}
+
+	accuracy := 0.9
+	if _, err := client.RecordRound(ctx, &monitoringpb.Round{
+		Id:            started.GetId(),
+		FederationId:  "fed-1",
+		RoundNumber:   1,
+		StartTime:     timestamppb.New(start),
+		EndTime:       timestamppb.New(start.Add(time.Minute)),
+		ModelAccuracy: &accuracy,
+		Status:        "completed",
+	}); err != nil {
+		t.Fatalf("RecordRound(end) error = %v", err)
+	}
+
+	round, err := storage.GetRound(ctx, started.GetId())
+	if err != nil {
+		t.Fatalf("GetRound() error = %v", err)
+	}
+	if round.EndTime == nil || !round.EndTime.Equal(start.Add(time.Minute)) || round.ModelAccuracy == nil || *round.ModelAccuracy != accuracy {
+		t.Errorf("round = %+v, want it finished with accuracy %v", round, accuracy)
+	}
+
+	// Finishing a round needs its ID
+	_, err = client.RecordRound(ctx, &monitoringpb.Round{FederationId: "fed-1", EndTime: timestamppb.Now()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RecordRound(end without id) code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestGRPCStreamMetrics(t *testing.T) {
+	client, storage := newGRPCTestClient(t, &MonitoringConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamMetrics(ctx)
+	if err != nil {
+		t.Fatalf("StreamMetrics() error = %v", err)
+	}
+	metrics := []*monitoringpb.Metric{
+		{Metric: &monitoringpb.Metric_Update{Update: &monitoringpb.Update{FederationId: "fed-1", CollaboratorId: "collab-1", RoundNumber: 1}}},
+		{Metric: &monitoringpb.Metric_Event{Event: &monitoringpb.Event{FederationId: "fed-1", Type: string(MetricTypeTraining), Message: "epoch done"}}},
+		{Metric: &monitoringpb.Metric_Resources{Resources: &monitoringpb.Resources{CpuUsagePercent: 50}}},
+		{Metric: &monitoringpb.Metric_Resources{Resources: &monitoringpb.Resources{Source: "collab-1", CpuUsagePercent: 50}}},
+		{},
+	}
+	for _, metric := range metrics {
+		if err := stream.Send(metric); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv() error = %v", err)
+	}
+
+	if summary.GetAccepted() != 3 || summary.GetFailed() != 2 {
+		t.Fatalf("summary = %v, want 3 accepted and 2 failed", summary)
+	}
+	if errs := summary.GetErrors(); len(errs) != 2 || errs[0].GetIndex() != 2 || errs[1].GetIndex() != 4 {
+		t.Errorf("errors = %v, want metrics 2 and 4", errs)
+	}
+
+	updates, _ := storage.GetModelUpdates(ctx, &MetricsFilter{FederationID: "fed-1"})
+	events, _ := storage.GetEvents(ctx, &MetricsFilter{FederationID: "fed-1", MetricType: MetricTypeTraining})
+	if len(updates) != 1 || len(events) != 1 {
+		t.Errorf("recorded %d updates and %d training events, want 1 each", len(updates), len(events))
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	client, storage := newGRPCTestClient(t, &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeyAuth: APIKeyConfig{Enabled: true, Keys: map[string]string{
+				"monitor-key":  RoleMonitor,
+				"readonly-key": RoleReadOnly,
+			}},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   codes.Code
+	}{
+		{"no credentials", "", codes.Unauthenticated},
+		{"unknown key", "wrong-key", codes.Unauthenticated},
+		{"read-only key", "readonly-key", codes.PermissionDenied},
+		{"monitor key", "monitor-key", codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.apiKey != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", tt.apiKey)
+			}
+			_, err := client.RecordEvent(ctx, &monitoringpb.Event{FederationId: "fed-1", Type: string(MetricTypeRound)})
+			if status.Code(err) != tt.want {
+				t.Errorf("RecordEvent() code = %v, want %v", status.Code(err), tt.want)
+			}
+		})
+	}
+
+	// Authorized calls are audited like REST writes
+	entries, err := storage.GetAuditLog(context.Background(), &AuditFilter{Action: AuditEventCreate})
+	if err != nil || len(entries) != 1 || entries[0].Method != "GRPC" || !entries[0].Success {
+		t.Errorf("audit entries = %v, %v, want one successful gRPC event.create", entries, err)
+	}
+}
@@ -273,6 +273,29 @@ func (h *MonitoringHooks) OnModelUpdateReceived(ctx context.Context, federationI
 	return nil
 }
 
+// OnUpdateDropped records when an aggregator rejects a model update
+func (h *MonitoringHooks) OnUpdateDropped(ctx context.Context, federationID, collaboratorID string, roundNumber int, reason DropReason, detail string) error {
+	if !h.enabled {
+		return nil
+	}
+
+	dropped := &DroppedUpdate{
+		FederationID:   federationID,
+		CollaboratorID: collaboratorID,
+		RoundNumber:    roundNumber,
+		Reason:         reason,
+		Detail:         detail,
+		Timestamp:      time.Now(),
+	}
+
+	if err := h.service.RecordDroppedUpdate(ctx, dropped); err != nil {
+		log.Printf("Failed to record dropped update: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 // Aggregation Hooks
 
 // OnAggregationStart records when aggregation starts
@@ -305,22 +328,21 @@ func (h *MonitoringHooks) OnAggregationEnd(ctx context.Context, aggregationID st
 		return nil
 	}
 
-	// This would need to be implemented to update the existing aggregation record
-	// For now, we'll create a new record with the completion data
-	endTime := time.Now()
-
-	// In a real implementation, you'd update the existing record
-	// Here we're showing the data structure for completion
-	_ = &AggregationMetrics{
-		ID:                 aggregationID,
-		EndTime:            endTime,
-		Duration:           duration,
-		ModelConvergence:   convergence,
-		AggregationQuality: quality,
+	aggregation, err := h.service.GetAggregation(ctx, aggregationID)
+	if err != nil {
+		log.Printf("Failed to record aggregation end: %v", err)
+		return err
 	}
 
-	// Log completion for now
-	log.Printf("Aggregation %s completed in %v", aggregationID, duration)
+	aggregation.EndTime = time.Now()
+	aggregation.Duration = duration
+	aggregation.ModelConvergence = convergence
+	aggregation.AggregationQuality = quality
+
+	if err := h.service.UpdateAggregation(ctx, aggregationID, aggregation); err != nil {
+		log.Printf("Failed to record aggregation end: %v", err)
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,369 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImportBodyBytes limits the size of a historical import request body
+const maxImportBodyBytes = 64 << 20
+
+// ImportArchive holds the historical records of a single federation
+type ImportArchive struct {
+	Federation    *FederationMetrics     `json:"federation,omitempty"`
+	Collaborators []*CollaboratorMetrics `json:"collaborators,omitempty"`
+	Rounds        []*RoundMetrics        `json:"rounds,omitempty"`
+	Updates       []*ModelUpdateMetrics  `json:"updates,omitempty"`
+	Events        []*MonitoringEvent     `json:"events,omitempty"`
+}
+
+// ImportResult reports what a historical import added to storage
+type ImportResult struct {
+	FederationID      string `json:"federation_id"`
+	FederationCreated bool   `json:"federation_created"`
+	Collaborators     int    `json:"collaborators"`
+	Rounds            int    `json:"rounds"`
+	Updates           int    `json:"updates"`
+	Events            int    `json:"events"`
+	Skipped           int    `json:"skipped"` // records that were already stored
+}
+
+// ImportHistory validates archive and stores its records. Records keep their
+// original timestamps, and records already in storage are skipped, so an
+// archive can be imported more than once.
+func ImportHistory(ctx context.Context, service MonitoringService, archive *ImportArchive) (*ImportResult, error) {
+	if err := archive.normalize(); err != nil {
+		return nil, err
+	}
+	return service.ImportFederationHistory(ctx, archive)
+}
+
+// FederationID returns the federation the archive belongs to
+func (a *ImportArchive) FederationID() string {
+	if a.Federation != nil && a.Federation.ID != "" {
+		return a.Federation.ID
+	}
+	for _, round := range a.Rounds {
+		if round.FederationID != "" {
+			return round.FederationID
+		}
+	}
+	for _, update := range a.Updates {
+		if update.FederationID != "" {
+			return update.FederationID
+		}
+	}
+	for _, event := range a.Events {
+		if event.FederationID != "" {
+			return event.FederationID
+		}
+	}
+	return ""
+}
+
+// normalize checks that every record belongs to one federation and has a
+// timestamp, and assigns stable IDs to records without one
+func (a *ImportArchive) normalize() error {
+	federationID := a.FederationID()
+	if federationID == "" {
+		return fmt.Errorf("federation id is required")
+	}
+	if a.Federation != nil {
+		a.Federation.ID = federationID
+	}
+
+	checkFederation := func(kind string, index int, id *string) error {
+		if *id == "" {
+			*id = federationID
+		} else if *id != federationID {
+			return fmt.Errorf("%s %d belongs to federation %s, expected %s", kind, index, *id, federationID)
+		}
+		return nil
+	}
+
+	for i, collaborator := range a.Collaborators {
+		if err := checkFederation("collaborator", i, &collaborator.FederationID); err != nil {
+			return err
+		}
+		if collaborator.ID == "" {
+			return fmt.Errorf("collaborator %d: id is required", i)
+		}
+	}
+
+	for i, round := range a.Rounds {
+		if err := checkFederation("round", i, &round.FederationID); err != nil {
+			return err
+		}
+		if round.StartTime.IsZero() {
+			return fmt.Errorf("round %d: start_time is required", i)
+		}
+		if round.ID == "" {
+			round.ID = fmt.Sprintf("%s-round-%d", federationID, round.RoundNumber)
+		}
+	}
+
+	for i, update := range a.Updates {
+		if err := checkFederation("update", i, &update.FederationID); err != nil {
+			return err
+		}
+		if update.Timestamp.IsZero() {
+			return fmt.Errorf("update %d: timestamp is required", i)
+		}
+		if update.ID == "" {
+			update.ID = fmt.Sprintf("%s-%s-%d-%d", federationID, update.CollaboratorID,
+				update.RoundNumber, update.Timestamp.UnixNano())
+		}
+	}
+
+	for i, event := range a.Events {
+		if err := checkFederation("event", i, &event.FederationID); err != nil {
+			return err
+		}
+		if event.Timestamp.IsZero() {
+			return fmt.Errorf("event %d: timestamp is required", i)
+		}
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("%s-event-%d-%d", federationID, event.Timestamp.UnixNano(), i)
+		}
+	}
+
+	return nil
+}
+
+// summaryFederation derives a federation record from the archive's rounds for
+// archives that do not include one
+func (a *ImportArchive) summaryFederation() *FederationMetrics {
+	federation := &FederationMetrics{
+		ID:     a.FederationID(),
+		Name:   a.FederationID(),
+		Status: StatusCompleted,
+	}
+
+	var endTime time.Time
+	for _, round := range a.Rounds {
+		if federation.StartTime.IsZero() || round.StartTime.Before(federation.StartTime) {
+			federation.StartTime = round.StartTime
+		}
+		if round.EndTime != nil && round.EndTime.After(endTime) {
+			endTime = *round.EndTime
+		}
+		if round.RoundNumber > federation.CurrentRound {
+			federation.CurrentRound = round.RoundNumber
+		}
+		if federation.Algorithm == "" {
+			federation.Algorithm = round.Algorithm
+		}
+	}
+
+	collaborators := make(map[string]bool)
+	for _, collaborator := range a.Collaborators {
+		collaborators[collaborator.ID] = true
+	}
+	for _, update := range a.Updates {
+		collaborators[update.CollaboratorID] = true
+	}
+
+	federation.TotalRounds = federation.CurrentRound
+	federation.TotalCollabs = len(collaborators)
+	if !endTime.IsZero() {
+		federation.EndTime = &endTime
+		federation.LastUpdate = endTime
+	} else {
+		federation.LastUpdate = federation.StartTime
+	}
+
+	return federation
+}
+
+// ReadExportCSV reads a CSV file written by ExportMetrics and appends its
+// records to archive
+func ReadExportCSV(dataset ExportDataset, r io.Reader, archive *ImportArchive) error {
+	switch dataset {
+	case ExportRounds:
+		rows, err := readCSV[roundExportRow](r)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			archive.Rounds = append(archive.Rounds, fromRoundExportRow(row))
+		}
+
+	case ExportModelUpdates:
+		rows, err := readCSV[updateExportRow](r)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			archive.Updates = append(archive.Updates, &ModelUpdateMetrics{
+				ID:                row.ID,
+				FederationID:      row.FederationID,
+				CollaboratorID:    row.CollaboratorID,
+				RoundNumber:       int(row.RoundNumber),
+				Timestamp:         row.Timestamp,
+				UpdateSize:        int(row.UpdateSizeBytes),
+				ProcessingTime:    row.ProcessingTimeMs,
+				Staleness:         int(row.Staleness),
+				Weight:            row.Weight,
+				QualityScore:      row.QualityScore,
+				CompressionRatio:  row.CompressionRatio,
+				DPClipNorm:        row.DPClipNorm,
+				DPNoiseMultiplier: row.DPNoiseMultiplier,
+			})
+		}
+
+	case ExportEvents:
+		rows, err := readCSV[eventExportRow](r)
+		if err != nil {
+			return err
+		}
+		for i, row := range rows {
+			event := &MonitoringEvent{
+				ID:           row.ID,
+				FederationID: row.FederationID,
+				Type:         MetricType(row.Type),
+				Timestamp:    row.Timestamp,
+				Source:       row.Source,
+				Level:        row.Level,
+				Message:      row.Message,
+			}
+			if row.Data != "" {
+				if err := json.Unmarshal([]byte(row.Data), &event.Data); err != nil {
+					return fmt.Errorf("row %d: invalid event data: %v", i+1, err)
+				}
+			}
+			archive.Events = append(archive.Events, event)
+		}
+
+	default:
+		return fmt.Errorf("unsupported import dataset: %s", dataset)
+	}
+
+	return nil
+}
+
+func fromRoundExportRow(row roundExportRow) *RoundMetrics {
+	round := &RoundMetrics{
+		ID:               row.ID,
+		FederationID:     row.FederationID,
+		RoundNumber:      int(row.RoundNumber),
+		Algorithm:        row.Algorithm,
+		StartTime:        row.StartTime,
+		Duration:         time.Duration(row.DurationMs) * time.Millisecond,
+		ParticipantCount: int(row.ParticipantCount),
+		UpdatesReceived:  int(row.UpdatesReceived),
+		AggregationTime:  time.Duration(row.AggregationTimeMs) * time.Millisecond,
+		ModelAccuracy:    row.ModelAccuracy,
+		ModelLoss:        row.ModelLoss,
+		ConvergenceRate:  row.ConvergenceRate,
+		Status:           row.Status,
+	}
+	if row.EndTime != nil {
+		endTime := time.UnixMilli(*row.EndTime).UTC()
+		round.EndTime = &endTime
+	}
+	return round
+}
+
+// readCSV reads rows written by writeCSV, matching columns by header name.
+// Unknown columns are ignored.
+func readCSV[T any](r io.Reader) ([]T, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	rowType := reflect.TypeOf((*T)(nil)).Elem()
+	fieldIndex := make(map[string]int, rowType.NumField())
+	for i := 0; i < rowType.NumField(); i++ {
+		fieldIndex[strings.Split(rowType.Field(i).Tag.Get("parquet"), ",")[0]] = i
+	}
+
+	columns := make([]int, len(header))
+	for i, name := range header {
+		index, exists := fieldIndex[strings.TrimSpace(name)]
+		if !exists {
+			index = -1
+		}
+		columns[i] = index
+	}
+
+	var rows []T
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+
+		var row T
+		value := reflect.ValueOf(&row).Elem()
+		for i, field := range columns {
+			if field < 0 || i >= len(record) {
+				continue
+			}
+			if err := parseCSVValue(value.Field(field), record[i]); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %v", line, header[i], err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCSVValue is the inverse of formatCSVValue
+func parseCSVValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			return nil
+		}
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	switch field.Interface().(type) {
+	case time.Time:
+		if value == "" {
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+	case string:
+		field.SetString(value)
+	case int64:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case float64:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported column type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportHistoryFromExportCSV(t *testing.T) {
+	source := newExportTestStorage(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	filter := &MetricsFilter{FederationID: "fed-a"}
+	if err := ExportMetrics(ctx, source, ExportRounds, ExportFormatCSV, filter, &buf); err != nil {
+		t.Fatalf("ExportMetrics() error = %v", err)
+	}
+
+	archive := &ImportArchive{}
+	if err := ReadExportCSV(ExportRounds, bytes.NewReader(buf.Bytes()), archive); err != nil {
+		t.Fatalf("ReadExportCSV() error = %v", err)
+	}
+
+	target := NewMemoryStorage(&MonitoringConfig{})
+	result, err := ImportHistory(ctx, target, archive)
+	if err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	if result.FederationID != "fed-a" || !result.FederationCreated || result.Rounds != 2 {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+
+	federation, err := target.GetFederation(ctx, "fed-a")
+	if err != nil {
+		t.Fatalf("Imported federation not found: %v", err)
+	}
+	if federation.Status != StatusCompleted || federation.TotalRounds != 2 {
+		t.Errorf("Unexpected derived federation: %+v", federation)
+	}
+
+	rounds, _ := target.GetFederationRounds(ctx, "fed-a")
+	original, _ := source.GetFederationRounds(ctx, "fed-a")
+	if len(rounds) != len(original) {
+		t.Fatalf("Expected %d rounds, got %d", len(original), len(rounds))
+	}
+	for i := range rounds {
+		if !rounds[i].StartTime.Equal(original[i].StartTime) || *rounds[i].ModelAccuracy != *original[i].ModelAccuracy {
+			t.Errorf("Round %d not preserved: got %+v, want %+v", i, rounds[i], original[i])
+		}
+	}
+
+	// Re-importing the same records is a no-op
+	again := &ImportArchive{}
+	if err := ReadExportCSV(ExportRounds, bytes.NewReader(buf.Bytes()), again); err != nil {
+		t.Fatalf("ReadExportCSV() error = %v", err)
+	}
+	result, err = ImportHistory(ctx, target, again)
+	if err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	if result.FederationCreated || result.Rounds != 0 || result.Skipped != 2 {
+		t.Errorf("Expected re-import to skip all rounds, got %+v", result)
+	}
+}
+
+func TestImportHistoryValidation(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		archive *ImportArchive
+	}{
+		{"no federation", &ImportArchive{Rounds: []*RoundMetrics{{RoundNumber: 1, StartTime: now}}}},
+		{"mixed federations", &ImportArchive{
+			Federation: &FederationMetrics{ID: "fed-a"},
+			Rounds:     []*RoundMetrics{{FederationID: "fed-b", RoundNumber: 1, StartTime: now}},
+		}},
+		{"missing round time", &ImportArchive{Rounds: []*RoundMetrics{{FederationID: "fed-a", RoundNumber: 1}}}},
+		{"missing update time", &ImportArchive{
+			Federation: &FederationMetrics{ID: "fed-a"},
+			Updates:    []*ModelUpdateMetrics{{CollaboratorID: "c1"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewMemoryStorage(&MonitoringConfig{})
+			if _, err := ImportHistory(context.Background(), storage, tt.archive); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestParseAggregatorLog(t *testing.T) {
+	log := `2025/03/01 10:00:00 Starting SYNC aggregator on localhost:50051
+2025/03/01 10:00:00 Expecting 2 collaborators for 2 rounds
+2025/03/01 10:00:00 Model size: 10 parameters
+2025/03/01 10:00:01 Collaborator collab1 joining federation
+2025/03/01 10:00:01 Starting round 1/2
+2025/03/01 10:00:05 Received update 1/2 for round 1
+2025/03/01 10:00:06 Dropped update from collab2 in round 1 (size_mismatch): expected 10 parameters, got 4
+2025/03/01 10:00:07 Received update 2/2 for round 1
+2025/03/01 10:00:08 Round 1 complete, model saved to save/model_round_1.pt
+2025/03/01 10:00:08 Starting round 2/2
+not a log line
+2025/03/01 10:00:12.250000 Received update 1/2 for round 2
+2025/03/01 10:00:13 Received update 2/2 for round 2
+2025/03/01 10:00:14 Round 2 complete, model saved to save/model_round_2.pt
+2025/03/01 10:00:14 All 2 rounds completed successfully
+`
+
+	archive, err := ParseAggregatorLog(strings.NewReader(log), "fed-legacy")
+	if err != nil {
+		t.Fatalf("ParseAggregatorLog() error = %v", err)
+	}
+
+	federation := archive.Federation
+	if federation.Status != StatusCompleted || federation.Mode != "sync" || federation.TotalRounds != 2 ||
+		federation.TotalCollabs != 2 || federation.ModelSize != 10 || federation.EndTime == nil {
+		t.Errorf("Unexpected federation: %+v", federation)
+	}
+
+	if len(archive.Rounds) != 2 {
+		t.Fatalf("Expected 2 rounds, got %d", len(archive.Rounds))
+	}
+	first := archive.Rounds[0]
+	if first.UpdatesReceived != 2 || first.Duration != 7*time.Second || first.Status != "completed" {
+		t.Errorf("Unexpected round 1: %+v", first)
+	}
+
+	if len(archive.Updates) != 4 {
+		t.Errorf("Expected 4 updates, got %d", len(archive.Updates))
+	}
+	if got := archive.Updates[2].Timestamp.Nanosecond(); got != 250*int(time.Millisecond) {
+		t.Errorf("Expected fractional seconds to be parsed, got %dns", got)
+	}
+
+	if len(archive.Events) != 1 || archive.Events[0].Source != "collab2" || archive.Events[0].Data["reason"] != "size_mismatch" {
+		t.Errorf("Unexpected drop events: %+v", archive.Events)
+	}
+	if len(archive.Collaborators) != 1 || archive.Collaborators[0].ID != "collab1" {
+		t.Errorf("Unexpected collaborators: %+v", archive.Collaborators)
+	}
+
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	result, err := ImportHistory(context.Background(), storage, archive)
+	if err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	if result.Rounds != 2 || result.Updates != 4 || result.Events != 1 {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+}
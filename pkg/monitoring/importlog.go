@@ -0,0 +1,251 @@
+package monitoring
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logTimeLayout is the timestamp prefix written by the standard log package
+const logTimeLayout = "2006/01/02 15:04:05"
+
+var (
+	logLinePattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2})(\.\d+)? (.*)$`)
+
+	logStartSync     = regexp.MustCompile(`^Starting SYNC aggregator on (\S+)`)
+	logStartAsync    = regexp.MustCompile(`^Starting ASYNC aggregator on (\S+)`)
+	logStartModular  = regexp.MustCompile(`^Starting Modular Aggregator with (\S+) algorithm in (\S+) mode`)
+	logExpecting     = regexp.MustCompile(`^Expecting (\d+) collaborators for (\d+) rounds`)
+	logModelSize     = regexp.MustCompile(`^(?:Model size: |Loaded initial model with )(\d+) parameters`)
+	logRoundStart    = regexp.MustCompile(`^Starting round (\d+)/(\d+)`)
+	logRoundEnd      = regexp.MustCompile(`^Round (\d+) complete`)
+	logAsyncRoundEnd = regexp.MustCompile(`^Async round (\d+) complete`)
+	logJoin          = regexp.MustCompile(`^Collaborator (\S+) joining`)
+	logSyncUpdate    = regexp.MustCompile(`^Received update \d+/\d+ for round (\d+)`)
+	logNamedUpdate   = regexp.MustCompile(`^Received (?:\S+ )?update \d+ from (\S+) \(round (\d+)\)`)
+	logDropped       = regexp.MustCompile(`^Dropped update from (\S+) in round (\d+) \(([^)]+)\): (.*)$`)
+	logAborted       = regexp.MustCompile(`^Aborting in round (\d+)`)
+	logCompleted     = regexp.MustCompile(`^(?:All \d+ rounds completed successfully|Async FL completed)`)
+)
+
+// ParseAggregatorLog reconstructs the history of a federation from an
+// aggregator log written by the standard log package. Sync aggregator logs do
+// not name the sender of each update, so their updates are attributed to
+// "unknown".
+func ParseAggregatorLog(r io.Reader, federationID string) (*ImportArchive, error) {
+	if federationID == "" {
+		return nil, fmt.Errorf("federation id is required")
+	}
+
+	federation := &FederationMetrics{
+		ID:     federationID,
+		Name:   federationID,
+		Status: StatusStopped,
+	}
+	archive := &ImportArchive{Federation: federation}
+
+	rounds := make(map[int]*RoundMetrics)
+	collaborators := make(map[string]*CollaboratorMetrics)
+	var lastRoundEnd time.Time
+	var lastTime time.Time
+
+	roundFor := func(number int, start time.Time) *RoundMetrics {
+		round, exists := rounds[number]
+		if !exists {
+			round = &RoundMetrics{
+				FederationID: federationID,
+				RoundNumber:  number,
+				Algorithm:    federation.Algorithm,
+				StartTime:    start,
+				Status:       "running",
+			}
+			rounds[number] = round
+		}
+		return round
+	}
+
+	recordUpdate := func(collaboratorID string, number int, timestamp time.Time) {
+		start := lastRoundEnd
+		if start.IsZero() {
+			start = timestamp
+		}
+		round := roundFor(number, start)
+		round.UpdatesReceived++
+
+		archive.Updates = append(archive.Updates, &ModelUpdateMetrics{
+			FederationID:   federationID,
+			CollaboratorID: collaboratorID,
+			RoundNumber:    number,
+			Timestamp:      timestamp,
+		})
+
+		if collaborator, exists := collaborators[collaboratorID]; exists {
+			collaborator.UpdatesSubmitted++
+			collaborator.LastSeen = timestamp
+			collaborator.CurrentRound = number
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		parts := logLinePattern.FindStringSubmatch(scanner.Text())
+		if parts == nil {
+			continue
+		}
+		layout := logTimeLayout
+		if parts[2] != "" {
+			// Logs written with log.Lmicroseconds carry fractional seconds
+			layout += "." + strings.Repeat("0", len(parts[2])-1)
+		}
+		timestamp, err := time.ParseInLocation(layout, parts[1]+parts[2], time.Local)
+		if err != nil {
+			continue
+		}
+		lastTime = timestamp
+		message := parts[3]
+
+		switch {
+		case logStartSync.MatchString(message):
+			match := logStartSync.FindStringSubmatch(message)
+			federation.Mode = "sync"
+			federation.AggregatorAddress = match[1]
+			federation.StartTime = timestamp
+
+		case logStartAsync.MatchString(message):
+			match := logStartAsync.FindStringSubmatch(message)
+			federation.Mode = "async"
+			federation.AggregatorAddress = match[1]
+			federation.StartTime = timestamp
+
+		case logStartModular.MatchString(message):
+			match := logStartModular.FindStringSubmatch(message)
+			federation.Algorithm = match[1]
+			federation.Mode = strings.ToLower(match[2])
+			federation.StartTime = timestamp
+
+		case logExpecting.MatchString(message):
+			match := logExpecting.FindStringSubmatch(message)
+			federation.TotalCollabs, _ = strconv.Atoi(match[1])
+			federation.TotalRounds, _ = strconv.Atoi(match[2])
+
+		case logModelSize.MatchString(message):
+			federation.ModelSize, _ = strconv.Atoi(logModelSize.FindStringSubmatch(message)[1])
+
+		case logRoundStart.MatchString(message):
+			match := logRoundStart.FindStringSubmatch(message)
+			number, _ := strconv.Atoi(match[1])
+			federation.TotalRounds, _ = strconv.Atoi(match[2])
+			roundFor(number, timestamp).StartTime = timestamp
+
+		case logRoundEnd.MatchString(message), logAsyncRoundEnd.MatchString(message):
+			match := logRoundEnd.FindStringSubmatch(message)
+			if match == nil {
+				match = logAsyncRoundEnd.FindStringSubmatch(message)
+			}
+			number, _ := strconv.Atoi(match[1])
+			start := lastRoundEnd
+			if start.IsZero() {
+				start = federation.StartTime
+			}
+			round := roundFor(number, start)
+			endTime := timestamp
+			round.EndTime = &endTime
+			round.Duration = endTime.Sub(round.StartTime)
+			round.ParticipantCount = round.UpdatesReceived
+			round.Status = "completed"
+			lastRoundEnd = timestamp
+			if number > federation.CurrentRound {
+				federation.CurrentRound = number
+			}
+
+		case logJoin.MatchString(message):
+			collaboratorID := logJoin.FindStringSubmatch(message)[1]
+			if _, exists := collaborators[collaboratorID]; !exists {
+				collaborators[collaboratorID] = &CollaboratorMetrics{
+					ID:           collaboratorID,
+					FederationID: federationID,
+					Status:       CollabStatusDisconnected,
+					JoinTime:     timestamp,
+					LastSeen:     timestamp,
+				}
+			}
+
+		case logNamedUpdate.MatchString(message):
+			match := logNamedUpdate.FindStringSubmatch(message)
+			number, _ := strconv.Atoi(match[2])
+			recordUpdate(match[1], number, timestamp)
+
+		case logSyncUpdate.MatchString(message):
+			number, _ := strconv.Atoi(logSyncUpdate.FindStringSubmatch(message)[1])
+			recordUpdate("unknown", number, timestamp)
+
+		case logDropped.MatchString(message):
+			match := logDropped.FindStringSubmatch(message)
+			number, _ := strconv.Atoi(match[2])
+			archive.Events = append(archive.Events, &MonitoringEvent{
+				FederationID: federationID,
+				Type:         MetricTypeModelUpdate,
+				Timestamp:    timestamp,
+				Source:       match[1],
+				Level:        "warning",
+				Message:      fmt.Sprintf("Dropped update from %s in round %d: %s", match[1], number, match[4]),
+				Data: map[string]interface{}{
+					"round":  number,
+					"reason": match[3],
+				},
+			})
+
+		case logAborted.MatchString(message):
+			federation.Status = StatusStopped
+			endTime := timestamp
+			federation.EndTime = &endTime
+
+		case logCompleted.MatchString(message):
+			federation.Status = StatusCompleted
+			endTime := timestamp
+			federation.EndTime = &endTime
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %v", err)
+	}
+
+	if federation.StartTime.IsZero() {
+		return nil, fmt.Errorf("no aggregator start found in log")
+	}
+
+	for _, round := range rounds {
+		archive.Rounds = append(archive.Rounds, round)
+	}
+	sort.Slice(archive.Rounds, func(i, j int) bool {
+		return archive.Rounds[i].RoundNumber < archive.Rounds[j].RoundNumber
+	})
+
+	for _, collaborator := range collaborators {
+		archive.Collaborators = append(archive.Collaborators, collaborator)
+	}
+	sort.Slice(archive.Collaborators, func(i, j int) bool {
+		return archive.Collaborators[i].ID < archive.Collaborators[j].ID
+	})
+
+	if federation.Algorithm == "" {
+		federation.Algorithm = "fedavg"
+	}
+	for _, round := range archive.Rounds {
+		if round.Algorithm == "" {
+			round.Algorithm = federation.Algorithm
+		}
+	}
+	if federation.TotalCollabs == 0 {
+		federation.TotalCollabs = len(collaborators)
+	}
+	federation.LastUpdate = lastTime
+
+	return archive, nil
+}
@@ -0,0 +1,142 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxIngestBatchSize limits the number of items accepted in a single ingest request
+const MaxIngestBatchSize = 1000
+
+// maxIngestBodyBytes limits the size of an ingest request body
+const maxIngestBodyBytes = 10 << 20
+
+// IngestItemType identifies the kind of metric carried by an ingest item
+type IngestItemType string
+
+const (
+	IngestRoundStart   IngestItemType = "round_start"
+	IngestRoundEnd     IngestItemType = "round_end"
+	IngestModelUpdate  IngestItemType = "model_update"
+	IngestAggregation  IngestItemType = "aggregation"
+	IngestContribution IngestItemType = "contribution"
+	IngestEvent        IngestItemType = "event"
+	IngestResource     IngestItemType = "resource"
+)
+
+// IngestItem is a single metric in a bulk ingest batch.
+// Source is required for resource metrics and names the reporting component.
+type IngestItem struct {
+	Type   IngestItemType  `json:"type"`
+	Source string          `json:"source,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// IngestBatch is the request body of the bulk ingest endpoint
+type IngestBatch struct {
+	Items []IngestItem `json:"items"`
+}
+
+// IngestError describes why a single batch item was rejected
+type IngestError struct {
+	Index int            `json:"index"`
+	Type  IngestItemType `json:"type"`
+	Error string         `json:"error"`
+}
+
+// IngestResult reports the outcome of a bulk ingest request
+type IngestResult struct {
+	Accepted int           `json:"accepted"`
+	Failed   int           `json:"failed"`
+	Errors   []IngestError `json:"errors,omitempty"`
+}
+
+// IngestMetrics records every item of the batch, continuing past failures
+func IngestMetrics(ctx context.Context, service MonitoringService, batch *IngestBatch) *IngestResult {
+	result := &IngestResult{}
+
+	for i, item := range batch.Items {
+		if err := ingestItem(ctx, service, item); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, IngestError{
+				Index: i,
+				Type:  item.Type,
+				Error: err.Error(),
+			})
+			continue
+		}
+		result.Accepted++
+	}
+
+	return result
+}
+
+func ingestItem(ctx context.Context, service MonitoringService, item IngestItem) error {
+	if len(item.Data) == 0 {
+		return fmt.Errorf("missing data")
+	}
+
+	switch item.Type {
+	case IngestRoundStart:
+		var round RoundMetrics
+		if err := json.Unmarshal(item.Data, &round); err != nil {
+			return fmt.Errorf("invalid round: %v", err)
+		}
+		return service.RecordRoundStart(ctx, &round)
+
+	case IngestRoundEnd:
+		var round RoundMetrics
+		if err := json.Unmarshal(item.Data, &round); err != nil {
+			return fmt.Errorf("invalid round: %v", err)
+		}
+		if round.ID == "" {
+			return fmt.Errorf("round id is required")
+		}
+		return service.RecordRoundEnd(ctx, round.ID, &round)
+
+	case IngestModelUpdate:
+		var update ModelUpdateMetrics
+		if err := json.Unmarshal(item.Data, &update); err != nil {
+			return fmt.Errorf("invalid model update: %v", err)
+		}
+		return service.RecordModelUpdate(ctx, &update)
+
+	case IngestAggregation:
+		var aggregation AggregationMetrics
+		if err := json.Unmarshal(item.Data, &aggregation); err != nil {
+			return fmt.Errorf("invalid aggregation: %v", err)
+		}
+		return service.RecordAggregation(ctx, &aggregation)
+
+	case IngestContribution:
+		var score ContributionScore
+		if err := json.Unmarshal(item.Data, &score); err != nil {
+			return fmt.Errorf("invalid contribution score: %v", err)
+		}
+		if score.CollaboratorID == "" {
+			return fmt.Errorf("collaborator_id is required for contribution scores")
+		}
+		return service.RecordContributions(ctx, []*ContributionScore{&score})
+
+	case IngestEvent:
+		var event MonitoringEvent
+		if err := json.Unmarshal(item.Data, &event); err != nil {
+			return fmt.Errorf("invalid event: %v", err)
+		}
+		return service.RecordEvent(ctx, &event)
+
+	case IngestResource:
+		if item.Source == "" {
+			return fmt.Errorf("source is required for resource metrics")
+		}
+		var resources ResourceMetrics
+		if err := json.Unmarshal(item.Data, &resources); err != nil {
+			return fmt.Errorf("invalid resource metrics: %v", err)
+		}
+		return service.RecordResourceMetrics(ctx, item.Source, &resources)
+
+	default:
+		return fmt.Errorf("unknown item type: %s", item.Type)
+	}
+}
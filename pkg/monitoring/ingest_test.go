@@ -0,0 +1,40 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestIngestMetrics(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	ctx := context.Background()
+
+	batch := &IngestBatch{Items: []IngestItem{
+		{Type: IngestRoundStart, Data: json.RawMessage(`{"id":"r1","federation_id":"fed-1","round_number":1}`)},
+		{Type: IngestModelUpdate, Data: json.RawMessage(`{"federation_id":"fed-1","collaborator_id":"c1","round_number":1}`)},
+		{Type: IngestResource, Source: "c1", Data: json.RawMessage(`{"cpu_usage_percent":42}`)},
+		{Type: IngestResource, Data: json.RawMessage(`{"cpu_usage_percent":42}`)},
+		{Type: IngestRoundEnd, Data: json.RawMessage(`{"id":"missing","status":"completed"}`)},
+		{Type: "bogus", Data: json.RawMessage(`{}`)},
+		{Type: IngestEvent},
+	}}
+
+	result := IngestMetrics(ctx, storage, batch)
+
+	if result.Accepted != 3 || result.Failed != 4 {
+		t.Fatalf("IngestMetrics() accepted=%d failed=%d, want 3 and 4", result.Accepted, result.Failed)
+	}
+
+	wantIndexes := []int{3, 4, 5, 6}
+	for i, ingestErr := range result.Errors {
+		if ingestErr.Index != wantIndexes[i] {
+			t.Errorf("Error %d index = %d, want %d", i, ingestErr.Index, wantIndexes[i])
+		}
+	}
+
+	updates, _ := storage.GetModelUpdates(ctx, &MetricsFilter{FederationID: "fed-1"})
+	if len(updates) != 1 {
+		t.Errorf("Expected 1 model update, got %d", len(updates))
+	}
+}
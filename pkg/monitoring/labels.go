@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxLabels limits the labels of a single federation or round
+const maxLabels = 64
+
+// ValidateLabels checks that labels can be written as a selector: keys are
+// not empty and contain neither '=' nor ',', and values contain no ','
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("at most %d labels are allowed", maxLabels)
+	}
+	for key, value := range labels {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("label keys must not be empty")
+		}
+		if strings.ContainsAny(key, "=,") {
+			return fmt.Errorf("label key %q must not contain '=' or ','", key)
+		}
+		if strings.Contains(value, ",") {
+			return fmt.Errorf("value of label %q must not contain ','", key)
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector parses a selector such as "experiment=ablation-3,team=vision"
+// into the labels a record must have
+func ParseLabelSelector(selector string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, requirement := range strings.Split(selector, ",") {
+		if requirement = strings.TrimSpace(requirement); requirement == "" {
+			continue
+		}
+		key, value, found := strings.Cut(requirement, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid label selector %q: expected key=value", requirement)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// FormatLabelSelector writes labels as a selector, sorted by key
+func FormatLabelSelector(labels map[string]string) string {
+	requirements := make([]string, 0, len(labels))
+	for key, value := range labels {
+		requirements = append(requirements, key+"="+value)
+	}
+	sort.Strings(requirements)
+	return strings.Join(requirements, ",")
+}
+
+// hasLabels reports whether labels include every label of selector
+func hasLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, exists := labels[key]; !exists || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeToLabels restricts filter to the federations matching its label
+// selector. Records without labels of their own, such as events or model
+// updates, are selected by the labels of their federation.
+func (s *APIServer) scopeToLabels(ctx context.Context, filter *MetricsFilter) error {
+	if len(filter.Labels) == 0 {
+		return nil
+	}
+
+	federations, err := s.service.GetFederationHistory(ctx, &MetricsFilter{
+		FederationID: filter.FederationID,
+		Labels:       filter.Labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	filter.Federations = make(map[string]bool, len(federations))
+	for _, federation := range federations {
+		filter.Federations[federation.ID] = true
+	}
+	return nil
+}
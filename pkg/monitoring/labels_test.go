@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	labels, err := ParseLabelSelector(" experiment=ablation-3, team=vision,")
+	if err != nil {
+		t.Fatalf("ParseLabelSelector: %v", err)
+	}
+	want := map[string]string{"experiment": "ablation-3", "team": "vision"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	if got := FormatLabelSelector(labels); got != "experiment=ablation-3,team=vision" {
+		t.Errorf("FormatLabelSelector() = %q", got)
+	}
+
+	for _, selector := range []string{"experiment", "=ablation-3"} {
+		if _, err := ParseLabelSelector(selector); err == nil {
+			t.Errorf("ParseLabelSelector(%q) succeeded, want an error", selector)
+		}
+	}
+	for _, labels := range []map[string]string{{"": "x"}, {"a=b": "x"}, {"a": "x,y"}} {
+		if err := ValidateLabels(labels); err == nil {
+			t.Errorf("ValidateLabels(%v) succeeded, want an error", labels)
+		}
+	}
+}
+
+func TestLabelSelectors(t *testing.T) {
+	server := newTenantTestServer()
+	requests := []struct {
+		path string
+		body string
+	}{
+		{"/api/v1/federations", `{"id": "fed-a", "labels": {"experiment": "ablation-3", "team": "vision"}}`},
+		{"/api/v1/federations", `{"id": "fed-b", "labels": {"experiment": "baseline"}}`},
+		{"/api/v1/rounds", `{"id": "round-a", "federation_id": "fed-a", "round_number": 1}`},
+		{"/api/v1/rounds", `{"id": "round-b", "federation_id": "fed-b", "round_number": 1, "labels": {"experiment": "ablation-3"}}`},
+		{"/api/v1/events", `{"id": "event-a", "federation_id": "fed-a", "type": "system", "level": "info"}`},
+		{"/api/v1/events", `{"id": "event-b", "federation_id": "fed-b", "type": "system", "level": "info"}`},
+	}
+	for _, tt := range requests {
+		if code := doAPIKeyRequest(t, server, "POST", tt.path, "lab-a-key", tt.body, nil); code != http.StatusOK {
+			t.Fatalf("POST %s = %d", tt.path, code)
+		}
+	}
+
+	var federations []*FederationMetrics
+	doAPIKeyRequest(t, server, "GET", "/api/v1/federations?label=experiment%3Dablation-3,team%3Dvision", "lab-a-key", "", &federations)
+	if len(federations) != 1 || federations[0].ID != "fed-a" {
+		t.Errorf("labeled federations = %+v", federations)
+	}
+
+	// Rounds are matched by their own labels
+	var rounds []*RoundMetrics
+	doAPIKeyRequest(t, server, "GET", "/api/v1/rounds?label=experiment%3Dablation-3", "lab-a-key", "", &rounds)
+	if len(rounds) != 1 || rounds[0].ID != "round-b" {
+		t.Errorf("labeled rounds = %+v", rounds)
+	}
+
+	// Events by the labels of their federation
+	var events []*MonitoringEvent
+	doAPIKeyRequest(t, server, "GET", "/api/v1/events?label=experiment%3Dbaseline", "lab-a-key", "", &events)
+	if len(events) == 0 {
+		t.Fatal("no events of the labeled federation")
+	}
+	for _, event := range events {
+		if event.FederationID != "fed-b" {
+			t.Errorf("event %s of federation %s selected", event.ID, event.FederationID)
+		}
+	}
+
+	// Labels do not widen the federations of other organizations
+	var other []*MonitoringEvent
+	doAPIKeyRequest(t, server, "GET", "/api/v1/events?label=experiment%3Dbaseline", "lab-b-key", "", &other)
+	if len(other) != 0 {
+		t.Errorf("events of another organization = %+v", other)
+	}
+
+	code := doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-c", "labels": {"a=b": "c"}}`, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("invalid label status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
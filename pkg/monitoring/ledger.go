@@ -0,0 +1,212 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultRoundCredit is the credit for each round joined when the ledger
+// config sets no credit rates
+const DefaultRoundCredit = 1.0
+
+// LedgerConfig sets how participation earns credits and where the ledger is
+// persisted. Credits are computed from an account's counters with the
+// current rates, so changing a rate reprices past participation too.
+type LedgerConfig struct {
+	Path               string  `yaml:"path" json:"path,omitempty"`                     // JSON file the ledger is kept in; empty keeps it in memory
+	RoundCredit        float64 `yaml:"round_credit" json:"round_credit"`               // Credits per round joined
+	SampleCredit       float64 `yaml:"sample_credit" json:"sample_credit"`             // Credits per 1000 samples contributed
+	ContributionCredit float64 `yaml:"contribution_credit" json:"contribution_credit"` // Credits per unit of contribution share
+}
+
+// LedgerAccount is the participation record of a collaborator in a
+// federation
+type LedgerAccount struct {
+	FederationID       string    `json:"federation_id"`
+	CollaboratorID     string    `json:"collaborator_id"`
+	RoundsJoined       int       `json:"rounds_joined"`
+	Updates            int       `json:"updates"`
+	SamplesContributed int64     `json:"samples_contributed"`
+	QualityScore       float64   `json:"quality_score"`   // Mean quality score the collaborator's updates reported
+	QualityReports     int       `json:"quality_reports"` // Updates that reported a quality score
+	ContributionShare  float64   `json:"contribution_share"`
+	Credits            float64   `json:"credits"`
+	FirstRound         int       `json:"first_round"`
+	LastRound          int       `json:"last_round"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ledger holds the accounts of all collaborators. The storage's lock guards
+// it.
+type ledger struct {
+	config   LedgerConfig
+	accounts map[string]*LedgerAccount // key: federation/collaborator
+}
+
+// ledgerFile is the persisted form of the ledger
+type ledgerFile struct {
+	Accounts []*LedgerAccount `json:"accounts"`
+}
+
+func newLedger(config LedgerConfig) *ledger {
+	if config.RoundCredit == 0 && config.SampleCredit == 0 && config.ContributionCredit == 0 {
+		config.RoundCredit = DefaultRoundCredit
+	}
+	return &ledger{config: config, accounts: make(map[string]*LedgerAccount)}
+}
+
+// account returns the account of a collaborator, opening it if needed
+func (l *ledger) account(federationID, collaboratorID string) *LedgerAccount {
+	key := federationID + "/" + collaboratorID
+	account, exists := l.accounts[key]
+	if !exists {
+		account = &LedgerAccount{FederationID: federationID, CollaboratorID: collaboratorID}
+		l.accounts[key] = account
+	}
+	return account
+}
+
+// recordUpdate credits a collaborator for an update. Every round the
+// collaborator submits an update in counts as joined once.
+func (l *ledger) recordUpdate(update *ModelUpdateMetrics) {
+	account := l.account(update.FederationID, update.CollaboratorID)
+	if account.Updates == 0 || update.RoundNumber != account.LastRound {
+		account.RoundsJoined++
+	}
+	if account.Updates == 0 || update.RoundNumber < account.FirstRound {
+		account.FirstRound = update.RoundNumber
+	}
+	account.LastRound = update.RoundNumber
+	account.Updates++
+	account.SamplesContributed += update.NumSamples
+	if update.QualityScore != nil {
+		account.QualityReports++
+		account.QualityScore += (*update.QualityScore - account.QualityScore) / float64(account.QualityReports)
+	}
+	account.UpdatedAt = time.Now()
+	l.price(account)
+}
+
+// recordContribution credits a collaborator for its contribution to an
+// aggregation
+func (l *ledger) recordContribution(score *ContributionScore) {
+	account := l.account(score.FederationID, score.CollaboratorID)
+	account.ContributionShare += score.Share
+	account.UpdatedAt = time.Now()
+	l.price(account)
+}
+
+// price sets the credits of an account from its counters
+func (l *ledger) price(account *LedgerAccount) {
+	account.Credits = l.config.RoundCredit*float64(account.RoundsJoined) +
+		l.config.SampleCredit*float64(account.SamplesContributed)/1000 +
+		l.config.ContributionCredit*account.ContributionShare
+}
+
+// load reads the persisted ledger. A missing file is an empty ledger.
+func (l *ledger) load() error {
+	if l.config.Path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(l.config.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid ledger %s: %v", l.config.Path, err)
+	}
+	for _, account := range file.Accounts {
+		l.accounts[account.FederationID+"/"+account.CollaboratorID] = account
+		l.price(account)
+	}
+	return nil
+}
+
+// save persists the ledger, replacing the previous file in one step so that
+// a crash never leaves a partial ledger
+func (l *ledger) save() error {
+	if l.config.Path == "" {
+		return nil
+	}
+	file := ledgerFile{Accounts: l.list("", "")}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.config.Path), 0750); err != nil {
+		return err
+	}
+	tmp := l.config.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.config.Path)
+}
+
+// persist saves the ledger, logging failures
+func (l *ledger) persist() {
+	if err := l.save(); err != nil {
+		log.Printf("Warning: failed to persist ledger: %v", err)
+	}
+}
+
+// list returns copies of the accounts of a federation, or of all
+// federations, most credits first
+func (l *ledger) list(federationID, collaboratorID string) []*LedgerAccount {
+	results := make([]*LedgerAccount, 0)
+	for _, account := range l.accounts {
+		if federationID != "" && account.FederationID != federationID {
+			continue
+		}
+		if collaboratorID != "" && account.CollaboratorID != collaboratorID {
+			continue
+		}
+		result := *account
+		results = append(results, &result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Credits != results[j].Credits {
+			return results[i].Credits > results[j].Credits
+		}
+		if results[i].FederationID != results[j].FederationID {
+			return results[i].FederationID < results[j].FederationID
+		}
+		return results[i].CollaboratorID < results[j].CollaboratorID
+	})
+	return results
+}
+
+// LoadLedger reads the ledger persisted at the configured ledger path
+func (m *MemoryStorage) LoadLedger() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ledger.load()
+}
+
+// GetLedger returns the ledger accounts matching the filter's federation and
+// collaborator, most credits first
+func (m *MemoryStorage) GetLedger(ctx context.Context, filter *MetricsFilter) ([]*LedgerAccount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if filter == nil {
+		return m.ledger.list("", ""), nil
+	}
+	results := make([]*LedgerAccount, 0)
+	for _, account := range m.ledger.list(filter.FederationID, filter.CollaboratorID) {
+		if filter.matchesFederation(account.FederationID) {
+			results = append(results, account)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestLedgerCredits(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	config := &MonitoringConfig{Ledger: LedgerConfig{Path: path, RoundCredit: 1, SampleCredit: 0.5, ContributionCredit: 10}}
+	storage := NewMemoryStorage(config)
+
+	quality := func(q float64) *float64 { return &q }
+	updates := []*ModelUpdateMetrics{
+		{FederationID: "fed-1", CollaboratorID: "a", RoundNumber: 1, NumSamples: 1000, QualityScore: quality(0.8)},
+		{FederationID: "fed-1", CollaboratorID: "a", RoundNumber: 1, NumSamples: 1000},
+		{FederationID: "fed-1", CollaboratorID: "a", RoundNumber: 2, NumSamples: 2000, QualityScore: quality(0.6)},
+		{FederationID: "fed-1", CollaboratorID: "b", RoundNumber: 2, NumSamples: 500},
+	}
+	for _, update := range updates {
+		if err := storage.RecordModelUpdate(ctx, update); err != nil {
+			t.Fatalf("RecordModelUpdate() error = %v", err)
+		}
+	}
+	scores := []*ContributionScore{
+		{FederationID: "fed-1", CollaboratorID: "a", RoundNumber: 2, Share: 0.75},
+		{FederationID: "fed-1", CollaboratorID: "b", RoundNumber: 2, Share: 0.25},
+	}
+	if err := storage.RecordContributions(ctx, scores); err != nil {
+		t.Fatalf("RecordContributions() error = %v", err)
+	}
+
+	// a: 2 rounds + 4000 samples * 0.5/1000 + 0.75 * 10 = 11.5
+	// b: 1 round + 500 samples * 0.5/1000 + 0.25 * 10 = 3.75
+	want := map[string]LedgerAccount{
+		"a": {RoundsJoined: 2, Updates: 3, SamplesContributed: 4000, QualityScore: 0.7, QualityReports: 2, ContributionShare: 0.75, Credits: 11.5, FirstRound: 1, LastRound: 2},
+		"b": {RoundsJoined: 1, Updates: 1, SamplesContributed: 500, ContributionShare: 0.25, Credits: 3.75, FirstRound: 2, LastRound: 2},
+	}
+
+	// A new storage reading the persisted ledger sees the same accounts
+	restored := NewMemoryStorage(config)
+	if err := restored.LoadLedger(); err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+
+	for name, s := range map[string]*MemoryStorage{"recorded": storage, "restored": restored} {
+		t.Run(name, func(t *testing.T) {
+			accounts, err := s.GetLedger(ctx, &MetricsFilter{FederationID: "fed-1"})
+			if err != nil {
+				t.Fatalf("GetLedger() error = %v", err)
+			}
+			if len(accounts) != 2 || accounts[0].CollaboratorID != "a" {
+				t.Fatalf("GetLedger() = %+v, want a then b", accounts)
+			}
+			for _, got := range accounts {
+				w := want[got.CollaboratorID]
+				if got.RoundsJoined != w.RoundsJoined || got.Updates != w.Updates || got.SamplesContributed != w.SamplesContributed ||
+					got.QualityReports != w.QualityReports || got.FirstRound != w.FirstRound || got.LastRound != w.LastRound {
+					t.Errorf("account %s = %+v, want %+v", got.CollaboratorID, got, w)
+				}
+				if math.Abs(got.QualityScore-w.QualityScore) > 1e-9 || math.Abs(got.ContributionShare-w.ContributionShare) > 1e-9 ||
+					math.Abs(got.Credits-w.Credits) > 1e-9 {
+					t.Errorf("account %s scores = %g, %g, %g, want %g, %g, %g", got.CollaboratorID,
+						got.QualityScore, got.ContributionShare, got.Credits, w.QualityScore, w.ContributionShare, w.Credits)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgerDefaultsToRoundCredit(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	ctx := context.Background()
+	for round := 1; round <= 3; round++ {
+		storage.RecordModelUpdate(ctx, &ModelUpdateMetrics{FederationID: "fed-1", CollaboratorID: "a", RoundNumber: round, NumSamples: 100})
+	}
+
+	accounts, _ := storage.GetLedger(ctx, nil)
+	if len(accounts) != 1 || accounts[0].Credits != 3*DefaultRoundCredit {
+		t.Errorf("GetLedger() = %+v, want one account with %g credits", accounts, 3*DefaultRoundCredit)
+	}
+}
@@ -0,0 +1,266 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MLflow limits the entries of a log-batch request and the length of param
+// values
+const (
+	mlflowMaxBatchParams  = 100
+	mlflowMaxBatchMetrics = 1000
+	mlflowMaxParamLength  = 500
+)
+
+// MLflow run statuses accepted by EndRun
+const (
+	MLflowRunFinished = "FINISHED"
+	MLflowRunFailed   = "FAILED"
+	MLflowRunKilled   = "KILLED"
+)
+
+// MLflowClient talks to the REST API of an MLflow tracking server
+type MLflowClient struct {
+	baseURL  string
+	token    string
+	username string
+	password string
+	client   *http.Client
+}
+
+// MLflowMetric is one value of a run metric
+type MLflowMetric struct {
+	Key       string  `json:"key"`
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"` // milliseconds since the epoch
+	Step      int     `json:"step"`
+}
+
+// MLflowRun is a run created on a tracking server
+type MLflowRun struct {
+	client      *MLflowClient
+	ID          string
+	ArtifactURI string
+}
+
+// NewMLflowClient creates a client for the tracking server at trackingURI.
+// Requests authenticate with token when it is set, and otherwise with
+// MLFLOW_TRACKING_USERNAME and MLFLOW_TRACKING_PASSWORD when those are set.
+func NewMLflowClient(trackingURI, token string) (*MLflowClient, error) {
+	u, err := url.Parse(trackingURI)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("MLflow tracking URI must be an http(s) URL of a tracking server, got %q", trackingURI)
+	}
+	return &MLflowClient{
+		baseURL:  strings.TrimRight(trackingURI, "/"),
+		token:    token,
+		username: os.Getenv("MLFLOW_TRACKING_USERNAME"),
+		password: os.Getenv("MLFLOW_TRACKING_PASSWORD"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// StartRun creates a run named runName in the named experiment, creating the
+// experiment when it does not exist
+func (c *MLflowClient) StartRun(experiment, runName string, tags map[string]string) (*MLflowRun, error) {
+	experimentID, err := c.experimentID(experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	type tag struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	request := struct {
+		ExperimentID string `json:"experiment_id"`
+		RunName      string `json:"run_name"`
+		StartTime    int64  `json:"start_time"`
+		Tags         []tag  `json:"tags"`
+	}{ExperimentID: experimentID, RunName: runName, StartTime: time.Now().UnixMilli()}
+	for key, value := range tags {
+		request.Tags = append(request.Tags, tag{Key: key, Value: value})
+	}
+
+	var response struct {
+		Run struct {
+			Info struct {
+				RunID       string `json:"run_id"`
+				ArtifactURI string `json:"artifact_uri"`
+			} `json:"info"`
+		} `json:"run"`
+	}
+	if err := c.call(http.MethodPost, "runs/create", request, &response); err != nil {
+		return nil, fmt.Errorf("failed to create MLflow run: %w", err)
+	}
+	return &MLflowRun{client: c, ID: response.Run.Info.RunID, ArtifactURI: response.Run.Info.ArtifactURI}, nil
+}
+
+func (c *MLflowClient) experimentID(name string) (string, error) {
+	var found struct {
+		Experiment struct {
+			ExperimentID string `json:"experiment_id"`
+		} `json:"experiment"`
+	}
+	err := c.call(http.MethodGet, "experiments/get-by-name?experiment_name="+url.QueryEscape(name), nil, &found)
+	if err == nil {
+		return found.Experiment.ExperimentID, nil
+	}
+	if !strings.Contains(err.Error(), "RESOURCE_DOES_NOT_EXIST") {
+		return "", fmt.Errorf("failed to look up MLflow experiment %s: %w", name, err)
+	}
+
+	var created struct {
+		ExperimentID string `json:"experiment_id"`
+	}
+	if err := c.call(http.MethodPost, "experiments/create", map[string]string{"name": name}, &created); err != nil {
+		return "", fmt.Errorf("failed to create MLflow experiment %s: %w", name, err)
+	}
+	return created.ExperimentID, nil
+}
+
+// LogParams logs params, truncating values longer than MLflow accepts
+func (r *MLflowRun) LogParams(params map[string]string) error {
+	type param struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	batch := make([]param, 0, len(params))
+	for key, value := range params {
+		if len(value) > mlflowMaxParamLength {
+			value = value[:mlflowMaxParamLength]
+		}
+		batch = append(batch, param{Key: key, Value: value})
+	}
+	for start := 0; start < len(batch); start += mlflowMaxBatchParams {
+		end := min(start+mlflowMaxBatchParams, len(batch))
+		request := map[string]interface{}{"run_id": r.ID, "params": batch[start:end]}
+		if err := r.client.call(http.MethodPost, "runs/log-batch", request, nil); err != nil {
+			return fmt.Errorf("failed to log MLflow params: %w", err)
+		}
+	}
+	return nil
+}
+
+// LogMetrics logs metric values
+func (r *MLflowRun) LogMetrics(metrics []MLflowMetric) error {
+	for start := 0; start < len(metrics); start += mlflowMaxBatchMetrics {
+		end := min(start+mlflowMaxBatchMetrics, len(metrics))
+		request := map[string]interface{}{"run_id": r.ID, "metrics": metrics[start:end]}
+		if err := r.client.call(http.MethodPost, "runs/log-batch", request, nil); err != nil {
+			return fmt.Errorf("failed to log MLflow metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// LogArtifact stores data as the artifact at artifactPath, a slash-separated
+// path relative to the run's artifact root. Runs whose artifacts are proxied
+// by the tracking server (mlflow-artifacts:) and runs with a local artifact
+// root are supported.
+func (r *MLflowRun) LogArtifact(artifactPath string, data []byte) error {
+	root, err := url.Parse(r.ArtifactURI)
+	if err != nil {
+		return fmt.Errorf("invalid MLflow artifact URI %q: %w", r.ArtifactURI, err)
+	}
+
+	switch root.Scheme {
+	case "mlflow-artifacts":
+		target := r.client.baseURL + "/api/2.0/mlflow-artifacts/artifacts/" +
+			strings.TrimPrefix(path.Join(root.Path, artifactPath), "/")
+		req, err := http.NewRequest(http.MethodPut, target, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		if err := r.client.do(req, nil); err != nil {
+			return fmt.Errorf("failed to upload MLflow artifact %s: %w", artifactPath, err)
+		}
+		return nil
+	case "", "file":
+		target := filepath.Join(filepath.FromSlash(root.Path), filepath.FromSlash(artifactPath))
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return fmt.Errorf("failed to create MLflow artifact directory: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0600); err != nil {
+			return fmt.Errorf("failed to write MLflow artifact %s: %w", artifactPath, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported MLflow artifact store %s; serve artifacts through the tracking server", root.Scheme)
+	}
+}
+
+// End marks the run as ended with status
+func (r *MLflowRun) End(status string) error {
+	request := map[string]interface{}{"run_id": r.ID, "status": status, "end_time": time.Now().UnixMilli()}
+	if err := r.client.call(http.MethodPost, "runs/update", request, nil); err != nil {
+		return fmt.Errorf("failed to end MLflow run: %w", err)
+	}
+	return nil
+}
+
+// call sends a request to the MLflow REST API and decodes the response into
+// response when it is not nil
+func (c *MLflowClient) call(method, endpoint string, request, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+"/api/2.0/mlflow/"+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, response)
+}
+
+func (c *MLflowClient) do(req *http.Request, response interface{}) error {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Errors carry an error_code such as RESOURCE_DOES_NOT_EXIST
+		var apiErr struct {
+			ErrorCode string `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.ErrorCode != "" {
+			return fmt.Errorf("MLflow returned %d %s: %s", resp.StatusCode, apiErr.ErrorCode, apiErr.Message)
+		}
+		return fmt.Errorf("MLflow returned %d", resp.StatusCode)
+	}
+	if response != nil && len(data) > 0 {
+		return json.Unmarshal(data, response)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMLflow records the requests of an MLflow tracking server that serves
+// artifacts itself
+type fakeMLflow struct {
+	mu          sync.Mutex
+	experiments map[string]string
+	requests    map[string][]map[string]interface{}
+	artifacts   map[string]string
+}
+
+func newFakeMLflow(t *testing.T) (*fakeMLflow, *httptest.Server) {
+	f := &fakeMLflow{
+		experiments: make(map[string]string),
+		requests:    make(map[string][]map[string]interface{}),
+		artifacts:   make(map[string]string),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if artifact, ok := strings.CutPrefix(r.URL.Path, "/api/2.0/mlflow-artifacts/artifacts/"); ok {
+			f.artifacts[artifact] = string(body)
+			w.Write([]byte("{}"))
+			return
+		}
+		endpoint := strings.TrimPrefix(r.URL.Path, "/api/2.0/mlflow/")
+		var request map[string]interface{}
+		json.Unmarshal(body, &request)
+		f.requests[endpoint] = append(f.requests[endpoint], request)
+
+		switch endpoint {
+		case "experiments/get-by-name":
+			id, ok := f.experiments[r.URL.Query().Get("experiment_name")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error_code": "RESOURCE_DOES_NOT_EXIST", "message": "no such experiment"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"experiment": map[string]string{"experiment_id": id}})
+		case "experiments/create":
+			f.experiments[request["name"].(string)] = "7"
+			w.Write([]byte(`{"experiment_id": "7"}`))
+		case "runs/create":
+			w.Write([]byte(`{"run": {"info": {"run_id": "r1", "artifact_uri": "mlflow-artifacts:/7/r1/artifacts"}}}`))
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func TestMLflowRun(t *testing.T) {
+	fake, server := newFakeMLflow(t)
+	client, err := NewMLflowClient(server.URL, "secret")
+	if err != nil {
+		t.Fatalf("NewMLflowClient() error = %v", err)
+	}
+
+	run, err := client.StartRun("fl", "fed-1", map[string]string{"mode": "sync"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	if run.ID != "r1" {
+		t.Errorf("run ID = %s, want r1", run.ID)
+	}
+	// The second run reuses the experiment
+	if _, err := client.StartRun("fl", "fed-2", nil); err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	if n := len(fake.requests["experiments/create"]); n != 1 {
+		t.Errorf("created %d experiments, want 1", n)
+	}
+
+	params := make(map[string]string)
+	for i := 0; i < 150; i++ {
+		params[strings.Repeat("k", i+1)] = strings.Repeat("v", 600)
+	}
+	if err := run.LogParams(params); err != nil {
+		t.Fatalf("LogParams() error = %v", err)
+	}
+	if err := run.LogMetrics([]MLflowMetric{{Key: "train/loss", Value: 0.5, Step: 1}}); err != nil {
+		t.Fatalf("LogMetrics() error = %v", err)
+	}
+	if err := run.LogArtifact("model/final_model.pt", []byte("weights")); err != nil {
+		t.Fatalf("LogArtifact() error = %v", err)
+	}
+	if err := run.End(MLflowRunFinished); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+
+	batches := fake.requests["runs/log-batch"]
+	if len(batches) != 3 {
+		t.Fatalf("sent %d batches, want 2 of params and 1 of metrics", len(batches))
+	}
+	for _, p := range batches[0]["params"].([]interface{}) {
+		if value := p.(map[string]interface{})["value"].(string); len(value) > mlflowMaxParamLength {
+			t.Errorf("param value of %d bytes was not truncated", len(value))
+		}
+	}
+	if got := fake.artifacts["7/r1/artifacts/model/final_model.pt"]; got != "weights" {
+		t.Errorf("artifact = %q, want weights", got)
+	}
+	if status := fake.requests["runs/update"][0]["status"]; status != MLflowRunFinished {
+		t.Errorf("run status = %v, want %s", status, MLflowRunFinished)
+	}
+}
+
+func TestNewMLflowClientRejectsFileStores(t *testing.T) {
+	if _, err := NewMLflowClient("./mlruns", ""); err == nil {
+		t.Error("expected an error for a local tracking URI")
+	}
+}
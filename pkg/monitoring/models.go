@@ -0,0 +1,146 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+)
+
+// PromoteModelRequest is the body of a model promotion
+type PromoteModelRequest struct {
+	Stage string `json:"stage"` // staging, production or archived
+}
+
+// ModelLineage is a model version with its ancestors, newest first
+type ModelLineage struct {
+	Model   *registry.ModelVersion   `json:"model"`
+	Lineage []*registry.ModelVersion `json:"lineage"`
+}
+
+// handleListModels lists registered model versions, optionally of one model
+func (s *APIServer) handleListModels(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRegistry(w, r) {
+		return
+	}
+
+	versions, err := s.models.List(r.URL.Query().Get("name"))
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list models", err)
+		return
+	}
+	if versions == nil {
+		versions = []*registry.ModelVersion{}
+	}
+	s.sendSuccess(w, versions)
+}
+
+// handleGetModel returns the metadata of a version. The version may be a
+// number, "latest" or a stage such as "production".
+func (s *APIServer) handleGetModel(w http.ResponseWriter, r *http.Request) {
+	version, ok := s.resolveModel(w, r)
+	if !ok {
+		return
+	}
+	s.sendSuccess(w, version)
+}
+
+// handleGetModelLineage returns a version with the versions it was aggregated from
+func (s *APIServer) handleGetModelLineage(w http.ResponseWriter, r *http.Request) {
+	version, ok := s.resolveModel(w, r)
+	if !ok {
+		return
+	}
+
+	lineage, err := s.models.Lineage(version)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get lineage", err)
+		return
+	}
+	s.sendSuccess(w, ModelLineage{Model: version, Lineage: lineage})
+}
+
+// handleDownloadModel serves the model file of a version
+func (s *APIServer) handleDownloadModel(w http.ResponseWriter, r *http.Request) {
+	version, ok := s.resolveModel(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := s.models.Download(version)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to read model", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s_v%d.pt", version.Name, version.Version)))
+	w.Header().Set("X-Model-Checksum", version.Checksum)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// handlePromoteModel moves a version to another stage
+func (s *APIServer) handlePromoteModel(w http.ResponseWriter, r *http.Request) {
+	version, ok := s.resolveModel(w, r)
+	if !ok {
+		return
+	}
+	setAuditResource(w, fmt.Sprintf("%s/v%d", version.Name, version.Version))
+
+	var req PromoteModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	stage, err := registry.ParseStage(req.Stage)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid stage", err)
+		return
+	}
+
+	promoted, err := s.models.Promote(version.Name, version.Version, stage)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to promote model", err)
+		return
+	}
+	s.sendSuccess(w, promoted)
+}
+
+// resolveModel looks up the version named by the route, writing the error
+// response if there is none
+func (s *APIServer) resolveModel(w http.ResponseWriter, r *http.Request) (*registry.ModelVersion, bool) {
+	if !s.requireRegistry(w, r) {
+		return nil, false
+	}
+
+	vars := mux.Vars(r)
+	version, err := s.models.Get(vars["name"], vars["version"])
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		s.sendError(w, http.StatusNotFound, "Model not found", err)
+		return nil, false
+	case err != nil:
+		s.sendError(w, http.StatusBadRequest, "Invalid model version", err)
+		return nil, false
+	}
+	return version, true
+}
+
+// requireRegistry writes the error response unless the registry is enabled
+// and the caller is server-wide. Model versions are not recorded per
+// organization, so callers with an organization cannot use the registry.
+func (s *APIServer) requireRegistry(w http.ResponseWriter, r *http.Request) bool {
+	if s.models == nil {
+		s.sendError(w, http.StatusNotFound, "Model registry is not enabled", nil)
+		return false
+	}
+	if orgFromContext(r.Context()) != "" {
+		s.sendError(w, http.StatusForbidden, "The model registry is only available to server-wide callers", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+)
+
+func TestModelRegistryEndpoints(t *testing.T) {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeyAuth: APIKeyConfig{
+				Enabled: true,
+				Keys:    map[string]string{"admin-key": RoleAdmin, "monitor-key": RoleMonitor, "lab-a-key": RoleAdmin},
+				Orgs:    map[string]string{"lab-a-key": "lab-a"},
+			},
+		},
+		Registry: registry.Config{Enabled: true, Dir: t.TempDir()},
+	}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	models, _ := registry.Open(config.Registry)
+	first, _ := models.Register("fed-1", []byte{1, 2, 3, 4}, registry.RegisterOptions{Round: 1})
+	models.Register("fed-1", []byte{5, 6, 7, 8}, registry.RegisterOptions{Parent: first.Version, Round: 2})
+
+	var versions []*registry.ModelVersion
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/models?name=fed-1", "monitor-key", "", &versions); code != http.StatusOK || len(versions) != 2 {
+		t.Fatalf("GET /models = %d with %d versions, want 200 with 2", code, len(versions))
+	}
+
+	var lineage ModelLineage
+	doAPIKeyRequest(t, server, "GET", "/api/v1/models/fed-1/versions/latest/lineage", "monitor-key", "", &lineage)
+	if lineage.Model == nil || lineage.Model.Version != 2 || len(lineage.Lineage) != 2 {
+		t.Errorf("Unexpected lineage: %+v", lineage)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		apiKey string
+		body   string
+		status int
+	}{
+		{"promote requires admin", "POST", "/api/v1/models/fed-1/versions/1/promote", "monitor-key", `{"stage": "production"}`, http.StatusForbidden},
+		{"invalid stage", "POST", "/api/v1/models/fed-1/versions/1/promote", "admin-key", `{"stage": "live"}`, http.StatusBadRequest},
+		{"promote", "POST", "/api/v1/models/fed-1/versions/1/promote", "admin-key", `{"stage": "production"}`, http.StatusOK},
+		{"get by stage", "GET", "/api/v1/models/fed-1/versions/production", "monitor-key", "", http.StatusOK},
+		{"missing version", "GET", "/api/v1/models/fed-1/versions/9", "monitor-key", "", http.StatusNotFound},
+		{"missing model", "GET", "/api/v1/models/fed-2/versions/latest", "monitor-key", "", http.StatusNotFound},
+		{"organizations cannot list", "GET", "/api/v1/models", "lab-a-key", "", http.StatusForbidden},
+		{"organizations cannot download", "GET", "/api/v1/models/fed-1/versions/latest/download", "lab-a-key", "", http.StatusForbidden},
+		{"organizations cannot promote", "POST", "/api/v1/models/fed-1/versions/1/promote", "lab-a-key", `{"stage": "archived"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := doAPIKeyRequest(t, server, tt.method, tt.path, tt.apiKey, tt.body, nil); code != tt.status {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, code, tt.status)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/models/fed-1/versions/production/download", nil)
+	req.Header.Set("X-API-Key", "monitor-key")
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.Len() != 4 || rr.Body.Bytes()[0] != 1 {
+		t.Errorf("Download of the production version = %d %v, want v1", rr.Code, rr.Body.Bytes())
+	}
+	if rr.Header().Get("X-Model-Checksum") != first.Checksum {
+		t.Error("Expected the model checksum header")
+	}
+}
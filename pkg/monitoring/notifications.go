@@ -0,0 +1,423 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Notification delivery modes
+const (
+	DeliveryInstant = "instant" // one webhook per event
+	DeliveryDigest  = "digest"  // the events of each digest interval in one webhook
+)
+
+// maxDigestEvents limits the events kept for a single digest; further
+// events are only counted
+const maxDigestEvents = 1000
+
+// minDigestInterval is the shortest digest interval, which is also how often
+// pending digests are checked
+const minDigestInterval = time.Minute
+
+// NotificationSubscription sends the events of some federations to a webhook
+// or to email addresses
+type NotificationSubscription struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	OrgID          string    `json:"org_id,omitempty"`
+	FederationIDs  []string  `json:"federation_ids,omitempty"` // empty: every federation visible to the organization
+	Levels         []string  `json:"levels,omitempty"`         // event levels sent (default: warning and error)
+	Delivery       string    `json:"delivery"`                 // instant (default) or digest
+	WebhookURL     string    `json:"webhook_url,omitempty"`
+	Email          []string  `json:"email,omitempty"`           // addresses mailed instead of a webhook; requires notifications.smtp
+	DigestInterval string    `json:"digest_interval,omitempty"` // Go duration between digests (default: 1h)
+	CreatedBy      string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NotificationStore persists notification subscriptions
+type NotificationStore interface {
+	CreateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error
+	GetNotificationSubscription(ctx context.Context, subscriptionID string) (*NotificationSubscription, error)
+	ListNotificationSubscriptions(ctx context.Context) ([]*NotificationSubscription, error)
+	UpdateNotificationSubscription(ctx context.Context, subscriptionID string, subscription *NotificationSubscription) error
+	DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error
+}
+
+// NotificationPayload is the body of a notification webhook
+type NotificationPayload struct {
+	SubscriptionID string             `json:"subscription_id"`
+	Name           string             `json:"name"`
+	Delivery       string             `json:"delivery"`
+	SentAt         time.Time          `json:"sent_at"`
+	Events         []*MonitoringEvent `json:"events"`
+	Dropped        int                `json:"dropped,omitempty"` // events of a digest beyond its limit
+}
+
+// NotificationConfig configures the delivery of notifications
+type NotificationConfig struct {
+	Disabled bool          `yaml:"disabled" json:"disabled"` // e.g. on all replicas but one
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"`   // webhook request and mail delivery timeout (default: 10s)
+	SMTP     SMTPConfig    `yaml:"smtp" json:"-"`            // mail server of email subscriptions
+}
+
+// SMTPConfig configures the mail server that sends email notifications
+type SMTPConfig struct {
+	Address  string `yaml:"address"`  // host:port of the mail server; email subscriptions are refused without it
+	From     string `yaml:"from"`     // sender address
+	Username string `yaml:"username"` // PLAIN authentication, only over TLS; empty for none
+	Password string `yaml:"password"`
+	// Connect over TLS (usually port 465) instead of upgrading the
+	// connection with STARTTLS, which is used whenever the server offers it
+	ImplicitTLS bool `yaml:"implicit_tls"`
+}
+
+// Validate checks a subscription and fills in its defaults
+func (s *NotificationSubscription) Validate() error {
+	switch {
+	case s.WebhookURL != "" && len(s.Email) > 0:
+		return fmt.Errorf("set either webhook_url or email, not both")
+	case len(s.Email) > 0:
+		for _, address := range s.Email {
+			if parsed, err := mail.ParseAddress(address); err != nil || parsed.Name != "" {
+				return fmt.Errorf("invalid email address: %q", address)
+			}
+		}
+	default:
+		target, err := url.Parse(s.WebhookURL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("webhook_url must be an http or https URL")
+		}
+	}
+
+	if len(s.Levels) == 0 {
+		s.Levels = []string{"warning", "error"}
+	}
+	for _, level := range s.Levels {
+		switch level {
+		case "info", "warning", "error":
+		default:
+			return fmt.Errorf("invalid level: %q", level)
+		}
+	}
+
+	switch s.Delivery {
+	case "":
+		s.Delivery = DeliveryInstant
+	case DeliveryInstant:
+	case DeliveryDigest:
+		if s.DigestInterval == "" {
+			s.DigestInterval = "1h"
+		}
+	default:
+		return fmt.Errorf("invalid delivery: %q", s.Delivery)
+	}
+
+	if s.DigestInterval != "" {
+		interval, err := time.ParseDuration(s.DigestInterval)
+		if err != nil {
+			return fmt.Errorf("invalid digest_interval: %w", err)
+		}
+		if interval < minDigestInterval {
+			return fmt.Errorf("digest_interval must be at least %s", minDigestInterval)
+		}
+	}
+
+	return nil
+}
+
+// digestInterval returns the time between the digests of a subscription
+func (s *NotificationSubscription) digestInterval() time.Duration {
+	if interval, err := time.ParseDuration(s.DigestInterval); err == nil {
+		return interval
+	}
+	return time.Hour
+}
+
+// matches reports whether an event of a federation owned by org is sent to
+// the subscription
+func (s *NotificationSubscription) matches(event *MonitoringEvent, org string) bool {
+	if s.OrgID != "" && s.OrgID != org {
+		return false
+	}
+	if len(s.FederationIDs) > 0 && !slices.Contains(s.FederationIDs, event.FederationID) {
+		return false
+	}
+	return slices.Contains(s.Levels, event.Level)
+}
+
+// Notifier delivers the events recorded by a MonitoringService to the
+// webhooks and email addresses of the notification subscriptions in its
+// store
+type Notifier struct {
+	service MonitoringService
+	client  *http.Client
+	smtp    SMTPConfig
+	timeout time.Duration
+	digests map[string]*pendingDigest // key: subscription ID; only used by Run
+}
+
+// pendingDigest holds the events of a digest that has not been sent yet
+type pendingDigest struct {
+	since   time.Time
+	events  []*MonitoringEvent
+	dropped int
+}
+
+// NewNotifier creates a notifier for the events of service. Pass the
+// storage before wrapping it with RelayEvents, so that each event is
+// delivered by the replica it was reported to.
+func NewNotifier(service MonitoringService, config NotificationConfig) *Notifier {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Notifier{
+		service: service,
+		client:  &http.Client{Timeout: timeout},
+		smtp:    config.SMTP,
+		timeout: timeout,
+		digests: make(map[string]*pendingDigest),
+	}
+}
+
+// Run delivers notifications until ctx is done
+func (n *Notifier) Run(ctx context.Context) error {
+	events, err := n.service.SubscribeToEvents(ctx, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	ticker := time.NewTicker(minDigestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			n.handleEvent(ctx, event)
+		case now := <-ticker.C:
+			n.sendDigests(ctx, now)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handleEvent sends an event to the instant subscriptions it matches and
+// adds it to the pending digests of the others
+func (n *Notifier) handleEvent(ctx context.Context, event *MonitoringEvent) {
+	subscriptions, err := n.service.ListNotificationSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to list notification subscriptions: %v", err)
+		return
+	}
+
+	org, orgKnown := "", false
+	for _, subscription := range subscriptions {
+		if subscription.OrgID != "" && !orgKnown {
+			if federation, err := n.service.GetFederation(ctx, event.FederationID); err == nil {
+				org = federation.OrgID
+			}
+			orgKnown = true
+		}
+		if !subscription.matches(event, org) {
+			continue
+		}
+
+		if subscription.Delivery != DeliveryDigest {
+			go n.deliver(ctx, subscription, []*MonitoringEvent{event}, 0)
+			continue
+		}
+
+		digest, exists := n.digests[subscription.ID]
+		if !exists {
+			digest = &pendingDigest{since: time.Now()}
+			n.digests[subscription.ID] = digest
+		}
+		if len(digest.events) < maxDigestEvents {
+			digest.events = append(digest.events, event)
+		} else {
+			digest.dropped++
+		}
+	}
+}
+
+// sendDigests sends the pending digests whose interval has passed. Digests
+// of deleted subscriptions are discarded.
+func (n *Notifier) sendDigests(ctx context.Context, now time.Time) {
+	subscriptions, err := n.service.ListNotificationSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to list notification subscriptions: %v", err)
+		return
+	}
+	byID := make(map[string]*NotificationSubscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byID[subscription.ID] = subscription
+	}
+
+	for id, digest := range n.digests {
+		subscription, exists := byID[id]
+		if !exists {
+			delete(n.digests, id)
+			continue
+		}
+		if now.Sub(digest.since) < subscription.digestInterval() {
+			continue
+		}
+		delete(n.digests, id)
+		go n.deliver(ctx, subscription, digest.events, digest.dropped)
+	}
+}
+
+// deliver sends events to the webhook or email addresses of a subscription
+func (n *Notifier) deliver(ctx context.Context, subscription *NotificationSubscription, events []*MonitoringEvent, dropped int) {
+	payload := &NotificationPayload{
+		SubscriptionID: subscription.ID,
+		Name:           subscription.Name,
+		Delivery:       subscription.Delivery,
+		SentAt:         time.Now().UTC(),
+		Events:         events,
+		Dropped:        dropped,
+	}
+	var err error
+	if len(subscription.Email) > 0 {
+		err = n.sendEmail(subscription.Email, payload)
+	} else {
+		err = n.postWebhook(ctx, subscription.WebhookURL, payload)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to notify subscription %s: %v", subscription.ID, err)
+	}
+}
+
+// postWebhook posts a notification to a webhook as JSON
+func (n *Notifier) postWebhook(ctx context.Context, webhookURL string, payload *NotificationPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendEmail mails a notification as plain text through the configured mail
+// server
+func (n *Notifier) sendEmail(to []string, payload *NotificationPayload) error {
+	if n.smtp.Address == "" || n.smtp.From == "" {
+		return fmt.Errorf("notifications.smtp.address and notifications.smtp.from must be set to send email")
+	}
+	host, _, err := net.SplitHostPort(n.smtp.Address)
+	if err != nil {
+		return fmt.Errorf("invalid notifications.smtp.address: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: n.timeout}
+	var conn net.Conn
+	if n.smtp.ImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", n.smtp.Address, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	} else {
+		conn, err = dialer.Dial("tcp", n.smtp.Address)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(n.timeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !n.smtp.ImplicitTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if n.smtp.Username != "" {
+		// PlainAuth refuses to send the password over a connection without TLS
+		if err := client.Auth(smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.smtp.From); err != nil {
+		return err
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailMessage(n.smtp.From, to, payload)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage formats a notification as a plain text message, one line
+// per event
+func emailMessage(from string, to []string, payload *NotificationPayload) []byte {
+	name := payload.Name
+	if name == "" {
+		name = payload.SubscriptionID
+	}
+	subject := fmt.Sprintf("fl-go %s: %d events", name, len(payload.Events)+payload.Dropped)
+	if payload.Delivery == DeliveryInstant && len(payload.Events) == 1 {
+		event := payload.Events[0]
+		subject = fmt.Sprintf("fl-go %s: %s in %s", name, event.Level, event.FederationID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", payload.SentAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	for _, event := range payload.Events {
+		fmt.Fprintf(&b, "%s  %-7s  %s  %s  %s\r\n", event.Timestamp.UTC().Format(time.RFC3339), event.Level,
+			event.FederationID, event.Type, strings.Join(strings.Fields(event.Message), " "))
+	}
+	if payload.Dropped > 0 {
+		fmt.Fprintf(&b, "\r\n%d more events were left out of this digest.\r\n", payload.Dropped)
+	}
+	fmt.Fprintf(&b, "\r\nSent for notification subscription %s.\r\n", payload.SubscriptionID)
+	return []byte(b.String())
+}
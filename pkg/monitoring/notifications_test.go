@@ -0,0 +1,249 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotificationSubscriptionAPI(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "admin-key", `{"id": "fed-x"}`, nil)
+
+	var subscription NotificationSubscription
+	code := doAPIKeyRequest(t, server, "POST", "/api/v1/notifications", "lab-a-key",
+		`{"name": "errors", "federation_ids": ["fed-a"], "webhook_url": "https://hooks.example.com/a"}`, &subscription)
+	if code != http.StatusOK || subscription.OrgID != "lab-a" || subscription.Delivery != DeliveryInstant || len(subscription.Levels) != 2 {
+		t.Fatalf("create = %d, %+v", code, subscription)
+	}
+
+	code = doAPIKeyRequest(t, server, "PUT", "/api/v1/notifications/"+subscription.ID, "lab-a-key",
+		`{"name": "digest", "delivery": "digest", "webhook_url": "https://hooks.example.com/a"}`, &subscription)
+	if code != http.StatusOK || subscription.DigestInterval != "1h" || subscription.OrgID != "lab-a" {
+		t.Errorf("update = %d, %+v", code, subscription)
+	}
+
+	requests := []struct {
+		method string
+		path   string
+		apiKey string
+		body   string
+		want   int
+	}{
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"webhook_url": "ftp://hooks.example.com"}`, http.StatusBadRequest},
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"webhook_url": "https://hooks.example.com", "levels": ["debug"]}`, http.StatusBadRequest},
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"webhook_url": "https://hooks.example.com", "delivery": "digest", "digest_interval": "10s"}`, http.StatusBadRequest},
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"webhook_url": "https://hooks.example.com", "federation_ids": ["fed-x"]}`, http.StatusBadRequest},
+		{"POST", "/api/v1/notifications", "lab-b-key", `{"webhook_url": "https://hooks.example.com"}`, http.StatusForbidden},
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"email": ["oncall@example.com"]}`, http.StatusBadRequest}, // no mail server
+		{"POST", "/api/v1/notifications", "lab-a-key", `{"email": ["oncall@example.com"], "webhook_url": "https://hooks.example.com"}`, http.StatusBadRequest},
+		{"GET", "/api/v1/notifications/" + subscription.ID, "admin-key", "", http.StatusOK},
+		{"DELETE", "/api/v1/notifications/missing", "lab-a-key", "", http.StatusNotFound},
+		{"DELETE", "/api/v1/notifications/" + subscription.ID, "lab-a-key", "", http.StatusOK},
+		{"GET", "/api/v1/notifications/" + subscription.ID, "lab-a-key", "", http.StatusNotFound},
+	}
+	for _, tt := range requests {
+		if code := doAPIKeyRequest(t, server, tt.method, tt.path, tt.apiKey, tt.body, nil); code != tt.want {
+			t.Errorf("%s %s as %s = %d, want %d", tt.method, tt.path, tt.apiKey, code, tt.want)
+		}
+	}
+}
+
+func TestNotifierDelivery(t *testing.T) {
+	payloads := make(chan NotificationPayload, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload NotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer webhook.Close()
+
+	ctx := context.Background()
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed-a", OrgID: "lab-a"})
+	storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed-b", OrgID: "lab-b"})
+
+	subscriptions := []*NotificationSubscription{
+		{ID: "instant", OrgID: "lab-a", Levels: []string{"error"}, WebhookURL: webhook.URL},
+		{ID: "digest", Delivery: DeliveryDigest, WebhookURL: webhook.URL},
+	}
+	for _, subscription := range subscriptions {
+		if err := subscription.Validate(); err != nil {
+			t.Fatalf("Validate: %v", err)
+		}
+		storage.CreateNotificationSubscription(ctx, subscription)
+	}
+
+	notifier := NewNotifier(storage, NotificationConfig{})
+	events := []*MonitoringEvent{
+		{ID: "1", FederationID: "fed-a", Level: "error"},
+		{ID: "2", FederationID: "fed-b", Level: "error"},   // other organization
+		{ID: "3", FederationID: "fed-a", Level: "warning"}, // digest only
+		{ID: "4", FederationID: "fed-a", Level: "info"},    // neither
+	}
+	for _, event := range events {
+		notifier.handleEvent(ctx, event)
+	}
+
+	select {
+	case payload := <-payloads:
+		if payload.SubscriptionID != "instant" || len(payload.Events) != 1 || payload.Events[0].ID != "1" {
+			t.Errorf("instant payload = %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("instant notification was not delivered")
+	}
+
+	// The digest waits for its interval
+	notifier.sendDigests(ctx, time.Now())
+	notifier.sendDigests(ctx, time.Now().Add(time.Hour))
+	select {
+	case payload := <-payloads:
+		if payload.SubscriptionID != "digest" || len(payload.Events) != 3 {
+			t.Errorf("digest payload = %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("digest was not delivered")
+	}
+
+	select {
+	case payload := <-payloads:
+		t.Errorf("unexpected notification %+v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotificationEmailValidation(t *testing.T) {
+	config := newTenantTestConfig()
+	config.Notifications.SMTP = SMTPConfig{Address: "localhost:25", From: "fl-go@example.com"}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	var subscription NotificationSubscription
+	code := doAPIKeyRequest(t, server, "POST", "/api/v1/notifications", "lab-a-key",
+		`{"name": "errors", "email": ["oncall@example.com"], "delivery": "digest"}`, &subscription)
+	if code != http.StatusOK || len(subscription.Email) != 1 || subscription.WebhookURL != "" {
+		t.Fatalf("create = %d, %+v", code, subscription)
+	}
+	for _, body := range []string{
+		`{"email": ["not an address"]}`,
+		`{"email": ["On Call <oncall@example.com>"]}`,
+		`{"email": ["oncall@example.com\r\nBcc: other@example.com"]}`,
+	} {
+		if code := doAPIKeyRequest(t, server, "POST", "/api/v1/notifications", "lab-a-key", body, nil); code != http.StatusBadRequest {
+			t.Errorf("create %s = %d, want %d", body, code, http.StatusBadRequest)
+		}
+	}
+}
+
+// smtpMessage is a message received by fakeSMTPServer
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer accepts mail without TLS or authentication and sends each
+// message it receives to messages
+func fakeSMTPServer(t *testing.T, messages chan<- smtpMessage) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				text := textproto.NewConn(conn)
+				var message smtpMessage
+				text.PrintfLine("220 localhost ESMTP")
+				for {
+					line, err := text.ReadLine()
+					if err != nil {
+						return
+					}
+					command := strings.ToUpper(line)
+					switch {
+					case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+						text.PrintfLine("250 localhost")
+					case strings.HasPrefix(command, "MAIL FROM:"):
+						message.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+						text.PrintfLine("250 OK")
+					case strings.HasPrefix(command, "RCPT TO:"):
+						message.to = append(message.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+						text.PrintfLine("250 OK")
+					case command == "DATA":
+						text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+						data, err := text.ReadDotBytes()
+						if err != nil {
+							return
+						}
+						message.data = string(data)
+						messages <- message
+						text.PrintfLine("250 OK")
+					case command == "QUIT":
+						text.PrintfLine("221 Bye")
+						return
+					default:
+						text.PrintfLine("250 OK")
+					}
+				}
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestNotifierEmail(t *testing.T) {
+	messages := make(chan smtpMessage, 10)
+	address := fakeSMTPServer(t, messages)
+
+	ctx := context.Background()
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	subscription := &NotificationSubscription{ID: "digest", Name: "On call", Delivery: DeliveryDigest,
+		Email: []string{"a@example.com", "b@example.com"}}
+	if err := subscription.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	storage.CreateNotificationSubscription(ctx, subscription)
+
+	notifier := NewNotifier(storage, NotificationConfig{SMTP: SMTPConfig{Address: address, From: "fl-go@example.com"}})
+	notifier.handleEvent(ctx, &MonitoringEvent{ID: "1", FederationID: "fed-a", Level: "error", Type: MetricTypeRound, Message: "round 3\r\nfailed"})
+	notifier.handleEvent(ctx, &MonitoringEvent{ID: "2", FederationID: "fed-a", Level: "warning", Message: "slow collaborator"})
+	notifier.sendDigests(ctx, time.Now().Add(time.Hour))
+
+	select {
+	case message := <-messages:
+		if message.from != "fl-go@example.com" || len(message.to) != 2 || message.to[1] != "b@example.com" {
+			t.Errorf("message from %s to %v, want fl-go@example.com to both addresses", message.from, message.to)
+		}
+		header, err := textproto.NewReader(bufio.NewReader(strings.NewReader(message.data))).ReadMIMEHeader()
+		if err != nil {
+			t.Fatalf("message header: %v", err)
+		}
+		if subject := header.Get("Subject"); subject != "fl-go On call: 2 events" {
+			t.Errorf("Subject = %q", subject)
+		}
+		if !strings.Contains(message.data, "fed-a  round  round 3 failed") || !strings.Contains(message.data, "slow collaborator") {
+			t.Errorf("message body does not list the events:\n%s", message.data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("digest was not mailed")
+	}
+}
@@ -0,0 +1,463 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ishaileshpant/fl-go/pkg/registry"
+)
+
+// routeDoc describes a route of the monitoring API in the OpenAPI document.
+// Request and Response are sample values whose types give the schemas of the
+// request body and of the data field of the response.
+type routeDoc struct {
+	Summary     string
+	Tag         string
+	Request     interface{}
+	Response    interface{}
+	Query       []queryParam
+	Raw         bool   // the response is not wrapped in APIResponse
+	ContentType string // content type of a response that is not JSON
+	Public      bool   // served without authentication
+}
+
+// queryParam is a query parameter of a route
+type queryParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// metricsFilterParams are the query parameters read by parseMetricsFilter
+var metricsFilterParams = []queryParam{
+	{"federation_id", "string", "Only records of this federation"},
+	{"collaborator_id", "string", "Only records of this collaborator"},
+	{"status", "string", "Only records with this status"},
+	{"metric_type", "string", "Only records of this metric type"},
+	{"round_number", "integer", "Only records of this round"},
+	{"page", "integer", "Page number, starting at 1"},
+	{"per_page", "integer", "Records per page"},
+	{"start_time", "date-time", "Only records at or after this time (RFC 3339)"},
+	{"end_time", "date-time", "Only records at or before this time (RFC 3339)"},
+	{"tag", "string", "Only collaborators with this tag; repeat or separate with commas for several"},
+	{"label", "string", "Only records labeled key=value, or of a federation labeled so; repeat or separate with commas for several"},
+}
+
+func withParams(params ...queryParam) []queryParam {
+	return append(append([]queryParam{}, metricsFilterParams...), params...)
+}
+
+// apiRouteDocs documents every route under /api/v1, keyed by method and
+// path template relative to /api/v1. TestOpenAPICoversRoutes keeps it in
+// sync with setupRoutes.
+var apiRouteDocs = map[string]routeDoc{
+	"GET /health":       {Summary: "Check the health of the monitoring service", Tag: "system", Response: map[string]interface{}{}, Public: true},
+	"GET /stats":        {Summary: "Get record counts of the monitoring storage", Tag: "system", Response: MetricsStats{}},
+	"GET /openapi.json": {Summary: "Get this OpenAPI document", Tag: "system", Response: map[string]interface{}{}, Raw: true, Public: true},
+	"GET /docs":         {Summary: "Browse this API with Swagger UI", Tag: "system", ContentType: "text/html", Public: true},
+	"POST /ingest":      {Summary: "Record a batch of metrics of different kinds", Tag: "ingest", Request: IngestBatch{}, Response: IngestResult{}},
+	"POST /import":      {Summary: "Backfill the historical records of a federation", Tag: "ingest", Request: ImportArchive{}, Response: ImportResult{}},
+	"POST /auth/token":  {Summary: "Issue a JWT for the caller", Tag: "auth", Request: TokenRequest{}, Response: TokenResponse{}},
+
+	"GET /apikeys":                      {Summary: "List managed API keys", Tag: "auth", Response: []*APIKey{}},
+	"POST /apikeys":                     {Summary: "Issue a managed API key", Tag: "auth", Request: APIKeyRequest{}, Response: IssuedAPIKey{}},
+	"DELETE /apikeys/{id}":              {Summary: "Revoke a managed API key", Tag: "auth", Response: APIKey{}},
+	"POST /apikeys/{id}/rotate":         {Summary: "Replace a managed API key with a new secret", Tag: "auth", Response: IssuedAPIKey{}},
+	"GET /notifications":                {Summary: "List notification subscriptions", Tag: "notifications", Response: []*NotificationSubscription{}},
+	"POST /notifications":               {Summary: "Subscribe a webhook or email addresses to the events of federations", Tag: "notifications", Request: NotificationSubscription{}, Response: NotificationSubscription{}},
+	"GET /notifications/{id}":           {Summary: "Get a notification subscription", Tag: "notifications", Response: NotificationSubscription{}},
+	"PUT /notifications/{id}":           {Summary: "Update a notification subscription", Tag: "notifications", Request: NotificationSubscription{}, Response: NotificationSubscription{}},
+	"DELETE /notifications/{id}":        {Summary: "Delete a notification subscription", Tag: "notifications", Response: map[string]string{}},
+	"GET /audit":                        {Summary: "List audit log entries", Tag: "audit", Response: []*AuditEntry{}, Query: auditParams},
+	"GET /federations":                  {Summary: "List federations", Tag: "federations", Response: []*FederationMetrics{}, Query: withParams(queryParam{"active", "boolean", "Only running federations"})},
+	"POST /federations":                 {Summary: "Register a federation", Tag: "federations", Request: FederationMetrics{}, Response: FederationMetrics{}},
+	"GET /federations/{id}":             {Summary: "Get a federation", Tag: "federations", Response: FederationMetrics{}},
+	"PUT /federations/{id}":             {Summary: "Update a federation", Tag: "federations", Request: FederationMetrics{}, Response: FederationMetrics{}},
+	"GET /federations/{id}/overview":    {Summary: "Get the system overview of a federation", Tag: "federations", Response: SystemOverview{}},
+	"GET /federations/{id}/insights":    {Summary: "Get performance insights of a federation", Tag: "federations", Response: PerformanceInsights{}},
+	"GET /federations/{id}/convergence": {Summary: "Analyze the convergence of a federation", Tag: "federations", Response: ConvergenceAnalysis{}},
+	"GET /federations/{id}/efficiency":  {Summary: "Get the efficiency metrics of a federation", Tag: "federations", Response: EfficiencyMetrics{}},
+	"GET /federations/{id}/timeseries": {Summary: "Get bucketed time series of a federation", Tag: "federations", Response: []*TimeSeries{}, Query: []queryParam{
+		{"metrics", "string", "Comma-separated metrics to return"},
+		{"source", "string", "Resource source of resource metrics"},
+		{"start_time", "date-time", "Start of the series (RFC 3339)"},
+		{"end_time", "date-time", "End of the series (RFC 3339)"},
+		{"interval", "string", "Bucket width, such as 1m or 1h"},
+	}},
+	"GET /federations/{id}/topology": {Summary: "Get the collaborators of a federation with their locations, grouped by region", Tag: "federations", Response: Topology{},
+		Query: []queryParam{{"tag", "string", "Only collaborators with this tag; repeat or separate with commas for several"}}},
+	"GET /federations/{id}/compare/{other}": {Summary: "Compare a federation with another round by round", Tag: "federations", Response: FederationComparison{},
+		Query: []queryParam{{"threshold", "number", "Accuracy to compare the rounds and time to reach by"}}},
+	"POST /federations/{id}/pause":     {Summary: "Pause a federation: its aggregator starts no new rounds until it is resumed", Tag: "federations", Request: ControlRequest{}, Response: FederationMetrics{}},
+	"POST /federations/{id}/resume":    {Summary: "Resume a paused federation", Tag: "federations", Request: ControlRequest{}, Response: FederationMetrics{}},
+	"POST /federations/{id}/abort":     {Summary: "Abort a federation through its aggregator", Tag: "federations", Request: ControlRequest{}, Response: FederationMetrics{}},
+	"GET /federations/{id}/aggregator": {Summary: "Get the live state of a federation's aggregator and of its current round", Tag: "federations", Response: AggregatorStatus{}},
+	"GET /federations/{id}/export/{dataset}": {Summary: "Export a dataset of a federation", Tag: "federations", ContentType: "text/csv",
+		Query: withParams(queryParam{"format", "string", "csv (default) or parquet"})},
+	"POST /federations/{id}/import/{dataset}": {Summary: "Backfill a dataset of a federation from an exported CSV file", Tag: "ingest", Response: ImportResult{}},
+
+	"GET /collaborators":           {Summary: "List collaborators", Tag: "collaborators", Response: []*CollaboratorMetrics{}, Query: metricsFilterParams},
+	"POST /collaborators":          {Summary: "Register a collaborator", Tag: "collaborators", Request: CollaboratorMetrics{}, Response: CollaboratorMetrics{}},
+	"GET /collaborators/{id}":      {Summary: "Get a collaborator", Tag: "collaborators", Response: CollaboratorMetrics{}},
+	"PUT /collaborators/{id}":      {Summary: "Update a collaborator", Tag: "collaborators", Request: CollaboratorMetrics{}, Response: CollaboratorMetrics{}},
+	"GET /rounds":                  {Summary: "List rounds", Tag: "rounds", Response: []*RoundMetrics{}, Query: metricsFilterParams},
+	"POST /rounds":                 {Summary: "Record a round", Tag: "rounds", Request: RoundMetrics{}, Response: RoundMetrics{}},
+	"GET /rounds/{id}":             {Summary: "Get a round", Tag: "rounds", Response: RoundMetrics{}},
+	"PUT /rounds/{id}":             {Summary: "Update a round", Tag: "rounds", Request: RoundMetrics{}, Response: RoundMetrics{}},
+	"GET /updates":                 {Summary: "List model updates", Tag: "updates", Response: []*ModelUpdateMetrics{}, Query: metricsFilterParams},
+	"POST /updates":                {Summary: "Record a model update", Tag: "updates", Request: ModelUpdateMetrics{}, Response: ModelUpdateMetrics{}},
+	"GET /updates/statistics":      {Summary: "Get model update statistics of a federation", Tag: "updates", Response: UpdateStatistics{}, Query: federationRoundParams},
+	"GET /updates/dropped":         {Summary: "Summarize dropped model updates", Tag: "updates", Response: []*DroppedUpdateStats{}, Query: withParams(queryParam{"reason", "string", "Only updates dropped for this reason"})},
+	"POST /updates/dropped":        {Summary: "Record a dropped model update", Tag: "updates", Request: DroppedUpdate{}, Response: DroppedUpdate{}},
+	"GET /aggregations":            {Summary: "List aggregations", Tag: "aggregations", Response: []*AggregationMetrics{}, Query: metricsFilterParams},
+	"POST /aggregations":           {Summary: "Record an aggregation", Tag: "aggregations", Request: AggregationMetrics{}, Response: AggregationMetrics{}},
+	"GET /aggregations/statistics": {Summary: "Get aggregation statistics of a federation", Tag: "aggregations", Response: AggregationStatistics{}, Query: federationParams},
+	"GET /aggregations/{id}":       {Summary: "Get an aggregation", Tag: "aggregations", Response: AggregationMetrics{}},
+	"PUT /aggregations/{id}":       {Summary: "Update an aggregation, e.g. with its completion data", Tag: "aggregations", Request: AggregationMetrics{}, Response: AggregationMetrics{}},
+	"GET /contributions":           {Summary: "List contribution scores", Tag: "contributions", Response: []*ContributionScore{}, Query: metricsFilterParams},
+	"POST /contributions":          {Summary: "Record the contribution scores of an aggregation", Tag: "contributions", Request: []*ContributionScore{}, Response: []*ContributionScore{}},
+	"GET /contributions/summary":   {Summary: "Summarize contributions per collaborator", Tag: "contributions", Response: []*ContributionSummary{}, Query: federationParams},
+	"GET /ledger":                  {Summary: "Get the participation ledger", Tag: "contributions", Response: []*LedgerAccount{}, Query: metricsFilterParams},
+
+	"GET /resources/{source}":  {Summary: "Get resource metrics of a source", Tag: "resources", Response: []*ResourceMetrics{}, Query: []queryParam{{"time_range", "string", "How far back to look, such as 1h"}}},
+	"POST /resources/{source}": {Summary: "Record resource metrics of a source", Tag: "resources", Request: ResourceMetrics{}, Response: ResourceMetrics{}},
+	"GET /events":              {Summary: "List monitoring events", Tag: "events", Response: []*MonitoringEvent{}, Query: metricsFilterParams},
+	"POST /events":             {Summary: "Record a monitoring event", Tag: "events", Request: MonitoringEvent{}, Response: MonitoringEvent{}},
+	"GET /events/alerts":       {Summary: "List active alerts", Tag: "events", Response: []*Alert{}, Query: federationParams},
+	"GET /ws": {Summary: "Stream monitoring events over a WebSocket", Tag: "events", Query: []queryParam{
+		{"federation_id", "string", "Only events of this federation"},
+		{"event_types", "string", "Comma-separated event types"},
+	}},
+
+	"GET /dashboards":                   {Summary: "List dashboards", Tag: "dashboards", Response: []*Dashboard{}},
+	"POST /dashboards":                  {Summary: "Create a dashboard", Tag: "dashboards", Request: Dashboard{}, Response: Dashboard{}},
+	"GET /dashboards/templates":         {Summary: "List dashboard templates", Tag: "dashboards", Response: []DashboardTemplate{}},
+	"POST /dashboards/templates/{name}": {Summary: "Create a dashboard of a federation from a template", Tag: "dashboards", Request: DashboardTemplateRequest{}, Response: Dashboard{}},
+	"GET /dashboards/{id}":              {Summary: "Get a dashboard", Tag: "dashboards", Response: Dashboard{}},
+	"PUT /dashboards/{id}":              {Summary: "Update a dashboard", Tag: "dashboards", Request: Dashboard{}, Response: Dashboard{}},
+	"DELETE /dashboards/{id}":           {Summary: "Delete a dashboard", Tag: "dashboards", Response: map[string]string{}},
+	"GET /dashboards/{id}/widgets/{wid}/data": {Summary: "Get the data of a dashboard widget, ready to render", Tag: "dashboards", Response: WidgetData{}, Query: []queryParam{
+		{"start_time", "date-time", "Start of the series (RFC 3339), overriding the widget's time_range"},
+		{"end_time", "date-time", "End of the series (RFC 3339)"},
+		{"interval", "string", "Bucket width, such as 1m or 1h"},
+	}},
+
+	"GET /models": {Summary: "List registered model versions", Tag: "models", Response: []*registry.ModelVersion{},
+		Query: []queryParam{{"name", "string", "Only versions of this model"}}},
+	"GET /models/{name}/versions/{version}":          {Summary: "Get a model version", Tag: "models", Response: registry.ModelVersion{}},
+	"GET /models/{name}/versions/{version}/lineage":  {Summary: "Get the training lineage of a model version", Tag: "models", Response: ModelLineage{}},
+	"GET /models/{name}/versions/{version}/download": {Summary: "Download the weights of a model version", Tag: "models", ContentType: "application/octet-stream"},
+	"POST /models/{name}/versions/{version}/promote": {Summary: "Move a model version to another stage", Tag: "models", Request: PromoteModelRequest{}, Response: registry.ModelVersion{}},
+
+	"GET /grafana":              {Summary: "Test the Grafana datasource connection", Tag: "grafana"},
+	"GET /grafana/":             {Summary: "Test the Grafana datasource connection", Tag: "grafana"},
+	"POST /grafana/search":      {Summary: "List Grafana query targets", Tag: "grafana", Request: GrafanaSearchRequest{}, Response: []string{}, Raw: true},
+	"POST /grafana/query":       {Summary: "Query Grafana time series and tables", Tag: "grafana", Request: GrafanaQueryRequest{}, Response: []interface{}{}, Raw: true},
+	"POST /grafana/annotations": {Summary: "Query Grafana annotations", Tag: "grafana", Request: GrafanaAnnotationRequest{}, Response: []GrafanaAnnotation{}, Raw: true},
+	"GET /public/status":        {Summary: "Get the public status of federations", Tag: "system", Response: PublicStatus{}, Public: true},
+}
+
+var federationParams = []queryParam{{"federation_id", "string", "Federation to report on"}}
+
+var federationRoundParams = []queryParam{
+	{"federation_id", "string", "Federation to report on"},
+	{"round_number", "integer", "Round to report on"},
+}
+
+var auditParams = []queryParam{
+	{"user_id", "string", "Only actions of this caller"},
+	{"org_id", "string", "Only actions within this organization"},
+	{"action", "string", "Only actions of this kind"},
+	{"resource_id", "string", "Only actions on this resource"},
+	{"success", "boolean", "Only successful or failed actions"},
+	{"start_time", "date-time", "Only actions at or after this time (RFC 3339)"},
+	{"end_time", "date-time", "Only actions at or before this time (RFC 3339)"},
+	{"page", "integer", "Page number, starting at 1"},
+	{"per_page", "integer", "Entries per page"},
+}
+
+// pathParamPattern matches the variables of a mux path template
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+// buildOpenAPI generates an OpenAPI 3 document for the /api/v1 routes
+// registered on the router
+func (s *APIServer) buildOpenAPI() (map[string]interface{}, error) {
+	schemas := newSchemaBuilder()
+	paths := make(map[string]map[string]interface{})
+	tags := make(map[string]bool)
+
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/v1") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		relative := strings.TrimPrefix(template, "/api/v1")
+		path := pathParamPattern.ReplaceAllString(template, "{$1}")
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		for _, method := range methods {
+			doc, documented := apiRouteDocs[method+" "+relative]
+			if !documented {
+				doc = routeDoc{Summary: method + " " + relative}
+			}
+			if doc.Tag != "" {
+				tags[doc.Tag] = true
+			}
+			paths[path][strings.ToLower(method)] = doc.operation(schemas, template)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	tagList := make([]map[string]string, 0, len(tags))
+	for tag := range tags {
+		tagList = append(tagList, map[string]string{"name": tag})
+	}
+	sort.Slice(tagList, func(i, j int) bool { return tagList[i]["name"] < tagList[j]["name"] })
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "FL-GO Monitoring API",
+			"description": "Monitoring API of FL-GO federations. Responses are wrapped in an envelope with success, data and error fields.",
+			"version":     "1.0.0",
+		},
+		"tags":  tagList,
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]string{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": map[string]string{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}, nil
+}
+
+// operation builds the OpenAPI operation of a route
+func (doc routeDoc) operation(schemas *schemaBuilder, template string) map[string]interface{} {
+	op := map[string]interface{}{"summary": doc.Summary}
+	if doc.Tag != "" {
+		op["tags"] = []string{doc.Tag}
+	}
+	if doc.Public {
+		op["security"] = []interface{}{}
+	} else {
+		op["security"] = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	}
+
+	var params []map[string]interface{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(template, -1) {
+		params = append(params, map[string]interface{}{
+			"name": match[1], "in": "path", "required": true,
+			"schema": map[string]string{"type": "string"},
+		})
+	}
+	for _, param := range doc.Query {
+		params = append(params, map[string]interface{}{
+			"name": param.Name, "in": "query", "description": param.Description,
+			"schema": paramSchema(param.Type),
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if doc.Request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemas.schemaFor(reflect.TypeOf(doc.Request))},
+			},
+		}
+	}
+
+	success := map[string]interface{}{"description": "Success"}
+	switch {
+	case doc.ContentType != "":
+		success["content"] = map[string]interface{}{doc.ContentType: map[string]interface{}{}}
+	case doc.Response != nil:
+		schema := schemas.schemaFor(reflect.TypeOf(doc.Response))
+		if !doc.Raw {
+			schema = schemas.envelopeSchema(schema)
+		}
+		success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schemas.envelopeSchema(nil)},
+		},
+	}
+	op["responses"] = map[string]interface{}{"200": success, "default": errorResponse}
+	return op
+}
+
+// envelopeSchema is the schema of an APIResponse whose data has schema data
+func (b *schemaBuilder) envelopeSchema(data map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"success": map[string]string{"type": "boolean"},
+		"error":   map[string]string{"type": "string"},
+		"meta":    b.schemaFor(reflect.TypeOf(MetaInfo{})),
+	}
+	if data != nil {
+		properties["data"] = data
+	}
+	return map[string]interface{}{"type": "object", "required": []string{"success"}, "properties": properties}
+}
+
+func paramSchema(paramType string) map[string]string {
+	if paramType == "date-time" {
+		return map[string]string{"type": "string", "format": "date-time"}
+	}
+	return map[string]string{"type": paramType}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// schemaBuilder derives JSON schemas from Go types. Named structs become
+// shared component schemas.
+type schemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: make(map[string]interface{})}
+}
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "Duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schemaFor(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, exists := b.schemas[t.Name()]; !exists {
+			// Registered before the fields so that recursive types terminate
+			b.schemas[t.Name()] = nil
+			b.schemas[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema describes the JSON encoding of a struct
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.addFields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if tag == "-" {
+			continue
+		}
+
+		// Fields of embedded structs are promoted, even when the struct
+		// itself is unexported
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			b.addFields(fieldType, properties)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaFor(field.Type)
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document of the API
+func (s *APIServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	document, err := s.buildOpenAPI()
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to build OpenAPI document", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(document)
+}
+
+// handleAPIDocs serves Swagger UI for the OpenAPI document
+func (s *APIServer) handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, swaggerUIPage)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>FL-GO Monitoring API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,132 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newOpenAPITestServer() *APIServer {
+	config := &MonitoringConfig{PublicStatus: PublicStatusConfig{Enabled: true}}
+	return NewAPIServer(NewMemoryStorage(config), config)
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	server := newOpenAPITestServer()
+
+	routes := make(map[string]bool)
+	server.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/v1") {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			key := method + " " + strings.TrimPrefix(template, "/api/v1")
+			routes[key] = true
+			if _, documented := apiRouteDocs[key]; !documented {
+				t.Errorf("route %s is not documented in apiRouteDocs", key)
+			}
+		}
+		return nil
+	})
+
+	for key := range apiRouteDocs {
+		if !routes[key] {
+			t.Errorf("apiRouteDocs documents %s, which is not a route", key)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	server := newOpenAPITestServer()
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+
+	var document struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", document.OpenAPI)
+	}
+
+	operations := []struct {
+		path   string
+		method string
+	}{
+		{"/api/v1/federations/{id}", "get"},
+		{"/api/v1/federations/{id}", "put"},
+		{"/api/v1/models/{name}/versions/{version}/promote", "post"},
+		{"/api/v1/grafana/query", "post"},
+	}
+	for _, op := range operations {
+		if _, exists := document.Paths[op.path][op.method]; !exists {
+			t.Errorf("missing operation %s %s", op.method, op.path)
+		}
+	}
+
+	// Schemas follow the JSON encoding of the types
+	federation, exists := document.Components.Schemas["FederationMetrics"]
+	if !exists {
+		t.Fatal("missing FederationMetrics schema")
+	}
+	for _, property := range []string{"id", "status", "start_time"} {
+		if _, exists := federation.Properties[property]; !exists {
+			t.Errorf("FederationMetrics schema is missing %s", property)
+		}
+	}
+	if _, exists := document.Components.Schemas["MetricsFilter"]; exists {
+		t.Error("unused MetricsFilter schema was generated")
+	}
+}
+
+func TestSchemaFor(t *testing.T) {
+	type inner struct {
+		Value float64 `json:"value"`
+	}
+	type sample struct {
+		inner
+		Name    string            `json:"name"`
+		Tags    map[string]string `json:"tags,omitempty"`
+		Ignored string            `json:"-"`
+		Next    *sample           `json:"next"`
+	}
+
+	b := newSchemaBuilder()
+	ref := b.schemaFor(reflect.TypeOf([]*sample{}))
+	if ref["type"] != "array" {
+		t.Fatalf("schema = %v, want an array", ref)
+	}
+
+	schema, ok := b.schemas["sample"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("sample schema was not registered: %v", b.schemas)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	for _, property := range []string{"value", "name", "tags", "next"} {
+		if _, exists := properties[property]; !exists {
+			t.Errorf("missing property %s", property)
+		}
+	}
+	if _, exists := properties["Ignored"]; exists {
+		t.Error("json:\"-\" field was described")
+	}
+}
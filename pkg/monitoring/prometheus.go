@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PrometheusSample is a single labeled value of a Prometheus metric
+type PrometheusSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// WritePrometheusMetric writes a metric family in the Prometheus text exposition format
+func WritePrometheusMetric(w io.Writer, name, help, metricType string, samples []PrometheusSample) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType); err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		if _, err := fmt.Fprintf(w, "%s%s %g\n", name, formatPrometheusLabels(sample.Labels), sample.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatPrometheusLabels renders labels in a stable order with escaped values
+func formatPrometheusLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, key, escaper.Replace(labels[key])))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
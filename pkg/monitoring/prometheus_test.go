@@ -0,0 +1,29 @@
+package monitoring
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWritePrometheusMetric(t *testing.T) {
+	var buf bytes.Buffer
+	samples := []PrometheusSample{
+		{Labels: map[string]string{"reason": "stale", "collaborator_id": "c1"}, Value: 3},
+		{Labels: map[string]string{"reason": `bad "quote"`}, Value: 1},
+		{Value: 0.5},
+	}
+
+	if err := WritePrometheusMetric(&buf, "flgo_test_total", "Test metric", "counter", samples); err != nil {
+		t.Fatalf("WritePrometheusMetric() error = %v", err)
+	}
+
+	expected := "# HELP flgo_test_total Test metric\n" +
+		"# TYPE flgo_test_total counter\n" +
+		"flgo_test_total{collaborator_id=\"c1\",reason=\"stale\"} 3\n" +
+		"flgo_test_total{reason=\"bad \\\"quote\\\"\"} 1\n" +
+		"flgo_test_total 0.5\n"
+
+	if buf.String() != expected {
+		t.Errorf("WritePrometheusMetric() output =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,264 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitClients bounds the number of callers whose limits are tracked.
+// Once it is reached, idle callers are forgotten, and then the least recently
+// seen ones until a tenth of the room is free again.
+const maxRateLimitClients = 10000
+
+// roleUnauthenticated names the limits of requests whose credentials were
+// rejected, which are counted per client address before authentication
+const roleUnauthenticated = "unauthenticated"
+
+// RateLimit limits the requests of a single caller. Zero values are
+// unlimited.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`       // default: requests_per_second rounded up
+	DailyQuota        int     `yaml:"daily_quota"` // requests per UTC day
+}
+
+// RateLimitConfig configures per-caller rate limiting of the monitoring API.
+// Callers are identified by API key or JWT subject, or by client address
+// when authentication is disabled.
+type RateLimitConfig struct {
+	Enabled           bool                 `yaml:"enabled"`
+	RequestsPerSecond float64              `yaml:"requests_per_second"`
+	Burst             int                  `yaml:"burst"`
+	DailyQuota        int                  `yaml:"daily_quota"`
+	Roles             map[string]RateLimit `yaml:"roles"` // limits that replace the defaults for a role
+}
+
+// limitFor returns the limits of callers with role
+func (c RateLimitConfig) limitFor(role string) RateLimit {
+	limit, exists := c.Roles[role]
+	if !exists {
+		limit = RateLimit{RequestsPerSecond: c.RequestsPerSecond, Burst: c.Burst, DailyQuota: c.DailyQuota}
+	}
+	if limit.RequestsPerSecond > 0 && limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+	return limit
+}
+
+// rateLimiter keeps a token bucket and a daily request count per caller
+type rateLimiter struct {
+	config  RateLimitConfig
+	mu      sync.Mutex
+	clients map[string]*clientUsage
+	now     func() time.Time
+}
+
+// clientUsage is the usage of one caller
+type clientUsage struct {
+	tokens float64
+	last   time.Time // when tokens was last refilled
+	day    time.Time // UTC day that used counts requests for
+	used   int
+}
+
+// rateDecision is the outcome of checking a request against its limits
+type rateDecision struct {
+	limit          RateLimit
+	allowed        bool
+	reason         string
+	remaining      int           // tokens left in the bucket
+	quotaRemaining int           // requests left today
+	retryAfter     time.Duration // when a rejected request can be retried
+	reset          time.Time     // when the daily quota resets
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		clients: make(map[string]*clientUsage),
+		now:     time.Now,
+	}
+}
+
+// allow takes a request of a caller with role from its bucket and quota
+func (l *rateLimiter) allow(client, role string) rateDecision {
+	return l.take(client, role, true)
+}
+
+// check reports whether a caller with role has a request left, without
+// taking it
+func (l *rateLimiter) check(client, role string) rateDecision {
+	return l.take(client, role, false)
+}
+
+// take decides whether a caller with role has a request left, and takes it
+// from the bucket and quota when consume is set
+func (l *rateLimiter) take(client, role string, consume bool) rateDecision {
+	limit := l.config.limitFor(role)
+	now := l.now()
+	today := now.UTC().Truncate(24 * time.Hour)
+	decision := rateDecision{limit: limit, allowed: true, reset: today.Add(24 * time.Hour)}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage, exists := l.clients[client]
+	if !exists {
+		if !consume {
+			// An unseen caller has its whole bucket and quota
+			decision.remaining = limit.Burst
+			decision.quotaRemaining = limit.DailyQuota
+			return decision
+		}
+		if len(l.clients) >= maxRateLimitClients {
+			l.prune(now, today)
+		}
+		usage = &clientUsage{tokens: float64(limit.Burst), last: now, day: today}
+		l.clients[client] = usage
+	}
+
+	if !usage.day.Equal(today) {
+		usage.day = today
+		usage.used = 0
+	}
+	if limit.RequestsPerSecond > 0 {
+		usage.tokens = math.Min(float64(limit.Burst), usage.tokens+now.Sub(usage.last).Seconds()*limit.RequestsPerSecond)
+	}
+	usage.last = now
+
+	switch {
+	case limit.DailyQuota > 0 && usage.used >= limit.DailyQuota:
+		decision.allowed = false
+		decision.reason = fmt.Sprintf("daily quota of %d requests exceeded", limit.DailyQuota)
+		decision.retryAfter = decision.reset.Sub(now)
+	case limit.RequestsPerSecond > 0 && usage.tokens < 1:
+		decision.allowed = false
+		decision.reason = fmt.Sprintf("rate limit of %g requests per second exceeded", limit.RequestsPerSecond)
+		decision.retryAfter = time.Duration((1 - usage.tokens) / limit.RequestsPerSecond * float64(time.Second))
+	case consume:
+		if limit.RequestsPerSecond > 0 {
+			usage.tokens--
+		}
+		usage.used++
+	}
+
+	decision.remaining = int(usage.tokens)
+	decision.quotaRemaining = max(limit.DailyQuota-usage.used, 0)
+	return decision
+}
+
+// prune makes room for new callers. It forgets callers whose bucket is full
+// and whose quota has reset, as tracking them further changes nothing, and
+// then the least recently seen callers while the map is over nine tenths of
+// maxRateLimitClients, so that a flood of distinct callers on one day cannot
+// grow it without bound.
+func (l *rateLimiter) prune(now, today time.Time) {
+	for client, usage := range l.clients {
+		if usage.day.Before(today) && now.Sub(usage.last) > time.Minute {
+			delete(l.clients, client)
+		}
+	}
+
+	excess := len(l.clients) - maxRateLimitClients*9/10
+	if excess <= 0 {
+		return
+	}
+	clients := make([]string, 0, len(l.clients))
+	for client := range l.clients {
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return l.clients[clients[i]].last.Before(l.clients[clients[j]].last)
+	})
+	for _, client := range clients[:excess] {
+		delete(l.clients, client)
+	}
+}
+
+// setHeaders describes the caller's limits in the response headers
+func (d rateDecision) setHeaders(w http.ResponseWriter) {
+	header := w.Header()
+	if d.limit.RequestsPerSecond > 0 {
+		header.Set("X-RateLimit-Limit", strconv.Itoa(d.limit.Burst))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(d.remaining))
+	}
+	if d.limit.DailyQuota > 0 {
+		header.Set("X-Quota-Limit", strconv.Itoa(d.limit.DailyQuota))
+		header.Set("X-Quota-Remaining", strconv.Itoa(d.quotaRemaining))
+		header.Set("X-Quota-Reset", strconv.FormatInt(d.reset.Unix(), 10))
+	}
+	if !d.allowed {
+		header.Set("Retry-After", strconv.Itoa(int(math.Ceil(d.retryAfter.Seconds()))))
+	}
+}
+
+// rateLimit wraps an authenticated handler so that each caller's requests
+// are limited. It runs inside the auth middleware, which identifies the
+// caller.
+func (s *APIServer) rateLimit(handler http.Handler) http.Handler {
+	if s.limiter == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, role := "", ""
+		if user, ok := GetUserFromContext(r.Context()); ok {
+			client, role = user.UserID, user.Role
+		}
+		if client == "" || client == "anonymous" {
+			client = remoteHost(r)
+		}
+
+		decision := s.limiter.allow(client, role)
+		decision.setHeaders(w)
+		if !decision.allowed {
+			s.sendError(w, http.StatusTooManyRequests, "Too many requests", errors.New(decision.reason))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// limitFailedAuth wraps a handler protected by the auth middleware so that
+// requests whose credentials are rejected are limited by client address under
+// the unauthenticated role. A client over that limit is refused before its
+// credentials are checked, which keeps it from guessing API keys or flooding
+// the server with requests that never reach rateLimit.
+func (s *APIServer) limitFailedAuth(protect func(http.Handler) http.Handler, handler http.Handler) http.Handler {
+	if s.limiter == nil {
+		return protect(handler)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := "addr:" + remoteHost(r)
+		if decision := s.limiter.check(client, roleUnauthenticated); !decision.allowed {
+			decision.setHeaders(w)
+			s.sendError(w, http.StatusTooManyRequests, "Too many requests", errors.New(decision.reason))
+			return
+		}
+
+		// The response writer is passed through untouched, since websocket
+		// upgrades hijack it, so a rejection is told by the handler not running
+		passed := false
+		protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed = true
+			handler.ServeHTTP(w, r)
+		})).ServeHTTP(w, r)
+		if !passed {
+			s.limiter.allow(client, roleUnauthenticated)
+		}
+	})
+}
+
+// remoteHost returns the address of the client of a request without its port
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
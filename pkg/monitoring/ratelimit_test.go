@@ -0,0 +1,186 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 2, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    bool
+	}{
+		{"burst 1", 0, true},
+		{"burst 2", 0, true},
+		{"burst 3", 0, true},
+		{"bucket empty", 0, false},
+		{"half a token refilled", 250 * time.Millisecond, false},
+		{"a token refilled", 250 * time.Millisecond, true},
+		{"bucket empty again", 0, false},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		decision := limiter.allow("key-a", RoleMonitor)
+		if decision.allowed != step.want {
+			t.Errorf("%s: allowed = %v, want %v", step.name, decision.allowed, step.want)
+		}
+	}
+
+	// Other callers have their own bucket
+	if !limiter.allow("key-b", RoleMonitor).allowed {
+		t.Error("key-b was limited by key-a's requests")
+	}
+}
+
+func TestRateLimiterDailyQuota(t *testing.T) {
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(RateLimitConfig{
+		Enabled:    true,
+		DailyQuota: 2,
+		Roles:      map[string]RateLimit{RoleAdmin: {}},
+	})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if !limiter.allow("key-a", RoleReadOnly).allowed {
+			t.Fatalf("request %d was rejected", i+1)
+		}
+	}
+	decision := limiter.allow("key-a", RoleReadOnly)
+	if decision.allowed || decision.quotaRemaining != 0 || decision.retryAfter != time.Hour {
+		t.Errorf("over quota decision = %+v, want rejected for an hour", decision)
+	}
+
+	// Admins have no limits, and the quota resets at midnight UTC
+	if !limiter.allow("admin", RoleAdmin).allowed {
+		t.Error("admin was limited")
+	}
+	now = now.Add(time.Hour)
+	if !limiter.allow("key-a", RoleReadOnly).allowed {
+		t.Error("quota did not reset at midnight")
+	}
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1})
+	limiter.now = func() time.Time { return now }
+
+	// Distinct callers on one day are all still active, so the least recently
+	// seen ones make room
+	for i := 0; i < maxRateLimitClients; i++ {
+		limiter.allow(fmt.Sprintf("client-%d", i), RoleReadOnly)
+		now = now.Add(time.Millisecond)
+	}
+	limiter.allow("newest", RoleReadOnly)
+
+	if len(limiter.clients) > maxRateLimitClients*9/10+1 {
+		t.Errorf("tracked %d callers, want at most %d", len(limiter.clients), maxRateLimitClients*9/10+1)
+	}
+	if _, ok := limiter.clients["client-0"]; ok {
+		t.Error("the least recently seen caller was kept")
+	}
+	for _, client := range []string{fmt.Sprintf("client-%d", maxRateLimitClients-1), "newest"} {
+		if _, ok := limiter.clients[client]; !ok {
+			t.Errorf("recently seen caller %s was forgotten", client)
+		}
+	}
+}
+
+func TestRateLimitFailedAuth(t *testing.T) {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled:    true,
+			APIKeyAuth: APIKeyConfig{Enabled: true, Keys: map[string]string{"reader-key": RoleReadOnly}},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Roles:   map[string]RateLimit{roleUnauthenticated: {RequestsPerSecond: 1, Burst: 2}},
+		},
+	}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	request := func(addr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/federations", nil)
+		req.RemoteAddr = addr
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Valid keys are not counted against the address
+	for i := 0; i < 3; i++ {
+		if rr := request("10.0.0.1:1234", "reader-key"); rr.Code != http.StatusOK {
+			t.Fatalf("valid request %d status = %d, want 200", i+1, rr.Code)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := request("10.0.0.1:1234", "guessed-key"); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("bad key %d status = %d, want 401", i+1, rr.Code)
+		}
+	}
+	// Once the address has used its failures, it is refused before its
+	// credentials are checked, and other addresses are not
+	limited := request("10.0.0.1:5678", "reader-key")
+	if limited.Code != http.StatusTooManyRequests || limited.Header().Get("Retry-After") == "" {
+		t.Errorf("request after failures status = %d, Retry-After = %q, want 429 with Retry-After",
+			limited.Code, limited.Header().Get("Retry-After"))
+	}
+	if rr := request("10.0.0.2:1234", "guessed-key"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("bad key from another address status = %d, want 401", rr.Code)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled:    true,
+			APIKeyAuth: APIKeyConfig{Enabled: true, Keys: map[string]string{"reader-key": RoleReadOnly}},
+		},
+		RateLimit: RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1, DailyQuota: 100},
+	}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/federations", nil)
+		req.Header.Set("X-API-Key", "reader-key")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := request()
+	if first.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", first.Code)
+	}
+	if first.Header().Get("X-RateLimit-Limit") != "1" || first.Header().Get("X-Quota-Remaining") != "99" {
+		t.Errorf("headers = %v, want limit 1 and 99 requests left today", first.Header())
+	}
+
+	second := request()
+	if second.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", second.Code)
+	}
+	if second.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", second.Header().Get("Retry-After"))
+	}
+
+	// The health check is never limited
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/health", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("health status = %d, want 200", rr.Code)
+		}
+	}
+}
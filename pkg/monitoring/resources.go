@@ -0,0 +1,263 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResourceInterval is how often resource metrics are reported when
+// the plan sets no monitoring.report_interval
+const DefaultResourceInterval = 30 * time.Second
+
+// ResourceSampler measures the resource usage of the host. CPU usage and
+// network rates are averages since the previous sample, so the first sample
+// reports neither.
+type ResourceSampler struct {
+	diskPath string
+
+	mu       sync.Mutex
+	prevCPU  cpuTimes
+	prevNet  netCounters
+	prevTime time.Time
+}
+
+// NewResourceSampler creates a sampler that reports the disk usage of the
+// file system holding diskPath
+func NewResourceSampler(diskPath string) *ResourceSampler {
+	return &ResourceSampler{diskPath: diskPath}
+}
+
+// Sample measures the current resource usage. Memory must be measurable;
+// CPU, disk, network and GPU usage are left unset when they are not.
+func (s *ResourceSampler) Sample() (*ResourceMetrics, error) {
+	total, available, err := readMemInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory usage: %v", err)
+	}
+	now := time.Now()
+	metrics := &ResourceMetrics{
+		Timestamp:   now,
+		MemoryTotal: total,
+		MemoryUsed:  total - available,
+	}
+	if total > 0 {
+		metrics.MemoryUsage = 100 * float64(total-available) / float64(total)
+	}
+	if usage, err := diskUsage(s.diskPath); err == nil {
+		metrics.DiskUsage = usage
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	gpus, err := QueryGPUs(ctx)
+	cancel()
+	if err == nil {
+		setGPUMetrics(metrics, gpus)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cpu, err := readCPUTimes(); err == nil {
+		if !s.prevTime.IsZero() {
+			metrics.CPUUsage = cpu.usageSince(s.prevCPU)
+		}
+		s.prevCPU = cpu
+	}
+	if net, err := readNetCounters(); err == nil {
+		if seconds := now.Sub(s.prevTime).Seconds(); !s.prevTime.IsZero() && seconds > 0 {
+			metrics.NetworkRxRate = mbps(net.rx-s.prevNet.rx, seconds)
+			metrics.NetworkTxRate = mbps(net.tx-s.prevNet.tx, seconds)
+		}
+		s.prevNet = net
+	}
+	s.prevTime = now
+	return metrics, nil
+}
+
+func mbps(bytes uint64, seconds float64) float64 {
+	return float64(bytes) * 8 / 1e6 / seconds
+}
+
+// cpuTimes are the cumulative busy and total CPU times of the host
+type cpuTimes struct {
+	busy, total uint64
+}
+
+// usageSince returns the CPU usage in percent between prev and t
+func (t cpuTimes) usageSince(prev cpuTimes) float64 {
+	if t.total <= prev.total || t.busy < prev.busy {
+		return 0
+	}
+	return 100 * float64(t.busy-prev.busy) / float64(t.total-prev.total)
+}
+
+// netCounters are the bytes received and sent on all interfaces but loopback
+type netCounters struct {
+	rx, tx uint64
+}
+
+// parseCPUTimes parses the aggregate cpu line of /proc/stat
+func parseCPUTimes(data []byte) (cpuTimes, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var t cpuTimes
+		for i, field := range fields[1:] {
+			// guest and guest_nice are included in user and nice
+			if i >= 8 {
+				break
+			}
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return cpuTimes{}, fmt.Errorf("invalid cpu time %q", field)
+			}
+			t.total += v
+			if i != 3 && i != 4 { // idle and iowait
+				t.busy += v
+			}
+		}
+		return t, nil
+	}
+	return cpuTimes{}, fmt.Errorf("no cpu line")
+}
+
+// parseMemInfo returns the total and available memory in bytes from
+// /proc/meminfo
+func parseMemInfo(data []byte) (total, available int64, err error) {
+	found := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var target *int64
+		switch fields[0] {
+		case "MemTotal:":
+			target = &total
+		case "MemAvailable:":
+			target = &available
+		default:
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid %s %q", fields[0], fields[1])
+		}
+		*target = kb * 1024
+		found++
+	}
+	if found < 2 {
+		return 0, 0, fmt.Errorf("MemTotal or MemAvailable missing")
+	}
+	return total, available, nil
+}
+
+// parseNetDev sums the bytes received and sent on all interfaces but
+// loopback from /proc/net/dev
+func parseNetDev(data []byte) (netCounters, error) {
+	var counters netCounters
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		name, stats, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(stats)
+		if len(fields) < 9 {
+			continue
+		}
+		rx, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return netCounters{}, fmt.Errorf("invalid received bytes %q", fields[0])
+		}
+		tx, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return netCounters{}, fmt.Errorf("invalid sent bytes %q", fields[8])
+		}
+		counters.rx += rx
+		counters.tx += tx
+	}
+	return counters, nil
+}
+
+// ReportResources posts a sample of sampler to the monitoring server at
+// serverURL every interval until ctx is done, as the resource metrics of
+// source. Failed samples and posts are logged once.
+func ReportResources(ctx context.Context, serverURL, apiKey, source string, interval time.Duration, sampler *ResourceSampler) {
+	if interval <= 0 {
+		interval = DefaultResourceInterval
+	}
+	url := strings.TrimRight(serverURL, "/") + "/api/v1/resources/" + source
+	client := &http.Client{Timeout: 5 * time.Second}
+	warned := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := func() error {
+			metrics, err := sampler.Sample()
+			if err != nil {
+				return err
+			}
+			body, err := json.Marshal(metrics)
+			if err != nil {
+				return err
+			}
+			return postJSON(ctx, client, url, apiKey, body)
+		}()
+		if err != nil && !warned && ctx.Err() == nil {
+			log.Printf("Warning: failed to report resource metrics to monitoring: %v", err)
+			warned = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RegisterCollaborator announces a collaborator to the monitoring server, so
+// that its resource metrics are shown with it
+func RegisterCollaborator(ctx context.Context, serverURL, apiKey string, collaborator *CollaboratorMetrics) error {
+	body, err := json.Marshal(collaborator)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	return postJSON(ctx, client, strings.TrimRight(serverURL, "/")+"/api/v1/collaborators", apiKey, body)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("monitoring server returned %d", resp.StatusCode)
+	}
+	return nil
+}
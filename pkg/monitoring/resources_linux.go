@@ -0,0 +1,46 @@
+//go:build linux
+
+package monitoring
+
+import (
+	"os"
+	"syscall"
+)
+
+func readCPUTimes() (cpuTimes, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	return parseCPUTimes(data)
+}
+
+func readMemInfo() (total, available int64, err error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseMemInfo(data)
+}
+
+func readNetCounters() (netCounters, error) {
+	data, err := os.ReadFile("/proc/net/dev")
+	if err != nil {
+		return netCounters{}, err
+	}
+	return parseNetDev(data)
+}
+
+// diskUsage returns the used share of the file system holding path in
+// percent, counting the blocks reserved for root as df does
+func diskUsage(path string) (float64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	used := stat.Blocks - stat.Bfree
+	if used+stat.Bavail == 0 {
+		return 0, nil
+	}
+	return 100 * float64(used) / float64(used+stat.Bavail), nil
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package monitoring
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func readCPUTimes() (cpuTimes, error) {
+	return cpuTimes{}, fmt.Errorf("CPU usage is not supported on %s", runtime.GOOS)
+}
+
+func readMemInfo() (total, available int64, err error) {
+	return 0, 0, fmt.Errorf("memory usage is not supported on %s", runtime.GOOS)
+}
+
+func readNetCounters() (netCounters, error) {
+	return netCounters{}, fmt.Errorf("network usage is not supported on %s", runtime.GOOS)
+}
+
+func diskUsage(path string) (float64, error) {
+	return 0, fmt.Errorf("disk usage is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,192 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCPUTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    cpuTimes
+		wantErr bool
+	}{
+		{
+			name: "aggregate line",
+			data: "cpu  100 5 50 800 20 3 2 0 7 0\ncpu0 50 2 25 400 10 1 1 0 0 0\n",
+			// guest times are part of user, so only the first 8 fields count
+			want: cpuTimes{busy: 160, total: 980},
+		},
+		{name: "no cpu line", data: "intr 1 2 3\n", wantErr: true},
+		{name: "invalid time", data: "cpu  1 x 3 4 5\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCPUTimes([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCPUTimes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCPUTimes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCPUUsageSince(t *testing.T) {
+	prev := cpuTimes{busy: 100, total: 1000}
+	if got := (cpuTimes{busy: 150, total: 1200}).usageSince(prev); got != 25 {
+		t.Errorf("usageSince() = %v, want 25", got)
+	}
+	if got := prev.usageSince(prev); got != 0 {
+		t.Errorf("usageSince() without elapsed time = %v, want 0", got)
+	}
+}
+
+func TestParseMemInfo(t *testing.T) {
+	data := "MemTotal:        8000000 kB\nMemFree:         1000000 kB\nMemAvailable:    6000000 kB\n"
+	total, available, err := parseMemInfo([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 8000000*1024 || available != 6000000*1024 {
+		t.Errorf("parseMemInfo() = %d, %d", total, available)
+	}
+	if _, _, err := parseMemInfo([]byte("MemTotal: 1 kB\n")); err == nil {
+		t.Error("parseMemInfo() without MemAvailable succeeded")
+	}
+}
+
+func TestParseNetDev(t *testing.T) {
+	data := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 5000      10    0    0    0     0          0         0     5000      10    0    0    0     0       0          0
+  eth0: 1000      20    0    0    0     0          0         0     300       5    0    0    0     0       0          0
+  eth1: 24        1    0    0    0     0          0         0     12        1    0    0    0     0       0          0
+`
+	got, err := parseNetDev([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (netCounters{rx: 1024, tx: 312}); got != want {
+		t.Errorf("parseNetDev() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResourceMetricsUpdateCollaborator(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	ctx := context.Background()
+	if err := storage.RegisterCollaborator(ctx, &CollaboratorMetrics{ID: "site-a", FederationID: "fed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sample := &ResourceMetrics{Timestamp: time.Now(), CPUUsage: 93, MemoryUsage: 71}
+	if err := storage.RecordResourceMetrics(ctx, "site-a", sample); err != nil {
+		t.Fatal(err)
+	}
+	collaborator, err := storage.GetCollaborator(ctx, "site-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collaborator.ResourceMetrics == nil || collaborator.ResourceMetrics.CPUUsage != 93 {
+		t.Errorf("collaborator resource metrics = %+v, want the latest sample", collaborator.ResourceMetrics)
+	}
+	if !collaborator.LastSeen.Equal(sample.Timestamp) {
+		t.Errorf("LastSeen = %v, want %v", collaborator.LastSeen, sample.Timestamp)
+	}
+}
+
+func TestReportResources(t *testing.T) {
+	received := make(chan ResourceMetrics, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/resources/site-a" || r.Header.Get("X-API-Key") != "key" {
+			t.Errorf("unexpected request %s with key %q", r.URL.Path, r.Header.Get("X-API-Key"))
+		}
+		var metrics ResourceMetrics
+		if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		select {
+		case received <- metrics:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	sampler := NewResourceSampler(t.TempDir())
+	if _, err := sampler.Sample(); err != nil {
+		t.Skipf("resource metrics are not supported here: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ReportResources(ctx, server.URL+"/", "key", "site-a", time.Hour, sampler)
+		close(done)
+	}()
+
+	select {
+	case metrics := <-received:
+		if metrics.MemoryTotal <= 0 || math.IsNaN(metrics.MemoryUsage) || metrics.MemoryUsage <= 0 || metrics.MemoryUsage > 100 {
+			t.Errorf("implausible memory usage: %+v", metrics)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no resource metrics were reported")
+	}
+	cancel()
+	<-done
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	data := "0, NVIDIA A100-SXM4-40GB, 87, 30000, 40960, 71\n1, NVIDIA A100-SXM4-40GB, 13, 2048, 40960, [N/A]\n"
+	gpus, err := parseNvidiaSMI([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GPUStats{
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Utilization: 87, MemoryUsed: 30000 << 20, MemoryTotal: 40960 << 20, Temperature: 71},
+		{Index: 1, Name: "NVIDIA A100-SXM4-40GB", Utilization: 13, MemoryUsed: 2048 << 20, MemoryTotal: 40960 << 20},
+	}
+	if len(gpus) != len(want) {
+		t.Fatalf("parseNvidiaSMI() = %d GPUs, want %d", len(gpus), len(want))
+	}
+	for i := range want {
+		if gpus[i] != want[i] {
+			t.Errorf("GPU %d = %+v, want %+v", i, gpus[i], want[i])
+		}
+	}
+
+	if _, err := parseNvidiaSMI([]byte("0, GPU, 1\n")); err == nil {
+		t.Error("parseNvidiaSMI() accepted a short line")
+	}
+}
+
+func TestSetGPUMetrics(t *testing.T) {
+	metrics := &ResourceMetrics{}
+	setGPUMetrics(metrics, nil)
+	if metrics.GPUUsage != nil || metrics.GPUCount != 0 {
+		t.Errorf("setGPUMetrics() without GPUs = %+v", metrics)
+	}
+
+	setGPUMetrics(metrics, []GPUStats{
+		{Utilization: 80, MemoryUsed: 30, MemoryTotal: 40, Temperature: 70},
+		{Utilization: 20, MemoryUsed: 10, MemoryTotal: 40, Temperature: 50},
+	})
+	if metrics.GPUUsage == nil || *metrics.GPUUsage != 50 {
+		t.Errorf("GPUUsage = %v, want the mean 50", metrics.GPUUsage)
+	}
+	if metrics.GPUMemory == nil || *metrics.GPUMemory != 50 {
+		t.Errorf("GPUMemory = %v, want 50", metrics.GPUMemory)
+	}
+	if metrics.GPUTemperature == nil || *metrics.GPUTemperature != 70 {
+		t.Errorf("GPUTemperature = %v, want the hottest 70", metrics.GPUTemperature)
+	}
+	if metrics.GPUCount != 2 {
+		t.Errorf("GPUCount = %d, want 2", metrics.GPUCount)
+	}
+}
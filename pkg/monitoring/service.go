@@ -13,6 +13,7 @@ type MonitoringService interface {
 	GetFederation(ctx context.Context, federationID string) (*FederationMetrics, error)
 	GetActiveFederations(ctx context.Context) ([]*FederationMetrics, error)
 	GetFederationHistory(ctx context.Context, filter *MetricsFilter) ([]*FederationMetrics, error)
+	ImportFederationHistory(ctx context.Context, archive *ImportArchive) (*ImportResult, error)
 
 	// Collaborator metrics
 	RegisterCollaborator(ctx context.Context, metrics *CollaboratorMetrics) error
@@ -32,15 +33,27 @@ type MonitoringService interface {
 	RecordModelUpdate(ctx context.Context, metrics *ModelUpdateMetrics) error
 	GetModelUpdates(ctx context.Context, filter *MetricsFilter) ([]*ModelUpdateMetrics, error)
 	GetUpdateStatistics(ctx context.Context, federationID string, roundNumber int) (*UpdateStatistics, error)
+	RecordDroppedUpdate(ctx context.Context, dropped *DroppedUpdate) error
+	GetDroppedUpdateStats(ctx context.Context, filter *MetricsFilter) ([]*DroppedUpdateStats, error)
 
 	// Aggregation metrics
 	RecordAggregation(ctx context.Context, metrics *AggregationMetrics) error
+	GetAggregation(ctx context.Context, aggregationID string) (*AggregationMetrics, error)
+	UpdateAggregation(ctx context.Context, aggregationID string, metrics *AggregationMetrics) error
 	GetAggregations(ctx context.Context, filter *MetricsFilter) ([]*AggregationMetrics, error)
 	GetAggregationStatistics(ctx context.Context, federationID string) (*AggregationStatistics, error)
 
+	// Contribution scores
+	RecordContributions(ctx context.Context, scores []*ContributionScore) error
+	GetContributions(ctx context.Context, filter *MetricsFilter) ([]*ContributionScore, error)
+	GetContributionSummary(ctx context.Context, federationID string) ([]*ContributionSummary, error)
+	GetLedger(ctx context.Context, filter *MetricsFilter) ([]*LedgerAccount, error)
+
 	// Resource metrics
 	RecordResourceMetrics(ctx context.Context, source string, metrics *ResourceMetrics) error
 	GetResourceMetrics(ctx context.Context, source string, timeRange time.Duration) ([]*ResourceMetrics, error)
+	RegisterResourceSource(ctx context.Context, source *ResourceSource) (*ResourceSource, error)
+	GetResourceSource(ctx context.Context, sourceID string) (*ResourceSource, error)
 	GetSystemOverview(ctx context.Context, federationID string) (*SystemOverview, error)
 
 	// Events and alerts
@@ -52,6 +65,7 @@ type MonitoringService interface {
 	GetPerformanceInsights(ctx context.Context, federationID string) (*PerformanceInsights, error)
 	GetConvergenceAnalysis(ctx context.Context, federationID string) (*ConvergenceAnalysis, error)
 	GetEfficiencyMetrics(ctx context.Context, federationID string) (*EfficiencyMetrics, error)
+	GetTimeSeries(ctx context.Context, federationID string, query *TimeSeriesQuery) ([]*TimeSeries, error)
 
 	// Dashboard management
 	CreateDashboard(ctx context.Context, dashboard *Dashboard) error
@@ -60,6 +74,16 @@ type MonitoringService interface {
 	UpdateDashboard(ctx context.Context, dashboardID string, dashboard *Dashboard) error
 	DeleteDashboard(ctx context.Context, dashboardID string) error
 
+	// API key management
+	APIKeyStore
+
+	// Notification subscriptions
+	NotificationStore
+
+	// Audit log
+	RecordAudit(ctx context.Context, entry *AuditEntry) error
+	GetAuditLog(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error)
+
 	// Real-time subscriptions
 	SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error)
 	UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error
@@ -80,11 +104,13 @@ type UpdateStatistics struct {
 }
 
 type AggregationStatistics struct {
-	TotalAggregations   int     `json:"total_aggregations"`
-	AverageTime         float64 `json:"average_time_ms"`
-	AverageParticipants float64 `json:"average_participants"`
-	ConvergenceRate     float64 `json:"convergence_rate"`
-	ModelQuality        float64 `json:"model_quality"`
+	TotalAggregations      int     `json:"total_aggregations"`
+	AverageTime            float64 `json:"average_time_ms"`
+	AverageParticipants    float64 `json:"average_participants"`
+	ConvergenceRate        float64 `json:"convergence_rate"`
+	ModelQuality           float64 `json:"model_quality"`
+	TotalClipped           int     `json:"total_clipped"`
+	AverageClippedFraction float64 `json:"average_clipped_fraction"`
 }
 
 type SystemOverview struct {
@@ -183,3 +209,40 @@ type EventSubscription struct {
 	Channel      chan *MonitoringEvent `json:"-"`
 	CreatedAt    time.Time             `json:"created_at"`
 }
+
+// TimeSeriesMetric identifies a metric that can be queried as a time series
+type TimeSeriesMetric string
+
+const (
+	SeriesAccuracy      TimeSeriesMetric = "accuracy"
+	SeriesLoss          TimeSeriesMetric = "loss"
+	SeriesRoundDuration TimeSeriesMetric = "round_duration"
+	SeriesUpdateLatency TimeSeriesMetric = "update_latency"
+	SeriesCPU           TimeSeriesMetric = "cpu"
+	SeriesMemory        TimeSeriesMetric = "memory"
+)
+
+// TimeSeriesQuery describes a downsampled time-series query
+type TimeSeriesQuery struct {
+	Metrics   []TimeSeriesMetric `json:"metrics"`
+	StartTime time.Time          `json:"start_time"`
+	EndTime   time.Time          `json:"end_time"`
+	Interval  time.Duration      `json:"interval"`
+	Source    string             `json:"source,omitempty"` // resource source filter for cpu/memory
+}
+
+// TimeSeriesBucket holds the aggregated values of one downsampling bucket
+type TimeSeriesBucket struct {
+	Timestamp time.Time `json:"timestamp"`
+	Avg       float64   `json:"avg"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Count     int       `json:"count"`
+}
+
+// TimeSeries is a single downsampled metric series
+type TimeSeries struct {
+	Metric   TimeSeriesMetric   `json:"metric"`
+	Interval int64              `json:"interval_ms"`
+	Buckets  []TimeSeriesBucket `json:"buckets"`
+}
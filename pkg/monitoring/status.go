@@ -0,0 +1,140 @@
+package monitoring
+
+import (
+	"context"
+	"html/template"
+	"math"
+	"sort"
+	"time"
+)
+
+// PublicStatusConfig controls the unauthenticated public status page
+type PublicStatusConfig struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	Title       string   `yaml:"title,omitempty" json:"title,omitempty"`
+	Federations []string `yaml:"federations,omitempty" json:"federations,omitempty"` // IDs to publish; empty publishes all running federations
+}
+
+// PublicFederationStatus is the restricted view of a federation shown on the
+// public status page. It deliberately omits IDs, addresses and collaborator
+// identities.
+type PublicFederationStatus struct {
+	Name            string           `json:"name"`
+	Status          FederationStatus `json:"status"`
+	CurrentRound    int              `json:"current_round"`
+	TotalRounds     int              `json:"total_rounds,omitempty"`
+	ProgressPercent *float64         `json:"progress_percent,omitempty"` // unset for async federations
+	Participants    int              `json:"participants"`
+	LastUpdate      time.Time        `json:"last_update"`
+}
+
+// PublicStatus is the payload of the public status endpoint
+type PublicStatus struct {
+	Title       string                    `json:"title"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Federations []*PublicFederationStatus `json:"federations"`
+}
+
+// BuildPublicStatus collects the public view of the configured federations
+func BuildPublicStatus(ctx context.Context, service MonitoringService, config PublicStatusConfig) (*PublicStatus, error) {
+	var federations []*FederationMetrics
+	if len(config.Federations) == 0 {
+		active, err := service.GetActiveFederations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		federations = active
+	} else {
+		for _, id := range config.Federations {
+			federation, err := service.GetFederation(ctx, id)
+			if err != nil {
+				// Unknown federations are skipped rather than revealing which IDs exist
+				continue
+			}
+			federations = append(federations, federation)
+		}
+	}
+
+	title := config.Title
+	if title == "" {
+		title = "Federated Learning Status"
+	}
+
+	status := &PublicStatus{
+		Title:       title,
+		GeneratedAt: time.Now(),
+		Federations: make([]*PublicFederationStatus, 0, len(federations)),
+	}
+
+	for _, federation := range federations {
+		status.Federations = append(status.Federations, toPublicFederationStatus(federation))
+	}
+
+	sort.Slice(status.Federations, func(i, j int) bool {
+		return status.Federations[i].Name < status.Federations[j].Name
+	})
+
+	return status, nil
+}
+
+func toPublicFederationStatus(federation *FederationMetrics) *PublicFederationStatus {
+	name := federation.Name
+	if name == "" {
+		name = "Unnamed federation"
+	}
+
+	public := &PublicFederationStatus{
+		Name:         name,
+		Status:       federation.Status,
+		CurrentRound: federation.CurrentRound,
+		TotalRounds:  federation.TotalRounds,
+		Participants: federation.ActiveCollabs,
+		LastUpdate:   federation.LastUpdate,
+	}
+
+	if federation.TotalRounds > 0 {
+		progress := float64(federation.CurrentRound) / float64(federation.TotalRounds) * 100
+		if federation.Status == StatusCompleted {
+			progress = 100
+		}
+		progress = math.Round(math.Min(progress, 100)*10) / 10
+		public.ProgressPercent = &progress
+	}
+
+	return public
+}
+
+var publicStatusTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 720px; margin: 2em auto; color: #222; }
+.federation { border: 1px solid #ddd; border-radius: 6px; padding: 1em; margin-bottom: 1em; }
+.bar { background: #eee; border-radius: 4px; height: 12px; }
+.fill { background: #2d7ff9; border-radius: 4px; height: 12px; }
+.meta { color: #666; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Federations}}
+<div class="federation">
+  <h2>{{.Name}} <small class="meta">{{.Status}}</small></h2>
+  {{if .ProgressPercent}}
+  <div class="bar"><div class="fill" style="width: {{.ProgressPercent}}%"></div></div>
+  <p>Round {{.CurrentRound}} of {{.TotalRounds}} ({{.ProgressPercent}}%)</p>
+  {{else}}
+  <p>Round {{.CurrentRound}}</p>
+  {{end}}
+  <p class="meta">{{.Participants}} participants &middot; last update {{.LastUpdate.Format "2006-01-02 15:04 MST"}}</p>
+</div>
+{{else}}
+<p>No federations are currently running.</p>
+{{end}}
+<p class="meta">Generated {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublicStatusPage(t *testing.T) {
+	config := &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled:    true,
+			APIKeyAuth: APIKeyConfig{Enabled: true, Keys: map[string]string{"key": RoleAdmin}},
+		},
+		PublicStatus: PublicStatusConfig{Enabled: true, Title: "Consortium"},
+	}
+	storage := NewMemoryStorage(config)
+	storage.RegisterFederation(context.Background(), &FederationMetrics{
+		ID:                "fed-secret-id",
+		Name:              "Hospital study",
+		Status:            StatusRunning,
+		CurrentRound:      3,
+		TotalRounds:       12,
+		ActiveCollabs:     4,
+		AggregatorAddress: "10.0.0.1:50051",
+	})
+	server := NewAPIServer(storage, config)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		contains       string
+	}{
+		{"public JSON", "/api/v1/public/status", http.StatusOK, `"progress_percent":25`},
+		{"public page", "/status", http.StatusOK, "Round 3 of 12 (25%)"},
+		{"detailed API still protected", "/api/v1/federations", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rr.Code, tt.expectedStatus)
+			}
+
+			body := rr.Body.String()
+			if tt.contains != "" && !strings.Contains(body, tt.contains) {
+				t.Errorf("GET %s body missing %q:\n%s", tt.path, tt.contains, body)
+			}
+			if strings.Contains(body, "fed-secret-id") || strings.Contains(body, "10.0.0.1") {
+				t.Errorf("GET %s leaked federation details:\n%s", tt.path, body)
+			}
+		})
+	}
+}
+
+func TestPublicStatusDisabled(t *testing.T) {
+	config := &MonitoringConfig{}
+	server := NewAPIServer(NewMemoryStorage(config), config)
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/public/status", nil))
+
+	if rr.Code == http.StatusOK && strings.Contains(rr.Body.String(), "federations") {
+		t.Errorf("Public status should not be served when disabled")
+	}
+}
@@ -17,11 +17,18 @@ type MemoryStorage struct {
 	collaborators   map[string]*CollaboratorMetrics
 	rounds          map[string]*RoundMetrics
 	modelUpdates    []*ModelUpdateMetrics
+	droppedUpdates  map[string]*DroppedUpdateStats // key: federation/collaborator/reason
 	aggregations    []*AggregationMetrics
+	contributions   []*ContributionScore
+	ledger          *ledger                       // participation credits of each collaborator
 	resourceMetrics map[string][]*ResourceMetrics // key: source (aggregator/collaborator ID)
+	resourceSources map[string]*ResourceSource
 	events          []*MonitoringEvent
 	alerts          []*Alert
 	dashboards      map[string]*Dashboard
+	apiKeys         map[string]*APIKey // key: API key ID
+	notifications   map[string]*NotificationSubscription
+	auditLog        []*AuditEntry
 	subscriptions   map[string]*EventSubscription
 	config          *MonitoringConfig
 	startTime       time.Time
@@ -34,11 +41,18 @@ func NewMemoryStorage(config *MonitoringConfig) *MemoryStorage {
 		collaborators:   make(map[string]*CollaboratorMetrics),
 		rounds:          make(map[string]*RoundMetrics),
 		modelUpdates:    make([]*ModelUpdateMetrics, 0),
+		droppedUpdates:  make(map[string]*DroppedUpdateStats),
 		aggregations:    make([]*AggregationMetrics, 0),
+		contributions:   make([]*ContributionScore, 0),
+		ledger:          newLedger(config.Ledger),
 		resourceMetrics: make(map[string][]*ResourceMetrics),
+		resourceSources: make(map[string]*ResourceSource),
 		events:          make([]*MonitoringEvent, 0),
 		alerts:          make([]*Alert, 0),
 		dashboards:      make(map[string]*Dashboard),
+		apiKeys:         make(map[string]*APIKey),
+		notifications:   make(map[string]*NotificationSubscription),
+		auditLog:        make([]*AuditEntry, 0),
 		subscriptions:   make(map[string]*EventSubscription),
 		config:          config,
 		startTime:       time.Now(),
@@ -50,23 +64,14 @@ func (m *MemoryStorage) RegisterFederation(ctx context.Context, metrics *Federat
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Registering a federation again, e.g. when a client retries, updates it
+	_, exists := m.federations[metrics.ID]
 	m.federations[metrics.ID] = metrics
-
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.ID,
-		Type:         MetricTypeRound,
-		Timestamp:    time.Now(),
-		Source:       "aggregator",
-		Level:        "info",
-		Message:      fmt.Sprintf("Federation %s registered", metrics.Name),
-		Data: map[string]interface{}{
-			"mode":      metrics.Mode,
-			"algorithm": metrics.Algorithm,
-			"rounds":    metrics.TotalRounds,
-		},
+	if exists {
+		return nil
 	}
+
+	event := federationRegisteredEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
@@ -137,6 +142,83 @@ func (m *MemoryStorage) GetFederationHistory(ctx context.Context, filter *Metric
 	return m.paginateFederations(results, filter), nil
 }
 
+// ImportFederationHistory stores historical records without emitting the
+// events that live recording produces. Records that are already stored are skipped.
+func (m *MemoryStorage) ImportFederationHistory(ctx context.Context, archive *ImportArchive) (*ImportResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	federationID := archive.FederationID()
+	result := &ImportResult{FederationID: federationID}
+
+	if _, exists := m.federations[federationID]; !exists {
+		federation := archive.Federation
+		if federation == nil {
+			federation = archive.summaryFederation()
+		}
+		m.federations[federationID] = federation
+		result.FederationCreated = true
+	}
+
+	for _, collaborator := range archive.Collaborators {
+		if _, exists := m.collaborators[collaborator.ID]; exists {
+			result.Skipped++
+			continue
+		}
+		m.collaborators[collaborator.ID] = collaborator
+		result.Collaborators++
+	}
+
+	for _, round := range archive.Rounds {
+		if _, exists := m.rounds[round.ID]; exists {
+			result.Skipped++
+			continue
+		}
+		m.rounds[round.ID] = round
+		result.Rounds++
+	}
+
+	updateIDs := make(map[string]bool, len(m.modelUpdates))
+	for _, update := range m.modelUpdates {
+		updateIDs[update.ID] = true
+	}
+	for _, update := range archive.Updates {
+		if updateIDs[update.ID] {
+			result.Skipped++
+			continue
+		}
+		updateIDs[update.ID] = true
+		m.modelUpdates = append(m.modelUpdates, update)
+		result.Updates++
+	}
+
+	eventIDs := make(map[string]bool, len(m.events))
+	for _, event := range m.events {
+		eventIDs[event.ID] = true
+	}
+	for _, event := range archive.Events {
+		if eventIDs[event.ID] {
+			result.Skipped++
+			continue
+		}
+		eventIDs[event.ID] = true
+		m.events = append(m.events, event)
+		result.Events++
+	}
+
+	event := historyImportedEvent(result)
+	m.events = append(m.events, event)
+	m.notifySubscribers(event)
+
+	// Keep only recent events to prevent memory overflow
+	maxEvents := 10000
+	if len(m.events) > maxEvents {
+		m.events = m.events[len(m.events)-maxEvents:]
+	}
+
+	return result, nil
+}
+
 // Collaborator metrics implementation
 func (m *MemoryStorage) RegisterCollaborator(ctx context.Context, metrics *CollaboratorMetrics) error {
 	m.mu.Lock()
@@ -144,20 +226,7 @@ func (m *MemoryStorage) RegisterCollaborator(ctx context.Context, metrics *Colla
 
 	m.collaborators[metrics.ID] = metrics
 
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.FederationID,
-		Type:         MetricTypeCollaborator,
-		Timestamp:    time.Now(),
-		Source:       metrics.ID,
-		Level:        "info",
-		Message:      fmt.Sprintf("Collaborator %s joined federation", metrics.ID),
-		Data: map[string]interface{}{
-			"address": metrics.Address,
-			"status":  metrics.Status,
-		},
-	}
+	event := collaboratorJoinedEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
@@ -235,23 +304,18 @@ func (m *MemoryStorage) RecordRoundStart(ctx context.Context, metrics *RoundMetr
 		metrics.ID = uuid.New().String()
 	}
 
+	// A round started again with the same ID, e.g. when a client retries, is
+	// updated instead of recorded twice
+	existing, exists := m.rounds[metrics.ID]
+	if exists && existing.FederationID != metrics.FederationID {
+		return fmt.Errorf("round %s belongs to another federation", metrics.ID)
+	}
 	m.rounds[metrics.ID] = metrics
-
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.FederationID,
-		Type:         MetricTypeRound,
-		Timestamp:    time.Now(),
-		Source:       "aggregator",
-		Level:        "info",
-		Message:      fmt.Sprintf("Round %d started", metrics.RoundNumber),
-		Data: map[string]interface{}{
-			"round_id":     metrics.ID,
-			"algorithm":    metrics.Algorithm,
-			"participants": metrics.ParticipantCount,
-		},
+	if exists {
+		return nil
 	}
+
+	event := roundStartedEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
@@ -262,29 +326,19 @@ func (m *MemoryStorage) RecordRoundEnd(ctx context.Context, roundID string, metr
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.rounds[roundID]; !exists {
+	existing, exists := m.rounds[roundID]
+	if !exists {
 		return fmt.Errorf("round %s not found", roundID)
 	}
 
 	metrics.ID = roundID
 	m.rounds[roundID] = metrics
-
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.FederationID,
-		Type:         MetricTypeRound,
-		Timestamp:    time.Now(),
-		Source:       "aggregator",
-		Level:        "info",
-		Message:      fmt.Sprintf("Round %d completed", metrics.RoundNumber),
-		Data: map[string]interface{}{
-			"round_id":     metrics.ID,
-			"duration_ms":  metrics.Duration.Milliseconds(),
-			"participants": metrics.ParticipantCount,
-			"updates":      metrics.UpdatesReceived,
-		},
+	if existing.EndTime != nil {
+		// Already completed; the round is updated but not announced again
+		return nil
 	}
+
+	event := roundCompletedEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
@@ -354,22 +408,10 @@ func (m *MemoryStorage) RecordModelUpdate(ctx context.Context, metrics *ModelUpd
 	}
 
 	m.modelUpdates = append(m.modelUpdates, metrics)
+	m.ledger.recordUpdate(metrics)
+	m.ledger.persist()
 
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.FederationID,
-		Type:         MetricTypeModelUpdate,
-		Timestamp:    time.Now(),
-		Source:       metrics.CollaboratorID,
-		Level:        "info",
-		Message:      fmt.Sprintf("Model update received from %s", metrics.CollaboratorID),
-		Data: map[string]interface{}{
-			"round":         metrics.RoundNumber,
-			"size_bytes":    metrics.UpdateSize,
-			"processing_ms": metrics.ProcessingTime,
-		},
-	}
+	event := updateReceivedEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
@@ -440,6 +482,69 @@ func (m *MemoryStorage) GetUpdateStatistics(ctx context.Context, federationID st
 	return stats, nil
 }
 
+func (m *MemoryStorage) RecordDroppedUpdate(ctx context.Context, dropped *DroppedUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dropped.Timestamp.IsZero() {
+		dropped.Timestamp = time.Now()
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", dropped.FederationID, dropped.CollaboratorID, dropped.Reason)
+	stats, exists := m.droppedUpdates[key]
+	if !exists {
+		stats = &DroppedUpdateStats{
+			FederationID:   dropped.FederationID,
+			CollaboratorID: dropped.CollaboratorID,
+			Reason:         dropped.Reason,
+		}
+		m.droppedUpdates[key] = stats
+	}
+	stats.Count++
+	stats.LastDetail = dropped.Detail
+	if dropped.Timestamp.After(stats.LastDropped) {
+		stats.LastDropped = dropped.Timestamp
+	}
+
+	event := updateDroppedEvent(dropped)
+	m.events = append(m.events, event)
+	m.notifySubscribers(event)
+
+	return nil
+}
+
+func (m *MemoryStorage) GetDroppedUpdateStats(ctx context.Context, filter *MetricsFilter) ([]*DroppedUpdateStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]*DroppedUpdateStats, 0)
+	for _, stats := range m.droppedUpdates {
+		if filter != nil {
+			if !filter.matchesFederation(stats.FederationID) {
+				continue
+			}
+			if filter.CollaboratorID != "" && stats.CollaboratorID != filter.CollaboratorID {
+				continue
+			}
+			if filter.Status != "" && string(stats.Reason) != filter.Status {
+				continue
+			}
+		}
+		result := *stats
+		results = append(results, &result)
+	}
+
+	// Sort by count (most dropped first)
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count == results[j].Count {
+			return results[i].LastDropped.After(results[j].LastDropped)
+		}
+		return results[i].Count > results[j].Count
+	})
+
+	return results, nil
+}
+
 // Aggregation metrics implementation
 func (m *MemoryStorage) RecordAggregation(ctx context.Context, metrics *AggregationMetrics) error {
 	m.mu.Lock()
@@ -451,27 +556,48 @@ func (m *MemoryStorage) RecordAggregation(ctx context.Context, metrics *Aggregat
 
 	m.aggregations = append(m.aggregations, metrics)
 
-	// Record event
-	event := &MonitoringEvent{
-		ID:           uuid.New().String(),
-		FederationID: metrics.FederationID,
-		Type:         MetricTypeAggregation,
-		Timestamp:    time.Now(),
-		Source:       "aggregator",
-		Level:        "info",
-		Message:      fmt.Sprintf("Aggregation completed for round %d", metrics.RoundNumber),
-		Data: map[string]interface{}{
-			"algorithm":   metrics.Algorithm,
-			"duration_ms": metrics.Duration.Milliseconds(),
-			"updates":     metrics.UpdatesAggregated,
-		},
-	}
+	event := aggregationRecordedEvent(metrics)
 	m.events = append(m.events, event)
 	m.notifySubscribers(event)
 
 	return nil
 }
 
+func (m *MemoryStorage) GetAggregation(ctx context.Context, aggregationID string) (*AggregationMetrics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, aggregation := range m.aggregations {
+		if aggregation.ID == aggregationID {
+			result := *aggregation
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("aggregation %s not found", aggregationID)
+}
+
+// UpdateAggregation replaces a recorded aggregation, typically with its
+// completion data once the aggregation finished
+func (m *MemoryStorage) UpdateAggregation(ctx context.Context, aggregationID string, metrics *AggregationMetrics) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.aggregations {
+		if existing.ID != aggregationID {
+			continue
+		}
+		if metrics.FederationID == "" {
+			metrics.FederationID = existing.FederationID
+		} else if metrics.FederationID != existing.FederationID {
+			return fmt.Errorf("aggregation %s belongs to another federation", aggregationID)
+		}
+		metrics.ID = aggregationID
+		m.aggregations[i] = metrics
+		return nil
+	}
+	return fmt.Errorf("aggregation %s not found", aggregationID)
+}
+
 func (m *MemoryStorage) GetAggregations(ctx context.Context, filter *MetricsFilter) ([]*AggregationMetrics, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -512,11 +638,13 @@ func (m *MemoryStorage) GetAggregationStatistics(ctx context.Context, federation
 		TotalAggregations: len(aggregations),
 	}
 
-	var totalTime, totalParticipants, totalConvergence, totalQuality float64
+	var totalTime, totalParticipants, totalConvergence, totalQuality, totalClippedFraction float64
 
 	for _, agg := range aggregations {
 		totalTime += float64(agg.Duration.Milliseconds())
 		totalParticipants += float64(agg.UpdatesAggregated)
+		stats.TotalClipped += agg.UpdatesClipped
+		totalClippedFraction += agg.ClippedFraction
 		if agg.ModelConvergence != nil {
 			totalConvergence += *agg.ModelConvergence
 		}
@@ -529,6 +657,7 @@ func (m *MemoryStorage) GetAggregationStatistics(ctx context.Context, federation
 	stats.AverageParticipants = totalParticipants / float64(len(aggregations))
 	stats.ConvergenceRate = totalConvergence / float64(len(aggregations))
 	stats.ModelQuality = totalQuality / float64(len(aggregations))
+	stats.AverageClippedFraction = totalClippedFraction / float64(len(aggregations))
 
 	return stats, nil
 }
@@ -550,6 +679,13 @@ func (m *MemoryStorage) RecordResourceMetrics(ctx context.Context, source string
 		m.resourceMetrics[source] = m.resourceMetrics[source][len(m.resourceMetrics[source])-maxMetrics:]
 	}
 
+	// Collaborators report their own usage, which is shown with them
+	if collaborator, exists := m.collaborators[source]; exists {
+		latest := *metrics
+		collaborator.ResourceMetrics = &latest
+		collaborator.LastSeen = metrics.Timestamp
+	}
+
 	return nil
 }
 
@@ -575,6 +711,37 @@ func (m *MemoryStorage) GetResourceMetrics(ctx context.Context, source string, t
 	return results, nil
 }
 
+// RegisterResourceSource records the owner of a source unless it has one
+// already, and returns the recorded owner
+func (m *MemoryStorage) RegisterResourceSource(ctx context.Context, source *ResourceSource) (*ResourceSource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.resourceSources[source.ID]
+	if !exists {
+		stored = &ResourceSource{}
+		*stored = *source
+		stored.CreatedAt = time.Now()
+		m.resourceSources[source.ID] = stored
+	}
+
+	result := *stored
+	return &result, nil
+}
+
+func (m *MemoryStorage) GetResourceSource(ctx context.Context, sourceID string) (*ResourceSource, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source, exists := m.resourceSources[sourceID]
+	if !exists {
+		return nil, fmt.Errorf("resource source %s %w", sourceID, errUnknownResourceSource)
+	}
+
+	result := *source
+	return &result, nil
+}
+
 func (m *MemoryStorage) GetSystemOverview(ctx context.Context, federationID string) (*SystemOverview, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -643,6 +810,20 @@ func (m *MemoryStorage) RecordEvent(ctx context.Context, event *MonitoringEvent)
 
 	if event.ID == "" {
 		event.ID = uuid.New().String()
+	} else {
+		// A retried event replaces the one recorded with the same ID
+		for i := len(m.events) - 1; i >= 0; i-- {
+			if m.events[i].ID == event.ID {
+				if m.events[i].FederationID != event.FederationID {
+					return fmt.Errorf("event %s belongs to another federation", event.ID)
+				}
+				m.events[i] = event
+				return nil
+			}
+		}
+	}
+	if event.Type == MetricTypeClustering {
+		m.applyClusters(event)
 	}
 
 	m.events = append(m.events, event)
@@ -657,6 +838,17 @@ func (m *MemoryStorage) RecordEvent(ctx context.Context, event *MonitoringEvent)
 	return nil
 }
 
+// applyClusters sets the cluster of each collaborator listed in a clustering
+// event. The caller holds m.mu.
+func (m *MemoryStorage) applyClusters(event *MonitoringEvent) {
+	for id, c := range eventClusters(event) {
+		if collaborator, exists := m.collaborators[id]; exists && collaborator.FederationID == event.FederationID {
+			cluster := c
+			collaborator.Cluster = &cluster
+		}
+	}
+}
+
 func (m *MemoryStorage) GetEvents(ctx context.Context, filter *MetricsFilter) ([]*MonitoringEvent, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -694,6 +886,18 @@ func (m *MemoryStorage) GetActiveAlerts(ctx context.Context, federationID string
 
 // Placeholder implementations for advanced analytics
 func (m *MemoryStorage) GetPerformanceInsights(ctx context.Context, federationID string) (*PerformanceInsights, error) {
+	return placeholderPerformanceInsights(federationID), nil
+}
+
+func (m *MemoryStorage) GetConvergenceAnalysis(ctx context.Context, federationID string) (*ConvergenceAnalysis, error) {
+	return placeholderConvergenceAnalysis(federationID), nil
+}
+
+func (m *MemoryStorage) GetEfficiencyMetrics(ctx context.Context, federationID string) (*EfficiencyMetrics, error) {
+	return placeholderEfficiencyMetrics(federationID), nil
+}
+
+func placeholderPerformanceInsights(federationID string) *PerformanceInsights {
 	// This would contain complex analysis logic
 	return &PerformanceInsights{
 		FederationID:            federationID,
@@ -703,27 +907,111 @@ func (m *MemoryStorage) GetPerformanceInsights(ctx context.Context, federationID
 		ResourceUtilization:     67.8,
 		BottleneckAnalysis:      []string{"Network latency between collaborators", "Heterogeneous compute capabilities"},
 		Recommendations:         []string{"Consider increasing batch size", "Implement adaptive learning rates"},
-	}, nil
+	}
 }
 
-func (m *MemoryStorage) GetConvergenceAnalysis(ctx context.Context, federationID string) (*ConvergenceAnalysis, error) {
+func placeholderConvergenceAnalysis(federationID string) *ConvergenceAnalysis {
 	// This would analyze model convergence trends
 	return &ConvergenceAnalysis{
 		FederationID:      federationID,
 		ConvergenceRate:   0.15,
 		ParticipationRate: 95.0,
 		QualityMetrics:    map[string]float64{"accuracy": 0.87, "f1_score": 0.82},
-	}, nil
+	}
 }
 
-func (m *MemoryStorage) GetEfficiencyMetrics(ctx context.Context, federationID string) (*EfficiencyMetrics, error) {
+func placeholderEfficiencyMetrics(federationID string) *EfficiencyMetrics {
 	// This would calculate various efficiency metrics
 	return &EfficiencyMetrics{
 		FederationID:            federationID,
 		ComputationalEfficiency: 78.5,
 		CommunicationEfficiency: 85.2,
 		ResourceOptimization:    72.1,
-	}, nil
+	}
+}
+
+func (m *MemoryStorage) GetTimeSeries(ctx context.Context, federationID string, query *TimeSeriesQuery) ([]*TimeSeries, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.federations[federationID]; !exists {
+		return nil, fmt.Errorf("federation %s not found", federationID)
+	}
+
+	series := make([]*TimeSeries, 0, len(query.Metrics))
+	for _, metric := range query.Metrics {
+		samples := m.collectSeriesSamples(federationID, metric, query.Source)
+		buckets := downsample(samples, query.StartTime, query.EndTime, query.Interval)
+
+		interval := query.Interval
+		if interval <= 0 {
+			interval = query.EndTime.Sub(query.StartTime) / defaultSeriesBuckets
+		}
+
+		series = append(series, &TimeSeries{
+			Metric:   metric,
+			Interval: interval.Milliseconds(),
+			Buckets:  buckets,
+		})
+	}
+
+	return series, nil
+}
+
+// collectSeriesSamples gathers raw samples for a metric. Callers must hold the read lock.
+func (m *MemoryStorage) collectSeriesSamples(federationID string, metric TimeSeriesMetric, source string) []seriesSample {
+	var samples []seriesSample
+
+	switch metric {
+	case SeriesAccuracy, SeriesLoss, SeriesRoundDuration:
+		for _, round := range m.rounds {
+			if round.FederationID != federationID {
+				continue
+			}
+
+			timestamp := round.StartTime
+			if round.EndTime != nil {
+				timestamp = *round.EndTime
+			}
+
+			switch {
+			case metric == SeriesAccuracy && round.ModelAccuracy != nil:
+				samples = append(samples, seriesSample{Timestamp: timestamp, Value: *round.ModelAccuracy})
+			case metric == SeriesLoss && round.ModelLoss != nil:
+				samples = append(samples, seriesSample{Timestamp: timestamp, Value: *round.ModelLoss})
+			case metric == SeriesRoundDuration && round.EndTime != nil:
+				samples = append(samples, seriesSample{Timestamp: timestamp, Value: float64(round.Duration.Milliseconds())})
+			}
+		}
+	case SeriesUpdateLatency:
+		for _, update := range m.modelUpdates {
+			if update.FederationID == federationID {
+				samples = append(samples, seriesSample{Timestamp: update.Timestamp, Value: update.ProcessingTime})
+			}
+		}
+	case SeriesCPU, SeriesMemory:
+		sources := []string{source}
+		if source == "" {
+			sources = []string{"aggregator"}
+			for _, collab := range m.collaborators {
+				if collab.FederationID == federationID {
+					sources = append(sources, collab.ID)
+				}
+			}
+		}
+
+		for _, src := range sources {
+			for _, resource := range m.resourceMetrics[src] {
+				value := resource.CPUUsage
+				if metric == SeriesMemory {
+					value = resource.MemoryUsage
+				}
+				samples = append(samples, seriesSample{Timestamp: resource.Timestamp, Value: value})
+			}
+		}
+	}
+
+	return samples
 }
 
 // Dashboard management
@@ -799,6 +1087,214 @@ func (m *MemoryStorage) DeleteDashboard(ctx context.Context, dashboardID string)
 	return nil
 }
 
+// API key management
+func (m *MemoryStorage) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	if _, exists := m.apiKeys[key.ID]; exists {
+		return fmt.Errorf("API key %s already exists", key.ID)
+	}
+
+	result := *key
+	m.apiKeys[key.ID] = &result
+	return nil
+}
+
+func (m *MemoryStorage) GetAPIKey(ctx context.Context, keyID string) (*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, exists := m.apiKeys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("API key %s not found", keyID)
+	}
+
+	result := *key
+	return &result, nil
+}
+
+func (m *MemoryStorage) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.apiKeys {
+		if CompareAPIKeys(keyHash, key.KeyHash) {
+			result := *key
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("API key not found")
+}
+
+func (m *MemoryStorage) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]*APIKey, 0, len(m.apiKeys))
+	for _, key := range m.apiKeys {
+		result := *key
+		keys = append(keys, &result)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (m *MemoryStorage) UpdateAPIKey(ctx context.Context, keyID string, key *APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.apiKeys[keyID]; !exists {
+		return fmt.Errorf("API key %s not found", keyID)
+	}
+
+	result := *key
+	result.ID = keyID
+	m.apiKeys[keyID] = &result
+	return nil
+}
+
+// Notification subscriptions
+func (m *MemoryStorage) CreateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	result := *subscription
+	m.notifications[subscription.ID] = &result
+	return nil
+}
+
+func (m *MemoryStorage) GetNotificationSubscription(ctx context.Context, subscriptionID string) (*NotificationSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscription, exists := m.notifications[subscriptionID]
+	if !exists {
+		return nil, fmt.Errorf("notification subscription %s not found", subscriptionID)
+	}
+
+	result := *subscription
+	return &result, nil
+}
+
+func (m *MemoryStorage) ListNotificationSubscriptions(ctx context.Context) ([]*NotificationSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscriptions := make([]*NotificationSubscription, 0, len(m.notifications))
+	for _, subscription := range m.notifications {
+		result := *subscription
+		subscriptions = append(subscriptions, &result)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+func (m *MemoryStorage) UpdateNotificationSubscription(ctx context.Context, subscriptionID string, subscription *NotificationSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.notifications[subscriptionID]
+	if !exists {
+		return fmt.Errorf("notification subscription %s not found", subscriptionID)
+	}
+
+	subscription.ID = subscriptionID
+	subscription.CreatedAt = existing.CreatedAt
+	subscription.UpdatedAt = time.Now()
+
+	result := *subscription
+	m.notifications[subscriptionID] = &result
+	return nil
+}
+
+func (m *MemoryStorage) DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.notifications[subscriptionID]; !exists {
+		return fmt.Errorf("notification subscription %s not found", subscriptionID)
+	}
+
+	delete(m.notifications, subscriptionID)
+	return nil
+}
+
+// Audit log
+func (m *MemoryStorage) RecordAudit(ctx context.Context, entry *AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	result := *entry
+	m.auditLog = append(m.auditLog, &result)
+
+	if len(m.auditLog) > maxAuditEntries {
+		m.auditLog = m.auditLog[len(m.auditLog)-maxAuditEntries:]
+	}
+
+	return nil
+}
+
+func (m *MemoryStorage) GetAuditLog(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Collect in reverse so entries with equal timestamps stay newest first
+	results := make([]*AuditEntry, 0)
+	for i := len(m.auditLog) - 1; i >= 0; i-- {
+		if filter.Matches(m.auditLog[i]) {
+			result := *m.auditLog[i]
+			results = append(results, &result)
+		}
+	}
+
+	// Sort by timestamp (newest first)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+
+	if filter == nil || filter.Page <= 0 {
+		return results, nil
+	}
+
+	perPage := filter.PerPage
+	if perPage <= 0 {
+		perPage = 100
+	}
+	start := (filter.Page - 1) * perPage
+	if start >= len(results) {
+		return []*AuditEntry{}, nil
+	}
+	end := start + perPage
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[start:end], nil
+}
+
 // Real-time subscriptions
 func (m *MemoryStorage) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
 	m.mu.Lock()
@@ -875,7 +1371,7 @@ func (m *MemoryStorage) matchesFederationFilter(federation *FederationMetrics, f
 		return true
 	}
 
-	if filter.FederationID != "" && federation.ID != filter.FederationID {
+	if !filter.matchesFederation(federation.ID) {
 		return false
 	}
 
@@ -883,6 +1379,10 @@ func (m *MemoryStorage) matchesFederationFilter(federation *FederationMetrics, f
 		return false
 	}
 
+	if !hasLabels(federation.Labels, filter.Labels) {
+		return false
+	}
+
 	if filter.StartTime != nil && federation.StartTime.Before(*filter.StartTime) {
 		return false
 	}
@@ -899,7 +1399,7 @@ func (m *MemoryStorage) matchesCollaboratorFilter(collaborator *CollaboratorMetr
 		return true
 	}
 
-	if filter.FederationID != "" && collaborator.FederationID != filter.FederationID {
+	if !filter.matchesFederation(collaborator.FederationID) {
 		return false
 	}
 
@@ -911,6 +1411,10 @@ func (m *MemoryStorage) matchesCollaboratorFilter(collaborator *CollaboratorMetr
 		return false
 	}
 
+	if !collaborator.HasTags(filter.Tags) {
+		return false
+	}
+
 	if filter.StartTime != nil && collaborator.JoinTime.Before(*filter.StartTime) {
 		return false
 	}
@@ -927,7 +1431,7 @@ func (m *MemoryStorage) matchesRoundFilter(round *RoundMetrics, filter *MetricsF
 		return true
 	}
 
-	if filter.FederationID != "" && round.FederationID != filter.FederationID {
+	if !filter.matchesFederation(round.FederationID) {
 		return false
 	}
 
@@ -939,6 +1443,10 @@ func (m *MemoryStorage) matchesRoundFilter(round *RoundMetrics, filter *MetricsF
 		return false
 	}
 
+	if !hasLabels(round.Labels, filter.Labels) {
+		return false
+	}
+
 	if filter.StartTime != nil && round.StartTime.Before(*filter.StartTime) {
 		return false
 	}
@@ -955,7 +1463,7 @@ func (m *MemoryStorage) matchesUpdateFilter(update *ModelUpdateMetrics, filter *
 		return true
 	}
 
-	if filter.FederationID != "" && update.FederationID != filter.FederationID {
+	if !filter.matchesFederation(update.FederationID) {
 		return false
 	}
 
@@ -983,7 +1491,7 @@ func (m *MemoryStorage) matchesAggregationFilter(aggregation *AggregationMetrics
 		return true
 	}
 
-	if filter.FederationID != "" && aggregation.FederationID != filter.FederationID {
+	if !filter.matchesFederation(aggregation.FederationID) {
 		return false
 	}
 
@@ -1007,7 +1515,7 @@ func (m *MemoryStorage) matchesEventFilter(event *MonitoringEvent, filter *Metri
 		return true
 	}
 
-	if filter.FederationID != "" && event.FederationID != filter.FederationID {
+	if !filter.matchesFederation(event.FederationID) {
 		return false
 	}
 
@@ -1180,25 +1688,10 @@ func (m *MemoryStorage) paginateEvents(results []*MonitoringEvent, filter *Metri
 // notifySubscribers sends events to all relevant subscribers
 func (m *MemoryStorage) notifySubscribers(event *MonitoringEvent) {
 	for _, subscription := range m.subscriptions {
-		// Check if subscription matches the event
-		if subscription.FederationID != "" && subscription.FederationID != event.FederationID {
+		if !subscription.matches(event) {
 			continue
 		}
 
-		// Check if event type matches subscription
-		if len(subscription.EventTypes) > 0 {
-			matches := false
-			for _, eventType := range subscription.EventTypes {
-				if eventType == event.Type {
-					matches = true
-					break
-				}
-			}
-			if !matches {
-				continue
-			}
-		}
-
 		// Send event to subscriber (non-blocking)
 		select {
 		case subscription.Channel <- event:
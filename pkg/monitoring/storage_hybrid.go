@@ -0,0 +1,327 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// archiveAttempts is how many times a write is tried against the archive
+// tier before it is given up
+const archiveAttempts = 5
+
+// archiveRetryBackoff is the wait before the first retry of an archive write;
+// it doubles with every retry
+const archiveRetryBackoff = 500 * time.Millisecond
+
+// HybridStorage implements Storage interface with two tiers: a hot tier,
+// usually Redis, that holds recent metrics for fast dashboards, and an
+// archive tier, usually PostgreSQL, that keeps the full history. Writes go
+// to the hot tier and are copied to the archive in the background; reads
+// merge both tiers, preferring the hot copy of a record.
+type HybridStorage struct {
+	hot     Storage
+	archive Storage
+	config  HybridConfig
+	backoff time.Duration // wait before the first retry of an archive write
+
+	queue   chan func(Storage) error
+	done    chan struct{}
+	closing chan struct{} // closed by Close to stop waiting between retries
+	closeMu sync.Mutex
+	closed  bool
+
+	healthMu   sync.Mutex
+	archiveErr error // error of the last archive write, nil once one succeeds
+	lost       int   // writes given up on since the storage was created
+}
+
+// HybridConfig represents configuration for tiered storage
+type HybridConfig struct {
+	HotRetention string `yaml:"hot_retention"` // Age after which Cleanup drops data from the hot tier
+	ArchiveQueue int    `yaml:"archive_queue"` // Writes waiting to be archived before writers block
+}
+
+// NewHybridStorage creates a tiered storage backend. It takes ownership of
+// both tiers and closes them on Close.
+func NewHybridStorage(hot, archive Storage, config HybridConfig) (*HybridStorage, error) {
+	if config.HotRetention != "" {
+		if _, err := time.ParseDuration(config.HotRetention); err != nil {
+			return nil, fmt.Errorf("invalid hot_retention: %w", err)
+		}
+	}
+	queueSize := config.ArchiveQueue
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	h := &HybridStorage{
+		hot:     hot,
+		archive: archive,
+		config:  config,
+		backoff: archiveRetryBackoff,
+		queue:   make(chan func(Storage) error, queueSize),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	go h.archiveWrites()
+	return h, nil
+}
+
+// hotRetention returns how long data stays in the hot tier
+func (h *HybridStorage) hotRetention() time.Duration {
+	if retention, err := time.ParseDuration(h.config.HotRetention); err == nil {
+		return retention
+	}
+	return 24 * time.Hour // Default hot retention
+}
+
+// archiveWrites copies queued writes to the archive tier until Close. A
+// failed write is retried with exponential backoff, which holds back the
+// writes queued after it, so that a short archive outage loses nothing.
+func (h *HybridStorage) archiveWrites() {
+	defer close(h.done)
+	for write := range h.queue {
+		err := h.archiveWrite(write)
+		if err != nil {
+			log.Printf("Warning: failed to archive metrics after %d attempts: %v", archiveAttempts, err)
+		}
+
+		h.healthMu.Lock()
+		h.archiveErr = err
+		if err != nil {
+			h.lost++
+		}
+		h.healthMu.Unlock()
+	}
+}
+
+// archiveWrite applies a write to the archive tier, retrying it until it
+// succeeds, archiveAttempts are used up or Close is called
+func (h *HybridStorage) archiveWrite(write func(Storage) error) error {
+	backoff := h.backoff
+	for attempt := 1; ; attempt++ {
+		err := write(h.archive)
+		if err == nil || attempt == archiveAttempts {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-h.closing:
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// Health returns an error while the archive tier is failing: when the last
+// write copied to it failed after all retries. The error counts the writes
+// that only reached the hot tier, which are lost once they age out of it.
+func (h *HybridStorage) Health() error {
+	h.healthMu.Lock()
+	defer h.healthMu.Unlock()
+	if h.archiveErr != nil {
+		return fmt.Errorf("archive tier is failing (%d writes not archived): %w", h.lost, h.archiveErr)
+	}
+	return nil
+}
+
+// store writes to the hot tier and queues the write for the archive tier
+func (h *HybridStorage) store(write func(Storage) error) error {
+	if err := write(h.hot); err != nil {
+		return err
+	}
+
+	h.closeMu.Lock()
+	defer h.closeMu.Unlock()
+	if h.closed {
+		return fmt.Errorf("storage is closed")
+	}
+	h.queue <- write
+	return nil
+}
+
+// StoreFederationMetrics stores federation metrics in both tiers
+func (h *HybridStorage) StoreFederationMetrics(federation FederationMetrics) error {
+	return h.store(func(s Storage) error { return s.StoreFederationMetrics(federation) })
+}
+
+// GetFederationMetrics retrieves federation metrics, falling back to the
+// archive for federations no longer in the hot tier
+func (h *HybridStorage) GetFederationMetrics(id string) (*FederationMetrics, error) {
+	if federation, err := h.hot.GetFederationMetrics(id); err == nil && federation != nil {
+		return federation, nil
+	}
+	return h.archive.GetFederationMetrics(id)
+}
+
+// ListFederations lists the federations of both tiers
+func (h *HybridStorage) ListFederations(activeOnly bool) ([]FederationMetrics, error) {
+	hot, err := h.hot.ListFederations(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := h.archive.ListFederations(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	federations := mergeTiers(hot, archived, func(f FederationMetrics) string { return f.ID })
+	sort.Slice(federations, func(i, j int) bool {
+		return federations[i].StartTime.After(federations[j].StartTime)
+	})
+	return federations, nil
+}
+
+// StoreCollaboratorMetrics stores collaborator metrics in both tiers
+func (h *HybridStorage) StoreCollaboratorMetrics(collaborator CollaboratorMetrics) error {
+	return h.store(func(s Storage) error { return s.StoreCollaboratorMetrics(collaborator) })
+}
+
+// GetCollaboratorMetrics retrieves the collaborators of a federation from
+// both tiers
+func (h *HybridStorage) GetCollaboratorMetrics(federationID string) ([]CollaboratorMetrics, error) {
+	hot, err := h.hot.GetCollaboratorMetrics(federationID)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := h.archive.GetCollaboratorMetrics(federationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeTiers(hot, archived, func(c CollaboratorMetrics) string { return c.ID }), nil
+}
+
+// StoreRoundMetrics stores round metrics in both tiers
+func (h *HybridStorage) StoreRoundMetrics(round RoundMetrics) error {
+	return h.store(func(s Storage) error { return s.StoreRoundMetrics(round) })
+}
+
+// GetRoundMetrics retrieves the latest rounds of a federation from both
+// tiers, highest round numbers first
+func (h *HybridStorage) GetRoundMetrics(federationID string, limit int) ([]RoundMetrics, error) {
+	hot, err := h.hot.GetRoundMetrics(federationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := h.archive.GetRoundMetrics(federationID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := mergeTiers(hot, archived, func(r RoundMetrics) string { return r.ID })
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i].RoundNumber > rounds[j].RoundNumber
+	})
+	if limit > 0 && len(rounds) > limit {
+		rounds = rounds[:limit]
+	}
+	return rounds, nil
+}
+
+// StoreResourceMetrics stores resource metrics in both tiers
+func (h *HybridStorage) StoreResourceMetrics(metrics ResourceMetrics) error {
+	return h.store(func(s Storage) error { return s.StoreResourceMetrics(metrics) })
+}
+
+// StoreEvent stores a monitoring event in both tiers. Events without an ID
+// are given one so that the tiers can be merged without duplicates.
+func (h *HybridStorage) StoreEvent(event MonitoringEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	return h.store(func(s Storage) error { return s.StoreEvent(event) })
+}
+
+// ListEvents retrieves monitoring events from both tiers, newest first
+func (h *HybridStorage) ListEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
+	// Either tier may hold any page of the merged list, so read both from
+	// the start
+	count := limit
+	if limit > 0 {
+		count += offset
+	}
+	hot, err := h.hot.ListEvents(federationID, count, 0)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := h.archive.ListEvents(federationID, count, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	events := mergeTiers(hot, archived, func(e MonitoringEvent) string { return e.ID })
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.After(events[j].Timestamp)
+	})
+
+	// Apply offset and limit
+	if offset > len(events) {
+		return []MonitoringEvent{}, nil
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(events) {
+		end = len(events)
+	}
+	return events[offset:end], nil
+}
+
+// mergeTiers combines the records of both tiers, dropping archived records
+// that are also in the hot tier
+func mergeTiers[T any](hot, archived []T, id func(T) string) []T {
+	seen := make(map[string]bool, len(hot))
+	merged := make([]T, 0, len(hot)+len(archived))
+	for _, record := range hot {
+		seen[id(record)] = true
+		merged = append(merged, record)
+	}
+	for _, record := range archived {
+		if !seen[id(record)] {
+			merged = append(merged, record)
+		}
+	}
+	return merged
+}
+
+// Cleanup removes data older than maxAge from the archive, and data older
+// than the hot retention from the hot tier
+func (h *HybridStorage) Cleanup(maxAge time.Duration) error {
+	hotAge := h.hotRetention()
+	if maxAge < hotAge {
+		hotAge = maxAge
+	}
+	if err := h.hot.Cleanup(hotAge); err != nil {
+		return fmt.Errorf("failed to clean up hot tier: %w", err)
+	}
+	if err := h.archive.Cleanup(maxAge); err != nil {
+		return fmt.Errorf("failed to clean up archive: %w", err)
+	}
+	return nil
+}
+
+// Close archives the pending writes, without retrying those that fail, and
+// closes both tiers
+func (h *HybridStorage) Close() error {
+	h.closeMu.Lock()
+	if h.closed {
+		h.closeMu.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.closing)
+	close(h.queue)
+	h.closeMu.Unlock()
+
+	<-h.done
+
+	hotErr := h.hot.Close()
+	if err := h.archive.Close(); err != nil {
+		return err
+	}
+	return hotErr
+}
@@ -0,0 +1,160 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHybridStorage(t *testing.T) {
+	hot := NewMemoryStorageBackend(MemoryConfig{MaxEntries: 1000})
+	archive := NewMemoryStorageBackend(MemoryConfig{MaxEntries: 1000})
+	storage, err := NewHybridStorage(hot, archive, HybridConfig{HotRetention: "1h"})
+	if err != nil {
+		t.Fatalf("NewHybridStorage: %v", err)
+	}
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		round := RoundMetrics{ID: fmt.Sprintf("round-%d", i), FederationID: "fed", RoundNumber: i}
+		if err := storage.StoreRoundMetrics(round); err != nil {
+			t.Fatalf("StoreRoundMetrics: %v", err)
+		}
+		event := MonitoringEvent{FederationID: "fed", Type: MetricTypeRound, Timestamp: now.Add(time.Duration(i) * time.Minute)}
+		if err := storage.StoreEvent(event); err != nil {
+			t.Fatalf("StoreEvent: %v", err)
+		}
+	}
+
+	// Wait for the writes to reach the archive, then age the first round
+	// and event out of the hot tier
+	deadline := time.Now().Add(time.Second)
+	for {
+		events, _ := archive.ListEvents("fed", 0, 0)
+		if len(events) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("archived %d events, want 3", len(events))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	hot.rounds["fed"] = hot.rounds["fed"][:2]
+	hot.events = hot.events[1:]
+
+	rounds, err := storage.GetRoundMetrics("fed", 0)
+	if err != nil {
+		t.Fatalf("GetRoundMetrics: %v", err)
+	}
+	if len(rounds) != 3 || rounds[0].RoundNumber != 3 || rounds[2].RoundNumber != 1 {
+		t.Errorf("got rounds %+v, want rounds 3, 2 and 1", rounds)
+	}
+
+	events, err := storage.ListEvents("fed", 2, 1)
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 2 || !events[0].Timestamp.Equal(now.Add(2*time.Minute)) || !events[1].Timestamp.Equal(now.Add(time.Minute)) {
+		t.Errorf("got events %+v, want the second and first events", events)
+	}
+
+	if err := storage.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := storage.StoreEvent(MonitoringEvent{FederationID: "fed"}); err == nil {
+		t.Error("StoreEvent succeeded after Close")
+	}
+}
+
+// flakyStorage fails the event writes of the storage it wraps while failures
+// are left
+type flakyStorage struct {
+	*MemoryStorageBackend
+	mu       sync.Mutex
+	failures int
+}
+
+func (f *flakyStorage) StoreEvent(event MonitoringEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("archive unavailable")
+	}
+	return f.MemoryStorageBackend.StoreEvent(event)
+}
+
+func TestHybridStorageArchiveRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		wantStored int
+		wantErr    bool
+	}{
+		{name: "recovers", failures: archiveAttempts - 1, wantStored: 1},
+		{name: "gives up", failures: archiveAttempts, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &flakyStorage{MemoryStorageBackend: NewMemoryStorageBackend(MemoryConfig{}), failures: tt.failures}
+			storage, err := NewHybridStorage(NewMemoryStorageBackend(MemoryConfig{}), archive, HybridConfig{})
+			if err != nil {
+				t.Fatalf("NewHybridStorage: %v", err)
+			}
+			storage.backoff = time.Millisecond
+
+			if err := storage.StoreEvent(MonitoringEvent{FederationID: "fed", Type: MetricTypeRound}); err != nil {
+				t.Fatalf("StoreEvent: %v", err)
+			}
+			// The retries finish before the next write is archived
+			if err := storage.StoreFederationMetrics(FederationMetrics{ID: "fed"}); err != nil {
+				t.Fatalf("StoreFederationMetrics: %v", err)
+			}
+			deadline := time.Now().Add(time.Second)
+			for {
+				if federation, _ := archive.GetFederationMetrics("fed"); federation != nil {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("the federation was not archived")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			events, _ := archive.ListEvents("fed", 0, 0)
+			if len(events) != tt.wantStored {
+				t.Errorf("archived %d events, want %d", len(events), tt.wantStored)
+			}
+			storage.healthMu.Lock()
+			lost := storage.lost
+			storage.healthMu.Unlock()
+			if (lost > 0) != tt.wantErr {
+				t.Errorf("lost %d writes, want lost writes %v", lost, tt.wantErr)
+			}
+			storage.Close()
+		})
+	}
+
+	// Health reports a failing archive until a write reaches it again
+	archive := &flakyStorage{MemoryStorageBackend: NewMemoryStorageBackend(MemoryConfig{}), failures: archiveAttempts}
+	storage, _ := NewHybridStorage(NewMemoryStorageBackend(MemoryConfig{}), archive, HybridConfig{})
+	storage.backoff = time.Millisecond
+	defer storage.Close()
+	storage.StoreEvent(MonitoringEvent{FederationID: "fed"})
+	waitForHealth := func(wantErr bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for (storage.Health() != nil) != wantErr {
+			if time.Now().After(deadline) {
+				t.Fatalf("Health() = %v, wantErr %v", storage.Health(), wantErr)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitForHealth(true)
+	storage.StoreEvent(MonitoringEvent{FederationID: "fed"})
+	waitForHealth(false)
+}
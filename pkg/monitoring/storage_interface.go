@@ -24,7 +24,7 @@ type Storage interface {
 
 	// Event operations
 	StoreEvent(event MonitoringEvent) error
-	GetEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error)
+	ListEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error)
 
 	// Cleanup operations
 	Cleanup(maxAge time.Duration) error
@@ -33,10 +33,11 @@ type Storage interface {
 
 // StorageConfig represents configuration for different storage backends
 type StorageConfig struct {
-	Backend    string         `yaml:"backend"` // memory, postgres, redis
+	Backend    string         `yaml:"backend"` // memory, postgres, timescaledb, redis, hybrid
 	Memory     MemoryConfig   `yaml:"memory"`
 	PostgreSQL DatabaseConfig `yaml:"postgresql"`
 	Redis      RedisConfig    `yaml:"redis"`
+	Hybrid     HybridConfig   `yaml:"hybrid"` // Redis hot tier archived to PostgreSQL
 }
 
 // MemoryConfig represents configuration for in-memory storage
@@ -51,10 +52,35 @@ func NewStorage(config StorageConfig) (Storage, error) {
 		return NewMemoryStorageBackend(config.Memory), nil
 	case "postgres", "postgresql":
 		return NewPostgreSQLStorage(config.PostgreSQL)
+	case "timescaledb":
+		config.PostgreSQL.TimescaleDB = true
+		return NewPostgreSQLStorage(config.PostgreSQL)
 	case "redis":
 		return NewRedisStorage(config.Redis)
+	case "hybrid":
+		return newHybridStorage(config)
 	default:
 		// Default to memory storage
 		return NewMemoryStorageBackend(config.Memory), nil
 	}
 }
+
+// newHybridStorage creates a Redis hot tier archived to PostgreSQL
+func newHybridStorage(config StorageConfig) (Storage, error) {
+	hot, err := NewRedisStorage(config.Redis)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := NewPostgreSQLStorage(config.PostgreSQL)
+	if err != nil {
+		hot.Close()
+		return nil, err
+	}
+	storage, err := NewHybridStorage(hot, archive, config.Hybrid)
+	if err != nil {
+		hot.Close()
+		archive.Close()
+		return nil, err
+	}
+	return storage, nil
+}
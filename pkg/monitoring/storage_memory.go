@@ -186,6 +186,16 @@ func (m *MemoryStorageBackend) StoreEvent(event MonitoringEvent) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// A retried event replaces the one stored with the same ID
+	if event.ID != "" {
+		for i := len(m.events) - 1; i >= 0; i-- {
+			if m.events[i].ID == event.ID {
+				m.events[i] = event
+				return nil
+			}
+		}
+	}
+
 	m.events = append(m.events, event)
 
 	// Cleanup old entries if we exceed max entries
@@ -198,8 +208,8 @@ func (m *MemoryStorageBackend) StoreEvent(event MonitoringEvent) error {
 	return nil
 }
 
-// GetEvents retrieves monitoring events from memory
-func (m *MemoryStorageBackend) GetEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
+// ListEvents retrieves monitoring events from memory
+func (m *MemoryStorageBackend) ListEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -1,22 +1,33 @@
 package monitoring
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-// PostgreSQLStorage implements Storage interface using PostgreSQL
+// PostgreSQLStorage implements the Storage interface and MonitoringService
+// using PostgreSQL
 type PostgreSQLStorage struct {
-	db     *sql.DB
-	config DatabaseConfig
+	db           *sql.DB
+	config       DatabaseConfig
+	ledgerConfig LedgerConfig
+	startTime    time.Time
+
+	mu            sync.Mutex
+	lastCleanup   time.Time
+	subscriptions map[string]*EventSubscription
+	listener      *eventListener // started by the first subscription
 }
 
 // DatabaseConfig represents database connection configuration
 type DatabaseConfig struct {
+	URL      string `yaml:"url"` // connection URL, used instead of the fields below when set
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
 	User     string `yaml:"user"`
@@ -24,14 +35,24 @@ type DatabaseConfig struct {
 	Database string `yaml:"database"`
 	SSLMode  string `yaml:"ssl_mode"`
 	MaxConns int    `yaml:"max_connections"`
+
+	// TimescaleDB stores model updates, resource metrics and events in
+	// hypertables with hourly rollups. It requires the timescaledb extension.
+	TimescaleDB bool `yaml:"timescaledb"`
+}
+
+// dsn returns the connection string of the database
+func (c DatabaseConfig) dsn() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Database, c.SSLMode)
 }
 
 // NewPostgreSQLStorage creates a new PostgreSQL storage backend
 func NewPostgreSQLStorage(config DatabaseConfig) (*PostgreSQLStorage, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.Database, config.SSLMode)
-
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", config.dsn())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -48,18 +69,30 @@ func NewPostgreSQLStorage(config DatabaseConfig) (*PostgreSQLStorage, error) {
 	db.SetConnMaxLifetime(time.Hour)
 
 	storage := &PostgreSQLStorage{
-		db:     db,
-		config: config,
+		db:            db,
+		config:        config,
+		startTime:     time.Now(),
+		subscriptions: make(map[string]*EventSubscription),
 	}
 
 	// Initialize database schema
 	if err := storage.initSchema(); err != nil {
 		return nil, fmt.Errorf("failed to initialize database schema: %w", err)
 	}
+	if config.TimescaleDB {
+		if err := storage.initTimescale(); err != nil {
+			return nil, fmt.Errorf("failed to initialize TimescaleDB schema: %w", err)
+		}
+	}
 
 	return storage, nil
 }
 
+// SetLedgerConfig sets the credit rates of the participation ledger
+func (p *PostgreSQLStorage) SetLedgerConfig(config LedgerConfig) {
+	p.ledgerConfig = config
+}
+
 // initSchema creates the necessary database tables
 func (p *PostgreSQLStorage) initSchema() error {
 	schemas := []string{
@@ -106,9 +139,12 @@ func (p *PostgreSQLStorage) initSchema() error {
 			loss REAL DEFAULT 0,
 			convergence_rate REAL DEFAULT 0,
 			communication_cost REAL DEFAULT 0,
+			hyperparameters JSONB,
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			FOREIGN KEY (federation_id) REFERENCES federations(id) ON DELETE CASCADE
 		)`,
+		// Added after the initial schema
+		`ALTER TABLE rounds ADD COLUMN IF NOT EXISTS hyperparameters JSONB`,
 
 		`CREATE TABLE IF NOT EXISTS model_updates (
 			id VARCHAR(255) PRIMARY KEY,
@@ -138,8 +174,16 @@ func (p *PostgreSQLStorage) initSchema() error {
 			timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS resource_sources (
+			id VARCHAR(255) PRIMARY KEY,
+			org_id VARCHAR(255),
+			data JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS events (
 			id SERIAL PRIMARY KEY,
+			event_id VARCHAR(255),
 			federation_id VARCHAR(255),
 			event_type VARCHAR(100) NOT NULL,
 			description TEXT,
@@ -157,6 +201,117 @@ func (p *PostgreSQLStorage) initSchema() error {
 		`CREATE INDEX IF NOT EXISTS idx_resource_metrics_source ON resource_metrics(source_id, timestamp)`,
 		`CREATE INDEX IF NOT EXISTS idx_events_federation ON events(federation_id, timestamp)`,
 		`CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp)`,
+
+		// Client-supplied event IDs make retried events upserts
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS event_id VARCHAR(255)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_events_event_id ON events(event_id)`,
+
+		// Full records of the monitoring service. The data column holds the
+		// record as JSON; the other columns are what queries filter and
+		// aggregate on.
+		`ALTER TABLE federations ADD COLUMN IF NOT EXISTS start_time TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE federations ADD COLUMN IF NOT EXISTS data JSONB`,
+		`ALTER TABLE collaborators ADD COLUMN IF NOT EXISTS join_time TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE collaborators ADD COLUMN IF NOT EXISTS data JSONB`,
+		`ALTER TABLE rounds ADD COLUMN IF NOT EXISTS status VARCHAR(50)`,
+		`ALTER TABLE rounds ADD COLUMN IF NOT EXISTS data JSONB`,
+		`ALTER TABLE model_updates ADD COLUMN IF NOT EXISTS quality_score REAL`,
+		`ALTER TABLE model_updates ADD COLUMN IF NOT EXISTS compression_ratio REAL`,
+		`ALTER TABLE model_updates ADD COLUMN IF NOT EXISTS data JSONB`,
+		// Updates can be recorded before their collaborator registers
+		`ALTER TABLE model_updates DROP CONSTRAINT IF EXISTS model_updates_collaborator_id_fkey`,
+		`ALTER TABLE resource_metrics ADD COLUMN IF NOT EXISTS data JSONB`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS source VARCHAR(255)`,
+
+		`CREATE TABLE IF NOT EXISTS dropped_updates (
+			federation_id VARCHAR(255) NOT NULL,
+			collaborator_id VARCHAR(255) NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			count INTEGER NOT NULL DEFAULT 0,
+			last_dropped TIMESTAMP WITH TIME ZONE,
+			last_detail TEXT,
+			PRIMARY KEY (federation_id, collaborator_id, reason)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS aggregations (
+			id VARCHAR(255) PRIMARY KEY,
+			federation_id VARCHAR(255) NOT NULL,
+			round_number INTEGER NOT NULL,
+			start_time TIMESTAMP WITH TIME ZONE,
+			duration_ms BIGINT NOT NULL DEFAULT 0,
+			updates_aggregated INTEGER NOT NULL DEFAULT 0,
+			updates_clipped INTEGER NOT NULL DEFAULT 0,
+			clipped_fraction REAL NOT NULL DEFAULT 0,
+			model_convergence REAL,
+			aggregation_quality REAL,
+			data JSONB NOT NULL,
+			FOREIGN KEY (federation_id) REFERENCES federations(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS contributions (
+			id VARCHAR(255) PRIMARY KEY,
+			federation_id VARCHAR(255) NOT NULL,
+			collaborator_id VARCHAR(255) NOT NULL,
+			round_number INTEGER NOT NULL,
+			timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			cosine DOUBLE PRECISION NOT NULL DEFAULT 0,
+			share DOUBLE PRECISION NOT NULL DEFAULT 0,
+			FOREIGN KEY (federation_id) REFERENCES federations(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS ledger_accounts (
+			federation_id VARCHAR(255) NOT NULL,
+			collaborator_id VARCHAR(255) NOT NULL,
+			data JSONB NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (federation_id, collaborator_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS alerts (
+			id VARCHAR(255) PRIMARY KEY,
+			federation_id VARCHAR(255) NOT NULL,
+			resolved_at TIMESTAMP WITH TIME ZONE,
+			data JSONB NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS dashboards (
+			id VARCHAR(255) PRIMARY KEY,
+			data JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR(255) PRIMARY KEY,
+			key_hash VARCHAR(255) NOT NULL,
+			data JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS notification_subscriptions (
+			id VARCHAR(255) PRIMARY KEY,
+			data JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id VARCHAR(255) PRIMARY KEY,
+			timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+			user_id VARCHAR(255),
+			org_id VARCHAR(255),
+			action VARCHAR(255),
+			resource_id VARCHAR(255),
+			success BOOLEAN,
+			data JSONB NOT NULL
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_federations_start_time ON federations(start_time)`,
+		`CREATE INDEX IF NOT EXISTS idx_rounds_start_time ON rounds(federation_id, start_time)`,
+		`CREATE INDEX IF NOT EXISTS idx_model_updates_round ON model_updates(federation_id, round_number)`,
+		`CREATE INDEX IF NOT EXISTS idx_aggregations_federation ON aggregations(federation_id, start_time)`,
+		`CREATE INDEX IF NOT EXISTS idx_contributions_federation ON contributions(federation_id, round_number)`,
+		`CREATE INDEX IF NOT EXISTS idx_alerts_federation ON alerts(federation_id) WHERE resolved_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys(key_hash)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp)`,
 	}
 
 	for _, schema := range schemas {
@@ -170,9 +325,20 @@ func (p *PostgreSQLStorage) initSchema() error {
 
 // StoreFederationMetrics stores federation metrics in PostgreSQL
 func (p *PostgreSQLStorage) StoreFederationMetrics(federation FederationMetrics) error {
+	_, err := p.upsertFederation(context.Background(), p.db, &federation)
+	return err
+}
+
+// upsertFederation stores a federation, reporting whether it was new
+func (p *PostgreSQLStorage) upsertFederation(ctx context.Context, q queryer, federation *FederationMetrics) (bool, error) {
+	data, err := json.Marshal(federation)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal federation: %w", err)
+	}
+
 	query := `
-		INSERT INTO federations (id, name, status, mode, algorithm, current_round, total_rounds, active_collaborators, total_collaborators, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		INSERT INTO federations (id, name, status, mode, algorithm, current_round, total_rounds, active_collaborators, total_collaborators, start_time, data, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			status = EXCLUDED.status,
@@ -182,14 +348,18 @@ func (p *PostgreSQLStorage) StoreFederationMetrics(federation FederationMetrics)
 			total_rounds = EXCLUDED.total_rounds,
 			active_collaborators = EXCLUDED.active_collaborators,
 			total_collaborators = EXCLUDED.total_collaborators,
+			start_time = EXCLUDED.start_time,
+			data = EXCLUDED.data,
 			updated_at = NOW()
+		RETURNING (xmax = 0)
 	`
 
-	_, err := p.db.Exec(query, federation.ID, federation.Name, federation.Status, federation.Mode,
+	var inserted bool
+	err = q.QueryRowContext(ctx, query, federation.ID, federation.Name, federation.Status, federation.Mode,
 		federation.Algorithm, federation.CurrentRound, federation.TotalRounds,
-		federation.ActiveCollabs, federation.TotalCollabs)
+		federation.ActiveCollabs, federation.TotalCollabs, nullTime(federation.StartTime), data).Scan(&inserted)
 
-	return err
+	return inserted, err
 }
 
 // GetFederationMetrics retrieves federation metrics from PostgreSQL
@@ -268,9 +438,19 @@ func (p *PostgreSQLStorage) ListFederations(activeOnly bool) ([]FederationMetric
 
 // StoreCollaboratorMetrics stores collaborator metrics in PostgreSQL
 func (p *PostgreSQLStorage) StoreCollaboratorMetrics(collaborator CollaboratorMetrics) error {
+	return p.upsertCollaborator(context.Background(), p.db, &collaborator)
+}
+
+// upsertCollaborator stores a collaborator
+func (p *PostgreSQLStorage) upsertCollaborator(ctx context.Context, q queryer, collaborator *CollaboratorMetrics) error {
+	data, err := json.Marshal(collaborator)
+	if err != nil {
+		return fmt.Errorf("failed to marshal collaborator: %w", err)
+	}
+
 	query := `
-		INSERT INTO collaborators (id, federation_id, name, status, address, last_seen, updates_submitted, errors, avg_training_time, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		INSERT INTO collaborators (id, federation_id, name, status, address, last_seen, updates_submitted, errors, avg_training_time, join_time, data, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			federation_id = EXCLUDED.federation_id,
 			name = EXCLUDED.name,
@@ -280,15 +460,18 @@ func (p *PostgreSQLStorage) StoreCollaboratorMetrics(collaborator CollaboratorMe
 			updates_submitted = EXCLUDED.updates_submitted,
 			errors = EXCLUDED.errors,
 			avg_training_time = EXCLUDED.avg_training_time,
+			join_time = EXCLUDED.join_time,
+			data = EXCLUDED.data,
 			updated_at = NOW()
 	`
 
 	// Extract training time as float64 seconds
 	trainingTimeSeconds := collaborator.TrainingTime.Seconds()
 
-	_, err := p.db.Exec(query, collaborator.ID, collaborator.FederationID, collaborator.ID, // Use ID as name for now
-		collaborator.Status, collaborator.Address, collaborator.LastSeen,
-		collaborator.UpdatesSubmitted, collaborator.ErrorCount, trainingTimeSeconds)
+	_, err = q.ExecContext(ctx, query, collaborator.ID, collaborator.FederationID, collaborator.ID, // Use ID as name for now
+		collaborator.Status, collaborator.Address, nullTime(collaborator.LastSeen),
+		collaborator.UpdatesSubmitted, collaborator.ErrorCount, trainingTimeSeconds,
+		nullTime(collaborator.JoinTime), data)
 
 	return err
 }
@@ -340,9 +523,19 @@ func (p *PostgreSQLStorage) GetCollaboratorMetrics(federationID string) ([]Colla
 
 // StoreRoundMetrics stores round metrics in PostgreSQL
 func (p *PostgreSQLStorage) StoreRoundMetrics(round RoundMetrics) error {
+	return p.upsertRound(context.Background(), p.db, &round)
+}
+
+// upsertRound stores a round
+func (p *PostgreSQLStorage) upsertRound(ctx context.Context, q queryer, round *RoundMetrics) error {
+	data, err := json.Marshal(round)
+	if err != nil {
+		return fmt.Errorf("failed to marshal round: %w", err)
+	}
+
 	query := `
-		INSERT INTO rounds (id, federation_id, round_number, algorithm, participants, start_time, end_time, duration_seconds, updates_received, accuracy, loss, convergence_rate, communication_cost)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO rounds (id, federation_id, round_number, algorithm, participants, start_time, end_time, duration_seconds, updates_received, accuracy, loss, convergence_rate, communication_cost, hyperparameters, status, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (id) DO UPDATE SET
 			federation_id = EXCLUDED.federation_id,
 			round_number = EXCLUDED.round_number,
@@ -355,7 +548,10 @@ func (p *PostgreSQLStorage) StoreRoundMetrics(round RoundMetrics) error {
 			accuracy = EXCLUDED.accuracy,
 			loss = EXCLUDED.loss,
 			convergence_rate = EXCLUDED.convergence_rate,
-			communication_cost = EXCLUDED.communication_cost
+			communication_cost = EXCLUDED.communication_cost,
+			hyperparameters = EXCLUDED.hyperparameters,
+			status = EXCLUDED.status,
+			data = EXCLUDED.data
 	`
 
 	// Handle optional fields
@@ -369,10 +565,19 @@ func (p *PostgreSQLStorage) StoreRoundMetrics(round RoundMetrics) error {
 	if round.ConvergenceRate != nil {
 		convergenceRate = *round.ConvergenceRate
 	}
+	var hyperparameters interface{}
+	if len(round.Hyperparameters) > 0 {
+		encoded, err := json.Marshal(round.Hyperparameters)
+		if err != nil {
+			return fmt.Errorf("failed to marshal round hyperparameters: %w", err)
+		}
+		hyperparameters = encoded
+	}
 
-	_, err := p.db.Exec(query, round.ID, round.FederationID, round.RoundNumber, round.Algorithm,
+	_, err = q.ExecContext(ctx, query, round.ID, round.FederationID, round.RoundNumber, round.Algorithm,
 		round.ParticipantCount, round.StartTime, round.EndTime, round.Duration.Seconds(),
-		round.UpdatesReceived, accuracy, loss, convergenceRate, 0.0) // communication_cost placeholder
+		round.UpdatesReceived, accuracy, loss, convergenceRate, 0.0, // communication_cost placeholder
+		hyperparameters, round.Status, data)
 
 	return err
 }
@@ -380,7 +585,7 @@ func (p *PostgreSQLStorage) StoreRoundMetrics(round RoundMetrics) error {
 // GetRoundMetrics retrieves round metrics from PostgreSQL
 func (p *PostgreSQLStorage) GetRoundMetrics(federationID string, limit int) ([]RoundMetrics, error) {
 	query := `
-		SELECT id, federation_id, round_number, algorithm, participants, start_time, end_time, duration_seconds, updates_received, accuracy, loss, convergence_rate, communication_cost, created_at
+		SELECT id, federation_id, round_number, algorithm, participants, start_time, end_time, duration_seconds, updates_received, accuracy, loss, convergence_rate, communication_cost, hyperparameters, created_at
 		FROM rounds WHERE federation_id = $1 ORDER BY round_number DESC
 	`
 
@@ -402,16 +607,22 @@ func (p *PostgreSQLStorage) GetRoundMetrics(federationID string, limit int) ([]R
 		var createdAt time.Time
 		var accuracy, loss, convergenceRate sql.NullFloat64
 		var communicationCost float64
+		var hyperparameters []byte
 
 		err := rows.Scan(
 			&round.ID, &round.FederationID, &round.RoundNumber, &round.Algorithm,
 			&round.ParticipantCount, &startTime, &endTime, &durationSeconds,
 			&round.UpdatesReceived, &accuracy, &loss,
-			&convergenceRate, &communicationCost, &createdAt,
+			&convergenceRate, &communicationCost, &hyperparameters, &createdAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if len(hyperparameters) > 0 {
+			if err := json.Unmarshal(hyperparameters, &round.Hyperparameters); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal round hyperparameters: %w", err)
+			}
+		}
 
 		if startTime.Valid {
 			round.StartTime = startTime.Time
@@ -440,43 +651,77 @@ func (p *PostgreSQLStorage) GetRoundMetrics(federationID string, limit int) ([]R
 
 // StoreResourceMetrics stores resource metrics in PostgreSQL
 func (p *PostgreSQLStorage) StoreResourceMetrics(metrics ResourceMetrics) error {
+	// Use placeholder values for source_id and source_type since they're not in ResourceMetrics
+	return p.insertResourceMetrics(context.Background(), p.db, "unknown", "system", &metrics)
+}
+
+// insertResourceMetrics stores a resource usage sample of a source
+func (p *PostgreSQLStorage) insertResourceMetrics(ctx context.Context, q queryer, sourceID, sourceType string, metrics *ResourceMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource metrics: %w", err)
+	}
+
 	query := `
-		INSERT INTO resource_metrics (source_id, source_type, cpu_usage, memory_usage, disk_usage, network_in, network_out, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO resource_metrics (source_id, source_type, cpu_usage, memory_usage, disk_usage, network_in, network_out, timestamp, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	// Use placeholder values for source_id and source_type since they're not in ResourceMetrics
-	sourceID := "unknown"
-	sourceType := "system"
-
-	_, err := p.db.Exec(query, sourceID, sourceType, metrics.CPUUsage,
-		metrics.MemoryUsage, metrics.DiskUsage, metrics.NetworkRxRate, metrics.NetworkTxRate, metrics.Timestamp)
+	_, err = q.ExecContext(ctx, query, sourceID, sourceType, metrics.CPUUsage,
+		metrics.MemoryUsage, metrics.DiskUsage, metrics.NetworkRxRate, metrics.NetworkTxRate, metrics.Timestamp, data)
 
 	return err
 }
 
 // StoreEvent stores monitoring events in PostgreSQL
 func (p *PostgreSQLStorage) StoreEvent(event MonitoringEvent) error {
+	_, err := p.upsertEvent(context.Background(), p.db, &event)
+	return err
+}
+
+// upsertEvent stores an event, reporting whether it was new. An event with
+// the ID of an event of another federation is rejected.
+func (p *PostgreSQLStorage) upsertEvent(ctx context.Context, q queryer, event *MonitoringEvent) (bool, error) {
 	metadataJSON, err := json.Marshal(event.Data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event data: %w", err)
+		return false, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	// Events without an ID are stored with a NULL event_id, which never
+	// conflicts
+	var eventID sql.NullString
+	if event.ID != "" {
+		eventID = sql.NullString{String: event.ID, Valid: true}
 	}
 
 	query := `
-		INSERT INTO events (federation_id, event_type, description, severity, metadata, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO events (event_id, federation_id, event_type, description, severity, metadata, timestamp, source)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (` + p.eventConflictKey() + `) DO UPDATE SET
+			event_type = EXCLUDED.event_type,
+			description = EXCLUDED.description,
+			severity = EXCLUDED.severity,
+			metadata = EXCLUDED.metadata,
+			timestamp = EXCLUDED.timestamp,
+			source = EXCLUDED.source
+		WHERE events.federation_id IS NOT DISTINCT FROM EXCLUDED.federation_id
+		RETURNING (xmax = 0)
 	`
 
-	_, err = p.db.Exec(query, event.FederationID, event.Type, event.Message,
-		event.Level, metadataJSON, event.Timestamp)
+	var inserted bool
+	err = q.QueryRowContext(ctx, query, eventID, event.FederationID, event.Type, event.Message,
+		event.Level, metadataJSON, event.Timestamp, event.Source).Scan(&inserted)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("event %s belongs to another federation", event.ID)
+	}
 
-	return err
+	return inserted, err
 }
 
-// GetEvents retrieves monitoring events from PostgreSQL
-func (p *PostgreSQLStorage) GetEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
+// ListEvents retrieves monitoring events from PostgreSQL
+func (p *PostgreSQLStorage) ListEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
 	query := `
-		SELECT federation_id, event_type, description, severity, metadata, timestamp
+		SELECT event_id, federation_id, event_type, description, severity, metadata, timestamp
 		FROM events
 	`
 
@@ -513,16 +758,17 @@ func (p *PostgreSQLStorage) GetEvents(federationID string, limit int, offset int
 	for rows.Next() {
 		var event MonitoringEvent
 		var metadataJSON []byte
-		var federationID sql.NullString
+		var eventID, federationID sql.NullString
 
 		err := rows.Scan(
-			&federationID, &event.Type, &event.Message,
+			&eventID, &federationID, &event.Type, &event.Message,
 			&event.Level, &metadataJSON, &event.Timestamp,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		event.ID = eventID.String
 		if federationID.Valid {
 			event.FederationID = federationID.String
 		}
@@ -540,8 +786,16 @@ func (p *PostgreSQLStorage) GetEvents(federationID string, limit int, offset int
 	return events, rows.Err()
 }
 
-// Close closes the PostgreSQL database connection
+// Close stops the event listener and closes the PostgreSQL database
+// connection
 func (p *PostgreSQLStorage) Close() error {
+	p.mu.Lock()
+	if p.listener != nil {
+		p.listener.close()
+		p.listener = nil
+	}
+	p.mu.Unlock()
+
 	return p.db.Close()
 }
 
@@ -549,6 +803,16 @@ func (p *PostgreSQLStorage) Close() error {
 func (p *PostgreSQLStorage) Cleanup(maxAge time.Duration) error {
 	cutoff := time.Now().Add(-maxAge)
 
+	if p.config.TimescaleDB {
+		if err := p.dropChunks(cutoff); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.lastCleanup = time.Now()
+		p.mu.Unlock()
+		return nil
+	}
+
 	queries := []string{
 		"DELETE FROM resource_metrics WHERE timestamp < $1",
 		"DELETE FROM events WHERE timestamp < $1",
@@ -561,5 +825,9 @@ func (p *PostgreSQLStorage) Cleanup(maxAge time.Duration) error {
 		}
 	}
 
+	p.mu.Lock()
+	p.lastCleanup = time.Now()
+	p.mu.Unlock()
+
 	return nil
 }
@@ -0,0 +1,137 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// eventsChannel is the PostgreSQL notification channel new events are
+// announced on. The payload is the event ID, as events can exceed the
+// payload size limit.
+const eventsChannel = "fl_monitoring_events"
+
+// eventListener receives the events announced on eventsChannel, including
+// those recorded by other monitoring servers sharing the database
+type eventListener struct {
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+func (l *eventListener) close() {
+	close(l.done)
+	l.listener.Close()
+}
+
+// SubscribeToEvents delivers the events recorded from now on by any
+// monitoring server using the database. The subscription ends with ctx.
+func (p *PostgreSQLStorage) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		listener, err := p.listen()
+		if err != nil {
+			return nil, err
+		}
+		p.listener = listener
+	}
+
+	subscription := &EventSubscription{
+		ID:           uuid.New().String(),
+		FederationID: federationID,
+		EventTypes:   eventTypes,
+		Channel:      make(chan *MonitoringEvent, 100), // Buffered channel
+		CreatedAt:    time.Now(),
+	}
+	p.subscriptions[subscription.ID] = subscription
+
+	go func() {
+		<-ctx.Done()
+		p.UnsubscribeFromEvents(context.Background(), subscription.ID)
+	}()
+
+	return subscription.Channel, nil
+}
+
+func (p *PostgreSQLStorage) UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subscription, exists := p.subscriptions[subscriptionID]
+	if !exists {
+		return fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+
+	close(subscription.Channel)
+	delete(p.subscriptions, subscriptionID)
+	return nil
+}
+
+// listen starts listening on eventsChannel. The caller holds p.mu.
+func (p *PostgreSQLStorage) listen() (*eventListener, error) {
+	listener := pq.NewListener(p.config.dsn(), 10*time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Warning: monitoring event listener: %v", err)
+		}
+	})
+	if err := listener.Listen(eventsChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for events: %w", err)
+	}
+
+	l := &eventListener{listener: listener, done: make(chan struct{})}
+	go p.dispatchEvents(l)
+	return l, nil
+}
+
+// dispatchEvents delivers announced events to the subscribers until the
+// listener is closed
+func (p *PostgreSQLStorage) dispatchEvents(l *eventListener) {
+	for {
+		select {
+		case <-l.done:
+			return
+		case notification := <-l.listener.Notify:
+			if notification == nil {
+				// The connection was re-established; events announced
+				// meanwhile are lost
+				continue
+			}
+			events, err := p.queryEvents(context.Background(), ` WHERE event_id = $1`, notification.Extra)
+			if err != nil {
+				log.Printf("Warning: failed to load event %s: %v", notification.Extra, err)
+				continue
+			}
+			for _, event := range events {
+				p.notifySubscribers(event)
+			}
+		case <-time.After(90 * time.Second):
+			// Detect lost connections while no events arrive
+			go l.listener.Ping()
+		}
+	}
+}
+
+// notifySubscribers sends an event to all relevant subscribers
+func (p *PostgreSQLStorage) notifySubscribers(event *MonitoringEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, subscription := range p.subscriptions {
+		if !subscription.matches(event) {
+			continue
+		}
+
+		// Send event to subscriber (non-blocking)
+		select {
+		case subscription.Channel <- event:
+		default:
+			// Channel is full, skip this event to prevent blocking
+		}
+	}
+}
@@ -0,0 +1,1441 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// nullTime stores zero times as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// inTx runs fn in a transaction, committing it if fn succeeds
+func (p *PostgreSQLStorage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryRecords decodes the JSON records returned by a query selecting a
+// single data column. Rows written before the column existed are skipped.
+func queryRecords[T any](ctx context.Context, q queryer, query string, args ...interface{}) ([]*T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*T, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		record := new(T)
+		if err := json.Unmarshal(data, record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record: %w", err)
+		}
+		results = append(results, record)
+	}
+
+	return results, rows.Err()
+}
+
+// queryRecord decodes the JSON record returned by a query selecting a single
+// data column, returning notFound when there is none
+func queryRecord[T any](ctx context.Context, q queryer, notFound error, query string, args ...interface{}) (*T, error) {
+	records, err := queryRecords[T](ctx, q, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, notFound
+	}
+	return records[0], nil
+}
+
+// sqlConditions collects the conditions of a WHERE clause and their
+// arguments
+type sqlConditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// add appends a condition on arg, whose placeholder is written as $%d
+func (c *sqlConditions) add(clause string, arg interface{}) {
+	c.args = append(c.args, arg)
+	c.clauses = append(c.clauses, strings.ReplaceAll(clause, "$%d", fmt.Sprintf("$%d", len(c.args))))
+}
+
+// where returns the WHERE clause, if there are conditions
+func (c *sqlConditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// filterColumns names the columns a MetricsFilter is applied to. Filters on
+// columns without a name are ignored.
+type filterColumns struct {
+	federation   string
+	collaborator string
+	round        string
+	status       string
+	metricType   string
+	time         string
+	tags         string // JSON array of tags
+	labels       string // JSON object of labels
+}
+
+// metricsConditions translates a MetricsFilter into SQL conditions
+func metricsConditions(filter *MetricsFilter, columns filterColumns) *sqlConditions {
+	conditions := &sqlConditions{}
+	if filter == nil {
+		return conditions
+	}
+
+	if filter.FederationID != "" {
+		conditions.add(columns.federation+" = $%d", filter.FederationID)
+	}
+	if filter.Federations != nil {
+		federations := make([]string, 0, len(filter.Federations))
+		for id, allowed := range filter.Federations {
+			if allowed {
+				federations = append(federations, id)
+			}
+		}
+		sort.Strings(federations)
+		conditions.add(columns.federation+" = ANY($%d)", pq.Array(federations))
+	}
+	if filter.CollaboratorID != "" && columns.collaborator != "" {
+		conditions.add(columns.collaborator+" = $%d", filter.CollaboratorID)
+	}
+	if filter.RoundNumber != nil && columns.round != "" {
+		conditions.add(columns.round+" = $%d", *filter.RoundNumber)
+	}
+	if filter.Status != "" && columns.status != "" {
+		conditions.add(columns.status+" = $%d", filter.Status)
+	}
+	if filter.MetricType != "" && columns.metricType != "" {
+		conditions.add(columns.metricType+" = $%d", string(filter.MetricType))
+	}
+	if len(filter.Tags) > 0 && columns.tags != "" {
+		tags, _ := json.Marshal(filter.Tags)
+		conditions.add(columns.tags+" @> $%d::jsonb", string(tags))
+	}
+	if len(filter.Labels) > 0 && columns.labels != "" {
+		labels, _ := json.Marshal(filter.Labels)
+		conditions.add(columns.labels+" @> $%d::jsonb", string(labels))
+	}
+	if filter.StartTime != nil && columns.time != "" {
+		conditions.add(columns.time+" >= $%d", *filter.StartTime)
+	}
+	if filter.EndTime != nil && columns.time != "" {
+		conditions.add(columns.time+" <= $%d", *filter.EndTime)
+	}
+
+	return conditions
+}
+
+// pageClause returns the LIMIT and OFFSET of the page a filter selects, or
+// nothing when it selects all results
+func pageClause(page, perPage, defaultPerPage int) string {
+	if page <= 0 {
+		return ""
+	}
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", perPage, (page-1)*perPage)
+}
+
+// metricsPage returns the page clause of a MetricsFilter
+func metricsPage(filter *MetricsFilter, defaultPerPage int) string {
+	if filter == nil {
+		return ""
+	}
+	return pageClause(filter.Page, filter.PerPage, defaultPerPage)
+}
+
+var (
+	federationColumns   = filterColumns{federation: "id", status: "status", time: "start_time", labels: "data->'labels'"}
+	collaboratorColumns = filterColumns{federation: "federation_id", collaborator: "id", status: "status", time: "join_time", tags: "data->'tags'"}
+	roundColumns        = filterColumns{federation: "federation_id", round: "round_number", status: "status", time: "start_time", labels: "data->'labels'"}
+	updateColumns       = filterColumns{federation: "federation_id", collaborator: "collaborator_id", round: "round_number", time: "timestamp"}
+	droppedColumns      = filterColumns{federation: "federation_id", collaborator: "collaborator_id", status: "reason"}
+	aggregationColumns  = filterColumns{federation: "federation_id", round: "round_number", time: "start_time"}
+	contributionColumns = filterColumns{federation: "federation_id", collaborator: "collaborator_id", round: "round_number", time: "timestamp"}
+	eventColumns        = filterColumns{federation: "federation_id", metricType: "event_type", time: "timestamp"}
+	ledgerColumns       = filterColumns{federation: "federation_id", collaborator: "collaborator_id"}
+)
+
+// recordEvent stores a new event and notifies the subscribers of all
+// replicas sharing the database once the transaction commits
+func (p *PostgreSQLStorage) recordEvent(ctx context.Context, q queryer, event *MonitoringEvent) error {
+	inserted, err := p.upsertEvent(ctx, q, event)
+	if err != nil || !inserted {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, event.ID)
+	return err
+}
+
+// Federation metrics implementation
+func (p *PostgreSQLStorage) RegisterFederation(ctx context.Context, metrics *FederationMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		// Registering a federation again, e.g. when a client retries, updates it
+		inserted, err := p.upsertFederation(ctx, tx, metrics)
+		if err != nil || !inserted {
+			return err
+		}
+		return p.recordEvent(ctx, tx, federationRegisteredEvent(metrics))
+	})
+}
+
+func (p *PostgreSQLStorage) UpdateFederation(ctx context.Context, federationID string, metrics *FederationMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := p.lockRecord(ctx, tx, "federations", federationID); err != nil {
+			return err
+		}
+		metrics.ID = federationID
+		_, err := p.upsertFederation(ctx, tx, metrics)
+		return err
+	})
+}
+
+func (p *PostgreSQLStorage) GetFederation(ctx context.Context, federationID string) (*FederationMetrics, error) {
+	return queryRecord[FederationMetrics](ctx, p.db, fmt.Errorf("federation %s not found", federationID),
+		`SELECT data FROM federations WHERE id = $1`, federationID)
+}
+
+func (p *PostgreSQLStorage) GetActiveFederations(ctx context.Context) ([]*FederationMetrics, error) {
+	return queryRecords[FederationMetrics](ctx, p.db, `SELECT data FROM federations WHERE status = $1`, StatusRunning)
+}
+
+func (p *PostgreSQLStorage) GetFederationHistory(ctx context.Context, filter *MetricsFilter) ([]*FederationMetrics, error) {
+	conditions := metricsConditions(filter, federationColumns)
+	query := `SELECT data FROM federations` + conditions.where() +
+		` ORDER BY start_time DESC NULLS LAST` + metricsPage(filter, 20)
+	return queryRecords[FederationMetrics](ctx, p.db, query, conditions.args...)
+}
+
+// ImportFederationHistory stores historical records without emitting the
+// events that live recording produces. Records that are already stored are skipped.
+func (p *PostgreSQLStorage) ImportFederationHistory(ctx context.Context, archive *ImportArchive) (*ImportResult, error) {
+	federationID := archive.FederationID()
+	result := &ImportResult{FederationID: federationID}
+
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		exists, err := p.recordExists(ctx, tx, "federations", "id", federationID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			federation := archive.Federation
+			if federation == nil {
+				federation = archive.summaryFederation()
+			}
+			if _, err := p.upsertFederation(ctx, tx, federation); err != nil {
+				return err
+			}
+			result.FederationCreated = true
+		}
+
+		for _, collaborator := range archive.Collaborators {
+			exists, err := p.recordExists(ctx, tx, "collaborators", "id", collaborator.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+			if err := p.upsertCollaborator(ctx, tx, collaborator); err != nil {
+				return err
+			}
+			result.Collaborators++
+		}
+
+		for _, round := range archive.Rounds {
+			exists, err := p.recordExists(ctx, tx, "rounds", "id", round.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+			if err := p.upsertRound(ctx, tx, round); err != nil {
+				return err
+			}
+			result.Rounds++
+		}
+
+		for _, update := range archive.Updates {
+			inserted, err := p.upsertModelUpdate(ctx, tx, update)
+			if err != nil {
+				return err
+			}
+			if !inserted {
+				result.Skipped++
+				continue
+			}
+			result.Updates++
+		}
+
+		for _, event := range archive.Events {
+			exists, err := p.recordExists(ctx, tx, "events", "event_id", event.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+			if _, err := p.upsertEvent(ctx, tx, event); err != nil {
+				return err
+			}
+			result.Events++
+		}
+
+		return p.recordEvent(ctx, tx, historyImportedEvent(result))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// recordExists reports whether a table has a row with the given key
+func (p *PostgreSQLStorage) recordExists(ctx context.Context, q queryer, table, column, key string) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s = $1)`, table, column)
+	err := q.QueryRowContext(ctx, query, key).Scan(&exists)
+	return exists, err
+}
+
+// lockRecord locks the row of a record for the rest of the transaction,
+// returning its federation. Federations are their own federation.
+func (p *PostgreSQLStorage) lockRecord(ctx context.Context, tx *sql.Tx, table, id string) (string, error) {
+	column := "federation_id"
+	if table == "federations" {
+		column = "id"
+	}
+
+	var federationID string
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 FOR UPDATE`, column, table)
+	err := tx.QueryRowContext(ctx, query, id).Scan(&federationID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%s %s not found", strings.TrimSuffix(table, "s"), id)
+	}
+	return federationID, err
+}
+
+// Collaborator metrics implementation
+func (p *PostgreSQLStorage) RegisterCollaborator(ctx context.Context, metrics *CollaboratorMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		if err := p.upsertCollaborator(ctx, tx, metrics); err != nil {
+			return err
+		}
+		return p.recordEvent(ctx, tx, collaboratorJoinedEvent(metrics))
+	})
+}
+
+func (p *PostgreSQLStorage) UpdateCollaborator(ctx context.Context, collaboratorID string, metrics *CollaboratorMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := p.lockRecord(ctx, tx, "collaborators", collaboratorID); err != nil {
+			return err
+		}
+		metrics.ID = collaboratorID
+		return p.upsertCollaborator(ctx, tx, metrics)
+	})
+}
+
+func (p *PostgreSQLStorage) GetCollaborator(ctx context.Context, collaboratorID string) (*CollaboratorMetrics, error) {
+	return queryRecord[CollaboratorMetrics](ctx, p.db, fmt.Errorf("collaborator %s not found", collaboratorID),
+		`SELECT data FROM collaborators WHERE id = $1`, collaboratorID)
+}
+
+func (p *PostgreSQLStorage) GetFederationCollaborators(ctx context.Context, federationID string) ([]*CollaboratorMetrics, error) {
+	return queryRecords[CollaboratorMetrics](ctx, p.db,
+		`SELECT data FROM collaborators WHERE federation_id = $1 ORDER BY id`, federationID)
+}
+
+func (p *PostgreSQLStorage) GetCollaboratorHistory(ctx context.Context, filter *MetricsFilter) ([]*CollaboratorMetrics, error) {
+	conditions := metricsConditions(filter, collaboratorColumns)
+	query := `SELECT data FROM collaborators` + conditions.where() +
+		` ORDER BY join_time DESC NULLS LAST` + metricsPage(filter, 20)
+	return queryRecords[CollaboratorMetrics](ctx, p.db, query, conditions.args...)
+}
+
+// Round metrics implementation
+func (p *PostgreSQLStorage) RecordRoundStart(ctx context.Context, metrics *RoundMetrics) error {
+	if metrics.ID == "" {
+		metrics.ID = uuid.New().String()
+	}
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		// A round started again with the same ID, e.g. when a client retries, is
+		// updated instead of recorded twice
+		var federationID string
+		err := tx.QueryRowContext(ctx, `SELECT federation_id FROM rounds WHERE id = $1 FOR UPDATE`, metrics.ID).Scan(&federationID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		exists := err == nil
+		if exists && federationID != metrics.FederationID {
+			return fmt.Errorf("round %s belongs to another federation", metrics.ID)
+		}
+		if err := p.upsertRound(ctx, tx, metrics); err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+		return p.recordEvent(ctx, tx, roundStartedEvent(metrics))
+	})
+}
+
+func (p *PostgreSQLStorage) RecordRoundEnd(ctx context.Context, roundID string, metrics *RoundMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		var completed bool
+		err := tx.QueryRowContext(ctx, `SELECT end_time IS NOT NULL FROM rounds WHERE id = $1 FOR UPDATE`, roundID).Scan(&completed)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("round %s not found", roundID)
+		}
+		if err != nil {
+			return err
+		}
+
+		metrics.ID = roundID
+		if err := p.upsertRound(ctx, tx, metrics); err != nil {
+			return err
+		}
+		if completed {
+			// Already completed; the round is updated but not announced again
+			return nil
+		}
+		return p.recordEvent(ctx, tx, roundCompletedEvent(metrics))
+	})
+}
+
+func (p *PostgreSQLStorage) GetRound(ctx context.Context, roundID string) (*RoundMetrics, error) {
+	return queryRecord[RoundMetrics](ctx, p.db, fmt.Errorf("round %s not found", roundID),
+		`SELECT data FROM rounds WHERE id = $1`, roundID)
+}
+
+func (p *PostgreSQLStorage) GetFederationRounds(ctx context.Context, federationID string) ([]*RoundMetrics, error) {
+	return queryRecords[RoundMetrics](ctx, p.db,
+		`SELECT data FROM rounds WHERE federation_id = $1 ORDER BY round_number`, federationID)
+}
+
+func (p *PostgreSQLStorage) GetRoundHistory(ctx context.Context, filter *MetricsFilter) ([]*RoundMetrics, error) {
+	conditions := metricsConditions(filter, roundColumns)
+	query := `SELECT data FROM rounds` + conditions.where() +
+		` ORDER BY start_time DESC NULLS LAST` + metricsPage(filter, 20)
+	return queryRecords[RoundMetrics](ctx, p.db, query, conditions.args...)
+}
+
+// Model update metrics implementation
+func (p *PostgreSQLStorage) RecordModelUpdate(ctx context.Context, metrics *ModelUpdateMetrics) error {
+	if metrics.ID == "" {
+		metrics.ID = uuid.New().String()
+	}
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		// A retried update is stored once and credited once
+		inserted, err := p.upsertModelUpdate(ctx, tx, metrics)
+		if err != nil || !inserted {
+			return err
+		}
+		err = p.creditLedger(ctx, tx, metrics.FederationID, metrics.CollaboratorID, func(l *ledger) {
+			l.recordUpdate(metrics)
+		})
+		if err != nil {
+			return err
+		}
+		return p.recordEvent(ctx, tx, updateReceivedEvent(metrics))
+	})
+}
+
+// upsertModelUpdate stores a model update, reporting whether it was new
+func (p *PostgreSQLStorage) upsertModelUpdate(ctx context.Context, q queryer, update *ModelUpdateMetrics) (bool, error) {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal model update: %w", err)
+	}
+
+	query := `
+		INSERT INTO model_updates (id, federation_id, collaborator_id, round_number, update_size, processing_time, staleness, weight, quality_score, compression_ratio, timestamp, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (` + p.updateConflictKey() + `) DO NOTHING
+	`
+
+	result, err := q.ExecContext(ctx, query, update.ID, update.FederationID, update.CollaboratorID,
+		update.RoundNumber, update.UpdateSize, update.ProcessingTime, update.Staleness, update.Weight,
+		update.QualityScore, update.CompressionRatio, update.Timestamp, data)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *PostgreSQLStorage) GetModelUpdates(ctx context.Context, filter *MetricsFilter) ([]*ModelUpdateMetrics, error) {
+	conditions := metricsConditions(filter, updateColumns)
+	query := `SELECT data FROM model_updates` + conditions.where() +
+		` ORDER BY timestamp DESC` + metricsPage(filter, 50)
+	return queryRecords[ModelUpdateMetrics](ctx, p.db, query, conditions.args...)
+}
+
+func (p *PostgreSQLStorage) GetUpdateStatistics(ctx context.Context, federationID string, roundNumber int) (*UpdateStatistics, error) {
+	// Updates that reported no quality score or compression ratio count as 0,
+	// and all recorded updates are assumed successful
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(AVG(processing_time), 0),
+		       COALESCE(AVG(update_size), 0),
+		       COALESCE(SUM(quality_score) / COUNT(*), 0),
+		       COALESCE(SUM(compression_ratio) / COUNT(*), 0)
+		FROM model_updates WHERE federation_id = $1 AND round_number = $2
+	`
+
+	stats := &UpdateStatistics{}
+	err := p.db.QueryRowContext(ctx, query, federationID, roundNumber).Scan(
+		&stats.TotalUpdates, &stats.AverageLatency, &stats.AverageSize,
+		&stats.QualityScore, &stats.CompressionRatio,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalUpdates > 0 {
+		stats.SuccessRate = 100
+	}
+
+	return stats, nil
+}
+
+func (p *PostgreSQLStorage) RecordDroppedUpdate(ctx context.Context, dropped *DroppedUpdate) error {
+	if dropped.Timestamp.IsZero() {
+		dropped.Timestamp = time.Now()
+	}
+
+	query := `
+		INSERT INTO dropped_updates (federation_id, collaborator_id, reason, count, last_dropped, last_detail)
+		VALUES ($1, $2, $3, 1, $4, $5)
+		ON CONFLICT (federation_id, collaborator_id, reason) DO UPDATE SET
+			count = dropped_updates.count + 1,
+			last_dropped = GREATEST(dropped_updates.last_dropped, EXCLUDED.last_dropped),
+			last_detail = EXCLUDED.last_detail
+	`
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query, dropped.FederationID, dropped.CollaboratorID,
+			dropped.Reason, dropped.Timestamp, dropped.Detail)
+		if err != nil {
+			return err
+		}
+		return p.recordEvent(ctx, tx, updateDroppedEvent(dropped))
+	})
+}
+
+func (p *PostgreSQLStorage) GetDroppedUpdateStats(ctx context.Context, filter *MetricsFilter) ([]*DroppedUpdateStats, error) {
+	conditions := metricsConditions(filter, droppedColumns)
+	query := `
+		SELECT federation_id, collaborator_id, reason, count, last_dropped, COALESCE(last_detail, '')
+		FROM dropped_updates` + conditions.where() + `
+		ORDER BY count DESC, last_dropped DESC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, conditions.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Sorted by count (most dropped first)
+	results := make([]*DroppedUpdateStats, 0)
+	for rows.Next() {
+		var stats DroppedUpdateStats
+		var lastDropped sql.NullTime
+		err := rows.Scan(&stats.FederationID, &stats.CollaboratorID, &stats.Reason,
+			&stats.Count, &lastDropped, &stats.LastDetail)
+		if err != nil {
+			return nil, err
+		}
+		stats.LastDropped = lastDropped.Time
+		results = append(results, &stats)
+	}
+
+	return results, rows.Err()
+}
+
+// Aggregation metrics implementation
+func (p *PostgreSQLStorage) RecordAggregation(ctx context.Context, metrics *AggregationMetrics) error {
+	if metrics.ID == "" {
+		metrics.ID = uuid.New().String()
+	}
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		inserted, err := p.upsertAggregation(ctx, tx, metrics)
+		if err != nil || !inserted {
+			return err
+		}
+		return p.recordEvent(ctx, tx, aggregationRecordedEvent(metrics))
+	})
+}
+
+// upsertAggregation stores an aggregation, reporting whether it was new
+func (p *PostgreSQLStorage) upsertAggregation(ctx context.Context, q queryer, aggregation *AggregationMetrics) (bool, error) {
+	data, err := json.Marshal(aggregation)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal aggregation: %w", err)
+	}
+
+	query := `
+		INSERT INTO aggregations (id, federation_id, round_number, start_time, duration_ms, updates_aggregated, updates_clipped, clipped_fraction, model_convergence, aggregation_quality, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET
+			round_number = EXCLUDED.round_number,
+			start_time = EXCLUDED.start_time,
+			duration_ms = EXCLUDED.duration_ms,
+			updates_aggregated = EXCLUDED.updates_aggregated,
+			updates_clipped = EXCLUDED.updates_clipped,
+			clipped_fraction = EXCLUDED.clipped_fraction,
+			model_convergence = EXCLUDED.model_convergence,
+			aggregation_quality = EXCLUDED.aggregation_quality,
+			data = EXCLUDED.data
+		RETURNING (xmax = 0)
+	`
+
+	var inserted bool
+	err = q.QueryRowContext(ctx, query, aggregation.ID, aggregation.FederationID, aggregation.RoundNumber,
+		nullTime(aggregation.StartTime), aggregation.Duration.Milliseconds(), aggregation.UpdatesAggregated,
+		aggregation.UpdatesClipped, aggregation.ClippedFraction, aggregation.ModelConvergence,
+		aggregation.AggregationQuality, data).Scan(&inserted)
+
+	return inserted, err
+}
+
+func (p *PostgreSQLStorage) GetAggregation(ctx context.Context, aggregationID string) (*AggregationMetrics, error) {
+	return queryRecord[AggregationMetrics](ctx, p.db, fmt.Errorf("aggregation %s not found", aggregationID),
+		`SELECT data FROM aggregations WHERE id = $1`, aggregationID)
+}
+
+// UpdateAggregation replaces a recorded aggregation, typically with its
+// completion data once the aggregation finished
+func (p *PostgreSQLStorage) UpdateAggregation(ctx context.Context, aggregationID string, metrics *AggregationMetrics) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		federationID, err := p.lockRecord(ctx, tx, "aggregations", aggregationID)
+		if err != nil {
+			return err
+		}
+		if metrics.FederationID == "" {
+			metrics.FederationID = federationID
+		} else if metrics.FederationID != federationID {
+			return fmt.Errorf("aggregation %s belongs to another federation", aggregationID)
+		}
+		metrics.ID = aggregationID
+		_, err = p.upsertAggregation(ctx, tx, metrics)
+		return err
+	})
+}
+
+func (p *PostgreSQLStorage) GetAggregations(ctx context.Context, filter *MetricsFilter) ([]*AggregationMetrics, error) {
+	conditions := metricsConditions(filter, aggregationColumns)
+	query := `SELECT data FROM aggregations` + conditions.where() +
+		` ORDER BY start_time DESC NULLS LAST` + metricsPage(filter, 20)
+	return queryRecords[AggregationMetrics](ctx, p.db, query, conditions.args...)
+}
+
+func (p *PostgreSQLStorage) GetAggregationStatistics(ctx context.Context, federationID string) (*AggregationStatistics, error) {
+	// Aggregations that reported no convergence or quality count as 0
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(AVG(duration_ms), 0),
+		       COALESCE(AVG(updates_aggregated), 0),
+		       COALESCE(SUM(model_convergence) / COUNT(*), 0),
+		       COALESCE(SUM(aggregation_quality) / COUNT(*), 0),
+		       COALESCE(SUM(updates_clipped), 0),
+		       COALESCE(AVG(clipped_fraction), 0)
+		FROM aggregations WHERE federation_id = $1
+	`
+
+	stats := &AggregationStatistics{}
+	err := p.db.QueryRowContext(ctx, query, federationID).Scan(
+		&stats.TotalAggregations, &stats.AverageTime, &stats.AverageParticipants,
+		&stats.ConvergenceRate, &stats.ModelQuality, &stats.TotalClipped, &stats.AverageClippedFraction,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Contribution scores implementation
+func (p *PostgreSQLStorage) RecordContributions(ctx context.Context, scores []*ContributionScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO contributions (id, federation_id, collaborator_id, round_number, timestamp, cosine, share)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		for _, score := range scores {
+			if score.ID == "" {
+				score.ID = uuid.New().String()
+			}
+			if score.Timestamp.IsZero() {
+				score.Timestamp = time.Now()
+			}
+			_, err := tx.ExecContext(ctx, query, score.ID, score.FederationID, score.CollaboratorID,
+				score.RoundNumber, score.Timestamp, score.Cosine, score.Share)
+			if err != nil {
+				return err
+			}
+			err = p.creditLedger(ctx, tx, score.FederationID, score.CollaboratorID, func(l *ledger) {
+				l.recordContribution(score)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return p.recordEvent(ctx, tx, contributionsRecordedEvent(scores))
+	})
+}
+
+// GetContributions returns the contribution scores matching filter, latest
+// round first
+func (p *PostgreSQLStorage) GetContributions(ctx context.Context, filter *MetricsFilter) ([]*ContributionScore, error) {
+	conditions := metricsConditions(filter, contributionColumns)
+	query := `
+		SELECT id, federation_id, collaborator_id, round_number, timestamp, cosine, share
+		FROM contributions` + conditions.where() + `
+		ORDER BY round_number DESC, collaborator_id` + metricsPage(filter, 50)
+
+	rows, err := p.db.QueryContext(ctx, query, conditions.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*ContributionScore, 0)
+	for rows.Next() {
+		var score ContributionScore
+		err := rows.Scan(&score.ID, &score.FederationID, &score.CollaboratorID, &score.RoundNumber,
+			&score.Timestamp, &score.Cosine, &score.Share)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &score)
+	}
+
+	return results, rows.Err()
+}
+
+// GetContributionSummary sums up the contribution scores of each
+// collaborator of a federation, highest total share first
+func (p *PostgreSQLStorage) GetContributionSummary(ctx context.Context, federationID string) ([]*ContributionSummary, error) {
+	query := `
+		SELECT collaborator_id, COUNT(*), AVG(cosine), AVG(share), SUM(share),
+		       COUNT(*) FILTER (WHERE cosine < 0), MAX(round_number)
+		FROM contributions WHERE federation_id = $1
+		GROUP BY collaborator_id
+		ORDER BY SUM(share) DESC, collaborator_id
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, federationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*ContributionSummary, 0)
+	for rows.Next() {
+		summary := &ContributionSummary{FederationID: federationID}
+		err := rows.Scan(&summary.CollaboratorID, &summary.Rounds, &summary.MeanCosine, &summary.MeanShare,
+			&summary.TotalShare, &summary.OpposedRounds, &summary.LastRound)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, summary)
+	}
+
+	return results, rows.Err()
+}
+
+// creditLedger applies credit to the ledger account of a collaborator. The
+// account stays locked until the transaction ends.
+func (p *PostgreSQLStorage) creditLedger(ctx context.Context, tx *sql.Tx, federationID, collaboratorID string, credit func(l *ledger)) error {
+	// Open the account first so that concurrent credits wait on its lock
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO ledger_accounts (federation_id, collaborator_id, data) VALUES ($1, $2, '{}')
+		ON CONFLICT DO NOTHING
+	`, federationID, collaboratorID)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT data FROM ledger_accounts WHERE federation_id = $1 AND collaborator_id = $2 FOR UPDATE
+	`, federationID, collaboratorID).Scan(&data)
+	if err != nil {
+		return err
+	}
+
+	l := newLedger(p.ledgerConfig)
+	account := l.account(federationID, collaboratorID)
+	if err := json.Unmarshal(data, account); err != nil {
+		return fmt.Errorf("invalid ledger account of %s: %w", collaboratorID, err)
+	}
+	credit(l)
+
+	if data, err = json.Marshal(account); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE ledger_accounts SET data = $3, updated_at = NOW() WHERE federation_id = $1 AND collaborator_id = $2
+	`, federationID, collaboratorID, data)
+	return err
+}
+
+// GetLedger returns the ledger accounts matching the filter's federation and
+// collaborator, most credits first
+func (p *PostgreSQLStorage) GetLedger(ctx context.Context, filter *MetricsFilter) ([]*LedgerAccount, error) {
+	conditions := metricsConditions(filter, ledgerColumns)
+	accounts, err := queryRecords[LedgerAccount](ctx, p.db, `SELECT data FROM ledger_accounts`+conditions.where(), conditions.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Credits are priced with the current rates
+	l := newLedger(p.ledgerConfig)
+	for _, account := range accounts {
+		l.accounts[account.FederationID+"/"+account.CollaboratorID] = account
+		l.price(account)
+	}
+	return l.list("", ""), nil
+}
+
+// Resource metrics implementation
+func (p *PostgreSQLStorage) RecordResourceMetrics(ctx context.Context, source string, metrics *ResourceMetrics) error {
+	latest, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource metrics: %w", err)
+	}
+	lastSeen, err := json.Marshal(metrics.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		// Collaborators report their own usage, which is shown with them
+		result, err := tx.ExecContext(ctx, `
+			UPDATE collaborators
+			SET data = data || jsonb_build_object('resource_metrics', $2::jsonb, 'last_seen', $3::jsonb), last_seen = $4
+			WHERE id = $1 AND data IS NOT NULL
+		`, source, latest, lastSeen, metrics.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		sourceType := "system"
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			sourceType = "collaborator"
+		}
+		return p.insertResourceMetrics(ctx, tx, source, sourceType, metrics)
+	})
+}
+
+func (p *PostgreSQLStorage) GetResourceMetrics(ctx context.Context, source string, timeRange time.Duration) ([]*ResourceMetrics, error) {
+	return queryRecords[ResourceMetrics](ctx, p.db, `
+		SELECT data FROM resource_metrics WHERE source_id = $1 AND timestamp > $2 ORDER BY timestamp
+	`, source, time.Now().Add(-timeRange))
+}
+
+// RegisterResourceSource records the owner of a source unless it has one
+// already, and returns the recorded owner
+func (p *PostgreSQLStorage) RegisterResourceSource(ctx context.Context, source *ResourceSource) (*ResourceSource, error) {
+	record := *source
+	record.CreatedAt = time.Now()
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource source: %w", err)
+	}
+
+	if _, err := p.db.ExecContext(ctx, `
+		INSERT INTO resource_sources (id, org_id, data, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`, record.ID, record.OrgID, data, record.CreatedAt); err != nil {
+		return nil, err
+	}
+	return p.GetResourceSource(ctx, source.ID)
+}
+
+func (p *PostgreSQLStorage) GetResourceSource(ctx context.Context, sourceID string) (*ResourceSource, error) {
+	return queryRecord[ResourceSource](ctx, p.db, fmt.Errorf("resource source %s %w", sourceID, errUnknownResourceSource),
+		`SELECT data FROM resource_sources WHERE id = $1`, sourceID)
+}
+
+func (p *PostgreSQLStorage) GetSystemOverview(ctx context.Context, federationID string) (*SystemOverview, error) {
+	federation, err := p.GetFederation(ctx, federationID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &SystemOverview{
+		FederationID: federationID,
+		Status:       federation.Status,
+		CurrentRound: federation.CurrentRound,
+		TotalRounds:  federation.TotalRounds,
+	}
+	if federation.TotalRounds > 0 {
+		overview.Progress = float64(federation.CurrentRound) / float64(federation.TotalRounds) * 100
+	}
+
+	err = p.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status IN ($2, $3))
+		FROM collaborators WHERE federation_id = $1
+	`, federationID, CollabStatusConnected, CollabStatusTraining).Scan(&overview.TotalCollaborators, &overview.ActiveCollaborators)
+	if err != nil {
+		return nil, err
+	}
+
+	if overview.RecentEvents, err = p.GetEvents(ctx, &MetricsFilter{FederationID: federationID, Page: 1, PerPage: 10}); err != nil {
+		return nil, err
+	}
+	if overview.Alerts, err = p.GetActiveAlerts(ctx, federationID); err != nil {
+		return nil, err
+	}
+
+	return overview, nil
+}
+
+// Events and alerts implementation
+func (p *PostgreSQLStorage) RecordEvent(ctx context.Context, event *MonitoringEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		// A retried event replaces the one recorded with the same ID
+		exists, err := p.recordExists(ctx, tx, "events", "event_id", event.ID)
+		if err != nil {
+			return err
+		}
+		if !exists && event.Type == MetricTypeClustering {
+			if err := p.applyClusters(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		return p.recordEvent(ctx, tx, event)
+	})
+}
+
+// applyClusters sets the cluster of each collaborator listed in a clustering
+// event
+func (p *PostgreSQLStorage) applyClusters(ctx context.Context, tx *sql.Tx, event *MonitoringEvent) error {
+	for id, cluster := range eventClusters(event) {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE collaborators SET data = jsonb_set(data, '{cluster}', to_jsonb($3::int))
+			WHERE id = $1 AND federation_id = $2 AND data IS NOT NULL
+		`, id, event.FederationID, cluster)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) GetEvents(ctx context.Context, filter *MetricsFilter) ([]*MonitoringEvent, error) {
+	conditions := metricsConditions(filter, eventColumns)
+	return p.queryEvents(ctx, conditions.where()+` ORDER BY timestamp DESC`+metricsPage(filter, 100), conditions.args...)
+}
+
+// queryEvents returns the events selected by the clause following FROM
+func (p *PostgreSQLStorage) queryEvents(ctx context.Context, clause string, args ...interface{}) ([]*MonitoringEvent, error) {
+	query := `
+		SELECT event_id, federation_id, event_type, description, severity, metadata, timestamp, source
+		FROM events` + clause
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*MonitoringEvent, 0)
+	for rows.Next() {
+		var event MonitoringEvent
+		var eventID, federationID, description, source sql.NullString
+		var metadata []byte
+
+		err := rows.Scan(&eventID, &federationID, &event.Type, &description,
+			&event.Level, &metadata, &event.Timestamp, &source)
+		if err != nil {
+			return nil, err
+		}
+		event.ID = eventID.String
+		event.FederationID = federationID.String
+		event.Message = description.String
+		event.Source = source.String
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &event.Data); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+			}
+		}
+		results = append(results, &event)
+	}
+
+	return results, rows.Err()
+}
+
+func (p *PostgreSQLStorage) GetActiveAlerts(ctx context.Context, federationID string) ([]*Alert, error) {
+	return queryRecords[Alert](ctx, p.db, `
+		SELECT data FROM alerts WHERE federation_id = $1 AND resolved_at IS NULL
+	`, federationID)
+}
+
+// Placeholder implementations for advanced analytics
+func (p *PostgreSQLStorage) GetPerformanceInsights(ctx context.Context, federationID string) (*PerformanceInsights, error) {
+	return placeholderPerformanceInsights(federationID), nil
+}
+
+func (p *PostgreSQLStorage) GetConvergenceAnalysis(ctx context.Context, federationID string) (*ConvergenceAnalysis, error) {
+	return placeholderConvergenceAnalysis(federationID), nil
+}
+
+func (p *PostgreSQLStorage) GetEfficiencyMetrics(ctx context.Context, federationID string) (*EfficiencyMetrics, error) {
+	return placeholderEfficiencyMetrics(federationID), nil
+}
+
+func (p *PostgreSQLStorage) GetTimeSeries(ctx context.Context, federationID string, query *TimeSeriesQuery) ([]*TimeSeries, error) {
+	if _, err := p.GetFederation(ctx, federationID); err != nil {
+		return nil, err
+	}
+
+	series := make([]*TimeSeries, 0, len(query.Metrics))
+	for _, metric := range query.Metrics {
+		buckets, err := p.seriesBuckets(ctx, federationID, metric, query)
+		if err != nil {
+			return nil, err
+		}
+
+		interval := query.Interval
+		if interval <= 0 {
+			interval = query.EndTime.Sub(query.StartTime) / defaultSeriesBuckets
+		}
+
+		series = append(series, &TimeSeries{
+			Metric:   metric,
+			Interval: interval.Milliseconds(),
+			Buckets:  buckets,
+		})
+	}
+
+	return series, nil
+}
+
+// seriesBuckets computes the buckets of a metric, from the rollups when the
+// buckets are wide enough
+func (p *PostgreSQLStorage) seriesBuckets(ctx context.Context, federationID string, metric TimeSeriesMetric, query *TimeSeriesQuery) ([]TimeSeriesBucket, error) {
+	if p.usesRollups(query) {
+		rollups, ok, err := p.queryRollups(ctx, federationID, metric, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return mergeRollups(rollups, query.StartTime, query.EndTime, query.Interval), nil
+		}
+	}
+
+	samples, err := p.querySeriesSamples(ctx, federationID, metric, query)
+	if err != nil {
+		return nil, err
+	}
+	return downsample(samples, query.StartTime, query.EndTime, query.Interval), nil
+}
+
+// querySeriesSamples selects the raw samples of a metric in the query's time
+// range
+func (p *PostgreSQLStorage) querySeriesSamples(ctx context.Context, federationID string, metric TimeSeriesMetric, query *TimeSeriesQuery) ([]seriesSample, error) {
+	var samplesQuery string
+	args := []interface{}{federationID, query.StartTime, query.EndTime}
+
+	switch metric {
+	case SeriesAccuracy, SeriesLoss:
+		column := "accuracy"
+		if metric == SeriesLoss {
+			column = "loss"
+		}
+		samplesQuery = fmt.Sprintf(`
+			SELECT COALESCE(end_time, start_time) AS ts, %[1]s FROM rounds
+			WHERE federation_id = $1 AND %[1]s IS NOT NULL
+		`, column)
+	case SeriesRoundDuration:
+		samplesQuery = `
+			SELECT end_time AS ts, duration_seconds * 1000 FROM rounds
+			WHERE federation_id = $1 AND end_time IS NOT NULL
+		`
+	case SeriesUpdateLatency:
+		samplesQuery = `
+			SELECT timestamp AS ts, processing_time FROM model_updates WHERE federation_id = $1
+		`
+	case SeriesCPU, SeriesMemory:
+		column := "cpu_usage"
+		if metric == SeriesMemory {
+			column = "memory_usage"
+		}
+		sources := `(source_id = 'aggregator' OR source_id IN (SELECT id FROM collaborators WHERE federation_id = $1))`
+		if query.Source != "" {
+			sources = `source_id = $1`
+			args[0] = query.Source
+		}
+		samplesQuery = fmt.Sprintf(`
+			SELECT timestamp AS ts, %s FROM resource_metrics WHERE %s
+		`, column, sources)
+	default:
+		return nil, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, `SELECT * FROM (`+samplesQuery+`) samples WHERE ts >= $2 AND ts <= $3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []seriesSample
+	for rows.Next() {
+		var sample seriesSample
+		if err := rows.Scan(&sample.Timestamp, &sample.Value); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, rows.Err()
+}
+
+// Dashboard management
+func (p *PostgreSQLStorage) CreateDashboard(ctx context.Context, dashboard *Dashboard) error {
+	if dashboard.ID == "" {
+		dashboard.ID = uuid.New().String()
+	}
+	dashboard.CreatedAt = time.Now()
+	dashboard.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dashboard: %w", err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO dashboards (id, data, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, created_at = EXCLUDED.created_at
+	`, dashboard.ID, data, dashboard.CreatedAt)
+	return err
+}
+
+func (p *PostgreSQLStorage) GetDashboard(ctx context.Context, dashboardID string) (*Dashboard, error) {
+	return queryRecord[Dashboard](ctx, p.db, fmt.Errorf("dashboard %s not found", dashboardID),
+		`SELECT data FROM dashboards WHERE id = $1`, dashboardID)
+}
+
+func (p *PostgreSQLStorage) ListDashboards(ctx context.Context) ([]*Dashboard, error) {
+	return queryRecords[Dashboard](ctx, p.db, `SELECT data FROM dashboards ORDER BY created_at DESC`)
+}
+
+func (p *PostgreSQLStorage) UpdateDashboard(ctx context.Context, dashboardID string, dashboard *Dashboard) error {
+	dashboard.ID = dashboardID
+	dashboard.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dashboard: %w", err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `UPDATE dashboards SET data = $2 WHERE id = $1`, dashboardID, data)
+	return requireAffected(result, err, fmt.Errorf("dashboard %s not found", dashboardID))
+}
+
+func (p *PostgreSQLStorage) DeleteDashboard(ctx context.Context, dashboardID string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM dashboards WHERE id = $1`, dashboardID)
+	return requireAffected(result, err, fmt.Errorf("dashboard %s not found", dashboardID))
+}
+
+// requireAffected returns notFound when a statement changed no rows
+func requireAffected(result sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// API key management. The key hash is not part of the JSON record and is
+// kept in its own column.
+func (p *PostgreSQLStorage) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal API key: %w", err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, key_hash, data, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`, key.ID, key.KeyHash, data, key.CreatedAt)
+	return requireAffected(result, err, fmt.Errorf("API key %s already exists", key.ID))
+}
+
+func (p *PostgreSQLStorage) GetAPIKey(ctx context.Context, keyID string) (*APIKey, error) {
+	return p.queryAPIKey(ctx, fmt.Errorf("API key %s not found", keyID), `WHERE id = $1`, keyID)
+}
+
+func (p *PostgreSQLStorage) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	return p.queryAPIKey(ctx, fmt.Errorf("API key not found"), `WHERE key_hash = $1`, keyHash)
+}
+
+// queryAPIKey returns the first API key selected by a WHERE clause
+func (p *PostgreSQLStorage) queryAPIKey(ctx context.Context, notFound error, where string, args ...interface{}) (*APIKey, error) {
+	keys, err := p.queryAPIKeys(ctx, where+` LIMIT 1`, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, notFound
+	}
+	return keys[0], nil
+}
+
+// queryAPIKeys returns the API keys selected by the clause following FROM
+func (p *PostgreSQLStorage) queryAPIKeys(ctx context.Context, clause string, args ...interface{}) ([]*APIKey, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT key_hash, data FROM api_keys `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*APIKey, 0)
+	for rows.Next() {
+		var keyHash string
+		var data []byte
+		if err := rows.Scan(&keyHash, &data); err != nil {
+			return nil, err
+		}
+		var key APIKey
+		if err := json.Unmarshal(data, &key); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal API key: %w", err)
+		}
+		key.KeyHash = keyHash
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (p *PostgreSQLStorage) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	return p.queryAPIKeys(ctx, `ORDER BY created_at`)
+}
+
+func (p *PostgreSQLStorage) UpdateAPIKey(ctx context.Context, keyID string, key *APIKey) error {
+	result := *key
+	result.ID = keyID
+
+	data, err := json.Marshal(&result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal API key: %w", err)
+	}
+
+	updated, err := p.db.ExecContext(ctx, `UPDATE api_keys SET key_hash = $2, data = $3 WHERE id = $1`, keyID, result.KeyHash, data)
+	return requireAffected(updated, err, fmt.Errorf("API key %s not found", keyID))
+}
+
+// Notification subscriptions
+func (p *PostgreSQLStorage) CreateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error {
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification subscription: %w", err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO notification_subscriptions (id, data, created_at) VALUES ($1, $2, $3)
+	`, subscription.ID, data, subscription.CreatedAt)
+	return err
+}
+
+func (p *PostgreSQLStorage) GetNotificationSubscription(ctx context.Context, subscriptionID string) (*NotificationSubscription, error) {
+	return queryRecord[NotificationSubscription](ctx, p.db, fmt.Errorf("notification subscription %s not found", subscriptionID),
+		`SELECT data FROM notification_subscriptions WHERE id = $1`, subscriptionID)
+}
+
+func (p *PostgreSQLStorage) ListNotificationSubscriptions(ctx context.Context) ([]*NotificationSubscription, error) {
+	return queryRecords[NotificationSubscription](ctx, p.db, `SELECT data FROM notification_subscriptions ORDER BY created_at`)
+}
+
+func (p *PostgreSQLStorage) UpdateNotificationSubscription(ctx context.Context, subscriptionID string, subscription *NotificationSubscription) error {
+	existing, err := p.GetNotificationSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	subscription.ID = subscriptionID
+	subscription.CreatedAt = existing.CreatedAt
+	subscription.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification subscription: %w", err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `UPDATE notification_subscriptions SET data = $2 WHERE id = $1`, subscriptionID, data)
+	return requireAffected(result, err, fmt.Errorf("notification subscription %s not found", subscriptionID))
+}
+
+func (p *PostgreSQLStorage) DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM notification_subscriptions WHERE id = $1`, subscriptionID)
+	return requireAffected(result, err, fmt.Errorf("notification subscription %s not found", subscriptionID))
+}
+
+// Audit log
+func (p *PostgreSQLStorage) RecordAudit(ctx context.Context, entry *AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO audit_log (id, timestamp, user_id, org_id, action, resource_id, success, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, entry.ID, entry.Timestamp, entry.UserID, entry.OrgID, entry.Action, entry.ResourceID, entry.Success, data)
+	return err
+}
+
+func (p *PostgreSQLStorage) GetAuditLog(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error) {
+	conditions := auditConditions(filter)
+	query := `SELECT data FROM audit_log` + conditions.where() + ` ORDER BY timestamp DESC`
+	if filter != nil {
+		query += pageClause(filter.Page, filter.PerPage, 100)
+	}
+	return queryRecords[AuditEntry](ctx, p.db, query, conditions.args...)
+}
+
+// auditConditions translates an AuditFilter into SQL conditions
+func auditConditions(filter *AuditFilter) *sqlConditions {
+	conditions := &sqlConditions{}
+	if filter == nil {
+		return conditions
+	}
+
+	if filter.UserID != "" {
+		conditions.add("user_id = $%d", filter.UserID)
+	}
+	if filter.Action != "" {
+		// An action also matches the actions on a resource, e.g. "apikey"
+		// matches "apikey.revoke"
+		conditions.add("(action = $%d OR starts_with(action, $%d || '.'))", filter.Action)
+	}
+	if filter.OrgID != "" {
+		conditions.add("org_id = $%d", filter.OrgID)
+	}
+	if filter.ResourceID != "" {
+		conditions.add("resource_id = $%d", filter.ResourceID)
+	}
+	if filter.Success != nil {
+		conditions.add("success = $%d", *filter.Success)
+	}
+	if filter.StartTime != nil {
+		conditions.add("timestamp >= $%d", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		conditions.add("timestamp <= $%d", *filter.EndTime)
+	}
+
+	return conditions
+}
+
+// Health and status
+func (p *PostgreSQLStorage) HealthCheck(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+func (p *PostgreSQLStorage) GetMetricsStats(ctx context.Context) (*MetricsStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM federations),
+			(SELECT COUNT(*) FROM federations WHERE status = $1),
+			(SELECT COUNT(*) FROM collaborators),
+			(SELECT COUNT(*) FROM collaborators WHERE status IN ($2, $3)),
+			(SELECT COUNT(*) FROM rounds),
+			(SELECT COUNT(*) FROM model_updates),
+			pg_database_size(current_database())
+	`
+
+	stats := &MetricsStats{}
+	err := p.db.QueryRowContext(ctx, query, StatusRunning, CollabStatusConnected, CollabStatusTraining).Scan(
+		&stats.TotalFederations, &stats.ActiveFederations,
+		&stats.TotalCollaborators, &stats.ActiveCollaborators,
+		&stats.TotalRounds, &stats.TotalUpdates, &stats.StorageUsed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	stats.LastCleanup = p.lastCleanup
+	p.mu.Unlock()
+	stats.UptimeSeconds = int64(time.Since(p.startTime).Seconds())
+
+	return stats, nil
+}
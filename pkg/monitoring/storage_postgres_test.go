@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postgresTestURLEnv names the variable holding the connection URL of a
+// PostgreSQL database for the tests that need one
+const postgresTestURLEnv = "FLGO_TEST_POSTGRES_URL"
+
+// newPostgresTestStorage returns storage in a new schema of the database
+// named by FLGO_TEST_POSTGRES_URL, dropped when the test ends. The test is
+// skipped when the variable is not set.
+func newPostgresTestStorage(t *testing.T) *PostgreSQLStorage {
+	t.Helper()
+	dsn := os.Getenv(postgresTestURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresTestURLEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	schema := fmt.Sprintf("flgo_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	storage, err := NewPostgreSQLStorage(DatabaseConfig{URL: withSearchPath(t, dsn, schema)})
+	if err != nil {
+		t.Fatalf("NewPostgreSQLStorage() error = %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+// withSearchPath returns a connection string that opens sessions with schema
+// as their search path, so that the tables are created there
+func withSearchPath(t *testing.T, dsn, schema string) string {
+	t.Helper()
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", postgresTestURLEnv, err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func TestPostgreSQLMonitoringService(t *testing.T) {
+	if os.Getenv(postgresTestURLEnv) == "" {
+		t.Skipf("%s is not set", postgresTestURLEnv)
+	}
+	for _, tt := range monitoringServiceTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newPostgresTestStorage(t))
+		})
+	}
+}
+
+func TestMetricsConditions(t *testing.T) {
+	round := 3
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &MetricsFilter{
+		FederationID:   "fed",
+		CollaboratorID: "collab-1",
+		RoundNumber:    &round,
+		Status:         "completed",
+		StartTime:      &start,
+		Page:           3,
+		PerPage:        10,
+	}
+
+	conditions := metricsConditions(filter, updateColumns)
+	want := " WHERE federation_id = $1 AND collaborator_id = $2 AND round_number = $3 AND timestamp >= $4"
+	if got := conditions.where(); got != want {
+		t.Errorf("where() = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(conditions.args, []interface{}{"fed", "collab-1", 3, start}) {
+		t.Errorf("args = %v", conditions.args)
+	}
+	if got := metricsPage(filter, 50); got != " LIMIT 10 OFFSET 20" {
+		t.Errorf("metricsPage() = %q", got)
+	}
+
+	// Federations filter on the federation column of each record type
+	conditions = metricsConditions(&MetricsFilter{Federations: map[string]bool{"b": true, "a": true}}, federationColumns)
+	if got := conditions.where(); got != " WHERE id = ANY($1)" {
+		t.Errorf("where() = %q", got)
+	}
+
+	// Tags match collaborators that have all of them
+	conditions = metricsConditions(&MetricsFilter{Tags: []string{"gpu", "eu"}}, collaboratorColumns)
+	if got := conditions.where(); got != " WHERE data->'tags' @> $1::jsonb" || conditions.args[0] != `["gpu","eu"]` {
+		t.Errorf("where() = %q, args = %v", got, conditions.args)
+	}
+
+	// Labels match federations and rounds; other records have no label column
+	labels := &MetricsFilter{Labels: map[string]string{"experiment": "ablation-3"}}
+	conditions = metricsConditions(labels, roundColumns)
+	if got := conditions.where(); got != " WHERE data->'labels' @> $1::jsonb" || conditions.args[0] != `{"experiment":"ablation-3"}` {
+		t.Errorf("where() = %q, args = %v", got, conditions.args)
+	}
+	if got := metricsConditions(labels, eventColumns).where(); got != "" {
+		t.Errorf("where() of labeled events = %q, want none", got)
+	}
+
+	if got := metricsConditions(nil, eventColumns).where(); got != "" {
+		t.Errorf("where() of no filter = %q, want none", got)
+	}
+	if got := metricsPage(&MetricsFilter{}, 50); got != "" {
+		t.Errorf("metricsPage() without a page = %q, want none", got)
+	}
+}
+
+func TestAuditConditions(t *testing.T) {
+	success := false
+	conditions := auditConditions(&AuditFilter{Action: "apikey", Success: &success})
+
+	want := " WHERE (action = $1 OR starts_with(action, $1 || '.')) AND success = $2"
+	if got := conditions.where(); got != want {
+		t.Errorf("where() = %q, want %q", got, want)
+	}
+	if len(conditions.args) != 2 {
+		t.Errorf("args = %v, want the action and success", conditions.args)
+	}
+}
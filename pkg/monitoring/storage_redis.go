@@ -4,19 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisStorage implements Storage interface using Redis
+// RedisStorage implements Storage interface using Redis. Its live event
+// subscriptions use Redis pub/sub, so they deliver the events stored by any
+// monitoring server sharing the Redis instance.
 type RedisStorage struct {
 	client *redis.Client
 	config RedisConfig
 	ctx    context.Context
+
+	mu            sync.Mutex
+	subscriptions map[string]*EventSubscription
+	pubsub        *redis.PubSub // subscribed to redisEventsChannel by the first subscription
 }
 
+// redisEventsChannel is the pub/sub channel events are published on
+const redisEventsChannel = "monitoring:events"
+
 // RedisConfig represents Redis connection configuration
 type RedisConfig struct {
 	Address  string `yaml:"address"`
@@ -47,9 +60,10 @@ func NewRedisStorage(config RedisConfig) (*RedisStorage, error) {
 	}
 
 	return &RedisStorage{
-		client: client,
-		config: config,
-		ctx:    ctx,
+		client:        client,
+		config:        config,
+		ctx:           ctx,
+		subscriptions: make(map[string]*EventSubscription),
 	}, nil
 }
 
@@ -306,12 +320,25 @@ func (r *RedisStorage) StoreEvent(event MonitoringEvent) error {
 		streamKey = fmt.Sprintf("events:%s", event.FederationID)
 	}
 
+	// Streams are append-only, so a retried event is dropped rather than
+	// replacing the one stored with the same ID
+	if event.ID != "" {
+		first, err := r.client.SetNX(r.ctx, fmt.Sprintf("event:%s", event.ID), streamKey, r.getDefaultTTL()).Result()
+		if err != nil {
+			return fmt.Errorf("failed to check event ID: %w", err)
+		}
+		if !first {
+			return nil
+		}
+	}
+
 	dataJSON, err := json.Marshal(event.Data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
 	values := map[string]interface{}{
+		"id":            event.ID,
 		"federation_id": event.FederationID,
 		"type":          event.Type,
 		"message":       event.Message,
@@ -333,11 +360,11 @@ func (r *RedisStorage) StoreEvent(event MonitoringEvent) error {
 	// Trim stream to keep only recent entries (last 10000 events)
 	r.client.XTrimMaxLen(r.ctx, streamKey, 10000)
 
-	return nil
+	return r.PublishEvent(r.ctx, &event)
 }
 
-// GetEvents retrieves monitoring events from Redis
-func (r *RedisStorage) GetEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
+// ListEvents retrieves monitoring events from Redis
+func (r *RedisStorage) ListEvents(federationID string, limit int, offset int) ([]MonitoringEvent, error) {
 	streamKey := "events"
 	if federationID != "" {
 		streamKey = fmt.Sprintf("events:%s", federationID)
@@ -379,6 +406,10 @@ func (r *RedisStorage) GetEvents(federationID string, limit int, offset int) ([]
 		// Parse values from stream
 		for field, value := range stream.Values {
 			switch field {
+			case "id":
+				if str, ok := value.(string); ok {
+					event.ID = str
+				}
 			case "federation_id":
 				if str, ok := value.(string); ok {
 					event.FederationID = str
@@ -417,8 +448,114 @@ func (r *RedisStorage) GetEvents(federationID string, limit int, offset int) ([]
 	return events, nil
 }
 
-// Close closes the Redis connection
+// PublishEvent sends an event to the live event subscribers of all
+// monitoring servers sharing the Redis instance
+func (r *RedisStorage) PublishEvent(ctx context.Context, event *MonitoringEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if err := r.client.Publish(ctx, redisEventsChannel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeToEvents delivers the events published from now on by any
+// monitoring server sharing the Redis instance. The subscription ends with
+// ctx.
+func (r *RedisStorage) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pubsub == nil {
+		pubsub := r.client.Subscribe(r.ctx, redisEventsChannel)
+		// Wait for the confirmation so that no event published after
+		// subscribing is missed
+		if _, err := pubsub.Receive(r.ctx); err != nil {
+			pubsub.Close()
+			return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+		}
+		r.pubsub = pubsub
+		go r.dispatchEvents(pubsub)
+	}
+
+	subscription := &EventSubscription{
+		ID:           uuid.New().String(),
+		FederationID: federationID,
+		EventTypes:   eventTypes,
+		Channel:      make(chan *MonitoringEvent, 100), // Buffered channel
+		CreatedAt:    time.Now(),
+	}
+	r.subscriptions[subscription.ID] = subscription
+
+	go func() {
+		<-ctx.Done()
+		r.UnsubscribeFromEvents(context.Background(), subscription.ID)
+	}()
+
+	return subscription.Channel, nil
+}
+
+// UnsubscribeFromEvents ends a live event subscription
+func (r *RedisStorage) UnsubscribeFromEvents(ctx context.Context, subscriptionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, exists := r.subscriptions[subscriptionID]
+	if !exists {
+		return fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+
+	close(subscription.Channel)
+	delete(r.subscriptions, subscriptionID)
+	return nil
+}
+
+// dispatchEvents delivers published events to the subscribers until the
+// pub/sub connection is closed. go-redis reconnects it as needed; events
+// published while it is down are lost.
+func (r *RedisStorage) dispatchEvents(pubsub *redis.PubSub) {
+	for message := range pubsub.Channel() {
+		var event MonitoringEvent
+		if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+			log.Printf("Warning: invalid event published on %s: %v", message.Channel, err)
+			continue
+		}
+		r.notifySubscribers(&event)
+	}
+}
+
+// notifySubscribers sends an event to all relevant subscribers
+func (r *RedisStorage) notifySubscribers(event *MonitoringEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, subscription := range r.subscriptions {
+		if !subscription.matches(event) {
+			continue
+		}
+
+		// Send event to subscriber (non-blocking)
+		select {
+		case subscription.Channel <- event:
+		default:
+			// Channel is full, skip this event to prevent blocking
+		}
+	}
+}
+
+// Close closes the event subscription and the Redis connection
 func (r *RedisStorage) Close() error {
+	r.mu.Lock()
+	if r.pubsub != nil {
+		r.pubsub.Close()
+		r.pubsub = nil
+	}
+	r.mu.Unlock()
+
 	return r.client.Close()
 }
 
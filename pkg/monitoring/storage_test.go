@@ -1,6 +1,10 @@
 package monitoring
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 )
@@ -181,7 +185,7 @@ func testStorageImplementation(t *testing.T, storage Storage) {
 			t.Fatalf("Failed to store event: %v", err)
 		}
 
-		events, err := storage.GetEvents("test-federation", 10, 0)
+		events, err := storage.ListEvents("test-federation", 10, 0)
 		if err != nil {
 			t.Fatalf("Failed to get events: %v", err)
 		}
@@ -194,8 +198,20 @@ func testStorageImplementation(t *testing.T, storage Storage) {
 			t.Errorf("Event type mismatch: got %s, want %s", events[0].Type, event.Type)
 		}
 
+		// Events with an ID are stored once however often they are sent
+		event.ID = "event-1"
+		for i := 0; i < 2; i++ {
+			if err := storage.StoreEvent(event); err != nil {
+				t.Fatalf("Failed to store event: %v", err)
+			}
+		}
+		events, _ = storage.ListEvents("test-federation", 10, 0)
+		if len(events) != 2 {
+			t.Errorf("Expected 2 events after storing event-1 twice, got %d", len(events))
+		}
+
 		// Test pagination
-		paginatedEvents, err := storage.GetEvents("", 5, 0)
+		paginatedEvents, err := storage.ListEvents("", 5, 0)
 		if err != nil {
 			t.Fatalf("Failed to get paginated events: %v", err)
 		}
@@ -286,3 +302,198 @@ func TestStorageFactory(t *testing.T) {
 		})
 	}
 }
+
+// monitoringServiceTests check the behavior every MonitoringService
+// implementation shares. Each runs on an empty service.
+var monitoringServiceTests = []struct {
+	name string
+	run  func(t *testing.T, storage MonitoringService)
+}{
+	{"clustering event sets collaborator clusters", testClusteringEventSetsCollaboratorClusters},
+	{"contribution summary", testContributionSummary},
+	{"retried records are upserts", testRetriedRecordsAreUpserts},
+	{"aggregation completion is persisted", testAggregationCompletionIsPersisted},
+}
+
+func TestMemoryMonitoringService(t *testing.T) {
+	for _, tt := range monitoringServiceTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, NewMemoryStorage(&MonitoringConfig{}))
+		})
+	}
+}
+
+func testClusteringEventSetsCollaboratorClusters(t *testing.T, storage MonitoringService) {
+	ctx := context.Background()
+	if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed", Name: "Federation", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"site-a", "site-b"} {
+		if err := storage.RegisterCollaborator(ctx, &CollaboratorMetrics{ID: id, FederationID: "fed"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Events posted to the API carry the membership as decoded JSON
+	var event MonitoringEvent
+	data := `{"federation_id": "fed", "type": "clustering", "data": {"round": 1, "clusters": {"site-a": 0, "site-b": 1, "site-c": 1}}}`
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.RecordEvent(ctx, &event); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, expected := range map[string]int{"site-a": 0, "site-b": 1} {
+		collaborator, err := storage.GetCollaborator(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if collaborator.Cluster == nil || *collaborator.Cluster != expected {
+			t.Errorf("%s cluster = %v, want %d", id, collaborator.Cluster, expected)
+		}
+	}
+}
+
+func testContributionSummary(t *testing.T, storage MonitoringService) {
+	ctx := context.Background()
+	for _, id := range []string{"fed", "other"} {
+		if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: id, Name: id, Status: StatusRunning}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rounds := [][]*ContributionScore{
+		{
+			{FederationID: "fed", CollaboratorID: "site-a", RoundNumber: 1, Cosine: 0.9, Share: 0.7},
+			{FederationID: "fed", CollaboratorID: "site-b", RoundNumber: 1, Cosine: 0.5, Share: 0.3},
+		},
+		{
+			{FederationID: "fed", CollaboratorID: "site-a", RoundNumber: 2, Cosine: 0.7, Share: 1.1},
+			{FederationID: "fed", CollaboratorID: "site-b", RoundNumber: 2, Cosine: -0.1, Share: -0.1},
+			{FederationID: "other", CollaboratorID: "site-c", RoundNumber: 2, Cosine: 1, Share: 1},
+		},
+	}
+	for _, scores := range rounds {
+		if err := storage.RecordContributions(ctx, scores); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary, err := storage.GetContributionSummary(ctx, "fed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary) != 2 || summary[0].CollaboratorID != "site-a" {
+		t.Fatalf("summary = %+v, want site-a then site-b", summary)
+	}
+	a, b := summary[0], summary[1]
+	if a.Rounds != 2 || math.Abs(a.TotalShare-1.8) > 1e-9 || math.Abs(a.MeanCosine-0.8) > 1e-9 || a.LastRound != 2 {
+		t.Errorf("site-a = %+v, want 2 rounds, total share 1.8, mean cosine 0.8", a)
+	}
+	if b.OpposedRounds != 1 || math.Abs(b.MeanShare-0.1) > 1e-9 {
+		t.Errorf("site-b = %+v, want 1 opposed round, mean share 0.1", b)
+	}
+
+	round := 2
+	scores, err := storage.GetContributions(ctx, &MetricsFilter{FederationID: "fed", RoundNumber: &round})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 2 || scores[0].CollaboratorID != "site-a" {
+		t.Errorf("round 2 scores = %+v, want site-a and site-b", scores)
+	}
+}
+
+func testRetriedRecordsAreUpserts(t *testing.T, storage MonitoringService) {
+	ctx := context.Background()
+
+	// Each record is sent twice, as a client retrying after a lost response would
+	for i := 0; i < 2; i++ {
+		if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed", Name: "Federation", Status: StatusRunning}); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.RecordRoundStart(ctx, &RoundMetrics{ID: "round-1", FederationID: "fed", RoundNumber: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	accuracy := 0.8
+	for i := 0; i < 2; i++ {
+		end := time.Now()
+		if err := storage.RecordRoundEnd(ctx, "round-1", &RoundMetrics{FederationID: "fed", RoundNumber: 1, EndTime: &end, ModelAccuracy: &accuracy}); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.RecordEvent(ctx, &MonitoringEvent{ID: "event-1", FederationID: "fed", Type: MetricTypeTraining, Message: fmt.Sprintf("attempt %d", i+1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, _ := storage.GetEvents(ctx, &MetricsFilter{FederationID: "fed"})
+	counts := make(map[string]int)
+	for _, event := range events {
+		counts[event.Message]++
+	}
+	for message, want := range map[string]int{
+		"Federation Federation registered": 1,
+		"Round 1 started":                  1,
+		"Round 1 completed":                1,
+		"attempt 1":                        0,
+		"attempt 2":                        1,
+	} {
+		if counts[message] != want {
+			t.Errorf("%d events %q, want %d", counts[message], message, want)
+		}
+	}
+
+	round, err := storage.GetRound(ctx, "round-1")
+	if err != nil || round.ModelAccuracy == nil || *round.ModelAccuracy != accuracy {
+		t.Errorf("round = %+v, %v, want the completed round", round, err)
+	}
+
+	// IDs cannot be reused across federations
+	if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: "other", Name: "Other", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.RecordRoundStart(ctx, &RoundMetrics{ID: "round-1", FederationID: "other"}); err == nil {
+		t.Error("RecordRoundStart() reused a round ID of another federation")
+	}
+	if err := storage.RecordEvent(ctx, &MonitoringEvent{ID: "event-1", FederationID: "other"}); err == nil {
+		t.Error("RecordEvent() reused an event ID of another federation")
+	}
+}
+
+func testAggregationCompletionIsPersisted(t *testing.T, storage MonitoringService) {
+	hooks := NewMonitoringHooks(storage, true)
+	ctx := context.Background()
+	for _, id := range []string{"fed", "other"} {
+		if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: id, Name: id, Status: StatusRunning}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id, err := hooks.OnAggregationStart(ctx, "fed", 1, "fedavg", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	convergence, quality := 0.02, 0.9
+	if err := hooks.OnAggregationEnd(ctx, id, 2*time.Second, &convergence, &quality); err != nil {
+		t.Fatal(err)
+	}
+
+	aggregation, err := storage.GetAggregation(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aggregation.Duration != 2*time.Second || aggregation.EndTime.IsZero() || aggregation.UpdatesAggregated != 3 {
+		t.Errorf("aggregation = %+v, want the start data with a 2s duration", aggregation)
+	}
+	if aggregation.ModelConvergence == nil || *aggregation.ModelConvergence != convergence || aggregation.AggregationQuality == nil || *aggregation.AggregationQuality != quality {
+		t.Errorf("convergence = %v, quality = %v, want %v and %v", aggregation.ModelConvergence, aggregation.AggregationQuality, convergence, quality)
+	}
+
+	if err := hooks.OnAggregationEnd(ctx, "missing", time.Second, nil, nil); err == nil {
+		t.Error("OnAggregationEnd() of an unknown aggregation returned no error")
+	}
+	if err := storage.UpdateAggregation(ctx, id, &AggregationMetrics{FederationID: "other"}); err == nil {
+		t.Error("UpdateAggregation() moved an aggregation to another federation")
+	}
+}
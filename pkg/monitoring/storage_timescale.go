@@ -0,0 +1,244 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// With TimescaleDB enabled, the high-volume tables of the PostgreSQL backend
+// (model updates, resource metrics and events) become hypertables
+// partitioned by time, and continuous aggregates keep hourly rollups of
+// them. Cleanup drops whole chunks of raw rows while the rollups are kept,
+// and time series with buckets of an hour or more are read from the rollups.
+
+// rollupInterval is the bucket width of the continuous aggregates
+const rollupInterval = time.Hour
+
+// timescaleSchema converts the tables created by initSchema. Unique indexes
+// of a hypertable must include its time column, so records are deduplicated
+// by ID and timestamp, which the retries of a record share. The unique
+// index on event IDs keeps its name so that initSchema leaves it alone.
+var timescaleSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS timescaledb`,
+
+	`ALTER TABLE model_updates DROP CONSTRAINT IF EXISTS model_updates_pkey`,
+	`ALTER TABLE model_updates ALTER COLUMN timestamp SET NOT NULL`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_model_updates_id ON model_updates(id, timestamp)`,
+	`SELECT create_hypertable('model_updates', 'timestamp', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE, migrate_data => TRUE)`,
+
+	`ALTER TABLE resource_metrics DROP CONSTRAINT IF EXISTS resource_metrics_pkey`,
+	`ALTER TABLE resource_metrics ALTER COLUMN timestamp SET NOT NULL`,
+	`SELECT create_hypertable('resource_metrics', 'timestamp', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE, migrate_data => TRUE)`,
+
+	`ALTER TABLE events DROP CONSTRAINT IF EXISTS events_pkey`,
+	`ALTER TABLE events ALTER COLUMN timestamp SET NOT NULL`,
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM timescaledb_information.hypertables WHERE hypertable_name = 'events') THEN
+			DROP INDEX IF EXISTS idx_events_event_id;
+			CREATE UNIQUE INDEX idx_events_event_id ON events(event_id, timestamp);
+		END IF;
+	END $$`,
+	`SELECT create_hypertable('events', 'timestamp', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE, migrate_data => TRUE)`,
+
+	// Hourly rollups, which also answer for the last hour from raw rows
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS model_updates_hourly
+	WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+	SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, federation_id, collaborator_id,
+	       COUNT(*) AS updates,
+	       SUM(update_size) AS update_bytes,
+	       SUM(processing_time) AS processing_time_sum,
+	       MIN(processing_time) AS processing_time_min,
+	       MAX(processing_time) AS processing_time_max,
+	       AVG(staleness) AS staleness_avg
+	FROM model_updates
+	GROUP BY bucket, federation_id, collaborator_id
+	WITH NO DATA`,
+
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS resource_metrics_hourly
+	WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+	SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, source_id,
+	       COUNT(*) AS samples,
+	       SUM(cpu_usage) AS cpu_usage_sum,
+	       MIN(cpu_usage) AS cpu_usage_min,
+	       MAX(cpu_usage) AS cpu_usage_max,
+	       SUM(memory_usage) AS memory_usage_sum,
+	       MIN(memory_usage) AS memory_usage_min,
+	       MAX(memory_usage) AS memory_usage_max,
+	       AVG(disk_usage) AS disk_usage_avg,
+	       SUM(network_in) AS network_in,
+	       SUM(network_out) AS network_out
+	FROM resource_metrics
+	GROUP BY bucket, source_id
+	WITH NO DATA`,
+
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS events_hourly
+	WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+	SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, federation_id, event_type, severity,
+	       COUNT(*) AS events
+	FROM events
+	GROUP BY bucket, federation_id, event_type, severity
+	WITH NO DATA`,
+
+	`SELECT add_continuous_aggregate_policy('model_updates_hourly', start_offset => INTERVAL '3 hours', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 hour', if_not_exists => TRUE)`,
+	`SELECT add_continuous_aggregate_policy('resource_metrics_hourly', start_offset => INTERVAL '3 hours', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 hour', if_not_exists => TRUE)`,
+	`SELECT add_continuous_aggregate_policy('events_hourly', start_offset => INTERVAL '3 hours', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 hour', if_not_exists => TRUE)`,
+}
+
+// initTimescale converts the high-volume tables to hypertables and creates
+// their rollups
+func (p *PostgreSQLStorage) initTimescale() error {
+	for _, schema := range timescaleSchema {
+		if _, err := p.db.Exec(schema); err != nil {
+			return fmt.Errorf("failed to execute schema: %s, error: %w", schema, err)
+		}
+	}
+	return nil
+}
+
+// updateConflictKey returns the unique key model updates are deduplicated on
+func (p *PostgreSQLStorage) updateConflictKey() string {
+	if p.config.TimescaleDB {
+		return "id, timestamp"
+	}
+	return "id"
+}
+
+// eventConflictKey returns the unique key events are deduplicated on
+func (p *PostgreSQLStorage) eventConflictKey() string {
+	if p.config.TimescaleDB {
+		return "event_id, timestamp"
+	}
+	return "event_id"
+}
+
+// dropChunks removes the raw rows of the hypertables older than cutoff. The
+// rollups of the dropped rows are kept.
+func (p *PostgreSQLStorage) dropChunks(cutoff time.Time) error {
+	for _, table := range []string{"resource_metrics", "events", "model_updates"} {
+		if _, err := p.db.Exec(`SELECT drop_chunks($1, older_than => $2::timestamptz)`, table, cutoff); err != nil {
+			return fmt.Errorf("failed to drop chunks of %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// seriesRollup is the aggregate of the samples of a metric in one rollup
+// bucket
+type seriesRollup struct {
+	Timestamp time.Time
+	Count     int
+	Sum       float64
+	Min       float64
+	Max       float64
+}
+
+// usesRollups reports whether a time series query is answered from the
+// rollups rather than raw rows
+func (p *PostgreSQLStorage) usesRollups(query *TimeSeriesQuery) bool {
+	interval := query.Interval
+	if interval <= 0 {
+		interval = query.EndTime.Sub(query.StartTime) / defaultSeriesBuckets
+	}
+	return p.config.TimescaleDB && interval >= rollupInterval
+}
+
+// queryRollups selects the rollups of a metric in the query's time range,
+// reporting false for metrics without rollups
+func (p *PostgreSQLStorage) queryRollups(ctx context.Context, federationID string, metric TimeSeriesMetric, query *TimeSeriesQuery) ([]seriesRollup, bool, error) {
+	var rollupsQuery string
+	args := []interface{}{federationID, query.StartTime, query.EndTime}
+
+	switch metric {
+	case SeriesUpdateLatency:
+		rollupsQuery = `
+			SELECT bucket, SUM(updates), SUM(processing_time_sum), MIN(processing_time_min), MAX(processing_time_max)
+			FROM model_updates_hourly WHERE federation_id = $1
+		`
+	case SeriesCPU, SeriesMemory:
+		column := "cpu_usage"
+		if metric == SeriesMemory {
+			column = "memory_usage"
+		}
+		sources := `(source_id = 'aggregator' OR source_id IN (SELECT id FROM collaborators WHERE federation_id = $1))`
+		if query.Source != "" {
+			sources = `source_id = $1`
+			args[0] = query.Source
+		}
+		rollupsQuery = fmt.Sprintf(`
+			SELECT bucket, SUM(samples), SUM(%[1]s_sum), MIN(%[1]s_min), MAX(%[1]s_max)
+			FROM resource_metrics_hourly WHERE %[2]s
+		`, column, sources)
+	default:
+		return nil, false, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, rollupsQuery+` AND bucket >= $2 AND bucket <= $3 GROUP BY bucket`, args...)
+	if err != nil {
+		return nil, true, err
+	}
+	defer rows.Close()
+
+	var rollups []seriesRollup
+	for rows.Next() {
+		var rollup seriesRollup
+		if err := rows.Scan(&rollup.Timestamp, &rollup.Count, &rollup.Sum, &rollup.Min, &rollup.Max); err != nil {
+			return nil, true, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, true, rows.Err()
+}
+
+// mergeRollups combines rollups into the buckets of a time series, like
+// downsample does for raw samples. Rollups are assigned to buckets by their
+// start, so bucket boundaries are rounded to the rollup interval.
+func mergeRollups(rollups []seriesRollup, start, end time.Time, interval time.Duration) []TimeSeriesBucket {
+	if interval <= 0 {
+		interval = end.Sub(start) / defaultSeriesBuckets
+		if interval <= 0 {
+			interval = time.Second
+		}
+	}
+
+	buckets := make(map[int64]*TimeSeriesBucket)
+	for _, rollup := range rollups {
+		if rollup.Count == 0 || rollup.Timestamp.Before(start) || rollup.Timestamp.After(end) {
+			continue
+		}
+
+		index := int64(rollup.Timestamp.Sub(start) / interval)
+		bucket, exists := buckets[index]
+		if !exists {
+			bucket = &TimeSeriesBucket{
+				Timestamp: start.Add(time.Duration(index) * interval),
+				Min:       rollup.Min,
+				Max:       rollup.Max,
+			}
+			buckets[index] = bucket
+		}
+
+		bucket.Avg += rollup.Sum
+		bucket.Count += rollup.Count
+		if rollup.Min < bucket.Min {
+			bucket.Min = rollup.Min
+		}
+		if rollup.Max > bucket.Max {
+			bucket.Max = rollup.Max
+		}
+	}
+
+	result := make([]TimeSeriesBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		bucket.Avg /= float64(bucket.Count)
+		result = append(result, *bucket)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+
+	return result
+}
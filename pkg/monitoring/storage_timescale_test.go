@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeRollups(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rollups := []seriesRollup{
+		{Timestamp: start, Count: 2, Sum: 30, Min: 10, Max: 20},
+		{Timestamp: start.Add(time.Hour), Count: 1, Sum: 60, Min: 60, Max: 60},
+		{Timestamp: start.Add(2 * time.Hour), Count: 4, Sum: 8, Min: 1, Max: 3},
+		{Timestamp: start.Add(5 * time.Hour), Count: 1, Sum: 5, Min: 5, Max: 5}, // after the range
+	}
+
+	buckets := mergeRollups(rollups, start, start.Add(4*time.Hour), 2*time.Hour)
+	want := []TimeSeriesBucket{
+		{Timestamp: start, Avg: 30, Min: 10, Max: 60, Count: 3},
+		{Timestamp: start.Add(2 * time.Hour), Avg: 2, Min: 1, Max: 3, Count: 4},
+	}
+	if !reflect.DeepEqual(buckets, want) {
+		t.Errorf("mergeRollups() = %+v, want %+v", buckets, want)
+	}
+}
+
+func TestUsesRollups(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timescale := &PostgreSQLStorage{config: DatabaseConfig{TimescaleDB: true}}
+
+	tests := []struct {
+		name    string
+		storage *PostgreSQLStorage
+		query   *TimeSeriesQuery
+		want    bool
+	}{
+		{"hourly buckets", timescale, &TimeSeriesQuery{StartTime: start, EndTime: start.Add(24 * time.Hour), Interval: time.Hour}, true},
+		{"minute buckets", timescale, &TimeSeriesQuery{StartTime: start, EndTime: start.Add(24 * time.Hour), Interval: time.Minute}, false},
+		{"default buckets of a long range", timescale, &TimeSeriesQuery{StartTime: start, EndTime: start.Add(30 * 24 * time.Hour)}, true},
+		{"plain PostgreSQL", &PostgreSQLStorage{}, &TimeSeriesQuery{StartTime: start, EndTime: start.Add(24 * time.Hour), Interval: time.Hour}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.storage.usesRollups(tt.query); got != tt.want {
+				t.Errorf("usesRollups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
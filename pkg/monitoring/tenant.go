@@ -0,0 +1,910 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tenantService scopes a MonitoringService to the organization of the
+// caller. Requests by users with an organization only see and write the
+// federations of that organization; requests by server-wide users, and
+// requests made by the server itself, pass through unchanged. A federation
+// belongs to the organization that first reports it, as recorded in the
+// OrgID of the stored federation.
+type tenantService struct {
+	MonitoringService
+
+	mu      sync.RWMutex
+	owners  map[string]string // federation ID -> organization, cached from storage
+	sources map[string]string // resource metrics source -> organization, cached from storage
+}
+
+// errOtherOrganization is returned for writes to data owned by another
+// organization
+var errOtherOrganization = errors.New("belongs to another organization")
+
+// aggregatorSourcePrefix prefixes the federation ID in the resource source
+// an aggregator reports its own usage under
+const aggregatorSourcePrefix = "aggregator-"
+
+// errHidden is returned for reads of data owned by another organization,
+// which is reported as not found
+var errHidden = errors.New("not found")
+
+func newTenantService(service MonitoringService) *tenantService {
+	return &tenantService{
+		MonitoringService: service,
+		owners:            make(map[string]string),
+		sources:           make(map[string]string),
+	}
+}
+
+// orgFromContext returns the organization of the user that made a request
+func orgFromContext(ctx context.Context) string {
+	if user, ok := GetUserFromContext(ctx); ok {
+		return user.OrgID
+	}
+	return ""
+}
+
+// owner returns the organization that owns a federation, and whether the
+// federation is known at all. Owners are cached, and otherwise resolved from
+// the OrgID of the stored federation, so that ownership survives restarts and
+// is shared by every server over the same storage. A stored federation
+// without an OrgID was reported by a server-wide user and belongs to no
+// organization.
+func (t *tenantService) owner(ctx context.Context, federationID string) (string, bool, error) {
+	t.mu.RLock()
+	owner, exists := t.owners[federationID]
+	t.mu.RUnlock()
+	if exists {
+		return owner, true, nil
+	}
+
+	stored, err := t.MonitoringService.GetFederationHistory(ctx, &MetricsFilter{FederationID: federationID})
+	if err != nil || len(stored) == 0 {
+		return "", false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.owners[federationID] = stored[0].OrgID
+	return stored[0].OrgID, true, nil
+}
+
+// claim checks that org may write to a federation, assigning the federation
+// to org if it is not known yet
+func (t *tenantService) claim(ctx context.Context, org, federationID string) error {
+	if federationID == "" {
+		return fmt.Errorf("federation_id is required")
+	}
+	owner, exists, err := t.owner(ctx, federationID)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !exists {
+		// Another request may have claimed the federation since the lookup
+		if owner, exists = t.owners[federationID]; !exists {
+			t.owners[federationID] = org
+			return nil
+		}
+	}
+	if owner != org {
+		return fmt.Errorf("federation %s %w", federationID, errOtherOrganization)
+	}
+	return nil
+}
+
+// check returns an error unless org owns a federation. Federations of other
+// organizations are reported as not found.
+func (t *tenantService) check(ctx context.Context, org, federationID string) error {
+	owner, exists, err := t.owner(ctx, federationID)
+	if err != nil {
+		return err
+	}
+	if !exists || owner != org {
+		return fmt.Errorf("federation %s %w", federationID, errHidden)
+	}
+	return nil
+}
+
+// owns reports whether org owns a federation
+func (t *tenantService) owns(ctx context.Context, org, federationID string) bool {
+	return t.check(ctx, org, federationID) == nil
+}
+
+// scope returns a copy of filter restricted to the federations of org, and
+// to the federations it already selected, if any. The owners of stored
+// federations are reloaded first, so federations registered through other
+// servers are included.
+func (t *tenantService) scope(ctx context.Context, org string, filter *MetricsFilter) (*MetricsFilter, error) {
+	stored, err := t.MonitoringService.GetFederationHistory(ctx, &MetricsFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := &MetricsFilter{}
+	if filter != nil {
+		*scoped = *filter
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, federation := range stored {
+		t.owners[federation.ID] = federation.OrgID
+	}
+	scoped.Federations = make(map[string]bool)
+	for federationID, owner := range t.owners {
+		if owner == org && (filter == nil || filter.Federations == nil || filter.Federations[federationID]) {
+			scoped.Federations[federationID] = true
+		}
+	}
+	return scoped, nil
+}
+
+// Federation metrics
+
+func (t *tenantService) RegisterFederation(ctx context.Context, metrics *FederationMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, metrics.ID); err != nil {
+			return err
+		}
+		metrics.OrgID = org
+	}
+	return t.MonitoringService.RegisterFederation(ctx, metrics)
+}
+
+func (t *tenantService) UpdateFederation(ctx context.Context, federationID string, metrics *FederationMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return err
+		}
+		metrics.OrgID = org
+	}
+	return t.MonitoringService.UpdateFederation(ctx, federationID, metrics)
+}
+
+func (t *tenantService) GetFederation(ctx context.Context, federationID string) (*FederationMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetFederation(ctx, federationID)
+}
+
+func (t *tenantService) GetActiveFederations(ctx context.Context) ([]*FederationMetrics, error) {
+	federations, err := t.MonitoringService.GetActiveFederations(ctx)
+	org := orgFromContext(ctx)
+	if err != nil || org == "" {
+		return federations, err
+	}
+
+	var results []*FederationMetrics
+	for _, federation := range federations {
+		if t.owns(ctx, org, federation.ID) {
+			results = append(results, federation)
+		}
+	}
+	return results, nil
+}
+
+func (t *tenantService) GetFederationHistory(ctx context.Context, filter *MetricsFilter) ([]*FederationMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetFederationHistory(ctx, filter)
+}
+
+func (t *tenantService) ImportFederationHistory(ctx context.Context, archive *ImportArchive) (*ImportResult, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, archive.FederationID()); err != nil {
+			return nil, err
+		}
+		if archive.Federation != nil {
+			archive.Federation.OrgID = org
+		}
+	}
+	return t.MonitoringService.ImportFederationHistory(ctx, archive)
+}
+
+// Collaborator metrics
+
+func (t *tenantService) RegisterCollaborator(ctx context.Context, metrics *CollaboratorMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, metrics.FederationID); err != nil {
+			return err
+		}
+		// Collaborator IDs are shared by every federation, and resource
+		// sources are resolved through them
+		if existing, err := t.MonitoringService.GetCollaborator(ctx, metrics.ID); err == nil && !t.owns(ctx, org, existing.FederationID) {
+			return fmt.Errorf("collaborator %s %w", metrics.ID, errOtherOrganization)
+		}
+	}
+	return t.MonitoringService.RegisterCollaborator(ctx, metrics)
+}
+
+func (t *tenantService) UpdateCollaborator(ctx context.Context, collaboratorID string, metrics *CollaboratorMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if _, err := t.GetCollaborator(ctx, collaboratorID); err != nil {
+			return err
+		}
+		if metrics.FederationID != "" {
+			if err := t.claim(ctx, org, metrics.FederationID); err != nil {
+				return err
+			}
+		}
+	}
+	return t.MonitoringService.UpdateCollaborator(ctx, collaboratorID, metrics)
+}
+
+func (t *tenantService) GetCollaborator(ctx context.Context, collaboratorID string) (*CollaboratorMetrics, error) {
+	collaborator, err := t.MonitoringService.GetCollaborator(ctx, collaboratorID)
+	if org := orgFromContext(ctx); err == nil && org != "" && !t.owns(ctx, org, collaborator.FederationID) {
+		return nil, fmt.Errorf("collaborator %s not found", collaboratorID)
+	}
+	return collaborator, err
+}
+
+func (t *tenantService) GetFederationCollaborators(ctx context.Context, federationID string) ([]*CollaboratorMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetFederationCollaborators(ctx, federationID)
+}
+
+func (t *tenantService) GetCollaboratorHistory(ctx context.Context, filter *MetricsFilter) ([]*CollaboratorMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetCollaboratorHistory(ctx, filter)
+}
+
+// Round metrics
+
+func (t *tenantService) RecordRoundStart(ctx context.Context, metrics *RoundMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, metrics.FederationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordRoundStart(ctx, metrics)
+}
+
+func (t *tenantService) RecordRoundEnd(ctx context.Context, roundID string, metrics *RoundMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if _, err := t.GetRound(ctx, roundID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordRoundEnd(ctx, roundID, metrics)
+}
+
+func (t *tenantService) GetRound(ctx context.Context, roundID string) (*RoundMetrics, error) {
+	round, err := t.MonitoringService.GetRound(ctx, roundID)
+	if org := orgFromContext(ctx); err == nil && org != "" && !t.owns(ctx, org, round.FederationID) {
+		return nil, fmt.Errorf("round %s not found", roundID)
+	}
+	return round, err
+}
+
+func (t *tenantService) GetFederationRounds(ctx context.Context, federationID string) ([]*RoundMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetFederationRounds(ctx, federationID)
+}
+
+func (t *tenantService) GetRoundHistory(ctx context.Context, filter *MetricsFilter) ([]*RoundMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetRoundHistory(ctx, filter)
+}
+
+// Model update metrics
+
+func (t *tenantService) RecordModelUpdate(ctx context.Context, metrics *ModelUpdateMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, metrics.FederationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordModelUpdate(ctx, metrics)
+}
+
+func (t *tenantService) GetModelUpdates(ctx context.Context, filter *MetricsFilter) ([]*ModelUpdateMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetModelUpdates(ctx, filter)
+}
+
+func (t *tenantService) GetUpdateStatistics(ctx context.Context, federationID string, roundNumber int) (*UpdateStatistics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetUpdateStatistics(ctx, federationID, roundNumber)
+}
+
+func (t *tenantService) RecordDroppedUpdate(ctx context.Context, dropped *DroppedUpdate) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, dropped.FederationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordDroppedUpdate(ctx, dropped)
+}
+
+func (t *tenantService) GetDroppedUpdateStats(ctx context.Context, filter *MetricsFilter) ([]*DroppedUpdateStats, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetDroppedUpdateStats(ctx, filter)
+}
+
+// Aggregation metrics
+
+func (t *tenantService) RecordAggregation(ctx context.Context, metrics *AggregationMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, metrics.FederationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordAggregation(ctx, metrics)
+}
+
+func (t *tenantService) GetAggregation(ctx context.Context, aggregationID string) (*AggregationMetrics, error) {
+	aggregation, err := t.MonitoringService.GetAggregation(ctx, aggregationID)
+	if org := orgFromContext(ctx); err == nil && org != "" && !t.owns(ctx, org, aggregation.FederationID) {
+		return nil, fmt.Errorf("aggregation %s not found", aggregationID)
+	}
+	return aggregation, err
+}
+
+func (t *tenantService) UpdateAggregation(ctx context.Context, aggregationID string, metrics *AggregationMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if _, err := t.GetAggregation(ctx, aggregationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.UpdateAggregation(ctx, aggregationID, metrics)
+}
+
+func (t *tenantService) GetAggregations(ctx context.Context, filter *MetricsFilter) ([]*AggregationMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetAggregations(ctx, filter)
+}
+
+func (t *tenantService) GetAggregationStatistics(ctx context.Context, federationID string) (*AggregationStatistics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetAggregationStatistics(ctx, federationID)
+}
+
+// Contribution scores
+
+func (t *tenantService) RecordContributions(ctx context.Context, scores []*ContributionScore) error {
+	if org := orgFromContext(ctx); org != "" {
+		for _, score := range scores {
+			if err := t.claim(ctx, org, score.FederationID); err != nil {
+				return err
+			}
+		}
+	}
+	return t.MonitoringService.RecordContributions(ctx, scores)
+}
+
+func (t *tenantService) GetContributions(ctx context.Context, filter *MetricsFilter) ([]*ContributionScore, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetContributions(ctx, filter)
+}
+
+func (t *tenantService) GetContributionSummary(ctx context.Context, federationID string) ([]*ContributionSummary, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetContributionSummary(ctx, federationID)
+}
+
+func (t *tenantService) GetLedger(ctx context.Context, filter *MetricsFilter) ([]*LedgerAccount, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetLedger(ctx, filter)
+}
+
+// Resource metrics are reported per source rather than per federation. A
+// source belongs to the organization of the federation it reports for: the
+// federation whose aggregator reports as aggregator-<federation ID> (or under
+// the bare federation ID), or the federation of the collaborator of that ID. The owner is recorded in storage when the
+// source first reports, so that it survives restarts, is shared by every
+// server over the same storage, and stays with the source if its
+// collaborator is registered again elsewhere. A source that reports for no
+// federation of an organization is never given to it.
+
+// sourceOwner returns the organization recorded as the owner of a resource
+// source, and whether it has one
+func (t *tenantService) sourceOwner(ctx context.Context, source string) (string, bool, error) {
+	t.mu.RLock()
+	owner, exists := t.sources[source]
+	t.mu.RUnlock()
+	if exists {
+		return owner, true, nil
+	}
+
+	stored, err := t.MonitoringService.GetResourceSource(ctx, source)
+	if errors.Is(err, errUnknownResourceSource) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sources[source] = stored.OrgID
+	return stored.OrgID, true, nil
+}
+
+// sourceFederation returns the federation a resource source reports for, if
+// it is owned by org
+func (t *tenantService) sourceFederation(ctx context.Context, org, source string) (string, bool) {
+	federationID, _ := strings.CutPrefix(source, aggregatorSourcePrefix)
+	if t.owns(ctx, org, federationID) {
+		return federationID, true
+	}
+	collaborator, err := t.MonitoringService.GetCollaborator(ctx, source)
+	if err != nil || !t.owns(ctx, org, collaborator.FederationID) {
+		return "", false
+	}
+	return collaborator.FederationID, true
+}
+
+// claimSource checks that org may report the resource usage of a source,
+// recording org as its owner if the source reports for one of its
+// federations and has no owner yet
+func (t *tenantService) claimSource(ctx context.Context, org, source string) error {
+	owner, exists, err := t.sourceOwner(ctx, source)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		federationID, ok := t.sourceFederation(ctx, org, source)
+		if !ok {
+			return fmt.Errorf("resource source %s is not a federation or collaborator of the organization: %w", source, errOtherOrganization)
+		}
+		// Another server may have recorded an owner since the lookup
+		stored, err := t.MonitoringService.RegisterResourceSource(ctx, &ResourceSource{ID: source, OrgID: org, FederationID: federationID})
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		t.sources[source] = stored.OrgID
+		t.mu.Unlock()
+		owner = stored.OrgID
+	}
+	if owner != org {
+		return fmt.Errorf("resource source %s %w", source, errOtherOrganization)
+	}
+	return nil
+}
+
+// ownsSource reports whether org owns a resource source. A source that has
+// not reported yet is owned by the organization it would be recorded for.
+func (t *tenantService) ownsSource(ctx context.Context, org, source string) (bool, error) {
+	owner, exists, err := t.sourceOwner(ctx, source)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return owner == org, nil
+	}
+	_, ok := t.sourceFederation(ctx, org, source)
+	return ok, nil
+}
+
+func (t *tenantService) RecordResourceMetrics(ctx context.Context, source string, metrics *ResourceMetrics) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claimSource(ctx, org, source); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordResourceMetrics(ctx, source, metrics)
+}
+
+func (t *tenantService) GetResourceMetrics(ctx context.Context, source string, timeRange time.Duration) ([]*ResourceMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		owned, err := t.ownsSource(ctx, org, source)
+		if err != nil {
+			return nil, err
+		}
+		if !owned {
+			return []*ResourceMetrics{}, nil
+		}
+	}
+	return t.MonitoringService.GetResourceMetrics(ctx, source, timeRange)
+}
+
+func (t *tenantService) GetSystemOverview(ctx context.Context, federationID string) (*SystemOverview, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetSystemOverview(ctx, federationID)
+}
+
+// Events and alerts
+
+func (t *tenantService) RecordEvent(ctx context.Context, event *MonitoringEvent) error {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.claim(ctx, org, event.FederationID); err != nil {
+			return err
+		}
+	}
+	return t.MonitoringService.RecordEvent(ctx, event)
+}
+
+func (t *tenantService) GetEvents(ctx context.Context, filter *MetricsFilter) ([]*MonitoringEvent, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped, err := t.scope(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = scoped
+	}
+	return t.MonitoringService.GetEvents(ctx, filter)
+}
+
+func (t *tenantService) GetActiveAlerts(ctx context.Context, federationID string) ([]*Alert, error) {
+	org := orgFromContext(ctx)
+	if org != "" && federationID != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	alerts, err := t.MonitoringService.GetActiveAlerts(ctx, federationID)
+	if err != nil || org == "" || federationID != "" {
+		return alerts, err
+	}
+
+	results := make([]*Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if t.owns(ctx, org, alert.FederationID) {
+			results = append(results, alert)
+		}
+	}
+	return results, nil
+}
+
+// Analytics and insights
+
+func (t *tenantService) GetPerformanceInsights(ctx context.Context, federationID string) (*PerformanceInsights, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetPerformanceInsights(ctx, federationID)
+}
+
+func (t *tenantService) GetConvergenceAnalysis(ctx context.Context, federationID string) (*ConvergenceAnalysis, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetConvergenceAnalysis(ctx, federationID)
+}
+
+func (t *tenantService) GetEfficiencyMetrics(ctx context.Context, federationID string) (*EfficiencyMetrics, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.GetEfficiencyMetrics(ctx, federationID)
+}
+
+// GetTimeSeries checks the source of resource series as well as the
+// federation, since a source replaces the federation's own sources in the
+// query
+func (t *tenantService) GetTimeSeries(ctx context.Context, federationID string, query *TimeSeriesQuery) ([]*TimeSeries, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+		if query.Source != "" {
+			owned, err := t.ownsSource(ctx, org, query.Source)
+			if err != nil {
+				return nil, err
+			}
+			if !owned {
+				return nil, fmt.Errorf("resource source %s %w", query.Source, errHidden)
+			}
+		}
+	}
+	return t.MonitoringService.GetTimeSeries(ctx, federationID, query)
+}
+
+// Dashboards belong to the organization that created them
+
+func (t *tenantService) CreateDashboard(ctx context.Context, dashboard *Dashboard) error {
+	dashboard.OrgID = orgFromContext(ctx)
+	return t.MonitoringService.CreateDashboard(ctx, dashboard)
+}
+
+func (t *tenantService) GetDashboard(ctx context.Context, dashboardID string) (*Dashboard, error) {
+	dashboard, err := t.MonitoringService.GetDashboard(ctx, dashboardID)
+	if org := orgFromContext(ctx); err == nil && org != "" && dashboard.OrgID != org {
+		return nil, fmt.Errorf("dashboard %s not found", dashboardID)
+	}
+	return dashboard, err
+}
+
+func (t *tenantService) ListDashboards(ctx context.Context) ([]*Dashboard, error) {
+	dashboards, err := t.MonitoringService.ListDashboards(ctx)
+	org := orgFromContext(ctx)
+	if err != nil || org == "" {
+		return dashboards, err
+	}
+
+	results := make([]*Dashboard, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		if dashboard.OrgID == org {
+			results = append(results, dashboard)
+		}
+	}
+	return results, nil
+}
+
+func (t *tenantService) UpdateDashboard(ctx context.Context, dashboardID string, dashboard *Dashboard) error {
+	existing, err := t.GetDashboard(ctx, dashboardID)
+	if err != nil {
+		return err
+	}
+	dashboard.OrgID = existing.OrgID
+	return t.MonitoringService.UpdateDashboard(ctx, dashboardID, dashboard)
+}
+
+func (t *tenantService) DeleteDashboard(ctx context.Context, dashboardID string) error {
+	if _, err := t.GetDashboard(ctx, dashboardID); err != nil {
+		return err
+	}
+	return t.MonitoringService.DeleteDashboard(ctx, dashboardID)
+}
+
+// API keys belong to the organization they were issued for. Keys are looked
+// up by hash before the caller is known, so GetAPIKeyByHash is not scoped.
+
+func (t *tenantService) GetAPIKey(ctx context.Context, keyID string) (*APIKey, error) {
+	key, err := t.MonitoringService.GetAPIKey(ctx, keyID)
+	if org := orgFromContext(ctx); err == nil && org != "" && key.OrgID != org {
+		return nil, fmt.Errorf("API key %s not found", keyID)
+	}
+	return key, err
+}
+
+func (t *tenantService) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	keys, err := t.MonitoringService.ListAPIKeys(ctx)
+	org := orgFromContext(ctx)
+	if err != nil || org == "" {
+		return keys, err
+	}
+
+	results := make([]*APIKey, 0, len(keys))
+	for _, key := range keys {
+		if key.OrgID == org {
+			results = append(results, key)
+		}
+	}
+	return results, nil
+}
+
+func (t *tenantService) UpdateAPIKey(ctx context.Context, keyID string, key *APIKey) error {
+	if _, err := t.GetAPIKey(ctx, keyID); err != nil {
+		return err
+	}
+	return t.MonitoringService.UpdateAPIKey(ctx, keyID, key)
+}
+
+// Notification subscriptions belong to the organization that created them,
+// and may only name federations of that organization
+
+func (t *tenantService) CreateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error {
+	org := orgFromContext(ctx)
+	if err := t.checkFederations(ctx, org, subscription.FederationIDs); err != nil {
+		return err
+	}
+	subscription.OrgID = org
+	return t.MonitoringService.CreateNotificationSubscription(ctx, subscription)
+}
+
+func (t *tenantService) GetNotificationSubscription(ctx context.Context, subscriptionID string) (*NotificationSubscription, error) {
+	subscription, err := t.MonitoringService.GetNotificationSubscription(ctx, subscriptionID)
+	if org := orgFromContext(ctx); err == nil && org != "" && subscription.OrgID != org {
+		return nil, fmt.Errorf("notification subscription %s not found", subscriptionID)
+	}
+	return subscription, err
+}
+
+func (t *tenantService) ListNotificationSubscriptions(ctx context.Context) ([]*NotificationSubscription, error) {
+	subscriptions, err := t.MonitoringService.ListNotificationSubscriptions(ctx)
+	org := orgFromContext(ctx)
+	if err != nil || org == "" {
+		return subscriptions, err
+	}
+
+	results := make([]*NotificationSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscription.OrgID == org {
+			results = append(results, subscription)
+		}
+	}
+	return results, nil
+}
+
+func (t *tenantService) UpdateNotificationSubscription(ctx context.Context, subscriptionID string, subscription *NotificationSubscription) error {
+	existing, err := t.GetNotificationSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if err := t.checkFederations(ctx, orgFromContext(ctx), subscription.FederationIDs); err != nil {
+		return err
+	}
+	subscription.OrgID = existing.OrgID
+	return t.MonitoringService.UpdateNotificationSubscription(ctx, subscriptionID, subscription)
+}
+
+func (t *tenantService) DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error {
+	if _, err := t.GetNotificationSubscription(ctx, subscriptionID); err != nil {
+		return err
+	}
+	return t.MonitoringService.DeleteNotificationSubscription(ctx, subscriptionID)
+}
+
+// checkFederations returns an error unless org owns all of federationIDs.
+// Server-wide users may name any federation.
+func (t *tenantService) checkFederations(ctx context.Context, org string, federationIDs []string) error {
+	if org == "" {
+		return nil
+	}
+	for _, federationID := range federationIDs {
+		if err := t.check(ctx, org, federationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Audit log
+
+func (t *tenantService) GetAuditLog(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error) {
+	if org := orgFromContext(ctx); org != "" {
+		scoped := &AuditFilter{}
+		if filter != nil {
+			*scoped = *filter
+		}
+		scoped.OrgID = org
+		filter = scoped
+	}
+	return t.MonitoringService.GetAuditLog(ctx, filter)
+}
+
+// Real-time subscriptions
+
+func (t *tenantService) SubscribeToEvents(ctx context.Context, federationID string, eventTypes []MetricType) (<-chan *MonitoringEvent, error) {
+	if org := orgFromContext(ctx); org != "" {
+		if federationID == "" {
+			return nil, fmt.Errorf("federation_id is required")
+		}
+		if err := t.check(ctx, org, federationID); err != nil {
+			return nil, err
+		}
+	}
+	return t.MonitoringService.SubscribeToEvents(ctx, federationID, eventTypes)
+}
+
+// Health and status
+
+func (t *tenantService) GetMetricsStats(ctx context.Context) (*MetricsStats, error) {
+	stats, err := t.MonitoringService.GetMetricsStats(ctx)
+	org := orgFromContext(ctx)
+	if err != nil || org == "" {
+		return stats, err
+	}
+
+	federations, err := t.GetFederationHistory(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	collaborators, err := t.GetCollaboratorHistory(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	rounds, err := t.GetRoundHistory(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	updates, err := t.GetModelUpdates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := &MetricsStats{
+		TotalFederations:   len(federations),
+		TotalCollaborators: len(collaborators),
+		TotalRounds:        len(rounds),
+		TotalUpdates:       len(updates),
+		LastCleanup:        stats.LastCleanup,
+		UptimeSeconds:      stats.UptimeSeconds,
+	}
+	for _, federation := range federations {
+		if federation.Status == StatusRunning {
+			scoped.ActiveFederations++
+		}
+	}
+	for _, collaborator := range collaborators {
+		if collaborator.Status == CollabStatusConnected || collaborator.Status == CollabStatusTraining {
+			scoped.ActiveCollaborators++
+		}
+	}
+	return scoped, nil
+}
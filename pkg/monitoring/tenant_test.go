@@ -0,0 +1,288 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTenantTestConfig() *MonitoringConfig {
+	return &MonitoringConfig{
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeyAuth: APIKeyConfig{
+				Enabled: true,
+				Keys: map[string]string{
+					"admin-key": RoleAdmin,
+					"lab-a-key": RoleAdmin,
+					"lab-b-key": RoleMonitor,
+				},
+				Orgs: map[string]string{"lab-a-key": "lab-a", "lab-b-key": "lab-b"},
+			},
+		},
+	}
+}
+
+func newTenantTestServer() *APIServer {
+	config := newTenantTestConfig()
+	return NewAPIServer(NewMemoryStorage(config), config)
+}
+
+func TestTenantScoping(t *testing.T) {
+	server := newTenantTestServer()
+
+	writes := []struct {
+		name   string
+		apiKey string
+		path   string
+		body   string
+		want   int
+	}{
+		{"lab-a registers its federation", "lab-a-key", "/api/v1/federations", `{"id": "fed-a", "name": "A"}`, http.StatusOK},
+		{"lab-a reports an update", "lab-a-key", "/api/v1/updates", `{"federation_id": "fed-a", "collaborator_id": "a1", "round_number": 1}`, http.StatusOK},
+		{"lab-b reports to its own federation", "lab-b-key", "/api/v1/updates", `{"federation_id": "fed-b", "collaborator_id": "b1", "round_number": 1}`, http.StatusOK},
+		{"lab-b cannot report to lab-a", "lab-b-key", "/api/v1/updates", `{"federation_id": "fed-a", "collaborator_id": "b1", "round_number": 1}`, http.StatusForbidden},
+		{"lab-b cannot register lab-a's federation", "lab-b-key", "/api/v1/federations", `{"id": "fed-a"}`, http.StatusForbidden},
+	}
+	for _, tt := range writes {
+		if code := doAPIKeyRequest(t, server, "POST", tt.path, tt.apiKey, tt.body, nil); code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+
+	reads := []struct {
+		apiKey string
+		want   map[string]bool
+	}{
+		{"lab-a-key", map[string]bool{"fed-a": true}},
+		{"lab-b-key", map[string]bool{"fed-b": true}},
+		{"admin-key", map[string]bool{"fed-a": true, "fed-b": true}},
+	}
+	for _, tt := range reads {
+		var updates []*ModelUpdateMetrics
+		if code := doAPIKeyRequest(t, server, "GET", "/api/v1/updates", tt.apiKey, "", &updates); code != http.StatusOK {
+			t.Fatalf("%s: list updates status = %d", tt.apiKey, code)
+		}
+		if len(updates) != len(tt.want) {
+			t.Errorf("%s: got %d updates, want %d", tt.apiKey, len(updates), len(tt.want))
+		}
+		for _, update := range updates {
+			if !tt.want[update.FederationID] {
+				t.Errorf("%s: got update of %s", tt.apiKey, update.FederationID)
+			}
+		}
+	}
+
+	// Another organization's federation is not found rather than forbidden
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a", "lab-b-key", "", nil); code != http.StatusNotFound {
+		t.Errorf("lab-b get fed-a status = %d, want 404", code)
+	}
+	var federation FederationMetrics
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a", "lab-a-key", "", &federation); code != http.StatusOK || federation.OrgID != "lab-a" {
+		t.Errorf("lab-a get fed-a = %d, %+v, want 200 owned by lab-a", code, federation)
+	}
+}
+
+func TestTenantOwnershipFromStorage(t *testing.T) {
+	config := newTenantTestConfig()
+	storage := NewMemoryStorage(config)
+	first := NewAPIServer(storage, config)
+	doAPIKeyRequest(t, first, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil)
+	doAPIKeyRequest(t, first, "POST", "/api/v1/federations", "admin-key", `{"id": "fed-shared", "name": "Shared"}`, nil)
+
+	// A restarted server, or another replica, knows the owners only from storage
+	server := NewAPIServer(storage, config)
+	tests := []struct {
+		name   string
+		method string
+		apiKey string
+		path   string
+		body   string
+		want   int
+	}{
+		{"lab-b cannot claim lab-a's federation", "POST", "lab-b-key", "/api/v1/updates", `{"federation_id": "fed-a", "collaborator_id": "b1", "round_number": 1}`, http.StatusForbidden},
+		{"lab-b cannot claim a server-wide federation", "POST", "lab-b-key", "/api/v1/updates", `{"federation_id": "fed-shared", "collaborator_id": "b1", "round_number": 1}`, http.StatusForbidden},
+		{"lab-a reads its federation", "GET", "lab-a-key", "/api/v1/federations/fed-a", "", http.StatusOK},
+		{"lab-b cannot read lab-a's federation", "GET", "lab-b-key", "/api/v1/federations/fed-a", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code := doAPIKeyRequest(t, server, tt.method, tt.path, tt.apiKey, tt.body, nil); code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+
+	var federations []*FederationMetrics
+	doAPIKeyRequest(t, server, "GET", "/api/v1/federations", "lab-a-key", "", &federations)
+	if len(federations) != 1 || federations[0].ID != "fed-a" {
+		t.Errorf("lab-a lists %v, want only fed-a", federations)
+	}
+}
+
+func TestTenantAPIKeys(t *testing.T) {
+	server := newTenantTestServer()
+
+	var issued IssuedAPIKey
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys", "lab-a-key", `{"name": "agg", "role": "monitor"}`, &issued); code != http.StatusOK {
+		t.Fatalf("create key status = %d, want 200", code)
+	}
+	if issued.OrgID != "lab-a" {
+		t.Errorf("issued key org = %q, want lab-a", issued.OrgID)
+	}
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys", "lab-a-key", `{"role": "monitor", "org_id": "lab-b"}`, nil); code == http.StatusOK {
+		t.Error("lab-a issued a key for lab-b")
+	}
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/apikeys", "admin-key", `{"role": "readonly", "org_id": "lab-b"}`, nil); code != http.StatusOK {
+		t.Errorf("server-wide admin create lab-b key status = %d, want 200", code)
+	}
+
+	// Each organization only lists its own keys
+	var keys []*APIKey
+	doAPIKeyRequest(t, server, "GET", "/api/v1/apikeys", "lab-a-key", "", &keys)
+	if len(keys) != 1 || keys[0].ID != issued.ID {
+		t.Errorf("lab-a keys = %+v, want only %s", keys, issued.ID)
+	}
+	doAPIKeyRequest(t, server, "GET", "/api/v1/apikeys", "admin-key", "", &keys)
+	if len(keys) != 2 {
+		t.Errorf("server-wide keys = %d, want 2", len(keys))
+	}
+
+	// The issued key writes into its organization's federations
+	doAPIKeyRequest(t, server, "POST", "/api/v1/updates", issued.Key, `{"federation_id": "fed-a", "collaborator_id": "a1"}`, nil)
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/updates", "lab-b-key", `{"federation_id": "fed-a", "collaborator_id": "b1"}`, nil); code != http.StatusForbidden {
+		t.Errorf("lab-b write to fed-a status = %d, want 403", code)
+	}
+}
+
+func TestCallerOrg(t *testing.T) {
+	tests := []struct {
+		name      string
+		callerOrg string
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{"defaults to the caller's organization", "lab-a", "", "lab-a", false},
+		{"own organization", "lab-a", "lab-a", "lab-a", false},
+		{"other organization", "lab-a", "lab-b", "", true},
+		{"server-wide caller scopes", "", "lab-b", "lab-b", false},
+		{"server-wide stays server-wide", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := callerOrg(&UserContext{OrgID: tt.callerOrg}, tt.requested)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("callerOrg() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTCarriesOrganization(t *testing.T) {
+	am, err := NewAuthManager(AuthConfig{Enabled: true, JWTAuth: JWTConfig{Enabled: true, Secret: "secret"}})
+	if err != nil {
+		t.Fatalf("NewAuthManager() error = %v", err)
+	}
+
+	token, err := am.IssueToken(&UserContext{UserID: "alice", Role: RoleMonitor, OrgID: "lab-a"}, TokenRequest{Role: RoleReadOnly})
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if token.OrgID != "lab-a" {
+		t.Errorf("token org = %q, want lab-a", token.OrgID)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/federations", nil)
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	user, err := am.AuthenticateRequest(req)
+	if err != nil {
+		t.Fatalf("AuthenticateRequest() error = %v", err)
+	}
+	if user.OrgID != "lab-a" || user.Role != RoleReadOnly {
+		t.Errorf("user = %+v, want readonly in lab-a", user)
+	}
+}
+
+func TestTenantTimeSeriesSource(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/collaborators", "lab-a-key", `{"id": "a-host", "federation_id": "fed-a"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/collaborators", "lab-b-key", `{"id": "b-secret-host", "federation_id": "fed-b"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/resources/a-host", "lab-a-key", `{"cpu_usage_percent": 10}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/resources/b-secret-host", "lab-b-key", `{"cpu_usage_percent": 90}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/resources/aggregator", "admin-key", `{"cpu_usage_percent": 50}`, nil)
+
+	tests := []struct {
+		name   string
+		apiKey string
+		path   string
+		want   int
+	}{
+		{"own source", "lab-a-key", "/api/v1/federations/fed-a/timeseries?metrics=cpu&source=a-host", http.StatusOK},
+		{"another organization's source", "lab-a-key", "/api/v1/federations/fed-a/timeseries?metrics=cpu&source=b-secret-host", http.StatusNotFound},
+		{"server-wide source", "lab-a-key", "/api/v1/federations/fed-a/timeseries?metrics=cpu&source=aggregator", http.StatusNotFound},
+		{"another organization's federation", "lab-b-key", "/api/v1/federations/fed-a/timeseries?metrics=cpu", http.StatusNotFound},
+		{"unknown federation", "admin-key", "/api/v1/federations/missing/timeseries?metrics=cpu", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code := doAPIKeyRequest(t, server, "GET", tt.path, tt.apiKey, "", nil); code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+}
+
+func TestTenantResourceSources(t *testing.T) {
+	config := newTenantTestConfig()
+	storage := NewMemoryStorage(config)
+	first := NewAPIServer(storage, config)
+	sample := fmt.Sprintf(`{"timestamp": %q, "cpu_usage_percent": 10}`, time.Now().Format(time.RFC3339Nano))
+	doAPIKeyRequest(t, first, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A"}`, nil)
+	doAPIKeyRequest(t, first, "POST", "/api/v1/collaborators", "lab-a-key", `{"id": "a1", "federation_id": "fed-a"}`, nil)
+	doAPIKeyRequest(t, first, "POST", "/api/v1/resources/a1", "lab-a-key", sample, nil)
+
+	// A restarted server, or another replica, knows the owners only from storage
+	server := NewAPIServer(storage, config)
+	writes := []struct {
+		name   string
+		apiKey string
+		path   string
+		body   string
+		want   int
+	}{
+		{"lab-a reports its aggregator", "lab-a-key", "/api/v1/resources/aggregator-fed-a", sample, http.StatusOK},
+		{"lab-b cannot report lab-a's collaborator", "lab-b-key", "/api/v1/resources/a1", sample, http.StatusForbidden},
+		{"lab-b cannot report a source of no federation", "lab-b-key", "/api/v1/resources/some-host", sample, http.StatusForbidden},
+		{"lab-b cannot take over lab-a's collaborator", "lab-b-key", "/api/v1/collaborators", `{"id": "a1", "federation_id": "fed-b"}`, http.StatusForbidden},
+	}
+	for _, tt := range writes {
+		if code := doAPIKeyRequest(t, server, "POST", tt.path, tt.apiKey, tt.body, nil); code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+
+	reads := []struct {
+		apiKey string
+		source string
+		want   int
+	}{
+		{"lab-a-key", "a1", 1},
+		{"lab-a-key", "aggregator-fed-a", 1},
+		{"lab-b-key", "a1", 0},
+		{"admin-key", "a1", 1},
+	}
+	for _, tt := range reads {
+		var metrics []*ResourceMetrics
+		if code := doAPIKeyRequest(t, server, "GET", "/api/v1/resources/"+tt.source, tt.apiKey, "", &metrics); code != http.StatusOK {
+			t.Fatalf("%s: get %s status = %d", tt.apiKey, tt.source, code)
+		}
+		if len(metrics) != tt.want {
+			t.Errorf("%s: got %d samples of %s, want %d", tt.apiKey, len(metrics), tt.source, tt.want)
+		}
+	}
+	if source, err := storage.GetResourceSource(context.Background(), "a1"); err != nil || source.OrgID != "lab-a" {
+		t.Errorf("GetResourceSource(a1) = %+v, %v, want owned by lab-a", source, err)
+	}
+}
@@ -0,0 +1,163 @@
+package monitoring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TensorBoardWriter appends scalars and histograms to a TensorBoard event
+// file. Events are TensorFlow Event protos framed as TFRecords, so TensorBoard
+// and other tfevents readers load them without TensorFlow being installed.
+type TensorBoardWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewTensorBoardWriter creates an event file in dir, named the way
+// TensorBoard expects
+func NewTensorBoardWriter(dir string) (*TensorBoardWriter, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("events.out.tfevents.%d.%s", now.Unix(), host))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &TensorBoardWriter{file: file}
+	// Every event file starts with its version
+	event := appendEventHeader(nil, now, 0)
+	event = protowire.AppendTag(event, 3, protowire.BytesType)
+	event = protowire.AppendString(event, "brain.Event:2")
+	if err := w.writeRecord(event); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the path of the event file
+func (w *TensorBoardWriter) Path() string {
+	return w.file.Name()
+}
+
+// AddScalar writes value as the scalar tag at step
+func (w *TensorBoardWriter) AddScalar(tag string, step int, value float64) error {
+	var summaryValue []byte
+	summaryValue = protowire.AppendTag(summaryValue, 1, protowire.BytesType)
+	summaryValue = protowire.AppendString(summaryValue, tag)
+	summaryValue = protowire.AppendTag(summaryValue, 2, protowire.Fixed32Type)
+	summaryValue = protowire.AppendFixed32(summaryValue, math.Float32bits(float32(value)))
+	return w.writeSummary(step, summaryValue)
+}
+
+// AddHistogram writes the distribution of values as the histogram tag at
+// step, with one bucket per distinct value
+func (w *TensorBoardWriter) AddHistogram(tag string, step int, values []float64) error {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Sorted(slices.Values(values))
+
+	var sum, sumSquares float64
+	var limits, counts []float64
+	for _, v := range sorted {
+		sum += v
+		sumSquares += v * v
+		if n := len(limits); n > 0 && limits[n-1] == v {
+			counts[n-1]++
+			continue
+		}
+		limits = append(limits, v)
+		counts = append(counts, 1)
+	}
+
+	var histo []byte
+	for field, v := range []float64{sorted[0], sorted[len(sorted)-1], float64(len(sorted)), sum, sumSquares} {
+		histo = protowire.AppendTag(histo, protowire.Number(field+1), protowire.Fixed64Type)
+		histo = protowire.AppendFixed64(histo, math.Float64bits(v))
+	}
+	histo = appendPackedDoubles(histo, 6, limits)
+	histo = appendPackedDoubles(histo, 7, counts)
+
+	var summaryValue []byte
+	summaryValue = protowire.AppendTag(summaryValue, 1, protowire.BytesType)
+	summaryValue = protowire.AppendString(summaryValue, tag)
+	summaryValue = protowire.AppendTag(summaryValue, 5, protowire.BytesType)
+	summaryValue = protowire.AppendBytes(summaryValue, histo)
+	return w.writeSummary(step, summaryValue)
+}
+
+// Close closes the event file
+func (w *TensorBoardWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// writeSummary writes an event with a summary of one value
+func (w *TensorBoardWriter) writeSummary(step int, summaryValue []byte) error {
+	var summary []byte
+	summary = protowire.AppendTag(summary, 1, protowire.BytesType)
+	summary = protowire.AppendBytes(summary, summaryValue)
+
+	event := appendEventHeader(nil, time.Now(), step)
+	event = protowire.AppendTag(event, 5, protowire.BytesType)
+	event = protowire.AppendBytes(event, summary)
+	return w.writeRecord(event)
+}
+
+// appendEventHeader appends the wall time and step fields of an Event
+func appendEventHeader(b []byte, wallTime time.Time, step int) []byte {
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(float64(wallTime.UnixNano())/1e9))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(step))
+}
+
+func appendPackedDoubles(b []byte, field protowire.Number, values []float64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(v))
+	}
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC is the checksum of TFRecord framing
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, castagnoli)
+	return (crc>>15 | crc<<17) + 0xa282ead8
+}
+
+// writeRecord writes data framed as a TFRecord: its length, the length's
+// checksum, the data and the data's checksum
+func (w *TensorBoardWriter) writeRecord(data []byte) error {
+	record := make([]byte, 12, 16+len(data))
+	binary.LittleEndian.PutUint64(record, uint64(len(data)))
+	binary.LittleEndian.PutUint32(record[8:], maskedCRC(record[:8]))
+	record = append(record, data...)
+	record = binary.LittleEndian.AppendUint32(record, maskedCRC(data))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.file.Write(record)
+	return err
+}
@@ -0,0 +1,97 @@
+package monitoring
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// readRecords reads the TFRecords of an event file, checking their framing
+func readRecords(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records [][]byte
+	for len(data) > 0 {
+		n := binary.LittleEndian.Uint64(data)
+		if binary.LittleEndian.Uint32(data[8:]) != maskedCRC(data[:8]) {
+			t.Fatal("record length checksum mismatch")
+		}
+		record := data[12 : 12+n]
+		if binary.LittleEndian.Uint32(data[12+n:]) != maskedCRC(record) {
+			t.Fatal("record data checksum mismatch")
+		}
+		records = append(records, record)
+		data = data[16+n:]
+	}
+	return records
+}
+
+// fields returns the last value of each field of a protobuf message
+func fields(t *testing.T, b []byte) map[protowire.Number][]byte {
+	t.Helper()
+	values := make(map[protowire.Number][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			t.Fatalf("invalid field %d", num)
+		}
+		value := b[:m]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(b)
+		}
+		values[num] = value
+		b = b[m:]
+	}
+	return values
+}
+
+func TestTensorBoardWriter(t *testing.T) {
+	w, err := NewTensorBoardWriter(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewTensorBoardWriter() error = %v", err)
+	}
+	if err := w.AddScalar("train/loss", 3, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddHistogram("staleness", 3, []float64{2, 0, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readRecords(t, w.Path())
+	if len(records) != 3 {
+		t.Fatalf("%d records, want the version, a scalar and a histogram", len(records))
+	}
+	if version := fields(t, records[0])[3]; string(version) != "brain.Event:2" {
+		t.Errorf("file version = %q", version)
+	}
+
+	scalar := fields(t, records[1])
+	if step, _ := protowire.ConsumeVarint(scalar[2]); step != 3 {
+		t.Errorf("step = %d, want 3", step)
+	}
+	value := fields(t, fields(t, scalar[5])[1])
+	bits, _ := protowire.ConsumeFixed32(value[2])
+	if string(value[1]) != "train/loss" || math.Float32frombits(bits) != 0.25 {
+		t.Errorf("scalar = %s %v, want train/loss 0.25", value[1], math.Float32frombits(bits))
+	}
+
+	histo := fields(t, fields(t, fields(t, records[2])[5])[1])[5]
+	num, _ := protowire.ConsumeFixed64(fields(t, histo)[3])
+	if math.Float64frombits(num) != 3 {
+		t.Errorf("histogram count = %v, want 3", math.Float64frombits(num))
+	}
+	if limits := fields(t, histo)[6]; len(limits) != 16 {
+		t.Errorf("histogram has %d bucket limits, want 2 distinct values", len(limits)/8)
+	}
+}
@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultSeriesBuckets is the number of buckets used when no interval is given
+const defaultSeriesBuckets = 100
+
+// seriesSample is a single raw observation before downsampling
+type seriesSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// ParseTimeSeriesMetrics parses a comma-separated list of metric names
+func ParseTimeSeriesMetrics(value string) ([]TimeSeriesMetric, error) {
+	var metrics []TimeSeriesMetric
+	for _, name := range strings.Split(value, ",") {
+		metric := TimeSeriesMetric(strings.TrimSpace(name))
+		switch metric {
+		case SeriesAccuracy, SeriesLoss, SeriesRoundDuration, SeriesUpdateLatency, SeriesCPU, SeriesMemory:
+			metrics = append(metrics, metric)
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported time series metric: %s", metric)
+		}
+	}
+
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("at least one metric is required")
+	}
+
+	return metrics, nil
+}
+
+// downsample groups samples into fixed-width buckets between start and end
+// and computes avg/min/max per bucket. Empty buckets are omitted.
+func downsample(samples []seriesSample, start, end time.Time, interval time.Duration) []TimeSeriesBucket {
+	if interval <= 0 {
+		interval = end.Sub(start) / defaultSeriesBuckets
+		if interval <= 0 {
+			interval = time.Second
+		}
+	}
+
+	buckets := make(map[int64]*TimeSeriesBucket)
+	for _, sample := range samples {
+		if sample.Timestamp.Before(start) || sample.Timestamp.After(end) {
+			continue
+		}
+
+		index := int64(sample.Timestamp.Sub(start) / interval)
+		bucket, exists := buckets[index]
+		if !exists {
+			bucket = &TimeSeriesBucket{
+				Timestamp: start.Add(time.Duration(index) * interval),
+				Min:       sample.Value,
+				Max:       sample.Value,
+			}
+			buckets[index] = bucket
+		}
+
+		bucket.Avg += sample.Value
+		bucket.Count++
+		if sample.Value < bucket.Min {
+			bucket.Min = sample.Value
+		}
+		if sample.Value > bucket.Max {
+			bucket.Max = sample.Value
+		}
+	}
+
+	result := make([]TimeSeriesBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		bucket.Avg /= float64(bucket.Count)
+		result = append(result, *bucket)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+
+	return result
+}
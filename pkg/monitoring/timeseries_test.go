@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	samples := []seriesSample{
+		{Timestamp: start.Add(1 * time.Minute), Value: 1},
+		{Timestamp: start.Add(2 * time.Minute), Value: 3},
+		{Timestamp: start.Add(12 * time.Minute), Value: 10},
+		{Timestamp: start.Add(-time.Minute), Value: 100}, // outside range
+	}
+
+	buckets := downsample(samples, start, end, 10*time.Minute)
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(buckets))
+	}
+
+	first := buckets[0]
+	if !first.Timestamp.Equal(start) {
+		t.Errorf("First bucket timestamp = %v, want %v", first.Timestamp, start)
+	}
+	if first.Avg != 2 || first.Min != 1 || first.Max != 3 || first.Count != 2 {
+		t.Errorf("First bucket = %+v, want avg=2 min=1 max=3 count=2", first)
+	}
+
+	if buckets[1].Avg != 10 || buckets[1].Count != 1 {
+		t.Errorf("Second bucket = %+v, want avg=10 count=1", buckets[1])
+	}
+}
+
+func TestParseTimeSeriesMetrics(t *testing.T) {
+	metrics, err := ParseTimeSeriesMetrics("accuracy, loss")
+	if err != nil {
+		t.Fatalf("ParseTimeSeriesMetrics() error = %v", err)
+	}
+	if len(metrics) != 2 || metrics[0] != SeriesAccuracy || metrics[1] != SeriesLoss {
+		t.Errorf("ParseTimeSeriesMetrics() = %v", metrics)
+	}
+
+	if _, err := ParseTimeSeriesMetrics("bogus"); err == nil {
+		t.Error("Expected error for unsupported metric")
+	}
+
+	if _, err := ParseTimeSeriesMetrics(""); err == nil {
+		t.Error("Expected error for empty metric list")
+	}
+}
+
+func TestMemoryStorage_GetTimeSeries(t *testing.T) {
+	storage := NewMemoryStorage(&MonitoringConfig{})
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := storage.RegisterFederation(ctx, &FederationMetrics{ID: "fed-1", Name: "Test"}); err != nil {
+		t.Fatalf("Failed to register federation: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		endTime := now.Add(-time.Duration(4-i) * time.Minute)
+		accuracy := 0.5 + float64(i)*0.1
+		round := &RoundMetrics{
+			FederationID:  "fed-1",
+			RoundNumber:   i,
+			StartTime:     endTime.Add(-30 * time.Second),
+			EndTime:       &endTime,
+			Duration:      30 * time.Second,
+			ModelAccuracy: &accuracy,
+		}
+		if err := storage.RecordRoundStart(ctx, round); err != nil {
+			t.Fatalf("Failed to record round: %v", err)
+		}
+	}
+
+	series, err := storage.GetTimeSeries(ctx, "fed-1", &TimeSeriesQuery{
+		Metrics:   []TimeSeriesMetric{SeriesAccuracy, SeriesRoundDuration},
+		StartTime: now.Add(-time.Hour),
+		EndTime:   now,
+		Interval:  time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("GetTimeSeries() error = %v", err)
+	}
+
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 series, got %d", len(series))
+	}
+
+	accuracy := series[0]
+	if len(accuracy.Buckets) != 1 || accuracy.Buckets[0].Count != 3 {
+		t.Fatalf("Unexpected accuracy buckets: %+v", accuracy.Buckets)
+	}
+	if accuracy.Buckets[0].Max < 0.79 || accuracy.Buckets[0].Min > 0.61 {
+		t.Errorf("Unexpected accuracy range: %+v", accuracy.Buckets[0])
+	}
+
+	if _, err := storage.GetTimeSeries(ctx, "missing", &TimeSeriesQuery{}); err == nil {
+		t.Error("Expected error for unknown federation")
+	}
+}
@@ -0,0 +1,120 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/security"
+)
+
+// Default http.Server timeouts, used when the configuration leaves them unset
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = 30 * time.Second
+	defaultWriteTimeout      = 60 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+)
+
+// defaultCertDir is where auto-generated certificates are written
+const defaultCertDir = "certs/monitoring"
+
+// TLSServerConfig configures HTTPS for the monitoring API
+type TLSServerConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	CertFile         string `yaml:"cert_file"`
+	KeyFile          string `yaml:"key_file"`
+	AutoGenerateCert bool   `yaml:"auto_generate_cert"` // generate a self-signed CA and server certificate when no cert_file is set
+	CertDir          string `yaml:"cert_dir"`           // where generated certificates are kept (default: certs/monitoring)
+	HTTPRedirectPort int    `yaml:"http_redirect_port"` // serve plain HTTP redirects to HTTPS on this port; 0 disables
+}
+
+// LoadTLSConfig returns the server TLS configuration. Generated certificates
+// are reused across restarts so that clients keep trusting the same CA.
+func (c TLSServerConfig) LoadTLSConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+
+	switch {
+	case c.CertFile != "" || c.KeyFile != "":
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("both cert_file and key_file must be set")
+		}
+		loaded, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate: %w", err)
+		}
+		cert = loaded
+
+	case c.AutoGenerateCert:
+		certDir := c.CertDir
+		if certDir == "" {
+			certDir = defaultCertDir
+		}
+		_, statErr := os.Stat(filepath.Join(certDir, "server.crt"))
+		manager, err := security.NewTLSManager(security.TLSConfig{
+			Enabled:          true,
+			AutoGenerateCert: os.IsNotExist(statErr),
+		}, certDir)
+		if err != nil {
+			return nil, err
+		}
+		cert = manager.ServerCertificate()
+
+	default:
+		return nil, fmt.Errorf("TLS is enabled but neither cert_file/key_file nor auto_generate_cert is set")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// newHTTPServer creates an http.Server with the configured timeouts
+func (s *APIServer) newHTTPServer(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+	}
+
+	if server.ReadTimeout <= 0 {
+		server.ReadTimeout = defaultReadTimeout
+	}
+	if server.WriteTimeout <= 0 {
+		server.WriteTimeout = defaultWriteTimeout
+	}
+	if server.IdleTimeout <= 0 {
+		server.IdleTimeout = defaultIdleTimeout
+	}
+
+	return server
+}
+
+// httpsRedirectHandler redirects every request to the same URL on the HTTPS port
+func httpsRedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
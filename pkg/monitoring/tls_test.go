@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSServerConfig_AutoGenerate(t *testing.T) {
+	certDir := t.TempDir()
+	config := TLSServerConfig{Enabled: true, AutoGenerateCert: true, CertDir: certDir}
+
+	tlsConfig, err := config.LoadTLSConfig()
+	if err != nil {
+		t.Fatalf("LoadTLSConfig() error = %v", err)
+	}
+
+	// A restart reuses the generated certificate instead of replacing the CA
+	again, err := config.LoadTLSConfig()
+	if err != nil {
+		t.Fatalf("LoadTLSConfig() error = %v", err)
+	}
+	if !bytes.Equal(tlsConfig.Certificates[0].Certificate[0], again.Certificates[0].Certificate[0]) {
+		t.Error("Expected the generated certificate to be reused")
+	}
+
+	caPEM, err := os.ReadFile(filepath.Join(certDir, "ca.crt"))
+	if err != nil {
+		t.Fatalf("Failed to read CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("HTTPS request with the generated CA failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTLSServerConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TLSServerConfig
+	}{
+		{"no certificate source", TLSServerConfig{Enabled: true}},
+		{"key without cert", TLSServerConfig{Enabled: true, KeyFile: "server.key"}},
+		{"missing files", TLSServerConfig{Enabled: true, CertFile: "missing.crt", KeyFile: "missing.key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.LoadTLSConfig(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		port      int
+		host      string
+		path      string
+		wantedURL string
+	}{
+		{"custom port", 8443, "monitor.local:8080", "/api/v1/health?x=1", "https://monitor.local:8443/api/v1/health?x=1"},
+		{"default port", 443, "monitor.local", "/", "https://monitor.local/"},
+		{"ipv6", 8443, "[::1]:8080", "/status", "https://[::1]:8443/status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = tt.host
+			rr := httptest.NewRecorder()
+			httpsRedirectHandler(tt.port).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusPermanentRedirect {
+				t.Errorf("status = %d, want %d", rr.Code, http.StatusPermanentRedirect)
+			}
+			if location := rr.Header().Get("Location"); location != tt.wantedURL {
+				t.Errorf("Location = %q, want %q", location, tt.wantedURL)
+			}
+		})
+	}
+}
+
+func TestNewHTTPServerTimeouts(t *testing.T) {
+	server := NewAPIServer(NewMemoryStorage(&MonitoringConfig{}), &MonitoringConfig{WriteTimeout: 5 * time.Minute})
+	httpServer := server.newHTTPServer(":0", server.router)
+
+	if httpServer.WriteTimeout != 5*time.Minute {
+		t.Errorf("WriteTimeout = %v, want configured 5m", httpServer.WriteTimeout)
+	}
+	if httpServer.ReadTimeout != defaultReadTimeout || httpServer.IdleTimeout != defaultIdleTimeout {
+		t.Errorf("Expected default read and idle timeouts, got %v and %v", httpServer.ReadTimeout, httpServer.IdleTimeout)
+	}
+}
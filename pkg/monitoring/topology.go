@@ -0,0 +1,181 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Topology lays out a federation for map views: the aggregator, every
+// collaborator with its location, and the collaborators grouped by region.
+// Collaborators connect to the aggregator only, so links are not listed.
+type Topology struct {
+	FederationID  string           `json:"federation_id"`
+	Aggregator    TopologyNode     `json:"aggregator"`
+	Collaborators []TopologyNode   `json:"collaborators"`
+	Regions       []TopologyRegion `json:"regions"`
+}
+
+// TopologyNode is a participant of a federation
+type TopologyNode struct {
+	ID        string     `json:"id"`
+	Address   string     `json:"address,omitempty"`
+	Status    string     `json:"status"`
+	Region    string     `json:"region,omitempty"`
+	Site      string     `json:"site,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Latitude  *float64   `json:"latitude,omitempty"`
+	Longitude *float64   `json:"longitude,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
+
+// TopologyRegion summarizes the collaborators of a region. Collaborators
+// without a region are counted in the region "".
+type TopologyRegion struct {
+	Region        string   `json:"region"`
+	Sites         []string `json:"sites,omitempty"`
+	Collaborators int      `json:"collaborators"`
+	Active        int      `json:"active"`
+	Latitude      *float64 `json:"latitude,omitempty"` // average of the collaborators with coordinates
+	Longitude     *float64 `json:"longitude,omitempty"`
+}
+
+// ValidateLocation checks the coordinates of a collaborator
+func (c *CollaboratorMetrics) ValidateLocation() error {
+	if (c.Latitude == nil) != (c.Longitude == nil) {
+		return fmt.Errorf("latitude and longitude must be set together")
+	}
+	if c.Latitude != nil && (*c.Latitude < -90 || *c.Latitude > 90) {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if c.Longitude != nil && (*c.Longitude < -180 || *c.Longitude > 180) {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// HasTags reports whether the collaborator has all of tags
+func (c *CollaboratorMetrics) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(c.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// active reports whether a collaborator takes part in the federation
+func (c *CollaboratorMetrics) active() bool {
+	return c.Status != CollabStatusDisconnected && c.Status != CollabStatusError
+}
+
+// BuildTopology lays out a federation and its collaborators
+func BuildTopology(federation *FederationMetrics, collaborators []*CollaboratorMetrics) *Topology {
+	topology := &Topology{
+		FederationID: federation.ID,
+		Aggregator: TopologyNode{
+			ID:      "aggregator",
+			Address: federation.AggregatorAddress,
+			Status:  string(federation.Status),
+		},
+		Collaborators: make([]TopologyNode, 0, len(collaborators)),
+		Regions:       []TopologyRegion{},
+	}
+
+	type regionTotals struct {
+		region              *TopologyRegion
+		located             int
+		latitude, longitude float64
+	}
+	regions := make(map[string]*regionTotals)
+
+	for _, collaborator := range collaborators {
+		lastSeen := collaborator.LastSeen
+		node := TopologyNode{
+			ID:        collaborator.ID,
+			Address:   collaborator.Address,
+			Status:    string(collaborator.Status),
+			Region:    collaborator.Region,
+			Site:      collaborator.Site,
+			Tags:      collaborator.Tags,
+			Latitude:  collaborator.Latitude,
+			Longitude: collaborator.Longitude,
+		}
+		if !lastSeen.IsZero() {
+			node.LastSeen = &lastSeen
+		}
+		topology.Collaborators = append(topology.Collaborators, node)
+
+		totals, exists := regions[collaborator.Region]
+		if !exists {
+			totals = &regionTotals{region: &TopologyRegion{Region: collaborator.Region}}
+			regions[collaborator.Region] = totals
+		}
+		region := totals.region
+		region.Collaborators++
+		if collaborator.active() {
+			region.Active++
+		}
+		if collaborator.Site != "" && !slices.Contains(region.Sites, collaborator.Site) {
+			region.Sites = append(region.Sites, collaborator.Site)
+		}
+		if collaborator.Latitude != nil && collaborator.Longitude != nil {
+			totals.located++
+			totals.latitude += *collaborator.Latitude
+			totals.longitude += *collaborator.Longitude
+		}
+	}
+
+	sort.Slice(topology.Collaborators, func(i, j int) bool {
+		return topology.Collaborators[i].ID < topology.Collaborators[j].ID
+	})
+
+	for _, totals := range regions {
+		region := totals.region
+		if totals.located > 0 {
+			latitude := totals.latitude / float64(totals.located)
+			longitude := totals.longitude / float64(totals.located)
+			region.Latitude, region.Longitude = &latitude, &longitude
+		}
+		sort.Strings(region.Sites)
+		topology.Regions = append(topology.Regions, *region)
+	}
+	sort.Slice(topology.Regions, func(i, j int) bool {
+		return topology.Regions[i].Region < topology.Regions[j].Region
+	})
+
+	return topology
+}
+
+func (s *APIServer) handleGetTopology(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	federation, err := s.service.GetFederation(ctx, id)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Federation not found", err)
+		return
+	}
+
+	collaborators, err := s.service.GetFederationCollaborators(ctx, id)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get collaborators", err)
+		return
+	}
+
+	if tags := parseTags(r); len(tags) > 0 {
+		tagged := collaborators[:0]
+		for _, collaborator := range collaborators {
+			if collaborator.HasTags(tags) {
+				tagged = append(tagged, collaborator)
+			}
+		}
+		collaborators = tagged
+	}
+
+	s.sendSuccess(w, BuildTopology(federation, collaborators))
+}
@@ -0,0 +1,64 @@
+package monitoring
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBuildTopology(t *testing.T) {
+	lat1, lon1, lat2, lon2 := 52.0, 4.0, 54.0, 6.0
+	federation := &FederationMetrics{ID: "fed-1", Status: StatusRunning, AggregatorAddress: "agg:50051"}
+	collaborators := []*CollaboratorMetrics{
+		{ID: "c2", Region: "eu-west", Site: "Leiden", Status: CollabStatusDisconnected, Latitude: &lat2, Longitude: &lon2},
+		{ID: "c1", Region: "eu-west", Site: "Amsterdam", Status: CollabStatusTraining, Latitude: &lat1, Longitude: &lon1},
+		{ID: "c3", Status: CollabStatusConnected},
+	}
+
+	topology := BuildTopology(federation, collaborators)
+	if topology.Aggregator.Address != "agg:50051" || len(topology.Collaborators) != 3 || topology.Collaborators[0].ID != "c1" {
+		t.Fatalf("topology = %+v", topology)
+	}
+	if len(topology.Regions) != 2 || topology.Regions[0].Region != "" || topology.Regions[0].Latitude != nil {
+		t.Fatalf("regions = %+v", topology.Regions)
+	}
+
+	region := topology.Regions[1]
+	if region.Collaborators != 2 || region.Active != 1 || len(region.Sites) != 2 || region.Sites[0] != "Amsterdam" {
+		t.Errorf("eu-west = %+v", region)
+	}
+	if *region.Latitude != 53 || *region.Longitude != 5 {
+		t.Errorf("eu-west center = %v, %v, want 53, 5", *region.Latitude, *region.Longitude)
+	}
+}
+
+func TestTopologyAPI(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a"}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/collaborators", "lab-a-key",
+		`{"id": "c1", "federation_id": "fed-a", "region": "us-east", "tags": ["gpu", "hospital"], "latitude": 40.7, "longitude": -74}`, nil)
+	doAPIKeyRequest(t, server, "POST", "/api/v1/collaborators", "lab-a-key",
+		`{"id": "c2", "federation_id": "fed-a", "region": "us-west", "tags": ["hospital"]}`, nil)
+
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/collaborators", "lab-a-key",
+		`{"id": "c3", "federation_id": "fed-a", "latitude": 95, "longitude": 0}`, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid latitude status = %d, want 400", code)
+	}
+
+	var collaborators []*CollaboratorMetrics
+	doAPIKeyRequest(t, server, "GET", "/api/v1/collaborators?tag=hospital,gpu", "lab-a-key", "", &collaborators)
+	if len(collaborators) != 1 || collaborators[0].ID != "c1" {
+		t.Errorf("collaborators tagged hospital and gpu = %+v", collaborators)
+	}
+
+	var topology Topology
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a/topology?tag=hospital", "lab-a-key", "", &topology); code != http.StatusOK {
+		t.Fatalf("topology status = %d", code)
+	}
+	if len(topology.Collaborators) != 2 || len(topology.Regions) != 2 || topology.Collaborators[0].Latitude == nil {
+		t.Errorf("topology = %+v", topology)
+	}
+
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/federations/fed-a/topology", "lab-b-key", "", nil); code != http.StatusNotFound {
+		t.Errorf("topology of another organization status = %d, want 404", code)
+	}
+}
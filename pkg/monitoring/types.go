@@ -1,7 +1,10 @@
 package monitoring
 
 import (
+	"errors"
 	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/registry"
 )
 
 // MetricType represents the type of metric being tracked
@@ -15,6 +18,11 @@ const (
 	MetricTypeTraining       MetricType = "training"
 	MetricTypeSystemResource MetricType = "system_resource"
 	MetricTypePerformance    MetricType = "performance"
+	MetricTypeRunReport      MetricType = "run_report"
+	MetricTypeClustering     MetricType = "clustering"
+	MetricTypeContribution   MetricType = "contribution"
+	MetricTypeLineage        MetricType = "lineage"
+	MetricTypeEvaluation     MetricType = "evaluation"
 )
 
 // FederationStatus represents the current status of a federation
@@ -26,6 +34,8 @@ const (
 	StatusCompleted FederationStatus = "completed"
 	StatusFailed    FederationStatus = "failed"
 	StatusStopped   FederationStatus = "stopped"
+	StatusPaused    FederationStatus = "paused"  // no new rounds start until the federation is resumed
+	StatusAborted   FederationStatus = "aborted" // ended through the control API
 )
 
 // CollaboratorStatus represents the status of a collaborator
@@ -41,20 +51,22 @@ const (
 
 // FederationMetrics contains overall federation statistics
 type FederationMetrics struct {
-	ID                string           `json:"id"`
-	Name              string           `json:"name"`
-	Status            FederationStatus `json:"status"`
-	Mode              string           `json:"mode"` // sync/async
-	Algorithm         string           `json:"algorithm"`
-	StartTime         time.Time        `json:"start_time"`
-	EndTime           *time.Time       `json:"end_time,omitempty"`
-	CurrentRound      int              `json:"current_round"`
-	TotalRounds       int              `json:"total_rounds"`
-	ActiveCollabs     int              `json:"active_collaborators"`
-	TotalCollabs      int              `json:"total_collaborators"`
-	ModelSize         int              `json:"model_size"`
-	LastUpdate        time.Time        `json:"last_update"`
-	AggregatorAddress string           `json:"aggregator_address"`
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Status            FederationStatus  `json:"status"`
+	Mode              string            `json:"mode"` // sync/async
+	Algorithm         string            `json:"algorithm"`
+	StartTime         time.Time         `json:"start_time"`
+	EndTime           *time.Time        `json:"end_time,omitempty"`
+	CurrentRound      int               `json:"current_round"`
+	TotalRounds       int               `json:"total_rounds"`
+	ActiveCollabs     int               `json:"active_collaborators"`
+	TotalCollabs      int               `json:"total_collaborators"`
+	ModelSize         int               `json:"model_size"`
+	LastUpdate        time.Time         `json:"last_update"`
+	AggregatorAddress string            `json:"aggregator_address"`
+	OrgID             string            `json:"org_id,omitempty"` // organization that owns the federation
+	Labels            map[string]string `json:"labels,omitempty"` // free-form key=value labels, such as experiment=ablation-3
 }
 
 // CollaboratorMetrics contains metrics for a specific collaborator
@@ -72,6 +84,13 @@ type CollaboratorMetrics struct {
 	ErrorCount       int                `json:"error_count"`
 	LastError        string             `json:"last_error,omitempty"`
 	ResourceMetrics  *ResourceMetrics   `json:"resource_metrics,omitempty"`
+	HasGPU           bool               `json:"has_gpu,omitempty"` // the collaborator's host has an NVIDIA GPU
+	Cluster          *int               `json:"cluster,omitempty"` // global model the collaborator trains in a clustered federation
+	Region           string             `json:"region,omitempty"`
+	Site             string             `json:"site,omitempty"` // name of the site the collaborator runs at
+	Tags             []string           `json:"tags,omitempty"`
+	Latitude         *float64           `json:"latitude,omitempty"`
+	Longitude        *float64           `json:"longitude,omitempty"`
 }
 
 // RoundMetrics contains metrics for a specific training round
@@ -90,6 +109,9 @@ type RoundMetrics struct {
 	ModelLoss        *float64      `json:"model_loss,omitempty"`
 	ConvergenceRate  *float64      `json:"convergence_rate,omitempty"`
 	Status           string        `json:"status"`
+	// Train task args used in the round, after the hyperparameter schedule
+	Hyperparameters map[string]interface{} `json:"hyperparameters,omitempty"`
+	Labels          map[string]string      `json:"labels,omitempty"` // free-form key=value labels
 }
 
 // ModelUpdateMetrics contains metrics for model updates
@@ -100,11 +122,16 @@ type ModelUpdateMetrics struct {
 	RoundNumber      int       `json:"round_number"`
 	Timestamp        time.Time `json:"timestamp"`
 	UpdateSize       int       `json:"update_size_bytes"`
+	NumSamples       int64     `json:"num_samples,omitempty"` // training samples the update was computed on
 	ProcessingTime   float64   `json:"processing_time_ms"`
 	Staleness        int       `json:"staleness,omitempty"` // for async FL
 	Weight           float64   `json:"weight,omitempty"`    // aggregation weight
 	QualityScore     *float64  `json:"quality_score,omitempty"`
 	CompressionRatio *float64  `json:"compression_ratio,omitempty"`
+	ImageDigest      string    `json:"image_digest,omitempty"` // container image the update was trained in
+	// Local differential privacy the collaborator applied to the update, if any
+	DPClipNorm        float64 `json:"dp_clip_norm,omitempty"`
+	DPNoiseMultiplier float64 `json:"dp_noise_multiplier,omitempty"`
 }
 
 // ResourceMetrics contains system resource usage metrics
@@ -119,8 +146,24 @@ type ResourceMetrics struct {
 	NetworkTxRate float64   `json:"network_tx_rate_mbps"`
 	GPUUsage      *float64  `json:"gpu_usage_percent,omitempty"`
 	GPUMemory     *float64  `json:"gpu_memory_percent,omitempty"`
+	// Temperature of the hottest NVIDIA GPU, and the number of GPUs
+	GPUTemperature *float64 `json:"gpu_temperature_celsius,omitempty"`
+	GPUCount       int      `json:"gpu_count,omitempty"`
+}
+
+// ResourceSource records the organization that owns the resource metrics of
+// a source, so that ownership is kept in storage like the OrgID of a
+// federation
+type ResourceSource struct {
+	ID           string    `json:"id"`
+	OrgID        string    `json:"org_id"`
+	FederationID string    `json:"federation_id"` // federation the source reports for
+	CreatedAt    time.Time `json:"created_at"`
 }
 
+// errUnknownResourceSource is returned for sources without a recorded owner
+var errUnknownResourceSource = errors.New("has no recorded owner")
+
 // AggregationMetrics contains metrics specific to aggregation operations
 type AggregationMetrics struct {
 	ID                 string        `json:"id"`
@@ -134,6 +177,11 @@ type AggregationMetrics struct {
 	ModelConvergence   *float64      `json:"model_convergence,omitempty"`
 	AggregationQuality *float64      `json:"aggregation_quality,omitempty"`
 	ComputationCost    *float64      `json:"computation_cost,omitempty"`
+	UpdatesClipped     int           `json:"updates_clipped"`
+	ClippedFraction    float64       `json:"clipped_fraction"`                    // Share of the updates that were clipped
+	ClipNorm           float64       `json:"clip_norm,omitempty"`                 // Cap on the norm of an update's change
+	AggregationDelay   float64       `json:"aggregation_delay_seconds,omitempty"` // Async: delay chosen before this aggregation
+	ArrivalRate        float64       `json:"arrival_rate,omitempty"`              // Async: estimated updates per second
 }
 
 // MonitoringEvent represents a real-time event in the FL system
@@ -150,17 +198,32 @@ type MonitoringEvent struct {
 
 // MonitoringConfig contains configuration for the monitoring system
 type MonitoringConfig struct {
-	Enabled               bool          `yaml:"enabled" json:"enabled"`
-	APIPort               int           `yaml:"api_port" json:"api_port"`
-	WebUIPort             int           `yaml:"webui_port" json:"webui_port"`
-	MetricsRetention      time.Duration `yaml:"metrics_retention" json:"metrics_retention"`
-	CollectionInterval    time.Duration `yaml:"collection_interval" json:"collection_interval"`
-	EnableResourceMetrics bool          `yaml:"enable_resource_metrics" json:"enable_resource_metrics"`
-	EnableRealTimeEvents  bool          `yaml:"enable_realtime_events" json:"enable_realtime_events"`
-	StorageBackend        string        `yaml:"storage_backend" json:"storage_backend"` // memory/sqlite/postgres
-	DatabaseURL           string        `yaml:"database_url,omitempty" json:"database_url,omitempty"`
-	Production            bool          `yaml:"production" json:"production"`
-	AllowedOrigins        []string      `yaml:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`
+	Enabled               bool               `yaml:"enabled" json:"enabled"`
+	APIPort               int                `yaml:"api_port" json:"api_port"`
+	GRPCPort              int                `yaml:"grpc_port,omitempty" json:"grpc_port,omitempty"` // gRPC ingestion API, disabled when 0
+	WebUIPort             int                `yaml:"webui_port" json:"webui_port"`
+	WebUIDir              string             `yaml:"webui_dir,omitempty" json:"webui_dir,omitempty"` // serve a built web UI (e.g. web/dist) instead of the embedded dashboard
+	MetricsRetention      time.Duration      `yaml:"metrics_retention" json:"metrics_retention"`
+	CollectionInterval    time.Duration      `yaml:"collection_interval" json:"collection_interval"`
+	EnableResourceMetrics bool               `yaml:"enable_resource_metrics" json:"enable_resource_metrics"`
+	EnableRealTimeEvents  bool               `yaml:"enable_realtime_events" json:"enable_realtime_events"`
+	StorageBackend        string             `yaml:"storage_backend" json:"storage_backend"` // memory/sqlite/postgres/timescaledb
+	DatabaseURL           string             `yaml:"database_url,omitempty" json:"database_url,omitempty"`
+	EventBus              RedisConfig        `yaml:"event_bus,omitempty" json:"-"` // share live events between replicas over Redis pub/sub, disabled without an address
+	Production            bool               `yaml:"production" json:"production"`
+	AllowedOrigins        []string           `yaml:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`
+	Auth                  AuthConfig         `yaml:"auth" json:"-"`
+	RateLimit             RateLimitConfig    `yaml:"rate_limit" json:"-"`
+	PublicStatus          PublicStatusConfig `yaml:"public_status" json:"public_status"`
+	TLS                   TLSServerConfig    `yaml:"tls" json:"-"`
+	ReadTimeout           time.Duration      `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout          time.Duration      `yaml:"write_timeout" json:"write_timeout"`
+	IdleTimeout           time.Duration      `yaml:"idle_timeout" json:"idle_timeout"`
+	Registry              registry.Config    `yaml:"registry" json:"-"`                  // model registry served under /api/v1/models
+	Ledger                LedgerConfig       `yaml:"ledger" json:"ledger"`               // participation credits served under /api/v1/ledger
+	Dashboards            DashboardConfig    `yaml:"dashboards" json:"dashboards"`       // dashboards created for new federations
+	Notifications         NotificationConfig `yaml:"notifications" json:"notifications"` // webhooks of notification subscriptions
+	Control               ControlConfig      `yaml:"control" json:"-"`                   // pausing, resuming, aborting and inspecting federations through their aggregators
 }
 
 // APIResponse represents a standard API response structure
@@ -181,15 +244,26 @@ type MetaInfo struct {
 
 // MetricsFilter contains filtering options for metrics queries
 type MetricsFilter struct {
-	FederationID   string     `json:"federation_id,omitempty"`
-	CollaboratorID string     `json:"collaborator_id,omitempty"`
-	StartTime      *time.Time `json:"start_time,omitempty"`
-	EndTime        *time.Time `json:"end_time,omitempty"`
-	MetricType     MetricType `json:"metric_type,omitempty"`
-	RoundNumber    *int       `json:"round_number,omitempty"`
-	Status         string     `json:"status,omitempty"`
-	Page           int        `json:"page,omitempty"`
-	PerPage        int        `json:"per_page,omitempty"`
+	FederationID   string            `json:"federation_id,omitempty"`
+	CollaboratorID string            `json:"collaborator_id,omitempty"`
+	StartTime      *time.Time        `json:"start_time,omitempty"`
+	EndTime        *time.Time        `json:"end_time,omitempty"`
+	MetricType     MetricType        `json:"metric_type,omitempty"`
+	RoundNumber    *int              `json:"round_number,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	Page           int               `json:"page,omitempty"`
+	PerPage        int               `json:"per_page,omitempty"`
+	Tags           []string          `json:"tags,omitempty"`   // collaborators with all of these tags
+	Labels         map[string]string `json:"labels,omitempty"` // federations and rounds with all of these labels
+	Federations    map[string]bool   `json:"-"`                // when set, only records of these federations match
+}
+
+// matchesFederation reports whether the filter selects records of federationID
+func (f *MetricsFilter) matchesFederation(federationID string) bool {
+	if f.FederationID != "" && federationID != f.FederationID {
+		return false
+	}
+	return f.Federations == nil || f.Federations[federationID]
 }
 
 // Dashboard represents a monitoring dashboard configuration
@@ -197,6 +271,7 @@ type Dashboard struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	OrgID       string    `json:"org_id,omitempty"`
 	Widgets     []Widget  `json:"widgets"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -213,3 +288,35 @@ type Widget struct {
 	Width  int                    `json:"width"`
 	Height int                    `json:"height"`
 }
+
+// DropReason describes why an aggregator rejected a model update
+type DropReason string
+
+const (
+	DropReasonStale            DropReason = "stale"
+	DropReasonSizeMismatch     DropReason = "size_mismatch"
+	DropReasonValidationFailed DropReason = "validation_failed"
+	DropReasonDuplicate        DropReason = "duplicate"
+	DropReasonRateLimited      DropReason = "rate_limited"
+	DropReasonRoundMismatch    DropReason = "round_mismatch" // trained for a later round or on another model
+)
+
+// DroppedUpdate records a single rejected model update
+type DroppedUpdate struct {
+	FederationID   string     `json:"federation_id"`
+	CollaboratorID string     `json:"collaborator_id"`
+	RoundNumber    int        `json:"round_number"`
+	Reason         DropReason `json:"reason"`
+	Detail         string     `json:"detail,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// DroppedUpdateStats aggregates dropped updates per federation, collaborator and reason
+type DroppedUpdateStats struct {
+	FederationID   string     `json:"federation_id"`
+	CollaboratorID string     `json:"collaborator_id"`
+	Reason         DropReason `json:"reason"`
+	Count          int        `json:"count"`
+	LastDropped    time.Time  `json:"last_dropped"`
+	LastDetail     string     `json:"last_detail,omitempty"`
+}
@@ -0,0 +1,30 @@
+package monitoring
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"net/http"
+)
+
+// webUIFiles holds the built-in dashboard, so the monitor works without a
+// separately built web UI
+//
+//go:embed webui
+var webUIFiles embed.FS
+
+// webUIHandler serves the web UI from webui_dir when configured, otherwise
+// the embedded dashboard
+func (s *APIServer) webUIHandler() http.Handler {
+	if dir := s.config.WebUIDir; dir != "" {
+		log.Printf("Serving web UI from %s", dir)
+		return http.FileServer(http.Dir(dir))
+	}
+
+	files, err := fs.Sub(webUIFiles, "webui")
+	if err != nil {
+		// The embedded directory is fixed at build time
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
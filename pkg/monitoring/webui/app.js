@@ -0,0 +1,228 @@
+// Built-in FL monitoring dashboard. Talks to the same-origin /api/v1 endpoints.
+(function () {
+  "use strict";
+
+  var REFRESH_MS = 5000;
+  var MAX_EVENTS = 100;
+
+  var state = {
+    apiKey: localStorage.getItem("flgo-api-key") || "",
+    federationID: null,
+    events: [],
+    socket: null
+  };
+
+  function $(id) { return document.getElementById(id); }
+
+  function showError(message) {
+    var el = $("error");
+    el.textContent = message;
+    el.hidden = !message;
+  }
+
+  function api(path) {
+    var headers = {};
+    if (state.apiKey) {
+      headers["X-API-Key"] = state.apiKey;
+    }
+    return fetch("/api/v1" + path, { headers: headers }).then(function (resp) {
+      if (resp.status === 401 || resp.status === 403) {
+        throw new Error("Access denied: enter an API key with the readonly role");
+      }
+      return resp.json().then(function (body) {
+        if (!body.success) {
+          throw new Error(body.error || "Request failed");
+        }
+        return body.data || [];
+      });
+    });
+  }
+
+  function cell(row, text, className) {
+    var td = document.createElement("td");
+    if (className) {
+      var span = document.createElement("span");
+      span.className = className;
+      span.textContent = text;
+      td.appendChild(span);
+    } else {
+      td.textContent = text;
+    }
+    row.appendChild(td);
+  }
+
+  function formatTime(value) {
+    if (!value) {
+      return "";
+    }
+    return new Date(value).toLocaleString();
+  }
+
+  function renderFederations(federations) {
+    var tbody = $("federations").querySelector("tbody");
+    tbody.innerHTML = "";
+    federations.sort(function (a, b) { return (a.name || a.id).localeCompare(b.name || b.id); });
+    federations.forEach(function (f) {
+      var row = document.createElement("tr");
+      if (f.id === state.federationID) {
+        row.className = "selected";
+      }
+      cell(row, f.name || f.id);
+      cell(row, f.status, "status status-" + f.status);
+      cell(row, f.mode);
+      cell(row, f.algorithm);
+      cell(row, f.total_rounds ? f.current_round + " / " + f.total_rounds : String(f.current_round));
+      cell(row, f.active_collaborators + " / " + f.total_collaborators);
+      cell(row, formatTime(f.last_update));
+      row.addEventListener("click", function () { selectFederation(f); });
+      tbody.appendChild(row);
+    });
+    if (!state.federationID && federations.length > 0) {
+      selectFederation(federations[0]);
+    }
+  }
+
+  function renderCollaborators(collaborators) {
+    var tbody = $("collaborators").querySelector("tbody");
+    tbody.innerHTML = "";
+    collaborators.sort(function (a, b) { return a.id.localeCompare(b.id); });
+    collaborators.forEach(function (c) {
+      var row = document.createElement("tr");
+      cell(row, c.id);
+      cell(row, c.status, "status status-" + c.status);
+      cell(row, String(c.current_round));
+      cell(row, String(c.updates_submitted));
+      cell(row, (c.average_latency_ms || 0).toFixed(1));
+      cell(row, String(c.error_count));
+      cell(row, formatTime(c.last_seen));
+      tbody.appendChild(row);
+    });
+  }
+
+  // drawChart renders points ({x, y}) as a line chart into an SVG element
+  function drawChart(svg, points) {
+    var width = 400, height = 160, pad = 28;
+    svg.innerHTML = "";
+    if (points.length === 0) {
+      svg.innerHTML = '<text class="axis" x="' + width / 2 + '" y="' + height / 2 + '" text-anchor="middle">No data</text>';
+      return;
+    }
+
+    var xs = points.map(function (p) { return p.x; });
+    var ys = points.map(function (p) { return p.y; });
+    var minX = Math.min.apply(null, xs), maxX = Math.max.apply(null, xs);
+    var minY = Math.min.apply(null, ys), maxY = Math.max.apply(null, ys);
+    if (maxX === minX) { maxX = minX + 1; }
+    if (maxY === minY) { maxY = minY + 1; }
+
+    function sx(x) { return pad + (x - minX) / (maxX - minX) * (width - 2 * pad); }
+    function sy(y) { return height - pad - (y - minY) / (maxY - minY) * (height - 2 * pad); }
+
+    var path = points.map(function (p, i) {
+      return (i === 0 ? "M" : "L") + sx(p.x).toFixed(1) + " " + sy(p.y).toFixed(1);
+    }).join(" ");
+
+    svg.innerHTML =
+      '<path class="line" d="' + path + '"></path>' +
+      '<text class="axis" x="2" y="' + (pad - 8) + '">' + maxY.toFixed(3) + '</text>' +
+      '<text class="axis" x="2" y="' + (height - pad + 4) + '">' + minY.toFixed(3) + '</text>' +
+      '<text class="axis" x="' + pad + '" y="' + (height - 6) + '">round ' + minX + '</text>' +
+      '<text class="axis" x="' + (width - pad) + '" y="' + (height - 6) + '" text-anchor="end">round ' + maxX + '</text>';
+  }
+
+  function renderRounds(rounds) {
+    rounds.sort(function (a, b) { return a.round_number - b.round_number; });
+    function series(field) {
+      return rounds.filter(function (r) { return r[field] !== undefined && r[field] !== null; })
+        .map(function (r) { return { x: r.round_number, y: r[field] }; });
+    }
+    drawChart($("accuracy-chart"), series("model_accuracy"));
+    drawChart($("loss-chart"), series("model_loss"));
+    drawChart($("updates-chart"), series("updates_received"));
+  }
+
+  function renderEvents() {
+    var list = $("events");
+    list.innerHTML = "";
+    state.events.forEach(function (e) {
+      var li = document.createElement("li");
+      li.className = "level-" + (e.level || "info");
+      li.textContent = formatTime(e.timestamp) + "  [" + e.type + "] " + (e.source ? e.source + ": " : "") + e.message;
+      list.appendChild(li);
+    });
+  }
+
+  function addEvents(events) {
+    var seen = {};
+    state.events.forEach(function (e) { seen[e.id] = true; });
+    events.forEach(function (e) {
+      if (!seen[e.id]) {
+        state.events.push(e);
+      }
+    });
+    state.events.sort(function (a, b) { return new Date(b.timestamp) - new Date(a.timestamp); });
+    state.events = state.events.slice(0, MAX_EVENTS);
+    renderEvents();
+  }
+
+  // connectEvents streams events over the WebSocket. Browsers cannot send the
+  // API key header on a WebSocket, so with a key set the events are polled.
+  function connectEvents() {
+    if (state.socket) {
+      state.socket.close();
+      state.socket = null;
+    }
+    if (state.apiKey || !window.WebSocket) {
+      $("events-mode").textContent = "(polling)";
+      return;
+    }
+
+    var scheme = location.protocol === "https:" ? "wss://" : "ws://";
+    var query = state.federationID ? "?federation_id=" + encodeURIComponent(state.federationID) : "";
+    var socket = new WebSocket(scheme + location.host + "/api/v1/ws" + query);
+    socket.onmessage = function (msg) { addEvents([JSON.parse(msg.data)]); };
+    socket.onopen = function () { $("events-mode").textContent = "(streaming)"; };
+    socket.onclose = function () {
+      if (state.socket === socket) {
+        state.socket = null;
+        $("events-mode").textContent = "(polling)";
+      }
+    };
+    state.socket = socket;
+  }
+
+  function selectFederation(federation) {
+    state.federationID = federation.id;
+    state.events = [];
+    $("federation-name").textContent = federation.name || federation.id;
+    $("details").hidden = false;
+    connectEvents();
+    refresh();
+  }
+
+  function refresh() {
+    var requests = [api("/federations").then(renderFederations)];
+    if (state.federationID) {
+      var query = "?federation_id=" + encodeURIComponent(state.federationID);
+      requests.push(api("/rounds" + query + "&per_page=1000").then(renderRounds));
+      requests.push(api("/collaborators" + query).then(renderCollaborators));
+      if (!state.socket) {
+        requests.push(api("/events" + query + "&per_page=" + MAX_EVENTS).then(addEvents));
+      }
+    }
+    Promise.all(requests).then(function () { showError(""); }, function (err) { showError(err.message); });
+  }
+
+  $("api-key").value = state.apiKey;
+  $("auth").addEventListener("submit", function (e) {
+    e.preventDefault();
+    state.apiKey = $("api-key").value.trim();
+    localStorage.setItem("flgo-api-key", state.apiKey);
+    connectEvents();
+    refresh();
+  });
+
+  connectEvents();
+  refresh();
+  setInterval(refresh, REFRESH_MS);
+})();
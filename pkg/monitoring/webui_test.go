@@ -0,0 +1,55 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedWebUI(t *testing.T) {
+	server := NewAPIServer(NewMemoryStorage(&MonitoringConfig{}), &MonitoringConfig{})
+
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/", "text/html", `<script src="app.js">`},
+		{"/app.js", "javascript", "/api/v1"},
+		{"/style.css", "text/css", "#federations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("GET %s status = %d, want 200", tt.path, rr.Code)
+			}
+			if contentType := rr.Header().Get("Content-Type"); !strings.Contains(contentType, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %s", contentType, tt.contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tt.contains) {
+				t.Errorf("GET %s body does not contain %q", tt.path, tt.contains)
+			}
+		})
+	}
+}
+
+func TestWebUIDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("custom build"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := NewAPIServer(NewMemoryStorage(&MonitoringConfig{}), &MonitoringConfig{WebUIDir: dir})
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "custom build" {
+		t.Errorf("GET / = %d %q, want the configured webui_dir", rr.Code, rr.Body.String())
+	}
+}
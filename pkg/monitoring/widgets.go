@@ -0,0 +1,352 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Widget config keys understood by the data endpoint:
+//
+//	source         what the widget shows: timeseries, federation, convergence,
+//	               alerts, rounds, events, collaborators, dropped_updates,
+//	               aggregations or contributions (default: timeseries when
+//	               metrics are set)
+//	federation_id  federation the data belongs to
+//	metrics        comma-separated time series metrics; "metric" names one
+//	resource       resource source of cpu and memory series
+//	time_range     how far back a series reaches, such as 24h (default: 1h)
+//	interval       bucket width of a series (default: time_range / 100)
+//	aggregation    bucket value plotted: avg (default), min, max or count
+//	field          field of a federation or convergence widget shown as its value
+//	limit          maximum number of rows of a table widget
+
+// Widget data sources
+const (
+	WidgetSourceTimeSeries     = "timeseries"
+	WidgetSourceFederation     = "federation"
+	WidgetSourceConvergence    = "convergence"
+	WidgetSourceAlerts         = "alerts"
+	WidgetSourceRounds         = "rounds"
+	WidgetSourceEvents         = "events"
+	WidgetSourceCollaborators  = "collaborators"
+	WidgetSourceDroppedUpdates = "dropped_updates"
+	WidgetSourceAggregations   = "aggregations"
+	WidgetSourceContributions  = "contributions"
+)
+
+// defaultWidgetRange is how far back a series reaches without a time_range
+const defaultWidgetRange = time.Hour
+
+// defaultWidgetLimit is the number of rows of a table widget without a limit
+const defaultWidgetLimit = 50
+
+// WidgetData is the data of a dashboard widget, ready to render: chart
+// widgets get Series, metric widgets a Value and table and alert widgets Rows
+type WidgetData struct {
+	WidgetID     string         `json:"widget_id"`
+	Type         string         `json:"type"`
+	Source       string         `json:"source"`
+	FederationID string         `json:"federation_id"`
+	StartTime    *time.Time     `json:"start_time,omitempty"`
+	EndTime      *time.Time     `json:"end_time,omitempty"`
+	Series       []WidgetSeries `json:"series,omitempty"`
+	Value        interface{}    `json:"value,omitempty"`
+	Rows         interface{}    `json:"rows,omitempty"`
+}
+
+// WidgetSeries is a time series of a chart widget
+type WidgetSeries struct {
+	Metric      TimeSeriesMetric `json:"metric"`
+	Aggregation string           `json:"aggregation"`
+	IntervalMs  int64            `json:"interval_ms"`
+	Points      []WidgetPoint    `json:"points"`
+}
+
+// WidgetPoint is a single point of a widget series
+type WidgetPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// WidgetRange overrides the time range of a widget's config, e.g. with the
+// range picked in the dashboard. Zero fields keep the configured range.
+type WidgetRange struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Interval  time.Duration
+}
+
+// widgetQuery is the data a widget's config asks for
+type widgetQuery struct {
+	widget       *Widget
+	source       string
+	federationID string
+	metrics      []TimeSeriesMetric
+	resource     string
+	start, end   time.Time
+	interval     time.Duration
+	aggregation  string
+	field        string
+	limit        int
+}
+
+// newWidgetQuery interprets the config of a widget, applying the overrides
+// of the request
+func newWidgetQuery(widget *Widget, override WidgetRange) (*widgetQuery, error) {
+	q := &widgetQuery{
+		widget:       widget,
+		source:       configString(widget.Config, "source"),
+		federationID: configString(widget.Config, "federation_id"),
+		resource:     configString(widget.Config, "resource"),
+		aggregation:  configString(widget.Config, "aggregation"),
+		field:        configString(widget.Config, "field"),
+		limit:        defaultWidgetLimit,
+	}
+
+	metrics := configString(widget.Config, "metrics")
+	if metrics == "" {
+		metrics = configString(widget.Config, "metric")
+	}
+	if q.source == "" && metrics != "" {
+		q.source = WidgetSourceTimeSeries
+	}
+
+	switch q.source {
+	case WidgetSourceTimeSeries:
+		parsed, err := ParseTimeSeriesMetrics(metrics)
+		if err != nil {
+			return nil, err
+		}
+		q.metrics = parsed
+	case WidgetSourceFederation, WidgetSourceConvergence, WidgetSourceAlerts, WidgetSourceRounds, WidgetSourceEvents,
+		WidgetSourceCollaborators, WidgetSourceDroppedUpdates, WidgetSourceAggregations, WidgetSourceContributions:
+	case "":
+		return nil, fmt.Errorf("widget %s has no data source", widget.ID)
+	default:
+		return nil, fmt.Errorf("unsupported widget source: %s", q.source)
+	}
+
+	if q.federationID == "" {
+		return nil, fmt.Errorf("widget %s has no federation_id", widget.ID)
+	}
+
+	switch q.aggregation {
+	case "":
+		q.aggregation = "avg"
+	case "avg", "min", "max", "count":
+	default:
+		return nil, fmt.Errorf("unsupported aggregation: %s", q.aggregation)
+	}
+
+	if limit, ok, err := configInt(widget.Config, "limit"); err != nil {
+		return nil, err
+	} else if ok {
+		if limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive")
+		}
+		q.limit = limit
+	}
+
+	timeRange := defaultWidgetRange
+	if value := configString(widget.Config, "time_range"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid time_range: %s", value)
+		}
+		timeRange = parsed
+	}
+	if value := configString(widget.Config, "interval"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid interval: %s", value)
+		}
+		q.interval = parsed
+	}
+
+	q.end = time.Now()
+	if !override.EndTime.IsZero() {
+		q.end = override.EndTime
+	}
+	q.start = q.end.Add(-timeRange)
+	if !override.StartTime.IsZero() {
+		q.start = override.StartTime
+	}
+	if !q.end.After(q.start) {
+		return nil, fmt.Errorf("end_time must be after start_time")
+	}
+	if override.Interval > 0 {
+		q.interval = override.Interval
+	}
+
+	return q, nil
+}
+
+// resolve reads the data of the widget from service
+func (q *widgetQuery) resolve(ctx context.Context, service MonitoringService) (*WidgetData, error) {
+	data := &WidgetData{
+		WidgetID:     q.widget.ID,
+		Type:         q.widget.Type,
+		Source:       q.source,
+		FederationID: q.federationID,
+	}
+	filter := &MetricsFilter{FederationID: q.federationID, Page: 1, PerPage: q.limit}
+
+	var err error
+	switch q.source {
+	case WidgetSourceTimeSeries:
+		data.StartTime, data.EndTime = &q.start, &q.end
+		data.Series, err = q.series(ctx, service)
+	case WidgetSourceFederation:
+		var federation *FederationMetrics
+		if federation, err = service.GetFederation(ctx, q.federationID); err == nil {
+			data.Value, err = widgetField(federation, q.field)
+		}
+	case WidgetSourceConvergence:
+		var analysis *ConvergenceAnalysis
+		if analysis, err = service.GetConvergenceAnalysis(ctx, q.federationID); err == nil {
+			data.Value, err = widgetField(analysis, q.field)
+		}
+	case WidgetSourceAlerts:
+		var alerts []*Alert
+		if alerts, err = service.GetActiveAlerts(ctx, q.federationID); err == nil {
+			data.Value = len(alerts)
+			data.Rows = limitRows(alerts, q.limit)
+		}
+	case WidgetSourceRounds:
+		data.Rows, err = service.GetRoundHistory(ctx, filter)
+	case WidgetSourceEvents:
+		data.Rows, err = service.GetEvents(ctx, filter)
+	case WidgetSourceCollaborators:
+		var collaborators []*CollaboratorMetrics
+		if collaborators, err = service.GetFederationCollaborators(ctx, q.federationID); err == nil {
+			data.Rows = limitRows(collaborators, q.limit)
+		}
+	case WidgetSourceDroppedUpdates:
+		var dropped []*DroppedUpdateStats
+		if dropped, err = service.GetDroppedUpdateStats(ctx, &MetricsFilter{FederationID: q.federationID}); err == nil {
+			data.Rows = limitRows(dropped, q.limit)
+		}
+	case WidgetSourceAggregations:
+		data.Rows, err = service.GetAggregations(ctx, filter)
+	case WidgetSourceContributions:
+		var summary []*ContributionSummary
+		if summary, err = service.GetContributionSummary(ctx, q.federationID); err == nil {
+			data.Rows = limitRows(summary, q.limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// series reads the time series of a chart widget, keeping the configured
+// value of each bucket
+func (q *widgetQuery) series(ctx context.Context, service MonitoringService) ([]WidgetSeries, error) {
+	series, err := service.GetTimeSeries(ctx, q.federationID, &TimeSeriesQuery{
+		Metrics:   q.metrics,
+		StartTime: q.start,
+		EndTime:   q.end,
+		Interval:  q.interval,
+		Source:    q.resource,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]WidgetSeries, 0, len(series))
+	for _, s := range series {
+		points := make([]WidgetPoint, 0, len(s.Buckets))
+		for _, bucket := range s.Buckets {
+			value := bucket.Avg
+			switch q.aggregation {
+			case "min":
+				value = bucket.Min
+			case "max":
+				value = bucket.Max
+			case "count":
+				value = float64(bucket.Count)
+			}
+			points = append(points, WidgetPoint{Timestamp: bucket.Timestamp, Value: value})
+		}
+		result = append(result, WidgetSeries{
+			Metric:      s.Metric,
+			Aggregation: q.aggregation,
+			IntervalMs:  s.Interval,
+			Points:      points,
+		})
+	}
+	return result, nil
+}
+
+// widgetField returns a JSON field of a record, or the whole record when no
+// field is configured
+func widgetField(record interface{}, field string) (interface{}, error) {
+	if field == "" {
+		return record, nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	value, exists := fields[field]
+	if !exists {
+		return nil, fmt.Errorf("unknown field: %s", field)
+	}
+	return value, nil
+}
+
+// limitRows returns at most limit rows
+func limitRows[T any](rows []T, limit int) []T {
+	if len(rows) > limit {
+		return rows[:limit]
+	}
+	return rows
+}
+
+// configString returns a string value of a widget config
+func configString(config map[string]interface{}, key string) string {
+	switch value := config[key].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// configInt returns an integer value of a widget config, which is a float
+// once the config has been through JSON
+func configInt(config map[string]interface{}, key string) (int, bool, error) {
+	switch value := config[key].(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return value, true, nil
+	case float64:
+		if value != float64(int(value)) {
+			return 0, false, fmt.Errorf("%s must be an integer", key)
+		}
+		return int(value), true, nil
+	case string:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be an integer", key)
+		}
+		return parsed, true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be an integer", key)
+	}
+}
@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWidgetData(t *testing.T) {
+	server := newTenantTestServer()
+	doAPIKeyRequest(t, server, "POST", "/api/v1/federations", "lab-a-key", `{"id": "fed-a", "name": "A", "current_round": 3}`, nil)
+
+	now := time.Now().UTC()
+	for i := 1; i <= 3; i++ {
+		end := now.Add(-time.Duration(4-i) * time.Minute)
+		body := fmt.Sprintf(`{"id": "round-%d", "federation_id": "fed-a", "round_number": %d, "start_time": %q, "end_time": %q, "model_accuracy": %v}`,
+			i, i, end.Add(-30*time.Second).Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), 0.5+float64(i)*0.1)
+		if code := doAPIKeyRequest(t, server, "POST", "/api/v1/rounds", "lab-a-key", body, nil); code != http.StatusOK {
+			t.Fatalf("record round status = %d", code)
+		}
+	}
+
+	dashboard := `{"id": "custom", "name": "Custom", "widgets": [
+		{"id": "peak", "type": "chart", "config": {"federation_id": "fed-a", "metric": "accuracy", "aggregation": "max", "time_range": "1h", "interval": "1h"}},
+		{"id": "bad", "type": "chart", "config": {"federation_id": "fed-a", "source": "timeseries", "metrics": "unknown"}}
+	]}`
+	if code := doAPIKeyRequest(t, server, "POST", "/api/v1/dashboards", "lab-a-key", dashboard, nil); code != http.StatusOK {
+		t.Fatalf("create dashboard status = %d", code)
+	}
+
+	var data WidgetData
+	if code := doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards/custom/widgets/peak/data", "lab-a-key", "", &data); code != http.StatusOK {
+		t.Fatalf("series widget status = %d", code)
+	}
+	if data.Source != WidgetSourceTimeSeries || len(data.Series) != 1 || len(data.Series[0].Points) != 1 {
+		t.Fatalf("series widget = %+v", data)
+	}
+	if value := data.Series[0].Points[0].Value; value < 0.79 || value > 0.81 {
+		t.Errorf("max accuracy = %v, want 0.8", value)
+	}
+
+	// The request's time range overrides the configured one
+	path := "/api/v1/dashboards/custom/widgets/peak/data?start_time=" + now.Add(-2*time.Hour).Format(time.RFC3339) +
+		"&end_time=" + now.Add(-time.Hour).Format(time.RFC3339)
+	doAPIKeyRequest(t, server, "GET", path, "lab-a-key", "", &data)
+	if len(data.Series) != 1 || len(data.Series[0].Points) != 0 {
+		t.Errorf("series outside the range = %+v", data.Series)
+	}
+
+	doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards/fed-a-federation-overview/widgets/current-round/data", "lab-a-key", "", &data)
+	if data.Value != float64(3) {
+		t.Errorf("current round = %v, want 3", data.Value)
+	}
+
+	var rows struct {
+		Rows []*RoundMetrics `json:"rows"`
+	}
+	doAPIKeyRequest(t, server, "GET", "/api/v1/dashboards/fed-a-federation-overview/widgets/recent-rounds/data", "lab-a-key", "", &rows)
+	if len(rows.Rows) != 3 || rows.Rows[0].RoundNumber != 3 {
+		t.Errorf("recent rounds = %+v", rows.Rows)
+	}
+
+	errors := []struct {
+		path   string
+		apiKey string
+		want   int
+	}{
+		{"/api/v1/dashboards/custom/widgets/bad/data", "lab-a-key", http.StatusBadRequest},
+		{"/api/v1/dashboards/custom/widgets/missing/data", "lab-a-key", http.StatusNotFound},
+		{"/api/v1/dashboards/custom/widgets/peak/data?interval=soon", "lab-a-key", http.StatusBadRequest},
+		{"/api/v1/dashboards/custom/widgets/peak/data", "lab-b-key", http.StatusNotFound},
+	}
+	for _, tt := range errors {
+		if code := doAPIKeyRequest(t, server, "GET", tt.path, tt.apiKey, "", nil); code != tt.want {
+			t.Errorf("GET %s as %s = %d, want %d", tt.path, tt.apiKey, code, tt.want)
+		}
+	}
+}
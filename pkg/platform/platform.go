@@ -0,0 +1,42 @@
+// Package platform hides the differences between the operating systems FL-GO
+// runs on: the Python interpreter that runs tasks, and how a process is asked
+// to stop.
+package platform
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+)
+
+// Python returns the interpreter python tasks run with: the task's own
+// interpreter if it sets one, then FLGO_PYTHON, then python on Windows, whose
+// installers do not create python3, and python3 elsewhere
+func Python(interpreter string) string {
+	if interpreter != "" {
+		return interpreter
+	}
+	if python := os.Getenv("FLGO_PYTHON"); python != "" {
+		return python
+	}
+	if runtime.GOOS == "windows" {
+		return "python"
+	}
+	return "python3"
+}
+
+// ShutdownContext returns a context that is cancelled when the process is
+// asked to stop: on Ctrl+C and SIGTERM, and when it runs as a Windows service,
+// on a stop or shutdown request of the service control manager. The service
+// is only reported stopped once stop is called, so the process can finish its
+// cleanup, such as writing the run report, before Windows ends it.
+func ShutdownContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	ctx, stopSignals := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	ctx, stopService := serviceContext(ctx)
+	return ctx, func() {
+		stopService()
+		stopSignals()
+	}
+}
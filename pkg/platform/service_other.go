@@ -0,0 +1,11 @@
+//go:build !windows
+
+package platform
+
+import "context"
+
+// serviceContext returns parent, since only Windows runs processes as services
+// that are stopped by other means than signals
+func serviceContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return parent, func() {}
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package platform
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// serviceContext cancels the context on a stop or shutdown request when the
+// process runs as a Windows service. Services get no console signals, so
+// without it the service control manager would kill the process after its
+// timeout.
+func serviceContext(parent context.Context) (context.Context, context.CancelFunc) {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		log.Printf("Failed to detect Windows service: %v", err)
+	}
+	if !isService {
+		return parent, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	handler := &service{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		// The service name is ignored for services that run in their own process
+		if err := svc.Run("", handler); err != nil {
+			log.Printf("Windows service failed: %v", err)
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(handler.done) })
+		cancel()
+	}
+}
+
+// service answers the requests of the service control manager
+type service struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the process finished its cleanup
+}
+
+func (s *service) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				s.cancel()
+				<-s.done
+				return false, 0
+			}
+		case <-s.done:
+			return false, 0
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backend stores registry objects by key. Keys are slash-separated paths.
+type Backend interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error) // returns ErrNotFound for missing keys
+}
+
+// LocalBackend stores objects as files below a directory
+type LocalBackend struct {
+	dir string
+}
+
+// NewLocalBackend creates a backend rooted at dir
+func NewLocalBackend(dir string) *LocalBackend {
+	return &LocalBackend{dir: dir}
+}
+
+// Put writes the object atomically, so that readers never see a partial file
+func (b *LocalBackend) Put(key string, data []byte) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (b *LocalBackend) Get(key string) ([]byte, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - key validated in path()
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// path maps a key to a file below the backend directory
+func (b *LocalBackend) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid registry key %q", key)
+	}
+	return filepath.Join(b.dir, clean), nil
+}
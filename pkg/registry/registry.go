@@ -0,0 +1,336 @@
+// Package registry keeps versioned global models with their lineage and
+// training metadata in a local directory or an S3 bucket.
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// indexKey is the object that lists every registered model version
+const indexKey = "index.json"
+
+// validName restricts model names to characters that are safe in object keys
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ErrNotFound is returned when a model, version or object does not exist
+var ErrNotFound = errors.New("not found")
+
+// Stage marks the lifecycle state of a model version
+type Stage string
+
+const (
+	StageNone       Stage = ""
+	StageStaging    Stage = "staging"
+	StageProduction Stage = "production" // at most one version per model
+	StageArchived   Stage = "archived"   // former production versions
+)
+
+// ParseStage validates a stage name given to promote
+func ParseStage(s string) (Stage, error) {
+	switch Stage(s) {
+	case StageStaging, StageProduction, StageArchived:
+		return Stage(s), nil
+	default:
+		return "", fmt.Errorf("invalid stage %q (expected staging, production or archived)", s)
+	}
+}
+
+// Config selects and configures the registry backend
+type Config struct {
+	Enabled         bool   `yaml:"enabled"`
+	Backend         string `yaml:"backend"`           // local (default) or s3
+	Name            string `yaml:"name"`              // Model name (default: the federation ID)
+	Dir             string `yaml:"dir"`               // Local backend directory (default: registry)
+	Bucket          string `yaml:"bucket"`            // S3 bucket
+	Prefix          string `yaml:"prefix"`            // S3 key prefix
+	Region          string `yaml:"region"`            // S3 region (default: us-east-1)
+	Endpoint        string `yaml:"endpoint"`          // S3-compatible endpoint such as MinIO, addressed path-style
+	AccessKeyID     string `yaml:"access_key_id"`     // Default: AWS_ACCESS_KEY_ID
+	SecretAccessKey string `yaml:"secret_access_key"` // Default: AWS_SECRET_ACCESS_KEY
+}
+
+// ModelVersion is the metadata of a registered model
+type ModelVersion struct {
+	Name       string            `json:"name"`
+	Version    int               `json:"version"`
+	Parent     int               `json:"parent,omitempty"` // version this model was aggregated from; 0 for the first of a run that did not warm-start from this model
+	Algorithm  string            `json:"algorithm,omitempty"`
+	Mode       string            `json:"mode,omitempty"`
+	Round      int               `json:"round"`
+	Checksum   string            `json:"checksum"` // sha256 of the model file
+	Size       int               `json:"size"`
+	Stage      Stage             `json:"stage,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	PromotedAt *time.Time        `json:"promoted_at,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// RegisterOptions describes how a model was produced
+type RegisterOptions struct {
+	Parent    int
+	Algorithm string
+	Mode      string
+	Round     int
+	Metadata  map[string]string
+}
+
+// Registry stores model versions in a backend. The index is re-read for every
+// operation so that the aggregator and the monitoring server can share one
+// registry.
+type Registry struct {
+	backend Backend
+	mu      sync.Mutex
+}
+
+// New creates a registry on top of backend
+func New(backend Backend) *Registry {
+	return &Registry{backend: backend}
+}
+
+// Open creates a registry with the backend selected by config
+func Open(config Config) (*Registry, error) {
+	switch config.Backend {
+	case "", "local":
+		dir := config.Dir
+		if dir == "" {
+			dir = "registry"
+		}
+		return New(NewLocalBackend(dir)), nil
+	case "s3":
+		backend, err := NewS3Backend(config)
+		if err != nil {
+			return nil, err
+		}
+		return New(backend), nil
+	default:
+		return nil, fmt.Errorf("unknown registry backend %q (expected local or s3)", config.Backend)
+	}
+}
+
+// Register stores model as the next version of name
+func (r *Registry) Register(name string, model []byte, opts RegisterOptions) (*ModelVersion, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid model name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	version := &ModelVersion{
+		Name:      name,
+		Version:   1,
+		Parent:    opts.Parent,
+		Algorithm: opts.Algorithm,
+		Mode:      opts.Mode,
+		Round:     opts.Round,
+		Checksum:  checksum(model),
+		Size:      len(model),
+		CreatedAt: time.Now(),
+		Metadata:  opts.Metadata,
+	}
+	for _, v := range versions {
+		if v.Name == name && v.Version >= version.Version {
+			version.Version = v.Version + 1
+		}
+	}
+
+	if err := r.backend.Put(modelKey(name, version.Version), model); err != nil {
+		return nil, fmt.Errorf("failed to store model: %w", err)
+	}
+	if err := r.save(append(versions, version)); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// List returns the versions of name, or of every model when name is empty,
+// ordered by name and version
+func (r *Registry) List(name string) ([]*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*ModelVersion
+	for _, v := range versions {
+		if name == "" || v.Name == name {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// Get returns a version of name. ref is a version number, "latest" or a stage
+// such as "production".
+func (r *Registry) Get(name, ref string) (*ModelVersion, error) {
+	versions, err := r.List(name)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(versions, name, ref)
+}
+
+// Download returns the model file of a version after verifying its checksum
+func (r *Registry) Download(version *ModelVersion) ([]byte, error) {
+	data, err := r.backend.Get(modelKey(version.Name, version.Version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s v%d: %w", version.Name, version.Version, err)
+	}
+	if sum := checksum(data); sum != version.Checksum {
+		return nil, fmt.Errorf("checksum mismatch for %s v%d: got %s, want %s", version.Name, version.Version, sum, version.Checksum)
+	}
+	return data, nil
+}
+
+// Promote moves a version to stage. Promoting to production archives the
+// model's previous production version.
+func (r *Registry) Promote(name string, number int, stage Stage) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var target *ModelVersion
+	for _, v := range versions {
+		if v.Name == name && v.Version == number {
+			target = v
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%s v%d: %w", name, number, ErrNotFound)
+	}
+
+	now := time.Now()
+	if stage == StageProduction {
+		for _, v := range versions {
+			if v.Name == name && v.Stage == StageProduction && v != target {
+				v.Stage = StageArchived
+			}
+		}
+	}
+	target.Stage = stage
+	target.PromotedAt = &now
+
+	if err := r.save(versions); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Lineage returns version and its ancestors, newest first
+func (r *Registry) Lineage(version *ModelVersion) ([]*ModelVersion, error) {
+	versions, err := r.List(version.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[int]*ModelVersion, len(versions))
+	for _, v := range versions {
+		byNumber[v.Version] = v
+	}
+
+	lineage := []*ModelVersion{version}
+	for parent := version.Parent; parent != 0; {
+		v, ok := byNumber[parent]
+		if !ok || len(lineage) > len(versions) {
+			break
+		}
+		lineage = append(lineage, v)
+		parent = v.Parent
+	}
+	return lineage, nil
+}
+
+func (r *Registry) load() ([]*ModelVersion, error) {
+	data, err := r.backend.Get(indexKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry index: %w", err)
+	}
+
+	var versions []*ModelVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse registry index: %w", err)
+	}
+	return versions, nil
+}
+
+func (r *Registry) save(versions []*ModelVersion) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := r.backend.Put(indexKey, data); err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	return nil
+}
+
+// resolve picks the version of name matching ref from versions sorted by number
+func resolve(versions []*ModelVersion, name, ref string) (*ModelVersion, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("model %s: %w", name, ErrNotFound)
+	}
+
+	if ref == "" || ref == "latest" {
+		return versions[len(versions)-1], nil
+	}
+
+	if number, err := strconv.Atoi(strings.TrimPrefix(ref, "v")); err == nil {
+		for _, v := range versions {
+			if v.Version == number {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%s v%d: %w", name, number, ErrNotFound)
+	}
+
+	stage, err := ParseStage(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: expected a number, latest or a stage", ref)
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Stage == stage {
+			return versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no %s version: %w", name, stage, ErrNotFound)
+}
+
+func modelKey(name string, version int) string {
+	return fmt.Sprintf("models/%s/v%d.bin", name, version)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
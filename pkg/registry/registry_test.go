@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryVersionsAndLineage(t *testing.T) {
+	dir := t.TempDir()
+	registry, err := Open(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	parent := 0
+	for round := 1; round <= 3; round++ {
+		version, err := registry.Register("fed-1", []byte{byte(round)}, RegisterOptions{Parent: parent, Algorithm: "fedavg", Round: round})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		if version.Version != round {
+			t.Errorf("round %d registered as v%d", round, version.Version)
+		}
+		parent = version.Version
+	}
+	if _, err := registry.Register("fed-2", []byte{9}, RegisterOptions{Round: 1}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := registry.Register("../escape", []byte{9}, RegisterOptions{}); err == nil {
+		t.Error("Expected an invalid model name to be rejected")
+	}
+
+	all, _ := registry.List("")
+	fed1, _ := registry.List("fed-1")
+	if len(all) != 4 || len(fed1) != 3 {
+		t.Errorf("List() returned %d versions and %d for fed-1, want 4 and 3", len(all), len(fed1))
+	}
+
+	latest, err := registry.Get("fed-1", "latest")
+	if err != nil || latest.Version != 3 {
+		t.Fatalf("Get(latest) = %v, %v", latest, err)
+	}
+	lineage, _ := registry.Lineage(latest)
+	if len(lineage) != 3 || lineage[2].Version != 1 {
+		t.Errorf("Lineage(v3) has %d entries, want v3 -> v2 -> v1", len(lineage))
+	}
+
+	if _, err := registry.Get("fed-1", "production"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(production) before promotion error = %v, want ErrNotFound", err)
+	}
+	if _, err := registry.Promote("fed-1", 2, StageProduction); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	if _, err := registry.Promote("fed-1", 3, StageProduction); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	production, _ := registry.Get("fed-1", "production")
+	previous, _ := registry.Get("fed-1", "v2")
+	if production.Version != 3 || previous.Stage != StageArchived {
+		t.Errorf("production = v%d, v2 stage = %q; want v3 and archived", production.Version, previous.Stage)
+	}
+
+	data, err := registry.Download(previous)
+	if err != nil || len(data) != 1 || data[0] != 2 {
+		t.Errorf("Download(v2) = %v, %v", data, err)
+	}
+
+	// A modified model file fails checksum verification
+	if err := os.WriteFile(filepath.Join(dir, "models", "fed-1", "v2.bin"), []byte{7}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Download(previous); err == nil {
+		t.Error("Expected a checksum mismatch")
+	}
+}
+
+func TestRegistryGetErrors(t *testing.T) {
+	registry := New(NewLocalBackend(t.TempDir()))
+	if _, err := registry.Register("fed-1", []byte{1}, RegisterOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{"missing model", ""},
+		{"missing version", "5"},
+		{"invalid reference", "newest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := "fed-1"
+			if tt.ref == "" {
+				model = "unknown"
+			}
+			if _, err := registry.Get(model, tt.ref); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
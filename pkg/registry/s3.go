@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+)
+
+// S3Backend stores objects in an S3 bucket, or in an S3-compatible store
+// when an endpoint is configured
+type S3Backend struct {
+	client *storage.S3Client
+	prefix string
+}
+
+// NewS3Backend creates an S3 backend from the registry configuration.
+// Credentials and region default to the AWS environment variables.
+func NewS3Backend(config Config) (*S3Backend, error) {
+	s3Config := storage.S3Config{
+		Bucket:          config.Bucket,
+		Region:          config.Region,
+		Endpoint:        config.Endpoint,
+		AccessKeyID:     config.AccessKeyID,
+		SecretAccessKey: config.SecretAccessKey,
+	}
+	env := storage.S3ConfigFromEnv(config.Bucket)
+	if s3Config.AccessKeyID == "" && s3Config.SecretAccessKey == "" {
+		s3Config.AccessKeyID = env.AccessKeyID
+		s3Config.SecretAccessKey = env.SecretAccessKey
+		s3Config.SessionToken = env.SessionToken
+	}
+	if s3Config.Region == "" {
+		s3Config.Region = env.Region
+	}
+
+	client, err := storage.NewS3Client(s3Config)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Backend{client: client, prefix: strings.Trim(config.Prefix, "/")}, nil
+}
+
+func (b *S3Backend) Put(key string, data []byte) error {
+	return b.client.Put(b.key(key), data)
+}
+
+func (b *S3Backend) Get(key string) ([]byte, error) {
+	data, err := b.client.Get(b.key(key))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (b *S3Backend) key(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+	return b.prefix + "/" + key
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestS3Backend(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	registry, err := Open(Config{Backend: "s3", Endpoint: server.URL, Bucket: "models", Prefix: "fl-go",
+		AccessKeyID: "key", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	version, err := registry.Register("fed-1", []byte{1, 2, 3, 4}, RegisterOptions{Round: 1})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, ok := objects["/models/fl-go/models/fed-1/v1.bin"]; !ok {
+		t.Errorf("Expected path-style object keys below the prefix, got %v", objects)
+	}
+
+	data, err := registry.Download(version)
+	if err != nil || len(data) != 4 {
+		t.Errorf("Download() = %v, %v", data, err)
+	}
+}
@@ -0,0 +1,222 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Attestation evidence types of the trusted execution environments
+// collaborators commonly run in
+const (
+	AttestationSGX    = "sgx"     // Intel SGX DCAP quote
+	AttestationSEVSNP = "sev-snp" // AMD SEV-SNP attestation report
+	AttestationNitro  = "nitro"   // AWS Nitro Enclaves attestation document
+)
+
+// attestationReportDataPrefix separates attestation report data from other
+// uses of the hash
+const attestationReportDataPrefix = "fl-go attestation v2\n"
+
+// ErrNoAttestation is returned when an attested collaborator is required but
+// none was presented
+var ErrNoAttestation = errors.New("no attestation evidence was presented")
+
+// AttestationReportData is what a collaborator's evidence must commit to in
+// its report data, user data or nonce field: the SHA-256 of its ID, plan
+// fingerprint and the aggregator's challenge nonce, so that evidence cannot
+// be replayed by another collaborator, for another federation or in a later
+// join
+func AttestationReportData(collaboratorID, planFingerprint string, nonce []byte) []byte {
+	sum := sha256.Sum256([]byte(attestationReportDataPrefix + collaboratorID + "\n" + planFingerprint + "\n" + hex.EncodeToString(nonce)))
+	return sum[:]
+}
+
+// AttestationClaims are what a verifier vouches for once the evidence checks
+// out against the hardware vendor's root of trust
+type AttestationClaims struct {
+	Type        string `json:"type"`
+	Measurement string `json:"measurement"` // hex digest of the enclave's code: MRENCLAVE, SNP launch measurement or Nitro PCR0
+	ReportData  string `json:"report_data"` // hex report data the evidence commits to
+	Debug       bool   `json:"debug,omitempty"`
+}
+
+// Attester produces the attestation evidence of the environment a
+// collaborator runs in, committing to reportData
+type Attester interface {
+	Attest(ctx context.Context, reportData []byte) ([]byte, error)
+}
+
+// AttestationVerifier checks attestation evidence of one type and returns
+// its claims
+type AttestationVerifier interface {
+	Verify(ctx context.Context, evidence []byte) (*AttestationClaims, error)
+}
+
+var (
+	attestersMu sync.RWMutex
+	attesters   = make(map[string]Attester)
+	verifiersMu sync.RWMutex
+	verifiers   = make(map[string]AttestationVerifier)
+)
+
+// RegisterAttester makes attester available to collaborators with
+// attestation type evidenceType and no command
+func RegisterAttester(evidenceType string, attester Attester) {
+	attestersMu.Lock()
+	defer attestersMu.Unlock()
+	attesters[evidenceType] = attester
+}
+
+// RegisterAttestationVerifier makes verifier check evidence of evidenceType
+// on aggregators. Registered verifiers take precedence over the plan's
+// verifier_command.
+func RegisterAttestationVerifier(evidenceType string, verifier AttestationVerifier) {
+	verifiersMu.Lock()
+	defer verifiersMu.Unlock()
+	verifiers[evidenceType] = verifier
+}
+
+// NewAttester returns the attester of evidenceType: command when it is set,
+// and the registered attester otherwise
+func NewAttester(evidenceType string, command []string) (Attester, error) {
+	if len(command) > 0 {
+		return &CommandAttester{Command: command}, nil
+	}
+	attestersMu.RLock()
+	defer attestersMu.RUnlock()
+	attester, ok := attesters[evidenceType]
+	if !ok {
+		return nil, fmt.Errorf("no attester is registered for %s evidence; set a command", evidenceType)
+	}
+	return attester, nil
+}
+
+// CommandAttester runs a command, such as a vendor tool, that writes the
+// evidence to stdout. The report data is passed hex encoded in
+// FL_ATTESTATION_REPORT_DATA.
+type CommandAttester struct {
+	Command []string
+}
+
+// Attest runs the command
+func (a *CommandAttester) Attest(ctx context.Context, reportData []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, a.Command[0], a.Command[1:]...) // #nosec G204 - the command comes from the collaborator's own config
+	cmd.Env = append(os.Environ(), "FL_ATTESTATION_REPORT_DATA="+hex.EncodeToString(reportData))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	evidence, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %s", a.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if len(evidence) == 0 {
+		return nil, fmt.Errorf("%s wrote no evidence", a.Command[0])
+	}
+	return evidence, nil
+}
+
+// CommandVerifier runs a command with the evidence on stdin and its type in
+// FL_ATTESTATION_TYPE. The command checks the evidence against the vendor's
+// certificates, prints the AttestationClaims as JSON and exits non-zero for
+// invalid evidence.
+type CommandVerifier struct {
+	Type    string
+	Command []string
+}
+
+// Verify runs the command
+func (v *CommandVerifier) Verify(ctx context.Context, evidence []byte) (*AttestationClaims, error) {
+	cmd := exec.CommandContext(ctx, v.Command[0], v.Command[1:]...) // #nosec G204 - the command comes from the aggregator's plan
+	cmd.Env = append(os.Environ(), "FL_ATTESTATION_TYPE="+v.Type)
+	cmd.Stdin = bytes.NewReader(evidence)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s rejected the evidence: %v: %s", v.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	var claims AttestationClaims
+	if err := json.Unmarshal(out, &claims); err != nil {
+		return nil, fmt.Errorf("%s printed invalid claims: %v", v.Command[0], err)
+	}
+	return &claims, nil
+}
+
+// AttestationPolicy admits collaborators by their attestation evidence
+type AttestationPolicy struct {
+	Required        bool     // reject collaborators without valid evidence
+	Types           []string // accepted evidence types; empty accepts any
+	Measurements    []string // allowed hex measurements; empty accepts any verified one
+	AllowDebug      bool     // admit enclaves in debug mode
+	VerifierCommand []string // verifies evidence of types without a registered verifier
+	Exempt          []string // collaborator IDs admitted without evidence
+}
+
+// Admit checks the evidence a collaborator presented when joining with plan
+// fingerprint planFingerprint, in answer to the challenge nonce. It returns
+// the verified claims, or nil claims when the collaborator is exempt or
+// presented no evidence and none is required.
+func (p *AttestationPolicy) Admit(ctx context.Context, collaboratorID, planFingerprint string, nonce []byte, evidenceType string, evidence []byte) (*AttestationClaims, error) {
+	if slices.Contains(p.Exempt, collaboratorID) {
+		return nil, nil
+	}
+	if len(evidence) == 0 {
+		if p.Required {
+			return nil, ErrNoAttestation
+		}
+		return nil, nil
+	}
+	if len(p.Types) > 0 && !slices.Contains(p.Types, evidenceType) {
+		return nil, fmt.Errorf("%s evidence is not accepted (accepted: %s)", evidenceType, strings.Join(p.Types, ", "))
+	}
+	verifier, err := p.verifier(evidenceType)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifier.Verify(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("the evidence answers no attestation challenge")
+	}
+	want := hex.EncodeToString(AttestationReportData(collaboratorID, planFingerprint, nonce))
+	if !strings.EqualFold(claims.ReportData, want) {
+		return nil, fmt.Errorf("the evidence was produced for another collaborator, plan or challenge")
+	}
+	if claims.Debug && !p.AllowDebug {
+		return nil, fmt.Errorf("the enclave runs in debug mode")
+	}
+	if len(p.Measurements) > 0 && !slices.ContainsFunc(p.Measurements, func(m string) bool {
+		return strings.EqualFold(m, claims.Measurement)
+	}) {
+		return nil, fmt.Errorf("enclave measurement %.16s is not allowed", claims.Measurement)
+	}
+	claims.Type = evidenceType
+	return claims, nil
+}
+
+// verifier returns the registered verifier of evidenceType, or the policy's
+// verifier command
+func (p *AttestationPolicy) verifier(evidenceType string) (AttestationVerifier, error) {
+	verifiersMu.RLock()
+	verifier, ok := verifiers[evidenceType]
+	verifiersMu.RUnlock()
+	if ok {
+		return verifier, nil
+	}
+	if len(p.VerifierCommand) > 0 {
+		return &CommandVerifier{Type: evidenceType, Command: p.VerifierCommand}, nil
+	}
+	return nil, fmt.Errorf("no verifier for %s evidence; set security.attestation.verifier_command", evidenceType)
+}
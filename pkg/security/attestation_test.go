@@ -0,0 +1,94 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeEvidence is evidence whose claims a shell command can print: the
+// attester writes the claims and the verifier echoes them
+const fakeEvidence = `printf '{"measurement": "%s", "report_data": "%s", "debug": %s}' "$MEASUREMENT" "$FL_ATTESTATION_REPORT_DATA" "${DEBUG:-false}"`
+
+func TestAttestationPolicy(t *testing.T) {
+	ctx := context.Background()
+	challenge := []byte("challenge-1")
+	attestTo := func(t *testing.T, collaboratorID string, nonce []byte, env string) []byte {
+		t.Helper()
+		attester, err := NewAttester(AttestationSEVSNP, []string{"sh", "-c", env + "; " + fakeEvidence})
+		if err != nil {
+			t.Fatal(err)
+		}
+		evidence, err := attester.Attest(ctx, AttestationReportData(collaboratorID, "plan-fp", nonce))
+		if err != nil {
+			t.Fatalf("Attest: %v", err)
+		}
+		return evidence
+	}
+	attest := func(t *testing.T, collaboratorID, env string) []byte {
+		t.Helper()
+		return attestTo(t, collaboratorID, challenge, env)
+	}
+	policy := func() *AttestationPolicy {
+		return &AttestationPolicy{
+			Required:        true,
+			Types:           []string{AttestationSEVSNP},
+			Measurements:    []string{"ABCD"},
+			VerifierCommand: []string{"cat"},
+			Exempt:          []string{"edge-1"},
+		}
+	}
+
+	claims, err := policy().Admit(ctx, "collab-a", "plan-fp", challenge, AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd"))
+	if err != nil {
+		t.Fatalf("Admit: %v", err)
+	}
+	if claims.Type != AttestationSEVSNP || claims.Measurement != "abcd" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	tests := []struct {
+		name           string
+		edit           func(p *AttestationPolicy)
+		collaboratorID string
+		evidenceType   string
+		evidence       []byte
+		wantClaims     bool
+		wantErr        bool
+	}{
+		{"evidence of another collaborator", nil, "collab-b", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd"), false, true},
+		{"evidence of another challenge", nil, "collab-a", AttestationSEVSNP, attestTo(t, "collab-a", []byte("challenge-0"), "MEASUREMENT=abcd"), false, true},
+		{"measurement not allowed", nil, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=ffff"), false, true},
+		{"any measurement", func(p *AttestationPolicy) { p.Measurements = nil }, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=ffff"), true, false},
+		{"debug enclave", nil, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd DEBUG=true"), false, true},
+		{"debug enclave allowed", func(p *AttestationPolicy) { p.AllowDebug = true }, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd DEBUG=true"), true, false},
+		{"type not accepted", nil, "collab-a", AttestationNitro, attest(t, "collab-a", "MEASUREMENT=abcd"), false, true},
+		{"verifier rejects", func(p *AttestationPolicy) { p.VerifierCommand = []string{"false"} }, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd"), false, true},
+		{"no verifier", func(p *AttestationPolicy) { p.VerifierCommand = nil }, "collab-a", AttestationSEVSNP, attest(t, "collab-a", "MEASUREMENT=abcd"), false, true},
+		{"no evidence", nil, "collab-a", "", nil, false, true},
+		{"no evidence, not required", func(p *AttestationPolicy) { p.Required = false }, "collab-a", "", nil, false, false},
+		{"exempt", nil, "edge-1", "", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy()
+			if tt.edit != nil {
+				tt.edit(p)
+			}
+			claims, err := p.Admit(ctx, tt.collaboratorID, "plan-fp", challenge, tt.evidenceType, tt.evidence)
+			if (err != nil) != tt.wantErr || (claims != nil) != tt.wantClaims {
+				t.Errorf("Admit() = %+v, %v", claims, err)
+			}
+		})
+	}
+
+	if _, err := policy().Admit(ctx, "collab-a", "plan-fp", nil, AttestationSEVSNP, attestTo(t, "collab-a", nil, "MEASUREMENT=abcd")); err == nil {
+		t.Error("evidence without a challenge was admitted")
+	}
+	if _, err := policy().Admit(ctx, "collab-a", "plan-fp", challenge, "", nil); !errors.Is(err, ErrNoAttestation) {
+		t.Errorf("missing evidence error = %v, want ErrNoAttestation", err)
+	}
+	if _, err := NewAttester(AttestationSGX, nil); err == nil {
+		t.Error("attester without a command or registration was created")
+	}
+}
@@ -0,0 +1,211 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Encrypted updates pack several fixed-point weights into each Paillier
+// plaintext. Every weight takes a slot of heSlotBits bits, of which it uses
+// heValueBits after being offset to be non-negative; the bits above leave
+// room for the slots of summed updates to grow without carrying into the
+// next slot.
+const (
+	heSlotBits     = 64
+	heValueBits    = 48
+	heFractionBits = 16 // fixed-point precision of the encrypted weights
+)
+
+// MaxEncryptedUpdates is the most updates an EncryptedSum holds before its
+// slots could overflow
+const MaxEncryptedUpdates = 1 << (heSlotBits - heValueBits)
+
+var (
+	heScale  = math.Ldexp(1, heFractionBits)
+	heOffset = int64(1) << (heValueBits - 1)
+)
+
+// heSlots returns how many weights the plaintexts of key hold. Their slots
+// stay below 2^(N.BitLen()-1) < N, so sums are never reduced modulo N.
+func heSlots(key *PaillierPublicKey) int {
+	return (key.N.BitLen() - 1) / heSlotBits
+}
+
+// EncryptedUpdateSize returns the size of an update of modelSize weights
+// encrypted with key
+func EncryptedUpdateSize(key *PaillierPublicKey, modelSize int) int {
+	slots := heSlots(key)
+	return (modelSize + slots - 1) / slots * key.CiphertextSize()
+}
+
+// EncryptUpdate encrypts weights scaled by weight, the update's sample count,
+// so that decrypting the sum of several updates gives their weighted sum.
+// Scaled weights must stay within ±2^31.
+func EncryptUpdate(key *PaillierPublicKey, weights []float32, weight float64) ([]byte, error) {
+	slots := heSlots(key)
+	size := key.CiphertextSize()
+	count := (len(weights) + slots - 1) / slots
+	out := make([]byte, count*size)
+	err := forEachCiphertext(count, func(i int) error {
+		chunk := weights[i*slots : min((i+1)*slots, len(weights))]
+		plaintext := make([]byte, slots*heSlotBits/8)
+		for j, w := range chunk {
+			q := math.Round(float64(w) * weight * heScale)
+			if math.IsNaN(q) || math.Abs(q) >= float64(heOffset) {
+				return fmt.Errorf("weight %d of the update, %g, is out of the encryptable range", i*slots+j, float64(w)*weight)
+			}
+			// Slot j holds bits [64j, 64j+64) of the big-endian plaintext
+			binary.BigEndian.PutUint64(plaintext[len(plaintext)-(j+1)*8:], uint64(int64(q)+heOffset)) // #nosec G115 - q is offset to be non-negative
+		}
+		c, err := key.Encrypt(rand.Reader, new(big.Int).SetBytes(plaintext))
+		if err != nil {
+			return err
+		}
+		c.FillBytes(out[i*size : (i+1)*size])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncryptedSum adds encrypted updates without decrypting them
+type EncryptedSum struct {
+	key       *PaillierPublicKey
+	modelSize int
+	mu        sync.Mutex
+	sums      []*big.Int
+	count     int
+}
+
+// NewEncryptedSum returns an empty sum of encrypted updates of modelSize
+// weights
+func NewEncryptedSum(key *PaillierPublicKey, modelSize int) *EncryptedSum {
+	return &EncryptedSum{key: key, modelSize: modelSize}
+}
+
+// Add adds an update encrypted by EncryptUpdate. An invalid update leaves
+// the sum unchanged.
+func (s *EncryptedSum) Add(data []byte) error {
+	ciphertexts, err := parseCiphertexts(s.key, data, s.modelSize)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count >= MaxEncryptedUpdates {
+		return fmt.Errorf("an encrypted sum holds at most %d updates", MaxEncryptedUpdates)
+	}
+	if s.sums == nil {
+		s.sums = ciphertexts
+	} else {
+		_ = forEachCiphertext(len(s.sums), func(i int) error {
+			s.sums[i] = s.key.Add(s.sums[i], ciphertexts[i])
+			return nil
+		})
+	}
+	s.count++
+	return nil
+}
+
+// Count returns the number of updates added
+func (s *EncryptedSum) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Bytes returns the encrypted sum in the encoding of EncryptUpdate
+func (s *EncryptedSum) Bytes() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size := s.key.CiphertextSize()
+	out := make([]byte, len(s.sums)*size)
+	for i, c := range s.sums {
+		c.FillBytes(out[i*size : (i+1)*size])
+	}
+	return out
+}
+
+// DecryptSum decrypts the sum of count encrypted updates of modelSize weights
+// and returns the weighted sum of each weight
+func DecryptSum(key *PaillierPrivateKey, data []byte, count, modelSize int) ([]float64, error) {
+	if count <= 0 || count > MaxEncryptedUpdates {
+		return nil, fmt.Errorf("invalid number of summed updates %d", count)
+	}
+	ciphertexts, err := parseCiphertexts(key.Public(), data, modelSize)
+	if err != nil {
+		return nil, err
+	}
+	slots := heSlots(key.Public())
+	offset := new(big.Int).Mul(big.NewInt(heOffset), big.NewInt(int64(count)))
+	sums := make([]float64, modelSize)
+	err = forEachCiphertext(len(ciphertexts), func(i int) error {
+		m, err := key.Decrypt(ciphertexts[i])
+		if err != nil {
+			return err
+		}
+		plaintext := m.FillBytes(make([]byte, slots*heSlotBits/8))
+		for j := 0; j < slots && i*slots+j < modelSize; j++ {
+			slot := new(big.Int).SetBytes(plaintext[len(plaintext)-(j+1)*8 : len(plaintext)-j*8])
+			value, _ := new(big.Float).SetInt(slot.Sub(slot, offset)).Float64()
+			sums[i*slots+j] = value / heScale
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// parseCiphertexts splits an encrypted update of modelSize weights into its
+// ciphertexts
+func parseCiphertexts(key *PaillierPublicKey, data []byte, modelSize int) ([]*big.Int, error) {
+	if len(data) != EncryptedUpdateSize(key, modelSize) {
+		return nil, fmt.Errorf("encrypted update has %d bytes, expected %d for %d weights",
+			len(data), EncryptedUpdateSize(key, modelSize), modelSize)
+	}
+	size := key.CiphertextSize()
+	ciphertexts := make([]*big.Int, len(data)/size)
+	for i := range ciphertexts {
+		ciphertexts[i] = new(big.Int).SetBytes(data[i*size : (i+1)*size])
+		if !key.validCiphertext(ciphertexts[i]) {
+			return nil, errors.New("encrypted update holds an invalid ciphertext")
+		}
+	}
+	return ciphertexts, nil
+}
+
+// forEachCiphertext calls fn for 0 <= i < n on all CPUs and returns the first
+// error
+func forEachCiphertext(n int, fn func(i int) error) error {
+	var (
+		next     atomic.Int64
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				if err := fn(i); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
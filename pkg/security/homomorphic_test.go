@@ -0,0 +1,130 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"math"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPaillier(t *testing.T) {
+	key, err := GeneratePaillierKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatalf("GeneratePaillierKey: %v", err)
+	}
+	public := key.Public()
+	c1, err := public.Encrypt(rand.Reader, big.NewInt(1234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := public.Encrypt(rand.Reader, big.NewInt(4321))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := key.Decrypt(public.Add(c1, c2))
+	if err != nil || m.Int64() != 5555 {
+		t.Errorf("decrypted sum = %v, %v; want 5555", m, err)
+	}
+	if _, err := public.Encrypt(rand.Reader, public.N); err == nil {
+		t.Error("encrypting a plaintext out of range succeeded")
+	}
+
+	// Keys survive a round trip through their files
+	dir := t.TempDir()
+	publicPEM, err := MarshalPaillierPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM, err := MarshalPaillierPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPath, privatePath := filepath.Join(dir, "he.pub"), filepath.Join(dir, "he.key")
+	if err := os.WriteFile(publicPath, publicPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(privatePath, privatePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	loadedPublic, err := LoadPaillierPublicKey(publicPath)
+	if err != nil || loadedPublic.N.Cmp(public.N) != 0 {
+		t.Fatalf("LoadPaillierPublicKey = %v, %v", loadedPublic, err)
+	}
+	loaded, err := LoadPaillierPrivateKey(privatePath)
+	if err != nil {
+		t.Fatalf("LoadPaillierPrivateKey: %v", err)
+	}
+	if m, err := loaded.Decrypt(c1); err != nil || m.Int64() != 1234 {
+		t.Errorf("loaded key decrypted %v, %v; want 1234", m, err)
+	}
+	if _, err := LoadPaillierPrivateKey(publicPath); err == nil {
+		t.Error("loading a public key as a private key succeeded")
+	}
+}
+
+func TestEncryptedSum(t *testing.T) {
+	key, err := GeneratePaillierKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := key.Public()
+	// More weights than a ciphertext holds, so that updates span several
+	updates := [][]float32{
+		{0.5, -1.25, 3, 0, -0.001, 7, 8, 9, 10, -11},
+		{1.5, 2.25, -3, 1, 0.002, -7, 8, 9, 10, 11},
+		{-2, 0, 0, 0, 0, 0, 0, 0, 0, 1e-4},
+	}
+	samples := []float64{10, 30, 1}
+	modelSize := len(updates[0])
+
+	sum := NewEncryptedSum(public, modelSize)
+	want := make([]float64, modelSize)
+	for k, update := range updates {
+		data, err := EncryptUpdate(public, update, samples[k])
+		if err != nil {
+			t.Fatalf("EncryptUpdate: %v", err)
+		}
+		if len(data) != EncryptedUpdateSize(public, modelSize) {
+			t.Errorf("encrypted update has %d bytes, want %d", len(data), EncryptedUpdateSize(public, modelSize))
+		}
+		if err := sum.Add(data); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		for i, w := range update {
+			want[i] += float64(w) * samples[k]
+		}
+	}
+	if err := sum.Add(make([]byte, 3)); err == nil || sum.Count() != len(updates) {
+		t.Errorf("adding a malformed update = %v, count %d", err, sum.Count())
+	}
+
+	holder := httptest.NewServer(NewKeyHolderHandler(key, "secret", 2))
+	defer holder.Close()
+	got, err := NewRemoteKeyHolder(holder.URL, "secret").DecryptSum(context.Background(), sum.Bytes(), sum.Count(), modelSize)
+	if err != nil {
+		t.Fatalf("DecryptSum: %v", err)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-3 {
+			t.Errorf("weighted sum of weight %d = %g, want %g", i, got[i], want[i])
+		}
+	}
+
+	if _, err := NewRemoteKeyHolder(holder.URL, "wrong").DecryptSum(context.Background(), sum.Bytes(), sum.Count(), modelSize); err == nil {
+		t.Error("key holder decrypted with a wrong API key")
+	}
+	single, err := EncryptUpdate(public, updates[0], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRemoteKeyHolder(holder.URL, "secret").DecryptSum(context.Background(), single, 1, modelSize); err == nil {
+		t.Error("key holder decrypted a single update")
+	}
+	if _, err := EncryptUpdate(public, []float32{1e6}, 1e4); err == nil {
+		t.Error("encrypting a weight out of range succeeded")
+	}
+}
@@ -0,0 +1,143 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyHolder decrypts the encrypted sums of homomorphic aggregation. It holds
+// the private key the aggregator lacks, so that the aggregator only learns
+// the sum of a round's updates.
+type KeyHolder interface {
+	DecryptSum(ctx context.Context, sum []byte, count, modelSize int) ([]float64, error)
+}
+
+// LocalKeyHolder decrypts sums in process. An aggregator with the private key
+// could decrypt single updates as well, so it is only meant for testing.
+type LocalKeyHolder struct {
+	key *PaillierPrivateKey
+}
+
+// NewLocalKeyHolder returns a key holder that decrypts with key
+func NewLocalKeyHolder(key *PaillierPrivateKey) *LocalKeyHolder {
+	return &LocalKeyHolder{key: key}
+}
+
+// DecryptSum decrypts the sum of count encrypted updates
+func (h *LocalKeyHolder) DecryptSum(ctx context.Context, sum []byte, count, modelSize int) ([]float64, error) {
+	return DecryptSum(h.key, sum, count, modelSize)
+}
+
+// RemoteKeyHolder asks a key holder served by NewKeyHolderHandler, usually
+// `fx he key-holder`, to decrypt sums
+type RemoteKeyHolder struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRemoteKeyHolder returns a client of the key holder at baseURL, which is
+// sent token as X-API-Key when it is set
+func NewRemoteKeyHolder(baseURL, token string) *RemoteKeyHolder {
+	return &RemoteKeyHolder{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Minute}, // decrypting large models takes a while
+	}
+}
+
+// DecryptSum sends the sum of count encrypted updates to the key holder
+func (h *RemoteKeyHolder) DecryptSum(ctx context.Context, sum []byte, count, modelSize int) ([]float64, error) {
+	query := url.Values{"updates": {strconv.Itoa(count)}, "model_size": {strconv.Itoa(modelSize)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url+"/v1/decrypt?"+query.Encode(), bytes.NewReader(sum))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if h.token != "" {
+		req.Header.Set("X-API-Key", h.token)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("key holder unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key holder refused to decrypt: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if len(body) != modelSize*8 {
+		return nil, fmt.Errorf("key holder returned %d bytes for %d weights", len(body), modelSize)
+	}
+	sums := make([]float64, modelSize)
+	for i := range sums {
+		sums[i] = math.Float64frombits(binary.LittleEndian.Uint64(body[i*8:]))
+	}
+	return sums, nil
+}
+
+// NewKeyHolderHandler serves POST /v1/decrypt, which decrypts the sum of the
+// number of updates in the updates query parameter and answers with the
+// weighted sums as little-endian float64s. It refuses sums of fewer than
+// minUpdates updates, which would reveal single updates, and requests
+// without token in X-API-Key when token is set. The key holder cannot tell
+// how many updates a sum really holds, so it trusts the aggregator to follow
+// the protocol.
+func NewKeyHolderHandler(key *PaillierPrivateKey, token string, minUpdates int) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/decrypt", func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(token)) != 1 {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		count, err := strconv.Atoi(r.URL.Query().Get("updates"))
+		if err != nil {
+			http.Error(w, "updates must be a number", http.StatusBadRequest)
+			return
+		}
+		modelSize, err := strconv.Atoi(r.URL.Query().Get("model_size"))
+		if err != nil || modelSize <= 0 {
+			http.Error(w, "model_size must be a positive number", http.StatusBadRequest)
+			return
+		}
+		if count < minUpdates {
+			http.Error(w, fmt.Sprintf("sums of fewer than %d updates are not decrypted", minUpdates), http.StatusForbidden)
+			return
+		}
+		limit := int64(EncryptedUpdateSize(key.Public(), modelSize))
+		sum, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		start := time.Now()
+		sums, err := DecryptSum(key, sum, count, modelSize)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Decrypted the sum of %d updates of %d weights in %v", count, modelSize, time.Since(start).Round(time.Millisecond))
+
+		out := make([]byte, len(sums)*8)
+		for i, s := range sums {
+			binary.LittleEndian.PutUint64(out[i*8:], math.Float64bits(s))
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(out)
+	})
+	return mux
+}
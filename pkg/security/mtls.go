@@ -408,6 +408,12 @@ func (tm *TLSManager) loadCertificates() error {
 	return nil
 }
 
+// ServerCertificate returns the loaded server certificate, for serving
+// protocols other than gRPC
+func (tm *TLSManager) ServerCertificate() tls.Certificate {
+	return tm.serverCert
+}
+
 // NewServerOptions returns gRPC server options with mTLS
 func (tm *TLSManager) NewServerOptions() ([]grpc.ServerOption, error) {
 	creds, err := tm.GetServerCredentials()
@@ -0,0 +1,244 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// PEM block types of Paillier keys
+const (
+	paillierPublicKeyType  = "PAILLIER PUBLIC KEY"
+	paillierPrivateKeyType = "PAILLIER PRIVATE KEY"
+)
+
+// MinPaillierKeyBits is the smallest modulus GeneratePaillierKey accepts,
+// small enough for tests; deployments should use 2048 bits or more
+const MinPaillierKeyBits = 256
+
+var one = big.NewInt(1)
+
+// PaillierPublicKey encrypts integers so that anyone with the key can add
+// them up without decrypting them. Encryption uses the generator N + 1.
+type PaillierPublicKey struct {
+	N  *big.Int
+	n2 *big.Int // N², the ciphertext modulus
+}
+
+// PaillierPrivateKey decrypts the ciphertexts of its public key
+type PaillierPrivateKey struct {
+	PaillierPublicKey
+	P, Q *big.Int
+
+	// Decryption works modulo p² and q² and combines the halves with the
+	// Chinese remainder theorem
+	p2, q2 *big.Int
+	hp, hq *big.Int // L_p((N+1)^(p-1) mod p²)⁻¹ mod p, and likewise for q
+	qInv   *big.Int // q⁻¹ mod p
+}
+
+// NewPaillierPublicKey returns the public key with modulus n
+func NewPaillierPublicKey(n *big.Int) *PaillierPublicKey {
+	return &PaillierPublicKey{N: n, n2: new(big.Int).Mul(n, n)}
+}
+
+// GeneratePaillierKey generates a private key with a modulus of bits bits
+func GeneratePaillierKey(random io.Reader, bits int) (*PaillierPrivateKey, error) {
+	if bits < MinPaillierKeyBits {
+		return nil, fmt.Errorf("paillier keys need at least %d bits, got %d", MinPaillierKeyBits, bits)
+	}
+	for {
+		p, err := rand.Prime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(random, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 || new(big.Int).Mul(p, q).BitLen() != bits {
+			continue
+		}
+		return NewPaillierPrivateKey(p, q)
+	}
+}
+
+// NewPaillierPrivateKey returns the private key with primes p and q
+func NewPaillierPrivateKey(p, q *big.Int) (*PaillierPrivateKey, error) {
+	if p.Cmp(q) == 0 || !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+		return nil, errors.New("paillier keys need two distinct primes")
+	}
+	n := new(big.Int).Mul(p, q)
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	// The generator N + 1 requires gcd(N, φ(N)) = 1
+	if new(big.Int).GCD(nil, nil, n, new(big.Int).Mul(pMinus1, qMinus1)).Cmp(one) != 0 {
+		return nil, errors.New("paillier primes p and q must not divide q-1 and p-1")
+	}
+	key := &PaillierPrivateKey{
+		PaillierPublicKey: *NewPaillierPublicKey(n),
+		P:                 p,
+		Q:                 q,
+		p2:                new(big.Int).Mul(p, p),
+		q2:                new(big.Int).Mul(q, q),
+		qInv:              new(big.Int).ModInverse(q, p),
+	}
+	key.hp = key.h(p, key.p2)
+	key.hq = key.h(q, key.q2)
+	return key, nil
+}
+
+// h returns L_p((N+1)^(p-1) mod p²)⁻¹ mod p for the prime p of the key
+func (k *PaillierPrivateKey) h(p, p2 *big.Int) *big.Int {
+	g := new(big.Int).Add(k.N, one)
+	x := new(big.Int).Exp(g, new(big.Int).Sub(p, one), p2)
+	return new(big.Int).ModInverse(lFunction(x, p), p)
+}
+
+// lFunction is L(x) = (x - 1) / n
+func lFunction(x, n *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Sub(x, one), n)
+}
+
+// Public returns the key's public half
+func (k *PaillierPrivateKey) Public() *PaillierPublicKey {
+	return &k.PaillierPublicKey
+}
+
+// CiphertextSize is the size of an encoded ciphertext in bytes
+func (k *PaillierPublicKey) CiphertextSize() int {
+	return (k.n2.BitLen() + 7) / 8
+}
+
+// Encrypt encrypts m, which must be in [0, N)
+func (k *PaillierPublicKey) Encrypt(random io.Reader, m *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(k.N) >= 0 {
+		return nil, errors.New("paillier plaintext out of range")
+	}
+	r, err := k.randomUnit(random)
+	if err != nil {
+		return nil, err
+	}
+	// (N+1)^m = 1 + mN mod N², so c = (1 + mN) r^N mod N²
+	c := new(big.Int).Mul(m, k.N)
+	c.Add(c, one)
+	c.Mul(c, new(big.Int).Exp(r, k.N, k.n2))
+	return c.Mod(c, k.n2), nil
+}
+
+// randomUnit returns a random number in [1, N) that is coprime to N
+func (k *PaillierPublicKey) randomUnit(random io.Reader) (*big.Int, error) {
+	for {
+		r, err := rand.Int(random, k.N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, k.N).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
+
+// Add returns the ciphertext of the sum of the plaintexts of c1 and c2,
+// modulo N
+func (k *PaillierPublicKey) Add(c1, c2 *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1, c2)
+	return c.Mod(c, k.n2)
+}
+
+// validCiphertext reports whether c can be a ciphertext of the key
+func (k *PaillierPublicKey) validCiphertext(c *big.Int) bool {
+	return c.Sign() > 0 && c.Cmp(k.n2) < 0
+}
+
+// Decrypt returns the plaintext of c
+func (k *PaillierPrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
+	if !k.validCiphertext(c) {
+		return nil, errors.New("paillier ciphertext out of range")
+	}
+	mp := k.decryptModPrime(c, k.P, k.p2, k.hp)
+	mq := k.decryptModPrime(c, k.Q, k.q2, k.hq)
+	// m = mq + q ((mp - mq) q⁻¹ mod p)
+	m := new(big.Int).Sub(mp, mq)
+	m.Mul(m, k.qInv)
+	m.Mod(m, k.P)
+	m.Mul(m, k.Q)
+	return m.Add(m, mq), nil
+}
+
+func (k *PaillierPrivateKey) decryptModPrime(c, p, p2, h *big.Int) *big.Int {
+	x := new(big.Int).Exp(new(big.Int).Mod(c, p2), new(big.Int).Sub(p, one), p2)
+	m := lFunction(x, p)
+	m.Mul(m, h)
+	return m.Mod(m, p)
+}
+
+// paillierPublicKeyASN1 and paillierPrivateKeyASN1 are the DER forms of the
+// keys inside their PEM blocks
+type paillierPublicKeyASN1 struct {
+	N *big.Int
+}
+
+type paillierPrivateKeyASN1 struct {
+	P, Q *big.Int
+}
+
+// MarshalPaillierPublicKey encodes a public key as PEM
+func MarshalPaillierPublicKey(key *PaillierPublicKey) ([]byte, error) {
+	der, err := asn1.Marshal(paillierPublicKeyASN1{N: key.N})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: paillierPublicKeyType, Bytes: der}), nil
+}
+
+// MarshalPaillierPrivateKey encodes a private key as PEM
+func MarshalPaillierPrivateKey(key *PaillierPrivateKey) ([]byte, error) {
+	der, err := asn1.Marshal(paillierPrivateKeyASN1{P: key.P, Q: key.Q})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: paillierPrivateKeyType, Bytes: der}), nil
+}
+
+// LoadPaillierPublicKey reads a public key written by
+// MarshalPaillierPublicKey
+func LoadPaillierPublicKey(path string) (*PaillierPublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != paillierPublicKeyType {
+		return nil, fmt.Errorf("%s is not a Paillier public key", path)
+	}
+	var key paillierPublicKeyASN1
+	if _, err := asn1.Unmarshal(block.Bytes, &key); err != nil || key.N == nil || key.N.BitLen() < MinPaillierKeyBits {
+		return nil, fmt.Errorf("invalid Paillier public key %s", path)
+	}
+	return NewPaillierPublicKey(key.N), nil
+}
+
+// LoadPaillierPrivateKey reads a private key written by
+// MarshalPaillierPrivateKey
+func LoadPaillierPrivateKey(path string) (*PaillierPrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != paillierPrivateKeyType {
+		return nil, fmt.Errorf("%s is not a Paillier private key", path)
+	}
+	var key paillierPrivateKeyASN1
+	if _, err := asn1.Unmarshal(block.Bytes, &key); err != nil || key.P == nil || key.Q == nil {
+		return nil, fmt.Errorf("invalid Paillier private key %s", path)
+	}
+	private, err := NewPaillierPrivateKey(key.P, key.Q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Paillier private key %s: %v", path, err)
+	}
+	return private, nil
+}
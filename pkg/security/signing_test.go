@@ -0,0 +1,82 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeKeys writes an Ed25519 key pair as PEM files, like openssl does
+func writeKeys(t *testing.T, dir string) (keyPath, publicKeyPath string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath, publicKeyPath = filepath.Join(dir, "signing.key"), filepath.Join(dir, "signing.pub")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath, publicKeyPath
+}
+
+func TestModelSigning(t *testing.T) {
+	keyPath, publicKeyPath := writeKeys(t, t.TempDir())
+	signer, err := LoadModelSigner(keyPath)
+	if err != nil {
+		t.Fatalf("LoadModelSigner: %v", err)
+	}
+	verifier, err := LoadModelVerifier(publicKeyPath)
+	if err != nil {
+		t.Fatalf("LoadModelVerifier: %v", err)
+	}
+	if signer.KeyID() != verifier.KeyID() {
+		t.Errorf("signer key %s does not match verifier key %s", signer.KeyID(), verifier.KeyID())
+	}
+
+	signature := signer.Sign("abc123")
+	if err := verifier.Verify("abc123", signature); err != nil {
+		t.Errorf("Verify of a signed model: %v", err)
+	}
+	if err := verifier.Verify("abc124", signature); err == nil {
+		t.Error("Verify accepted the signature of another model")
+	}
+	if err := verifier.Verify("abc123", nil); !errors.Is(err, ErrUnsignedModel) {
+		t.Errorf("Verify of an unsigned model = %v, want ErrUnsignedModel", err)
+	}
+
+	_, otherPublicPath := writeKeys(t, t.TempDir())
+	other, err := LoadModelVerifier(otherPublicPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Verify("abc123", signature); err == nil {
+		t.Error("Verify accepted a signature by another key")
+	}
+
+	var nilSigner *ModelSigner
+	if nilSigner.Sign("abc123") != nil || nilSigner.KeyID() != "" {
+		t.Error("nil signer signed a model")
+	}
+	if _, err := LoadModelSigner(publicKeyPath); err == nil {
+		t.Error("LoadModelSigner accepted a public key")
+	}
+	if _, err := LoadModelVerifier(keyPath); err == nil {
+		t.Error("LoadModelVerifier accepted a private key")
+	}
+}
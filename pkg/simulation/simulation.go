@@ -0,0 +1,273 @@
+// Package simulation runs a federation of virtual collaborators in one
+// process. Updates are aggregated in memory with the plan's algorithm, without
+// gRPC, so that algorithms can be compared quickly and integration tests are
+// deterministic.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// Config controls a simulated federation
+type Config struct {
+	Clients   int     // number of virtual collaborators
+	Rounds    int     // overrides the plan's rounds when positive
+	Fraction  float64 // share of clients sampled each round, in (0, 1]; 0 samples all
+	Seed      uint64  // seeds client sample counts, sampling and the synthetic trainer
+	ModelSize int     // size of a zero initial model when the plan names none
+	Trainer   Trainer // trains the clients
+}
+
+// Client is a virtual collaborator
+type Client struct {
+	ID         string
+	Index      int
+	NumSamples int
+}
+
+// Trainer trains a virtual client, returning its updated model. It is called
+// concurrently for different clients.
+type Trainer interface {
+	Train(client Client, round int, model []float32) ([]float32, error)
+}
+
+// Evaluator is implemented by trainers that can score a model on a client's
+// data
+type Evaluator interface {
+	Loss(client Client, model []float32) float64
+}
+
+// RoundResult summarizes a simulated round
+type RoundResult struct {
+	Round        int           `json:"round"`
+	Participants int           `json:"participants"`
+	ModelDelta   float64       `json:"model_delta"`    // relative L2 change of the global model
+	Loss         float64       `json:"loss,omitempty"` // mean client loss after the round, when the trainer evaluates
+	Duration     time.Duration `json:"duration"`
+}
+
+// Result summarizes a simulated run
+type Result struct {
+	Algorithm string        `json:"algorithm"`
+	Clients   int           `json:"clients"`
+	Rounds    []RoundResult `json:"rounds"`
+	Model     []float32     `json:"-"`
+}
+
+// Run simulates the plan's rounds with config's virtual clients and returns
+// the final model
+func Run(ctx context.Context, plan *federation.FLPlan, config Config) (*Result, error) {
+	if config.Clients < 1 {
+		return nil, fmt.Errorf("simulation requires at least one client")
+	}
+	if config.Fraction < 0 || config.Fraction > 1 {
+		return nil, fmt.Errorf("fraction must be in (0, 1], got %v", config.Fraction)
+	}
+	if config.Trainer == nil {
+		return nil, fmt.Errorf("simulation requires a trainer")
+	}
+	rounds := plan.Rounds
+	if config.Rounds > 0 {
+		rounds = config.Rounds
+	}
+	if rounds < 1 {
+		return nil, fmt.Errorf("simulation requires at least one round")
+	}
+
+	model, err := initialModel(plan, config.ModelSize)
+	if err != nil {
+		return nil, err
+	}
+
+	name := plan.Algorithm.Name
+	if name == "" {
+		name = string(aggregator.FedAvg)
+	}
+	algorithm, err := aggregator.CreateAggregationAlgorithm(aggregator.AlgorithmType(name))
+	if err != nil {
+		return nil, err
+	}
+	if err := algorithm.Initialize(aggregator.AlgorithmConfig{
+		AlgorithmName:   name,
+		ModelSize:       len(model),
+		Hyperparameters: plan.Algorithm.Hyperparameters,
+		Mode:            federation.ModeSync,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize algorithm: %v", err)
+	}
+
+	clients := newClients(config.Clients, config.Seed)
+	result := &Result{Algorithm: algorithm.GetName(), Clients: len(clients)}
+	log.Printf("Simulating %d rounds of %s with %d clients", rounds, result.Algorithm, len(clients))
+
+	for round := 1; round <= rounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		participants := sample(clients, config.Fraction, config.Seed, round)
+
+		updates, err := train(config.Trainer, participants, round, model)
+		if err != nil {
+			return nil, err
+		}
+		newModel, err := algorithm.Aggregate(updates, model)
+		if err != nil {
+			return nil, fmt.Errorf("aggregation failed in round %d: %v", round, err)
+		}
+
+		rr := RoundResult{
+			Round:        round,
+			Participants: len(participants),
+			ModelDelta:   relativeDelta(model, newModel),
+			Duration:     time.Since(start),
+		}
+		model = newModel
+		if evaluator, ok := config.Trainer.(Evaluator); ok {
+			rr.Loss = meanLoss(evaluator, clients, model)
+		}
+		result.Rounds = append(result.Rounds, rr)
+		log.Printf("Round %d/%d: %d clients, model delta %.6f, loss %.6f",
+			round, rounds, rr.Participants, rr.ModelDelta, rr.Loss)
+	}
+
+	result.Model = model
+	return result, nil
+}
+
+// initialModel reads the plan's initial model, or returns a zero model of size
+func initialModel(plan *federation.FLPlan, size int) ([]float32, error) {
+	if plan.InitialModel == "" {
+		if size < 1 {
+			return nil, fmt.Errorf("plan names no initial model; set a model size")
+		}
+		return make([]float32, size), nil
+	}
+	data, err := storage.ReadFile(plan.InitialModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read initial model: %v", err)
+	}
+	model, err := transport.DecodeWeights(data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial model %s: %v", plan.InitialModel, err)
+	}
+	return model, nil
+}
+
+// newClients creates the virtual clients with between 100 and 1000 samples each
+func newClients(n int, seed uint64) []Client {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	clients := make([]Client, n)
+	for i := range clients {
+		clients[i] = Client{ID: fmt.Sprintf("client-%d", i+1), Index: i, NumSamples: 100 + rng.IntN(900)}
+	}
+	return clients
+}
+
+// sample picks the clients of a round, keeping their order
+func sample(clients []Client, fraction float64, seed uint64, round int) []Client {
+	if fraction == 0 || fraction == 1 {
+		return clients
+	}
+	n := max(1, int(math.Round(fraction*float64(len(clients)))))
+	rng := rand.New(rand.NewPCG(seed, uint64(round))) // #nosec G115 - rounds are positive
+	picked := rng.Perm(len(clients))[:n]
+	chosen := make([]bool, len(clients))
+	for _, i := range picked {
+		chosen[i] = true
+	}
+	participants := make([]Client, 0, n)
+	for i, client := range clients {
+		if chosen[i] {
+			participants = append(participants, client)
+		}
+	}
+	return participants
+}
+
+// train runs the participants in parallel and returns their updates in
+// participant order, so that aggregation is deterministic
+func train(trainer Trainer, participants []Client, round int, model []float32) ([]aggregator.ClientUpdate, error) {
+	updates := make([]aggregator.ClientUpdate, len(participants))
+	errs := make([]error, len(participants))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(participants)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				client := participants[i]
+				weights, err := trainer.Train(client, round, append([]float32(nil), model...))
+				if err == nil && len(weights) != len(model) {
+					err = fmt.Errorf("update has %d parameters, expected %d", len(weights), len(model))
+				}
+				if err != nil {
+					errs[i] = fmt.Errorf("client %s failed in round %d: %v", client.ID, round, err)
+					continue
+				}
+				updates[i] = aggregator.ClientUpdate{
+					CollaboratorID: client.ID,
+					Weights:        weights,
+					Timestamp:      time.Now(),
+					Round:          round,
+					NumSamples:     client.NumSamples,
+				}
+			}
+		}()
+	}
+	for i := range participants {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return updates, nil
+}
+
+// meanLoss is the sample-weighted loss of model over all clients
+func meanLoss(evaluator Evaluator, clients []Client, model []float32) float64 {
+	var loss, samples float64
+	for _, client := range clients {
+		loss += float64(client.NumSamples) * evaluator.Loss(client, model)
+		samples += float64(client.NumSamples)
+	}
+	return loss / samples
+}
+
+// relativeDelta is the L2 distance between two models relative to the norm of
+// the first
+func relativeDelta(old, updated []float32) float64 {
+	var diff, norm float64
+	for i := range old {
+		d := float64(updated[i] - old[i])
+		diff += d * d
+		norm += float64(old[i]) * float64(old[i])
+	}
+	if norm == 0 {
+		return math.Sqrt(diff)
+	}
+	return math.Sqrt(diff / norm)
+}
+
+// SaveModel writes a simulated model in the encoding of the aggregators
+func SaveModel(path string, model []float32) error {
+	return storage.WriteFile(path, transport.EncodeWeights(model))
+}
@@ -0,0 +1,75 @@
+package simulation
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestRunIsDeterministic(t *testing.T) {
+	plan := &federation.FLPlan{Rounds: 5}
+	run := func() *Result {
+		result, err := Run(context.Background(), plan, Config{
+			Clients:   20,
+			Fraction:  0.5,
+			Seed:      7,
+			ModelSize: 16,
+			Trainer:   NewSyntheticTrainer(7, 1),
+		})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return result
+	}
+
+	first, second := run(), run()
+	if !slices.Equal(first.Model, second.Model) {
+		t.Errorf("runs with the same seed produced different models")
+	}
+	if len(first.Rounds) != 5 {
+		t.Fatalf("rounds = %d, want 5", len(first.Rounds))
+	}
+	for _, r := range first.Rounds {
+		if r.Participants != 10 {
+			t.Errorf("round %d participants = %d, want 10", r.Round, r.Participants)
+		}
+	}
+}
+
+func TestRunReducesLoss(t *testing.T) {
+	plan := &federation.FLPlan{Rounds: 10, Algorithm: federation.AlgorithmConfig{Name: "fedavg"}}
+	result, err := Run(context.Background(), plan, Config{
+		Clients:   10,
+		ModelSize: 8,
+		Trainer:   NewSyntheticTrainer(1, 0.5),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	first, last := result.Rounds[0].Loss, result.Rounds[len(result.Rounds)-1].Loss
+	if last >= first {
+		t.Errorf("loss went from %v to %v, want a decrease", first, last)
+	}
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	trainer := NewSyntheticTrainer(0, 0)
+	tests := []struct {
+		name   string
+		plan   *federation.FLPlan
+		config Config
+	}{
+		{"no clients", &federation.FLPlan{Rounds: 1}, Config{ModelSize: 4, Trainer: trainer}},
+		{"fraction above one", &federation.FLPlan{Rounds: 1}, Config{Clients: 2, Fraction: 1.5, ModelSize: 4, Trainer: trainer}},
+		{"no trainer", &federation.FLPlan{Rounds: 1}, Config{Clients: 2, ModelSize: 4}},
+		{"no rounds", &federation.FLPlan{}, Config{Clients: 2, ModelSize: 4, Trainer: trainer}},
+		{"no model", &federation.FLPlan{Rounds: 1}, Config{Clients: 2, Trainer: trainer}},
+	}
+	for _, tt := range tests {
+		if _, err := Run(context.Background(), tt.plan, tt.config); err == nil {
+			t.Errorf("%s: Run() succeeded, want an error", tt.name)
+		}
+	}
+}
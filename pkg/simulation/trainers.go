@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ishaileshpant/fl-go/pkg/collaborator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// SyntheticTrainer trains each client on a quadratic loss around its own
+// optimum. Optima are spread around a shared one by Heterogeneity, so the
+// effect of non-IID data on an algorithm can be studied without a dataset.
+type SyntheticTrainer struct {
+	Seed          uint64
+	Heterogeneity float64 // standard deviation of client optima around the shared one
+	LearningRate  float64 // local step size (default: 0.1)
+	Epochs        int     // local steps per round (default: 5)
+
+	mu     sync.Mutex
+	optima map[int][]float32 // client index -> optimum
+	shared []float32
+}
+
+// NewSyntheticTrainer returns a synthetic trainer with the default learning
+// rate and epochs
+func NewSyntheticTrainer(seed uint64, heterogeneity float64) *SyntheticTrainer {
+	return &SyntheticTrainer{Seed: seed, Heterogeneity: heterogeneity, LearningRate: 0.1, Epochs: 5}
+}
+
+// Train takes Epochs gradient steps on 0.5 * ||model - optimum||^2
+func (t *SyntheticTrainer) Train(client Client, round int, model []float32) ([]float32, error) {
+	optimum := t.optimum(client, len(model))
+	lr := float32(t.LearningRate)
+	if lr <= 0 {
+		lr = 0.1
+	}
+	epochs := t.Epochs
+	if epochs <= 0 {
+		epochs = 5
+	}
+	for e := 0; e < epochs; e++ {
+		for i := range model {
+			model[i] -= lr * (model[i] - optimum[i])
+		}
+	}
+	return model, nil
+}
+
+// Loss is the mean squared distance of model from the client's optimum
+func (t *SyntheticTrainer) Loss(client Client, model []float32) float64 {
+	optimum := t.optimum(client, len(model))
+	var loss float64
+	for i := range model {
+		d := float64(model[i] - optimum[i])
+		loss += 0.5 * d * d
+	}
+	return loss / float64(len(model))
+}
+
+// optimum returns the client's optimum, drawing it on first use
+func (t *SyntheticTrainer) optimum(client Client, size int) []float32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.shared == nil {
+		rng := rand.New(rand.NewPCG(t.Seed, 1<<32))
+		t.shared = make([]float32, size)
+		for i := range t.shared {
+			t.shared[i] = float32(rng.NormFloat64())
+		}
+		t.optima = make(map[int][]float32)
+	}
+	if optimum, ok := t.optima[client.Index]; ok {
+		return optimum
+	}
+
+	rng := rand.New(rand.NewPCG(t.Seed, uint64(client.Index)+1<<33)) // #nosec G115 - indices are not negative
+	optimum := make([]float32, size)
+	for i := range optimum {
+		optimum[i] = t.shared[i] + float32(t.Heterogeneity*rng.NormFloat64())
+	}
+	t.optima[client.Index] = optimum
+	return optimum
+}
+
+// TaskTrainer trains clients with the plan's train task, each in its own
+// directory under Dir. The clients share the task's data, so it suits tasks
+// that pick their data from their args, such as Go functions.
+type TaskTrainer struct {
+	Task federation.TaskConfig
+	Dir  string
+}
+
+// Train runs the task on the model and reads back the update
+func (t *TaskTrainer) Train(client Client, round int, model []float32) ([]float32, error) {
+	runner, err := collaborator.NewTaskRunner(t.Task)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(t.Dir, client.ID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	files := collaborator.TaskFiles{
+		ModelIn:  filepath.Join(dir, "model_init.pt"),
+		ModelOut: filepath.Join(dir, "update.pt"),
+	}
+	if err := os.WriteFile(files.ModelIn, transport.EncodeWeights(model), 0600); err != nil {
+		return nil, err
+	}
+	if err := runner.Run(files); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(files.ModelOut)
+	if err != nil {
+		return nil, err
+	}
+	update, err := transport.DecodeWeights(data, len(model))
+	if err != nil {
+		return nil, fmt.Errorf("invalid update: %v", err)
+	}
+	return update, nil
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion is the Blob service REST API version requests are made with
+const azureAPIVersion = "2021-08-06"
+
+// AzureConfig configures an Azure Blob Storage client. Requests are signed
+// with the account key, or authorized with a SAS token when there is no key.
+type AzureConfig struct {
+	Container  string
+	Account    string
+	AccountKey string // base64 account key
+	SASToken   string
+	Endpoint   string // default: https://<account>.blob.core.windows.net
+	PartSize   int    // block size (default: DefaultPartSize)
+}
+
+// AzureConfigFromEnv reads AZURE_STORAGE_ACCOUNT, AZURE_STORAGE_KEY,
+// AZURE_STORAGE_SAS_TOKEN and AZURE_STORAGE_BLOB_ENDPOINT
+func AzureConfigFromEnv(container string) AzureConfig {
+	return AzureConfig{
+		Container:  container,
+		Account:    os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		AccountKey: os.Getenv("AZURE_STORAGE_KEY"),
+		SASToken:   os.Getenv("AZURE_STORAGE_SAS_TOKEN"),
+		Endpoint:   os.Getenv("AZURE_STORAGE_BLOB_ENDPOINT"),
+	}
+}
+
+// AzureClient stores blobs in an Azure Blob Storage container. Blobs larger
+// than the block size are uploaded as blocks and committed with a block list.
+type AzureClient struct {
+	baseURL   *url.URL // container URL; blob names are appended to its path
+	account   string
+	key       []byte
+	sas       url.Values
+	blockSize int
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewAzureClient creates an Azure Blob Storage client for one container
+func NewAzureClient(config AzureConfig) (*AzureClient, error) {
+	if config.Container == "" {
+		return nil, fmt.Errorf("azure storage requires a container")
+	}
+	if config.Account == "" {
+		return nil, fmt.Errorf("azure storage requires an account (AZURE_STORAGE_ACCOUNT)")
+	}
+
+	c := &AzureClient{
+		account:   config.Account,
+		blockSize: config.PartSize,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+		now:       time.Now,
+	}
+	if c.blockSize <= 0 {
+		c.blockSize = DefaultPartSize
+	}
+
+	switch {
+	case config.AccountKey != "":
+		key, err := base64.StdEncoding.DecodeString(config.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid azure account key: %v", err)
+		}
+		c.key = key
+	case config.SASToken != "":
+		sas, err := url.ParseQuery(strings.TrimPrefix(config.SASToken, "?"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid azure SAS token: %v", err)
+		}
+		c.sas = sas
+	default:
+		return nil, fmt.Errorf("azure storage requires credentials (AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN)")
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", config.Account)
+	}
+	baseURL, err := url.Parse(endpoint + "/" + config.Container + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure endpoint: %w", err)
+	}
+	c.baseURL = baseURL
+	return c, nil
+}
+
+// Get reads a blob, returning ErrNotFound if it does not exist
+func (c *AzureClient) Get(key string) ([]byte, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, azureError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Put writes a block blob, uploading large blobs block by block
+func (c *AzureClient) Put(key string, data []byte) error {
+	if len(data) > c.blockSize {
+		return c.putBlocks(key, data)
+	}
+
+	resp, err := c.do(http.MethodPut, key, nil, map[string]string{"x-ms-blob-type": "BlockBlob"}, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return azureError(resp)
+	}
+	return nil
+}
+
+type azureBlockList struct {
+	XMLName xml.Name `xml:"BlockList"`
+	Latest  []string `xml:"Latest"`
+}
+
+// putBlocks stages data as blocks and commits them. Uncommitted blocks are
+// discarded by the service, so a failed upload leaves the blob unchanged.
+func (c *AzureClient) putBlocks(key string, data []byte) error {
+	parts := splitParts(data, c.blockSize)
+	blockList := azureBlockList{Latest: make([]string, 0, len(parts))}
+	for i, part := range parts {
+		// Block IDs must have the same length within a blob
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%06d", i)))
+		query := url.Values{"comp": {"block"}, "blockid": {id}}
+		resp, err := c.do(http.MethodPut, key, query, nil, part)
+		if err != nil {
+			return fmt.Errorf("failed to upload block %d/%d: %w", i+1, len(parts), err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			return fmt.Errorf("failed to upload block %d/%d: status %d", i+1, len(parts), resp.StatusCode)
+		}
+		blockList.Latest = append(blockList.Latest, id)
+	}
+
+	body, err := xml.Marshal(blockList)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(http.MethodPut, key, url.Values{"comp": {"blocklist"}}, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return azureError(resp)
+	}
+	return nil
+}
+
+// do sends an authorized request for the blob at key
+func (c *AzureClient) do(method, key string, query url.Values, headers map[string]string, body []byte) (*http.Response, error) {
+	blobURL := *c.baseURL
+	blobURL.Path += key
+
+	params := url.Values{}
+	for name, values := range query {
+		params[name] = values
+	}
+	for name, values := range c.sas {
+		params[name] = values
+	}
+	blobURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(method, blobURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", c.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if c.key != nil {
+		signAzureRequest(req, c.account, c.key)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("azure %s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+func azureError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("azure request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// signAzureRequest adds a Shared Key Authorization header to req
+func signAzureRequest(req *http.Request, account string, key []byte) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+	var canonicalHeaders strings.Builder
+	for _, name := range msHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	canonicalResource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		canonicalResource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalHeaders.String() + canonicalResource,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+signature)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAzureBlockUpload(t *testing.T) {
+	blocks := make(map[string][]byte)
+	blobs := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devaccount:") || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPut && query.Get("comp") == "block":
+			blocks[query.Get("blockid")] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+			var list azureBlockList
+			xml.Unmarshal(body, &list)
+			var blob []byte
+			for _, id := range list.Latest {
+				blob = append(blob, blocks[id]...)
+			}
+			blobs[r.URL.Path] = blob
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.Header.Get("x-ms-blob-type") == "BlockBlob":
+			blobs[r.URL.Path] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet:
+			blob, ok := blobs[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAzureClient(AzureConfig{Container: "models", Account: "devaccount",
+		AccountKey: base64.StdEncoding.EncodeToString([]byte("secret")), Endpoint: server.URL + "/devaccount", PartSize: 4})
+	if err != nil {
+		t.Fatalf("NewAzureClient() error = %v", err)
+	}
+
+	for _, data := range [][]byte{[]byte("abc"), []byte("0123456789")} {
+		if err := client.Put("runs/final.pt", data); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		got, err := client.Get("runs/final.pt")
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Get() = %q, %v, want %q", got, err, data)
+		}
+	}
+	if len(blocks) != 3 {
+		t.Errorf("staged %d blocks, want 3", len(blocks))
+	}
+	if _, ok := blobs["/devaccount/models/runs/final.pt"]; !ok {
+		t.Errorf("blob paths = %v, want /devaccount/models/runs/final.pt", blobs)
+	}
+}
@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// gcsChunkAlign is the granularity of resumable upload chunks required by GCS
+const gcsChunkAlign = 256 << 10
+
+// GCSConfig configures a Google Cloud Storage client. An access token or a
+// service account key file is required, except with a custom endpoint such as
+// a storage emulator, where requests without one are sent unauthenticated.
+// Metadata server and workload identity credentials are not used.
+type GCSConfig struct {
+	Bucket          string
+	Endpoint        string // default: https://storage.googleapis.com
+	AccessToken     string // OAuth2 access token
+	CredentialsFile string // service account key file, used when there is no access token
+	PartSize        int    // resumable upload chunk size (default: DefaultPartSize)
+}
+
+// GCSConfigFromEnv reads GOOGLE_OAUTH_ACCESS_TOKEN,
+// GOOGLE_APPLICATION_CREDENTIALS and STORAGE_EMULATOR_HOST
+func GCSConfigFromEnv(bucket string) GCSConfig {
+	config := GCSConfig{
+		Bucket:          bucket,
+		AccessToken:     os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		CredentialsFile: os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
+	}
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		config.Endpoint = host
+	}
+	return config
+}
+
+// GCSClient stores objects in a Google Cloud Storage bucket through the JSON
+// API. Objects larger than the part size are sent as resumable uploads.
+type GCSClient struct {
+	bucket    string
+	endpoint  string
+	chunkSize int
+	tokens    *gcsTokenSource
+	client    *http.Client
+}
+
+// NewGCSClient creates a GCS client for one bucket
+func NewGCSClient(config GCSConfig) (*GCSClient, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("gcs storage requires a bucket")
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if endpoint == "" {
+		if config.AccessToken == "" && config.CredentialsFile == "" {
+			return nil, fmt.Errorf("gcs storage requires credentials (GOOGLE_OAUTH_ACCESS_TOKEN or GOOGLE_APPLICATION_CREDENTIALS)")
+		}
+		endpoint = "https://storage.googleapis.com"
+	}
+
+	chunkSize := config.PartSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultPartSize
+	}
+	if chunkSize%gcsChunkAlign != 0 {
+		chunkSize += gcsChunkAlign - chunkSize%gcsChunkAlign
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	tokens := &gcsTokenSource{token: config.AccessToken, client: client}
+	if config.AccessToken == "" && config.CredentialsFile != "" {
+		if err := tokens.loadServiceAccount(config.CredentialsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &GCSClient{
+		bucket:    config.Bucket,
+		endpoint:  endpoint,
+		chunkSize: chunkSize,
+		tokens:    tokens,
+		client:    client,
+	}, nil
+}
+
+// Get reads an object, returning ErrNotFound if it does not exist
+func (c *GCSClient) Get(key string) ([]byte, error) {
+	objectURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", c.endpoint, url.PathEscape(c.bucket), url.PathEscape(key))
+	resp, err := c.do(http.MethodGet, objectURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, gcsError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Put writes an object, using a resumable upload for large objects
+func (c *GCSClient) Put(key string, data []byte) error {
+	uploadType := "media"
+	if len(data) > c.chunkSize {
+		uploadType = "resumable"
+	}
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=%s&name=%s",
+		c.endpoint, url.PathEscape(c.bucket), uploadType, url.QueryEscape(key))
+
+	if uploadType == "resumable" {
+		return c.putResumable(uploadURL, data)
+	}
+
+	resp, err := c.do(http.MethodPost, uploadURL, map[string]string{"Content-Type": "application/octet-stream"}, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return gcsError(resp)
+	}
+	return nil
+}
+
+// putResumable starts a resumable upload session and sends data in chunks
+func (c *GCSClient) putResumable(uploadURL string, data []byte) error {
+	resp, err := c.do(http.MethodPost, uploadURL, map[string]string{
+		"X-Upload-Content-Type":   "application/octet-stream",
+		"X-Upload-Content-Length": fmt.Sprint(len(data)),
+	}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return gcsError(resp)
+	}
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return fmt.Errorf("gcs did not return a resumable upload session")
+	}
+
+	offset := 0
+	for i, chunk := range splitParts(data, c.chunkSize) {
+		contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, offset+len(chunk)-1, len(data))
+		resp, err := c.do(http.MethodPut, session, map[string]string{"Content-Range": contentRange}, chunk)
+		if err != nil {
+			return fmt.Errorf("failed to upload chunk %d: %w", i+1, err)
+		}
+		resp.Body.Close()
+
+		offset += len(chunk)
+		last := offset == len(data)
+		// 308 acknowledges an intermediate chunk, 200 or 201 the final one
+		switch {
+		case !last && resp.StatusCode == http.StatusPermanentRedirect:
+		case last && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated):
+		default:
+			return fmt.Errorf("gcs upload of chunk %d failed with status %d", i+1, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+func (c *GCSClient) do(method, rawURL string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	token, err := c.tokens.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs %s failed: %w", method, err)
+	}
+	return resp, nil
+}
+
+func gcsError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("gcs request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// gcsTokenSource returns a fixed access token, or exchanges a service account
+// key for access tokens and caches them until shortly before they expire
+type gcsTokenSource struct {
+	token   string
+	expires time.Time // zero for a fixed token
+
+	email    string
+	tokenURI string
+	key      interface{}
+
+	client *http.Client
+	mu     sync.Mutex
+}
+
+func (s *gcsTokenSource) loadServiceAccount(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read GCS credentials: %v", err)
+	}
+	var account struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return fmt.Errorf("failed to parse GCS credentials: %v", err)
+	}
+	if account.Type != "service_account" {
+		return fmt.Errorf("unsupported GCS credentials type %q (expected service_account)", account.Type)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("invalid GCS service account key: %v", err)
+	}
+	s.email = account.ClientEmail
+	s.key = key
+	s.tokenURI = account.TokenURI
+	if s.tokenURI == "" {
+		s.tokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return nil
+}
+
+// Token returns the current access token, or "" when no credentials are set
+func (s *gcsTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key == nil || time.Until(s.expires) > time.Minute {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.email,
+		"scope": "https://www.googleapis.com/auth/devstorage.read_write",
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GCS token request: %v", err)
+	}
+
+	resp, err := s.client.PostForm(s.tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get GCS access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", gcsError(resp)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse GCS access token: %v", err)
+	}
+	s.token = result.AccessToken
+	s.expires = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.token, nil
+}
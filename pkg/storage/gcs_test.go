@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGCSResumableUpload(t *testing.T) {
+	var object, chunks []byte
+	var ranges []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/upload/storage/v1/b/models/o" && r.URL.Query().Get("uploadType") == "resumable":
+			if r.URL.Query().Get("name") != "fl/final.pt" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Location", server.URL+"/session")
+		case r.URL.Path == "/session":
+			ranges = append(ranges, r.Header.Get("Content-Range"))
+			chunks = append(chunks, body...)
+			if strings.HasSuffix(r.Header.Get("Content-Range"), fmt.Sprintf("-%d/%d", len(chunks)-1, len(chunks))) {
+				object = chunks
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.WriteHeader(http.StatusPermanentRedirect)
+		case r.URL.Path == "/storage/v1/b/models/o/fl/final.pt":
+			// the escaped object name is decoded into the path
+			w.Write(object)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGCSClient(GCSConfig{Bucket: "models", Endpoint: server.URL, AccessToken: "token", PartSize: 1})
+	if err != nil {
+		t.Fatalf("NewGCSClient() error = %v", err)
+	}
+
+	data := bytes.Repeat([]byte{7}, gcsChunkAlign+100)
+	if err := client.Put("fl/final.pt", data); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	wantRanges := []string{
+		fmt.Sprintf("bytes 0-%d/%d", gcsChunkAlign-1, len(data)),
+		fmt.Sprintf("bytes %d-%d/%d", gcsChunkAlign, len(data)-1, len(data)),
+	}
+	if fmt.Sprint(ranges) != fmt.Sprint(wantRanges) {
+		t.Errorf("Content-Range headers = %v, want %v", ranges, wantRanges)
+	}
+
+	got, err := client.Get("fl/final.pt")
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get() returned %d bytes, %v; want %d bytes", len(got), err, len(data))
+	}
+	if _, err := client.Get("missing.pt"); err != ErrNotFound {
+		t.Errorf("Get() of a missing object error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGCSCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  GCSConfig
+		wantErr bool
+	}{
+		{name: "access token", config: GCSConfig{Bucket: "models", AccessToken: "token"}},
+		{name: "emulator", config: GCSConfig{Bucket: "models", Endpoint: "http://localhost:4443"}},
+		{name: "no credentials", config: GCSConfig{Bucket: "models"}, wantErr: true},
+		{name: "missing key file", config: GCSConfig{Bucket: "models", CredentialsFile: "missing.json"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGCSClient(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("NewGCSClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
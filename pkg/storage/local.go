@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumSuffix is appended to a local model's path to name its checksum
+// sidecar, a SHA-256 line in the format of sha256sum
+const ChecksumSuffix = ".sha256"
+
+// ErrCorrupt is returned when a local model does not match its checksum
+var ErrCorrupt = errors.New("model file is corrupt")
+
+// ChecksumPath returns the path of the checksum sidecar of a local model
+func ChecksumPath(path string) string {
+	return path + ChecksumSuffix
+}
+
+// VerifyChecksum checks data read from the local path against the path's
+// checksum sidecar. Files without a sidecar, such as models written by
+// training scripts, are not checked.
+func VerifyChecksum(path string, data []byte) error {
+	sidecar, err := os.ReadFile(ChecksumPath(path)) // #nosec G304 - sidecar of a model the caller reads
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	want, _, _ := strings.Cut(strings.TrimSpace(string(sidecar)), " ")
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("%w: %s has checksum %.12s, its sidecar expects %.12s (remove %s if the file was replaced on purpose)",
+			ErrCorrupt, path, got, want, ChecksumPath(path))
+	}
+	return nil
+}
+
+// readLocal reads a local model and verifies it against its sidecar
+func readLocal(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - model paths come from the plan
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeLocal replaces a local model in one step, so that readers and a crash
+// never see a partial file, and writes its checksum sidecar. The old sidecar
+// is removed first: after a crash the model is either the old one without a
+// sidecar or the new one, never one that fails its check.
+func writeLocal(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(ChecksumPath(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := replaceFile(path, data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(path))
+	return replaceFile(ChecksumPath(path), []byte(line))
+}
+
+// replaceFile writes data to a temporary file next to path, syncs it and
+// renames it over path
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3 client
+type S3Config struct {
+	Bucket          string
+	Region          string // default: us-east-1
+	Endpoint        string // S3-compatible endpoint such as MinIO, addressed path-style
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+	PartSize        int    // default: DefaultPartSize
+}
+
+// S3ConfigFromEnv reads the standard AWS environment variables: AWS_REGION
+// (or AWS_DEFAULT_REGION), AWS_ENDPOINT_URL, AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+func S3ConfigFromEnv(bucket string) S3Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return S3Config{
+		Bucket:          bucket,
+		Region:          region,
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// S3Client stores objects in an S3 bucket. Requests are signed with AWS
+// Signature V4 and objects larger than the part size use multipart uploads.
+type S3Client struct {
+	baseURL         *url.URL // bucket URL; object keys are appended to its path
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	partSize        int
+	client          *http.Client
+	now             func() time.Time
+}
+
+// NewS3Client creates an S3 client for one bucket
+func NewS3Client(config S3Config) (*S3Client, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires a bucket")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 storage requires credentials (AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Custom endpoints are addressed path-style, AWS virtual-hosted style
+	rawURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", config.Bucket, region)
+	if config.Endpoint != "" {
+		rawURL = strings.TrimSuffix(config.Endpoint, "/") + "/" + config.Bucket + "/"
+	}
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	partSize := config.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	return &S3Client{
+		baseURL:         baseURL,
+		region:          region,
+		accessKeyID:     config.AccessKeyID,
+		secretAccessKey: config.SecretAccessKey,
+		sessionToken:    config.SessionToken,
+		partSize:        partSize,
+		client:          &http.Client{Timeout: 5 * time.Minute},
+		now:             time.Now,
+	}, nil
+}
+
+// Get reads an object, returning ErrNotFound if it does not exist
+func (c *S3Client) Get(key string) ([]byte, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Put writes an object, using a multipart upload for large objects
+func (c *S3Client) Put(key string, data []byte) error {
+	if len(data) > c.partSize {
+		return c.putMultipart(key, data)
+	}
+
+	resp, err := c.do(http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// putMultipart uploads data in parts, aborting the upload if a part fails
+func (c *S3Client) putMultipart(key string, data []byte) error {
+	resp, err := c.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = decodeS3XML(resp, &initiated)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	parts := splitParts(data, c.partSize)
+	complete := completeMultipartUpload{Parts: make([]completedPart, 0, len(parts))}
+	for i, part := range parts {
+		number := i + 1
+		etag, err := c.uploadPart(key, initiated.UploadID, number, part)
+		if err != nil {
+			c.abortMultipart(key, initiated.UploadID)
+			return fmt.Errorf("failed to upload part %d/%d: %w", number, len(parts), err)
+		}
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: number, ETag: etag})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	resp, err = c.do(http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, body)
+	if err != nil {
+		c.abortMultipart(key, initiated.UploadID)
+		return err
+	}
+	// S3 can report a failed completion in the body of a 200 response
+	var result struct {
+		XMLName xml.Name
+		Message string `xml:"Message"`
+	}
+	if err := decodeS3XML(resp, &result); err != nil {
+		c.abortMultipart(key, initiated.UploadID)
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if result.XMLName.Local == "Error" {
+		c.abortMultipart(key, initiated.UploadID)
+		return fmt.Errorf("failed to complete multipart upload: %s", result.Message)
+	}
+	return nil
+}
+
+func (c *S3Client) uploadPart(key, uploadID string, number int, part []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := c.do(http.MethodPut, key, query, part)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", s3Error(resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *S3Client) abortMultipart(key, uploadID string) {
+	resp, err := c.do(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		log.Printf("Failed to abort multipart upload of %s: %v", key, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// do sends a signed request for the object at key
+func (c *S3Client) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	objectURL := *c.baseURL
+	objectURL.Path += key
+	objectURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+	SignS3Request(req, body, c.accessKeyID, c.secretAccessKey, c.region, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// decodeS3XML decodes the XML body of a successful response and closes it
+func decodeS3XML(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// SignS3Request adds AWS Signature V4 headers to req, signing the host and
+// every header already set on the request
+func SignS3Request(req *http.Request, body []byte, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,133 @@
+// Package storage reads and writes model files on the local filesystem or in
+// object storage addressed by s3://, gs:// and az:// URIs.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultPartSize is the size of the parts of a multipart upload. Objects up
+// to this size are uploaded in a single request.
+const DefaultPartSize = 8 << 20
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectStore reads and writes the objects of one bucket or container
+type ObjectStore interface {
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+}
+
+// Location is a parsed object storage URI
+type Location struct {
+	Scheme string // s3, gs or az
+	Bucket string // bucket, or container for az
+	Key    string
+}
+
+// IsRemote reports whether path is an object storage URI rather than a local path
+func IsRemote(path string) bool {
+	scheme, _, ok := strings.Cut(path, "://")
+	if !ok {
+		return false
+	}
+	switch scheme {
+	case "s3", "gs", "az":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseURI parses an s3://bucket/key, gs://bucket/key or az://container/blob URI
+func ParseURI(uri string) (Location, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return Location{}, fmt.Errorf("invalid storage URI %q: %v", uri, err)
+	}
+	switch u.Scheme {
+	case "s3", "gs", "az":
+	default:
+		return Location{}, fmt.Errorf("unsupported storage URI %q (expected s3://, gs:// or az://)", uri)
+	}
+
+	loc := Location{Scheme: u.Scheme, Bucket: u.Host, Key: strings.TrimPrefix(u.Path, "/")}
+	if loc.Bucket == "" || loc.Key == "" || strings.HasSuffix(loc.Key, "/") {
+		return Location{}, fmt.Errorf("storage URI %q must name a bucket and an object", uri)
+	}
+	return loc, nil
+}
+
+// Open returns the store for the bucket of loc, with credentials taken from
+// the environment
+func Open(loc Location) (ObjectStore, error) {
+	switch loc.Scheme {
+	case "s3":
+		return NewS3Client(S3ConfigFromEnv(loc.Bucket))
+	case "gs":
+		return NewGCSClient(GCSConfigFromEnv(loc.Bucket))
+	case "az":
+		return NewAzureClient(AzureConfigFromEnv(loc.Bucket))
+	default:
+		return nil, fmt.Errorf("unsupported storage scheme %q", loc.Scheme)
+	}
+}
+
+// ReadFile reads a model from a local path or an object storage URI. Local
+// models are verified against their checksum sidecar, if they have one.
+func ReadFile(path string) ([]byte, error) {
+	if !IsRemote(path) {
+		return readLocal(path)
+	}
+
+	loc, err := ParseURI(path)
+	if err != nil {
+		return nil, err
+	}
+	store, err := Open(loc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(loc.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// WriteFile writes a model to a local path or an object storage URI. Local
+// models are replaced atomically and get a checksum sidecar, and their parent
+// directories are created as needed. Object stores commit uploads atomically
+// and check their integrity themselves.
+func WriteFile(path string, data []byte) error {
+	if !IsRemote(path) {
+		return writeLocal(path, data)
+	}
+
+	loc, err := ParseURI(path)
+	if err != nil {
+		return err
+	}
+	store, err := Open(loc)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(loc.Key, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// splitParts cuts data into parts of at most size bytes
+func splitParts(data []byte, size int) [][]byte {
+	var parts [][]byte
+	for len(data) > size {
+		parts = append(parts, data[:size])
+		data = data[size:]
+	}
+	return append(parts, data)
+}
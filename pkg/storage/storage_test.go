@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    Location
+		wantErr bool
+	}{
+		{uri: "s3://models/fl/final.pt", want: Location{Scheme: "s3", Bucket: "models", Key: "fl/final.pt"}},
+		{uri: "gs://models/init.pt", want: Location{Scheme: "gs", Bucket: "models", Key: "init.pt"}},
+		{uri: "az://container/runs/1/final.pt", want: Location{Scheme: "az", Bucket: "container", Key: "runs/1/final.pt"}},
+		{uri: "s3://models", wantErr: true},
+		{uri: "s3://models/dir/", wantErr: true},
+		{uri: "http://example.com/model.pt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := ParseURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseURI() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for path, want := range map[string]bool{
+		"save/final_model.pt": false,
+		"/tmp/model.pt":       false,
+		"s3://b/model.pt":     true,
+		"gs://b/model.pt":     true,
+		"az://c/model.pt":     true,
+		"file://model.pt":     false,
+	} {
+		if got := IsRemote(path); got != want {
+			t.Errorf("IsRemote(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestLocalFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save", "model.pt")
+	if err := WriteFile(path, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := ReadFile(path)
+	if err != nil || !bytes.Equal(data, []byte{1, 2, 3, 4}) {
+		t.Errorf("ReadFile() = %v, %v", data, err)
+	}
+}
+
+func TestLocalChecksums(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.pt")
+	if err := WriteFile(path, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte{5, 6, 7, 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sidecar is in sha256sum format, and no temporary files are left
+	sidecar, err := os.ReadFile(ChecksumPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "  model.pt\n"; len(sidecar) != 64+len(want) || !strings.HasSuffix(string(sidecar), want) {
+		t.Errorf("Sidecar = %q", sidecar)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Directory has %d entries, want the model and its sidecar", len(entries))
+	}
+
+	// A truncated model fails its check
+	if err := os.WriteFile(path, []byte{5, 6}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("ReadFile() of a truncated model error = %v, want ErrCorrupt", err)
+	}
+
+	// Models without a sidecar are read unchecked
+	if err := os.Remove(ChecksumPath(path)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ReadFile(path); err != nil || !bytes.Equal(data, []byte{5, 6}) {
+		t.Errorf("ReadFile() without sidecar = %v, %v", data, err)
+	}
+}
@@ -0,0 +1,176 @@
+package testkit
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ishaileshpant/fl-go/pkg/collaborator"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// TrainFunction is the name the linear regression task is registered under
+// for tasks with runner go
+const TrainFunction = "testkit.linear_regression"
+
+func init() {
+	collaborator.RegisterTrainFunc(TrainFunction, trainLinearRegression)
+}
+
+// LinearRegression is a synthetic task that fits y = x·w + b. Each
+// collaborator trains on its own shard, whose features are shifted by a
+// shard-specific offset, so the shards are not identically distributed but
+// share one true model. Models are the weights followed by the bias.
+type LinearRegression struct {
+	Seed     uint64
+	Features int
+	Samples  int     // samples per shard
+	Noise    float64 // standard deviation of the label noise
+}
+
+// Truth returns the model the shards are drawn from
+func (r LinearRegression) Truth() []float32 {
+	rng := rand.New(rand.NewPCG(r.Seed, 0))
+	truth := make([]float32, r.Features+1)
+	for i := range truth {
+		truth[i] = float32(rng.NormFloat64())
+	}
+	return truth
+}
+
+// Shard returns the features and labels of the shard with index shard
+func (r LinearRegression) Shard(shard int) (x [][]float64, y []float64) {
+	truth := r.Truth()
+	rng := rand.New(rand.NewPCG(r.Seed, uint64(shard)+1)) // #nosec G115 - shard indices are not negative
+	offset := make([]float64, r.Features)
+	for j := range offset {
+		offset[j] = 0.5 * rng.NormFloat64()
+	}
+
+	x = make([][]float64, r.Samples)
+	y = make([]float64, r.Samples)
+	for i := range x {
+		x[i] = make([]float64, r.Features)
+		label := float64(truth[r.Features])
+		for j := range x[i] {
+			x[i][j] = rng.NormFloat64() + offset[j]
+			label += x[i][j] * float64(truth[j])
+		}
+		y[i] = label + r.Noise*rng.NormFloat64()
+	}
+	return x, y
+}
+
+// Train takes epochs full-batch gradient steps of size learningRate on the
+// mean squared error of the shard
+func (r LinearRegression) Train(model []float32, shard, epochs int, learningRate float64) []float32 {
+	x, y := r.Shard(shard)
+	w := make([]float64, len(model))
+	for i, v := range model {
+		w[i] = float64(v)
+	}
+	grad := make([]float64, len(w))
+	for e := 0; e < epochs; e++ {
+		clear(grad)
+		for i := range x {
+			residual := predict(w, x[i]) - y[i]
+			for j, v := range x[i] {
+				grad[j] += residual * v
+			}
+			grad[r.Features] += residual
+		}
+		for j := range w {
+			w[j] -= learningRate * grad[j] / float64(len(x))
+		}
+	}
+
+	updated := make([]float32, len(w))
+	for i, v := range w {
+		updated[i] = float32(v)
+	}
+	return updated
+}
+
+// Loss returns the mean squared error of model over the first shards shards
+func (r LinearRegression) Loss(model []float32, shards int) float64 {
+	w := make([]float64, len(model))
+	for i, v := range model {
+		w[i] = float64(v)
+	}
+	var loss float64
+	var n int
+	for shard := 0; shard < shards; shard++ {
+		x, y := r.Shard(shard)
+		for i := range x {
+			residual := predict(w, x[i]) - y[i]
+			loss += residual * residual
+			n++
+		}
+	}
+	return loss / float64(n)
+}
+
+// args returns the shared train task args of the task. Each collaborator
+// adds the shard it trains on.
+func (r LinearRegression) args() map[string]interface{} {
+	return map[string]interface{}{
+		"seed":     int(r.Seed), // #nosec G115 - reinterpreted, and reversed by the task
+		"features": r.Features,
+		"samples":  r.Samples,
+		"noise":    r.Noise,
+	}
+}
+
+func predict(w, x []float64) float64 {
+	prediction := w[len(x)]
+	for j, v := range x {
+		prediction += w[j] * v
+	}
+	return prediction
+}
+
+// trainLinearRegression is the TrainFunc of the task. Its args are those of
+// LinearRegression.args, with the shard, epochs and learning_rate.
+func trainLinearRegression(model []byte, args map[string]interface{}) ([]byte, error) {
+	// The seed is an integer arg, since a float64 does not hold every seed
+	seed, ok := args["seed"].(int)
+	if !ok {
+		return nil, fmt.Errorf("linear regression task requires an integer seed, got %v", args["seed"])
+	}
+	var values [6]float64
+	for i, key := range []string{"features", "samples", "noise", "shard", "epochs", "learning_rate"} {
+		v, err := number(args, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	task := LinearRegression{
+		Seed:     uint64(seed), // #nosec G115 - reverses args
+		Features: int(values[0]),
+		Samples:  int(values[1]),
+		Noise:    values[2],
+	}
+	weights, err := transport.DecodeWeights(model, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(weights) != task.Features+1 {
+		return nil, fmt.Errorf("model has %d parameters, the task %d features and a bias", len(weights), task.Features)
+	}
+	return transport.EncodeWeights(task.Train(weights, int(values[3]), int(values[4]), values[5])), nil
+}
+
+// number returns the numeric arg key, which is an int or a float64 after
+// YAML decoding
+func number(args map[string]interface{}, key string) (float64, error) {
+	switch v := args[key].(type) {
+	case int:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case nil:
+		return 0, fmt.Errorf("linear regression task requires the arg %s", key)
+	default:
+		return 0, fmt.Errorf("linear regression arg %s must be a number, got %v", key, v)
+	}
+}
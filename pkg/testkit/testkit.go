@@ -0,0 +1,256 @@
+// Package testkit runs complete federations in process for end-to-end
+// tests. An aggregator and its collaborators talk gRPC on an ephemeral local
+// port and train a synthetic linear regression task implemented in Go, so a
+// test can assert that a mode or algorithm converges without Python or a
+// dataset.
+//
+// Run changes the working directory to a temporary one for the duration of
+// the federation, since aggregators write their models relative to it. Tests
+// that use it must not run in parallel.
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/collaborator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"github.com/ishaileshpant/fl-go/pkg/storage"
+	"github.com/ishaileshpant/fl-go/pkg/transport"
+)
+
+// Options describes a federation. The zero value runs three collaborators
+// for five sync rounds of FedAvg.
+type Options struct {
+	Mode            federation.FLMode      // sync (default) or async
+	Algorithm       string                 // fedavg (default), fedavgm, fednova, fedopt, fedprox, ...
+	Hyperparameters map[string]interface{} // of the algorithm
+	Collaborators   int                    // default 3
+	Rounds          int                    // rounds, or in async mode the rounds each collaborator trains (default 5)
+	Epochs          int                    // local gradient steps per round (default 5)
+	LearningRate    float64                // local step size (default 0.1)
+	Task            LinearRegression       // default: seed 1, 4 features, 64 samples per shard, noise 0.05
+	Timeout         time.Duration          // the federation fails when it takes longer (default 2 minutes)
+	// Plan adjusts the plan before the federation starts, such as to enable
+	// a feature under test
+	Plan func(plan *federation.FLPlan)
+}
+
+// Result is the outcome of a federation
+type Result struct {
+	Plan        *federation.FLPlan
+	Model       []float32 // the final model
+	Report      *aggregator.RunReport
+	InitialLoss float64 // of the initial model on all shards
+	Loss        float64 // of the final model on all shards
+}
+
+// AssertConverged fails the test unless the final model lowered the loss of
+// the initial one to at most ratio of it
+func (r *Result) AssertConverged(t testing.TB, ratio float64) {
+	t.Helper()
+	if r.Loss > ratio*r.InitialLoss {
+		t.Errorf("%s %s federation did not converge: loss %.4g, want at most %.4g (%g of the initial %.4g)",
+			r.Plan.Mode, r.Report.Algorithm, r.Loss, ratio*r.InitialLoss, ratio, r.InitialLoss)
+	}
+}
+
+// Run runs a federation to completion and fails the test if the aggregator
+// or a collaborator fails. Sync federations end after their rounds, async
+// ones once every collaborator trained its rounds.
+func Run(t testing.TB, opts Options) *Result {
+	t.Helper()
+	opts = withDefaults(opts)
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	address, err := transport.FreeAddress()
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	plan := NewPlan(opts, address)
+	initial := make([]float32, opts.Task.Features+1)
+	if err := storage.WriteFile(plan.InitialModel, transport.EncodeWeights(initial)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	aggCtx, stopAggregator := context.WithCancel(ctx)
+	defer stopAggregator()
+
+	agg := aggregator.NewAggregator(plan)
+	aggDone := make(chan error, 1)
+	go func() { aggDone <- agg.Start(aggCtx) }()
+	if err := waitForAggregator(ctx, address, aggDone); err != nil {
+		t.Fatalf("aggregator did not start: %v", err)
+	}
+
+	collabErrs := runCollaborators(ctx, plan)
+	if plan.Mode == federation.ModeAsync || collabErrs != nil {
+		// Async aggregators run until they are stopped, and a failed
+		// collaborator leaves a sync round waiting
+		stopAggregator()
+	}
+	aggErr := <-aggDone
+	if collabErrs != nil {
+		t.Fatalf("collaborators failed: %v", collabErrs)
+	}
+	report := agg.Report()
+	if aggErr != nil && report.Status == aggregator.RunFailed {
+		t.Fatalf("aggregator failed: %v", aggErr)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("federation did not complete within %s", opts.Timeout)
+	}
+
+	data, err := storage.ReadFile(plan.OutputModel)
+	if err != nil {
+		t.Fatalf("failed to read the final model: %v", err)
+	}
+	model, err := transport.DecodeWeights(data, 0)
+	if err != nil {
+		t.Fatalf("invalid final model: %v", err)
+	}
+	return &Result{
+		Plan:        plan,
+		Model:       model,
+		Report:      report,
+		InitialLoss: opts.Task.Loss(initial, opts.Collaborators),
+		Loss:        opts.Task.Loss(model, opts.Collaborators),
+	}
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Mode == "" {
+		opts.Mode = federation.ModeSync
+	}
+	if opts.Collaborators <= 0 {
+		opts.Collaborators = 3
+	}
+	if opts.Rounds <= 0 {
+		opts.Rounds = 5
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = 5
+	}
+	if opts.LearningRate <= 0 {
+		opts.LearningRate = 0.1
+	}
+	if opts.Task.Features <= 0 {
+		opts.Task = LinearRegression{Seed: 1, Features: 4, Samples: 64, Noise: 0.05}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+	return opts
+}
+
+// NewPlan returns the plan of the federation opts describes, with its
+// aggregator on address and its models in the working directory
+func NewPlan(opts Options, address string) *federation.FLPlan {
+	opts = withDefaults(opts)
+	args := opts.Task.args()
+	args["epochs"] = opts.Epochs
+	args["learning_rate"] = opts.LearningRate
+
+	plan := &federation.FLPlan{
+		Mode:         opts.Mode,
+		Rounds:       opts.Rounds,
+		Seed:         opts.Task.Seed,
+		InitialModel: "models/initial.pt",
+		OutputModel:  "models/final.pt",
+		Aggregator:   federation.AggregatorEntry{Address: address},
+		Algorithm:    federation.AlgorithmConfig{Name: opts.Algorithm, Hyperparameters: opts.Hyperparameters},
+		Tasks: federation.TasksConfig{
+			Train: federation.TaskConfig{Runner: federation.RunnerGo, Function: TrainFunction, Args: args},
+		},
+	}
+	for i := 0; i < opts.Collaborators; i++ {
+		plan.Collaborators = append(plan.Collaborators, federation.Collaborator{
+			ID:       fmt.Sprintf("collaborator%d", i+1),
+			TaskArgs: federation.TaskArgsConfig{Train: map[string]interface{}{"shard": i}},
+		})
+	}
+	if opts.Mode == federation.ModeAsync {
+		plan.AsyncConfig = federation.AsyncConfig{
+			MaxStaleness:     60,
+			MinUpdates:       opts.Collaborators,
+			AggregationDelay: 1,
+			StalenessWeight:  0.9,
+		}
+	}
+	if opts.Plan != nil {
+		opts.Plan(plan)
+	}
+	return plan
+}
+
+// runCollaborators runs the plan's collaborators until all are done and
+// returns their errors
+func runCollaborators(ctx context.Context, plan *federation.FLPlan) error {
+	errs := make([]error, len(plan.Collaborators))
+	var wg sync.WaitGroup
+	for i, collab := range plan.Collaborators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runCollaborator(ctx, plan, collab.ID); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", collab.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func runCollaborator(ctx context.Context, plan *federation.FLPlan, id string) error {
+	c := collaborator.NewCollaborator(plan, id)
+	c.SetContext(ctx)
+	c.SetModelDir(filepath.Join("collaborators", id))
+	if err := c.SetLocalConfig(federation.LocalConfig{}); err != nil {
+		return err
+	}
+	if err := c.Connect(); err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Run(federation.CollaboratorTasks(plan, id).Train)
+}
+
+// waitForAggregator waits until the aggregator accepts connections on
+// address, or returns why it stopped
+func waitForAggregator(ctx context.Context, address string, done <-chan error) error {
+	for {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		select {
+		case err := <-done:
+			if err == nil {
+				err = errors.New("the aggregator stopped")
+			}
+			return err
+		case <-ctx.Done():
+			return err
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package testkit
+
+import (
+	"testing"
+
+	"github.com/ishaileshpant/fl-go/pkg/aggregator"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+)
+
+func TestLinearRegressionTrain(t *testing.T) {
+	task := LinearRegression{Seed: 3, Features: 3, Samples: 128, Noise: 0.01}
+	model := task.Train(make([]float32, 4), 0, 200, 0.1)
+	if loss := task.Loss(model, 1); loss > 0.01 {
+		t.Errorf("loss after training = %v, want at most 0.01", loss)
+	}
+	for i, want := range task.Truth() {
+		if d := model[i] - want; d > 0.05 || d < -0.05 {
+			t.Errorf("model = %v, want about %v", model, task.Truth())
+			break
+		}
+	}
+}
+
+func TestSyncFederation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a federation")
+	}
+	result := Run(t, Options{})
+	if result.Report.Status != aggregator.RunCompleted || result.Report.RoundsCompleted != 5 {
+		t.Errorf("report = %s after %d rounds, want completed after 5", result.Report.Status, result.Report.RoundsCompleted)
+	}
+	result.AssertConverged(t, 0.05)
+}
+
+func TestAsyncFederation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a federation")
+	}
+	result := Run(t, Options{Mode: federation.ModeAsync, Rounds: 3})
+	if result.Report.RoundsCompleted == 0 {
+		t.Error("async federation completed no aggregation")
+	}
+	result.AssertConverged(t, 0.2)
+}
+
+func TestAlgorithms(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs federations")
+	}
+	// feddf is left out, since it distills with a script
+	tests := []struct {
+		algorithm       string
+		mode            federation.FLMode
+		hyperparameters map[string]interface{}
+	}{
+		{algorithm: "fedavgm", hyperparameters: map[string]interface{}{"momentum": 0.5}},
+		{algorithm: "fednova"},
+		{algorithm: "fedopt", hyperparameters: map[string]interface{}{"server_learning_rate": 0.3}},
+		{algorithm: "fedprox", hyperparameters: map[string]interface{}{"mu": 0.01}},
+		{algorithm: "fedprox", mode: federation.ModeAsync, hyperparameters: map[string]interface{}{"mu": 0.01}},
+	}
+	for _, tt := range tests {
+		name := tt.algorithm
+		if tt.mode != "" {
+			name += "/" + string(tt.mode)
+		}
+		rounds, ratio := 10, 0.1
+		if tt.mode == federation.ModeAsync {
+			// Collaborators wait between async rounds, so they train fewer
+			rounds, ratio = 3, 0.2
+		}
+		t.Run(name, func(t *testing.T) {
+			result := Run(t, Options{Mode: tt.mode, Algorithm: tt.algorithm, Hyperparameters: tt.hyperparameters, Rounds: rounds})
+			result.AssertConverged(t, ratio)
+		})
+	}
+}
+
+func TestPlanOptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a federation")
+	}
+	result := Run(t, Options{
+		Algorithm:     "fedprox",
+		Collaborators: 2,
+		Rounds:        10,
+		Plan: func(plan *federation.FLPlan) {
+			plan.Security.UpdateClipping = federation.UpdateClippingConfig{Enabled: true, MaxNorm: 10}
+		},
+	})
+	if !result.Plan.Security.UpdateClipping.Enabled || len(result.Report.Participation) != 2 {
+		t.Errorf("plan = %+v, participation = %v", result.Plan.Security, result.Report.Participation)
+	}
+	result.AssertConverged(t, 0.1)
+}
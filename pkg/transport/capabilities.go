@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+)
+
+const (
+	// ProtocolVersion is the federation protocol version of this build.
+	// Version 1 processes send no capabilities when joining.
+	ProtocolVersion = 2
+	// MinProtocolVersion is the oldest version this build works with
+	MinProtocolVersion = 1
+)
+
+// Update codecs
+const (
+	CodecNone = "none" // raw little-endian float32 weights
+)
+
+// Optional protocol features
+const (
+	FeatureTaskDispatch = "task_dispatch"     // GetTask and SubmitTaskResult
+	FeatureLocalPrivacy = "local_privacy"     // local differential privacy parameters sent with updates
+	FeatureEvents       = "events"            // WatchEvents control stream
+	FeatureDelta        = "delta"             // models and updates sent as diffs from a model both sides have
+	FeatureEncrypted    = "encrypted_updates" // updates encrypted for homomorphic aggregation
+)
+
+// LocalCapabilities returns what this build supports
+func LocalCapabilities() *pb.Capabilities {
+	return &pb.Capabilities{
+		ProtoVersion: ProtocolVersion,
+		Compression:  []string{CodecNone},
+		Features:     []string{FeatureTaskDispatch, FeatureLocalPrivacy, FeatureEvents, FeatureDelta, FeatureEncrypted},
+	}
+}
+
+// Negotiate returns the capabilities local and remote have in common, in
+// local's order of preference. It fails when they share no protocol version
+// or codec, or when remote lacks one of the required features. A nil remote
+// is a process of protocol version 1.
+func Negotiate(local, remote *pb.Capabilities, required ...string) (*pb.Capabilities, error) {
+	if remote == nil {
+		remote = &pb.Capabilities{ProtoVersion: 1, Compression: []string{CodecNone}}
+	}
+	if remote.ProtoVersion < MinProtocolVersion {
+		return nil, fmt.Errorf("protocol version %d is older than the oldest supported version %d",
+			remote.ProtoVersion, MinProtocolVersion)
+	}
+
+	negotiated := &pb.Capabilities{
+		ProtoVersion: min(local.ProtoVersion, remote.ProtoVersion),
+		Compression:  intersect(local.Compression, remote.Compression),
+		Features:     intersect(local.Features, remote.Features),
+	}
+	if len(negotiated.Compression) == 0 {
+		return nil, fmt.Errorf("no common compression codec: supported %s, offered %s",
+			strings.Join(local.Compression, ", "), strings.Join(remote.Compression, ", "))
+	}
+
+	var missing []string
+	for _, feature := range required {
+		if !HasFeature(negotiated, feature) {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required features %s (protocol version %d); upgrade it",
+			strings.Join(missing, ", "), remote.ProtoVersion)
+	}
+	return negotiated, nil
+}
+
+// HasFeature reports whether capabilities include feature
+func HasFeature(capabilities *pb.Capabilities, feature string) bool {
+	for _, f := range capabilities.GetFeatures() {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// intersect returns the values of a that are also in b, in a's order
+func intersect(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[v] = true
+	}
+	var common []string
+	for _, v := range a {
+		if in[v] {
+			common = append(common, v)
+		}
+	}
+	return common
+}
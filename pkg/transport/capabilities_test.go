@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+)
+
+func TestNegotiate(t *testing.T) {
+	local := &pb.Capabilities{
+		ProtoVersion: 3,
+		Compression:  []string{"zstd", "gzip", CodecNone},
+		Features:     []string{FeatureTaskDispatch, FeatureLocalPrivacy},
+	}
+
+	tests := []struct {
+		name     string
+		remote   *pb.Capabilities
+		required []string
+		want     *pb.Capabilities
+		wantErr  bool
+	}{
+		{
+			name:   "common subset",
+			remote: &pb.Capabilities{ProtoVersion: 2, Compression: []string{CodecNone, "gzip"}, Features: []string{FeatureLocalPrivacy, "streaming"}},
+			want:   &pb.Capabilities{ProtoVersion: 2, Compression: []string{"gzip", CodecNone}, Features: []string{FeatureLocalPrivacy}},
+		},
+		{
+			name: "version 1 peer",
+			want: &pb.Capabilities{ProtoVersion: 1, Compression: []string{CodecNone}},
+		},
+		{
+			name:     "version 1 peer without required feature",
+			required: []string{FeatureTaskDispatch},
+			wantErr:  true,
+		},
+		{
+			name:    "no common codec",
+			remote:  &pb.Capabilities{ProtoVersion: 2, Compression: []string{"lz4"}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported version",
+			remote:  &pb.Capabilities{ProtoVersion: 0, Compression: []string{CodecNone}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Negotiate(local, tt.remote, tt.required...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Negotiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ProtoVersion != tt.want.ProtoVersion ||
+				!reflect.DeepEqual(got.Compression, tt.want.Compression) ||
+				!reflect.DeepEqual(got.Features, tt.want.Features) {
+				t.Errorf("Negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// EncodeDelta returns the difference between two encoded models of the same
+// size as the bitwise XOR of their float32 values, compressed with DEFLATE.
+// Unchanged parameters XOR to zero and slowly changing ones share their sign,
+// exponent and leading mantissa bits, so the XOR is stored byte plane by byte
+// plane, which puts those zeros next to each other. Unlike a subtraction, the
+// XOR restores model bit for bit, so its hash can be checked.
+func EncodeDelta(base, model []byte) ([]byte, error) {
+	if len(base) != len(model) || len(model)%4 != 0 {
+		return nil, fmt.Errorf("cannot diff a %d-byte model against a %d-byte base", len(model), len(base))
+	}
+
+	n := len(model) / 4
+	planes := make([]byte, len(model))
+	for i := 0; i < n; i++ {
+		for b := 0; b < 4; b++ {
+			planes[b*n+i] = model[i*4+b] ^ base[i*4+b]
+		}
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(planes); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ApplyDelta restores the model that EncodeDelta diffed against base
+func ApplyDelta(base, delta []byte) ([]byte, error) {
+	if len(base)%4 != 0 {
+		return nil, fmt.Errorf("base model of %d bytes is not a whole number of float32 values", len(base))
+	}
+
+	planes := make([]byte, len(base))
+	r := flate.NewReader(bytes.NewReader(delta))
+	defer r.Close()
+	if _, err := io.ReadFull(r, planes); err != nil {
+		return nil, fmt.Errorf("invalid model delta: %v", err)
+	}
+	if n, _ := r.Read(make([]byte, 1)); n > 0 {
+		return nil, fmt.Errorf("model delta is larger than its %d-byte base", len(base))
+	}
+
+	n := len(base) / 4
+	model := make([]byte, len(base))
+	for i := 0; i < n; i++ {
+		for b := 0; b < 4; b++ {
+			model[i*4+b] = planes[b*n+i] ^ base[i*4+b]
+		}
+	}
+	return model, nil
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDeltaRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	base := make([]byte, 4*10000)
+	model := make([]byte, len(base))
+	for i := 0; i < len(base)/4; i++ {
+		v := rng.Float32()
+		binary.LittleEndian.PutUint32(base[i*4:], math.Float32bits(v))
+		if i%10 == 0 { // a slowly changing model
+			v += 1e-3 * rng.Float32()
+		}
+		binary.LittleEndian.PutUint32(model[i*4:], math.Float32bits(v))
+	}
+
+	delta, err := EncodeDelta(base, model)
+	if err != nil {
+		t.Fatalf("EncodeDelta() error = %v", err)
+	}
+	if len(delta) > len(model)/4 {
+		t.Errorf("delta of %d bytes for a %d-byte model that changed in 10%% of its values", len(delta), len(model))
+	}
+
+	got, err := ApplyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("ApplyDelta() error = %v", err)
+	}
+	if !bytes.Equal(got, model) {
+		t.Error("ApplyDelta() did not restore the model")
+	}
+}
+
+func TestDeltaRejectsMismatchedSizes(t *testing.T) {
+	if _, err := EncodeDelta(make([]byte, 8), make([]byte, 4)); err == nil {
+		t.Error("EncodeDelta() accepted models of different sizes")
+	}
+	delta, err := EncodeDelta(make([]byte, 8), make([]byte, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, base := range [][]byte{make([]byte, 4), make([]byte, 12)} {
+		if _, err := ApplyDelta(base, delta); err == nil {
+			t.Errorf("ApplyDelta() accepted a delta of an 8-byte model for a %d-byte base", len(base))
+		}
+	}
+}
+
+func FuzzApplyDelta(f *testing.F) {
+	base := []byte{0, 0, 128, 63, 0, 0, 0, 64}
+	delta, err := EncodeDelta(base, []byte{0, 0, 64, 64, 0, 0, 0, 64})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(base, delta)
+	f.Add(base[:4], delta)
+	f.Add(base[:7], delta)
+	f.Add(base, []byte("not a delta"))
+	f.Fuzz(func(t *testing.T, base, delta []byte) {
+		model, err := ApplyDelta(base, delta)
+		if err != nil {
+			return
+		}
+		if len(model) != len(base) {
+			t.Fatalf("ApplyDelta() restored a %d-byte model from a %d-byte base", len(model), len(base))
+		}
+		if roundTrip, err := EncodeDelta(base, model); err != nil {
+			t.Fatalf("EncodeDelta() of a restored model error = %v", err)
+		} else if again, err := ApplyDelta(base, roundTrip); err != nil || !bytes.Equal(again, model) {
+			t.Fatalf("re-encoded delta restored %v, %v", again, err)
+		}
+	})
+}
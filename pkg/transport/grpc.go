@@ -0,0 +1,115 @@
+// Package transport builds the gRPC server and client options of aggregators
+// and collaborators from a plan's grpc settings.
+package transport
+
+import (
+	"net"
+	"time"
+
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// DefaultMaxMessageSize is the message size limit in MB when the plan sets
+	// none. gRPC's own 4 MB limit is too small for most models.
+	DefaultMaxMessageSize = 256
+	// DefaultCallTimeout bounds a single call when the plan sets no deadline
+	DefaultCallTimeout = 30 * time.Second
+	// defaultKeepaliveTimeout is gRPC's wait for a ping acknowledgement
+	defaultKeepaliveTimeout = 20 * time.Second
+	// minKeepaliveTime is the shortest ping interval gRPC clients allow
+	minKeepaliveTime = 10 * time.Second
+)
+
+// ServerOptions returns the options of a gRPC server, to be combined with its
+// credentials
+func ServerOptions(config federation.GRPCConfig) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(megabytes(config.MaxRecvMsgSize)),
+		grpc.MaxSendMsgSize(megabytes(config.MaxSendMsgSize)),
+	}
+	if config.KeepaliveTime > 0 {
+		pingTime := keepaliveTime(config)
+		opts = append(opts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{Time: pingTime, Timeout: keepaliveTimeout(config)}),
+			// Clients use the same plan, so allow them to ping as often
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: pingTime, PermitWithoutStream: true}),
+		)
+	}
+	if config.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(seconds(config.ConnectionTimeout)))
+	}
+	return opts
+}
+
+// DialOptions returns the options of a gRPC client, to be combined with its
+// credentials
+func DialOptions(config federation.GRPCConfig) []grpc.DialOption {
+	opts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(megabytes(config.MaxRecvMsgSize)),
+			grpc.MaxCallSendMsgSize(megabytes(config.MaxSendMsgSize)),
+		),
+	}
+	if config.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime(config),
+			Timeout:             keepaliveTimeout(config),
+			PermitWithoutStream: true,
+		}))
+	}
+	if config.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: seconds(config.ConnectionTimeout),
+		}))
+	}
+	return opts
+}
+
+// CallTimeout returns the deadline of a single call
+func CallTimeout(config federation.GRPCConfig) time.Duration {
+	if config.CallTimeout <= 0 {
+		return DefaultCallTimeout
+	}
+	return seconds(config.CallTimeout)
+}
+
+func megabytes(mb int) int {
+	if mb <= 0 {
+		mb = DefaultMaxMessageSize
+	}
+	return mb << 20
+}
+
+func keepaliveTime(config federation.GRPCConfig) time.Duration {
+	if d := seconds(config.KeepaliveTime); d > minKeepaliveTime {
+		return d
+	}
+	return minKeepaliveTime
+}
+
+func keepaliveTimeout(config federation.GRPCConfig) time.Duration {
+	if config.KeepaliveTimeout <= 0 {
+		return defaultKeepaliveTimeout
+	}
+	return seconds(config.KeepaliveTimeout)
+}
+
+func seconds(s int) time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// FreeAddress returns a local address with a port that is free right now, for
+// aggregators started in-process by tests and load tests
+func FreeAddress() (string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer lis.Close()
+	return lis.Addr().String(), nil
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/ishaileshpant/fl-go/api"
+	"github.com/ishaileshpant/fl-go/pkg/federation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// largeModelServer hands out a model larger than gRPC's default 4 MB limit
+type largeModelServer struct {
+	pb.UnimplementedFederatedLearningServer
+}
+
+func (largeModelServer) JoinFederation(ctx context.Context, req *pb.JoinRequest) (*pb.JoinResponse, error) {
+	return &pb.JoinResponse{InitialModel: make([]byte, 6<<20)}, nil
+}
+
+func TestMessageSizeLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   federation.GRPCConfig
+		wantCode codes.Code
+	}{
+		{name: "default", wantCode: codes.OK},
+		{name: "keepalive and timeouts", config: federation.GRPCConfig{KeepaliveTime: 30, ConnectionTimeout: 5}, wantCode: codes.OK},
+		{name: "receive limit", config: federation.GRPCConfig{MaxRecvMsgSize: 1}, wantCode: codes.ResourceExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := grpc.NewServer(ServerOptions(tt.config)...)
+			pb.RegisterFederatedLearningServer(srv, largeModelServer{})
+			go srv.Serve(lis)
+			defer srv.Stop()
+
+			opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, DialOptions(tt.config)...)
+			conn, err := grpc.NewClient(lis.Addr().String(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), CallTimeout(tt.config))
+			defer cancel()
+			_, err = pb.NewFederatedLearningClient(conn).JoinFederation(ctx, &pb.JoinRequest{CollaboratorId: "c1"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("JoinFederation() code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	if got := CallTimeout(federation.GRPCConfig{}); got != DefaultCallTimeout {
+		t.Errorf("CallTimeout() = %v, want %v", got, DefaultCallTimeout)
+	}
+	if got := CallTimeout(federation.GRPCConfig{CallTimeout: 120}); got != 2*time.Minute {
+		t.Errorf("CallTimeout() = %v, want 2m", got)
+	}
+}
@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+// ModelHash identifies the version of an encoded model, such as the model an
+// update was trained on
+func ModelHash(model []byte) string {
+	sum := sha256.Sum256(model)
+	return hex.EncodeToString(sum[:])
+}
+
+// EncodeWeights encodes a model or update as an array of little-endian
+// float32 values, the encoding DecodeWeights reads
+func EncodeWeights(weights []float32) []byte {
+	data := make([]byte, 4*len(weights))
+	for i, v := range weights {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	return data
+}
+
+// DecodeWeights decodes a model or update, an array of little-endian float32
+// values. It fails unless data holds a whole, non-zero number of values, size
+// of them when size is positive, all of them finite, so that a malformed
+// payload is rejected instead of being truncated or read out of bounds.
+func DecodeWeights(data []byte, size int) ([]float32, error) {
+	if err := CheckWeights(data, size); err != nil {
+		return nil, err
+	}
+	weights := make([]float32, len(data)/4)
+	for i := range weights {
+		weights[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return weights, nil
+}
+
+// CheckWeights checks data like DecodeWeights does, without decoding it
+func CheckWeights(data []byte, size int) error {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return fmt.Errorf("payload of %d bytes is not a float32 array", len(data))
+	}
+	if size > 0 && len(data)/4 != size {
+		return fmt.Errorf("payload has %d parameters, expected %d", len(data)/4, size)
+	}
+	for i := 0; i < len(data); i += 4 {
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("payload contains non-finite value at index %d", i/4)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func encodeValues(values ...float32) []byte {
+	buf := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func TestDecodeWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		size    int
+		wantErr bool
+	}{
+		{"valid", encodeValues(1, -2, 3), 3, false},
+		{"any size", encodeValues(1, -2), 0, false},
+		{"empty", nil, 0, true},
+		{"truncated", encodeValues(1, 2)[:7], 0, true},
+		{"size mismatch", encodeValues(1, 2), 3, true},
+		{"nan", encodeValues(1, float32(math.NaN())), 2, true},
+		{"inf", encodeValues(float32(math.Inf(-1))), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := DecodeWeights(tt.data, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeWeights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(encodeValues(weights...), tt.data) {
+				t.Errorf("DecodeWeights() = %v, which does not encode back to the payload", weights)
+			}
+		})
+	}
+}
+
+func FuzzDecodeWeights(f *testing.F) {
+	f.Add(encodeValues(1, 2, 3), 3)
+	f.Add(encodeValues(1, 2, 3), 0)
+	f.Add([]byte{1, 2, 3}, 0)
+	f.Add(encodeValues(float32(math.NaN())), 1)
+	f.Add(encodeValues(float32(math.Inf(1)), 0), 2)
+	f.Fuzz(func(t *testing.T, data []byte, size int) {
+		weights, err := DecodeWeights(data, size)
+		if checkErr := CheckWeights(data, size); (checkErr == nil) != (err == nil) {
+			t.Fatalf("CheckWeights() = %v, DecodeWeights() = %v", checkErr, err)
+		}
+		if err != nil {
+			return
+		}
+		if len(weights) == 0 || 4*len(weights) != len(data) || (size > 0 && len(weights) != size) {
+			t.Fatalf("decoded %d weights from %d bytes with size %d", len(weights), len(data), size)
+		}
+		for i, v := range weights {
+			if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+				t.Fatalf("decoded non-finite weight %v at index %d", v, i)
+			}
+		}
+		if !bytes.Equal(encodeValues(weights...), data) {
+			t.Fatal("decoded weights do not encode back to the payload")
+		}
+	})
+}
+
+func TestEncodeWeights(t *testing.T) {
+	weights := []float32{1, -2.5, 0, float32(math.MaxFloat32)}
+	if data := EncodeWeights(weights); !bytes.Equal(data, encodeValues(weights...)) {
+		t.Errorf("EncodeWeights(%v) = %x, want little-endian float32 values", weights, data)
+	}
+	if data := EncodeWeights(nil); len(data) != 0 {
+		t.Errorf("EncodeWeights(nil) = %x, want no bytes", data)
+	}
+}
@@ -0,0 +1,68 @@
+#!/usr/bin/env python3
+"""
+Server-side ensemble distillation for the feddf algorithm.
+
+The student and the teachers are flat little-endian float32 models of a
+linear classifier, stored as the weight matrix (classes x features, row
+major) followed by the bias. The student is trained to match the mean of the
+teachers' softened predictions on unlabeled data.
+"""
+import argparse
+import json
+
+import numpy as np
+
+
+def load_model(path, features, classes):
+    params = np.fromfile(path, dtype="<f4").astype(np.float64)
+    expected = classes * features + classes
+    if params.size != expected:
+        raise SystemExit(f"{path} has {params.size} parameters, expected {expected}")
+    return params[:-classes].reshape(classes, features), params[-classes:]
+
+
+def softmax(logits, temperature):
+    z = logits / temperature
+    z -= z.max(axis=1, keepdims=True)
+    e = np.exp(z)
+    return e / e.sum(axis=1, keepdims=True)
+
+
+if __name__ == "__main__":
+    p = argparse.ArgumentParser()
+    p.add_argument("--model-in", required=True, help="student model, the average of the teachers")
+    p.add_argument("--model-out", required=True, help="where to write the distilled model")
+    p.add_argument("--teachers", required=True, help="JSON list of the client models")
+    p.add_argument("--data", required=True, help="unlabeled samples as a .npy array")
+    p.add_argument("--classes", type=int, default=10)
+    p.add_argument("--epochs", type=int, default=1)
+    p.add_argument("--batch-size", type=int, default=64)
+    p.add_argument("--lr", type=float, default=0.01)
+    p.add_argument("--temperature", type=float, default=1.0)
+    args = p.parse_args()
+
+    x = np.load(args.data).astype(np.float64)
+    x = x.reshape(len(x), -1)
+    features = x.shape[1]
+
+    with open(args.teachers) as f:
+        teachers = json.load(f)
+    ensemble = np.zeros((len(x), args.classes))
+    for teacher in teachers:
+        w, b = load_model(teacher["path"], features, args.classes)
+        ensemble += softmax(x @ w.T + b, args.temperature)
+    ensemble /= len(teachers)
+
+    w, b = load_model(args.model_in, features, args.classes)
+    rng = np.random.default_rng(0)
+    for _ in range(args.epochs):
+        order = rng.permutation(len(x))
+        for start in range(0, len(x), args.batch_size):
+            batch = order[start:start + args.batch_size]
+            student = softmax(x[batch] @ w.T + b, args.temperature)
+            # Gradient of T^2 * KL(ensemble || student) with respect to the logits
+            grad = (student - ensemble[batch]) * args.temperature / len(batch)
+            w -= args.lr * grad.T @ x[batch]
+            b -= args.lr * grad.sum(axis=0)
+
+    np.concatenate([w.ravel(), b]).astype("<f4").tofile(args.model_out)
@@ -0,0 +1,6 @@
+"""Python client of the FL-GO monitoring and control APIs."""
+
+from .monitoring import DEFAULT_SERVER, APIError, Client
+
+__all__ = ["DEFAULT_SERVER", "APIError", "Client"]
+__version__ = "1.0.0"
@@ -0,0 +1,346 @@
+"""Client of the FL-GO monitoring API.
+
+Mirrors the Go client in pkg/monitoring/client. Records are returned as the
+dicts decoded from the API's JSON; their schemas are described by the OpenAPI
+document served at /api/v1/openapi.json.
+"""
+
+import datetime
+import json
+import os
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+
+DEFAULT_SERVER = "http://localhost:8080"
+
+# Records requested per page while taking a backup
+_BACKUP_PAGE_SIZE = 1000
+# Records sent per import request while restoring a backup
+_RESTORE_BATCH_SIZE = 5000
+
+# Statuses of requests the server turned away before processing them
+_REJECTED = (429, 503)
+# Statuses worth retrying for requests that are safe to repeat
+_TRANSIENT = (502, 504)
+_IDEMPOTENT = ("GET", "PUT", "DELETE")
+
+
+class APIError(Exception):
+    """An error response of the monitoring API."""
+
+    def __init__(self, status, message="", data=None):
+        self.status = status
+        self.message = message
+        self.data = data
+        if message:
+            super().__init__(f"monitoring server returned {status}: {message}")
+        else:
+            super().__init__(f"monitoring server returned {status}")
+
+    @property
+    def not_found(self):
+        return self.status == 404
+
+
+class Client:
+    """Calls the monitoring API.
+
+    Requests authenticate with api_key (sent as X-API-Key), or with token (a
+    JWT) when no key is given. api_key defaults to $FLGO_API_KEY. Requests the
+    server turned away, and safe requests that failed in transit, are retried
+    max_retries times with exponential backoff.
+    """
+
+    def __init__(self, server=DEFAULT_SERVER, api_key=None, token=None,
+                 timeout=30.0, max_retries=3, retry_backoff=0.5):
+        parsed = urllib.parse.urlparse(server)
+        if parsed.scheme not in ("http", "https") or not parsed.netloc:
+            raise ValueError(f"monitoring server must be an http(s) URL, got {server!r}")
+        self.server = server.rstrip("/")
+        self.api_key = api_key if api_key is not None else os.environ.get("FLGO_API_KEY")
+        self.token = token
+        self.timeout = timeout
+        self.max_retries = max(max_retries, 0)
+        self.retry_backoff = retry_backoff
+
+    # Queries
+
+    def health(self):
+        return self._call("GET", "/health")
+
+    def list_federations(self, **filters):
+        """Lists federations. Filters are the query parameters of the API,
+        such as status="running" or active=True."""
+        return self._call("GET", "/federations", filters) or []
+
+    def get_federation(self, federation_id):
+        return self._call("GET", "/federations/" + _escape(federation_id))
+
+    def get_rounds(self, **filters):
+        return self._call("GET", "/rounds", filters) or []
+
+    def get_collaborators(self, **filters):
+        return self._call("GET", "/collaborators", filters) or []
+
+    def get_events(self, **filters):
+        return self._call("GET", "/events", filters) or []
+
+    def get_ledger(self, **filters):
+        return self._call("GET", "/ledger", filters) or []
+
+    def get_time_series(self, federation_id, metrics, interval=None, start_time=None, end_time=None):
+        """Gets bucketed time series of a federation. metrics is a list of
+        metric names such as ["accuracy", "loss"]."""
+        params = {"metrics": ",".join(metrics), "interval": interval,
+                  "start_time": start_time, "end_time": end_time}
+        return self._call("GET", f"/federations/{_escape(federation_id)}/timeseries", params) or []
+
+    def compare_federations(self, a, b, threshold=None):
+        """Compares the configuration, metrics and convergence of two
+        federations. With a threshold, the rounds and time each took to reach
+        that accuracy are compared too."""
+        return self._call("GET", f"/federations/{_escape(a)}/compare/{_escape(b)}",
+                          {"threshold": threshold})
+
+    def get_updates(self, **filters):
+        return self._call("GET", "/updates", filters) or []
+
+    def list_dashboards(self):
+        return self._call("GET", "/dashboards") or []
+
+    # Controlling federations
+
+    def pause_federation(self, federation_id, reason=None):
+        """Pauses a federation through its aggregator and returns the
+        federation with its new status."""
+        return self._control(federation_id, "pause", reason)
+
+    def resume_federation(self, federation_id, reason=None):
+        return self._control(federation_id, "resume", reason)
+
+    def abort_federation(self, federation_id, reason=None):
+        return self._control(federation_id, "abort", reason)
+
+    def _control(self, federation_id, action, reason):
+        return self._call("POST", f"/federations/{_escape(federation_id)}/{action}",
+                          body={"reason": reason or ""})
+
+    # Submitting metrics
+
+    def post_metrics(self, items):
+        """Records a batch of metrics of different kinds. Each item is a dict
+        with a type (round_start, round_end, model_update, aggregation,
+        contribution, event or resource) and the record as data. Items are
+        processed independently; when some fail, APIError is raised with the
+        result, which lists them, as its data."""
+        return self._call("POST", "/ingest", body={"items": items})
+
+    def record_event(self, event):
+        return self._call("POST", "/events", body=event)
+
+    def import_history(self, archive):
+        return self._call("POST", "/import", body=archive)
+
+    # Backups
+
+    def backup(self):
+        """Reads all federations with their collaborators, rounds, model
+        updates and events, and all dashboards. Each federation is kept as an
+        import archive, so the backup restores through restore() or
+        import_history() on any server."""
+        backup = {"created_at": datetime.datetime.now(datetime.timezone.utc).isoformat(),
+                  "federations": [], "dashboards": []}
+        for federation in self._list_all(self.list_federations):
+            fid = federation["id"]
+            backup["federations"].append({
+                "federation": federation,
+                "collaborators": self._list_all(self.get_collaborators, federation_id=fid),
+                "rounds": self._list_all(self.get_rounds, federation_id=fid),
+                "updates": self._list_all(self.get_updates, federation_id=fid),
+                "events": self._list_all(self.get_events, federation_id=fid),
+            })
+        backup["dashboards"] = self.list_dashboards()
+        return backup
+
+    def restore(self, backup):
+        """Imports the federations and dashboards of a backup taken by
+        backup(). Records that the server already has are skipped, so an
+        interrupted restore can be run again. Returns what was added, as the
+        Go client's Restore does."""
+        result = {"federations": [], "dashboards": 0}
+        for archive in backup.get("federations") or []:
+            total = {"federation_id": _archive_federation_id(archive), "federation_created": False,
+                     "collaborators": 0, "rounds": 0, "updates": 0, "events": 0, "skipped": 0}
+            for part in _split_archive(archive, _RESTORE_BATCH_SIZE):
+                imported = self.import_history(part) or {}
+                total["federation_created"] = total["federation_created"] or bool(imported.get("federation_created"))
+                for key in ("collaborators", "rounds", "updates", "events", "skipped"):
+                    total[key] += imported.get(key, 0)
+            result["federations"].append(total)
+        for dashboard in backup.get("dashboards") or []:
+            self._call("POST", "/dashboards", body=dashboard)
+            result["dashboards"] += 1
+        return result
+
+    def _list_all(self, list_page, **filters):
+        """Requests the pages of a list until a page comes back short."""
+        records = []
+        page = 1
+        while True:
+            batch = list_page(page=page, per_page=_BACKUP_PAGE_SIZE, **filters)
+            records.extend(batch)
+            if len(batch) < _BACKUP_PAGE_SIZE:
+                return records
+            page += 1
+
+    # API keys
+
+    def list_api_keys(self):
+        return self._call("GET", "/apikeys") or []
+
+    def create_api_key(self, role, name=None, expires_in=None, org_id=None):
+        request = {"role": role, "name": name, "expires_in": expires_in, "org_id": org_id}
+        return self._call("POST", "/apikeys", body={k: v for k, v in request.items() if v})
+
+    def revoke_api_key(self, key_id):
+        return self._call("DELETE", "/apikeys/" + _escape(key_id))
+
+    def rotate_api_key(self, key_id):
+        return self._call("POST", f"/apikeys/{_escape(key_id)}/rotate")
+
+    # Streaming
+
+    def stream_events(self, federation_id=None, event_types=None):
+        """Yields the events of a federation, or of all federations, as they
+        are recorded. Requires the websocket-client package (pip install
+        flgo[stream])."""
+        try:
+            import websocket
+        except ImportError as e:
+            raise ImportError("stream_events requires websocket-client; install flgo[stream]") from e
+
+        params = {"federation_id": federation_id}
+        if event_types:
+            params["event_types"] = ",".join(event_types)
+        url = "ws" + self._url("/ws", params)[len("http"):]
+
+        conn = self._retry("GET", lambda: websocket.create_connection(
+            url, header=[f"{k}: {v}" for k, v in self._auth_headers().items()], timeout=None))
+        try:
+            while True:
+                try:
+                    message = conn.recv()
+                except websocket.WebSocketConnectionClosedException:
+                    return
+                if message:
+                    yield json.loads(message)
+        finally:
+            conn.close()
+
+    # Plumbing
+
+    def _call(self, method, endpoint, params=None, body=None):
+        """Sends a request to endpoint under /api/v1 and returns the data of
+        the response envelope."""
+        payload = None if body is None else json.dumps(body).encode()
+        headers = self._auth_headers()
+        if payload is not None:
+            headers["Content-Type"] = "application/json"
+        request = urllib.request.Request(self._url(endpoint, params), data=payload,
+                                         headers=headers, method=method)
+
+        def attempt():
+            try:
+                with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                    envelope = _decode(response.read())
+                    status = response.status
+            except urllib.error.HTTPError as e:
+                envelope = _decode(e.read())
+                raise APIError(e.code, envelope.get("error", ""), envelope.get("data")) from None
+            if status != 200 or not envelope.get("success"):
+                raise APIError(status, envelope.get("error", ""), envelope.get("data"))
+            return envelope.get("data")
+
+        return self._retry(method, attempt)
+
+    def _retry(self, method, attempt):
+        backoff = self.retry_backoff
+        for tries in range(self.max_retries + 1):
+            try:
+                return attempt()
+            except APIError as e:
+                retryable = e.status in _REJECTED or (e.status in _TRANSIENT and method in _IDEMPOTENT)
+                if not retryable or tries == self.max_retries:
+                    raise
+            except (urllib.error.URLError, OSError):
+                # A request that may have reached the server is only repeated
+                # when repeating it is harmless
+                if method not in _IDEMPOTENT or tries == self.max_retries:
+                    raise
+            time.sleep(backoff)
+            backoff *= 2
+
+    def _url(self, endpoint, params=None):
+        url = self.server + "/api/v1" + endpoint
+        query = {k: _query_value(v) for k, v in (params or {}).items() if v is not None and v != ""}
+        if query:
+            url += "?" + urllib.parse.urlencode(query)
+        return url
+
+    def _auth_headers(self):
+        if self.api_key:
+            return {"X-API-Key": self.api_key}
+        if self.token:
+            return {"Authorization": "Bearer " + self.token}
+        return {}
+
+
+def _archive_federation_id(archive):
+    federation = archive.get("federation") or {}
+    if federation.get("id"):
+        return federation["id"]
+    for kind in ("rounds", "updates", "events"):
+        for record in archive.get(kind) or []:
+            if record.get("federation_id"):
+                return record["federation_id"]
+    return ""
+
+
+def _split_archive(archive, max_records):
+    """Splits an import archive into archives of at most max_records rounds,
+    updates and events. The first holds the federation and collaborators, so
+    they are imported before the records that refer to them."""
+    parts = [{"federation": archive.get("federation"), "collaborators": archive.get("collaborators") or []}]
+    count = 0
+    for kind in ("rounds", "updates", "events"):
+        for record in archive.get(kind) or []:
+            if count == max_records:
+                parts.append({})
+                count = 0
+            parts[-1].setdefault(kind, []).append(record)
+            count += 1
+    return parts
+
+
+def _escape(segment):
+    return urllib.parse.quote(segment, safe="")
+
+
+def _query_value(value):
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if hasattr(value, "isoformat"):
+        # The API expects RFC 3339 times with a zone
+        text = value.isoformat()
+        return text if value.tzinfo else text + "Z"
+    return str(value)
+
+
+def _decode(data):
+    try:
+        envelope = json.loads(data or b"{}")
+    except ValueError:
+        return {}
+    return envelope if isinstance(envelope, dict) else {}